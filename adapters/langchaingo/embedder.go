@@ -0,0 +1,76 @@
+package langchaingo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/tmc/langchaingo/embeddings"
+)
+
+// Embedder implements embeddings.Embedder on top of a Bifrost client.
+type Embedder struct {
+	client *bifrost.Bifrost
+	config Config
+}
+
+var _ embeddings.Embedder = (*Embedder)(nil)
+
+// NewEmbedder creates an embeddings.Embedder that sends embedding requests through client.
+func NewEmbedder(client *bifrost.Bifrost, config Config) (*Embedder, error) {
+	if client == nil {
+		return nil, errors.New("bifrost client is required")
+	}
+	if config.Provider == "" || config.Model == "" {
+		return nil, errors.New("provider and model are required")
+	}
+	return &Embedder{client: client, config: config}, nil
+}
+
+// EmbedDocuments implements embeddings.Embedder, returning a vector for each text.
+func (e *Embedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	resp, bifrostErr := e.client.EmbeddingRequest(ctx, &schemas.BifrostRequest{
+		Provider:  e.config.Provider,
+		Model:     e.config.Model,
+		Input:     schemas.RequestInput{EmbeddingInput: &schemas.EmbeddingInput{Texts: texts}},
+		Fallbacks: e.config.Fallbacks,
+	})
+	if bifrostErr != nil {
+		return nil, toError(bifrostErr)
+	}
+
+	data := append([]schemas.BifrostEmbedding(nil), resp.Data...)
+	sort.SliceStable(data, func(i, j int) bool { return data[i].Index < data[j].Index })
+
+	vectors := make([][]float32, 0, len(texts))
+	for _, embedding := range data {
+		switch {
+		case embedding.Embedding.EmbeddingArray != nil:
+			vectors = append(vectors, *embedding.Embedding.EmbeddingArray)
+		case embedding.Embedding.Embedding2DArray != nil:
+			vectors = append(vectors, *embedding.Embedding.Embedding2DArray...)
+		default:
+			return nil, errors.New("bifrost: embedding response is not a float vector")
+		}
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("bifrost: got %d embeddings for %d texts", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+// EmbedQuery implements embeddings.Embedder, returning the vector of a single text.
+func (e *Embedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := e.EmbedDocuments(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
@@ -0,0 +1,461 @@
+// Package langchaingo adapts the Bifrost core client to the interfaces of LangChain Go
+// (github.com/tmc/langchaingo), so existing LangChain Go applications gain Bifrost's fallbacks,
+// governance and observability by swapping the model they construct:
+//
+//	client, _ := bifrost.Init(ctx, schemas.BifrostConfig{Account: account})
+//
+//	llm, _ := langchaingo.NewLLM(client, langchaingo.Config{
+//		Provider:  schemas.OpenAI,
+//		Model:     "gpt-4o-mini",
+//		Fallbacks: []schemas.Fallback{{Provider: schemas.Anthropic, Model: "claude-3-5-haiku-20241022"}},
+//	})
+//	answer, _ := llms.GenerateFromSinglePrompt(ctx, llm, "Hello!")
+//
+//	embedder, _ := langchaingo.NewEmbedder(client, langchaingo.Config{
+//		Provider: schemas.OpenAI,
+//		Model:    "text-embedding-3-small",
+//	})
+//
+// Requests run with the context passed to each call, so context values read by plugins, such as
+// governance virtual keys, apply as they do for direct Bifrost calls.
+package langchaingo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	bifrost "github.com/maximhq/bifrost/core"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Config selects the model behind an LLM or Embedder.
+type Config struct {
+	Provider  schemas.ModelProvider // Provider of the model
+	Model     string                // Model to use, overridden per call by llms.WithModel
+	Fallbacks []schemas.Fallback    // Models tried in order when the primary one fails
+}
+
+// LLM implements llms.Model on top of a Bifrost client.
+type LLM struct {
+	client *bifrost.Bifrost
+	config Config
+}
+
+var _ llms.Model = (*LLM)(nil)
+
+// NewLLM creates an llms.Model that sends chat completions through client.
+func NewLLM(client *bifrost.Bifrost, config Config) (*LLM, error) {
+	if client == nil {
+		return nil, errors.New("bifrost client is required")
+	}
+	if config.Provider == "" || config.Model == "" {
+		return nil, errors.New("provider and model are required")
+	}
+	return &LLM{client: client, config: config}, nil
+}
+
+// Call implements llms.Model, generating a completion for a single text prompt.
+//
+// Deprecated: use GenerateContent or llms.GenerateFromSinglePrompt.
+func (l *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, l, prompt, options...)
+}
+
+// GenerateContent implements llms.Model. When a streaming function is set with
+// llms.WithStreamingFunc, the response is streamed and each content delta is passed to it.
+func (l *LLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	opts := llms.CallOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	chatMessages, err := convertMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+	params, err := convertCallOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, model := l.config.Provider, l.config.Model
+	if opts.Model != "" {
+		provider, model = parseModel(opts.Model, provider)
+	}
+
+	req := &schemas.BifrostRequest{
+		Provider:  provider,
+		Model:     model,
+		Input:     schemas.RequestInput{ChatCompletionInput: &chatMessages},
+		Params:    params,
+		Fallbacks: l.config.Fallbacks,
+	}
+
+	if opts.StreamingFunc != nil {
+		return l.generateStream(ctx, req, opts.StreamingFunc)
+	}
+
+	resp, bifrostErr := l.client.ChatCompletionRequest(ctx, req)
+	if bifrostErr != nil {
+		return nil, toError(bifrostErr)
+	}
+	return convertResponse(resp), nil
+}
+
+// generateStream runs a chat stream, passing content deltas to streamingFunc, and assembles the
+// streamed choices into a single response.
+func (l *LLM) generateStream(ctx context.Context, req *schemas.BifrostRequest, streamingFunc func(ctx context.Context, chunk []byte) error) (*llms.ContentResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, bifrostErr := l.client.ChatCompletionStreamRequest(ctx, req)
+	if bifrostErr != nil {
+		return nil, toError(bifrostErr)
+	}
+
+	var (
+		content   strings.Builder
+		reasoning strings.Builder
+		toolCalls []llms.ToolCall
+		stop      string
+		usage     *schemas.LLMUsage
+	)
+	for chunk := range stream {
+		if chunk == nil {
+			continue
+		}
+		if chunk.BifrostError != nil {
+			cancel()
+			drain(stream)
+			return nil, toError(chunk.BifrostError)
+		}
+		if chunk.BifrostResponse == nil {
+			continue
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 {
+				continue
+			}
+			if choice.FinishReason != nil {
+				stop = *choice.FinishReason
+			}
+			if choice.BifrostStreamResponseChoice == nil {
+				continue
+			}
+			delta := choice.BifrostStreamResponseChoice.Delta
+			if delta.Thought != nil {
+				reasoning.WriteString(*delta.Thought)
+			}
+			if delta.Content != nil && *delta.Content != "" {
+				content.WriteString(*delta.Content)
+				if err := streamingFunc(ctx, []byte(*delta.Content)); err != nil {
+					cancel()
+					drain(stream)
+					return nil, err
+				}
+			}
+			toolCalls = mergeToolCallDeltas(toolCalls, delta.ToolCalls)
+		}
+	}
+
+	choice := &llms.ContentChoice{
+		Content:          content.String(),
+		StopReason:       stop,
+		GenerationInfo:   generationInfo(usage),
+		ToolCalls:        toolCalls,
+		ReasoningContent: reasoning.String(),
+	}
+	if len(toolCalls) > 0 {
+		choice.FuncCall = toolCalls[0].FunctionCall
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{choice}}, nil
+}
+
+// mergeToolCallDeltas adds streamed tool call deltas to the calls assembled so far: a delta with
+// an ID starts a new call, one without continues the arguments of the last call.
+func mergeToolCallDeltas(calls []llms.ToolCall, deltas []schemas.ToolCall) []llms.ToolCall {
+	for _, delta := range deltas {
+		if delta.ID != nil && *delta.ID != "" || len(calls) == 0 {
+			calls = append(calls, convertToolCall(delta))
+			continue
+		}
+		last := calls[len(calls)-1].FunctionCall
+		if delta.Function.Name != nil && last.Name == "" {
+			last.Name = *delta.Function.Name
+		}
+		last.Arguments += delta.Function.Arguments
+	}
+	return calls
+}
+
+// convertMessages converts LangChain Go messages to Bifrost chat messages. Tool messages become
+// one Bifrost tool message per tool call response they hold.
+func convertMessages(messages []llms.MessageContent) ([]schemas.BifrostMessage, error) {
+	chatMessages := make([]schemas.BifrostMessage, 0, len(messages))
+	for _, msg := range messages {
+		var role schemas.ModelChatMessageRole
+		switch msg.Role {
+		case llms.ChatMessageTypeSystem:
+			role = schemas.ModelChatMessageRoleSystem
+		case llms.ChatMessageTypeHuman, llms.ChatMessageTypeGeneric:
+			role = schemas.ModelChatMessageRoleUser
+		case llms.ChatMessageTypeAI:
+			role = schemas.ModelChatMessageRoleAssistant
+		case llms.ChatMessageTypeTool, llms.ChatMessageTypeFunction:
+			for _, part := range msg.Parts {
+				response, ok := part.(llms.ToolCallResponse)
+				if !ok {
+					return nil, fmt.Errorf("unsupported %s message part %T", msg.Role, part)
+				}
+				chatMessages = append(chatMessages, schemas.BifrostMessage{
+					Role:        schemas.ModelChatMessageRoleTool,
+					Content:     schemas.MessageContent{ContentStr: bifrost.Ptr(response.Content)},
+					ToolMessage: &schemas.ToolMessage{ToolCallID: bifrost.Ptr(response.ToolCallID)},
+				})
+			}
+			continue
+		default:
+			return nil, fmt.Errorf("unsupported message role %q", msg.Role)
+		}
+
+		var blocks []schemas.ContentBlock
+		var toolCalls []schemas.ToolCall
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case llms.TextContent:
+				blocks = append(blocks, schemas.ContentBlock{Type: schemas.ContentBlockTypeText, Text: bifrost.Ptr(p.Text)})
+			case llms.ImageURLContent:
+				image := &schemas.ImageURLStruct{URL: p.URL}
+				if p.Detail != "" {
+					image.Detail = bifrost.Ptr(p.Detail)
+				}
+				blocks = append(blocks, schemas.ContentBlock{Type: schemas.ContentBlockTypeImage, ImageURL: image})
+			case llms.BinaryContent:
+				if !strings.HasPrefix(p.MIMEType, "image/") {
+					return nil, fmt.Errorf("unsupported binary content type %q", p.MIMEType)
+				}
+				blocks = append(blocks, schemas.ContentBlock{Type: schemas.ContentBlockTypeImage, ImageURL: &schemas.ImageURLStruct{URL: p.String()}})
+			case llms.ToolCall:
+				toolCall := schemas.ToolCall{Type: bifrost.Ptr(p.Type), ID: bifrost.Ptr(p.ID)}
+				if p.FunctionCall != nil {
+					toolCall.Function = schemas.FunctionCall{Name: bifrost.Ptr(p.FunctionCall.Name), Arguments: p.FunctionCall.Arguments}
+				}
+				toolCalls = append(toolCalls, toolCall)
+			default:
+				return nil, fmt.Errorf("unsupported %s message part %T", msg.Role, part)
+			}
+		}
+
+		chatMsg := schemas.BifrostMessage{Role: role}
+		if len(blocks) == 1 && blocks[0].Text != nil {
+			chatMsg.Content = schemas.MessageContent{ContentStr: blocks[0].Text}
+		} else if len(blocks) > 0 {
+			chatMsg.Content = schemas.MessageContent{ContentBlocks: &blocks}
+		}
+		if len(toolCalls) > 0 {
+			chatMsg.AssistantMessage = &schemas.AssistantMessage{ToolCalls: &toolCalls}
+		}
+		chatMessages = append(chatMessages, chatMsg)
+	}
+	return chatMessages, nil
+}
+
+// convertCallOptions converts LangChain Go call options to Bifrost model parameters. Zero values
+// are treated as unset, as LangChain Go does.
+func convertCallOptions(opts llms.CallOptions) (*schemas.ModelParameters, error) {
+	params := &schemas.ModelParameters{
+		ExtraParams: make(map[string]interface{}),
+	}
+
+	if opts.MaxTokens > 0 {
+		params.MaxTokens = bifrost.Ptr(opts.MaxTokens)
+	}
+	if opts.Temperature > 0 {
+		params.Temperature = bifrost.Ptr(opts.Temperature)
+	}
+	if opts.TopP > 0 {
+		params.TopP = bifrost.Ptr(opts.TopP)
+	}
+	if opts.TopK > 0 {
+		params.TopK = bifrost.Ptr(opts.TopK)
+	}
+	if opts.PresencePenalty != 0 {
+		params.PresencePenalty = bifrost.Ptr(opts.PresencePenalty)
+	}
+	if opts.FrequencyPenalty != 0 {
+		params.FrequencyPenalty = bifrost.Ptr(opts.FrequencyPenalty)
+	}
+	if len(opts.StopWords) > 0 {
+		params.StopSequences = &opts.StopWords
+	}
+	if opts.Seed != 0 {
+		params.ExtraParams["seed"] = opts.Seed
+	}
+	if opts.N > 1 {
+		params.ExtraParams["n"] = opts.N
+	}
+	if opts.JSONMode {
+		params.ExtraParams["response_format"] = map[string]interface{}{"type": "json_object"}
+	}
+
+	var tools []schemas.Tool
+	for _, tool := range opts.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		converted, err := convertFunction(*tool.Function)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, converted)
+	}
+	for _, function := range opts.Functions {
+		converted, err := convertFunction(function)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, converted)
+	}
+	if len(tools) > 0 {
+		params.Tools = &tools
+	}
+
+	switch choice := opts.ToolChoice.(type) {
+	case string:
+		params.ToolChoice = &schemas.ToolChoice{ToolChoiceStr: bifrost.Ptr(choice)}
+	case llms.ToolChoice:
+		params.ToolChoice = convertToolChoice(choice)
+	case *llms.ToolChoice:
+		if choice != nil {
+			params.ToolChoice = convertToolChoice(*choice)
+		}
+	}
+
+	return params, nil
+}
+
+// convertFunction converts a LangChain Go function definition to a Bifrost tool.
+func convertFunction(function llms.FunctionDefinition) (schemas.Tool, error) {
+	tool := schemas.Tool{
+		Type: "function",
+		Function: schemas.Function{
+			Name:        function.Name,
+			Description: function.Description,
+			Parameters:  schemas.FunctionParameters{Type: "object"},
+		},
+	}
+	if function.Parameters != nil {
+		data, err := sonic.Marshal(function.Parameters)
+		if err != nil {
+			return tool, fmt.Errorf("invalid parameters of function %s: %w", function.Name, err)
+		}
+		if err := sonic.Unmarshal(data, &tool.Function.Parameters); err != nil {
+			return tool, fmt.Errorf("invalid parameters of function %s: %w", function.Name, err)
+		}
+	}
+	return tool, nil
+}
+
+// convertToolChoice converts a LangChain Go tool choice to Bifrost format.
+func convertToolChoice(choice llms.ToolChoice) *schemas.ToolChoice {
+	if choice.Function == nil {
+		return &schemas.ToolChoice{ToolChoiceStr: bifrost.Ptr(choice.Type)}
+	}
+	return &schemas.ToolChoice{ToolChoiceStruct: &schemas.ToolChoiceStruct{
+		Type:     schemas.ToolChoiceTypeFunction,
+		Function: schemas.ToolChoiceFunction{Name: choice.Function.Name},
+	}}
+}
+
+// convertResponse converts a Bifrost chat response to a LangChain Go content response.
+func convertResponse(resp *schemas.BifrostResponse) *llms.ContentResponse {
+	response := &llms.ContentResponse{Choices: make([]*llms.ContentChoice, 0, len(resp.Choices))}
+	for _, choice := range resp.Choices {
+		contentChoice := &llms.ContentChoice{GenerationInfo: generationInfo(resp.Usage)}
+		if choice.FinishReason != nil {
+			contentChoice.StopReason = *choice.FinishReason
+		}
+		if choice.BifrostNonStreamResponseChoice != nil {
+			msg := choice.BifrostNonStreamResponseChoice.Message
+			contentChoice.Content = msg.Content.Text()
+			if msg.AssistantMessage != nil {
+				if msg.AssistantMessage.Thought != nil {
+					contentChoice.ReasoningContent = *msg.AssistantMessage.Thought
+				}
+				if msg.AssistantMessage.ToolCalls != nil {
+					for _, toolCall := range *msg.AssistantMessage.ToolCalls {
+						contentChoice.ToolCalls = append(contentChoice.ToolCalls, convertToolCall(toolCall))
+					}
+				}
+			}
+		}
+		if len(contentChoice.ToolCalls) > 0 {
+			contentChoice.FuncCall = contentChoice.ToolCalls[0].FunctionCall
+		}
+		response.Choices = append(response.Choices, contentChoice)
+	}
+	return response
+}
+
+// convertToolCall converts a Bifrost tool call to LangChain Go format.
+func convertToolCall(toolCall schemas.ToolCall) llms.ToolCall {
+	converted := llms.ToolCall{
+		Type:         "function",
+		FunctionCall: &llms.FunctionCall{Arguments: toolCall.Function.Arguments},
+	}
+	if toolCall.ID != nil {
+		converted.ID = *toolCall.ID
+	}
+	if toolCall.Type != nil {
+		converted.Type = *toolCall.Type
+	}
+	if toolCall.Function.Name != nil {
+		converted.FunctionCall.Name = *toolCall.Function.Name
+	}
+	return converted
+}
+
+// generationInfo reports token usage under the keys LangChain Go's own clients use.
+func generationInfo(usage *schemas.LLMUsage) map[string]any {
+	if usage == nil {
+		return nil
+	}
+	return map[string]any{
+		"PromptTokens":     usage.PromptTokens,
+		"CompletionTokens": usage.CompletionTokens,
+		"TotalTokens":      usage.TotalTokens,
+	}
+}
+
+// parseModel splits a "provider/model" string, keeping the default provider for plain model names.
+func parseModel(model string, defaultProvider schemas.ModelProvider) (schemas.ModelProvider, string) {
+	if provider, name, ok := strings.Cut(model, "/"); ok && provider != "" && name != "" {
+		return schemas.ModelProvider(provider), name
+	}
+	return defaultProvider, model
+}
+
+// toError converts a Bifrost error to a Go error, wrapping the underlying error if there is one.
+func toError(bifrostErr *schemas.BifrostError) error {
+	message := bifrostErr.Error.Message
+	if bifrostErr.StatusCode != nil {
+		message = fmt.Sprintf("%s (status %d)", message, *bifrostErr.StatusCode)
+	}
+	if bifrostErr.Error.Error != nil {
+		return fmt.Errorf("bifrost: %s: %w", message, bifrostErr.Error.Error)
+	}
+	return fmt.Errorf("bifrost: %s", message)
+}
+
+// drain discards the remaining chunks of a cancelled stream.
+func drain(stream chan *schemas.BifrostStream) {
+	for range stream {
+	}
+}
@@ -0,0 +1,401 @@
+// Package openaigo provides an http.RoundTripper that serves the requests of OpenAI API clients,
+// such as the official openai-go SDK, with the Bifrost core client in the same process. The SDK
+// keeps its types and call sites while requests gain Bifrost's fallbacks, load balancing and
+// plugins, without a network hop to a Bifrost gateway:
+//
+//	client, _ := bifrost.Init(ctx, schemas.BifrostConfig{Account: account})
+//
+//	oai := openai.NewClient(
+//		option.WithAPIKey("unused"),
+//		option.WithHTTPClient(&http.Client{Transport: openaigo.NewTransport(client, openaigo.Config{})}),
+//	)
+//	completion, _ := oai.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+//		Model:    "anthropic/claude-3-5-sonnet-20241022",
+//		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Hello!")},
+//	})
+//
+// Chat completions, streamed or not, and embeddings are served. Models may carry a provider
+// prefix ("provider/model"); plain model names use the configured default provider. Requests run
+// with the context of the SDK call, so context values read by plugins apply as they do for direct
+// Bifrost calls.
+package openaigo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	bifrost "github.com/maximhq/bifrost/core"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// Config configures a Transport.
+type Config struct {
+	// DefaultProvider serves models without a provider prefix, OpenAI if empty.
+	DefaultProvider schemas.ModelProvider
+	// Fallbacks are tried in order when the requested model fails.
+	Fallbacks []schemas.Fallback
+	// Next handles requests Bifrost does not serve, such as file uploads. If nil they are
+	// answered with a 404 error.
+	Next http.RoundTripper
+}
+
+// Transport is an http.RoundTripper that answers OpenAI API requests with a Bifrost client.
+type Transport struct {
+	client *bifrost.Bifrost
+	config Config
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// NewTransport creates a Transport that serves OpenAI API requests with client.
+func NewTransport(client *bifrost.Bifrost, config Config) *Transport {
+	if config.DefaultProvider == "" {
+		config.DefaultProvider = schemas.OpenAI
+	}
+	return &Transport{client: client, config: config}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	switch {
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/chat/completions"):
+		return t.chatCompletion(req)
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/embeddings"):
+		return t.embedding(req)
+	case t.config.Next != nil:
+		return t.config.Next.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return errorResponse(req, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("%s %s is not served by bifrost", req.Method, req.URL.Path)), nil
+}
+
+// chatCompletion serves a chat completion request, streamed when the request asks for it.
+func (t *Transport) chatCompletion(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if err := sonic.Unmarshal(body, &fields); err != nil {
+		return errorResponse(req, http.StatusBadRequest, "invalid_request_error", "invalid JSON body: "+err.Error()), nil
+	}
+	var input struct {
+		Messages []schemas.BifrostMessage `json:"messages"`
+		Stream   bool                     `json:"stream"`
+	}
+	if err := sonic.Unmarshal(body, &input); err != nil {
+		return errorResponse(req, http.StatusBadRequest, "invalid_request_error", "invalid messages: "+err.Error()), nil
+	}
+	params, err := convertParameters(fields)
+	if err != nil {
+		return errorResponse(req, http.StatusBadRequest, "invalid_request_error", err.Error()), nil
+	}
+
+	bifrostReq := t.newRequest(fields)
+	bifrostReq.Input.ChatCompletionInput = &input.Messages
+	bifrostReq.Params = params
+
+	if input.Stream {
+		return t.chatCompletionStream(req, bifrostReq)
+	}
+
+	resp, bifrostErr := t.client.ChatCompletionRequest(req.Context(), bifrostReq)
+	if bifrostErr != nil {
+		return bifrostErrorResponse(req, bifrostErr), nil
+	}
+	result := *resp
+	if result.Object == "" {
+		result.Object = "chat.completion"
+	}
+	return jsonResponse(req, &result)
+}
+
+// chatCompletionStream serves a streamed chat completion as server-sent events, ending with the
+// [DONE] event the SDK expects.
+func (t *Transport) chatCompletionStream(req *http.Request, bifrostReq *schemas.BifrostRequest) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	stream, bifrostErr := t.client.ChatCompletionStreamRequest(ctx, bifrostReq)
+	if bifrostErr != nil {
+		cancel()
+		return bifrostErrorResponse(req, bifrostErr), nil
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		defer cancel()
+		defer writer.Close()
+		for chunk := range stream {
+			if chunk == nil {
+				continue
+			}
+			var event interface{}
+			if chunk.BifrostError != nil {
+				event = errorBody(chunk.BifrostError.Error.Message, errorType(chunk.BifrostError), chunk.BifrostError.Error.Code)
+			} else if chunk.BifrostResponse != nil {
+				result := *chunk.BifrostResponse
+				if result.Object == "" {
+					result.Object = "chat.completion.chunk"
+				}
+				event = &result
+			} else {
+				continue
+			}
+			data, err := sonic.Marshal(event)
+			if err == nil {
+				_, err = fmt.Fprintf(writer, "data: %s\n\n", data)
+			}
+			if err != nil {
+				// The reader was closed, the SDK is no longer reading the stream
+				cancel()
+				drain(stream)
+				return
+			}
+		}
+		fmt.Fprint(writer, "data: [DONE]\n\n")
+	}()
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       reader,
+		Request:    req,
+	}, nil
+}
+
+// embedding serves an embedding request.
+func (t *Transport) embedding(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if err := sonic.Unmarshal(body, &fields); err != nil {
+		return errorResponse(req, http.StatusBadRequest, "invalid_request_error", "invalid JSON body: "+err.Error()), nil
+	}
+	var input struct {
+		Input schemas.EmbeddingInput `json:"input"`
+	}
+	if err := sonic.Unmarshal(body, &input); err != nil {
+		return errorResponse(req, http.StatusBadRequest, "invalid_request_error", "invalid input: "+err.Error()), nil
+	}
+
+	bifrostReq := t.newRequest(fields)
+	bifrostReq.Input.EmbeddingInput = &input.Input
+	bifrostReq.Params = &schemas.ModelParameters{}
+	if format, ok := fields["encoding_format"].(string); ok {
+		bifrostReq.Params.EncodingFormat = &format
+	}
+	if dimensions, ok := fields["dimensions"].(float64); ok {
+		bifrostReq.Params.Dimensions = bifrost.Ptr(int(dimensions))
+	}
+	if user, ok := fields["user"].(string); ok {
+		bifrostReq.Params.User = &user
+	}
+
+	resp, bifrostErr := t.client.EmbeddingRequest(req.Context(), bifrostReq)
+	if bifrostErr != nil {
+		return bifrostErrorResponse(req, bifrostErr), nil
+	}
+	return jsonResponse(req, map[string]interface{}{
+		"object": "list",
+		"data":   resp.Data,
+		"model":  resp.Model,
+		"usage":  resp.Usage,
+	})
+}
+
+// newRequest creates a Bifrost request for the model of an OpenAI request body.
+func (t *Transport) newRequest(fields map[string]interface{}) *schemas.BifrostRequest {
+	model, _ := fields["model"].(string)
+	provider := t.config.DefaultProvider
+	if prefix, name, ok := strings.Cut(model, "/"); ok && prefix != "" && name != "" {
+		provider, model = schemas.ModelProvider(prefix), name
+	}
+	return &schemas.BifrostRequest{
+		Provider:  provider,
+		Model:     model,
+		Fallbacks: t.config.Fallbacks,
+	}
+}
+
+// convertParameters converts the parameters of an OpenAI chat request body to Bifrost model
+// parameters. Parameters without a Bifrost field are passed on as extra parameters.
+func convertParameters(fields map[string]interface{}) (*schemas.ModelParameters, error) {
+	params := &schemas.ModelParameters{ExtraParams: make(map[string]interface{})}
+
+	for key, value := range fields {
+		if value == nil {
+			continue
+		}
+		switch key {
+		case "model", "messages", "stream":
+		case "max_tokens":
+			if tokens, ok := value.(float64); ok {
+				params.MaxTokens = bifrost.Ptr(int(tokens))
+			}
+		case "max_completion_tokens":
+			// Reasoning models reject max_tokens, so this is kept as its own parameter
+			if tokens, ok := value.(float64); ok {
+				params.ExtraParams["max_completion_tokens"] = int(tokens)
+			}
+		case "temperature":
+			params.Temperature = floatParam(value)
+		case "top_p":
+			params.TopP = floatParam(value)
+		case "presence_penalty":
+			params.PresencePenalty = floatParam(value)
+		case "frequency_penalty":
+			params.FrequencyPenalty = floatParam(value)
+		case "parallel_tool_calls":
+			if parallel, ok := value.(bool); ok {
+				params.ParallelToolCalls = &parallel
+			}
+		case "user":
+			if user, ok := value.(string); ok {
+				params.User = &user
+			}
+		case "stop":
+			switch stop := value.(type) {
+			case string:
+				params.StopSequences = &[]string{stop}
+			case []interface{}:
+				sequences := make([]string, 0, len(stop))
+				for _, sequence := range stop {
+					if s, ok := sequence.(string); ok {
+						sequences = append(sequences, s)
+					}
+				}
+				params.StopSequences = &sequences
+			}
+		case "tools":
+			var tools []schemas.Tool
+			if err := remarshal(value, &tools); err != nil {
+				return nil, fmt.Errorf("invalid tools: %w", err)
+			}
+			params.Tools = &tools
+		case "tool_choice":
+			var toolChoice schemas.ToolChoice
+			if err := remarshal(value, &toolChoice); err != nil {
+				return nil, fmt.Errorf("invalid tool_choice: %w", err)
+			}
+			params.ToolChoice = &toolChoice
+		default:
+			params.ExtraParams[key] = value
+		}
+	}
+
+	return params, nil
+}
+
+// floatParam returns a JSON number parameter as a float pointer, nil if it is not a number.
+func floatParam(value interface{}) *float64 {
+	if number, ok := value.(float64); ok {
+		return &number
+	}
+	return nil
+}
+
+// remarshal converts a decoded JSON value to a typed value.
+func remarshal(value interface{}, target interface{}) error {
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return sonic.Unmarshal(data, target)
+}
+
+// readBody reads and closes the body of a request.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// jsonResponse creates a 200 response with a JSON body.
+func jsonResponse(req *http.Request, body interface{}) (*http.Response, error) {
+	data, err := sonic.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(req, http.StatusOK, data), nil
+}
+
+// bifrostErrorResponse creates an OpenAI error response from a Bifrost error, with the status
+// code of the provider when known.
+func bifrostErrorResponse(req *http.Request, bifrostErr *schemas.BifrostError) *http.Response {
+	status := http.StatusInternalServerError
+	if bifrostErr.StatusCode != nil {
+		status = *bifrostErr.StatusCode
+	} else if bifrostErr.IsBifrostError {
+		status = http.StatusBadRequest
+	}
+	data, _ := sonic.Marshal(errorBody(bifrostErr.Error.Message, errorType(bifrostErr), bifrostErr.Error.Code))
+	return newResponse(req, status, data)
+}
+
+// errorResponse creates an OpenAI error response.
+func errorResponse(req *http.Request, status int, errType, message string) *http.Response {
+	data, _ := sonic.Marshal(errorBody(message, errType, nil))
+	return newResponse(req, status, data)
+}
+
+// errorBody creates the body of an OpenAI error.
+func errorBody(message, errType string, code *string) map[string]interface{} {
+	return map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": message,
+			"type":    errType,
+			"code":    code,
+			"param":   nil,
+		},
+	}
+}
+
+// errorType returns the OpenAI error type of a Bifrost error.
+func errorType(bifrostErr *schemas.BifrostError) string {
+	if bifrostErr.Error.Type != nil {
+		return *bifrostErr.Error.Type
+	}
+	if bifrostErr.Type != nil {
+		return *bifrostErr.Type
+	}
+	return "api_error"
+}
+
+// newResponse creates a response with a JSON body.
+func newResponse(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// drain discards the remaining chunks of a cancelled stream.
+func drain(stream chan *schemas.BifrostStream) {
+	for range stream {
+	}
+}
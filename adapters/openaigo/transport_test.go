@@ -0,0 +1,146 @@
+package openaigo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestConvertParameters(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want *schemas.ModelParameters
+	}{
+		{
+			name: "max_tokens",
+			body: `{"model":"gpt-4o","messages":[],"max_tokens":100}`,
+			want: &schemas.ModelParameters{MaxTokens: bifrost.Ptr(100), ExtraParams: map[string]interface{}{}},
+		},
+		{
+			name: "max_completion_tokens",
+			body: `{"model":"o3","messages":[],"max_completion_tokens":200}`,
+			want: &schemas.ModelParameters{ExtraParams: map[string]interface{}{"max_completion_tokens": 200}},
+		},
+		{
+			name: "sampling parameters and stop string",
+			body: `{"temperature":0.5,"top_p":0.9,"presence_penalty":0.1,"frequency_penalty":0.2,"stop":"END","user":"u1","parallel_tool_calls":false}`,
+			want: &schemas.ModelParameters{
+				Temperature:       bifrost.Ptr(0.5),
+				TopP:              bifrost.Ptr(0.9),
+				PresencePenalty:   bifrost.Ptr(0.1),
+				FrequencyPenalty:  bifrost.Ptr(0.2),
+				StopSequences:     &[]string{"END"},
+				User:              bifrost.Ptr("u1"),
+				ParallelToolCalls: bifrost.Ptr(false),
+				ExtraParams:       map[string]interface{}{},
+			},
+		},
+		{
+			name: "stop list and unknown parameters",
+			body: `{"stop":["a","b"],"reasoning_effort":"low","seed":null}`,
+			want: &schemas.ModelParameters{
+				StopSequences: &[]string{"a", "b"},
+				ExtraParams:   map[string]interface{}{"reasoning_effort": "low"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := map[string]interface{}{}
+			if err := sonic.Unmarshal([]byte(tt.body), &fields); err != nil {
+				t.Fatal(err)
+			}
+			got, err := convertParameters(fields)
+			if err != nil {
+				t.Fatalf("convertParameters() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertParameters() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// upstreamAccount serves OpenAI requests from a test server.
+type upstreamAccount struct {
+	baseURL string
+}
+
+func (a *upstreamAccount) GetConfiguredProviders() ([]schemas.ModelProvider, error) {
+	return []schemas.ModelProvider{schemas.OpenAI}, nil
+}
+
+func (a *upstreamAccount) GetKeysForProvider(ctx *context.Context, providerKey schemas.ModelProvider) ([]schemas.Key, error) {
+	return []schemas.Key{{Value: "test-key", Models: []string{}, Weight: 1.0}}, nil
+}
+
+func (a *upstreamAccount) GetConfigForProvider(providerKey schemas.ModelProvider) (*schemas.ProviderConfig, error) {
+	networkConfig := schemas.DefaultNetworkConfig
+	networkConfig.BaseURL = a.baseURL
+	return &schemas.ProviderConfig{
+		NetworkConfig:            networkConfig,
+		ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+	}, nil
+}
+
+func TestTransportTokenLimitRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		param     string
+		wantField string
+		dropField string
+	}{
+		{name: "max_tokens", param: `"max_tokens":64`, wantField: "max_tokens", dropField: "max_completion_tokens"},
+		{name: "max_completion_tokens", param: `"max_completion_tokens":64`, wantField: "max_completion_tokens", dropField: "max_tokens"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var upstreamBody map[string]interface{}
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				upstreamBody = map[string]interface{}{}
+				_ = sonic.Unmarshal(data, &upstreamBody)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"o3","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+			}))
+			defer upstream.Close()
+
+			client, err := bifrost.Init(context.Background(), schemas.BifrostConfig{
+				Account: &upstreamAccount{baseURL: upstream.URL},
+				Logger:  bifrost.NewDefaultLogger(schemas.LogLevelError),
+			})
+			if err != nil {
+				t.Fatalf("bifrost.Init() error = %v", err)
+			}
+			defer client.Shutdown()
+
+			body := `{"model":"o3","messages":[{"role":"user","content":"hello"}],` + tt.param + `}`
+			req := httptest.NewRequest(http.MethodPost, "https://api.openai.com/v1/chat/completions", strings.NewReader(body))
+			resp, err := NewTransport(client, Config{}).RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				data, _ := io.ReadAll(resp.Body)
+				t.Fatalf("RoundTrip() status = %d, body %s", resp.StatusCode, data)
+			}
+
+			if got, ok := upstreamBody[tt.wantField].(float64); !ok || got != 64 {
+				t.Errorf("upstream %s = %v, want 64", tt.wantField, upstreamBody[tt.wantField])
+			}
+			if _, exists := upstreamBody[tt.dropField]; exists {
+				t.Errorf("upstream body has %s = %v, want it unset", tt.dropField, upstreamBody[tt.dropField])
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package bifrost
+
+import (
+	"context"
+	"sync"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// AbortFinishReason is the finish reason set on the terminal chunk emitted when a stream is aborted.
+const AbortFinishReason = "aborted"
+
+// AbortableStream wraps a Bifrost stream with an explicit Abort handle.
+// Consumers read from Stream exactly as they would from a regular stream channel.
+// Calling Abort stops the upstream request, emits one terminal chunk carrying the abort
+// reason and the usage observed so far, and then closes Stream.
+type AbortableStream struct {
+	Stream chan *schemas.BifrostStream
+
+	cancel    context.CancelFunc
+	abortChan chan string
+	done      chan struct{}
+	abortOnce sync.Once
+}
+
+// Abort stops the stream with the given reason. It is safe to call multiple times and from
+// multiple goroutines; only the first call has any effect. Abort does not block on the consumer,
+// the terminal chunk is delivered on Stream and the channel is closed right after it.
+func (s *AbortableStream) Abort(reason string) {
+	s.abortOnce.Do(func() {
+		select {
+		case s.abortChan <- reason:
+		case <-s.done:
+			// Stream already finished on its own, nothing left to abort
+		}
+	})
+}
+
+// Done returns a channel that is closed once Stream has been closed.
+func (s *AbortableStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// ChatCompletionStreamRequestWithAbort sends a chat completion stream request and returns
+// an AbortableStream that can be stopped explicitly, e.g. from a "stop generating" button.
+func (bifrost *Bifrost) ChatCompletionStreamRequestWithAbort(ctx context.Context, req *schemas.BifrostRequest) (*AbortableStream, *schemas.BifrostError) {
+	return bifrost.handleAbortableStreamRequest(ctx, req, bifrost.ChatCompletionStreamRequest)
+}
+
+// SpeechStreamRequestWithAbort sends a speech stream request and returns an AbortableStream.
+func (bifrost *Bifrost) SpeechStreamRequestWithAbort(ctx context.Context, req *schemas.BifrostRequest) (*AbortableStream, *schemas.BifrostError) {
+	return bifrost.handleAbortableStreamRequest(ctx, req, bifrost.SpeechStreamRequest)
+}
+
+// TranscriptionStreamRequestWithAbort sends a transcription stream request and returns an AbortableStream.
+func (bifrost *Bifrost) TranscriptionStreamRequestWithAbort(ctx context.Context, req *schemas.BifrostRequest) (*AbortableStream, *schemas.BifrostError) {
+	return bifrost.handleAbortableStreamRequest(ctx, req, bifrost.TranscriptionStreamRequest)
+}
+
+// handleAbortableStreamRequest starts the stream on a cancellable context and forwards its chunks
+// to the returned AbortableStream until the upstream finishes or Abort is called.
+func (bifrost *Bifrost) handleAbortableStreamRequest(
+	ctx context.Context,
+	req *schemas.BifrostRequest,
+	start func(context.Context, *schemas.BifrostRequest) (chan *schemas.BifrostStream, *schemas.BifrostError),
+) (*AbortableStream, *schemas.BifrostError) {
+	if ctx == nil {
+		ctx = bifrost.ctx
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+
+	upstream, bifrostErr := start(streamCtx, req)
+	if bifrostErr != nil {
+		cancel()
+		return nil, bifrostErr
+	}
+
+	stream := &AbortableStream{
+		Stream:    make(chan *schemas.BifrostStream),
+		cancel:    cancel,
+		abortChan: make(chan string),
+		done:      make(chan struct{}),
+	}
+
+	go stream.forward(ctx, req, upstream)
+
+	return stream, nil
+}
+
+// forward relays upstream chunks to the consumer, tracking usage so that an abort
+// can report what has been generated until that point.
+func (s *AbortableStream) forward(ctx context.Context, req *schemas.BifrostRequest, upstream chan *schemas.BifrostStream) {
+	defer close(s.done)
+	defer close(s.Stream)
+	defer s.cancel()
+
+	var (
+		lastResponse  *schemas.BifrostResponse
+		usage         *schemas.LLMUsage
+		contentChunks int
+	)
+
+	abort := func(reason string) {
+		// Cancel the upstream request and drain whatever is still in flight so that
+		// the provider goroutine is never left blocked on a send.
+		s.cancel()
+		go func() {
+			for range upstream {
+			}
+		}()
+
+		terminal := newAbortChunk(req, lastResponse, usage, contentChunks, reason)
+
+		select {
+		case s.Stream <- &schemas.BifrostStream{BifrostResponse: terminal}:
+		case <-ctx.Done():
+		}
+	}
+
+	for {
+		select {
+		case reason := <-s.abortChan:
+			abort(reason)
+			return
+		case chunk, ok := <-upstream:
+			if !ok {
+				return
+			}
+			if chunk == nil {
+				continue
+			}
+
+			if chunk.BifrostResponse != nil {
+				lastResponse = chunk.BifrostResponse
+				if chunk.BifrostResponse.Usage != nil {
+					usage = chunk.BifrostResponse.Usage
+				}
+				for _, choice := range chunk.BifrostResponse.Choices {
+					if choice.BifrostStreamResponseChoice != nil && choice.Delta.Content != nil && *choice.Delta.Content != "" {
+						contentChunks++
+					}
+				}
+			}
+
+			select {
+			case s.Stream <- chunk:
+			case reason := <-s.abortChan:
+				abort(reason)
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// newAbortChunk builds the terminal chunk sent to consumers after an abort.
+// If the provider did not report usage before the abort, completion tokens are
+// approximated by the number of content deltas received, and the chunk carries
+// an estimated warning.
+func newAbortChunk(req *schemas.BifrostRequest, lastResponse *schemas.BifrostResponse, usage *schemas.LLMUsage, contentChunks int, reason string) *schemas.BifrostResponse {
+	var warnings []schemas.Warning
+	if usage == nil {
+		usage = &schemas.LLMUsage{
+			CompletionTokens: contentChunks,
+			TotalTokens:      contentChunks,
+		}
+		warnings = append(warnings, schemas.Warning{
+			Code:    schemas.WarningEstimated,
+			Message: "the provider reported no usage before the abort, completion tokens are approximated by the content chunks received",
+			Origin:  schemas.WarningOriginBifrost,
+		})
+	}
+
+	response := &schemas.BifrostResponse{
+		Model: req.Model,
+		Usage: usage,
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider:    req.Provider,
+			AbortReason: &reason,
+			Warnings:    warnings,
+		},
+	}
+	if req.Params != nil {
+		response.ExtraFields.Params = *req.Params
+	}
+
+	if lastResponse != nil {
+		response.ID = lastResponse.ID
+		response.Object = lastResponse.Object
+		response.ExtraFields.ChunkIndex = lastResponse.ExtraFields.ChunkIndex + 1
+		if lastResponse.Model != "" {
+			response.Model = lastResponse.Model
+		}
+	}
+
+	if req.Input.ChatCompletionInput != nil {
+		response.Choices = []schemas.BifrostResponseChoice{
+			{
+				FinishReason: Ptr(AbortFinishReason),
+				BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+					Delta: schemas.BifrostStreamDelta{},
+				},
+			},
+		}
+	}
+
+	return response
+}
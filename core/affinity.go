@@ -0,0 +1,258 @@
+package bifrost
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// SESSION AFFINITY
+// ============================================================================
+
+// affinityContextKey is the context key of the affinityKeySelection of a request.
+type affinityContextKey struct{}
+
+// sessionAffinity is the provider, model and key that last served a session.
+type sessionAffinity struct {
+	sessionID string
+	provider  schemas.ModelProvider
+	model     string
+	keyID     string
+	lastUsed  time.Time
+}
+
+// affinityStore remembers the affinity of recently active sessions, forgetting sessions
+// that were idle for longer than the TTL or least recently used beyond maxSessions.
+type affinityStore struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	maxSessions int
+	sessions    map[string]*list.Element // Elements hold a *sessionAffinity
+	order       *list.List               // Most recently used first
+}
+
+// affinityKeySelection is shared between a request and the workers serving its attempts, so
+// key selection can prefer the pinned key and report the key it picked.
+type affinityKeySelection struct {
+	mu                sync.Mutex
+	preferredProvider schemas.ModelProvider
+	preferredKeyID    string
+	selectedProvider  schemas.ModelProvider
+	selectedKeyID     string
+}
+
+// newAffinityStore creates an affinity store, applying defaults to unset config values.
+func newAffinityStore(config *schemas.SessionAffinityConfig) *affinityStore {
+	store := &affinityStore{
+		ttl:         schemas.DefaultSessionAffinityTTL,
+		maxSessions: schemas.DefaultSessionAffinityMaxSessions,
+		sessions:    make(map[string]*list.Element),
+		order:       list.New(),
+	}
+	if config != nil {
+		if config.TTL > 0 {
+			store.ttl = config.TTL
+		}
+		if config.MaxSessions > 0 {
+			store.maxSessions = config.MaxSessions
+		}
+	}
+	return store
+}
+
+// get returns the affinity of a session, if it has one that has not expired.
+func (s *affinityStore) get(sessionID string) (sessionAffinity, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.sessions[sessionID]
+	if !ok {
+		return sessionAffinity{}, false
+	}
+	affinity := element.Value.(*sessionAffinity)
+	if time.Since(affinity.lastUsed) > s.ttl {
+		s.order.Remove(element)
+		delete(s.sessions, sessionID)
+		return sessionAffinity{}, false
+	}
+	return *affinity, true
+}
+
+// set stores the affinity of a session and evicts sessions beyond maxSessions.
+func (s *affinityStore) set(affinity sessionAffinity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	affinity.lastUsed = time.Now()
+	if element, ok := s.sessions[affinity.sessionID]; ok {
+		element.Value = &affinity
+		s.order.MoveToFront(element)
+		return
+	}
+	s.sessions[affinity.sessionID] = s.order.PushFront(&affinity)
+
+	for s.order.Len() > s.maxSessions {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.sessions, oldest.Value.(*sessionAffinity).sessionID)
+	}
+}
+
+// affinityRequest tracks session affinity through the attempts of a single request.
+// A nil affinityRequest is valid and does nothing.
+type affinityRequest struct {
+	store     *affinityStore
+	sessionID string
+	previous  *sessionAffinity // Affinity before this request, nil for a new session
+	pinned    bool             // Whether the previous provider and model are attempted first
+	selection *affinityKeySelection
+}
+
+// applySessionAffinity pins a request of a session to the provider, model and key that served
+// the session before, so the provider-side prompt cache of earlier turns can be reused. When the
+// pinned target is one of the request's fallbacks it is moved to the front of the chain and the
+// original primary becomes the first fallback. It returns the possibly reordered request and the
+// context carrying the key preference.
+func (bifrost *Bifrost) applySessionAffinity(ctx context.Context, req *schemas.BifrostRequest) (context.Context, *schemas.BifrostRequest, *affinityRequest) {
+	sessionID, ok := ctx.Value(schemas.BifrostContextKeySessionID).(string)
+	if !ok || sessionID == "" {
+		return ctx, req, nil
+	}
+
+	affinity := &affinityRequest{
+		store:     bifrost.affinityStore,
+		sessionID: sessionID,
+		selection: &affinityKeySelection{},
+	}
+
+	if previous, ok := bifrost.affinityStore.get(sessionID); ok {
+		affinity.previous = &previous
+		isPinned := func(fallback schemas.Fallback) bool {
+			return fallback.Provider == previous.provider && fallback.Model == previous.model
+		}
+
+		if req.Provider == previous.provider && req.Model == previous.model {
+			affinity.pinned = true
+		} else if i := slices.IndexFunc(req.Fallbacks, isPinned); i >= 0 {
+			pinnedReq := *req
+			pinnedReq.Provider = previous.provider
+			pinnedReq.Model = previous.model
+			pinnedReq.Fallbacks = append([]schemas.Fallback{{Provider: req.Provider, Model: req.Model}}, slices.Delete(slices.Clone(req.Fallbacks), i, i+1)...)
+			req = &pinnedReq
+			affinity.pinned = true
+		}
+
+		if affinity.pinned {
+			affinity.selection.preferredProvider = previous.provider
+			affinity.selection.preferredKeyID = previous.keyID
+		}
+	}
+
+	return context.WithValue(ctx, affinityContextKey{}, affinity.selection), req, affinity
+}
+
+// served records that provider and model served the request and returns the cache reset
+// marker for the response, nil when the session kept its provider, model and key.
+func (a *affinityRequest) served(provider schemas.ModelProvider, model string) *schemas.CacheReset {
+	if a == nil {
+		return nil
+	}
+
+	keyID := a.selection.selected(provider)
+	a.store.set(sessionAffinity{
+		sessionID: a.sessionID,
+		provider:  provider,
+		model:     model,
+		keyID:     keyID,
+	})
+
+	previous := a.previous
+	if previous == nil {
+		return nil
+	}
+
+	var reason string
+	switch {
+	case previous.provider != provider || previous.model != model:
+		reason = schemas.CacheResetReasonRequestChanged
+		if a.pinned {
+			reason = schemas.CacheResetReasonFailover
+		}
+	case previous.keyID != "" && previous.keyID != keyID:
+		reason = schemas.CacheResetReasonKeyChanged
+	default:
+		return nil
+	}
+
+	return &schemas.CacheReset{
+		SessionID:        a.sessionID,
+		Reason:           reason,
+		PreviousProvider: previous.provider,
+		PreviousModel:    previous.model,
+		PreviousKeyID:    previous.keyID,
+	}
+}
+
+// getAffinityKeySelection returns the key selection of a request with session affinity, if any.
+func getAffinityKeySelection(ctx *context.Context) *affinityKeySelection {
+	if ctx == nil || *ctx == nil {
+		return nil
+	}
+	selection, _ := (*ctx).Value(affinityContextKey{}).(*affinityKeySelection)
+	return selection
+}
+
+// preferred returns the ID of the key to prefer for a provider, empty if there is none.
+func (s *affinityKeySelection) preferred(provider schemas.ModelProvider) string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.preferredProvider != provider {
+		return ""
+	}
+	return s.preferredKeyID
+}
+
+// record records the key picked for a provider.
+func (s *affinityKeySelection) record(provider schemas.ModelProvider, keyID string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.selectedProvider = provider
+	s.selectedKeyID = keyID
+}
+
+// selected returns the ID of the key last picked for a provider, empty if none was.
+func (s *affinityKeySelection) selected(provider schemas.ModelProvider) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.selectedProvider != provider {
+		return ""
+	}
+	return s.selectedKeyID
+}
+
+// streamWithCacheReset sets the cache reset marker on the first response chunk of a stream.
+func streamWithCacheReset(stream chan *schemas.BifrostStream, cacheReset *schemas.CacheReset) chan *schemas.BifrostStream {
+	outputStream := make(chan *schemas.BifrostStream, cap(stream))
+	go func() {
+		defer close(outputStream)
+		for chunk := range stream {
+			if cacheReset != nil && chunk != nil && chunk.BifrostResponse != nil {
+				chunk.BifrostResponse.ExtraFields.CacheReset = cacheReset
+				cacheReset = nil
+			}
+			outputStream <- chunk
+		}
+	}()
+	return outputStream
+}
@@ -0,0 +1,470 @@
+package bifrost
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// AGENT STEP TRACING
+// ============================================================================
+
+// agentTraceMaxContent is the length beyond which tool results are truncated in spans.
+const agentTraceMaxContent = 1024
+
+// agentTrace holds the spans of a trace, in the order they started.
+type agentTrace struct {
+	id        string
+	spans     []*schemas.AgentSpan
+	dropped   int
+	toolCalls map[string]string // Tool call ID -> ID of the decision span that requested it
+	lastUsed  time.Time
+}
+
+// agentTracer records the steps of agent loops, forgetting traces that were idle for longer
+// than the TTL or least recently used beyond maxTraces.
+type agentTracer struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	maxTraces int
+	maxSpans  int
+	handler   schemas.AgentSpanHandler
+	traces    map[string]*list.Element // Elements hold an *agentTrace
+	order     *list.List               // Most recently used first
+}
+
+// newAgentTracer creates the agent tracer, nil if config is nil.
+func newAgentTracer(config *schemas.AgentTracingConfig) *agentTracer {
+	if config == nil {
+		return nil
+	}
+	tracer := &agentTracer{
+		ttl:       schemas.DefaultAgentTraceTTL,
+		maxTraces: schemas.DefaultAgentTraceMaxTraces,
+		maxSpans:  schemas.DefaultAgentTraceMaxSpans,
+		handler:   config.SpanHandler,
+		traces:    make(map[string]*list.Element),
+		order:     list.New(),
+	}
+	if config.TTL > 0 {
+		tracer.ttl = config.TTL
+	}
+	if config.MaxTraces > 0 {
+		tracer.maxTraces = config.MaxTraces
+	}
+	if config.MaxSpans > 0 {
+		tracer.maxSpans = config.MaxSpans
+	}
+	return tracer
+}
+
+// newTraceID returns a random trace ID, in the 16 byte hex format of OpenTelemetry.
+func newTraceID() string {
+	return randomHex(16)
+}
+
+// newSpanID returns a random span ID, in the 8 byte hex format of OpenTelemetry.
+func newSpanID() string {
+	return randomHex(8)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// trace returns a trace, creating it if it is new or expired. The caller must hold the lock.
+func (t *agentTracer) trace(traceID string) *agentTrace {
+	if element, ok := t.traces[traceID]; ok {
+		trace := element.Value.(*agentTrace)
+		if time.Since(trace.lastUsed) <= t.ttl {
+			trace.lastUsed = time.Now()
+			t.order.MoveToFront(element)
+			return trace
+		}
+		t.order.Remove(element)
+		delete(t.traces, traceID)
+	}
+
+	trace := &agentTrace{id: traceID, toolCalls: make(map[string]string), lastUsed: time.Now()}
+	t.traces[traceID] = t.order.PushFront(trace)
+	for t.order.Len() > t.maxTraces {
+		oldest := t.order.Back()
+		t.order.Remove(oldest)
+		delete(t.traces, oldest.Value.(*agentTrace).id)
+	}
+	return trace
+}
+
+// agentSpan is a span that has started. A nil agentSpan is valid and does nothing.
+type agentSpan struct {
+	tracer *agentTracer
+	span   *schemas.AgentSpan
+}
+
+// start records a new span of a trace. Spans beyond maxSpans are not kept in the trace but
+// still reach the span handler.
+func (t *agentTracer) start(traceID, parentSpanID string, kind schemas.AgentStepKind, name string, attributes map[string]interface{}) *agentSpan {
+	if attributes == nil {
+		attributes = make(map[string]interface{})
+	}
+	span := &schemas.AgentSpan{
+		TraceID:      traceID,
+		SpanID:       newSpanID(),
+		ParentSpanID: parentSpanID,
+		Kind:         kind,
+		Name:         name,
+		StartTime:    time.Now(),
+		Attributes:   attributes,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	trace := t.trace(traceID)
+	if len(trace.spans) < t.maxSpans {
+		trace.spans = append(trace.spans, span)
+	} else {
+		trace.dropped++
+	}
+	return &agentSpan{tracer: t, span: span}
+}
+
+// end ends a span, failed if errMessage is set, and hands it to the span handler.
+func (s *agentSpan) end(errMessage string, attributes map[string]interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.tracer.mu.Lock()
+	s.span.EndTime = time.Now()
+	s.span.Status = schemas.AgentSpanStatusOK
+	if errMessage != "" {
+		s.span.Status = schemas.AgentSpanStatusError
+		s.span.Error = errMessage
+	}
+	for key, value := range attributes {
+		s.span.Attributes[key] = value
+	}
+	span := copyAgentSpan(s.span)
+	s.tracer.mu.Unlock()
+
+	if s.tracer.handler != nil {
+		s.tracer.handler(span)
+	}
+}
+
+// copyAgentSpan returns a copy of a span that shares nothing with it but attribute values.
+func copyAgentSpan(span *schemas.AgentSpan) schemas.AgentSpan {
+	result := *span
+	result.Attributes = make(map[string]interface{}, len(span.Attributes))
+	for key, value := range span.Attributes {
+		result.Attributes[key] = value
+	}
+	result.Children = nil
+	return result
+}
+
+// GetAgentTrace returns the steps of a trace as a tree, false if agent tracing is disabled or
+// the trace is unknown.
+func (bifrost *Bifrost) GetAgentTrace(traceID string) (*schemas.AgentTrace, bool) {
+	tracer := bifrost.agentTracer
+	if tracer == nil {
+		return nil, false
+	}
+
+	tracer.mu.Lock()
+	element, ok := tracer.traces[traceID]
+	if !ok {
+		tracer.mu.Unlock()
+		return nil, false
+	}
+	trace := element.Value.(*agentTrace)
+	spans := make([]*schemas.AgentSpan, len(trace.spans))
+	for i, span := range trace.spans {
+		spanCopy := copyAgentSpan(span)
+		spans[i] = &spanCopy
+	}
+	result := &schemas.AgentTrace{TraceID: traceID, DroppedSpans: trace.dropped, Spans: []*schemas.AgentSpan{}}
+	tracer.mu.Unlock()
+
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].StartTime.Before(spans[j].StartTime) })
+	byID := make(map[string]*schemas.AgentSpan, len(spans))
+	for _, span := range spans {
+		byID[span.SpanID] = span
+	}
+	for _, span := range spans {
+		if parent, ok := byID[span.ParentSpanID]; ok {
+			parent.Children = append(parent.Children, span)
+		} else {
+			result.Spans = append(result.Spans, span)
+		}
+		if result.StartTime.IsZero() || span.StartTime.Before(result.StartTime) {
+			result.StartTime = span.StartTime
+		}
+		if span.EndTime.After(result.EndTime) {
+			result.EndTime = span.EndTime
+		}
+	}
+	return result, true
+}
+
+// agentModelCall traces a model call. A nil agentModelCall is valid and does nothing.
+type agentModelCall struct {
+	tracer  *agentTracer
+	traceID string
+	span    *agentSpan
+}
+
+// startAgentModelCall starts the model call span of a chat or text completion request. Requests
+// without a trace ID start a new trace, whose ID is set on the returned context.
+func (bifrost *Bifrost) startAgentModelCall(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType) (context.Context, *agentModelCall) {
+	tracer := bifrost.agentTracer
+	if tracer == nil {
+		return ctx, nil
+	}
+	switch requestType {
+	case schemas.TextCompletionRequest, schemas.ChatCompletionRequest, schemas.ChatCompletionStreamRequest:
+	default:
+		return ctx, nil
+	}
+
+	traceID, _ := ctx.Value(schemas.BifrostContextKeyTraceID).(string)
+	if traceID == "" {
+		traceID = newTraceID()
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyTraceID, traceID)
+	}
+
+	attributes := map[string]interface{}{
+		"provider":     string(req.Provider),
+		"model":        req.Model,
+		"request_type": string(requestType),
+	}
+	if req.Input.ChatCompletionInput != nil {
+		attributes["messages"] = len(*req.Input.ChatCompletionInput)
+	}
+	if req.Params != nil && req.Params.Tools != nil {
+		attributes["tools"] = len(*req.Params.Tools)
+	}
+	if len(req.Fallbacks) > 0 {
+		attributes["fallbacks"] = len(req.Fallbacks)
+	}
+
+	return ctx, &agentModelCall{
+		tracer:  tracer,
+		traceID: traceID,
+		span:    tracer.start(traceID, "", schemas.AgentStepModelCall, fmt.Sprintf("%s %s/%s", requestType, req.Provider, req.Model), attributes),
+	}
+}
+
+// agentToolCallRef is a tool call requested by a model.
+type agentToolCallRef struct {
+	id   string
+	name string
+}
+
+// finish ends the model call with its outcome and records the model's decision.
+func (c *agentModelCall) finish(result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) {
+	if c == nil {
+		return
+	}
+	if bifrostErr != nil {
+		attributes := map[string]interface{}{}
+		if bifrostErr.StatusCode != nil {
+			attributes["status_code"] = *bifrostErr.StatusCode
+		}
+		if bifrostErr.Error.Type != nil {
+			attributes["error_type"] = *bifrostErr.Error.Type
+		}
+		c.span.end(bifrostErr.Error.Message, attributes)
+		return
+	}
+	if result == nil {
+		c.span.end("", nil)
+		return
+	}
+
+	result.ExtraFields.TraceID = c.traceID
+	var toolCalls []agentToolCallRef
+	var finishReason string
+	for _, choice := range result.Choices {
+		if choice.FinishReason != nil && finishReason == "" {
+			finishReason = *choice.FinishReason
+		}
+		if choice.BifrostNonStreamResponseChoice == nil || choice.Message.AssistantMessage == nil || choice.Message.AssistantMessage.ToolCalls == nil {
+			continue
+		}
+		for _, toolCall := range *choice.Message.AssistantMessage.ToolCalls {
+			toolCalls = append(toolCalls, newAgentToolCallRef(toolCall))
+		}
+	}
+	c.end(result.ExtraFields.Provider, result.Usage, finishReason, toolCalls, "")
+}
+
+// end ends the model call span and records a decision span for what the model did.
+func (c *agentModelCall) end(servedBy schemas.ModelProvider, usage *schemas.LLMUsage, finishReason string, toolCalls []agentToolCallRef, errMessage string) {
+	attributes := map[string]interface{}{}
+	if servedBy != "" {
+		attributes["served_by"] = string(servedBy)
+	}
+	if usage != nil {
+		attributes["usage.prompt_tokens"] = usage.PromptTokens
+		attributes["usage.completion_tokens"] = usage.CompletionTokens
+		attributes["usage.total_tokens"] = usage.TotalTokens
+	}
+	if finishReason != "" {
+		attributes["finish_reason"] = finishReason
+	}
+
+	if errMessage == "" {
+		decision := schemas.AgentDecisionAnswer
+		decisionAttributes := map[string]interface{}{}
+		if finishReason != "" {
+			decisionAttributes["finish_reason"] = finishReason
+		}
+		if len(toolCalls) > 0 {
+			decision = schemas.AgentDecisionToolCalls
+			names := make([]string, len(toolCalls))
+			for i, toolCall := range toolCalls {
+				names[i] = toolCall.name
+			}
+			decisionAttributes["tool_calls"] = names
+		}
+		decisionSpan := c.tracer.start(c.traceID, c.span.span.SpanID, schemas.AgentStepDecision, decision, decisionAttributes)
+
+		c.tracer.mu.Lock()
+		trace := c.tracer.trace(c.traceID)
+		for _, toolCall := range toolCalls {
+			if toolCall.id != "" {
+				trace.toolCalls[toolCall.id] = decisionSpan.span.SpanID
+			}
+		}
+		c.tracer.mu.Unlock()
+		decisionSpan.end("", nil)
+	}
+
+	c.span.end(errMessage, attributes)
+}
+
+func newAgentToolCallRef(toolCall schemas.ToolCall) agentToolCallRef {
+	var ref agentToolCallRef
+	if toolCall.ID != nil {
+		ref.id = *toolCall.ID
+	}
+	if toolCall.Function.Name != nil {
+		ref.name = *toolCall.Function.Name
+	}
+	return ref
+}
+
+// streamWithAgentTrace sets the trace ID on the first chunk of a stream and ends the model call
+// span with the outcome of the stream once it closes.
+func streamWithAgentTrace(stream chan *schemas.BifrostStream, call *agentModelCall) chan *schemas.BifrostStream {
+	outputStream := make(chan *schemas.BifrostStream, cap(stream))
+	go func() {
+		defer close(outputStream)
+
+		first := true
+		var servedBy schemas.ModelProvider
+		var usage *schemas.LLMUsage
+		var finishReason, errMessage string
+		var toolCalls []agentToolCallRef
+		for chunk := range stream {
+			if chunk != nil && chunk.BifrostError != nil {
+				errMessage = chunk.BifrostError.Error.Message
+			}
+			if chunk != nil && chunk.BifrostResponse != nil {
+				response := chunk.BifrostResponse
+				if first {
+					response.ExtraFields.TraceID = call.traceID
+					first = false
+				}
+				servedBy = response.ExtraFields.Provider
+				if response.Usage != nil {
+					usage = response.Usage
+				}
+				for _, choice := range response.Choices {
+					if choice.FinishReason != nil && finishReason == "" {
+						finishReason = *choice.FinishReason
+					}
+					if choice.BifrostStreamResponseChoice == nil {
+						continue
+					}
+					for _, toolCall := range choice.Delta.ToolCalls {
+						// Only the first delta of a tool call carries its ID and name
+						if toolCall.ID != nil {
+							toolCalls = append(toolCalls, newAgentToolCallRef(toolCall))
+						}
+					}
+				}
+			}
+			outputStream <- chunk
+		}
+		call.end(servedBy, usage, finishReason, toolCalls, errMessage)
+	}()
+	return outputStream
+}
+
+// startAgentToolCall starts the span of an MCP tool execution, a child of the decision that
+// requested the tool call. Tool calls are only traced when the context carries a trace ID.
+func (bifrost *Bifrost) startAgentToolCall(ctx context.Context, toolCall schemas.ToolCall) *agentSpan {
+	tracer := bifrost.agentTracer
+	if tracer == nil || ctx == nil {
+		return nil
+	}
+	traceID, _ := ctx.Value(schemas.BifrostContextKeyTraceID).(string)
+	if traceID == "" {
+		return nil
+	}
+
+	ref := newAgentToolCallRef(toolCall)
+	tracer.mu.Lock()
+	parentSpanID := tracer.trace(traceID).toolCalls[ref.id]
+	tracer.mu.Unlock()
+
+	attributes := map[string]interface{}{
+		"tool_name": ref.name,
+		"arguments": toolCall.Function.Arguments,
+	}
+	if ref.id != "" {
+		attributes["tool_call_id"] = ref.id
+	}
+	return tracer.start(traceID, parentSpanID, schemas.AgentStepToolCall, ref.name, attributes)
+}
+
+// finishToolCall records the result of a tool execution and ends its span.
+func (s *agentSpan) finishToolCall(result *schemas.BifrostMessage, err error) {
+	if s == nil {
+		return
+	}
+
+	resultAttributes := map[string]interface{}{}
+	errMessage := ""
+	if err != nil {
+		errMessage = err.Error()
+	} else if result != nil && result.Content.ContentStr != nil {
+		content := *result.Content.ContentStr
+		resultAttributes["content_length"] = len(content)
+		if len(content) > agentTraceMaxContent {
+			cut := agentTraceMaxContent
+			for cut > 0 && !utf8.RuneStart(content[cut]) {
+				cut--
+			}
+			content = content[:cut] + "..."
+		}
+		resultAttributes["content"] = content
+	}
+
+	resultSpan := s.tracer.start(s.span.TraceID, s.span.SpanID, schemas.AgentStepToolResult, s.span.Name, resultAttributes)
+	resultSpan.end(errMessage, nil)
+	s.end(errMessage, nil)
+}
@@ -0,0 +1,83 @@
+// Package audiosplit splits long audio into chunks that fit the upload limits of transcription
+// APIs. Consecutive chunks overlap, so a word cut at a boundary is heard whole in one of them.
+// WAV (PCM) audio is cut at the quietest point before each limit, MP3 audio at frame boundaries.
+package audiosplit
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+// Supported formats, as returned by Detect.
+const (
+	FormatWAV = "wav"
+	FormatMP3 = "mp3"
+)
+
+// Defaults used when the matching Config field is not set.
+const (
+	DefaultSilenceWindow = 3 * time.Second
+	silenceBlock         = 20 * time.Millisecond // Length of the blocks compared when looking for silence
+)
+
+// ErrUnsupportedFormat is returned by Split for audio that is neither WAV (PCM) nor MP3 (Layer III).
+var ErrUnsupportedFormat = errors.New("audiosplit: unsupported audio format")
+
+// Config bounds the chunks returned by Split.
+type Config struct {
+	MaxBytes      int           // Maximum size of a chunk in bytes, headers included. Required
+	MaxDuration   time.Duration // Maximum duration of a chunk, no limit if 0
+	Overlap       time.Duration // Audio repeated at the start of the next chunk
+	SilenceWindow time.Duration // How far before each limit a cut looks for silence (WAV only), DefaultSilenceWindow if 0
+}
+
+// Chunk is a playable piece of the original audio.
+type Chunk struct {
+	Data  []byte
+	Start time.Duration // Offset of the chunk in the original audio
+	End   time.Duration
+}
+
+// Detect returns the format of the audio, or an empty string if it is not supported.
+func Detect(data []byte) string {
+	switch {
+	case len(data) >= 12 && bytes.Equal(data[0:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WAVE")):
+		return FormatWAV
+	case len(data) >= 3 && bytes.Equal(data[0:3], []byte("ID3")):
+		return FormatMP3
+	case len(data) >= 4 && isMP3FrameHeader(data):
+		return FormatMP3
+	default:
+		return ""
+	}
+}
+
+// Split cuts the audio into chunks within the limits of cfg. Audio within the limits is returned
+// as a single chunk holding the original data.
+func Split(data []byte, cfg Config) ([]Chunk, error) {
+	if cfg.MaxBytes <= 0 {
+		return nil, errors.New("audiosplit: max bytes must be positive")
+	}
+	if cfg.SilenceWindow <= 0 {
+		cfg.SilenceWindow = DefaultSilenceWindow
+	}
+
+	switch Detect(data) {
+	case FormatWAV:
+		return splitWAV(data, cfg)
+	case FormatMP3:
+		return splitMP3(data, cfg)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// nextStart returns where the chunk after [start, end) begins: overlap before its end, but always
+// after its start so splitting makes progress.
+func nextStart(start, end, overlap int) int {
+	if next := end - overlap; next > start {
+		return next
+	}
+	return end
+}
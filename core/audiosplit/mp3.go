@@ -0,0 +1,162 @@
+package audiosplit
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Bitrates of MPEG audio Layer III in kbit/s, by bitrate index.
+var (
+	mpeg1Bitrates = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mpeg2Bitrates = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+)
+
+// Sample rates in Hz by MPEG version bits and sample rate index.
+var mpegSampleRates = map[byte][3]int{
+	3: {44100, 48000, 32000}, // MPEG 1
+	2: {22050, 24000, 16000}, // MPEG 2
+	0: {11025, 12000, 8000},  // MPEG 2.5
+}
+
+// mp3Frame is a frame of an MP3 stream.
+type mp3Frame struct {
+	offset   int
+	size     int
+	duration time.Duration
+}
+
+// isMP3FrameHeader reports whether data starts with a valid MPEG audio Layer III frame header.
+func isMP3FrameHeader(data []byte) bool {
+	_, ok := parseMP3FrameHeader(data)
+	return ok
+}
+
+// parseMP3FrameHeader decodes the frame header at the start of data.
+func parseMP3FrameHeader(data []byte) (mp3Frame, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	version := (data[1] >> 3) & 0x03
+	layer := (data[1] >> 1) & 0x03
+	bitrateIndex := data[2] >> 4
+	sampleRateIndex := (data[2] >> 2) & 0x03
+	padding := int((data[2] >> 1) & 0x01)
+
+	sampleRates, ok := mpegSampleRates[version]
+	if !ok || layer != 1 || sampleRateIndex == 3 {
+		return mp3Frame{}, false
+	}
+	sampleRate := sampleRates[sampleRateIndex]
+
+	bitrate, samples, coefficient := mpeg2Bitrates[bitrateIndex], 576, 72
+	if version == 3 {
+		bitrate, samples, coefficient = mpeg1Bitrates[bitrateIndex], 1152, 144
+	}
+	if bitrate == 0 {
+		return mp3Frame{}, false
+	}
+
+	return mp3Frame{
+		size:     coefficient*bitrate*1000/sampleRate + padding,
+		duration: time.Duration(samples) * time.Second / time.Duration(sampleRate),
+	}, true
+}
+
+// parseMP3Frames returns the audio frames of an MP3 file, skipping ID3 tags and the Xing/Info
+// frame, whose totals would be wrong for every chunk.
+func parseMP3Frames(data []byte) ([]mp3Frame, error) {
+	offset := 0
+	if len(data) >= 10 && bytes.Equal(data[0:3], []byte("ID3")) {
+		// ID3v2 sizes are syncsafe integers, 7 bits per byte
+		size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+		offset = 10 + size
+		if data[5]&0x10 != 0 {
+			offset += 10 // Footer
+		}
+	}
+
+	var frames []mp3Frame
+	for offset < len(data) {
+		frame, ok := parseMP3FrameHeader(data[offset:])
+		if !ok || offset+frame.size > len(data) {
+			// Trailing tags or garbage, the audio ends here
+			break
+		}
+		frame.offset = offset
+		if len(frames) == 0 && isXingFrame(data[offset:offset+frame.size]) {
+			offset += frame.size
+			continue
+		}
+		frames = append(frames, frame)
+		offset += frame.size
+	}
+
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("audiosplit: no mp3 frames found")
+	}
+	return frames, nil
+}
+
+// isXingFrame reports whether the frame carries a Xing or Info header instead of audio.
+func isXingFrame(frame []byte) bool {
+	head := frame[:min(len(frame), 64)]
+	return bytes.Contains(head, []byte("Xing")) || bytes.Contains(head, []byte("Info"))
+}
+
+// splitMP3 cuts an MP3 file at frame boundaries. MP3 frames may borrow bits from the frames before
+// them, so the first few milliseconds of a chunk can decode as silence; the overlap covers them.
+func splitMP3(data []byte, cfg Config) ([]Chunk, error) {
+	frames, err := parseMP3Frames(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var total time.Duration
+	for _, frame := range frames {
+		total += frame.duration
+	}
+	if len(data) <= cfg.MaxBytes && (cfg.MaxDuration <= 0 || total <= cfg.MaxDuration) {
+		return []Chunk{{Data: data, End: total}}, nil
+	}
+
+	// starts[i] is the offset in time of frame i
+	starts := make([]time.Duration, len(frames)+1)
+	for i, frame := range frames {
+		starts[i+1] = starts[i] + frame.duration
+	}
+
+	var chunks []Chunk
+	for start := 0; start < len(frames); {
+		end, size := start, 0
+		for end < len(frames) {
+			if size+frames[end].size > cfg.MaxBytes {
+				break
+			}
+			if cfg.MaxDuration > 0 && starts[end+1]-starts[start] > cfg.MaxDuration {
+				break
+			}
+			size += frames[end].size
+			end++
+		}
+		if end == start {
+			return nil, fmt.Errorf("audiosplit: mp3 frame of %d bytes exceeds the chunk limit", frames[start].size)
+		}
+
+		chunkData := make([]byte, 0, size)
+		for _, frame := range frames[start:end] {
+			chunkData = append(chunkData, data[frame.offset:frame.offset+frame.size]...)
+		}
+		chunks = append(chunks, Chunk{Data: chunkData, Start: starts[start], End: starts[end]})
+		if end == len(frames) {
+			break
+		}
+
+		overlapFrames := 0
+		for overlapFrames < end-start && starts[end]-starts[end-overlapFrames] < cfg.Overlap {
+			overlapFrames++
+		}
+		start = nextStart(start, end, overlapFrames)
+	}
+	return chunks, nil
+}
@@ -0,0 +1,171 @@
+package audiosplit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// wavHeaderSize is the size of the header written before the samples of each chunk, without the fmt body.
+const wavHeaderSize = 12 + 8 + 8
+
+// wavFormats are the WAVE format tags that hold uncompressed samples.
+var wavFormats = map[uint16]bool{
+	1:      true, // PCM
+	3:      true, // IEEE float
+	0xFFFE: true, // Extensible
+}
+
+// wavAudio is the layout of a parsed WAV file.
+type wavAudio struct {
+	fmtBody       []byte // Body of the fmt chunk, copied as is into every chunk
+	samples       []byte // Body of the data chunk
+	sampleRate    int
+	blockAlign    int // Bytes per frame, all channels included
+	bitsPerSample int
+	channels      int
+}
+
+// parseWAV reads the fmt and data chunks of a RIFF WAVE file.
+func parseWAV(data []byte) (*wavAudio, error) {
+	audio := &wavAudio{}
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		end := body + size
+		if end > len(data) {
+			// Streamed WAV files may carry a placeholder data size, the data runs to the end of the file
+			end = len(data)
+		}
+
+		switch id {
+		case "fmt ":
+			if end-body < 16 {
+				return nil, fmt.Errorf("audiosplit: wav fmt chunk too short")
+			}
+			fmtBody := data[body:end]
+			if !wavFormats[binary.LittleEndian.Uint16(fmtBody[0:2])] {
+				return nil, ErrUnsupportedFormat
+			}
+			audio.fmtBody = fmtBody
+			audio.channels = int(binary.LittleEndian.Uint16(fmtBody[2:4]))
+			audio.sampleRate = int(binary.LittleEndian.Uint32(fmtBody[4:8]))
+			audio.blockAlign = int(binary.LittleEndian.Uint16(fmtBody[12:14]))
+			audio.bitsPerSample = int(binary.LittleEndian.Uint16(fmtBody[14:16]))
+		case "data":
+			audio.samples = data[body:end]
+		}
+
+		// Chunks are padded to an even size
+		offset = end + size%2
+	}
+
+	if audio.fmtBody == nil || audio.samples == nil {
+		return nil, fmt.Errorf("audiosplit: wav file without fmt or data chunk")
+	}
+	if audio.sampleRate <= 0 || audio.blockAlign <= 0 || audio.channels <= 0 {
+		return nil, fmt.Errorf("audiosplit: invalid wav format")
+	}
+	return audio, nil
+}
+
+// splitWAV cuts the samples of a WAV file into chunks, each written as a WAV file of its own.
+func splitWAV(data []byte, cfg Config) ([]Chunk, error) {
+	audio, err := parseWAV(data)
+	if err != nil {
+		return nil, err
+	}
+
+	totalFrames := len(audio.samples) / audio.blockAlign
+	maxFrames := (cfg.MaxBytes - wavHeaderSize - len(audio.fmtBody) - 1) / audio.blockAlign // 1 byte of padding at most
+	if cfg.MaxDuration > 0 {
+		maxFrames = min(maxFrames, audio.frames(cfg.MaxDuration))
+	}
+	overlapFrames := audio.frames(cfg.Overlap)
+	if maxFrames <= overlapFrames {
+		return nil, fmt.Errorf("audiosplit: chunks of %d frames cannot hold the overlap", maxFrames)
+	}
+
+	if totalFrames <= maxFrames {
+		return []Chunk{{Data: data, End: audio.duration(totalFrames)}}, nil
+	}
+
+	var chunks []Chunk
+	for start := 0; start < totalFrames; {
+		end := totalFrames
+		if totalFrames-start > maxFrames {
+			// Never cut inside the overlap, the next chunk has to start after this one
+			earliest := max(start+maxFrames-audio.frames(cfg.SilenceWindow), start+overlapFrames+1)
+			end = audio.quietestFrame(earliest, start+maxFrames)
+		}
+		chunks = append(chunks, Chunk{
+			Data:  audio.encode(start, end),
+			Start: audio.duration(start),
+			End:   audio.duration(end),
+		})
+		if end == totalFrames {
+			break
+		}
+		start = nextStart(start, end, overlapFrames)
+	}
+	return chunks, nil
+}
+
+// quietestFrame returns the frame in [from, to] at the center of the quietest block of audio,
+// or to when the samples cannot be measured.
+func (a *wavAudio) quietestFrame(from, to int) int {
+	if a.bitsPerSample != 16 || from >= to {
+		return to
+	}
+	block := max(a.frames(silenceBlock), 1)
+
+	best, bestEnergy := to, math.MaxFloat64
+	for blockStart := from; blockStart+block <= to; blockStart += block {
+		var energy float64
+		for frame := blockStart; frame < blockStart+block; frame++ {
+			offset := frame * a.blockAlign
+			for channel := 0; channel < a.channels; channel++ {
+				sample := float64(int16(binary.LittleEndian.Uint16(a.samples[offset+channel*2:])))
+				energy += sample * sample
+			}
+		}
+		// Ties go to the latest block, keeping chunks as long as possible
+		if energy <= bestEnergy {
+			best, bestEnergy = blockStart+block/2, energy
+		}
+	}
+	return best
+}
+
+// encode writes frames [start, end) as a WAV file with the original format.
+func (a *wavAudio) encode(start, end int) []byte {
+	samples := a.samples[start*a.blockAlign : end*a.blockAlign]
+	padding := len(samples) % 2
+
+	var buf bytes.Buffer
+	buf.Grow(wavHeaderSize + len(a.fmtBody) + len(samples) + padding)
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+8+len(a.fmtBody)+8+len(samples)+padding))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(a.fmtBody)))
+	buf.Write(a.fmtBody)
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(samples)))
+	buf.Write(samples)
+	if padding == 1 {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+func (a *wavAudio) frames(d time.Duration) int {
+	return int(d.Seconds() * float64(a.sampleRate))
+}
+
+func (a *wavAudio) duration(frames int) time.Duration {
+	return time.Duration(float64(frames) / float64(a.sampleRate) * float64(time.Second))
+}
@@ -26,25 +26,52 @@ type ChannelMessage struct {
 	ResponseStream chan chan *schemas.BifrostStream
 	Err            chan schemas.BifrostError
 	Type           schemas.RequestType
+	queueWait      *queueWait // Set when the caller asked for queue status updates
+	fairQueued     bool       // A token for the next request of the provider's fair queue, see fairQueuing
 }
 
 // Bifrost manages providers and maintains specified open channels for concurrent processing.
 // It handles request routing, provider management, and response processing.
 type Bifrost struct {
 	ctx                 context.Context
-	account             schemas.Account  // account interface
-	plugins             []schemas.Plugin // list of plugins
-	requestQueues       sync.Map         // provider request queues (thread-safe)
-	waitGroups          sync.Map         // wait groups for each provider (thread-safe)
-	providerMutexes     sync.Map         // mutexes for each provider to prevent concurrent updates (thread-safe)
-	channelMessagePool  sync.Pool        // Pool for ChannelMessage objects, initial pool size is set in Init
-	responseChannelPool sync.Pool        // Pool for response channels, initial pool size is set in Init
-	errorChannelPool    sync.Pool        // Pool for error channels, initial pool size is set in Init
-	responseStreamPool  sync.Pool        // Pool for response stream channels, initial pool size is set in Init
-	pluginPipelinePool  sync.Pool        // Pool for PluginPipeline objects
-	logger              schemas.Logger   // logger instance, default logger is used if not provided
-	mcpManager          *MCPManager      // MCP integration manager (nil if MCP not configured)
-	dropExcessRequests  atomic.Bool      // If true, in cases where the queue is full, requests will not wait for the queue to be empty and will be dropped instead.
+	account             schemas.Account                               // account interface
+	plugins             []schemas.Plugin                              // list of plugins
+	requestQueues       sync.Map                                      // provider request queues (thread-safe)
+	waitGroups          sync.Map                                      // wait groups for each provider (thread-safe)
+	providerMutexes     sync.Map                                      // mutexes for each provider to prevent concurrent updates (thread-safe)
+	channelMessagePool  sync.Pool                                     // Pool for ChannelMessage objects, initial pool size is set in Init
+	responseChannelPool sync.Pool                                     // Pool for response channels, initial pool size is set in Init
+	errorChannelPool    sync.Pool                                     // Pool for error channels, initial pool size is set in Init
+	responseStreamPool  sync.Pool                                     // Pool for response stream channels, initial pool size is set in Init
+	pluginPipelinePool  sync.Pool                                     // Pool for PluginPipeline objects
+	logger              schemas.Logger                                // logger instance, default logger is used if not provided
+	mcpManager          *MCPManager                                   // MCP integration manager (nil if MCP not configured)
+	dropExcessRequests  atomic.Bool                                   // If true, in cases where the queue is full, requests will not wait for the queue to be empty and will be dropped instead.
+	embeddingDimension  *schemas.EmbeddingDimensionConfig             // Optional dimension coercion applied to embedding responses
+	strictParams        bool                                          // If true, request parameters are validated against paramSchemas before being sent
+	paramSchemas        map[schemas.ModelProvider]schemas.ParamSchema // Parameter schemas used in strict mode, built-in schemas merged with configured ones
+	requestPolicyLimits schemas.RequestPolicyLimits                   // Server-side maximums for per-request policy overrides
+	queueTrackers       sync.Map                                      // provider queue trackers for queue status feedback (thread-safe)
+	affinityStore       *affinityStore                                // provider, model and key that last served each session
+	sessionLimiter      *sessionLimiter                               // turn and token limits of sessions (nil if not configured)
+	agentTracer         *agentTracer                                  // agent step traces (nil if not configured)
+	promptCache         *promptCacheManager                           // prompt prefix cache manager (nil if not configured)
+	embeddingBatcher    *embeddingBatcher                             // embedding micro-batching layer (nil if not configured)
+	autoMaxTokens       *autoMaxTokens                                // automatic max_tokens layer (nil if not configured)
+	payloadSlimmer      *payloadSlimmer                               // slimming of requests rejected as too large (nil if not configured)
+	providerActivity    sync.Map                                      // provider drain states and in-flight request counts (thread-safe)
+	audioChunking       *schemas.TranscriptionChunkingConfig          // long audio splitting for transcriptions (nil if not configured)
+	videoJobs           *videoJobStore                                // keys that submitted recent video generation jobs
+	tokenCounts         *tokenCountCache                              // exact token counts of recent token count requests
+	stickyRouter        *stickyRouter                                 // consistent hash rings mapping routing keys onto provider keys
+	embeddingMigrator   *embeddingMigrator                            // embedding model migration dual-write (nil if not configured)
+	events              *eventBus                                     // subscriptions to request and provider events
+	middleware          *MiddlewareChain                              // middleware around provider calls
+	providers           sync.Map                                      // running provider instances, used by warm-ups (thread-safe)
+	warmUp              *providerWarmUp                               // provider warm-up at startup and after idle periods (nil if not configured)
+	fairQueuing         *fairQueuing                                  // weighted fair queuing of provider queues across tenants
+	loopDetector        *loopDetector                                 // agent loop detection of chat requests (nil if not configured)
+	incompleteStream    schemas.IncompleteStreamBehavior              // ending of streams closed without a terminator
 }
 
 // PluginPipeline encapsulates the execution of plugin PreHooks and PostHooks, tracks how many plugins ran, and manages short-circuiting and error aggregation.
@@ -78,13 +105,45 @@ func Init(ctx context.Context, config schemas.BifrostConfig) (*Bifrost, error) {
 	if config.Account == nil {
 		return nil, fmt.Errorf("account is required to initialize Bifrost")
 	}
+	if err := validateEmbeddingDimensionConfig(config.EmbeddingDimension); err != nil {
+		return nil, err
+	}
+	if config.IncompleteStreamBehavior != "" && !config.IncompleteStreamBehavior.IsValid() {
+		return nil, fmt.Errorf("unknown incomplete stream behavior %q, must be one of %q, %q or %q", config.IncompleteStreamBehavior, schemas.IncompleteStreamFinish, schemas.IncompleteStreamError, schemas.IncompleteStreamIgnore)
+	}
 
 	bifrost := &Bifrost{
-		ctx:           ctx,
-		account:       config.Account,
-		plugins:       config.Plugins,
-		requestQueues: sync.Map{},
-		waitGroups:    sync.Map{},
+		ctx:                 ctx,
+		account:             config.Account,
+		plugins:             config.Plugins,
+		requestQueues:       sync.Map{},
+		waitGroups:          sync.Map{},
+		embeddingDimension:  config.EmbeddingDimension,
+		strictParams:        config.StrictParams,
+		paramSchemas:        buildParamSchemas(config.ParamSchemas),
+		requestPolicyLimits: buildRequestPolicyLimits(config.RequestPolicyLimits),
+		affinityStore:       newAffinityStore(config.SessionAffinity),
+		sessionLimiter:      newSessionLimiter(config.SessionLimits),
+		agentTracer:         newAgentTracer(config.AgentTracing),
+		promptCache:         newPromptCacheManager(config.PromptCache),
+		autoMaxTokens:       newAutoMaxTokens(config.AutoMaxTokens),
+		payloadSlimmer:      newPayloadSlimmer(config.PayloadSlimming),
+		audioChunking:       newTranscriptionChunking(config.TranscriptionChunking),
+		videoJobs:           newVideoJobStore(),
+		tokenCounts:         newTokenCountCache(),
+		stickyRouter:        newStickyRouter(config.StickyRouting),
+		events:              newEventBus(),
+		warmUp:              newProviderWarmUp(config.WarmUp),
+		fairQueuing:         newFairQueuing(config.FairQueuing),
+		loopDetector:        newLoopDetector(config.LoopDetection),
+		incompleteStream:    config.IncompleteStreamBehavior,
+	}
+	bifrost.embeddingBatcher = newEmbeddingBatcher(bifrost, config.EmbeddingBatching)
+	bifrost.middleware = newMiddlewareChain(bifrost.builtinMiddleware()...)
+	for _, middleware := range config.Middleware {
+		if err := bifrost.middleware.Use(middleware); err != nil {
+			return nil, fmt.Errorf("failed to add middleware: %w", err)
+		}
 	}
 	bifrost.dropExcessRequests.Store(config.DropExcessRequests)
 
@@ -141,6 +200,12 @@ func Init(ctx context.Context, config schemas.BifrostConfig) (*Bifrost, error) {
 	}
 	bifrost.logger = config.Logger
 
+	embeddingMigrator, err := newEmbeddingMigrator(bifrost, config.EmbeddingMigration)
+	if err != nil {
+		return nil, err
+	}
+	bifrost.embeddingMigrator = embeddingMigrator
+
 	// Initialize MCP manager if configured
 	if config.MCPConfig != nil {
 		mcpManager, err := newMCPManager(ctx, *config.MCPConfig, bifrost.logger)
@@ -176,6 +241,11 @@ func Init(ctx context.Context, config schemas.BifrostConfig) (*Bifrost, error) {
 		}
 	}
 
+	// Warm up the providers in the background, requests are served meanwhile
+	if bifrost.warmUp != nil {
+		go bifrost.warmUp.run(bifrost)
+	}
+
 	return bifrost, nil
 }
 
@@ -186,6 +256,7 @@ func (bifrost *Bifrost) TextCompletionRequest(ctx context.Context, req *schemas.
 	if req.Input.TextCompletionInput == nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
 			Error: schemas.ErrorField{
 				Message: "text not provided for text completion request",
 			},
@@ -200,6 +271,7 @@ func (bifrost *Bifrost) ChatCompletionRequest(ctx context.Context, req *schemas.
 	if req.Input.ChatCompletionInput == nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
 			Error: schemas.ErrorField{
 				Message: "chats not provided for chat completion request",
 			},
@@ -214,6 +286,7 @@ func (bifrost *Bifrost) ChatCompletionStreamRequest(ctx context.Context, req *sc
 	if req.Input.ChatCompletionInput == nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
 			Error: schemas.ErrorField{
 				Message: "chats not provided for chat completion request",
 			},
@@ -228,13 +301,26 @@ func (bifrost *Bifrost) EmbeddingRequest(ctx context.Context, req *schemas.Bifro
 	if req.Input.EmbeddingInput == nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
 			Error: schemas.ErrorField{
 				Message: "embedding input not provided for embedding request",
 			},
 		}
 	}
 
-	return bifrost.handleRequest(ctx, req, schemas.EmbeddingRequest)
+	response, bifrostErr := bifrost.handleRequest(ctx, req, schemas.EmbeddingRequest)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	if bifrostErr := applyEmbeddingDimension(response, bifrost.getEmbeddingDimensionConfig(ctx)); bifrostErr != nil {
+		bifrostErr.Provider = req.Provider
+		return nil, bifrostErr
+	}
+
+	bifrost.embeddingMigrator.enqueue(ctx, req, response)
+
+	return response, nil
 }
 
 // SpeechRequest sends a speech request to the specified provider.
@@ -242,6 +328,7 @@ func (bifrost *Bifrost) SpeechRequest(ctx context.Context, req *schemas.BifrostR
 	if req.Input.SpeechInput == nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
 			Error: schemas.ErrorField{
 				Message: "speech input not provided for speech request",
 			},
@@ -256,6 +343,7 @@ func (bifrost *Bifrost) SpeechStreamRequest(ctx context.Context, req *schemas.Bi
 	if req.Input.SpeechInput == nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
 			Error: schemas.ErrorField{
 				Message: "speech input not provided for speech stream request",
 			},
@@ -270,20 +358,77 @@ func (bifrost *Bifrost) TranscriptionRequest(ctx context.Context, req *schemas.B
 	if req.Input.TranscriptionInput == nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
 			Error: schemas.ErrorField{
 				Message: "transcription input not provided for transcription request",
 			},
 		}
 	}
 
+	if chunks := bifrost.splitTranscription(ctx, req); chunks != nil {
+		return bifrost.chunkedTranscription(ctx, req, schemas.TranscriptionRequest, chunks)
+	}
+
 	return bifrost.handleRequest(ctx, req, schemas.TranscriptionRequest)
 }
 
+// TranslationRequest sends a translation request to the specified provider.
+// The audio is transcribed into English whatever language it is spoken in.
+func (bifrost *Bifrost) TranslationRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if req.Input.TranscriptionInput == nil {
+		return nil, &schemas.BifrostError{
+			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
+			Error: schemas.ErrorField{
+				Message: "transcription input not provided for translation request",
+			},
+		}
+	}
+
+	if chunks := bifrost.splitTranscription(ctx, req); chunks != nil {
+		return bifrost.chunkedTranscription(ctx, req, schemas.TranslationRequest, chunks)
+	}
+
+	return bifrost.handleRequest(ctx, req, schemas.TranslationRequest)
+}
+
+// ImageGenerationRequest sends an image generation request to the specified provider.
+func (bifrost *Bifrost) ImageGenerationRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if req.Input.ImageInput == nil || req.Input.ImageInput.Prompt == "" {
+		return nil, &schemas.BifrostError{
+			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
+			Error: schemas.ErrorField{
+				Message: "prompt not provided for image generation request",
+			},
+		}
+	}
+
+	return bifrost.handleRequest(ctx, req, schemas.ImageGenerationRequest)
+}
+
+// RerankRequest sends a rerank request to the specified provider, which orders the documents
+// of the request by their relevance to its query.
+func (bifrost *Bifrost) RerankRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if req.Input.RerankInput == nil || req.Input.RerankInput.Query == "" || len(req.Input.RerankInput.Documents) == 0 {
+		return nil, &schemas.BifrostError{
+			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
+			Error: schemas.ErrorField{
+				Message: "query and documents not provided for rerank request",
+			},
+		}
+	}
+
+	return bifrost.handleRequest(ctx, req, schemas.RerankRequest)
+}
+
 // TranscriptionStreamRequest sends a transcription stream request to the specified provider.
 func (bifrost *Bifrost) TranscriptionStreamRequest(ctx context.Context, req *schemas.BifrostRequest) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	if req.Input.TranscriptionInput == nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
 			Error: schemas.ErrorField{
 				Message: "transcription input not provided for transcription stream request",
 			},
@@ -360,6 +505,7 @@ func (bifrost *Bifrost) UpdateProviderConcurrency(providerKey schemas.ModelProvi
 						select {
 						case m.Err <- schemas.BifrostError{
 							IsBifrostError: false,
+							Origin:         schemas.ErrorOriginBifrostInternal,
 							Error: schemas.ErrorField{
 								Message: "request failed during provider concurrency update",
 							},
@@ -407,12 +553,15 @@ transferComplete:
 		return fmt.Errorf("failed to create provider instance for %s: %v", providerKey, err)
 	}
 
+	bifrost.providers.Store(providerKey, provider)
+
 	// Step 8: Start new workers with updated concurrency
 	bifrost.logger.Debug("starting %d new workers for provider %s with buffer size %d",
 		providerConfig.ConcurrencyAndBufferSize.Concurrency,
 		providerKey,
 		providerConfig.ConcurrencyAndBufferSize.BufferSize)
 
+	bifrost.getQueueTracker(providerKey).concurrency.Store(int64(providerConfig.ConcurrencyAndBufferSize.Concurrency))
 	for range providerConfig.ConcurrencyAndBufferSize.Concurrency {
 		waitGroupValue, _ := bifrost.waitGroups.Load(providerKey)
 		waitGroup := waitGroupValue.(*sync.WaitGroup)
@@ -489,16 +638,20 @@ func (bifrost *Bifrost) ExecuteMCPTool(ctx context.Context, toolCall schemas.Too
 	if bifrost.mcpManager == nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginBifrostInternal,
 			Error: schemas.ErrorField{
 				Message: "MCP is not configured in this Bifrost instance",
 			},
 		}
 	}
 
+	toolCallSpan := bifrost.startAgentToolCall(ctx, toolCall)
 	result, err := bifrost.mcpManager.executeTool(ctx, toolCall)
+	toolCallSpan.finishToolCall(result, err)
 	if err != nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginBifrostInternal,
 			Error: schemas.ErrorField{
 				Message: err.Error(),
 				Error:   err,
@@ -509,6 +662,24 @@ func (bifrost *Bifrost) ExecuteMCPTool(ctx context.Context, toolCall schemas.Too
 	return result, nil
 }
 
+// SetMCPToolPolicy sets or replaces the execution policy of an MCP tool.
+// Policies control timeouts, concurrency, allowed argument ranges and whether
+// each execution must be approved through the configured approval handler.
+//
+// Parameters:
+//   - toolName: Name of the tool the policy applies to
+//   - policy: Execution policy for the tool
+//
+// Returns:
+//   - error: Any validation error
+func (bifrost *Bifrost) SetMCPToolPolicy(toolName string, policy schemas.MCPToolPolicy) error {
+	if bifrost.mcpManager == nil {
+		return fmt.Errorf("MCP is not configured in this Bifrost instance")
+	}
+
+	return bifrost.mcpManager.SetToolPolicy(toolName, policy)
+}
+
 // IMPORTANT: Running the MCP client management operations (GetMCPClients, AddMCPClient, RemoveMCPClient, EditMCPClientTools)
 // may temporarily increase latency for incoming requests while the operations are being processed.
 // These operations involve network I/O and connection management that require mutex locks
@@ -698,6 +869,36 @@ func (bifrost *Bifrost) createBaseProvider(providerKey schemas.ModelProvider, co
 		return providers.NewGeminiProvider(config, bifrost.logger), nil
 	case schemas.OpenRouter:
 		return providers.NewOpenRouterProvider(config, bifrost.logger), nil
+	case schemas.Perplexity:
+		return providers.NewPerplexityProvider(config, bifrost.logger)
+	case schemas.DeepSeek:
+		return providers.NewDeepSeekProvider(config, bifrost.logger)
+	case schemas.Together:
+		return providers.NewTogetherProvider(config, bifrost.logger)
+	case schemas.Replicate:
+		return providers.NewReplicateProvider(config, bifrost.logger)
+	case schemas.Qwen:
+		return providers.NewQwenProvider(config, bifrost.logger)
+	case schemas.Zhipu:
+		return providers.NewZhipuProvider(config, bifrost.logger)
+	case schemas.Moonshot:
+		return providers.NewMoonshotProvider(config, bifrost.logger)
+	case schemas.Qianfan:
+		return providers.NewQianfanProvider(config, bifrost.logger)
+	case schemas.SambaNova:
+		return providers.NewSambaNovaProvider(config, bifrost.logger)
+	case schemas.NIM:
+		return providers.NewNIMProvider(config, bifrost.logger)
+	case schemas.Watsonx:
+		return providers.NewWatsonxProvider(config, bifrost.logger)
+	case schemas.Stability:
+		return providers.NewStabilityProvider(config, bifrost.logger)
+	case schemas.BFL:
+		return providers.NewBFLProvider(config, bifrost.logger)
+	case schemas.Luma:
+		return providers.NewLumaProvider(config, bifrost.logger)
+	case schemas.Template:
+		return providers.NewTemplateProvider(config, bifrost.logger)
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", targetProviderKey)
 	}
@@ -723,7 +924,9 @@ func (bifrost *Bifrost) prepareProvider(providerKey schemas.ModelProvider, confi
 	if err != nil {
 		return fmt.Errorf("failed to create provider for the given key: %v", err)
 	}
+	bifrost.providers.Store(providerKey, provider)
 
+	bifrost.getQueueTracker(providerKey).concurrency.Store(int64(providerConfig.ConcurrencyAndBufferSize.Concurrency))
 	for range providerConfig.ConcurrencyAndBufferSize.Concurrency {
 		waitGroupValue, _ := bifrost.waitGroups.Load(providerKey)
 		waitGroup := waitGroupValue.(*sync.WaitGroup)
@@ -800,8 +1003,8 @@ func (bifrost *Bifrost) shouldTryFallbacks(req *schemas.BifrostRequest, primaryE
 		return false
 	}
 
-	// If no fallbacks configured, return primary error
-	if len(req.Fallbacks) == 0 {
+	// If no fallbacks configured or allowed by the request policy, return primary error
+	if len(bifrost.getFallbacks(req)) == 0 {
 		primaryErr.Provider = req.Provider
 		return false
 	}
@@ -860,8 +1063,43 @@ func (bifrost *Bifrost) handleRequest(ctx context.Context, req *schemas.BifrostR
 		ctx = bifrost.ctx
 	}
 
+	ctx, events := bifrost.events.requestStarted(ctx, req, requestType)
+
+	// Trace the request as a step of its agent loop
+	ctx, modelCall := bifrost.startAgentModelCall(ctx, req, requestType)
+	result, bifrostErr := bifrost.handleTracedRequest(ctx, req, requestType)
+	modelCall.finish(result, bifrostErr)
+	events.finished(req, result, bifrostErr)
+	return result, bifrostErr
+}
+
+// handleTracedRequest runs a request against its primary provider and fallbacks.
+func (bifrost *Bifrost) handleTracedRequest(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	ctx, warnings := withWarnings(ctx)
+
+	// Count the request against the limits of its session
+	req, turn, limitErr := bifrost.applySessionLimits(ctx, req, requestType)
+	if limitErr != nil {
+		return nil, limitErr
+	}
+
+	// Stop or correct agent loops that keep making the same tool call
+	req, loopErr := bifrost.applyLoopDetection(ctx, req, requestType)
+	if loopErr != nil {
+		return nil, loopErr
+	}
+
+	// Route the turns of a session to the provider that served it before
+	ctx, req, affinity := bifrost.applySessionAffinity(ctx, req)
+
 	// Try the primary provider first
 	primaryResult, primaryErr := bifrost.tryRequest(req, ctx, requestType)
+	bifrost.events.recordAttempt(req.Provider, primaryErr)
+	if primaryErr == nil && primaryResult != nil {
+		primaryResult.ExtraFields.CacheReset = affinity.served(req.Provider, req.Model)
+		primaryResult.ExtraFields.SessionUsage = turn.record(primaryResult.Usage)
+		addResponseWarnings(primaryResult, warnings)
+	}
 
 	// Check if we should proceed with fallbacks
 	shouldTryFallbacks := bifrost.shouldTryFallbacks(req, primaryErr)
@@ -870,16 +1108,25 @@ func (bifrost *Bifrost) handleRequest(ctx context.Context, req *schemas.BifrostR
 	}
 
 	// Try fallbacks in order
-	for _, fallback := range req.Fallbacks {
+	events := getRequestEvents(ctx)
+	failedReq, failedErr := req, primaryErr
+	for _, fallback := range bifrost.getFallbacks(req) {
 		fallbackReq := bifrost.prepareFallbackRequest(req, fallback)
 		if fallbackReq == nil {
 			continue
 		}
+		events.fallback(failedReq, fallback, failedErr)
 
 		// Try the fallback provider
 		result, fallbackErr := bifrost.tryRequest(fallbackReq, ctx, requestType)
+		bifrost.events.recordAttempt(fallback.Provider, fallbackErr)
 		if fallbackErr == nil {
 			bifrost.logger.Info(fmt.Sprintf("Successfully used fallback provider %s with model %s", fallback.Provider, fallback.Model))
+			if result != nil {
+				result.ExtraFields.CacheReset = affinity.served(fallback.Provider, fallback.Model)
+				result.ExtraFields.SessionUsage = turn.record(result.Usage)
+				addResponseWarnings(result, warnings)
+			}
 			return result, nil
 		}
 
@@ -887,6 +1134,7 @@ func (bifrost *Bifrost) handleRequest(ctx context.Context, req *schemas.BifrostR
 		if !bifrost.shouldContinueWithFallbacks(fallback, fallbackErr) {
 			return nil, fallbackErr
 		}
+		failedReq, failedErr = fallbackReq, fallbackErr
 	}
 
 	primaryErr.Provider = req.Provider
@@ -909,8 +1157,74 @@ func (bifrost *Bifrost) handleStreamRequest(ctx context.Context, req *schemas.Bi
 		ctx = bifrost.ctx
 	}
 
+	// Tell providers how to end the stream if it is closed without a terminator, unless the
+	// request already does
+	if _, ok := ctx.Value(schemas.BifrostContextKeyIncompleteStream).(schemas.IncompleteStreamBehavior); !ok && bifrost.incompleteStream != "" {
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyIncompleteStream, bifrost.incompleteStream)
+	}
+
+	ctx, events := bifrost.events.requestStarted(ctx, req, requestType)
+	stream, bifrostErr := bifrost.routeStreamRequest(ctx, req, requestType)
+	return events.streamFinished(req, stream, bifrostErr)
+}
+
+// routeStreamRequest runs a stream request with queue status, speculative draft or agent tracing
+// when requested.
+func (bifrost *Bifrost) routeStreamRequest(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	// Deliver queue status on the stream, which then has to be returned before the request runs
+	if queueStatusEvents, ok := ctx.Value(schemas.BifrostContextKeyQueueStatusEvents).(bool); ok && queueStatusEvents {
+		return bifrost.streamWithQueueStatus(ctx, req, requestType), nil
+	}
+
+	// Stream a fast draft model until the requested model starts, which also has to run in the background
+	if draft, ok := getSpeculativeDraft(ctx, requestType); ok {
+		return bifrost.streamWithSpeculativeDraft(ctx, req, requestType, draft), nil
+	}
+
+	// Trace the request as a step of its agent loop
+	ctx, modelCall := bifrost.startAgentModelCall(ctx, req, requestType)
+	stream, bifrostErr := bifrost.handleTracedStreamRequest(ctx, req, requestType)
+	if bifrostErr != nil {
+		modelCall.finish(nil, bifrostErr)
+		return nil, bifrostErr
+	}
+	if modelCall != nil {
+		stream = streamWithAgentTrace(stream, modelCall)
+	}
+	return stream, nil
+}
+
+// handleTracedStreamRequest runs a stream request against its primary provider and fallbacks.
+func (bifrost *Bifrost) handleTracedStreamRequest(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	ctx, warnings := withWarnings(ctx)
+
+	// Count the request against the limits of its session
+	req, turn, limitErr := bifrost.applySessionLimits(ctx, req, requestType)
+	if limitErr != nil {
+		return nil, limitErr
+	}
+
+	// Stop or correct agent loops that keep making the same tool call
+	req, loopErr := bifrost.applyLoopDetection(ctx, req, requestType)
+	if loopErr != nil {
+		return nil, loopErr
+	}
+
+	// Route the turns of a session to the provider that served it before
+	ctx, req, affinity := bifrost.applySessionAffinity(ctx, req)
+
 	// Try the primary provider first
 	primaryResult, primaryErr := bifrost.tryStreamRequest(req, ctx, requestType)
+	bifrost.events.recordAttempt(req.Provider, primaryErr)
+	if primaryErr == nil {
+		if cacheReset := affinity.served(req.Provider, req.Model); cacheReset != nil {
+			primaryResult = streamWithCacheReset(primaryResult, cacheReset)
+		}
+		if turn != nil {
+			primaryResult = streamWithSessionUsage(primaryResult, turn)
+		}
+		primaryResult = streamWithWarnings(primaryResult, warnings)
+	}
 
 	// Check if we should proceed with fallbacks
 	shouldTryFallbacks := bifrost.shouldTryFallbacks(req, primaryErr)
@@ -919,23 +1233,34 @@ func (bifrost *Bifrost) handleStreamRequest(ctx context.Context, req *schemas.Bi
 	}
 
 	// Try fallbacks in order
-	for _, fallback := range req.Fallbacks {
+	events := getRequestEvents(ctx)
+	failedReq, failedErr := req, primaryErr
+	for _, fallback := range bifrost.getFallbacks(req) {
 		fallbackReq := bifrost.prepareFallbackRequest(req, fallback)
 		if fallbackReq == nil {
 			continue
 		}
+		events.fallback(failedReq, fallback, failedErr)
 
 		// Try the fallback provider
 		result, fallbackErr := bifrost.tryStreamRequest(fallbackReq, ctx, requestType)
+		bifrost.events.recordAttempt(fallback.Provider, fallbackErr)
 		if fallbackErr == nil {
 			bifrost.logger.Info(fmt.Sprintf("Successfully used fallback provider %s with model %s", fallback.Provider, fallback.Model))
-			return result, nil
+			if cacheReset := affinity.served(fallback.Provider, fallback.Model); cacheReset != nil {
+				result = streamWithCacheReset(result, cacheReset)
+			}
+			if turn != nil {
+				result = streamWithSessionUsage(result, turn)
+			}
+			return streamWithWarnings(result, warnings), nil
 		}
 
 		// Check if we should continue with more fallbacks
 		if !bifrost.shouldContinueWithFallbacks(fallback, fallbackErr) {
 			return nil, fallbackErr
 		}
+		failedReq, failedErr = fallbackReq, fallbackErr
 	}
 
 	primaryErr.Provider = req.Provider
@@ -946,18 +1271,31 @@ func (bifrost *Bifrost) handleStreamRequest(ctx context.Context, req *schemas.Bi
 // tryRequest is a generic function that handles common request processing logic
 // It consolidates queue setup, plugin pipeline execution, enqueue logic, and response handling
 func (bifrost *Bifrost) tryRequest(req *schemas.BifrostRequest, ctx context.Context, requestType schemas.RequestType) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	// Draining and inactive providers take no new requests
+	activity, unavailableErr := bifrost.acquireProvider(req.Provider)
+	if unavailableErr != nil {
+		return nil, unavailableErr
+	}
+	defer activity.release()
+
 	queue, err := bifrost.getProviderQueue(req.Provider)
 	if err != nil {
-		return nil, newBifrostError(err)
+		return nil, newBifrostError(err, schemas.ErrorOriginBifrostInternal)
 	}
 
 	// Attach context keys to the context
 	ctx = attachContextKeys(ctx, req, requestType)
+	ctx, warnings := withWarnings(ctx)
 
 	// Add MCP tools to request if MCP is configured and requested
 	if requestType != schemas.EmbeddingRequest &&
 		requestType != schemas.SpeechRequest &&
 		requestType != schemas.TranscriptionRequest &&
+		requestType != schemas.TranslationRequest &&
+		requestType != schemas.ImageGenerationRequest &&
+		requestType != schemas.VideoGenerationRequest &&
+		requestType != schemas.VideoStatusRequest &&
+		requestType != schemas.RerankRequest &&
 		bifrost.mcpManager != nil {
 		req = bifrost.mcpManager.addMCPToolsToBifrostRequest(ctx, req)
 	}
@@ -973,77 +1311,108 @@ func (bifrost *Bifrost) tryRequest(req *schemas.BifrostRequest, ctx context.Cont
 			if bifrostErr != nil {
 				return nil, bifrostErr
 			}
+			addResponseWarnings(resp, warnings)
 			return resp, nil
 		}
 		// Handle short-circuit with error
 		if shortCircuit.Error != nil {
+			getRequestEvents(ctx).blocked(req, pipeline.plugins[preCount-1].GetName(), shortCircuit.Error)
 			resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, shortCircuit.Error, preCount)
 			if bifrostErr != nil {
 				return nil, bifrostErr
 			}
+			addResponseWarnings(resp, warnings)
 			return resp, nil
 		}
 	}
 	if preReq == nil {
-		return nil, newBifrostErrorFromMsg("bifrost request after plugin hooks cannot be nil")
+		return nil, newBifrostErrorFromMsg("bifrost request after plugin hooks cannot be nil", schemas.ErrorOriginBifrostInternal)
 	}
+	// In strict mode the provider checks the request body it builds against the provider's schema
+	ctx = bifrost.withStrictParams(ctx, preReq.Provider)
+
+	// Keep the stable prompt prefix identical across requests for provider-side caching
+	promptCache := bifrost.getPromptCacheManager(ctx)
+	preReq, prefixKey := promptCache.prepare(preReq, requestType)
+
+	// Fit max_tokens into the context window left after the prompt
+	preReq = bifrost.getAutoMaxTokens(ctx).apply(ctx, preReq, requestType)
 
 	msg := bifrost.getChannelMessage(*preReq, requestType)
 	msg.Context = ctx
 
-	select {
-	case queue <- *msg:
-		// Message was sent successfully
-	case <-ctx.Done():
-		bifrost.releaseChannelMessage(msg)
-		return nil, newBifrostErrorFromMsg("request cancelled while waiting for queue space")
-	default:
-		if bifrost.dropExcessRequests.Load() {
-			bifrost.releaseChannelMessage(msg)
-			bifrost.logger.Warn("Request dropped: queue is full, please increase the queue size or set dropExcessRequests to false")
-			return nil, newBifrostErrorFromMsg("request dropped: queue is full")
-		}
-		select {
-		case queue <- *msg:
-			// Message was sent successfully
-		case <-ctx.Done():
+	var reporter *queueReporter
+	if batcher := bifrost.getEmbeddingBatcher(ctx, preReq, requestType); batcher != nil {
+		// Merged with other embedding requests into one provider call
+		batcher.add(msg)
+	} else {
+		reporter = bifrost.newQueueReporter(ctx, preReq.Provider, msg)
+		defer reporter.stop()
+
+		if enqueueErr := bifrost.enqueueRequest(ctx, queue, msg, reporter); enqueueErr != nil {
 			bifrost.releaseChannelMessage(msg)
-			return nil, newBifrostErrorFromMsg("request cancelled while waiting for queue space")
+			return nil, enqueueErr
 		}
 	}
 
 	var result *schemas.BifrostResponse
 	var resp *schemas.BifrostResponse
-	select {
-	case result = <-msg.Response:
-		resp, bifrostErr := pipeline.RunPostHooks(&ctx, result, nil, len(bifrost.plugins))
-		if bifrostErr != nil {
+	for {
+		select {
+		case <-reporter.C():
+			reporter.report()
+		case result = <-msg.Response:
+			if result != nil && prefixKey != "" {
+				result.ExtraFields.PromptCache = promptCache.observe(preReq, prefixKey, result.Usage, result.ExtraFields.PromptCache)
+			}
+			resp, bifrostErr := pipeline.RunPostHooks(&ctx, result, nil, len(bifrost.plugins))
+			if bifrostErr != nil {
+				bifrost.releaseChannelMessage(msg)
+				return nil, bifrostErr
+			}
 			bifrost.releaseChannelMessage(msg)
-			return nil, bifrostErr
-		}
-		bifrost.releaseChannelMessage(msg)
-		return resp, nil
-	case bifrostErrVal := <-msg.Err:
-		bifrostErrPtr := &bifrostErrVal
-		resp, bifrostErrPtr = pipeline.RunPostHooks(&ctx, nil, bifrostErrPtr, len(bifrost.plugins))
-		bifrost.releaseChannelMessage(msg)
-		if bifrostErrPtr != nil {
-			return nil, bifrostErrPtr
+			addResponseWarnings(resp, warnings)
+			return resp, nil
+		case bifrostErrVal := <-msg.Err:
+			bifrostErrPtr := &bifrostErrVal
+			resp, bifrostErrPtr = pipeline.RunPostHooks(&ctx, nil, bifrostErrPtr, len(bifrost.plugins))
+			bifrost.releaseChannelMessage(msg)
+			if bifrostErrPtr != nil {
+				return nil, bifrostErrPtr
+			}
+			addResponseWarnings(resp, warnings)
+			return resp, nil
 		}
-		return resp, nil
 	}
 }
 
-// tryStreamRequest is a generic function that handles common request processing logic
-// It consolidates queue setup, plugin pipeline execution, enqueue logic, and response handling
+// tryStreamRequest runs a stream request against its provider, counting it in flight until its
+// stream ends so that draining the provider waits for it.
 func (bifrost *Bifrost) tryStreamRequest(req *schemas.BifrostRequest, ctx context.Context, requestType schemas.RequestType) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	// Draining and inactive providers take no new requests
+	activity, unavailableErr := bifrost.acquireProvider(req.Provider)
+	if unavailableErr != nil {
+		return nil, unavailableErr
+	}
+	stream, bifrostErr := bifrost.tryAcquiredStreamRequest(req, ctx, requestType)
+	if stream == nil {
+		activity.release()
+		return nil, bifrostErr
+	}
+	return streamWithRelease(stream, activity), bifrostErr
+}
+
+// tryAcquiredStreamRequest is a generic function that handles common request processing logic
+// It consolidates queue setup, plugin pipeline execution, enqueue logic, and response handling
+func (bifrost *Bifrost) tryAcquiredStreamRequest(req *schemas.BifrostRequest, ctx context.Context, requestType schemas.RequestType) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	queue, err := bifrost.getProviderQueue(req.Provider)
 	if err != nil {
-		return nil, newBifrostError(err)
+		return nil, newBifrostError(err, schemas.ErrorOriginBifrostInternal)
 	}
 
 	// Attach context keys to the context
 	ctx = attachContextKeys(ctx, req, requestType)
+	ctx, warnings := withWarnings(ctx)
 
 	// Add MCP tools to request if MCP is configured and requested
 	if requestType != schemas.SpeechStreamRequest && requestType != schemas.TranscriptionStreamRequest && bifrost.mcpManager != nil {
@@ -1061,6 +1430,7 @@ func (bifrost *Bifrost) tryStreamRequest(req *schemas.BifrostRequest, ctx contex
 			if bifrostErr != nil {
 				return nil, bifrostErr
 			}
+			addResponseWarnings(resp, warnings)
 			return newBifrostMessageChan(resp), nil
 		}
 		// Handle short-circuit with stream
@@ -1091,63 +1461,69 @@ func (bifrost *Bifrost) tryStreamRequest(req *schemas.BifrostRequest, ctx contex
 				}
 			}()
 
-			return outputStream, nil
+			return streamWithWarnings(outputStream, warnings), nil
 		}
 		// Handle short-circuit with error
 		if shortCircuit.Error != nil {
+			getRequestEvents(ctx).blocked(req, pipeline.plugins[preCount-1].GetName(), shortCircuit.Error)
 			resp, bifrostErr := pipeline.RunPostHooks(&ctx, nil, shortCircuit.Error, preCount)
 			if bifrostErr != nil {
 				return nil, bifrostErr
 			}
+			addResponseWarnings(resp, warnings)
 			return newBifrostMessageChan(resp), nil
 		}
 	}
 	if preReq == nil {
-		return nil, newBifrostErrorFromMsg("bifrost request after plugin hooks cannot be nil")
+		return nil, newBifrostErrorFromMsg("bifrost request after plugin hooks cannot be nil", schemas.ErrorOriginBifrostInternal)
 	}
+	// In strict mode the provider checks the request body it builds against the provider's schema
+	ctx = bifrost.withStrictParams(ctx, preReq.Provider)
+
+	// Keep the stable prompt prefix identical across requests for provider-side caching
+	promptCache := bifrost.getPromptCacheManager(ctx)
+	preReq, prefixKey := promptCache.prepare(preReq, requestType)
+
+	// Fit max_tokens into the context window left after the prompt
+	preReq = bifrost.getAutoMaxTokens(ctx).apply(ctx, preReq, requestType)
 
 	msg := bifrost.getChannelMessage(*preReq, requestType)
 	msg.Context = ctx
 
-	select {
-	case queue <- *msg:
-		// Message was sent successfully
-	case <-ctx.Done():
+	reporter := bifrost.newQueueReporter(ctx, preReq.Provider, msg)
+	defer reporter.stop()
+
+	if enqueueErr := bifrost.enqueueRequest(ctx, queue, msg, reporter); enqueueErr != nil {
 		bifrost.releaseChannelMessage(msg)
-		return nil, newBifrostErrorFromMsg("request cancelled while waiting for queue space")
-	default:
-		if bifrost.dropExcessRequests.Load() {
-			bifrost.releaseChannelMessage(msg)
-			bifrost.logger.Warn("Request dropped: queue is full, please increase the queue size or set dropExcessRequests to false")
-			return nil, newBifrostErrorFromMsg("request dropped: queue is full")
-		}
-		select {
-		case queue <- *msg:
-			// Message was sent successfully
-		case <-ctx.Done():
-			bifrost.releaseChannelMessage(msg)
-			return nil, newBifrostErrorFromMsg("request cancelled while waiting for queue space")
-		}
+		return nil, enqueueErr
 	}
 
-	select {
-	case stream := <-msg.ResponseStream:
-		bifrost.releaseChannelMessage(msg)
-		return stream, nil
-	case bifrostErrVal := <-msg.Err:
-		bifrost.logger.Warn("error while executing stream request: %v", bifrostErrVal.Error.Message)
-		// Marking final chunk
-		ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
-		// On error we will complete post-hooks
-		recoveredResp, recoveredErr := pipeline.RunPostHooks(&ctx, nil, &bifrostErrVal, len(bifrost.plugins))
-		bifrost.releaseChannelMessage(msg)
-		if recoveredErr != nil {
-			return nil, recoveredErr
-		}
-		if recoveredResp != nil {
-			return newBifrostMessageChan(recoveredResp), nil
+	for {
+		select {
+		case <-reporter.C():
+			reporter.report()
+		case stream := <-msg.ResponseStream:
+			bifrost.releaseChannelMessage(msg)
+			if prefixKey != "" {
+				stream = promptCache.observeStream(preReq, prefixKey, stream)
+			}
+			return streamWithWarnings(stream, warnings), nil
+		case bifrostErrVal := <-msg.Err:
+			bifrost.logger.Warn("error while executing stream request: %v", bifrostErrVal.Error.Message)
+			// Marking final chunk
+			ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+			// On error we will complete post-hooks
+			recoveredResp, recoveredErr := pipeline.RunPostHooks(&ctx, nil, &bifrostErrVal, len(bifrost.plugins))
+			bifrost.releaseChannelMessage(msg)
+			if recoveredErr != nil {
+				return nil, recoveredErr
+			}
+			if recoveredResp != nil {
+				addResponseWarnings(recoveredResp, warnings)
+				return newBifrostMessageChan(recoveredResp), nil
+			}
+			return nil, &bifrostErrVal
 		}
-		return nil, &bifrostErrVal
 	}
 }
 
@@ -1161,7 +1537,23 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 		}
 	}()
 
+	tracker := bifrost.getQueueTracker(provider.GetProviderKey())
+	caller := bifrost.middleware.newChainCaller(providerCaller{provider: provider})
+
 	for req := range queue {
+		if req.fairQueued {
+			next, ok := bifrost.fairQueuing.queue(provider.GetProviderKey()).pop()
+			if !ok {
+				continue
+			}
+			req = next
+		}
+		tracker.dequeued.Add(1)
+		if req.queueWait != nil {
+			req.queueWait.started.Store(true)
+		}
+		serviceStart := time.Now()
+
 		var result *schemas.BifrostResponse
 		var stream chan *schemas.BifrostStream
 		var bifrostError *schemas.BifrostError
@@ -1173,6 +1565,18 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 			baseProvider = cfg.BaseProviderType
 		}
 
+		// Providers without service tiers serve every request at their only tier
+		if req.Params != nil && req.Params.ServiceTier != nil && !providers.SupportsServiceTier(baseProvider) {
+			schemas.AddWarning(req.Context, schemas.WarningParamDropped, schemas.WarningOriginBifrost,
+				fmt.Sprintf("service_tier %q was not sent: %s has no service tiers", *req.Params.ServiceTier, provider.GetProviderKey()))
+		}
+
+		// Send the roles of chat messages the way the provider accepts them
+		if req.Input.ChatCompletionInput != nil {
+			messages := normalizeRoles(req.Context, baseProvider, *req.Input.ChatCompletionInput)
+			req.Input.ChatCompletionInput = &messages
+		}
+
 		key := schemas.Key{}
 		if providerRequiresKey(baseProvider) {
 			// Use the custom provider name for actual key selection, but pass base provider type for key validation
@@ -1181,6 +1585,7 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 				bifrost.logger.Warn("error selecting key for model %s: %v", req.Model, err)
 				req.Err <- schemas.BifrostError{
 					IsBifrostError: false,
+					Origin:         schemas.ErrorOriginBifrostInternal,
 					Error: schemas.ErrorField{
 						Message: err.Error(),
 						Error:   err,
@@ -1190,10 +1595,7 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 			}
 		}
 
-		// Track attempts
-		var attempts int
-
-		// Create plugin pipeline for streaming requests outside retry loop to prevent leaks
+		// Create plugin pipeline for streaming requests outside the middleware chain to prevent leaks
 		var postHookRunner schemas.PostHookRunner
 		if IsStreamRequestType(req.Type) {
 			pipeline := bifrost.getPluginPipeline()
@@ -1208,48 +1610,28 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 			}
 		}
 
-		// Execute request with retries
-		for attempts = 0; attempts <= config.NetworkConfig.MaxRetries; attempts++ {
-			if attempts > 0 {
-				// Log retry attempt
-				bifrost.logger.Info("retrying request (attempt %d/%d) for model %s: %s", attempts, config.NetworkConfig.MaxRetries, req.Model, bifrostError.Error.Message)
-
-				// Calculate and apply backoff
-				backoff := calculateBackoff(attempts-1, config)
-				time.Sleep(backoff)
-			}
-
-			bifrost.logger.Debug("attempting request for provider %s", provider.GetProviderKey())
-
-			// Attempt the request
-			if IsStreamRequestType(req.Type) {
-				stream, bifrostError = handleProviderStreamRequest(provider, &req, key, postHookRunner, req.Type)
-				if bifrostError != nil && !bifrostError.IsBifrostError {
-					break // Don't retry client errors
-				}
-			} else {
-				result, bifrostError = handleProviderRequest(provider, &req, key, req.Type)
-				if bifrostError != nil {
-					break // Don't retry client errors
-				}
-			}
-
-			bifrost.logger.Debug("request for provider %s completed", provider.GetProviderKey())
-
-			// Check if successful or if we should retry
-			if bifrostError == nil ||
-				bifrostError.IsBifrostError ||
-				(bifrostError.StatusCode != nil && !retryableStatusCodes[*bifrostError.StatusCode]) ||
-				(bifrostError.Error.Type != nil && *bifrostError.Error.Type == schemas.RequestCancelled) {
-				break
-			}
+		// Call the provider through the middleware chain, which handles slimming, retries,
+		// timeouts and panics
+		call := &schemas.ProviderCall{
+			Context:        req.Context,
+			Type:           req.Type,
+			Provider:       provider.GetProviderKey(),
+			Config:         config,
+			Model:          req.Model,
+			Key:            key,
+			Input:          req.Input,
+			Params:         req.Params,
+			PostHookRunner: postHookRunner,
+		}
+		if IsStreamRequestType(req.Type) {
+			stream, bifrostError = caller.get().CallStream(call)
+		} else {
+			result, bifrostError = caller.get().Call(call)
 		}
 
+		tracker.observe(time.Since(serviceStart))
+
 		if bifrostError != nil {
-			// Add retry information to error
-			if attempts > 0 {
-				bifrost.logger.Warn("request failed after %d %s", attempts, map[bool]string{true: "retries", false: "retry"}[attempts > 1])
-			}
 			// Send error with context awareness to prevent deadlock
 			select {
 			case req.Err <- *bifrostError:
@@ -1294,8 +1676,9 @@ func (bifrost *Bifrost) requestWorker(provider schemas.Provider, config *schemas
 }
 
 // handleProviderRequest handles the request to the provider based on the request type
-func handleProviderRequest(provider schemas.Provider, req *ChannelMessage, key schemas.Key, reqType schemas.RequestType) (*schemas.BifrostResponse, *schemas.BifrostError) {
-	switch reqType {
+func handleProviderRequest(provider schemas.Provider, req *schemas.ProviderCall) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	key := req.Key
+	switch req.Type {
 	case schemas.TextCompletionRequest:
 		return provider.TextCompletion(req.Context, req.Model, key, *req.Input.TextCompletionInput, req.Params)
 	case schemas.ChatCompletionRequest:
@@ -1306,19 +1689,33 @@ func handleProviderRequest(provider schemas.Provider, req *ChannelMessage, key s
 		return provider.Speech(req.Context, req.Model, key, req.Input.SpeechInput, req.Params)
 	case schemas.TranscriptionRequest:
 		return provider.Transcription(req.Context, req.Model, key, req.Input.TranscriptionInput, req.Params)
+	case schemas.TranslationRequest:
+		return provider.Translation(req.Context, req.Model, key, req.Input.TranscriptionInput, req.Params)
+	case schemas.ImageGenerationRequest:
+		return provider.ImageGeneration(req.Context, req.Model, key, req.Input.ImageInput, req.Params)
+	case schemas.VideoGenerationRequest:
+		return provider.VideoGeneration(req.Context, req.Model, key, req.Input.VideoInput, req.Params)
+	case schemas.VideoStatusRequest:
+		return provider.VideoStatus(req.Context, req.Model, key, req.Input.VideoJobInput)
+	case schemas.TokenCountRequest:
+		return provider.CountTokens(req.Context, req.Model, key, *req.Input.ChatCompletionInput, req.Params)
+	case schemas.RerankRequest:
+		return provider.Rerank(req.Context, req.Model, key, req.Input.RerankInput, req.Params)
 	default:
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
 			Error: schemas.ErrorField{
-				Message: fmt.Sprintf("unsupported request type: %s", reqType),
+				Message: fmt.Sprintf("unsupported request type: %s", req.Type),
 			},
 		}
 	}
 }
 
 // handleProviderStreamRequest handles the stream request to the provider based on the request type
-func handleProviderStreamRequest(provider schemas.Provider, req *ChannelMessage, key schemas.Key, postHookRunner schemas.PostHookRunner, reqType schemas.RequestType) (chan *schemas.BifrostStream, *schemas.BifrostError) {
-	switch reqType {
+func handleProviderStreamRequest(provider schemas.Provider, req *schemas.ProviderCall) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	key, postHookRunner := req.Key, req.PostHookRunner
+	switch req.Type {
 	case schemas.ChatCompletionStreamRequest:
 		return provider.ChatCompletionStream(req.Context, postHookRunner, req.Model, key, *req.Input.ChatCompletionInput, req.Params)
 	case schemas.SpeechStreamRequest:
@@ -1328,8 +1725,9 @@ func handleProviderStreamRequest(provider schemas.Provider, req *ChannelMessage,
 	default:
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginClientRequest,
 			Error: schemas.ErrorField{
-				Message: fmt.Sprintf("unsupported request type: %s", reqType),
+				Message: fmt.Sprintf("unsupported request type: %s", req.Type),
 			},
 		}
 	}
@@ -1342,7 +1740,7 @@ func (p *PluginPipeline) RunPreHooks(ctx *context.Context, req *schemas.BifrostR
 	var shortCircuit *schemas.PluginShortCircuit
 	var err error
 	for i, plugin := range p.plugins {
-		req, shortCircuit, err = plugin.PreHook(ctx, req)
+		req, shortCircuit, err = p.runPreHook(plugin, ctx, req)
 		if err != nil {
 			p.preHookErrors = append(p.preHookErrors, err)
 			p.logger.Warn("error in PreHook for plugin %s: %v", plugin.GetName(), err)
@@ -1369,7 +1767,7 @@ func (p *PluginPipeline) RunPostHooks(ctx *context.Context, resp *schemas.Bifros
 	var err error
 	for i := count - 1; i >= 0; i-- {
 		plugin := p.plugins[i]
-		resp, bifrostErr, err = plugin.PostHook(ctx, resp, bifrostErr)
+		resp, bifrostErr, err = p.runPostHook(plugin, ctx, resp, bifrostErr)
 		if err != nil {
 			p.postHookErrors = append(p.postHookErrors, err)
 			p.logger.Warn("error in PostHook for plugin %s: %v", plugin.GetName(), err)
@@ -1471,11 +1869,13 @@ func (bifrost *Bifrost) releaseChannelMessage(msg *ChannelMessage) {
 	msg.Response = nil
 	msg.ResponseStream = nil
 	msg.Err = nil
+	msg.queueWait = nil
 	bifrost.channelMessagePool.Put(msg)
 }
 
 // selectKeyFromProviderForModel selects an appropriate API key for a given provider and model.
-// It uses weighted random selection if multiple keys are available.
+// It prefers the key pinned by session affinity, then the key the routing key of the request
+// hashes to, and otherwise uses weighted random selection if multiple keys are available.
 func (bifrost *Bifrost) selectKeyFromProviderForModel(ctx *context.Context, providerKey schemas.ModelProvider, model string, baseProviderType schemas.ModelProvider) (schemas.Key, error) {
 	// Check if key has been set in the context explicitly
 	if ctx != nil {
@@ -1485,6 +1885,16 @@ func (bifrost *Bifrost) selectKeyFromProviderForModel(ctx *context.Context, prov
 		}
 	}
 
+	key, err := bifrost.selectSupportedKey(ctx, providerKey, model, baseProviderType)
+	if err == nil {
+		getAffinityKeySelection(ctx).record(providerKey, key.ID)
+	}
+	return key, err
+}
+
+// selectSupportedKey selects a key of the provider that supports the model.
+func (bifrost *Bifrost) selectSupportedKey(ctx *context.Context, providerKey schemas.ModelProvider, model string, baseProviderType schemas.ModelProvider) (schemas.Key, error) {
+
 	keys, err := bifrost.account.GetKeysForProvider(ctx, providerKey)
 	if err != nil {
 		return schemas.Key{}, err
@@ -1529,6 +1939,20 @@ func (bifrost *Bifrost) selectKeyFromProviderForModel(ctx *context.Context, prov
 		return supportedKeys[0], nil
 	}
 
+	// Keep the session on the key whose prompt cache it has warmed up, unless the key was drained
+	if preferredKeyID := getAffinityKeySelection(ctx).preferred(providerKey); preferredKeyID != "" {
+		if i := slices.IndexFunc(supportedKeys, func(key schemas.Key) bool { return key.ID == preferredKeyID && key.Weight > 0 }); i >= 0 {
+			return supportedKeys[i], nil
+		}
+	}
+
+	// Send the same user or session to the same key, for cache locality on the backend behind it
+	if routingKey := getRoutingKey(ctx); routingKey != "" {
+		if key, ok := bifrost.stickyRouter.selectKey(providerKey, model, routingKey, supportedKeys); ok {
+			return key, nil
+		}
+	}
+
 	// Use a weighted random selection based on key weights
 	totalWeight := 0
 	for _, key := range supportedKeys {
@@ -1555,6 +1979,10 @@ func (bifrost *Bifrost) selectKeyFromProviderForModel(ctx *context.Context, prov
 // Shutdown gracefully stops all workers when triggered.
 // It closes all request channels and waits for workers to exit.
 func (bifrost *Bifrost) Shutdown() {
+	// Stop background embedding migration before the provider queues it uses are closed
+	bifrost.embeddingMigrator.stopAndWait()
+	bifrost.warmUp.stopAndWait()
+
 	bifrost.logger.Info("closing all request channels...")
 
 	// Close all provider queues to signal workers to stop
@@ -1585,4 +2013,7 @@ func (bifrost *Bifrost) Shutdown() {
 			bifrost.logger.Warn(fmt.Sprintf("Error cleaning up plugin: %s", err.Error()))
 		}
 	}
+
+	// End the event subscriptions
+	bifrost.events.close()
 }
@@ -0,0 +1,412 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// BULK RUNS
+// ============================================================================
+
+const (
+	// DefaultBulkConcurrency is the number of items run at once when BulkRequest.Concurrency is not set.
+	DefaultBulkConcurrency = 8
+	// DefaultBulkMaxRetries is the number of retries of a failed item when BulkRequest.MaxRetries is not set.
+	DefaultBulkMaxRetries = 2
+	// DefaultBulkRetryBackoff is the wait before the first retry when BulkRequest.RetryBackoff is not set.
+	DefaultBulkRetryBackoff = time.Second
+	// maxBulkRetryBackoff caps the doubling backoff of retries and rate limited targets.
+	maxBulkRetryBackoff = time.Minute
+)
+
+// BulkRequest describes a batch of requests to run concurrently, such as one prompt per row of a
+// dataset. Every item is a request of its own, going through the plugins, key selection and
+// fallbacks of the client.
+type BulkRequest struct {
+	// One request per item, chat completion, text completion or embedding depending on its input.
+	// Provider and Model may be left empty when Targets are given.
+	Requests []*schemas.BifrostRequest
+	// Providers and models the items are spread over, round robin. A target rate limited with a
+	// 429 cools down, and the items meanwhile go to the other targets. If empty, items run on the
+	// provider and model of their request.
+	Targets     []schemas.Fallback
+	Concurrency int // Items run at once, DefaultBulkConcurrency if 0
+	// Retries of an item failing with a retryable error (rate limits, server and network errors),
+	// DefaultBulkMaxRetries if 0, none if negative.
+	MaxRetries   int
+	RetryBackoff time.Duration // Wait before the first retry, doubled for every next one, DefaultBulkRetryBackoff if 0
+	// Total tokens the run may use. Items not started by the time it is reached are skipped.
+	// 0 for no limit. A budget exhausted in the governance plugin (402) also skips the remaining items.
+	TokenBudget int
+	// Called as each item ends, from the goroutine that ran it.
+	OnResult func(BulkResult)
+}
+
+// BulkItemStatus is the outcome of an item of a bulk run.
+type BulkItemStatus string
+
+const (
+	BulkItemSucceeded BulkItemStatus = "succeeded"
+	BulkItemFailed    BulkItemStatus = "failed"
+	BulkItemSkipped   BulkItemStatus = "skipped" // Not run, the budget was exhausted or the context cancelled
+)
+
+// BulkResult is the outcome of an item of a bulk run.
+type BulkResult struct {
+	Index    int                      `json:"index"` // Index of the item in BulkRequest.Requests
+	Status   BulkItemStatus           `json:"status"`
+	Provider schemas.ModelProvider    `json:"provider,omitempty"` // Target of the last attempt
+	Model    string                   `json:"model,omitempty"`
+	Attempts int                      `json:"attempts"`
+	Latency  time.Duration            `json:"latency,omitempty"` // Of all attempts and the waits between them
+	Response *schemas.BifrostResponse `json:"response,omitempty"`
+	Error    *schemas.BifrostError    `json:"error,omitempty"` // Error of the last attempt, or why the item was skipped
+}
+
+// BulkResponse is the result of a bulk run, with the results in the order of the requests.
+type BulkResponse struct {
+	Results   []BulkResult     `json:"results"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Skipped   int              `json:"skipped"`
+	Usage     schemas.LLMUsage `json:"usage"` // Total of the succeeded items
+	Duration  time.Duration    `json:"duration"`
+}
+
+// bulkTarget is a target of a bulk run and its rate limit cooldown.
+type bulkTarget struct {
+	provider      schemas.ModelProvider
+	model         string
+	cooldownUntil time.Time
+	rateLimits    int // Consecutive rate limited attempts, doubling the cooldown
+}
+
+// bulkRun is the state shared by the items of a bulk run.
+type bulkRun struct {
+	bifrost      *Bifrost
+	req          *BulkRequest
+	maxRetries   int
+	retryBackoff time.Duration
+
+	mu              sync.Mutex
+	targets         []*bulkTarget
+	budgetExhausted bool
+
+	tokensUsed atomic.Int64
+}
+
+// BulkRun runs a batch of requests concurrently and returns the outcome of every item. Items
+// failing with a retryable error are retried with backoff, rate limited targets cool down while
+// the other targets take over, and the run stops starting items once its token budget or a
+// governance budget is exhausted. An error is only returned for an invalid bulk request; the
+// errors of items are in their results.
+//
+// Parameters:
+//   - ctx: Context for the run, cancelling it skips the items not started yet
+//   - req: The requests and how to run them
+//
+// Returns:
+//   - *BulkResponse: The result of every item and the totals of the run
+//   - *schemas.BifrostError: Error if the bulk request is invalid
+//
+// Example:
+//
+//	response, err := client.BulkRun(ctx, bifrost.BulkRequest{
+//	    Requests: requests, // One chat completion request per prompt
+//	    Targets: []schemas.Fallback{
+//	        {Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+//	        {Provider: schemas.Anthropic, Model: "claude-3-5-haiku-latest"},
+//	    },
+//	    Concurrency: 16,
+//	    TokenBudget: 2_000_000,
+//	})
+func (bifrost *Bifrost) BulkRun(ctx context.Context, req BulkRequest) (*BulkResponse, *schemas.BifrostError) {
+	if req.Concurrency < 0 {
+		return nil, newBifrostErrorFromMsg("concurrency cannot be negative", schemas.ErrorOriginClientRequest)
+	}
+	if req.TokenBudget < 0 {
+		return nil, newBifrostErrorFromMsg("token budget cannot be negative", schemas.ErrorOriginClientRequest)
+	}
+	for i, request := range req.Requests {
+		if request == nil {
+			return nil, newBifrostErrorFromMsg(fmt.Sprintf("request %d is nil", i), schemas.ErrorOriginClientRequest)
+		}
+		if _, err := bulkRequestType(request); err != nil {
+			return nil, newBifrostErrorFromMsg(fmt.Sprintf("request %d: %v", i, err), schemas.ErrorOriginClientRequest)
+		}
+		if len(req.Targets) == 0 && (request.Provider == "" || request.Model == "") {
+			return nil, newBifrostErrorFromMsg(fmt.Sprintf("request %d has no provider or model and no targets are given", i), schemas.ErrorOriginClientRequest)
+		}
+	}
+
+	run := &bulkRun{
+		bifrost:      bifrost,
+		req:          &req,
+		maxRetries:   req.MaxRetries,
+		retryBackoff: req.RetryBackoff,
+	}
+	if run.maxRetries == 0 {
+		run.maxRetries = DefaultBulkMaxRetries
+	} else if run.maxRetries < 0 {
+		run.maxRetries = 0
+	}
+	if run.retryBackoff <= 0 {
+		run.retryBackoff = DefaultBulkRetryBackoff
+	}
+	for _, target := range req.Targets {
+		run.targets = append(run.targets, &bulkTarget{provider: target.Provider, model: target.Model})
+	}
+	concurrency := req.Concurrency
+	if concurrency == 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	start := time.Now()
+	response := &BulkResponse{Results: make([]BulkResult, len(req.Requests))}
+	items := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(req.Requests)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range items {
+				result := run.runItem(ctx, index)
+				response.Results[index] = result
+				if req.OnResult != nil {
+					req.OnResult(result)
+				}
+			}
+		}()
+	}
+	for i := range req.Requests {
+		items <- i
+	}
+	close(items)
+	wg.Wait()
+
+	for _, result := range response.Results {
+		switch result.Status {
+		case BulkItemSucceeded:
+			response.Succeeded++
+			if result.Response != nil && result.Response.Usage != nil {
+				response.Usage.PromptTokens += result.Response.Usage.PromptTokens
+				response.Usage.CompletionTokens += result.Response.Usage.CompletionTokens
+				response.Usage.TotalTokens += result.Response.Usage.TotalTokens
+			}
+		case BulkItemFailed:
+			response.Failed++
+		case BulkItemSkipped:
+			response.Skipped++
+		}
+	}
+	response.Duration = time.Since(start)
+	return response, nil
+}
+
+// runItem runs an item until it succeeds, fails with an error that is not retried, or runs out
+// of retries.
+func (run *bulkRun) runItem(ctx context.Context, index int) (result BulkResult) {
+	result.Index = index
+	start := time.Now()
+	defer func() { result.Latency = time.Since(start) }()
+
+	for attempt := 0; ; attempt++ {
+		if skipErr := run.skipError(ctx); skipErr != nil {
+			if attempt == 0 {
+				result.Status = BulkItemSkipped
+				result.Error = skipErr
+			} else {
+				result.Status = BulkItemFailed
+			}
+			return result
+		}
+
+		target, err := run.acquireTarget(ctx, index+attempt)
+		if err != nil {
+			result.Status = BulkItemFailed
+			if attempt == 0 {
+				result.Status = BulkItemSkipped
+				result.Error = newBifrostError(err, schemas.ErrorOriginClientRequest)
+			}
+			return result
+		}
+
+		request := *run.req.Requests[index]
+		if target != nil {
+			request.Provider = target.provider
+			request.Model = target.model
+		}
+		result.Provider = request.Provider
+		result.Model = request.Model
+		result.Attempts++
+
+		response, bifrostErr := run.attempt(ctx, &request)
+		run.release(target, bifrostErr)
+		if bifrostErr == nil {
+			if response != nil && response.Usage != nil {
+				run.tokensUsed.Add(int64(response.Usage.TotalTokens))
+			}
+			result.Status = BulkItemSucceeded
+			result.Response = response
+			result.Error = nil
+			return result
+		}
+
+		result.Error = bifrostErr
+		if isBudgetExhaustedError(bifrostErr) {
+			run.mu.Lock()
+			run.budgetExhausted = true
+			run.mu.Unlock()
+		}
+		if attempt >= run.maxRetries || !isRetryableError(bifrostErr) || ctx.Err() != nil {
+			result.Status = BulkItemFailed
+			return result
+		}
+		// Rate limited targets cool down instead, the next attempt goes to another target
+		if !isRateLimitError(bifrostErr) || target == nil {
+			if err := sleepWithContext(ctx, bulkBackoff(run.retryBackoff, attempt)); err != nil {
+				result.Status = BulkItemFailed
+				return result
+			}
+		}
+	}
+}
+
+// attempt sends a request of an item.
+func (run *bulkRun) attempt(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	requestType, _ := bulkRequestType(req)
+	switch requestType {
+	case schemas.TextCompletionRequest:
+		return run.bifrost.TextCompletionRequest(ctx, req)
+	case schemas.EmbeddingRequest:
+		return run.bifrost.EmbeddingRequest(ctx, req)
+	default:
+		return run.bifrost.ChatCompletionRequest(ctx, req)
+	}
+}
+
+// skipError returns why items can no longer be started, nil if they can.
+func (run *bulkRun) skipError(ctx context.Context) *schemas.BifrostError {
+	if ctx.Err() != nil {
+		return newBifrostError(ctx.Err(), schemas.ErrorOriginClientRequest)
+	}
+	run.mu.Lock()
+	budgetExhausted := run.budgetExhausted
+	run.mu.Unlock()
+	if budgetExhausted {
+		return newBifrostErrorFromMsg("budget exhausted", schemas.ErrorOriginPolicy)
+	}
+	if run.req.TokenBudget > 0 && run.tokensUsed.Load() >= int64(run.req.TokenBudget) {
+		return newBifrostErrorFromMsg(fmt.Sprintf("token budget of %d exhausted", run.req.TokenBudget), schemas.ErrorOriginPolicy)
+	}
+	return nil
+}
+
+// acquireTarget returns the target of an attempt, starting the round robin at offset and skipping
+// the targets cooling down. If all of them are, it waits for the first to be available again.
+// It returns nil if the run has no targets.
+func (run *bulkRun) acquireTarget(ctx context.Context, offset int) (*bulkTarget, error) {
+	if len(run.targets) == 0 {
+		return nil, nil
+	}
+	for {
+		run.mu.Lock()
+		now := time.Now()
+		var next time.Time
+		for i := range run.targets {
+			target := run.targets[(offset+i)%len(run.targets)]
+			if !target.cooldownUntil.After(now) {
+				run.mu.Unlock()
+				return target, nil
+			}
+			if next.IsZero() || target.cooldownUntil.Before(next) {
+				next = target.cooldownUntil
+			}
+		}
+		run.mu.Unlock()
+
+		if err := sleepWithContext(ctx, time.Until(next)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// release records the outcome of an attempt on its target: a rate limit starts or extends its
+// cooldown, a success ends its backoff.
+func (run *bulkRun) release(target *bulkTarget, bifrostErr *schemas.BifrostError) {
+	if target == nil {
+		return
+	}
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	switch {
+	case bifrostErr == nil:
+		target.rateLimits = 0
+	case isRateLimitError(bifrostErr):
+		cooldownUntil := time.Now().Add(bulkBackoff(run.retryBackoff, target.rateLimits))
+		if cooldownUntil.After(target.cooldownUntil) {
+			target.cooldownUntil = cooldownUntil
+		}
+		target.rateLimits++
+		run.bifrost.logger.Debug(fmt.Sprintf("bulk run: %s/%s rate limited, cooling down until %s", target.provider, target.model, target.cooldownUntil.Format(time.RFC3339)))
+	}
+}
+
+// bulkRequestType returns the request type of an item from its input.
+func bulkRequestType(req *schemas.BifrostRequest) (schemas.RequestType, error) {
+	switch {
+	case req.Input.ChatCompletionInput != nil:
+		return schemas.ChatCompletionRequest, nil
+	case req.Input.TextCompletionInput != nil:
+		return schemas.TextCompletionRequest, nil
+	case req.Input.EmbeddingInput != nil:
+		return schemas.EmbeddingRequest, nil
+	default:
+		return "", fmt.Errorf("bulk runs support chat completion, text completion and embedding inputs")
+	}
+}
+
+// bulkBackoff returns the wait before retry number attempt+1, doubling from initial.
+func bulkBackoff(initial time.Duration, attempt int) time.Duration {
+	backoff := initial
+	for range attempt {
+		backoff *= 2
+		if backoff >= maxBulkRetryBackoff {
+			return maxBulkRetryBackoff
+		}
+	}
+	return backoff
+}
+
+// isRateLimitError reports whether a request was rejected by a rate limit, of the provider or
+// of the governance plugin.
+func isRateLimitError(bifrostErr *schemas.BifrostError) bool {
+	return bifrostErr.StatusCode != nil && *bifrostErr.StatusCode == 429
+}
+
+// isBudgetExhaustedError reports whether a request was rejected for an exhausted budget, as the
+// governance plugin does with a 402.
+func isBudgetExhaustedError(bifrostErr *schemas.BifrostError) bool {
+	return bifrostErr.StatusCode != nil && *bifrostErr.StatusCode == 402
+}
+
+// sleepWithContext waits for d, or returns the error of ctx if it ends first.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
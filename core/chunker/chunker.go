@@ -0,0 +1,231 @@
+// Package chunker splits raw documents into token bounded, optionally overlapping chunks
+// for RAG ingestion. Splits prefer structural boundaries (markdown headings and fenced
+// code blocks, paragraphs, lines, sentences) and only fall back to words when needed.
+package chunker
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Mode selects which boundaries are preferred when splitting.
+type Mode string
+
+const (
+	ModeText     Mode = "text"     // Paragraphs, then sentences, then words
+	ModeMarkdown Mode = "markdown" // Headings and fenced code blocks, then paragraphs, sentences and words
+	ModeCode     Mode = "code"     // Blank line separated blocks, then lines, then words
+)
+
+// DefaultMaxTokens is used when Config.MaxTokens is not set.
+const DefaultMaxTokens = 512
+
+// TokenCounter returns the number of tokens of a text.
+type TokenCounter func(text string) int
+
+// Config configures how documents are chunked.
+type Config struct {
+	MaxTokens     int          // Maximum tokens per chunk, DefaultMaxTokens if 0
+	OverlapTokens int          // Tokens of trailing context repeated at the start of the next chunk
+	Mode          Mode         // Boundary preference, ModeText if empty
+	CountTokens   TokenCounter // Tokenizer of the embedding model, ApproximateTokenCount if nil
+}
+
+// Chunk is a contiguous piece of a document.
+type Chunk struct {
+	Index   int    `json:"index"`
+	Text    string `json:"text"`
+	Start   int    `json:"start"` // Byte offset of the chunk in the document (inclusive)
+	End     int    `json:"end"`   // Byte offset of the chunk in the document (exclusive)
+	Tokens  int    `json:"tokens"`
+	Heading string `json:"heading,omitempty"` // Closest markdown heading before the chunk, ModeMarkdown only
+}
+
+// ApproximateTokenCount estimates tokens as one per four characters, which is close
+// enough for English text with BPE tokenizers when the exact tokenizer is not available.
+func ApproximateTokenCount(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// separators lists, per mode, the boundaries tried from coarsest to finest.
+// Each separator is kept at the end of the segment that precedes it.
+var separators = map[Mode][][]string{
+	ModeText:     {{"\n\n"}, {"\n"}, {". ", "! ", "? ", "; "}, {" "}},
+	ModeMarkdown: {{"\n\n"}, {"\n"}, {". ", "! ", "? ", "; "}, {" "}},
+	ModeCode:     {{"\n\n"}, {"\n"}, {" "}},
+}
+
+// span is a [start, end) byte range of the document with its token count.
+type span struct {
+	start, end int
+	tokens     int
+}
+
+// splitter holds the state of a single Split call.
+type splitter struct {
+	text   string
+	config Config
+	levels [][]string
+}
+
+// Split splits text into chunks according to config.
+func Split(text string, config Config) ([]Chunk, error) {
+	if config.MaxTokens == 0 {
+		config.MaxTokens = DefaultMaxTokens
+	}
+	if config.Mode == "" {
+		config.Mode = ModeText
+	}
+	if config.CountTokens == nil {
+		config.CountTokens = ApproximateTokenCount
+	}
+	if config.MaxTokens < 0 {
+		return nil, fmt.Errorf("max tokens cannot be negative")
+	}
+	if config.OverlapTokens < 0 || config.OverlapTokens >= config.MaxTokens {
+		return nil, fmt.Errorf("overlap tokens must be between 0 and max tokens (%d), got %d", config.MaxTokens, config.OverlapTokens)
+	}
+	levels, ok := separators[config.Mode]
+	if !ok {
+		return nil, fmt.Errorf("unsupported chunking mode: %s", config.Mode)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	s := &splitter{text: text, config: config, levels: levels}
+
+	var segments []span
+	if config.Mode == ModeMarkdown {
+		for _, block := range markdownBlocks(text) {
+			segments = append(segments, s.segments(block.start, block.end, 0)...)
+		}
+	} else {
+		segments = s.segments(0, len(text), 0)
+	}
+
+	chunks := s.pack(segments)
+	if config.Mode == ModeMarkdown {
+		headings := markdownHeadings(text)
+		for i := range chunks {
+			chunks[i].Heading = headingAt(headings, chunks[i].Start)
+		}
+	}
+	return chunks, nil
+}
+
+// segments splits [start, end) into spans that each fit in MaxTokens, using the separators
+// from the given level onwards.
+func (s *splitter) segments(start, end, level int) []span {
+	tokens := s.config.CountTokens(s.text[start:end])
+	if tokens <= s.config.MaxTokens {
+		return []span{{start: start, end: end, tokens: tokens}}
+	}
+	if level >= len(s.levels) {
+		return s.hardSplit(start, end)
+	}
+
+	pieces := cutAfter(s.text, start, end, s.levels[level])
+	if len(pieces) == 1 {
+		return s.segments(start, end, level+1)
+	}
+
+	var result []span
+	for _, piece := range pieces {
+		result = append(result, s.segments(piece.start, piece.end, level+1)...)
+	}
+	return result
+}
+
+// hardSplit splits a span without any usable separator (e.g. a very long token) by runes.
+func (s *splitter) hardSplit(start, end int) []span {
+	var result []span
+	for start < end {
+		// Grow the piece one rune at a time until it would exceed the limit
+		pieceEnd := start
+		for pieceEnd < end {
+			_, size := utf8.DecodeRuneInString(s.text[pieceEnd:end])
+			if pieceEnd > start && s.config.CountTokens(s.text[start:pieceEnd+size]) > s.config.MaxTokens {
+				break
+			}
+			pieceEnd += size
+		}
+		result = append(result, span{start: start, end: pieceEnd, tokens: s.config.CountTokens(s.text[start:pieceEnd])})
+		start = pieceEnd
+	}
+	return result
+}
+
+// pack greedily merges consecutive segments into chunks of at most MaxTokens, starting each
+// chunk with the trailing segments of the previous one that fit in OverlapTokens.
+func (s *splitter) pack(segments []span) []Chunk {
+	var chunks []Chunk
+
+	first := 0
+	for first < len(segments) {
+		last := first
+		tokens := segments[first].tokens
+		for last+1 < len(segments) && tokens+segments[last+1].tokens <= s.config.MaxTokens {
+			last++
+			tokens += segments[last].tokens
+		}
+
+		start, end := segments[first].start, segments[last].end
+		chunkText := s.text[start:end]
+		if strings.TrimSpace(chunkText) != "" {
+			chunks = append(chunks, Chunk{
+				Index:  len(chunks),
+				Text:   chunkText,
+				Start:  start,
+				End:    end,
+				Tokens: s.config.CountTokens(chunkText),
+			})
+		}
+
+		if last+1 >= len(segments) {
+			break
+		}
+
+		// Walk back from the end of the chunk to find where the overlap starts,
+		// always moving forward by at least one segment
+		next := last + 1
+		overlap := 0
+		for next-1 > first && overlap+segments[next-1].tokens <= s.config.OverlapTokens {
+			next--
+			overlap += segments[next].tokens
+		}
+		first = next
+	}
+
+	return chunks
+}
+
+// cutAfter splits [start, end) after every occurrence of any of the separators.
+func cutAfter(text string, start, end int, seps []string) []span {
+	var result []span
+	pieceStart := start
+	for i := start; i < end; {
+		matched := 0
+		for _, sep := range seps {
+			if strings.HasPrefix(text[i:end], sep) {
+				matched = len(sep)
+				break
+			}
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		i += matched
+		// Absorb repeated separators (e.g. several blank lines) into the same piece
+		for i < end && strings.HasPrefix(text[i:end], seps[0]) {
+			i += len(seps[0])
+		}
+		if i < end {
+			result = append(result, span{start: pieceStart, end: i})
+			pieceStart = i
+		}
+	}
+	return append(result, span{start: pieceStart, end: end})
+}
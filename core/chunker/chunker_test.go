@@ -0,0 +1,169 @@
+package chunker
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// countWords counts whitespace separated words, which makes chunk sizes easy to reason about.
+func countWords(text string) int {
+	return len(strings.Fields(text))
+}
+
+func chunkTexts(chunks []Chunk) []string {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+	return texts
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		config Config
+		want   []string
+	}{
+		{
+			name:   "fits in one chunk",
+			text:   "a short document",
+			config: Config{MaxTokens: 10, CountTokens: countWords},
+			want:   []string{"a short document"},
+		},
+		{
+			name:   "words without overlap",
+			text:   "a b c d e f g h",
+			config: Config{MaxTokens: 3, CountTokens: countWords},
+			want:   []string{"a b c ", "d e f ", "g h"},
+		},
+		{
+			name:   "words with overlap",
+			text:   "a b c d e f g h",
+			config: Config{MaxTokens: 3, OverlapTokens: 1, CountTokens: countWords},
+			want:   []string{"a b c ", "c d e ", "e f g ", "g h"},
+		},
+		{
+			name:   "paragraphs before sentences",
+			text:   "one two. three four.\n\nfive six.",
+			config: Config{MaxTokens: 4, CountTokens: countWords},
+			want:   []string{"one two. three four.\n\n", "five six."},
+		},
+		{
+			name:   "sentences when a paragraph is too long",
+			text:   "one two. three four. five six.",
+			config: Config{MaxTokens: 4, CountTokens: countWords},
+			want:   []string{"one two. three four. ", "five six."},
+		},
+		{
+			name:   "code lines",
+			text:   "func a() {\n\treturn 1\n}\n",
+			config: Config{MaxTokens: 3, Mode: ModeCode, CountTokens: countWords},
+			want:   []string{"func a() {\n", "\treturn 1\n}\n"},
+		},
+		{
+			name:   "hard split of a long token",
+			text:   "abcdefghij",
+			config: Config{MaxTokens: 1},
+			want:   []string{"abcd", "efgh", "ij"},
+		},
+		{
+			name:   "blank document",
+			text:   " \n\n ",
+			config: Config{MaxTokens: 3, CountTokens: countWords},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := Split(tt.text, tt.config)
+			if err != nil {
+				t.Fatalf("Split() error = %v", err)
+			}
+			if got := chunkTexts(chunks); !slices.Equal(got, tt.want) {
+				t.Fatalf("Split() = %q, want %q", got, tt.want)
+			}
+
+			countTokens := tt.config.CountTokens
+			if countTokens == nil {
+				countTokens = ApproximateTokenCount
+			}
+			for i, chunk := range chunks {
+				if chunk.Index != i {
+					t.Errorf("chunk %d has index %d", i, chunk.Index)
+				}
+				if tt.text[chunk.Start:chunk.End] != chunk.Text {
+					t.Errorf("chunk %d offsets [%d, %d) do not match its text %q", i, chunk.Start, chunk.End, chunk.Text)
+				}
+				if chunk.Tokens != countTokens(chunk.Text) || chunk.Tokens > tt.config.MaxTokens {
+					t.Errorf("chunk %d has %d tokens, max %d", i, chunk.Tokens, tt.config.MaxTokens)
+				}
+				if i > 0 && chunk.Start < chunks[i-1].Start {
+					t.Errorf("chunk %d starts before the previous chunk", i)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitOverlap(t *testing.T) {
+	text := strings.Repeat("word ", 100)
+	chunks, err := Split(text, Config{MaxTokens: 10, OverlapTokens: 3, CountTokens: countWords})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(chunks); i++ {
+		previous, chunk := chunks[i-1], chunks[i]
+		overlap := countWords(text[chunk.Start:min(previous.End, chunk.End)])
+		if chunk.Start >= previous.End || overlap != 3 {
+			t.Errorf("chunk %d overlaps the previous chunk by %d tokens, want 3", i, overlap)
+		}
+	}
+	if last := chunks[len(chunks)-1]; last.End != len(text) {
+		t.Errorf("last chunk ends at %d, want %d", last.End, len(text))
+	}
+}
+
+func TestSplitMarkdown(t *testing.T) {
+	text := "# Intro\nSome intro text.\n\n## Setup\n```sh\nmake build\nmake test\n```\nDone.\n"
+	chunks, err := Split(text, Config{MaxTokens: 6, Mode: ModeMarkdown, CountTokens: countWords})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fence *Chunk
+	for i, chunk := range chunks {
+		if strings.Contains(chunk.Text, "```") {
+			fence = &chunks[i]
+			break
+		}
+	}
+	if fence == nil || !strings.Contains(fence.Text, "```sh\nmake build\nmake test\n```\n") {
+		t.Fatalf("fenced code block is split across chunks: %q", chunkTexts(chunks))
+	}
+	if fence.Heading != "Setup" {
+		t.Errorf("code block heading = %q, want Setup", fence.Heading)
+	}
+	if chunks[0].Heading != "Intro" {
+		t.Errorf("first chunk heading = %q, want Intro", chunks[0].Heading)
+	}
+}
+
+func TestSplitConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "negative max tokens", config: Config{MaxTokens: -1}},
+		{name: "overlap equal to max tokens", config: Config{MaxTokens: 10, OverlapTokens: 10}},
+		{name: "negative overlap", config: Config{MaxTokens: 10, OverlapTokens: -1}},
+		{name: "unknown mode", config: Config{Mode: "html"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Split("some text", tt.config); err == nil {
+				t.Error("Split() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package chunker
+
+import (
+	"sort"
+	"strings"
+)
+
+// heading is a markdown heading line and its byte offset in the document.
+type heading struct {
+	offset int
+	text   string
+}
+
+// markdownLine is a line of a markdown document, including its trailing newline.
+type markdownLine struct {
+	start, end int
+	heading    bool // ATX heading outside of a code fence
+	fence      bool // Opening or closing code fence
+}
+
+// scanMarkdownLines classifies the lines of a markdown document.
+func scanMarkdownLines(text string) []markdownLine {
+	var lines []markdownLine
+	inFence := false
+	fenceMarker := ""
+
+	for start := 0; start < len(text); {
+		end := strings.IndexByte(text[start:], '\n')
+		if end == -1 {
+			end = len(text)
+		} else {
+			end += start + 1
+		}
+
+		line := markdownLine{start: start, end: end}
+		trimmed := strings.TrimLeft(text[start:end], " ")
+		switch {
+		case inFence:
+			if strings.HasPrefix(trimmed, fenceMarker) {
+				line.fence = true
+				inFence = false
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			line.fence = true
+			inFence = true
+			fenceMarker = trimmed[:3]
+		case strings.HasPrefix(trimmed, "#"):
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			rest := trimmed[level:]
+			line.heading = level <= 6 && (rest == "" || rest[0] == ' ' || rest[0] == '\n')
+		}
+
+		lines = append(lines, line)
+		start = end
+	}
+
+	return lines
+}
+
+// markdownBlocks splits a markdown document into blocks that start at every heading and
+// keep fenced code blocks together, so that chunks don't straddle sections or code.
+func markdownBlocks(text string) []span {
+	var blocks []span
+	blockStart := 0
+	inFence := false
+
+	cut := func(at int) {
+		if at > blockStart {
+			blocks = append(blocks, span{start: blockStart, end: at})
+			blockStart = at
+		}
+	}
+
+	for _, line := range scanMarkdownLines(text) {
+		switch {
+		case line.fence && !inFence:
+			cut(line.start)
+			inFence = true
+		case line.fence && inFence:
+			inFence = false
+			cut(line.end)
+		case line.heading:
+			cut(line.start)
+		}
+	}
+	cut(len(text))
+
+	return blocks
+}
+
+// markdownHeadings returns the headings of a markdown document in order.
+func markdownHeadings(text string) []heading {
+	var headings []heading
+	for _, line := range scanMarkdownLines(text) {
+		if line.heading {
+			headings = append(headings, heading{
+				offset: line.start,
+				text:   strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(text[line.start:line.end]), "#")),
+			})
+		}
+	}
+	return headings
+}
+
+// headingAt returns the closest heading at or before offset.
+func headingAt(headings []heading, offset int) string {
+	i := sort.Search(len(headings), func(i int) bool { return headings[i].offset > offset })
+	if i == 0 {
+		return ""
+	}
+	return headings[i-1].text
+}
@@ -0,0 +1,130 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/maximhq/bifrost/core/chunker"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/core/vecmath"
+)
+
+// DefaultEmbeddingBatchSize is the number of chunks sent per embedding request when
+// DocumentEmbeddingRequest.BatchSize is not set.
+const DefaultEmbeddingBatchSize = 96
+
+// DocumentEmbeddingRequest describes raw documents to be chunked and embedded.
+type DocumentEmbeddingRequest struct {
+	Provider  schemas.ModelProvider
+	Model     string
+	Documents []string
+	Chunking  chunker.Config           // Chunking options, set CountTokens to the embedding model's tokenizer when available
+	BatchSize int                      // Chunks per embedding request, DefaultEmbeddingBatchSize if 0
+	Params    *schemas.ModelParameters // Embedding parameters, e.g. Dimensions
+	Fallbacks []schemas.Fallback
+}
+
+// EmbeddedChunk is a chunk of a document together with its embedding.
+type EmbeddedChunk struct {
+	DocumentIndex int `json:"document_index"`
+	chunker.Chunk
+	Embedding []float32 `json:"embedding"`
+}
+
+// EmbedDocuments chunks raw documents and embeds every chunk, batching chunks into as few
+// embedding requests as possible. Chunks are returned in document order.
+//
+// Parameters:
+//   - ctx: Context for the embedding requests
+//   - req: Documents, chunking options and the embedding model to use
+//
+// Returns:
+//   - []EmbeddedChunk: Chunks with their embeddings
+//   - *schemas.BifrostError: Any chunking or embedding error
+//
+// Example:
+//
+//	chunks, err := client.EmbedDocuments(ctx, bifrost.DocumentEmbeddingRequest{
+//	    Provider:  schemas.OpenAI,
+//	    Model:     "text-embedding-3-small",
+//	    Documents: []string{readme, guide},
+//	    Chunking:  chunker.Config{MaxTokens: 400, OverlapTokens: 50, Mode: chunker.ModeMarkdown},
+//	})
+func (bifrost *Bifrost) EmbedDocuments(ctx context.Context, req DocumentEmbeddingRequest) ([]EmbeddedChunk, *schemas.BifrostError) {
+	if req.BatchSize < 0 {
+		return nil, newBifrostErrorFromMsg("batch size cannot be negative", schemas.ErrorOriginClientRequest)
+	}
+	batchSize := req.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultEmbeddingBatchSize
+	}
+
+	var chunks []EmbeddedChunk
+	for i, document := range req.Documents {
+		documentChunks, err := chunker.Split(document, req.Chunking)
+		if err != nil {
+			return nil, newBifrostError(fmt.Errorf("failed to chunk document %d: %w", i, err), schemas.ErrorOriginClientRequest)
+		}
+		for _, chunk := range documentChunks {
+			chunks = append(chunks, EmbeddedChunk{DocumentIndex: i, Chunk: chunk})
+		}
+	}
+
+	for start := 0; start < len(chunks); start += batchSize {
+		end := min(start+batchSize, len(chunks))
+
+		texts := make([]string, 0, end-start)
+		for _, chunk := range chunks[start:end] {
+			texts = append(texts, chunk.Text)
+		}
+
+		response, bifrostErr := bifrost.EmbeddingRequest(ctx, &schemas.BifrostRequest{
+			Provider:  req.Provider,
+			Model:     req.Model,
+			Input:     schemas.RequestInput{EmbeddingInput: &schemas.EmbeddingInput{Texts: texts}},
+			Params:    req.Params,
+			Fallbacks: req.Fallbacks,
+		})
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		// Embeddings are ordered by their index, the position of their text in the batch, as
+		// providers may return them in any order
+		if err := checkEmbeddingIndices(response.Data, len(texts)); err != nil {
+			return nil, newBifrostError(err, schemas.ErrorOriginProvider)
+		}
+		embeddings, err := vecmath.FromResponse(response)
+		if err != nil {
+			return nil, newBifrostError(err, schemas.ErrorOriginProvider)
+		}
+		if len(embeddings) != len(texts) {
+			return nil, newBifrostErrorFromMsg(fmt.Sprintf("expected %d embeddings, provider returned %d", len(texts), len(embeddings)), schemas.ErrorOriginProvider)
+		}
+		for i, embedding := range embeddings {
+			chunks[start+i].Embedding = embedding
+		}
+	}
+
+	return chunks, nil
+}
+
+// checkEmbeddingIndices checks that the indices of a batch's embeddings are the distinct
+// positions of its texts. Responses without indices, all 0, are taken in input order.
+func checkEmbeddingIndices(data []schemas.BifrostEmbedding, count int) error {
+	if !slices.ContainsFunc(data, func(embedding schemas.BifrostEmbedding) bool { return embedding.Index != 0 }) {
+		return nil
+	}
+	seen := make([]bool, count)
+	for _, embedding := range data {
+		if embedding.Index < 0 || embedding.Index >= count {
+			return fmt.Errorf("embedding index %d is out of range for a batch of %d texts", embedding.Index, count)
+		}
+		if seen[embedding.Index] {
+			return fmt.Errorf("embedding index %d is returned more than once", embedding.Index)
+		}
+		seen[embedding.Index] = true
+	}
+	return nil
+}
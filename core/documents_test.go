@@ -0,0 +1,113 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/chunker"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// upstreamAccount serves OpenAI requests from a test server.
+type upstreamAccount struct {
+	baseURL string
+}
+
+func (a *upstreamAccount) GetConfiguredProviders() ([]schemas.ModelProvider, error) {
+	return []schemas.ModelProvider{schemas.OpenAI}, nil
+}
+
+func (a *upstreamAccount) GetKeysForProvider(ctx *context.Context, providerKey schemas.ModelProvider) ([]schemas.Key, error) {
+	return []schemas.Key{{Value: "test-key", Models: []string{}, Weight: 1.0}}, nil
+}
+
+func (a *upstreamAccount) GetConfigForProvider(providerKey schemas.ModelProvider) (*schemas.ProviderConfig, error) {
+	networkConfig := schemas.DefaultNetworkConfig
+	networkConfig.BaseURL = a.baseURL
+	return &schemas.ProviderConfig{
+		NetworkConfig:            networkConfig,
+		ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+	}, nil
+}
+
+func TestEmbedDocumentsMatchesEmbeddingsByIndex(t *testing.T) {
+	// The server embeds each text as its length and returns the embeddings in reverse order
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data := make([]map[string]interface{}, 0, len(req.Input))
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, map[string]interface{}{"object": "embedding", "index": i, "embedding": []float32{float32(len(req.Input[i]))}})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "model": "text-embedding-3-small", "data": data})
+	}))
+	defer server.Close()
+
+	client, err := Init(context.Background(), schemas.BifrostConfig{
+		Account: &upstreamAccount{baseURL: server.URL},
+		Logger:  NewDefaultLogger(schemas.LogLevelError),
+	})
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer client.Shutdown()
+
+	chunks, bifrostErr := client.EmbedDocuments(context.Background(), DocumentEmbeddingRequest{
+		Provider:  schemas.OpenAI,
+		Model:     "text-embedding-3-small",
+		Documents: []string{"a bb ccc dddd", "eeee"},
+		Chunking:  chunker.Config{MaxTokens: 1},
+		BatchSize: 3,
+	})
+	if bifrostErr != nil {
+		t.Fatalf("EmbedDocuments() error = %v", bifrostErr.Error.Message)
+	}
+	if len(chunks) != 5 {
+		t.Fatalf("got %d chunks, want 5", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if len(chunk.Embedding) != 1 || int(chunk.Embedding[0]) != len(chunk.Text) {
+			t.Errorf("chunk %d %q has embedding %v, want [%d]", i, chunk.Text, chunk.Embedding, len(chunk.Text))
+		}
+	}
+}
+
+func TestCheckEmbeddingIndices(t *testing.T) {
+	embeddings := func(indices ...int) []schemas.BifrostEmbedding {
+		data := make([]schemas.BifrostEmbedding, len(indices))
+		for i, index := range indices {
+			data[i].Index = index
+		}
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		data    []schemas.BifrostEmbedding
+		count   int
+		wantErr bool
+	}{
+		{name: "in order", data: embeddings(0, 1, 2), count: 3},
+		{name: "shuffled", data: embeddings(2, 0, 1), count: 3},
+		{name: "no indices", data: embeddings(0, 0, 0), count: 3},
+		{name: "out of range", data: embeddings(0, 3, 1), count: 3, wantErr: true},
+		{name: "negative", data: embeddings(0, -1), count: 2, wantErr: true},
+		{name: "duplicate", data: embeddings(0, 0, 2), count: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkEmbeddingIndices(tt.data, tt.count); (err != nil) != tt.wantErr {
+				t.Errorf("checkEmbeddingIndices() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package bifrost
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// drainPollInterval is how often a drain checks whether the in-flight requests have finished.
+const drainPollInterval = 50 * time.Millisecond
+
+// States of a provider, see schemas.ProviderState.
+const (
+	providerActive int32 = iota
+	providerDraining
+	providerInactive
+)
+
+var providerStates = map[int32]schemas.ProviderState{
+	providerActive:   schemas.ProviderStateActive,
+	providerDraining: schemas.ProviderStateDraining,
+	providerInactive: schemas.ProviderStateInactive,
+}
+
+// providerActivity tracks whether a provider accepts new requests and how many of its requests
+// are in flight, from before they are queued until their response or the end of their stream.
+type providerActivity struct {
+	state    atomic.Int32
+	inFlight atomic.Int64
+	lastUsed atomic.Int64 // Unix nanoseconds of the last request or warm-up, see providerWarmUp
+}
+
+func (bifrost *Bifrost) getProviderActivity(providerKey schemas.ModelProvider) *providerActivity {
+	if value, ok := bifrost.providerActivity.Load(providerKey); ok {
+		return value.(*providerActivity)
+	}
+	value, _ := bifrost.providerActivity.LoadOrStore(providerKey, &providerActivity{})
+	return value.(*providerActivity)
+}
+
+// acquireProvider counts a request in flight for its provider, or returns an error allowing
+// fallbacks if the provider is draining or inactive. Acquired requests must be released.
+func (bifrost *Bifrost) acquireProvider(providerKey schemas.ModelProvider) (*providerActivity, *schemas.BifrostError) {
+	activity := bifrost.getProviderActivity(providerKey)
+	// Counted before the state is checked, so a drain that sees no request in flight cannot miss one
+	activity.inFlight.Add(1)
+	activity.lastUsed.Store(time.Now().UnixNano())
+	if state := activity.state.Load(); state != providerActive {
+		activity.inFlight.Add(-1)
+		return nil, &schemas.BifrostError{
+			IsBifrostError: true,
+			Provider:       providerKey,
+			StatusCode:     Ptr(http.StatusServiceUnavailable),
+			Type:           Ptr(schemas.ProviderUnavailable),
+			Origin:         schemas.ErrorOriginBifrostInternal,
+			Error: schemas.ErrorField{
+				Type:    Ptr(schemas.ProviderUnavailable),
+				Message: fmt.Sprintf("provider %s is %s", providerKey, providerStates[state]),
+			},
+		}
+	}
+	return activity, nil
+}
+
+func (a *providerActivity) release() {
+	a.lastUsed.Store(time.Now().UnixNano())
+	a.inFlight.Add(-1)
+}
+
+// streamWithRelease releases the request of a stream once the stream ends.
+func streamWithRelease(stream chan *schemas.BifrostStream, activity *providerActivity) chan *schemas.BifrostStream {
+	outputStream := make(chan *schemas.BifrostStream, cap(stream))
+	go func() {
+		defer close(outputStream)
+		defer activity.release()
+		for chunk := range stream {
+			outputStream <- chunk
+		}
+	}()
+	return outputStream
+}
+
+// DrainProvider stops routing new requests to a provider, waits for its in-flight requests and
+// streams to finish, then stops its workers and marks it inactive, e.g. to rotate its keys or for
+// maintenance without a restart. New requests for the provider fail with a ProviderUnavailable
+// error and go to their fallbacks. If the deadline passes first, DrainProvider returns an error
+// and the provider stays draining until the remaining requests finish.
+func (bifrost *Bifrost) DrainProvider(providerKey schemas.ModelProvider, deadline time.Time) error {
+	activity := bifrost.getProviderActivity(providerKey)
+	if !activity.state.CompareAndSwap(providerActive, providerDraining) {
+		return fmt.Errorf("provider %s is already %s", providerKey, providerStates[activity.state.Load()])
+	}
+	bifrost.logger.Info(fmt.Sprintf("draining provider %s, %d requests in flight", providerKey, activity.inFlight.Load()))
+	bifrost.events.providerStateChanged(providerKey, schemas.ProviderStateActive, schemas.ProviderStateDraining)
+
+	for activity.inFlight.Load() > 0 {
+		if time.Now().After(deadline) {
+			// Requests can no longer be acquired, so the count only goes down from here
+			go func() {
+				for activity.inFlight.Load() > 0 {
+					time.Sleep(drainPollInterval)
+				}
+				bifrost.deactivateProvider(providerKey, activity)
+			}()
+			return fmt.Errorf("provider %s still has %d requests in flight at the drain deadline, it becomes inactive once they finish", providerKey, activity.inFlight.Load())
+		}
+		time.Sleep(drainPollInterval)
+	}
+
+	bifrost.deactivateProvider(providerKey, activity)
+	return nil
+}
+
+// deactivateProvider stops the workers of a drained provider and marks it inactive.
+func (bifrost *Bifrost) deactivateProvider(providerKey schemas.ModelProvider, activity *providerActivity) {
+	providerMutex := bifrost.getProviderMutex(providerKey)
+	providerMutex.Lock()
+	defer providerMutex.Unlock()
+
+	if queueValue, ok := bifrost.requestQueues.LoadAndDelete(providerKey); ok {
+		close(queueValue.(chan ChannelMessage))
+		if waitGroupValue, ok := bifrost.waitGroups.Load(providerKey); ok {
+			waitGroupValue.(*sync.WaitGroup).Wait()
+		}
+		bifrost.waitGroups.Delete(providerKey)
+	}
+	bifrost.providers.Delete(providerKey)
+	activity.state.Store(providerInactive)
+	bifrost.logger.Info(fmt.Sprintf("provider %s drained and inactive", providerKey))
+	bifrost.events.providerStateChanged(providerKey, schemas.ProviderStateDraining, schemas.ProviderStateInactive)
+}
+
+// ActivateProvider lets an inactive provider accept requests again. Its workers start with the
+// current configuration of the account on its next request, so keys and settings changed while
+// it was inactive take effect.
+func (bifrost *Bifrost) ActivateProvider(providerKey schemas.ModelProvider) error {
+	activity := bifrost.getProviderActivity(providerKey)
+	if !activity.state.CompareAndSwap(providerInactive, providerActive) {
+		state := providerStates[activity.state.Load()]
+		if state == schemas.ProviderStateActive {
+			return nil
+		}
+		return fmt.Errorf("provider %s is %s, it can be activated once inactive", providerKey, state)
+	}
+	bifrost.logger.Info(fmt.Sprintf("provider %s activated", providerKey))
+	bifrost.events.providerStateChanged(providerKey, schemas.ProviderStateInactive, schemas.ProviderStateActive)
+	return nil
+}
+
+// GetProviderStatus returns whether a provider accepts new requests and how many are in flight.
+func (bifrost *Bifrost) GetProviderStatus(providerKey schemas.ModelProvider) schemas.ProviderStatus {
+	activity := bifrost.getProviderActivity(providerKey)
+	return schemas.ProviderStatus{
+		Provider: providerKey,
+		State:    providerStates[activity.state.Load()],
+		InFlight: activity.inFlight.Load(),
+	}
+}
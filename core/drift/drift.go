@@ -0,0 +1,342 @@
+// Package drift detects silent changes in model behavior. A Detector replays a fixed
+// prompt set through Bifrost, compares every response with a stored baseline using
+// lexical and (optionally) embedding similarity as well as the model and system
+// fingerprint reported by the provider, and reports drift when a provider swaps the
+// weights behind a model alias.
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/core/vecmath"
+)
+
+const (
+	// DefaultThreshold is the minimum similarity to the baseline below which a response drifted.
+	DefaultThreshold = 0.8
+	// DefaultInterval is the replay interval used by Start when Config.Interval is not set.
+	DefaultInterval = time.Hour
+)
+
+// Client is the subset of the Bifrost client used for replaying prompts, satisfied by *bifrost.Bifrost.
+type Client interface {
+	ChatCompletionRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError)
+	EmbeddingRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError)
+}
+
+// Prompt is a fixed prompt replayed on every run. Use deterministic parameters, Params
+// defaults to temperature 0.
+type Prompt struct {
+	ID       string                   `json:"id"`
+	Provider schemas.ModelProvider    `json:"provider"`
+	Model    string                   `json:"model"`
+	Messages []schemas.BifrostMessage `json:"messages"`
+	Params   *schemas.ModelParameters `json:"params,omitempty"`
+}
+
+// EmbeddingModel selects the model used to compare responses semantically.
+type EmbeddingModel struct {
+	Provider schemas.ModelProvider `json:"provider"`
+	Model    string                `json:"model"`
+}
+
+// Config configures a Detector.
+type Config struct {
+	Client    Client
+	Store     Store
+	Prompts   []Prompt
+	Embedding *EmbeddingModel // Optional, adds embedding cosine similarity to the comparison
+	Threshold float64         // Minimum similarity, DefaultThreshold if 0
+	Interval  time.Duration   // Replay interval for Start, DefaultInterval if 0
+	OnDrift   func(Result)    // Called for every drifted prompt, e.g. to send an alert
+	Logger    schemas.Logger  // Optional
+	IsLeader  func() bool     // Optional, Start skips runs while it returns false so one replica of a fleet replays the prompts
+}
+
+// Snapshot is a recorded response to a prompt.
+type Snapshot struct {
+	PromptID          string    `json:"prompt_id"`
+	Text              string    `json:"text"`
+	ResponseModel     string    `json:"response_model,omitempty"`     // Model reported by the provider, may differ from the requested alias
+	SystemFingerprint string    `json:"system_fingerprint,omitempty"` // Backend configuration fingerprint reported by the provider
+	Embedding         []float32 `json:"embedding,omitempty"`
+	RecordedAt        time.Time `json:"recorded_at"`
+}
+
+// Result is the comparison of a replayed prompt with its baseline.
+type Result struct {
+	PromptID            string    `json:"prompt_id"`
+	Baseline            *Snapshot `json:"baseline,omitempty"` // Nil if this run recorded the baseline
+	Current             *Snapshot `json:"current,omitempty"`
+	LexicalSimilarity   float64   `json:"lexical_similarity"`
+	EmbeddingSimilarity *float64  `json:"embedding_similarity,omitempty"`
+	ModelChanged        bool      `json:"model_changed"`
+	FingerprintChanged  bool      `json:"fingerprint_changed"`
+	Drifted             bool      `json:"drifted"`
+	Reasons             []string  `json:"reasons,omitempty"`
+	Error               string    `json:"error,omitempty"` // Set if the prompt could not be replayed
+}
+
+// Detector replays prompts and compares responses with their baselines.
+type Detector struct {
+	config Config
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDetector creates a drift detector.
+func NewDetector(config Config) (*Detector, error) {
+	if config.Client == nil {
+		return nil, errors.New("drift detector requires a client")
+	}
+	if config.Store == nil {
+		return nil, errors.New("drift detector requires a baseline store")
+	}
+	if config.Threshold < 0 || config.Threshold > 1 {
+		return nil, fmt.Errorf("threshold must be between 0 and 1, got %v", config.Threshold)
+	}
+	if config.Threshold == 0 {
+		config.Threshold = DefaultThreshold
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+
+	seen := make(map[string]bool, len(config.Prompts))
+	for _, prompt := range config.Prompts {
+		if prompt.ID == "" {
+			return nil, errors.New("every prompt needs an id")
+		}
+		if seen[prompt.ID] {
+			return nil, fmt.Errorf("duplicate prompt id %q", prompt.ID)
+		}
+		seen[prompt.ID] = true
+	}
+
+	return &Detector{config: config}, nil
+}
+
+// Run replays every prompt once. Prompts without a baseline record their response as
+// the baseline. OnDrift is called for every drifted result.
+func (d *Detector) Run(ctx context.Context) []Result {
+	results := make([]Result, 0, len(d.config.Prompts))
+	for _, prompt := range d.config.Prompts {
+		result := d.check(ctx, prompt)
+		if result.Drifted && d.config.OnDrift != nil {
+			d.config.OnDrift(result)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Start replays the prompts every Interval in the background until Stop is called or ctx
+// is cancelled. The first run starts immediately. Runs are skipped while IsLeader is set and
+// returns false.
+func (d *Detector) Start(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cancel != nil {
+		return errors.New("drift detector already started")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	d.cancel = cancel
+	d.done = make(chan struct{})
+
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(d.config.Interval)
+		defer ticker.Stop()
+		for {
+			if d.config.IsLeader == nil || d.config.IsLeader() {
+				d.Run(ctx)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops a detector started with Start and waits for the running replay to finish.
+func (d *Detector) Stop() {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.cancel, d.done = nil, nil
+	d.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// ResetBaseline replaces the baseline of a prompt with its current response, e.g. after
+// a drift was reviewed and accepted.
+func (d *Detector) ResetBaseline(ctx context.Context, promptID string) (*Snapshot, error) {
+	for _, prompt := range d.config.Prompts {
+		if prompt.ID != promptID {
+			continue
+		}
+		snapshot, err := d.snapshot(ctx, prompt)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.config.Store.Save(snapshot); err != nil {
+			return nil, err
+		}
+		return snapshot, nil
+	}
+	return nil, fmt.Errorf("unknown prompt id %q", promptID)
+}
+
+// check replays a prompt and compares the response with its baseline.
+func (d *Detector) check(ctx context.Context, prompt Prompt) Result {
+	result := Result{PromptID: prompt.ID}
+
+	current, err := d.snapshot(ctx, prompt)
+	if err != nil {
+		result.Error = err.Error()
+		d.warn(fmt.Sprintf("drift: failed to replay prompt %s: %v", prompt.ID, err))
+		return result
+	}
+	result.Current = current
+
+	baseline, err := d.config.Store.Load(prompt.ID)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to load baseline: %v", err)
+		return result
+	}
+	if baseline == nil {
+		if err := d.config.Store.Save(current); err != nil {
+			result.Error = fmt.Sprintf("failed to save baseline: %v", err)
+		}
+		result.LexicalSimilarity = 1
+		return result
+	}
+	result.Baseline = baseline
+
+	compare(&result, baseline, current, d.config.Threshold)
+	return result
+}
+
+// compare fills the similarity metrics and drift verdict of a result.
+func compare(result *Result, baseline, current *Snapshot, threshold float64) {
+	result.LexicalSimilarity = LexicalSimilarity(baseline.Text, current.Text)
+	similarity := result.LexicalSimilarity
+
+	if len(baseline.Embedding) > 0 && len(current.Embedding) > 0 {
+		if cosine, err := vecmath.Cosine(baseline.Embedding, current.Embedding); err == nil {
+			result.EmbeddingSimilarity = &cosine
+			// Paraphrases are not drift, so the semantic similarity takes precedence
+			similarity = cosine
+		}
+	}
+
+	if similarity < threshold {
+		result.Drifted = true
+		result.Reasons = append(result.Reasons, fmt.Sprintf("similarity %.3f is below the threshold %.3f", similarity, threshold))
+	}
+	if baseline.ResponseModel != "" && current.ResponseModel != "" && baseline.ResponseModel != current.ResponseModel {
+		result.ModelChanged = true
+		result.Drifted = true
+		result.Reasons = append(result.Reasons, fmt.Sprintf("provider reported model changed from %s to %s", baseline.ResponseModel, current.ResponseModel))
+	}
+	if baseline.SystemFingerprint != "" && current.SystemFingerprint != "" && baseline.SystemFingerprint != current.SystemFingerprint {
+		// Fingerprints also change with routine infrastructure updates, so they only
+		// count as drift together with a behavior change
+		result.FingerprintChanged = true
+		if result.Drifted {
+			result.Reasons = append(result.Reasons, fmt.Sprintf("system fingerprint changed from %s to %s", baseline.SystemFingerprint, current.SystemFingerprint))
+		}
+	}
+}
+
+// snapshot replays a prompt and records the response.
+func (d *Detector) snapshot(ctx context.Context, prompt Prompt) (*Snapshot, error) {
+	params := prompt.Params
+	if params == nil {
+		temperature := 0.0
+		params = &schemas.ModelParameters{Temperature: &temperature}
+	}
+	messages := prompt.Messages
+
+	response, bifrostErr := d.config.Client.ChatCompletionRequest(ctx, &schemas.BifrostRequest{
+		Provider: prompt.Provider,
+		Model:    prompt.Model,
+		Input:    schemas.RequestInput{ChatCompletionInput: &messages},
+		Params:   params,
+	})
+	if bifrostErr != nil {
+		return nil, errors.New(errorMessage(bifrostErr))
+	}
+	if len(response.Choices) == 0 || response.Choices[0].BifrostNonStreamResponseChoice == nil {
+		return nil, errors.New("response has no message")
+	}
+
+	snapshot := &Snapshot{
+		PromptID:      prompt.ID,
+		Text:          response.Choices[0].BifrostNonStreamResponseChoice.Message.Content.Text(),
+		ResponseModel: response.Model,
+		RecordedAt:    time.Now().UTC(),
+	}
+	if response.SystemFingerprint != nil {
+		snapshot.SystemFingerprint = *response.SystemFingerprint
+	}
+
+	if d.config.Embedding != nil && snapshot.Text != "" {
+		embedding, err := d.embed(ctx, snapshot.Text)
+		if err != nil {
+			// Lexical comparison still works, so a failed embedding only degrades the check
+			d.warn(fmt.Sprintf("drift: failed to embed response of prompt %s: %v", prompt.ID, err))
+		}
+		snapshot.Embedding = embedding
+	}
+	return snapshot, nil
+}
+
+func (d *Detector) embed(ctx context.Context, text string) ([]float32, error) {
+	response, bifrostErr := d.config.Client.EmbeddingRequest(ctx, &schemas.BifrostRequest{
+		Provider: d.config.Embedding.Provider,
+		Model:    d.config.Embedding.Model,
+		Input:    schemas.RequestInput{EmbeddingInput: &schemas.EmbeddingInput{Text: &text}},
+	})
+	if bifrostErr != nil {
+		return nil, errors.New(errorMessage(bifrostErr))
+	}
+	embeddings, err := vecmath.FromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, errors.New("embedding response is empty")
+	}
+	return embeddings[0], nil
+}
+
+func (d *Detector) warn(message string) {
+	if d.config.Logger != nil {
+		d.config.Logger.Warn(message)
+	}
+}
+
+func errorMessage(bifrostErr *schemas.BifrostError) string {
+	if bifrostErr.Error.Message != "" {
+		return bifrostErr.Error.Message
+	}
+	if bifrostErr.Error.Error != nil {
+		return bifrostErr.Error.Error.Error()
+	}
+	return "request failed"
+}
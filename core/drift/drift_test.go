@@ -0,0 +1,211 @@
+package drift
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestLexicalSimilarity(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		wantMin float64
+		wantMax float64
+	}{
+		{name: "identical", a: "The capital of France is Paris.", b: "the capital of france is paris", wantMin: 1, wantMax: 1},
+		{name: "both empty", a: "", b: " ...", wantMin: 1, wantMax: 1},
+		{name: "one empty", a: "Paris", b: "", wantMin: 0, wantMax: 0},
+		{name: "no common words", a: "Paris", b: "London", wantMin: 0, wantMax: 0},
+		{name: "word order matters through bigrams", a: "dog bites man", b: "man bites dog", wantMin: 0.4, wantMax: 0.8},
+		{name: "small edit", a: "The capital of France is Paris.", b: "The capital of France is Paris, of course.", wantMin: 0.8, wantMax: 0.99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LexicalSimilarity(tt.a, tt.b)
+			if got < tt.wantMin-1e-9 || got > tt.wantMax+1e-9 {
+				t.Errorf("LexicalSimilarity() = %v, want within [%v, %v]", got, tt.wantMin, tt.wantMax)
+			}
+			if reverse := LexicalSimilarity(tt.b, tt.a); math.Abs(reverse-got) > 1e-9 {
+				t.Errorf("LexicalSimilarity() is not symmetric: %v and %v", got, reverse)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name                   string
+		baseline, current      Snapshot
+		wantDrifted            bool
+		wantModelChanged       bool
+		wantFingerprintChanged bool
+		wantEmbedding          bool
+		wantReasons            int
+	}{
+		{
+			name:     "unchanged",
+			baseline: Snapshot{Text: "Paris is the capital.", ResponseModel: "gpt-4o-2024-08-06"},
+			current:  Snapshot{Text: "Paris is the capital.", ResponseModel: "gpt-4o-2024-08-06"},
+		},
+		{
+			name:        "different text",
+			baseline:    Snapshot{Text: "Paris is the capital."},
+			current:     Snapshot{Text: "I cannot help with that."},
+			wantDrifted: true,
+			wantReasons: 1,
+		},
+		{
+			name:          "paraphrase with close embeddings",
+			baseline:      Snapshot{Text: "Paris is the capital.", Embedding: []float32{1, 0, 0.1}},
+			current:       Snapshot{Text: "The French capital city is called Paris.", Embedding: []float32{1, 0.05, 0.1}},
+			wantEmbedding: true,
+		},
+		{
+			name:          "same words with distant embeddings",
+			baseline:      Snapshot{Text: "Paris is the capital.", Embedding: []float32{1, 0}},
+			current:       Snapshot{Text: "Paris is the capital.", Embedding: []float32{0, 1}},
+			wantDrifted:   true,
+			wantEmbedding: true,
+			wantReasons:   1,
+		},
+		{
+			name:             "model changed",
+			baseline:         Snapshot{Text: "Paris", ResponseModel: "gpt-4o-2024-05-13"},
+			current:          Snapshot{Text: "Paris", ResponseModel: "gpt-4o-2024-08-06"},
+			wantDrifted:      true,
+			wantModelChanged: true,
+			wantReasons:      1,
+		},
+		{
+			name:     "model unknown",
+			baseline: Snapshot{Text: "Paris"},
+			current:  Snapshot{Text: "Paris", ResponseModel: "gpt-4o-2024-08-06"},
+		},
+		{
+			name:                   "fingerprint changed alone",
+			baseline:               Snapshot{Text: "Paris", SystemFingerprint: "fp_1"},
+			current:                Snapshot{Text: "Paris", SystemFingerprint: "fp_2"},
+			wantFingerprintChanged: true,
+		},
+		{
+			name:                   "fingerprint changed with the text",
+			baseline:               Snapshot{Text: "Paris", SystemFingerprint: "fp_1"},
+			current:                Snapshot{Text: "London", SystemFingerprint: "fp_2"},
+			wantDrifted:            true,
+			wantFingerprintChanged: true,
+			wantReasons:            2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result Result
+			compare(&result, &tt.baseline, &tt.current, DefaultThreshold)
+			if result.Drifted != tt.wantDrifted {
+				t.Errorf("Drifted = %v, want %v (reasons %q)", result.Drifted, tt.wantDrifted, result.Reasons)
+			}
+			if result.ModelChanged != tt.wantModelChanged {
+				t.Errorf("ModelChanged = %v, want %v", result.ModelChanged, tt.wantModelChanged)
+			}
+			if result.FingerprintChanged != tt.wantFingerprintChanged {
+				t.Errorf("FingerprintChanged = %v, want %v", result.FingerprintChanged, tt.wantFingerprintChanged)
+			}
+			if (result.EmbeddingSimilarity != nil) != tt.wantEmbedding {
+				t.Errorf("EmbeddingSimilarity = %v, want set %v", result.EmbeddingSimilarity, tt.wantEmbedding)
+			}
+			if len(result.Reasons) != tt.wantReasons {
+				t.Errorf("Reasons = %q, want %d", result.Reasons, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	client := &replayClient{}
+	store := NewMemoryStore()
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "valid", config: Config{Client: client, Store: store, Prompts: []Prompt{{ID: "a"}, {ID: "b"}}}},
+		{name: "no client", config: Config{Store: store}, wantErr: true},
+		{name: "no store", config: Config{Client: client}, wantErr: true},
+		{name: "threshold above 1", config: Config{Client: client, Store: store, Threshold: 1.5}, wantErr: true},
+		{name: "prompt without id", config: Config{Client: client, Store: store, Prompts: []Prompt{{}}}, wantErr: true},
+		{name: "duplicate prompt id", config: Config{Client: client, Store: store, Prompts: []Prompt{{ID: "a"}, {ID: "a"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector, err := NewDetector(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewDetector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (detector.config.Threshold != DefaultThreshold || detector.config.Interval != DefaultInterval) {
+				t.Errorf("defaults not applied: threshold %v, interval %v", detector.config.Threshold, detector.config.Interval)
+			}
+		})
+	}
+}
+
+func TestDetectorRun(t *testing.T) {
+	client := &replayClient{text: "Paris is the capital of France.", model: "gpt-4o-2024-05-13"}
+	var drifted []Result
+	detector, err := NewDetector(Config{
+		Client:  client,
+		Store:   NewMemoryStore(),
+		Prompts: []Prompt{{ID: "capital", Provider: schemas.OpenAI, Model: "gpt-4o"}},
+		OnDrift: func(result Result) { drifted = append(drifted, result) },
+	})
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+
+	// The first run records the baseline, the second compares with it
+	if results := detector.Run(context.Background()); results[0].Baseline != nil || results[0].Drifted {
+		t.Fatalf("first run = %+v, want the baseline recorded", results[0])
+	}
+	if results := detector.Run(context.Background()); results[0].Baseline == nil || results[0].Drifted {
+		t.Fatalf("second run = %+v, want no drift", results[0])
+	}
+
+	client.text, client.model = "I am not able to answer that.", "gpt-4o-2024-08-06"
+	results := detector.Run(context.Background())
+	if !results[0].Drifted || !results[0].ModelChanged || len(drifted) != 1 {
+		t.Fatalf("run after the model changed = %+v, want drift reported once", results[0])
+	}
+
+	// Accepting the change makes the new response the baseline
+	if _, err := detector.ResetBaseline(context.Background(), "capital"); err != nil {
+		t.Fatalf("ResetBaseline() error = %v", err)
+	}
+	if results := detector.Run(context.Background()); results[0].Drifted {
+		t.Errorf("run after resetting the baseline = %+v, want no drift", results[0])
+	}
+	if _, err := detector.ResetBaseline(context.Background(), "unknown"); err == nil {
+		t.Error("ResetBaseline() accepted an unknown prompt id")
+	}
+}
+
+// replayClient answers every chat request with a fixed text and model.
+type replayClient struct {
+	text  string
+	model string
+}
+
+func (c *replayClient) ChatCompletionRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	text := c.text
+	return &schemas.BifrostResponse{
+		Model: c.model,
+		Choices: []schemas.BifrostResponseChoice{{BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
+			Message: schemas.BifrostMessage{Role: schemas.ModelChatMessageRoleAssistant, Content: schemas.MessageContent{ContentStr: &text}},
+		}}},
+	}, nil
+}
+
+func (c *replayClient) EmbeddingRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, &schemas.BifrostError{Error: schemas.ErrorField{Message: "embeddings not supported"}}
+}
@@ -0,0 +1,46 @@
+package drift
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// LexicalSimilarity returns the cosine similarity of the word unigram and bigram
+// frequencies of a and b, in [0, 1]. Identical texts score 1, texts without any common
+// words score 0, and two empty texts are identical.
+func LexicalSimilarity(a, b string) float64 {
+	termsA, termsB := termFrequencies(a), termFrequencies(b)
+	if len(termsA) == 0 && len(termsB) == 0 {
+		return 1
+	}
+	if len(termsA) == 0 || len(termsB) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for term, countA := range termsA {
+		dot += countA * termsB[term]
+		normA += countA * countA
+	}
+	for _, countB := range termsB {
+		normB += countB * countB
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// termFrequencies counts the lowercased words and word bigrams of a text.
+func termFrequencies(text string) map[string]float64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make(map[string]float64, len(words)*2)
+	for i, word := range words {
+		terms[word]++
+		if i > 0 {
+			terms[words[i-1]+" "+word]++
+		}
+	}
+	return terms
+}
@@ -0,0 +1,103 @@
+package drift
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists baseline snapshots.
+type Store interface {
+	// Load returns the baseline of a prompt, or nil if none was recorded yet.
+	Load(promptID string) (*Snapshot, error)
+	// Save records the baseline of a prompt, replacing any previous one.
+	Save(snapshot *Snapshot) error
+}
+
+// MemoryStore keeps baselines in memory, mostly useful for tests and one-off comparisons.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]*Snapshot
+}
+
+// NewMemoryStore creates an empty in-memory baseline store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{snapshots: make(map[string]*Snapshot)}
+}
+
+// Load returns the baseline of a prompt, or nil if none was recorded yet.
+func (s *MemoryStore) Load(promptID string) (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshots[promptID], nil
+}
+
+// Save records the baseline of a prompt.
+func (s *MemoryStore) Save(snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[snapshot.PromptID] = snapshot
+	return nil
+}
+
+// FileStore keeps one JSON file per prompt in a directory, so baselines can be reviewed
+// and committed alongside the prompt set.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a file baseline store in dir, creating the directory if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create baseline directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(promptID string) string {
+	return filepath.Join(s.dir, url.PathEscape(promptID)+".json")
+}
+
+// Load returns the baseline of a prompt, or nil if none was recorded yet.
+func (s *FileStore) Load(promptID string) (*Snapshot, error) {
+	data, err := os.ReadFile(s.path(promptID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode baseline %s: %w", promptID, err)
+	}
+	return &snapshot, nil
+}
+
+// Save records the baseline of a prompt. The file is replaced atomically.
+func (s *FileStore) Save(snapshot *Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".baseline-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(snapshot.PromptID))
+}
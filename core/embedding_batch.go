@@ -0,0 +1,300 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// EMBEDDING MICRO-BATCHING
+// ============================================================================
+
+// embeddingBatcher merges text embedding requests that arrive within a short window into a
+// single provider call and splits the result back into one response per request. Requests
+// run their plugin hooks individually, only the provider call is shared.
+type embeddingBatcher struct {
+	bifrost   *Bifrost
+	window    time.Duration
+	maxInputs int
+	providers []schemas.ModelProvider
+
+	mu      sync.Mutex
+	pending map[string]*embeddingBatch // Open batches by batch key
+}
+
+// embeddingBatch is a provider call being assembled.
+type embeddingBatch struct {
+	provider schemas.ModelProvider
+	key      *schemas.Key // Key selected for every member, nil if the provider needs none
+	members  []*ChannelMessage
+	texts    []string
+	timer    *time.Timer
+}
+
+// newEmbeddingBatcher creates an embedding batcher, nil if config is nil.
+func newEmbeddingBatcher(bifrost *Bifrost, config *schemas.EmbeddingBatchConfig) *embeddingBatcher {
+	if config == nil {
+		return nil
+	}
+	batcher := &embeddingBatcher{
+		bifrost:   bifrost,
+		window:    schemas.DefaultEmbeddingBatchWindow,
+		maxInputs: schemas.DefaultEmbeddingBatchMaxInputs,
+		providers: schemas.DefaultEmbeddingBatchProviders,
+		pending:   make(map[string]*embeddingBatch),
+	}
+	if config.Window > 0 {
+		batcher.window = config.Window
+	}
+	if config.MaxInputs > 0 {
+		batcher.maxInputs = config.MaxInputs
+	}
+	if len(config.Providers) > 0 {
+		batcher.providers = config.Providers
+	}
+	return batcher
+}
+
+// getEmbeddingBatcher returns the batcher for a request, nil when batching is not configured,
+// turned off in the context, or not applicable to the request.
+func (bifrost *Bifrost) getEmbeddingBatcher(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType) *embeddingBatcher {
+	batcher := bifrost.embeddingBatcher
+	if batcher == nil || requestType != schemas.EmbeddingRequest || !slices.Contains(batcher.providers, req.Provider) {
+		return nil
+	}
+	if enabled, ok := ctx.Value(schemas.BifrostContextKeyEmbeddingBatching).(bool); ok && !enabled {
+		return nil
+	}
+	if texts := embeddingTexts(req.Input.EmbeddingInput); len(texts) == 0 || len(texts) >= batcher.maxInputs {
+		return nil
+	}
+	return batcher
+}
+
+// embeddingTexts returns the texts of a text embedding input, nil for token inputs.
+func embeddingTexts(input *schemas.EmbeddingInput) []string {
+	switch {
+	case input == nil:
+		return nil
+	case input.Text != nil:
+		return []string{*input.Text}
+	default:
+		return input.Texts
+	}
+}
+
+// add places msg in an open batch, starting a new one if needed. The result is delivered on
+// msg's Response or Err channel as if msg had been enqueued directly.
+func (b *embeddingBatcher) add(msg *ChannelMessage) {
+	key, batchKey, err := b.batchKey(msg)
+	if err != nil {
+		msg.Err <- *newBifrostError(err, schemas.ErrorOriginBifrostInternal)
+		return
+	}
+	texts := embeddingTexts(msg.Input.EmbeddingInput)
+
+	b.mu.Lock()
+	batch := b.pending[batchKey]
+	if batch != nil && len(batch.texts)+len(texts) > b.maxInputs {
+		// No room left, send the open batch and start a new one
+		b.detach(batchKey, batch)
+		go b.dispatch(batch)
+		batch = nil
+	}
+	if batch == nil {
+		batch = &embeddingBatch{provider: msg.Provider, key: key}
+		b.pending[batchKey] = batch
+		batch.timer = time.AfterFunc(b.window, func() {
+			b.mu.Lock()
+			detached := b.detach(batchKey, batch)
+			b.mu.Unlock()
+			if detached {
+				b.dispatch(batch)
+			}
+		})
+	}
+	batch.members = append(batch.members, msg)
+	batch.texts = append(batch.texts, texts...)
+	if len(batch.texts) >= b.maxInputs {
+		b.detach(batchKey, batch)
+		go b.dispatch(batch)
+	}
+	b.mu.Unlock()
+}
+
+// detach removes batch from the open batches, reporting whether it was still open.
+// Callers must hold the lock.
+func (b *embeddingBatcher) detach(batchKey string, batch *embeddingBatch) bool {
+	if b.pending[batchKey] != batch {
+		return false
+	}
+	delete(b.pending, batchKey)
+	batch.timer.Stop()
+	return true
+}
+
+// batchKey selects the API key of msg and returns it with the key of the batches msg may
+// join: requests are only merged when provider, model, parameters and API key all match.
+func (b *embeddingBatcher) batchKey(msg *ChannelMessage) (*schemas.Key, string, error) {
+	var key *schemas.Key
+	config, err := b.bifrost.account.GetConfigForProvider(msg.Provider)
+	if err != nil {
+		return nil, "", err
+	}
+	baseProvider := msg.Provider
+	if cfg := config.CustomProviderConfig; cfg != nil && cfg.BaseProviderType != "" {
+		baseProvider = cfg.BaseProviderType
+	}
+	if providerRequiresKey(baseProvider) {
+		selected, err := b.bifrost.selectKeyFromProviderForModel(&msg.Context, msg.Provider, msg.Model, baseProvider)
+		if err != nil {
+			return nil, "", err
+		}
+		key = &selected
+	}
+
+	params, err := sonic.Marshal(msg.Params)
+	if err != nil {
+		return nil, "", err
+	}
+	var extraParams []byte
+	if msg.Params != nil && len(msg.Params.ExtraParams) > 0 {
+		if extraParams, err = sonic.Marshal(msg.Params.ExtraParams); err != nil {
+			return nil, "", err
+		}
+	}
+
+	batchKey := fmt.Sprintf("%s\x00%s\x00%s\x00%s", msg.Provider, msg.Model, params, extraParams)
+	if key != nil {
+		batchKey += "\x00" + key.ID + "\x00" + key.Value
+	}
+	return key, batchKey, nil
+}
+
+// dispatch sends a batch to its provider as a single request and delivers each member's
+// share of the result. A batch of one is enqueued as is.
+func (b *embeddingBatcher) dispatch(batch *embeddingBatch) {
+	if len(batch.members) == 1 {
+		b.enqueueAlone(batch.members[0])
+		return
+	}
+
+	queue, err := b.bifrost.getProviderQueue(batch.provider)
+	if err != nil {
+		b.fail(batch, newBifrostError(err, schemas.ErrorOriginBifrostInternal))
+		return
+	}
+
+	// The call outlives any single member, it carries the values of the first and the shared key
+	first := batch.members[0]
+	req := first.BifrostRequest
+	req.Input = schemas.RequestInput{EmbeddingInput: &schemas.EmbeddingInput{Texts: batch.texts}}
+	ctx := context.WithoutCancel(first.Context)
+	if batch.key != nil {
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyDirectKey, *batch.key)
+	}
+
+	msg := b.bifrost.getChannelMessage(req, schemas.EmbeddingRequest)
+	msg.Context = ctx
+	defer b.bifrost.releaseChannelMessage(msg)
+
+	if enqueueErr := b.bifrost.enqueueRequest(ctx, queue, msg, nil); enqueueErr != nil {
+		b.fail(batch, enqueueErr)
+		return
+	}
+
+	select {
+	case result := <-msg.Response:
+		if !b.split(batch, result) {
+			b.bifrost.logger.Warn("batched embedding response for %s has an unexpected shape, sending %d requests individually", batch.provider, len(batch.members))
+			for _, member := range batch.members {
+				b.enqueueAlone(member)
+			}
+		}
+	case bifrostErr := <-msg.Err:
+		b.fail(batch, &bifrostErr)
+	}
+}
+
+// enqueueAlone sends a member to its provider queue unbatched.
+func (b *embeddingBatcher) enqueueAlone(member *ChannelMessage) {
+	queue, err := b.bifrost.getProviderQueue(member.Provider)
+	if err != nil {
+		member.Err <- *newBifrostError(err, schemas.ErrorOriginBifrostInternal)
+		return
+	}
+	if enqueueErr := b.bifrost.enqueueRequest(member.Context, queue, member, nil); enqueueErr != nil {
+		member.Err <- *enqueueErr
+	}
+}
+
+// fail delivers the error of a batched call to every member.
+func (b *embeddingBatcher) fail(batch *embeddingBatch, bifrostErr *schemas.BifrostError) {
+	for _, member := range batch.members {
+		member.Err <- *bifrostErr
+	}
+}
+
+// split delivers each member its embeddings from a batched response, with token usage
+// attributed in proportion to the length of its texts. It reports false without delivering
+// anything if the response does not hold one embedding per text.
+func (b *embeddingBatcher) split(batch *embeddingBatch, result *schemas.BifrostResponse) bool {
+	if result == nil || len(result.Data) != len(batch.texts) {
+		return false
+	}
+	data := slices.Clone(result.Data)
+	sort.SliceStable(data, func(i, j int) bool { return data[i].Index < data[j].Index })
+
+	totalChars := 0
+	for _, text := range batch.texts {
+		totalChars += len(text)
+	}
+
+	info := &schemas.EmbeddingBatch{Requests: len(batch.members), Inputs: len(batch.texts)}
+	offset := 0
+	var assignedPrompt, assignedTotal int
+	for i, member := range batch.members {
+		count := len(embeddingTexts(member.Input.EmbeddingInput))
+
+		response := *result
+		response.Data = make([]schemas.BifrostEmbedding, count)
+		for j := range count {
+			response.Data[j] = data[offset+j]
+			response.Data[j].Index = j
+		}
+		response.ExtraFields.EmbeddingBatch = info
+
+		if result.Usage != nil {
+			usage := *result.Usage
+			if i == len(batch.members)-1 {
+				usage.PromptTokens = result.Usage.PromptTokens - assignedPrompt
+				usage.TotalTokens = result.Usage.TotalTokens - assignedTotal
+			} else {
+				chars := 0
+				for _, text := range batch.texts[offset : offset+count] {
+					chars += len(text)
+				}
+				share := 1 / float64(len(batch.members))
+				if totalChars > 0 {
+					share = float64(chars) / float64(totalChars)
+				}
+				usage.PromptTokens = int(float64(result.Usage.PromptTokens) * share)
+				usage.TotalTokens = int(float64(result.Usage.TotalTokens) * share)
+				assignedPrompt += usage.PromptTokens
+				assignedTotal += usage.TotalTokens
+			}
+			response.Usage = &usage
+		}
+
+		member.Response <- &response
+		offset += count
+	}
+	return true
+}
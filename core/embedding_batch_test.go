@@ -0,0 +1,225 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestGetEmbeddingBatcher(t *testing.T) {
+	text := "hello"
+	bifrost := &Bifrost{}
+	bifrost.embeddingBatcher = newEmbeddingBatcher(bifrost, &schemas.EmbeddingBatchConfig{MaxInputs: 3})
+	disabled := context.WithValue(context.Background(), schemas.BifrostContextKeyEmbeddingBatching, false)
+
+	tests := []struct {
+		name        string
+		bifrost     *Bifrost
+		ctx         context.Context
+		provider    schemas.ModelProvider
+		requestType schemas.RequestType
+		input       *schemas.EmbeddingInput
+		want        bool
+	}{
+		{name: "single text", provider: schemas.OpenAI, input: &schemas.EmbeddingInput{Text: &text}, want: true},
+		{name: "texts below the limit", provider: schemas.Cohere, input: &schemas.EmbeddingInput{Texts: []string{"a", "b"}}, want: true},
+		{name: "not configured", bifrost: &Bifrost{}, provider: schemas.OpenAI, input: &schemas.EmbeddingInput{Text: &text}},
+		{name: "not an embedding request", provider: schemas.OpenAI, requestType: schemas.ChatCompletionRequest, input: &schemas.EmbeddingInput{Text: &text}},
+		{name: "provider not batched", provider: schemas.Bedrock, input: &schemas.EmbeddingInput{Text: &text}},
+		{name: "disabled per request", ctx: disabled, provider: schemas.OpenAI, input: &schemas.EmbeddingInput{Text: &text}},
+		{name: "token input", provider: schemas.OpenAI, input: &schemas.EmbeddingInput{Embedding: []int{1, 2}}},
+		{name: "texts fill a batch", provider: schemas.OpenAI, input: &schemas.EmbeddingInput{Texts: []string{"a", "b", "c"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, ctx, requestType := tt.bifrost, tt.ctx, tt.requestType
+			if b == nil {
+				b = bifrost
+			}
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if requestType == "" {
+				requestType = schemas.EmbeddingRequest
+			}
+			req := &schemas.BifrostRequest{Provider: tt.provider, Input: schemas.RequestInput{EmbeddingInput: tt.input}}
+			if got := b.getEmbeddingBatcher(ctx, req, requestType) != nil; got != tt.want {
+				t.Errorf("getEmbeddingBatcher() returned a batcher = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddingBatchSplit(t *testing.T) {
+	embedding := func(index int) schemas.BifrostEmbedding {
+		values := []float32{float32(index)}
+		return schemas.BifrostEmbedding{Index: index, Embedding: schemas.BifrostEmbeddingResponse{EmbeddingArray: &values}}
+	}
+	member := func(texts ...string) *ChannelMessage {
+		return &ChannelMessage{
+			BifrostRequest: schemas.BifrostRequest{Input: schemas.RequestInput{EmbeddingInput: &schemas.EmbeddingInput{Texts: texts}}},
+			Response:       make(chan *schemas.BifrostResponse, 1),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		data       []schemas.BifrostEmbedding // Provider response, possibly out of order
+		usage      *schemas.LLMUsage
+		wantOK     bool
+		wantValues [][]float32 // Embedding values delivered to each member
+		wantTokens []int       // Prompt tokens attributed to each member
+	}{
+		{
+			name:       "out of order with usage",
+			data:       []schemas.BifrostEmbedding{embedding(2), embedding(0), embedding(1)},
+			usage:      &schemas.LLMUsage{PromptTokens: 71, TotalTokens: 71},
+			wantOK:     true,
+			wantValues: [][]float32{{0}, {1, 2}},
+			wantTokens: []int{20, 51}, // 2 and 5 of 7 characters, the rounding remainder goes to the last member
+		},
+		{
+			name:       "without usage",
+			data:       []schemas.BifrostEmbedding{embedding(0), embedding(1), embedding(2)},
+			wantOK:     true,
+			wantValues: [][]float32{{0}, {1, 2}},
+		},
+		{
+			name: "one embedding per text missing",
+			data: []schemas.BifrostEmbedding{embedding(0), embedding(1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			members := []*ChannelMessage{member("aa"), member("b", "cccc")}
+			batch := &embeddingBatch{provider: schemas.OpenAI, members: members, texts: []string{"aa", "b", "cccc"}}
+			result := &schemas.BifrostResponse{Data: tt.data, Usage: tt.usage}
+
+			if ok := (&embeddingBatcher{}).split(batch, result); ok != tt.wantOK {
+				t.Fatalf("split() = %v, want %v", ok, tt.wantOK)
+			}
+			for i, m := range members {
+				if !tt.wantOK {
+					if len(m.Response) != 0 {
+						t.Errorf("member %d got a response from a rejected split", i)
+					}
+					continue
+				}
+				response := <-m.Response
+				if len(response.Data) != len(tt.wantValues[i]) {
+					t.Fatalf("member %d got %d embeddings, want %d", i, len(response.Data), len(tt.wantValues[i]))
+				}
+				for j, want := range tt.wantValues[i] {
+					if got := response.Data[j]; got.Index != j || (*got.Embedding.EmbeddingArray)[0] != want {
+						t.Errorf("member %d embedding %d = index %d value %v, want index %d value %v", i, j, got.Index, (*got.Embedding.EmbeddingArray)[0], j, want)
+					}
+				}
+				if info := response.ExtraFields.EmbeddingBatch; info == nil || info.Requests != 2 || info.Inputs != 3 {
+					t.Errorf("member %d batch info = %+v, want 2 requests and 3 inputs", i, info)
+				}
+				if tt.wantTokens == nil {
+					if response.Usage != nil {
+						t.Errorf("member %d got usage %+v, want none", i, response.Usage)
+					}
+				} else if response.Usage == nil || response.Usage.PromptTokens != tt.wantTokens[i] {
+					t.Errorf("member %d usage = %+v, want %d prompt tokens", i, response.Usage, tt.wantTokens[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddingRequestsAreBatched(t *testing.T) {
+	// The server embeds each text as its length and records the size of every call
+	var mu sync.Mutex
+	var calls []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		calls = append(calls, len(req.Input))
+		mu.Unlock()
+		data := make([]map[string]interface{}, 0, len(req.Input))
+		for i, text := range req.Input {
+			data = append(data, map[string]interface{}{"object": "embedding", "index": i, "embedding": []float32{float32(len(text))}})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "model": "text-embedding-3-small", "data": data})
+	}))
+	defer server.Close()
+
+	client, err := Init(context.Background(), schemas.BifrostConfig{
+		Account:           &upstreamAccount{baseURL: server.URL},
+		Logger:            NewDefaultLogger(schemas.LogLevelError),
+		EmbeddingBatching: &schemas.EmbeddingBatchConfig{Window: 200 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer client.Shutdown()
+
+	embed := func(ctx context.Context, texts ...string) *schemas.BifrostResponse {
+		response, bifrostErr := client.EmbeddingRequest(ctx, &schemas.BifrostRequest{
+			Provider: schemas.OpenAI,
+			Model:    "text-embedding-3-small",
+			Input:    schemas.RequestInput{EmbeddingInput: &schemas.EmbeddingInput{Texts: texts}},
+		})
+		if bifrostErr != nil {
+			t.Errorf("EmbeddingRequest() error = %s", bifrostErr.Error.Message)
+			return nil
+		}
+		return response
+	}
+
+	inputs := [][]string{{"a"}, {"bb", "ccc"}, {"dddd"}}
+	responses := make([]*schemas.BifrostResponse, len(inputs))
+	var wg sync.WaitGroup
+	for i, texts := range inputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = embed(context.Background(), texts...)
+		}()
+	}
+	wg.Wait()
+
+	for i, response := range responses {
+		if response == nil {
+			continue
+		}
+		if len(response.Data) != len(inputs[i]) {
+			t.Fatalf("request %d got %d embeddings, want %d", i, len(response.Data), len(inputs[i]))
+		}
+		for j, text := range inputs[i] {
+			if got := (*response.Data[j].Embedding.EmbeddingArray)[0]; got != float32(len(text)) {
+				t.Errorf("request %d embedding %d = %v, want the embedding of %q", i, j, got, text)
+			}
+		}
+		if info := response.ExtraFields.EmbeddingBatch; info == nil || info.Requests != 3 || info.Inputs != 4 {
+			t.Errorf("request %d batch info = %+v, want 3 requests and 4 inputs", i, info)
+		}
+	}
+
+	// Turning batching off sends the request on its own right away
+	disabled := context.WithValue(context.Background(), schemas.BifrostContextKeyEmbeddingBatching, false)
+	if response := embed(disabled, "eeeee"); response != nil && response.ExtraFields.EmbeddingBatch != nil {
+		t.Errorf("unbatched request got batch info %+v", response.ExtraFields.EmbeddingBatch)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 2 || calls[0] != 4 || calls[1] != 1 {
+		t.Errorf("upstream calls had %v inputs, want one batched call of 4 then one of 1", calls)
+	}
+}
@@ -0,0 +1,112 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/core/vecmath"
+)
+
+// getEmbeddingDimensionConfig returns the dimension config for a request, preferring the
+// per-request override in the context over the one set in BifrostConfig.
+func (bifrost *Bifrost) getEmbeddingDimensionConfig(ctx context.Context) *schemas.EmbeddingDimensionConfig {
+	if ctx != nil {
+		if config, ok := ctx.Value(schemas.BifrostContextKeyEmbeddingDimension).(*schemas.EmbeddingDimensionConfig); ok && config != nil {
+			return config
+		}
+	}
+	return bifrost.embeddingDimension
+}
+
+// validateEmbeddingDimensionConfig checks that the target dimension is usable.
+func validateEmbeddingDimensionConfig(config *schemas.EmbeddingDimensionConfig) error {
+	if config != nil && config.Dimension <= 0 {
+		return fmt.Errorf("embedding dimension must be greater than 0, got %d", config.Dimension)
+	}
+	return nil
+}
+
+// applyEmbeddingDimension resizes every embedding of the response to the configured dimension
+// and records the applied transformation in the response's ExtraFields.
+// Base64 embeddings are decoded as little-endian float32 arrays and re-encoded after resizing.
+func applyEmbeddingDimension(response *schemas.BifrostResponse, config *schemas.EmbeddingDimensionConfig) *schemas.BifrostError {
+	if response == nil || config == nil || len(response.Data) == 0 {
+		return nil
+	}
+	if err := validateEmbeddingDimensionConfig(config); err != nil {
+		return newBifrostError(err, schemas.ErrorOriginClientRequest)
+	}
+
+	transform := &schemas.EmbeddingTransform{
+		OriginalDimension: -1,
+		Dimension:         config.Dimension,
+		Operation:         schemas.EmbeddingTransformNone,
+		Normalized:        config.Normalize,
+	}
+
+	resize := func(vector []float32) []float32 {
+		if transform.OriginalDimension == -1 {
+			transform.OriginalDimension = len(vector)
+		}
+		resized, operation := resizeEmbedding(vector, config.Dimension)
+		if operation != schemas.EmbeddingTransformNone {
+			transform.Operation = operation
+		}
+		if config.Normalize {
+			vecmath.Normalize(resized)
+		}
+		return resized
+	}
+
+	for i := range response.Data {
+		embedding := &response.Data[i].Embedding
+		switch {
+		case embedding.EmbeddingArray != nil:
+			resized := resize(*embedding.EmbeddingArray)
+			embedding.EmbeddingArray = &resized
+		case embedding.Embedding2DArray != nil:
+			vectors := make([][]float32, len(*embedding.Embedding2DArray))
+			for j, vector := range *embedding.Embedding2DArray {
+				vectors[j] = resize(vector)
+			}
+			embedding.Embedding2DArray = &vectors
+		case embedding.EmbeddingStr != nil:
+			vector, err := vecmath.DecodeBase64(*embedding.EmbeddingStr)
+			if err != nil {
+				return newBifrostError(fmt.Errorf("failed to decode base64 embedding at index %d: %w", i, err), schemas.ErrorOriginProvider)
+			}
+			encoded := vecmath.EncodeBase64(resize(vector))
+			embedding.EmbeddingStr = &encoded
+		}
+	}
+
+	if transform.OriginalDimension == -1 {
+		// Nothing was resized
+		return nil
+	}
+
+	response.ExtraFields.EmbeddingTransform = transform
+	if transform.Operation == schemas.EmbeddingTransformTruncate {
+		response.ExtraFields.Warnings = append(response.ExtraFields.Warnings, schemas.Warning{
+			Code:    schemas.WarningTruncated,
+			Message: fmt.Sprintf("embeddings truncated from %d to %d dimensions", transform.OriginalDimension, transform.Dimension),
+			Origin:  schemas.WarningOriginBifrost,
+		})
+	}
+	return nil
+}
+
+// resizeEmbedding truncates or zero pads a vector to the given dimension and reports the operation applied.
+func resizeEmbedding(vector []float32, dimension int) ([]float32, string) {
+	switch {
+	case len(vector) > dimension:
+		return append([]float32(nil), vector[:dimension]...), schemas.EmbeddingTransformTruncate
+	case len(vector) < dimension:
+		padded := make([]float32, dimension)
+		copy(padded, vector)
+		return padded, schemas.EmbeddingTransformPad
+	default:
+		return append([]float32(nil), vector...), schemas.EmbeddingTransformNone
+	}
+}
@@ -0,0 +1,210 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// EMBEDDING MODEL MIGRATION
+// ============================================================================
+
+// embeddingMigrator re-embeds the texts of requests served by the source model of a migration
+// with its target model in the background and writes both embeddings to the sink.
+type embeddingMigrator struct {
+	bifrost     *Bifrost
+	source      schemas.Fallback
+	target      schemas.Fallback
+	params      *schemas.ModelParameters
+	concurrency int
+	sink        schemas.EmbeddingMigrationSink
+
+	queue chan embeddingMigrationJob
+	done  chan struct{}
+	stop  sync.Once
+	wg    sync.WaitGroup
+
+	inFlight      atomic.Int64
+	completed     atomic.Int64
+	failed        atomic.Int64
+	dropped       atomic.Int64
+	textsMigrated atomic.Int64
+
+	mu          sync.Mutex
+	lastError   string
+	lastErrorAt *time.Time
+}
+
+// embeddingMigrationJob is a served source request waiting to be migrated.
+type embeddingMigrationJob struct {
+	ctx              context.Context
+	texts            []string
+	params           *schemas.ModelParameters
+	sourceEmbeddings []schemas.BifrostEmbedding
+}
+
+// newEmbeddingMigrator creates an embedding migrator and starts its workers, nil if config is nil.
+func newEmbeddingMigrator(bifrost *Bifrost, config *schemas.EmbeddingMigrationConfig) (*embeddingMigrator, error) {
+	if config == nil {
+		return nil, nil
+	}
+	if config.Sink == nil {
+		return nil, fmt.Errorf("embedding migration requires a sink")
+	}
+	if config.Source.Provider == "" || config.Source.Model == "" || config.Target.Provider == "" || config.Target.Model == "" {
+		return nil, fmt.Errorf("embedding migration requires a source and a target provider and model")
+	}
+	if config.Source == config.Target {
+		return nil, fmt.Errorf("embedding migration source and target are both %s/%s", config.Source.Provider, config.Source.Model)
+	}
+
+	migrator := &embeddingMigrator{
+		bifrost:     bifrost,
+		source:      config.Source,
+		target:      config.Target,
+		params:      config.Params,
+		concurrency: schemas.DefaultEmbeddingMigrationConcurrency,
+		sink:        config.Sink,
+		done:        make(chan struct{}),
+	}
+	if config.Concurrency > 0 {
+		migrator.concurrency = config.Concurrency
+	}
+	queueSize := schemas.DefaultEmbeddingMigrationQueueSize
+	if config.QueueSize > 0 {
+		queueSize = config.QueueSize
+	}
+	migrator.queue = make(chan embeddingMigrationJob, queueSize)
+
+	for range migrator.concurrency {
+		migrator.wg.Add(1)
+		go migrator.work()
+	}
+	return migrator, nil
+}
+
+// enqueue queues the texts of a successful embedding request for migration if it was made for
+// the source model. It never blocks: requests arriving while the queue is full are dropped.
+func (m *embeddingMigrator) enqueue(ctx context.Context, req *schemas.BifrostRequest, response *schemas.BifrostResponse) {
+	if m == nil || req.Provider != m.source.Provider || req.Model != m.source.Model {
+		return
+	}
+	texts := embeddingTexts(req.Input.EmbeddingInput)
+	if len(texts) == 0 {
+		return
+	}
+
+	params := m.params
+	if params == nil {
+		params = req.Params
+	}
+	job := embeddingMigrationJob{
+		// Keeps the values of the request, such as governance headers, but not its cancellation
+		ctx:              context.WithoutCancel(ctx),
+		texts:            texts,
+		params:           params,
+		sourceEmbeddings: response.Data,
+	}
+
+	select {
+	case <-m.done:
+	case m.queue <- job:
+	default:
+		m.dropped.Add(1)
+	}
+}
+
+// work migrates queued requests until the migrator is stopped.
+func (m *embeddingMigrator) work() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.done:
+			return
+		case job := <-m.queue:
+			m.inFlight.Add(1)
+			if err := m.migrate(job); err != nil {
+				m.failed.Add(1)
+				m.recordError(err)
+			} else {
+				m.completed.Add(1)
+				m.textsMigrated.Add(int64(len(job.texts)))
+			}
+			m.inFlight.Add(-1)
+		}
+	}
+}
+
+// migrate embeds the texts of a job with the target model and writes them to the sink.
+func (m *embeddingMigrator) migrate(job embeddingMigrationJob) error {
+	response, bifrostErr := m.bifrost.EmbeddingRequest(job.ctx, &schemas.BifrostRequest{
+		Provider: m.target.Provider,
+		Model:    m.target.Model,
+		Input: schemas.RequestInput{
+			EmbeddingInput: &schemas.EmbeddingInput{Texts: job.texts},
+		},
+		Params: job.params,
+	})
+	if bifrostErr != nil {
+		return fmt.Errorf("target embedding failed: %s", bifrostErr.Error.Message)
+	}
+
+	err := m.sink.WriteEmbeddings(job.ctx, schemas.EmbeddingMigrationRecord{
+		Texts:            job.texts,
+		Source:           m.source,
+		Target:           m.target,
+		SourceEmbeddings: job.sourceEmbeddings,
+		TargetEmbeddings: response.Data,
+	})
+	if err != nil {
+		return fmt.Errorf("sink write failed: %w", err)
+	}
+	return nil
+}
+
+func (m *embeddingMigrator) recordError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.lastError = err.Error()
+	m.lastErrorAt = &now
+	m.bifrost.logger.Warn(fmt.Sprintf("embedding migration to %s/%s: %s", m.target.Provider, m.target.Model, err))
+}
+
+// stopAndWait stops the workers once their current request is done. Queued requests are discarded.
+func (m *embeddingMigrator) stopAndWait() {
+	if m == nil {
+		return
+	}
+	m.stop.Do(func() { close(m.done) })
+	m.wg.Wait()
+}
+
+// GetEmbeddingMigrationStatus returns the progress of the configured embedding migration, or
+// false if none is configured.
+func (bifrost *Bifrost) GetEmbeddingMigrationStatus() (schemas.EmbeddingMigrationStatus, bool) {
+	m := bifrost.embeddingMigrator
+	if m == nil {
+		return schemas.EmbeddingMigrationStatus{}, false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return schemas.EmbeddingMigrationStatus{
+		Source:        m.source,
+		Target:        m.target,
+		Queued:        int64(len(m.queue)),
+		InFlight:      m.inFlight.Load(),
+		Completed:     m.completed.Load(),
+		Failed:        m.failed.Load(),
+		Dropped:       m.dropped.Load(),
+		TextsMigrated: m.textsMigrated.Load(),
+		LastError:     m.lastError,
+		LastErrorAt:   m.lastErrorAt,
+	}, true
+}
@@ -0,0 +1,351 @@
+package bifrost
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// EVENTS
+// ============================================================================
+
+// Applications embedding Bifrost can subscribe to typed events of requests and providers
+// instead of writing a plugin. Events are delivered without ever blocking requests, and nothing
+// is tracked for requests while there are no subscribers.
+
+// eventBufferSize is the capacity of the channel of a subscription.
+const eventBufferSize = 256
+
+// providerUnhealthyFailures is the number of consecutive failed requests after which a provider
+// is reported unhealthy. Its next successful request reports it healthy again.
+const providerUnhealthyFailures = 3
+
+// eventBus fans out events to the subscriptions and tracks the health of providers.
+type eventBus struct {
+	mu            sync.RWMutex
+	subscriptions map[*eventSubscription]struct{}
+	closed        bool
+	active        atomic.Int32  // Number of subscriptions, read on every request
+	nextRequestID atomic.Uint64 // IDs of the requests that events are published for
+	health        sync.Map      // provider -> *providerHealth
+}
+
+type eventSubscription struct {
+	types   map[schemas.EventType]bool // Nil for every type
+	events  chan schemas.Event
+	dropped atomic.Int64
+}
+
+// providerHealth counts the consecutive failed requests of a provider.
+type providerHealth struct {
+	mu        sync.Mutex
+	failures  int
+	unhealthy bool
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subscriptions: make(map[*eventSubscription]struct{})}
+}
+
+// Subscribe returns a channel receiving the events of the given types, or of every type if none
+// is given, and a function ending the subscription and closing the channel. Events are sent
+// without blocking requests: while the channel is full they are dropped, and the next event
+// delivered reports how many in Event.Dropped. The channel is also closed on Shutdown.
+func (bifrost *Bifrost) Subscribe(eventTypes ...schemas.EventType) (<-chan schemas.Event, func()) {
+	return bifrost.events.subscribe(eventTypes)
+}
+
+func (bus *eventBus) subscribe(eventTypes []schemas.EventType) (<-chan schemas.Event, func()) {
+	subscription := &eventSubscription{events: make(chan schemas.Event, eventBufferSize)}
+	if len(eventTypes) > 0 {
+		subscription.types = make(map[schemas.EventType]bool, len(eventTypes))
+		for _, eventType := range eventTypes {
+			subscription.types[eventType] = true
+		}
+	}
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	if bus.closed {
+		close(subscription.events)
+		return subscription.events, func() {}
+	}
+	bus.subscriptions[subscription] = struct{}{}
+	bus.active.Add(1)
+
+	return subscription.events, func() {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+		if _, ok := bus.subscriptions[subscription]; ok {
+			delete(bus.subscriptions, subscription)
+			bus.active.Add(-1)
+			close(subscription.events)
+		}
+	}
+}
+
+// hasSubscribers reports whether events are worth building.
+func (bus *eventBus) hasSubscribers() bool {
+	return bus != nil && bus.active.Load() > 0
+}
+
+// publish sends an event to the subscriptions to its type.
+func (bus *eventBus) publish(event schemas.Event) {
+	if !bus.hasSubscribers() {
+		return
+	}
+	event.Time = time.Now()
+
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+	for subscription := range bus.subscriptions {
+		if subscription.types != nil && !subscription.types[event.Type] {
+			continue
+		}
+		delivered := event
+		delivered.Dropped = int(subscription.dropped.Swap(0))
+		select {
+		case subscription.events <- delivered:
+		default:
+			subscription.dropped.Add(int64(delivered.Dropped) + 1)
+		}
+	}
+}
+
+// close ends every subscription.
+func (bus *eventBus) close() {
+	if bus == nil {
+		return
+	}
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.closed = true
+	for subscription := range bus.subscriptions {
+		close(subscription.events)
+	}
+	bus.subscriptions = make(map[*eventSubscription]struct{})
+	bus.active.Store(0)
+}
+
+// ============================================================================
+// REQUEST EVENTS
+// ============================================================================
+
+type requestEventsContextKey struct{}
+
+// requestEvents publishes the events of a request. It is nil, and its methods do nothing, for
+// requests received while there were no subscribers.
+type requestEvents struct {
+	bus         *eventBus
+	id          uint64
+	requestType schemas.RequestType
+	start       time.Time
+}
+
+// requestStarted publishes the start of a request and attaches its events to the context, for
+// fallbacks and plugins blocking it to be reported. Requests Bifrost makes on behalf of another,
+// such as speculative drafts or the chunks of a long transcription, are part of its events.
+func (bus *eventBus) requestStarted(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType) (context.Context, *requestEvents) {
+	if !bus.hasSubscribers() || getRequestEvents(ctx) != nil {
+		return ctx, nil
+	}
+	events := &requestEvents{
+		bus:         bus,
+		id:          bus.nextRequestID.Add(1),
+		requestType: requestType,
+		start:       time.Now(),
+	}
+	events.publish(schemas.EventRequestStarted, schemas.RequestEvent{Provider: req.Provider, Model: req.Model})
+	return context.WithValue(ctx, requestEventsContextKey{}, events), events
+}
+
+func getRequestEvents(ctx context.Context) *requestEvents {
+	events, _ := ctx.Value(requestEventsContextKey{}).(*requestEvents)
+	return events
+}
+
+func (events *requestEvents) publish(eventType schemas.EventType, request schemas.RequestEvent) {
+	request.ID = events.id
+	request.RequestType = events.requestType
+	events.bus.publish(schemas.Event{Type: eventType, Request: &request})
+}
+
+// finished publishes the outcome of a request.
+func (events *requestEvents) finished(req *schemas.BifrostRequest, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) {
+	if events == nil {
+		return
+	}
+	request := schemas.RequestEvent{
+		Provider: req.Provider,
+		Model:    req.Model,
+		Latency:  time.Since(events.start),
+	}
+	if bifrostErr != nil {
+		if bifrostErr.Provider != "" {
+			request.Provider = bifrostErr.Provider
+		}
+		request.Error = bifrostErr
+		events.publish(schemas.EventRequestFailed, request)
+		return
+	}
+	if result != nil {
+		if result.ExtraFields.Provider != "" {
+			request.Provider = result.ExtraFields.Provider
+		}
+		if result.Model != "" {
+			request.Model = result.Model
+		}
+		request.Usage = result.Usage
+	}
+	events.publish(schemas.EventRequestCompleted, request)
+}
+
+// streamFinished publishes the outcome of a stream request once its stream ends: failed if it
+// ended with an error, completed otherwise.
+func (events *requestEvents) streamFinished(req *schemas.BifrostRequest, stream chan *schemas.BifrostStream, bifrostErr *schemas.BifrostError) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	if events == nil {
+		return stream, bifrostErr
+	}
+	if stream == nil {
+		events.finished(req, nil, bifrostErr)
+		return stream, bifrostErr
+	}
+
+	outputStream := make(chan *schemas.BifrostStream, cap(stream))
+	go func() {
+		defer close(outputStream)
+		var last *schemas.BifrostStream
+		var usage *schemas.LLMUsage
+		for chunk := range stream {
+			if chunk != nil {
+				last = chunk
+				if chunk.BifrostResponse != nil && chunk.BifrostResponse.Usage != nil {
+					usage = chunk.BifrostResponse.Usage
+				}
+			}
+			outputStream <- chunk
+		}
+
+		if last != nil && last.BifrostError != nil {
+			events.finished(req, nil, last.BifrostError)
+			return
+		}
+		result := &schemas.BifrostResponse{Usage: usage}
+		if last != nil && last.BifrostResponse != nil {
+			result.Model = last.BifrostResponse.Model
+			result.ExtraFields.Provider = last.BifrostResponse.ExtraFields.Provider
+		}
+		events.finished(req, result, nil)
+	}()
+	return outputStream, bifrostErr
+}
+
+// fallback publishes that a request failed on a provider and goes on to a fallback.
+func (events *requestEvents) fallback(req *schemas.BifrostRequest, fallback schemas.Fallback, bifrostErr *schemas.BifrostError) {
+	if events == nil {
+		return
+	}
+	events.publish(schemas.EventRequestFallback, schemas.RequestEvent{
+		Provider: req.Provider,
+		Model:    req.Model,
+		Fallback: &fallback,
+		Error:    bifrostErr,
+	})
+}
+
+// blocked publishes that a plugin rejected a request by short-circuiting it with an error.
+func (events *requestEvents) blocked(req *schemas.BifrostRequest, plugin string, bifrostErr *schemas.BifrostError) {
+	if events == nil {
+		return
+	}
+	events.publish(schemas.EventRequestBlocked, schemas.RequestEvent{
+		Provider: req.Provider,
+		Model:    req.Model,
+		Plugin:   plugin,
+		Error:    bifrostErr,
+	})
+}
+
+// ============================================================================
+// PROVIDER EVENTS
+// ============================================================================
+
+// providerStateChanged publishes a drain or activation of a provider.
+func (bus *eventBus) providerStateChanged(providerKey schemas.ModelProvider, previous, state schemas.ProviderState) {
+	if !bus.hasSubscribers() {
+		return
+	}
+	bus.publish(schemas.Event{
+		Type: schemas.EventProviderStateChanged,
+		Provider: &schemas.ProviderEvent{
+			Provider:      providerKey,
+			State:         state,
+			PreviousState: previous,
+			Healthy:       !bus.isUnhealthy(providerKey),
+		},
+	})
+}
+
+// recordAttempt updates the health of a provider with the outcome of a request it served.
+// Only failures of the provider count, such as server errors, rate limits and network errors;
+// errors of the request itself, cancellations and rejections of draining providers do not.
+func (bus *eventBus) recordAttempt(providerKey schemas.ModelProvider, bifrostErr *schemas.BifrostError) {
+	if bus == nil {
+		return
+	}
+	failed := bifrostErr != nil && isRetryableError(bifrostErr) &&
+		(bifrostErr.Type == nil || *bifrostErr.Type != schemas.ProviderUnavailable)
+	if bifrostErr != nil && !failed {
+		return
+	}
+
+	value, ok := bus.health.Load(providerKey)
+	if !ok {
+		value, _ = bus.health.LoadOrStore(providerKey, &providerHealth{})
+	}
+	health := value.(*providerHealth)
+
+	health.mu.Lock()
+	var changed bool
+	if failed {
+		health.failures++
+		changed = !health.unhealthy && health.failures >= providerUnhealthyFailures
+		if changed {
+			health.unhealthy = true
+		}
+	} else {
+		changed = health.unhealthy
+		health.failures = 0
+		health.unhealthy = false
+	}
+	failures := health.failures
+	health.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	event := &schemas.ProviderEvent{
+		Provider: providerKey,
+		Healthy:  !failed,
+	}
+	if failed {
+		event.Failures = failures
+		event.Error = bifrostErr
+	}
+	bus.publish(schemas.Event{Type: schemas.EventProviderHealthChanged, Provider: event})
+}
+
+func (bus *eventBus) isUnhealthy(providerKey schemas.ModelProvider) bool {
+	value, ok := bus.health.Load(providerKey)
+	if !ok {
+		return false
+	}
+	health := value.(*providerHealth)
+	health.mu.Lock()
+	defer health.mu.Unlock()
+	return health.unhealthy
+}
@@ -0,0 +1,316 @@
+package bifrost
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// WEIGHTED FAIR QUEUING
+// ============================================================================
+
+// With fair queuing, requests wait in the per-tenant queues of a fairQueue, and the provider
+// channel only carries a token per request to wake a worker. The worker that takes a token
+// serves the request the fair queue picks, whichever request sent the token, so the FIFO order
+// of the channel no longer decides who goes first. Requests blocked waiting for channel space
+// are already in the fair queue, so they are picked fairly too.
+//
+// Requests are picked by start-time fair queuing: a request's start tag is the later of the
+// queue's virtual time and the finish tag of its tenant's previous request, the finish tag adds
+// 1/weight to it, and the request with the smallest start tag is served next.
+
+// fairQueueWaitWeight is the weight of the latest sample in the wait time average of a tenant.
+const fairQueueWaitWeight = 0.2
+
+// fairQueuing holds the fair queues of every provider.
+type fairQueuing struct {
+	config atomic.Pointer[schemas.FairQueuingConfig] // Nil when fair queuing is off
+	queues sync.Map                                  // Provider -> *fairQueue
+}
+
+// fairQueue is the queue of a provider, split by tenant.
+type fairQueue struct {
+	mu          sync.Mutex
+	tenants     map[string]*fairTenant
+	virtualTime float64 // Start tag of the last request served
+	seq         uint64  // Breaks ties between equal start tags in arrival order
+}
+
+// fairTenant is the queue of a tenant for a provider.
+type fairTenant struct {
+	name       string
+	weight     float64
+	items      []*fairItem
+	lastFinish float64 // Finish tag of the tenant's last queued request
+	dispatched int64
+	dropped    int64
+	avgWaitNs  float64
+}
+
+// fairItem is a request waiting in a tenant queue.
+type fairItem struct {
+	msg        ChannelMessage
+	tenant     *fairTenant
+	start      float64
+	seq        uint64
+	enqueuedAt time.Time
+	admitted   bool // Its token is in the provider channel
+}
+
+// newFairQueuing creates the fair queues, with fair queuing on if config is set.
+func newFairQueuing(config *schemas.FairQueuingConfig) *fairQueuing {
+	fq := &fairQueuing{}
+	fq.config.Store(config)
+	return fq
+}
+
+// queue returns the fair queue of a provider, creating it if needed.
+func (f *fairQueuing) queue(providerKey schemas.ModelProvider) *fairQueue {
+	if value, ok := f.queues.Load(providerKey); ok {
+		return value.(*fairQueue)
+	}
+	value, _ := f.queues.LoadOrStore(providerKey, &fairQueue{tenants: make(map[string]*fairTenant)})
+	return value.(*fairQueue)
+}
+
+// tenantWeight returns the weight of a tenant in config.
+func tenantWeight(config *schemas.FairQueuingConfig, tenant string) float64 {
+	if weight, ok := config.Weights[tenant]; ok && weight > 0 {
+		return weight
+	}
+	if config.DefaultWeight > 0 {
+		return config.DefaultWeight
+	}
+	return 1
+}
+
+// tenant returns the queue of a tenant, creating it if needed, with its weight updated from config.
+// Callers hold q.mu.
+func (q *fairQueue) tenant(name string, config *schemas.FairQueuingConfig) *fairTenant {
+	tenant, ok := q.tenants[name]
+	if !ok {
+		tenant = &fairTenant{name: name}
+		q.tenants[name] = tenant
+	}
+	tenant.weight = tenantWeight(config, name)
+	return tenant
+}
+
+// push adds a request to the queue of its tenant. Callers hold q.mu.
+func (q *fairQueue) push(tenant *fairTenant, msg *ChannelMessage, admitted bool) *fairItem {
+	q.seq++
+	item := &fairItem{
+		msg:        *msg,
+		tenant:     tenant,
+		start:      max(q.virtualTime, tenant.lastFinish),
+		seq:        q.seq,
+		enqueuedAt: time.Now(),
+		admitted:   admitted,
+	}
+	tenant.lastFinish = item.start + 1/tenant.weight
+	tenant.items = append(tenant.items, item)
+	return item
+}
+
+// pop removes the request to serve next, false if the queue is empty.
+func (q *fairQueue) pop() (ChannelMessage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var next *fairItem
+	for _, tenant := range q.tenants {
+		if len(tenant.items) == 0 {
+			continue
+		}
+		if head := tenant.items[0]; next == nil || head.start < next.start || (head.start == next.start && head.seq < next.seq) {
+			next = head
+		}
+	}
+	if next == nil {
+		return ChannelMessage{}, false
+	}
+
+	tenant := next.tenant
+	tenant.items[0] = nil
+	tenant.items = tenant.items[1:]
+	q.virtualTime = next.start
+	tenant.dispatched++
+	wait := float64(time.Since(next.enqueuedAt))
+	if tenant.avgWaitNs == 0 {
+		tenant.avgWaitNs = wait
+	} else {
+		tenant.avgWaitNs = tenant.avgWaitNs*(1-fairQueueWaitWeight) + wait*fairQueueWaitWeight
+	}
+	return next.msg, true
+}
+
+// remove takes a request out of its tenant queue, false if it was already served or removed.
+// Callers hold q.mu.
+func (q *fairQueue) remove(item *fairItem) bool {
+	tenant := item.tenant
+	index := slices.Index(tenant.items, item)
+	if index < 0 {
+		return false
+	}
+	tenant.items = slices.Delete(tenant.items, index, index+1)
+	// The tenant only gives back its share if no later request was tagged after this one
+	if index == len(tenant.items) {
+		tenant.lastFinish = item.start
+	}
+	return true
+}
+
+// evictionVictim returns the newest admitted request of the tenant furthest above its share of
+// the queue, if that tenant is further above its share than the incoming tenant would be with
+// one more request. Callers hold q.mu.
+func (q *fairQueue) evictionVictim(incoming *fairTenant) *fairItem {
+	var heaviest *fairTenant
+	for _, tenant := range q.tenants {
+		if tenant != incoming && len(tenant.items) > 0 && (heaviest == nil || float64(len(tenant.items))/tenant.weight > float64(len(heaviest.items))/heaviest.weight) {
+			heaviest = tenant
+		}
+	}
+	if heaviest == nil || float64(len(heaviest.items))/heaviest.weight <= float64(len(incoming.items)+1)/incoming.weight {
+		return nil
+	}
+	for i := len(heaviest.items) - 1; i >= 0; i-- {
+		if heaviest.items[i].admitted {
+			return heaviest.items[i]
+		}
+	}
+	return nil
+}
+
+// stats returns the queue of every tenant that used it.
+func (q *fairQueue) stats(providerKey schemas.ModelProvider) []schemas.TenantQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := make([]schemas.TenantQueueStats, 0, len(q.tenants))
+	for _, tenant := range q.tenants {
+		stats = append(stats, schemas.TenantQueueStats{
+			Provider:   providerKey,
+			Tenant:     tenant.name,
+			Weight:     tenant.weight,
+			Queued:     len(tenant.items),
+			Dispatched: tenant.dispatched,
+			Dropped:    tenant.dropped,
+			AvgWaitMs:  tenant.avgWaitNs / float64(time.Millisecond),
+		})
+	}
+	return stats
+}
+
+// getTenant returns the tenant of a request, empty if it has none.
+func getTenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(schemas.BifrostContextKeyTenant).(string)
+	return tenant
+}
+
+// enqueueFairRequest queues msg in the fair queue of its provider and sends its token to the
+// provider channel, honouring dropExcessRequests and reporting the queue status while it waits
+// for space.
+func (bifrost *Bifrost) enqueueFairRequest(ctx context.Context, config *schemas.FairQueuingConfig, queue chan ChannelMessage, msg *ChannelMessage, reporter *queueReporter) *schemas.BifrostError {
+	tracker := bifrost.getQueueTracker(msg.Provider)
+	fq := bifrost.fairQueuing.queue(msg.Provider)
+	token := ChannelMessage{fairQueued: true}
+
+	fq.mu.Lock()
+	tenant := fq.tenant(getTenant(ctx), config)
+
+	if bifrost.dropExcessRequests.Load() {
+		// Sent under the lock, so a worker taking the token finds the request in the queue
+		select {
+		case queue <- token:
+			fq.push(tenant, msg, true)
+			fq.mu.Unlock()
+			reporter.entered(tracker.enqueued.Add(1))
+			return nil
+		default:
+		}
+
+		// The queue is full: the incoming request takes the place, and the token, of a request
+		// of a tenant further above its share, or is dropped
+		victim := fq.evictionVictim(tenant)
+		if victim == nil {
+			tenant.dropped++
+			fq.mu.Unlock()
+			bifrost.logger.Warn("Request dropped: queue is full, please increase the queue size or set dropExcessRequests to false")
+			return newBifrostErrorFromMsg("request dropped: queue is full", schemas.ErrorOriginBifrostInternal)
+		}
+		fq.remove(victim)
+		victim.tenant.dropped++
+		fq.push(tenant, msg, true)
+		fq.mu.Unlock()
+
+		tracker.dequeued.Add(1)
+		reporter.entered(tracker.enqueued.Add(1))
+		bifrost.logger.Warn("Request of tenant %q dropped: queue is full, making room for tenant %q", victim.tenant.name, tenant.name)
+		select {
+		case victim.msg.Err <- *newBifrostErrorFromMsg("request dropped: queue is full", schemas.ErrorOriginBifrostInternal):
+		default:
+		}
+		return nil
+	}
+
+	item := fq.push(tenant, msg, false)
+	fq.mu.Unlock()
+
+	admit := func() {
+		fq.mu.Lock()
+		item.admitted = true
+		fq.mu.Unlock()
+		reporter.entered(tracker.enqueued.Add(1))
+	}
+
+	for {
+		select {
+		case queue <- token:
+			admit()
+			return nil
+		case <-ctx.Done():
+			fq.mu.Lock()
+			removed := fq.remove(item)
+			fq.mu.Unlock()
+			if removed {
+				return newBifrostErrorFromMsg("request cancelled while waiting for queue space", schemas.ErrorOriginClientRequest)
+			}
+			// A worker already took the request with the token of another one, which still
+			// counts on this token to be served
+			queue <- token
+			admit()
+			return nil
+		case <-reporter.C():
+			reporter.report()
+		}
+	}
+}
+
+// UpdateFairQueuing turns weighted fair queuing across tenants on with the given weights, or off
+// if config is nil. Weights apply to the requests queued from then on; requests already queued
+// are still served fairly when fair queuing is turned off.
+func (bifrost *Bifrost) UpdateFairQueuing(config *schemas.FairQueuingConfig) {
+	bifrost.fairQueuing.config.Store(config)
+}
+
+// GetTenantQueueStats returns the fair queue of every tenant of a provider, or of every provider
+// if providerKey is empty, sorted by provider and tenant.
+func (bifrost *Bifrost) GetTenantQueueStats(providerKey schemas.ModelProvider) []schemas.TenantQueueStats {
+	var stats []schemas.TenantQueueStats
+	bifrost.fairQueuing.queues.Range(func(key, value any) bool {
+		if providerKey == "" || key.(schemas.ModelProvider) == providerKey {
+			stats = append(stats, value.(*fairQueue).stats(key.(schemas.ModelProvider))...)
+		}
+		return true
+	})
+	slices.SortFunc(stats, func(a, b schemas.TenantQueueStats) int {
+		return cmp.Or(cmp.Compare(a.Provider, b.Provider), cmp.Compare(a.Tenant, b.Tenant))
+	})
+	return stats
+}
@@ -0,0 +1,211 @@
+// Package language enforces the language of responses. Detect identifies the language of a
+// text from its script and, for Latin script languages, its most common words; Plugin is a
+// PostHook plugin that re-prompts the model or machine-translates completions that are not in
+// the required language.
+package language
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Detection is the language detected in a text.
+type Detection struct {
+	Language   string  // ISO 639-1 code, empty if undetermined
+	Confidence float64 // In [0, 1]
+}
+
+// minDetectionWords is the number of words below which Latin script text is undetermined.
+const minDetectionWords = 4
+
+// ignoredSpans matches fenced code blocks, inline code and URLs, which are usually English
+// whatever the language of the text around them.
+var ignoredSpans = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`|https?://\\S+")
+
+// scriptLanguages maps the scripts used by a single major language to it. Han and Cyrillic
+// are handled separately, as they are shared by several languages.
+var scriptLanguages = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+}
+
+// stopwords are frequent words distinctive of each Latin script language. Words common to
+// several of the languages, such as "de" or "que", are left out.
+var stopwords = map[string]map[string]bool{
+	"en": wordSet("the and of to that it with for this you be was have not on are but they from what which will can would there their an or if has"),
+	"es": wordSet("el los las y del por pero más está muy también esto hay sus cuando donde tiene puede hacer porque sobre entre todos"),
+	"fr": wordSet("le les et est des une du dans pour pas qui sur avec ce sont vous nous mais elle être cette très aussi leur comme"),
+	"de": wordSet("der die das und ist nicht ein eine zu den mit von sich auf für auch ich sie wir dem wird sind oder aber wenn"),
+	"it": wordSet("il gli della che di è per non sono del anche questo alla più nel ha delle essere molto quando perché"),
+	"pt": wordSet("os não uma um do da em para com é são mais também isso muito você pelo pela seu sua está ao"),
+	"nl": wordSet("het een van niet dat op te zijn voor ook maar wordt er naar bij hoe wat worden kan deze dit"),
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+// Detect returns the language of a text. Code and URLs are ignored. Texts in a script of a
+// single language are detected from their script, Chinese and Japanese from their use of kana,
+// and Latin script texts from their stopwords.
+func Detect(text string) Detection {
+	text = ignoredSpans.ReplaceAllString(text, " ")
+
+	var letters, latin, han, kana, cyrillic, ukrainian int
+	scripts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				ukrainian++
+			}
+		default:
+			for _, script := range scriptLanguages {
+				if unicode.Is(script.table, r) {
+					scripts[script.language]++
+					break
+				}
+			}
+		}
+	}
+	if letters == 0 {
+		return Detection{}
+	}
+
+	// The script most letters are in
+	best, bestCount := "latin", latin
+	candidates := map[string]int{"cjk": han + kana, "cyrillic": cyrillic}
+	for language, count := range scripts {
+		candidates[language] = count
+	}
+	for script, count := range candidates {
+		if count > bestCount {
+			best, bestCount = script, count
+		}
+	}
+	share := float64(bestCount) / float64(letters)
+
+	switch best {
+	case "latin":
+		detection := detectLatin(text)
+		detection.Confidence *= share
+		return detection
+	case "cjk":
+		// Japanese mixes kanji with kana, Chinese has none
+		if kana > 0 && float64(kana)/float64(han+kana) > 0.1 {
+			return Detection{Language: "ja", Confidence: share}
+		}
+		return Detection{Language: "zh", Confidence: share}
+	case "cyrillic":
+		if ukrainian > 0 {
+			return Detection{Language: "uk", Confidence: share}
+		}
+		return Detection{Language: "ru", Confidence: share}
+	default:
+		return Detection{Language: best, Confidence: share}
+	}
+}
+
+// detectLatin detects a Latin script language from the stopwords of a text. The confidence
+// grows with the share of stopwords of the best language among the words of the text and with
+// its lead over the runner-up.
+func detectLatin(text string) Detection {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) < minDetectionWords {
+		return Detection{}
+	}
+
+	hits := make(map[string]int, len(stopwords))
+	for _, word := range words {
+		for language, set := range stopwords {
+			if set[word] {
+				hits[language]++
+			}
+		}
+	}
+
+	best, bestHits, secondHits := "", 0, 0
+	for language, count := range hits {
+		switch {
+		case count > bestHits || count == bestHits && language < best:
+			if best != "" {
+				secondHits = max(secondHits, bestHits)
+			}
+			best, bestHits = language, count
+		case count > secondHits:
+			secondHits = count
+		}
+	}
+	if bestHits == 0 {
+		return Detection{}
+	}
+
+	// The stopwords of a language make up a fifth or more of the words of ordinary prose
+	coverage := min(1, float64(bestHits)/(0.2*float64(len(words))))
+	lead := float64(bestHits-secondHits) / float64(bestHits)
+	return Detection{Language: best, Confidence: coverage * lead}
+}
+
+// Normalize returns the ISO 639-1 code of a language tag, e.g. "en" for "en-US".
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+// languageNames are the English names of the languages Detect identifies, used in the
+// instructions of correction requests.
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"el": "Greek",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"th": "Thai",
+	"uk": "Ukrainian",
+	"zh": "Chinese",
+}
+
+// Name returns the English name of a language code, or the code if it is unknown.
+func Name(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
@@ -0,0 +1,257 @@
+package language
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// PluginName is the name of the language enforcement plugin.
+const PluginName = "language-enforcement"
+
+// Action is how the plugin corrects a response in the wrong language.
+type Action string
+
+const (
+	ActionReprompt  Action = "reprompt"  // Ask the same model again to answer in the required language
+	ActionTranslate Action = "translate" // Translate the response with PluginConfig.TranslationModel
+)
+
+const (
+	// DefaultMinConfidence is the detection confidence below which a response is left unchanged.
+	DefaultMinConfidence = 0.5
+	// DefaultMinLength is the length in characters below which a response is left unchanged.
+	DefaultMinLength = 20
+)
+
+// ContextKeyLanguage overrides the plugin's required language for a single request, an empty
+// string disables enforcement.
+const ContextKeyLanguage schemas.BifrostContextKey = "bifrost-response-language" // string
+
+const (
+	// contextKeyCorrection marks the requests made by the plugin, which are not checked again.
+	contextKeyCorrection schemas.BifrostContextKey = "bifrost-language-correction" // bool
+	// contextKeyRequest holds the original request, needed to re-prompt the model.
+	contextKeyRequest schemas.BifrostContextKey = "bifrost-language-request" // *schemas.BifrostRequest
+)
+
+// Client is the subset of the Bifrost client used for correction requests, satisfied by *bifrost.Bifrost.
+type Client interface {
+	ChatCompletionRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError)
+}
+
+// PluginConfig configures the language enforcement plugin.
+type PluginConfig struct {
+	Language         string                 // Required language as an ISO 639-1 code or language tag, e.g. "de" or "pt-BR"; empty to only enforce per request
+	Action           Action                 // ActionReprompt if empty
+	Client           Client                 // Client for correction requests, see Plugin.SetClient
+	TranslationModel *schemas.Fallback      // Model translating responses, required for ActionTranslate
+	MinConfidence    float64                // DefaultMinConfidence if 0
+	MinLength        int                    // DefaultMinLength if 0
+	Detect           func(string) Detection // Language detector, Detect if nil
+}
+
+// Plugin is a PostHook plugin that detects response choices that are not in the required
+// language and corrects them, either by re-prompting the model that produced them or by
+// translating them with a configured model. Every correction is recorded in
+// ExtraFields.LanguageCorrections; when it fails, the original content is kept. Only complete
+// (non-streaming) responses are checked, and only chat completions can be re-prompted.
+type Plugin struct {
+	config PluginConfig
+
+	mu     sync.RWMutex
+	client Client
+}
+
+// NewPlugin creates a language enforcement plugin.
+func NewPlugin(config PluginConfig) (*Plugin, error) {
+	if config.Action == "" {
+		config.Action = ActionReprompt
+	}
+	switch config.Action {
+	case ActionReprompt:
+	case ActionTranslate:
+		if config.TranslationModel == nil || config.TranslationModel.Provider == "" || config.TranslationModel.Model == "" {
+			return nil, fmt.Errorf("language action %q requires a translation model", config.Action)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported language action %q", config.Action)
+	}
+	if config.MinConfidence <= 0 {
+		config.MinConfidence = DefaultMinConfidence
+	}
+	if config.MinLength <= 0 {
+		config.MinLength = DefaultMinLength
+	}
+	if config.Detect == nil {
+		config.Detect = Detect
+	}
+	config.Language = Normalize(config.Language)
+	return &Plugin{config: config, client: config.Client}, nil
+}
+
+// SetClient sets the client used for correction requests. The plugin is usually created
+// before the Bifrost client it is registered with, which is set here once it exists.
+func (p *Plugin) SetClient(client Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.client = client
+}
+
+func (p *Plugin) getClient() Client {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.client
+}
+
+// GetName returns the plugin name.
+func (p *Plugin) GetName() string {
+	return PluginName
+}
+
+// PreHook keeps the request in the context, to re-prompt the model with it.
+func (p *Plugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	if ctx != nil && *ctx != nil {
+		*ctx = context.WithValue(*ctx, contextKeyRequest, req)
+	}
+	return req, nil, nil
+}
+
+// PostHook checks the language of every response choice and corrects the ones in another language.
+func (p *Plugin) PostHook(ctx *context.Context, result *schemas.BifrostResponse, err *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	if err != nil || result == nil || ctx == nil || *ctx == nil {
+		return result, err, nil
+	}
+	if correction, _ := (*ctx).Value(contextKeyCorrection).(bool); correction {
+		return result, err, nil
+	}
+
+	required := p.config.Language
+	if override, ok := (*ctx).Value(ContextKeyLanguage).(string); ok {
+		required = Normalize(override)
+	}
+	if required == "" {
+		return result, err, nil
+	}
+	req, _ := (*ctx).Value(contextKeyRequest).(*schemas.BifrostRequest)
+
+	for i := range result.Choices {
+		choice := &result.Choices[i]
+		if choice.BifrostNonStreamResponseChoice == nil {
+			continue
+		}
+		message := &choice.BifrostNonStreamResponseChoice.Message
+		text := message.Content.Text()
+		if len(text) < p.config.MinLength {
+			continue
+		}
+
+		detection := p.config.Detect(text)
+		if detection.Language == "" || detection.Language == required || detection.Confidence < p.config.MinConfidence {
+			continue
+		}
+
+		correction := schemas.LanguageCorrection{
+			ChoiceIndex: choice.Index,
+			Required:    required,
+			Detected:    detection.Language,
+			Confidence:  detection.Confidence,
+			Action:      string(p.config.Action),
+		}
+		corrected, correctionErr := p.correct(*ctx, req, text, required, &correction)
+		if correctionErr != nil {
+			correction.Error = correctionErr.Error()
+		} else {
+			message.Content = schemas.MessageContent{ContentStr: &corrected}
+			correction.Corrected = true
+		}
+		result.ExtraFields.LanguageCorrections = append(result.ExtraFields.LanguageCorrections, correction)
+	}
+
+	return result, err, nil
+}
+
+// correct requests the content of a message in the required language and checks the language
+// of the answer. The provider, model and usage of the correction request are recorded.
+func (p *Plugin) correct(ctx context.Context, req *schemas.BifrostRequest, text string, required string, correction *schemas.LanguageCorrection) (string, error) {
+	client := p.getClient()
+	if client == nil {
+		return "", fmt.Errorf("no client configured")
+	}
+
+	var correctionReq *schemas.BifrostRequest
+	switch p.config.Action {
+	case ActionReprompt:
+		if req == nil || req.Input.ChatCompletionInput == nil {
+			return "", fmt.Errorf("only chat completions can be re-prompted")
+		}
+		messages := append([]schemas.BifrostMessage{}, *req.Input.ChatCompletionInput...)
+		messages = append(messages,
+			schemas.BifrostMessage{
+				Role:    schemas.ModelChatMessageRoleAssistant,
+				Content: schemas.MessageContent{ContentStr: &text},
+			},
+			schemas.BifrostMessage{
+				Role:    schemas.ModelChatMessageRoleUser,
+				Content: schemas.MessageContent{ContentStr: ptr(fmt.Sprintf("Your previous answer was not in %s. Write the same answer again, entirely in %s.", Name(required), Name(required)))},
+			},
+		)
+		correctionReq = &schemas.BifrostRequest{
+			Provider:  req.Provider,
+			Model:     req.Model,
+			Input:     schemas.RequestInput{ChatCompletionInput: &messages},
+			Params:    req.Params,
+			Fallbacks: req.Fallbacks,
+		}
+	case ActionTranslate:
+		correctionReq = &schemas.BifrostRequest{
+			Provider: p.config.TranslationModel.Provider,
+			Model:    p.config.TranslationModel.Model,
+			Input: schemas.RequestInput{
+				ChatCompletionInput: &[]schemas.BifrostMessage{
+					{
+						Role:    schemas.ModelChatMessageRoleSystem,
+						Content: schemas.MessageContent{ContentStr: ptr(fmt.Sprintf("Translate the user's text into %s. Keep its formatting, code and URLs unchanged. Reply with the translation only.", Name(required)))},
+					},
+					{
+						Role:    schemas.ModelChatMessageRoleUser,
+						Content: schemas.MessageContent{ContentStr: &text},
+					},
+				},
+			},
+			Params: &schemas.ModelParameters{Temperature: ptr(0.0)},
+		}
+	}
+
+	response, bifrostErr := client.ChatCompletionRequest(context.WithValue(ctx, contextKeyCorrection, true), correctionReq)
+	if bifrostErr != nil {
+		return "", fmt.Errorf("correction request failed: %s", bifrostErr.Error.Message)
+	}
+	correction.Provider = string(response.ExtraFields.Provider)
+	correction.Model = response.Model
+	correction.Usage = response.Usage
+	if len(response.Choices) == 0 || response.Choices[0].BifrostNonStreamResponseChoice == nil {
+		return "", fmt.Errorf("correction response has no choices")
+	}
+
+	corrected := strings.TrimSpace(response.Choices[0].BifrostNonStreamResponseChoice.Message.Content.Text())
+	if corrected == "" {
+		return "", fmt.Errorf("correction response is empty")
+	}
+	if detection := p.config.Detect(corrected); detection.Language != "" && detection.Language != required && detection.Confidence >= p.config.MinConfidence {
+		return "", fmt.Errorf("correction is in %s", Name(detection.Language))
+	}
+	return corrected, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// Cleanup is a no-op for this plugin.
+func (p *Plugin) Cleanup() error {
+	return nil
+}
@@ -0,0 +1,226 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// LOOP DETECTION
+// ============================================================================
+
+// defaultLoopCorrectionMessage is the corrective system message appended to requests whose agent
+// loop repeats itself, with the tool name and the number of calls filled in.
+const defaultLoopCorrectionMessage = "You have called the tool %s %d times with the same arguments and got the same result each time. " +
+	"Calling it again will not give a different result. Do not repeat this call: use the results you already have, " +
+	"try a different approach, or answer with what you know."
+
+// loopDetector detects agent loops in the history of chat requests.
+type loopDetector struct {
+	threshold  int
+	window     int
+	similarity float64
+	action     schemas.LoopDetectionAction
+	message    string
+	abortAfter int
+}
+
+// loopToolCall is a tool call of the history of a request, fingerprinted by its arguments and result.
+type loopToolCall struct {
+	name        string
+	fingerprint string   // Canonical arguments and result
+	tokens      []string // Distinct words of the fingerprint, for similarity
+}
+
+// newLoopDetector creates the loop detector with the defaults filled in, nil if config is nil.
+func newLoopDetector(config *schemas.LoopDetectionConfig) *loopDetector {
+	if config == nil {
+		return nil
+	}
+	detector := &loopDetector{
+		threshold:  schemas.DefaultLoopDetectionThreshold,
+		window:     schemas.DefaultLoopDetectionWindow,
+		similarity: schemas.DefaultLoopDetectionSimilarity,
+		action:     schemas.LoopDetectionIntervene,
+		message:    config.Message,
+		abortAfter: max(config.AbortAfter, 0),
+	}
+	if config.Threshold > 1 {
+		detector.threshold = config.Threshold
+	}
+	if config.Window > 0 {
+		detector.window = config.Window
+	}
+	if config.Similarity > 0 {
+		detector.similarity = min(config.Similarity, 1)
+	}
+	if config.Action != "" {
+		detector.action = config.Action
+	}
+	return detector
+}
+
+// detect returns the repeated tool call of messages, nil if the latest tool call is not repeated
+// at least threshold times among the latest window calls.
+func (d *loopDetector) detect(messages []schemas.BifrostMessage) *schemas.LoopDetectedError {
+	calls := loopToolCalls(messages)
+	if len(calls) < d.threshold {
+		return nil
+	}
+	calls = calls[max(len(calls)-d.window, 0):]
+
+	latest := calls[len(calls)-1]
+	repetitions := 0
+	for _, call := range calls {
+		if call.name == latest.name && (call.fingerprint == latest.fingerprint || (d.similarity < 1 && tokenSimilarity(call.tokens, latest.tokens) >= d.similarity)) {
+			repetitions++
+		}
+	}
+	if repetitions < d.threshold {
+		return nil
+	}
+	return &schemas.LoopDetectedError{Tool: latest.name, Repetitions: repetitions, Threshold: d.threshold}
+}
+
+// applyLoopDetection checks the history of a chat request for an agent loop repeating the same
+// tool call. Depending on the action, a request caught in a loop fails with a LoopDetected
+// error, or continues with a corrective system message appended, which is reported as a
+// warning. It returns the possibly corrected request.
+func (bifrost *Bifrost) applyLoopDetection(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType) (*schemas.BifrostRequest, *schemas.BifrostError) {
+	detector := bifrost.loopDetector
+	if detector == nil || req.Input.ChatCompletionInput == nil || (requestType != schemas.ChatCompletionRequest && requestType != schemas.ChatCompletionStreamRequest) {
+		return req, nil
+	}
+	if enabled, ok := ctx.Value(schemas.BifrostContextKeyLoopDetection).(bool); ok && !enabled {
+		return req, nil
+	}
+
+	loopErr := detector.detect(*req.Input.ChatCompletionInput)
+	if loopErr == nil {
+		return req, nil
+	}
+	if detector.action == schemas.LoopDetectionAbort || (detector.abortAfter > 0 && loopErr.Repetitions >= detector.abortAfter) {
+		bifrost.logger.Warn(fmt.Sprintf("aborting request: %s", loopErr.Error()))
+		return nil, newLoopDetectedError(req, loopErr)
+	}
+
+	message := fmt.Sprintf(defaultLoopCorrectionMessage, loopErr.Tool, loopErr.Repetitions)
+	if detector.message != "" {
+		message = detector.message
+	}
+	messages := make([]schemas.BifrostMessage, 0, len(*req.Input.ChatCompletionInput)+1)
+	messages = append(messages, *req.Input.ChatCompletionInput...)
+	messages = append(messages, schemas.BifrostMessage{
+		Role:    schemas.ModelChatMessageRoleSystem,
+		Content: schemas.MessageContent{ContentStr: &message},
+	})
+
+	correctedReq := *req
+	correctedReq.Input.ChatCompletionInput = &messages
+	schemas.AddWarning(ctx, schemas.WarningEnforced, schemas.WarningOriginBifrost,
+		loopErr.Error()+", a corrective system message was added")
+	return &correctedReq, nil
+}
+
+// loopToolCalls returns the tool calls of messages in order, each fingerprinted with the result
+// of its tool message.
+func loopToolCalls(messages []schemas.BifrostMessage) []loopToolCall {
+	results := make(map[string]string)
+	for _, message := range messages {
+		if message.Role == schemas.ModelChatMessageRoleTool && message.ToolMessage != nil && message.ToolCallID != nil {
+			results[*message.ToolCallID] = message.Content.Text()
+		}
+	}
+
+	var calls []loopToolCall
+	for _, message := range messages {
+		if message.AssistantMessage == nil || message.ToolCalls == nil {
+			continue
+		}
+		for _, toolCall := range *message.ToolCalls {
+			if toolCall.Function.Name == nil {
+				continue
+			}
+			fingerprint := canonicalArguments(toolCall.Function.Arguments)
+			if toolCall.ID != nil {
+				fingerprint += "\n" + strings.TrimSpace(results[*toolCall.ID])
+			}
+			calls = append(calls, loopToolCall{
+				name:        *toolCall.Function.Name,
+				fingerprint: fingerprint,
+				tokens:      distinctTokens(fingerprint),
+			})
+		}
+	}
+	return calls
+}
+
+// canonicalArguments returns the JSON arguments of a tool call with sorted keys and no
+// whitespace, so the same arguments fingerprint the same whatever their formatting. Arguments
+// that are not valid JSON are returned trimmed.
+func canonicalArguments(arguments string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(arguments), &value); err != nil {
+		return strings.TrimSpace(arguments)
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return strings.TrimSpace(arguments)
+	}
+	return string(canonical)
+}
+
+// distinctTokens returns the distinct lowercased words and numbers of text.
+func distinctTokens(text string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, token := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// tokenSimilarity returns the Jaccard similarity of two sets of distinct tokens, 1 if both are empty.
+func tokenSimilarity(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	set := make(map[string]bool, len(a))
+	for _, token := range a {
+		set[token] = true
+	}
+	shared := 0
+	for _, token := range b {
+		if set[token] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// newLoopDetectedError returns the error of a request aborted because its agent loop repeats itself.
+func newLoopDetectedError(req *schemas.BifrostRequest, loopErr *schemas.LoopDetectedError) *schemas.BifrostError {
+	return &schemas.BifrostError{
+		IsBifrostError: true,
+		Provider:       req.Provider,
+		StatusCode:     Ptr(400),
+		Type:           Ptr(schemas.LoopDetected),
+		Origin:         schemas.ErrorOriginPolicy,
+		Error: schemas.ErrorField{
+			Type:    Ptr(schemas.LoopDetected),
+			Message: loopErr.Error(),
+			Error:   loopErr,
+		},
+		AllowFallbacks: Ptr(false),
+	}
+}
@@ -0,0 +1,278 @@
+// Package markdown converts markdown model output for consumers that cannot render it.
+// It extracts fenced code blocks, strips markdown down to plain text and renders
+// sanitized HTML. The supported syntax is the subset models commonly produce: ATX
+// headings, fenced code, block quotes, lists, pipe tables, rules and inline emphasis,
+// code spans, links, images and autolinks. Raw HTML is never passed through.
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockCode
+	blockQuote
+	blockList
+	blockRule
+	blockTable
+)
+
+// block is a parsed block level element.
+type block struct {
+	kind     blockKind
+	level    int        // Heading level
+	language string     // Code block language tag
+	lines    []string   // Paragraph, heading and code lines
+	items    []listItem // List items
+	children []block    // Block quote contents
+	rows     [][]string // Table rows, the first row is the header
+}
+
+// listItem is a single list entry. Nested lists are flattened, with depth recording the nesting.
+type listItem struct {
+	depth   int
+	ordered bool
+	number  int
+	text    string
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	fencePattern   = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+	rulePattern    = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	listPattern    = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])(?:[ \t]+(.*))?$`)
+	quotePattern   = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	tableSeparator = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+)
+
+const (
+	indentTabWidth  = 4  // Columns a tab advances to when measuring list indentation
+	indentPerLevel  = 2  // Columns of indentation per nested list level
+	maxQuoteNesting = 32 // Deeper block quotes are treated as paragraphs
+)
+
+// ExtractCodeBlocks returns the fenced code blocks of a markdown document in order,
+// including blocks nested in block quotes. An unclosed fence extends to the end of the
+// document, so code in truncated responses is still returned.
+func ExtractCodeBlocks(text string) []schemas.CodeBlock {
+	var codeBlocks []schemas.CodeBlock
+	var collect func(blocks []block)
+	collect = func(blocks []block) {
+		for _, b := range blocks {
+			switch b.kind {
+			case blockCode:
+				codeBlocks = append(codeBlocks, schemas.CodeBlock{Language: b.language, Code: strings.Join(b.lines, "\n")})
+			case blockQuote:
+				collect(b.children)
+			}
+		}
+	}
+	collect(parseBlocks(splitLines(text), 0))
+	return codeBlocks
+}
+
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimRight(text, "\n"), "\n")
+}
+
+// parseBlocks parses lines into block level elements.
+func parseBlocks(lines []string, depth int) []block {
+	var blocks []block
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			i++
+			continue
+		}
+
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			b, next := parseFence(lines, i, m)
+			blocks = append(blocks, b)
+			i = next
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, block{kind: blockHeading, level: len(m[1]), lines: []string{m[2]}})
+			i++
+			continue
+		}
+
+		if rulePattern.MatchString(line) {
+			blocks = append(blocks, block{kind: blockRule})
+			i++
+			continue
+		}
+
+		if quotePattern.MatchString(line) && depth < maxQuoteNesting {
+			var quoted []string
+			for ; i < len(lines); i++ {
+				m := quotePattern.FindStringSubmatch(lines[i])
+				if m == nil {
+					break
+				}
+				quoted = append(quoted, m[1])
+			}
+			blocks = append(blocks, block{kind: blockQuote, children: parseBlocks(quoted, depth+1)})
+			continue
+		}
+
+		if listPattern.MatchString(line) {
+			b, next := parseList(lines, i)
+			blocks = append(blocks, b)
+			i = next
+			continue
+		}
+
+		if i+1 < len(lines) && strings.Contains(line, "|") && tableSeparator.MatchString(lines[i+1]) {
+			b, next := parseTable(lines, i)
+			blocks = append(blocks, b)
+			i = next
+			continue
+		}
+
+		paragraph := []string{strings.TrimSpace(line)}
+		for i++; i < len(lines) && !startsBlock(lines, i); i++ {
+			paragraph = append(paragraph, strings.TrimSpace(lines[i]))
+		}
+		blocks = append(blocks, block{kind: blockParagraph, lines: paragraph})
+	}
+	return blocks
+}
+
+// startsBlock reports whether line i ends a paragraph.
+func startsBlock(lines []string, i int) bool {
+	line := lines[i]
+	return strings.TrimSpace(line) == "" ||
+		fencePattern.MatchString(line) ||
+		headingPattern.MatchString(line) ||
+		rulePattern.MatchString(line) ||
+		quotePattern.MatchString(line) ||
+		listPattern.MatchString(line)
+}
+
+// parseFence parses a fenced code block starting at line start.
+func parseFence(lines []string, start int, m []string) (block, int) {
+	indent, fence := len(m[1]), m[2]
+	language := ""
+	if fields := strings.Fields(m[3]); len(fields) > 0 {
+		language = fields[0]
+	}
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if len(lines[i])-len(trimmed) <= 3 && strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]+" \t") == "" {
+			i++
+			break
+		}
+		code = append(code, removeIndent(lines[i], indent))
+	}
+	return block{kind: blockCode, language: language, lines: code}, i
+}
+
+// removeIndent removes up to n leading spaces.
+func removeIndent(line string, n int) string {
+	for n > 0 && strings.HasPrefix(line, " ") {
+		line = line[1:]
+		n--
+	}
+	return line
+}
+
+// parseList parses consecutive list items starting at line start. Indented lines that
+// do not start an item continue the previous item.
+func parseList(lines []string, start int) (block, int) {
+	var items []listItem
+	baseIndent := -1
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			// A blank line ends the list unless another item follows.
+			if i+1 < len(lines) && listPattern.MatchString(lines[i+1]) && !rulePattern.MatchString(lines[i+1]) {
+				continue
+			}
+			break
+		}
+		if m := listPattern.FindStringSubmatch(line); m != nil && !rulePattern.MatchString(line) {
+			indent := indentWidth(m[1])
+			if baseIndent == -1 || indent < baseIndent {
+				baseIndent = indent
+			}
+			item := listItem{depth: (indent - baseIndent) / indentPerLevel, text: strings.TrimSpace(m[3])}
+			if marker := m[2]; marker[0] >= '0' && marker[0] <= '9' {
+				item.ordered = true
+				item.number, _ = strconv.Atoi(marker[:len(marker)-1])
+			}
+			items = append(items, item)
+			continue
+		}
+		if len(items) == 0 || indentWidth(line) == 0 && startsBlock(lines, i) {
+			break
+		}
+		last := &items[len(items)-1]
+		last.text = strings.TrimSpace(last.text + "\n" + strings.TrimSpace(line))
+	}
+	return block{kind: blockList, items: items}, i
+}
+
+func indentWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += indentTabWidth - width%indentTabWidth
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// parseTable parses a pipe table whose header is at line start.
+func parseTable(lines []string, start int) (block, int) {
+	rows := [][]string{splitTableRow(lines[start])}
+	i := start + 2
+	for ; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+		rows = append(rows, splitTableRow(lines[i]))
+	}
+	return block{kind: blockTable, rows: rows}, i
+}
+
+// splitTableRow splits a table row into cells, honoring escaped pipes.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
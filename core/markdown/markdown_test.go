@@ -0,0 +1,133 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestExtractCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []schemas.CodeBlock
+	}{
+		{
+			name: "no code",
+			text: "Just *text*.",
+		},
+		{
+			name: "language tags and order",
+			text: "Intro\n```go\nfmt.Println(1)\n```\nMiddle\n~~~\nplain\n~~~",
+			want: []schemas.CodeBlock{{Language: "go", Code: "fmt.Println(1)"}, {Code: "plain"}},
+		},
+		{
+			name: "shorter fence does not close",
+			text: "````md\n```\ninner\n```\n````",
+			want: []schemas.CodeBlock{{Language: "md", Code: "```\ninner\n```"}},
+		},
+		{
+			name: "indented fence",
+			text: "  ```py\n  x = 1\n    y = 2\n  ```",
+			want: []schemas.CodeBlock{{Language: "py", Code: "x = 1\n  y = 2"}},
+		},
+		{
+			name: "inside block quote",
+			text: "> ```sh\n> ls\n> ```",
+			want: []schemas.CodeBlock{{Language: "sh", Code: "ls"}},
+		},
+		{
+			name: "unclosed fence",
+			text: "```js\nlet a = 1;\nlet b",
+			want: []schemas.CodeBlock{{Language: "js", Code: "let a = 1;\nlet b"}},
+		},
+		{
+			name: "crlf line endings",
+			text: "```\r\na\r\nb\r\n```\r\n",
+			want: []schemas.CodeBlock{{Code: "a\nb"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractCodeBlocks(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractCodeBlocks() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrip(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"heading and emphasis", "# Title\n\nSome **bold**, _italic_ and ~~gone~~ text.", "Title\n\nSome bold, italic and gone text."},
+		{"code span and block", "Run `go test`:\n\n```sh\ngo test ./...\n```", "Run go test:\n\ngo test ./..."},
+		{"links and images", "See [docs](https://example.com) and ![logo](logo.png) or <https://a.io>.", "See docs (https://example.com) and logo or https://a.io."},
+		{"link text equal to url", "[https://a.io](https://a.io)", "https://a.io"},
+		{"nested list", "- one\n  - two\n1. first\n2. second", "- one\n  - two\n1. first\n2. second"},
+		{"list item continuation", "- one\n  more", "- one\nmore"},
+		{"table", "| a | b |\n|---|:-:|\n| `1` | 2 \\| 3 |", "a | b\n1 | 2 | 3"},
+		{"rule and quote", "above\n\n---\n\n> quoted *text*", "above\n\nquoted text"},
+		{"escapes", `\*not emphasis\* and snake_case_name`, "*not emphasis* and snake_case_name"},
+		{"unmatched markers", "2 * 3 = 6 and a_b", "2 * 3 = 6 and a_b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Strip(tt.text); got != tt.want {
+				t.Errorf("Strip() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"heading and paragraph", "## Hi\nthere **you**", "<h2>Hi</h2>\n<p>there <strong>you</strong></p>"},
+		{"raw html is escaped", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"code block", "```go\nif a < b {}\n```", "<pre><code class=\"language-go\">if a &lt; b {}\n</code></pre>"},
+		{"safe link", "[x](https://a.io \"title\")", "<p><a href=\"https://a.io\">x</a></p>"},
+		{"unsafe link", "[x](javascript:alert(1))", "<p>x</p>"},
+		{"unsafe image", "![alt](data:image/png;base64,AAAA)", "<p>alt</p>"},
+		{"email autolink", "<me@example.com>", "<p><a href=\"mailto:me@example.com\">me@example.com</a></p>"},
+		{"nested list", "- a\n  1. b\n- c", "<ul>\n<li>a\n<ol>\n<li>b</li>\n</ol>\n</li>\n<li>c</li>\n</ul>"},
+		{"ordered list start", "3. c\n4. d", "<ol start=\"3\">\n<li>c</li>\n<li>d</li>\n</ol>"},
+		{"table", "a|b\n-|-\n1|2", "<table>\n<thead>\n<tr><th>a</th><th>b</th></tr>\n</thead>\n<tbody>\n<tr><td>1</td><td>2</td></tr>\n</tbody>\n</table>"},
+		{"quote and rule", "> q\n\n***", "<blockquote>\n<p>q</p>\n</blockquote>\n<hr>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToHTML(tt.text); got != tt.want {
+				t.Errorf("ToHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com", true},
+		{"HTTP://example.com", true},
+		{"mailto:me@example.com", true},
+		{"/docs/page", true},
+		{"page?a=b:c", true},
+		{"javascript:alert(1)", false},
+		{" JavaScript:alert(1)", false},
+		{"data:text/html,hi", false},
+		{"vbscript:x", false},
+	}
+	for _, tt := range tests {
+		if got := safeURL(tt.url); got != tt.want {
+			t.Errorf("safeURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
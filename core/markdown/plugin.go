@@ -0,0 +1,133 @@
+package markdown
+
+import (
+	"context"
+	"fmt"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// PluginName is the name of the markdown format plugin.
+const PluginName = "markdown-format"
+
+// Format is the output format the plugin converts response content to.
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown" // Leave content unchanged
+	FormatPlain    Format = "plain"    // Strip markdown, see Strip
+	FormatHTML     Format = "html"     // Render sanitized HTML, see ToHTML
+)
+
+// ContextKeyFormat overrides the plugin's configured format for a single request.
+const ContextKeyFormat schemas.BifrostContextKey = "bifrost-markdown-format" // Format
+
+// PluginConfig configures the markdown format plugin.
+type PluginConfig struct {
+	Format            Format // Output format, FormatMarkdown if empty
+	ExtractCodeBlocks bool   // Populate AssistantMessage.CodeBlocks from the original markdown
+}
+
+// Plugin is a PostHook plugin that converts markdown assistant content for downstream
+// services that cannot render it. Only complete (non-streaming) responses are converted,
+// since markdown split across stream chunks cannot be converted reliably. Converting
+// content invalidates character offsets such as MessageCitation indexes.
+type Plugin struct {
+	config PluginConfig
+}
+
+// NewPlugin creates a markdown format plugin.
+func NewPlugin(config PluginConfig) (*Plugin, error) {
+	if config.Format == "" {
+		config.Format = FormatMarkdown
+	}
+	if err := validateFormat(config.Format); err != nil {
+		return nil, err
+	}
+	return &Plugin{config: config}, nil
+}
+
+func validateFormat(format Format) error {
+	switch format {
+	case FormatMarkdown, FormatPlain, FormatHTML:
+		return nil
+	}
+	return fmt.Errorf("unsupported markdown format %q", format)
+}
+
+// GetName returns the plugin name.
+func (p *Plugin) GetName() string {
+	return PluginName
+}
+
+// PreHook is not used by this plugin.
+func (p *Plugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	return req, nil, nil
+}
+
+// PostHook extracts code blocks from and converts the text content of every response choice.
+func (p *Plugin) PostHook(ctx *context.Context, result *schemas.BifrostResponse, err *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	if err != nil || result == nil {
+		return result, err, nil
+	}
+
+	format := p.config.Format
+	if ctx != nil {
+		if override, ok := (*ctx).Value(ContextKeyFormat).(Format); ok {
+			if validateErr := validateFormat(override); validateErr != nil {
+				return result, err, validateErr
+			}
+			format = override
+		}
+	}
+
+	for i := range result.Choices {
+		choice := &result.Choices[i]
+		if choice.BifrostNonStreamResponseChoice == nil {
+			continue
+		}
+		message := &choice.BifrostNonStreamResponseChoice.Message
+
+		if p.config.ExtractCodeBlocks && message.Role == schemas.ModelChatMessageRoleAssistant {
+			var codeBlocks []schemas.CodeBlock
+			forEachText(&message.Content, func(text *string) {
+				codeBlocks = append(codeBlocks, ExtractCodeBlocks(*text)...)
+			})
+			if len(codeBlocks) > 0 {
+				if message.AssistantMessage == nil {
+					message.AssistantMessage = &schemas.AssistantMessage{}
+				}
+				message.AssistantMessage.CodeBlocks = codeBlocks
+			}
+		}
+
+		switch format {
+		case FormatPlain:
+			forEachText(&message.Content, func(text *string) { *text = Strip(*text) })
+		case FormatHTML:
+			forEachText(&message.Content, func(text *string) { *text = ToHTML(*text) })
+		}
+	}
+
+	return result, err, nil
+}
+
+// Cleanup is a no-op for this plugin.
+func (p *Plugin) Cleanup() error {
+	return nil
+}
+
+// forEachText calls fn with every text of a message content.
+func forEachText(content *schemas.MessageContent, fn func(text *string)) {
+	if content.ContentStr != nil {
+		fn(content.ContentStr)
+	}
+	if content.ContentBlocks != nil {
+		for i := range *content.ContentBlocks {
+			block := &(*content.ContentBlocks)[i]
+			if block.Type == schemas.ContentBlockTypeText && block.Text != nil {
+				fn(block.Text)
+			}
+		}
+	}
+}
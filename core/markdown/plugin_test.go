@@ -0,0 +1,65 @@
+package markdown
+
+import (
+	"context"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestPluginPostHook(t *testing.T) {
+	const content = "**Hi**\n```go\nx := 1\n```"
+
+	tests := []struct {
+		name           string
+		config         PluginConfig
+		override       Format // Per request format, none if empty
+		wantContent    string
+		wantCodeBlocks int
+		wantErr        bool
+	}{
+		{name: "unchanged by default", wantContent: content},
+		{name: "plain", config: PluginConfig{Format: FormatPlain}, wantContent: "Hi\n\nx := 1"},
+		{name: "html with code blocks", config: PluginConfig{Format: FormatHTML, ExtractCodeBlocks: true}, wantContent: "<p><strong>Hi</strong></p>\n<pre><code class=\"language-go\">x := 1\n</code></pre>", wantCodeBlocks: 1},
+		{name: "override", config: PluginConfig{Format: FormatHTML}, override: FormatMarkdown, wantContent: content},
+		{name: "invalid override", override: "rtf", wantContent: content, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin, err := NewPlugin(tt.config)
+			if err != nil {
+				t.Fatalf("NewPlugin() error = %v", err)
+			}
+			ctx := context.Background()
+			if tt.override != "" {
+				ctx = context.WithValue(ctx, ContextKeyFormat, tt.override)
+			}
+			text := content
+			result := &schemas.BifrostResponse{Choices: []schemas.BifrostResponseChoice{{
+				BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
+					Message: schemas.BifrostMessage{Role: schemas.ModelChatMessageRoleAssistant, Content: schemas.MessageContent{ContentStr: &text}},
+				},
+			}}}
+
+			result, _, err = plugin.PostHook(&ctx, result, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PostHook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			message := result.Choices[0].BifrostNonStreamResponseChoice.Message
+			if got := *message.Content.ContentStr; got != tt.wantContent {
+				t.Errorf("content = %q, want %q", got, tt.wantContent)
+			}
+			codeBlocks := 0
+			if message.AssistantMessage != nil {
+				codeBlocks = len(message.AssistantMessage.CodeBlocks)
+			}
+			if codeBlocks != tt.wantCodeBlocks {
+				t.Errorf("got %d code blocks, want %d", codeBlocks, tt.wantCodeBlocks)
+			}
+		})
+	}
+
+	if _, err := NewPlugin(PluginConfig{Format: "rtf"}); err == nil {
+		t.Error("NewPlugin() accepted an unsupported format")
+	}
+}
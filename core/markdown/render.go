@@ -0,0 +1,428 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Strip converts markdown to plain text. Formatting markers are removed, code blocks keep
+// their contents, links keep their text followed by the URL in parentheses and images are
+// replaced by their alt text.
+func Strip(text string) string {
+	var out strings.Builder
+	writePlainBlocks(&out, parseBlocks(splitLines(text), 0))
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// ToHTML renders markdown as sanitized HTML. All text is escaped, raw HTML in the input is
+// rendered literally and link and image URLs are limited to http, https, mailto and
+// relative URLs.
+func ToHTML(text string) string {
+	var out strings.Builder
+	writeHTMLBlocks(&out, parseBlocks(splitLines(text), 0))
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// ============================================================================
+// PLAIN TEXT
+// ============================================================================
+
+func writePlainBlocks(out *strings.Builder, blocks []block) {
+	written := false
+	for _, b := range blocks {
+		if b.kind == blockRule {
+			continue // Rules only separate content, the blank line between blocks is enough
+		}
+		if written {
+			out.WriteString("\n")
+		}
+		written = true
+		switch b.kind {
+		case blockParagraph, blockHeading:
+			out.WriteString(renderInline(strings.Join(b.lines, "\n"), false))
+			out.WriteString("\n")
+		case blockCode:
+			for _, line := range b.lines {
+				out.WriteString(line)
+				out.WriteString("\n")
+			}
+		case blockQuote:
+			writePlainBlocks(out, b.children)
+		case blockList:
+			for _, item := range b.items {
+				out.WriteString(strings.Repeat("  ", item.depth))
+				if item.ordered {
+					out.WriteString(strconv.Itoa(item.number) + ". ")
+				} else {
+					out.WriteString("- ")
+				}
+				out.WriteString(renderInline(item.text, false))
+				out.WriteString("\n")
+			}
+		case blockTable:
+			for _, row := range b.rows {
+				cells := make([]string, len(row))
+				for j, cell := range row {
+					cells[j] = renderInline(cell, false)
+				}
+				out.WriteString(strings.Join(cells, " | "))
+				out.WriteString("\n")
+			}
+		}
+	}
+}
+
+// ============================================================================
+// HTML
+// ============================================================================
+
+func writeHTMLBlocks(out *strings.Builder, blocks []block) {
+	for _, b := range blocks {
+		switch b.kind {
+		case blockParagraph:
+			fmt.Fprintf(out, "<p>%s</p>\n", renderInline(strings.Join(b.lines, "\n"), true))
+		case blockHeading:
+			fmt.Fprintf(out, "<h%d>%s</h%d>\n", b.level, renderInline(b.lines[0], true), b.level)
+		case blockCode:
+			out.WriteString("<pre><code")
+			if b.language != "" {
+				fmt.Fprintf(out, ` class="language-%s"`, html.EscapeString(b.language))
+			}
+			out.WriteString(">")
+			for _, line := range b.lines {
+				out.WriteString(html.EscapeString(line))
+				out.WriteString("\n")
+			}
+			out.WriteString("</code></pre>\n")
+		case blockQuote:
+			out.WriteString("<blockquote>\n")
+			writeHTMLBlocks(out, b.children)
+			out.WriteString("</blockquote>\n")
+		case blockList:
+			writeHTMLList(out, b.items)
+		case blockTable:
+			writeHTMLTable(out, b.rows)
+		case blockRule:
+			out.WriteString("<hr>\n")
+		}
+	}
+}
+
+// writeHTMLList renders flattened list items as nested lists.
+func writeHTMLList(out *strings.Builder, items []listItem) {
+	var open []string // Closing tags of the open lists, innermost last
+	for i, item := range items {
+		tag := "ul"
+		if item.ordered {
+			tag = "ol"
+		}
+
+		switch {
+		case len(open) == 0 || item.depth >= len(open):
+			// Nested lists open inside the previous item, which stays open
+			for depth := len(open); depth <= item.depth; depth++ {
+				if item.ordered && item.number != 1 && depth == item.depth {
+					fmt.Fprintf(out, "<ol start=\"%d\">\n", item.number)
+				} else {
+					fmt.Fprintf(out, "<%s>\n", tag)
+				}
+				open = append(open, tag)
+			}
+		default:
+			out.WriteString("</li>\n")
+			for len(open) > item.depth+1 {
+				fmt.Fprintf(out, "</%s>\n</li>\n", open[len(open)-1])
+				open = open[:len(open)-1]
+			}
+			if open[len(open)-1] != tag {
+				fmt.Fprintf(out, "</%s>\n<%s>\n", open[len(open)-1], tag)
+				open[len(open)-1] = tag
+			}
+		}
+
+		fmt.Fprintf(out, "<li>%s", renderInline(item.text, true))
+		if i+1 == len(items) || items[i+1].depth <= item.depth {
+			continue
+		}
+		out.WriteString("\n")
+	}
+
+	for len(open) > 0 {
+		fmt.Fprintf(out, "</li>\n</%s>\n", open[len(open)-1])
+		open = open[:len(open)-1]
+	}
+}
+
+func writeHTMLTable(out *strings.Builder, rows [][]string) {
+	out.WriteString("<table>\n<thead>\n<tr>")
+	for _, cell := range rows[0] {
+		fmt.Fprintf(out, "<th>%s</th>", renderInline(cell, true))
+	}
+	out.WriteString("</tr>\n</thead>\n")
+	if len(rows) > 1 {
+		out.WriteString("<tbody>\n")
+		for _, row := range rows[1:] {
+			out.WriteString("<tr>")
+			for _, cell := range row {
+				fmt.Fprintf(out, "<td>%s</td>", renderInline(cell, true))
+			}
+			out.WriteString("</tr>\n")
+		}
+		out.WriteString("</tbody>\n")
+	}
+	out.WriteString("</table>\n")
+}
+
+// ============================================================================
+// INLINE
+// ============================================================================
+
+// emphasisDelimiter is an emphasis marker with the HTML tags it renders as.
+type emphasisDelimiter struct {
+	marker string
+	open   string
+	close  string
+}
+
+// emphasisDelimiters are the supported emphasis markers, longest first.
+var emphasisDelimiters = []emphasisDelimiter{
+	{"***", "<strong><em>", "</em></strong>"},
+	{"___", "<strong><em>", "</em></strong>"},
+	{"**", "<strong>", "</strong>"},
+	{"__", "<strong>", "</strong>"},
+	{"~~", "<del>", "</del>"},
+	{"*", "<em>", "</em>"},
+	{"_", "<em>", "</em>"},
+}
+
+// renderInline renders inline markdown as HTML or plain text.
+func renderInline(text string, asHTML bool) string {
+	var out strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("\\`*_{}[]()#+-.!|~<>", text[i+1]) >= 0:
+			writeText(&out, text[i+1:i+2], asHTML)
+			i += 2
+			continue
+
+		case c == '`':
+			if code, next, ok := parseCodeSpan(text, i); ok {
+				if asHTML {
+					fmt.Fprintf(&out, "<code>%s</code>", html.EscapeString(code))
+				} else {
+					out.WriteString(code)
+				}
+				i = next
+				continue
+			}
+
+		case c == '!' && i+1 < len(text) && text[i+1] == '[':
+			if label, url, next, ok := parseLink(text, i+1); ok {
+				if asHTML && safeURL(url) {
+					fmt.Fprintf(&out, `<img src="%s" alt="%s">`, html.EscapeString(url), html.EscapeString(renderInline(label, false)))
+				} else {
+					writeText(&out, renderInline(label, false), asHTML)
+				}
+				i = next
+				continue
+			}
+
+		case c == '[':
+			if label, url, next, ok := parseLink(text, i); ok {
+				rendered := renderInline(label, asHTML)
+				switch {
+				case asHTML && safeURL(url):
+					fmt.Fprintf(&out, `<a href="%s">%s</a>`, html.EscapeString(url), rendered)
+				case asHTML:
+					out.WriteString(rendered)
+				case rendered == url || url == "":
+					out.WriteString(rendered)
+				default:
+					fmt.Fprintf(&out, "%s (%s)", rendered, url)
+				}
+				i = next
+				continue
+			}
+
+		case c == '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				url := text[i+1 : i+end]
+				if isAutolink(url) {
+					switch {
+					case !asHTML:
+						out.WriteString(url)
+					case strings.Contains(url, "@") && !strings.Contains(url, ":"):
+						fmt.Fprintf(&out, `<a href="mailto:%s">%s</a>`, html.EscapeString(url), html.EscapeString(url))
+					default:
+						fmt.Fprintf(&out, `<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(url))
+					}
+					i += end + 1
+					continue
+				}
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			if inner, delimiter, next, ok := parseEmphasis(text, i); ok {
+				rendered := renderInline(inner, asHTML)
+				if asHTML {
+					rendered = delimiter.open + rendered + delimiter.close
+				}
+				out.WriteString(rendered)
+				i = next
+				continue
+			}
+		}
+
+		writeText(&out, text[i:i+1], asHTML)
+		i++
+	}
+	return out.String()
+}
+
+func writeText(out *strings.Builder, s string, asHTML bool) {
+	if asHTML {
+		out.WriteString(html.EscapeString(s))
+		return
+	}
+	out.WriteString(s)
+}
+
+// parseCodeSpan parses a code span opened by a backtick run at start.
+func parseCodeSpan(text string, start int) (string, int, bool) {
+	n := 0
+	for start+n < len(text) && text[start+n] == '`' {
+		n++
+	}
+	fence := text[start : start+n]
+	for i := start + n; i < len(text); {
+		j := strings.Index(text[i:], fence)
+		if j < 0 {
+			return "", 0, false
+		}
+		j += i
+		end := j + n
+		if end < len(text) && text[end] == '`' {
+			// Longer backtick runs do not close the span
+			for end < len(text) && text[end] == '`' {
+				end++
+			}
+			i = end
+			continue
+		}
+		code := strings.ReplaceAll(text[start+n:j], "\n", " ")
+		if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		return code, end, true
+	}
+	return "", 0, false
+}
+
+// parseLink parses [label](url "title") starting at the opening bracket.
+func parseLink(text string, start int) (string, string, int, bool) {
+	depth := 0
+	closeLabel := -1
+	for i := start; i < len(text) && closeLabel < 0; i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeLabel = i
+			}
+		}
+	}
+	if closeLabel < 0 || closeLabel+1 >= len(text) || text[closeLabel+1] != '(' {
+		return "", "", 0, false
+	}
+
+	depth = 0
+	for i := closeLabel + 1; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				destination := strings.TrimSpace(text[closeLabel+2 : i])
+				if fields := strings.Fields(destination); len(fields) > 0 {
+					destination = fields[0] // Drop the optional title
+				}
+				destination = strings.TrimSuffix(strings.TrimPrefix(destination, "<"), ">")
+				return text[start+1 : closeLabel], destination, i + 1, true
+			}
+		}
+	}
+	return "", "", 0, false
+}
+
+// parseEmphasis parses an emphasis span opened by a delimiter run at start.
+func parseEmphasis(text string, start int) (string, emphasisDelimiter, int, bool) {
+	for _, delimiter := range emphasisDelimiters {
+		marker := delimiter.marker
+		if !strings.HasPrefix(text[start:], marker) {
+			continue
+		}
+		open := start + len(marker)
+		// Openers must be followed by non-space, "_" must not be inside a word
+		if open >= len(text) || text[open] == ' ' || text[open] == '\n' {
+			return "", emphasisDelimiter{}, 0, false
+		}
+		if marker[0] == '_' && start > 0 && isWordByte(text[start-1]) {
+			return "", emphasisDelimiter{}, 0, false
+		}
+
+		for i := open + 1; i <= len(text)-len(marker); i++ {
+			if text[i-1] == '\\' || !strings.HasPrefix(text[i:], marker) || text[i-1] == ' ' || text[i-1] == '\n' {
+				continue
+			}
+			end := i + len(marker)
+			if end < len(text) && text[end] == marker[0] {
+				// Part of a longer run, e.g. the "*" of a closing "**"
+				continue
+			}
+			if marker[0] == '_' && end < len(text) && isWordByte(text[end]) {
+				continue
+			}
+			return text[open:i], delimiter, end, true
+		}
+	}
+	return "", emphasisDelimiter{}, 0, false
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// isAutolink reports whether the contents of <...> form an autolink.
+func isAutolink(s string) bool {
+	if s == "" || strings.ContainsAny(s, " \t\n<") {
+		return false
+	}
+	lower := strings.ToLower(s)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "mailto:") {
+		return true
+	}
+	at := strings.IndexByte(s, '@')
+	return at > 0 && strings.Contains(s[at:], ".")
+}
+
+// safeURL reports whether a URL can be emitted into HTML, rejecting schemes such as javascript:.
+func safeURL(url string) bool {
+	lower := strings.ToLower(strings.TrimSpace(url))
+	colon := strings.IndexByte(lower, ':')
+	if colon < 0 || strings.ContainsAny(lower[:colon], "/?#") {
+		return true // Relative URL
+	}
+	switch lower[:colon] {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
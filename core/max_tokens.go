@@ -0,0 +1,247 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/maximhq/bifrost/core/chunker"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// AUTOMATIC MAX TOKENS
+// ============================================================================
+
+const (
+	// autoMaxTokensPerMessage approximates the tokens of the role and delimiters of a message.
+	autoMaxTokensPerMessage = 4
+	// autoMaxTokensPerImage approximates the tokens of an image input.
+	autoMaxTokensPerImage = 1000
+)
+
+// builtinModelLimits are the token limits of common models, keyed by a fragment of the model
+// name. Dated and provider-prefixed names (e.g. "anthropic.claude-3-5-sonnet-20241022-v2:0")
+// match through the longest fragment they contain.
+var builtinModelLimits = map[string]schemas.ModelLimits{
+	// OpenAI
+	"gpt-4o":        {ContextWindow: 128000, MaxOutputTokens: 16384},
+	"gpt-4o-mini":   {ContextWindow: 128000, MaxOutputTokens: 16384},
+	"gpt-4-turbo":   {ContextWindow: 128000, MaxOutputTokens: 4096},
+	"gpt-4":         {ContextWindow: 8192, MaxOutputTokens: 8192},
+	"gpt-4.1":       {ContextWindow: 1047576, MaxOutputTokens: 32768},
+	"gpt-3.5-turbo": {ContextWindow: 16385, MaxOutputTokens: 4096},
+	"o1":            {ContextWindow: 200000, MaxOutputTokens: 100000, MaxCompletionTokens: true},
+	"o3":            {ContextWindow: 200000, MaxOutputTokens: 100000, MaxCompletionTokens: true},
+	"o4-mini":       {ContextWindow: 200000, MaxOutputTokens: 100000, MaxCompletionTokens: true},
+
+	// Anthropic
+	"claude-3-opus":     {ContextWindow: 200000, MaxOutputTokens: 4096},
+	"claude-3-haiku":    {ContextWindow: 200000, MaxOutputTokens: 4096},
+	"claude-3-5-sonnet": {ContextWindow: 200000, MaxOutputTokens: 8192},
+	"claude-3-5-haiku":  {ContextWindow: 200000, MaxOutputTokens: 8192},
+	"claude-3-7-sonnet": {ContextWindow: 200000, MaxOutputTokens: 64000},
+	"claude-sonnet-4":   {ContextWindow: 200000, MaxOutputTokens: 64000},
+	"claude-opus-4":     {ContextWindow: 200000, MaxOutputTokens: 32000},
+
+	// Google
+	"gemini-1.5-pro":   {ContextWindow: 2097152, MaxOutputTokens: 8192},
+	"gemini-1.5-flash": {ContextWindow: 1048576, MaxOutputTokens: 8192},
+	"gemini-2.0-flash": {ContextWindow: 1048576, MaxOutputTokens: 8192},
+	"gemini-2.5-pro":   {ContextWindow: 1048576, MaxOutputTokens: 65536},
+	"gemini-2.5-flash": {ContextWindow: 1048576, MaxOutputTokens: 65536},
+
+	// Open weight and other hosted models
+	"llama-3.1":      {ContextWindow: 131072},
+	"llama-3.3":      {ContextWindow: 131072},
+	"command-r":      {ContextWindow: 128000, MaxOutputTokens: 4096},
+	"command-r-plus": {ContextWindow: 128000, MaxOutputTokens: 4096},
+	"mistral-large":  {ContextWindow: 128000},
+}
+
+// autoMaxTokens fits the max_tokens of text and chat requests into the context window left
+// after the prompt.
+type autoMaxTokens struct {
+	headroomPercent float64
+	models          map[string]schemas.ModelLimits
+	countTokens     func(text string) int
+}
+
+// newAutoMaxTokens creates the automatic max_tokens layer, nil if config is nil.
+func newAutoMaxTokens(config *schemas.AutoMaxTokensConfig) *autoMaxTokens {
+	if config == nil {
+		return nil
+	}
+	a := &autoMaxTokens{
+		headroomPercent: schemas.DefaultAutoMaxTokensHeadroomPercent,
+		models:          make(map[string]schemas.ModelLimits, len(builtinModelLimits)+len(config.Models)),
+		countTokens:     chunker.ApproximateTokenCount,
+	}
+	if config.HeadroomPercent > 0 {
+		a.headroomPercent = min(config.HeadroomPercent, 100)
+	}
+	for model, limits := range builtinModelLimits {
+		a.models[model] = limits
+	}
+	for model, limits := range config.Models {
+		a.models[model] = limits
+	}
+	if config.CountTokens != nil {
+		a.countTokens = config.CountTokens
+	}
+	return a
+}
+
+// getAutoMaxTokens returns the automatic max_tokens layer to use for a request, nil when it is
+// not configured or the context turns it off.
+func (bifrost *Bifrost) getAutoMaxTokens(ctx context.Context) *autoMaxTokens {
+	if ctx != nil {
+		if enabled, ok := ctx.Value(schemas.BifrostContextKeyAutoMaxTokens).(bool); ok && !enabled {
+			return nil
+		}
+	}
+	return bifrost.autoMaxTokens
+}
+
+// limits returns the limits of a model, matching the longest known name fragment it contains.
+func (a *autoMaxTokens) limits(model string) (schemas.ModelLimits, bool) {
+	model = strings.ToLower(model)
+	var best string
+	var limits schemas.ModelLimits
+	for name, candidate := range a.models {
+		if len(name) > len(best) && strings.Contains(model, strings.ToLower(name)) {
+			best, limits = name, candidate
+		}
+	}
+	return limits, best != "" && limits.ContextWindow > 0
+}
+
+// apply sets max_tokens on a copy of the request's parameters: unset values become the tokens
+// left in the context window after the prompt and the headroom, capped by the model's output
+// limit, and larger values are lowered to that budget, with a warning. Models marked with
+// MaxCompletionTokens get ExtraParams["max_completion_tokens"] instead. Requests for unknown
+// models, or whose prompt already fills the window, are returned unchanged.
+func (a *autoMaxTokens) apply(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType) *schemas.BifrostRequest {
+	if a == nil {
+		return req
+	}
+	switch requestType {
+	case schemas.TextCompletionRequest, schemas.ChatCompletionRequest, schemas.ChatCompletionStreamRequest:
+	default:
+		return req
+	}
+
+	limits, ok := a.limits(req.Model)
+	if !ok {
+		return req
+	}
+
+	var params schemas.ModelParameters
+	if req.Params != nil {
+		params = *req.Params
+	}
+
+	headroom := int(float64(limits.ContextWindow) * a.headroomPercent / 100)
+	available := limits.ContextWindow - a.promptTokens(req.Input, params.Tools) - headroom
+	if available <= 0 {
+		return req
+	}
+	if limits.MaxOutputTokens > 0 {
+		available = min(available, limits.MaxOutputTokens)
+	}
+
+	if limits.MaxCompletionTokens {
+		if !applyMaxCompletionTokens(ctx, &params, available, req.Model) {
+			return req
+		}
+	} else {
+		switch {
+		case params.MaxTokens == nil:
+			params.MaxTokens = Ptr(available)
+		case *params.MaxTokens > available:
+			schemas.AddWarning(ctx, schemas.WarningParamAdjusted, schemas.WarningOriginBifrost,
+				fmt.Sprintf("max_tokens lowered from %d to %d to fit the context window of %s", *params.MaxTokens, available, req.Model))
+			params.MaxTokens = Ptr(available)
+		default:
+			return req
+		}
+	}
+
+	prepared := *req
+	prepared.Params = &params
+	return &prepared
+}
+
+// applyMaxCompletionTokens sets or lowers max_completion_tokens on a copy of ExtraParams and
+// reports whether it changed. Values that are not numbers are left alone.
+func applyMaxCompletionTokens(ctx context.Context, params *schemas.ModelParameters, available int, model string) bool {
+	var current int
+	value, exists := params.ExtraParams["max_completion_tokens"]
+	if exists {
+		switch v := value.(type) {
+		case int:
+			current = v
+		case int64:
+			current = int(v)
+		case float64:
+			current = int(v)
+		default:
+			return false
+		}
+		if current <= available {
+			return false
+		}
+		schemas.AddWarning(ctx, schemas.WarningParamAdjusted, schemas.WarningOriginBifrost,
+			fmt.Sprintf("max_completion_tokens lowered from %d to %d to fit the context window of %s", current, available, model))
+	}
+
+	extraParams := make(map[string]interface{}, len(params.ExtraParams)+1)
+	for k, v := range params.ExtraParams {
+		extraParams[k] = v
+	}
+	extraParams["max_completion_tokens"] = available
+	params.ExtraParams = extraParams
+	return true
+}
+
+// promptTokens estimates the prompt tokens of a request: its text or messages, including tool
+// calls and thoughts, and its tool definitions.
+func (a *autoMaxTokens) promptTokens(input schemas.RequestInput, tools *[]schemas.Tool) int {
+	tokens := 0
+	if input.TextCompletionInput != nil {
+		tokens += a.countTokens(*input.TextCompletionInput)
+	}
+	if input.ChatCompletionInput != nil {
+		for _, msg := range *input.ChatCompletionInput {
+			tokens += autoMaxTokensPerMessage
+			tokens += a.countTokens(msg.Content.Text())
+			if msg.Content.ContentBlocks != nil {
+				for _, block := range *msg.Content.ContentBlocks {
+					if block.ImageURL != nil {
+						tokens += autoMaxTokensPerImage
+					}
+				}
+			}
+			if msg.AssistantMessage != nil {
+				if msg.AssistantMessage.Thought != nil {
+					tokens += a.countTokens(*msg.AssistantMessage.Thought)
+				}
+				if msg.AssistantMessage.ToolCalls != nil {
+					for _, toolCall := range *msg.AssistantMessage.ToolCalls {
+						if toolCall.Function.Name != nil {
+							tokens += a.countTokens(*toolCall.Function.Name)
+						}
+						tokens += a.countTokens(toolCall.Function.Arguments)
+					}
+				}
+			}
+		}
+	}
+	if tools != nil && len(*tools) > 0 {
+		if data, err := sonic.Marshal(*tools); err == nil {
+			tokens += a.countTokens(string(data))
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,106 @@
+package bifrost
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestAutoMaxTokensLimits(t *testing.T) {
+	a := newAutoMaxTokens(&schemas.AutoMaxTokensConfig{
+		Models: map[string]schemas.ModelLimits{"custom-model": {ContextWindow: 1000}},
+	})
+
+	tests := []struct {
+		model     string
+		wantLimit schemas.ModelLimits
+		wantOK    bool
+	}{
+		{model: "gpt-4o", wantLimit: builtinModelLimits["gpt-4o"], wantOK: true},
+		{model: "gpt-4o-mini-2024-07-18", wantLimit: builtinModelLimits["gpt-4o-mini"], wantOK: true},
+		{model: "anthropic.claude-3-5-sonnet-20241022-v2:0", wantLimit: builtinModelLimits["claude-3-5-sonnet"], wantOK: true},
+		{model: "GPT-4-Turbo", wantLimit: builtinModelLimits["gpt-4-turbo"], wantOK: true},
+		{model: "o3-mini", wantLimit: builtinModelLimits["o3"], wantOK: true},
+		{model: "custom-model-v2", wantLimit: schemas.ModelLimits{ContextWindow: 1000}, wantOK: true},
+		{model: "unknown-model"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			limits, ok := a.limits(tt.model)
+			if ok != tt.wantOK || (ok && limits != tt.wantLimit) {
+				t.Errorf("limits(%q) = %+v, %v, want %+v, %v", tt.model, limits, ok, tt.wantLimit, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAutoMaxTokensApply(t *testing.T) {
+	a := newAutoMaxTokens(&schemas.AutoMaxTokensConfig{
+		HeadroomPercent: 10,
+		Models: map[string]schemas.ModelLimits{
+			"chat-model":      {ContextWindow: 1000, MaxOutputTokens: 500},
+			"reasoning-model": {ContextWindow: 1000, MaxOutputTokens: 800, MaxCompletionTokens: true},
+			"small-model":     {ContextWindow: 10},
+		},
+		CountTokens: func(text string) int { return len(text) },
+	})
+
+	// "hello" counts 4 message tokens and 5 text tokens, leaving 1000 - 9 - 100 = 891.
+	tests := []struct {
+		name            string
+		model           string
+		requestType     schemas.RequestType
+		params          *schemas.ModelParameters
+		wantMaxTokens   *int
+		wantExtraParams map[string]interface{}
+		wantWarning     bool
+		wantUnchanged   bool
+	}{
+		{name: "unset max_tokens", model: "chat-model", requestType: schemas.ChatCompletionRequest, wantMaxTokens: Ptr(500)},
+		{name: "max_tokens within budget", model: "chat-model", requestType: schemas.ChatCompletionRequest, params: &schemas.ModelParameters{MaxTokens: Ptr(100)}, wantUnchanged: true},
+		{name: "max_tokens over budget", model: "chat-model", requestType: schemas.ChatCompletionStreamRequest, params: &schemas.ModelParameters{MaxTokens: Ptr(600)}, wantMaxTokens: Ptr(500), wantWarning: true},
+		{name: "reasoning model unset", model: "reasoning-model", requestType: schemas.ChatCompletionRequest, wantExtraParams: map[string]interface{}{"max_completion_tokens": 800}},
+		{name: "reasoning model keeps other extra params", model: "reasoning-model", requestType: schemas.ChatCompletionRequest, params: &schemas.ModelParameters{ExtraParams: map[string]interface{}{"reasoning_effort": "low"}}, wantExtraParams: map[string]interface{}{"reasoning_effort": "low", "max_completion_tokens": 800}},
+		{name: "reasoning model over budget", model: "reasoning-model", requestType: schemas.ChatCompletionRequest, params: &schemas.ModelParameters{ExtraParams: map[string]interface{}{"max_completion_tokens": float64(900)}}, wantExtraParams: map[string]interface{}{"max_completion_tokens": 800}, wantWarning: true},
+		{name: "reasoning model within budget", model: "reasoning-model", requestType: schemas.ChatCompletionRequest, params: &schemas.ModelParameters{ExtraParams: map[string]interface{}{"max_completion_tokens": 100}}, wantUnchanged: true},
+		{name: "prompt fills the window", model: "small-model", requestType: schemas.ChatCompletionRequest, wantUnchanged: true},
+		{name: "unknown model", model: "unknown-model", requestType: schemas.ChatCompletionRequest, wantUnchanged: true},
+		{name: "embedding request", model: "chat-model", requestType: schemas.EmbeddingRequest, wantUnchanged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := &schemas.Warnings{}
+			ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyWarnings, warnings)
+			req := &schemas.BifrostRequest{
+				Model:  tt.model,
+				Input:  schemas.RequestInput{ChatCompletionInput: &[]schemas.BifrostMessage{{Role: schemas.ModelChatMessageRoleUser, Content: schemas.MessageContent{ContentStr: Ptr("hello")}}}},
+				Params: tt.params,
+			}
+
+			got := a.apply(ctx, req, tt.requestType)
+			if tt.wantUnchanged {
+				if got != req {
+					t.Fatalf("apply() changed the request, params %+v", got.Params)
+				}
+				return
+			}
+			if got == req {
+				t.Fatal("apply() returned the original request")
+			}
+			if !reflect.DeepEqual(got.Params.MaxTokens, tt.wantMaxTokens) {
+				t.Errorf("MaxTokens = %v, want %v", got.Params.MaxTokens, tt.wantMaxTokens)
+			}
+			if tt.wantExtraParams != nil && !reflect.DeepEqual(got.Params.ExtraParams, tt.wantExtraParams) {
+				t.Errorf("ExtraParams = %v, want %v", got.Params.ExtraParams, tt.wantExtraParams)
+			}
+			if gotWarning := len(warnings.Since(0)) > 0; gotWarning != tt.wantWarning {
+				t.Errorf("warnings = %v, want warning %v", warnings.Since(0), tt.wantWarning)
+			}
+			if tt.params != nil && tt.params.ExtraParams != nil && reflect.DeepEqual(tt.params.ExtraParams, got.Params.ExtraParams) {
+				t.Error("apply() modified the caller's ExtraParams")
+			}
+		})
+	}
+}
@@ -52,6 +52,13 @@ type MCPManager struct {
 	mu            sync.RWMutex          // Read-write mutex for thread-safe operations
 	serverRunning bool                  // Track whether local MCP server is running
 	logger        schemas.Logger        // Logger instance for structured logging
+
+	// Tool execution policies
+	policyMu        sync.RWMutex                     // Guards toolPolicies and toolSemaphores
+	toolPolicies    map[string]schemas.MCPToolPolicy // Per-tool execution policies
+	toolSemaphores  map[string]chan struct{}         // Per-tool concurrency limiters
+	approvalHandler schemas.MCPToolApprovalHandler   // Called for tools requiring approval
+	auditHandler    schemas.MCPToolAuditHandler      // Receives an audit record for every invocation
 }
 
 // MCPClient represents a connected MCP client with its configuration and tools.
@@ -91,9 +98,18 @@ type MCPToolHandler[T any] func(args T) (string, error)
 func newMCPManager(ctx context.Context, config schemas.MCPConfig, logger schemas.Logger) (*MCPManager, error) {
 	// Creating new instance
 	manager := &MCPManager{
-		ctx:       ctx,
-		clientMap: make(map[string]*MCPClient),
-		logger:    logger,
+		ctx:             ctx,
+		clientMap:       make(map[string]*MCPClient),
+		logger:          logger,
+		toolPolicies:    make(map[string]schemas.MCPToolPolicy),
+		toolSemaphores:  make(map[string]chan struct{}),
+		approvalHandler: config.ApprovalHandler,
+		auditHandler:    config.AuditHandler,
+	}
+	for toolName, policy := range config.ToolPolicies {
+		if err := manager.SetToolPolicy(toolName, policy); err != nil {
+			return nil, err
+		}
 	}
 	// Process client configs: create client map entries and establish connections
 	for _, clientConfig := range config.ClientConfigs {
@@ -526,23 +542,40 @@ func (m *MCPManager) startLocalMCPServer() error {
 // Returns:
 //   - schemas.BifrostMessage: Tool message with execution result
 //   - error: Any execution error
-func (m *MCPManager) executeTool(ctx context.Context, toolCall schemas.ToolCall) (*schemas.BifrostMessage, error) {
+func (m *MCPManager) executeTool(ctx context.Context, toolCall schemas.ToolCall) (_ *schemas.BifrostMessage, err error) {
+	record := schemas.MCPToolAuditRecord{
+		ToolCallID: toolCall.ID,
+		StartedAt:  time.Now(),
+	}
+	// Every exit path is audited, including calls rejected before reaching the MCP server.
+	// auditErr overrides the returned error when the audit needs the unwrapped cause.
+	var auditErr error
+	defer func() {
+		if auditErr == nil {
+			auditErr = err
+		}
+		m.recordToolAudit(&record, auditErr)
+	}()
+
 	if toolCall.Function.Name == nil {
 		return nil, fmt.Errorf("tool call missing function name")
 	}
 	toolName := *toolCall.Function.Name
+	record.ToolName = toolName
 
 	// Parse tool arguments
 	var arguments map[string]interface{}
 	if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &arguments); err != nil {
 		return nil, fmt.Errorf("failed to parse tool arguments for '%s': %v", toolName, err)
 	}
+	record.Arguments = arguments
 
 	// Find which client has this tool
 	client := m.findMCPClientForTool(toolName)
 	if client == nil {
 		return nil, fmt.Errorf("tool '%s' not found in any connected MCP client", toolName)
 	}
+	record.ClientName = client.Name
 
 	if client.Conn == nil {
 		return nil, fmt.Errorf("client '%s' has no active connection", client.Name)
@@ -559,11 +592,22 @@ func (m *MCPManager) executeTool(ctx context.Context, toolCall schemas.ToolCall)
 		},
 	}
 
+	// Enforce the tool execution policy, if any
+	ctx, release, policyErr := m.applyToolPolicy(ctx, client.Name, toolCall, toolName, arguments, &record)
+	if policyErr != nil {
+		return nil, policyErr
+	}
+	defer release()
+
 	m.logger.Debug(fmt.Sprintf("%s Starting tool execution: %s via client: %s", MCPLogPrefix, toolName, client.Name))
 
 	toolResponse, callErr := client.Conn.CallTool(ctx, callRequest)
 	if callErr != nil {
 		m.logger.Error("%s Tool execution failed for %s via client %s: %v", MCPLogPrefix, toolName, client.Name, callErr)
+		if ctx.Err() == context.DeadlineExceeded {
+			callErr = fmt.Errorf("%w: %v", context.DeadlineExceeded, callErr)
+		}
+		auditErr = callErr
 		return nil, fmt.Errorf("MCP tool call failed: %v", callErr)
 	}
 
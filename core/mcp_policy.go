@@ -0,0 +1,256 @@
+package bifrost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// TOOL EXECUTION POLICIES
+// ============================================================================
+
+// errToolExecutionRejected is returned when a tool requiring approval is not approved.
+var errToolExecutionRejected = errors.New("tool execution was not approved")
+
+// SetToolPolicy sets or replaces the execution policy of a tool.
+// Passing a zero value policy effectively removes all constraints.
+func (m *MCPManager) SetToolPolicy(toolName string, policy schemas.MCPToolPolicy) error {
+	if err := validateToolPolicy(policy); err != nil {
+		return fmt.Errorf("invalid policy for tool '%s': %w", toolName, err)
+	}
+
+	m.policyMu.Lock()
+	defer m.policyMu.Unlock()
+
+	if m.toolPolicies == nil {
+		m.toolPolicies = make(map[string]schemas.MCPToolPolicy)
+	}
+	m.toolPolicies[toolName] = policy
+	// The semaphore is recreated lazily with the new limit, in-flight executions keep the old one
+	delete(m.toolSemaphores, toolName)
+
+	return nil
+}
+
+// getToolPolicy returns the policy of a tool and whether one is configured.
+func (m *MCPManager) getToolPolicy(toolName string) (schemas.MCPToolPolicy, bool) {
+	m.policyMu.RLock()
+	defer m.policyMu.RUnlock()
+
+	policy, ok := m.toolPolicies[toolName]
+	return policy, ok
+}
+
+// getToolSemaphore returns the semaphore limiting concurrent executions of a tool.
+func (m *MCPManager) getToolSemaphore(toolName string, limit int) chan struct{} {
+	m.policyMu.Lock()
+	defer m.policyMu.Unlock()
+
+	if m.toolSemaphores == nil {
+		m.toolSemaphores = make(map[string]chan struct{})
+	}
+	sem, ok := m.toolSemaphores[toolName]
+	if !ok {
+		sem = make(chan struct{}, limit)
+		m.toolSemaphores[toolName] = sem
+	}
+	return sem
+}
+
+// applyToolPolicy enforces the policy of a tool before it is executed. It validates the arguments,
+// waits for approval if required, acquires a concurrency slot and applies the timeout.
+// The returned release function must be called once the execution finishes.
+func (m *MCPManager) applyToolPolicy(ctx context.Context, clientName string, toolCall schemas.ToolCall, toolName string, arguments map[string]interface{}, record *schemas.MCPToolAuditRecord) (context.Context, func(), error) {
+	noop := func() {}
+
+	policy, ok := m.getToolPolicy(toolName)
+	if !ok {
+		return ctx, noop, nil
+	}
+
+	if err := checkToolArguments(policy.ArgumentConstraints, arguments); err != nil {
+		record.Status = schemas.MCPToolAuditStatusDenied
+		return ctx, noop, fmt.Errorf("tool '%s' arguments violate policy: %w", toolName, err)
+	}
+
+	if policy.RequireApproval {
+		approved, err := m.requestToolApproval(ctx, clientName, toolCall, toolName, arguments)
+		record.Approved = &approved
+		if err != nil || !approved {
+			record.Status = schemas.MCPToolAuditStatusRejected
+			if err == nil {
+				err = errToolExecutionRejected
+			}
+			return ctx, noop, fmt.Errorf("tool '%s': %w", toolName, err)
+		}
+	}
+
+	release := noop
+	if policy.MaxConcurrentExecutions > 0 {
+		sem := m.getToolSemaphore(toolName, policy.MaxConcurrentExecutions)
+		select {
+		case sem <- struct{}{}:
+			release = func() { <-sem }
+		case <-ctx.Done():
+			record.Status = schemas.MCPToolAuditStatusError
+			return ctx, noop, fmt.Errorf("tool '%s' cancelled while waiting for a free execution slot: %w", toolName, ctx.Err())
+		}
+	}
+
+	if policy.TimeoutInSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(policy.TimeoutInSeconds)*time.Second)
+		releaseSlot := release
+		release = func() {
+			cancel()
+			releaseSlot()
+		}
+	}
+
+	return ctx, release, nil
+}
+
+// requestToolApproval emits an approval event and blocks until the approval handler decides.
+func (m *MCPManager) requestToolApproval(ctx context.Context, clientName string, toolCall schemas.ToolCall, toolName string, arguments map[string]interface{}) (bool, error) {
+	if m.approvalHandler == nil {
+		return false, fmt.Errorf("approval required but no approval handler is configured")
+	}
+
+	m.logger.Info(fmt.Sprintf("%s Waiting for approval to execute tool: %s", MCPLogPrefix, toolName))
+
+	approved, reason, err := m.approvalHandler(ctx, schemas.MCPToolApprovalRequest{
+		ToolName:    toolName,
+		ClientName:  clientName,
+		ToolCallID:  toolCall.ID,
+		Arguments:   maps.Clone(arguments),
+		RequestedAt: time.Now(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("approval failed: %w", err)
+	}
+	if !approved && reason != "" {
+		return false, fmt.Errorf("%w: %s", errToolExecutionRejected, reason)
+	}
+
+	return approved, nil
+}
+
+// recordToolAudit finalizes an audit record and hands it to the audit handler, if any.
+func (m *MCPManager) recordToolAudit(record *schemas.MCPToolAuditRecord, err error) {
+	record.Duration = time.Since(record.StartedAt)
+	if err != nil {
+		record.Error = err.Error()
+		if record.Status == "" {
+			record.Status = schemas.MCPToolAuditStatusError
+			if errors.Is(err, context.DeadlineExceeded) {
+				record.Status = schemas.MCPToolAuditStatusTimeout
+			}
+		}
+	} else if record.Status == "" {
+		record.Status = schemas.MCPToolAuditStatusSuccess
+	}
+
+	if m.auditHandler != nil {
+		m.auditHandler(*record)
+	}
+}
+
+// checkToolArguments validates tool arguments against the configured constraints.
+func checkToolArguments(constraints map[string]schemas.MCPToolArgumentConstraint, arguments map[string]interface{}) error {
+	for name, constraint := range constraints {
+		value, ok := arguments[name]
+		if !ok || value == nil {
+			if constraint.Required {
+				return fmt.Errorf("argument '%s' is required", name)
+			}
+			continue
+		}
+
+		if len(constraint.AllowedValues) > 0 {
+			allowed := false
+			for _, candidate := range constraint.AllowedValues {
+				if toolArgumentEquals(candidate, value) {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return fmt.Errorf("argument '%s' value %v is not one of the allowed values", name, value)
+			}
+		}
+
+		switch v := value.(type) {
+		case float64:
+			if constraint.Min != nil && v < *constraint.Min {
+				return fmt.Errorf("argument '%s' value %v is below the minimum %v", name, v, *constraint.Min)
+			}
+			if constraint.Max != nil && v > *constraint.Max {
+				return fmt.Errorf("argument '%s' value %v is above the maximum %v", name, v, *constraint.Max)
+			}
+		case string:
+			if constraint.MaxLength != nil && len(v) > *constraint.MaxLength {
+				return fmt.Errorf("argument '%s' exceeds the maximum length of %d", name, *constraint.MaxLength)
+			}
+			if constraint.Pattern != nil {
+				matched, err := regexp.MatchString(*constraint.Pattern, v)
+				if err != nil {
+					return fmt.Errorf("argument '%s' has an invalid pattern constraint: %w", name, err)
+				}
+				if !matched {
+					return fmt.Errorf("argument '%s' does not match the allowed pattern", name)
+				}
+			}
+		default:
+			if constraint.Min != nil || constraint.Max != nil {
+				return fmt.Errorf("argument '%s' must be a number", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// toolArgumentEquals compares a configured allowed value with a JSON decoded argument.
+// Numbers from configs may be ints while JSON decoding always yields float64.
+func toolArgumentEquals(candidate, value interface{}) bool {
+	if n, ok := value.(float64); ok {
+		rv := reflect.ValueOf(candidate)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(rv.Int()) == n
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(rv.Uint()) == n
+		case reflect.Float32, reflect.Float64:
+			return rv.Float() == n
+		}
+	}
+	return reflect.DeepEqual(candidate, value)
+}
+
+// validateToolPolicy checks a policy for obviously invalid values.
+func validateToolPolicy(policy schemas.MCPToolPolicy) error {
+	if policy.TimeoutInSeconds < 0 {
+		return fmt.Errorf("timeout_in_seconds cannot be negative")
+	}
+	if policy.MaxConcurrentExecutions < 0 {
+		return fmt.Errorf("max_concurrent_executions cannot be negative")
+	}
+	for name, constraint := range policy.ArgumentConstraints {
+		if constraint.Min != nil && constraint.Max != nil && *constraint.Min > *constraint.Max {
+			return fmt.Errorf("argument '%s' has min greater than max", name)
+		}
+		if constraint.Pattern != nil {
+			if _, err := regexp.Compile(*constraint.Pattern); err != nil {
+				return fmt.Errorf("argument '%s' has an invalid pattern: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
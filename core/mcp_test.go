@@ -0,0 +1,65 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/server"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestExecuteToolAuditsEveryExit(t *testing.T) {
+	conn, err := client.NewInProcessClient(server.NewMCPServer("test", "1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		toolName   *string
+		arguments  string
+		wantStatus schemas.MCPToolAuditStatus
+		wantClient string
+	}{
+		{name: "missing function name", arguments: `{}`, wantStatus: schemas.MCPToolAuditStatusError},
+		{name: "invalid arguments", toolName: Ptr("search"), arguments: `{`, wantStatus: schemas.MCPToolAuditStatusError},
+		{name: "unknown tool", toolName: Ptr("missing"), arguments: `{}`, wantStatus: schemas.MCPToolAuditStatusError},
+		{name: "client without connection", toolName: Ptr("offline"), arguments: `{}`, wantStatus: schemas.MCPToolAuditStatusError, wantClient: "disconnected"},
+		{name: "policy denial", toolName: Ptr("search"), arguments: `{}`, wantStatus: schemas.MCPToolAuditStatusDenied, wantClient: "connected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []schemas.MCPToolAuditRecord
+			m := &MCPManager{
+				clientMap: map[string]*MCPClient{
+					"connected":    {Name: "connected", Conn: conn, ToolMap: map[string]schemas.Tool{"search": {}}},
+					"disconnected": {Name: "disconnected", ToolMap: map[string]schemas.Tool{"offline": {}}},
+				},
+				toolPolicies: map[string]schemas.MCPToolPolicy{
+					"search": {ArgumentConstraints: map[string]schemas.MCPToolArgumentConstraint{"query": {Required: true}}},
+				},
+				logger:       NewDefaultLogger(schemas.LogLevelError),
+				auditHandler: func(record schemas.MCPToolAuditRecord) { records = append(records, record) },
+			}
+
+			_, err := m.executeTool(context.Background(), schemas.ToolCall{
+				ID:       Ptr("call_1"),
+				Function: schemas.FunctionCall{Name: tt.toolName, Arguments: tt.arguments},
+			})
+			if err == nil {
+				t.Fatal("executeTool() error = nil, want an error")
+			}
+			if len(records) != 1 {
+				t.Fatalf("got %d audit records, want 1", len(records))
+			}
+			record := records[0]
+			if record.Status != tt.wantStatus || record.Error == "" || record.ClientName != tt.wantClient {
+				t.Errorf("audit record = %+v, want status %s, client %q and an error", record, tt.wantStatus, tt.wantClient)
+			}
+			if record.ToolCallID == nil || *record.ToolCallID != "call_1" {
+				t.Errorf("audit record tool call ID = %v, want call_1", record.ToolCallID)
+			}
+		})
+	}
+}
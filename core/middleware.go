@@ -0,0 +1,199 @@
+package bifrost
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/maximhq/bifrost/core/providers"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// PROVIDER MIDDLEWARE CHAIN
+// ============================================================================
+
+// MiddlewareChain is the ordered chain of middleware around provider calls, from the outermost
+// to the innermost. It starts with the built-in middleware (see schemas.MiddlewarePayloadSlimming
+// and the names following it) and is safe to change while requests are served: workers pick up
+// the new chain on their next call.
+type MiddlewareChain struct {
+	mu         sync.RWMutex
+	middleware []schemas.ProviderMiddleware
+	version    atomic.Uint64 // Incremented on every change, so workers know to rebuild their callers
+}
+
+// newMiddlewareChain creates a chain of the given middleware.
+func newMiddlewareChain(middleware ...schemas.ProviderMiddleware) *MiddlewareChain {
+	return &MiddlewareChain{middleware: middleware}
+}
+
+// Names returns the names of the middleware in the chain, from the outermost to the innermost.
+func (c *MiddlewareChain) Names() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, len(c.middleware))
+	for i, m := range c.middleware {
+		names[i] = m.GetName()
+	}
+	return names
+}
+
+// Use appends middleware to the end of the chain, closest to the provider, where it sees every
+// attempt of a request.
+func (c *MiddlewareChain) Use(middleware schemas.ProviderMiddleware) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.insert(len(c.middleware), middleware)
+}
+
+// InsertBefore inserts middleware right before the named one, so it wraps it.
+func (c *MiddlewareChain) InsertBefore(name string, middleware schemas.ProviderMiddleware) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.index(name)
+	if i < 0 {
+		return fmt.Errorf("middleware %s not found", name)
+	}
+	return c.insert(i, middleware)
+}
+
+// InsertAfter inserts middleware right after the named one, so it is wrapped by it.
+func (c *MiddlewareChain) InsertAfter(name string, middleware schemas.ProviderMiddleware) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.index(name)
+	if i < 0 {
+		return fmt.Errorf("middleware %s not found", name)
+	}
+	return c.insert(i+1, middleware)
+}
+
+// Replace replaces the named middleware, keeping its position. The replacement may have a
+// different name.
+func (c *MiddlewareChain) Replace(name string, middleware schemas.ProviderMiddleware) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.index(name)
+	if i < 0 {
+		return fmt.Errorf("middleware %s not found", name)
+	}
+	if middleware == nil {
+		return fmt.Errorf("middleware is nil")
+	}
+	if other := c.index(middleware.GetName()); other >= 0 && other != i {
+		return fmt.Errorf("middleware %s is already in the chain", middleware.GetName())
+	}
+	c.middleware = slices.Clone(c.middleware)
+	c.middleware[i] = middleware
+	c.version.Add(1)
+	return nil
+}
+
+// Remove removes the named middleware. Removing panic_recovery lets a panic of a provider crash
+// the process.
+func (c *MiddlewareChain) Remove(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.index(name)
+	if i < 0 {
+		return fmt.Errorf("middleware %s not found", name)
+	}
+	c.middleware = slices.Delete(slices.Clone(c.middleware), i, i+1)
+	c.version.Add(1)
+	return nil
+}
+
+// insert inserts middleware at position i. The slice is copied so callers being built from the
+// previous chain are not affected. Must be called with the lock held.
+func (c *MiddlewareChain) insert(i int, middleware schemas.ProviderMiddleware) error {
+	if middleware == nil {
+		return fmt.Errorf("middleware is nil")
+	}
+	if c.index(middleware.GetName()) >= 0 {
+		return fmt.Errorf("middleware %s is already in the chain", middleware.GetName())
+	}
+	c.middleware = slices.Insert(slices.Clone(c.middleware), i, middleware)
+	c.version.Add(1)
+	return nil
+}
+
+// index returns the position of the named middleware, -1 if it is not in the chain. Must be
+// called with the lock held.
+func (c *MiddlewareChain) index(name string) int {
+	return slices.IndexFunc(c.middleware, func(m schemas.ProviderMiddleware) bool {
+		return m.GetName() == name
+	})
+}
+
+// chainCaller is a worker's caller built from the chain, rebuilt when the chain changes.
+type chainCaller struct {
+	chain    *MiddlewareChain
+	terminal schemas.ProviderCaller
+	caller   schemas.ProviderCaller
+	version  uint64
+}
+
+// newChainCaller creates the caller of a worker, running the chain around terminal.
+func (c *MiddlewareChain) newChainCaller(terminal schemas.ProviderCaller) *chainCaller {
+	caller := &chainCaller{chain: c, terminal: terminal}
+	caller.build()
+	return caller
+}
+
+// get returns the caller, rebuilding it first if the chain changed since it was built.
+func (c *chainCaller) get() schemas.ProviderCaller {
+	if c.chain.version.Load() != c.version {
+		c.build()
+	}
+	return c.caller
+}
+
+// build wraps the terminal caller in the middleware of the chain, innermost first.
+func (c *chainCaller) build() {
+	c.chain.mu.RLock()
+	middleware := c.chain.middleware
+	c.version = c.chain.version.Load()
+	c.chain.mu.RUnlock()
+
+	caller := c.terminal
+	for i := len(middleware) - 1; i >= 0; i-- {
+		caller = middleware[i].Wrap(caller)
+	}
+	c.caller = caller
+}
+
+// providerCaller is the innermost caller of the chain, calling the provider itself.
+type providerCaller struct {
+	provider schemas.Provider
+}
+
+// Call calls the provider method of the call's request type.
+func (p providerCaller) Call(call *schemas.ProviderCall) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return handleProviderRequest(p.provider, call)
+}
+
+// CallStream calls the provider method of the call's request type. Post hooks may end the stream
+// early and cancel the upstream request.
+func (p providerCaller) CallStream(call *schemas.ProviderCall) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	attempt := *call
+	attempt.Context = providers.WithStreamAbort(call.Context)
+	return handleProviderStreamRequest(p.provider, &attempt)
+}
+
+// Middleware returns the chain of middleware around provider calls, to insert custom middleware
+// at precise positions or to remove built-in middleware.
+func (bifrost *Bifrost) Middleware() *MiddlewareChain {
+	return bifrost.middleware
+}
+
+// builtinMiddleware returns the built-in middleware, in their order in the chain.
+func (bifrost *Bifrost) builtinMiddleware() []schemas.ProviderMiddleware {
+	return []schemas.ProviderMiddleware{
+		schemas.NewProviderMiddleware(schemas.MiddlewarePayloadSlimming, bifrost.payloadSlimmingMiddleware),
+		schemas.NewProviderMiddleware(schemas.MiddlewareRetry, bifrost.retryMiddleware),
+		schemas.NewProviderMiddleware(schemas.MiddlewareTimeout, bifrost.timeoutMiddleware),
+		schemas.NewProviderMiddleware(schemas.MiddlewarePanicRecovery, bifrost.panicRecoveryMiddleware),
+	}
+}
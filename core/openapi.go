@@ -0,0 +1,585 @@
+package bifrost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	// openAPIMaxRefDepth bounds $ref resolution so that recursive schemas do not loop forever.
+	openAPIMaxRefDepth = 16
+	// openAPIMaxToolNameLength is the longest function name accepted by most providers.
+	openAPIMaxToolNameLength = 64
+	// openAPIBodyArgument is the tool argument that carries the JSON request body of an operation.
+	openAPIBodyArgument = "body"
+	// openAPIDefaultTimeout is used when OpenAPIToolsConfig.Timeout is not set.
+	openAPIDefaultTimeout = 30 * time.Second
+)
+
+var openAPIToolNameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// openAPIMethods lists the HTTP methods that are turned into tools, in a stable order.
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete"}
+
+// OpenAPIAuth holds the credentials used when invoking the endpoints of an OpenAPI document.
+// Only the fields that are set are applied, so several schemes can be combined.
+type OpenAPIAuth struct {
+	BearerToken   string            `json:"bearer_token,omitempty"`   // Sent as "Authorization: Bearer <token>"
+	APIKey        string            `json:"api_key,omitempty"`        // API key value
+	APIKeyHeader  string            `json:"api_key_header,omitempty"` // Header to send APIKey in, e.g. "X-API-Key"
+	APIKeyQuery   string            `json:"api_key_query,omitempty"`  // Query parameter to send APIKey in, used when APIKeyHeader is empty
+	BasicUsername string            `json:"basic_username,omitempty"` // HTTP basic auth username
+	BasicPassword string            `json:"basic_password,omitempty"` // HTTP basic auth password
+	Headers       map[string]string `json:"headers,omitempty"`        // Additional static headers sent with every call
+}
+
+// OpenAPIToolsConfig configures how an OpenAPI document is turned into tools.
+type OpenAPIToolsConfig struct {
+	BaseURL      string        `json:"base_url,omitempty"`       // Overrides the first server URL declared in the document
+	Auth         OpenAPIAuth   `json:"auth,omitempty"`           // Credentials applied to every call
+	Operations   []string      `json:"operations,omitempty"`     // Optional allow-list of operationIds (or generated tool names)
+	ToolPrefix   string        `json:"tool_prefix,omitempty"`    // Optional prefix added to every generated tool name
+	Timeout      time.Duration `json:"timeout,omitempty"`        // Per-call timeout, defaults to 30 seconds
+	MaxBodyBytes int64         `json:"max_body_bytes,omitempty"` // Truncates response bodies returned to the model, 0 means no limit
+	HTTPClient   *http.Client  `json:"-"`                        // Optional custom client
+}
+
+// OpenAPIToolset is the set of tools generated from an OpenAPI document together with
+// the executor that invokes the described REST endpoints.
+type OpenAPIToolset struct {
+	Tools []schemas.Tool
+
+	baseURL    string
+	config     OpenAPIToolsConfig
+	client     *http.Client
+	operations map[string]*openAPIOperation
+}
+
+// openAPIOperation is the resolved form of a single operation needed at execution time.
+type openAPIOperation struct {
+	method       string
+	path         string
+	pathParams   []string
+	queryParams  []string
+	headerParams []string
+	hasBody      bool
+}
+
+// openAPIParameter mirrors the subset of the OpenAPI parameter object Bifrost uses.
+type openAPIParameter struct {
+	Ref         string                 `json:"$ref,omitempty"`
+	Name        string                 `json:"name"`
+	In          string                 `json:"in"`
+	Description string                 `json:"description,omitempty"`
+	Required    bool                   `json:"required,omitempty"`
+	Schema      map[string]interface{} `json:"schema,omitempty"`
+}
+
+// openAPIOperationSpec mirrors the subset of the OpenAPI operation object Bifrost uses.
+type openAPIOperationSpec struct {
+	OperationID string             `json:"operationId,omitempty"`
+	Summary     string             `json:"summary,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Parameters  []openAPIParameter `json:"parameters,omitempty"`
+	RequestBody *struct {
+		Ref         string `json:"$ref,omitempty"`
+		Description string `json:"description,omitempty"`
+		Required    bool   `json:"required,omitempty"`
+		Content     map[string]struct {
+			Schema map[string]interface{} `json:"schema,omitempty"`
+		} `json:"content,omitempty"`
+	} `json:"requestBody,omitempty"`
+}
+
+// openAPIDocument mirrors the subset of an OpenAPI 3 document Bifrost uses.
+type openAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers,omitempty"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components map[string]interface{}                `json:"components,omitempty"`
+}
+
+// NewOpenAPIToolset parses an OpenAPI 3 document (JSON) and generates one tool per operation.
+// Path, query and header parameters become top level tool arguments, and the JSON request body,
+// if any, is exposed as the "body" argument.
+//
+// Parameters:
+//   - spec: OpenAPI 3 document in JSON format
+//   - config: Base URL, auth and filtering options
+//
+// Returns:
+//   - *OpenAPIToolset: Generated tools and their executor
+//   - error: Any parsing error
+func NewOpenAPIToolset(spec []byte, config OpenAPIToolsConfig) (*OpenAPIToolset, error) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, only 3.x documents are supported", doc.OpenAPI)
+	}
+
+	var root map[string]interface{}
+	if err := json.Unmarshal(spec, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" && len(doc.Servers) > 0 {
+		baseURL = doc.Servers[0].URL
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("no base URL configured and no servers declared in the OpenAPI document")
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		timeout := config.Timeout
+		if timeout <= 0 {
+			timeout = openAPIDefaultTimeout
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+
+	toolset := &OpenAPIToolset{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		config:     config,
+		client:     client,
+		operations: make(map[string]*openAPIOperation),
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := doc.Paths[path]
+
+		// Path level parameters apply to every operation under the path
+		var sharedParams []openAPIParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &sharedParams); err != nil {
+				return nil, fmt.Errorf("failed to parse parameters of path %s: %w", path, err)
+			}
+		}
+
+		for _, method := range openAPIMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+
+			var op openAPIOperationSpec
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("failed to parse operation %s %s: %w", strings.ToUpper(method), path, err)
+			}
+
+			name := openAPIToolName(config.ToolPrefix, op.OperationID, method, path)
+			if len(config.Operations) > 0 && !slices.Contains(config.Operations, op.OperationID) && !slices.Contains(config.Operations, name) {
+				continue
+			}
+			if _, exists := toolset.operations[name]; exists {
+				return nil, fmt.Errorf("duplicate tool name %q generated for %s %s", name, strings.ToUpper(method), path)
+			}
+
+			tool, operation, err := buildOpenAPITool(root, name, method, path, append(slices.Clone(sharedParams), op.Parameters...), op)
+			if err != nil {
+				return nil, err
+			}
+
+			toolset.Tools = append(toolset.Tools, tool)
+			toolset.operations[name] = operation
+		}
+	}
+
+	if len(toolset.Tools) == 0 {
+		return nil, fmt.Errorf("no operations found in the OpenAPI document")
+	}
+
+	return toolset, nil
+}
+
+// Execute runs a tool call produced by a model against the described endpoint
+// and returns the result as a tool message.
+func (t *OpenAPIToolset) Execute(ctx context.Context, toolCall schemas.ToolCall) (*schemas.BifrostMessage, error) {
+	if toolCall.Function.Name == nil {
+		return nil, fmt.Errorf("tool call missing function name")
+	}
+
+	var args map[string]interface{}
+	if strings.TrimSpace(toolCall.Function.Arguments) != "" {
+		if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+			return nil, fmt.Errorf("failed to parse tool arguments for '%s': %v", *toolCall.Function.Name, err)
+		}
+	}
+
+	result, err := t.Call(ctx, *toolCall.Function.Name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.BifrostMessage{
+		Role: schemas.ModelChatMessageRoleTool,
+		Content: schemas.MessageContent{
+			ContentStr: &result,
+		},
+		ToolMessage: &schemas.ToolMessage{
+			ToolCallID: toolCall.ID,
+		},
+	}, nil
+}
+
+// Call invokes the endpoint behind the named tool with the given arguments and returns
+// the response body. Non 2xx responses are returned as errors including the body.
+func (t *OpenAPIToolset) Call(ctx context.Context, name string, args map[string]interface{}) (string, error) {
+	op, ok := t.operations[name]
+	if !ok {
+		return "", fmt.Errorf("tool '%s' not found in OpenAPI toolset", name)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	path := op.path
+	for _, param := range op.pathParams {
+		value, ok := args[param]
+		if !ok {
+			return "", fmt.Errorf("missing required path parameter '%s' for tool '%s'", param, name)
+		}
+		path = strings.ReplaceAll(path, "{"+param+"}", url.PathEscape(openAPIArgString(value)))
+	}
+
+	query := url.Values{}
+	for _, param := range op.queryParams {
+		if value, ok := args[param]; ok && value != nil {
+			if values, isList := value.([]interface{}); isList {
+				for _, v := range values {
+					query.Add(param, openAPIArgString(v))
+				}
+				continue
+			}
+			query.Set(param, openAPIArgString(value))
+		}
+	}
+	if t.config.Auth.APIKey != "" && t.config.Auth.APIKeyHeader == "" && t.config.Auth.APIKeyQuery != "" {
+		query.Set(t.config.Auth.APIKeyQuery, t.config.Auth.APIKey)
+	}
+
+	requestURL := t.baseURL + path
+	if encoded := query.Encode(); encoded != "" {
+		requestURL += "?" + encoded
+	}
+
+	var body io.Reader
+	if op.hasBody {
+		if payload, ok := args[openAPIBodyArgument]; ok && payload != nil {
+			jsonBody, err := json.Marshal(payload)
+			if err != nil {
+				return "", fmt.Errorf("failed to marshal request body for tool '%s': %w", name, err)
+			}
+			body = bytes.NewReader(jsonBody)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(op.method), requestURL, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request for tool '%s': %w", name, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	for _, param := range op.headerParams {
+		if value, ok := args[param]; ok && value != nil {
+			req.Header.Set(param, openAPIArgString(value))
+		}
+	}
+	t.applyAuth(req)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call %s %s: %w", strings.ToUpper(op.method), op.path, err)
+	}
+	defer resp.Body.Close()
+
+	reader := io.Reader(resp.Body)
+	if t.config.MaxBodyBytes > 0 {
+		reader = io.LimitReader(resp.Body, t.config.MaxBodyBytes)
+	}
+	respBody, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response of %s %s: %w", strings.ToUpper(op.method), op.path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("%s %s returned status %d: %s", strings.ToUpper(op.method), op.path, resp.StatusCode, string(respBody))
+	}
+
+	return string(respBody), nil
+}
+
+// applyAuth adds the configured credentials to the outgoing request.
+func (t *OpenAPIToolset) applyAuth(req *http.Request) {
+	auth := t.config.Auth
+	for key, value := range auth.Headers {
+		req.Header.Set(key, value)
+	}
+	if auth.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+auth.BearerToken)
+	}
+	if auth.BasicUsername != "" || auth.BasicPassword != "" {
+		req.SetBasicAuth(auth.BasicUsername, auth.BasicPassword)
+	}
+	if auth.APIKey != "" && auth.APIKeyHeader != "" {
+		req.Header.Set(auth.APIKeyHeader, auth.APIKey)
+	}
+}
+
+// RegisterOpenAPITools generates tools from an OpenAPI 3 document and registers each of them
+// with the MCP integration, so every described endpoint becomes available to models in one call.
+// The endpoint calls made by the tools are bound to ctx, cancelling it aborts calls in flight
+// and fails later ones.
+//
+// Parameters:
+//   - ctx: Context for the endpoint calls of the registered tools
+//   - spec: OpenAPI 3 document in JSON format
+//   - config: Base URL, auth and filtering options
+//
+// Returns:
+//   - []string: Names of the registered tools
+//   - error: Any parsing or registration error
+//
+// Example:
+//
+//	names, err := bifrost.RegisterOpenAPITools(ctx, specBytes, bifrost.OpenAPIToolsConfig{
+//	    BaseURL: "https://internal.example.com/api",
+//	    Auth:    bifrost.OpenAPIAuth{BearerToken: os.Getenv("INTERNAL_API_TOKEN")},
+//	})
+func (bifrost *Bifrost) RegisterOpenAPITools(ctx context.Context, spec []byte, config OpenAPIToolsConfig) ([]string, error) {
+	if bifrost.mcpManager == nil {
+		return nil, fmt.Errorf("MCP is not configured in this Bifrost instance")
+	}
+
+	toolset, err := NewOpenAPIToolset(spec, config)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(toolset.Tools))
+	for _, tool := range toolset.Tools {
+		name := tool.Function.Name
+		handler := func(args any) (string, error) {
+			arguments, _ := args.(map[string]interface{})
+			return toolset.Call(ctx, name, arguments)
+		}
+		if err := bifrost.mcpManager.registerTool(name, tool.Function.Description, handler, tool); err != nil {
+			return names, fmt.Errorf("failed to register tool '%s': %w", name, err)
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// buildOpenAPITool converts a single operation into a Bifrost tool and its execution metadata.
+func buildOpenAPITool(root map[string]interface{}, name, method, path string, params []openAPIParameter, op openAPIOperationSpec) (schemas.Tool, *openAPIOperation, error) {
+	operation := &openAPIOperation{method: method, path: path}
+	properties := make(map[string]interface{})
+	var required []string
+
+	for _, param := range params {
+		if param.Ref != "" {
+			resolved, err := resolveOpenAPIRef(root, param.Ref)
+			if err != nil {
+				return schemas.Tool{}, nil, fmt.Errorf("operation %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			raw, _ := json.Marshal(resolved)
+			if err := json.Unmarshal(raw, &param); err != nil {
+				return schemas.Tool{}, nil, fmt.Errorf("operation %s %s: invalid parameter %s: %w", strings.ToUpper(method), path, param.Ref, err)
+			}
+		}
+
+		switch param.In {
+		case "path":
+			operation.pathParams = append(operation.pathParams, param.Name)
+			param.Required = true
+		case "query":
+			operation.queryParams = append(operation.queryParams, param.Name)
+		case "header":
+			operation.headerParams = append(operation.headerParams, param.Name)
+		default:
+			// Cookie parameters are not supported
+			continue
+		}
+
+		schema := inlineOpenAPIRefs(root, param.Schema, 0)
+		property, _ := schema.(map[string]interface{})
+		if property == nil {
+			property = map[string]interface{}{"type": "string"}
+		}
+		if param.Description != "" {
+			property["description"] = param.Description
+		}
+		properties[param.Name] = property
+		if param.Required {
+			required = append(required, param.Name)
+		}
+	}
+
+	if op.RequestBody != nil {
+		requestBody := op.RequestBody
+		if requestBody.Ref != "" {
+			resolved, err := resolveOpenAPIRef(root, requestBody.Ref)
+			if err != nil {
+				return schemas.Tool{}, nil, fmt.Errorf("operation %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			raw, _ := json.Marshal(resolved)
+			if err := json.Unmarshal(raw, requestBody); err != nil {
+				return schemas.Tool{}, nil, fmt.Errorf("operation %s %s: invalid request body %s: %w", strings.ToUpper(method), path, requestBody.Ref, err)
+			}
+		}
+		if content, ok := requestBody.Content["application/json"]; ok {
+			operation.hasBody = true
+			schema := inlineOpenAPIRefs(root, content.Schema, 0)
+			property, _ := schema.(map[string]interface{})
+			if property == nil {
+				property = map[string]interface{}{"type": "object"}
+			}
+			if requestBody.Description != "" {
+				property["description"] = requestBody.Description
+			}
+			properties[openAPIBodyArgument] = property
+			if requestBody.Required {
+				required = append(required, openAPIBodyArgument)
+			}
+		}
+	}
+
+	description := op.Summary
+	if op.Description != "" {
+		if description != "" {
+			description += "\n\n"
+		}
+		description += op.Description
+	}
+	if description == "" {
+		description = fmt.Sprintf("%s %s", strings.ToUpper(method), path)
+	}
+
+	return schemas.Tool{
+		Type: "function",
+		Function: schemas.Function{
+			Name:        name,
+			Description: description,
+			Parameters: schemas.FunctionParameters{
+				Type:       "object",
+				Required:   required,
+				Properties: properties,
+			},
+		},
+	}, operation, nil
+}
+
+// inlineOpenAPIRefs returns a copy of the schema with every local $ref replaced by its target.
+func inlineOpenAPIRefs(root map[string]interface{}, schema interface{}, depth int) interface{} {
+	switch value := schema.(type) {
+	case map[string]interface{}:
+		if ref, ok := value["$ref"].(string); ok {
+			if depth >= openAPIMaxRefDepth {
+				// Recursive schema, stop expanding and let the model send a free form object
+				return map[string]interface{}{"type": "object"}
+			}
+			resolved, err := resolveOpenAPIRef(root, ref)
+			if err != nil {
+				return map[string]interface{}{"type": "object"}
+			}
+			return inlineOpenAPIRefs(root, resolved, depth+1)
+		}
+		out := make(map[string]interface{}, len(value))
+		for key, v := range value {
+			out[key] = inlineOpenAPIRefs(root, v, depth)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, v := range value {
+			out[i] = inlineOpenAPIRefs(root, v, depth)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// resolveOpenAPIRef resolves a local JSON pointer such as "#/components/schemas/Pet".
+func resolveOpenAPIRef(root map[string]interface{}, ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("only local references are supported, got %q", ref)
+	}
+
+	var current interface{} = root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		node, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference %q", ref)
+		}
+		if current, ok = node[part]; !ok {
+			return nil, fmt.Errorf("unresolvable reference %q", ref)
+		}
+	}
+
+	return current, nil
+}
+
+// openAPIToolName derives a provider-safe tool name from the operationId, falling back to method and path.
+func openAPIToolName(prefix, operationID, method, path string) string {
+	name := operationID
+	if name == "" {
+		name = method + "_" + strings.NewReplacer("{", "", "}", "").Replace(path)
+	}
+	name = strings.Trim(openAPIToolNameSanitizer.ReplaceAllString(prefix+name, "_"), "_")
+	if len(name) > openAPIMaxToolNameLength {
+		name = name[:openAPIMaxToolNameLength]
+	}
+	return name
+}
+
+// openAPIArgString formats a tool argument for use in a path, query string or header.
+func openAPIArgString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	}
+}
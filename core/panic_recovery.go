@@ -0,0 +1,81 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// PANIC RECOVERY
+// ============================================================================
+
+// A panic in a provider or a plugin hook would otherwise crash the whole process. Panics of
+// providers fail the request with an InternalPanic error, which is not retried but lets
+// fallbacks be tried; panics of plugin hooks are handled like hook errors, the request goes on
+// without the hook's changes. Every recovered panic is logged with its stack.
+
+// panicRecoveryMiddleware wraps next, converting a panic of the provider into an error. Panics
+// of the goroutines reading a stream are recovered by the providers.
+func (bifrost *Bifrost) panicRecoveryMiddleware(next schemas.ProviderCaller) schemas.ProviderCaller {
+	return schemas.ProviderCallerFuncs{
+		CallFunc: func(call *schemas.ProviderCall) (result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					result, bifrostErr = nil, bifrost.providerPanicError(call, recovered)
+				}
+			}()
+			return next.Call(call)
+		},
+		CallStreamFunc: func(call *schemas.ProviderCall) (stream chan *schemas.BifrostStream, bifrostErr *schemas.BifrostError) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					stream, bifrostErr = nil, bifrost.providerPanicError(call, recovered)
+				}
+			}()
+			return next.CallStream(call)
+		},
+	}
+}
+
+// providerPanicError logs a panic recovered from a provider call and returns it as an error.
+func (bifrost *Bifrost) providerPanicError(call *schemas.ProviderCall, recovered any) *schemas.BifrostError {
+	panicErr := schemas.NewPanicError(string(call.Provider), recovered)
+	logPanic(bifrost.logger, panicErr)
+	return panicErr.BifrostError(call.Provider)
+}
+
+// runPreHook runs the PreHook of a plugin. If it panics, the request it was given is passed on
+// and the panic is returned as the hook's error.
+func (p *PluginPipeline) runPreHook(plugin schemas.Plugin, ctx *context.Context, req *schemas.BifrostRequest) (result *schemas.BifrostRequest, shortCircuit *schemas.PluginShortCircuit, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panicErr := schemas.NewPanicError(fmt.Sprintf("PreHook of %s", plugin.GetName()), recovered)
+			logPanic(p.logger, panicErr)
+			result, shortCircuit, err = req, nil, panicErr
+		}
+	}()
+	return plugin.PreHook(ctx, req)
+}
+
+// runPostHook runs the PostHook of a plugin. If it panics, the response and error it was given
+// are passed on and the panic is returned as the hook's error.
+func (p *PluginPipeline) runPostHook(plugin schemas.Plugin, ctx *context.Context, resp *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (result *schemas.BifrostResponse, resultErr *schemas.BifrostError, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panicErr := schemas.NewPanicError(fmt.Sprintf("PostHook of %s", plugin.GetName()), recovered)
+			logPanic(p.logger, panicErr)
+			result, resultErr, err = resp, bifrostErr, panicErr
+		}
+	}()
+	return plugin.PostHook(ctx, resp, bifrostErr)
+}
+
+// logPanic logs a recovered panic with its stack.
+func logPanic(logger schemas.Logger, panicErr *schemas.PanicError) {
+	if logger == nil {
+		return
+	}
+	logger.Error("recovered %s\n%s", panicErr.Error(), panicErr.Stack)
+}
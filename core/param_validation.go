@@ -0,0 +1,565 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// STRICT PARAMETER VALIDATION
+// ============================================================================
+
+// paramRuleAny accepts any value.
+var paramRuleAny = schemas.ParamRule{}
+
+func paramRuleType(t string) schemas.ParamRule {
+	return schemas.ParamRule{Type: t}
+}
+
+func paramRuleRange(t string, minimum, maximum float64) schemas.ParamRule {
+	return schemas.ParamRule{Type: t, Minimum: &minimum, Maximum: &maximum}
+}
+
+func paramRuleMin(t string, minimum float64) schemas.ParamRule {
+	return schemas.ParamRule{Type: t, Minimum: &minimum}
+}
+
+func paramRuleEnum(values ...interface{}) schemas.ParamRule {
+	return schemas.ParamRule{Enum: values}
+}
+
+// mergeParamSchemas returns a new schema containing the rules of all given schemas,
+// later schemas overriding earlier ones.
+func mergeParamSchemas(paramSchemas ...schemas.ParamSchema) schemas.ParamSchema {
+	merged := make(schemas.ParamSchema)
+	for _, paramSchema := range paramSchemas {
+		maps.Copy(merged, paramSchema)
+	}
+	return merged
+}
+
+func paramRuleObject(properties schemas.ParamSchema) schemas.ParamRule {
+	return schemas.ParamRule{Type: "object", Properties: properties}
+}
+
+// defaultParamSchemas returns the built-in parameter schemas of the standard providers.
+// They describe the top-level fields of the request bodies each provider builds: the fields
+// Bifrost fills from the request input, the parameters under the provider's names for them,
+// and the provider specific ExtraParams. Parameters providers nest in an object, such as
+// Gemini's generationConfig, are described by the properties of that object.
+func defaultParamSchemas() map[schemas.ModelProvider]schemas.ParamSchema {
+	common := schemas.ParamSchema{
+		"model":               paramRuleType("string"),
+		"messages":            paramRuleType("array"),
+		"stream":              paramRuleType("boolean"),
+		"tools":               paramRuleType("array"),
+		"tool_choice":         paramRuleAny,
+		"temperature":         paramRuleRange("number", 0, 2),
+		"top_p":               paramRuleRange("number", 0, 1),
+		"max_tokens":          paramRuleMin("integer", 1),
+		"stop_sequences":      paramRuleType("array"),
+		"user":                paramRuleType("string"),
+		"parallel_tool_calls": paramRuleType("boolean"),
+	}
+
+	openAI := mergeParamSchemas(common, schemas.ParamSchema{
+		"input":                   paramRuleAny, // string or array for embeddings, string for speech
+		"stream_options":          paramRuleType("object"),
+		"prompt_cache_key":        paramRuleType("string"),
+		"voice":                   paramRuleAny, // Speech
+		"instructions":            paramRuleType("string"),
+		"stream_format":           paramRuleEnum("sse", "audio"),
+		"presence_penalty":        paramRuleRange("number", -2, 2),
+		"frequency_penalty":       paramRuleRange("number", -2, 2),
+		"encoding_format":         paramRuleEnum("float", "base64"),
+		"dimensions":              paramRuleMin("integer", 1),
+		"logit_bias":              paramRuleType("object"),
+		"logprobs":                paramRuleType("boolean"),
+		"top_logprobs":            paramRuleRange("integer", 0, 20),
+		"n":                       paramRuleMin("integer", 1),
+		"seed":                    paramRuleType("integer"),
+		"stop":                    paramRuleAny,
+		"response_format":         paramRuleAny, // object for chat, string for audio
+		"max_completion_tokens":   paramRuleMin("integer", 1),
+		"metadata":                paramRuleType("object"),
+		"modalities":              paramRuleType("array"),
+		"prediction":              paramRuleType("object"),
+		"reasoning_effort":        paramRuleEnum("minimal", "low", "medium", "high"),
+		"service_tier":            paramRuleType("string"),
+		"store":                   paramRuleType("boolean"),
+		"web_search_options":      paramRuleType("object"),
+		"audio":                   paramRuleType("object"),
+		"speed":                   paramRuleRange("number", 0.25, 4),
+		"language":                paramRuleType("string"),
+		"prompt":                  paramRuleType("string"),
+		"include":                 paramRuleType("array"),
+		"timestamp_granularities": paramRuleType("array"),
+	})
+
+	anthropic := mergeParamSchemas(common, schemas.ParamSchema{
+		"prompt":               paramRuleType("string"), // Text completion
+		"max_tokens_to_sample": paramRuleMin("integer", 1),
+		"temperature":          paramRuleRange("number", 0, 1),
+		"top_k":                paramRuleMin("integer", 0),
+		"system":               paramRuleAny,
+		"metadata":             paramRuleType("object"),
+		"mcp_servers":          paramRuleType("array"),
+		"service_tier":         paramRuleType("string"),
+		"thinking":             paramRuleType("object"),
+	})
+
+	cohere := mergeParamSchemas(common, schemas.ParamSchema{
+		"p":                  paramRuleRange("number", 0, 1),    // top_p
+		"k":                  paramRuleRange("integer", 0, 500), // top_k
+		"texts":              paramRuleType("array"),            // Embedding
+		"query":              paramRuleType("string"),           // Rerank
+		"documents":          paramRuleType("array"),
+		"top_n":              paramRuleMin("integer", 1),
+		"presence_penalty":   paramRuleRange("number", 0, 1),
+		"frequency_penalty":  paramRuleRange("number", 0, 1),
+		"truncate":           paramRuleType("string"),
+		"return_likelihoods": paramRuleType("string"),
+		"logit_bias":         paramRuleType("object"),
+		"input_type":         paramRuleType("string"),
+		"embedding_types":    paramRuleType("array"),
+		"seed":               paramRuleType("integer"),
+	})
+	delete(cohere, "top_p")
+
+	mistral := mergeParamSchemas(common, schemas.ParamSchema{
+		"input":             paramRuleAny, // Embedding
+		"output_dimension":  paramRuleMin("integer", 1),
+		"output_dtype":      paramRuleEnum("float"),
+		"presence_penalty":  paramRuleRange("number", -2, 2),
+		"frequency_penalty": paramRuleRange("number", -2, 2),
+		"top_k":             paramRuleMin("integer", 0),
+		"n":                 paramRuleMin("integer", 1),
+		"prediction":        paramRuleType("object"),
+		"prompt_mode":       paramRuleType("string"),
+		"random_seed":       paramRuleType("integer"),
+		"response_format":   paramRuleType("object"),
+		"safe_prompt":       paramRuleType("boolean"),
+		"safe_mode":         paramRuleType("boolean"),
+	})
+
+	groq := mergeParamSchemas(common, schemas.ParamSchema{
+		"prompt":            paramRuleType("string"), // Text completion
+		"presence_penalty":  paramRuleRange("number", -2, 2),
+		"frequency_penalty": paramRuleRange("number", -2, 2),
+		"n":                 paramRuleRange("integer", 1, 1),
+		"seed":              paramRuleType("integer"),
+		"stop":              paramRuleAny,
+		"response_format":   paramRuleType("object"),
+		"reasoning_effort":  paramRuleType("string"),
+		"reasoning_format":  paramRuleType("string"),
+		"service_tier":      paramRuleType("string"),
+	})
+
+	// Ollama takes the sampling parameters and its own model options in an options object
+	ollamaOptions := schemas.ParamSchema{
+		"temperature":       paramRuleRange("number", 0, 2),
+		"top_p":             paramRuleRange("number", 0, 1),
+		"top_k":             paramRuleMin("integer", 0),
+		"num_predict":       paramRuleType("integer"),
+		"stop":              paramRuleType("array"),
+		"presence_penalty":  paramRuleRange("number", -2, 2),
+		"frequency_penalty": paramRuleRange("number", -2, 2),
+		"seed":              paramRuleType("integer"),
+	}
+	for _, name := range []string{
+		"num_ctx", "num_gpu", "num_thread", "repeat_penalty", "repeat_last_n", "tfs_z", "mirostat",
+		"mirostat_tau", "mirostat_eta", "low_vram", "main_gpu", "min_p", "num_batch", "num_keep", "numa",
+		"penalize_newline", "typical_p", "use_mlock", "use_mmap", "vocab_only",
+	} {
+		ollamaOptions[name] = paramRuleAny
+	}
+	ollama := schemas.ParamSchema{
+		"model":      paramRuleType("string"),
+		"messages":   paramRuleType("array"),
+		"prompt":     paramRuleType("string"), // Embedding
+		"stream":     paramRuleType("boolean"),
+		"tools":      paramRuleType("array"),
+		"options":    paramRuleObject(ollamaOptions),
+		"format":     paramRuleAny, // "json" or a JSON schema
+		"keep_alive": paramRuleAny,
+		"think":      paramRuleAny,
+	}
+
+	// Bedrock's Converse API takes the standard parameters in inferenceConfig, its invoke API
+	// takes the native body of the model
+	bedrock := mergeParamSchemas(anthropic, mistral, schemas.ParamSchema{
+		"temperature": paramRuleRange("number", 0, 1),
+		"inputText":   paramRuleType("string"), // Titan embedding
+		"texts":       paramRuleType("array"),  // Cohere embedding
+		"input_type":  paramRuleType("string"),
+		"toolConfig":  paramRuleType("object"),
+		"inferenceConfig": paramRuleObject(schemas.ParamSchema{
+			"maxTokens":     paramRuleMin("integer", 1),
+			"temperature":   paramRuleRange("number", 0, 1),
+			"topP":          paramRuleRange("number", 0, 1),
+			"stopSequences": paramRuleType("array"),
+		}),
+
+		"additionalModelRequestFields":      paramRuleType("object"),
+		"additionalModelResponseFieldPaths": paramRuleType("array"),
+		"guardrailConfig":                   paramRuleType("object"),
+		"performanceConfig":                 paramRuleType("object"),
+		"promptVariables":                   paramRuleType("object"),
+		"requestMetadata":                   paramRuleType("object"),
+	})
+	delete(bedrock, "model")
+
+	// Gemini's native API takes the standard parameters in generationConfig
+	gemini := mergeParamSchemas(openAI, schemas.ParamSchema{
+		"contents": paramRuleType("array"),
+		"generationConfig": paramRuleObject(schemas.ParamSchema{
+			"temperature":        paramRuleRange("number", 0, 2),
+			"topP":               paramRuleRange("number", 0, 1),
+			"topK":               paramRuleMin("integer", 0),
+			"maxOutputTokens":    paramRuleMin("integer", 1),
+			"stopSequences":      paramRuleType("array"),
+			"presencePenalty":    paramRuleType("number"),
+			"frequencyPenalty":   paramRuleType("number"),
+			"seed":               paramRuleType("integer"),
+			"candidateCount":     paramRuleMin("integer", 1),
+			"responseMimeType":   paramRuleType("string"),
+			"responseSchema":     paramRuleType("object"),
+			"responseJsonSchema": paramRuleType("object"),
+			"responseModalities": paramRuleType("array"),
+			"responseLogprobs":   paramRuleType("boolean"),
+			"logprobs":           paramRuleType("integer"),
+			"speechConfig":       paramRuleType("object"),
+			"thinkingConfig":     paramRuleType("object"),
+			"mediaResolution":    paramRuleType("string"),
+		}),
+		"safetySettings":    paramRuleType("array"),
+		"cachedContent":     paramRuleType("string"),
+		"toolConfig":        paramRuleType("object"),
+		"systemInstruction": paramRuleType("object"),
+		"instances":         paramRuleType("array"),  // Video generation
+		"parameters":        paramRuleType("object"), // Video generation
+	})
+
+	vertex := mergeParamSchemas(openAI, anthropic, gemini, schemas.ParamSchema{
+		"temperature":       paramRuleRange("number", 0, 2),
+		"anthropic_version": paramRuleType("string"),
+		"parameters": paramRuleObject(schemas.ParamSchema{ // Embedding
+			"autoTruncate":         paramRuleType("boolean"),
+			"outputDimensionality": paramRuleMin("integer", 1),
+		}),
+	})
+
+	openRouter := mergeParamSchemas(openAI, schemas.ParamSchema{
+		"top_k":              paramRuleMin("integer", 0),
+		"transforms":         paramRuleType("array"),
+		"models":             paramRuleType("array"),
+		"route":              paramRuleType("string"),
+		"provider":           paramRuleType("object"),
+		"top_a":              paramRuleRange("number", 0, 1),
+		"min_p":              paramRuleRange("number", 0, 1),
+		"repetition_penalty": paramRuleRange("number", 0, 2),
+	})
+
+	perplexity := mergeParamSchemas(openAI, schemas.ParamSchema{
+		"search_domain_filter":     paramRuleType("array"),
+		"search_recency_filter":    paramRuleEnum("month", "week", "day", "hour"),
+		"return_images":            paramRuleType("boolean"),
+		"return_related_questions": paramRuleType("boolean"),
+		"search_mode":              paramRuleEnum("web", "academic"),
+	})
+
+	deepSeek := mergeParamSchemas(openAI, schemas.ParamSchema{
+		"thinking": paramRuleAny, // boolean, or an object like {"type": "enabled"}
+	})
+
+	together := mergeParamSchemas(openAI, schemas.ParamSchema{
+		"top_k":              paramRuleMin("integer", 0),
+		"min_p":              paramRuleRange("number", 0, 1),
+		"repetition_penalty": paramRuleType("number"),
+		"safety_model":       paramRuleType("string"),
+		"width":              paramRuleMin("integer", 1), // Image generation
+		"height":             paramRuleMin("integer", 1),
+		"negative_prompt":    paramRuleType("string"),
+		"output_format":      paramRuleType("string"),
+		"steps":              paramRuleMin("integer", 1),
+		"guidance_scale":     paramRuleType("number"),
+	})
+
+	qwenParams := schemas.ParamSchema{
+		"top_k":                     paramRuleMin("integer", 0),
+		"repetition_penalty":        paramRuleMin("number", 0),
+		"enable_search":             paramRuleType("boolean"),
+		"search_options":            paramRuleType("object"), // e.g. {"enable_source": true, "forced_search": true}
+		"enable_thinking":           paramRuleType("boolean"),
+		"thinking_budget":           paramRuleMin("integer", 1),
+		"vl_high_resolution_images": paramRuleType("boolean"), // Multimodal models
+	}
+	// Multimodal models take the messages in input and the parameters in a parameters object
+	qwen := mergeParamSchemas(openAI, qwenParams, schemas.ParamSchema{
+		"parameters": paramRuleObject(mergeParamSchemas(openAI, qwenParams, schemas.ParamSchema{
+			"result_format":      paramRuleType("string"),
+			"incremental_output": paramRuleType("boolean"),
+		})),
+	})
+
+	zhipu := mergeParamSchemas(openAI, schemas.ParamSchema{
+		"do_sample":  paramRuleType("boolean"),
+		"request_id": paramRuleType("string"),
+		"user_id":    paramRuleType("string"),
+		"thinking":   paramRuleType("object"), // e.g. {"type": "enabled"}
+	})
+
+	qianfan := mergeParamSchemas(common, schemas.ParamSchema{
+		"temperature":       paramRuleRange("number", 0, 1),
+		"max_output_tokens": paramRuleMin("integer", 1), // max_tokens
+		"stop":              paramRuleType("array"),     // stop_sequences
+		"user_id":           paramRuleType("string"),    // user
+		"input":             paramRuleType("array"),     // Embedding
+		"penalty_score":     paramRuleRange("number", 1, 2),
+		"system":            paramRuleType("string"),
+		"functions":         paramRuleType("array"),
+		"response_format":   paramRuleEnum("text", "json_object"),
+		"disable_search":    paramRuleType("boolean"),
+		"enable_citation":   paramRuleType("boolean"),
+		"enable_trace":      paramRuleType("boolean"),
+	})
+	for _, name := range []string{"model", "max_tokens", "stop_sequences", "user", "parallel_tool_calls"} {
+		delete(qianfan, name)
+	}
+
+	sambaNova := mergeParamSchemas(openAI, schemas.ParamSchema{
+		"top_k": paramRuleRange("integer", 1, 100),
+	})
+
+	nim := mergeParamSchemas(openAI, schemas.ParamSchema{
+		"input_type": paramRuleType("string"), // Embedding
+		"query":      paramRuleType("object"), // Rerank
+		"passages":   paramRuleType("array"),
+		"truncate":   paramRuleType("string"),
+	})
+
+	// The watsonx generation API takes its parameters in a parameters object, the chat API at
+	// the top level like OpenAI
+	watsonx := mergeParamSchemas(openAI, schemas.ParamSchema{
+		"model_id":           paramRuleType("string"),
+		"project_id":         paramRuleType("string"),
+		"space_id":           paramRuleType("string"),
+		"tool_choice_option": paramRuleEnum("none", "auto", "required"),
+		"time_limit":         paramRuleMin("integer", 1),
+		"parameters": paramRuleObject(schemas.ParamSchema{
+			"decoding_method":       paramRuleEnum("greedy", "sample"),
+			"max_new_tokens":        paramRuleMin("integer", 1),
+			"min_new_tokens":        paramRuleMin("integer", 0),
+			"stop_sequences":        paramRuleType("array"),
+			"temperature":           paramRuleRange("number", 0, 2),
+			"top_p":                 paramRuleRange("number", 0, 1),
+			"top_k":                 paramRuleRange("integer", 1, 100),
+			"random_seed":           paramRuleMin("integer", 1),
+			"repetition_penalty":    paramRuleRange("number", 1, 2),
+			"time_limit":            paramRuleMin("integer", 1),
+			"truncate_input_tokens": paramRuleMin("integer", 1),
+			"include_stop_sequence": paramRuleType("boolean"),
+			"length_penalty":        paramRuleType("object"),
+			"return_options":        paramRuleType("object"),
+		}),
+	})
+	for _, name := range []string{"model", "stop_sequences", "user", "parallel_tool_calls"} {
+		delete(watsonx, name)
+	}
+
+	return map[schemas.ModelProvider]schemas.ParamSchema{
+		schemas.OpenAI:     openAI,
+		schemas.Azure:      openAI,
+		schemas.Anthropic:  anthropic,
+		schemas.Cohere:     cohere,
+		schemas.Mistral:    mistral,
+		schemas.Groq:       groq,
+		schemas.Ollama:     ollama,
+		schemas.Bedrock:    bedrock,
+		schemas.Vertex:     vertex,
+		schemas.Gemini:     gemini,
+		schemas.OpenRouter: openRouter,
+		schemas.Perplexity: perplexity,
+		schemas.DeepSeek:   deepSeek,
+		schemas.Together:   together,
+		schemas.Qwen:       qwen,
+		schemas.Zhipu:      zhipu,
+		schemas.Moonshot:   openAI,
+		schemas.Qianfan:    qianfan,
+		schemas.Cerebras:   openAI,
+		schemas.SambaNova:  sambaNova,
+		schemas.NIM:        nim,
+		schemas.Watsonx:    watsonx,
+		schemas.SGL:        openAI,
+		schemas.Parasail:   openAI,
+	}
+}
+
+// buildParamSchemas combines the built-in schemas with the ones configured by the user.
+// Configured schemas extend the built-in schema of the same provider.
+func buildParamSchemas(configured map[schemas.ModelProvider]schemas.ParamSchema) map[schemas.ModelProvider]schemas.ParamSchema {
+	paramSchemas := defaultParamSchemas()
+	for provider, paramSchema := range configured {
+		paramSchemas[provider] = mergeParamSchemas(paramSchemas[provider], paramSchema)
+	}
+	return paramSchemas
+}
+
+// isStrictParams reports whether strict parameter validation applies to the request.
+func (bifrost *Bifrost) isStrictParams(ctx context.Context) bool {
+	if ctx != nil {
+		if strict, ok := ctx.Value(schemas.BifrostContextKeyStrictParams).(bool); ok {
+			return strict
+		}
+	}
+	return bifrost.strictParams
+}
+
+// getParamSchema returns the parameter schema of a provider, falling back to the base
+// provider of custom providers. Returns nil if no schema is known for the provider.
+func (bifrost *Bifrost) getParamSchema(provider schemas.ModelProvider) schemas.ParamSchema {
+	if paramSchema, ok := bifrost.paramSchemas[provider]; ok {
+		return paramSchema
+	}
+	config, err := bifrost.account.GetConfigForProvider(provider)
+	if err != nil || config == nil || config.CustomProviderConfig == nil {
+		return nil
+	}
+	return bifrost.paramSchemas[config.CustomProviderConfig.BaseProviderType]
+}
+
+// withStrictParams returns ctx with a check of the provider request bodies against the schema
+// of the provider when strict mode applies to the request, and ctx unchanged otherwise.
+// Providers run the check on the final body, after extra params and the fields they inject
+// themselves have been merged in.
+func (bifrost *Bifrost) withStrictParams(ctx context.Context, provider schemas.ModelProvider) context.Context {
+	if !bifrost.isStrictParams(ctx) {
+		return ctx
+	}
+
+	paramSchema := bifrost.getParamSchema(provider)
+	if paramSchema == nil {
+		return ctx
+	}
+
+	check := schemas.RequestBodyCheck(func(body []byte) *schemas.BifrostError {
+		return checkRequestBody(provider, paramSchema, body)
+	})
+	return context.WithValue(ctx, schemas.BifrostContextKeyRequestBodyCheck, check)
+}
+
+// checkRequestBody validates the top-level fields of a provider request body against a schema,
+// returning an error listing every offending field. Bodies that are not JSON objects, such as
+// multipart uploads, are not checked.
+func checkRequestBody(provider schemas.ModelProvider, paramSchema schemas.ParamSchema, body []byte) *schemas.BifrostError {
+	var fields map[string]interface{}
+	if err := sonic.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil
+	}
+
+	violations := checkParams(paramSchema, fields)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "strict params: %d invalid field(s) in the request body for provider %s:", len(violations), provider)
+	for _, violation := range violations {
+		fmt.Fprintf(&message, "\n  - %s: %s", violation.Param, violation.Reason)
+	}
+
+	return &schemas.BifrostError{
+		IsBifrostError: true,
+		Provider:       provider,
+		Origin:         schemas.ErrorOriginClientRequest,
+		Error: schemas.ErrorField{
+			Type:    Ptr("invalid_request_error"),
+			Message: message.String(),
+			Param:   violations,
+		},
+	}
+}
+
+// checkParams returns the violations of the fields of a JSON object against a schema, sorted by
+// field name. Fields of nested objects are named by their path, e.g. generationConfig.topK.
+func checkParams(paramSchema schemas.ParamSchema, params map[string]interface{}) []schemas.ParamViolation {
+	var violations []schemas.ParamViolation
+
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		value := params[name]
+		rule, ok := paramSchema[name]
+		if !ok {
+			violations = append(violations, schemas.ParamViolation{Param: name, Value: value, Reason: "unsupported parameter"})
+			continue
+		}
+		if reason := checkParamRule(rule, value); reason != "" {
+			violations = append(violations, schemas.ParamViolation{Param: name, Value: value, Reason: reason})
+			continue
+		}
+		if fields, ok := value.(map[string]interface{}); ok && rule.Properties != nil {
+			for _, violation := range checkParams(rule.Properties, fields) {
+				violation.Param = name + "." + violation.Param
+				violations = append(violations, violation)
+			}
+		}
+	}
+
+	return violations
+}
+
+// checkParamRule returns why a JSON decoded value does not satisfy a rule, or "" if it does.
+func checkParamRule(rule schemas.ParamRule, value interface{}) string {
+	if rule.Type != "" {
+		if actual := jsonTypeOf(value); actual != rule.Type && (rule.Type != "number" || actual != "integer") {
+			return fmt.Sprintf("expected %s, got %s", rule.Type, actual)
+		}
+	}
+
+	if len(rule.Enum) > 0 && !slices.ContainsFunc(rule.Enum, func(candidate interface{}) bool {
+		return toolArgumentEquals(candidate, value)
+	}) {
+		return fmt.Sprintf("%v is not one of %v", value, rule.Enum)
+	}
+
+	if n, ok := value.(float64); ok {
+		if rule.Minimum != nil && n < *rule.Minimum {
+			return fmt.Sprintf("%v is below the minimum %v", n, *rule.Minimum)
+		}
+		if rule.Maximum != nil && n > *rule.Maximum {
+			return fmt.Sprintf("%v is above the maximum %v", n, *rule.Maximum)
+		}
+	}
+
+	return ""
+}
+
+// jsonTypeOf returns the JSON schema type name of a JSON decoded value.
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return reflect.TypeOf(value).String()
+	}
+}
@@ -0,0 +1,153 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestCheckRequestBody(t *testing.T) {
+	paramSchemas := defaultParamSchemas()
+
+	tests := []struct {
+		name       string
+		provider   schemas.ModelProvider
+		body       string
+		wantParams []string // Offending fields, none if the body is valid
+	}{
+		{
+			name:     "valid body",
+			provider: schemas.OpenAI,
+			body:     `{"model":"gpt-4o","messages":[],"stream":true,"stream_options":{"include_usage":true},"temperature":0.5}`,
+		},
+		{
+			name:       "unsupported and out of range fields",
+			provider:   schemas.OpenAI,
+			body:       `{"model":"gpt-4o","messages":[],"top_k":5,"temperature":3}`,
+			wantParams: []string{"temperature", "top_k"},
+		},
+		{
+			name:       "wrong type",
+			provider:   schemas.Anthropic,
+			body:       `{"model":"claude","messages":[],"max_tokens":"many"}`,
+			wantParams: []string{"max_tokens"},
+		},
+		{
+			name:     "renamed parameter",
+			provider: schemas.Cohere,
+			body:     `{"model":"command-r","messages":[],"p":0.5,"k":10}`,
+		},
+		{
+			name:       "nested parameters",
+			provider:   schemas.Gemini,
+			body:       `{"contents":[],"generationConfig":{"temperature":1,"topK":-1,"bogus":true}}`,
+			wantParams: []string{"generationConfig.bogus", "generationConfig.topK"},
+		},
+		{
+			name:     "not a JSON object",
+			provider: schemas.OpenAI,
+			body:     "--boundary\r\nContent-Disposition: form-data; name=\"model\"\r\n\r\nwhisper-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bifrostErr := checkRequestBody(tt.provider, paramSchemas[tt.provider], []byte(tt.body))
+			if len(tt.wantParams) == 0 {
+				if bifrostErr != nil {
+					t.Fatalf("checkRequestBody() = %s, want no error", bifrostErr.Error.Message)
+				}
+				return
+			}
+			if bifrostErr == nil {
+				t.Fatalf("checkRequestBody() = nil, want errors for %v", tt.wantParams)
+			}
+			violations := bifrostErr.Error.Param.([]schemas.ParamViolation)
+			var got []string
+			for _, violation := range violations {
+				got = append(got, violation.Param)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantParams, ",") {
+				t.Errorf("offending fields = %v, want %v", got, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestStrictParamsChecksProviderBody(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": "chatcmpl-1", "object": "chat.completion", "model": "gpt-4o",
+			"choices": []map[string]interface{}{{"index": 0, "finish_reason": "stop", "message": map[string]interface{}{"role": "assistant", "content": "hi"}}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := Init(context.Background(), schemas.BifrostConfig{
+		Account:      &upstreamAccount{baseURL: server.URL},
+		Logger:       NewDefaultLogger(schemas.LogLevelError),
+		StrictParams: true,
+		ParamSchemas: map[schemas.ModelProvider]schemas.ParamSchema{
+			schemas.OpenAI: {"custom_flag": {Type: "boolean"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer client.Shutdown()
+
+	text := "hello"
+	temperature := 0.5
+	tests := []struct {
+		name        string
+		extraParams map[string]interface{}
+		strict      *bool // Per request override
+		wantErr     string
+	}{
+		{name: "valid"},
+		{name: "unsupported extra param", extraParams: map[string]interface{}{"bogus": 1}, wantErr: "bogus: unsupported parameter"},
+		{name: "invalid extra param", extraParams: map[string]interface{}{"top_logprobs": 50}, wantErr: "top_logprobs: 50 is above the maximum 20"},
+		{name: "configured field", extraParams: map[string]interface{}{"custom_flag": true}},
+		{name: "disabled per request", extraParams: map[string]interface{}{"bogus": 1}, strict: new(bool)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.strict != nil {
+				ctx = context.WithValue(ctx, schemas.BifrostContextKeyStrictParams, *tt.strict)
+			}
+			before := requests.Load()
+			_, bifrostErr := client.ChatCompletionRequest(ctx, &schemas.BifrostRequest{
+				Provider: schemas.OpenAI,
+				Model:    "gpt-4o",
+				Input:    schemas.RequestInput{ChatCompletionInput: &[]schemas.BifrostMessage{{Role: schemas.ModelChatMessageRoleUser, Content: schemas.MessageContent{ContentStr: &text}}}},
+				Params:   &schemas.ModelParameters{Temperature: &temperature, ExtraParams: tt.extraParams},
+			})
+			sent := requests.Load() - before
+
+			if tt.wantErr == "" {
+				if bifrostErr != nil {
+					t.Fatalf("request error = %s", bifrostErr.Error.Message)
+				}
+				if sent != 1 {
+					t.Errorf("upstream got %d requests, want 1", sent)
+				}
+				return
+			}
+			if bifrostErr == nil || !strings.Contains(bifrostErr.Error.Message, tt.wantErr) {
+				t.Fatalf("request error = %+v, want one containing %q", bifrostErr, tt.wantErr)
+			}
+			if sent != 0 {
+				t.Errorf("upstream got %d requests, want the request to fail locally", sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// PAYLOAD SLIMMING
+// ============================================================================
+
+// requestTooLargeMarkers are fragments of the errors providers return with a 400 for prompts
+// exceeding the context window or the request size limit. Other 400s are not recoverable by
+// slimming the request.
+var requestTooLargeMarkers = []string{
+	"context_length_exceeded",
+	"context length",
+	"context window",
+	"maximum context",
+	"prompt is too long",
+	"input is too long",
+	"too many tokens",
+	"request too large",
+	"request_too_large",
+	"payload too large",
+	"entity too large",
+}
+
+// slimmedImagePlaceholder replaces the inline images dropped from a message.
+const slimmedImagePlaceholder = "[image omitted]"
+
+// payloadSlimmer shrinks chat and text completion requests rejected as too large.
+type payloadSlimmer struct {
+	targetPercent  float64
+	imageOffloader func(ctx context.Context, data []byte, mimeType string) (string, error)
+}
+
+// newPayloadSlimmer creates the payload slimming layer, nil if config is nil.
+func newPayloadSlimmer(config *schemas.PayloadSlimmingConfig) *payloadSlimmer {
+	if config == nil {
+		return nil
+	}
+	slimmer := &payloadSlimmer{
+		targetPercent:  schemas.DefaultPayloadSlimmingTargetPercent,
+		imageOffloader: config.ImageOffloader,
+	}
+	if config.TargetPercent > 0 {
+		slimmer.targetPercent = min(config.TargetPercent, 100)
+	}
+	return slimmer
+}
+
+// getPayloadSlimmer returns the payload slimming layer to use for a request, nil when it is not
+// configured or the context turns it off.
+func (bifrost *Bifrost) getPayloadSlimmer(ctx context.Context) *payloadSlimmer {
+	if ctx != nil {
+		if enabled, ok := ctx.Value(schemas.BifrostContextKeyPayloadSlimming).(bool); ok && !enabled {
+			return nil
+		}
+	}
+	return bifrost.payloadSlimmer
+}
+
+// isRequestTooLargeError reports whether a provider rejected a request for its size: a 413, or a
+// 400 whose error is about the context length or request size.
+func isRequestTooLargeError(bifrostErr *schemas.BifrostError) bool {
+	if bifrostErr.StatusCode == nil {
+		return false
+	}
+	switch *bifrostErr.StatusCode {
+	case 413:
+		return true
+	case 400:
+		text := bifrostErr.Error.Message
+		if bifrostErr.Error.Type != nil {
+			text += " " + *bifrostErr.Error.Type
+		}
+		if bifrostErr.Error.Code != nil {
+			text += " " + *bifrostErr.Error.Code
+		}
+		text = strings.ToLower(text)
+		for _, marker := range requestTooLargeMarkers {
+			if strings.Contains(text, marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// payloadSlimmingMiddleware wraps next, slimming a request rejected as too large and sending it
+// again once. The attempts of the slimmed request go through the rest of the chain again, retries
+// included.
+func (bifrost *Bifrost) payloadSlimmingMiddleware(next schemas.ProviderCaller) schemas.ProviderCaller {
+	return schemas.ProviderCallerFuncs{
+		CallFunc: func(call *schemas.ProviderCall) (*schemas.BifrostResponse, *schemas.BifrostError) {
+			return withPayloadSlimming(bifrost, call, next.Call)
+		},
+		CallStreamFunc: func(call *schemas.ProviderCall) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+			return withPayloadSlimming(bifrost, call, next.CallStream)
+		},
+	}
+}
+
+// withPayloadSlimming sends a call, and sends a slimmed copy of it if it was rejected as too large.
+func withPayloadSlimming[T any](bifrost *Bifrost, call *schemas.ProviderCall, send func(*schemas.ProviderCall) (T, *schemas.BifrostError)) (T, *schemas.BifrostError) {
+	result, bifrostErr := send(call)
+	slimmer := bifrost.getPayloadSlimmer(call.Context)
+	if bifrostErr == nil || slimmer == nil || !isRequestTooLargeError(bifrostErr) {
+		return result, bifrostErr
+	}
+	slimmed := *call
+	if !slimmer.slimRequest(&slimmed) {
+		return result, bifrostErr
+	}
+	bifrost.logger.Info("request for model %s was too large, sending it again slimmed down: %s", call.Model, bifrostErr.Error.Message)
+	return send(&slimmed)
+}
+
+// slimRequest slims the input of a request rejected as too large, in place, and records what was
+// removed as a warning. It reports false if the request could not be slimmed, in which case the
+// error stands.
+func (s *payloadSlimmer) slimRequest(req *schemas.ProviderCall) bool {
+	var changes []string
+	switch req.Type {
+	case schemas.ChatCompletionRequest, schemas.ChatCompletionStreamRequest:
+		if req.Input.ChatCompletionInput == nil {
+			return false
+		}
+		var messages []schemas.BifrostMessage
+		messages, changes = s.slimMessages(req.Context, *req.Input.ChatCompletionInput)
+		if len(changes) > 0 {
+			req.Input.ChatCompletionInput = &messages
+		}
+	case schemas.TextCompletionRequest:
+		if req.Input.TextCompletionInput == nil {
+			return false
+		}
+		if text, compressed := compressWhitespace(*req.Input.TextCompletionInput); compressed {
+			req.Input.TextCompletionInput = &text
+			changes = append(changes, "compressed whitespace")
+		}
+	}
+	if len(changes) == 0 {
+		return false
+	}
+
+	schemas.AddWarning(req.Context, schemas.WarningTruncated, schemas.WarningOriginBifrost,
+		fmt.Sprintf("request was too large for %s, sent again after slimming it: %s", req.Model, strings.Join(changes, ", ")))
+	return true
+}
+
+// slimMessages returns a slimmed copy of the messages of a chat request and what was removed.
+// Inline images are offloaded or omitted and whitespace compressed first; if the messages are
+// still above the target size, the oldest turns are dropped until they fit, keeping system
+// messages and the last user turn. Omitted images are reported with a warning of their own, as
+// the model no longer sees them.
+func (s *payloadSlimmer) slimMessages(ctx context.Context, messages []schemas.BifrostMessage) ([]schemas.BifrostMessage, []string) {
+	originalSize := messagesSize(messages)
+
+	slimmed := make([]schemas.BifrostMessage, len(messages))
+	var offloadedImages, droppedImages int
+	var compressed bool
+	for i, msg := range messages {
+		slimmed[i] = msg
+		if msg.Content.ContentStr != nil {
+			if text, changed := compressWhitespace(*msg.Content.ContentStr); changed {
+				slimmed[i].Content.ContentStr = &text
+				compressed = true
+			}
+		}
+		if msg.Content.ContentBlocks != nil {
+			blocks := make([]schemas.ContentBlock, len(*msg.Content.ContentBlocks))
+			for j, block := range *msg.Content.ContentBlocks {
+				blocks[j] = block
+				switch {
+				case block.Type == schemas.ContentBlockTypeImage && block.ImageURL != nil && strings.HasPrefix(block.ImageURL.URL, "data:"):
+					if url, ok := s.offloadImage(ctx, block.ImageURL.URL); ok {
+						imageURL := *block.ImageURL
+						imageURL.URL = url
+						blocks[j].ImageURL = &imageURL
+						offloadedImages++
+						continue
+					}
+					blocks[j] = schemas.ContentBlock{Type: schemas.ContentBlockTypeText, Text: Ptr(slimmedImagePlaceholder)}
+					droppedImages++
+				case block.Text != nil:
+					if text, changed := compressWhitespace(*block.Text); changed {
+						blocks[j].Text = &text
+						compressed = true
+					}
+				}
+			}
+			slimmed[i].Content.ContentBlocks = &blocks
+		}
+	}
+
+	var changes []string
+	if offloadedImages > 0 {
+		changes = append(changes, fmt.Sprintf("sent %d inline %s by URL", offloadedImages, plural(offloadedImages, "image", "images")))
+	}
+	if droppedImages > 0 {
+		changes = append(changes, fmt.Sprintf("dropped %d inline %s", droppedImages, plural(droppedImages, "image", "images")))
+		schemas.AddWarning(ctx, schemas.WarningTruncated, schemas.WarningOriginBifrost,
+			fmt.Sprintf("%d inline %s replaced by %q to fit the request, the model did not see %s",
+				droppedImages, plural(droppedImages, "image was", "images were"), slimmedImagePlaceholder, plural(droppedImages, "it", "them")))
+	}
+	if compressed {
+		changes = append(changes, "compressed whitespace")
+	}
+
+	target := int(float64(originalSize) * s.targetPercent / 100)
+	if messagesSize(slimmed) > target {
+		var dropped int
+		slimmed, dropped = dropOldestTurns(slimmed, target)
+		if dropped > 0 {
+			changes = append(changes, fmt.Sprintf("dropped the %d oldest %s", dropped, plural(dropped, "message", "messages")))
+		}
+	}
+	return slimmed, changes
+}
+
+// offloadImage stores the image of a base64 data URL with the image offloader and returns the
+// URL it can be fetched from. It returns false if there is no offloader or the image could not
+// be stored, in which case the image is omitted.
+func (s *payloadSlimmer) offloadImage(ctx context.Context, dataURL string) (string, bool) {
+	if s.imageOffloader == nil {
+		return "", false
+	}
+	header, encoded, ok := strings.Cut(strings.TrimPrefix(dataURL, "data:"), ",")
+	mimeType, isBase64 := strings.CutSuffix(header, ";base64")
+	if !ok || !isBase64 {
+		return "", false
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	url, err := s.imageOffloader(ctx, data, mimeType)
+	if err != nil || url == "" {
+		return "", false
+	}
+	return url, true
+}
+
+// dropOldestTurns drops the oldest turns of a conversation, each a user message and the messages
+// answering it, until the messages fit in size. System messages and the turn of the last user
+// message are kept, so the result may still be above size. It returns the messages left and the
+// number dropped.
+func dropOldestTurns(messages []schemas.BifrostMessage, size int) ([]schemas.BifrostMessage, int) {
+	lastUser := -1
+	for i, msg := range messages {
+		if msg.Role == schemas.ModelChatMessageRoleUser {
+			lastUser = i
+		}
+	}
+	if lastUser <= 0 {
+		return messages, 0
+	}
+
+	drop := make([]bool, len(messages))
+	current := messagesSize(messages)
+	start := 0
+	for current > size {
+		// Skip to the first message not dropped or kept as a system message
+		for start < lastUser && (drop[start] || messages[start].Role.IsInstruction()) {
+			start++
+		}
+		if start >= lastUser {
+			break
+		}
+		// The turn runs until the next user message, dropping tool results with their calls
+		end := start + 1
+		for end < lastUser && messages[end].Role != schemas.ModelChatMessageRoleUser {
+			end++
+		}
+		for i := start; i < end; i++ {
+			if !messages[i].Role.IsInstruction() {
+				drop[i] = true
+				current -= messageSize(messages[i])
+			}
+		}
+		start = end
+	}
+
+	kept := make([]schemas.BifrostMessage, 0, len(messages))
+	for i, msg := range messages {
+		if !drop[i] {
+			kept = append(kept, msg)
+		}
+	}
+	return kept, len(messages) - len(kept)
+}
+
+// messagesSize is the size of the content of messages in bytes, the measure slimming reduces.
+func messagesSize(messages []schemas.BifrostMessage) int {
+	size := 0
+	for _, msg := range messages {
+		size += messageSize(msg)
+	}
+	return size
+}
+
+func messageSize(msg schemas.BifrostMessage) int {
+	size := 0
+	if msg.Content.ContentStr != nil {
+		size += len(*msg.Content.ContentStr)
+	}
+	if msg.Content.ContentBlocks != nil {
+		for _, block := range *msg.Content.ContentBlocks {
+			if block.Text != nil {
+				size += len(*block.Text)
+			}
+			if block.ImageURL != nil {
+				size += len(block.ImageURL.URL)
+			}
+			if block.InputAudio != nil {
+				size += len(block.InputAudio.Data)
+			}
+		}
+	}
+	if msg.AssistantMessage != nil && msg.AssistantMessage.ToolCalls != nil {
+		for _, toolCall := range *msg.AssistantMessage.ToolCalls {
+			size += len(toolCall.Function.Arguments)
+		}
+	}
+	return size
+}
+
+// compressWhitespace removes trailing whitespace from lines, collapses runs of blank lines into one
+// and runs of spaces and tabs within a line into a single space. Indentation is kept, as it is
+// meaningful in code. It reports whether the text changed.
+func compressWhitespace(text string) (string, bool) {
+	lines := strings.Split(text, "\n")
+	var builder strings.Builder
+	builder.Grow(len(text))
+	blankLines := 0
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			blankLines++
+			if blankLines > 1 {
+				continue
+			}
+		} else {
+			blankLines = 0
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		builder.WriteString(line[:indent])
+		space := false
+		for _, r := range line[indent:] {
+			if r == ' ' || r == '\t' {
+				space = true
+				continue
+			}
+			if space {
+				builder.WriteByte(' ')
+				space = false
+			}
+			builder.WriteRune(r)
+		}
+		if i < len(lines)-1 {
+			builder.WriteByte('\n')
+		}
+	}
+	compressed := builder.String()
+	return compressed, compressed != text
+}
+
+// plural returns singular for one and plural otherwise.
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
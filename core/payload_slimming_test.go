@@ -0,0 +1,159 @@
+package bifrost
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestIsRequestTooLargeError(t *testing.T) {
+	status := func(code int) *int { return &code }
+	code := func(code string) *string { return &code }
+
+	tests := []struct {
+		name string
+		err  schemas.BifrostError
+		want bool
+	}{
+		{name: "413", err: schemas.BifrostError{StatusCode: status(413)}, want: true},
+		{name: "400 context length message", err: schemas.BifrostError{StatusCode: status(400), Error: schemas.ErrorField{Message: "This model's maximum context length is 8192 tokens"}}, want: true},
+		{name: "400 prompt too long", err: schemas.BifrostError{StatusCode: status(400), Error: schemas.ErrorField{Message: "prompt is too long: 210000 tokens > 200000 maximum"}}, want: true},
+		{name: "400 context length code", err: schemas.BifrostError{StatusCode: status(400), Error: schemas.ErrorField{Message: "invalid request", Code: code("context_length_exceeded")}}, want: true},
+		{name: "400 other error", err: schemas.BifrostError{StatusCode: status(400), Error: schemas.ErrorField{Message: "temperature must be at most 2"}}},
+		{name: "500 with marker", err: schemas.BifrostError{StatusCode: status(500), Error: schemas.ErrorField{Message: "request too large"}}},
+		{name: "no status", err: schemas.BifrostError{Error: schemas.ErrorField{Message: "context length exceeded"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRequestTooLargeError(&tt.err); got != tt.want {
+				t.Errorf("isRequestTooLargeError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropOldestTurns(t *testing.T) {
+	message := func(role schemas.ModelChatMessageRole, text string) schemas.BifrostMessage {
+		return schemas.BifrostMessage{Role: role, Content: schemas.MessageContent{ContentStr: &text}}
+	}
+	system := message(schemas.ModelChatMessageRoleSystem, "sys")
+	user1 := message(schemas.ModelChatMessageRoleUser, "aaaaaaaaaa")
+	assistant1 := message(schemas.ModelChatMessageRoleAssistant, "bbbbbbbbbb")
+	user2 := message(schemas.ModelChatMessageRoleUser, "cccccccccc")
+	tool2 := message(schemas.ModelChatMessageRoleTool, "dddddddddd")
+	user3 := message(schemas.ModelChatMessageRoleUser, "eeeeeeeeee")
+	conversation := []schemas.BifrostMessage{system, user1, assistant1, user2, tool2, user3}
+
+	tests := []struct {
+		name        string
+		messages    []schemas.BifrostMessage
+		size        int
+		want        []schemas.BifrostMessage
+		wantDropped int
+	}{
+		{name: "fits", messages: conversation, size: 100, want: conversation},
+		{name: "drops the oldest turn", messages: conversation, size: 45, want: []schemas.BifrostMessage{system, user2, tool2, user3}, wantDropped: 2},
+		{name: "keeps system and last user turn", messages: conversation, size: 0, want: []schemas.BifrostMessage{system, user3}, wantDropped: 4},
+		{name: "single user turn", messages: []schemas.BifrostMessage{system, user1}, size: 0, want: []schemas.BifrostMessage{system, user1}},
+		{
+			name:        "keeps answers to the last user message",
+			messages:    []schemas.BifrostMessage{user1, assistant1, user2, tool2},
+			size:        0,
+			want:        []schemas.BifrostMessage{user2, tool2},
+			wantDropped: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dropped := dropOldestTurns(tt.messages, tt.size)
+			if dropped != tt.wantDropped {
+				t.Errorf("dropped = %d, want %d", dropped, tt.wantDropped)
+			}
+			if !slices.EqualFunc(got, tt.want, func(a, b schemas.BifrostMessage) bool {
+				return a.Role == b.Role && *a.Content.ContentStr == *b.Content.ContentStr
+			}) {
+				t.Errorf("dropOldestTurns() kept %d messages, want %d", len(got), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestSlimMessagesImages(t *testing.T) {
+	const dataURL = "data:image/png;base64,iVBORw0KGgo="
+	text := "describe   this"
+	messages := []schemas.BifrostMessage{{
+		Role: schemas.ModelChatMessageRoleUser,
+		Content: schemas.MessageContent{ContentBlocks: &[]schemas.ContentBlock{
+			{Type: schemas.ContentBlockTypeText, Text: &text},
+			{Type: schemas.ContentBlockTypeImage, ImageURL: &schemas.ImageURLStruct{URL: dataURL}},
+			{Type: schemas.ContentBlockTypeImage, ImageURL: &schemas.ImageURLStruct{URL: "https://example.com/cat.png"}},
+		}},
+	}}
+
+	tests := []struct {
+		name         string
+		offloader    func(ctx context.Context, data []byte, mimeType string) (string, error)
+		wantImageURL string
+		wantWarning  bool
+	}{
+		{
+			name: "offloaded",
+			offloader: func(ctx context.Context, data []byte, mimeType string) (string, error) {
+				if mimeType != "image/png" || len(data) == 0 {
+					t.Errorf("offloader got %d bytes of %q", len(data), mimeType)
+				}
+				return "https://artifacts.example.com/image.png", nil
+			},
+			wantImageURL: "https://artifacts.example.com/image.png",
+		},
+		{
+			name: "offload failed",
+			offloader: func(ctx context.Context, data []byte, mimeType string) (string, error) {
+				return "", errors.New("store unavailable")
+			},
+			wantWarning: true,
+		},
+		{name: "no offloader", wantWarning: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := &schemas.Warnings{}
+			ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyWarnings, warnings)
+			slimmer := newPayloadSlimmer(&schemas.PayloadSlimmingConfig{TargetPercent: 100, ImageOffloader: tt.offloader})
+
+			slimmed, changes := slimmer.slimMessages(ctx, messages)
+			blocks := *slimmed[0].Content.ContentBlocks
+			if *blocks[0].Text != "describe this" {
+				t.Errorf("text = %q, want whitespace compressed", *blocks[0].Text)
+			}
+			if tt.wantImageURL != "" {
+				if blocks[1].ImageURL == nil || blocks[1].ImageURL.URL != tt.wantImageURL {
+					t.Errorf("image = %+v, want URL %q", blocks[1], tt.wantImageURL)
+				}
+			} else if blocks[1].Text == nil || *blocks[1].Text != slimmedImagePlaceholder {
+				t.Errorf("image = %+v, want the placeholder", blocks[1])
+			}
+			if blocks[2].ImageURL == nil || blocks[2].ImageURL.URL != "https://example.com/cat.png" {
+				t.Errorf("image URL block = %+v, want it kept", blocks[2])
+			}
+			if (*messages[0].Content.ContentBlocks)[1].ImageURL.URL != dataURL {
+				t.Error("slimMessages() changed the original messages")
+			}
+			if len(changes) != 2 {
+				t.Errorf("changes = %q, want the image and whitespace changes", changes)
+			}
+
+			var warned bool
+			for _, warning := range warnings.Since(0) {
+				warned = warned || strings.Contains(warning.Message, slimmedImagePlaceholder)
+			}
+			if warned != tt.wantWarning {
+				t.Errorf("omitted image warning = %v, want %v", warned, tt.wantWarning)
+			}
+		})
+	}
+}
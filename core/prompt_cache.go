@@ -0,0 +1,270 @@
+package bifrost
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// PROMPT PREFIX CACHING
+// ============================================================================
+
+// promptCacheManager keeps the stable prefix of chat requests, their system messages and tool
+// definitions, byte-identical across requests and marks it for provider-side prompt caching.
+// It tracks how often each prefix is served from the provider's cache.
+type promptCacheManager struct {
+	mu                  sync.Mutex
+	minPrefixChars      int
+	maxTrackedPrefixes  int
+	ttl                 time.Duration
+	hoistSystemMessages bool
+	prefixes            map[promptPrefixID]*list.Element // Elements hold a *schemas.PromptPrefixStats
+	order               *list.List                       // Most recently used first
+}
+
+// promptPrefixID identifies the stats of a prefix on one provider and model.
+type promptPrefixID struct {
+	prefixKey string
+	provider  schemas.ModelProvider
+	model     string
+}
+
+// newPromptCacheManager creates a prompt cache manager, nil if config is nil.
+func newPromptCacheManager(config *schemas.PromptCacheConfig) *promptCacheManager {
+	if config == nil {
+		return nil
+	}
+	manager := &promptCacheManager{
+		minPrefixChars:      schemas.DefaultPromptCacheMinPrefixChars,
+		maxTrackedPrefixes:  schemas.DefaultPromptCacheMaxTrackedPrefixes,
+		ttl:                 schemas.DefaultPromptCacheTTL,
+		hoistSystemMessages: config.HoistSystemMessages,
+		prefixes:            make(map[promptPrefixID]*list.Element),
+		order:               list.New(),
+	}
+	if config.MinPrefixChars > 0 {
+		manager.minPrefixChars = config.MinPrefixChars
+	}
+	if config.MaxTrackedPrefixes > 0 {
+		manager.maxTrackedPrefixes = config.MaxTrackedPrefixes
+	}
+	if config.TTL > 0 {
+		manager.ttl = config.TTL
+	}
+	return manager
+}
+
+// getPromptCacheManager returns the manager to use for a request, nil when prompt caching is
+// not configured or the context turns it off.
+func (bifrost *Bifrost) getPromptCacheManager(ctx context.Context) *promptCacheManager {
+	if ctx != nil {
+		if enabled, ok := ctx.Value(schemas.BifrostContextKeyPromptCache).(bool); ok && !enabled {
+			return nil
+		}
+	}
+	return bifrost.promptCache
+}
+
+// prepare structures a chat request for prefix caching: tools are ordered by name, system
+// messages are optionally hoisted to the front, and a PromptCacheHint carrying the prefix key
+// is set on a copy of the parameters. The caller's request is never modified. It returns the
+// prepared request and the prefix key, empty when the request has no prefix worth caching.
+func (m *promptCacheManager) prepare(req *schemas.BifrostRequest, requestType schemas.RequestType) (*schemas.BifrostRequest, string) {
+	if m == nil || (requestType != schemas.ChatCompletionRequest && requestType != schemas.ChatCompletionStreamRequest) || req.Input.ChatCompletionInput == nil {
+		return req, ""
+	}
+
+	prepared := *req
+	messages := *req.Input.ChatCompletionInput
+	if m.hoistSystemMessages {
+		messages = hoistSystemMessages(messages)
+		prepared.Input.ChatCompletionInput = &messages
+	}
+
+	var params schemas.ModelParameters
+	if req.Params != nil {
+		params = *req.Params
+	}
+	if params.Tools != nil && len(*params.Tools) > 1 {
+		tools := slices.Clone(*params.Tools)
+		slices.SortStableFunc(tools, func(a, b schemas.Tool) int {
+			return strings.Compare(promptCacheToolName(a), promptCacheToolName(b))
+		})
+		params.Tools = &tools
+	}
+
+	prefix := promptPrefix(messages, params.Tools)
+	if len(prefix) < m.minPrefixChars {
+		if req.Params != nil {
+			prepared.Params = &params
+		}
+		return &prepared, ""
+	}
+
+	sum := sha256.Sum256([]byte(prefix))
+	prefixKey := hex.EncodeToString(sum[:16])
+	params.PromptCache = &schemas.PromptCacheHint{PrefixKey: prefixKey}
+	prepared.Params = &params
+
+	m.track(promptPrefixID{prefixKey: prefixKey, provider: req.Provider, model: req.Model}, len(prefix))
+	return &prepared, prefixKey
+}
+
+// hoistSystemMessages moves system messages ahead of all other messages, keeping the
+// relative order of both groups.
+func hoistSystemMessages(messages []schemas.BifrostMessage) []schemas.BifrostMessage {
+	hoisted := make([]schemas.BifrostMessage, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role.IsInstruction() {
+			hoisted = append(hoisted, msg)
+		}
+	}
+	for _, msg := range messages {
+		if !msg.Role.IsInstruction() {
+			hoisted = append(hoisted, msg)
+		}
+	}
+	return hoisted
+}
+
+// promptCacheToolName returns the name tools are ordered by.
+func promptCacheToolName(tool schemas.Tool) string {
+	if tool.Function.Name != "" {
+		return tool.Function.Name
+	}
+	return tool.Type
+}
+
+// promptPrefix returns the stable prefix of a chat request: its leading system messages
+// followed by its tool definitions.
+func promptPrefix(messages []schemas.BifrostMessage, tools *[]schemas.Tool) string {
+	var builder strings.Builder
+	for _, msg := range messages {
+		if !msg.Role.IsInstruction() {
+			break
+		}
+		builder.WriteString(msg.Content.Text())
+		builder.WriteByte('\n')
+	}
+	if tools != nil && len(*tools) > 0 {
+		if data, err := sonic.Marshal(*tools); err == nil {
+			builder.Write(data)
+		}
+	}
+	return builder.String()
+}
+
+// track counts a request sent with a prefix, evicting expired and least recently used prefixes.
+func (m *promptCacheManager) track(id promptPrefixID, prefixChars int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for oldest := m.order.Back(); oldest != nil && now.Sub(oldest.Value.(*schemas.PromptPrefixStats).LastUsed) > m.ttl; oldest = m.order.Back() {
+		m.remove(oldest)
+	}
+
+	if element, ok := m.prefixes[id]; ok {
+		stats := element.Value.(*schemas.PromptPrefixStats)
+		stats.Requests++
+		stats.LastUsed = now
+		m.order.MoveToFront(element)
+		return
+	}
+
+	stats := &schemas.PromptPrefixStats{
+		PrefixKey:   id.prefixKey,
+		Provider:    id.provider,
+		Model:       id.model,
+		PrefixChars: prefixChars,
+		Requests:    1,
+		LastUsed:    now,
+	}
+	// OpenAI routes requests sharing a prompt_cache_key to the same cache
+	if id.provider == schemas.OpenAI {
+		stats.CacheID = id.prefixKey
+	}
+	m.prefixes[id] = m.order.PushFront(stats)
+
+	for m.order.Len() > m.maxTrackedPrefixes {
+		m.remove(m.order.Back())
+	}
+}
+
+// remove forgets a tracked prefix. Callers must hold the lock.
+func (m *promptCacheManager) remove(element *list.Element) {
+	stats := m.order.Remove(element).(*schemas.PromptPrefixStats)
+	delete(m.prefixes, promptPrefixID{prefixKey: stats.PrefixKey, provider: stats.Provider, model: stats.Model})
+}
+
+// observe records the cache usage reported by a response to a request prepared with
+// prefixKey and returns the result to attach to the response. reported is the result the
+// provider attached to the response, if any, carrying the identifier of its cache.
+func (m *promptCacheManager) observe(req *schemas.BifrostRequest, prefixKey string, usage *schemas.LLMUsage, reported *schemas.PromptCacheResult) *schemas.PromptCacheResult {
+	if m == nil || prefixKey == "" {
+		return nil
+	}
+
+	result := &schemas.PromptCacheResult{PrefixKey: prefixKey}
+	if usage != nil && usage.TokenDetails != nil {
+		result.CachedTokens = usage.TokenDetails.CachedTokens
+		result.Hit = result.CachedTokens > 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.prefixes[promptPrefixID{prefixKey: prefixKey, provider: req.Provider, model: req.Model}]; ok {
+		stats := element.Value.(*schemas.PromptPrefixStats)
+		if reported != nil && reported.CacheID != "" {
+			stats.CacheID = reported.CacheID
+		}
+		result.CacheID = stats.CacheID
+		if result.Hit {
+			stats.Hits++
+			stats.CachedTokens += int64(result.CachedTokens)
+		}
+	}
+	return result
+}
+
+// observeStream records the cache usage reported by the usage chunk of a stream and attaches
+// the result to that chunk.
+func (m *promptCacheManager) observeStream(req *schemas.BifrostRequest, prefixKey string, stream chan *schemas.BifrostStream) chan *schemas.BifrostStream {
+	outputStream := make(chan *schemas.BifrostStream, cap(stream))
+	go func() {
+		defer close(outputStream)
+		for chunk := range stream {
+			if chunk != nil && chunk.BifrostResponse != nil && chunk.BifrostResponse.Usage != nil {
+				chunk.BifrostResponse.ExtraFields.PromptCache = m.observe(req, prefixKey, chunk.BifrostResponse.Usage, chunk.BifrostResponse.ExtraFields.PromptCache)
+			}
+			outputStream <- chunk
+		}
+	}()
+	return outputStream
+}
+
+// GetPromptCacheStats returns the cache usage of the prompt prefixes tracked by the prompt
+// cache manager, most recently used first. It returns nil if prompt caching is not configured.
+func (bifrost *Bifrost) GetPromptCacheStats() []schemas.PromptPrefixStats {
+	m := bifrost.promptCache
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make([]schemas.PromptPrefixStats, 0, m.order.Len())
+	for element := m.order.Front(); element != nil; element = element.Next() {
+		stats = append(stats, *element.Value.(*schemas.PromptPrefixStats))
+	}
+	return stats
+}
@@ -0,0 +1,98 @@
+package bifrost
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestPromptCachePrefixKey(t *testing.T) {
+	message := func(role schemas.ModelChatMessageRole, text string) schemas.BifrostMessage {
+		return schemas.BifrostMessage{Role: role, Content: schemas.MessageContent{ContentStr: &text}}
+	}
+	tool := func(name string) schemas.Tool {
+		return schemas.Tool{Type: "function", Function: schemas.Function{Name: name}}
+	}
+	request := func(tools []schemas.Tool, messages ...schemas.BifrostMessage) *schemas.BifrostRequest {
+		req := &schemas.BifrostRequest{Provider: schemas.OpenAI, Model: "gpt-4o", Input: schemas.RequestInput{ChatCompletionInput: &messages}}
+		if tools != nil {
+			req.Params = &schemas.ModelParameters{Tools: &tools}
+		}
+		return req
+	}
+	system := message(schemas.ModelChatMessageRoleSystem, "You are a helpful assistant for the billing team.")
+	otherSystem := message(schemas.ModelChatMessageRoleSystem, "You are a helpful assistant for the support team.")
+	question := message(schemas.ModelChatMessageRoleUser, "What is my balance?")
+	otherQuestion := message(schemas.ModelChatMessageRoleUser, "When is my invoice due?")
+
+	manager := newPromptCacheManager(&schemas.PromptCacheConfig{MinPrefixChars: 20})
+	key := func(req *schemas.BifrostRequest) string {
+		_, prefixKey := manager.prepare(req, schemas.ChatCompletionRequest)
+		return prefixKey
+	}
+	base := key(request([]schemas.Tool{tool("a"), tool("b")}, system, question))
+	if base == "" {
+		t.Fatal("prefix key is empty for a request with a long enough prefix")
+	}
+
+	tests := []struct {
+		name     string
+		req      *schemas.BifrostRequest
+		wantSame bool
+	}{
+		{name: "other conversation", req: request([]schemas.Tool{tool("a"), tool("b")}, system, otherQuestion), wantSame: true},
+		{name: "tools in another order", req: request([]schemas.Tool{tool("b"), tool("a")}, system, question), wantSame: true},
+		{name: "other system prompt", req: request([]schemas.Tool{tool("a"), tool("b")}, otherSystem, question)},
+		{name: "other tools", req: request([]schemas.Tool{tool("a"), tool("c")}, system, question)},
+		{name: "no tools", req: request(nil, system, question)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := key(tt.req)
+			if got == "" {
+				t.Fatal("prefix key is empty")
+			}
+			if (got == base) != tt.wantSame {
+				t.Errorf("prefix key %s, base %s, want same = %v", got, base, tt.wantSame)
+			}
+		})
+	}
+
+	t.Run("short prefix", func(t *testing.T) {
+		if got := key(request(nil, message(schemas.ModelChatMessageRoleSystem, "Be brief."), question)); got != "" {
+			t.Errorf("prefix key = %q, want none below MinPrefixChars", got)
+		}
+	})
+	t.Run("other request types", func(t *testing.T) {
+		if _, got := manager.prepare(request(nil, system, question), schemas.EmbeddingRequest); got != "" {
+			t.Errorf("prefix key = %q, want none for embeddings", got)
+		}
+	})
+}
+
+func TestPromptCachePrepareCopiesRequest(t *testing.T) {
+	text := "You are a helpful assistant for the billing team."
+	question := "What is my balance?"
+	messages := []schemas.BifrostMessage{
+		{Role: schemas.ModelChatMessageRoleUser, Content: schemas.MessageContent{ContentStr: &question}},
+		{Role: schemas.ModelChatMessageRoleSystem, Content: schemas.MessageContent{ContentStr: &text}},
+	}
+	tools := []schemas.Tool{{Type: "function", Function: schemas.Function{Name: "b"}}, {Type: "function", Function: schemas.Function{Name: "a"}}}
+	req := &schemas.BifrostRequest{Input: schemas.RequestInput{ChatCompletionInput: &messages}, Params: &schemas.ModelParameters{Tools: &tools}}
+
+	manager := newPromptCacheManager(&schemas.PromptCacheConfig{MinPrefixChars: 20, HoistSystemMessages: true})
+	prepared, prefixKey := manager.prepare(req, schemas.ChatCompletionStreamRequest)
+
+	if prefixKey == "" || prepared.Params.PromptCache == nil || prepared.Params.PromptCache.PrefixKey != prefixKey {
+		t.Fatalf("prepared params carry hint %+v, want prefix key %q", prepared.Params.PromptCache, prefixKey)
+	}
+	if got := (*prepared.Input.ChatCompletionInput)[0].Role; got != schemas.ModelChatMessageRoleSystem {
+		t.Errorf("first prepared message role = %s, want the hoisted system message", got)
+	}
+	if got := (*prepared.Params.Tools)[0].Function.Name; got != "a" {
+		t.Errorf("first prepared tool = %s, want tools ordered by name", got)
+	}
+	if messages[0].Role != schemas.ModelChatMessageRoleUser || tools[0].Function.Name != "b" || req.Params.PromptCache != nil {
+		t.Error("prepare modified the caller's request")
+	}
+}
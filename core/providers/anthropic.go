@@ -3,15 +3,16 @@
 package providers
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/bytedance/sonic"
 	schemas "github.com/maximhq/bifrost/core/schemas"
@@ -46,22 +47,31 @@ type AnthropicChatResponse struct {
 	Type    string `json:"type"` // Type of completion
 	Role    string `json:"role"` // Role of the message sender
 	Content []struct {
-		Type     string                 `json:"type"`               // Type of content
-		Text     string                 `json:"text,omitempty"`     // Text content
-		Thinking string                 `json:"thinking,omitempty"` // Thinking process
-		ID       string                 `json:"id"`                 // Content identifier
-		Name     string                 `json:"name"`               // Name of the content
-		Input    map[string]interface{} `json:"input"`              // Input parameters
+		Type      string                 `json:"type"`                // Type of content
+		Text      string                 `json:"text,omitempty"`      // Text content
+		Thinking  string                 `json:"thinking,omitempty"`  // Thinking process
+		Signature string                 `json:"signature,omitempty"` // Signature of the thinking process
+		ID        string                 `json:"id"`                  // Content identifier
+		Name      string                 `json:"name"`                // Name of the content
+		Input     map[string]interface{} `json:"input"`               // Input parameters
+		// Citations supporting a text block
+		Citations []AnthropicTextCitation `json:"citations,omitempty"`
 	} `json:"content"` // Array of content items
 	Model        string  `json:"model"`                   // Model used for the completion
 	StopReason   string  `json:"stop_reason,omitempty"`   // Reason for completion termination
 	StopSequence *string `json:"stop_sequence,omitempty"` // Sequence that caused completion to stop
 	Usage        struct {
-		InputTokens  int `json:"input_tokens"`  // Number of input tokens used
-		OutputTokens int `json:"output_tokens"` // Number of output tokens generated
+		InputTokens              int    `json:"input_tokens"`                // Number of input tokens used
+		OutputTokens             int    `json:"output_tokens"`               // Number of output tokens generated
+		CacheCreationInputTokens int    `json:"cache_creation_input_tokens"` // Number of input tokens written to the prompt cache
+		CacheReadInputTokens     int    `json:"cache_read_input_tokens"`     // Number of input tokens read from the prompt cache
+		ServiceTier              string `json:"service_tier,omitempty"`      // Tier that served the request: standard, priority or batch
 	} `json:"usage"` // Token usage statistics
 }
 
+// anthropicEphemeralCacheControl marks the end of a prompt prefix for Anthropic's prompt caching.
+var anthropicEphemeralCacheControl = map[string]interface{}{"type": "ephemeral"}
+
 // AnthropicStreamEvent represents a single event in the Anthropic streaming response.
 // It corresponds to the various event types defined in Anthropic's Messages API streaming documentation.
 type AnthropicStreamEvent struct {
@@ -84,23 +94,37 @@ type AnthropicStreamMessage struct {
 	Model        string                  `json:"model"`
 	StopReason   *string                 `json:"stop_reason"`
 	StopSequence *string                 `json:"stop_sequence"`
-	Usage        *schemas.LLMUsage       `json:"usage"`
+	Usage        *AnthropicUsage         `json:"usage"`
 }
 
 // AnthropicContentBlock represents a content block in Anthropic responses.
 // This includes text, tool_use, thinking, and web_search_tool_result blocks.
 type AnthropicContentBlock struct {
-	Type     string                 `json:"type"`
-	Text     string                 `json:"text,omitempty"`
-	ID       string                 `json:"id,omitempty"`
-	Name     string                 `json:"name,omitempty"`
-	Input    map[string]interface{} `json:"input,omitempty"`
-	Thinking string                 `json:"thinking,omitempty"`
+	Type      string                 `json:"type"`
+	Text      string                 `json:"text,omitempty"`
+	ID        string                 `json:"id,omitempty"`
+	Name      string                 `json:"name,omitempty"`
+	Input     map[string]interface{} `json:"input,omitempty"`
+	Thinking  string                 `json:"thinking,omitempty"`
+	Signature string                 `json:"signature,omitempty"` // Signature of a thinking block
 	// Web search tool result specific fields
 	ToolUseID string                 `json:"tool_use_id,omitempty"`
 	Content   []AnthropicToolContent `json:"content,omitempty"`
 }
 
+// AnthropicTextCitation represents a citation attached to a text block.
+// Only the fields relevant to the citation type are set.
+type AnthropicTextCitation struct {
+	Type           string  `json:"type"` // web_search_result_location, char_location, page_location or content_block_location
+	CitedText      string  `json:"cited_text,omitempty"`
+	URL            *string `json:"url,omitempty"`
+	Title          *string `json:"title,omitempty"`
+	DocumentIndex  *int    `json:"document_index,omitempty"`
+	DocumentTitle  *string `json:"document_title,omitempty"`
+	StartCharIndex *int    `json:"start_char_index,omitempty"`
+	EndCharIndex   *int    `json:"end_char_index,omitempty"`
+}
+
 // AnthropicToolContent represents content within tool result blocks
 type AnthropicToolContent struct {
 	Type             string  `json:"type"`
@@ -124,10 +148,11 @@ type AnthropicDelta struct {
 
 // AnthropicUsage represents the usage information for Anthropic's API.
 type AnthropicUsage struct {
-	InputTokens              int `json:"input_tokens"`
-	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
-	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
-	OutputTokens             int `json:"output_tokens"`
+	InputTokens              int    `json:"input_tokens"`
+	CacheCreationInputTokens int    `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int    `json:"cache_read_input_tokens"`
+	OutputTokens             int    `json:"output_tokens"`
+	ServiceTier              string `json:"service_tier,omitempty"` // Tier that served the request: standard, priority or batch
 }
 
 // AnthropicStreamError represents error events in the streaming response.
@@ -253,6 +278,10 @@ func NewAnthropicProvider(config *schemas.ProviderConfig, logger schemas.Logger)
 	// Configure proxy if provided
 	client = configureProxy(client, config.ProxyConfig, logger)
 
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
 		config.NetworkConfig.BaseURL = "https://api.anthropic.com"
@@ -275,6 +304,11 @@ func (provider *AnthropicProvider) GetProviderKey() schemas.ModelProvider {
 	return getProviderName(schemas.Anthropic, provider.customProviderConfig)
 }
 
+// WarmUp opens connections to the Anthropic API ahead of the first request.
+func (provider *AnthropicProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
 // prepareTextCompletionParams prepares text completion parameters for Anthropic's API.
 // It handles parameter mapping and conversion to the format expected by Anthropic.
 // Returns the modified parameters map.
@@ -297,7 +331,7 @@ func (provider *AnthropicProvider) prepareTextCompletionParams(params map[string
 // completeRequest sends a request to Anthropic's API and handles the response.
 // It constructs the API URL, sets up authentication, and processes the response.
 // Returns the response body or an error if the request fails.
-func (provider *AnthropicProvider) completeRequest(ctx context.Context, requestBody map[string]interface{}, url string, key string) ([]byte, *schemas.BifrostError) {
+func (provider *AnthropicProvider) completeRequest(ctx context.Context, requestBody map[string]interface{}, url string, key string, betaHeader string) ([]byte, *schemas.BifrostError) {
 	// Marshal the request body
 	jsonData, err := sonic.Marshal(requestBody)
 	if err != nil {
@@ -318,6 +352,9 @@ func (provider *AnthropicProvider) completeRequest(ctx context.Context, requestB
 	req.Header.SetContentType("application/json")
 	req.Header.Set("x-api-key", key)
 	req.Header.Set("anthropic-version", provider.apiVersion)
+	if betaHeader != "" {
+		req.Header.Set("anthropic-beta", betaHeader)
+	}
 
 	req.SetBody(jsonData)
 
@@ -362,7 +399,7 @@ func (provider *AnthropicProvider) TextCompletion(ctx context.Context, model str
 		"prompt": fmt.Sprintf("\n\nHuman: %s\n\nAssistant:", text),
 	}, preparedParams)
 
-	responseBody, err := provider.completeRequest(ctx, requestBody, provider.networkConfig.BaseURL+"/v1/complete", key.Value)
+	responseBody, err := provider.completeRequest(ctx, requestBody, provider.networkConfig.BaseURL+"/v1/complete", key.Value, "")
 	if err != nil {
 		return nil, err
 	}
@@ -422,8 +459,12 @@ func (provider *AnthropicProvider) ChatCompletion(ctx context.Context, model str
 	if err := checkOperationAllowed(schemas.Anthropic, provider.customProviderConfig, schemas.OperationChatCompletion); err != nil {
 		return nil, err
 	}
+	if err := checkAnthropicTools(params, schemas.Anthropic); err != nil {
+		return nil, err
+	}
 
 	formattedMessages, preparedParams := prepareAnthropicChatRequest(messages, params)
+	setServiceTier(schemas.Anthropic, params, preparedParams)
 
 	// Merge additional parameters
 	requestBody := mergeConfig(map[string]interface{}{
@@ -431,7 +472,7 @@ func (provider *AnthropicProvider) ChatCompletion(ctx context.Context, model str
 		"messages": formattedMessages,
 	}, preparedParams)
 
-	responseBody, err := provider.completeRequest(ctx, requestBody, provider.networkConfig.BaseURL+"/v1/messages", key.Value)
+	responseBody, err := provider.completeRequest(ctx, requestBody, provider.networkConfig.BaseURL+"/v1/messages", key.Value, getAnthropicBetaHeader(params))
 	if err != nil {
 		return nil, err
 	}
@@ -468,6 +509,64 @@ func (provider *AnthropicProvider) ChatCompletion(ctx context.Context, model str
 	return bifrostResponse, nil
 }
 
+// anthropicCountTokensFields are the fields of a Messages API request that count_tokens accepts
+// besides the model and messages; it rejects the sampling parameters.
+var anthropicCountTokensFields = []string{"system", "tools", "tool_choice", "thinking", "mcp_servers"}
+
+// CountTokens counts the input tokens of a chat request with Anthropic's count_tokens endpoint,
+// which includes the system prompt, tools, images and documents of the request.
+func (provider *AnthropicProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	// Counting tokens is part of serving chat requests
+	if err := checkOperationAllowed(schemas.Anthropic, provider.customProviderConfig, schemas.OperationChatCompletion); err != nil {
+		return nil, err
+	}
+
+	formattedMessages, preparedParams := prepareAnthropicChatRequest(messages, params)
+	requestBody := map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+	}
+	for _, field := range anthropicCountTokensFields {
+		if value, ok := preparedParams[field]; ok {
+			requestBody[field] = value
+		}
+	}
+
+	responseBody, err := provider.completeRequest(ctx, requestBody, provider.networkConfig.BaseURL+"/v1/messages/count_tokens", key.Value, getAnthropicBetaHeader(params))
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		InputTokens int `json:"input_tokens"`
+	}
+	rawResponse, bifrostErr := handleProviderResponse(responseBody, &response, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		Object: "token_count",
+		Model:  model,
+		TokenCount: &schemas.BifrostTokenCount{
+			InputTokens: response.InputTokens,
+			Exact:       true,
+		},
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: provider.GetProviderKey(),
+		},
+	}
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	return bifrostResponse, nil
+}
+
+func (provider *AnthropicProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "anthropic")
+}
+
 // buildAnthropicImageSourceMap creates the "source" map for an Anthropic image content part.
 func buildAnthropicImageSourceMap(imgContent *schemas.ImageURLStruct) map[string]interface{} {
 	if imgContent == nil {
@@ -510,7 +609,7 @@ func prepareAnthropicChatRequest(messages []schemas.BifrostMessage, params *sche
 	// Add system messages if present
 	var systemMessages []BedrockAnthropicSystemMessage
 	for _, msg := range messages {
-		if msg.Role == schemas.ModelChatMessageRoleSystem {
+		if msg.Role.IsInstruction() {
 			if msg.Content.ContentStr != nil {
 				systemMessages = append(systemMessages, BedrockAnthropicSystemMessage{
 					Text: *msg.Content.ContentStr,
@@ -532,7 +631,7 @@ func prepareAnthropicChatRequest(messages []schemas.BifrostMessage, params *sche
 	for _, msg := range messages {
 		var content []interface{}
 
-		if msg.Role != schemas.ModelChatMessageRoleSystem {
+		if !msg.Role.IsInstruction() {
 			if msg.Role == schemas.ModelChatMessageRoleTool && msg.ToolMessage != nil && msg.ToolMessage.ToolCallID != nil {
 				toolCallResult := map[string]interface{}{
 					"type":        "tool_result",
@@ -554,6 +653,16 @@ func prepareAnthropicChatRequest(messages []schemas.BifrostMessage, params *sche
 								"text": *block.Text,
 							})
 						}
+						// Image results, e.g. computer use screenshots
+						if block.ImageURL != nil {
+							imageSource := buildAnthropicImageSourceMap(block.ImageURL)
+							if imageSource != nil {
+								toolCallResultContent = append(toolCallResultContent, map[string]interface{}{
+									"type":   "image",
+									"source": imageSource,
+								})
+							}
+						}
 					}
 				}
 
@@ -586,12 +695,14 @@ func prepareAnthropicChatRequest(messages []schemas.BifrostMessage, params *sche
 					}
 				}
 
-				// Add thinking content if present in AssistantMessage
-				if msg.AssistantMessage != nil && msg.AssistantMessage.Thought != nil {
-					content = append(content, map[string]interface{}{
-						"type":     "thinking",
-						"thinking": *msg.AssistantMessage.Thought,
-					})
+				// Add thinking content if present in AssistantMessage. Anthropic only accepts thinking
+				// blocks with the signature it returned them with, first in the message
+				if msg.AssistantMessage != nil && msg.AssistantMessage.Thought != nil && msg.AssistantMessage.ThoughtSignature != nil {
+					content = append([]interface{}{map[string]interface{}{
+						"type":      "thinking",
+						"thinking":  *msg.AssistantMessage.Thought,
+						"signature": *msg.AssistantMessage.ThoughtSignature,
+					}}, content...)
 				}
 
 				// Add tool calls as content if present
@@ -642,6 +753,14 @@ func prepareAnthropicChatRequest(messages []schemas.BifrostMessage, params *sche
 	if params != nil && params.Tools != nil && len(*params.Tools) > 0 {
 		var tools []map[string]interface{}
 		for _, tool := range *params.Tools {
+			if tool.ComputerUse != nil {
+				tools = append(tools, anthropicComputerUseTool(tool))
+				continue
+			}
+			if tool.WebSearch != nil {
+				tools = append(tools, anthropicWebSearchTool(tool))
+				continue
+			}
 			tools = append(tools, map[string]interface{}{
 				"name":         tool.Function.Name,
 				"description":  tool.Function.Description,
@@ -649,6 +768,11 @@ func prepareAnthropicChatRequest(messages []schemas.BifrostMessage, params *sche
 			})
 		}
 
+		// Cache the tool definitions as part of the stable prompt prefix
+		if params.PromptCache != nil {
+			tools[len(tools)-1]["cache_control"] = anthropicEphemeralCacheControl
+		}
+
 		preparedParams["tools"] = tools
 	}
 
@@ -681,7 +805,18 @@ func prepareAnthropicChatRequest(messages []schemas.BifrostMessage, params *sche
 			messages = append(messages, message.Text)
 		}
 
-		preparedParams["system"] = strings.Join(messages, " ")
+		if params != nil && params.PromptCache != nil {
+			// Cache the system prompt as part of the stable prompt prefix
+			preparedParams["system"] = []map[string]interface{}{
+				{
+					"type":          "text",
+					"text":          strings.Join(messages, " "),
+					"cache_control": anthropicEphemeralCacheControl,
+				},
+			}
+		} else {
+			preparedParams["system"] = strings.Join(messages, " ")
+		}
 	}
 
 	// Post-process formattedMessages for tool call results
@@ -751,19 +886,29 @@ func prepareAnthropicChatRequest(messages []schemas.BifrostMessage, params *sche
 func parseAnthropicResponse(response *AnthropicChatResponse, bifrostResponse *schemas.BifrostResponse) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	// Collect all content and tool calls into a single message
 	var toolCalls []schemas.ToolCall
-	var thinking string
+	var thinking, thinkingSignature string
 
 	var contentBlocks []schemas.ContentBlock
+	var citations []schemas.MessageCitation
+	// Character offset of the current text block within the concatenated message text
+	textOffset := 0
 	// Process content and tool calls
 	for _, c := range response.Content {
 		switch c.Type {
 		case "thinking":
 			thinking = c.Thinking
+			thinkingSignature = c.Signature
 		case "text":
 			contentBlocks = append(contentBlocks, schemas.ContentBlock{
 				Type: "text",
 				Text: &c.Text,
 			})
+			// Anthropic cites whole text blocks, so the cited span is the block itself
+			textLength := utf8.RuneCountInString(c.Text)
+			for _, citation := range c.Citations {
+				citations = append(citations, convertAnthropicCitation(citation, textOffset, textOffset+textLength))
+			}
+			textOffset += textLength
 		case "tool_use":
 			function := schemas.FunctionCall{
 				Name: &c.Name,
@@ -776,25 +921,36 @@ func parseAnthropicResponse(response *AnthropicChatResponse, bifrostResponse *sc
 				function.Arguments = string(args)
 			}
 
-			toolCalls = append(toolCalls, schemas.ToolCall{
+			toolCall := schemas.ToolCall{
 				Type:     Ptr("function"),
 				ID:       &c.ID,
 				Function: function,
-			})
+			}
+			if c.Name == anthropicComputerUseToolName {
+				toolCall.ComputerAction = parseAnthropicComputerAction(c.Input)
+			}
+
+			toolCalls = append(toolCalls, toolCall)
 		}
 	}
 
 	// Create the assistant message
 	var assistantMessage *schemas.AssistantMessage
 
-	// Create AssistantMessage if we have tool calls or thinking
-	if len(toolCalls) > 0 || thinking != "" {
+	// Create AssistantMessage if we have tool calls, thinking or citations
+	if len(toolCalls) > 0 || thinking != "" || len(citations) > 0 {
 		assistantMessage = &schemas.AssistantMessage{}
 		if len(toolCalls) > 0 {
 			assistantMessage.ToolCalls = &toolCalls
 		}
 		if thinking != "" {
 			assistantMessage.Thought = &thinking
+			if thinkingSignature != "" {
+				assistantMessage.ThoughtSignature = &thinkingSignature
+			}
+		}
+		if len(citations) > 0 {
+			assistantMessage.Citations = citations
 		}
 	}
 
@@ -827,11 +983,42 @@ func parseAnthropicResponse(response *AnthropicChatResponse, bifrostResponse *sc
 		CompletionTokens: response.Usage.OutputTokens,
 		TotalTokens:      response.Usage.InputTokens + response.Usage.OutputTokens,
 	}
+	if response.Usage.CacheReadInputTokens > 0 {
+		bifrostResponse.Usage.TokenDetails = &schemas.TokenDetails{
+			CachedTokens: response.Usage.CacheReadInputTokens,
+		}
+	}
+	if response.Usage.ServiceTier != "" {
+		bifrostResponse.ServiceTier = &response.Usage.ServiceTier
+	}
 	bifrostResponse.Model = response.Model
 
 	return bifrostResponse, nil
 }
 
+// convertAnthropicCitation converts an Anthropic text block citation into a normalized citation
+// covering the [start, end) character span of the cited text block.
+func convertAnthropicCitation(citation AnthropicTextCitation, start, end int) schemas.MessageCitation {
+	messageCitation := schemas.MessageCitation{
+		Type:       schemas.MessageCitationTypeDocument,
+		StartIndex: Ptr(start),
+		EndIndex:   Ptr(end),
+		Title:      citation.DocumentTitle,
+	}
+	if citation.CitedText != "" {
+		messageCitation.CitedText = Ptr(citation.CitedText)
+	}
+	if citation.Type == "web_search_result_location" {
+		messageCitation.Type = schemas.MessageCitationTypeURL
+		messageCitation.URL = citation.URL
+		messageCitation.Title = citation.Title
+	}
+	if citation.DocumentIndex != nil {
+		messageCitation.SourceID = Ptr(strconv.Itoa(*citation.DocumentIndex))
+	}
+	return messageCitation
+}
+
 // Embedding is not supported by the Anthropic provider.
 func (provider *AnthropicProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("embedding", "anthropic")
@@ -844,8 +1031,12 @@ func (provider *AnthropicProvider) ChatCompletionStream(ctx context.Context, pos
 	if err := checkOperationAllowed(schemas.Anthropic, provider.customProviderConfig, schemas.OperationChatCompletionStream); err != nil {
 		return nil, err
 	}
+	if err := checkAnthropicTools(params, schemas.Anthropic); err != nil {
+		return nil, err
+	}
 
 	formattedMessages, preparedParams := prepareAnthropicChatRequest(messages, params)
+	setServiceTier(schemas.Anthropic, params, preparedParams)
 
 	// Merge additional parameters and set stream to true
 	requestBody := mergeConfig(map[string]interface{}{
@@ -862,6 +1053,9 @@ func (provider *AnthropicProvider) ChatCompletionStream(ctx context.Context, pos
 		"Accept":            "text/event-stream",
 		"Cache-Control":     "no-cache",
 	}
+	if betaHeader := getAnthropicBetaHeader(params); betaHeader != "" {
+		headers["anthropic-beta"] = betaHeader
+	}
 
 	// Use shared Anthropic streaming logic
 	return handleAnthropicStreaming(
@@ -898,6 +1092,10 @@ func handleAnthropicStreaming(
 		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerType)
 	}
 
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
 	// Create HTTP request for streaming
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
 	if err != nil {
@@ -931,9 +1129,10 @@ func handleAnthropicStreaming(
 	// Start streaming in a goroutine
 	go func() {
 		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerType, responseChan, logger)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
+		scanner := newStreamScanner(resp.Body)
 		chunkIndex := -1
 
 		// Track minimal state needed for response format
@@ -941,10 +1140,14 @@ func handleAnthropicStreaming(
 		var modelName string
 		var usage *schemas.LLMUsage
 		var finishReason *string
+		var serviceTier *string
+
+		// Track computer use tool blocks so their actions can be emitted once complete
+		computerToolBlocks := make(map[int]*strings.Builder)
+		computerToolIDs := make(map[int]string)
 
 		// Track SSE event parsing state
 		var eventType string
-		var eventData string
 
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -958,101 +1161,70 @@ func handleAnthropicStreaming(
 			if strings.HasPrefix(line, "event: ") {
 				eventType = strings.TrimPrefix(line, "event: ")
 				continue
-			} else if strings.HasPrefix(line, "data: ") {
-				eventData = strings.TrimPrefix(line, "data: ")
-			} else {
-				continue
-			}
-
-			// Skip if we don't have both event type and data
-			if eventType == "" || eventData == "" {
+			} else if !strings.HasPrefix(line, "data:") {
 				continue
 			}
 
-			var event AnthropicStreamEvent
-			if err := sonic.Unmarshal([]byte(eventData), &event); err != nil {
-				logger.Warn(fmt.Sprintf("Failed to parse message_start event: %v", err))
-				continue
-			}
-
-			if event.Usage != nil {
-				usage = &schemas.LLMUsage{
-					PromptTokens:     event.Usage.InputTokens,
-					CompletionTokens: event.Usage.OutputTokens,
-					TotalTokens:      event.Usage.InputTokens + event.Usage.OutputTokens,
+			// A data line can pack several events, whose types are then only in their data
+			objects, _ := splitStreamDataObjects(line)
+			for _, eventData := range objects {
+				var event AnthropicStreamEvent
+				if err := sonic.Unmarshal([]byte(eventData), &event); err != nil {
+					logger.Warn(fmt.Sprintf("Failed to parse message_start event: %v", err))
+					continue
+				}
+				if event.Type != "" {
+					eventType = event.Type
 				}
-			}
-			if event.Delta != nil && event.Delta.StopReason != nil {
-				mappedReason := MapAnthropicFinishReason(*event.Delta.StopReason)
-				finishReason = &mappedReason
-			}
 
-			// Handle different event types
-			switch eventType {
-			case "message_start":
-				if event.Message != nil {
-					messageID = event.Message.ID
-					modelName = event.Message.Model
+				// Skip events without a type
+				if eventType == "" {
+					continue
+				}
 
-					// Send first chunk with role
-					if event.Message.Role != "" {
-						chunkIndex++
-						role := event.Message.Role
-
-						// Create streaming response for message start with role
-						streamResponse := &schemas.BifrostResponse{
-							ID:     messageID,
-							Object: "chat.completion.chunk",
-							Model:  modelName,
-							Choices: []schemas.BifrostResponseChoice{
-								{
-									Index: 0,
-									BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
-										Delta: schemas.BifrostStreamDelta{
-											Role: &role,
-										},
-									},
-								},
-							},
-							ExtraFields: schemas.BifrostResponseExtraFields{
-								Provider:   providerType,
-								ChunkIndex: chunkIndex,
-							},
+				if event.Usage != nil {
+					usage = &schemas.LLMUsage{
+						PromptTokens:     event.Usage.InputTokens,
+						CompletionTokens: event.Usage.OutputTokens,
+						TotalTokens:      event.Usage.InputTokens + event.Usage.OutputTokens,
+					}
+					if event.Usage.CacheReadInputTokens > 0 {
+						usage.TokenDetails = &schemas.TokenDetails{
+							CachedTokens: event.Usage.CacheReadInputTokens,
 						}
-
-						// Use utility function to process and send response
-						processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
 					}
 				}
+				if event.Delta != nil && event.Delta.StopReason != nil {
+					mappedReason := MapAnthropicFinishReason(*event.Delta.StopReason)
+					finishReason = &mappedReason
+				}
 
-			case "content_block_start":
-				if event.Index != nil && event.ContentBlock != nil {
-					chunkIndex++
+				// Handle different event types
+				switch eventType {
+				case "message_start":
+					if event.Message != nil {
+						messageID = event.Message.ID
+						modelName = event.Message.Model
+						if event.Message.Usage != nil && event.Message.Usage.ServiceTier != "" {
+							serviceTier = &event.Message.Usage.ServiceTier
+						}
+
+						// Send first chunk with role
+						if event.Message.Role != "" {
+							chunkIndex++
+							role := event.Message.Role
 
-					// Handle different content block types
-					switch event.ContentBlock.Type {
-					case "tool_use":
-						// Tool use content block initialization
-						if event.ContentBlock.Name != "" && event.ContentBlock.ID != "" {
-							// Create streaming response for tool start
+							// Create streaming response for message start with role
 							streamResponse := &schemas.BifrostResponse{
 								ID:     messageID,
 								Object: "chat.completion.chunk",
 								Model:  modelName,
 								Choices: []schemas.BifrostResponseChoice{
 									{
-										Index: *event.Index,
+										Index: 0,
 										BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
 											Delta: schemas.BifrostStreamDelta{
-												ToolCalls: []schemas.ToolCall{
-													{
-														Type: func() *string { s := "function"; return &s }(),
-														ID:   &event.ContentBlock.ID,
-														Function: schemas.FunctionCall{
-															Name: &event.ContentBlock.Name,
-														},
-													},
-												},
+												Role: &role,
 											},
 										},
 									},
@@ -1066,52 +1238,64 @@ func handleAnthropicStreaming(
 							// Use utility function to process and send response
 							processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
 						}
-					default:
-						thought := ""
-						if event.ContentBlock.Thinking != "" {
-							thought = event.ContentBlock.Thinking
-						}
-						content := ""
-						if event.ContentBlock.Text != "" {
-							content = event.ContentBlock.Text
-						}
+					}
+
+				case "content_block_start":
+					if event.Index != nil && event.ContentBlock != nil {
+						chunkIndex++
 
-						// Send empty message for other content block types
-						streamResponse := &schemas.BifrostResponse{
-							ID:     messageID,
-							Object: "chat.completion.chunk",
-							Model:  modelName,
-							Choices: []schemas.BifrostResponseChoice{
-								{
-									Index: *event.Index,
-									BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
-										Delta: schemas.BifrostStreamDelta{
-											Thought: &thought,
-											Content: &content,
+						// Handle different content block types
+						switch event.ContentBlock.Type {
+						case "tool_use":
+							if event.ContentBlock.Name == anthropicComputerUseToolName {
+								computerToolBlocks[*event.Index] = &strings.Builder{}
+								computerToolIDs[*event.Index] = event.ContentBlock.ID
+							}
+							// Tool use content block initialization
+							if event.ContentBlock.Name != "" && event.ContentBlock.ID != "" {
+								// Create streaming response for tool start
+								streamResponse := &schemas.BifrostResponse{
+									ID:     messageID,
+									Object: "chat.completion.chunk",
+									Model:  modelName,
+									Choices: []schemas.BifrostResponseChoice{
+										{
+											Index: *event.Index,
+											BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+												Delta: schemas.BifrostStreamDelta{
+													ToolCalls: []schemas.ToolCall{
+														{
+															Type: func() *string { s := "function"; return &s }(),
+															ID:   &event.ContentBlock.ID,
+															Function: schemas.FunctionCall{
+																Name: &event.ContentBlock.Name,
+															},
+														},
+													},
+												},
+											},
 										},
 									},
-								},
-							},
-							ExtraFields: schemas.BifrostResponseExtraFields{
-								Provider:   providerType,
-								ChunkIndex: chunkIndex,
-							},
-						}
-
-						// Use utility function to process and send response
-						processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
-					}
-				}
+									ExtraFields: schemas.BifrostResponseExtraFields{
+										Provider:   providerType,
+										ChunkIndex: chunkIndex,
+									},
+								}
 
-			case "content_block_delta":
-				if event.Index != nil && event.Delta != nil {
-					chunkIndex++
+								// Use utility function to process and send response
+								processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
+							}
+						default:
+							thought := ""
+							if event.ContentBlock.Thinking != "" {
+								thought = event.ContentBlock.Thinking
+							}
+							content := ""
+							if event.ContentBlock.Text != "" {
+								content = event.ContentBlock.Text
+							}
 
-					// Handle different delta types
-					switch event.Delta.Type {
-					case "text_delta":
-						if event.Delta.Text != "" {
-							// Create streaming response for this delta
+							// Send empty message for other content block types
 							streamResponse := &schemas.BifrostResponse{
 								ID:     messageID,
 								Object: "chat.completion.chunk",
@@ -1121,7 +1305,8 @@ func handleAnthropicStreaming(
 										Index: *event.Index,
 										BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
 											Delta: schemas.BifrostStreamDelta{
-												Content: &event.Delta.Text,
+												Thought: &thought,
+												Content: &content,
 											},
 										},
 									},
@@ -1135,117 +1320,215 @@ func handleAnthropicStreaming(
 							// Use utility function to process and send response
 							processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
 						}
+					}
 
-					case "input_json_delta":
-						// Handle tool use streaming - accumulate partial JSON
-						if event.Delta.PartialJSON != "" {
-							// Create streaming response for tool input delta
-							streamResponse := &schemas.BifrostResponse{
-								ID:     messageID,
-								Object: "chat.completion.chunk",
-								Model:  modelName,
-								Choices: []schemas.BifrostResponseChoice{
-									{
-										Index: *event.Index,
-										BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
-											Delta: schemas.BifrostStreamDelta{
-												ToolCalls: []schemas.ToolCall{
-													{
-														Type: func() *string { s := "function"; return &s }(),
-														Function: schemas.FunctionCall{
-															Arguments: event.Delta.PartialJSON,
+				case "content_block_delta":
+					if event.Index != nil && event.Delta != nil {
+						chunkIndex++
+
+						// Handle different delta types
+						switch event.Delta.Type {
+						case "text_delta":
+							if event.Delta.Text != "" {
+								// Create streaming response for this delta
+								streamResponse := &schemas.BifrostResponse{
+									ID:     messageID,
+									Object: "chat.completion.chunk",
+									Model:  modelName,
+									Choices: []schemas.BifrostResponseChoice{
+										{
+											Index: *event.Index,
+											BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+												Delta: schemas.BifrostStreamDelta{
+													Content: &event.Delta.Text,
+												},
+											},
+										},
+									},
+									ExtraFields: schemas.BifrostResponseExtraFields{
+										Provider:   providerType,
+										ChunkIndex: chunkIndex,
+									},
+								}
+
+								// Use utility function to process and send response
+								processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
+							}
+
+						case "input_json_delta":
+							if builder, ok := computerToolBlocks[*event.Index]; ok {
+								builder.WriteString(event.Delta.PartialJSON)
+							}
+							// Handle tool use streaming - accumulate partial JSON
+							if event.Delta.PartialJSON != "" {
+								// Create streaming response for tool input delta
+								streamResponse := &schemas.BifrostResponse{
+									ID:     messageID,
+									Object: "chat.completion.chunk",
+									Model:  modelName,
+									Choices: []schemas.BifrostResponseChoice{
+										{
+											Index: *event.Index,
+											BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+												Delta: schemas.BifrostStreamDelta{
+													ToolCalls: []schemas.ToolCall{
+														{
+															Type: func() *string { s := "function"; return &s }(),
+															Function: schemas.FunctionCall{
+																Arguments: event.Delta.PartialJSON,
+															},
 														},
 													},
 												},
 											},
 										},
 									},
-								},
-								ExtraFields: schemas.BifrostResponseExtraFields{
-									Provider:   providerType,
-									ChunkIndex: chunkIndex,
-								},
-							}
+									ExtraFields: schemas.BifrostResponseExtraFields{
+										Provider:   providerType,
+										ChunkIndex: chunkIndex,
+									},
+								}
 
-							// Use utility function to process and send response
-							processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
-						}
+								// Use utility function to process and send response
+								processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
+							}
 
-					case "thinking_delta":
-						// Handle thinking content streaming
-						if event.Delta.Thinking != "" {
-							// Create streaming response for thinking delta
-							streamResponse := &schemas.BifrostResponse{
-								ID:     messageID,
-								Object: "chat.completion.chunk",
-								Model:  modelName,
-								Choices: []schemas.BifrostResponseChoice{
-									{
-										Index: *event.Index,
-										BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
-											Delta: schemas.BifrostStreamDelta{
-												Thought: &event.Delta.Thinking,
+						case "thinking_delta":
+							// Handle thinking content streaming
+							if event.Delta.Thinking != "" {
+								// Create streaming response for thinking delta
+								streamResponse := &schemas.BifrostResponse{
+									ID:     messageID,
+									Object: "chat.completion.chunk",
+									Model:  modelName,
+									Choices: []schemas.BifrostResponseChoice{
+										{
+											Index: *event.Index,
+											BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+												Delta: schemas.BifrostStreamDelta{
+													Thought: &event.Delta.Thinking,
+												},
 											},
 										},
 									},
-								},
-								ExtraFields: schemas.BifrostResponseExtraFields{
-									Provider:   providerType,
-									ChunkIndex: chunkIndex,
-								},
-							}
+									ExtraFields: schemas.BifrostResponseExtraFields{
+										Provider:   providerType,
+										ChunkIndex: chunkIndex,
+									},
+								}
 
-							// Use utility function to process and send response
-							processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
-						}
+								// Use utility function to process and send response
+								processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
+							}
 
-					case "signature_delta":
-						// Handle signature verification for thinking content
-						// This is used to verify the integrity of thinking content
+						case "signature_delta":
+							// The signature of a thinking block, sent whole before the block stops. Clients
+							// need it to send the thinking back in later turns
+							if event.Delta.Signature != "" {
+								streamResponse := &schemas.BifrostResponse{
+									ID:     messageID,
+									Object: "chat.completion.chunk",
+									Model:  modelName,
+									Choices: []schemas.BifrostResponseChoice{
+										{
+											Index: *event.Index,
+											BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+												Delta: schemas.BifrostStreamDelta{
+													ThoughtSignature: &event.Delta.Signature,
+												},
+											},
+										},
+									},
+									ExtraFields: schemas.BifrostResponseExtraFields{
+										Provider:   providerType,
+										ChunkIndex: chunkIndex,
+									},
+								}
 
+								processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
+							}
+						}
 					}
-				}
-
-			case "content_block_stop":
-				// Content block is complete, no specific action needed for streaming
-				continue
 
-			case "message_delta":
-				continue
-
-			case "message_stop":
-				continue
+				case "content_block_stop":
+					// Emit the parsed action once a computer use tool block is complete
+					if event.Index != nil {
+						if builder, ok := computerToolBlocks[*event.Index]; ok {
+							toolID := computerToolIDs[*event.Index]
+							delete(computerToolBlocks, *event.Index)
+							delete(computerToolIDs, *event.Index)
+
+							if action := parseAnthropicComputerActionArguments(builder.String()); action != nil {
+								chunkIndex++
+								streamResponse := &schemas.BifrostResponse{
+									ID:     messageID,
+									Object: "chat.completion.chunk",
+									Model:  modelName,
+									Choices: []schemas.BifrostResponseChoice{
+										{
+											Index: *event.Index,
+											BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+												Delta: schemas.BifrostStreamDelta{
+													ToolCalls: []schemas.ToolCall{
+														{
+															Type:           Ptr("function"),
+															ID:             &toolID,
+															ComputerAction: action,
+														},
+													},
+												},
+											},
+										},
+									},
+									ExtraFields: schemas.BifrostResponseExtraFields{
+										Provider:   providerType,
+										ChunkIndex: chunkIndex,
+									},
+								}
 
-			case "ping":
-				// Ping events are just keepalive, no action needed
-				continue
+								processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, logger)
+							}
+						}
+					}
+					continue
+
+				case "message_delta":
+					continue
+
+				case "message_stop":
+					continue
+
+				case "ping":
+					// Ping events are just keepalive, no action needed
+					continue
+
+				case "error":
+					if event.Error != nil {
+						// Send error through channel before closing
+						bifrostErr := &schemas.BifrostError{
+							IsBifrostError: false,
+							Origin:         schemas.ErrorOriginProvider,
+							Error: schemas.ErrorField{
+								Type:    &event.Error.Type,
+								Message: event.Error.Message,
+							},
+						}
 
-			case "error":
-				if event.Error != nil {
-					// Send error through channel before closing
-					bifrostErr := &schemas.BifrostError{
-						IsBifrostError: false,
-						Error: schemas.ErrorField{
-							Type:    &event.Error.Type,
-							Message: event.Error.Message,
-						},
+						ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+						processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, logger)
 					}
+					return
 
-					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
-					processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, logger)
+				default:
+					// Unknown event type - handle gracefully as per Anthropic's versioning policy
+					// New event types may be added, so we should not error but log and continue
+					logger.Debug(fmt.Sprintf("Unknown %s stream event type: %s, data: %s", providerType, eventType, eventData))
+					continue
 				}
-				return
-
-			default:
-				// Unknown event type - handle gracefully as per Anthropic's versioning policy
-				// New event types may be added, so we should not error but log and continue
-				logger.Debug(fmt.Sprintf("Unknown %s stream event type: %s, data: %s", providerType, eventType, eventData))
-				continue
 			}
 
 			// Reset for next event
 			eventType = ""
-			eventData = ""
 		}
 
 		if err := scanner.Err(); err != nil {
@@ -1253,6 +1536,7 @@ func handleAnthropicStreaming(
 			processAndSendError(ctx, postHookRunner, err, responseChan, logger)
 		} else {
 			response := createBifrostChatCompletionChunkResponse(messageID, usage, finishReason, chunkIndex, params, providerType)
+			response.ServiceTier = serviceTier
 			handleStreamEndWithSuccess(ctx, response, postHookRunner, responseChan, logger)
 		}
 	}()
@@ -1275,3 +1559,19 @@ func (provider *AnthropicProvider) Transcription(ctx context.Context, model stri
 func (provider *AnthropicProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("transcription stream", "anthropic")
 }
+
+func (provider *AnthropicProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "anthropic")
+}
+
+func (provider *AnthropicProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "anthropic")
+}
+
+func (provider *AnthropicProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "anthropic")
+}
+
+func (provider *AnthropicProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "anthropic")
+}
@@ -0,0 +1,53 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains helpers shared by the providers whose operations are asynchronous: a
+// request creates an operation, such as a task or a prediction, that is polled until it ends.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// asyncPoll configures how an asynchronous operation is polled.
+type asyncPoll struct {
+	interval    time.Duration // Delay before the first poll
+	maxInterval time.Duration // Delays double after every poll up to maxInterval, constant if 0
+	timeout     time.Duration // Bounds the time waited for the operation to end
+}
+
+// pollOperation calls poll until it reports the operation ended, poll fails, or the timeout or
+// the context ends. operation names the operation in the error returned when it did not end.
+func pollOperation[T any](
+	ctx context.Context,
+	config asyncPoll,
+	operation string,
+	providerName schemas.ModelProvider,
+	poll func(ctx context.Context) (T, bool, *schemas.BifrostError),
+) (T, *schemas.BifrostError) {
+	var zero T
+
+	ctx, cancel := context.WithTimeout(ctx, config.timeout)
+	defer cancel()
+
+	interval := config.interval
+	for {
+		if !waitOrDone(ctx, interval) {
+			return zero, newBifrostOperationError(fmt.Sprintf("%s did not finish", operation), ctx.Err(), providerName)
+		}
+
+		result, done, bifrostErr := poll(ctx)
+		if bifrostErr != nil {
+			return zero, bifrostErr
+		}
+		if done {
+			return result, nil
+		}
+
+		if config.maxInterval > 0 {
+			interval = min(interval*2, config.maxInterval)
+		}
+	}
+}
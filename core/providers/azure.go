@@ -6,6 +6,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -43,6 +45,9 @@ type AzureError struct {
 // AzureAuthorizationTokenKey is the context key for the Azure authentication token.
 const AzureAuthorizationTokenKey ContextKey = "azure-authorization-token"
 
+// AzureDefaultAPIVersion is the API version of requests whose key does not set one.
+const AzureDefaultAPIVersion = "2024-08-01-preview"
+
 // azureTextCompletionResponsePool provides a pool for Azure text completion response objects.
 var azureTextCompletionResponsePool = sync.Pool{
 	New: func() interface{} {
@@ -121,6 +126,10 @@ func NewAzureProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*A
 	// Configure proxy if provided
 	client = configureProxy(client, config.ProxyConfig, logger)
 
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
 	return &AzureProvider{
 		logger:              logger,
 		client:              client,
@@ -135,6 +144,14 @@ func (provider *AzureProvider) GetProviderKey() schemas.ModelProvider {
 	return schemas.Azure
 }
 
+// WarmUp opens connections to the Azure endpoint of a key ahead of the first request.
+func (provider *AzureProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	if key.AzureKeyConfig == nil || key.AzureKeyConfig.Endpoint == "" {
+		return fmt.Errorf("endpoint not set")
+	}
+	return warmUpConnections(ctx, provider.client, provider.streamClient, strings.TrimRight(key.AzureKeyConfig.Endpoint, "/"))
+}
+
 // completeRequest sends a request to Azure's API and handles the response.
 // It constructs the API URL, sets up authentication, and processes the response.
 // Returns the response body or an error if the request fails.
@@ -149,26 +166,9 @@ func (provider *AzureProvider) completeRequest(ctx context.Context, requestBody
 		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Azure)
 	}
 
-	if key.AzureKeyConfig.Endpoint == "" {
-		return nil, newConfigurationError("endpoint not set", schemas.Azure)
-	}
-
-	url := key.AzureKeyConfig.Endpoint
-
-	if key.AzureKeyConfig.Deployments != nil {
-		deployment := key.AzureKeyConfig.Deployments[model]
-		if deployment == "" {
-			return nil, newConfigurationError(fmt.Sprintf("deployment not found for model %s", model), schemas.Azure)
-		}
-
-		apiVersion := key.AzureKeyConfig.APIVersion
-		if apiVersion == nil {
-			apiVersion = Ptr("2024-02-01")
-		}
-
-		url = fmt.Sprintf("%s/openai/deployments/%s/%s?api-version=%s", url, deployment, path, *apiVersion)
-	} else {
-		return nil, newConfigurationError("deployments not set", schemas.Azure)
+	url, bifrostErr := azureDeploymentURL(key, model, path)
+	if bifrostErr != nil {
+		return nil, bifrostErr
 	}
 
 	// Create the request with the JSON body
@@ -194,7 +194,7 @@ func (provider *AzureProvider) completeRequest(ctx context.Context, requestBody
 	req.SetBody(jsonData)
 
 	// Send the request
-	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	bifrostErr = makeRequestWithContext(ctx, provider.client, req, resp)
 	if bifrostErr != nil {
 		return nil, bifrostErr
 	}
@@ -218,6 +218,29 @@ func (provider *AzureProvider) completeRequest(ctx context.Context, requestBody
 	return body, nil
 }
 
+// azureDeploymentURL returns the URL of an operation of the deployment serving a model. Keys map
+// models to deployment names; without a mapping, models are deployed under their own name.
+func azureDeploymentURL(key schemas.Key, model string, path string) (string, *schemas.BifrostError) {
+	if key.AzureKeyConfig.Endpoint == "" {
+		return "", newConfigurationError("endpoint not set", schemas.Azure)
+	}
+
+	deployment := model
+	if len(key.AzureKeyConfig.Deployments) > 0 {
+		deployment = key.AzureKeyConfig.Deployments[model]
+		if deployment == "" {
+			return "", newConfigurationError(fmt.Sprintf("deployment not found for model %s", model), schemas.Azure)
+		}
+	}
+
+	apiVersion := AzureDefaultAPIVersion
+	if key.AzureKeyConfig.APIVersion != nil && *key.AzureKeyConfig.APIVersion != "" {
+		apiVersion = *key.AzureKeyConfig.APIVersion
+	}
+
+	return fmt.Sprintf("%s/openai/deployments/%s/%s?api-version=%s", strings.TrimRight(key.AzureKeyConfig.Endpoint, "/"), url.PathEscape(deployment), path, url.QueryEscape(apiVersion)), nil
+}
+
 // TextCompletion performs a text completion request to Azure's API.
 // It formats the request, sends it to Azure, and processes the response.
 // Returns a BifrostResponse containing the completion results or an error if the request fails.
@@ -294,6 +317,10 @@ func (provider *AzureProvider) TextCompletion(ctx context.Context, model string,
 // It formats the request, sends it to Azure, and processes the response.
 // Returns a BifrostResponse containing the completion results or an error if the request fails.
 func (provider *AzureProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if err := checkOpenAIChatTools(params, schemas.Azure); err != nil {
+		return nil, err
+	}
+
 	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
 
 	// Merge additional parameters
@@ -374,6 +401,10 @@ func (provider *AzureProvider) Embedding(ctx context.Context, model string, key
 // Uses Azure-specific URL construction with deployments and supports both api-key and Bearer token authentication.
 // Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
 func (provider *AzureProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	if err := checkOpenAIChatTools(params, schemas.Azure); err != nil {
+		return nil, err
+	}
+
 	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
 
 	if key.AzureKeyConfig == nil {
@@ -388,27 +419,9 @@ func (provider *AzureProvider) ChatCompletionStream(ctx context.Context, postHoo
 	}, preparedParams)
 
 	// Construct Azure-specific URL with deployment
-	if key.AzureKeyConfig.Endpoint == "" {
-		return nil, newConfigurationError("endpoint not set", schemas.Azure)
-	}
-
-	baseURL := key.AzureKeyConfig.Endpoint
-	var fullURL string
-
-	if key.AzureKeyConfig.Deployments != nil {
-		deployment := key.AzureKeyConfig.Deployments[model]
-		if deployment == "" {
-			return nil, newConfigurationError(fmt.Sprintf("deployment not found for model %s", model), schemas.Azure)
-		}
-
-		apiVersion := key.AzureKeyConfig.APIVersion
-		if apiVersion == nil {
-			apiVersion = Ptr("2024-02-01")
-		}
-
-		fullURL = fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", baseURL, deployment, *apiVersion)
-	} else {
-		return nil, newConfigurationError("deployments not set", schemas.Azure)
+	fullURL, bifrostErr := azureDeploymentURL(key, model, "chat/completions")
+	if bifrostErr != nil {
+		return nil, bifrostErr
 	}
 
 	// Prepare Azure-specific headers
@@ -454,3 +467,27 @@ func (provider *AzureProvider) Transcription(ctx context.Context, model string,
 func (provider *AzureProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("transcription stream", "azure")
 }
+
+func (provider *AzureProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "azure")
+}
+
+func (provider *AzureProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "azure")
+}
+
+func (provider *AzureProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "azure")
+}
+
+func (provider *AzureProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "azure")
+}
+
+func (provider *AzureProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "azure")
+}
+
+func (provider *AzureProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "azure")
+}
@@ -54,7 +54,8 @@ type BedrockChatResponse struct {
 		Message struct {
 			Content []struct {
 				Text *string `json:"text"` // Message content
-				// Bedrock returns a union type where either Text or ToolUse is present (mutually exclusive)
+				// Bedrock returns a union type where either Text, ReasoningContent or ToolUse is present (mutually exclusive)
+				ReasoningContent *BedrockReasoningContent `json:"reasoningContent,omitempty"`
 				BedrockAnthropicToolUseMessage
 			} `json:"content"` // Array of message content
 			Role string `json:"role"` // Role of the message sender
@@ -62,12 +63,26 @@ type BedrockChatResponse struct {
 	} `json:"output"` // Output structure
 	StopReason string `json:"stopReason"` // Reason for completion termination
 	Usage      struct {
-		InputTokens  int `json:"inputTokens"`  // Number of input tokens used
-		OutputTokens int `json:"outputTokens"` // Number of output tokens generated
-		TotalTokens  int `json:"totalTokens"`  // Total number of tokens used
+		InputTokens           int `json:"inputTokens"`           // Number of input tokens used
+		OutputTokens          int `json:"outputTokens"`          // Number of output tokens generated
+		TotalTokens           int `json:"totalTokens"`           // Total number of tokens used
+		CacheReadInputTokens  int `json:"cacheReadInputTokens"`  // Input tokens read from the prompt cache
+		CacheWriteInputTokens int `json:"cacheWriteInputTokens"` // Input tokens written to the prompt cache
 	} `json:"usage"` // Token usage statistics
 }
 
+// BedrockReasoningContent is the reasoning of a model, such as the extended thinking of Claude.
+type BedrockReasoningContent struct {
+	ReasoningText *BedrockReasoningText `json:"reasoningText,omitempty"`
+}
+
+// BedrockReasoningText is the text of a reasoning block and the signature verifying it, which
+// has to be sent back with the reasoning in later turns.
+type BedrockReasoningText struct {
+	Text      string `json:"text"`
+	Signature string `json:"signature,omitempty"`
+}
+
 // BedrockAnthropicSystemMessage represents a system message for Anthropic models.
 type BedrockAnthropicSystemMessage struct {
 	Text string `json:"text"` // System message text
@@ -237,6 +252,9 @@ func NewBedrockProvider(config *schemas.ProviderConfig, logger schemas.Logger) (
 
 	client := &http.Client{Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds)}
 
+	// Apply the HTTP client profile if provided
+	client = configureNetHTTPClientProfile(client, config.HTTPClientProfile, logger)
+
 	// Pre-warm response pools
 	for range config.ConcurrencyAndBufferSize.Concurrency {
 		bedrockChatResponsePool.Put(&BedrockChatResponse{})
@@ -256,6 +274,15 @@ func (provider *BedrockProvider) GetProviderKey() schemas.ModelProvider {
 	return getProviderName(schemas.Bedrock, provider.customProviderConfig)
 }
 
+// WarmUp opens connections to the Bedrock region of a key ahead of the first request.
+func (provider *BedrockProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	region := "us-east-1"
+	if key.BedrockKeyConfig != nil && key.BedrockKeyConfig.Region != nil {
+		region = *key.BedrockKeyConfig.Region
+	}
+	return warmUpConnections(ctx, nil, provider.client, fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region))
+}
+
 // CompleteRequest sends a request to Bedrock's API and handles the response.
 // It constructs the API URL, sets up AWS authentication, and processes the response.
 // Returns the response body or an error if the request fails.
@@ -272,6 +299,7 @@ func (provider *BedrockProvider) completeRequest(ctx context.Context, requestBod
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return nil, &schemas.BifrostError{
 				IsBifrostError: false,
+				Origin:         requestErrorOrigin(err),
 				Error: schemas.ErrorField{
 					Type:    Ptr(schemas.RequestCancelled),
 					Message: fmt.Sprintf("Request cancelled or timed out by context: %v", ctx.Err()),
@@ -281,6 +309,7 @@ func (provider *BedrockProvider) completeRequest(ctx context.Context, requestBod
 		}
 		return nil, &schemas.BifrostError{
 			IsBifrostError: true,
+			Origin:         schemas.ErrorOriginBifrostInternal,
 			Error: schemas.ErrorField{
 				Message: schemas.ErrProviderJSONMarshaling,
 				Error:   err,
@@ -288,11 +317,16 @@ func (provider *BedrockProvider) completeRequest(ctx context.Context, requestBod
 		}
 	}
 
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
 	// Create the request with the JSON body
 	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com/model/%s", region, path), bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: true,
+			Origin:         schemas.ErrorOriginBifrostInternal,
 			Error: schemas.ErrorField{
 				Message: "error creating request",
 				Error:   err,
@@ -318,6 +352,7 @@ func (provider *BedrockProvider) completeRequest(ctx context.Context, requestBod
 	if err != nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         requestErrorOrigin(err),
 			Error: schemas.ErrorField{
 				Message: schemas.ErrProviderRequest,
 				Error:   err,
@@ -331,6 +366,7 @@ func (provider *BedrockProvider) completeRequest(ctx context.Context, requestBod
 	if err != nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: true,
+			Origin:         schemas.ErrorOriginNetwork,
 			Error: schemas.ErrorField{
 				Message: "error reading request",
 				Error:   err,
@@ -345,6 +381,7 @@ func (provider *BedrockProvider) completeRequest(ctx context.Context, requestBod
 			return nil, &schemas.BifrostError{
 				IsBifrostError: true,
 				StatusCode:     &resp.StatusCode,
+				Origin:         schemas.ErrorOriginProvider,
 				Error: schemas.ErrorField{
 					Message: schemas.ErrProviderResponseUnmarshal,
 					Error:   err,
@@ -354,6 +391,7 @@ func (provider *BedrockProvider) completeRequest(ctx context.Context, requestBod
 
 		return nil, &schemas.BifrostError{
 			StatusCode: &resp.StatusCode,
+			Origin:     schemas.ErrorOriginProvider,
 			Error: schemas.ErrorField{
 				Message: errorResp.Message,
 			},
@@ -373,6 +411,7 @@ func (provider *BedrockProvider) getTextCompletionResult(result []byte, model st
 		if err := sonic.Unmarshal(result, &response); err != nil {
 			return nil, &schemas.BifrostError{
 				IsBifrostError: true,
+				Origin:         schemas.ErrorOriginProvider,
 				Error: schemas.ErrorField{
 					Message: "error parsing response",
 					Error:   err,
@@ -407,6 +446,7 @@ func (provider *BedrockProvider) getTextCompletionResult(result []byte, model st
 		if err := sonic.Unmarshal(result, &response); err != nil {
 			return nil, &schemas.BifrostError{
 				IsBifrostError: true,
+				Origin:         schemas.ErrorOriginProvider,
 				Error: schemas.ErrorField{
 					Message: "error parsing response",
 					Error:   err,
@@ -463,15 +503,113 @@ func parseBedrockAnthropicMessageToolCallContent(content string) map[string]inte
 }
 
 // PrepareChatCompletionMessages formats chat messages for Bedrock's API.
-// It handles different model types (Anthropic and Mistral) and formats messages accordingly.
+// Mistral models use their own message format, all other models the Converse message format.
 // Returns a map containing the formatted messages and any system messages, or an error if formatting fails.
 func (provider *BedrockProvider) prepareChatCompletionMessages(messages []schemas.BifrostMessage, model string) (map[string]interface{}, *schemas.BifrostError) {
 	switch {
-	case strings.Contains(model, "anthropic."):
+	case strings.Contains(model, "mistral."):
+		var bedrockMessages []BedrockMistralChatMessage
+		for _, msg := range messages {
+			// Check if this is a tool message before changing the role
+			isToolMessage := msg.Role == schemas.ModelChatMessageRoleTool
+
+			// Convert tool messages to user messages (Mistral doesn't support tool role)
+			role := msg.Role
+			switch role {
+			case schemas.ModelChatMessageRoleTool, schemas.ModelChatMessageRoleSystem, schemas.ModelChatMessageRoleDeveloper:
+				role = schemas.ModelChatMessageRoleUser
+			}
+
+			// Only process user and assistant messages
+			if role != schemas.ModelChatMessageRoleUser && role != schemas.ModelChatMessageRoleAssistant {
+				continue
+			}
+
+			var filteredToolCalls []BedrockAnthropicToolCall
+			if msg.AssistantMessage != nil && msg.AssistantMessage.ToolCalls != nil {
+				for _, toolCall := range *msg.AssistantMessage.ToolCalls {
+					if toolCall.ID != nil && toolCall.Function.Name != nil {
+						// Parse the arguments to get parameters
+						var params interface{}
+						if toolCall.Function.Arguments != "" {
+							if err := sonic.Unmarshal([]byte(toolCall.Function.Arguments), &params); err != nil {
+								// If parsing fails, use empty object
+								params = map[string]interface{}{}
+							}
+						}
+
+						filteredToolCalls = append(filteredToolCalls, BedrockAnthropicToolCall{
+							ToolSpec: BedrockAnthropicToolSpec{
+								Name:        *toolCall.Function.Name,
+								Description: "Tool function", // Default description since FunctionCall doesn't have one
+								InputSchema: struct {
+									Json interface{} `json:"json"`
+								}{
+									Json: params,
+								},
+							},
+						})
+					}
+				}
+			}
+
+			message := BedrockMistralChatMessage{
+				Role: role,
+			}
+
+			// Ensure message has valid content
+			var hasValidContent bool
+			switch {
+			case msg.Content.ContentStr != nil && *msg.Content.ContentStr != "":
+				message.Content = []BedrockMistralContent{{Text: *msg.Content.ContentStr}}
+				hasValidContent = true
+			case msg.Content.ContentBlocks != nil && len(*msg.Content.ContentBlocks) > 0:
+				for _, b := range *msg.Content.ContentBlocks {
+					if b.Text != nil && *b.Text != "" {
+						message.Content = append(message.Content, BedrockMistralContent{Text: *b.Text})
+						hasValidContent = true
+					}
+				}
+			}
+
+			// For tool messages that were converted to user messages, ensure they have content
+			if isToolMessage && !hasValidContent {
+				// If tool message has no content, create a default content
+				defaultText := "Tool result received"
+				if msg.ToolCallID != nil {
+					defaultText = fmt.Sprintf("Tool result for call ID: %s", *msg.ToolCallID)
+				}
+				message.Content = []BedrockMistralContent{{Text: defaultText}}
+				hasValidContent = true
+			}
+
+			// Final safety check: ensure message always has content
+			if !hasValidContent {
+				message.Content = []BedrockMistralContent{{Text: "Message content"}}
+				hasValidContent = true
+			}
+
+			// Only add messages that have valid content or tool calls
+			if hasValidContent || len(filteredToolCalls) > 0 {
+				if len(filteredToolCalls) > 0 {
+					message.ToolCalls = &filteredToolCalls
+				}
+				bedrockMessages = append(bedrockMessages, message)
+			}
+		}
+
+		body := map[string]interface{}{
+			"messages": bedrockMessages,
+		}
+
+		return body, nil
+
+	default:
+		// Converse format, shared by Anthropic, Meta, Amazon, Cohere and the other Converse models
 		// Add system messages if present
 		var systemMessages []BedrockAnthropicSystemMessage
 		for _, msg := range messages {
-			if msg.Role == schemas.ModelChatMessageRoleSystem {
+			if msg.Role.IsInstruction() {
 				if msg.Content.ContentStr != nil {
 					systemMessages = append(systemMessages, BedrockAnthropicSystemMessage{
 						Text: *msg.Content.ContentStr,
@@ -492,7 +630,7 @@ func (provider *BedrockProvider) prepareChatCompletionMessages(messages []schema
 		var bedrockMessages []map[string]interface{}
 		for _, msg := range messages {
 			var content []interface{}
-			if msg.Role != schemas.ModelChatMessageRoleSystem {
+			if !msg.Role.IsInstruction() {
 				if msg.Role == schemas.ModelChatMessageRoleTool && msg.ToolCallID != nil {
 					toolCallResult := map[string]interface{}{
 						"toolUseId": *msg.ToolCallID,
@@ -512,6 +650,18 @@ func (provider *BedrockProvider) prepareChatCompletionMessages(messages []schema
 						"toolResult": toolCallResult,
 					})
 				} else {
+					// Signed reasoning is sent back first, as models like Claude require it with tool use
+					if msg.AssistantMessage != nil && msg.AssistantMessage.Thought != nil && msg.AssistantMessage.ThoughtSignature != nil {
+						content = append(content, map[string]interface{}{
+							"reasoningContent": BedrockReasoningContent{
+								ReasoningText: &BedrockReasoningText{
+									Text:      *msg.AssistantMessage.Thought,
+									Signature: *msg.AssistantMessage.ThoughtSignature,
+								},
+							},
+						})
+					}
+
 					// Bedrock wants only toolUse block on content, text blocks are not allowed when tools are called.
 					if msg.AssistantMessage != nil && msg.AssistantMessage.ToolCalls != nil {
 						for _, toolCall := range *msg.AssistantMessage.ToolCalls {
@@ -664,134 +814,28 @@ func (provider *BedrockProvider) prepareChatCompletionMessages(messages []schema
 			body["system"] = systemMessages
 		}
 		return body, nil
-
-	case strings.Contains(model, "mistral."):
-		var bedrockMessages []BedrockMistralChatMessage
-		for _, msg := range messages {
-			// Check if this is a tool message before changing the role
-			isToolMessage := msg.Role == schemas.ModelChatMessageRoleTool
-
-			// Convert tool messages to user messages (Mistral doesn't support tool role)
-			role := msg.Role
-			switch role {
-			case schemas.ModelChatMessageRoleTool, schemas.ModelChatMessageRoleSystem:
-				role = schemas.ModelChatMessageRoleUser
-			}
-
-			// Only process user and assistant messages
-			if role != schemas.ModelChatMessageRoleUser && role != schemas.ModelChatMessageRoleAssistant {
-				continue
-			}
-
-			var filteredToolCalls []BedrockAnthropicToolCall
-			if msg.AssistantMessage != nil && msg.AssistantMessage.ToolCalls != nil {
-				for _, toolCall := range *msg.AssistantMessage.ToolCalls {
-					if toolCall.ID != nil && toolCall.Function.Name != nil {
-						// Parse the arguments to get parameters
-						var params interface{}
-						if toolCall.Function.Arguments != "" {
-							if err := sonic.Unmarshal([]byte(toolCall.Function.Arguments), &params); err != nil {
-								// If parsing fails, use empty object
-								params = map[string]interface{}{}
-							}
-						}
-
-						filteredToolCalls = append(filteredToolCalls, BedrockAnthropicToolCall{
-							ToolSpec: BedrockAnthropicToolSpec{
-								Name:        *toolCall.Function.Name,
-								Description: "Tool function", // Default description since FunctionCall doesn't have one
-								InputSchema: struct {
-									Json interface{} `json:"json"`
-								}{
-									Json: params,
-								},
-							},
-						})
-					}
-				}
-			}
-
-			message := BedrockMistralChatMessage{
-				Role: role,
-			}
-
-			// Ensure message has valid content
-			var hasValidContent bool
-			switch {
-			case msg.Content.ContentStr != nil && *msg.Content.ContentStr != "":
-				message.Content = []BedrockMistralContent{{Text: *msg.Content.ContentStr}}
-				hasValidContent = true
-			case msg.Content.ContentBlocks != nil && len(*msg.Content.ContentBlocks) > 0:
-				for _, b := range *msg.Content.ContentBlocks {
-					if b.Text != nil && *b.Text != "" {
-						message.Content = append(message.Content, BedrockMistralContent{Text: *b.Text})
-						hasValidContent = true
-					}
-				}
-			}
-
-			// For tool messages that were converted to user messages, ensure they have content
-			if isToolMessage && !hasValidContent {
-				// If tool message has no content, create a default content
-				defaultText := "Tool result received"
-				if msg.ToolCallID != nil {
-					defaultText = fmt.Sprintf("Tool result for call ID: %s", *msg.ToolCallID)
-				}
-				message.Content = []BedrockMistralContent{{Text: defaultText}}
-				hasValidContent = true
-			}
-
-			// Final safety check: ensure message always has content
-			if !hasValidContent {
-				message.Content = []BedrockMistralContent{{Text: "Message content"}}
-				hasValidContent = true
-			}
-
-			// Only add messages that have valid content or tool calls
-			if hasValidContent || len(filteredToolCalls) > 0 {
-				if len(filteredToolCalls) > 0 {
-					message.ToolCalls = &filteredToolCalls
-				}
-				bedrockMessages = append(bedrockMessages, message)
-			}
-		}
-
-		body := map[string]interface{}{
-			"messages": bedrockMessages,
-		}
-
-		return body, nil
 	}
-
-	return nil, newConfigurationError(fmt.Sprintf("invalid model choice: %s", model), provider.GetProviderKey())
 }
 
 // GetChatCompletionTools prepares tool specifications for Bedrock's API.
-// It formats tool definitions for different model types (Anthropic and Mistral).
-// Returns tool specifications appropriate for the given model type.
+// It formats tool definitions in the toolSpec structure shared by all Converse models.
 func (provider *BedrockProvider) getChatCompletionTools(params *schemas.ModelParameters, model string) (interface{}, *schemas.BifrostError) {
-	switch {
-	case strings.Contains(model, "anthropic."), strings.Contains(model, "mistral."):
-		// Both Anthropic and Mistral models on Bedrock use toolConfig.tools with toolSpec structure
-		var tools []BedrockAnthropicToolCall
-		for _, tool := range *params.Tools {
-			tools = append(tools, BedrockAnthropicToolCall{
-				ToolSpec: BedrockAnthropicToolSpec{
-					Name:        tool.Function.Name,
-					Description: tool.Function.Description,
-					InputSchema: struct {
-						Json interface{} `json:"json"`
-					}{
-						Json: tool.Function.Parameters,
-					},
+	// All Converse models use toolConfig.tools with toolSpec structure
+	var tools []BedrockAnthropicToolCall
+	for _, tool := range *params.Tools {
+		tools = append(tools, BedrockAnthropicToolCall{
+			ToolSpec: BedrockAnthropicToolSpec{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				InputSchema: struct {
+					Json interface{} `json:"json"`
+				}{
+					Json: tool.Function.Parameters,
 				},
-			})
-		}
-		return tools, nil
-
-	default:
-		return nil, newConfigurationError(fmt.Sprintf("unsupported model for tool calling: %s", model), provider.GetProviderKey())
+			},
+		})
 	}
+	return tools, nil
 }
 
 // prepareTextCompletionParams prepares text completion parameters for Bedrock's API.
@@ -957,32 +1001,87 @@ func (provider *BedrockProvider) prepareToolChoice(params *schemas.ModelParamete
 	return nil
 }
 
-// ChatCompletion performs a chat completion request to Bedrock's API.
-// It formats the request, sends it to Bedrock, and processes the response.
-// Returns a BifrostResponse containing the completion results or an error if the request fails.
-func (provider *BedrockProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
-	if err := checkOperationAllowed(schemas.Bedrock, provider.customProviderConfig, schemas.OperationChatCompletion); err != nil {
-		return nil, err
-	}
-
-	providerName := provider.GetProviderKey()
-
-	if key.BedrockKeyConfig == nil {
-		return nil, newConfigurationError("bedrock key config is not provided", providerName)
-	}
+// converseRequestFields are the top level fields of Converse requests. Extra params with these
+// names are sent as they are, other extra params are sent as additional model request fields.
+var converseRequestFields = map[string]bool{
+	"toolConfig":                        true,
+	"guardrailConfig":                   true,
+	"additionalModelRequestFields":      true,
+	"additionalModelResponseFieldPaths": true,
+	"promptVariables":                   true,
+	"performanceConfig":                 true,
+	"requestMetadata":                   true,
+}
 
-	messageBody, err := provider.prepareChatCompletionMessages(messages, model)
+// prepareConverseRequest builds the body of a Converse or ConverseStream request. The sampling
+// parameters Converse supports go in inferenceConfig, top_k in the additional model request
+// fields, and the parameters Converse has no equivalent for are dropped with a warning.
+func (provider *BedrockProvider) prepareConverseRequest(ctx context.Context, messages []schemas.BifrostMessage, model string, params *schemas.ModelParameters) (map[string]interface{}, *schemas.BifrostError) {
+	requestBody, err := provider.prepareChatCompletionMessages(messages, model)
 	if err != nil {
 		return nil, err
 	}
 
-	preparedParams := prepareParams(params)
+	inferenceConfig := map[string]interface{}{}
+	additionalFields := map[string]interface{}{}
+	if params != nil {
+		if params.MaxTokens != nil {
+			inferenceConfig["maxTokens"] = *params.MaxTokens
+		}
+		if params.Temperature != nil {
+			inferenceConfig["temperature"] = *params.Temperature
+		}
+		if params.TopP != nil {
+			inferenceConfig["topP"] = *params.TopP
+		}
+		if params.StopSequences != nil {
+			inferenceConfig["stopSequences"] = *params.StopSequences
+		}
+		if params.TopK != nil {
+			additionalFields["top_k"] = *params.TopK
+		}
+
+		var dropped []string
+		if params.PresencePenalty != nil {
+			dropped = append(dropped, "presence_penalty")
+		}
+		if params.FrequencyPenalty != nil {
+			dropped = append(dropped, "frequency_penalty")
+		}
+		if params.ParallelToolCalls != nil {
+			dropped = append(dropped, "parallel_tool_calls")
+		}
+		if len(dropped) > 0 {
+			schemas.AddWarning(ctx, schemas.WarningParamDropped, string(provider.GetProviderKey()),
+				fmt.Sprintf("%s not sent: not supported by the Converse API", strings.Join(dropped, ", ")))
+		}
 
-	if strings.Contains(model, "anthropic.") {
-		if _, exists := preparedParams["max_tokens"]; !exists {
-			preparedParams["max_tokens"] = AnthropicDefaultMaxTokens
+		for name, value := range params.ExtraParams {
+			switch {
+			case name == "inferenceConfig":
+				if config, ok := value.(map[string]interface{}); ok {
+					maps.Copy(inferenceConfig, config)
+				}
+			case name == "additionalModelRequestFields":
+				if fields, ok := value.(map[string]interface{}); ok {
+					maps.Copy(additionalFields, fields)
+				}
+			case converseRequestFields[name]:
+				requestBody[name] = value
+			default:
+				additionalFields[name] = value
+			}
 		}
 	}
+	if _, ok := inferenceConfig["maxTokens"]; !ok && strings.Contains(model, "anthropic.") {
+		inferenceConfig["maxTokens"] = AnthropicDefaultMaxTokens
+	}
+	if len(inferenceConfig) > 0 {
+		requestBody["inferenceConfig"] = inferenceConfig
+	}
+	if len(additionalFields) > 0 {
+		requestBody["additionalModelRequestFields"] = additionalFields
+	}
 
 	// Transform tools if present
 	if params != nil && params.Tools != nil && len(*params.Tools) > 0 {
@@ -999,11 +1098,8 @@ func (provider *BedrockProvider) ChatCompletion(ctx context.Context, model strin
 			toolConfig["toolChoice"] = toolChoice
 		}
 
-		preparedParams["toolConfig"] = toolConfig
-
-		delete(preparedParams, "tools")
-		delete(preparedParams, "tool_choice")
-	} else {
+		requestBody["toolConfig"] = toolConfig
+	} else if _, ok := requestBody["toolConfig"]; !ok {
 		// Check if conversation history contains tool use/result blocks
 		// Bedrock requires toolConfig when such blocks are present
 		hasToolContent, toolsFromHistory := provider.extractToolsFromHistory(messages)
@@ -1011,13 +1107,62 @@ func (provider *BedrockProvider) ChatCompletion(ctx context.Context, model strin
 		// If conversation contains tool content but no tools provided in current request,
 		// include the extracted tools to satisfy Bedrock's toolConfig requirement
 		if hasToolContent && len(toolsFromHistory) > 0 {
-			preparedParams["toolConfig"] = map[string]interface{}{
+			requestBody["toolConfig"] = map[string]interface{}{
 				"tools": toolsFromHistory,
 			}
 		}
 	}
 
-	requestBody := mergeConfig(messageBody, preparedParams)
+	return requestBody, nil
+}
+
+// MapBedrockStopReason maps a Converse stop reason to the OpenAI finish reasons used by Bifrost.
+func MapBedrockStopReason(stopReason string) string {
+	switch stopReason {
+	case "end_turn", "stop_sequence":
+		return "stop"
+	case "max_tokens", "model_context_window_exceeded":
+		return "length"
+	case "tool_use":
+		return "tool_calls"
+	case "guardrail_intervened", "content_filtered":
+		return "content_filter"
+	default:
+		return stopReason
+	}
+}
+
+// convertBedrockUsage converts Converse token usage, reporting cache reads as cached tokens.
+func convertBedrockUsage(inputTokens, outputTokens, totalTokens, cacheReadTokens int) *schemas.LLMUsage {
+	usage := &schemas.LLMUsage{
+		PromptTokens:     inputTokens,
+		CompletionTokens: outputTokens,
+		TotalTokens:      totalTokens,
+	}
+	if cacheReadTokens > 0 {
+		usage.TokenDetails = &schemas.TokenDetails{CachedTokens: cacheReadTokens}
+	}
+	return usage
+}
+
+// ChatCompletion performs a chat completion request to Bedrock's API.
+// It formats the request, sends it to Bedrock, and processes the response.
+// Returns a BifrostResponse containing the completion results or an error if the request fails.
+func (provider *BedrockProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if err := checkOperationAllowed(schemas.Bedrock, provider.customProviderConfig, schemas.OperationChatCompletion); err != nil {
+		return nil, err
+	}
+
+	providerName := provider.GetProviderKey()
+
+	if key.BedrockKeyConfig == nil {
+		return nil, newConfigurationError("bedrock key config is not provided", providerName)
+	}
+
+	requestBody, err := provider.prepareConverseRequest(ctx, messages, model, params)
+	if err != nil {
+		return nil, err
+	}
 
 	// Format the path with proper model identifier
 	path := provider.getModelPath("converse", model, key)
@@ -1041,8 +1186,16 @@ func (provider *BedrockProvider) ChatCompletion(ctx context.Context, model strin
 	var toolCalls []schemas.ToolCall
 
 	var contentBlocks []schemas.ContentBlock
+	var thought, thoughtSignature string
 	// Process content and tool calls
 	for _, choice := range response.Output.Message.Content {
+		if choice.ReasoningContent != nil && choice.ReasoningContent.ReasoningText != nil {
+			thought += choice.ReasoningContent.ReasoningText.Text
+			if choice.ReasoningContent.ReasoningText.Signature != "" {
+				thoughtSignature = choice.ReasoningContent.ReasoningText.Signature
+			}
+		}
+
 		if choice.Text != nil && *choice.Text != "" {
 			contentBlocks = append(contentBlocks, schemas.ContentBlock{
 				Type: "text",
@@ -1074,10 +1227,17 @@ func (provider *BedrockProvider) ChatCompletion(ctx context.Context, model strin
 	// Create the assistant message
 	var assistantMessage *schemas.AssistantMessage
 
-	// Create AssistantMessage if we have tool calls
-	if len(toolCalls) > 0 {
-		assistantMessage = &schemas.AssistantMessage{
-			ToolCalls: &toolCalls,
+	// Create AssistantMessage if we have tool calls or reasoning
+	if len(toolCalls) > 0 || thought != "" {
+		assistantMessage = &schemas.AssistantMessage{}
+		if len(toolCalls) > 0 {
+			assistantMessage.ToolCalls = &toolCalls
+		}
+		if thought != "" {
+			assistantMessage.Thought = &thought
+		}
+		if thoughtSignature != "" {
+			assistantMessage.ThoughtSignature = &thoughtSignature
 		}
 	}
 
@@ -1094,7 +1254,7 @@ func (provider *BedrockProvider) ChatCompletion(ctx context.Context, model strin
 					AssistantMessage: assistantMessage,
 				},
 			},
-			FinishReason: &response.StopReason,
+			FinishReason: Ptr(MapBedrockStopReason(response.StopReason)),
 		},
 	}
 
@@ -1102,13 +1262,10 @@ func (provider *BedrockProvider) ChatCompletion(ctx context.Context, model strin
 
 	// Create final response
 	bifrostResponse := &schemas.BifrostResponse{
+		Object:  "chat.completion",
 		Choices: choices,
-		Usage: &schemas.LLMUsage{
-			PromptTokens:     response.Usage.InputTokens,
-			CompletionTokens: response.Usage.OutputTokens,
-			TotalTokens:      response.Usage.TotalTokens,
-		},
-		Model: model,
+		Usage:   convertBedrockUsage(response.Usage.InputTokens, response.Usage.OutputTokens, response.Usage.TotalTokens, response.Usage.CacheReadInputTokens),
+		Model:   model,
 		ExtraFields: schemas.BifrostResponseExtraFields{
 			Latency:  &latency,
 			Provider: providerName,
@@ -1353,52 +1510,11 @@ func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postH
 		return nil, newConfigurationError("bedrock key config is not provided", providerName)
 	}
 
-	messageBody, err := provider.prepareChatCompletionMessages(messages, model)
+	requestBody, err := provider.prepareConverseRequest(ctx, messages, model, params)
 	if err != nil {
 		return nil, err
 	}
 
-	preparedParams := prepareParams(params)
-
-	if strings.Contains(model, "anthropic.") {
-		if _, exists := preparedParams["max_tokens"]; !exists {
-			preparedParams["max_tokens"] = AnthropicDefaultMaxTokens
-		}
-	}
-
-	// Transform tools if present
-	if params != nil && params.Tools != nil && len(*params.Tools) > 0 {
-		tools, err := provider.getChatCompletionTools(params, model)
-		if err != nil {
-			return nil, err
-		}
-
-		toolConfig := map[string]interface{}{
-			"tools": tools,
-		}
-
-		// Add tool choice if specified
-		if toolChoice := provider.prepareToolChoice(params, model); toolChoice != nil {
-			toolConfig["toolChoice"] = toolChoice
-		}
-
-		preparedParams["toolConfig"] = toolConfig
-	} else {
-		// Check if conversation history contains tool use/result blocks
-		// Bedrock requires toolConfig when such blocks are present
-		hasToolContent, toolsFromHistory := provider.extractToolsFromHistory(messages)
-
-		// If conversation contains tool content but no tools provided in current request,
-		// include the extracted tools to satisfy Bedrock's toolConfig requirement
-		if hasToolContent && len(toolsFromHistory) > 0 {
-			preparedParams["toolConfig"] = map[string]interface{}{
-				"tools": toolsFromHistory,
-			}
-		}
-	}
-
-	requestBody := mergeConfig(messageBody, preparedParams)
-
 	// Format the path with proper model identifier for streaming
 	path := provider.getModelPath("converse-stream", model, key)
 
@@ -1413,6 +1529,10 @@ func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postH
 		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, jsonErr, providerName)
 	}
 
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
 	// Create HTTP request for streaming
 	req, reqErr := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com/model/%s", region, path), bytes.NewReader(jsonBody))
 	if reqErr != nil {
@@ -1451,6 +1571,7 @@ func (provider *BedrockProvider) ChatCompletionStream(ctx context.Context, postH
 	// Start streaming in a goroutine
 	go func() {
 		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, provider.logger)
 		defer resp.Body.Close()
 
 		// Process AWS Event Stream format
@@ -1619,153 +1740,99 @@ func (provider *BedrockProvider) processEventBuffer(ctx context.Context, postHoo
 			processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, provider.logger)
 		}
 
-	case event["contentBlockIndex"] != nil && event["delta"] != nil:
-		// This is a contentBlockDelta event
+	case event["contentBlockIndex"] != nil && event["start"] != nil:
+		// This is a contentBlockStart event, which names the tool of a tool use block
+		start, _ := event["start"].(map[string]interface{})
+		toolUse, ok := start["toolUse"].(map[string]interface{})
+		if !ok {
+			return
+		}
 		*chunkIndex++
-		contentBlockIndex := 0
-		if idx, ok := event["contentBlockIndex"].(float64); ok {
-			contentBlockIndex = int(idx)
+		toolCall := schemas.ToolCall{Type: Ptr("function")}
+		if toolUseID, hasID := toolUse["toolUseId"].(string); hasID {
+			toolCall.ID = &toolUseID
 		}
+		if name, hasName := toolUse["name"].(string); hasName {
+			toolCall.Function.Name = &name
+		}
+		provider.sendStreamDelta(ctx, postHookRunner, schemas.BifrostStreamDelta{ToolCalls: []schemas.ToolCall{toolCall}}, *messageID, *chunkIndex, model, providerName, responseChan)
 
-		if delta, ok := event["delta"].(map[string]interface{}); ok {
-			switch {
-			case delta["text"] != nil:
-				// Handle text delta
-				if text, ok := delta["text"].(string); ok && text != "" {
-					// Create streaming response for this delta
-					streamResponse := &schemas.BifrostResponse{
-						ID:     *messageID,
-						Object: "chat.completion.chunk",
-						Model:  model,
-						Choices: []schemas.BifrostResponseChoice{
-							{
-								Index: contentBlockIndex,
-								BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
-									Delta: schemas.BifrostStreamDelta{
-										Content: &text,
-									},
-								},
-							},
-						},
-						ExtraFields: schemas.BifrostResponseExtraFields{
-							Provider:   providerName,
-							ChunkIndex: *chunkIndex,
-						},
-					}
-
-					// Use utility function to process and send response
-					processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, provider.logger)
-				}
-
-			case delta["toolUse"] != nil:
-				// Handle tool use delta
-				if toolUse, ok := delta["toolUse"].(map[string]interface{}); ok {
-					// Parse the tool use structure properly
-					var toolCall schemas.ToolCall
-					toolCall.Type = func() *string { s := "function"; return &s }()
+	case event["contentBlockIndex"] != nil && event["delta"] != nil:
+		// This is a contentBlockDelta event. All content blocks belong to the single choice
+		// of the response, content block indexes are not choice indexes.
+		delta, ok := event["delta"].(map[string]interface{})
+		if !ok {
+			return
+		}
 
-					// Extract toolUseId
-					if toolUseID, hasID := toolUse["toolUseId"].(string); hasID {
-						toolCall.ID = &toolUseID
-					}
+		var streamDelta schemas.BifrostStreamDelta
+		switch {
+		case delta["text"] != nil:
+			// Handle text delta
+			if text, ok := delta["text"].(string); ok && text != "" {
+				streamDelta.Content = &text
+			}
 
-					// Extract name
-					if name, hasName := toolUse["name"].(string); hasName {
-						toolCall.Function.Name = &name
-					}
+		case delta["reasoningContent"] != nil:
+			// Handle reasoning delta, its text then the signature of the reasoning block
+			if reasoning, ok := delta["reasoningContent"].(map[string]interface{}); ok {
+				if text, ok := reasoning["text"].(string); ok && text != "" {
+					streamDelta.Thought = &text
+				}
+				if signature, ok := reasoning["signature"].(string); ok && signature != "" {
+					streamDelta.ThoughtSignature = &signature
+				}
+			}
 
-					// Extract and marshal input as arguments
-					if input, hasInput := toolUse["input"].(map[string]interface{}); hasInput {
-						inputBytes, err := sonic.Marshal(input)
-						if err != nil {
-							toolCall.Function.Arguments = "{}"
-						} else {
-							toolCall.Function.Arguments = string(inputBytes)
-						}
-					} else {
+		case delta["toolUse"] != nil:
+			// Handle tool use delta, the input arrives as fragments of JSON continuing the tool
+			// call started by the contentBlockStart event
+			if toolUse, ok := delta["toolUse"].(map[string]interface{}); ok {
+				toolCall := schemas.ToolCall{Type: Ptr("function")}
+				switch input := toolUse["input"].(type) {
+				case string:
+					toolCall.Function.Arguments = input
+				case map[string]interface{}:
+					inputBytes, err := sonic.Marshal(input)
+					if err != nil {
 						toolCall.Function.Arguments = "{}"
+					} else {
+						toolCall.Function.Arguments = string(inputBytes)
 					}
-
-					// Create streaming response for tool delta
-					streamResponse := &schemas.BifrostResponse{
-						ID:     *messageID,
-						Object: "chat.completion.chunk",
-						Model:  model,
-						Choices: []schemas.BifrostResponseChoice{
-							{
-								Index: contentBlockIndex,
-								BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
-									Delta: schemas.BifrostStreamDelta{
-										ToolCalls: []schemas.ToolCall{toolCall},
-									},
-								},
-							},
-						},
-						ExtraFields: schemas.BifrostResponseExtraFields{
-							Provider:   providerName,
-							ChunkIndex: *chunkIndex,
-						},
-					}
-
-					// Use utility function to process and send response
-					processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, provider.logger)
 				}
+				if toolUseID, hasID := toolUse["toolUseId"].(string); hasID {
+					toolCall.ID = &toolUseID
+				}
+				if name, hasName := toolUse["name"].(string); hasName {
+					toolCall.Function.Name = &name
+				}
+				streamDelta.ToolCalls = []schemas.ToolCall{toolCall}
 			}
 		}
 
+		if streamDelta.Content == nil && streamDelta.Thought == nil && streamDelta.ThoughtSignature == nil && len(streamDelta.ToolCalls) == 0 {
+			return
+		}
+		*chunkIndex++
+		provider.sendStreamDelta(ctx, postHookRunner, streamDelta, *messageID, *chunkIndex, model, providerName, responseChan)
+
 	case event["stopReason"] != nil:
 		// This is a messageStop event
 		if stopReason, ok := event["stopReason"].(string); ok {
-			*finishReason = &stopReason
+			*finishReason = Ptr(MapBedrockStopReason(stopReason))
 		}
 
 	case event["usage"] != nil:
 		// This is a metadata event with usage information at top level
 		if usageData, ok := event["usage"].(map[string]interface{}); ok {
-			inputTokens := 0
-			outputTokens := 0
-			totalTokens := 0
-
-			if val, exists := usageData["inputTokens"].(float64); exists {
-				inputTokens = int(val)
-			}
-			if val, exists := usageData["outputTokens"].(float64); exists {
-				outputTokens = int(val)
-			}
-			if val, exists := usageData["totalTokens"].(float64); exists {
-				totalTokens = int(val)
-			}
-
-			*usage = &schemas.LLMUsage{
-				PromptTokens:     inputTokens,
-				CompletionTokens: outputTokens,
-				TotalTokens:      totalTokens,
-			}
+			*usage = parseBedrockStreamUsage(usageData)
 		}
 
 	case event["metadata"] != nil:
 		// This is a metadata event - check if it contains nested usage information
 		if metadata, ok := event["metadata"].(map[string]interface{}); ok {
 			if usageData, ok := metadata["usage"].(map[string]interface{}); ok {
-				inputTokens := 0
-				outputTokens := 0
-				totalTokens := 0
-
-				if val, exists := usageData["inputTokens"].(float64); exists {
-					inputTokens = int(val)
-				}
-				if val, exists := usageData["outputTokens"].(float64); exists {
-					outputTokens = int(val)
-				}
-				if val, exists := usageData["totalTokens"].(float64); exists {
-					totalTokens = int(val)
-				}
-
-				*usage = &schemas.LLMUsage{
-					PromptTokens:     inputTokens,
-					CompletionTokens: outputTokens,
-					TotalTokens:      totalTokens,
-				}
+				*usage = parseBedrockStreamUsage(usageData)
 			}
 		}
 
@@ -1775,6 +1842,41 @@ func (provider *BedrockProvider) processEventBuffer(ctx context.Context, postHoo
 	}
 }
 
+// sendStreamDelta sends a delta of the single choice of a Converse stream.
+func (provider *BedrockProvider) sendStreamDelta(ctx context.Context, postHookRunner schemas.PostHookRunner, delta schemas.BifrostStreamDelta, messageID string, chunkIndex int, model string, providerName schemas.ModelProvider, responseChan chan *schemas.BifrostStream) {
+	streamResponse := &schemas.BifrostResponse{
+		ID:     messageID,
+		Object: "chat.completion.chunk",
+		Model:  model,
+		Choices: []schemas.BifrostResponseChoice{
+			{
+				Index: 0,
+				BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+					Delta: delta,
+				},
+			},
+		},
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider:   providerName,
+			ChunkIndex: chunkIndex,
+		},
+	}
+
+	// Use utility function to process and send response
+	processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, provider.logger)
+}
+
+// parseBedrockStreamUsage converts the usage of a Converse stream metadata event.
+func parseBedrockStreamUsage(usageData map[string]interface{}) *schemas.LLMUsage {
+	tokens := func(name string) int {
+		if val, exists := usageData[name].(float64); exists {
+			return int(val)
+		}
+		return 0
+	}
+	return convertBedrockUsage(tokens("inputTokens"), tokens("outputTokens"), tokens("totalTokens"), tokens("cacheReadInputTokens"))
+}
+
 func (provider *BedrockProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("speech", "bedrock")
 }
@@ -1791,6 +1893,30 @@ func (provider *BedrockProvider) TranscriptionStream(ctx context.Context, postHo
 	return nil, newUnsupportedOperationError("transcription stream", "bedrock")
 }
 
+func (provider *BedrockProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "bedrock")
+}
+
+func (provider *BedrockProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "bedrock")
+}
+
+func (provider *BedrockProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "bedrock")
+}
+
+func (provider *BedrockProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "bedrock")
+}
+
+func (provider *BedrockProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "bedrock")
+}
+
+func (provider *BedrockProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "bedrock")
+}
+
 func (provider *BedrockProvider) getModelPath(basePath string, model string, key schemas.Key) string {
 	// Format the path with proper model identifier for streaming
 	path := fmt.Sprintf("%s/%s", model, basePath)
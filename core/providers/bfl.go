@@ -0,0 +1,410 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the Black Forest Labs (FLUX) provider implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// bflPollInterval is the delay between two polls of a generation task.
+	bflPollInterval = 500 * time.Millisecond
+	// bflMaxPollDuration bounds the time waited for a generation task to finish.
+	bflMaxPollDuration = 2 * time.Minute
+	// bflSizeStep is the multiple FLUX image dimensions are rounded to.
+	bflSizeStep = 32
+)
+
+// BFL task statuses, as returned when polling a generation task.
+const (
+	bflStatusReady   = "Ready"
+	bflStatusPending = "Pending"
+)
+
+// BFLTaskResponse represents the response of a FLUX generation request, a task to poll.
+type BFLTaskResponse struct {
+	ID         string   `json:"id"`
+	PollingURL string   `json:"polling_url"`
+	Cost       *float64 `json:"cost,omitempty"` // Credits charged, when reported
+}
+
+// BFLResultResponse represents the state of a generation task.
+type BFLResultResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // Ready, Pending, Request Moderated, Content Moderated, Error, Task not found
+	Result *struct {
+		Sample string `json:"sample"` // Signed URL of the image, valid for 10 minutes
+		Prompt string `json:"prompt,omitempty"`
+		Seed   *int   `json:"seed,omitempty"`
+	} `json:"result,omitempty"`
+}
+
+// BFLProvider implements the Provider interface for Black Forest Labs' FLUX API.
+// Generations are asynchronous: each request returns a task that is polled until the image
+// is ready. Every task produces a single image.
+type BFLProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+}
+
+// NewBFLProvider creates a new Black Forest Labs provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewBFLProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*BFLProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://api.bfl.ai"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &BFLProvider{
+		logger:              logger,
+		client:              client,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Black Forest Labs.
+func (provider *BFLProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.BFL
+}
+
+// WarmUp opens connections to the BFL API ahead of the first request.
+func (provider *BFLProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, nil, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the Black Forest Labs provider.
+func (provider *BFLProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "bfl")
+}
+
+// ChatCompletion is not supported by the Black Forest Labs provider.
+func (provider *BFLProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("chat completion", "bfl")
+}
+
+// ChatCompletionStream is not supported by the Black Forest Labs provider.
+func (provider *BFLProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("chat completion stream", "bfl")
+}
+
+// Embedding is not supported by the Black Forest Labs provider.
+func (provider *BFLProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("embedding", "bfl")
+}
+
+func (provider *BFLProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "bfl")
+}
+
+func (provider *BFLProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "bfl")
+}
+
+func (provider *BFLProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "bfl")
+}
+
+func (provider *BFLProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "bfl")
+}
+
+func (provider *BFLProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "bfl")
+}
+
+func (provider *BFLProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "bfl")
+}
+
+func (provider *BFLProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "bfl")
+}
+
+func (provider *BFLProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "bfl")
+}
+
+func (provider *BFLProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "bfl")
+}
+
+// ImageGeneration generates images with a FLUX model, e.g. "flux-pro-1.1" or "flux-kontext-pro".
+// Ultra and Kontext models are sized by aspect ratio, the others by width and height, which are
+// derived from whichever of Size and AspectRatio is set. Options such as safety_tolerance and
+// prompt_upsampling are passed through ModelParameters.ExtraParams.
+func (provider *BFLProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	n, err := imageCount(input)
+	if err != nil {
+		return nil, newConfigurationError(err.Error(), schemas.BFL)
+	}
+
+	requestBody := map[string]interface{}{
+		"prompt": input.Prompt,
+	}
+	if err := bflSetSize(requestBody, model, input); err != nil {
+		return nil, newConfigurationError(err.Error(), schemas.BFL)
+	}
+	outputFormat := "jpeg"
+	if input.OutputFormat != nil {
+		outputFormat = *input.OutputFormat
+	}
+	requestBody["output_format"] = outputFormat
+	if params != nil {
+		requestBody = mergeConfig(requestBody, params.ExtraParams)
+	}
+
+	rawResponses := make([]interface{}, n)
+	costs := make([]*float64, n)
+	images, bifrostErr := generateImages(ctx, n, func(ctx context.Context, index int) (*schemas.GeneratedImage, *schemas.BifrostError) {
+		body := requestBody
+		if input.Seed != nil {
+			// Consecutive seeds keep the images of one request distinct yet reproducible
+			body = mergeConfig(requestBody, map[string]interface{}{"seed": *input.Seed + index})
+		}
+
+		task, bifrostErr := provider.submitTask(ctx, model, key, body)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		result, rawResponse, bifrostErr := provider.pollTask(ctx, key, task)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		rawResponses[index] = rawResponse
+
+		image := &schemas.GeneratedImage{
+			URL:      result.Result.Sample,
+			MimeType: imageMimeType(outputFormat),
+			Seed:     result.Result.Seed,
+		}
+		if !wantsImageURL(input) {
+			image.B64JSON, image.MimeType, bifrostErr = downloadImage(ctx, provider.client, result.Result.Sample, schemas.BFL)
+			if bifrostErr != nil {
+				return nil, bifrostErr
+			}
+			image.URL = ""
+		}
+		costs[index] = task.Cost
+		return image, nil
+	})
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	usage := &schemas.ImageUsage{Images: len(images)}
+	for _, cost := range costs {
+		if cost == nil {
+			continue
+		}
+		if usage.Credits == nil {
+			usage.Credits = Ptr(0.0)
+		}
+		*usage.Credits += *cost
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		Object:  "image.generation",
+		Model:   model,
+		Created: int(time.Now().Unix()),
+		Image: &schemas.BifrostImage{
+			Images: images,
+			Usage:  usage,
+		},
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.BFL,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponses
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// submitTask starts a generation task.
+func (provider *BFLProvider) submitTask(ctx context.Context, model string, key schemas.Key, requestBody map[string]interface{}) (*BFLTaskResponse, *schemas.BifrostError) {
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.BFL)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/v1/" + model)
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("x-key", key.Value)
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return nil, provider.parseError(resp)
+	}
+
+	task := &BFLTaskResponse{}
+	if _, bifrostErr := handleProviderResponse(resp.Body(), task, false); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+	if task.PollingURL == "" {
+		// Older deployments only return the task id
+		task.PollingURL = provider.networkConfig.BaseURL + "/v1/get_result?id=" + task.ID
+	}
+	return task, nil
+}
+
+// pollTask polls a generation task until its image is ready, it fails, or the context ends.
+func (provider *BFLProvider) pollTask(ctx context.Context, key schemas.Key, task *BFLTaskResponse) (*BFLResultResponse, interface{}, *schemas.BifrostError) {
+	var rawResponse interface{}
+	result, bifrostErr := pollOperation(ctx, asyncPoll{interval: bflPollInterval, timeout: bflMaxPollDuration}, "generation task "+task.ID, schemas.BFL, func(ctx context.Context) (*BFLResultResponse, bool, *schemas.BifrostError) {
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		defer fasthttp.ReleaseRequest(req)
+		defer fasthttp.ReleaseResponse(resp)
+
+		setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+		req.SetRequestURI(task.PollingURL)
+		req.Header.SetMethod("GET")
+		req.Header.Set("x-key", key.Value)
+
+		if bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp); bifrostErr != nil {
+			return nil, false, bifrostErr
+		}
+		if resp.StatusCode() != fasthttp.StatusOK {
+			return nil, false, provider.parseError(resp)
+		}
+
+		result := &BFLResultResponse{}
+		raw, bifrostErr := handleProviderResponse(resp.Body(), result, provider.sendBackRawResponse)
+		if bifrostErr != nil {
+			return nil, false, bifrostErr
+		}
+
+		switch result.Status {
+		case bflStatusPending:
+			return nil, false, nil
+		case bflStatusReady:
+			if result.Result == nil || result.Result.Sample == "" {
+				return nil, false, newBifrostOperationError("generation task finished without an image", nil, schemas.BFL)
+			}
+			rawResponse = raw
+			return result, true, nil
+		case "Request Moderated", "Content Moderated":
+			return nil, false, newProviderAPIError("BFL error: "+strings.ToLower(result.Status), nil, fasthttp.StatusBadRequest, schemas.BFL, Ptr("content_filter"), nil)
+		default:
+			return nil, false, newProviderAPIError(fmt.Sprintf("BFL error: generation task %s: %s", task.ID, result.Status), nil, fasthttp.StatusInternalServerError, schemas.BFL, nil, nil)
+		}
+	})
+	if bifrostErr != nil {
+		return nil, nil, bifrostErr
+	}
+	return result, rawResponse, nil
+}
+
+// parseError converts an error response of the FLUX API.
+func (provider *BFLProvider) parseError(resp *fasthttp.Response) *schemas.BifrostError {
+	provider.logger.Debug(fmt.Sprintf("error from bfl provider: %s", string(resp.Body())))
+
+	var errorResp map[string]interface{}
+	bifrostErr := handleProviderAPIError(resp, &errorResp)
+	if detail, ok := errorResp["detail"]; ok {
+		bifrostErr.Error.Message = fmt.Sprintf("BFL error: %v", detail)
+	} else {
+		bifrostErr.Error.Message = fmt.Sprintf("BFL error: %v", errorResp)
+	}
+	return bifrostErr
+}
+
+// bflSetSize sets the size fields of a generation request. Ultra and Kontext models take an
+// aspect ratio, the other models a width and height in multiples of 32.
+func bflSetSize(requestBody map[string]interface{}, model string, input *schemas.ImageInput) error {
+	byRatio := strings.Contains(model, "ultra") || strings.Contains(model, "kontext")
+
+	if input.Size != nil && *input.Size != "" {
+		width, height, err := parseImageSize(*input.Size)
+		if err != nil {
+			return err
+		}
+		if byRatio {
+			divisor := gcd(width, height)
+			requestBody["aspect_ratio"] = fmt.Sprintf("%d:%d", width/divisor, height/divisor)
+		} else {
+			requestBody["width"] = roundToMultiple(width, bflSizeStep)
+			requestBody["height"] = roundToMultiple(height, bflSizeStep)
+		}
+		return nil
+	}
+
+	if input.AspectRatio == nil || *input.AspectRatio == "" {
+		return nil
+	}
+	if byRatio {
+		requestBody["aspect_ratio"] = *input.AspectRatio
+		return nil
+	}
+	// Width and height of about one megapixel with the requested ratio
+	width, height, err := parseImageSize(strings.Replace(*input.AspectRatio, ":", "x", 1))
+	if err != nil {
+		return fmt.Errorf("invalid aspect ratio %q", *input.AspectRatio)
+	}
+	scale := 1024 / float64(max(width, height))
+	requestBody["width"] = roundToMultiple(int(float64(width)*scale), bflSizeStep)
+	requestBody["height"] = roundToMultiple(int(float64(height)*scale), bflSizeStep)
+	return nil
+}
+
+// roundToMultiple rounds value to the nearest multiple of step, at least step.
+func roundToMultiple(value, step int) int {
+	return max((value+step/2)/step*step, step)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
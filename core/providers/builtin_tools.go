@@ -0,0 +1,127 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the conversion of provider hosted (built-in) tools.
+package providers
+
+import (
+	"slices"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// isBuiltInTool reports whether the tool is hosted by the provider rather than a function tool.
+func isBuiltInTool(tool schemas.Tool) bool {
+	return tool.ComputerUse != nil || tool.CodeInterpreter != nil || tool.FileSearch != nil || tool.WebSearch != nil
+}
+
+// anthropicWebSearchToolType is the Anthropic server tool type for web search.
+const anthropicWebSearchToolType = "web_search_20250305"
+
+// builtInToolType returns the schemas tool type of a built-in tool, or "" for function tools.
+func builtInToolType(tool schemas.Tool) string {
+	switch {
+	case tool.ComputerUse != nil:
+		return schemas.ToolTypeComputerUse
+	case tool.CodeInterpreter != nil:
+		return schemas.ToolTypeCodeInterpreter
+	case tool.FileSearch != nil:
+		return schemas.ToolTypeFileSearch
+	case tool.WebSearch != nil:
+		return schemas.ToolTypeWebSearch
+	}
+	return ""
+}
+
+// checkBuiltInTools returns an unsupported operation error for the first built-in tool whose
+// type is not in supported, so requests fail instead of sending tools the provider rejects.
+func checkBuiltInTools(params *schemas.ModelParameters, providerName schemas.ModelProvider, supported ...string) *schemas.BifrostError {
+	if params == nil || params.Tools == nil {
+		return nil
+	}
+	for _, tool := range *params.Tools {
+		toolType := builtInToolType(tool)
+		if toolType != "" && !slices.Contains(supported, toolType) {
+			return newUnsupportedOperationError(toolType+" tool", string(providerName))
+		}
+	}
+	return nil
+}
+
+// checkOpenAIChatTools rejects the built-in tools the chat completions API does not accept.
+// OpenAI only offers computer use, code interpreter and file search through its Responses API.
+func checkOpenAIChatTools(params *schemas.ModelParameters, providerName schemas.ModelProvider) *schemas.BifrostError {
+	return checkBuiltInTools(params, providerName, schemas.ToolTypeWebSearch)
+}
+
+// checkAnthropicTools rejects the built-in tools Anthropic has no server tool for.
+func checkAnthropicTools(params *schemas.ModelParameters, providerName schemas.ModelProvider) *schemas.BifrostError {
+	return checkBuiltInTools(params, providerName, schemas.ToolTypeComputerUse, schemas.ToolTypeWebSearch)
+}
+
+// anthropicWebSearchTool returns the Anthropic server tool definition for a web search tool.
+// Anthropic takes the approximate location fields directly in user_location.
+func anthropicWebSearchTool(tool schemas.Tool) map[string]interface{} {
+	anthropicTool := map[string]interface{}{
+		"type": anthropicWebSearchToolType,
+		"name": schemas.ToolTypeWebSearch,
+	}
+	if location := tool.WebSearch.UserLocation; location != nil {
+		if approximate, ok := location["approximate"].(map[string]interface{}); ok {
+			userLocation := map[string]interface{}{"type": "approximate"}
+			for k, v := range approximate {
+				userLocation[k] = v
+			}
+			location = userLocation
+		}
+		anthropicTool["user_location"] = location
+	}
+	return anthropicTool
+}
+
+// convertOpenAIBuiltInTools rewrites built-in tools in the prepared params into OpenAI's format.
+// Web search is enabled through "web_search_options" on the chat completions API and function
+// tools are passed through unchanged. The chat completions API has no other built-in tools, so
+// they are dropped here; OpenAI and Azure reject them up front with checkOpenAIChatTools.
+func convertOpenAIBuiltInTools(params *schemas.ModelParameters, preparedParams map[string]interface{}) {
+	if params == nil || params.Tools == nil {
+		return
+	}
+
+	hasBuiltIn := false
+	for _, tool := range *params.Tools {
+		if isBuiltInTool(tool) {
+			hasBuiltIn = true
+			break
+		}
+	}
+	if !hasBuiltIn {
+		return
+	}
+
+	tools := make([]interface{}, 0, len(*params.Tools))
+	for _, tool := range *params.Tools {
+		switch {
+		case tool.WebSearch != nil:
+			webSearchOptions := map[string]interface{}{}
+			if tool.WebSearch.SearchContextSize != nil {
+				webSearchOptions["search_context_size"] = *tool.WebSearch.SearchContextSize
+			}
+			if tool.WebSearch.UserLocation != nil {
+				webSearchOptions["user_location"] = tool.WebSearch.UserLocation
+			}
+			// Explicit web_search_options passed through ExtraParams take precedence
+			if _, exists := preparedParams["web_search_options"]; !exists {
+				preparedParams["web_search_options"] = webSearchOptions
+			}
+		case isBuiltInTool(tool):
+			continue
+		default:
+			tools = append(tools, tool)
+		}
+	}
+
+	if len(tools) > 0 {
+		preparedParams["tools"] = tools
+	} else {
+		delete(preparedParams, "tools")
+	}
+}
@@ -0,0 +1,85 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestCheckBuiltInTools(t *testing.T) {
+	function := schemas.Tool{Type: "function", Function: schemas.Function{Name: "lookup"}}
+	computerUse := schemas.Tool{Type: schemas.ToolTypeComputerUse, ComputerUse: &schemas.ComputerUseTool{DisplayWidth: 1024, DisplayHeight: 768}}
+	codeInterpreter := schemas.Tool{Type: schemas.ToolTypeCodeInterpreter, CodeInterpreter: &schemas.CodeInterpreterTool{}}
+	fileSearch := schemas.Tool{Type: schemas.ToolTypeFileSearch, FileSearch: &schemas.FileSearchTool{VectorStoreIDs: []string{"vs_1"}}}
+	webSearch := schemas.Tool{Type: schemas.ToolTypeWebSearch, WebSearch: &schemas.WebSearchTool{}}
+
+	tests := []struct {
+		name    string
+		check   func(*schemas.ModelParameters, schemas.ModelProvider) *schemas.BifrostError
+		tools   []schemas.Tool
+		wantErr bool
+	}{
+		{name: "openai function and web search", check: checkOpenAIChatTools, tools: []schemas.Tool{function, webSearch}},
+		{name: "openai computer use", check: checkOpenAIChatTools, tools: []schemas.Tool{function, computerUse}, wantErr: true},
+		{name: "openai code interpreter", check: checkOpenAIChatTools, tools: []schemas.Tool{codeInterpreter}, wantErr: true},
+		{name: "openai file search", check: checkOpenAIChatTools, tools: []schemas.Tool{fileSearch}, wantErr: true},
+		{name: "anthropic computer use and web search", check: checkAnthropicTools, tools: []schemas.Tool{computerUse, webSearch, function}},
+		{name: "anthropic code interpreter", check: checkAnthropicTools, tools: []schemas.Tool{codeInterpreter}, wantErr: true},
+		{name: "anthropic file search", check: checkAnthropicTools, tools: []schemas.Tool{fileSearch}, wantErr: true},
+		{name: "no tools", check: checkOpenAIChatTools},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &schemas.ModelParameters{}
+			if tt.tools != nil {
+				params.Tools = &tt.tools
+			}
+			if err := tt.check(params, schemas.OpenAI); (err != nil) != tt.wantErr {
+				t.Errorf("check returned %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvertOpenAIBuiltInTools(t *testing.T) {
+	function := schemas.Tool{Type: "function", Function: schemas.Function{Name: "lookup"}}
+	webSearch := schemas.Tool{Type: schemas.ToolTypeWebSearch, WebSearch: &schemas.WebSearchTool{SearchContextSize: Ptr("low")}}
+
+	tools := []schemas.Tool{function, webSearch}
+	preparedParams := map[string]interface{}{"tools": tools}
+	convertOpenAIBuiltInTools(&schemas.ModelParameters{Tools: &tools}, preparedParams)
+
+	wantTools := []interface{}{function}
+	if !reflect.DeepEqual(preparedParams["tools"], wantTools) {
+		t.Errorf("tools = %v, want %v", preparedParams["tools"], wantTools)
+	}
+	wantOptions := map[string]interface{}{"search_context_size": "low"}
+	if !reflect.DeepEqual(preparedParams["web_search_options"], wantOptions) {
+		t.Errorf("web_search_options = %v, want %v", preparedParams["web_search_options"], wantOptions)
+	}
+
+	tools = []schemas.Tool{webSearch}
+	preparedParams = map[string]interface{}{"tools": tools}
+	convertOpenAIBuiltInTools(&schemas.ModelParameters{Tools: &tools}, preparedParams)
+	if _, exists := preparedParams["tools"]; exists {
+		t.Errorf("tools = %v, want none", preparedParams["tools"])
+	}
+}
+
+func TestAnthropicWebSearchTool(t *testing.T) {
+	tool := schemas.Tool{Type: schemas.ToolTypeWebSearch, WebSearch: &schemas.WebSearchTool{
+		UserLocation: map[string]interface{}{
+			"type":        "approximate",
+			"approximate": map[string]interface{}{"country": "US", "city": "Austin"},
+		},
+	}}
+	want := map[string]interface{}{
+		"type":          anthropicWebSearchToolType,
+		"name":          "web_search",
+		"user_location": map[string]interface{}{"type": "approximate", "country": "US", "city": "Austin"},
+	}
+	if got := anthropicWebSearchTool(tool); !reflect.DeepEqual(got, want) {
+		t.Errorf("anthropicWebSearchTool() = %v, want %v", got, want)
+	}
+}
@@ -57,6 +57,21 @@ func releaseCerebrasTextResponse(resp *AzureTextResponse) {
 	}
 }
 
+// CerebrasTimeInfo is the time_info object of Cerebras responses, the latency breakdown of the
+// request in seconds. Streams report it in their last chunk.
+type CerebrasTimeInfo struct {
+	QueueTime      float64 `json:"queue_time"`
+	PromptTime     float64 `json:"prompt_time"`
+	CompletionTime float64 `json:"completion_time"`
+	TotalTime      float64 `json:"total_time"`
+	Created        float64 `json:"created"`
+}
+
+// CerebrasResponseFields are the fields of a Cerebras completion the OpenAI format has no place for.
+type CerebrasResponseFields struct {
+	TimeInfo *CerebrasTimeInfo `json:"time_info,omitempty"`
+}
+
 // CerebrasProvider implements the Provider interface for Cerebras's API.
 type CerebrasProvider struct {
 	logger              schemas.Logger        // Logger for provider operations
@@ -92,6 +107,10 @@ func NewCerebrasProvider(config *schemas.ProviderConfig, logger schemas.Logger)
 	// Configure proxy if provided
 	client = configureProxy(client, config.ProxyConfig, logger)
 
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
 		config.NetworkConfig.BaseURL = "https://api.cerebras.ai"
@@ -112,6 +131,11 @@ func (provider *CerebrasProvider) GetProviderKey() schemas.ModelProvider {
 	return schemas.Cerebras
 }
 
+// WarmUp opens connections to the Cerebras API ahead of the first request.
+func (provider *CerebrasProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
 // TextCompletion performs a text completion request to Cerebras's API.
 // It formats the request, sends it to Cerebras, and processes the response.
 // Returns a BifrostResponse containing the completion results or an error if the request fails.
@@ -208,7 +232,8 @@ func (provider *CerebrasProvider) TextCompletion(ctx context.Context, model stri
 		SystemFingerprint: response.SystemFingerprint,
 		Usage:             &usageCopy,
 		ExtraFields: schemas.BifrostResponseExtraFields{
-			Provider: schemas.Cerebras,
+			Provider:       schemas.Cerebras,
+			ProviderTiming: provider.parseTiming(responseBody, usageCopy.CompletionTokens),
 		},
 	}
 
@@ -286,6 +311,12 @@ func (provider *CerebrasProvider) ChatCompletion(ctx context.Context, model stri
 	// Create final response
 	response.ExtraFields.Provider = schemas.Cerebras
 
+	var completionTokens int
+	if response.Usage != nil {
+		completionTokens = response.Usage.CompletionTokens
+	}
+	response.ExtraFields.ProviderTiming = provider.parseTiming(responseBody, completionTokens)
+
 	if provider.sendBackRawResponse {
 		response.ExtraFields.RawResponse = rawResponse
 	}
@@ -324,8 +355,31 @@ func (provider *CerebrasProvider) ChatCompletionStream(ctx context.Context, post
 
 	headers["Authorization"] = "Bearer " + key.Value
 
+	// The latency breakdown comes in the time_info object of the last chunk
+	var timeInfo *CerebrasTimeInfo
+	chunkHook := func(rawChunk map[string]interface{}, response *schemas.BifrostResponse) {
+		info, err := parseCerebrasTimeInfo(rawChunk)
+		if err != nil {
+			provider.logger.Warn(fmt.Sprintf("Failed to parse cerebras time info: %v", err))
+			return
+		}
+		if info != nil {
+			timeInfo = info
+		}
+	}
+	endHook := func(response *schemas.BifrostResponse) {
+		if timeInfo == nil {
+			return
+		}
+		var completionTokens int
+		if response.Usage != nil {
+			completionTokens = response.Usage.CompletionTokens
+		}
+		response.ExtraFields.ProviderTiming = cerebrasTiming(timeInfo, completionTokens)
+	}
+
 	// Use shared OpenAI-compatible streaming logic
-	return handleOpenAIStreaming(
+	return handleOpenAIStreamingWithHook(
 		ctx,
 		provider.streamClient,
 		provider.networkConfig.BaseURL+"/v1/chat/completions",
@@ -336,6 +390,8 @@ func (provider *CerebrasProvider) ChatCompletionStream(ctx context.Context, post
 		params,
 		postHookRunner,
 		provider.logger,
+		chunkHook,
+		endHook,
 	)
 }
 
@@ -354,3 +410,74 @@ func (provider *CerebrasProvider) Transcription(ctx context.Context, model strin
 func (provider *CerebrasProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("transcription stream", "cerebras")
 }
+
+func (provider *CerebrasProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "cerebras")
+}
+
+func (provider *CerebrasProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "cerebras")
+}
+
+func (provider *CerebrasProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "cerebras")
+}
+
+func (provider *CerebrasProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "cerebras")
+}
+
+func (provider *CerebrasProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "cerebras")
+}
+
+func (provider *CerebrasProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "cerebras")
+}
+
+// parseTiming extracts the latency breakdown of a Cerebras response body, nil if it has none.
+func (provider *CerebrasProvider) parseTiming(responseBody []byte, completionTokens int) *schemas.ProviderTiming {
+	var fields CerebrasResponseFields
+	if err := sonic.Unmarshal(responseBody, &fields); err != nil {
+		provider.logger.Warn(fmt.Sprintf("Failed to parse cerebras time info: %v", err))
+		return nil
+	}
+	if fields.TimeInfo == nil {
+		return nil
+	}
+	return cerebrasTiming(fields.TimeInfo, completionTokens)
+}
+
+// parseCerebrasTimeInfo extracts the time_info object from a raw stream chunk, nil if it has none.
+func parseCerebrasTimeInfo(rawChunk map[string]interface{}) (*CerebrasTimeInfo, error) {
+	value, ok := rawChunk["time_info"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var info CerebrasTimeInfo
+	if err := sonic.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// cerebrasTiming converts a Cerebras time_info object, nil if it reports no times.
+func cerebrasTiming(info *CerebrasTimeInfo, completionTokens int) *schemas.ProviderTiming {
+	if info.TotalTime == 0 && info.QueueTime == 0 && info.PromptTime == 0 && info.CompletionTime == 0 {
+		return nil
+	}
+	timing := &schemas.ProviderTiming{
+		QueueTime:      info.QueueTime,
+		PromptTime:     info.PromptTime,
+		CompletionTime: info.CompletionTime,
+		TotalTime:      info.TotalTime,
+	}
+	if info.CompletionTime > 0 {
+		timing.OutputTokensPerSecond = float64(completionTokens) / info.CompletionTime
+	}
+	return timing
+}
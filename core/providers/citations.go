@@ -0,0 +1,161 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains helpers to normalize provider citations into schemas.MessageCitation.
+package providers
+
+import (
+	"unicode/utf8"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// citationsFromAnnotations converts OpenAI style annotations into normalized citations.
+// OpenAI annotation offsets are already character offsets into the message content.
+func citationsFromAnnotations(annotations []schemas.Annotation) []schemas.MessageCitation {
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	citations := make([]schemas.MessageCitation, 0, len(annotations))
+	for _, annotation := range annotations {
+		switch annotation.Type {
+		case schemas.AnnotationTypeURLCitation:
+			if annotation.Citation == nil {
+				continue
+			}
+			citation := schemas.MessageCitation{
+				Type:       schemas.MessageCitationTypeURL,
+				URL:        annotation.Citation.URL,
+				StartIndex: Ptr(annotation.Citation.StartIndex),
+				EndIndex:   Ptr(annotation.Citation.EndIndex),
+			}
+			if annotation.Citation.Title != "" {
+				citation.Title = Ptr(annotation.Citation.Title)
+			}
+			citations = append(citations, citation)
+		case schemas.AnnotationTypeFileCitation:
+			if annotation.FileCitation == nil {
+				continue
+			}
+			citations = append(citations, schemas.MessageCitation{
+				Type:       schemas.MessageCitationTypeFile,
+				Title:      annotation.FileCitation.Filename,
+				CitedText:  annotation.FileCitation.Quote,
+				StartIndex: annotation.FileCitation.StartIndex,
+				EndIndex:   annotation.FileCitation.EndIndex,
+				SourceID:   Ptr(annotation.FileCitation.FileID),
+			})
+		case schemas.AnnotationTypeContainerFileCitation:
+			if annotation.ContainerFileCitation == nil {
+				continue
+			}
+			citations = append(citations, schemas.MessageCitation{
+				Type:       schemas.MessageCitationTypeFile,
+				Title:      annotation.ContainerFileCitation.Filename,
+				StartIndex: Ptr(annotation.ContainerFileCitation.StartIndex),
+				EndIndex:   Ptr(annotation.ContainerFileCitation.EndIndex),
+				SourceID:   Ptr(annotation.ContainerFileCitation.FileID),
+			})
+		}
+	}
+
+	if len(citations) == 0 {
+		return nil
+	}
+	return citations
+}
+
+// populateCitations fills the normalized Citations of every choice in an OpenAI compatible
+// response (or stream chunk) from its annotations.
+func populateCitations(response *schemas.BifrostResponse) {
+	for i := range response.Choices {
+		choice := &response.Choices[i]
+		if choice.BifrostNonStreamResponseChoice != nil && choice.Message.AssistantMessage != nil && len(choice.Message.AssistantMessage.Citations) == 0 {
+			choice.Message.AssistantMessage.Citations = citationsFromAnnotations(choice.Message.AssistantMessage.Annotations)
+		}
+		if choice.BifrostStreamResponseChoice != nil && len(choice.Delta.Citations) == 0 {
+			choice.Delta.Citations = citationsFromAnnotations(choice.Delta.Annotations)
+		}
+	}
+}
+
+// citationsFromGeminiGrounding converts Gemini grounding metadata into normalized citations, one
+// per supported segment and source. Segment offsets are byte offsets into a part, so they are
+// converted to character offsets into the message content using the text of each part (empty
+// for non-text parts). Sources no segment refers to are added without offsets.
+func citationsFromGeminiGrounding(metadata *GeminiGroundingMetadata, partTexts []string) []schemas.MessageCitation {
+	if metadata == nil || len(metadata.GroundingChunks) == 0 {
+		return nil
+	}
+
+	// Character offset of each part in the message content
+	partOffsets := make([]int, len(partTexts))
+	offset := 0
+	for i, text := range partTexts {
+		partOffsets[i] = offset
+		offset += utf8.RuneCountInString(text)
+	}
+	charOffset := func(partIndex, byteOffset int) *int {
+		if partIndex < 0 || partIndex >= len(partTexts) {
+			return nil
+		}
+		text := partTexts[partIndex]
+		byteOffset = max(0, min(byteOffset, len(text)))
+		return Ptr(partOffsets[partIndex] + utf8.RuneCountInString(text[:byteOffset]))
+	}
+
+	var citations []schemas.MessageCitation
+	cited := make([]bool, len(metadata.GroundingChunks))
+	for _, support := range metadata.GroundingSupports {
+		for i, chunkIndex := range support.GroundingChunkIndices {
+			if chunkIndex < 0 || chunkIndex >= len(metadata.GroundingChunks) {
+				continue
+			}
+			citation, ok := geminiGroundingCitation(metadata.GroundingChunks[chunkIndex])
+			if !ok {
+				continue
+			}
+			cited[chunkIndex] = true
+			citation.StartIndex = charOffset(support.Segment.PartIndex, support.Segment.StartIndex)
+			citation.EndIndex = charOffset(support.Segment.PartIndex, support.Segment.EndIndex)
+			if i < len(support.ConfidenceScores) {
+				citation.Confidence = Ptr(support.ConfidenceScores[i])
+			}
+			citations = append(citations, citation)
+		}
+	}
+	for i, chunk := range metadata.GroundingChunks {
+		if cited[i] {
+			continue
+		}
+		if citation, ok := geminiGroundingCitation(chunk); ok {
+			citations = append(citations, citation)
+		}
+	}
+	return citations
+}
+
+// geminiGroundingCitation converts a grounding chunk into a citation without offsets.
+func geminiGroundingCitation(chunk GeminiGroundingChunk) (schemas.MessageCitation, bool) {
+	var citation schemas.MessageCitation
+	var source *GeminiGroundingSource
+	switch {
+	case chunk.Web != nil:
+		citation.Type = schemas.MessageCitationTypeURL
+		source = chunk.Web
+	case chunk.RetrievedContext != nil:
+		citation.Type = schemas.MessageCitationTypeFile
+		source = chunk.RetrievedContext
+		if source.Text != "" {
+			citation.CitedText = Ptr(source.Text)
+		}
+	default:
+		return citation, false
+	}
+	if source.URI != "" {
+		citation.URL = Ptr(source.URI)
+	}
+	if source.Title != "" {
+		citation.Title = Ptr(source.Title)
+	}
+	return citation, true
+}
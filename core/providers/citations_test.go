@@ -0,0 +1,171 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestCitationsFromAnnotations(t *testing.T) {
+	annotations := []schemas.Annotation{
+		{Type: schemas.AnnotationTypeURLCitation, Citation: &schemas.Citation{StartIndex: 3, EndIndex: 10, Title: "Docs", URL: Ptr("https://example.com")}},
+		{Type: schemas.AnnotationTypeURLCitation}, // missing url_citation
+		{Type: schemas.AnnotationTypeFileCitation, FileCitation: &schemas.FileCitation{FileID: "file-1", Filename: Ptr("a.pdf"), Quote: Ptr("quoted")}},
+		{Type: schemas.AnnotationTypeContainerFileCitation, ContainerFileCitation: &schemas.ContainerFileCitation{FileID: "cfile-1", Filename: Ptr("out.csv"), StartIndex: 1, EndIndex: 2}},
+		{Type: schemas.AnnotationTypeFilePath, FilePath: &schemas.FilePathAnnotation{FileID: "file-2"}},
+	}
+	want := []schemas.MessageCitation{
+		{Type: schemas.MessageCitationTypeURL, URL: Ptr("https://example.com"), Title: Ptr("Docs"), StartIndex: Ptr(3), EndIndex: Ptr(10)},
+		{Type: schemas.MessageCitationTypeFile, Title: Ptr("a.pdf"), CitedText: Ptr("quoted"), SourceID: Ptr("file-1")},
+		{Type: schemas.MessageCitationTypeFile, Title: Ptr("out.csv"), StartIndex: Ptr(1), EndIndex: Ptr(2), SourceID: Ptr("cfile-1")},
+	}
+	if got := citationsFromAnnotations(annotations); !reflect.DeepEqual(got, want) {
+		t.Errorf("citationsFromAnnotations() = %+v, want %+v", got, want)
+	}
+	if got := citationsFromAnnotations(nil); got != nil {
+		t.Errorf("citationsFromAnnotations(nil) = %+v, want nil", got)
+	}
+}
+
+func TestPerplexityCitations(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields PerplexitySearchFields
+		want   []schemas.MessageCitation
+	}{
+		{
+			name: "search results",
+			fields: PerplexitySearchFields{
+				Citations:     []string{"https://a.example", "https://b.example"},
+				SearchResults: []PerplexitySearchResult{{Title: "A", URL: "https://a.example"}, {URL: "https://b.example"}},
+			},
+			want: []schemas.MessageCitation{
+				{Type: schemas.MessageCitationTypeURL, URL: Ptr("https://a.example"), Title: Ptr("A"), SourceID: Ptr("1")},
+				{Type: schemas.MessageCitationTypeURL, URL: Ptr("https://b.example"), SourceID: Ptr("2")},
+			},
+		},
+		{
+			name:   "citation URLs only",
+			fields: PerplexitySearchFields{Citations: []string{"https://a.example"}},
+			want:   []schemas.MessageCitation{{Type: schemas.MessageCitationTypeURL, URL: Ptr("https://a.example"), SourceID: Ptr("1")}},
+		},
+		{name: "no sources"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := perplexityCitations(tt.fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("perplexityCitations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// geminiTestGrounding grounds "Café is good. Tea is fine." on two web pages. "é" is two bytes,
+// so the byte offsets of the second sentence are one past its character offsets.
+var geminiTestGrounding = &GeminiGroundingMetadata{
+	GroundingChunks: []GeminiGroundingChunk{
+		{Web: &GeminiGroundingSource{URI: "https://a.example", Title: "a.example"}},
+		{Web: &GeminiGroundingSource{URI: "https://b.example"}},
+		{RetrievedContext: &GeminiGroundingSource{URI: "gs://docs/c.txt", Title: "c.txt", Text: "tea facts"}},
+	},
+	GroundingSupports: []GeminiGroundingSupport{
+		{Segment: GeminiSegment{StartIndex: 0, EndIndex: 14, Text: "Café is good."}, GroundingChunkIndices: []int{0}, ConfidenceScores: []float64{0.9}},
+		{Segment: GeminiSegment{StartIndex: 15, EndIndex: 27, Text: "Tea is fine."}, GroundingChunkIndices: []int{0, 1, 7}, ConfidenceScores: []float64{0.8, 0.7}},
+	},
+}
+
+var geminiTestCitations = []schemas.MessageCitation{
+	{Type: schemas.MessageCitationTypeURL, URL: Ptr("https://a.example"), Title: Ptr("a.example"), StartIndex: Ptr(0), EndIndex: Ptr(13), Confidence: Ptr(0.9)},
+	{Type: schemas.MessageCitationTypeURL, URL: Ptr("https://a.example"), Title: Ptr("a.example"), StartIndex: Ptr(14), EndIndex: Ptr(26), Confidence: Ptr(0.8)},
+	{Type: schemas.MessageCitationTypeURL, URL: Ptr("https://b.example"), StartIndex: Ptr(14), EndIndex: Ptr(26), Confidence: Ptr(0.7)},
+	{Type: schemas.MessageCitationTypeFile, URL: Ptr("gs://docs/c.txt"), Title: Ptr("c.txt"), CitedText: Ptr("tea facts")},
+}
+
+func TestCitationsFromGeminiGrounding(t *testing.T) {
+	if got := citationsFromGeminiGrounding(geminiTestGrounding, []string{"Café is good. Tea is fine."}); !reflect.DeepEqual(got, geminiTestCitations) {
+		t.Errorf("citationsFromGeminiGrounding() = %+v, want %+v", got, geminiTestCitations)
+	}
+
+	// Offsets of later parts start after the text of the earlier parts
+	metadata := &GeminiGroundingMetadata{
+		GroundingChunks:   []GeminiGroundingChunk{{Web: &GeminiGroundingSource{URI: "https://a.example"}}},
+		GroundingSupports: []GeminiGroundingSupport{{Segment: GeminiSegment{PartIndex: 2, StartIndex: 0, EndIndex: 3}, GroundingChunkIndices: []int{0}}},
+	}
+	want := []schemas.MessageCitation{{Type: schemas.MessageCitationTypeURL, URL: Ptr("https://a.example"), StartIndex: Ptr(2), EndIndex: Ptr(4)}}
+	if got := citationsFromGeminiGrounding(metadata, []string{"", "ab", "cé"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("citationsFromGeminiGrounding() with parts = %+v, want %+v", got, want)
+	}
+
+	if got := citationsFromGeminiGrounding(nil, nil); got != nil {
+		t.Errorf("citationsFromGeminiGrounding(nil) = %+v, want nil", got)
+	}
+}
+
+func TestConvertGeminiCandidateCitations(t *testing.T) {
+	candidate := &Candidate{
+		Content:           &Content{Parts: []*Part{{Text: "Café is good. Tea is fine."}}},
+		FinishReason:      "STOP",
+		GroundingMetadata: geminiTestGrounding,
+	}
+	choice := convertGeminiCandidate(candidate, map[string]int{})
+	if choice.Message.AssistantMessage == nil {
+		t.Fatal("AssistantMessage = nil, want citations")
+	}
+	if got := choice.Message.AssistantMessage.Citations; !reflect.DeepEqual(got, geminiTestCitations) {
+		t.Errorf("citations = %+v, want %+v", got, geminiTestCitations)
+	}
+}
+
+func TestHandleGeminiStreamingCitations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"candidates":[{"content":{"parts":[{"text":"Café is good. "}],"role":"model"}}]}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"candidates":[{"content":{"parts":[{"text":"Tea is fine."}],"role":"model"},"finishReason":"STOP","groundingMetadata":{` +
+			`"groundingChunks":[{"web":{"uri":"https://a.example","title":"a.example"}},{"web":{"uri":"https://b.example"}},{"retrievedContext":{"uri":"gs://docs/c.txt","title":"c.txt","text":"tea facts"}}],` +
+			`"groundingSupports":[{"segment":{"endIndex":14},"groundingChunkIndices":[0],"confidenceScores":[0.9]},{"segment":{"startIndex":15,"endIndex":27},"groundingChunkIndices":[0,1],"confidenceScores":[0.8,0.7]}]}}]}` + "\n\n"))
+	}))
+	defer server.Close()
+
+	postHookRunner := func(ctx *context.Context, result *schemas.BifrostResponse, err *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError) {
+		return result, err
+	}
+	stream, bifrostErr := handleGeminiStreaming(context.Background(), server.Client(), server.URL, map[string]interface{}{}, nil, nil,
+		schemas.Gemini, "gemini-2.0-flash", nil, postHookRunner, nopLogger{})
+	if bifrostErr != nil {
+		t.Fatalf("handleGeminiStreaming() error = %v", bifrostErr.Error.Message)
+	}
+
+	var chunks []*schemas.BifrostResponse
+	for chunk := range stream {
+		if chunk.BifrostError != nil {
+			t.Fatalf("stream error = %v", chunk.BifrostError.Error.Message)
+		}
+		chunks = append(chunks, chunk.BifrostResponse)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	for _, chunk := range chunks[:2] {
+		if citations := chunk.Choices[0].Delta.Citations; len(citations) > 0 {
+			t.Errorf("content chunk has citations %+v, want them on the final chunk", citations)
+		}
+	}
+	if got := chunks[2].Choices[0].Delta.Citations; !reflect.DeepEqual(got, geminiTestCitations) {
+		t.Errorf("final chunk citations = %+v, want %+v", got, geminiTestCitations)
+	}
+}
+
+// nopLogger discards log messages.
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...any)                     {}
+func (nopLogger) Info(msg string, args ...any)                      {}
+func (nopLogger) Warn(msg string, args ...any)                      {}
+func (nopLogger) Error(msg string, args ...any)                     {}
+func (nopLogger) Fatal(msg string, args ...any)                     {}
+func (nopLogger) SetLevel(level schemas.LogLevel)                   {}
+func (nopLogger) SetOutputType(outputType schemas.LoggerOutputType) {}
@@ -3,12 +3,11 @@
 package providers
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -41,56 +40,53 @@ func releaseCohereResponse(resp *CohereChatResponse) {
 	}
 }
 
-// CohereParameterDefinition represents a parameter definition for a Cohere tool.
-// It defines the type, description, and whether the parameter is required.
-type CohereParameterDefinition struct {
-	Type        string  `json:"type"`                  // Type of the parameter
-	Description *string `json:"description,omitempty"` // Optional description of the parameter
-	Required    bool    `json:"required"`              // Whether the parameter is required
-}
-
-// CohereTool represents a tool definition for the Cohere API.
-// It includes the tool's name, description, and parameter definitions.
+// CohereTool represents a function tool definition for Cohere's v2 chat API.
 type CohereTool struct {
-	Name                 string                               `json:"name"`                  // Name of the tool
-	Description          string                               `json:"description"`           // Description of the tool
-	ParameterDefinitions map[string]CohereParameterDefinition `json:"parameter_definitions"` // Definitions of the tool's parameters
+	Type     string           `json:"type"`     // Always "function"
+	Function schemas.Function `json:"function"` // Name, description and JSON schema parameters of the function
 }
 
-// CohereToolCall represents a tool call made by the Cohere API.
-// It includes the name of the tool and its parameters.
+// CohereToolCall represents a tool call in Cohere's v2 chat API messages and responses.
 type CohereToolCall struct {
-	Name       string      `json:"name"`       // Name of the tool being called
-	Parameters interface{} `json:"parameters"` // Parameters passed to the tool
+	ID       string `json:"id,omitempty"`   // ID of the tool call
+	Type     string `json:"type,omitempty"` // Always "function"
+	Function struct {
+		Name      string `json:"name,omitempty"` // Name of the function being called
+		Arguments string `json:"arguments"`      // JSON encoded arguments of the call
+	} `json:"function"`
+}
+
+// CohereContentBlock represents a content block of a Cohere v2 chat message.
+type CohereContentBlock struct {
+	Type string `json:"type"` // text
+	Text string `json:"text"` // Text content of the block
+}
+
+// CohereUsage represents the token usage reported by Cohere's v2 APIs.
+type CohereUsage struct {
+	BilledUnits struct {
+		InputTokens     float64 `json:"input_tokens"`    // Number of input tokens billed
+		OutputTokens    float64 `json:"output_tokens"`   // Number of output tokens billed
+		Classifications float64 `json:"classifications"` // Number of classifications billed
+		SearchUnits     float64 `json:"search_units"`    // Number of search units billed
+	} `json:"billed_units"` // Token usage billing information
+	Tokens struct {
+		InputTokens  float64 `json:"input_tokens"`  // Number of input tokens used
+		OutputTokens float64 `json:"output_tokens"` // Number of output tokens generated
+	} `json:"tokens"` // Token usage statistics
 }
 
-// CohereChatResponse represents the response from Cohere's chat API.
-// It includes the response ID, generated text, chat history, and usage statistics.
+// CohereChatResponse represents the response from Cohere's v2 chat API.
 type CohereChatResponse struct {
-	GenerationID string `json:"generation_id"` // ID of the generation
-	Text         string `json:"text"`          // Generated text response
-	ChatHistory  []struct {
-		Role      schemas.ModelChatMessageRole `json:"role"`       // Role of the message sender
-		Message   string                       `json:"message"`    // Content of the message
-		ToolCalls []CohereToolCall             `json:"tool_calls"` // Tool calls made in the message
-	} `json:"chat_history"` // History of the chat conversation
+	ID           string `json:"id"`            // ID of the response
 	FinishReason string `json:"finish_reason"` // Reason for completion termination
-	Meta         struct {
-		APIVersion struct {
-			Version string `json:"version"` // Version of the API used
-		} `json:"api_version"` // API version information
-		BilledUnits struct {
-			InputTokens     float64 `json:"input_tokens"`    // Number of input tokens billed
-			OutputTokens    float64 `json:"output_tokens"`   // Number of output tokens billed
-			Classifications float64 `json:"classifications"` // Number of classifications billed
-			SearchUnits     float64 `json:"search_units"`    // Number of search units billed
-		} `json:"billed_units"` // Token usage billing information
-		Tokens struct {
-			InputTokens  float64 `json:"input_tokens"`  // Number of input tokens used
-			OutputTokens float64 `json:"output_tokens"` // Number of output tokens generated
-		} `json:"tokens"` // Token usage statistics
-	} `json:"meta"` // Metadata about the response
-	ToolCalls []CohereToolCall `json:"tool_calls"` // Tool calls made in the response
+	Message      struct {
+		Role      string               `json:"role"`       // Always "assistant"
+		Content   []CohereContentBlock `json:"content"`    // Generated content
+		ToolPlan  string               `json:"tool_plan"`  // Reasoning of the model about the tools to call
+		ToolCalls []CohereToolCall     `json:"tool_calls"` // Tool calls made in the response
+	} `json:"message"` // Generated message
+	Usage CohereUsage `json:"usage"` // Token usage
 }
 
 // CohereError represents an error response from the Cohere API.
@@ -124,6 +120,20 @@ type CohereEmbeddingResponse struct {
 	} `json:"meta"` // Metadata about the response
 }
 
+// CohereRerankResponse represents the response from Cohere's v2 rerank API.
+type CohereRerankResponse struct {
+	ID      string `json:"id"` // ID of the rerank request
+	Results []struct {
+		Index          int     `json:"index"`           // Index of the document in the request
+		RelevanceScore float64 `json:"relevance_score"` // Relevance of the document to the query
+	} `json:"results"` // Documents ordered by relevance
+	Meta struct {
+		BilledUnits struct {
+			SearchUnits float64 `json:"search_units"` // Number of search units billed
+		} `json:"billed_units"` // Billing information
+	} `json:"meta"` // Metadata about the response
+}
+
 // CohereProvider implements the Provider interface for Cohere.
 type CohereProvider struct {
 	logger               schemas.Logger                // Logger for provider operations
@@ -134,32 +144,22 @@ type CohereProvider struct {
 	customProviderConfig *schemas.CustomProviderConfig // Custom provider config
 }
 
-// CohereStreamStartEvent represents the start of a stream event.
-type CohereStreamStartEvent struct {
-	EventType    string `json:"event_type"`    // stream-start
-	GenerationID string `json:"generation_id"` // ID of the generation
-}
-
-// CohereStreamTextEvent represents the text generation event.
-type CohereStreamTextEvent struct {
-	EventType string `json:"event_type"` // text-generation
-	Text      string `json:"text"`       // Text content being generated
-}
-
-// CohereStreamToolEvent represents the tool use event.
-type CohereStreamToolCallEvent struct {
-	EventType string `json:"event_type"` // tool-use
-	ToolCall  struct {
-		ID         string `json:"id"`         // ID of the tool call
-		Parameters string `json:"parameters"` // Parameters of the tool being called
-	} `json:"tool_call"` // Tool call information
-	Text *string `json:"text"` // Text content being generated
-}
-
-// CohereStreamStopEvent represents the end of a stream event.
-type CohereStreamStopEvent struct {
-	EventType string             `json:"event_type"` // stream-end
-	Response  CohereChatResponse `json:"response"`   // Response information
+// CohereStreamEvent represents an event of a Cohere v2 chat stream. The content and tool
+// calls of the delta are decoded according to the event type, as message-start sends them
+// as arrays and the content and tool call events as objects.
+type CohereStreamEvent struct {
+	Type  string `json:"type"`  // message-start, content-delta, tool-plan-delta, tool-call-start, tool-call-delta, message-end, ...
+	ID    string `json:"id"`    // ID of the response, in message-start
+	Index int    `json:"index"` // Index of the content block or tool call
+	Delta struct {
+		Message struct {
+			Content   json.RawMessage `json:"content"`    // Content block delta
+			ToolPlan  string          `json:"tool_plan"`  // Tool plan delta
+			ToolCalls json.RawMessage `json:"tool_calls"` // Tool call delta
+		} `json:"message"`
+		FinishReason string       `json:"finish_reason"` // Set in message-end
+		Usage        *CohereUsage `json:"usage"`         // Set in message-end
+	} `json:"delta"`
 }
 
 // NewCohereProvider creates a new Cohere provider instance.
@@ -179,6 +179,10 @@ func NewCohereProvider(config *schemas.ProviderConfig, logger schemas.Logger) *C
 		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
 	}
 
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
 	// Pre-warm response pools
 	for i := 0; i < config.ConcurrencyAndBufferSize.Concurrency; i++ {
 		cohereResponsePool.Put(&CohereChatResponse{})
@@ -205,13 +209,18 @@ func (provider *CohereProvider) GetProviderKey() schemas.ModelProvider {
 	return getProviderName(schemas.Cohere, provider.customProviderConfig)
 }
 
+// WarmUp opens connections to the Cohere API ahead of the first request.
+func (provider *CohereProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
 // TextCompletion is not supported by the Cohere provider.
 // Returns an error indicating that text completion is not supported.
 func (provider *CohereProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("text completion", "cohere")
 }
 
-// ChatCompletion performs a chat completion request to the Cohere API.
+// ChatCompletion performs a chat completion request to Cohere's v2 chat API.
 // It formats the request, sends it to Cohere, and processes the response.
 // Returns a BifrostResponse containing the completion results or an error if the request fails.
 func (provider *CohereProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
@@ -223,66 +232,13 @@ func (provider *CohereProvider) ChatCompletion(ctx context.Context, model string
 	providerName := provider.GetProviderKey()
 
 	// Prepare request body using shared function
-	requestBody, err := prepareCohereChatRequest(messages, params, model, false)
-	if err != nil {
-		return nil, &schemas.BifrostError{
-			IsBifrostError: true,
-			Error: schemas.ErrorField{
-				Message: fmt.Sprintf("failed to prepare %s chat request", providerName),
-				Error:   err,
-			},
-		}
-	}
-
-	// Marshal request body
-	jsonBody, err := sonic.Marshal(requestBody)
-	if err != nil {
-		return nil, &schemas.BifrostError{
-			IsBifrostError: true,
-			Error: schemas.ErrorField{
-				Message: schemas.ErrProviderJSONMarshaling,
-				Error:   err,
-			},
-		}
-	}
-
-	// Create request
-	req := fasthttp.AcquireRequest()
-	resp := fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseRequest(req)
-	defer fasthttp.ReleaseResponse(resp)
-
-	// Set any extra headers from network config
-	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
-
-	req.SetRequestURI(provider.networkConfig.BaseURL + "/v1/chat")
-	req.Header.SetMethod("POST")
-	req.Header.SetContentType("application/json")
-	req.Header.Set("Authorization", "Bearer "+key.Value)
+	requestBody := prepareCohereChatRequest(messages, params, model, false)
 
-	req.SetBody(jsonBody)
-
-	// Make request
-	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	responseBody, bifrostErr := provider.completeRequest(ctx, key, "/v2/chat", requestBody)
 	if bifrostErr != nil {
 		return nil, bifrostErr
 	}
 
-	// Handle error response
-	if resp.StatusCode() != fasthttp.StatusOK {
-		provider.logger.Debug(fmt.Sprintf("error from %s provider: %s", providerName, string(resp.Body())))
-
-		var errorResp CohereError
-
-		bifrostErr := handleProviderAPIError(resp, &errorResp)
-		bifrostErr.Error.Message = errorResp.Message
-
-		return nil, bifrostErr
-	}
-
-	// Read response body
-	responseBody := resp.Body()
-
 	// Create response object from pool
 	response := acquireCohereResponse()
 	defer releaseCohereResponse(response)
@@ -292,74 +248,50 @@ func (provider *CohereProvider) ChatCompletion(ctx context.Context, model string
 		return nil, bifrostErr
 	}
 
-	// Transform tool calls if present
-	var toolCalls []schemas.ToolCall
-	if response.ToolCalls != nil {
-		for _, tool := range response.ToolCalls {
-			function := schemas.FunctionCall{
-				Name: &tool.Name,
-			}
-
-			args, err := sonic.Marshal(tool.Parameters)
-			if err != nil {
-				function.Arguments = fmt.Sprintf("%v", tool.Parameters)
-			} else {
-				function.Arguments = string(args)
-			}
-
-			toolCalls = append(toolCalls, schemas.ToolCall{
-				Function: function,
-			})
+	// Join the text blocks of the message
+	var content strings.Builder
+	for _, block := range response.Message.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
 		}
 	}
 
-	// Get role and content from the last message in chat history
-	var role schemas.ModelChatMessageRole
-	var content string
-	if len(response.ChatHistory) > 0 {
-		lastMsg := response.ChatHistory[len(response.ChatHistory)-1]
-		role = lastMsg.Role
-		content = lastMsg.Message
-	} else {
-		role = schemas.ModelChatMessageRoleChatbot
-		content = response.Text
+	assistantMessage := &schemas.AssistantMessage{}
+	if len(response.Message.ToolCalls) > 0 {
+		toolCalls := make([]schemas.ToolCall, 0, len(response.Message.ToolCalls))
+		for _, toolCall := range response.Message.ToolCalls {
+			toolCalls = append(toolCalls, convertCohereToolCall(toolCall))
+		}
+		assistantMessage.ToolCalls = &toolCalls
+	}
+	if response.Message.ToolPlan != "" {
+		assistantMessage.Thought = &response.Message.ToolPlan
 	}
 
 	// Create final response
 	bifrostResponse := &schemas.BifrostResponse{
-		ID: response.GenerationID,
+		ID:     response.ID,
+		Object: "chat.completion",
 		Choices: []schemas.BifrostResponseChoice{
 			{
 				Index: 0,
 				BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
 					Message: schemas.BifrostMessage{
-						Role: role,
+						Role: schemas.ModelChatMessageRoleAssistant,
 						Content: schemas.MessageContent{
-							ContentStr: &content,
-						},
-						AssistantMessage: &schemas.AssistantMessage{
-							ToolCalls: &toolCalls,
+							ContentStr: Ptr(content.String()),
 						},
+						AssistantMessage: assistantMessage,
 					},
 				},
-				FinishReason: &response.FinishReason,
+				FinishReason: Ptr(mapCohereFinishReason(response.FinishReason)),
 			},
 		},
-		Usage: &schemas.LLMUsage{
-			PromptTokens:     int(response.Meta.Tokens.InputTokens),
-			CompletionTokens: int(response.Meta.Tokens.OutputTokens),
-			TotalTokens:      int(response.Meta.Tokens.InputTokens + response.Meta.Tokens.OutputTokens),
-		},
+		Usage: convertCohereUsage(&response.Usage),
 		Model: model,
 		ExtraFields: schemas.BifrostResponseExtraFields{
-			Provider: providerName,
-			BilledUsage: &schemas.BilledLLMUsage{
-				PromptTokens:     Ptr(response.Meta.BilledUnits.InputTokens),
-				CompletionTokens: Ptr(response.Meta.BilledUnits.OutputTokens),
-				Classifications:  Ptr(response.Meta.BilledUnits.Classifications),
-				SearchUnits:      Ptr(response.Meta.BilledUnits.SearchUnits),
-			},
-			ChatHistory: convertChatHistory(response.ChatHistory),
+			Provider:    providerName,
+			BilledUsage: convertCohereBilledUsage(&response.Usage),
 		},
 	}
 
@@ -374,134 +306,93 @@ func (provider *CohereProvider) ChatCompletion(ctx context.Context, model string
 	return bifrostResponse, nil
 }
 
-// prepareCohereChatRequest prepares the request body for Cohere chat completion requests.
-// It transforms the messages into Cohere format and handles tools, parameters, and content formatting.
-func prepareCohereChatRequest(messages []schemas.BifrostMessage, params *schemas.ModelParameters, model string, stream bool) (map[string]interface{}, error) {
-	// Get the last message and chat history
-	lastMessage := messages[len(messages)-1]
-	chatHistory := messages[:len(messages)-1]
-
-	// Transform chat history
-	var cohereHistory []map[string]interface{}
-	for _, msg := range chatHistory {
-		historyMsg := map[string]interface{}{
-			"role": msg.Role,
-		}
+// completeRequest sends a JSON request to a path of the Cohere API and returns the body of a
+// successful response.
+func (provider *CohereProvider) completeRequest(ctx context.Context, key schemas.Key, path string, requestBody map[string]interface{}) ([]byte, *schemas.BifrostError) {
+	providerName := provider.GetProviderKey()
 
-		if msg.Role == schemas.ModelChatMessageRoleAssistant {
-			if msg.AssistantMessage != nil && msg.AssistantMessage.ToolCalls != nil {
-				var toolCalls []map[string]interface{}
-				for _, toolCall := range *msg.AssistantMessage.ToolCalls {
-					var arguments map[string]interface{}
-					var parsedJSON interface{}
-					err := sonic.Unmarshal([]byte(toolCall.Function.Arguments), &parsedJSON)
-					if err == nil {
-						if arr, ok := parsedJSON.(map[string]interface{}); ok {
-							arguments = arr
-						} else {
-							arguments = map[string]interface{}{"content": parsedJSON}
-						}
-					} else {
-						arguments = map[string]interface{}{"content": toolCall.Function.Arguments}
-					}
+	// Marshal request body
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
+	}
 
-					toolCalls = append(toolCalls, map[string]interface{}{
-						"name":       toolCall.Function.Name,
-						"parameters": arguments,
-					})
-				}
-				historyMsg["tool_calls"] = toolCalls
-			}
-		} else if msg.Role == schemas.ModelChatMessageRoleTool {
-			// Find the original tool call parameters from conversation history
-			var toolCallParameters map[string]interface{}
-
-			// Look back through the chat history to find the assistant message with the matching tool call
-			for i := len(chatHistory) - 1; i >= 0; i-- {
-				prevMsg := chatHistory[i]
-				if prevMsg.Role == schemas.ModelChatMessageRoleAssistant &&
-					prevMsg.AssistantMessage != nil &&
-					prevMsg.AssistantMessage.ToolCalls != nil {
-
-					// Search through tool calls in this assistant message
-					for _, toolCall := range *prevMsg.AssistantMessage.ToolCalls {
-						if toolCall.ID != nil && msg.ToolMessage != nil && msg.ToolMessage.ToolCallID != nil &&
-							*toolCall.ID == *msg.ToolMessage.ToolCallID {
-
-							// Found the matching tool call, extract its parameters
-							var parsedJSON interface{}
-							err := sonic.Unmarshal([]byte(toolCall.Function.Arguments), &parsedJSON)
-							if err == nil {
-								if arr, ok := parsedJSON.(map[string]interface{}); ok {
-									toolCallParameters = arr
-								} else {
-									toolCallParameters = map[string]interface{}{"content": parsedJSON}
-								}
-							} else {
-								toolCallParameters = map[string]interface{}{"content": toolCall.Function.Arguments}
-							}
-							break
-						}
-					}
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
 
-					// If we found the parameters, stop searching
-					if toolCallParameters != nil {
-						break
-					}
-				}
-			}
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
 
-			// If no parameters found, use empty map as fallback
-			if toolCallParameters == nil {
-				toolCallParameters = map[string]interface{}{}
-			}
+	req.SetRequestURI(provider.networkConfig.BaseURL + path)
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
 
-			toolResults := []map[string]interface{}{
-				{
-					"call": map[string]interface{}{
-						"name":       *msg.ToolMessage.ToolCallID,
-						"parameters": toolCallParameters,
-					},
-					"outputs": *msg.Content.ContentStr,
-				},
-			}
+	req.SetBody(jsonBody)
 
-			historyMsg["tool_results"] = toolResults
-		}
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
 
-		if msg.Content.ContentStr != nil {
-			historyMsg["message"] = *msg.Content.ContentStr
-		} else if msg.Content.ContentBlocks != nil {
-			// Create content array with text and image
-			contentArray := []map[string]interface{}{}
-
-			// Iterate over ContentBlocks to build the content array
-			for _, block := range *msg.Content.ContentBlocks {
-				if block.Text != nil {
-					contentArray = append(contentArray, map[string]interface{}{
-						"type": "text",
-						"text": *block.Text,
-					})
-				}
-				// Add image content using our helper function
-				// NOTE: Cohere v1 does not support image content
-				// if processedImageContent := processImageContent(block.ImageContent); processedImageContent != nil {
-				// 	contentArray = append(contentArray, processedImageContent)
-				// }
-			}
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from %s provider: %s", providerName, string(resp.Body())))
 
-			historyMsg["content"] = contentArray
-		}
+		var errorResp CohereError
+
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		bifrostErr.Error.Message = errorResp.Message
 
-		cohereHistory = append(cohereHistory, historyMsg)
+		return nil, bifrostErr
+	}
+
+	// Copy the body, the response is released on return
+	return append([]byte(nil), resp.Body()...), nil
+}
+
+// cohereChatParamNames maps Bifrost parameter names to their names in Cohere's v2 chat API.
+var cohereChatParamNames = map[string]string{
+	"top_p": "p",
+	"top_k": "k",
+}
+
+// cohereUnsupportedChatParams are the Bifrost parameters Cohere's v2 chat API rejects.
+var cohereUnsupportedChatParams = []string{"user", "parallel_tool_calls", "encoding_format", "dimensions"}
+
+// prepareCohereChatRequest prepares the request body for Cohere v2 chat requests.
+// It transforms the messages into Cohere format and handles tools, parameters, and content formatting.
+func prepareCohereChatRequest(messages []schemas.BifrostMessage, params *schemas.ModelParameters, model string, stream bool) map[string]interface{} {
+	cohereMessages := make([]map[string]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		cohereMessages = append(cohereMessages, convertMessageToCohere(msg))
 	}
 
 	preparedParams := prepareParams(params)
+	for name, cohereName := range cohereChatParamNames {
+		if value, ok := preparedParams[name]; ok {
+			delete(preparedParams, name)
+			preparedParams[cohereName] = value
+		}
+	}
+	for _, name := range cohereUnsupportedChatParams {
+		delete(preparedParams, name)
+	}
+	// Tools and tool choice are converted below, unless passed as is through ExtraParams
+	for _, name := range []string{"tools", "tool_choice"} {
+		if params == nil || params.ExtraParams[name] == nil {
+			delete(preparedParams, name)
+		}
+	}
 
 	// Prepare request body
 	requestBody := mergeConfig(map[string]interface{}{
-		"chat_history": cohereHistory,
-		"model":        model,
+		"messages": cohereMessages,
+		"model":    model,
 	}, preparedParams)
 
 	// Add stream parameter if streaming
@@ -509,119 +400,173 @@ func prepareCohereChatRequest(messages []schemas.BifrostMessage, params *schemas
 		requestBody["stream"] = true
 	}
 
-	if lastMessage.Content.ContentStr != nil {
-		requestBody["message"] = *lastMessage.Content.ContentStr
-	} else if lastMessage.Content.ContentBlocks != nil {
-		message := ""
-		for _, block := range *lastMessage.Content.ContentBlocks {
-			if block.Text != nil {
-				message += *block.Text + "\n"
+	if params == nil {
+		return requestBody
+	}
+
+	// Cohere cannot force a specific function, so the tools are narrowed down to it and a
+	// tool call is required
+	var forcedFunction string
+	if params.ToolChoice != nil {
+		if params.ToolChoice.ToolChoiceStr != nil {
+			switch schemas.ToolChoiceType(*params.ToolChoice.ToolChoiceStr) {
+			case schemas.ToolChoiceTypeNone:
+				requestBody["tool_choice"] = "NONE"
+			case schemas.ToolChoiceTypeRequired, schemas.ToolChoiceTypeAny:
+				requestBody["tool_choice"] = "REQUIRED"
+			}
+		} else if params.ToolChoice.ToolChoiceStruct != nil {
+			switch params.ToolChoice.ToolChoiceStruct.Type {
+			case schemas.ToolChoiceTypeNone:
+				requestBody["tool_choice"] = "NONE"
+			case schemas.ToolChoiceTypeRequired, schemas.ToolChoiceTypeAny:
+				requestBody["tool_choice"] = "REQUIRED"
+			case schemas.ToolChoiceTypeFunction:
+				requestBody["tool_choice"] = "REQUIRED"
+				forcedFunction = params.ToolChoice.ToolChoiceStruct.Function.Name
 			}
 		}
-		requestBody["message"] = strings.TrimSuffix(message, "\n")
 	}
 
-	// Add tools if present
-	if params != nil && params.Tools != nil && len(*params.Tools) > 0 {
+	// Add tools if present, Cohere only supports function tools
+	if params.Tools != nil && len(*params.Tools) > 0 {
 		var tools []CohereTool
 		for _, tool := range *params.Tools {
-			parameterDefinitions := make(map[string]CohereParameterDefinition)
-			params := tool.Function.Parameters
-			for name, prop := range tool.Function.Parameters.Properties {
-				propMap, ok := prop.(map[string]interface{})
-				if ok {
-					paramDef := CohereParameterDefinition{
-						Required: slices.Contains(params.Required, name),
-					}
+			if tool.Type != "" && tool.Type != "function" {
+				continue
+			}
+			if forcedFunction != "" && tool.Function.Name != forcedFunction {
+				continue
+			}
+			tools = append(tools, CohereTool{
+				Type:     "function",
+				Function: tool.Function,
+			})
+		}
+		if len(tools) > 0 {
+			requestBody["tools"] = tools
+		}
+	}
 
-					if typeStr, ok := propMap["type"].(string); ok {
-						paramDef.Type = typeStr
-					}
+	return requestBody
+}
 
-					if desc, ok := propMap["description"].(string); ok {
-						paramDef.Description = &desc
-					}
+// convertMessageToCohere converts a Bifrost message into a Cohere v2 chat message.
+func convertMessageToCohere(msg schemas.BifrostMessage) map[string]interface{} {
+	role := msg.Role
+	if role == schemas.ModelChatMessageRoleChatbot {
+		role = schemas.ModelChatMessageRoleAssistant
+	}
+	cohereMessage := map[string]interface{}{
+		"role": role,
+	}
 
-					parameterDefinitions[name] = paramDef
+	if msg.Content.ContentStr != nil {
+		cohereMessage["content"] = *msg.Content.ContentStr
+	} else if msg.Content.ContentBlocks != nil {
+		var contentArray []map[string]interface{}
+		for _, block := range *msg.Content.ContentBlocks {
+			if block.Text != nil {
+				contentArray = append(contentArray, map[string]interface{}{
+					"type": "text",
+					"text": *block.Text,
+				})
+			} else if block.ImageURL != nil && role == schemas.ModelChatMessageRoleUser {
+				// Images are only accepted in user messages, by vision models
+				sanitizedURL, err := SanitizeImageURL(block.ImageURL.URL)
+				if err != nil {
+					continue
 				}
+				contentArray = append(contentArray, map[string]interface{}{
+					"type":      "image_url",
+					"image_url": map[string]interface{}{"url": sanitizedURL},
+				})
 			}
-
-			tools = append(tools, CohereTool{
-				Name:                 tool.Function.Name,
-				Description:          tool.Function.Description,
-				ParameterDefinitions: parameterDefinitions,
-			})
 		}
-		requestBody["tools"] = tools
+		cohereMessage["content"] = contentArray
 	}
 
-	// Add tool choice if present
-	if params != nil && params.ToolChoice != nil {
-		if params.ToolChoice.ToolChoiceStr != nil {
-			requestBody["tool_choice"] = *params.ToolChoice.ToolChoiceStr
-		} else if params.ToolChoice.ToolChoiceStruct != nil {
-			requestBody["tool_choice"] = map[string]interface{}{
-				"type": strings.ToUpper(string(params.ToolChoice.ToolChoiceStruct.Type)),
+	switch role {
+	case schemas.ModelChatMessageRoleAssistant:
+		if msg.AssistantMessage == nil {
+			break
+		}
+		if msg.AssistantMessage.ToolCalls != nil && len(*msg.AssistantMessage.ToolCalls) > 0 {
+			var toolCalls []CohereToolCall
+			for _, toolCall := range *msg.AssistantMessage.ToolCalls {
+				cohereToolCall := CohereToolCall{Type: "function"}
+				if toolCall.ID != nil {
+					cohereToolCall.ID = *toolCall.ID
+				}
+				if toolCall.Function.Name != nil {
+					cohereToolCall.Function.Name = *toolCall.Function.Name
+				}
+				cohereToolCall.Function.Arguments = toolCall.Function.Arguments
+				toolCalls = append(toolCalls, cohereToolCall)
 			}
+			cohereMessage["tool_calls"] = toolCalls
+		}
+		if msg.AssistantMessage.Thought != nil {
+			cohereMessage["tool_plan"] = *msg.AssistantMessage.Thought
+		}
+	case schemas.ModelChatMessageRoleTool:
+		if msg.ToolMessage != nil && msg.ToolMessage.ToolCallID != nil {
+			cohereMessage["tool_call_id"] = *msg.ToolMessage.ToolCallID
 		}
 	}
 
-	return requestBody, nil
+	return cohereMessage
 }
 
-// processImageContent processes image content for Cohere API format.
-// NOTE: Cohere v1 does not support image content, so this function is a placeholder.
-// It returns nil since image processing is not available.
-func processImageContent(imageContent *schemas.ImageURLStruct) map[string]interface{} {
-	if imageContent == nil {
-		return nil
+// convertCohereToolCall converts a Cohere v2 tool call into a Bifrost tool call.
+func convertCohereToolCall(toolCall CohereToolCall) schemas.ToolCall {
+	converted := schemas.ToolCall{
+		Type: Ptr("function"),
+		Function: schemas.FunctionCall{
+			Arguments: toolCall.Function.Arguments,
+		},
 	}
-
-	// Cohere v1 does not support image content
-	// Return nil to skip image processing
-	return nil
+	if toolCall.ID != "" {
+		converted.ID = Ptr(toolCall.ID)
+	}
+	if toolCall.Function.Name != "" {
+		converted.Function.Name = Ptr(toolCall.Function.Name)
+	}
+	return converted
 }
 
-// convertChatHistory converts Cohere's chat history format to Bifrost's format for standardization.
-// It transforms the chat history messages and their tool calls.
-func convertChatHistory(history []struct {
-	Role      schemas.ModelChatMessageRole `json:"role"`
-	Message   string                       `json:"message"`
-	ToolCalls []CohereToolCall             `json:"tool_calls"`
-}) *[]schemas.BifrostMessage {
-	converted := make([]schemas.BifrostMessage, len(history))
-	for i, msg := range history {
-		var toolCalls []schemas.ToolCall
-		if msg.ToolCalls != nil {
-			for _, tool := range msg.ToolCalls {
-				function := schemas.FunctionCall{
-					Name: &tool.Name,
-				}
-
-				args, err := sonic.Marshal(tool.Parameters)
-				if err != nil {
-					function.Arguments = fmt.Sprintf("%v", tool.Parameters)
-				} else {
-					function.Arguments = string(args)
-				}
+// mapCohereFinishReason maps Cohere finish reasons to OpenAI-compatible ones
+func mapCohereFinishReason(cohereReason string) string {
+	switch cohereReason {
+	case "COMPLETE", "STOP_SEQUENCE":
+		return "stop"
+	case "MAX_TOKENS":
+		return "length"
+	case "TOOL_CALL":
+		return "tool_calls"
+	default:
+		// Pass through Cohere-specific reasons like "ERROR" and "TIMEOUT"
+		return strings.ToLower(cohereReason)
+	}
+}
 
-				toolCalls = append(toolCalls, schemas.ToolCall{
-					Function: function,
-				})
-			}
-		}
+// convertCohereUsage converts Cohere token usage into Bifrost usage.
+func convertCohereUsage(usage *CohereUsage) *schemas.LLMUsage {
+	return &schemas.LLMUsage{
+		PromptTokens:     int(usage.Tokens.InputTokens),
+		CompletionTokens: int(usage.Tokens.OutputTokens),
+		TotalTokens:      int(usage.Tokens.InputTokens + usage.Tokens.OutputTokens),
+	}
+}
 
-		converted[i] = schemas.BifrostMessage{
-			Role: msg.Role,
-			Content: schemas.MessageContent{
-				ContentStr: &msg.Message,
-			},
-			AssistantMessage: &schemas.AssistantMessage{
-				ToolCalls: &toolCalls,
-			},
-		}
+// convertCohereBilledUsage converts the billed units of Cohere usage into Bifrost billed usage.
+func convertCohereBilledUsage(usage *CohereUsage) *schemas.BilledLLMUsage {
+	return &schemas.BilledLLMUsage{
+		PromptTokens:     Ptr(usage.BilledUnits.InputTokens),
+		CompletionTokens: Ptr(usage.BilledUnits.OutputTokens),
+		Classifications:  Ptr(usage.BilledUnits.Classifications),
+		SearchUnits:      Ptr(usage.BilledUnits.SearchUnits),
 	}
-	return &converted
 }
 
 // Embedding generates embeddings for the given input text(s) using the Cohere API.
@@ -764,7 +709,7 @@ func handleCohereEmbeddingResponse(cohereResp CohereEmbeddingResponse, model str
 
 }
 
-// ChatCompletionStream performs a streaming chat completion request to the Cohere API.
+// ChatCompletionStream performs a streaming chat completion request to Cohere's v2 chat API.
 // It supports real-time streaming of responses using Server-Sent Events (SSE).
 // Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
 func (provider *CohereProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
@@ -776,18 +721,19 @@ func (provider *CohereProvider) ChatCompletionStream(ctx context.Context, postHo
 	providerName := provider.GetProviderKey()
 
 	// Prepare request body using shared function
-	requestBody, err := prepareCohereChatRequest(messages, params, model, true)
-	if err != nil {
-		return nil, newBifrostOperationError("failed to prepare chat request", err, providerName)
-	}
+	requestBody := prepareCohereChatRequest(messages, params, model, true)
 
 	jsonBody, err := sonic.Marshal(requestBody)
 	if err != nil {
 		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
 	}
 
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
 	// Create HTTP request for streaming
-	req, err := http.NewRequestWithContext(ctx, "POST", provider.networkConfig.BaseURL+"/v1/chat", bytes.NewReader(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", provider.networkConfig.BaseURL+"/v2/chat", bytes.NewReader(jsonBody))
 	if err != nil {
 		return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, providerName)
 	}
@@ -806,6 +752,7 @@ func (provider *CohereProvider) ChatCompletionStream(ctx context.Context, postHo
 	if err != nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         requestErrorOrigin(err),
 			Error: schemas.ErrorField{
 				Message: schemas.ErrProviderRequest,
 				Error:   err,
@@ -828,209 +775,111 @@ func (provider *CohereProvider) ChatCompletionStream(ctx context.Context, postHo
 	// Start streaming in a goroutine
 	go func() {
 		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, provider.logger)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
+		scanner := newStreamScanner(resp.Body)
 		var responseID string
 
+		// newChunk creates a chat completion chunk with the given delta
+		newChunk := func(delta schemas.BifrostStreamDelta) *schemas.BifrostResponse {
+			return &schemas.BifrostResponse{
+				ID:     responseID,
+				Object: "chat.completion.chunk",
+				Model:  model,
+				Choices: []schemas.BifrostResponseChoice{
+					{
+						Index: 0,
+						BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+							Delta: delta,
+						},
+					},
+				},
+				ExtraFields: schemas.BifrostResponseExtraFields{
+					Provider:   providerName,
+					ChunkIndex: chunkIndex,
+				},
+			}
+		}
+
 		for scanner.Scan() {
 			line := scanner.Text()
 
-			// Skip empty lines and comments
-			if line == "" || strings.HasPrefix(line, ":") {
+			// Skip empty lines, comments and event names, the event type is also in the data
+			if !strings.HasPrefix(line, "data:") {
 				continue
 			}
 
-			// Parse SSE data
-			if strings.HasPrefix(line, "data: ") {
-				jsonData := strings.TrimPrefix(line, "data: ")
-
+			objects, _ := splitStreamDataObjects(line)
+			for _, jsonData := range objects {
 				// Parse the streaming event
-				var streamEvent map[string]interface{}
-				if err := sonic.Unmarshal([]byte(jsonData), &streamEvent); err != nil {
+				var event CohereStreamEvent
+				if err := sonic.Unmarshal([]byte(jsonData), &event); err != nil {
 					provider.logger.Warn(fmt.Sprintf("Failed to parse stream event: %v", err))
 					continue
 				}
 
-				eventType, exists := streamEvent["event_type"].(string)
-				if !exists {
-					continue
-				}
-
-				chunkIndex++
-
-				switch eventType {
-				case "stream-start":
-					var startEvent CohereStreamStartEvent
-					if err := sonic.Unmarshal([]byte(jsonData), &startEvent); err != nil {
-						provider.logger.Warn(fmt.Sprintf("Failed to parse stream-start event: %v", err))
-						continue
-					}
-
-					responseID = startEvent.GenerationID
+				switch event.Type {
+				case "message-start":
+					responseID = event.ID
+					chunkIndex++
 
 					// Send empty message to signal stream start
-					streamResponse := &schemas.BifrostResponse{
-						ID:     responseID,
-						Object: "chat.completion.chunk",
-						Model:  model,
-						Choices: []schemas.BifrostResponseChoice{
-							{
-								Index: 0,
-
-								BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
-									Delta: schemas.BifrostStreamDelta{
-										Role: Ptr(string(schemas.ModelChatMessageRoleAssistant)),
-									},
-								},
-							},
-						},
-						ExtraFields: schemas.BifrostResponseExtraFields{
-							Provider:   providerName,
-							ChunkIndex: chunkIndex,
-						},
-					}
-
-					// Use utility function to process and send response
-					processAndSendResponse(ctx, postHookRunner, streamResponse, responseChan, provider.logger)
-
-				case "text-generation":
-					var textEvent CohereStreamTextEvent
-					if err := sonic.Unmarshal([]byte(jsonData), &textEvent); err != nil {
-						provider.logger.Warn(fmt.Sprintf("Failed to parse text-generation event: %v", err))
+					processAndSendResponse(ctx, postHookRunner, newChunk(schemas.BifrostStreamDelta{
+						Role: Ptr(string(schemas.ModelChatMessageRoleAssistant)),
+					}), responseChan, provider.logger)
+
+				case "content-delta":
+					var content CohereContentBlock
+					if err := sonic.Unmarshal(event.Delta.Message.Content, &content); err != nil {
+						provider.logger.Warn(fmt.Sprintf("Failed to parse content-delta event: %v", err))
 						continue
 					}
+					chunkIndex++
 
-					// Create response for this text chunk
-					response := &schemas.BifrostResponse{
-						ID:     responseID,
-						Object: "chat.completion.chunk",
-						Choices: []schemas.BifrostResponseChoice{
-							{
-								Index: 0,
-								BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
-									Delta: schemas.BifrostStreamDelta{
-										Content: &textEvent.Text,
-									},
-								},
-								FinishReason: nil, // Not finished yet
-							},
-						},
-						Model: model,
-						ExtraFields: schemas.BifrostResponseExtraFields{
-							Provider:   providerName,
-							ChunkIndex: chunkIndex,
-						},
-					}
+					processAndSendResponse(ctx, postHookRunner, newChunk(schemas.BifrostStreamDelta{
+						Content: &content.Text,
+					}), responseChan, provider.logger)
 
-					// Use utility function to process and send response
-					processAndSendResponse(ctx, postHookRunner, response, responseChan, provider.logger)
+				case "tool-plan-delta":
+					chunkIndex++
 
-				case "tool-calls-chunk":
-					var toolEvent CohereStreamToolCallEvent
-					if err := sonic.Unmarshal([]byte(jsonData), &toolEvent); err != nil {
-						provider.logger.Warn(fmt.Sprintf("Failed to parse tool-use event: %v", err))
-						continue
-					}
+					processAndSendResponse(ctx, postHookRunner, newChunk(schemas.BifrostStreamDelta{
+						Thought: &event.Delta.Message.ToolPlan,
+					}), responseChan, provider.logger)
 
-					toolCall := schemas.ToolCall{
-						ID: &toolEvent.ToolCall.ID,
-						Function: schemas.FunctionCall{
-							Name:      &toolEvent.ToolCall.ID,
-							Arguments: toolEvent.ToolCall.Parameters,
-						},
-					}
-
-					// Create response for tool calls
-					response := &schemas.BifrostResponse{
-						ID:     responseID,
-						Object: "chat.completion.chunk",
-						Choices: []schemas.BifrostResponseChoice{
-							{
-								Index: 0,
-								BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
-									Delta: schemas.BifrostStreamDelta{
-										ToolCalls: []schemas.ToolCall{toolCall},
-										Content:   toolEvent.Text,
-									},
-								},
-								FinishReason: nil,
-							},
-						},
-						Model: model,
-						ExtraFields: schemas.BifrostResponseExtraFields{
-							Provider:   providerName,
-							ChunkIndex: chunkIndex,
-						},
-					}
-
-					// Use utility function to process and send response
-					processAndSendResponse(ctx, postHookRunner, response, responseChan, provider.logger)
-
-				case "stream-end":
-					var stopEvent CohereStreamStopEvent
-					if err := sonic.Unmarshal([]byte(jsonData), &stopEvent); err != nil {
-						provider.logger.Warn(fmt.Sprintf("Failed to parse stream-end event: %v", err))
+				case "tool-call-start", "tool-call-delta":
+					// The start of a tool call carries its ID and name, the deltas continue its arguments
+					var toolCall CohereToolCall
+					if err := sonic.Unmarshal(event.Delta.Message.ToolCalls, &toolCall); err != nil {
+						provider.logger.Warn(fmt.Sprintf("Failed to parse %s event: %v", event.Type, err))
 						continue
 					}
+					chunkIndex++
 
-					// Convert tool calls from the final response
-					var toolCalls []schemas.ToolCall
-					for _, toolCall := range stopEvent.Response.ToolCalls {
-						function := schemas.FunctionCall{
-							Name: &toolCall.Name,
-						}
-
-						args, err := sonic.Marshal(toolCall.Parameters)
-						if err != nil {
-							function.Arguments = fmt.Sprintf("%v", toolCall.Parameters)
-						} else {
-							function.Arguments = string(args)
-						}
-
-						toolCalls = append(toolCalls, schemas.ToolCall{
-							Function: function,
-						})
-					}
+					processAndSendResponse(ctx, postHookRunner, newChunk(schemas.BifrostStreamDelta{
+						ToolCalls: []schemas.ToolCall{convertCohereToolCall(toolCall)},
+					}), responseChan, provider.logger)
 
-					// Send final response with complete content from the stopEvent
-					response := &schemas.BifrostResponse{
-						ID:     responseID,
-						Object: "chat.completion.chunk",
-						Choices: []schemas.BifrostResponseChoice{
-							{
-								Index: 0,
-								BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
-									Delta: schemas.BifrostStreamDelta{
-										Role:      Ptr(string(schemas.ModelChatMessageRoleAssistant)),
-										Content:   &stopEvent.Response.Text,
-										ToolCalls: toolCalls,
-									},
-								},
-								FinishReason: &stopEvent.Response.FinishReason,
-							},
-						},
-						Model: model,
-						ExtraFields: schemas.BifrostResponseExtraFields{
-							Provider:   providerName,
-							ChunkIndex: chunkIndex,
-						},
+				case "message-end":
+					var usage *schemas.LLMUsage
+					if event.Delta.Usage != nil {
+						usage = convertCohereUsage(event.Delta.Usage)
 					}
+					finishReason := mapCohereFinishReason(event.Delta.FinishReason)
 
-					if params != nil {
-						response.ExtraFields.Params = *params
+					response := createBifrostChatCompletionChunkResponse(responseID, usage, &finishReason, chunkIndex, params, providerName)
+					response.Model = model
+					if event.Delta.Usage != nil {
+						response.ExtraFields.BilledUsage = convertCohereBilledUsage(event.Delta.Usage)
 					}
 
-					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
-
-					// Use utility function to process and send response
-					processAndSendResponse(ctx, postHookRunner, response, responseChan, provider.logger)
-
+					handleStreamEndWithSuccess(ctx, response, postHookRunner, responseChan, provider.logger)
 					return // End of stream
 
 				default:
-					// Unknown event type, log and continue
-					provider.logger.Debug(fmt.Sprintf("Unknown stream event type: %s", eventType))
+					// Content and tool call boundaries and citations carry nothing to forward
+					provider.logger.Debug(fmt.Sprintf("Skipping stream event type: %s", event.Type))
 				}
 			}
 		}
@@ -1059,3 +908,95 @@ func (provider *CohereProvider) Transcription(ctx context.Context, model string,
 func (provider *CohereProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("transcription stream", "cohere")
 }
+
+func (provider *CohereProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "cohere")
+}
+
+func (provider *CohereProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "cohere")
+}
+
+func (provider *CohereProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "cohere")
+}
+
+func (provider *CohereProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "cohere")
+}
+
+func (provider *CohereProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "cohere")
+}
+
+// Rerank orders documents by their relevance to a query with Cohere's v2 rerank API, e.g. with
+// "rerank-v3.5". Options such as max_tokens_per_doc are passed through ModelParameters.ExtraParams.
+// The API does not return the documents, they are filled in from the input when requested.
+func (provider *CohereProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	// Check if rerank is allowed
+	if err := checkOperationAllowed(schemas.Cohere, provider.customProviderConfig, schemas.OperationRerank); err != nil {
+		return nil, err
+	}
+
+	providerName := provider.GetProviderKey()
+
+	requestBody := map[string]interface{}{
+		"model":     model,
+		"query":     input.Query,
+		"documents": input.Documents,
+	}
+	if input.TopN != nil {
+		requestBody["top_n"] = *input.TopN
+	}
+	if params != nil {
+		for k, v := range params.ExtraParams {
+			requestBody[k] = v
+		}
+	}
+
+	responseBody, bifrostErr := provider.completeRequest(ctx, key, "/v2/rerank", requestBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var cohereResp CohereRerankResponse
+	rawResponse, bifrostErr := handleProviderResponse(responseBody, &cohereResp, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	results := make([]schemas.BifrostRerankResult, 0, len(cohereResp.Results))
+	for _, result := range cohereResp.Results {
+		rerankResult := schemas.BifrostRerankResult{
+			Index:          result.Index,
+			RelevanceScore: result.RelevanceScore,
+		}
+		if input.ReturnDocuments != nil && *input.ReturnDocuments && result.Index >= 0 && result.Index < len(input.Documents) {
+			rerankResult.Document = &input.Documents[result.Index]
+		}
+		results = append(results, rerankResult)
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		ID:     cohereResp.ID,
+		Object: "rerank",
+		Rerank: results,
+		Model:  model,
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: providerName,
+			BilledUsage: &schemas.BilledLLMUsage{
+				SearchUnits: Ptr(cohereResp.Meta.BilledUnits.SearchUnits),
+			},
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
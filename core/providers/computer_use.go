@@ -0,0 +1,165 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the shared helpers for computer use tools.
+package providers
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	// anthropicComputerUseToolName is the fixed tool name Anthropic uses for computer use.
+	anthropicComputerUseToolName = "computer"
+	// anthropicDefaultComputerUseVersion is used when ComputerUseTool.Version is not set.
+	anthropicDefaultComputerUseVersion = "computer_20250124"
+)
+
+// anthropicComputerUseBetas maps Anthropic computer use tool versions to the beta flag they require.
+var anthropicComputerUseBetas = map[string]string{
+	"computer_20241022": "computer-use-2024-10-22",
+	"computer_20250124": "computer-use-2025-01-24",
+}
+
+// anthropicComputerUseTool returns the Anthropic tool definition for a computer use tool.
+func anthropicComputerUseTool(tool schemas.Tool) map[string]interface{} {
+	version := anthropicDefaultComputerUseVersion
+	if tool.ComputerUse.Version != nil && *tool.ComputerUse.Version != "" {
+		version = *tool.ComputerUse.Version
+	}
+
+	anthropicTool := map[string]interface{}{
+		"type":              version,
+		"name":              anthropicComputerUseToolName,
+		"display_width_px":  tool.ComputerUse.DisplayWidth,
+		"display_height_px": tool.ComputerUse.DisplayHeight,
+	}
+	if tool.ComputerUse.DisplayNumber != nil {
+		anthropicTool["display_number"] = *tool.ComputerUse.DisplayNumber
+	}
+
+	return anthropicTool
+}
+
+// getAnthropicBetaHeader returns the anthropic-beta header value required by the tools in params, if any.
+func getAnthropicBetaHeader(params *schemas.ModelParameters) string {
+	if params == nil || params.Tools == nil {
+		return ""
+	}
+
+	var betas []string
+	for _, tool := range *params.Tools {
+		if tool.ComputerUse == nil {
+			continue
+		}
+		version := anthropicDefaultComputerUseVersion
+		if tool.ComputerUse.Version != nil && *tool.ComputerUse.Version != "" {
+			version = *tool.ComputerUse.Version
+		}
+		if beta, ok := anthropicComputerUseBetas[version]; ok && !slices.Contains(betas, beta) {
+			betas = append(betas, beta)
+		}
+	}
+
+	return strings.Join(betas, ",")
+}
+
+// parseAnthropicComputerAction converts the input of an Anthropic computer tool_use block
+// into a normalized ComputerAction. Returns nil if the input is not a computer action.
+func parseAnthropicComputerAction(input map[string]interface{}) *schemas.ComputerAction {
+	actionName, ok := input["action"].(string)
+	if !ok {
+		return nil
+	}
+
+	action := &schemas.ComputerAction{Raw: input}
+	x, y := computerCoordinate(input["coordinate"])
+
+	switch actionName {
+	case "screenshot":
+		action.Type = schemas.ComputerActionScreenshot
+	case "left_click", "right_click", "middle_click":
+		action.Type = schemas.ComputerActionClick
+		action.Button = Ptr(strings.TrimSuffix(actionName, "_click"))
+	case "double_click":
+		action.Type = schemas.ComputerActionDoubleClick
+	case "triple_click":
+		action.Type = schemas.ComputerActionTripleClick
+	case "mouse_move":
+		action.Type = schemas.ComputerActionMove
+	case "left_click_drag":
+		action.Type = schemas.ComputerActionDrag
+		if startX, startY := computerCoordinate(input["start_coordinate"]); startX != nil && startY != nil {
+			action.Path = append(action.Path, schemas.ComputerCoordinate{X: *startX, Y: *startY})
+		}
+		if x != nil && y != nil {
+			action.Path = append(action.Path, schemas.ComputerCoordinate{X: *x, Y: *y})
+		}
+		x, y = nil, nil
+	case "scroll":
+		action.Type = schemas.ComputerActionScroll
+		amount := 1
+		if n, ok := input["scroll_amount"].(float64); ok {
+			amount = int(n)
+		}
+		switch input["scroll_direction"] {
+		case "up":
+			action.ScrollY = Ptr(-amount)
+		case "down":
+			action.ScrollY = Ptr(amount)
+		case "left":
+			action.ScrollX = Ptr(-amount)
+		case "right":
+			action.ScrollX = Ptr(amount)
+		}
+	case "type":
+		action.Type = schemas.ComputerActionTypeText
+	case "key", "hold_key":
+		action.Type = schemas.ComputerActionKeypress
+		if text, ok := input["text"].(string); ok {
+			action.Keys = strings.Split(text, "+")
+		}
+	case "wait":
+		action.Type = schemas.ComputerActionWait
+	case "cursor_position":
+		action.Type = schemas.ComputerActionCursor
+	default:
+		// Keep unknown actions so executors can still inspect Raw
+		action.Type = schemas.ComputerActionType(actionName)
+	}
+
+	action.X, action.Y = x, y
+	if text, ok := input["text"].(string); ok && action.Type == schemas.ComputerActionTypeText {
+		action.Text = &text
+	}
+	if duration, ok := input["duration"].(float64); ok {
+		action.Duration = &duration
+	}
+
+	return action
+}
+
+// parseAnthropicComputerActionArguments parses the accumulated JSON input of a streamed computer tool_use block.
+func parseAnthropicComputerActionArguments(arguments string) *schemas.ComputerAction {
+	var input map[string]interface{}
+	if err := sonic.Unmarshal([]byte(arguments), &input); err != nil {
+		return nil
+	}
+	return parseAnthropicComputerAction(input)
+}
+
+// computerCoordinate reads an [x, y] coordinate pair.
+func computerCoordinate(value interface{}) (*int, *int) {
+	coordinate, ok := value.([]interface{})
+	if !ok || len(coordinate) != 2 {
+		return nil, nil
+	}
+	x, okX := coordinate[0].(float64)
+	y, okY := coordinate[1].(float64)
+	if !okX || !okY {
+		return nil, nil
+	}
+	return Ptr(int(x)), Ptr(int(y))
+}
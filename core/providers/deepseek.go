@@ -0,0 +1,335 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the DeepSeek provider implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// deepSeekReasonerModel always answers in thinking mode, other models do when the thinking
+// parameter enables it.
+const deepSeekReasonerModel = "deepseek-reasoner"
+
+// deepSeekThinkingUnsupportedParams are the sampling parameters DeepSeek rejects or ignores in
+// thinking mode. They are dropped so requests shared with other providers still succeed.
+var deepSeekThinkingUnsupportedParams = []string{
+	"temperature",
+	"top_p",
+	"presence_penalty",
+	"frequency_penalty",
+	"logprobs",
+	"top_logprobs",
+}
+
+// DeepSeekUsage is the usage of a DeepSeek response, with its context caching breakdown.
+type DeepSeekUsage struct {
+	PromptCacheHitTokens    int                              `json:"prompt_cache_hit_tokens"`
+	PromptCacheMissTokens   int                              `json:"prompt_cache_miss_tokens"`
+	CompletionTokensDetails *schemas.CompletionTokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+// DeepSeekProvider implements the Provider interface for DeepSeek's API.
+type DeepSeekProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	streamClient        *http.Client          // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+}
+
+// NewDeepSeekProvider creates a new DeepSeek provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewDeepSeekProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*DeepSeekProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://api.deepseek.com"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &DeepSeekProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for DeepSeek.
+func (provider *DeepSeekProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.DeepSeek
+}
+
+// WarmUp opens connections to the DeepSeek API ahead of the first request.
+func (provider *DeepSeekProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the DeepSeek provider.
+func (provider *DeepSeekProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "deepseek")
+}
+
+// ChatCompletion performs a chat completion request to the DeepSeek API.
+// The reasoning_content of thinking mode responses is mapped to the thought of the assistant message.
+func (provider *DeepSeekProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+	provider.prepareDeepSeekParams(model, preparedParams)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+	}, preparedParams)
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.DeepSeek)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/chat/completions")
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from deepseek provider: %s", string(resp.Body())))
+
+		var errorResp map[string]interface{}
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		bifrostErr.Error.Message = fmt.Sprintf("DeepSeek error: %v", errorResp)
+		return nil, bifrostErr
+	}
+
+	responseBody := resp.Body()
+
+	// Parse and map reasoning_content to thought
+	response, bifrostErr := parseReasoningChatResponse(responseBody, schemas.DeepSeek, params, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var fields struct {
+		Usage *DeepSeekUsage `json:"usage,omitempty"`
+	}
+	if err := sonic.Unmarshal(responseBody, &fields); err != nil {
+		provider.logger.Warn(fmt.Sprintf("Failed to parse deepseek usage fields: %v", err))
+	} else {
+		applyDeepSeekUsage(response.Usage, fields.Usage)
+	}
+
+	return response, nil
+}
+
+// Embedding is not supported by the DeepSeek provider.
+func (provider *DeepSeekProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("embedding", "deepseek")
+}
+
+// ChatCompletionStream performs a streaming chat completion request to the DeepSeek API.
+// It supports real-time streaming of responses using Server-Sent Events (SSE).
+// Uses DeepSeek's OpenAI-compatible streaming format, reasoning_content deltas are sent as thought deltas.
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+func (provider *DeepSeekProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+	provider.prepareDeepSeekParams(model, preparedParams)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+		"stream":   true,
+		"stream_options": map[string]interface{}{
+			"include_usage": true,
+		},
+	}, preparedParams)
+
+	// Prepare DeepSeek headers
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + key.Value,
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+
+	// The caching and reasoning breakdown comes with the usage of the last chunk
+	var deepSeekUsage *DeepSeekUsage
+	chunkHook := func(rawChunk map[string]interface{}, response *schemas.BifrostResponse) {
+		usage, err := parseDeepSeekUsage(rawChunk)
+		if err != nil {
+			provider.logger.Warn(fmt.Sprintf("Failed to parse deepseek usage fields: %v", err))
+			return
+		}
+		if usage != nil {
+			deepSeekUsage = usage
+		}
+	}
+	endHook := func(response *schemas.BifrostResponse) {
+		applyDeepSeekUsage(response.Usage, deepSeekUsage)
+	}
+
+	// Use shared OpenAI-compatible streaming logic
+	return handleOpenAIStreamingWithHook(
+		ctx,
+		provider.streamClient,
+		provider.networkConfig.BaseURL+"/chat/completions",
+		requestBody,
+		headers,
+		provider.networkConfig.ExtraHeaders,
+		schemas.DeepSeek,
+		params,
+		postHookRunner,
+		provider.logger,
+		chunkHook,
+		endHook,
+	)
+}
+
+func (provider *DeepSeekProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "deepseek")
+}
+
+func (provider *DeepSeekProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "deepseek")
+}
+
+func (provider *DeepSeekProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "deepseek")
+}
+
+func (provider *DeepSeekProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "deepseek")
+}
+
+func (provider *DeepSeekProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "deepseek")
+}
+
+func (provider *DeepSeekProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "deepseek")
+}
+
+func (provider *DeepSeekProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "deepseek")
+}
+
+func (provider *DeepSeekProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "deepseek")
+}
+
+func (provider *DeepSeekProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "deepseek")
+}
+
+func (provider *DeepSeekProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "deepseek")
+}
+
+// prepareDeepSeekParams translates the thinking parameter, which can be given as a boolean or
+// as DeepSeek's {"type": "enabled"} object, and drops the sampling parameters thinking mode
+// does not support.
+func (provider *DeepSeekProvider) prepareDeepSeekParams(model string, preparedParams map[string]interface{}) {
+	thinking := model == deepSeekReasonerModel
+	switch value := preparedParams["thinking"].(type) {
+	case bool:
+		thinkingType := "disabled"
+		if value {
+			thinkingType = "enabled"
+		}
+		preparedParams["thinking"] = map[string]interface{}{"type": thinkingType}
+		thinking = thinking || value
+	case map[string]interface{}:
+		if thinkingType, ok := value["type"].(string); ok && thinkingType == "enabled" {
+			thinking = true
+		}
+	}
+	if !thinking {
+		return
+	}
+
+	for _, name := range deepSeekThinkingUnsupportedParams {
+		if _, ok := preparedParams[name]; ok {
+			provider.logger.Debug(fmt.Sprintf("dropping %s, not supported by deepseek in thinking mode", name))
+			delete(preparedParams, name)
+		}
+	}
+}
+
+// parseDeepSeekUsage extracts the usage of a raw stream chunk, nil if it has none.
+func parseDeepSeekUsage(rawChunk map[string]interface{}) (*DeepSeekUsage, error) {
+	value, ok := rawChunk["usage"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var usage DeepSeekUsage
+	if err := sonic.Unmarshal(data, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// applyDeepSeekUsage adds the cached prompt tokens and reasoning tokens reported by DeepSeek
+// to a Bifrost usage.
+func applyDeepSeekUsage(usage *schemas.LLMUsage, deepSeekUsage *DeepSeekUsage) {
+	if usage == nil || deepSeekUsage == nil {
+		return
+	}
+	if deepSeekUsage.PromptCacheHitTokens > 0 {
+		if usage.TokenDetails == nil {
+			usage.TokenDetails = &schemas.TokenDetails{}
+		}
+		usage.TokenDetails.CachedTokens = deepSeekUsage.PromptCacheHitTokens
+	}
+	if deepSeekUsage.CompletionTokensDetails != nil && usage.CompletionTokensDetails == nil {
+		usage.CompletionTokensDetails = deepSeekUsage.CompletionTokensDetails
+	}
+}
@@ -3,9 +3,9 @@
 package providers
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,6 +23,8 @@ type GenerateContentResponse struct {
 	Candidates []*Candidate `json:"candidates,omitempty"`
 	// Usage metadata about the response(s).
 	UsageMetadata *GenerateContentResponseUsageMetadata `json:"usageMetadata,omitempty"`
+	// Output only. The identifier of the response.
+	ResponseID string `json:"responseId,omitempty"`
 }
 
 // A response candidate generated from the model.
@@ -34,6 +36,44 @@ type Candidate struct {
 	FinishReason string `json:"finishReason,omitempty"`
 	// Output only. Index of the candidate.
 	Index int32 `json:"index,omitempty"`
+	// Output only. Sources the response was grounded on, set when search grounding or
+	// retrieval is enabled.
+	GroundingMetadata *GeminiGroundingMetadata `json:"groundingMetadata,omitempty"`
+}
+
+// GeminiGroundingMetadata links segments of a candidate to the sources they were grounded on.
+type GeminiGroundingMetadata struct {
+	GroundingChunks   []GeminiGroundingChunk   `json:"groundingChunks,omitempty"`
+	GroundingSupports []GeminiGroundingSupport `json:"groundingSupports,omitempty"`
+	WebSearchQueries  []string                 `json:"webSearchQueries,omitempty"`
+}
+
+// GeminiGroundingChunk is a source: a web page or a retrieved document.
+type GeminiGroundingChunk struct {
+	Web              *GeminiGroundingSource `json:"web,omitempty"`
+	RetrievedContext *GeminiGroundingSource `json:"retrievedContext,omitempty"`
+}
+
+// GeminiGroundingSource describes a grounding source.
+type GeminiGroundingSource struct {
+	URI   string `json:"uri,omitempty"`
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text,omitempty"` // Retrieved text, only set for retrieved contexts
+}
+
+// GeminiGroundingSupport links a segment of the response to the chunks supporting it.
+type GeminiGroundingSupport struct {
+	Segment               GeminiSegment `json:"segment"`
+	GroundingChunkIndices []int         `json:"groundingChunkIndices,omitempty"`
+	ConfidenceScores      []float64     `json:"confidenceScores,omitempty"` // Parallel to GroundingChunkIndices
+}
+
+// GeminiSegment is a span of a response part. Offsets are in bytes from the start of the part.
+type GeminiSegment struct {
+	PartIndex  int    `json:"partIndex,omitempty"`
+	StartIndex int    `json:"startIndex,omitempty"`
+	EndIndex   int    `json:"endIndex,omitempty"`
+	Text       string `json:"text,omitempty"`
 }
 
 // Contains the multi-part content of a message.
@@ -54,14 +94,46 @@ type Content struct {
 type Part struct {
 	// Optional. Inlined bytes data.
 	InlineData *Blob `json:"inlineData,omitempty"`
+	// Optional. URI based data.
+	FileData *GeminiFileData `json:"fileData,omitempty"`
 	// Optional. Text part (can be code).
 	Text string `json:"text,omitempty"`
+	// Optional. Whether the text is a thought of the model.
+	Thought bool `json:"thought,omitempty"`
+	// Optional. Signature of the thoughts preceding this part, to send them back in later turns.
+	ThoughtSignature string `json:"thoughtSignature,omitempty"`
+	// Optional. A function call predicted by the model.
+	FunctionCall *GeminiFunctionCall `json:"functionCall,omitempty"`
+	// Optional. The result of a function call, sent back to the model.
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// URI based data.
+type GeminiFileData struct {
+	MIMEType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+// A function call predicted by the model, with its arguments.
+type GeminiFunctionCall struct {
+	ID   string                 `json:"id,omitempty"`
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// The result of a function call. Response must be a JSON object.
+type GeminiFunctionResponse struct {
+	ID       string                 `json:"id,omitempty"`
+	Name     string                 `json:"name"`
+	Response map[string]interface{} `json:"response"`
 }
 
 // Content blob.
 type Blob struct {
 	// Required. Raw bytes.
 	Data []byte `json:"data,omitempty"`
+	// Mime type of the data, e.g. audio/L16;codec=pcm;rate=24000 for speech.
+	MIMEType string `json:"mimeType,omitempty"`
 }
 
 // Usage metadata about response(s).
@@ -73,6 +145,33 @@ type GenerateContentResponseUsageMetadata struct {
 	PromptTokenCount int32 `json:"promptTokenCount,omitempty"`
 	// Total token count for prompt, response candidates, and tool-use prompts (if present).
 	TotalTokenCount int32 `json:"totalTokenCount,omitempty"`
+	// Number of tokens in the cached part of the prompt.
+	CachedContentTokenCount int32 `json:"cachedContentTokenCount,omitempty"`
+	// Number of tokens of the thoughts of thinking models, not included in CandidatesTokenCount.
+	ThoughtsTokenCount int32 `json:"thoughtsTokenCount,omitempty"`
+}
+
+// GeminiVideoOperation represents a long running Veo generation operation.
+type GeminiVideoOperation struct {
+	Name  string `json:"name"` // models/<model>/operations/<id>
+	Done  bool   `json:"done,omitempty"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+	Response *struct {
+		GenerateVideoResponse *GeminiGenerateVideoResponse `json:"generateVideoResponse,omitempty"`
+	} `json:"response,omitempty"`
+}
+
+// GeminiGenerateVideoResponse holds the videos of a finished Veo operation.
+type GeminiGenerateVideoResponse struct {
+	GeneratedSamples []struct {
+		Video struct {
+			URI string `json:"uri"`
+		} `json:"video"`
+	} `json:"generatedSamples,omitempty"`
+	RAIMediaFilteredReasons []string `json:"raiMediaFilteredReasons,omitempty"` // Why videos were filtered out by safety checks
 }
 
 type GeminiProvider struct {
@@ -104,6 +203,10 @@ func NewGeminiProvider(config *schemas.ProviderConfig, logger schemas.Logger) *G
 	// Configure proxy if provided
 	client = configureProxy(client, config.ProxyConfig, logger)
 
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
 		config.NetworkConfig.BaseURL = "https://generativelanguage.googleapis.com/v1beta"
@@ -125,103 +228,39 @@ func (provider *GeminiProvider) GetProviderKey() schemas.ModelProvider {
 	return getProviderName(schemas.Gemini, provider.customProviderConfig)
 }
 
+// WarmUp opens connections to the Gemini API ahead of the first request.
+func (provider *GeminiProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
 // TextCompletion is not supported by the Gemini provider.
 func (provider *GeminiProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("text completion", string(provider.GetProviderKey()))
 }
 
 // ChatCompletion performs a chat completion request to the Gemini API.
+// It uses the native generateContent API, so thoughts, their signatures and
+// function calls are returned as Gemini produces them.
 func (provider *GeminiProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	// Check if chat completion is allowed for this provider
 	if err := checkOperationAllowed(schemas.Gemini, provider.customProviderConfig, schemas.OperationChatCompletion); err != nil {
 		return nil, err
 	}
 
-	providerName := provider.GetProviderKey()
-
-	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
-
-	requestBody := mergeConfig(map[string]interface{}{
-		"model":    model,
-		"messages": formattedMessages,
-	}, preparedParams)
-
-	jsonBody, err := sonic.Marshal(requestBody)
-	if err != nil {
-		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
-	}
-
-	// Create request
-	req := fasthttp.AcquireRequest()
-	resp := fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseRequest(req)
-	defer fasthttp.ReleaseResponse(resp)
-
-	// Set any extra headers from network config
-	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
-
-	req.SetRequestURI(provider.networkConfig.BaseURL + "/openai/chat/completions")
-	req.Header.SetMethod("POST")
-	req.Header.SetContentType("application/json")
-	req.Header.Set("Authorization", "Bearer "+key.Value)
-
-	req.SetBody(jsonBody)
-
-	// Make request
-	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
-	if bifrostErr != nil {
-		return nil, bifrostErr
-	}
-
-	// Handle error response
-	if resp.StatusCode() != fasthttp.StatusOK {
-		var errorResp map[string]interface{}
-		bifrostErr := handleProviderAPIError(resp, &errorResp)
-		bifrostErr.Error.Message = fmt.Sprintf("%s error: %v", providerName, errorResp)
-		return nil, bifrostErr
-	}
-
-	responseBody := resp.Body()
-
-	// Pre-allocate response structs from pools
-	// response := acquireGeminiResponse()
-	// defer releaseGeminiResponse(response)
-	response := &schemas.BifrostResponse{}
+	requestBody := prepareGeminiGenerationRequest(messages, params, nil)
 
-	// Use enhanced response handler with pre-allocated response
-	rawResponse, bifrostErr := handleProviderResponse(responseBody, response, provider.sendBackRawResponse)
+	response, geminiResponse, bifrostErr := provider.completeRequest(ctx, model, key, requestBody, ":generateContent", params)
 	if bifrostErr != nil {
 		return nil, bifrostErr
 	}
 
-	for _, choice := range response.Choices {
-		if choice.Message.AssistantMessage == nil || choice.Message.AssistantMessage.ToolCalls == nil {
-			continue
-		}
-		for i, toolCall := range *choice.Message.AssistantMessage.ToolCalls {
-			if (toolCall.ID == nil || *toolCall.ID == "") && toolCall.Function.Name != nil && *toolCall.Function.Name != "" {
-				id := *toolCall.Function.Name
-				(*choice.Message.AssistantMessage.ToolCalls)[i].ID = &id
-			}
-		}
-	}
-
-	response.ExtraFields.Provider = providerName
-
-	if provider.sendBackRawResponse {
-		response.ExtraFields.RawResponse = rawResponse
-	}
-
-	if params != nil {
-		response.ExtraFields.Params = *params
-	}
+	setGeminiChatResponse(response, geminiResponse)
 
 	return response, nil
 }
 
 // ChatCompletionStream performs a streaming chat completion request to the Gemini API.
-// It supports real-time streaming of responses using Server-Sent Events (SSE).
-// Uses Gemini's OpenAI-compatible streaming format.
+// It uses the native streamGenerateContent API with Server-Sent Events (SSE).
 // Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
 func (provider *GeminiProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	// Check if chat completion stream is allowed for this provider
@@ -229,33 +268,25 @@ func (provider *GeminiProvider) ChatCompletionStream(ctx context.Context, postHo
 		return nil, err
 	}
 
-	providerName := provider.GetProviderKey()
-
-	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
-
-	requestBody := mergeConfig(map[string]interface{}{
-		"model":    model,
-		"messages": formattedMessages,
-		"stream":   true,
-	}, preparedParams)
+	requestBody := prepareGeminiGenerationRequest(messages, params, nil)
 
-	// Prepare Gemini headers
 	headers := map[string]string{
-		"Content-Type":  "application/json",
-		"Authorization": "Bearer " + key.Value,
-		"Accept":        "text/event-stream",
-		"Cache-Control": "no-cache",
+		"Content-Type":   "application/json",
+		"x-goog-api-key": key.Value,
+		"Accept":         "text/event-stream",
+		"Cache-Control":  "no-cache",
 	}
 
-	// Use shared OpenAI-compatible streaming logic
-	return handleOpenAIStreaming(
+	// Use shared Gemini streaming logic
+	return handleGeminiStreaming(
 		ctx,
 		provider.streamClient,
-		provider.networkConfig.BaseURL+"/openai/chat/completions",
+		provider.networkConfig.BaseURL+"/models/"+model+":streamGenerateContent?alt=sse",
 		requestBody,
 		headers,
 		provider.networkConfig.ExtraHeaders,
-		providerName,
+		provider.GetProviderKey(),
+		model,
 		params,
 		postHookRunner,
 		provider.logger,
@@ -398,6 +429,10 @@ func (provider *GeminiProvider) SpeechStream(ctx context.Context, postHookRunner
 		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
 	}
 
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
 	// Create HTTP request for streaming
 	req, err := http.NewRequestWithContext(ctx, "POST", provider.networkConfig.BaseURL+"/models/"+model+":streamGenerateContent?alt=sse", bytes.NewReader(jsonBody))
 	if err != nil {
@@ -431,14 +466,14 @@ func (provider *GeminiProvider) SpeechStream(ctx context.Context, postHookRunner
 	// Start streaming in a goroutine
 	go func() {
 		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, provider.logger)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
-		// Increase buffer size to handle large chunks (especially for audio data)
-		buf := make([]byte, 0, 64*1024) // 64KB buffer
-		scanner.Buffer(buf, 1024*1024)  // Allow up to 1MB tokens
+		scanner := newStreamScanner(resp.Body)
 		chunkIndex := -1
 		usage := &schemas.AudioLLMUsage{}
+		// Gemini speech is raw PCM, the chunks name its exact format
+		contentType := speechMimeType("pcm")
 
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -448,89 +483,83 @@ func (provider *GeminiProvider) SpeechStream(ctx context.Context, postHookRunner
 				continue
 			}
 
-			var jsonData string
-			// Parse SSE data
-			if strings.HasPrefix(line, "data: ") {
-				jsonData = strings.TrimPrefix(line, "data: ")
-			} else {
-				// Handle raw JSON errors (without "data: " prefix)
-				jsonData = line
-			}
-
-			// Skip empty data
-			if strings.TrimSpace(jsonData) == "" {
-				continue
-			}
-
-			// Process chunk using shared function
-			geminiResponse, err := processGeminiStreamChunk(jsonData)
-			if err != nil {
-				if strings.Contains(err.Error(), "gemini api error") {
-					// Handle API error
-					bifrostErr := &schemas.BifrostError{
-						Type:           Ptr("gemini_api_error"),
-						IsBifrostError: false,
-						Error: schemas.ErrorField{
-							Message: err.Error(),
-							Error:   err,
-						},
+			// Split the data into its JSON objects, raw JSON errors come without the data prefix
+			objects, _ := splitStreamDataObjects(line)
+			for _, jsonData := range objects {
+				// Process chunk using shared function
+				geminiResponse, err := processGeminiStreamChunk(jsonData)
+				if err != nil {
+					if strings.Contains(err.Error(), "gemini api error") {
+						// Handle API error
+						bifrostErr := &schemas.BifrostError{
+							Type:           Ptr("gemini_api_error"),
+							IsBifrostError: false,
+							Origin:         schemas.ErrorOriginProvider,
+							Error: schemas.ErrorField{
+								Message: err.Error(),
+								Error:   err,
+							},
+						}
+						ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+						processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, provider.logger)
+						return
 					}
-					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
-					processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, provider.logger)
-					return
+					provider.logger.Warn(fmt.Sprintf("Failed to process chunk: %v", err))
+					continue
 				}
-				provider.logger.Warn(fmt.Sprintf("Failed to process chunk: %v", err))
-				continue
-			}
 
-			// Extract audio data from Gemini response for regular chunks
-			var audioChunk []byte
-			if len(geminiResponse.Candidates) > 0 {
-				candidate := geminiResponse.Candidates[0]
-				if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
-					var buf []byte
-					for _, part := range candidate.Content.Parts {
-						if part.InlineData != nil && part.InlineData.Data != nil {
-							buf = append(buf, part.InlineData.Data...)
+				// Extract audio data from Gemini response for regular chunks
+				var audioChunk []byte
+				if len(geminiResponse.Candidates) > 0 {
+					candidate := geminiResponse.Candidates[0]
+					if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
+						var buf []byte
+						for _, part := range candidate.Content.Parts {
+							if part.InlineData != nil && part.InlineData.Data != nil {
+								buf = append(buf, part.InlineData.Data...)
+								if part.InlineData.MIMEType != "" {
+									contentType = part.InlineData.MIMEType
+								}
+							}
+						}
+						if len(buf) > 0 {
+							audioChunk = buf
 						}
-					}
-					if len(buf) > 0 {
-						audioChunk = buf
 					}
 				}
-			}
-
-			// Check if this is the final chunk (has finishReason)
-			if len(geminiResponse.Candidates) > 0 && (geminiResponse.Candidates[0].FinishReason != "" || geminiResponse.UsageMetadata != nil) {
-				// Extract usage metadata using shared function
-				inputTokens, outputTokens, totalTokens := extractGeminiUsageMetadata(geminiResponse)
-				usage.InputTokens = inputTokens
-				usage.OutputTokens = outputTokens
-				usage.TotalTokens = totalTokens
-			}
 
-			// Only send response if we have actual audio content
-			if len(audioChunk) > 0 {
-				chunkIndex++
+				// Check if this is the final chunk (has finishReason)
+				if len(geminiResponse.Candidates) > 0 && (geminiResponse.Candidates[0].FinishReason != "" || geminiResponse.UsageMetadata != nil) {
+					// Extract usage metadata using shared function
+					inputTokens, outputTokens, totalTokens := extractGeminiUsageMetadata(geminiResponse)
+					usage.InputTokens = inputTokens
+					usage.OutputTokens = outputTokens
+					usage.TotalTokens = totalTokens
+				}
 
-				// Create Bifrost speech response for streaming
-				response := &schemas.BifrostResponse{
-					Object: "audio.speech.chunk",
-					Model:  model,
-					Speech: &schemas.BifrostSpeech{
-						Audio: audioChunk,
-						BifrostSpeechStreamResponse: &schemas.BifrostSpeechStreamResponse{
-							Type: "audio.speech.chunk",
+				// Only send response if we have actual audio content
+				if len(audioChunk) > 0 {
+					chunkIndex++
+
+					// Create Bifrost speech response for streaming
+					response := &schemas.BifrostResponse{
+						Object: "audio.speech.chunk",
+						Model:  model,
+						Speech: &schemas.BifrostSpeech{
+							Audio: audioChunk,
+							BifrostSpeechStreamResponse: &schemas.BifrostSpeechStreamResponse{
+								Type: "audio.speech.chunk",
+							},
 						},
-					},
-					ExtraFields: schemas.BifrostResponseExtraFields{
-						Provider:   providerName,
-						ChunkIndex: chunkIndex,
-					},
-				}
+						ExtraFields: schemas.BifrostResponseExtraFields{
+							Provider:   providerName,
+							ChunkIndex: chunkIndex,
+						},
+					}
 
-				// Process response through post-hooks and send to channel
-				processAndSendResponse(ctx, postHookRunner, response, responseChan, provider.logger)
+					// Process response through post-hooks and send to channel
+					processAndSendAudioChunk(ctx, postHookRunner, response, contentType, responseChan, provider.logger)
+				}
 			}
 		}
 
@@ -578,6 +607,167 @@ func (provider *GeminiProvider) Transcription(ctx context.Context, model string,
 		input.Prompt = Ptr("Generate a transcript of the speech.")
 	}
 
+	return provider.generateTranscript(ctx, model, key, input, params, "audio.transcription", "transcribe", input.Language)
+}
+
+// Translation transcribes the audio into English by prompting the model to translate it.
+// A prompt given with the request is appended to the instruction.
+func (provider *GeminiProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	// Check if translation is allowed for this provider
+	if err := checkOperationAllowed(schemas.Gemini, provider.customProviderConfig, schemas.OperationTranslation); err != nil {
+		return nil, err
+	}
+
+	providerName := provider.GetProviderKey()
+
+	// Check file size limit (Gemini has a 20MB limit for inline data)
+	const maxFileSize = 20 * 1024 * 1024 // 20MB
+	if len(input.File) > maxFileSize {
+		return nil, newBifrostOperationError("audio file too large for inline translation", fmt.Errorf("file size %d bytes exceeds 20MB limit", len(input.File)), providerName)
+	}
+
+	prompt := "Translate the speech into English and return only the English translation."
+	if input.Prompt != nil && *input.Prompt != "" {
+		prompt += " " + *input.Prompt
+	}
+	translationInput := *input
+	translationInput.Prompt = &prompt
+	translationInput.Language = nil
+
+	return provider.generateTranscript(ctx, model, key, &translationInput, params, "audio.translation", "translate", Ptr("english"))
+}
+
+func (provider *GeminiProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "gemini")
+}
+
+// VideoGeneration starts a Veo generation, e.g. with "veo-3.0-generate-preview", as a long
+// running operation whose name is the job ID. ImageURL, a URL or a base64 data URL, is sent
+// inline as the first frame. Options such as personGeneration go in ModelParameters.ExtraParams.
+func (provider *GeminiProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	// Check if video generation is allowed for this provider
+	if err := checkOperationAllowed(schemas.Gemini, provider.customProviderConfig, schemas.OperationVideoGeneration); err != nil {
+		return nil, err
+	}
+
+	providerName := provider.GetProviderKey()
+
+	instance := map[string]interface{}{
+		"prompt": input.Prompt,
+	}
+	if input.ImageURL != nil && *input.ImageURL != "" {
+		var data, mimeType string
+		if urlTypeInfo := ExtractURLTypeInfo(*input.ImageURL); urlTypeInfo.Type == ImageContentTypeBase64 && urlTypeInfo.DataURLWithoutPrefix != nil {
+			data = *urlTypeInfo.DataURLWithoutPrefix
+			if urlTypeInfo.MediaType != nil {
+				mimeType = *urlTypeInfo.MediaType
+			}
+		} else {
+			var bifrostErr *schemas.BifrostError
+			data, mimeType, bifrostErr = downloadImage(ctx, provider.client, *input.ImageURL, providerName)
+			if bifrostErr != nil {
+				return nil, bifrostErr
+			}
+		}
+		instance["image"] = map[string]interface{}{
+			"bytesBase64Encoded": data,
+			"mimeType":           mimeType,
+		}
+	}
+
+	parameters := map[string]interface{}{}
+	if input.NegativePrompt != nil {
+		parameters["negativePrompt"] = *input.NegativePrompt
+	}
+	if input.AspectRatio != nil {
+		parameters["aspectRatio"] = *input.AspectRatio
+	}
+	if input.Resolution != nil {
+		parameters["resolution"] = *input.Resolution
+	}
+	if input.Duration != nil {
+		parameters["durationSeconds"] = *input.Duration
+	}
+	if input.Seed != nil {
+		parameters["seed"] = *input.Seed
+	}
+	if params != nil {
+		parameters = mergeConfig(parameters, params.ExtraParams)
+	}
+
+	jsonBody, err := sonic.Marshal(map[string]interface{}{
+		"instances":  []interface{}{instance},
+		"parameters": parameters,
+	})
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
+	}
+
+	operation, rawResponse, bifrostErr := provider.videoOperationRequest(ctx, key, "POST", "/models/"+model+":predictLongRunning", jsonBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	bifrostResponse := geminiVideoResponse(operation, model, providerName)
+	bifrostResponse.Video.Prompt = input.Prompt
+	bifrostResponse.Video.AspectRatio = input.AspectRatio
+	bifrostResponse.Video.Resolution = input.Resolution
+	if input.Duration != nil {
+		bifrostResponse.Video.Duration = Ptr(float64(*input.Duration))
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// VideoStatus returns the state of a Veo operation. The URLs of completed videos are Gemini
+// file downloads, which need the API key as the x-goog-api-key header.
+func (provider *GeminiProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	// Check if video generation is allowed for this provider
+	if err := checkOperationAllowed(schemas.Gemini, provider.customProviderConfig, schemas.OperationVideoGeneration); err != nil {
+		return nil, err
+	}
+
+	providerName := provider.GetProviderKey()
+
+	// The job ID is the operation name, models/<model>/operations/<id>
+	if !strings.HasPrefix(input.JobID, "models/") || !strings.Contains(input.JobID, "/operations/") || strings.Contains(input.JobID, "..") {
+		return nil, newConfigurationError(fmt.Sprintf("invalid video job id %q", input.JobID), providerName)
+	}
+
+	operation, rawResponse, bifrostErr := provider.videoOperationRequest(ctx, key, "GET", "/"+input.JobID, nil)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	bifrostResponse := geminiVideoResponse(operation, model, providerName)
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	return bifrostResponse, nil
+}
+
+func (provider *GeminiProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "gemini")
+}
+
+func (provider *GeminiProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "gemini")
+}
+
+// generateTranscript asks the model for the text of the audio in input and returns it as a
+// response of the given object, task and language.
+func (provider *GeminiProvider) generateTranscript(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters, object string, task string, language *string) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	providerName := provider.GetProviderKey()
+
 	// Prepare request body using shared function
 	requestBody := prepareGeminiGenerationRequest(input, params, nil)
 
@@ -606,7 +796,7 @@ func (provider *GeminiProvider) Transcription(ctx context.Context, model string,
 	inputTokens, outputTokens, totalTokens := extractGeminiUsageMetadata(geminiResponse)
 
 	// Update the response with transcription-specific data
-	bifrostResponse.Object = "audio.transcription"
+	bifrostResponse.Object = object
 	bifrostResponse.Transcribe = &schemas.BifrostTranscribe{
 		Text: transcriptText,
 		Usage: &schemas.TranscriptionUsage{
@@ -616,8 +806,8 @@ func (provider *GeminiProvider) Transcription(ctx context.Context, model string,
 			TotalTokens:  &totalTokens,
 		},
 		BifrostTranscribeNonStreamResponse: &schemas.BifrostTranscribeNonStreamResponse{
-			Task:     Ptr("transcribe"),
-			Language: input.Language,
+			Task:     Ptr(task),
+			Language: language,
 		},
 	}
 
@@ -656,6 +846,10 @@ func (provider *GeminiProvider) TranscriptionStream(ctx context.Context, postHoo
 		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
 	}
 
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
 	// Create HTTP request for streaming
 	req, err := http.NewRequestWithContext(ctx, "POST", provider.networkConfig.BaseURL+"/models/"+model+":streamGenerateContent?alt=sse", bytes.NewReader(jsonBody))
 	if err != nil {
@@ -689,9 +883,10 @@ func (provider *GeminiProvider) TranscriptionStream(ctx context.Context, postHoo
 	// Start streaming in a goroutine
 	go func() {
 		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, provider.logger)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
+		scanner := newStreamScanner(resp.Body)
 		chunkIndex := -1
 		usage := &schemas.TranscriptionUsage{}
 
@@ -704,97 +899,88 @@ func (provider *GeminiProvider) TranscriptionStream(ctx context.Context, postHoo
 			if line == "" {
 				continue
 			}
-			var jsonData string
-			// Parse SSE data
-			if strings.HasPrefix(line, "data: ") {
-				jsonData = strings.TrimPrefix(line, "data: ")
-			} else {
-				// Handle raw JSON errors (without "data: " prefix)
-				jsonData = line
-			}
-
-			// Skip empty data
-			if strings.TrimSpace(jsonData) == "" {
-				continue
-			}
-
-			// First, check if this is an error response
-			var errorCheck map[string]interface{}
-			if err := sonic.Unmarshal([]byte(jsonData), &errorCheck); err != nil {
-				provider.logger.Warn(fmt.Sprintf("Failed to parse stream data as JSON: %v", err))
-				continue
-			}
+			// Split the data into its JSON objects, raw JSON errors come without the data prefix
+			objects, _ := splitStreamDataObjects(line)
+			for _, jsonData := range objects {
+				// First, check if this is an error response
+				var errorCheck map[string]interface{}
+				if err := sonic.Unmarshal([]byte(jsonData), &errorCheck); err != nil {
+					provider.logger.Warn(fmt.Sprintf("Failed to parse stream data as JSON: %v", err))
+					continue
+				}
 
-			// Handle error responses
-			if _, hasError := errorCheck["error"]; hasError {
-				bifrostErr := &schemas.BifrostError{
-					Type:           Ptr("gemini_api_error"),
-					IsBifrostError: false,
-					Error: schemas.ErrorField{
-						Message: fmt.Sprintf("Gemini API error: %v", errorCheck["error"]),
-						Error:   fmt.Errorf("stream error: %v", errorCheck["error"]),
-					},
+				// Handle error responses
+				if _, hasError := errorCheck["error"]; hasError {
+					bifrostErr := &schemas.BifrostError{
+						Type:           Ptr("gemini_api_error"),
+						IsBifrostError: false,
+						Origin:         schemas.ErrorOriginProvider,
+						Error: schemas.ErrorField{
+							Message: fmt.Sprintf("Gemini API error: %v", errorCheck["error"]),
+							Error:   fmt.Errorf("stream error: %v", errorCheck["error"]),
+						},
+					}
+					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+					processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, provider.logger)
+					return
 				}
-				ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
-				processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, provider.logger)
-				return
-			}
 
-			// Parse Gemini streaming response
-			var geminiResponse GenerateContentResponse
-			if err := sonic.Unmarshal([]byte(jsonData), &geminiResponse); err != nil {
-				provider.logger.Warn(fmt.Sprintf("Failed to parse Gemini stream response: %v", err))
-				continue
-			}
+				// Parse Gemini streaming response
+				var geminiResponse GenerateContentResponse
+				if err := sonic.Unmarshal([]byte(jsonData), &geminiResponse); err != nil {
+					provider.logger.Warn(fmt.Sprintf("Failed to parse Gemini stream response: %v", err))
+					continue
+				}
 
-			// Extract text from Gemini response for regular chunks
-			var deltaText string
-			if len(geminiResponse.Candidates) > 0 && geminiResponse.Candidates[0].Content != nil {
-				if len(geminiResponse.Candidates[0].Content.Parts) > 0 {
-					var sb strings.Builder
-					for _, p := range geminiResponse.Candidates[0].Content.Parts {
-						if p.Text != "" {
-							sb.WriteString(p.Text)
+				// Extract text from Gemini response for regular chunks
+				var deltaText string
+				if len(geminiResponse.Candidates) > 0 && geminiResponse.Candidates[0].Content != nil {
+					if len(geminiResponse.Candidates[0].Content.Parts) > 0 {
+						var sb strings.Builder
+						for _, p := range geminiResponse.Candidates[0].Content.Parts {
+							if p.Text != "" {
+								sb.WriteString(p.Text)
+							}
+						}
+						if sb.Len() > 0 {
+							deltaText = sb.String()
+							fullTranscriptionText += deltaText
 						}
-					}
-					if sb.Len() > 0 {
-						deltaText = sb.String()
-						fullTranscriptionText += deltaText
 					}
 				}
-			}
 
-			// Check if this is the final chunk (has finishReason)
-			if len(geminiResponse.Candidates) > 0 && (geminiResponse.Candidates[0].FinishReason != "" || geminiResponse.UsageMetadata != nil) {
-				// Extract usage metadata from Gemini response
-				inputTokens, outputTokens, totalTokens := extractGeminiUsageMetadata(&geminiResponse)
-				usage.InputTokens = Ptr(inputTokens)
-				usage.OutputTokens = Ptr(outputTokens)
-				usage.TotalTokens = Ptr(totalTokens)
-			}
-
-			// Only send response if we have actual text content
-			if deltaText != "" {
-				chunkIndex++
+				// Check if this is the final chunk (has finishReason)
+				if len(geminiResponse.Candidates) > 0 && (geminiResponse.Candidates[0].FinishReason != "" || geminiResponse.UsageMetadata != nil) {
+					// Extract usage metadata from Gemini response
+					inputTokens, outputTokens, totalTokens := extractGeminiUsageMetadata(&geminiResponse)
+					usage.InputTokens = Ptr(inputTokens)
+					usage.OutputTokens = Ptr(outputTokens)
+					usage.TotalTokens = Ptr(totalTokens)
+				}
 
-				// Create Bifrost transcription response for streaming
-				response := &schemas.BifrostResponse{
-					Object: "audio.transcription.chunk",
-					Transcribe: &schemas.BifrostTranscribe{
-						BifrostTranscribeStreamResponse: &schemas.BifrostTranscribeStreamResponse{
-							Type:  Ptr("transcript.text.delta"),
-							Delta: &deltaText, // Delta text for this chunk
+				// Only send response if we have actual text content
+				if deltaText != "" {
+					chunkIndex++
+
+					// Create Bifrost transcription response for streaming
+					response := &schemas.BifrostResponse{
+						Object: "audio.transcription.chunk",
+						Transcribe: &schemas.BifrostTranscribe{
+							BifrostTranscribeStreamResponse: &schemas.BifrostTranscribeStreamResponse{
+								Type:  Ptr("transcript.text.delta"),
+								Delta: &deltaText, // Delta text for this chunk
+							},
 						},
-					},
-					Model: model,
-					ExtraFields: schemas.BifrostResponseExtraFields{
-						Provider:   providerName,
-						ChunkIndex: chunkIndex,
-					},
-				}
+						Model: model,
+						ExtraFields: schemas.BifrostResponseExtraFields{
+							Provider:   providerName,
+							ChunkIndex: chunkIndex,
+						},
+					}
 
-				// Process response through post-hooks and send to channel
-				processAndSendResponse(ctx, postHookRunner, response, responseChan, provider.logger)
+					// Process response through post-hooks and send to channel
+					processAndSendResponse(ctx, postHookRunner, response, responseChan, provider.logger)
+				}
 			}
 		}
 
@@ -944,9 +1130,31 @@ func prepareGeminiGenerationRequest(input interface{}, params *schemas.ModelPara
 			}
 		}
 
-		// Add any extra parameters that might be Gemini-specific
+		// Add any extra parameters that might be Gemini-specific. The OpenAI parameters Gemini
+		// supports are mapped into generationConfig, and a generationConfig passed as an extra
+		// parameter, e.g. for thinkingConfig, extends the mapped one instead of replacing it
 		if params.ExtraParams != nil {
-			requestBody = mergeConfig(requestBody, params.ExtraParams)
+			extraParams := make(map[string]interface{}, len(params.ExtraParams))
+			for name, value := range params.ExtraParams {
+				switch name {
+				case "seed":
+					generationConfig["seed"] = value
+				case "response_format":
+					addGeminiResponseFormat(generationConfig, value)
+				case "generationConfig":
+					extraConfig, ok := value.(map[string]interface{})
+					if !ok {
+						extraParams[name] = value
+						continue
+					}
+					for configName, configValue := range extraConfig {
+						generationConfig[configName] = configValue
+					}
+				default:
+					extraParams[name] = value
+				}
+			}
+			requestBody = mergeConfig(requestBody, extraParams)
 		}
 	}
 
@@ -985,13 +1193,323 @@ func prepareGeminiGenerationRequest(input interface{}, params *schemas.ModelPara
 		}
 	case []schemas.BifrostMessage:
 		// Chat completion request
-		formattedMessages, _ := prepareOpenAIChatRequest(v, params)
-		requestBody["contents"] = formattedMessages
+		contents, systemInstruction := convertToGeminiContents(v)
+		requestBody["contents"] = contents
+		if systemInstruction != nil {
+			requestBody["systemInstruction"] = systemInstruction
+		}
 	}
 
 	return requestBody
 }
 
+// addGeminiResponseFormat maps an OpenAI response_format to the response MIME type and schema
+// of a generationConfig.
+func addGeminiResponseFormat(generationConfig map[string]interface{}, responseFormat interface{}) {
+	format, ok := responseFormat.(map[string]interface{})
+	if !ok {
+		return
+	}
+	switch format["type"] {
+	case "json_object":
+		generationConfig["responseMimeType"] = "application/json"
+	case "json_schema":
+		generationConfig["responseMimeType"] = "application/json"
+		if jsonSchema, ok := format["json_schema"].(map[string]interface{}); ok && jsonSchema["schema"] != nil {
+			generationConfig["responseJsonSchema"] = jsonSchema["schema"]
+		}
+	}
+}
+
+// convertToGeminiContents converts chat messages into Gemini contents and the system instruction,
+// nil without system messages. Assistant messages become "model" contents whose tool calls are
+// functionCall parts; tool results become functionResponse parts of "user" contents, named after
+// the function of the call they answer. Consecutive contents of the same role are merged, so
+// the results of parallel calls are sent together.
+func convertToGeminiContents(messages []schemas.BifrostMessage) ([]*Content, *Content) {
+	var contents []*Content
+	var systemParts []*Part
+	toolCallNames := make(map[string]string)
+
+	for _, msg := range messages {
+		var role string
+		var parts []*Part
+
+		switch msg.Role {
+		case schemas.ModelChatMessageRoleSystem, schemas.ModelChatMessageRoleDeveloper:
+			for _, text := range messageTexts(msg.Content) {
+				systemParts = append(systemParts, &Part{Text: text})
+			}
+			continue
+		case schemas.ModelChatMessageRoleTool:
+			role = "user"
+			var toolCallID string
+			if msg.ToolMessage != nil && msg.ToolMessage.ToolCallID != nil {
+				toolCallID = *msg.ToolMessage.ToolCallID
+			}
+			name, ok := toolCallNames[toolCallID]
+			if !ok {
+				// Gemini calls have no ID, the function name is used as one
+				name = toolCallID
+			}
+			result := strings.Join(messageTexts(msg.Content), "")
+			var response map[string]interface{}
+			if err := sonic.Unmarshal([]byte(result), &response); err != nil || response == nil {
+				response = map[string]interface{}{"content": result}
+			}
+			parts = append(parts, &Part{FunctionResponse: &GeminiFunctionResponse{Name: name, Response: response}})
+		case schemas.ModelChatMessageRoleAssistant, schemas.ModelChatMessageRoleChatbot:
+			role = "model"
+			if msg.AssistantMessage != nil && msg.AssistantMessage.Thought != nil && *msg.AssistantMessage.Thought != "" {
+				parts = append(parts, &Part{Text: *msg.AssistantMessage.Thought, Thought: true})
+			}
+			for _, text := range messageTexts(msg.Content) {
+				parts = append(parts, &Part{Text: text})
+			}
+			var signatureTarget *Part
+			if msg.AssistantMessage != nil && msg.AssistantMessage.ToolCalls != nil {
+				for _, toolCall := range *msg.AssistantMessage.ToolCalls {
+					if toolCall.Function.Name == nil {
+						continue
+					}
+					if toolCall.ID != nil {
+						toolCallNames[*toolCall.ID] = *toolCall.Function.Name
+					}
+					var args map[string]interface{}
+					if toolCall.Function.Arguments != "" {
+						if err := sonic.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+							args = nil
+						}
+					}
+					part := &Part{FunctionCall: &GeminiFunctionCall{Name: *toolCall.Function.Name, Args: args}}
+					if signatureTarget == nil {
+						signatureTarget = part
+					}
+					parts = append(parts, part)
+				}
+			}
+			// Gemini returns the signature of the thoughts on the first function call, or on the text
+			if msg.AssistantMessage != nil && msg.AssistantMessage.ThoughtSignature != nil {
+				if signatureTarget == nil {
+					for _, part := range parts {
+						if !part.Thought {
+							signatureTarget = part
+							break
+						}
+					}
+				}
+				if signatureTarget != nil {
+					signatureTarget.ThoughtSignature = *msg.AssistantMessage.ThoughtSignature
+				}
+			}
+		default:
+			role = "user"
+			if msg.Content.ContentStr != nil {
+				parts = append(parts, &Part{Text: *msg.Content.ContentStr})
+			} else if msg.Content.ContentBlocks != nil {
+				for _, block := range *msg.Content.ContentBlocks {
+					switch {
+					case block.Text != nil:
+						parts = append(parts, &Part{Text: *block.Text})
+					case block.ImageURL != nil:
+						if part := geminiImagePart(block.ImageURL); part != nil {
+							parts = append(parts, part)
+						}
+					}
+				}
+			}
+		}
+
+		if len(parts) == 0 {
+			continue
+		}
+		if last := len(contents) - 1; last >= 0 && contents[last].Role == role {
+			contents[last].Parts = append(contents[last].Parts, parts...)
+			continue
+		}
+		contents = append(contents, &Content{Role: role, Parts: parts})
+	}
+
+	if len(systemParts) == 0 {
+		return contents, nil
+	}
+	return contents, &Content{Parts: systemParts}
+}
+
+// messageTexts returns the text of a message content, one entry per text block.
+func messageTexts(content schemas.MessageContent) []string {
+	if content.ContentStr != nil {
+		if *content.ContentStr == "" {
+			return nil
+		}
+		return []string{*content.ContentStr}
+	}
+	var texts []string
+	if content.ContentBlocks != nil {
+		for _, block := range *content.ContentBlocks {
+			if block.Text != nil && *block.Text != "" {
+				texts = append(texts, *block.Text)
+			}
+		}
+	}
+	return texts
+}
+
+// geminiImagePart converts an image into an inlineData part for base64 data URLs, or a fileData
+// part for URLs. It returns nil for invalid images.
+func geminiImagePart(image *schemas.ImageURLStruct) *Part {
+	sanitizedURL, err := SanitizeImageURL(image.URL)
+	if err != nil {
+		return nil
+	}
+	urlTypeInfo := ExtractURLTypeInfo(sanitizedURL)
+	mimeType := "image/jpeg"
+	if urlTypeInfo.MediaType != nil && *urlTypeInfo.MediaType != "" {
+		mimeType = *urlTypeInfo.MediaType
+	}
+
+	if urlTypeInfo.Type == ImageContentTypeBase64 && urlTypeInfo.DataURLWithoutPrefix != nil {
+		data, err := base64.StdEncoding.DecodeString(*urlTypeInfo.DataURLWithoutPrefix)
+		if err != nil {
+			return nil
+		}
+		return &Part{InlineData: &Blob{MIMEType: mimeType, Data: data}}
+	}
+	return &Part{FileData: &GeminiFileData{MIMEType: mimeType, FileURI: sanitizedURL}}
+}
+
+// setGeminiChatResponse fills a chat completion response from a generateContent response.
+func setGeminiChatResponse(response *schemas.BifrostResponse, geminiResponse *GenerateContentResponse) {
+	response.ID = geminiResponse.ResponseID
+	response.Object = "chat.completion"
+	toolCallCounts := make(map[string]int)
+	for _, candidate := range geminiResponse.Candidates {
+		response.Choices = append(response.Choices, convertGeminiCandidate(candidate, toolCallCounts))
+	}
+	response.Usage = convertGeminiUsage(geminiResponse.UsageMetadata)
+}
+
+// convertGeminiCandidate converts a candidate into a chat completion choice.
+func convertGeminiCandidate(candidate *Candidate, toolCallCounts map[string]int) schemas.BifrostResponseChoice {
+	var text, thought strings.Builder
+	var toolCalls []schemas.ToolCall
+	var signature string
+	// Grounding segments are offsets into the candidate's parts, so every part has an entry
+	partTexts := []string{}
+	if candidate.Content != nil {
+		for _, part := range candidate.Content.Parts {
+			if part.ThoughtSignature != "" && signature == "" {
+				signature = part.ThoughtSignature
+			}
+			switch {
+			case part.FunctionCall != nil:
+				toolCalls = append(toolCalls, geminiToolCall(part.FunctionCall, toolCallCounts))
+				partTexts = append(partTexts, "")
+			case part.Thought:
+				thought.WriteString(part.Text)
+				partTexts = append(partTexts, "")
+			default:
+				text.WriteString(part.Text)
+				partTexts = append(partTexts, part.Text)
+			}
+		}
+	}
+	citations := citationsFromGeminiGrounding(candidate.GroundingMetadata, partTexts)
+
+	message := schemas.BifrostMessage{
+		Role:    schemas.ModelChatMessageRoleAssistant,
+		Content: schemas.MessageContent{ContentStr: Ptr(text.String())},
+	}
+	if thought.Len() > 0 || len(toolCalls) > 0 || signature != "" || len(citations) > 0 {
+		message.AssistantMessage = &schemas.AssistantMessage{Citations: citations}
+		if thought.Len() > 0 {
+			message.AssistantMessage.Thought = Ptr(thought.String())
+		}
+		if len(toolCalls) > 0 {
+			message.AssistantMessage.ToolCalls = &toolCalls
+		}
+		if signature != "" {
+			message.AssistantMessage.ThoughtSignature = &signature
+		}
+	}
+
+	choice := schemas.BifrostResponseChoice{
+		Index: int(candidate.Index),
+		BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
+			Message: message,
+		},
+	}
+	if candidate.FinishReason != "" {
+		choice.FinishReason = Ptr(MapGeminiFinishReason(candidate.FinishReason, len(toolCalls) > 0))
+	}
+	return choice
+}
+
+// geminiToolCall converts a function call of the model into a tool call. Gemini calls usually
+// have no ID, the function name is used instead, numbered when the model calls the same
+// function several times in a response.
+func geminiToolCall(call *GeminiFunctionCall, toolCallCounts map[string]int) schemas.ToolCall {
+	id := call.ID
+	if id == "" {
+		toolCallCounts[call.Name]++
+		id = call.Name
+		if count := toolCallCounts[call.Name]; count > 1 {
+			id = fmt.Sprintf("%s_%d", call.Name, count)
+		}
+	}
+	arguments := "{}"
+	if call.Args != nil {
+		if data, err := sonic.Marshal(call.Args); err == nil {
+			arguments = string(data)
+		}
+	}
+	return schemas.ToolCall{
+		Type: Ptr("function"),
+		ID:   &id,
+		Function: schemas.FunctionCall{
+			Name:      Ptr(call.Name),
+			Arguments: arguments,
+		},
+	}
+}
+
+// MapGeminiFinishReason maps a Gemini finish reason to the OpenAI finish reasons used by Bifrost.
+func MapGeminiFinishReason(geminiReason string, hasToolCalls bool) string {
+	switch geminiReason {
+	case "STOP":
+		if hasToolCalls {
+			return "tool_calls"
+		}
+		return "stop"
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return "content_filter"
+	default:
+		return strings.ToLower(geminiReason)
+	}
+}
+
+// convertGeminiUsage normalizes Gemini usage metadata. Thoughts are billed as output, so they
+// count as completion tokens and are detailed as reasoning tokens.
+func convertGeminiUsage(usageMetadata *GenerateContentResponseUsageMetadata) *schemas.LLMUsage {
+	if usageMetadata == nil {
+		return nil
+	}
+	usage := &schemas.LLMUsage{
+		PromptTokens:     int(usageMetadata.PromptTokenCount),
+		CompletionTokens: int(usageMetadata.CandidatesTokenCount + usageMetadata.ThoughtsTokenCount),
+		TotalTokens:      int(usageMetadata.TotalTokenCount),
+	}
+	if usageMetadata.CachedContentTokenCount > 0 {
+		usage.TokenDetails = &schemas.TokenDetails{CachedTokens: int(usageMetadata.CachedContentTokenCount)}
+	}
+	if usageMetadata.ThoughtsTokenCount > 0 {
+		usage.CompletionTokensDetails = &schemas.CompletionTokensDetails{ReasoningTokens: int(usageMetadata.ThoughtsTokenCount)}
+	}
+	return usage
+}
+
 // addSpeechConfig adds speech configuration to the request body
 func addSpeechConfig(requestBody map[string]interface{}, voiceConfig schemas.SpeechVoiceInput) {
 	speechConfig := map[string]interface{}{}
@@ -1030,6 +1548,194 @@ func addSpeechConfig(requestBody map[string]interface{}, voiceConfig schemas.Spe
 	}
 }
 
+// handleGeminiStreaming handles streaming of the native streamGenerateContent API with Server-Sent Events.
+// It is shared by the Gemini provider and the Gemini models of the Vertex provider, which differ only
+// in their URL, authentication and HTTP client.
+func handleGeminiStreaming(
+	ctx context.Context,
+	httpClient *http.Client,
+	url string,
+	requestBody map[string]interface{},
+	headers map[string]string,
+	extraHeaders map[string]string,
+	providerName schemas.ModelProvider,
+	model string,
+	params *schemas.ModelParameters,
+	postHookRunner schemas.PostHookRunner,
+	logger schemas.Logger,
+) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
+	}
+
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Create HTTP request for streaming
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, providerName)
+	}
+
+	// Set any extra headers from network config
+	setExtraHeadersHTTP(req, extraHeaders, nil)
+
+	// Set headers
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	// Make the request
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, providerName)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, parseStreamGeminiError(providerName, resp)
+	}
+
+	// Create response channel
+	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+
+	// Start streaming in a goroutine
+	go func() {
+		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, logger)
+		defer resp.Body.Close()
+
+		scanner := newStreamScanner(resp.Body)
+		chunkIndex := -1
+		var id string
+		var usage *schemas.LLMUsage
+		var finishReason *string
+		hasToolCalls := false
+		toolCallCounts := make(map[string]int)
+		// Grounding metadata of streams covers the text of all chunks, its citations are sent
+		// with the final chunk
+		var streamText strings.Builder
+		var citations []schemas.MessageCitation
+
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			// Skip empty lines
+			if line == "" {
+				continue
+			}
+
+			// Split the data into its JSON objects, raw JSON errors come without the data prefix
+			objects, _ := splitStreamDataObjects(line)
+			for _, jsonData := range objects {
+				// Process chunk using shared function
+				geminiResponse, err := processGeminiStreamChunk(jsonData)
+				if err != nil {
+					if strings.Contains(err.Error(), "gemini api error") {
+						// Handle API error
+						bifrostErr := &schemas.BifrostError{
+							Type:           Ptr("gemini_api_error"),
+							IsBifrostError: false,
+							Origin:         schemas.ErrorOriginProvider,
+							Error: schemas.ErrorField{
+								Message: err.Error(),
+								Error:   err,
+							},
+						}
+						ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+						processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, logger)
+						return
+					}
+					logger.Warn(fmt.Sprintf("Failed to process chunk: %v", err))
+					continue
+				}
+
+				if geminiResponse.ResponseID != "" {
+					id = geminiResponse.ResponseID
+				}
+				// Usage metadata is cumulative, the last one covers the whole response
+				if geminiResponse.UsageMetadata != nil {
+					usage = convertGeminiUsage(geminiResponse.UsageMetadata)
+				}
+				if len(geminiResponse.Candidates) == 0 {
+					continue
+				}
+
+				choice := convertGeminiCandidate(geminiResponse.Candidates[0], toolCallCounts)
+				message := choice.Message
+				if choice.FinishReason != nil {
+					finishReason = Ptr(geminiResponse.Candidates[0].FinishReason)
+				}
+
+				delta := schemas.BifrostStreamDelta{}
+				if message.Content.ContentStr != nil && *message.Content.ContentStr != "" {
+					delta.Content = message.Content.ContentStr
+					streamText.WriteString(*message.Content.ContentStr)
+				}
+				if grounding := geminiResponse.Candidates[0].GroundingMetadata; grounding != nil {
+					citations = citationsFromGeminiGrounding(grounding, []string{streamText.String()})
+				}
+				if message.AssistantMessage != nil {
+					delta.Thought = message.AssistantMessage.Thought
+					delta.ThoughtSignature = message.AssistantMessage.ThoughtSignature
+					if message.AssistantMessage.ToolCalls != nil {
+						delta.ToolCalls = *message.AssistantMessage.ToolCalls
+						hasToolCalls = true
+					}
+				}
+				if delta.Content == nil && delta.Thought == nil && delta.ThoughtSignature == nil && len(delta.ToolCalls) == 0 {
+					continue
+				}
+
+				chunkIndex++
+				if chunkIndex == 0 {
+					delta.Role = Ptr(string(schemas.ModelChatMessageRoleAssistant))
+				}
+
+				response := &schemas.BifrostResponse{
+					ID:     id,
+					Object: "chat.completion.chunk",
+					Model:  model,
+					Choices: []schemas.BifrostResponseChoice{
+						{
+							Index: 0,
+							BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+								Delta: delta,
+							},
+						},
+					},
+					ExtraFields: schemas.BifrostResponseExtraFields{
+						Provider:   providerName,
+						ChunkIndex: chunkIndex,
+					},
+				}
+
+				// Process response through post-hooks and send to channel
+				processAndSendResponse(ctx, postHookRunner, response, responseChan, logger)
+			}
+		}
+
+		// Handle scanner errors
+		if err := scanner.Err(); err != nil {
+			logger.Warn(fmt.Sprintf("Error reading stream: %v", err))
+			processAndSendError(ctx, postHookRunner, err, responseChan, logger)
+		} else {
+			if finishReason != nil {
+				finishReason = Ptr(MapGeminiFinishReason(*finishReason, hasToolCalls))
+			}
+			response := createBifrostChatCompletionChunkResponse(id, usage, finishReason, chunkIndex, params, providerName)
+			response.Model = model
+			response.Choices[0].Delta.Citations = citations
+			handleStreamEndWithSuccess(ctx, response, postHookRunner, responseChan, logger)
+		}
+	}()
+
+	return responseChan, nil
+}
+
 // processGeminiStreamChunk processes a single chunk from Gemini streaming response
 func processGeminiStreamChunk(jsonData string) (*GenerateContentResponse, error) {
 	// First, check if this is an error response
@@ -1132,6 +1838,92 @@ func (provider *GeminiProvider) completeRequest(ctx context.Context, model strin
 	return bifrostResponse, &geminiResponse, nil
 }
 
+// videoOperationRequest sends a request returning a long running Veo operation.
+func (provider *GeminiProvider) videoOperationRequest(ctx context.Context, key schemas.Key, method string, path string, body []byte) (*GeminiVideoOperation, interface{}, *schemas.BifrostError) {
+	providerName := provider.GetProviderKey()
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + path)
+	req.Header.SetMethod(method)
+	req.Header.Set("x-goog-api-key", key.Value)
+	if body != nil {
+		req.Header.SetContentType("application/json")
+		req.SetBody(body)
+	}
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return nil, nil, parseGeminiError(providerName, resp)
+	}
+
+	operation := &GeminiVideoOperation{}
+	rawResponse, bifrostErr := handleProviderResponse(resp.Body(), operation, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, nil, bifrostErr
+	}
+	return operation, rawResponse, nil
+}
+
+// geminiVideoResponse converts a Veo operation to a video generation response.
+func geminiVideoResponse(operation *GeminiVideoOperation, model string, providerName schemas.ModelProvider) *schemas.BifrostResponse {
+	video := &schemas.BifrostVideo{
+		JobID:  operation.Name,
+		Status: schemas.VideoJobProcessing,
+	}
+
+	if operation.Done {
+		var generated *GeminiGenerateVideoResponse
+		if operation.Response != nil {
+			generated = operation.Response.GenerateVideoResponse
+		}
+		switch {
+		case operation.Error != nil:
+			video.Status = schemas.VideoJobFailed
+			video.FailureReason = Ptr(operation.Error.Message)
+		case generated == nil || len(generated.GeneratedSamples) == 0:
+			video.Status = schemas.VideoJobFailed
+			reason := "no video was generated"
+			if generated != nil && len(generated.RAIMediaFilteredReasons) > 0 {
+				reason = strings.Join(generated.RAIMediaFilteredReasons, "; ")
+			}
+			video.FailureReason = &reason
+		default:
+			video.Status = schemas.VideoJobCompleted
+			for _, sample := range generated.GeneratedSamples {
+				video.Videos = append(video.Videos, schemas.GeneratedVideo{
+					URL:      sample.Video.URI,
+					MimeType: "video/mp4",
+				})
+			}
+		}
+	}
+
+	return &schemas.BifrostResponse{
+		ID:      operation.Name,
+		Object:  "video.generation",
+		Model:   model,
+		Created: int(time.Now().Unix()),
+		Video:   video,
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: providerName,
+		},
+	}
+}
+
 // parseStreamGeminiError parses Gemini streaming error responses
 func parseStreamGeminiError(providerName schemas.ModelProvider, resp *http.Response) *schemas.BifrostError {
 	body, err := io.ReadAll(resp.Body)
@@ -35,6 +35,30 @@ import (
 // 	}
 // }
 
+// GroqUsage is the usage of a Groq response, with the latency breakdown Groq adds to it in seconds.
+type GroqUsage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	QueueTime        float64 `json:"queue_time"`
+	PromptTime       float64 `json:"prompt_time"`
+	CompletionTime   float64 `json:"completion_time"`
+	TotalTime        float64 `json:"total_time"`
+}
+
+// GroqMetadata is the x_groq object of Groq responses. Streams report their usage in the
+// x_groq object of their last chunk instead of a usage object.
+type GroqMetadata struct {
+	ID    string     `json:"id"`
+	Usage *GroqUsage `json:"usage,omitempty"`
+}
+
+// GroqResponseFields are the fields of a Groq chat completion the OpenAI format has no place for.
+type GroqResponseFields struct {
+	Usage *GroqUsage    `json:"usage,omitempty"`
+	XGroq *GroqMetadata `json:"x_groq,omitempty"`
+}
+
 // GroqProvider implements the Provider interface for Groq's API.
 type GroqProvider struct {
 	logger              schemas.Logger        // Logger for provider operations
@@ -69,6 +93,10 @@ func NewGroqProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*Gr
 	// Configure proxy if provided
 	client = configureProxy(client, config.ProxyConfig, logger)
 
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
 		config.NetworkConfig.BaseURL = "https://api.groq.com/openai"
@@ -89,6 +117,11 @@ func (provider *GroqProvider) GetProviderKey() schemas.ModelProvider {
 	return schemas.Groq
 }
 
+// WarmUp opens connections to the Groq API ahead of the first request.
+func (provider *GroqProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
 // TextCompletion is not supported by the Groq provider.
 func (provider *GroqProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("text completion", "groq")
@@ -97,6 +130,7 @@ func (provider *GroqProvider) TextCompletion(ctx context.Context, model string,
 // ChatCompletion performs a chat completion request to the Groq API.
 func (provider *GroqProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+	setServiceTier(schemas.Groq, params, preparedParams)
 
 	requestBody := mergeConfig(map[string]interface{}{
 		"model":    model,
@@ -156,6 +190,17 @@ func (provider *GroqProvider) ChatCompletion(ctx context.Context, model string,
 	// Create final response
 	response.ExtraFields.Provider = schemas.Groq
 
+	var groqFields GroqResponseFields
+	if err := sonic.Unmarshal(responseBody, &groqFields); err != nil {
+		provider.logger.Warn(fmt.Sprintf("Failed to parse groq usage fields: %v", err))
+	} else if groqFields.Usage != nil {
+		var requestID string
+		if groqFields.XGroq != nil {
+			requestID = groqFields.XGroq.ID
+		}
+		response.ExtraFields.ProviderTiming = groqTiming(groqFields.Usage, requestID)
+	}
+
 	if provider.sendBackRawResponse {
 		response.ExtraFields.RawResponse = rawResponse
 	}
@@ -178,6 +223,7 @@ func (provider *GroqProvider) Embedding(ctx context.Context, model string, key s
 // Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
 func (provider *GroqProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+	setServiceTier(schemas.Groq, params, preparedParams)
 
 	requestBody := mergeConfig(map[string]interface{}{
 		"model":    model,
@@ -194,8 +240,33 @@ func (provider *GroqProvider) ChatCompletionStream(ctx context.Context, postHook
 
 	headers["Authorization"] = "Bearer " + key.Value
 
+	// The usage and latency breakdown come in the x_groq object of the last chunk
+	var timing *schemas.ProviderTiming
+	chunkHook := func(rawChunk map[string]interface{}, response *schemas.BifrostResponse) {
+		metadata, err := parseGroqMetadata(rawChunk)
+		if err != nil {
+			provider.logger.Warn(fmt.Sprintf("Failed to parse groq usage fields: %v", err))
+			return
+		}
+		if metadata == nil || metadata.Usage == nil {
+			return
+		}
+		usage := metadata.Usage
+		timing = groqTiming(usage, metadata.ID)
+		if response.Usage == nil {
+			response.Usage = &schemas.LLMUsage{
+				PromptTokens:     usage.PromptTokens,
+				CompletionTokens: usage.CompletionTokens,
+				TotalTokens:      usage.TotalTokens,
+			}
+		}
+	}
+	endHook := func(response *schemas.BifrostResponse) {
+		response.ExtraFields.ProviderTiming = timing
+	}
+
 	// Use shared OpenAI-compatible streaming logic
-	return handleOpenAIStreaming(
+	return handleOpenAIStreamingWithHook(
 		ctx,
 		provider.streamClient,
 		provider.networkConfig.BaseURL+"/v1/chat/completions",
@@ -206,6 +277,8 @@ func (provider *GroqProvider) ChatCompletionStream(ctx context.Context, postHook
 		params,
 		postHookRunner,
 		provider.logger,
+		chunkHook,
+		endHook,
 	)
 }
 
@@ -224,3 +297,62 @@ func (provider *GroqProvider) Transcription(ctx context.Context, model string, k
 func (provider *GroqProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("transcription stream", "groq")
 }
+
+func (provider *GroqProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "groq")
+}
+
+func (provider *GroqProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "groq")
+}
+
+func (provider *GroqProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "groq")
+}
+
+func (provider *GroqProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "groq")
+}
+
+func (provider *GroqProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "groq")
+}
+
+func (provider *GroqProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "groq")
+}
+
+// parseGroqMetadata extracts the x_groq object from a raw stream chunk, nil if it has none.
+func parseGroqMetadata(rawChunk map[string]interface{}) (*GroqMetadata, error) {
+	value, ok := rawChunk["x_groq"]
+	if !ok {
+		return nil, nil
+	}
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var metadata GroqMetadata
+	if err := sonic.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// groqTiming converts the latency breakdown of a Groq usage, nil if it has none.
+func groqTiming(usage *GroqUsage, requestID string) *schemas.ProviderTiming {
+	if usage.TotalTime == 0 && usage.QueueTime == 0 && usage.PromptTime == 0 && usage.CompletionTime == 0 {
+		return nil
+	}
+	timing := &schemas.ProviderTiming{
+		RequestID:      requestID,
+		QueueTime:      usage.QueueTime,
+		PromptTime:     usage.PromptTime,
+		CompletionTime: usage.CompletionTime,
+		TotalTime:      usage.TotalTime,
+	}
+	if usage.CompletionTime > 0 {
+		timing.OutputTokensPerSecond = float64(usage.CompletionTokens) / usage.CompletionTime
+	}
+	return timing
+}
@@ -0,0 +1,167 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file applies the HTTP client profiles of providers to their HTTP clients.
+package providers
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// fasthttpSpecialHeaders are the headers fasthttp stores apart from the others and always writes
+// first with their canonical names, so profiles can neither rename nor reorder them.
+var fasthttpSpecialHeaders = map[string]bool{
+	"Host":              true,
+	"User-Agent":        true,
+	"Content-Type":      true,
+	"Content-Length":    true,
+	"Cookie":            true,
+	"Connection":        true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+}
+
+// configureHTTPClientProfile applies the TLS settings and header rewriting of a profile to a
+// fasthttp client, which always uses HTTP/1.1.
+// Returns the configured client or the original client if the profile is invalid.
+func configureHTTPClientProfile(client *fasthttp.Client, profile *schemas.HTTPClientProfile, logger schemas.Logger) *fasthttp.Client {
+	if profile == nil {
+		return client
+	}
+
+	if err := profile.Validate(); err != nil {
+		logger.Warn(fmt.Sprintf("Invalid http client profile: %v", err))
+		return client
+	}
+	tlsConfig, _ := profile.TLS.Config() // Validated above
+
+	if tlsConfig != nil {
+		client.TLSConfig = tlsConfig
+	}
+	if profile.RewritesHeaders() {
+		next := client.Transport
+		if next == nil {
+			next = fasthttp.DefaultTransport
+		}
+		client.Transport = &profileTransport{next: next, profile: profile}
+	}
+
+	return client
+}
+
+// configureNetHTTPClientProfile applies the TLS settings, HTTP version and header names of a
+// profile to a net/http client, such as the streaming client of a provider.
+// Returns the configured client or the original client if the profile is invalid.
+func configureNetHTTPClientProfile(client *http.Client, profile *schemas.HTTPClientProfile, logger schemas.Logger) *http.Client {
+	if profile == nil {
+		return client
+	}
+
+	if err := profile.Validate(); err != nil {
+		logger.Warn(fmt.Sprintf("Invalid http client profile: %v", err))
+		return client
+	}
+	tlsConfig, _ := profile.TLS.Config() // Validated above
+
+	var transport http.RoundTripper = client.Transport
+	if tlsConfig != nil || profile.HTTPVersion == schemas.HTTPVersion1 {
+		base, ok := client.Transport.(*http.Transport)
+		if !ok || base == nil {
+			base = http.DefaultTransport.(*http.Transport)
+		}
+		configured := base.Clone()
+		if tlsConfig != nil {
+			configured.TLSClientConfig = tlsConfig
+		}
+		if profile.HTTPVersion == schemas.HTTPVersion1 {
+			// A non-nil empty map disables HTTP/2
+			configured.ForceAttemptHTTP2 = false
+			configured.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		}
+		transport = configured
+	}
+	if profile.RewritesHeaders() {
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		transport = &profileRoundTripper{next: transport, profile: profile}
+	}
+	client.Transport = transport
+
+	return client
+}
+
+// profileTransport rewrites the header names and order of fasthttp requests as their profile
+// configures before sending them.
+type profileTransport struct {
+	next    fasthttp.RoundTripper
+	profile *schemas.HTTPClientProfile
+}
+
+func (t *profileTransport) RoundTrip(hc *fasthttp.HostClient, req *fasthttp.Request, resp *fasthttp.Response) (bool, error) {
+	rewriteFasthttpHeaders(&req.Header, t.profile)
+	return t.next.RoundTrip(hc, req, resp)
+}
+
+// rewriteFasthttpHeaders sets the headers of a request again, without normalizing their names,
+// with the names and in the order of the profile. Retries rewrite the same headers again, which
+// leaves them unchanged.
+func rewriteFasthttpHeaders(header *fasthttp.RequestHeader, profile *schemas.HTTPClientProfile) {
+	type headerField struct{ name, value string }
+	var fields []headerField
+	for key, value := range header.All() {
+		name := string(key)
+		if fasthttpSpecialHeaders[textproto.CanonicalMIMEHeaderKey(name)] {
+			continue
+		}
+		fields = append(fields, headerField{name: name, value: string(value)})
+	}
+	if len(fields) == 0 {
+		return
+	}
+	for _, field := range fields {
+		header.Del(field.name)
+	}
+
+	ordered := make([]headerField, 0, len(fields))
+	for _, name := range profile.HeaderOrder {
+		for i := range fields {
+			if fields[i].name != "" && strings.EqualFold(fields[i].name, name) {
+				ordered = append(ordered, fields[i])
+				fields[i].name = ""
+			}
+		}
+	}
+	for _, field := range fields {
+		if field.name != "" {
+			ordered = append(ordered, field)
+		}
+	}
+
+	header.DisableNormalizing()
+	for _, field := range ordered {
+		header.Add(profile.HeaderName(field.name), field.value)
+	}
+}
+
+// profileRoundTripper renames the headers of net/http requests as their profile configures. It
+// cannot reorder them, as net/http writes headers sorted by name, and HTTP/2 lowercases them.
+type profileRoundTripper struct {
+	next    http.RoundTripper
+	profile *schemas.HTTPClientProfile
+}
+
+func (t *profileRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify its request
+	renamed := req.Clone(req.Context())
+	renamed.Header = make(http.Header, len(req.Header))
+	for name, values := range req.Header {
+		renamed.Header[t.profile.HeaderName(name)] = values
+	}
+	return t.next.RoundTrip(renamed)
+}
@@ -0,0 +1,188 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains helpers shared by the image generation providers.
+package providers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// maxImagesPerRequest bounds the images generated for a single request by providers that
+	// return one image per call.
+	maxImagesPerRequest = 10
+	// maxImageConcurrency is the number of images generated at the same time for a request.
+	maxImageConcurrency = 4
+)
+
+// imageCount returns the number of images requested, 1 if not set.
+func imageCount(input *schemas.ImageInput) (int, error) {
+	if input.N == nil {
+		return 1, nil
+	}
+	if *input.N < 1 || *input.N > maxImagesPerRequest {
+		return 0, fmt.Errorf("n must be between 1 and %d", maxImagesPerRequest)
+	}
+	return *input.N, nil
+}
+
+// parseImageSize parses a "<width>x<height>" size.
+func parseImageSize(size string) (int, int, error) {
+	width, height, ok := strings.Cut(strings.ToLower(strings.TrimSpace(size)), "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid image size %q, expected <width>x<height>", size)
+	}
+	w, errW := strconv.Atoi(width)
+	h, errH := strconv.Atoi(height)
+	if errW != nil || errH != nil || w <= 0 || h <= 0 {
+		return 0, 0, fmt.Errorf("invalid image size %q, expected <width>x<height>", size)
+	}
+	return w, h, nil
+}
+
+// imageAspectRatio returns the aspect ratio of the input, or of the supported ratios the closest
+// to its size when only the size is set. Returns "" when neither is set.
+func imageAspectRatio(input *schemas.ImageInput, supported []string) (string, error) {
+	if input.AspectRatio != nil && *input.AspectRatio != "" {
+		return *input.AspectRatio, nil
+	}
+	if input.Size == nil || *input.Size == "" {
+		return "", nil
+	}
+	width, height, err := parseImageSize(*input.Size)
+	if err != nil {
+		return "", err
+	}
+
+	target := float64(width) / float64(height)
+	best, bestDistance := "", math.MaxFloat64
+	for _, ratio := range supported {
+		w, h, ok := strings.Cut(ratio, ":")
+		if !ok {
+			continue
+		}
+		rw, _ := strconv.ParseFloat(w, 64)
+		rh, _ := strconv.ParseFloat(h, 64)
+		if rw <= 0 || rh <= 0 {
+			continue
+		}
+		// Compare in log space so 2:1 and 1:2 are as far from 1:1
+		if distance := math.Abs(math.Log(target) - math.Log(rw/rh)); distance < bestDistance {
+			best, bestDistance = ratio, distance
+		}
+	}
+	return best, nil
+}
+
+// generateImages runs generate once per image, at most maxImageConcurrency at a time, for
+// providers that return one image per call. The first error cancels the remaining images.
+func generateImages(ctx context.Context, n int, generate func(ctx context.Context, index int) (*schemas.GeneratedImage, *schemas.BifrostError)) ([]schemas.GeneratedImage, *schemas.BifrostError) {
+	if n == 1 {
+		image, err := generate(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		return []schemas.GeneratedImage{*image}, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	images := make([]schemas.GeneratedImage, n)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr *schemas.BifrostError
+	)
+	semaphore := make(chan struct{}, maxImageConcurrency)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			if ctx.Err() != nil {
+				return
+			}
+
+			image, err := generate(ctx, i)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			images[i] = *image
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return images, nil
+}
+
+// downloadImage fetches a provider hosted image and returns it base64 encoded with its mime type.
+func downloadImage(ctx context.Context, client *fasthttp.Client, url string, providerName schemas.ModelProvider) (string, string, *schemas.BifrostError) {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(url)
+	req.Header.SetMethod("GET")
+
+	if bifrostErr := makeRequestWithContext(ctx, client, req, resp); bifrostErr != nil {
+		return "", "", bifrostErr
+	}
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return "", "", newProviderAPIError(fmt.Sprintf("failed to download generated image: status %d", resp.StatusCode()), nil, resp.StatusCode(), providerName, nil, nil)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(resp.Body())
+	mimeType := string(resp.Header.ContentType())
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = detectImageTypeFromBase64(encoded)
+	}
+	return encoded, mimeType, nil
+}
+
+// waitOrDone sleeps for d, returning false early if ctx is done.
+func waitOrDone(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// imageMimeType returns the mime type of an image output format.
+func imageMimeType(format string) string {
+	switch format {
+	case "jpeg", "jpg":
+		return "image/jpeg"
+	case "webp":
+		return "image/webp"
+	default:
+		return "image/png"
+	}
+}
+
+// wantsImageURL reports whether the images should be returned as URLs instead of inline.
+func wantsImageURL(input *schemas.ImageInput) bool {
+	return input.ResponseFormat != nil && *input.ResponseFormat == "url"
+}
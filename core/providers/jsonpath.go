@@ -0,0 +1,118 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains a minimal JSONPath evaluator used by the template provider.
+package providers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep is a single step of a parsed JSONPath expression.
+type jsonPathStep struct {
+	key      string // Object key, empty for index steps
+	index    int    // Array index, negative values count from the end
+	isIndex  bool
+	wildcard bool // [*], selects every array element
+}
+
+// parseJSONPath parses the supported JSONPath subset: $ followed by .key, ['key'], [n] and [*] steps.
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path %q must start with $", path)
+	}
+
+	var steps []jsonPathStep
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("json path %q has an empty key", path)
+			}
+			steps = append(steps, jsonPathStep{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("json path %q has an unclosed bracket", path)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case selector == "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				steps = append(steps, jsonPathStep{key: selector[1 : len(selector)-1]})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return nil, fmt.Errorf("json path %q has an invalid index %q", path, selector)
+				}
+				steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("json path %q has an unexpected character %q", path, rest[0])
+		}
+	}
+
+	return steps, nil
+}
+
+// evaluateJSONPath evaluates a JSONPath expression against a decoded JSON document.
+// Paths containing a wildcard return a []interface{} of all matches. A missing value
+// yields (nil, false).
+func evaluateJSONPath(document interface{}, path string) (interface{}, bool, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	values := []interface{}{document}
+	hasWildcard := false
+	for _, step := range steps {
+		var next []interface{}
+		for _, value := range values {
+			switch {
+			case step.wildcard:
+				hasWildcard = true
+				if array, ok := value.([]interface{}); ok {
+					next = append(next, array...)
+				}
+			case step.isIndex:
+				array, ok := value.([]interface{})
+				if !ok {
+					continue
+				}
+				index := step.index
+				if index < 0 {
+					index += len(array)
+				}
+				if index >= 0 && index < len(array) {
+					next = append(next, array[index])
+				}
+			default:
+				if object, ok := value.(map[string]interface{}); ok {
+					if v, ok := object[step.key]; ok {
+						next = append(next, v)
+					}
+				}
+			}
+		}
+		values = next
+	}
+
+	if hasWildcard {
+		return values, len(values) > 0, nil
+	}
+	if len(values) == 0 {
+		return nil, false, nil
+	}
+	return values[0], true, nil
+}
@@ -0,0 +1,306 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the Luma AI (Dream Machine) provider implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// LumaGeneration represents a Dream Machine generation, as returned when creating and polling it.
+type LumaGeneration struct {
+	ID            string  `json:"id"`
+	State         string  `json:"state"` // queued, dreaming, completed or failed
+	FailureReason *string `json:"failure_reason,omitempty"`
+	CreatedAt     string  `json:"created_at,omitempty"`
+	Model         string  `json:"model,omitempty"`
+	Assets        *struct {
+		Video string `json:"video,omitempty"`
+		Image string `json:"image,omitempty"` // Thumbnail
+	} `json:"assets,omitempty"`
+	Request *struct {
+		Prompt      string  `json:"prompt,omitempty"`
+		AspectRatio *string `json:"aspect_ratio,omitempty"`
+		Resolution  *string `json:"resolution,omitempty"`
+		Duration    *string `json:"duration,omitempty"` // e.g. "5s"
+	} `json:"request,omitempty"`
+}
+
+// LumaError represents an error response from the Luma API.
+type LumaError struct {
+	Detail interface{} `json:"detail"` // A message, or a list of validation errors
+}
+
+// LumaProvider implements the Provider interface for Luma AI's Dream Machine API.
+// It only supports video generation, as jobs that are submitted and then polled.
+type LumaProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+}
+
+// NewLumaProvider creates a new Luma AI provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewLumaProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*LumaProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://api.lumalabs.ai/dream-machine/v1"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &LumaProvider{
+		logger:              logger,
+		client:              client,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Luma AI.
+func (provider *LumaProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Luma
+}
+
+// WarmUp opens connections to the Luma API ahead of the first request.
+func (provider *LumaProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, nil, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the Luma AI provider.
+func (provider *LumaProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "luma")
+}
+
+// ChatCompletion is not supported by the Luma AI provider.
+func (provider *LumaProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("chat completion", "luma")
+}
+
+// ChatCompletionStream is not supported by the Luma AI provider.
+func (provider *LumaProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("chat completion stream", "luma")
+}
+
+// Embedding is not supported by the Luma AI provider.
+func (provider *LumaProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("embedding", "luma")
+}
+
+func (provider *LumaProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "luma")
+}
+
+func (provider *LumaProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "luma")
+}
+
+func (provider *LumaProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "luma")
+}
+
+func (provider *LumaProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "luma")
+}
+
+func (provider *LumaProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "luma")
+}
+
+func (provider *LumaProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "luma")
+}
+
+// VideoGeneration creates a generation with a Ray model, e.g. "ray-2" or "ray-flash-2".
+// ImageURL becomes the first keyframe and must be a hosted URL. Duration is sent as "<n>s".
+// NegativePrompt and Seed have no Luma equivalent and are ignored; options such as
+// concepts or keyframes for the last frame go in ModelParameters.ExtraParams.
+func (provider *LumaProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	requestBody := map[string]interface{}{
+		"prompt": input.Prompt,
+		"model":  model,
+	}
+	if input.ImageURL != nil && *input.ImageURL != "" {
+		if strings.HasPrefix(*input.ImageURL, "data:") {
+			return nil, newConfigurationError("luma keyframes must be hosted image URLs, data URLs are not supported", schemas.Luma)
+		}
+		requestBody["keyframes"] = map[string]interface{}{
+			"frame0": map[string]interface{}{"type": "image", "url": *input.ImageURL},
+		}
+	}
+	if input.AspectRatio != nil {
+		requestBody["aspect_ratio"] = *input.AspectRatio
+	}
+	if input.Resolution != nil {
+		requestBody["resolution"] = *input.Resolution
+	}
+	if input.Duration != nil {
+		requestBody["duration"] = fmt.Sprintf("%ds", *input.Duration)
+	}
+	if input.Loop != nil {
+		requestBody["loop"] = *input.Loop
+	}
+	if params != nil {
+		requestBody = mergeConfig(requestBody, params.ExtraParams)
+	}
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Luma)
+	}
+
+	bifrostResponse, bifrostErr := provider.doRequest(ctx, key, "POST", "/generations", jsonBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// VideoStatus returns the state of a generation and, once completed, its video.
+func (provider *LumaProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return provider.doRequest(ctx, key, "GET", "/generations/"+url.PathEscape(input.JobID), nil)
+}
+
+func (provider *LumaProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "luma")
+}
+
+func (provider *LumaProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "luma")
+}
+
+// doRequest sends a request to the generations API and converts the returned generation.
+func (provider *LumaProvider) doRequest(ctx context.Context, key schemas.Key, method string, path string, body []byte) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + path)
+	req.Header.SetMethod(method)
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+	if body != nil {
+		req.Header.SetContentType("application/json")
+		req.SetBody(body)
+	}
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK && resp.StatusCode() != fasthttp.StatusCreated {
+		provider.logger.Debug(fmt.Sprintf("error from luma provider: %s", string(resp.Body())))
+
+		var errorResp LumaError
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		if errorResp.Detail != nil {
+			bifrostErr.Error.Message = fmt.Sprintf("Luma error: %v", errorResp.Detail)
+		}
+		return nil, bifrostErr
+	}
+
+	generation := &LumaGeneration{}
+	rawResponse, bifrostErr := handleProviderResponse(resp.Body(), generation, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		ID:      generation.ID,
+		Object:  "video.generation",
+		Model:   generation.Model,
+		Created: int(time.Now().Unix()),
+		Video:   lumaVideo(generation),
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Luma,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	return bifrostResponse, nil
+}
+
+// lumaVideo converts a Luma generation to a video job.
+func lumaVideo(generation *LumaGeneration) *schemas.BifrostVideo {
+	video := &schemas.BifrostVideo{
+		JobID:         generation.ID,
+		FailureReason: generation.FailureReason,
+	}
+
+	switch generation.State {
+	case "queued":
+		video.Status = schemas.VideoJobQueued
+	case "completed":
+		video.Status = schemas.VideoJobCompleted
+	case "failed":
+		video.Status = schemas.VideoJobFailed
+	default:
+		video.Status = schemas.VideoJobProcessing
+	}
+
+	if createdAt, err := time.Parse(time.RFC3339, generation.CreatedAt); err == nil {
+		video.CreatedAt = Ptr(int(createdAt.Unix()))
+	}
+
+	if request := generation.Request; request != nil {
+		video.Prompt = request.Prompt
+		video.AspectRatio = request.AspectRatio
+		video.Resolution = request.Resolution
+		if request.Duration != nil {
+			var seconds float64
+			if _, err := fmt.Sscanf(*request.Duration, "%gs", &seconds); err == nil {
+				video.Duration = &seconds
+			}
+		}
+	}
+
+	if assets := generation.Assets; assets != nil && assets.Video != "" {
+		generated := schemas.GeneratedVideo{
+			URL:      assets.Video,
+			MimeType: "video/mp4",
+		}
+		if assets.Image != "" {
+			generated.ThumbnailURL = Ptr(assets.Image)
+		}
+		video.Videos = []schemas.GeneratedVideo{generated}
+	}
+
+	return video
+}
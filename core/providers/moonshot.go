@@ -0,0 +1,578 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the Moonshot AI (Kimi) provider implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// moonshotCacheModel is the model family context caches are created for, the only one that
+	// supports them.
+	moonshotCacheModel = "moonshot-v1"
+	// moonshotCacheTTL is the lifetime of context caches, reset every time a request uses them.
+	moonshotCacheTTL = time.Hour
+	// moonshotCacheExpiryMargin is how long before their expiry context caches stop being used.
+	moonshotCacheExpiryMargin = time.Minute
+	// moonshotCachePollInterval is how often a pending context cache is checked.
+	moonshotCachePollInterval = time.Second
+	// moonshotCacheReadyTimeout is how long a context cache may take to become ready.
+	moonshotCacheReadyTimeout = time.Minute
+)
+
+// Statuses of Moonshot context caches.
+const (
+	moonshotCacheStatusPending = "pending"
+	moonshotCacheStatusReady   = "ready"
+	moonshotCacheStatusFailed  = "failed" // Local status of caches that could not be created
+)
+
+// MoonshotContextCache is a context cache of the Moonshot API, holding the messages and tools
+// requests reference with a cache message.
+type MoonshotContextCache struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"` // "pending", "ready", "error" or "inactive"
+	Tokens    int    `json:"tokens,omitempty"`
+	ExpiredAt int64  `json:"expired_at,omitempty"` // Unix seconds
+}
+
+// MoonshotUsage is the usage of a Moonshot response, which reports the prompt tokens read from a
+// context cache.
+type MoonshotUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+	CachedTokens     int `json:"cached_tokens,omitempty"`
+}
+
+// moonshotCacheEntry is a context cache of a stable prompt prefix, for one API key.
+type moonshotCacheEntry struct {
+	id        string
+	status    string
+	expiresAt time.Time // Of the cache once ready, or when a failed cache may be created again
+}
+
+// MoonshotProvider implements the Provider interface for Moonshot AI's API, which serves the
+// Kimi models through an OpenAI-compatible API. When the prompt cache manager marks the stable
+// prefix of a request, the system messages and tools, the provider stores it in a Moonshot
+// context cache and references the cache in later requests with the same prefix, so the prefix
+// is neither resent nor billed in full. Context caches only exist for the moonshot-v1 models.
+type MoonshotProvider struct {
+	logger              schemas.Logger                 // Logger for provider operations
+	client              *fasthttp.Client               // HTTP client for API requests
+	streamClient        *http.Client                   // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig          // Network configuration including extra headers
+	sendBackRawResponse bool                           // Whether to include raw response in BifrostResponse
+	cacheMu             sync.Mutex                     // Guards caches
+	caches              map[string]*moonshotCacheEntry // Context caches by API key and prefix key
+}
+
+// NewMoonshotProvider creates a new Moonshot provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+// The default base URL is the international endpoint; keys of the China platform need
+// https://api.moonshot.cn.
+func NewMoonshotProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*MoonshotProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://api.moonshot.ai"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &MoonshotProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+		caches:              make(map[string]*moonshotCacheEntry),
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Moonshot.
+func (provider *MoonshotProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Moonshot
+}
+
+// WarmUp opens connections to the Moonshot API ahead of the first request.
+func (provider *MoonshotProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the Moonshot provider.
+func (provider *MoonshotProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "moonshot")
+}
+
+// ChatCompletion performs a chat completion request to the Moonshot API.
+// The stable prefix of the request is read from its context cache once the cache is ready; if
+// Moonshot no longer has the cache, the request is sent again without it.
+func (provider *MoonshotProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	cacheID := provider.contextCacheID(model, key, messages, params)
+
+	response, bifrostErr := provider.chatCompletion(ctx, model, key, messages, params, cacheID)
+	if bifrostErr != nil && cacheID != "" && isMoonshotCacheError(bifrostErr) {
+		provider.logger.Debug(fmt.Sprintf("moonshot context cache %s is no longer usable: %s", cacheID, bifrostErr.Error.Message))
+		provider.forgetContextCache(key, params.PromptCache.PrefixKey)
+		return provider.chatCompletion(ctx, model, key, messages, params, "")
+	}
+	return response, bifrostErr
+}
+
+func (provider *MoonshotProvider) chatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters, cacheID string) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	formattedMessages, preparedParams := prepareMoonshotChatRequest(messages, params, cacheID)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+	}, preparedParams)
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Moonshot)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/v1/chat/completions")
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from moonshot provider: %s", string(resp.Body())))
+		return nil, parseOpenAIError(resp)
+	}
+
+	responseBody := resp.Body()
+
+	// Parse and map reasoning_content to thought
+	response, bifrostErr := parseReasoningChatResponse(responseBody, schemas.Moonshot, params, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var fields struct {
+		Usage *MoonshotUsage `json:"usage,omitempty"`
+	}
+	if err := sonic.Unmarshal(responseBody, &fields); err != nil {
+		provider.logger.Warn(fmt.Sprintf("Failed to parse moonshot usage: %v", err))
+	} else {
+		applyMoonshotUsage(response.Usage, fields.Usage)
+	}
+
+	if cacheID != "" {
+		response.ExtraFields.PromptCache = &schemas.PromptCacheResult{CacheID: cacheID}
+	}
+
+	return response, nil
+}
+
+// Embedding is not supported by the Moonshot provider.
+func (provider *MoonshotProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("embedding", "moonshot")
+}
+
+// ChatCompletionStream performs a streaming chat completion request to the Moonshot API.
+// It supports real-time streaming of responses using Server-Sent Events (SSE).
+// Uses Moonshot's OpenAI-compatible streaming format, which reports the usage in the last choice.
+// The stable prefix of the request is read from its context cache as in ChatCompletion.
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+func (provider *MoonshotProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	cacheID := provider.contextCacheID(model, key, messages, params)
+
+	stream, bifrostErr := provider.chatCompletionStream(ctx, postHookRunner, model, key, messages, params, cacheID)
+	if bifrostErr != nil && cacheID != "" && isMoonshotCacheError(bifrostErr) {
+		provider.logger.Debug(fmt.Sprintf("moonshot context cache %s is no longer usable: %s", cacheID, bifrostErr.Error.Message))
+		provider.forgetContextCache(key, params.PromptCache.PrefixKey)
+		return provider.chatCompletionStream(ctx, postHookRunner, model, key, messages, params, "")
+	}
+	return stream, bifrostErr
+}
+
+func (provider *MoonshotProvider) chatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters, cacheID string) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	formattedMessages, preparedParams := prepareMoonshotChatRequest(messages, params, cacheID)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+		"stream":   true,
+	}, preparedParams)
+
+	// Prepare Moonshot headers
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + key.Value,
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+
+	// The usage comes with the last choice rather than in a usage chunk
+	var moonshotUsage *MoonshotUsage
+	chunkHook := func(rawChunk map[string]interface{}, response *schemas.BifrostResponse) {
+		usage, err := parseMoonshotStreamUsage(rawChunk)
+		if err != nil {
+			provider.logger.Warn(fmt.Sprintf("Failed to parse moonshot usage: %v", err))
+			return
+		}
+		if usage != nil {
+			moonshotUsage = usage
+			if response.Usage == nil {
+				response.Usage = &schemas.LLMUsage{
+					PromptTokens:     usage.PromptTokens,
+					CompletionTokens: usage.CompletionTokens,
+					TotalTokens:      usage.TotalTokens,
+				}
+			}
+		}
+	}
+	endHook := func(response *schemas.BifrostResponse) {
+		applyMoonshotUsage(response.Usage, moonshotUsage)
+		if cacheID != "" {
+			response.ExtraFields.PromptCache = &schemas.PromptCacheResult{CacheID: cacheID}
+		}
+	}
+
+	// Use shared OpenAI-compatible streaming logic
+	return handleOpenAIStreamingWithHook(
+		ctx,
+		provider.streamClient,
+		provider.networkConfig.BaseURL+"/v1/chat/completions",
+		requestBody,
+		headers,
+		provider.networkConfig.ExtraHeaders,
+		schemas.Moonshot,
+		params,
+		postHookRunner,
+		provider.logger,
+		chunkHook,
+		endHook,
+	)
+}
+
+func (provider *MoonshotProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "moonshot")
+}
+
+func (provider *MoonshotProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "moonshot")
+}
+
+func (provider *MoonshotProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "moonshot")
+}
+
+func (provider *MoonshotProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "moonshot")
+}
+
+func (provider *MoonshotProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "moonshot")
+}
+
+func (provider *MoonshotProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "moonshot")
+}
+
+func (provider *MoonshotProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "moonshot")
+}
+
+func (provider *MoonshotProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "moonshot")
+}
+
+func (provider *MoonshotProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "moonshot")
+}
+
+func (provider *MoonshotProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "moonshot")
+}
+
+// contextCacheID returns the ready context cache of the stable prefix of a request, empty if the
+// request has no prefix marked by the prompt cache manager or its cache is not ready yet. The
+// first request with a prefix starts creating its cache in the background.
+func (provider *MoonshotProvider) contextCacheID(model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) string {
+	if params == nil || params.PromptCache == nil || params.PromptCache.PrefixKey == "" || !strings.HasPrefix(model, moonshotCacheModel) {
+		return ""
+	}
+	prefixLength := moonshotPrefixLength(messages)
+	tools := moonshotCacheTools(params)
+	if prefixLength == 0 && len(tools) == 0 {
+		return ""
+	}
+
+	cacheKey := moonshotCacheKey(key, params.PromptCache.PrefixKey)
+	now := time.Now()
+
+	provider.cacheMu.Lock()
+	defer provider.cacheMu.Unlock()
+
+	if entry, ok := provider.caches[cacheKey]; ok {
+		switch {
+		case entry.status == moonshotCacheStatusReady && now.Add(moonshotCacheExpiryMargin).Before(entry.expiresAt):
+			// Using the cache resets its TTL
+			entry.expiresAt = now.Add(moonshotCacheTTL)
+			return entry.id
+		case entry.status == moonshotCacheStatusPending:
+			return ""
+		case entry.status == moonshotCacheStatusFailed && now.Before(entry.expiresAt):
+			return ""
+		}
+	}
+
+	// Forget expired caches before tracking a new one
+	for existingKey, entry := range provider.caches {
+		if entry.status != moonshotCacheStatusPending && !now.Before(entry.expiresAt) {
+			delete(provider.caches, existingKey)
+		}
+	}
+	provider.caches[cacheKey] = &moonshotCacheEntry{status: moonshotCacheStatusPending}
+
+	prefixMessages, _ := prepareOpenAIChatRequest(messages[:prefixLength], nil)
+	cacheBody := map[string]interface{}{
+		"model": moonshotCacheModel,
+		"ttl":   int(moonshotCacheTTL.Seconds()),
+	}
+	if len(prefixMessages) > 0 {
+		cacheBody["messages"] = prefixMessages
+	}
+	if len(tools) > 0 {
+		cacheBody["tools"] = tools
+	}
+	go provider.createContextCache(cacheKey, key, cacheBody)
+
+	return ""
+}
+
+// createContextCache creates a context cache and waits for it to become ready, then records it
+// for the requests of its prefix. Caches that fail are not created again until moonshotCacheTTL
+// has passed.
+func (provider *MoonshotProvider) createContextCache(cacheKey string, key schemas.Key, cacheBody map[string]interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), moonshotCacheReadyTimeout)
+	defer cancel()
+
+	cache, err := provider.contextCacheRequest(ctx, "POST", "/v1/caching", key, cacheBody)
+	for err == nil && cache.Status == moonshotCacheStatusPending {
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("context cache %s was not ready after %s", cache.ID, moonshotCacheReadyTimeout)
+		case <-time.After(moonshotCachePollInterval):
+			cache, err = provider.contextCacheRequest(ctx, "GET", "/v1/caching/"+cache.ID, key, nil)
+		}
+	}
+	if err == nil && cache.Status != moonshotCacheStatusReady {
+		err = fmt.Errorf("context cache %s is %s", cache.ID, cache.Status)
+	}
+
+	now := time.Now()
+	entry := &moonshotCacheEntry{status: moonshotCacheStatusFailed, expiresAt: now.Add(moonshotCacheTTL)}
+	if err != nil {
+		provider.logger.Warn(fmt.Sprintf("Failed to create moonshot context cache: %v", err))
+	} else {
+		entry.id = cache.ID
+		entry.status = moonshotCacheStatusReady
+		if cache.ExpiredAt > 0 {
+			entry.expiresAt = time.Unix(cache.ExpiredAt, 0)
+		}
+		provider.logger.Debug(fmt.Sprintf("moonshot context cache %s is ready with %d tokens", cache.ID, cache.Tokens))
+	}
+
+	provider.cacheMu.Lock()
+	provider.caches[cacheKey] = entry
+	provider.cacheMu.Unlock()
+}
+
+// contextCacheRequest sends a request to the context cache API and parses the cache it returns.
+func (provider *MoonshotProvider) contextCacheRequest(ctx context.Context, method string, path string, key schemas.Key, body map[string]interface{}) (*MoonshotContextCache, error) {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + path)
+	req.Header.SetMethod(method)
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+	if body != nil {
+		jsonBody, err := sonic.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.SetContentType("application/json")
+		req.SetBody(jsonBody)
+	}
+
+	if bifrostErr := makeRequestWithContext(withoutRequestBodyCheck(ctx), provider.client, req, resp); bifrostErr != nil {
+		return nil, fmt.Errorf("%s", moonshotErrorMessage(bifrostErr))
+	}
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return nil, fmt.Errorf("%s", moonshotErrorMessage(parseOpenAIError(resp)))
+	}
+
+	var cache MoonshotContextCache
+	if err := sonic.Unmarshal(resp.Body(), &cache); err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+// forgetContextCache drops the context cache of a prefix, so the next request creates it again.
+func (provider *MoonshotProvider) forgetContextCache(key schemas.Key, prefixKey string) {
+	provider.cacheMu.Lock()
+	defer provider.cacheMu.Unlock()
+	delete(provider.caches, moonshotCacheKey(key, prefixKey))
+}
+
+// moonshotCacheKey identifies the context cache of a prefix, as caches belong to an API key.
+func moonshotCacheKey(key schemas.Key, prefixKey string) string {
+	return key.Value + "\x00" + prefixKey
+}
+
+// prepareMoonshotChatRequest formats a chat request in the OpenAI format. With a context cache,
+// its stable prefix, the leading system messages and the tools, is replaced by a cache message
+// that also resets the TTL of the cache.
+func prepareMoonshotChatRequest(messages []schemas.BifrostMessage, params *schemas.ModelParameters, cacheID string) ([]map[string]interface{}, map[string]interface{}) {
+	if cacheID == "" {
+		return prepareOpenAIChatRequest(messages, params)
+	}
+
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages[moonshotPrefixLength(messages):], params)
+	cacheMessage := map[string]interface{}{
+		"role":    "cache",
+		"content": fmt.Sprintf("cache_id=%s;reset_ttl=%d", cacheID, int(moonshotCacheTTL.Seconds())),
+	}
+	formattedMessages = append([]map[string]interface{}{cacheMessage}, formattedMessages...)
+	delete(preparedParams, "tools")
+
+	return formattedMessages, preparedParams
+}
+
+// moonshotPrefixLength returns the number of leading system messages, which the prompt cache
+// manager counts in the stable prefix.
+func moonshotPrefixLength(messages []schemas.BifrostMessage) int {
+	for i, msg := range messages {
+		if !msg.Role.IsInstruction() {
+			return i
+		}
+	}
+	return len(messages)
+}
+
+// moonshotCacheTools returns the function tools of a request, the ones a context cache can hold.
+func moonshotCacheTools(params *schemas.ModelParameters) []schemas.Tool {
+	if params.Tools == nil {
+		return nil
+	}
+	tools := make([]schemas.Tool, 0, len(*params.Tools))
+	for _, tool := range *params.Tools {
+		if !isBuiltInTool(tool) {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// isMoonshotCacheError reports whether a request failed because Moonshot no longer has the
+// context cache it references.
+func isMoonshotCacheError(bifrostErr *schemas.BifrostError) bool {
+	if bifrostErr.StatusCode == nil || (*bifrostErr.StatusCode != fasthttp.StatusBadRequest && *bifrostErr.StatusCode != fasthttp.StatusNotFound) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(moonshotErrorMessage(bifrostErr)), "cache")
+}
+
+// moonshotErrorMessage returns the message of an error, or of its cause.
+func moonshotErrorMessage(bifrostErr *schemas.BifrostError) string {
+	if bifrostErr.Error.Message != "" && bifrostErr.Error.Error != nil {
+		return bifrostErr.Error.Message + ": " + bifrostErr.Error.Error.Error()
+	}
+	if bifrostErr.Error.Error != nil {
+		return bifrostErr.Error.Error.Error()
+	}
+	return bifrostErr.Error.Message
+}
+
+// parseMoonshotStreamUsage extracts the usage of a raw stream chunk, which Moonshot sends in the
+// last choice, nil if it has none.
+func parseMoonshotStreamUsage(rawChunk map[string]interface{}) (*MoonshotUsage, error) {
+	value, ok := rawChunk["usage"]
+	if !ok || value == nil {
+		if choices, ok := rawChunk["choices"].([]interface{}); ok && len(choices) > 0 {
+			if choice, ok := choices[0].(map[string]interface{}); ok {
+				value = choice["usage"]
+			}
+		}
+	}
+	if value == nil {
+		return nil, nil
+	}
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var usage MoonshotUsage
+	if err := sonic.Unmarshal(data, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// applyMoonshotUsage adds the prompt tokens read from a context cache to a Bifrost usage.
+func applyMoonshotUsage(usage *schemas.LLMUsage, moonshotUsage *MoonshotUsage) {
+	if usage == nil || moonshotUsage == nil || moonshotUsage.CachedTokens == 0 {
+		return
+	}
+	if usage.TokenDetails == nil {
+		usage.TokenDetails = &schemas.TokenDetails{}
+	}
+	usage.TokenDetails.CachedTokens = moonshotUsage.CachedTokens
+}
@@ -0,0 +1,368 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the NVIDIA NIM provider implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// nimHostedBaseURL is the base URL of the NIM endpoints hosted on build.nvidia.com.
+	nimHostedBaseURL = "https://integrate.api.nvidia.com"
+	// nimHostedRetrievalURL is the base URL of the hosted reranking endpoints, which are served
+	// per model from a different host than the chat and embedding endpoints.
+	nimHostedRetrievalURL = "https://ai.api.nvidia.com/v1/retrieval"
+)
+
+// NIMRerankText is a query or passage of a NIM reranking request.
+type NIMRerankText struct {
+	Text string `json:"text"`
+}
+
+// NIMRerankResponse is the response of NIM's reranking endpoint.
+type NIMRerankResponse struct {
+	Rankings []struct {
+		Index int     `json:"index"`
+		Logit float64 `json:"logit"`
+	} `json:"rankings"`
+}
+
+// NIMProvider implements the Provider interface for NVIDIA NIM, either the endpoints hosted on
+// build.nvidia.com or self-hosted NIM containers.
+type NIMProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	streamClient        *http.Client          // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+}
+
+// NewNIMProvider creates a new NVIDIA NIM provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+// The base URL defaults to the hosted endpoints; set it to the address of a NIM container to
+// use a self-hosted one.
+func NewNIMProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*NIMProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = nimHostedBaseURL
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &NIMProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for NVIDIA NIM.
+func (provider *NIMProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.NIM
+}
+
+// WarmUp opens connections to the NIM API ahead of the first request, and to the hosted
+// reranking endpoints when the hosted API is used.
+func (provider *NIMProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	if err := warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL); err != nil {
+		return err
+	}
+	if provider.isHosted() {
+		return warmUpConnections(ctx, provider.client, nil, nimHostedRetrievalURL)
+	}
+	return nil
+}
+
+// TextCompletion is not supported by the NVIDIA NIM provider.
+func (provider *NIMProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "nim")
+}
+
+// ChatCompletion performs a chat completion request to the NIM API.
+// It formats the request, sends it to NIM, and processes the response.
+// Returns a BifrostResponse containing the completion results or an error if the request fails.
+func (provider *NIMProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+	}, preparedParams)
+
+	responseBody, bifrostErr := provider.completeRequest(ctx, key, provider.networkConfig.BaseURL+"/v1/chat/completions", requestBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	return parseReasoningChatResponse(responseBody, schemas.NIM, params, provider.sendBackRawResponse)
+}
+
+// Embedding generates embeddings for the given input text(s) using NIM's OpenAI compatible
+// embeddings endpoint. NIM's retrieval embedding models embed queries and passages differently
+// and require an input_type; it defaults to passage and can be set to query via ExtraParams.
+func (provider *NIMProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	requestBody := prepareOpenAIEmbeddingRequest(input, params)
+	requestBody["model"] = model
+	if _, ok := requestBody["input_type"]; !ok {
+		requestBody["input_type"] = "passage"
+	}
+
+	return handleOpenAIEmbeddingRequest(
+		ctx,
+		provider.client,
+		provider.networkConfig.BaseURL+"/v1/embeddings",
+		requestBody,
+		key,
+		params,
+		provider.networkConfig.ExtraHeaders,
+		schemas.NIM,
+		provider.sendBackRawResponse,
+		provider.logger,
+	)
+}
+
+// ChatCompletionStream performs a streaming chat completion request to the NIM API.
+// It supports real-time streaming of responses using Server-Sent Events (SSE).
+// Uses NIM's OpenAI-compatible streaming format.
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+func (provider *NIMProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+		"stream":   true,
+		"stream_options": map[string]interface{}{
+			"include_usage": true,
+		},
+	}, preparedParams)
+
+	// Prepare NIM headers
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+
+	// Only add Authorization header if key is provided (self-hosted NIM can run without auth)
+	if key.Value != "" {
+		headers["Authorization"] = "Bearer " + key.Value
+	}
+
+	// Use shared OpenAI-compatible streaming logic
+	return handleOpenAIStreaming(
+		ctx,
+		provider.streamClient,
+		provider.networkConfig.BaseURL+"/v1/chat/completions",
+		requestBody,
+		headers,
+		provider.networkConfig.ExtraHeaders,
+		schemas.NIM,
+		params,
+		postHookRunner,
+		provider.logger,
+	)
+}
+
+func (provider *NIMProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "nim")
+}
+
+func (provider *NIMProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "nim")
+}
+
+func (provider *NIMProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "nim")
+}
+
+func (provider *NIMProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "nim")
+}
+
+func (provider *NIMProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "nim")
+}
+
+func (provider *NIMProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "nim")
+}
+
+func (provider *NIMProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "nim")
+}
+
+func (provider *NIMProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "nim")
+}
+
+func (provider *NIMProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "nim")
+}
+
+// Rerank orders documents by their relevance to a query with a NIM reranking model, e.g.
+// nvidia/llama-3.2-nv-rerankqa-1b-v2. NIM scores documents with raw logits, which are returned
+// as the relevance scores, and has no top_n, so the results are cut to TopN here. Passages too
+// long for the model are truncated unless truncate is set to NONE via ExtraParams.
+func (provider *NIMProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	passages := make([]NIMRerankText, len(input.Documents))
+	for i, document := range input.Documents {
+		passages[i] = NIMRerankText{Text: document}
+	}
+
+	requestBody := map[string]interface{}{
+		"model":    model,
+		"query":    NIMRerankText{Text: input.Query},
+		"passages": passages,
+		"truncate": "END",
+	}
+	if params != nil {
+		requestBody = mergeConfig(requestBody, params.ExtraParams)
+	}
+
+	responseBody, bifrostErr := provider.completeRequest(ctx, key, provider.rerankURL(model), requestBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var nimResp NIMRerankResponse
+	rawResponse, bifrostErr := handleProviderResponse(responseBody, &nimResp, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	results := make([]schemas.BifrostRerankResult, 0, len(nimResp.Rankings))
+	for _, ranking := range nimResp.Rankings {
+		rerankResult := schemas.BifrostRerankResult{
+			Index:          ranking.Index,
+			RelevanceScore: ranking.Logit,
+		}
+		if input.ReturnDocuments != nil && *input.ReturnDocuments && ranking.Index >= 0 && ranking.Index < len(input.Documents) {
+			rerankResult.Document = &input.Documents[ranking.Index]
+		}
+		results = append(results, rerankResult)
+	}
+	slices.SortStableFunc(results, func(a, b schemas.BifrostRerankResult) int {
+		switch {
+		case a.RelevanceScore > b.RelevanceScore:
+			return -1
+		case a.RelevanceScore < b.RelevanceScore:
+			return 1
+		}
+		return 0
+	})
+	if input.TopN != nil && *input.TopN >= 0 && *input.TopN < len(results) {
+		results = results[:*input.TopN]
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		Object: "rerank",
+		Rerank: results,
+		Model:  model,
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.NIM,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// isHosted reports whether the provider uses the endpoints hosted on build.nvidia.com rather
+// than a self-hosted NIM container.
+func (provider *NIMProvider) isHosted() bool {
+	return provider.networkConfig.BaseURL == nimHostedBaseURL
+}
+
+// rerankURL returns the reranking endpoint of a model. Hosted reranking models each have their
+// own endpoint, whose path is the model name with dots replaced by underscores, while a
+// self-hosted NIM container serves its model at /v1/ranking.
+func (provider *NIMProvider) rerankURL(model string) string {
+	if provider.isHosted() {
+		return nimHostedRetrievalURL + "/" + strings.ReplaceAll(model, ".", "_") + "/reranking"
+	}
+	return provider.networkConfig.BaseURL + "/v1/ranking"
+}
+
+// completeRequest sends a request to a NIM endpoint and returns the response body.
+func (provider *NIMProvider) completeRequest(ctx context.Context, key schemas.Key, url string, requestBody map[string]interface{}) ([]byte, *schemas.BifrostError) {
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.NIM)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(url)
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	if key.Value != "" {
+		req.Header.Set("Authorization", "Bearer "+key.Value)
+	}
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from nim provider: %s", string(resp.Body())))
+
+		var errorResp map[string]interface{}
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		bifrostErr.Error.Message = fmt.Sprintf("NIM error: %v", errorResp)
+		return nil, bifrostErr
+	}
+
+	// Copy the body, the response is released on return
+	return append([]byte(nil), resp.Body()...), nil
+}
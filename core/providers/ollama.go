@@ -1,10 +1,12 @@
 // Package providers implements various LLM providers and their utility functions.
-// This file contains the Ollama provider implementation.
+// This file contains the Ollama provider implementation, which uses Ollama's native API.
 package providers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -35,7 +37,9 @@ import (
 // 	}
 // }
 
-// OllamaProvider implements the Provider interface for Ollama's API.
+// OllamaProvider implements the Provider interface for Ollama's native API (/api/chat and
+// /api/embeddings), rather than its OpenAI compatible endpoints, so Ollama specific options
+// such as num_ctx and keep_alive can be passed in ExtraParams.
 type OllamaProvider struct {
 	logger              schemas.Logger        // Logger for provider operations
 	client              *fasthttp.Client      // HTTP client for API requests
@@ -69,6 +73,10 @@ func NewOllamaProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*
 	// Configure proxy if provided
 	client = configureProxy(client, config.ProxyConfig, logger)
 
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
 	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
 
 	// BaseURL is required for Ollama
@@ -90,19 +98,22 @@ func (provider *OllamaProvider) GetProviderKey() schemas.ModelProvider {
 	return schemas.Ollama
 }
 
+// WarmUp opens connections to the Ollama API ahead of the first request.
+func (provider *OllamaProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
 // TextCompletion is not supported by the Ollama provider.
 func (provider *OllamaProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("text completion", "ollama")
 }
 
-// ChatCompletion performs a chat completion request to the Ollama API.
+// ChatCompletion performs a chat completion request to Ollama's native /api/chat endpoint.
 func (provider *OllamaProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
-	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
-
-	requestBody := mergeConfig(map[string]interface{}{
-		"model":    model,
-		"messages": formattedMessages,
-	}, preparedParams)
+	requestBody, bifrostErr := prepareOllamaChatRequest(model, messages, params, false)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
 
 	jsonBody, err := sonic.Marshal(requestBody)
 	if err != nil {
@@ -118,7 +129,7 @@ func (provider *OllamaProvider) ChatCompletion(ctx context.Context, model string
 	// Set any extra headers from network config
 	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
 
-	req.SetRequestURI(provider.networkConfig.BaseURL + "/v1/chat/completions")
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/api/chat")
 	req.Header.SetMethod("POST")
 	req.Header.SetContentType("application/json")
 	if key.Value != "" {
@@ -128,7 +139,7 @@ func (provider *OllamaProvider) ChatCompletion(ctx context.Context, model string
 	req.SetBody(jsonBody)
 
 	// Make request
-	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	bifrostErr = makeRequestWithContext(ctx, provider.client, req, resp)
 	if bifrostErr != nil {
 		return nil, bifrostErr
 	}
@@ -137,26 +148,57 @@ func (provider *OllamaProvider) ChatCompletion(ctx context.Context, model string
 	if resp.StatusCode() != fasthttp.StatusOK {
 		provider.logger.Debug(fmt.Sprintf("error from ollama provider: %s", string(resp.Body())))
 
-		var errorResp map[string]interface{}
+		var errorResp OllamaError
 		bifrostErr := handleProviderAPIError(resp, &errorResp)
-		bifrostErr.Error.Message = fmt.Sprintf("Ollama error: %v", errorResp)
+		if errorResp.Error != "" {
+			bifrostErr.Error.Message = errorResp.Error
+		}
 		return nil, bifrostErr
 	}
 
-	responseBody := resp.Body()
-
-	// Pre-allocate response structs from pools
-	// response := acquireOllamaResponse()
-	// defer releaseOllamaResponse(response)
-	response := &schemas.BifrostResponse{}
-
-	// Use enhanced response handler with pre-allocated response
-	rawResponse, bifrostErr := handleProviderResponse(responseBody, response, provider.sendBackRawResponse)
+	var ollamaResponse OllamaChatResponse
+	rawResponse, bifrostErr := handleProviderResponse(resp.Body(), &ollamaResponse, provider.sendBackRawResponse)
 	if bifrostErr != nil {
 		return nil, bifrostErr
 	}
 
-	response.ExtraFields.Provider = schemas.Ollama
+	var content string
+	assistantMessage := &schemas.AssistantMessage{}
+	var toolCalls []schemas.ToolCall
+	if ollamaResponse.Message != nil {
+		content = ollamaResponse.Message.Content
+		if ollamaResponse.Message.Thinking != "" {
+			assistantMessage.Thought = &ollamaResponse.Message.Thinking
+		}
+		if len(ollamaResponse.Message.ToolCalls) > 0 {
+			toolCalls = convertOllamaToolCalls(ollamaResponse.Message.ToolCalls, 0)
+			assistantMessage.ToolCalls = &toolCalls
+		}
+	}
+
+	response := &schemas.BifrostResponse{
+		Object: "chat.completion",
+		Choices: []schemas.BifrostResponseChoice{
+			{
+				Index: 0,
+				BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
+					Message: schemas.BifrostMessage{
+						Role:             schemas.ModelChatMessageRoleAssistant,
+						Content:          schemas.MessageContent{ContentStr: &content},
+						AssistantMessage: assistantMessage,
+					},
+				},
+				FinishReason: Ptr(mapOllamaFinishReason(ollamaResponse.DoneReason, len(toolCalls) > 0)),
+			},
+		},
+		Model:   model,
+		Created: parseOllamaTimestamp(ollamaResponse.CreatedAt),
+		Usage:   ollamaResponse.usage(),
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider:       schemas.Ollama,
+			ProviderTiming: ollamaResponse.timing(),
+		},
+	}
 
 	if provider.sendBackRawResponse {
 		response.ExtraFields.RawResponse = rawResponse
@@ -169,49 +211,590 @@ func (provider *OllamaProvider) ChatCompletion(ctx context.Context, model string
 	return response, nil
 }
 
-// Embedding is not supported by the Ollama provider.
+// Embedding generates embeddings with Ollama's native /api/embeddings endpoint. The endpoint
+// embeds one prompt per request, so texts are embedded one after the other. Ollama does not
+// report token usage for embeddings.
 func (provider *OllamaProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
-	return nil, newUnsupportedOperationError("embedding", "ollama")
+	var texts []string
+	switch {
+	case input.Text != nil:
+		texts = []string{*input.Text}
+	case len(input.Texts) > 0:
+		texts = input.Texts
+	default:
+		return nil, newConfigurationError("ollama embeddings only accept text input", schemas.Ollama)
+	}
+
+	options, topLevel := prepareOllamaOptions(params)
+
+	response := &schemas.BifrostResponse{
+		Object: "list",
+		Data:   make([]schemas.BifrostEmbedding, 0, len(texts)),
+		Model:  model,
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Ollama,
+		},
+	}
+	var rawResponses []interface{}
+
+	for i, text := range texts {
+		requestBody := OllamaEmbeddingRequest{
+			Model:     model,
+			Prompt:    text,
+			Options:   options,
+			KeepAlive: topLevel["keep_alive"],
+		}
+
+		jsonBody, err := sonic.Marshal(requestBody)
+		if err != nil {
+			return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Ollama)
+		}
+
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+
+		setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+		req.SetRequestURI(provider.networkConfig.BaseURL + "/api/embeddings")
+		req.Header.SetMethod("POST")
+		req.Header.SetContentType("application/json")
+		if key.Value != "" {
+			req.Header.Set("Authorization", "Bearer "+key.Value)
+		}
+
+		req.SetBody(jsonBody)
+
+		bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+		if bifrostErr == nil && resp.StatusCode() != fasthttp.StatusOK {
+			provider.logger.Debug(fmt.Sprintf("error from ollama provider: %s", string(resp.Body())))
+
+			var errorResp OllamaError
+			bifrostErr = handleProviderAPIError(resp, &errorResp)
+			if errorResp.Error != "" {
+				bifrostErr.Error.Message = errorResp.Error
+			}
+		}
+
+		var ollamaResponse OllamaEmbeddingResponse
+		var rawResponse interface{}
+		if bifrostErr == nil {
+			rawResponse, bifrostErr = handleProviderResponse(resp.Body(), &ollamaResponse, provider.sendBackRawResponse)
+		}
+
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		response.Data = append(response.Data, schemas.BifrostEmbedding{
+			Index:  i,
+			Object: "embedding",
+			Embedding: schemas.BifrostEmbeddingResponse{
+				EmbeddingArray: &ollamaResponse.Embedding,
+			},
+		})
+		rawResponses = append(rawResponses, rawResponse)
+	}
+
+	if provider.sendBackRawResponse {
+		response.ExtraFields.RawResponse = rawResponses
+	}
+
+	if params != nil {
+		response.ExtraFields.Params = *params
+	}
+
+	return response, nil
 }
 
-// ChatCompletionStream performs a streaming chat completion request to the Ollama API.
-// It supports real-time streaming of responses using Server-Sent Events (SSE).
-// Uses Ollama's OpenAI-compatible streaming format.
+// ChatCompletionStream performs a streaming chat completion request to Ollama's native /api/chat
+// endpoint, which streams newline delimited JSON objects: one per generated piece of the message,
+// the last of which is marked done and carries the usage and timings of the request.
 // Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
 func (provider *OllamaProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
-	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+	providerName := provider.GetProviderKey()
+
+	requestBody, bifrostErr := prepareOllamaChatRequest(model, messages, params, true)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
+	}
 
-	requestBody := mergeConfig(map[string]interface{}{
-		"model":    model,
-		"messages": formattedMessages,
-		"stream":   true,
-	}, preparedParams)
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
 
-	// Prepare Ollama headers (Ollama typically doesn't require authorization, but we include it if provided)
-	headers := map[string]string{
-		"Content-Type":  "application/json",
-		"Accept":        "text/event-stream",
-		"Cache-Control": "no-cache",
+	// Create HTTP request for streaming
+	req, err := http.NewRequestWithContext(ctx, "POST", provider.networkConfig.BaseURL+"/api/chat", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, providerName)
 	}
 
-	// Only add Authorization header if key is provided (Ollama can run without auth)
+	// Ollama can run without auth, the key is only sent when set
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/x-ndjson")
 	if key.Value != "" {
-		headers["Authorization"] = "Bearer " + key.Value
+		req.Header.Set("Authorization", "Bearer "+key.Value)
+	}
+
+	// Set any extra headers from network config
+	setExtraHeadersHTTP(req, provider.networkConfig.ExtraHeaders, nil)
+
+	// Make the request
+	resp, err := provider.streamClient.Do(req)
+	if err != nil {
+		return nil, &schemas.BifrostError{
+			IsBifrostError: false,
+			Origin:         requestErrorOrigin(err),
+			Error: schemas.ErrorField{
+				Message: schemas.ErrProviderRequest,
+				Error:   err,
+			},
+		}
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		message := fmt.Sprintf("HTTP error from %s: %d", providerName, resp.StatusCode)
+		var errorResp OllamaError
+		if err := sonic.Unmarshal(body, &errorResp); err == nil && errorResp.Error != "" {
+			message = errorResp.Error
+		}
+		return nil, newProviderAPIError(message, fmt.Errorf("%s", string(body)), resp.StatusCode, providerName, nil, nil)
+	}
+
+	// Create response channel
+	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+
+	// Start streaming in a goroutine
+	go func() {
+		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, provider.logger)
+		defer resp.Body.Close()
+
+		// Tool calls arrive whole, so lines can exceed the default maximum line size
+		scanner := newStreamScanner(resp.Body)
+		chunkIndex := -1
+		toolCallCount := 0
+		hasToolCalls := false
+
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			// Some servers write several objects on one line
+			objects, _ := splitStreamDataObjects(line)
+			for _, jsonData := range objects {
+				var chunk OllamaChatResponse
+				if err := sonic.Unmarshal([]byte(jsonData), &chunk); err != nil {
+					provider.logger.Warn(fmt.Sprintf("Failed to parse stream chunk: %v", err))
+					continue
+				}
+
+				// Errors after the stream started are sent as a line of their own
+				if chunk.Error != "" {
+					processAndSendBifrostError(ctx, postHookRunner, newProviderAPIError(chunk.Error, nil, http.StatusInternalServerError, providerName, nil, nil), responseChan, provider.logger)
+					return
+				}
+
+				if chunk.Message != nil && (chunk.Message.Content != "" || chunk.Message.Thinking != "" || len(chunk.Message.ToolCalls) > 0) {
+					delta := schemas.BifrostStreamDelta{}
+					if chunkIndex == -1 {
+						delta.Role = Ptr(string(schemas.ModelChatMessageRoleAssistant))
+					}
+					if chunk.Message.Content != "" {
+						delta.Content = &chunk.Message.Content
+					}
+					if chunk.Message.Thinking != "" {
+						delta.Thought = &chunk.Message.Thinking
+					}
+					if len(chunk.Message.ToolCalls) > 0 {
+						delta.ToolCalls = convertOllamaToolCalls(chunk.Message.ToolCalls, toolCallCount)
+						toolCallCount += len(chunk.Message.ToolCalls)
+						hasToolCalls = true
+					}
+					chunkIndex++
+
+					processAndSendResponse(ctx, postHookRunner, &schemas.BifrostResponse{
+						Object:  "chat.completion.chunk",
+						Model:   model,
+						Created: parseOllamaTimestamp(chunk.CreatedAt),
+						Choices: []schemas.BifrostResponseChoice{
+							{
+								Index: 0,
+								BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+									Delta: delta,
+								},
+							},
+						},
+						ExtraFields: schemas.BifrostResponseExtraFields{
+							Provider:   providerName,
+							ChunkIndex: chunkIndex,
+						},
+					}, responseChan, provider.logger)
+				}
+
+				if chunk.Done {
+					finishReason := mapOllamaFinishReason(chunk.DoneReason, hasToolCalls)
+					response := createBifrostChatCompletionChunkResponse("", chunk.usage(), &finishReason, chunkIndex, params, providerName)
+					response.Model = model
+					response.Created = parseOllamaTimestamp(chunk.CreatedAt)
+					response.ExtraFields.ProviderTiming = chunk.timing()
+
+					handleStreamEndWithSuccess(ctx, response, postHookRunner, responseChan, provider.logger)
+					return // End of stream
+				}
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			provider.logger.Warn(fmt.Sprintf("Error reading stream: %v", err))
+			processAndSendError(ctx, postHookRunner, err, responseChan, provider.logger)
+		}
+	}()
+
+	return responseChan, nil
+}
+
+// OllamaMessage represents a message of Ollama's native chat API
+type OllamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	Images    []string         `json:"images,omitempty"` // Base64 encoded images, without data URL prefix
+	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"` // Name of the tool a tool message is the result of
+}
+
+// OllamaToolCall represents a tool call of Ollama's native chat API. Tool calls have no IDs and
+// their arguments are a JSON object rather than a string.
+type OllamaToolCall struct {
+	Function OllamaToolCallFunction `json:"function"`
+}
+
+// OllamaToolCallFunction represents the function called by an Ollama tool call
+type OllamaToolCallFunction struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// OllamaTool represents a function tool of Ollama's native chat API
+type OllamaTool struct {
+	Type     string           `json:"type"`
+	Function schemas.Function `json:"function"`
+}
+
+// OllamaChatRequest represents a request to Ollama's /api/chat endpoint
+type OllamaChatRequest struct {
+	Model     string                 `json:"model"`
+	Messages  []OllamaMessage        `json:"messages"`
+	Tools     []OllamaTool           `json:"tools,omitempty"`
+	Format    interface{}            `json:"format,omitempty"`     // "json" or a JSON schema
+	Options   map[string]interface{} `json:"options,omitempty"`    // Model options, e.g. num_ctx or temperature
+	Stream    bool                   `json:"stream"`               // Ollama streams unless told not to
+	KeepAlive interface{}            `json:"keep_alive,omitempty"` // How long the model stays loaded, e.g. "5m" or a number of seconds
+	Think     interface{}            `json:"think,omitempty"`      // Whether thinking models think before answering
+}
+
+// OllamaChatResponse represents a response, or stream chunk, of Ollama's /api/chat endpoint.
+// Durations are in nanoseconds.
+type OllamaChatResponse struct {
+	Model              string         `json:"model"`
+	CreatedAt          string         `json:"created_at"`
+	Message            *OllamaMessage `json:"message,omitempty"`
+	Done               bool           `json:"done"`
+	DoneReason         string         `json:"done_reason,omitempty"`
+	TotalDuration      int64          `json:"total_duration,omitempty"`
+	LoadDuration       int64          `json:"load_duration,omitempty"`
+	PromptEvalCount    int            `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64          `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int            `json:"eval_count,omitempty"`
+	EvalDuration       int64          `json:"eval_duration,omitempty"`
+	Error              string         `json:"error,omitempty"` // Set on errors in the middle of a stream
+}
+
+// OllamaEmbeddingRequest represents a request to Ollama's /api/embeddings endpoint
+type OllamaEmbeddingRequest struct {
+	Model     string                 `json:"model"`
+	Prompt    string                 `json:"prompt"`
+	Options   map[string]interface{} `json:"options,omitempty"`
+	KeepAlive interface{}            `json:"keep_alive,omitempty"`
+}
+
+// OllamaEmbeddingResponse represents a response of Ollama's /api/embeddings endpoint
+type OllamaEmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+// OllamaError represents an Ollama error response
+type OllamaError struct {
+	Error string `json:"error"`
+}
+
+// ollamaRequestParams are the ExtraParams that are fields of Ollama requests. Other ExtraParams,
+// such as num_ctx or seed, are model options.
+var ollamaRequestParams = map[string]bool{
+	"format":     true,
+	"keep_alive": true,
+	"think":      true,
+}
+
+// prepareOllamaOptions splits the parameters of a request into Ollama model options and request
+// fields. ExtraParams may also set options in an "options" object, and an OpenAI style
+// response_format is converted to Ollama's format.
+func prepareOllamaOptions(params *schemas.ModelParameters) (map[string]interface{}, map[string]interface{}) {
+	options := make(map[string]interface{})
+	fields := make(map[string]interface{})
+	if params == nil {
+		return nil, fields
+	}
+
+	if params.Temperature != nil {
+		options["temperature"] = *params.Temperature
+	}
+	if params.TopP != nil {
+		options["top_p"] = *params.TopP
+	}
+	if params.TopK != nil {
+		options["top_k"] = *params.TopK
+	}
+	if params.MaxTokens != nil {
+		options["num_predict"] = *params.MaxTokens
+	}
+	if params.StopSequences != nil {
+		options["stop"] = *params.StopSequences
+	}
+	if params.PresencePenalty != nil {
+		options["presence_penalty"] = *params.PresencePenalty
+	}
+	if params.FrequencyPenalty != nil {
+		options["frequency_penalty"] = *params.FrequencyPenalty
+	}
+
+	for key, value := range params.ExtraParams {
+		switch {
+		case key == "options":
+			if extraOptions, ok := value.(map[string]interface{}); ok {
+				for option, optionValue := range extraOptions {
+					options[option] = optionValue
+				}
+			}
+		case key == "response_format":
+			if _, ok := params.ExtraParams["format"]; !ok {
+				if format := convertResponseFormatToOllama(value); format != nil {
+					fields["format"] = format
+				}
+			}
+		case ollamaRequestParams[key]:
+			fields[key] = value
+		default:
+			options[key] = value
+		}
+	}
+
+	if len(options) == 0 {
+		options = nil
+	}
+	return options, fields
+}
+
+// convertResponseFormatToOllama converts an OpenAI style response_format to Ollama's format:
+// "json" for JSON objects and the schema itself for JSON schemas.
+func convertResponseFormatToOllama(responseFormat interface{}) interface{} {
+	format, ok := responseFormat.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	switch format["type"] {
+	case "json_object":
+		return "json"
+	case "json_schema":
+		if jsonSchema, ok := format["json_schema"].(map[string]interface{}); ok {
+			return jsonSchema["schema"]
+		}
+	}
+	return nil
+}
+
+// prepareOllamaChatRequest builds a request to Ollama's /api/chat endpoint.
+func prepareOllamaChatRequest(model string, messages []schemas.BifrostMessage, params *schemas.ModelParameters, stream bool) (*OllamaChatRequest, *schemas.BifrostError) {
+	ollamaMessages, err := convertToOllamaMessages(messages)
+	if err != nil {
+		return nil, newConfigurationError(err.Error(), schemas.Ollama)
+	}
+
+	options, fields := prepareOllamaOptions(params)
+	request := &OllamaChatRequest{
+		Model:     model,
+		Messages:  ollamaMessages,
+		Options:   options,
+		Stream:    stream,
+		Format:    fields["format"],
+		KeepAlive: fields["keep_alive"],
+		Think:     fields["think"],
+	}
+
+	if params != nil && params.Tools != nil {
+		for _, tool := range *params.Tools {
+			// Ollama only has function tools
+			if tool.Type != "function" {
+				continue
+			}
+			request.Tools = append(request.Tools, OllamaTool{Type: "function", Function: tool.Function})
+		}
+	}
+
+	return request, nil
+}
+
+// convertToOllamaMessages converts Bifrost messages to Ollama messages. Text blocks are joined and
+// images are sent as base64. Tool results are given the name of the tool call they answer, since
+// Ollama tool calls have no IDs.
+func convertToOllamaMessages(messages []schemas.BifrostMessage) ([]OllamaMessage, error) {
+	ollamaMessages := make([]OllamaMessage, 0, len(messages))
+	toolNames := make(map[string]string)
+
+	for _, msg := range messages {
+		ollamaMessage := OllamaMessage{Role: string(msg.Role)}
+
+		if msg.Content.ContentStr != nil {
+			ollamaMessage.Content = *msg.Content.ContentStr
+		} else if msg.Content.ContentBlocks != nil {
+			var texts []string
+			for _, block := range *msg.Content.ContentBlocks {
+				if block.Text != nil {
+					texts = append(texts, *block.Text)
+				} else if block.ImageURL != nil {
+					sanitizedURL, err := SanitizeImageURL(block.ImageURL.URL)
+					if err != nil {
+						return nil, fmt.Errorf("invalid image: %w", err)
+					}
+					urlTypeInfo := ExtractURLTypeInfo(sanitizedURL)
+					if urlTypeInfo.Type != ImageContentTypeBase64 || urlTypeInfo.DataURLWithoutPrefix == nil {
+						return nil, fmt.Errorf("ollama only accepts base64 encoded images, not image URLs")
+					}
+					ollamaMessage.Images = append(ollamaMessage.Images, *urlTypeInfo.DataURLWithoutPrefix)
+				}
+			}
+			ollamaMessage.Content = strings.Join(texts, "\n")
+		}
+
+		if msg.AssistantMessage != nil {
+			if msg.AssistantMessage.Thought != nil {
+				ollamaMessage.Thinking = *msg.AssistantMessage.Thought
+			}
+			if msg.AssistantMessage.ToolCalls != nil {
+				for _, toolCall := range *msg.AssistantMessage.ToolCalls {
+					var name string
+					if toolCall.Function.Name != nil {
+						name = *toolCall.Function.Name
+					}
+					if toolCall.ID != nil {
+						toolNames[*toolCall.ID] = name
+					}
+					arguments := make(map[string]interface{})
+					if toolCall.Function.Arguments != "" {
+						if err := sonic.Unmarshal([]byte(toolCall.Function.Arguments), &arguments); err != nil {
+							return nil, fmt.Errorf("arguments of tool call %s are not a JSON object: %w", name, err)
+						}
+					}
+					ollamaMessage.ToolCalls = append(ollamaMessage.ToolCalls, OllamaToolCall{
+						Function: OllamaToolCallFunction{Name: name, Arguments: arguments},
+					})
+				}
+			}
+		}
+
+		if msg.ToolMessage != nil && msg.ToolMessage.ToolCallID != nil {
+			ollamaMessage.ToolName = toolNames[*msg.ToolMessage.ToolCallID]
+		}
+
+		ollamaMessages = append(ollamaMessages, ollamaMessage)
+	}
+
+	return ollamaMessages, nil
+}
+
+// convertOllamaToolCalls converts Ollama tool calls to Bifrost tool calls. Ollama tool calls have
+// no IDs, they are numbered from offset in the order of the response.
+func convertOllamaToolCalls(toolCalls []OllamaToolCall, offset int) []schemas.ToolCall {
+	converted := make([]schemas.ToolCall, 0, len(toolCalls))
+	for i, toolCall := range toolCalls {
+		arguments, err := sonic.Marshal(toolCall.Function.Arguments)
+		if err != nil || toolCall.Function.Arguments == nil {
+			arguments = []byte("{}")
+		}
+		converted = append(converted, schemas.ToolCall{
+			Type: Ptr("function"),
+			ID:   Ptr(fmt.Sprintf("call_%d", offset+i)),
+			Function: schemas.FunctionCall{
+				Name:      Ptr(toolCall.Function.Name),
+				Arguments: string(arguments),
+			},
+		})
+	}
+	return converted
+}
+
+// mapOllamaFinishReason maps Ollama's done reason to an OpenAI style finish reason.
+func mapOllamaFinishReason(doneReason string, hasToolCalls bool) string {
+	switch {
+	case hasToolCalls:
+		return "tool_calls"
+	case doneReason == "length":
+		return "length"
+	default:
+		return "stop"
+	}
+}
+
+// parseOllamaTimestamp returns the Unix time of an Ollama created_at timestamp, or 0 if it is invalid.
+func parseOllamaTimestamp(createdAt string) int {
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return 0
+	}
+	return int(t.Unix())
+}
+
+// usage returns the token usage of a final Ollama response.
+func (response *OllamaChatResponse) usage() *schemas.LLMUsage {
+	if !response.Done {
+		return nil
+	}
+	return &schemas.LLMUsage{
+		PromptTokens:     response.PromptEvalCount,
+		CompletionTokens: response.EvalCount,
+		TotalTokens:      response.PromptEvalCount + response.EvalCount,
 	}
+}
 
-	// Use shared OpenAI-compatible streaming logic
-	return handleOpenAIStreaming(
-		ctx,
-		provider.streamClient,
-		provider.networkConfig.BaseURL+"/v1/chat/completions",
-		requestBody,
-		headers,
-		provider.networkConfig.ExtraHeaders,
-		schemas.Ollama,
-		params,
-		postHookRunner,
-		provider.logger,
-	)
+// timing returns the timings of a final Ollama response in seconds. The time spent loading the
+// model is reported as queue time.
+func (response *OllamaChatResponse) timing() *schemas.ProviderTiming {
+	if !response.Done || response.TotalDuration == 0 {
+		return nil
+	}
+	timing := &schemas.ProviderTiming{
+		QueueTime:      time.Duration(response.LoadDuration).Seconds(),
+		PromptTime:     time.Duration(response.PromptEvalDuration).Seconds(),
+		CompletionTime: time.Duration(response.EvalDuration).Seconds(),
+		TotalTime:      time.Duration(response.TotalDuration).Seconds(),
+	}
+	if response.EvalDuration > 0 {
+		timing.OutputTokensPerSecond = float64(response.EvalCount) / timing.CompletionTime
+	}
+	return timing
 }
 
 func (provider *OllamaProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
@@ -229,3 +812,27 @@ func (provider *OllamaProvider) Transcription(ctx context.Context, model string,
 func (provider *OllamaProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("transcription stream", "ollama")
 }
+
+func (provider *OllamaProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "ollama")
+}
+
+func (provider *OllamaProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "ollama")
+}
+
+func (provider *OllamaProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "ollama")
+}
+
+func (provider *OllamaProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "ollama")
+}
+
+func (provider *OllamaProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "ollama")
+}
+
+func (provider *OllamaProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "ollama")
+}
@@ -3,7 +3,6 @@
 package providers
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"fmt"
@@ -74,6 +73,10 @@ func NewOpenAIProvider(config *schemas.ProviderConfig, logger schemas.Logger) *O
 	// Configure proxy if provided
 	client = configureProxy(client, config.ProxyConfig, logger)
 
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
 		config.NetworkConfig.BaseURL = "https://api.openai.com"
@@ -95,6 +98,11 @@ func (provider *OpenAIProvider) GetProviderKey() schemas.ModelProvider {
 	return getProviderName(schemas.OpenAI, provider.customProviderConfig)
 }
 
+// WarmUp opens connections to the OpenAI API ahead of the first request.
+func (provider *OpenAIProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
 // TextCompletion is not supported by the OpenAI provider.
 // Returns an error indicating that text completion is not available.
 func (provider *OpenAIProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
@@ -109,15 +117,20 @@ func (provider *OpenAIProvider) ChatCompletion(ctx context.Context, model string
 	if err := checkOperationAllowed(schemas.OpenAI, provider.customProviderConfig, schemas.OperationChatCompletion); err != nil {
 		return nil, err
 	}
+	if err := checkOpenAIChatTools(params, provider.GetProviderKey()); err != nil {
+		return nil, err
+	}
 
 	providerName := provider.GetProviderKey()
 
 	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+	setServiceTier(schemas.OpenAI, params, preparedParams)
 
 	requestBody := mergeConfig(map[string]interface{}{
 		"model":    model,
 		"messages": formattedMessages,
 	}, preparedParams)
+	setOpenAIPromptCacheKey(requestBody, params)
 
 	jsonBody, err := sonic.Marshal(requestBody)
 	if err != nil {
@@ -161,21 +174,7 @@ func (provider *OpenAIProvider) ChatCompletion(ctx context.Context, model string
 	}
 
 	// Map reasoning_content/reasoning to thought in message
-	if choices, ok := rawMap["choices"].([]interface{}); ok {
-		for _, choice := range choices {
-			if choiceMap, ok := choice.(map[string]interface{}); ok {
-				if message, ok := choiceMap["message"].(map[string]interface{}); ok {
-					if rc, exists := message["reasoning_content"]; exists {
-						message["thought"] = rc
-						delete(message, "reasoning_content")
-					} else if r, exists := message["reasoning"]; exists {
-						message["thought"] = r
-						delete(message, "reasoning")
-					}
-				}
-			}
-		}
-	}
+	mapReasoningFields(rawMap, "message")
 
 	// Re-marshal and parse into BifrostResponse
 	modifiedBody, err := sonic.Marshal(rawMap)
@@ -188,6 +187,8 @@ func (provider *OpenAIProvider) ChatCompletion(ctx context.Context, model string
 		return nil, newBifrostOperationError(schemas.ErrProviderResponseUnmarshal, err, providerName)
 	}
 
+	populateCitations(response)
+
 	response.ExtraFields.Provider = providerName
 
 	if provider.sendBackRawResponse {
@@ -201,6 +202,17 @@ func (provider *OpenAIProvider) ChatCompletion(ctx context.Context, model string
 	return response, nil
 }
 
+// setOpenAIPromptCacheKey sends the prefix key of a prompt cache hint as prompt_cache_key,
+// so requests sharing a prefix are routed to the same cache. An explicit key is kept.
+func setOpenAIPromptCacheKey(requestBody map[string]interface{}, params *schemas.ModelParameters) {
+	if params == nil || params.PromptCache == nil || params.PromptCache.PrefixKey == "" {
+		return
+	}
+	if _, exists := requestBody["prompt_cache_key"]; !exists {
+		requestBody["prompt_cache_key"] = params.PromptCache.PrefixKey
+	}
+}
+
 // prepareOpenAIChatRequest formats messages for the OpenAI API.
 // It handles both text and image content in messages.
 // Returns a slice of formatted messages and any additional parameters.
@@ -245,6 +257,7 @@ func prepareOpenAIChatRequest(messages []schemas.BifrostMessage, params *schemas
 	}
 
 	preparedParams := prepareParams(params)
+	convertOpenAIBuiltInTools(params, preparedParams)
 
 	return formattedMessages, preparedParams
 }
@@ -369,8 +382,12 @@ func (provider *OpenAIProvider) ChatCompletionStream(ctx context.Context, postHo
 	if err := checkOperationAllowed(schemas.OpenAI, provider.customProviderConfig, schemas.OperationChatCompletionStream); err != nil {
 		return nil, err
 	}
+	if err := checkOpenAIChatTools(params, provider.GetProviderKey()); err != nil {
+		return nil, err
+	}
 
 	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+	setServiceTier(schemas.OpenAI, params, preparedParams)
 
 	requestBody := mergeConfig(map[string]interface{}{
 		"model":    model,
@@ -380,6 +397,7 @@ func (provider *OpenAIProvider) ChatCompletionStream(ctx context.Context, postHo
 			"include_usage": true,
 		},
 	}, preparedParams)
+	setOpenAIPromptCacheKey(requestBody, params)
 
 	// Prepare OpenAI headers
 	headers := map[string]string{
@@ -406,6 +424,14 @@ func (provider *OpenAIProvider) ChatCompletionStream(ctx context.Context, postHo
 	)
 }
 
+// openAIStreamChunkHook lets OpenAI-compatible providers map non-standard fields of a raw
+// stream chunk onto the parsed response before it is sent.
+type openAIStreamChunkHook func(rawChunk map[string]interface{}, response *schemas.BifrostResponse)
+
+// openAIStreamEndHook lets OpenAI-compatible providers add what they collected from the chunks
+// to the final response of a stream before it is sent.
+type openAIStreamEndHook func(response *schemas.BifrostResponse)
+
 // performOpenAICompatibleStreaming handles streaming for OpenAI-compatible APIs (OpenAI, Azure).
 // This shared function reduces code duplication between providers that use the same SSE format.
 func handleOpenAIStreaming(
@@ -420,12 +446,35 @@ func handleOpenAIStreaming(
 	postHookRunner schemas.PostHookRunner,
 	logger schemas.Logger,
 ) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return handleOpenAIStreamingWithHook(ctx, httpClient, url, requestBody, headers, extraHeaders, providerName, params, postHookRunner, logger, nil, nil)
+}
+
+// handleOpenAIStreamingWithHook is handleOpenAIStreaming with optional hooks called for every parsed chunk
+// and for the final response.
+func handleOpenAIStreamingWithHook(
+	ctx context.Context,
+	httpClient *http.Client,
+	url string,
+	requestBody map[string]interface{},
+	headers map[string]string,
+	extraHeaders map[string]string,
+	providerName schemas.ModelProvider,
+	params *schemas.ModelParameters,
+	postHookRunner schemas.PostHookRunner,
+	logger schemas.Logger,
+	chunkHook openAIStreamChunkHook,
+	endHook openAIStreamEndHook,
+) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 
 	jsonBody, err := sonic.Marshal(requestBody)
 	if err != nil {
 		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
 	}
 
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
 	// Create HTTP request for streaming
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
 	if err != nil {
@@ -451,20 +500,40 @@ func handleOpenAIStreaming(
 		return nil, parseStreamOpenAIError(resp)
 	}
 
+	return streamOpenAIResponse(ctx, resp, providerName, params, postHookRunner, logger, chunkHook, endHook), nil
+}
+
+// streamOpenAIResponse reads the chunks of an OpenAI-compatible stream from a successful
+// response in a goroutine and sends them on the returned channel, for providers that open
+// their streams themselves.
+func streamOpenAIResponse(
+	ctx context.Context,
+	resp *http.Response,
+	providerName schemas.ModelProvider,
+	params *schemas.ModelParameters,
+	postHookRunner schemas.PostHookRunner,
+	logger schemas.Logger,
+	chunkHook openAIStreamChunkHook,
+	endHook openAIStreamEndHook,
+) chan *schemas.BifrostStream {
 	// Create response channel
 	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
 
 	// Start streaming in a goroutine
 	go func() {
 		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, logger)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
+		// Lines packing several objects can exceed the default maximum line size
+		scanner := newStreamScanner(resp.Body)
 		chunkIndex := -1
 		usage := &schemas.LLMUsage{}
 
 		var finishReason *string
 		var id string
+		// Whether the stream ended with [DONE], some backends close it without
+		terminated := false
 
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -474,120 +543,106 @@ func handleOpenAIStreaming(
 				continue
 			}
 
-			// Check for end of stream
-			if line == "data: [DONE]" {
-				break
-			}
-
-			var jsonData string
+			// Split the data into its JSON objects, as some OpenAI-compatible backends pack several
+			// into one data line or omit the blank lines between events
+			objects, done := splitStreamDataObjects(line)
 
-			// Parse SSE data
-			if strings.HasPrefix(line, "data: ") {
-				jsonData = strings.TrimPrefix(line, "data: ")
-			} else {
-				// Handle raw JSON errors (without "data: " prefix)
-				jsonData = line
-			}
-
-			// Skip empty data
-			if strings.TrimSpace(jsonData) == "" {
-				continue
-			}
-
-			// Parse as raw map to check for errors and preprocess reasoning fields
-			var rawChunk map[string]interface{}
-			if err := sonic.Unmarshal([]byte(jsonData), &rawChunk); err != nil {
-				logger.Warn(fmt.Sprintf("Failed to parse stream data as JSON: %v", err))
-				continue
-			}
-
-			// Handle error responses
-			if _, hasError := rawChunk["error"]; hasError {
-				bifrostErr, err := parseOpenAIErrorForStreamDataLine(jsonData)
-				if err != nil {
-					logger.Warn(fmt.Sprintf("Failed to parse error response: %v", err))
+			for _, jsonData := range objects {
+				// Parse as raw map to check for errors and preprocess reasoning fields
+				var rawChunk map[string]interface{}
+				if err := sonic.Unmarshal([]byte(jsonData), &rawChunk); err != nil {
+					logger.Warn(fmt.Sprintf("Failed to parse stream data as JSON: %v", err))
 					continue
 				}
-				ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
-				processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, logger)
-				return
-			}
 
-			// Map reasoning_content/reasoning to thought in delta for reasoning models
-			if choices, ok := rawChunk["choices"].([]interface{}); ok {
-				for _, choice := range choices {
-					if choiceMap, ok := choice.(map[string]interface{}); ok {
-						if delta, ok := choiceMap["delta"].(map[string]interface{}); ok {
-							if rc, exists := delta["reasoning_content"]; exists {
-								delta["thought"] = rc
-								delete(delta, "reasoning_content")
-							} else if r, exists := delta["reasoning"]; exists {
-								delta["thought"] = r
-								delete(delta, "reasoning")
-							}
-						}
+				// Handle error responses
+				if _, hasError := rawChunk["error"]; hasError {
+					bifrostErr, err := parseOpenAIErrorForStreamDataLine(jsonData)
+					if err != nil {
+						logger.Warn(fmt.Sprintf("Failed to parse error response: %v", err))
+						continue
 					}
+					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+					processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, logger)
+					return
 				}
-				// Re-marshal the modified data
-				if modifiedJSON, err := sonic.Marshal(rawChunk); err == nil {
-					jsonData = string(modifiedJSON)
+
+				// Map reasoning_content/reasoning to thought in delta for reasoning models
+				if _, ok := rawChunk["choices"].([]interface{}); ok {
+					mapReasoningFields(rawChunk, "delta")
+					// Re-marshal the modified data
+					if modifiedJSON, err := sonic.Marshal(rawChunk); err == nil {
+						jsonData = string(modifiedJSON)
+					}
 				}
-			}
 
-			// Parse into bifrost response
-			var response schemas.BifrostResponse
-			if err := sonic.Unmarshal([]byte(jsonData), &response); err != nil {
-				logger.Warn(fmt.Sprintf("Failed to parse stream response: %v", err))
-				continue
-			}
+				// Parse into bifrost response
+				var response schemas.BifrostResponse
+				if err := sonic.Unmarshal([]byte(jsonData), &response); err != nil {
+					logger.Warn(fmt.Sprintf("Failed to parse stream response: %v", err))
+					continue
+				}
 
-			// Handle usage-only chunks (when stream_options include_usage is true)
-			if response.Usage != nil {
-				// Collect usage information and send at the end of the stream
-				// Here in some cases usage comes before final message
-				// So we need to check if the response.Usage is nil and then if usage != nil
-				// then add up all tokens
-				if response.Usage.PromptTokens > usage.PromptTokens {
-					usage.PromptTokens = response.Usage.PromptTokens
+				if chunkHook != nil {
+					chunkHook(rawChunk, &response)
 				}
-				if response.Usage.CompletionTokens > usage.CompletionTokens {
-					usage.CompletionTokens = response.Usage.CompletionTokens
+
+				// Handle usage-only chunks (when stream_options include_usage is true)
+				if response.Usage != nil {
+					// Collect usage information and send at the end of the stream
+					// Here in some cases usage comes before final message
+					// So we need to check if the response.Usage is nil and then if usage != nil
+					// then add up all tokens
+					if response.Usage.PromptTokens > usage.PromptTokens {
+						usage.PromptTokens = response.Usage.PromptTokens
+					}
+					if response.Usage.CompletionTokens > usage.CompletionTokens {
+						usage.CompletionTokens = response.Usage.CompletionTokens
+					}
+					if response.Usage.TotalTokens > usage.TotalTokens {
+						usage.TotalTokens = response.Usage.TotalTokens
+					}
+					calculatedTotal := usage.PromptTokens + usage.CompletionTokens
+					if calculatedTotal > usage.TotalTokens {
+						usage.TotalTokens = calculatedTotal
+					}
+					response.Usage = nil
 				}
-				if response.Usage.TotalTokens > usage.TotalTokens {
-					usage.TotalTokens = response.Usage.TotalTokens
+
+				// Skip empty responses or responses without choices
+				if len(response.Choices) == 0 {
+					continue
 				}
-				calculatedTotal := usage.PromptTokens + usage.CompletionTokens
-				if calculatedTotal > usage.TotalTokens {
-					usage.TotalTokens = calculatedTotal
+
+				// Handle finish reason, usually in the final chunk
+				choice := response.Choices[0]
+				if choice.FinishReason != nil && *choice.FinishReason != "" {
+					// Collect finish reason and send at the end of the stream
+					finishReason = choice.FinishReason
+					response.Choices[0].FinishReason = nil
 				}
-				response.Usage = nil
-			}
 
-			// Skip empty responses or responses without choices
-			if len(response.Choices) == 0 {
-				continue
-			}
+				if response.ID != "" && id == "" {
+					id = response.ID
+				}
 
-			// Handle finish reason, usually in the final chunk
-			choice := response.Choices[0]
-			if choice.FinishReason != nil && *choice.FinishReason != "" {
-				// Collect finish reason and send at the end of the stream
-				finishReason = choice.FinishReason
-				response.Choices[0].FinishReason = nil
-			}
+				// Handle regular content chunks
+				if choice.BifrostStreamResponseChoice != nil && (choice.BifrostStreamResponseChoice.Delta.Content != nil || len(choice.BifrostStreamResponseChoice.Delta.ToolCalls) > 0 || len(choice.BifrostStreamResponseChoice.Delta.Annotations) > 0 || len(choice.BifrostStreamResponseChoice.Delta.Citations) > 0 || choice.BifrostStreamResponseChoice.Delta.Thought != nil) {
+					chunkIndex++
 
-			if response.ID != "" && id == "" {
-				id = response.ID
-			}
+					populateCitations(&response)
 
-			// Handle regular content chunks
-			if choice.BifrostStreamResponseChoice != nil && (choice.BifrostStreamResponseChoice.Delta.Content != nil || len(choice.BifrostStreamResponseChoice.Delta.ToolCalls) > 0) {
-				chunkIndex++
+					response.ExtraFields.Provider = providerName
+					response.ExtraFields.ChunkIndex = chunkIndex
 
-				response.ExtraFields.Provider = providerName
-				response.ExtraFields.ChunkIndex = chunkIndex
+					processAndSendResponse(ctx, postHookRunner, &response, responseChan, logger)
+				}
+			}
 
-				processAndSendResponse(ctx, postHookRunner, &response, responseChan, logger)
+			// Check for end of stream
+			if done {
+				terminated = true
+				break
 			}
 		}
 
@@ -596,12 +651,22 @@ func handleOpenAIStreaming(
 			logger.Warn(fmt.Sprintf("Error reading stream: %v", err))
 			processAndSendError(ctx, postHookRunner, err, responseChan, logger)
 		} else {
+			// A stream closed without [DONE] or a finish reason may have been cut short
+			if !terminated && finishReason == nil {
+				var ok bool
+				if finishReason, ok = incompleteStreamFinishReason(ctx, postHookRunner, providerName, responseChan, logger); !ok {
+					return
+				}
+			}
 			response := createBifrostChatCompletionChunkResponse(id, usage, finishReason, chunkIndex, params, providerName)
+			if endHook != nil {
+				endHook(response)
+			}
 			handleStreamEndWithSuccess(ctx, response, postHookRunner, responseChan, logger)
 		}
 	}()
 
-	return responseChan, nil
+	return responseChan
 }
 
 // Speech handles non-streaming speech synthesis requests.
@@ -702,6 +767,7 @@ func (provider *OpenAIProvider) SpeechStream(ctx context.Context, postHookRunner
 	if responseFormat == "" {
 		responseFormat = "mp3"
 	}
+	contentType := speechMimeType(responseFormat)
 
 	requestBody := map[string]interface{}{
 		"input":           input.Input,
@@ -721,6 +787,10 @@ func (provider *OpenAIProvider) SpeechStream(ctx context.Context, postHookRunner
 		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
 	}
 
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
 	// Prepare OpenAI headers
 	headers := map[string]string{
 		"Content-Type":  "application/json",
@@ -760,9 +830,10 @@ func (provider *OpenAIProvider) SpeechStream(ctx context.Context, postHookRunner
 	// Start streaming in a goroutine
 	go func() {
 		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, provider.logger)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
+		scanner := newStreamScanner(resp.Body)
 		chunkIndex := -1
 
 		for scanner.Scan() {
@@ -778,71 +849,60 @@ func (provider *OpenAIProvider) SpeechStream(ctx context.Context, postHookRunner
 				break
 			}
 
-			var jsonData string
-
-			// Parse SSE data
-			if strings.HasPrefix(line, "data: ") {
-				jsonData = strings.TrimPrefix(line, "data: ")
-			} else {
-				// Handle raw JSON errors (without "data: " prefix)
-				jsonData = line
-			}
+			// Split the data into its JSON objects, raw JSON errors come without the data prefix
+			objects, _ := splitStreamDataObjects(line)
+			for _, jsonData := range objects {
+				// First, check if this is an error response
+				var errorCheck map[string]interface{}
+				if err := sonic.Unmarshal([]byte(jsonData), &errorCheck); err != nil {
+					provider.logger.Warn(fmt.Sprintf("Failed to parse stream data as JSON: %v", err))
+					continue
+				}
 
-			// Skip empty data
-			if strings.TrimSpace(jsonData) == "" {
-				continue
-			}
+				// Handle error responses
+				if _, hasError := errorCheck["error"]; hasError {
+					bifrostErr, err := parseOpenAIErrorForStreamDataLine(jsonData)
+					if err != nil {
+						provider.logger.Warn(fmt.Sprintf("Failed to parse error response: %v", err))
+						continue
+					}
+					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+					processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, provider.logger)
+					return
+				}
 
-			// First, check if this is an error response
-			var errorCheck map[string]interface{}
-			if err := sonic.Unmarshal([]byte(jsonData), &errorCheck); err != nil {
-				provider.logger.Warn(fmt.Sprintf("Failed to parse stream data as JSON: %v", err))
-				continue
-			}
+				// Parse into bifrost response
+				var response schemas.BifrostResponse
 
-			// Handle error responses
-			if _, hasError := errorCheck["error"]; hasError {
-				bifrostErr, err := parseOpenAIErrorForStreamDataLine(jsonData)
-				if err != nil {
-					provider.logger.Warn(fmt.Sprintf("Failed to parse error response: %v", err))
+				var speechResponse schemas.BifrostSpeech
+				if err := sonic.Unmarshal([]byte(jsonData), &speechResponse); err != nil {
+					provider.logger.Warn(fmt.Sprintf("Failed to parse stream response: %v", err))
 					continue
 				}
-				ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
-				processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, provider.logger)
-				return
-			}
-
-			// Parse into bifrost response
-			var response schemas.BifrostResponse
 
-			var speechResponse schemas.BifrostSpeech
-			if err := sonic.Unmarshal([]byte(jsonData), &speechResponse); err != nil {
-				provider.logger.Warn(fmt.Sprintf("Failed to parse stream response: %v", err))
-				continue
-			}
+				chunkIndex++
 
-			chunkIndex++
+				response.Speech = &speechResponse
+				response.Object = "audio.speech.chunk"
+				response.Model = model
+				response.ExtraFields = schemas.BifrostResponseExtraFields{
+					Provider: providerName,
+				}
 
-			response.Speech = &speechResponse
-			response.Object = "audio.speech.chunk"
-			response.Model = model
-			response.ExtraFields = schemas.BifrostResponseExtraFields{
-				Provider: providerName,
-			}
+				response.ExtraFields.ChunkIndex = chunkIndex
 
-			response.ExtraFields.ChunkIndex = chunkIndex
+				if speechResponse.Usage != nil {
+					if params != nil {
+						response.ExtraFields.Params = *params
+					}
 
-			if speechResponse.Usage != nil {
-				if params != nil {
-					response.ExtraFields.Params = *params
+					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+					processAndSendAudioChunk(ctx, postHookRunner, &response, contentType, responseChan, provider.logger)
+					return
 				}
 
-				ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
-				processAndSendResponse(ctx, postHookRunner, &response, responseChan, provider.logger)
-				return
+				processAndSendAudioChunk(ctx, postHookRunner, &response, contentType, responseChan, provider.logger)
 			}
-
-			processAndSendResponse(ctx, postHookRunner, &response, responseChan, provider.logger)
 		}
 
 		// Handle scanner errors
@@ -863,6 +923,45 @@ func (provider *OpenAIProvider) Transcription(ctx context.Context, model string,
 		return nil, err
 	}
 
+	return provider.handleAudioTextRequest(ctx, "/v1/audio/transcriptions", "audio.transcription", model, key, input, params)
+}
+
+// Translation handles non-streaming translation requests, which transcribe the audio into English.
+// The request is the same multipart form as a transcription, without the language field.
+func (provider *OpenAIProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if err := checkOperationAllowed(schemas.OpenAI, provider.customProviderConfig, schemas.OperationTranslation); err != nil {
+		return nil, err
+	}
+
+	translationInput := *input
+	translationInput.Language = nil
+
+	return provider.handleAudioTextRequest(ctx, "/v1/audio/translations", "audio.translation", model, key, &translationInput, params)
+}
+
+func (provider *OpenAIProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "openai")
+}
+
+func (provider *OpenAIProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "openai")
+}
+
+func (provider *OpenAIProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "openai")
+}
+
+func (provider *OpenAIProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "openai")
+}
+
+func (provider *OpenAIProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "openai")
+}
+
+// handleAudioTextRequest sends the audio of a transcription or translation request to path
+// and parses the text returned.
+func (provider *OpenAIProvider) handleAudioTextRequest(ctx context.Context, path string, object string, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	providerName := provider.GetProviderKey()
 
 	// Create multipart form
@@ -882,7 +981,7 @@ func (provider *OpenAIProvider) Transcription(ctx context.Context, model string,
 	// Set any extra headers from network config
 	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
 
-	req.SetRequestURI(provider.networkConfig.BaseURL + "/v1/audio/transcriptions")
+	req.SetRequestURI(provider.networkConfig.BaseURL + path)
 	req.Header.SetMethod("POST")
 	req.Header.SetContentType(writer.FormDataContentType()) // This sets multipart/form-data with boundary
 	req.Header.Set("Authorization", "Bearer "+key.Value)
@@ -920,7 +1019,7 @@ func (provider *OpenAIProvider) Transcription(ctx context.Context, model string,
 
 	// Create final response
 	bifrostResponse := &schemas.BifrostResponse{
-		Object:     "audio.transcription",
+		Object:     object,
 		Model:      model,
 		Transcribe: transcribeResponse,
 		ExtraFields: schemas.BifrostResponseExtraFields{
@@ -998,9 +1097,10 @@ func (provider *OpenAIProvider) TranscriptionStream(ctx context.Context, postHoo
 	// Start streaming in a goroutine
 	go func() {
 		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, provider.logger)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
+		scanner := newStreamScanner(resp.Body)
 		chunkIndex := -1
 
 		for scanner.Scan() {
@@ -1016,69 +1116,59 @@ func (provider *OpenAIProvider) TranscriptionStream(ctx context.Context, postHoo
 				break
 			}
 
-			var jsonData string
-			// Parse SSE data
-			if strings.HasPrefix(line, "data: ") {
-				jsonData = strings.TrimPrefix(line, "data: ")
-			} else {
-				// Handle raw JSON errors (without "data: " prefix)
-				jsonData = line
-			}
+			// Split the data into its JSON objects, raw JSON errors come without the data prefix
+			objects, _ := splitStreamDataObjects(line)
+			for _, jsonData := range objects {
+				// First, check if this is an error response
+				var errorCheck map[string]interface{}
+				if err := sonic.Unmarshal([]byte(jsonData), &errorCheck); err != nil {
+					provider.logger.Warn(fmt.Sprintf("Failed to parse stream data as JSON: %v", err))
+					continue
+				}
 
-			// Skip empty data
-			if strings.TrimSpace(jsonData) == "" {
-				continue
-			}
+				// Handle error responses
+				if _, hasError := errorCheck["error"]; hasError {
+					bifrostErr, err := parseOpenAIErrorForStreamDataLine(jsonData)
+					if err != nil {
+						provider.logger.Warn(fmt.Sprintf("Failed to parse error response: %v", err))
+						continue
+					}
+					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+					processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, provider.logger)
+					return
+				}
 
-			// First, check if this is an error response
-			var errorCheck map[string]interface{}
-			if err := sonic.Unmarshal([]byte(jsonData), &errorCheck); err != nil {
-				provider.logger.Warn(fmt.Sprintf("Failed to parse stream data as JSON: %v", err))
-				continue
-			}
+				var response schemas.BifrostResponse
 
-			// Handle error responses
-			if _, hasError := errorCheck["error"]; hasError {
-				bifrostErr, err := parseOpenAIErrorForStreamDataLine(jsonData)
-				if err != nil {
-					provider.logger.Warn(fmt.Sprintf("Failed to parse error response: %v", err))
+				var transcriptionResponse schemas.BifrostTranscribe
+				if err := sonic.Unmarshal([]byte(jsonData), &transcriptionResponse); err != nil {
+					provider.logger.Warn(fmt.Sprintf("Failed to parse stream response: %v", err))
 					continue
 				}
-				ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
-				processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, provider.logger)
-				return
-			}
 
-			var response schemas.BifrostResponse
-
-			var transcriptionResponse schemas.BifrostTranscribe
-			if err := sonic.Unmarshal([]byte(jsonData), &transcriptionResponse); err != nil {
-				provider.logger.Warn(fmt.Sprintf("Failed to parse stream response: %v", err))
-				continue
-			}
+				chunkIndex++
 
-			chunkIndex++
+				response.Transcribe = &transcriptionResponse
+				response.Object = "audio.transcription.chunk"
+				response.Model = model
+				response.ExtraFields = schemas.BifrostResponseExtraFields{
+					Provider: providerName,
+				}
 
-			response.Transcribe = &transcriptionResponse
-			response.Object = "audio.transcription.chunk"
-			response.Model = model
-			response.ExtraFields = schemas.BifrostResponseExtraFields{
-				Provider: providerName,
-			}
+				response.ExtraFields.ChunkIndex = chunkIndex
 
-			response.ExtraFields.ChunkIndex = chunkIndex
+				if transcriptionResponse.Usage != nil {
+					if params != nil {
+						response.ExtraFields.Params = *params
+					}
 
-			if transcriptionResponse.Usage != nil {
-				if params != nil {
-					response.ExtraFields.Params = *params
+					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+					processAndSendResponse(ctx, postHookRunner, &response, responseChan, provider.logger)
+					return
 				}
 
-				ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
 				processAndSendResponse(ctx, postHookRunner, &response, responseChan, provider.logger)
-				return
 			}
-
-			processAndSendResponse(ctx, postHookRunner, &response, responseChan, provider.logger)
 		}
 
 		// Handle scanner errors
@@ -1193,6 +1283,7 @@ func parseStreamOpenAIError(resp *http.Response) *schemas.BifrostError {
 		return &schemas.BifrostError{
 			IsBifrostError: true,
 			StatusCode:     &statusCode,
+			Origin:         schemas.ErrorOriginProvider,
 			Error: schemas.ErrorField{
 				Message: schemas.ErrProviderResponseUnmarshal,
 				Error:   err,
@@ -1203,6 +1294,7 @@ func parseStreamOpenAIError(resp *http.Response) *schemas.BifrostError {
 	bifrostErr := &schemas.BifrostError{
 		IsBifrostError: false,
 		StatusCode:     &statusCode,
+		Origin:         schemas.ErrorOriginProvider,
 		Error:          schemas.ErrorField{},
 	}
 
@@ -1229,6 +1321,7 @@ func parseOpenAIErrorForStreamDataLine(jsonData string) (*schemas.BifrostError,
 	// Send error through channel
 	bifrostErr := &schemas.BifrostError{
 		IsBifrostError: false,
+		Origin:         schemas.ErrorOriginProvider,
 		Error: schemas.ErrorField{
 			Type:    openAIError.Error.Type,
 			Code:    openAIError.Error.Code,
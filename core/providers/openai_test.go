@@ -0,0 +1,32 @@
+package providers
+
+import (
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestSetOpenAIPromptCacheKey(t *testing.T) {
+	hint := &schemas.ModelParameters{PromptCache: &schemas.PromptCacheHint{PrefixKey: "prefix"}}
+
+	tests := []struct {
+		name   string
+		body   map[string]interface{}
+		params *schemas.ModelParameters
+		want   interface{}
+	}{
+		{name: "hint", body: map[string]interface{}{}, params: hint, want: "prefix"},
+		{name: "explicit key kept", body: map[string]interface{}{"prompt_cache_key": "explicit"}, params: hint, want: "explicit"},
+		{name: "empty prefix key", body: map[string]interface{}{}, params: &schemas.ModelParameters{PromptCache: &schemas.PromptCacheHint{}}},
+		{name: "no hint", body: map[string]interface{}{}, params: &schemas.ModelParameters{}},
+		{name: "no params", body: map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOpenAIPromptCacheKey(tt.body, tt.params)
+			if got := tt.body["prompt_cache_key"]; got != tt.want {
+				t.Errorf("prompt_cache_key = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ type OpenRouterTextResponse struct {
 	SystemFingerprint *string                `json:"system_fingerprint"`
 	Choices           []OpenRouterTextChoice `json:"choices"`
 	Usage             *schemas.LLMUsage      `json:"usage"`
+	Provider          string                 `json:"provider"` // Upstream provider that served the request
 }
 
 // OpenRouterTextChoice represents a choice in the OpenRouter text completion response
@@ -89,6 +90,10 @@ func NewOpenRouterProvider(config *schemas.ProviderConfig, logger schemas.Logger
 	// Configure proxy if provided
 	client = configureProxy(client, config.ProxyConfig, logger)
 
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
 	// Set default BaseURL if not provided
 	if config.NetworkConfig.BaseURL == "" {
 		config.NetworkConfig.BaseURL = "https://openrouter.ai/api"
@@ -109,6 +114,11 @@ func (provider *OpenRouterProvider) GetProviderKey() schemas.ModelProvider {
 	return schemas.OpenRouter
 }
 
+// WarmUp opens connections to the OpenRouter API ahead of the first request.
+func (provider *OpenRouterProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
 // TextCompletion performs a text completion request to the OpenRouter API.
 func (provider *OpenRouterProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	preparedParams := prepareParams(params)
@@ -192,7 +202,8 @@ func (provider *OpenRouterProvider) TextCompletion(ctx context.Context, model st
 		SystemFingerprint: response.SystemFingerprint,
 		Usage:             response.Usage,
 		ExtraFields: schemas.BifrostResponseExtraFields{
-			Provider: schemas.OpenRouter,
+			Provider:         schemas.OpenRouter,
+			UpstreamProvider: response.Provider,
 		},
 	}
 
@@ -263,6 +274,7 @@ func (provider *OpenRouterProvider) ChatCompletion(ctx context.Context, model st
 	}
 
 	response.ExtraFields.Provider = schemas.OpenRouter
+	response.ExtraFields.UpstreamProvider = openRouterUpstreamProvider(rawMap)
 
 	if provider.sendBackRawResponse {
 		response.ExtraFields.RawResponse = rawMap
@@ -296,8 +308,20 @@ func (provider *OpenRouterProvider) ChatCompletionStream(ctx context.Context, po
 		"Cache-Control": "no-cache",
 	}
 
+	// Every chunk names the upstream provider OpenRouter routed the request to
+	var upstreamProvider string
+	chunkHook := func(rawChunk map[string]interface{}, response *schemas.BifrostResponse) {
+		if upstream := openRouterUpstreamProvider(rawChunk); upstream != "" {
+			upstreamProvider = upstream
+		}
+		response.ExtraFields.UpstreamProvider = upstreamProvider
+	}
+	endHook := func(response *schemas.BifrostResponse) {
+		response.ExtraFields.UpstreamProvider = upstreamProvider
+	}
+
 	// Use shared OpenAI-compatible streaming logic (with reasoning field support)
-	return handleOpenAIStreaming(
+	return handleOpenAIStreamingWithHook(
 		ctx,
 		provider.streamClient,
 		provider.networkConfig.BaseURL+"/v1/chat/completions",
@@ -308,6 +332,8 @@ func (provider *OpenRouterProvider) ChatCompletionStream(ctx context.Context, po
 		params,
 		postHookRunner,
 		provider.logger,
+		chunkHook,
+		endHook,
 	)
 }
 
@@ -331,41 +357,33 @@ func (provider *OpenRouterProvider) TranscriptionStream(ctx context.Context, pos
 	return nil, newUnsupportedOperationError("transcription stream", "openrouter")
 }
 
-// parseResponseWithReasoningFields parses response body and maps reasoning_content/reasoning to thought
-func parseResponseWithReasoningFields(responseBody []byte, providerName schemas.ModelProvider) (map[string]interface{}, *schemas.BifrostResponse, *schemas.BifrostError) {
-	// Parse as raw map to handle reasoning fields
-	var rawMap map[string]interface{}
-	if err := sonic.Unmarshal(responseBody, &rawMap); err != nil {
-		return nil, nil, newBifrostOperationError(schemas.ErrProviderResponseUnmarshal, err, providerName)
-	}
+func (provider *OpenRouterProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "openrouter")
+}
 
-	// Map reasoning_content/reasoning to thought in message
-	if choices, ok := rawMap["choices"].([]interface{}); ok {
-		for _, choice := range choices {
-			if choiceMap, ok := choice.(map[string]interface{}); ok {
-				if message, ok := choiceMap["message"].(map[string]interface{}); ok {
-					if rc, exists := message["reasoning_content"]; exists {
-						message["thought"] = rc
-						delete(message, "reasoning_content")
-					} else if r, exists := message["reasoning"]; exists {
-						message["thought"] = r
-						delete(message, "reasoning")
-					}
-				}
-			}
-		}
-	}
+func (provider *OpenRouterProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "openrouter")
+}
 
-	// Re-marshal and parse into BifrostResponse
-	modifiedBody, err := sonic.Marshal(rawMap)
-	if err != nil {
-		return nil, nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
-	}
+func (provider *OpenRouterProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "openrouter")
+}
 
-	response := &schemas.BifrostResponse{}
-	if err := sonic.Unmarshal(modifiedBody, response); err != nil {
-		return nil, nil, newBifrostOperationError(schemas.ErrProviderResponseUnmarshal, err, providerName)
-	}
+func (provider *OpenRouterProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "openrouter")
+}
+
+func (provider *OpenRouterProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "openrouter")
+}
+
+func (provider *OpenRouterProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "openrouter")
+}
 
-	return rawMap, response, nil
+// openRouterUpstreamProvider returns the upstream provider named in an OpenRouter response or
+// stream chunk, e.g. "OpenAI" or "Together", empty if it has none.
+func openRouterUpstreamProvider(rawResponse map[string]interface{}) string {
+	upstream, _ := rawResponse["provider"].(string)
+	return upstream
 }
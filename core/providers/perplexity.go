@@ -0,0 +1,414 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the Perplexity provider implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// PerplexitySearchResult represents a search result returned by Perplexity.
+type PerplexitySearchResult struct {
+	Title       string  `json:"title"`
+	URL         string  `json:"url"`
+	Date        *string `json:"date,omitempty"`
+	LastUpdated *string `json:"last_updated,omitempty"`
+	Snippet     *string `json:"snippet,omitempty"`
+}
+
+// PerplexityImage represents an image returned by Perplexity when return_images is enabled.
+type PerplexityImage struct {
+	ImageURL  string  `json:"image_url"`
+	OriginURL *string `json:"origin_url,omitempty"`
+	Width     *int    `json:"width,omitempty"`
+	Height    *int    `json:"height,omitempty"`
+}
+
+// PerplexitySearchFields contains the search specific top level fields of a Perplexity
+// response or stream chunk, which are not part of the OpenAI format.
+type PerplexitySearchFields struct {
+	Citations     []string                 `json:"citations,omitempty"`
+	SearchResults []PerplexitySearchResult `json:"search_results,omitempty"`
+	Images        []PerplexityImage        `json:"images,omitempty"`
+}
+
+// PerplexityProvider implements the Provider interface for Perplexity's API.
+// Search options such as search_domain_filter, search_recency_filter and return_images
+// are passed through ModelParameters.ExtraParams.
+type PerplexityProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	streamClient        *http.Client          // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+}
+
+// NewPerplexityProvider creates a new Perplexity provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewPerplexityProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*PerplexityProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://api.perplexity.ai"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &PerplexityProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Perplexity.
+func (provider *PerplexityProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Perplexity
+}
+
+// WarmUp opens connections to the Perplexity API ahead of the first request.
+func (provider *PerplexityProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the Perplexity provider.
+func (provider *PerplexityProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "perplexity")
+}
+
+// ChatCompletion performs a chat completion request to the Perplexity API.
+// Citations and images returned by Perplexity are mapped onto the assistant message, and its search
+// results onto the response.
+func (provider *PerplexityProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+	}, preparedParams)
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Perplexity)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/chat/completions")
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from perplexity provider: %s", string(resp.Body())))
+
+		var errorResp map[string]interface{}
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		bifrostErr.Error.Message = fmt.Sprintf("Perplexity error: %v", errorResp)
+		return nil, bifrostErr
+	}
+
+	responseBody := resp.Body()
+
+	response := &schemas.BifrostResponse{}
+
+	rawResponse, bifrostErr := handleProviderResponse(responseBody, response, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var searchFields PerplexitySearchFields
+	if err := sonic.Unmarshal(responseBody, &searchFields); err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderResponseUnmarshal, err, schemas.Perplexity)
+	}
+
+	citations := perplexityCitations(searchFields)
+	images := perplexityImages(searchFields.Images)
+	for i := range response.Choices {
+		choice := &response.Choices[i]
+		if choice.BifrostNonStreamResponseChoice == nil || (len(citations) == 0 && len(images) == 0) {
+			continue
+		}
+		if choice.Message.AssistantMessage == nil {
+			choice.Message.AssistantMessage = &schemas.AssistantMessage{}
+		}
+		choice.Message.AssistantMessage.Citations = citations
+		choice.Message.AssistantMessage.Images = images
+	}
+
+	response.SearchResults = perplexitySearchResults(searchFields)
+
+	// Create final response
+	response.ExtraFields.Provider = schemas.Perplexity
+
+	if provider.sendBackRawResponse {
+		response.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		response.ExtraFields.Params = *params
+	}
+
+	return response, nil
+}
+
+// Embedding is not supported by the Perplexity provider.
+func (provider *PerplexityProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("embedding", "perplexity")
+}
+
+// ChatCompletionStream performs a streaming chat completion request to the Perplexity API.
+// It supports real-time streaming of responses using Server-Sent Events (SSE).
+// Perplexity repeats its citations and search results in every chunk, they are only forwarded on the
+// first chunk that carries them.
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+func (provider *PerplexityProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+		"stream":   true,
+	}, preparedParams)
+
+	// Prepare Perplexity headers
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+
+	headers["Authorization"] = "Bearer " + key.Value
+
+	sentCitations, sentImages, sentSearchResults := false, false, false
+	chunkHook := func(rawChunk map[string]interface{}, response *schemas.BifrostResponse) {
+		// Chunks decode the search results as is, they are set again below when first seen
+		response.SearchResults = nil
+		if (sentCitations && sentImages && sentSearchResults) || len(response.Choices) == 0 || response.Choices[0].BifrostStreamResponseChoice == nil {
+			return
+		}
+		searchFields, err := parsePerplexitySearchFields(rawChunk)
+		if err != nil {
+			provider.logger.Warn(fmt.Sprintf("Failed to parse perplexity search fields: %v", err))
+			return
+		}
+
+		delta := &response.Choices[0].Delta
+		if citations := perplexityCitations(searchFields); !sentCitations && len(citations) > 0 {
+			delta.Citations = citations
+			sentCitations = true
+		}
+		if images := perplexityImages(searchFields.Images); !sentImages && len(images) > 0 {
+			delta.Images = images
+			sentImages = true
+		}
+		if searchResults := perplexitySearchResults(searchFields); !sentSearchResults && len(searchResults) > 0 {
+			response.SearchResults = searchResults
+			sentSearchResults = true
+		}
+	}
+
+	// Use shared OpenAI-compatible streaming logic
+	return handleOpenAIStreamingWithHook(
+		ctx,
+		provider.streamClient,
+		provider.networkConfig.BaseURL+"/chat/completions",
+		requestBody,
+		headers,
+		provider.networkConfig.ExtraHeaders,
+		schemas.Perplexity,
+		params,
+		postHookRunner,
+		provider.logger,
+		chunkHook,
+		nil,
+	)
+}
+
+func (provider *PerplexityProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "perplexity")
+}
+
+func (provider *PerplexityProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "perplexity")
+}
+
+func (provider *PerplexityProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "perplexity")
+}
+
+func (provider *PerplexityProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "perplexity")
+}
+
+func (provider *PerplexityProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "perplexity")
+}
+
+func (provider *PerplexityProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "perplexity")
+}
+
+func (provider *PerplexityProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "perplexity")
+}
+
+func (provider *PerplexityProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "perplexity")
+}
+
+func (provider *PerplexityProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "perplexity")
+}
+
+func (provider *PerplexityProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "perplexity")
+}
+
+// parsePerplexitySearchFields extracts the search fields from a raw stream chunk.
+func parsePerplexitySearchFields(rawChunk map[string]interface{}) (PerplexitySearchFields, error) {
+	var searchFields PerplexitySearchFields
+
+	fields := make(map[string]interface{}, 3)
+	for _, name := range []string{"citations", "search_results", "images"} {
+		if value, ok := rawChunk[name]; ok {
+			fields[name] = value
+		}
+	}
+	if len(fields) == 0 {
+		return searchFields, nil
+	}
+
+	data, err := sonic.Marshal(fields)
+	if err != nil {
+		return searchFields, err
+	}
+	err = sonic.Unmarshal(data, &searchFields)
+	return searchFields, err
+}
+
+// perplexityCitations converts Perplexity search results into normalized citations.
+// Perplexity references sources inline with 1-based markers such as [1], which is
+// used as the citation SourceID. Older responses only carry a list of URLs.
+func perplexityCitations(searchFields PerplexitySearchFields) []schemas.MessageCitation {
+	if len(searchFields.SearchResults) > 0 {
+		citations := make([]schemas.MessageCitation, 0, len(searchFields.SearchResults))
+		for i, result := range searchFields.SearchResults {
+			citation := schemas.MessageCitation{
+				Type:     schemas.MessageCitationTypeURL,
+				URL:      Ptr(result.URL),
+				SourceID: Ptr(strconv.Itoa(i + 1)),
+			}
+			if result.Title != "" {
+				citation.Title = Ptr(result.Title)
+			}
+			citations = append(citations, citation)
+		}
+		return citations
+	}
+
+	if len(searchFields.Citations) == 0 {
+		return nil
+	}
+	citations := make([]schemas.MessageCitation, 0, len(searchFields.Citations))
+	for i, url := range searchFields.Citations {
+		citations = append(citations, schemas.MessageCitation{
+			Type:     schemas.MessageCitationTypeURL,
+			URL:      Ptr(url),
+			SourceID: Ptr(strconv.Itoa(i + 1)),
+		})
+	}
+	return citations
+}
+
+// perplexitySearchResults converts Perplexity search results into search results, keeping their
+// order. Older responses only carry a list of URLs.
+func perplexitySearchResults(searchFields PerplexitySearchFields) []schemas.SearchResult {
+	if len(searchFields.SearchResults) > 0 {
+		searchResults := make([]schemas.SearchResult, 0, len(searchFields.SearchResults))
+		for _, result := range searchFields.SearchResults {
+			searchResult := schemas.SearchResult{
+				URL:         result.URL,
+				Snippet:     result.Snippet,
+				Date:        result.Date,
+				LastUpdated: result.LastUpdated,
+			}
+			if result.Title != "" {
+				searchResult.Title = Ptr(result.Title)
+			}
+			searchResults = append(searchResults, searchResult)
+		}
+		return searchResults
+	}
+
+	if len(searchFields.Citations) == 0 {
+		return nil
+	}
+	searchResults := make([]schemas.SearchResult, 0, len(searchFields.Citations))
+	for _, url := range searchFields.Citations {
+		searchResults = append(searchResults, schemas.SearchResult{URL: url})
+	}
+	return searchResults
+}
+
+// perplexityImages converts Perplexity images into search images.
+func perplexityImages(images []PerplexityImage) []schemas.SearchImage {
+	if len(images) == 0 {
+		return nil
+	}
+	searchImages := make([]schemas.SearchImage, 0, len(images))
+	for _, image := range images {
+		searchImages = append(searchImages, schemas.SearchImage{
+			ImageURL:  image.ImageURL,
+			OriginURL: image.OriginURL,
+			Width:     image.Width,
+			Height:    image.Height,
+		})
+	}
+	return searchImages
+}
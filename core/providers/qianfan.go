@@ -0,0 +1,891 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the Baidu Qianfan (ERNIE) provider implementation.
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	qianfanTokenPath     = "/oauth/2.0/token"
+	qianfanChatPath      = "/rpc/2.0/ai_custom/v1/wenxinworkshop/chat/"
+	qianfanEmbeddingPath = "/rpc/2.0/ai_custom/v1/wenxinworkshop/embeddings/"
+
+	// qianfanTokenRefreshMargin is how long before their expiry cached access tokens are
+	// exchanged again. Access tokens are valid for 30 days.
+	qianfanTokenRefreshMargin = time.Hour
+)
+
+// qianfanModelEndpoints maps the names of ERNIE models to the endpoints serving them, for the
+// models whose endpoint is not their name. Other models, including custom deployments, are
+// served at the endpoint of their name.
+var qianfanModelEndpoints = map[string]string{
+	"ernie-4.0-8k":    "completions_pro",
+	"ernie-3.5-8k":    "completions",
+	"ernie-speed-8k":  "ernie_speed",
+	"ernie-bot-4":     "completions_pro",
+	"ernie-bot":       "completions",
+	"ernie-bot-turbo": "eb-instant",
+}
+
+// Qianfan error codes of invalid and expired access tokens, after which the token is exchanged again.
+const (
+	qianfanErrorTokenInvalid = 110
+	qianfanErrorTokenExpired = 111
+)
+
+// qianfanErrorStatusCodes maps Qianfan error codes, which are returned with HTTP 200, to the
+// status codes of the errors. Other codes are bad requests.
+var qianfanErrorStatusCodes = map[int]int{
+	1:                        http.StatusInternalServerError, // Unknown error
+	2:                        http.StatusServiceUnavailable,  // Service temporarily unavailable
+	4:                        http.StatusTooManyRequests,     // Cluster request limit reached
+	17:                       http.StatusTooManyRequests,     // Daily request limit reached
+	18:                       http.StatusTooManyRequests,     // QPS limit reached
+	qianfanErrorTokenInvalid: http.StatusUnauthorized,
+	qianfanErrorTokenExpired: http.StatusUnauthorized,
+	336000:                   http.StatusInternalServerError, // Internal error
+	336100:                   http.StatusServiceUnavailable,  // Service busy
+	336501:                   http.StatusTooManyRequests,     // RPM limit reached
+	336502:                   http.StatusTooManyRequests,     // TPM limit reached
+}
+
+// QianfanError represents an error of the Qianfan API, returned in the body of responses with
+// HTTP 200 as well as in failed responses.
+type QianfanError struct {
+	ErrorCode int    `json:"error_code,omitempty"`
+	ErrorMsg  string `json:"error_msg,omitempty"`
+}
+
+// QianfanUsage represents the token usage of a Qianfan response.
+type QianfanUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// QianfanFunctionCall represents the function call of an ERNIE response. ERNIE calls at most
+// one function per message, and explains why in its thoughts.
+type QianfanFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Thoughts  string `json:"thoughts,omitempty"`
+}
+
+// QianfanSearchInfo represents the web sources of an ERNIE response grounded by search.
+type QianfanSearchInfo struct {
+	SearchResults []struct {
+		Index int    `json:"index"`
+		URL   string `json:"url"`
+		Title string `json:"title"`
+	} `json:"search_results"`
+}
+
+// QianfanChatResponse represents a chat response of the Qianfan API, and a chunk of its streams.
+type QianfanChatResponse struct {
+	QianfanError
+	ID               string               `json:"id"`
+	Object           string               `json:"object"` // chat.completion, or chat.completion.chunk in streams
+	Created          int                  `json:"created"`
+	SentenceID       int                  `json:"sentence_id"` // Index of the chunk in streams
+	IsEnd            bool                 `json:"is_end"`      // Set on the last chunk of streams
+	IsTruncated      bool                 `json:"is_truncated"`
+	Result           string               `json:"result"`
+	FinishReason     string               `json:"finish_reason"`
+	NeedClearHistory bool                 `json:"need_clear_history"` // Set when the conversation was rejected by content moderation
+	FunctionCall     *QianfanFunctionCall `json:"function_call,omitempty"`
+	SearchInfo       *QianfanSearchInfo   `json:"search_info,omitempty"`
+	Usage            *QianfanUsage        `json:"usage,omitempty"`
+}
+
+// QianfanEmbeddingResponse represents an embedding response of the Qianfan API.
+type QianfanEmbeddingResponse struct {
+	QianfanError
+	ID      string `json:"id"`
+	Created int    `json:"created"`
+	Data    []struct {
+		Embedding []float32 `json:"embedding"`
+		Index     int       `json:"index"`
+	} `json:"data"`
+	Usage QianfanUsage `json:"usage"`
+}
+
+// qianfanTokenResponse represents the response of the OAuth endpoint exchanging API keys for
+// access tokens.
+type qianfanTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"` // Lifetime of the token in seconds
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// qianfanToken is an access token exchanged for an API key, cached until shortly before it expires.
+type qianfanToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// QianfanProvider implements the Provider interface for Baidu's Qianfan platform, which serves
+// the ERNIE models through its own API. Keys of the form "{api_key}:{secret_key}" are exchanged
+// for access tokens, which are cached until shortly before they expire and exchanged again when
+// Qianfan rejects them; other keys are used as access tokens as they are. Models are served at
+// their endpoint in qianfanModelEndpoints, or at the endpoint of their name. ERNIE specific
+// parameters, such as penalty_score or disable_search, go in ExtraParams.
+type QianfanProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	streamClient        *http.Client          // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+
+	tokenMu sync.Mutex               // Serializes token exchanges, so concurrent requests share one
+	tokens  map[string]*qianfanToken // Key value -> access token
+}
+
+// NewQianfanProvider creates a new Qianfan provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewQianfanProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*QianfanProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://aip.baidubce.com"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &QianfanProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+		tokens:              make(map[string]*qianfanToken),
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Qianfan.
+func (provider *QianfanProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Qianfan
+}
+
+// WarmUp opens connections to the Qianfan API ahead of the first request.
+func (provider *QianfanProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the Qianfan provider.
+func (provider *QianfanProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "qianfan")
+}
+
+// ChatCompletion performs a chat completion request to the Qianfan API.
+// ERNIE's result and function call are mapped to the assistant message, the thoughts of its
+// function call to the thought of the message, and its web sources to the search results.
+func (provider *QianfanProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	requestBody := prepareQianfanChatRequest(messages, params, false)
+
+	responseBody, bifrostErr := provider.completeRequest(ctx, key, qianfanChatPath+qianfanEndpoint(model), requestBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var response QianfanChatResponse
+	rawResponse, bifrostErr := handleProviderResponse(responseBody, &response, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	assistantMessage := &schemas.AssistantMessage{}
+	if response.FunctionCall != nil {
+		assistantMessage.ToolCalls = &[]schemas.ToolCall{convertQianfanFunctionCall(response.ID, response.FunctionCall)}
+		if response.FunctionCall.Thoughts != "" {
+			assistantMessage.Thought = Ptr(response.FunctionCall.Thoughts)
+		}
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		ID:     response.ID,
+		Object: "chat.completion",
+		Choices: []schemas.BifrostResponseChoice{
+			{
+				Index: 0,
+				BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
+					Message: schemas.BifrostMessage{
+						Role: schemas.ModelChatMessageRoleAssistant,
+						Content: schemas.MessageContent{
+							ContentStr: Ptr(response.Result),
+						},
+						AssistantMessage: assistantMessage,
+					},
+				},
+				FinishReason: Ptr(mapQianfanFinishReason(&response)),
+			},
+		},
+		SearchResults: qianfanSearchResults(response.SearchInfo),
+		Usage:         convertQianfanUsage(response.Usage),
+		Model:         model,
+		Created:       response.Created,
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Qianfan,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// Embedding generates embeddings for the given input text(s) using Qianfan's embedding models,
+// e.g. embedding-v1 or bge-large-zh.
+func (provider *QianfanProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	texts := input.Texts
+	if input.Text != nil {
+		texts = []string{*input.Text}
+	}
+	if len(texts) == 0 {
+		return nil, newBifrostOperationError("invalid embedding input: at least one text is required", nil, schemas.Qianfan)
+	}
+
+	requestBody := map[string]interface{}{
+		"input": texts,
+	}
+	if params != nil {
+		if params.User != nil {
+			requestBody["user_id"] = *params.User
+		}
+		for k, v := range params.ExtraParams {
+			requestBody[k] = v
+		}
+	}
+
+	responseBody, bifrostErr := provider.completeRequest(ctx, key, qianfanEmbeddingPath+qianfanEndpoint(model), requestBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var response QianfanEmbeddingResponse
+	rawResponse, bifrostErr := handleProviderResponse(responseBody, &response, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	embeddings := make([]schemas.BifrostEmbedding, 0, len(response.Data))
+	for _, data := range response.Data {
+		embedding := data.Embedding
+		embeddings = append(embeddings, schemas.BifrostEmbedding{
+			Index:  data.Index,
+			Object: "embedding",
+			Embedding: schemas.BifrostEmbeddingResponse{
+				EmbeddingArray: &embedding,
+			},
+		})
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		ID:      response.ID,
+		Object:  "list",
+		Data:    embeddings,
+		Model:   model,
+		Created: response.Created,
+		Usage:   convertQianfanUsage(&response.Usage),
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Qianfan,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// ChatCompletionStream performs a streaming chat completion request to the Qianfan API.
+// It supports real-time streaming of responses using Server-Sent Events (SSE). Each chunk of
+// ERNIE carries the next sentence of the result; the last one is marked with is_end and carries
+// the usage of the whole response. Web sources are forwarded with the first chunk.
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+func (provider *QianfanProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	requestBody := prepareQianfanChatRequest(messages, params, true)
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Qianfan)
+	}
+
+	resp, bifrostErr := provider.openStream(ctx, key, qianfanChatPath+qianfanEndpoint(model), jsonBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Create response channel
+	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+
+	chunkIndex := -1
+
+	// Start streaming in a goroutine
+	go func() {
+		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, schemas.Qianfan, responseChan, provider.logger)
+		defer resp.Body.Close()
+
+		scanner := newStreamScanner(resp.Body)
+
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			// Skip empty lines and comments
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			objects, _ := splitStreamDataObjects(line)
+			for _, jsonData := range objects {
+				var chunk QianfanChatResponse
+				if err := sonic.Unmarshal([]byte(jsonData), &chunk); err != nil {
+					provider.logger.Warn(fmt.Sprintf("Failed to parse stream chunk: %v", err))
+					continue
+				}
+
+				// Errors in the middle of the stream end it
+				if chunk.ErrorCode != 0 {
+					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+					processAndSendBifrostError(ctx, postHookRunner, newQianfanError(chunk.QianfanError), responseChan, provider.logger)
+					return
+				}
+
+				delta := schemas.BifrostStreamDelta{}
+				if chunkIndex == -1 {
+					delta.Role = Ptr(string(schemas.ModelChatMessageRoleAssistant))
+				}
+				if chunk.Result != "" {
+					delta.Content = Ptr(chunk.Result)
+				}
+				if chunk.FunctionCall != nil {
+					delta.ToolCalls = []schemas.ToolCall{convertQianfanFunctionCall(chunk.ID, chunk.FunctionCall)}
+					if chunk.FunctionCall.Thoughts != "" {
+						delta.Thought = Ptr(chunk.FunctionCall.Thoughts)
+					}
+				}
+
+				if delta.Role != nil || delta.Content != nil || len(delta.ToolCalls) > 0 {
+					chunkIndex++
+					response := &schemas.BifrostResponse{
+						ID:     chunk.ID,
+						Object: "chat.completion.chunk",
+						Choices: []schemas.BifrostResponseChoice{
+							{
+								Index: 0,
+								BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+									Delta: delta,
+								},
+							},
+						},
+						Model:   model,
+						Created: chunk.Created,
+						ExtraFields: schemas.BifrostResponseExtraFields{
+							Provider:   schemas.Qianfan,
+							ChunkIndex: chunkIndex,
+						},
+					}
+					if chunkIndex == 0 {
+						response.SearchResults = qianfanSearchResults(chunk.SearchInfo)
+					}
+					processAndSendResponse(ctx, postHookRunner, response, responseChan, provider.logger)
+				}
+
+				if chunk.IsEnd {
+					finishReason := mapQianfanFinishReason(&chunk)
+					response := createBifrostChatCompletionChunkResponse(chunk.ID, convertQianfanUsage(chunk.Usage), &finishReason, chunkIndex, params, schemas.Qianfan)
+					response.Model = model
+
+					handleStreamEndWithSuccess(ctx, response, postHookRunner, responseChan, provider.logger)
+					return // End of stream
+				}
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			provider.logger.Warn(fmt.Sprintf("Error reading stream: %v", err))
+			processAndSendError(ctx, postHookRunner, err, responseChan, provider.logger)
+		}
+	}()
+
+	return responseChan, nil
+}
+
+func (provider *QianfanProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "qianfan")
+}
+
+func (provider *QianfanProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "qianfan")
+}
+
+func (provider *QianfanProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "qianfan")
+}
+
+func (provider *QianfanProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "qianfan")
+}
+
+func (provider *QianfanProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "qianfan")
+}
+
+func (provider *QianfanProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "qianfan")
+}
+
+func (provider *QianfanProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "qianfan")
+}
+
+func (provider *QianfanProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "qianfan")
+}
+
+func (provider *QianfanProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "qianfan")
+}
+
+func (provider *QianfanProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "qianfan")
+}
+
+// completeRequest sends a JSON request to a path of the Qianfan API with the access token of the
+// key and returns the body of a successful response. A request rejected because its access
+// token is invalid or expired is sent again once, with a newly exchanged token.
+func (provider *QianfanProvider) completeRequest(ctx context.Context, key schemas.Key, path string, requestBody map[string]interface{}) ([]byte, *schemas.BifrostError) {
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Qianfan)
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, bifrostErr := provider.accessToken(ctx, key)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+
+		// Set any extra headers from network config
+		setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+		req.SetRequestURI(provider.networkConfig.BaseURL + path + "?access_token=" + url.QueryEscape(token))
+		req.Header.SetMethod("POST")
+		req.Header.SetContentType("application/json")
+		req.SetBody(jsonBody)
+
+		bifrostErr = makeRequestWithContext(ctx, provider.client, req, resp)
+		statusCode := resp.StatusCode()
+		// Copy the body, the response is released below
+		body := append([]byte(nil), resp.Body()...)
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		var errorResp QianfanError
+		if err := sonic.Unmarshal(body, &errorResp); err != nil && statusCode == fasthttp.StatusOK {
+			return nil, newBifrostOperationError(schemas.ErrProviderResponseUnmarshal, err, schemas.Qianfan)
+		}
+		if errorResp.ErrorCode == 0 && statusCode != fasthttp.StatusOK {
+			provider.logger.Debug(fmt.Sprintf("error from qianfan provider: %s", string(body)))
+			return nil, newProviderAPIError(fmt.Sprintf("HTTP error from qianfan: %d", statusCode), fmt.Errorf("%s", string(body)), statusCode, schemas.Qianfan, nil, nil)
+		}
+		if errorResp.ErrorCode != 0 {
+			provider.logger.Debug(fmt.Sprintf("error from qianfan provider: %s", string(body)))
+			if attempt == 0 && provider.forgetRejectedToken(key, errorResp.ErrorCode) {
+				continue
+			}
+			return nil, newQianfanError(errorResp)
+		}
+
+		return body, nil
+	}
+}
+
+// openStream sends a streaming request to a path of the Qianfan API and returns the response
+// streaming its chunks. Qianfan answers a failed streaming request with a plain JSON error
+// rather than a stream, which is returned as an error; as in completeRequest, a request whose
+// access token is rejected is sent again once.
+func (provider *QianfanProvider) openStream(ctx context.Context, key schemas.Key, path string, jsonBody []byte) (*http.Response, *schemas.BifrostError) {
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, bifrostErr := provider.accessToken(ctx, key)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", provider.networkConfig.BaseURL+path+"?access_token="+url.QueryEscape(token), bytes.NewReader(jsonBody))
+		if err != nil {
+			return nil, newBifrostOperationError("error creating request", err, schemas.Qianfan)
+		}
+
+		// Set headers
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Cache-Control", "no-cache")
+
+		// Set any extra headers from network config
+		setExtraHeadersHTTP(req, provider.networkConfig.ExtraHeaders, nil)
+
+		resp, err := provider.streamClient.Do(req)
+		if err != nil {
+			return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, schemas.Qianfan)
+		}
+
+		if resp.StatusCode == http.StatusOK && !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+			return resp, nil
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		var errorResp QianfanError
+		if err := sonic.Unmarshal(body, &errorResp); err != nil || errorResp.ErrorCode == 0 {
+			return nil, newProviderAPIError(fmt.Sprintf("HTTP error from qianfan: %d", resp.StatusCode), fmt.Errorf("%s", string(body)), resp.StatusCode, schemas.Qianfan, nil, nil)
+		}
+		if attempt == 0 && provider.forgetRejectedToken(key, errorResp.ErrorCode) {
+			continue
+		}
+		return nil, newQianfanError(errorResp)
+	}
+}
+
+// accessToken returns the access token of a key: a cached or newly exchanged token for keys of
+// the form "{api_key}:{secret_key}", and the key itself otherwise.
+func (provider *QianfanProvider) accessToken(ctx context.Context, key schemas.Key) (string, *schemas.BifrostError) {
+	apiKey, secretKey, ok := strings.Cut(key.Value, ":")
+	if !ok || apiKey == "" || secretKey == "" {
+		return key.Value, nil
+	}
+
+	provider.tokenMu.Lock()
+	defer provider.tokenMu.Unlock()
+
+	now := time.Now()
+	if token, ok := provider.tokens[key.Value]; ok && now.Add(qianfanTokenRefreshMargin).Before(token.expiresAt) {
+		return token.value, nil
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	query := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {apiKey},
+		"client_secret": {secretKey},
+	}
+	req.SetRequestURI(provider.networkConfig.BaseURL + qianfanTokenPath + "?" + query.Encode())
+	req.Header.SetMethod("POST")
+
+	if bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp); bifrostErr != nil {
+		return "", bifrostErr
+	}
+
+	var tokenResp qianfanTokenResponse
+	if err := sonic.Unmarshal(resp.Body(), &tokenResp); err != nil {
+		return "", newBifrostOperationError(schemas.ErrProviderResponseUnmarshal, err, schemas.Qianfan)
+	}
+	if tokenResp.Error != "" || tokenResp.AccessToken == "" {
+		statusCode := resp.StatusCode()
+		if statusCode == fasthttp.StatusOK {
+			statusCode = fasthttp.StatusUnauthorized
+		}
+		message := fmt.Sprintf("failed to get qianfan access token: %s", tokenResp.Error)
+		if tokenResp.ErrorDescription != "" {
+			message += ": " + tokenResp.ErrorDescription
+		}
+		return "", newProviderAPIError(message, nil, statusCode, schemas.Qianfan, Ptr(tokenResp.Error), nil)
+	}
+
+	provider.tokens[key.Value] = &qianfanToken{
+		value:     tokenResp.AccessToken,
+		expiresAt: now.Add(time.Duration(tokenResp.ExpiresIn) * time.Second),
+	}
+	return tokenResp.AccessToken, nil
+}
+
+// forgetRejectedToken drops the cached access token of a key if Qianfan rejected it as invalid
+// or expired, and reports whether a new token can be exchanged for the key.
+func (provider *QianfanProvider) forgetRejectedToken(key schemas.Key, errorCode int) bool {
+	if errorCode != qianfanErrorTokenInvalid && errorCode != qianfanErrorTokenExpired {
+		return false
+	}
+	if _, _, ok := strings.Cut(key.Value, ":"); !ok {
+		return false
+	}
+	provider.tokenMu.Lock()
+	delete(provider.tokens, key.Value)
+	provider.tokenMu.Unlock()
+	return true
+}
+
+// qianfanEndpoint returns the endpoint serving a model.
+func qianfanEndpoint(model string) string {
+	if endpoint, ok := qianfanModelEndpoints[strings.ToLower(model)]; ok {
+		return endpoint
+	}
+	return model
+}
+
+// newQianfanError converts a Qianfan error into a BifrostError with the status code of its code.
+func newQianfanError(errorResp QianfanError) *schemas.BifrostError {
+	statusCode, ok := qianfanErrorStatusCodes[errorResp.ErrorCode]
+	if !ok {
+		statusCode = http.StatusBadRequest
+	}
+	code := fmt.Sprintf("%d", errorResp.ErrorCode)
+	bifrostErr := newProviderAPIError(errorResp.ErrorMsg, nil, statusCode, schemas.Qianfan, nil, nil)
+	bifrostErr.Error.Code = &code
+	return bifrostErr
+}
+
+// qianfanChatParamNames maps Bifrost parameter names to their names in the Qianfan chat API.
+var qianfanChatParamNames = map[string]string{
+	"max_tokens":     "max_output_tokens",
+	"stop_sequences": "stop",
+	"user":           "user_id",
+}
+
+// qianfanUnsupportedChatParams are the Bifrost parameters the Qianfan chat API rejects.
+var qianfanUnsupportedChatParams = []string{"top_k", "presence_penalty", "frequency_penalty", "parallel_tool_calls", "encoding_format", "dimensions"}
+
+// prepareQianfanChatRequest prepares the request body of Qianfan chat requests. System messages
+// go in the system field, tool results are sent as function messages and tools as functions.
+// ERNIE can only be forced to call a specific function, so other tool choices are not sent.
+func prepareQianfanChatRequest(messages []schemas.BifrostMessage, params *schemas.ModelParameters, stream bool) map[string]interface{} {
+	var system []string
+	qianfanMessages := make([]map[string]interface{}, 0, len(messages))
+	// Function messages are named after the function whose result they carry
+	toolCallNames := make(map[string]string)
+
+	for _, msg := range messages {
+		content := msg.Content.Text()
+
+		switch msg.Role {
+		case schemas.ModelChatMessageRoleSystem, schemas.ModelChatMessageRoleDeveloper:
+			system = append(system, content)
+
+		case schemas.ModelChatMessageRoleTool:
+			functionMessage := map[string]interface{}{
+				"role":    "function",
+				"content": content,
+			}
+			if msg.ToolMessage != nil && msg.ToolMessage.ToolCallID != nil {
+				functionMessage["name"] = toolCallNames[*msg.ToolMessage.ToolCallID]
+			}
+			qianfanMessages = append(qianfanMessages, functionMessage)
+
+		case schemas.ModelChatMessageRoleAssistant, schemas.ModelChatMessageRoleChatbot:
+			assistantMessage := map[string]interface{}{
+				"role":    "assistant",
+				"content": content,
+			}
+			if msg.AssistantMessage != nil && msg.AssistantMessage.ToolCalls != nil {
+				for i, toolCall := range *msg.AssistantMessage.ToolCalls {
+					if toolCall.Function.Name == nil {
+						continue
+					}
+					if toolCall.ID != nil {
+						toolCallNames[*toolCall.ID] = *toolCall.Function.Name
+					}
+					// ERNIE messages carry a single function call
+					if i > 0 {
+						continue
+					}
+					functionCall := map[string]interface{}{
+						"name":      *toolCall.Function.Name,
+						"arguments": toolCall.Function.Arguments,
+					}
+					if msg.AssistantMessage.Thought != nil {
+						functionCall["thoughts"] = *msg.AssistantMessage.Thought
+					}
+					assistantMessage["function_call"] = functionCall
+				}
+			}
+			qianfanMessages = append(qianfanMessages, assistantMessage)
+
+		default:
+			qianfanMessages = append(qianfanMessages, map[string]interface{}{
+				"role":    "user",
+				"content": content,
+			})
+		}
+	}
+
+	preparedParams := prepareParams(params)
+	for name, qianfanName := range qianfanChatParamNames {
+		if value, ok := preparedParams[name]; ok {
+			delete(preparedParams, name)
+			preparedParams[qianfanName] = value
+		}
+	}
+	for _, name := range qianfanUnsupportedChatParams {
+		delete(preparedParams, name)
+	}
+	// Tools and tool choice are converted below, unless passed as is through ExtraParams
+	for _, name := range []string{"tools", "tool_choice"} {
+		if params == nil || params.ExtraParams[name] == nil {
+			delete(preparedParams, name)
+		}
+	}
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"messages": qianfanMessages,
+	}, preparedParams)
+
+	if len(system) > 0 {
+		if _, exists := requestBody["system"]; !exists {
+			requestBody["system"] = strings.Join(system, "\n\n")
+		}
+	}
+
+	if stream {
+		requestBody["stream"] = true
+	}
+
+	if params == nil {
+		return requestBody
+	}
+
+	// Add functions if present, ERNIE only supports function tools
+	if params.Tools != nil && params.ExtraParams["functions"] == nil {
+		var functions []schemas.Function
+		for _, tool := range *params.Tools {
+			if tool.Type != "" && tool.Type != "function" {
+				continue
+			}
+			functions = append(functions, tool.Function)
+		}
+		if len(functions) > 0 {
+			requestBody["functions"] = functions
+		}
+	}
+
+	if params.ToolChoice != nil && params.ToolChoice.ToolChoiceStruct != nil && params.ExtraParams["tool_choice"] == nil {
+		if choice := params.ToolChoice.ToolChoiceStruct; choice.Type == schemas.ToolChoiceTypeFunction && choice.Function.Name != "" {
+			requestBody["tool_choice"] = map[string]interface{}{
+				"type":     "function",
+				"function": map[string]interface{}{"name": choice.Function.Name},
+			}
+		}
+	}
+
+	return requestBody
+}
+
+// convertQianfanFunctionCall converts the function call of an ERNIE response into a tool call.
+// ERNIE function calls have no ID, so the call is identified by the response.
+func convertQianfanFunctionCall(responseID string, functionCall *QianfanFunctionCall) schemas.ToolCall {
+	return schemas.ToolCall{
+		Type: Ptr("function"),
+		ID:   Ptr("call_" + responseID),
+		Function: schemas.FunctionCall{
+			Name:      Ptr(functionCall.Name),
+			Arguments: functionCall.Arguments,
+		},
+	}
+}
+
+// mapQianfanFinishReason maps ERNIE finish reasons to OpenAI-compatible ones.
+func mapQianfanFinishReason(response *QianfanChatResponse) string {
+	switch response.FinishReason {
+	case "normal", "stop", "":
+		if response.FunctionCall != nil {
+			return "tool_calls"
+		}
+		if response.IsTruncated {
+			return "length"
+		}
+		return "stop"
+	case "function_call":
+		return "tool_calls"
+	default:
+		// length and content_filter are the same as OpenAI's
+		return response.FinishReason
+	}
+}
+
+// convertQianfanUsage converts Qianfan token usage into Bifrost usage.
+func convertQianfanUsage(usage *QianfanUsage) *schemas.LLMUsage {
+	if usage == nil {
+		return nil
+	}
+	return &schemas.LLMUsage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
+
+// qianfanSearchResults converts the web sources of an ERNIE response into search results, in
+// the order of ERNIE, whose indexes match the citation markers of the result.
+func qianfanSearchResults(searchInfo *QianfanSearchInfo) []schemas.SearchResult {
+	if searchInfo == nil || len(searchInfo.SearchResults) == 0 {
+		return nil
+	}
+	searchResults := make([]schemas.SearchResult, 0, len(searchInfo.SearchResults))
+	for _, result := range searchInfo.SearchResults {
+		if result.URL == "" {
+			continue
+		}
+		searchResult := schemas.SearchResult{URL: result.URL}
+		if result.Title != "" {
+			searchResult.Title = Ptr(result.Title)
+		}
+		searchResults = append(searchResults, searchResult)
+	}
+	return searchResults
+}
@@ -0,0 +1,731 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the Qwen (Alibaba Cloud DashScope) provider implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// qwenCompatiblePath is the prefix of DashScope's OpenAI-compatible endpoints.
+	qwenCompatiblePath = "/compatible-mode/v1"
+	// qwenMultimodalPath is DashScope's native endpoint of multimodal models such as qwen-vl.
+	qwenMultimodalPath = "/api/v1/services/aigc/multimodal-generation/generation"
+)
+
+// QwenSearchResult is a web source of a response to a request with enable_search, returned when
+// search_options enables enable_source.
+type QwenSearchResult struct {
+	Index    int    `json:"index"`
+	Title    string `json:"title,omitempty"`
+	URL      string `json:"url"`
+	SiteName string `json:"site_name,omitempty"`
+	Icon     string `json:"icon,omitempty"`
+}
+
+// QwenSearchInfo carries the web sources of a search-grounded response.
+type QwenSearchInfo struct {
+	SearchResults []QwenSearchResult `json:"search_results,omitempty"`
+}
+
+// QwenMultimodalContent is a part of a message in DashScope's native multimodal format, which
+// holds exactly one of its fields.
+type QwenMultimodalContent struct {
+	Text  *string `json:"text,omitempty"`
+	Image *string `json:"image,omitempty"` // URL or data URL
+}
+
+// QwenMultimodalMessage is a message in DashScope's native multimodal format.
+type QwenMultimodalMessage struct {
+	Role             string                  `json:"role"`
+	Content          []QwenMultimodalContent `json:"content"`
+	ReasoningContent *string                 `json:"reasoning_content,omitempty"` // Thinking of qvq and thinking qwen-vl models
+}
+
+// QwenMultimodalResponse is a response, or a stream event, of DashScope's native multimodal
+// endpoint.
+type QwenMultimodalResponse struct {
+	RequestID string `json:"request_id"`
+	Output    struct {
+		Choices []struct {
+			FinishReason string                `json:"finish_reason"` // "null" until the last event of a stream
+			Message      QwenMultimodalMessage `json:"message"`
+		} `json:"choices"`
+		SearchInfo *QwenSearchInfo `json:"search_info,omitempty"`
+	} `json:"output"`
+	Usage *struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+		TotalTokens  int `json:"total_tokens,omitempty"`
+		ImageTokens  int `json:"image_tokens,omitempty"`
+	} `json:"usage,omitempty"`
+}
+
+// QwenError represents an error response of DashScope's native endpoints.
+type QwenError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// QwenProvider implements the Provider interface for Alibaba Cloud's DashScope API, which serves
+// the Qwen models. Chat and embedding requests use DashScope's OpenAI-compatible mode, except the
+// chats of multimodal models (qwen-vl, qvq), which use DashScope's native multimodal endpoint and
+// its content format. DashScope specific parameters, such as enable_search, search_options or
+// enable_thinking, go in ExtraParams.
+type QwenProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	streamClient        *http.Client          // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+}
+
+// NewQwenProvider creates a new Qwen provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+// The default base URL is DashScope's international endpoint; keys of the Beijing region need
+// https://dashscope.aliyuncs.com.
+func NewQwenProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*QwenProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://dashscope-intl.aliyuncs.com"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &QwenProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Qwen.
+func (provider *QwenProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Qwen
+}
+
+// WarmUp opens connections to the Qwen API ahead of the first request.
+func (provider *QwenProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the Qwen provider.
+func (provider *QwenProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "qwen")
+}
+
+// ChatCompletion performs a chat completion request to the DashScope API.
+// The reasoning_content of thinking models is mapped to the thought of the assistant message, and
+// the web sources of requests with enable_search onto the search results of the response.
+func (provider *QwenProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if isQwenMultimodalModel(model) {
+		return provider.multimodalChatCompletion(ctx, model, key, messages, params)
+	}
+
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+	}, preparedParams)
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Qwen)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + qwenCompatiblePath + "/chat/completions")
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from qwen provider: %s", string(resp.Body())))
+
+		var errorResp map[string]interface{}
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		bifrostErr.Error.Message = fmt.Sprintf("Qwen error: %v", errorResp)
+		return nil, bifrostErr
+	}
+
+	responseBody := resp.Body()
+
+	// Parse and map reasoning_content to thought
+	response, bifrostErr := parseReasoningChatResponse(responseBody, schemas.Qwen, params, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var fields struct {
+		SearchInfo *QwenSearchInfo `json:"search_info,omitempty"`
+	}
+	if err := sonic.Unmarshal(responseBody, &fields); err != nil {
+		provider.logger.Warn(fmt.Sprintf("Failed to parse qwen search info: %v", err))
+	} else {
+		response.SearchResults = qwenSearchResults(fields.SearchInfo)
+	}
+
+	return response, nil
+}
+
+// Embedding generates embeddings for the given input text(s) using DashScope's OpenAI compatible
+// embeddings endpoint, e.g. with text-embedding-v3.
+func (provider *QwenProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	requestBody := prepareOpenAIEmbeddingRequest(input, params)
+	requestBody["model"] = model
+
+	return handleOpenAIEmbeddingRequest(
+		ctx,
+		provider.client,
+		provider.networkConfig.BaseURL+qwenCompatiblePath+"/embeddings",
+		requestBody,
+		key,
+		params,
+		provider.networkConfig.ExtraHeaders,
+		schemas.Qwen,
+		provider.sendBackRawResponse,
+		provider.logger,
+	)
+}
+
+// ChatCompletionStream performs a streaming chat completion request to the DashScope API.
+// It supports real-time streaming of responses using Server-Sent Events (SSE).
+// Uses DashScope's OpenAI-compatible streaming format, except for multimodal models. The search
+// results of requests with enable_search are only forwarded on the first chunk that carries them.
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+func (provider *QwenProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	if isQwenMultimodalModel(model) {
+		return provider.multimodalChatCompletionStream(ctx, postHookRunner, model, key, messages, params)
+	}
+
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+		"stream":   true,
+		"stream_options": map[string]interface{}{
+			"include_usage": true,
+		},
+	}, preparedParams)
+
+	// Prepare Qwen headers
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + key.Value,
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+
+	sentSearchResults := false
+	chunkHook := func(rawChunk map[string]interface{}, response *schemas.BifrostResponse) {
+		if sentSearchResults {
+			return
+		}
+		searchInfo, err := parseQwenSearchInfo(rawChunk)
+		if err != nil {
+			provider.logger.Warn(fmt.Sprintf("Failed to parse qwen search info: %v", err))
+			return
+		}
+		if searchResults := qwenSearchResults(searchInfo); len(searchResults) > 0 {
+			response.SearchResults = searchResults
+			sentSearchResults = true
+		}
+	}
+
+	// Use shared OpenAI-compatible streaming logic
+	return handleOpenAIStreamingWithHook(
+		ctx,
+		provider.streamClient,
+		provider.networkConfig.BaseURL+qwenCompatiblePath+"/chat/completions",
+		requestBody,
+		headers,
+		provider.networkConfig.ExtraHeaders,
+		schemas.Qwen,
+		params,
+		postHookRunner,
+		provider.logger,
+		chunkHook,
+		nil,
+	)
+}
+
+func (provider *QwenProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "qwen")
+}
+
+func (provider *QwenProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "qwen")
+}
+
+func (provider *QwenProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "qwen")
+}
+
+func (provider *QwenProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "qwen")
+}
+
+func (provider *QwenProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "qwen")
+}
+
+func (provider *QwenProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "qwen")
+}
+
+func (provider *QwenProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "qwen")
+}
+
+func (provider *QwenProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "qwen")
+}
+
+func (provider *QwenProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "qwen")
+}
+
+func (provider *QwenProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "qwen")
+}
+
+// multimodalChatCompletion performs a chat completion request to DashScope's native multimodal
+// endpoint.
+func (provider *QwenProvider) multimodalChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	requestBody, bifrostErr := qwenMultimodalRequestBody(model, messages, params, false)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Qwen)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + qwenMultimodalPath)
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr = makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from qwen provider: %s", string(resp.Body())))
+
+		var errorResp QwenError
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		bifrostErr.Error.Message = "Qwen error: " + errorResp.Message
+		if errorResp.Code != "" {
+			bifrostErr.Error.Code = Ptr(errorResp.Code)
+		}
+		return nil, bifrostErr
+	}
+
+	var multimodalResponse QwenMultimodalResponse
+	rawResponse, bifrostErr := handleProviderResponse(resp.Body(), &multimodalResponse, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	choices := make([]schemas.BifrostResponseChoice, 0, len(multimodalResponse.Output.Choices))
+	for i, choice := range multimodalResponse.Output.Choices {
+		message := schemas.BifrostMessage{
+			Role:    schemas.ModelChatMessageRoleAssistant,
+			Content: schemas.MessageContent{ContentStr: Ptr(qwenMultimodalText(choice.Message.Content))},
+		}
+		if choice.Message.ReasoningContent != nil && *choice.Message.ReasoningContent != "" {
+			message.AssistantMessage = &schemas.AssistantMessage{Thought: choice.Message.ReasoningContent}
+		}
+		choices = append(choices, schemas.BifrostResponseChoice{
+			Index:        i,
+			FinishReason: Ptr(choice.FinishReason),
+			BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
+				Message: message,
+			},
+		})
+	}
+
+	response := &schemas.BifrostResponse{
+		ID:            multimodalResponse.RequestID,
+		Object:        "chat.completion",
+		Model:         model,
+		Created:       int(time.Now().Unix()),
+		Choices:       choices,
+		Usage:         qwenMultimodalUsage(&multimodalResponse),
+		SearchResults: qwenSearchResults(multimodalResponse.Output.SearchInfo),
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Qwen,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		response.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		response.ExtraFields.Params = *params
+	}
+
+	return response, nil
+}
+
+// multimodalChatCompletionStream performs a streaming chat completion request to DashScope's
+// native multimodal endpoint, with incremental output so every event carries a delta.
+func (provider *QwenProvider) multimodalChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	providerName := provider.GetProviderKey()
+
+	requestBody, bifrostErr := qwenMultimodalRequestBody(model, messages, params, true)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
+	}
+
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Create HTTP request for streaming
+	req, err := http.NewRequestWithContext(ctx, "POST", provider.networkConfig.BaseURL+qwenMultimodalPath, strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, providerName)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("X-DashScope-SSE", "enable")
+
+	// Set any extra headers from network config
+	setExtraHeadersHTTP(req, provider.networkConfig.ExtraHeaders, nil)
+
+	// Make the request
+	resp, err := provider.streamClient.Do(req)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, providerName)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, newProviderAPIError(fmt.Sprintf("HTTP error from %s: %d", providerName, resp.StatusCode), fmt.Errorf("%s", string(body)), resp.StatusCode, providerName, nil, nil)
+	}
+
+	// Create response channel
+	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+
+	chunkIndex := -1
+
+	// Start streaming in a goroutine
+	go func() {
+		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, provider.logger)
+		defer resp.Body.Close()
+
+		scanner := newStreamScanner(resp.Body)
+
+		var event string
+		var data []string
+		sentSearchResults := false
+
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			if line != "" {
+				// Accumulate the fields of the event until the blank line that ends it, the
+				// :HTTP_STATUS comments are repeated in the data of error events
+				switch {
+				case strings.HasPrefix(line, "event:"):
+					event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+				case strings.HasPrefix(line, "data:"):
+					data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+				}
+				continue
+			}
+			if len(data) == 0 {
+				event = ""
+				continue
+			}
+
+			eventData := strings.Join(data, "\n")
+			eventType := event
+			event, data = "", nil
+
+			if eventType == "error" {
+				var errorResp QwenError
+				message := eventData
+				if err := sonic.Unmarshal([]byte(eventData), &errorResp); err == nil && errorResp.Message != "" {
+					message = errorResp.Message
+				}
+				ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+				processAndSendBifrostError(ctx, postHookRunner, newProviderAPIError("Qwen error: "+message, nil, fasthttp.StatusInternalServerError, providerName, nil, nil), responseChan, provider.logger)
+				return
+			}
+
+			// An event can pack several objects into its data
+			objects, _ := splitStreamDataObjects(eventData)
+			for _, jsonData := range objects {
+				var chunk QwenMultimodalResponse
+				if err := sonic.Unmarshal([]byte(jsonData), &chunk); err != nil {
+					provider.logger.Warn(fmt.Sprintf("Failed to parse stream event: %v", err))
+					continue
+				}
+				if len(chunk.Output.Choices) == 0 {
+					continue
+				}
+				choice := chunk.Output.Choices[0]
+
+				delta := schemas.BifrostStreamDelta{}
+				if text := qwenMultimodalText(choice.Message.Content); text != "" {
+					delta.Content = &text
+				}
+				if choice.Message.ReasoningContent != nil && *choice.Message.ReasoningContent != "" {
+					delta.Thought = choice.Message.ReasoningContent
+				}
+
+				if delta.Content != nil || delta.Thought != nil {
+					chunkIndex++
+					if chunkIndex == 0 {
+						delta.Role = Ptr(string(schemas.ModelChatMessageRoleAssistant))
+					}
+					response := &schemas.BifrostResponse{
+						ID:     chunk.RequestID,
+						Object: "chat.completion.chunk",
+						Model:  model,
+						Choices: []schemas.BifrostResponseChoice{
+							{
+								Index: 0,
+								BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+									Delta: delta,
+								},
+							},
+						},
+						ExtraFields: schemas.BifrostResponseExtraFields{
+							Provider:   providerName,
+							ChunkIndex: chunkIndex,
+						},
+					}
+					if searchResults := qwenSearchResults(chunk.Output.SearchInfo); !sentSearchResults && len(searchResults) > 0 {
+						response.SearchResults = searchResults
+						sentSearchResults = true
+					}
+					processAndSendResponse(ctx, postHookRunner, response, responseChan, provider.logger)
+				}
+
+				if choice.FinishReason != "" && choice.FinishReason != "null" {
+					// The usage of every event is cumulative, the last one is the total
+					response := createBifrostChatCompletionChunkResponse(chunk.RequestID, qwenMultimodalUsage(&chunk), Ptr(choice.FinishReason), chunkIndex, params, providerName)
+					response.Model = model
+
+					handleStreamEndWithSuccess(ctx, response, postHookRunner, responseChan, provider.logger)
+					return // End of stream
+				}
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			provider.logger.Warn(fmt.Sprintf("Error reading stream: %v", err))
+			processAndSendError(ctx, postHookRunner, err, responseChan, provider.logger)
+		}
+	}()
+
+	return responseChan, nil
+}
+
+// isQwenMultimodalModel reports whether model is a multimodal model served by DashScope's native
+// multimodal endpoint, e.g. qwen-vl-max, qwen2.5-vl-72b-instruct or qvq-max.
+func isQwenMultimodalModel(model string) bool {
+	lowerModel := strings.ToLower(model)
+	return strings.Contains(lowerModel, "-vl") || strings.HasPrefix(lowerModel, "qvq")
+}
+
+// qwenMultimodalRequestBody builds the body of a request to DashScope's native multimodal
+// endpoint. Messages are converted to its content format, where every part holds either a text
+// or an image, and parameters go in the parameters object. Tools are not supported.
+func qwenMultimodalRequestBody(model string, messages []schemas.BifrostMessage, params *schemas.ModelParameters, stream bool) (map[string]interface{}, *schemas.BifrostError) {
+	if params != nil && params.Tools != nil && len(*params.Tools) > 0 {
+		return nil, newConfigurationError(fmt.Sprintf("tools are not supported by qwen multimodal model %s", model), schemas.Qwen)
+	}
+
+	formattedMessages := make([]QwenMultimodalMessage, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == schemas.ModelChatMessageRoleTool || (msg.AssistantMessage != nil && msg.AssistantMessage.ToolCalls != nil) {
+			return nil, newConfigurationError(fmt.Sprintf("tool calls are not supported by qwen multimodal model %s", model), schemas.Qwen)
+		}
+
+		message := QwenMultimodalMessage{Role: string(msg.Role)}
+		if msg.Content.ContentStr != nil {
+			message.Content = append(message.Content, QwenMultimodalContent{Text: msg.Content.ContentStr})
+		} else if msg.Content.ContentBlocks != nil {
+			for _, block := range *msg.Content.ContentBlocks {
+				switch {
+				case block.Type == schemas.ContentBlockTypeText && block.Text != nil:
+					message.Content = append(message.Content, QwenMultimodalContent{Text: block.Text})
+				case block.Type == schemas.ContentBlockTypeImage && block.ImageURL != nil:
+					// DashScope takes both URLs and base64 data URLs
+					sanitizedURL, err := SanitizeImageURL(block.ImageURL.URL)
+					if err != nil {
+						return nil, newConfigurationError(fmt.Sprintf("invalid image: %v", err), schemas.Qwen)
+					}
+					message.Content = append(message.Content, QwenMultimodalContent{Image: &sanitizedURL})
+				default:
+					return nil, newConfigurationError(fmt.Sprintf("content of type %s is not supported by qwen multimodal model %s", block.Type, model), schemas.Qwen)
+				}
+			}
+		}
+		formattedMessages = append(formattedMessages, message)
+	}
+
+	parameters := prepareParams(params)
+	parameters["result_format"] = "message"
+	if stream {
+		parameters["incremental_output"] = true
+	}
+
+	return map[string]interface{}{
+		"model": model,
+		"input": map[string]interface{}{
+			"messages": formattedMessages,
+		},
+		"parameters": parameters,
+	}, nil
+}
+
+// qwenMultimodalText joins the text parts of a message in DashScope's native multimodal format.
+func qwenMultimodalText(content []QwenMultimodalContent) string {
+	var text strings.Builder
+	for _, part := range content {
+		if part.Text != nil {
+			text.WriteString(*part.Text)
+		}
+	}
+	return text.String()
+}
+
+// qwenMultimodalUsage converts the usage of a native multimodal response, whose input tokens
+// include the tokens of its images.
+func qwenMultimodalUsage(response *QwenMultimodalResponse) *schemas.LLMUsage {
+	if response.Usage == nil {
+		return nil
+	}
+	totalTokens := response.Usage.TotalTokens
+	if totalTokens == 0 {
+		totalTokens = response.Usage.InputTokens + response.Usage.OutputTokens
+	}
+	return &schemas.LLMUsage{
+		PromptTokens:     response.Usage.InputTokens,
+		CompletionTokens: response.Usage.OutputTokens,
+		TotalTokens:      totalTokens,
+	}
+}
+
+// parseQwenSearchInfo extracts the search info of a raw stream chunk, nil if it has none.
+func parseQwenSearchInfo(rawChunk map[string]interface{}) (*QwenSearchInfo, error) {
+	rawSearchInfo, ok := rawChunk["search_info"]
+	if !ok || rawSearchInfo == nil {
+		return nil, nil
+	}
+	encoded, err := sonic.Marshal(rawSearchInfo)
+	if err != nil {
+		return nil, err
+	}
+	var searchInfo QwenSearchInfo
+	if err := sonic.Unmarshal(encoded, &searchInfo); err != nil {
+		return nil, err
+	}
+	return &searchInfo, nil
+}
+
+// qwenSearchResults converts the web sources of a search-grounded response into search results,
+// ordered by their index so the [n] markers of the content match their position.
+func qwenSearchResults(searchInfo *QwenSearchInfo) []schemas.SearchResult {
+	if searchInfo == nil || len(searchInfo.SearchResults) == 0 {
+		return nil
+	}
+	results := make([]QwenSearchResult, len(searchInfo.SearchResults))
+	copy(results, searchInfo.SearchResults)
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+
+	searchResults := make([]schemas.SearchResult, 0, len(results))
+	for _, result := range results {
+		searchResult := schemas.SearchResult{URL: result.URL}
+		if result.Title != "" {
+			searchResult.Title = Ptr(result.Title)
+		}
+		searchResults = append(searchResults, searchResult)
+	}
+	return searchResults
+}
@@ -0,0 +1,896 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the Replicate provider implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// replicatePollInterval is the delay before the first poll of a prediction, doubled after
+	// every poll up to replicateMaxPollInterval.
+	replicatePollInterval    = 250 * time.Millisecond
+	replicateMaxPollInterval = 2 * time.Second
+	// replicateMaxPollDuration bounds the time waited for a prediction, which includes booting
+	// the model when it is cold.
+	replicateMaxPollDuration = 5 * time.Minute
+	// replicateCancelTimeout bounds the request canceling a prediction that was not waited for.
+	replicateCancelTimeout = 10 * time.Second
+)
+
+// Replicate prediction statuses.
+const (
+	replicateStatusStarting   = "starting"
+	replicateStatusProcessing = "processing"
+	replicateStatusSucceeded  = "succeeded"
+	replicateStatusFailed     = "failed"
+	replicateStatusCanceled   = "canceled"
+)
+
+// replicatePredictionParams are the ExtraParams set on the prediction itself rather than in its
+// model input.
+var replicatePredictionParams = map[string]bool{
+	"webhook":               true,
+	"webhook_events_filter": true,
+}
+
+// ReplicatePrediction represents a prediction, as returned when creating, polling and canceling it.
+type ReplicatePrediction struct {
+	ID        string      `json:"id"`
+	Model     string      `json:"model,omitempty"`
+	Version   string      `json:"version,omitempty"`
+	Status    string      `json:"status"`           // starting, processing, succeeded, failed or canceled
+	Output    interface{} `json:"output,omitempty"` // Depends on the model, usually a string or a list of strings
+	Error     interface{} `json:"error,omitempty"`
+	CreatedAt string      `json:"created_at,omitempty"`
+	URLs      struct {
+		Get    string `json:"get,omitempty"`
+		Cancel string `json:"cancel,omitempty"`
+		Stream string `json:"stream,omitempty"` // Set when the prediction was created with stream
+	} `json:"urls"`
+	Metrics *struct {
+		PredictTime      *float64 `json:"predict_time,omitempty"`
+		InputTokenCount  *int     `json:"input_token_count,omitempty"`
+		OutputTokenCount *int     `json:"output_token_count,omitempty"`
+	} `json:"metrics,omitempty"`
+}
+
+// ReplicateError represents an error response from the Replicate API.
+type ReplicateError struct {
+	Title  string `json:"title,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// ReplicateProvider implements the Provider interface for Replicate's predictions API.
+// Replicate runs a model as a prediction that is created and then polled until it ends, so every
+// request creates a prediction and waits for it, except video generation, whose predictions are
+// returned as jobs. Chat completion streams read the server-sent events of their prediction.
+type ReplicateProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	streamClient        *http.Client          // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+}
+
+// NewReplicateProvider creates a new Replicate provider instance.
+// It initializes the HTTP clients with the provided configuration.
+// The clients are configured with timeouts, concurrency limits, and optional proxy settings.
+func NewReplicateProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*ReplicateProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://api.replicate.com"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &ReplicateProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Replicate.
+func (provider *ReplicateProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Replicate
+}
+
+// WarmUp opens connections to the Replicate API ahead of the first request.
+func (provider *ReplicateProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion runs a language model, e.g. "meta/meta-llama-3-8b", on a prompt.
+func (provider *ReplicateProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	input := replicateLanguageInput(params)
+	input["prompt"] = text
+
+	prediction, rawResponse, bifrostErr := provider.runPrediction(ctx, model, key, replicatePredictionBody(input, params, false))
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	return provider.completionResponse(prediction, rawResponse, model, "text_completion", params), nil
+}
+
+// ChatCompletion runs a language model, e.g. "meta/meta-llama-3-70b-instruct", on a conversation.
+// Replicate language models take a single prompt: system messages become the system_prompt, and a
+// conversation of several turns is sent as a transcript. Only text content is supported.
+func (provider *ReplicateProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	input, bifrostErr := replicateChatInput(messages, params)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	prediction, rawResponse, bifrostErr := provider.runPrediction(ctx, model, key, replicatePredictionBody(input, params, false))
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	return provider.completionResponse(prediction, rawResponse, model, "chat.completion", params), nil
+}
+
+// ChatCompletionStream creates a streaming prediction and forwards the output events of its
+// stream URL. The usage is read from the prediction once its stream is done.
+func (provider *ReplicateProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	providerName := provider.GetProviderKey()
+
+	input, bifrostErr := replicateChatInput(messages, params)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	prediction, _, bifrostErr := provider.createPrediction(ctx, model, key, replicatePredictionBody(input, params, true))
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+	if prediction.URLs.Stream == "" {
+		provider.cancelPrediction(key, prediction)
+		return nil, newConfigurationError(fmt.Sprintf("replicate model %s does not support streaming", model), providerName)
+	}
+
+	// Create HTTP request for streaming
+	req, err := http.NewRequestWithContext(ctx, "GET", prediction.URLs.Stream, nil)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, providerName)
+	}
+
+	// Set headers
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-store")
+
+	// Set any extra headers from network config
+	setExtraHeadersHTTP(req, provider.networkConfig.ExtraHeaders, nil)
+
+	// Make the request
+	resp, err := provider.streamClient.Do(req)
+	if err != nil {
+		provider.cancelPrediction(key, prediction)
+		return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, providerName)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		provider.cancelPrediction(key, prediction)
+		return nil, newProviderAPIError(fmt.Sprintf("HTTP error from %s: %d", providerName, resp.StatusCode), fmt.Errorf("%s", string(body)), resp.StatusCode, providerName, nil, nil)
+	}
+
+	// Create response channel
+	responseChan := make(chan *schemas.BifrostStream, schemas.DefaultStreamBufferSize)
+
+	chunkIndex := -1
+
+	// Start streaming in a goroutine
+	go func() {
+		defer close(responseChan)
+		defer recoverStreamPanic(ctx, postHookRunner, providerName, responseChan, provider.logger)
+		defer resp.Body.Close()
+
+		// Outputs such as long code blocks can exceed the default line size. The data of output
+		// events is text rather than JSON objects, so it is not split like other streams.
+		scanner := newStreamScanner(resp.Body)
+
+		var event string
+		var data []string
+
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			if line != "" {
+				// Accumulate the fields of the event until the blank line that ends it
+				switch {
+				case strings.HasPrefix(line, "event:"):
+					event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+				case strings.HasPrefix(line, "data:"):
+					value := strings.TrimPrefix(line, "data:")
+					data = append(data, strings.TrimPrefix(value, " "))
+				}
+				continue
+			}
+
+			eventData := strings.Join(data, "\n")
+			eventType := event
+			event, data = "", nil
+
+			switch eventType {
+			case "output":
+				chunkIndex++
+
+				var role *string
+				if chunkIndex == 0 {
+					role = Ptr(string(schemas.ModelChatMessageRoleAssistant))
+				}
+				processAndSendResponse(ctx, postHookRunner, &schemas.BifrostResponse{
+					ID:     prediction.ID,
+					Object: "chat.completion.chunk",
+					Model:  model,
+					Choices: []schemas.BifrostResponseChoice{
+						{
+							Index: 0,
+							BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+								Delta: schemas.BifrostStreamDelta{
+									Role:    role,
+									Content: &eventData,
+								},
+							},
+						},
+					},
+					ExtraFields: schemas.BifrostResponseExtraFields{
+						Provider:   providerName,
+						ChunkIndex: chunkIndex,
+					},
+				}, responseChan, provider.logger)
+
+			case "error":
+				var errorResp ReplicateError
+				message := eventData
+				if err := sonic.Unmarshal([]byte(eventData), &errorResp); err == nil && errorResp.Detail != "" {
+					message = errorResp.Detail
+				}
+				ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+				processAndSendBifrostError(ctx, postHookRunner, newProviderAPIError("Replicate error: "+message, nil, fasthttp.StatusInternalServerError, providerName, nil, nil), responseChan, provider.logger)
+				return
+
+			case "done":
+				var done struct {
+					Reason string `json:"reason,omitempty"` // Set when the prediction was canceled
+				}
+				_ = sonic.Unmarshal([]byte(eventData), &done)
+				if done.Reason == replicateStatusCanceled {
+					ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+					processAndSendBifrostError(ctx, postHookRunner, newBifrostOperationError(fmt.Sprintf("prediction %s was canceled", prediction.ID), nil, providerName), responseChan, provider.logger)
+					return
+				}
+
+				// The usage is only reported by the prediction itself
+				var usage *schemas.LLMUsage
+				if ended, _, bifrostErr := provider.getPrediction(ctx, key, prediction); bifrostErr == nil {
+					usage = replicateUsage(ended)
+				} else {
+					provider.logger.Debug(fmt.Sprintf("failed to read the usage of replicate prediction %s: %s", prediction.ID, bifrostErr.Error.Message))
+				}
+
+				response := createBifrostChatCompletionChunkResponse(prediction.ID, usage, Ptr("stop"), chunkIndex, params, providerName)
+				response.Model = model
+
+				handleStreamEndWithSuccess(ctx, response, postHookRunner, responseChan, provider.logger)
+				return // End of stream
+
+			default:
+				// Keep-alive comments and empty events carry nothing to forward
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			provider.logger.Warn(fmt.Sprintf("Error reading stream: %v", err))
+			processAndSendError(ctx, postHookRunner, err, responseChan, provider.logger)
+		}
+	}()
+
+	return responseChan, nil
+}
+
+// Embedding is not supported by the Replicate provider.
+func (provider *ReplicateProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("embedding", "replicate")
+}
+
+func (provider *ReplicateProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "replicate")
+}
+
+func (provider *ReplicateProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "replicate")
+}
+
+func (provider *ReplicateProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "replicate")
+}
+
+func (provider *ReplicateProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "replicate")
+}
+
+func (provider *ReplicateProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "replicate")
+}
+
+// ImageGeneration runs an image model, e.g. "black-forest-labs/flux-schnell", and waits for its
+// images. Size is sent as an aspect ratio. Inputs the model does not take are rejected by
+// Replicate, model specific ones such as go_fast go in ModelParameters.ExtraParams.
+func (provider *ReplicateProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	n, err := imageCount(input)
+	if err != nil {
+		return nil, newConfigurationError(err.Error(), schemas.Replicate)
+	}
+
+	modelInput := map[string]interface{}{
+		"prompt": input.Prompt,
+	}
+	if n > 1 {
+		modelInput["num_outputs"] = n
+	}
+	if input.Size != nil && *input.Size != "" {
+		width, height, err := parseImageSize(*input.Size)
+		if err != nil {
+			return nil, newConfigurationError(err.Error(), schemas.Replicate)
+		}
+		divisor := gcd(width, height)
+		modelInput["aspect_ratio"] = fmt.Sprintf("%d:%d", width/divisor, height/divisor)
+	} else if input.AspectRatio != nil && *input.AspectRatio != "" {
+		modelInput["aspect_ratio"] = *input.AspectRatio
+	}
+	if input.NegativePrompt != nil {
+		modelInput["negative_prompt"] = *input.NegativePrompt
+	}
+	if input.Seed != nil {
+		modelInput["seed"] = *input.Seed
+	}
+	outputFormat := "webp"
+	if input.OutputFormat != nil {
+		outputFormat = *input.OutputFormat
+		modelInput["output_format"] = outputFormat
+	}
+
+	prediction, rawResponse, bifrostErr := provider.runPrediction(ctx, model, key, replicatePredictionBody(modelInput, params, false))
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	urls := replicateOutputURLs(prediction.Output)
+	if len(urls) == 0 {
+		return nil, newBifrostOperationError("prediction finished without an image", nil, schemas.Replicate)
+	}
+
+	images := make([]schemas.GeneratedImage, 0, len(urls))
+	for _, imageURL := range urls {
+		image := schemas.GeneratedImage{
+			URL:      imageURL,
+			MimeType: imageMimeType(outputFormat),
+		}
+		if !wantsImageURL(input) {
+			image.B64JSON, image.MimeType, bifrostErr = downloadImage(ctx, provider.client, imageURL, schemas.Replicate)
+			if bifrostErr != nil {
+				return nil, bifrostErr
+			}
+			image.URL = ""
+		}
+		images = append(images, image)
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		ID:      prediction.ID,
+		Object:  "image.generation",
+		Model:   model,
+		Created: int(time.Now().Unix()),
+		Image: &schemas.BifrostImage{
+			Images: images,
+			Usage:  &schemas.ImageUsage{Images: len(images)},
+		},
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Replicate,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// VideoGeneration creates a prediction with a video model, e.g. "minimax/video-01", and returns
+// it as a job without waiting for it. The inputs are sent under their common names: image for
+// ImageURL, and prompt, negative_prompt, aspect_ratio, resolution, duration, loop and seed.
+// Models that name them differently take them through ModelParameters.ExtraParams.
+func (provider *ReplicateProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	modelInput := map[string]interface{}{
+		"prompt": input.Prompt,
+	}
+	if input.NegativePrompt != nil {
+		modelInput["negative_prompt"] = *input.NegativePrompt
+	}
+	if input.ImageURL != nil && *input.ImageURL != "" {
+		// Replicate accepts hosted and data URLs for file inputs
+		modelInput["image"] = *input.ImageURL
+	}
+	if input.AspectRatio != nil {
+		modelInput["aspect_ratio"] = *input.AspectRatio
+	}
+	if input.Resolution != nil {
+		modelInput["resolution"] = *input.Resolution
+	}
+	if input.Duration != nil {
+		modelInput["duration"] = *input.Duration
+	}
+	if input.Loop != nil {
+		modelInput["loop"] = *input.Loop
+	}
+	if input.Seed != nil {
+		modelInput["seed"] = *input.Seed
+	}
+
+	prediction, rawResponse, bifrostErr := provider.createPrediction(ctx, model, key, replicatePredictionBody(modelInput, params, false))
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	bifrostResponse := provider.videoResponse(prediction, rawResponse)
+	bifrostResponse.Video.Prompt = input.Prompt
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// VideoStatus returns the state of a video prediction and, once it succeeded, its videos.
+func (provider *ReplicateProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	prediction, rawResponse, bifrostErr := provider.getPrediction(ctx, key, &ReplicatePrediction{ID: input.JobID})
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+	return provider.videoResponse(prediction, rawResponse), nil
+}
+
+func (provider *ReplicateProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "replicate")
+}
+
+func (provider *ReplicateProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "replicate")
+}
+
+// runPrediction creates a prediction and polls it until it succeeds. A prediction that fails or
+// is canceled is returned as an error, and one that is not waited for to the end is canceled so
+// that it stops running, and billing, on Replicate.
+func (provider *ReplicateProvider) runPrediction(ctx context.Context, model string, key schemas.Key, requestBody map[string]interface{}) (*ReplicatePrediction, interface{}, *schemas.BifrostError) {
+	prediction, rawResponse, bifrostErr := provider.createPrediction(ctx, model, key, requestBody)
+	if bifrostErr != nil {
+		return nil, nil, bifrostErr
+	}
+
+	if !replicateEnded(prediction.Status) {
+		current := prediction
+		poll := asyncPoll{interval: replicatePollInterval, maxInterval: replicateMaxPollInterval, timeout: replicateMaxPollDuration}
+		prediction, bifrostErr = pollOperation(ctx, poll, "prediction "+prediction.ID, schemas.Replicate, func(ctx context.Context) (*ReplicatePrediction, bool, *schemas.BifrostError) {
+			polled, raw, bifrostErr := provider.getPrediction(ctx, key, current)
+			if bifrostErr != nil {
+				return nil, false, bifrostErr
+			}
+			current, rawResponse = polled, raw
+			return polled, replicateEnded(polled.Status), nil
+		})
+		if bifrostErr != nil {
+			if !replicateEnded(current.Status) {
+				provider.cancelPrediction(key, current)
+			}
+			return nil, nil, bifrostErr
+		}
+	}
+
+	switch prediction.Status {
+	case replicateStatusSucceeded:
+		return prediction, rawResponse, nil
+	case replicateStatusCanceled:
+		return nil, nil, newBifrostOperationError(fmt.Sprintf("prediction %s was canceled", prediction.ID), nil, schemas.Replicate)
+	default:
+		return nil, nil, newProviderAPIError(fmt.Sprintf("Replicate error: prediction %s failed: %s", prediction.ID, replicateErrorMessage(prediction.Error)), nil, fasthttp.StatusInternalServerError, schemas.Replicate, nil, nil)
+	}
+}
+
+// createPrediction creates a prediction of an official model, "owner/name", or of a version of a
+// model, "owner/name:version".
+func (provider *ReplicateProvider) createPrediction(ctx context.Context, model string, key schemas.Key, requestBody map[string]interface{}) (*ReplicatePrediction, interface{}, *schemas.BifrostError) {
+	path := "/v1/models/" + model + "/predictions"
+	if _, version, ok := strings.Cut(model, ":"); ok {
+		path = "/v1/predictions"
+		requestBody = mergeConfig(requestBody, map[string]interface{}{"version": version})
+	}
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Replicate)
+	}
+
+	return provider.doRequest(ctx, key, "POST", provider.networkConfig.BaseURL+path, jsonBody)
+}
+
+// getPrediction returns the current state of a prediction.
+func (provider *ReplicateProvider) getPrediction(ctx context.Context, key schemas.Key, prediction *ReplicatePrediction) (*ReplicatePrediction, interface{}, *schemas.BifrostError) {
+	getURL := prediction.URLs.Get
+	if getURL == "" {
+		getURL = provider.networkConfig.BaseURL + "/v1/predictions/" + url.PathEscape(prediction.ID)
+	}
+	return provider.doRequest(ctx, key, "GET", getURL, nil)
+}
+
+// cancelPrediction cancels a prediction that is no longer waited for. It does not use the
+// context of the request, which is usually done by then.
+func (provider *ReplicateProvider) cancelPrediction(key schemas.Key, prediction *ReplicatePrediction) {
+	cancelURL := prediction.URLs.Cancel
+	if cancelURL == "" {
+		cancelURL = provider.networkConfig.BaseURL + "/v1/predictions/" + url.PathEscape(prediction.ID) + "/cancel"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), replicateCancelTimeout)
+	defer cancel()
+	if _, _, bifrostErr := provider.doRequest(ctx, key, "POST", cancelURL, nil); bifrostErr != nil {
+		provider.logger.Warn(fmt.Sprintf("failed to cancel replicate prediction %s: %s", prediction.ID, bifrostErr.Error.Message))
+	}
+}
+
+// doRequest sends a request to the predictions API and parses the returned prediction.
+func (provider *ReplicateProvider) doRequest(ctx context.Context, key schemas.Key, method string, requestURL string, body []byte) (*ReplicatePrediction, interface{}, *schemas.BifrostError) {
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(requestURL)
+	req.Header.SetMethod(method)
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+	if body != nil {
+		req.Header.SetContentType("application/json")
+		req.SetBody(body)
+	}
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK && resp.StatusCode() != fasthttp.StatusCreated && resp.StatusCode() != fasthttp.StatusAccepted {
+		provider.logger.Debug(fmt.Sprintf("error from replicate provider: %s", string(resp.Body())))
+
+		var errorResp ReplicateError
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		if errorResp.Detail != "" {
+			bifrostErr.Error.Message = "Replicate error: " + errorResp.Detail
+		}
+		return nil, nil, bifrostErr
+	}
+
+	prediction := &ReplicatePrediction{}
+	rawResponse, bifrostErr := handleProviderResponse(resp.Body(), prediction, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, nil, bifrostErr
+	}
+	return prediction, rawResponse, nil
+}
+
+// completionResponse converts a succeeded language model prediction.
+func (provider *ReplicateProvider) completionResponse(prediction *ReplicatePrediction, rawResponse interface{}, model string, object string, params *schemas.ModelParameters) *schemas.BifrostResponse {
+	bifrostResponse := &schemas.BifrostResponse{
+		ID:      prediction.ID,
+		Object:  object,
+		Model:   model,
+		Created: int(time.Now().Unix()),
+		Choices: []schemas.BifrostResponseChoice{
+			{
+				Index: 0,
+				BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
+					Message: schemas.BifrostMessage{
+						Role: schemas.ModelChatMessageRoleAssistant,
+						Content: schemas.MessageContent{
+							ContentStr: Ptr(replicateOutputText(prediction.Output)),
+						},
+					},
+				},
+				FinishReason: Ptr("stop"),
+			},
+		},
+		Usage: replicateUsage(prediction),
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Replicate,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse
+}
+
+// videoResponse converts a video prediction to a video job.
+func (provider *ReplicateProvider) videoResponse(prediction *ReplicatePrediction, rawResponse interface{}) *schemas.BifrostResponse {
+	video := &schemas.BifrostVideo{
+		JobID: prediction.ID,
+	}
+
+	switch prediction.Status {
+	case replicateStatusStarting:
+		video.Status = schemas.VideoJobQueued
+	case replicateStatusSucceeded:
+		video.Status = schemas.VideoJobCompleted
+	case replicateStatusFailed, replicateStatusCanceled:
+		video.Status = schemas.VideoJobFailed
+		reason := replicateErrorMessage(prediction.Error)
+		if prediction.Status == replicateStatusCanceled {
+			reason = "prediction was canceled"
+		}
+		video.FailureReason = &reason
+	default:
+		video.Status = schemas.VideoJobProcessing
+	}
+
+	if createdAt, err := time.Parse(time.RFC3339, prediction.CreatedAt); err == nil {
+		video.CreatedAt = Ptr(int(createdAt.Unix()))
+	}
+
+	if video.Status == schemas.VideoJobCompleted {
+		for _, videoURL := range replicateOutputURLs(prediction.Output) {
+			video.Videos = append(video.Videos, schemas.GeneratedVideo{
+				URL:      videoURL,
+				MimeType: "video/mp4",
+			})
+		}
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		ID:      prediction.ID,
+		Object:  "video.generation",
+		Model:   prediction.Model,
+		Created: int(time.Now().Unix()),
+		Video:   video,
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Replicate,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	return bifrostResponse
+}
+
+// replicatePredictionBody builds the body creating a prediction. ExtraParams go in the model input,
+// except the webhook options, which belong to the prediction.
+func replicatePredictionBody(input map[string]interface{}, params *schemas.ModelParameters, stream bool) map[string]interface{} {
+	requestBody := map[string]interface{}{}
+	if params != nil {
+		for name, value := range params.ExtraParams {
+			if replicatePredictionParams[name] {
+				requestBody[name] = value
+			} else {
+				input[name] = value
+			}
+		}
+	}
+	requestBody["input"] = input
+	if stream {
+		requestBody["stream"] = true
+	}
+	return requestBody
+}
+
+// replicateLanguageInput converts the sampling parameters to the inputs of Replicate language
+// models. Stop sequences are a comma separated list.
+func replicateLanguageInput(params *schemas.ModelParameters) map[string]interface{} {
+	input := map[string]interface{}{}
+	if params == nil {
+		return input
+	}
+	if params.MaxTokens != nil {
+		input["max_tokens"] = *params.MaxTokens
+	}
+	if params.Temperature != nil {
+		input["temperature"] = *params.Temperature
+	}
+	if params.TopP != nil {
+		input["top_p"] = *params.TopP
+	}
+	if params.TopK != nil {
+		input["top_k"] = *params.TopK
+	}
+	if params.PresencePenalty != nil {
+		input["presence_penalty"] = *params.PresencePenalty
+	}
+	if params.FrequencyPenalty != nil {
+		input["frequency_penalty"] = *params.FrequencyPenalty
+	}
+	if params.StopSequences != nil && len(*params.StopSequences) > 0 {
+		input["stop_sequences"] = strings.Join(*params.StopSequences, ",")
+	}
+	return input
+}
+
+// replicateChatInput converts a conversation to the inputs of a Replicate language model.
+// Instructions become the system_prompt. A single user message is the prompt as is, longer
+// conversations are a transcript of "User:" and "Assistant:" turns ending with "Assistant:".
+func replicateChatInput(messages []schemas.BifrostMessage, params *schemas.ModelParameters) (map[string]interface{}, *schemas.BifrostError) {
+	if params != nil && params.Tools != nil && len(*params.Tools) > 0 {
+		return nil, newConfigurationError("replicate models do not support tool calls", schemas.Replicate)
+	}
+
+	var system []string
+	var turns []schemas.BifrostMessage
+	for _, message := range messages {
+		if message.Content.ContentBlocks != nil {
+			for _, block := range *message.Content.ContentBlocks {
+				if block.Type != schemas.ContentBlockTypeText {
+					return nil, newConfigurationError("replicate chat completion only supports text content", schemas.Replicate)
+				}
+			}
+		}
+		if message.Role.IsInstruction() {
+			system = append(system, messageTexts(message.Content)...)
+			continue
+		}
+		turns = append(turns, message)
+	}
+
+	input := replicateLanguageInput(params)
+	if len(system) > 0 {
+		input["system_prompt"] = strings.Join(system, "\n\n")
+	}
+
+	if len(turns) == 1 && turns[0].Role == schemas.ModelChatMessageRoleUser {
+		input["prompt"] = strings.Join(messageTexts(turns[0].Content), "\n\n")
+		return input, nil
+	}
+
+	var transcript strings.Builder
+	for _, turn := range turns {
+		label := "User"
+		switch turn.Role {
+		case schemas.ModelChatMessageRoleAssistant:
+			label = "Assistant"
+		case schemas.ModelChatMessageRoleTool:
+			label = "Tool"
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n\n", label, strings.Join(messageTexts(turn.Content), "\n\n"))
+	}
+	transcript.WriteString("Assistant:")
+	input["prompt"] = transcript.String()
+	return input, nil
+}
+
+// replicateEnded reports whether a prediction status is final.
+func replicateEnded(status string) bool {
+	return status == replicateStatusSucceeded || status == replicateStatusFailed || status == replicateStatusCanceled
+}
+
+// replicateOutputText returns the text of a language model output, a list of tokens or a string.
+func replicateOutputText(output interface{}) string {
+	switch output := output.(type) {
+	case nil:
+		return ""
+	case string:
+		return output
+	case []interface{}:
+		var text strings.Builder
+		for _, part := range output {
+			if s, ok := part.(string); ok {
+				text.WriteString(s)
+			}
+		}
+		return text.String()
+	default:
+		encoded, err := sonic.MarshalString(output)
+		if err != nil {
+			return fmt.Sprintf("%v", output)
+		}
+		return encoded
+	}
+}
+
+// replicateOutputURLs returns the file URLs of an output, a URL or a list of URLs.
+func replicateOutputURLs(output interface{}) []string {
+	switch output := output.(type) {
+	case string:
+		if output == "" {
+			return nil
+		}
+		return []string{output}
+	case []interface{}:
+		var urls []string
+		for _, item := range output {
+			if s, ok := item.(string); ok && s != "" {
+				urls = append(urls, s)
+			}
+		}
+		return urls
+	}
+	return nil
+}
+
+// replicateUsage returns the token usage reported in the metrics of a prediction, if any.
+func replicateUsage(prediction *ReplicatePrediction) *schemas.LLMUsage {
+	if prediction.Metrics == nil || (prediction.Metrics.InputTokenCount == nil && prediction.Metrics.OutputTokenCount == nil) {
+		return nil
+	}
+	usage := &schemas.LLMUsage{}
+	if prediction.Metrics.InputTokenCount != nil {
+		usage.PromptTokens = *prediction.Metrics.InputTokenCount
+	}
+	if prediction.Metrics.OutputTokenCount != nil {
+		usage.CompletionTokens = *prediction.Metrics.OutputTokenCount
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return usage
+}
+
+// replicateErrorMessage returns the message of a prediction error, a string or an object.
+func replicateErrorMessage(predictionError interface{}) string {
+	switch predictionError := predictionError.(type) {
+	case nil:
+		return "unknown error"
+	case string:
+		return predictionError
+	default:
+		return fmt.Sprintf("%v", predictionError)
+	}
+}
@@ -0,0 +1,315 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the SambaNova provider implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// SambaNovaUsage is the usage object of SambaNova responses, which adds the latency of the
+// request in seconds to the OpenAI token counts.
+type SambaNovaUsage struct {
+	PromptTokens                     int     `json:"prompt_tokens"`
+	CompletionTokens                 int     `json:"completion_tokens"`
+	TotalTokens                      int     `json:"total_tokens"`
+	TimeToFirstToken                 float64 `json:"time_to_first_token"`
+	TotalLatency                     float64 `json:"total_latency"`
+	CompletionTokensPerSec           float64 `json:"completion_tokens_per_sec"`
+	CompletionTokensAfterFirstPerSec float64 `json:"completion_tokens_after_first_per_sec"`
+	IsLastResponse                   bool    `json:"is_last_response"`
+}
+
+// SambaNovaResponseFields are the fields of a SambaNova completion the OpenAI format has no place for.
+type SambaNovaResponseFields struct {
+	Usage *SambaNovaUsage `json:"usage,omitempty"`
+}
+
+// SambaNovaProvider implements the Provider interface for SambaNova Cloud's OpenAI-compatible API.
+type SambaNovaProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	streamClient        *http.Client          // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+}
+
+// NewSambaNovaProvider creates a new SambaNova provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewSambaNovaProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*SambaNovaProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://api.sambanova.ai"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &SambaNovaProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for SambaNova.
+func (provider *SambaNovaProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.SambaNova
+}
+
+// WarmUp opens connections to the SambaNova API ahead of the first request.
+func (provider *SambaNovaProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the SambaNova provider.
+func (provider *SambaNovaProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "sambanova")
+}
+
+// ChatCompletion performs a chat completion request to the SambaNova API.
+// It formats the request, sends it to SambaNova, and processes the response.
+// Returns a BifrostResponse containing the completion results or an error if the request fails.
+func (provider *SambaNovaProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+	}, preparedParams)
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.SambaNova)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/v1/chat/completions")
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from sambanova provider: %s", string(resp.Body())))
+
+		var errorResp map[string]interface{}
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		bifrostErr.Error.Message = fmt.Sprintf("SambaNova error: %v", errorResp)
+		return nil, bifrostErr
+	}
+
+	responseBody := resp.Body()
+
+	response, bifrostErr := parseReasoningChatResponse(responseBody, schemas.SambaNova, params, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var fields SambaNovaResponseFields
+	if err := sonic.Unmarshal(responseBody, &fields); err != nil {
+		provider.logger.Warn(fmt.Sprintf("Failed to parse sambanova usage: %v", err))
+	} else if fields.Usage != nil {
+		response.ExtraFields.ProviderTiming = sambaNovaTiming(fields.Usage)
+	}
+
+	return response, nil
+}
+
+// Embedding is not supported by the SambaNova provider.
+func (provider *SambaNovaProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("embedding", "sambanova")
+}
+
+// ChatCompletionStream performs a streaming chat completion request to the SambaNova API.
+// It supports real-time streaming of responses using Server-Sent Events (SSE).
+// Uses SambaNova's OpenAI-compatible streaming format.
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+//
+// Unlike OpenAI, SambaNova does not send its usage in a usage-only chunk after the finish
+// reason: the usage can come in the chunk carrying the finish reason, in a chunk before it, or
+// in a chunk whose delta has an empty content, depending on the model. The empty deltas are
+// dropped so they do not reach the client as content chunks, and the latency figures are taken
+// from the usage of the last chunk that reports them, whichever chunk that is.
+func (provider *SambaNovaProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+		"stream":   true,
+		"stream_options": map[string]interface{}{
+			"include_usage": true,
+		},
+	}, preparedParams)
+
+	// Prepare SambaNova headers
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + key.Value,
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+
+	var usage *SambaNovaUsage
+	chunkHook := func(rawChunk map[string]interface{}, response *schemas.BifrostResponse) {
+		if response.Usage == nil {
+			return
+		}
+		for i := range response.Choices {
+			if streamChoice := response.Choices[i].BifrostStreamResponseChoice; streamChoice != nil && streamChoice.Delta.Content != nil && *streamChoice.Delta.Content == "" {
+				streamChoice.Delta.Content = nil
+			}
+		}
+		chunkUsage, err := parseSambaNovaUsage(rawChunk)
+		if err != nil {
+			provider.logger.Warn(fmt.Sprintf("Failed to parse sambanova usage: %v", err))
+			return
+		}
+		// Chunks before the last response may carry partial figures
+		if chunkUsage != nil && (usage == nil || !usage.IsLastResponse) {
+			usage = chunkUsage
+		}
+	}
+	endHook := func(response *schemas.BifrostResponse) {
+		if usage != nil {
+			response.ExtraFields.ProviderTiming = sambaNovaTiming(usage)
+		}
+	}
+
+	// Use shared OpenAI-compatible streaming logic
+	return handleOpenAIStreamingWithHook(
+		ctx,
+		provider.streamClient,
+		provider.networkConfig.BaseURL+"/v1/chat/completions",
+		requestBody,
+		headers,
+		provider.networkConfig.ExtraHeaders,
+		schemas.SambaNova,
+		params,
+		postHookRunner,
+		provider.logger,
+		chunkHook,
+		endHook,
+	)
+}
+
+func (provider *SambaNovaProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "sambanova")
+}
+
+func (provider *SambaNovaProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "sambanova")
+}
+
+func (provider *SambaNovaProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "sambanova")
+}
+
+func (provider *SambaNovaProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "sambanova")
+}
+
+func (provider *SambaNovaProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "sambanova")
+}
+
+func (provider *SambaNovaProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "sambanova")
+}
+
+func (provider *SambaNovaProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "sambanova")
+}
+
+func (provider *SambaNovaProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "sambanova")
+}
+
+func (provider *SambaNovaProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "sambanova")
+}
+
+func (provider *SambaNovaProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "sambanova")
+}
+
+// parseSambaNovaUsage extracts the usage object from a raw stream chunk, nil if it has none.
+func parseSambaNovaUsage(rawChunk map[string]interface{}) (*SambaNovaUsage, error) {
+	value, ok := rawChunk["usage"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var usage SambaNovaUsage
+	if err := sonic.Unmarshal(data, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// sambaNovaTiming converts the latency figures of a SambaNova usage object, nil if it reports none.
+func sambaNovaTiming(usage *SambaNovaUsage) *schemas.ProviderTiming {
+	if usage.TotalLatency == 0 && usage.TimeToFirstToken == 0 {
+		return nil
+	}
+	timing := &schemas.ProviderTiming{
+		PromptTime: usage.TimeToFirstToken,
+		TotalTime:  usage.TotalLatency,
+	}
+	if usage.TotalLatency > usage.TimeToFirstToken {
+		timing.CompletionTime = usage.TotalLatency - usage.TimeToFirstToken
+	}
+	timing.OutputTokensPerSecond = usage.CompletionTokensAfterFirstPerSec
+	if timing.OutputTokensPerSecond == 0 {
+		timing.OutputTokensPerSecond = usage.CompletionTokensPerSec
+	}
+	return timing
+}
@@ -0,0 +1,309 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the Stability AI provider implementation.
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// stabilityAspectRatios are the aspect ratios accepted by the Stable Image generate endpoints.
+var stabilityAspectRatios = []string{"21:9", "16:9", "3:2", "5:4", "1:1", "4:5", "2:3", "9:16", "9:21"}
+
+// StabilityImageResponse represents the JSON response of the Stable Image generate endpoints.
+type StabilityImageResponse struct {
+	Image        string `json:"image"` // Base64 encoded image
+	FinishReason string `json:"finish_reason"`
+	Seed         *int   `json:"seed,omitempty"`
+}
+
+// StabilityError represents an error response from the Stability AI API.
+type StabilityError struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Errors []string `json:"errors"`
+}
+
+// StabilityProvider implements the Provider interface for Stability AI's Stable Image API.
+// It only supports image generation; every call returns a single image, so requests for
+// several images are sent as parallel calls with consecutive seeds.
+type StabilityProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+}
+
+// NewStabilityProvider creates a new Stability AI provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewStabilityProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*StabilityProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://api.stability.ai"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &StabilityProvider{
+		logger:              logger,
+		client:              client,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Stability AI.
+func (provider *StabilityProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Stability
+}
+
+// WarmUp opens connections to the Stability API ahead of the first request.
+func (provider *StabilityProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, nil, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the Stability AI provider.
+func (provider *StabilityProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "stability")
+}
+
+// ChatCompletion is not supported by the Stability AI provider.
+func (provider *StabilityProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("chat completion", "stability")
+}
+
+// ChatCompletionStream is not supported by the Stability AI provider.
+func (provider *StabilityProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("chat completion stream", "stability")
+}
+
+// Embedding is not supported by the Stability AI provider.
+func (provider *StabilityProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("embedding", "stability")
+}
+
+func (provider *StabilityProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "stability")
+}
+
+func (provider *StabilityProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "stability")
+}
+
+func (provider *StabilityProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "stability")
+}
+
+func (provider *StabilityProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "stability")
+}
+
+func (provider *StabilityProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "stability")
+}
+
+func (provider *StabilityProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "stability")
+}
+
+func (provider *StabilityProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "stability")
+}
+
+func (provider *StabilityProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "stability")
+}
+
+func (provider *StabilityProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "stability")
+}
+
+// ImageGeneration generates images with the Stable Image Ultra, Core or SD3 endpoints.
+// The model "ultra" or "core" selects those services, any other model (e.g. "sd3.5-large")
+// is sent to the SD3 endpoint. Options such as style_preset and cfg_scale are passed through
+// ModelParameters.ExtraParams. Images are always returned inline.
+func (provider *StabilityProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	n, err := imageCount(input)
+	if err != nil {
+		return nil, newConfigurationError(err.Error(), schemas.Stability)
+	}
+	aspectRatio, err := imageAspectRatio(input, stabilityAspectRatios)
+	if err != nil {
+		return nil, newConfigurationError(err.Error(), schemas.Stability)
+	}
+
+	path, sd3Model := stabilityEndpoint(model)
+	fields := map[string]string{
+		"prompt": input.Prompt,
+	}
+	if sd3Model != "" {
+		fields["model"] = sd3Model
+	}
+	if input.NegativePrompt != nil {
+		fields["negative_prompt"] = *input.NegativePrompt
+	}
+	if aspectRatio != "" {
+		fields["aspect_ratio"] = aspectRatio
+	}
+	outputFormat := "png"
+	if input.OutputFormat != nil {
+		outputFormat = *input.OutputFormat
+	}
+	fields["output_format"] = outputFormat
+	if params != nil {
+		for name, value := range params.ExtraParams {
+			fields[name] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	rawResponses := make([]interface{}, n)
+	images, bifrostErr := generateImages(ctx, n, func(ctx context.Context, index int) (*schemas.GeneratedImage, *schemas.BifrostError) {
+		imageFields := fields
+		if input.Seed != nil {
+			// Consecutive seeds keep the images of one request distinct yet reproducible
+			imageFields = make(map[string]string, len(fields)+1)
+			for name, value := range fields {
+				imageFields[name] = value
+			}
+			imageFields["seed"] = strconv.Itoa(*input.Seed + index)
+		}
+
+		response, rawResponse, bifrostErr := provider.generateImage(ctx, path, key, imageFields)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+		rawResponses[index] = rawResponse
+		return &schemas.GeneratedImage{
+			B64JSON:  response.Image,
+			MimeType: imageMimeType(outputFormat),
+			Seed:     response.Seed,
+		}, nil
+	})
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		Object:  "image.generation",
+		Model:   model,
+		Created: int(time.Now().Unix()),
+		Image: &schemas.BifrostImage{
+			Images: images,
+			Usage:  &schemas.ImageUsage{Images: len(images)},
+		},
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Stability,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponses
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// generateImage sends a single generate request as a multipart form.
+func (provider *StabilityProvider) generateImage(ctx context.Context, path string, key schemas.Key, fields map[string]string) (*StabilityImageResponse, interface{}, *schemas.BifrostError) {
+	// Create multipart form
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, nil, newBifrostOperationError(fmt.Sprintf("failed to write %s field", name), err, schemas.Stability)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, newBifrostOperationError("failed to close multipart writer", err, schemas.Stability)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + path)
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType(writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+	req.Header.Set("Accept", "application/json") // Base64 JSON instead of image bytes
+
+	req.SetBody(body.Bytes())
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from stability provider: %s", string(resp.Body())))
+
+		var errorResp StabilityError
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		if len(errorResp.Errors) > 0 {
+			bifrostErr.Error.Message = "Stability error: " + strings.Join(errorResp.Errors, "; ")
+		} else {
+			bifrostErr.Error.Message = fmt.Sprintf("Stability error: %s", errorResp.Name)
+		}
+		if errorResp.Name != "" {
+			bifrostErr.Error.Type = Ptr(errorResp.Name)
+		}
+		return nil, nil, bifrostErr
+	}
+
+	response := &StabilityImageResponse{}
+	rawResponse, bifrostErr := handleProviderResponse(resp.Body(), response, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, nil, bifrostErr
+	}
+
+	if response.FinishReason == "CONTENT_FILTERED" {
+		return nil, nil, newProviderAPIError("Stability error: image blocked by the content filter", nil, fasthttp.StatusBadRequest, schemas.Stability, Ptr("content_filter"), nil)
+	}
+
+	return response, rawResponse, nil
+}
+
+// stabilityEndpoint returns the generate endpoint of a model, and the model to send for the SD3 endpoint.
+func stabilityEndpoint(model string) (string, string) {
+	switch strings.TrimPrefix(model, "stable-image-") {
+	case "ultra":
+		return "/v2beta/stable-image/generate/ultra", ""
+	case "core":
+		return "/v2beta/stable-image/generate/core", ""
+	default:
+		return "/v2beta/stable-image/generate/sd3", model
+	}
+}
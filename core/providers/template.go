@@ -0,0 +1,474 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the template provider, which drives backends described purely by configuration.
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// templateOperation is a templated operation with its parsed body template.
+type templateOperation struct {
+	config schemas.TemplateOperationConfig
+	body   *template.Template
+}
+
+// templateRequestData is the data a body template is executed with.
+type templateRequestData struct {
+	Model    string
+	Messages []schemas.BifrostMessage
+	Prompt   string
+	Texts    []string
+	Params   map[string]interface{}
+}
+
+// templateFuncs are the functions available in body templates.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		return sonic.MarshalString(v)
+	},
+	"default": func(fallback, v interface{}) interface{} {
+		if v == nil {
+			return fallback
+		}
+		return v
+	},
+	"messageText": func(message schemas.BifrostMessage) string {
+		return message.Content.Text()
+	},
+	"lastUserMessage": templateLastUserMessage,
+}
+
+// TemplateProvider implements the Provider interface for backends whose request body and
+// response shape are described by CustomProviderConfig.RequestTemplates.
+type TemplateProvider struct {
+	logger               schemas.Logger                           // Logger for provider operations
+	client               *fasthttp.Client                         // HTTP client for API requests
+	networkConfig        schemas.NetworkConfig                    // Network configuration including extra headers
+	sendBackRawResponse  bool                                     // Whether to include raw response in BifrostResponse
+	customProviderConfig *schemas.CustomProviderConfig            // Custom provider config
+	operations           map[schemas.Operation]*templateOperation // Configured operations with parsed templates
+}
+
+// NewTemplateProvider creates a new template provider instance.
+// All body templates and response paths are validated upfront so that misconfigurations
+// surface when the provider is created rather than on the first request.
+func NewTemplateProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*TemplateProvider, error) {
+	config.CheckAndSetDefaults()
+
+	if config.CustomProviderConfig == nil || config.CustomProviderConfig.RequestTemplates == nil {
+		return nil, fmt.Errorf("request_templates are required for template providers")
+	}
+
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+	if config.NetworkConfig.BaseURL == "" {
+		return nil, fmt.Errorf("base_url is required for template providers")
+	}
+
+	templates := config.CustomProviderConfig.RequestTemplates
+	operations := make(map[schemas.Operation]*templateOperation)
+	for operation, operationConfig := range map[schemas.Operation]*schemas.TemplateOperationConfig{
+		schemas.OperationTextCompletion: templates.TextCompletion,
+		schemas.OperationChatCompletion: templates.ChatCompletion,
+		schemas.OperationEmbedding:      templates.Embedding,
+	} {
+		if operationConfig == nil {
+			continue
+		}
+		parsed, err := parseTemplateOperation(string(operation), *operationConfig)
+		if err != nil {
+			return nil, err
+		}
+		operations[operation] = parsed
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("request_templates must configure at least one operation")
+	}
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+
+	return &TemplateProvider{
+		logger:               logger,
+		client:               client,
+		networkConfig:        config.NetworkConfig,
+		sendBackRawResponse:  config.SendBackRawResponse,
+		customProviderConfig: config.CustomProviderConfig,
+		operations:           operations,
+	}, nil
+}
+
+// parseTemplateOperation parses the body template and validates the response paths of an operation.
+func parseTemplateOperation(name string, config schemas.TemplateOperationConfig) (*templateOperation, error) {
+	if strings.TrimSpace(config.Body) == "" {
+		return nil, fmt.Errorf("%s template body is required", name)
+	}
+	body, err := template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(config.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s body template: %w", name, err)
+	}
+
+	mapping := config.Response
+	for _, path := range []string{mapping.ID, mapping.Content, mapping.FinishReason, mapping.PromptTokens, mapping.CompletionTokens, mapping.TotalTokens, mapping.Embeddings} {
+		if path == "" {
+			continue
+		}
+		if _, err := parseJSONPath(path); err != nil {
+			return nil, fmt.Errorf("invalid %s response mapping: %w", name, err)
+		}
+	}
+
+	return &templateOperation{config: config, body: body}, nil
+}
+
+// GetProviderKey returns the provider identifier, which is always the custom provider name.
+func (provider *TemplateProvider) GetProviderKey() schemas.ModelProvider {
+	return getProviderName(schemas.Template, provider.customProviderConfig)
+}
+
+// WarmUp opens connections to the templated backend ahead of the first request.
+func (provider *TemplateProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, nil, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion performs a templated text completion request.
+func (provider *TemplateProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	response, bifrostErr := provider.execute(ctx, schemas.OperationTextCompletion, key, templateRequestData{
+		Model:  model,
+		Prompt: text,
+		Params: prepareParams(params),
+	}, params)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+	response.Object = "text_completion"
+	return response, nil
+}
+
+// ChatCompletion performs a templated chat completion request.
+func (provider *TemplateProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	var prompt strings.Builder
+	for _, message := range messages {
+		fmt.Fprintf(&prompt, "%s: %s\n", message.Role, message.Content.Text())
+	}
+
+	response, bifrostErr := provider.execute(ctx, schemas.OperationChatCompletion, key, templateRequestData{
+		Model:    model,
+		Messages: messages,
+		Prompt:   prompt.String(),
+		Params:   prepareParams(params),
+	}, params)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+	response.Object = "chat.completion"
+	return response, nil
+}
+
+// Embedding performs a templated embedding request.
+func (provider *TemplateProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	var texts []string
+	if input != nil {
+		if input.Text != nil {
+			texts = append(texts, *input.Text)
+		}
+		texts = append(texts, input.Texts...)
+	}
+
+	response, bifrostErr := provider.execute(ctx, schemas.OperationEmbedding, key, templateRequestData{
+		Model:  model,
+		Texts:  texts,
+		Params: prepareParams(params),
+	}, params)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+	response.Object = "list"
+	return response, nil
+}
+
+// ChatCompletionStream is not supported by the template provider.
+func (provider *TemplateProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("chat completion stream", string(provider.GetProviderKey()))
+}
+
+func (provider *TemplateProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", string(provider.GetProviderKey()))
+}
+
+func (provider *TemplateProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", string(provider.GetProviderKey()))
+}
+
+func (provider *TemplateProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", string(provider.GetProviderKey()))
+}
+
+func (provider *TemplateProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", string(provider.GetProviderKey()))
+}
+
+func (provider *TemplateProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", string(provider.GetProviderKey()))
+}
+
+func (provider *TemplateProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", string(provider.GetProviderKey()))
+}
+
+func (provider *TemplateProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", string(provider.GetProviderKey()))
+}
+
+func (provider *TemplateProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", string(provider.GetProviderKey()))
+}
+
+func (provider *TemplateProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", string(provider.GetProviderKey()))
+}
+
+func (provider *TemplateProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", string(provider.GetProviderKey()))
+}
+
+// execute renders the request body of an operation, sends it and maps the response back.
+func (provider *TemplateProvider) execute(ctx context.Context, operation schemas.Operation, key schemas.Key, data templateRequestData, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	providerName := provider.GetProviderKey()
+
+	if err := checkOperationAllowed(schemas.Template, provider.customProviderConfig, operation); err != nil {
+		return nil, err
+	}
+	op, ok := provider.operations[operation]
+	if !ok {
+		return nil, newUnsupportedOperationError(string(operation), string(providerName))
+	}
+
+	var body bytes.Buffer
+	if err := op.body.Execute(&body, data); err != nil {
+		return nil, newBifrostOperationError("failed to render request body template", err, providerName)
+	}
+	if !sonic.Valid(body.Bytes()) {
+		return nil, newConfigurationError(fmt.Sprintf("%s body template did not render valid JSON: %s", operation, body.String()), providerName)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+	for name, value := range op.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	method := op.config.Method
+	if method == "" {
+		method = fasthttp.MethodPost
+	}
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + op.config.Path)
+	req.Header.SetMethod(method)
+	req.Header.SetContentType("application/json")
+	if key.Value != "" {
+		req.Header.Set("Authorization", "Bearer "+key.Value)
+	}
+
+	req.SetBody(body.Bytes())
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
+		provider.logger.Debug(fmt.Sprintf("error from %s provider: %s", providerName, string(resp.Body())))
+
+		var errorResp map[string]interface{}
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		bifrostErr.Error.Message = fmt.Sprintf("%s error: %v", providerName, errorResp)
+		return nil, bifrostErr
+	}
+
+	var document interface{}
+	if err := sonic.Unmarshal(resp.Body(), &document); err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderResponseUnmarshal, err, providerName)
+	}
+
+	response, err := mapTemplateResponse(document, op.config.Response, operation)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderDecodeStructured, err, providerName)
+	}
+
+	response.Model = data.Model
+	response.ExtraFields.Provider = providerName
+
+	if provider.sendBackRawResponse {
+		response.ExtraFields.RawResponse = document
+	}
+
+	if params != nil {
+		response.ExtraFields.Params = *params
+	}
+
+	return response, nil
+}
+
+// mapTemplateResponse builds a BifrostResponse from a decoded response document.
+func mapTemplateResponse(document interface{}, mapping schemas.TemplateResponseMapping, operation schemas.Operation) (*schemas.BifrostResponse, error) {
+	response := &schemas.BifrostResponse{}
+
+	id, err := templateStringAt(document, mapping.ID)
+	if err != nil {
+		return nil, err
+	}
+	if id != nil {
+		response.ID = *id
+	}
+
+	usage := &schemas.LLMUsage{}
+	hasUsage := false
+	for path, target := range map[string]*int{
+		mapping.PromptTokens:     &usage.PromptTokens,
+		mapping.CompletionTokens: &usage.CompletionTokens,
+		mapping.TotalTokens:      &usage.TotalTokens,
+	} {
+		if path == "" {
+			continue
+		}
+		value, ok, err := evaluateJSONPath(document, path)
+		if err != nil {
+			return nil, err
+		}
+		if n, isNumber := value.(float64); ok && isNumber {
+			*target = int(n)
+			hasUsage = true
+		}
+	}
+	if hasUsage {
+		if usage.TotalTokens == 0 {
+			usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+		}
+		response.Usage = usage
+	}
+
+	if operation == schemas.OperationEmbedding {
+		if mapping.Embeddings == "" {
+			return nil, fmt.Errorf("embeddings response mapping is required")
+		}
+		value, ok, err := evaluateJSONPath(document, mapping.Embeddings)
+		if err != nil {
+			return nil, err
+		}
+		vectors, isList := value.([]interface{})
+		if !ok || !isList {
+			return nil, fmt.Errorf("embeddings path %s did not resolve to a list", mapping.Embeddings)
+		}
+		for i, vector := range vectors {
+			values, isList := vector.([]interface{})
+			if !isList {
+				return nil, fmt.Errorf("embedding %d is not an array", i)
+			}
+			embedding := make([]float32, len(values))
+			for j, v := range values {
+				n, isNumber := v.(float64)
+				if !isNumber {
+					return nil, fmt.Errorf("embedding %d has a non numeric value at %d", i, j)
+				}
+				embedding[j] = float32(n)
+			}
+			response.Data = append(response.Data, schemas.BifrostEmbedding{
+				Index:     i,
+				Object:    "embedding",
+				Embedding: schemas.BifrostEmbeddingResponse{EmbeddingArray: &embedding},
+			})
+		}
+		return response, nil
+	}
+
+	if mapping.Content == "" {
+		return nil, fmt.Errorf("content response mapping is required")
+	}
+	content, err := templateStringAt(document, mapping.Content)
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		return nil, fmt.Errorf("content path %s did not resolve to a string", mapping.Content)
+	}
+	finishReason, err := templateStringAt(document, mapping.FinishReason)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Choices = []schemas.BifrostResponseChoice{
+		{
+			Index:        0,
+			FinishReason: finishReason,
+			BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
+				Message: schemas.BifrostMessage{
+					Role:    schemas.ModelChatMessageRoleAssistant,
+					Content: schemas.MessageContent{ContentStr: content},
+				},
+			},
+		},
+	}
+
+	return response, nil
+}
+
+// templateStringAt returns the string at path, or nil if the path is empty or does not match a string.
+// Wildcard matches are concatenated, which is useful for backends returning content in parts.
+func templateStringAt(document interface{}, path string) (*string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	value, ok, err := evaluateJSONPath(document, path)
+	if err != nil || !ok {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case string:
+		return &v, nil
+	case []interface{}:
+		var joined strings.Builder
+		for _, part := range v {
+			if s, isString := part.(string); isString {
+				joined.WriteString(s)
+			}
+		}
+		return Ptr(joined.String()), nil
+	}
+	return nil, nil
+}
+
+// templateLastUserMessage returns the text of the last user message.
+func templateLastUserMessage(messages []schemas.BifrostMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == schemas.ModelChatMessageRoleUser {
+			return messages[i].Content.Text()
+		}
+	}
+	return ""
+}
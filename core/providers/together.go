@@ -0,0 +1,481 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the Together AI provider implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// togetherModelListTTL is how long the model list of a key is trusted before it is fetched again.
+const togetherModelListTTL = 10 * time.Minute
+
+// togetherModelTypes are the model types of Together's model list each operation accepts.
+var togetherModelTypes = map[schemas.Operation][]string{
+	schemas.OperationChatCompletion:  {"chat", "language", "code"},
+	schemas.OperationEmbedding:       {"embedding"},
+	schemas.OperationImageGeneration: {"image"},
+}
+
+// TogetherModel is an entry of Together's model list.
+type TogetherModel struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"` // chat, language, code, embedding, image, rerank, moderation, audio
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+// TogetherImageResponse is the response of Together's image generation endpoint.
+type TogetherImageResponse struct {
+	ID    string `json:"id"`
+	Model string `json:"model"`
+	Data  []struct {
+		Index   int    `json:"index"`
+		B64JSON string `json:"b64_json,omitempty"`
+		URL     string `json:"url,omitempty"`
+	} `json:"data"`
+}
+
+// togetherModelList is the model list of a key, keyed by model ID.
+type togetherModelList struct {
+	models    map[string]string // Model ID -> type
+	fetchedAt time.Time
+}
+
+// TogetherProvider implements the Provider interface for Together AI's API.
+type TogetherProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	streamClient        *http.Client          // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+
+	modelsMu sync.Mutex
+	models   map[string]*togetherModelList // Key value -> model list of the key
+}
+
+// NewTogetherProvider creates a new Together AI provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewTogetherProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*TogetherProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://api.together.xyz"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &TogetherProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+		models:              make(map[string]*togetherModelList),
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Together AI.
+func (provider *TogetherProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Together
+}
+
+// WarmUp opens connections to the Together API ahead of the first request.
+func (provider *TogetherProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the Together AI provider.
+func (provider *TogetherProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "together")
+}
+
+// ChatCompletion performs a chat completion request to the Together AI API.
+func (provider *TogetherProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if bifrostErr := provider.validateModel(ctx, model, key, schemas.OperationChatCompletion); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+	}, preparedParams)
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Together)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/v1/chat/completions")
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from together provider: %s", string(resp.Body())))
+
+		var errorResp map[string]interface{}
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		bifrostErr.Error.Message = fmt.Sprintf("Together error: %v", errorResp)
+		return nil, bifrostErr
+	}
+
+	return parseReasoningChatResponse(resp.Body(), schemas.Together, params, provider.sendBackRawResponse)
+}
+
+// Embedding generates embeddings for the given input text(s) using Together's OpenAI compatible
+// embeddings endpoint.
+func (provider *TogetherProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if bifrostErr := provider.validateModel(ctx, model, key, schemas.OperationEmbedding); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	requestBody := prepareOpenAIEmbeddingRequest(input, params)
+	requestBody["model"] = model
+
+	return handleOpenAIEmbeddingRequest(
+		ctx,
+		provider.client,
+		provider.networkConfig.BaseURL+"/v1/embeddings",
+		requestBody,
+		key,
+		params,
+		provider.networkConfig.ExtraHeaders,
+		schemas.Together,
+		provider.sendBackRawResponse,
+		provider.logger,
+	)
+}
+
+// ChatCompletionStream performs a streaming chat completion request to the Together AI API.
+// It supports real-time streaming of responses using Server-Sent Events (SSE).
+// Uses Together's OpenAI-compatible streaming format.
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+func (provider *TogetherProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	if bifrostErr := provider.validateModel(ctx, model, key, schemas.OperationChatCompletion); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+		"stream":   true,
+		"stream_options": map[string]interface{}{
+			"include_usage": true,
+		},
+	}, preparedParams)
+
+	// Prepare Together headers
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + key.Value,
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+
+	// Use shared OpenAI-compatible streaming logic
+	return handleOpenAIStreaming(
+		ctx,
+		provider.streamClient,
+		provider.networkConfig.BaseURL+"/v1/chat/completions",
+		requestBody,
+		headers,
+		provider.networkConfig.ExtraHeaders,
+		schemas.Together,
+		params,
+		postHookRunner,
+		provider.logger,
+	)
+}
+
+func (provider *TogetherProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "together")
+}
+
+func (provider *TogetherProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "together")
+}
+
+func (provider *TogetherProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "together")
+}
+
+func (provider *TogetherProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "together")
+}
+
+func (provider *TogetherProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "together")
+}
+
+// ImageGeneration generates images with Together's image models, such as FLUX. Together returns
+// every requested image in one response, sized by width and height.
+func (provider *TogetherProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	n, err := imageCount(input)
+	if err != nil {
+		return nil, newConfigurationError(err.Error(), schemas.Together)
+	}
+	if bifrostErr := provider.validateModel(ctx, model, key, schemas.OperationImageGeneration); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	requestBody := map[string]interface{}{
+		"model":  model,
+		"prompt": input.Prompt,
+		"n":      n,
+	}
+	if input.Size != nil && *input.Size != "" {
+		width, height, err := parseImageSize(*input.Size)
+		if err != nil {
+			return nil, newConfigurationError(err.Error(), schemas.Together)
+		}
+		requestBody["width"] = width
+		requestBody["height"] = height
+	}
+	if input.NegativePrompt != nil {
+		requestBody["negative_prompt"] = *input.NegativePrompt
+	}
+	if input.Seed != nil {
+		requestBody["seed"] = *input.Seed
+	}
+	outputFormat := "png"
+	if input.OutputFormat != nil {
+		outputFormat = *input.OutputFormat
+	}
+	if outputFormat == "jpg" {
+		outputFormat = "jpeg"
+	}
+	requestBody["output_format"] = outputFormat
+	if wantsImageURL(input) {
+		requestBody["response_format"] = "url"
+	} else {
+		requestBody["response_format"] = "base64"
+	}
+	if params != nil {
+		requestBody = mergeConfig(requestBody, params.ExtraParams)
+	}
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Together)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/v1/images/generations")
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from together provider: %s", string(resp.Body())))
+
+		var errorResp map[string]interface{}
+		bifrostErr := handleProviderAPIError(resp, &errorResp)
+		bifrostErr.Error.Message = fmt.Sprintf("Together error: %v", errorResp)
+		return nil, bifrostErr
+	}
+
+	var imageResponse TogetherImageResponse
+	rawResponse, bifrostErr := handleProviderResponse(resp.Body(), &imageResponse, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	images := make([]schemas.GeneratedImage, 0, len(imageResponse.Data))
+	for _, data := range imageResponse.Data {
+		image := schemas.GeneratedImage{
+			B64JSON:  data.B64JSON,
+			URL:      data.URL,
+			MimeType: imageMimeType(outputFormat),
+		}
+		if input.Seed != nil {
+			image.Seed = Ptr(*input.Seed + data.Index)
+		}
+		images = append(images, image)
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		ID:      imageResponse.ID,
+		Object:  "image.generation",
+		Model:   model,
+		Created: int(time.Now().Unix()),
+		Image: &schemas.BifrostImage{
+			Images: images,
+			Usage:  &schemas.ImageUsage{Images: len(images)},
+		},
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Together,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+func (provider *TogetherProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "together")
+}
+
+func (provider *TogetherProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "together")
+}
+
+func (provider *TogetherProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "together")
+}
+
+func (provider *TogetherProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "together")
+}
+
+// validateModel checks that model is in the model list of the key and of a type the operation
+// accepts, so a typo fails fast with a clear error instead of Together's generic one. The model
+// list is fetched once per key and refreshed after togetherModelListTTL; if it cannot be fetched
+// the request is sent unchecked.
+func (provider *TogetherProvider) validateModel(ctx context.Context, model string, key schemas.Key, operation schemas.Operation) *schemas.BifrostError {
+	models, err := provider.getModels(ctx, key)
+	if err != nil {
+		provider.logger.Warn(fmt.Sprintf("failed to fetch the together model list, not validating model %s: %v", model, err))
+		return nil
+	}
+
+	modelType, ok := models[model]
+	if !ok {
+		return newProviderAPIError(fmt.Sprintf("model %s is not available on together", model), nil, fasthttp.StatusNotFound, schemas.Together, nil, nil)
+	}
+	accepted := togetherModelTypes[operation]
+	for _, acceptedType := range accepted {
+		if modelType == acceptedType {
+			return nil
+		}
+	}
+	return newProviderAPIError(fmt.Sprintf("model %s has type %s, %s requires a model of type %s", model, modelType, operation, strings.Join(accepted, ", ")), nil, fasthttp.StatusBadRequest, schemas.Together, nil, nil)
+}
+
+// getModels returns the model list of a key, keyed by model ID, fetching it if it is not cached
+// or is stale.
+func (provider *TogetherProvider) getModels(ctx context.Context, key schemas.Key) (map[string]string, error) {
+	provider.modelsMu.Lock()
+	cached, ok := provider.models[key.Value]
+	provider.modelsMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < togetherModelListTTL {
+		return cached.models, nil
+	}
+
+	models, err := provider.fetchModels(ctx, key)
+	if err != nil {
+		if ok {
+			// A stale list is better than none while Together's model list is unavailable
+			return cached.models, nil
+		}
+		return nil, err
+	}
+
+	provider.modelsMu.Lock()
+	provider.models[key.Value] = &togetherModelList{models: models, fetchedAt: time.Now()}
+	provider.modelsMu.Unlock()
+	return models, nil
+}
+
+// fetchModels fetches the model list of a key from Together's models endpoint.
+func (provider *TogetherProvider) fetchModels(ctx context.Context, key schemas.Key) (map[string]string, error) {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/v1/models")
+	req.Header.SetMethod("GET")
+	req.Header.Set("Authorization", "Bearer "+key.Value)
+
+	if bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp); bifrostErr != nil {
+		return nil, fmt.Errorf("%s", bifrostErr.Error.Message)
+	}
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var list []TogetherModel
+	if err := sonic.Unmarshal(resp.Body(), &list); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %w", err)
+	}
+	models := make(map[string]string, len(list))
+	for _, model := range list {
+		models[model.ID] = model.Type
+	}
+	return models, nil
+}
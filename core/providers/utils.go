@@ -3,9 +3,12 @@
 package providers
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/textproto"
 	"net/url"
@@ -14,6 +17,8 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bytedance/sonic"
 	schemas "github.com/maximhq/bifrost/core/schemas"
@@ -103,8 +108,10 @@ func prepareParams(params *schemas.ModelParameters) map[string]interface{} {
 		field := val.Field(i)
 		fieldType := typ.Field(i)
 
-		// Skip the ExtraParams field as it's handled separately
-		if fieldType.Name == "ExtraParams" {
+		// Skip the ExtraParams field as it's handled separately, the request policy,
+		// which configures Bifrost itself and is never sent to the provider, and the
+		// service tier, which providers translate with setServiceTier
+		if fieldType.Name == "ExtraParams" || fieldType.Name == "RequestPolicy" || fieldType.Name == "ServiceTier" {
 			continue
 		}
 
@@ -129,11 +136,109 @@ func prepareParams(params *schemas.ModelParameters) map[string]interface{} {
 	return flatParams
 }
 
+// serviceTierParam translates a service tier into the provider's service_tier value.
+// It returns false for providers without service tiers.
+func serviceTierParam(providerName schemas.ModelProvider, tier schemas.ServiceTier) (string, bool) {
+	switch providerName {
+	case schemas.OpenAI:
+		return string(tier), true
+	case schemas.Anthropic:
+		// Anthropic serves "auto" from priority capacity when the workspace has it
+		if tier == schemas.ServiceTierAuto || tier == schemas.ServiceTierPriority {
+			return "auto", true
+		}
+		return "standard_only", true
+	case schemas.Groq:
+		if tier == schemas.ServiceTierAuto || tier == schemas.ServiceTierFlex {
+			return string(tier), true
+		}
+		return "on_demand", true
+	}
+	return "", false
+}
+
+// SupportsServiceTier reports whether a provider translates the service tier of requests.
+func SupportsServiceTier(providerName schemas.ModelProvider) bool {
+	_, ok := serviceTierParam(providerName, schemas.ServiceTierDefault)
+	return ok
+}
+
+// setServiceTier adds the typed service tier of params to the prepared params, replacing a
+// service_tier extra param so that tier changes made by plugins take effect.
+func setServiceTier(providerName schemas.ModelProvider, params *schemas.ModelParameters, preparedParams map[string]interface{}) {
+	if params == nil || params.ServiceTier == nil {
+		return
+	}
+	if value, ok := serviceTierParam(providerName, *params.ServiceTier); ok {
+		preparedParams["service_tier"] = value
+	}
+}
+
+// warmUpConnections opens a connection to baseURL on each of a provider's HTTP clients that is
+// set, so the DNS lookup, TCP connect and TLS handshake are done before the first request and the
+// connection is kept in the client's pool. A HEAD request is sent without credentials, any
+// response the server sends counts as success.
+func warmUpConnections(ctx context.Context, client *fasthttp.Client, streamClient *http.Client, baseURL string) error {
+	if baseURL == "" {
+		return fmt.Errorf("base url not set")
+	}
+
+	if client != nil {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			deadline = time.Now().Add(schemas.DefaultWarmUpTimeout)
+		}
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		req.SetRequestURI(baseURL)
+		req.Header.SetMethod(http.MethodHead)
+		err := client.DoDeadline(req, resp, deadline)
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+		if err != nil {
+			return err
+		}
+	}
+
+	if streamClient != nil {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, baseURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := streamClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// checkRequestBody runs the request body check Bifrost sets in strict mode, if any, on the
+// final body of a provider request. Bodies that are not JSON objects are not checked.
+func checkRequestBody(ctx context.Context, body []byte) *schemas.BifrostError {
+	check, ok := ctx.Value(schemas.BifrostContextKeyRequestBodyCheck).(schemas.RequestBodyCheck)
+	if !ok || check == nil {
+		return nil
+	}
+	return check(body)
+}
+
+// withoutRequestBodyCheck returns a context for the auxiliary requests of a provider, such as
+// creating a context cache, whose bodies are not model requests and must not be checked.
+func withoutRequestBodyCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, schemas.BifrostContextKeyRequestBodyCheck, nil)
+}
+
 // IMPORTANT: This function does NOT truly cancel the underlying fasthttp network request if the
 // context is done. The fasthttp client call will continue in its goroutine until it completes
 // or times out based on its own settings. This function merely stops *waiting* for the
 // fasthttp call and returns an error related to the context.
 func makeRequestWithContext(ctx context.Context, client *fasthttp.Client, req *fasthttp.Request, resp *fasthttp.Response) *schemas.BifrostError {
+	if bifrostErr := checkRequestBody(ctx, req.Body()); bifrostErr != nil {
+		return bifrostErr
+	}
+
 	errChan := make(chan error, 1)
 
 	go func() {
@@ -148,6 +253,7 @@ func makeRequestWithContext(ctx context.Context, client *fasthttp.Client, req *f
 		// Return a BifrostError indicating this.
 		return &schemas.BifrostError{
 			IsBifrostError: true,
+			Origin:         requestErrorOrigin(ctx.Err()),
 			Error: schemas.ErrorField{
 				Type:    Ptr(schemas.RequestCancelled),
 				Message: fmt.Sprintf("Request cancelled or timed out by context: %v", ctx.Err()),
@@ -160,6 +266,7 @@ func makeRequestWithContext(ctx context.Context, client *fasthttp.Client, req *f
 			// The HTTP request itself failed (e.g., connection error, fasthttp timeout).
 			return &schemas.BifrostError{
 				IsBifrostError: false,
+				Origin:         requestErrorOrigin(err),
 				Error: schemas.ErrorField{
 					Message: schemas.ErrProviderRequest,
 					Error:   err,
@@ -292,6 +399,7 @@ func handleProviderAPIError(resp *fasthttp.Response, errorResp any) *schemas.Bif
 		return &schemas.BifrostError{
 			IsBifrostError: true,
 			StatusCode:     &statusCode,
+			Origin:         schemas.ErrorOriginProvider,
 			Error: schemas.ErrorField{
 				Message: schemas.ErrProviderResponseUnmarshal,
 				Error:   err,
@@ -302,6 +410,7 @@ func handleProviderAPIError(resp *fasthttp.Response, errorResp any) *schemas.Bif
 	return &schemas.BifrostError{
 		IsBifrostError: false,
 		StatusCode:     &statusCode,
+		Origin:         schemas.ErrorOriginProvider,
 		Error:          schemas.ErrorField{},
 	}
 }
@@ -601,13 +710,34 @@ func detectAudioMimeType(audioData []byte) string {
 	return "audio/mp3"
 }
 
+// speechMimeType returns the mime type of a speech response format.
+func speechMimeType(format string) string {
+	switch format {
+	case "opus":
+		return "audio/ogg"
+	case "aac":
+		return "audio/aac"
+	case "flac":
+		return "audio/flac"
+	case "wav":
+		return "audio/wav"
+	case "pcm":
+		return "audio/L16;rate=24000;channels=1"
+	default:
+		return "audio/mpeg"
+	}
+}
+
 // newUnsupportedOperationError creates a standardized error for unsupported operations.
 // This helper reduces code duplication across providers that don't support certain operations.
 func newUnsupportedOperationError(operation string, providerName string) *schemas.BifrostError {
 	return &schemas.BifrostError{
 		IsBifrostError: false,
 		Provider:       schemas.ModelProvider(providerName),
+		Type:           Ptr(schemas.UnsupportedOperation),
+		Origin:         schemas.ErrorOriginClientRequest,
 		Error: schemas.ErrorField{
+			Type:    Ptr(schemas.UnsupportedOperation),
 			Message: fmt.Sprintf("%s is not supported by %s provider", operation, providerName),
 		},
 	}
@@ -637,6 +767,7 @@ func newConfigurationError(message string, providerType schemas.ModelProvider) *
 	return &schemas.BifrostError{
 		IsBifrostError: false,
 		Provider:       providerType,
+		Origin:         schemas.ErrorOriginBifrostInternal,
 		Error: schemas.ErrorField{
 			Message: message,
 		},
@@ -649,6 +780,7 @@ func newBifrostOperationError(message string, err error, providerType schemas.Mo
 	return &schemas.BifrostError{
 		IsBifrostError: true,
 		Provider:       providerType,
+		Origin:         operationErrorOrigin(message, err),
 		Error: schemas.ErrorField{
 			Message: message,
 			Error:   err,
@@ -656,6 +788,29 @@ func newBifrostOperationError(message string, err error, providerType schemas.Mo
 	}
 }
 
+// operationErrorOrigin returns the origin of a bifrost operation error from its message: failed
+// requests come from the network, responses that cannot be read from the provider, and the
+// other operations from Bifrost.
+func operationErrorOrigin(message string, err error) schemas.ErrorOrigin {
+	switch message {
+	case schemas.ErrProviderRequest:
+		return requestErrorOrigin(err)
+	case schemas.ErrProviderResponseUnmarshal, schemas.ErrProviderDecodeStructured, schemas.ErrProviderDecodeRaw, schemas.ErrProviderDecompress:
+		return schemas.ErrorOriginProvider
+	default:
+		return schemas.ErrorOriginBifrostInternal
+	}
+}
+
+// requestErrorOrigin returns the origin of the error of a request to a provider, which is the
+// client if it cancelled the request and the network otherwise, timeouts included.
+func requestErrorOrigin(err error) schemas.ErrorOrigin {
+	if errors.Is(err, context.Canceled) {
+		return schemas.ErrorOriginClientRequest
+	}
+	return schemas.ErrorOriginNetwork
+}
+
 // newProviderAPIError creates a standardized error for provider API errors.
 // This helper reduces code duplication across providers that have provider API errors.
 func newProviderAPIError(message string, err error, statusCode int, providerType schemas.ModelProvider, errorType *string, eventID *string) *schemas.BifrostError {
@@ -663,6 +818,7 @@ func newProviderAPIError(message string, err error, statusCode int, providerType
 		IsBifrostError: false,
 		Provider:       providerType,
 		StatusCode:     &statusCode,
+		Origin:         schemas.ErrorOriginProvider,
 		Type:           errorType,
 		EventID:        eventID,
 		Error: schemas.ErrorField{
@@ -673,6 +829,37 @@ func newProviderAPIError(message string, err error, statusCode int, providerType
 	}
 }
 
+// streamAbortContextKey is the context key of the streamAbort of a stream request.
+type streamAbortContextKey struct{}
+
+// streamAbort lets a post hook end a stream early: it cancels the upstream request and
+// silences the chunks and errors the provider produces while winding down.
+type streamAbort struct {
+	cancel  context.CancelFunc
+	aborted atomic.Bool
+}
+
+// WithStreamAbort returns a context for a stream request that is cancelled when a post hook
+// returns an error with StreamControl.AbortStream set.
+func WithStreamAbort(ctx context.Context) context.Context {
+	abortCtx, cancel := context.WithCancel(ctx)
+	return context.WithValue(abortCtx, streamAbortContextKey{}, &streamAbort{cancel: cancel})
+}
+
+// isStreamAborted reports whether the stream of the context was aborted by a post hook.
+func isStreamAborted(ctx context.Context) bool {
+	abort, ok := ctx.Value(streamAbortContextKey{}).(*streamAbort)
+	return ok && abort.aborted.Load()
+}
+
+// abortStream marks the stream of the context as aborted and cancels its upstream request.
+func abortStream(ctx context.Context) {
+	if abort, ok := ctx.Value(streamAbortContextKey{}).(*streamAbort); ok {
+		abort.aborted.Store(true)
+		abort.cancel()
+	}
+}
+
 // processAndSendResponse handles post-hook processing and sends the response to the channel.
 // This utility reduces code duplication across streaming implementations by encapsulating
 // the common pattern of running post hooks, handling errors, and sending responses with
@@ -684,6 +871,37 @@ func processAndSendResponse(
 	responseChan chan *schemas.BifrostStream,
 	logger schemas.Logger,
 ) {
+	processAndSendChunk(ctx, postHookRunner, response, "", responseChan, logger)
+}
+
+// processAndSendAudioChunk is processAndSendResponse for the chunks of speech streams. The audio
+// left in the response by the post hooks is also attached to the chunk as binary content of the
+// given mime type, so transports can stream it raw.
+func processAndSendAudioChunk(
+	ctx context.Context,
+	postHookRunner schemas.PostHookRunner,
+	response *schemas.BifrostResponse,
+	contentType string,
+	responseChan chan *schemas.BifrostStream,
+	logger schemas.Logger,
+) {
+	processAndSendChunk(ctx, postHookRunner, response, contentType, responseChan, logger)
+}
+
+// processAndSendChunk runs the post hooks on a stream chunk and sends it, with the speech audio
+// of the response as binary content when binaryContentType is set.
+func processAndSendChunk(
+	ctx context.Context,
+	postHookRunner schemas.PostHookRunner,
+	response *schemas.BifrostResponse,
+	binaryContentType string,
+	responseChan chan *schemas.BifrostStream,
+	logger schemas.Logger,
+) {
+	if isStreamAborted(ctx) {
+		return
+	}
+
 	// Run post hooks on the response
 	processedResponse, bifrostErr := postHookRunner(&ctx, response, nil)
 	if bifrostErr != nil {
@@ -702,15 +920,29 @@ func processAndSendResponse(
 		case responseChan <- errorResponse:
 		case <-ctx.Done():
 		}
+
+		// An aborting error is the last message of the stream, cancel the upstream request
+		if bifrostErr.StreamControl != nil && bifrostErr.StreamControl.AbortStream != nil && *bifrostErr.StreamControl.AbortStream {
+			abortStream(ctx)
+		}
 		return
 	}
 
-	// Send the response
-	select {
-	case responseChan <- &schemas.BifrostStream{
+	stream := &schemas.BifrostStream{
 		BifrostResponse: processedResponse,
 		BifrostError:    bifrostErr,
-	}:
+	}
+	if binaryContentType != "" && processedResponse != nil && processedResponse.Speech != nil && len(processedResponse.Speech.Audio) > 0 {
+		stream.Binary = &schemas.BinaryChunk{
+			Sequence:    processedResponse.ExtraFields.ChunkIndex,
+			ContentType: binaryContentType,
+			Data:        processedResponse.Speech.Audio,
+		}
+	}
+
+	// Send the response
+	select {
+	case responseChan <- stream:
 	case <-ctx.Done():
 		return
 	}
@@ -727,6 +959,10 @@ func processAndSendBifrostError(
 	responseChan chan *schemas.BifrostStream,
 	logger schemas.Logger,
 ) {
+	if isStreamAborted(ctx) {
+		return
+	}
+
 	// Send scanner error through channel
 	processedResponse, processedError := postHookRunner(&ctx, nil, bifrostErr)
 
@@ -744,6 +980,25 @@ func processAndSendBifrostError(
 	}
 }
 
+// recoverStreamPanic is deferred by the goroutines reading provider streams, after closing the
+// channel, so a panic on a malformed chunk ends the stream with an InternalPanic error instead
+// of crashing the process.
+func recoverStreamPanic(
+	ctx context.Context,
+	postHookRunner schemas.PostHookRunner,
+	providerName schemas.ModelProvider,
+	responseChan chan *schemas.BifrostStream,
+	logger schemas.Logger,
+) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	panicErr := schemas.NewPanicError(string(providerName), recovered)
+	logger.Error(fmt.Sprintf("recovered %s\n%s", panicErr.Error(), panicErr.Stack))
+	processAndSendBifrostError(ctx, postHookRunner, panicErr.BifrostError(providerName), responseChan, logger)
+}
+
 // processAndSendError handles post-hook processing and sends the error to the channel.
 // This utility reduces code duplication across streaming implementations by encapsulating
 // the common pattern of running post hooks, handling errors, and sending responses with
@@ -755,10 +1010,16 @@ func processAndSendError(
 	responseChan chan *schemas.BifrostStream,
 	logger schemas.Logger,
 ) {
+	// Errors of the cancelled upstream request of an aborted stream are not reported
+	if isStreamAborted(ctx) {
+		return
+	}
+
 	// Send scanner error through channel
 	bifrostError :=
 		&schemas.BifrostError{
 			IsBifrostError: true,
+			Origin:         requestErrorOrigin(err),
 			Error: schemas.ErrorField{
 				Message: fmt.Sprintf("Error reading stream: %v", err),
 				Error:   err,
@@ -822,6 +1083,33 @@ func handleStreamEndWithSuccess(
 	processAndSendResponse(ctx, postHookRunner, response, responseChan, logger)
 }
 
+// incompleteStreamFinishReason handles a stream the provider closed without a terminator, such
+// as [DONE] or a finish reason, according to the IncompleteStreamBehavior of the context. It
+// returns the finish reason of the final chunk, or false if the stream was ended with an error
+// and no final chunk must be sent.
+func incompleteStreamFinishReason(
+	ctx context.Context,
+	postHookRunner schemas.PostHookRunner,
+	providerName schemas.ModelProvider,
+	responseChan chan *schemas.BifrostStream,
+	logger schemas.Logger,
+) (*string, bool) {
+	behavior, _ := ctx.Value(schemas.BifrostContextKeyIncompleteStream).(schemas.IncompleteStreamBehavior)
+	switch behavior {
+	case schemas.IncompleteStreamIgnore:
+		return nil, true
+	case schemas.IncompleteStreamError:
+		ctx = context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+		bifrostErr := newProviderAPIError(fmt.Sprintf("%s closed the stream before the response was finished", providerName), nil, http.StatusBadGateway, providerName, Ptr(schemas.FinishReasonIncomplete), nil)
+		processAndSendBifrostError(ctx, postHookRunner, bifrostErr, responseChan, logger)
+		return nil, false
+	}
+	logger.Debug(fmt.Sprintf("%s closed the stream without a terminator, finishing it as incomplete", providerName))
+	schemas.AddWarning(ctx, schemas.WarningTruncated, string(providerName),
+		"the stream was closed without [DONE] or a finish reason, the response may be cut short")
+	return Ptr(schemas.FinishReasonIncomplete), true
+}
+
 func handleStreamControlSkip(logger schemas.Logger, bifrostErr *schemas.BifrostError) bool {
 	if bifrostErr == nil || bifrostErr.StreamControl == nil {
 		return false
@@ -846,3 +1134,148 @@ func getProviderName(defaultProvider schemas.ModelProvider, customConfig *schema
 	}
 	return defaultProvider
 }
+
+// mapReasoningFields renames the reasoning_content or reasoning field, in which reasoning models
+// such as DeepSeek R1 return their reasoning, to thought in the given object (message or delta)
+// of every choice of a raw chat completion response or chunk.
+func mapReasoningFields(rawMap map[string]interface{}, object string) {
+	choices, ok := rawMap["choices"].([]interface{})
+	if !ok {
+		return
+	}
+	for _, choice := range choices {
+		if choiceMap, ok := choice.(map[string]interface{}); ok {
+			if fields, ok := choiceMap[object].(map[string]interface{}); ok {
+				if rc, exists := fields["reasoning_content"]; exists {
+					fields["thought"] = rc
+					delete(fields, "reasoning_content")
+				} else if r, exists := fields["reasoning"]; exists {
+					fields["thought"] = r
+					delete(fields, "reasoning")
+				}
+			}
+		}
+	}
+}
+
+// parseResponseWithReasoningFields parses response body and maps reasoning_content/reasoning to thought
+func parseResponseWithReasoningFields(responseBody []byte, providerName schemas.ModelProvider) (map[string]interface{}, *schemas.BifrostResponse, *schemas.BifrostError) {
+	// Parse as raw map to handle reasoning fields
+	var rawMap map[string]interface{}
+	if err := sonic.Unmarshal(responseBody, &rawMap); err != nil {
+		return nil, nil, newBifrostOperationError(schemas.ErrProviderResponseUnmarshal, err, providerName)
+	}
+
+	mapReasoningFields(rawMap, "message")
+
+	// Re-marshal and parse into BifrostResponse
+	modifiedBody, err := sonic.Marshal(rawMap)
+	if err != nil {
+		return nil, nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, providerName)
+	}
+
+	response := &schemas.BifrostResponse{}
+	if err := sonic.Unmarshal(modifiedBody, response); err != nil {
+		return nil, nil, newBifrostOperationError(schemas.ErrProviderResponseUnmarshal, err, providerName)
+	}
+
+	return rawMap, response, nil
+}
+
+// parseReasoningChatResponse parses the chat completion response of an OpenAI-compatible
+// provider with parseResponseWithReasoningFields and fills in its extra fields.
+func parseReasoningChatResponse(responseBody []byte, providerName schemas.ModelProvider, params *schemas.ModelParameters, sendBackRawResponse bool) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	rawMap, response, bifrostErr := parseResponseWithReasoningFields(responseBody, providerName)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	response.ExtraFields.Provider = providerName
+
+	if sendBackRawResponse {
+		response.ExtraFields.RawResponse = rawMap
+	}
+
+	if params != nil {
+		response.ExtraFields.Params = *params
+	}
+
+	return response, nil
+}
+
+// maxStreamLineSize is the largest line stream scanners accept. Lines packing several objects,
+// or carrying whole tool calls, exceed bufio's default of 64KB.
+const maxStreamLineSize = 1024 * 1024
+
+// newStreamScanner returns a scanner over the lines of a stream body, accepting lines of up to
+// maxStreamLineSize.
+func newStreamScanner(body io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+	return scanner
+}
+
+// splitStreamDataObjects splits an SSE line into the JSON objects of its data. Some backends
+// pack several objects into one data line, or omit the blank lines between events, e.g.
+// `data: {...}{...}` or `data: {...}data: {...}`. done reports whether the
+// line ends the stream with [DONE]. Other SSE fields, such as event or id, carry no objects, and
+// data that is not made of JSON objects is returned as is, to be reported by the parser.
+func splitStreamDataObjects(line string) (objects []string, done bool) {
+	rest := strings.TrimSpace(line)
+	for _, field := range []string{"event:", "id:", "retry:"} {
+		if strings.HasPrefix(rest, field) {
+			return nil, false
+		}
+	}
+
+	for {
+		// Lines without the data prefix are raw JSON, such as errors
+		rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), "data:"))
+		if rest == "" {
+			return objects, false
+		}
+		if strings.HasPrefix(rest, "[DONE]") {
+			return objects, true
+		}
+		end := jsonObjectEnd(rest)
+		if end < 0 {
+			return append(objects, rest), false
+		}
+		objects = append(objects, rest[:end])
+		rest = rest[end:]
+	}
+}
+
+// jsonObjectEnd returns the length of the JSON object s starts with, or -1 if s does not start
+// with a complete JSON object.
+func jsonObjectEnd(s string) int {
+	if !strings.HasPrefix(s, "{") {
+		return -1
+	}
+	depth := 0
+	inString := false
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
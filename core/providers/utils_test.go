@@ -0,0 +1,64 @@
+package providers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestJSONObjectEnd(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want int
+	}{
+		{name: "object", s: `{"a":1}`, want: 7},
+		{name: "trailing data", s: `{"a":1}{"b":2}`, want: 7},
+		{name: "nested object and array", s: `{"a":{"b":[1,{"c":2}]}} `, want: 23},
+		{name: "brace inside string", s: `{"a":"}"}`, want: 9},
+		{name: "opening brace inside string", s: `{"a":"{"}{}`, want: 9},
+		{name: "escaped quote", s: `{"a":"\"}"}`, want: 11},
+		{name: "escaped backslash before quote", s: `{"a":"\\"}x`, want: 10},
+		{name: "truncated object", s: `{"a":1`, want: -1},
+		{name: "truncated string", s: `{"a":"}`, want: -1},
+		{name: "not an object", s: `[DONE]`, want: -1},
+		{name: "empty", s: ``, want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jsonObjectEnd(tt.s); got != tt.want {
+				t.Errorf("jsonObjectEnd(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitStreamDataObjects(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		wantObjects []string
+		wantDone    bool
+	}{
+		{name: "single object", line: `data: {"a":1}`, wantObjects: []string{`{"a":1}`}},
+		{name: "no space after prefix", line: `data:{"a":1}`, wantObjects: []string{`{"a":1}`}},
+		{name: "packed objects", line: `data: {"a":1}{"b":2}`, wantObjects: []string{`{"a":1}`, `{"b":2}`}},
+		{name: "missing blank line", line: `data: {"a":1}data: {"b":2}`, wantObjects: []string{`{"a":1}`, `{"b":2}`}},
+		{name: "done", line: `data: [DONE]`, wantDone: true},
+		{name: "object then done", line: `data: {"a":1}data: [DONE]`, wantObjects: []string{`{"a":1}`}, wantDone: true},
+		{name: "braces and quotes inside strings", line: `data: {"a":"}{\"data: x"}{"b":"{"}`, wantObjects: []string{`{"a":"}{\"data: x"}`, `{"b":"{"}`}},
+		{name: "truncated object", line: `data: {"a":1}{"b":`, wantObjects: []string{`{"a":1}`, `{"b":`}},
+		{name: "raw JSON error", line: `{"error":{"message":"bad"}}`, wantObjects: []string{`{"error":{"message":"bad"}}`}},
+		{name: "not JSON", line: `data: hello`, wantObjects: []string{`hello`}},
+		{name: "event field", line: `event: message`},
+		{name: "id field", line: `id: 1`},
+		{name: "empty data", line: `data: `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, done := splitStreamDataObjects(tt.line)
+			if !slices.Equal(objects, tt.wantObjects) || done != tt.wantDone {
+				t.Errorf("splitStreamDataObjects(%q) = %q, %v, want %q, %v", tt.line, objects, done, tt.wantObjects, tt.wantDone)
+			}
+		})
+	}
+}
@@ -13,7 +13,9 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 
 	"github.com/bytedance/sonic"
@@ -28,32 +30,39 @@ type VertexError struct {
 	} `json:"error"`
 }
 
-// vertexClientPool provides a pool/cache for authenticated Vertex HTTP clients.
-// This avoids creating and authenticating clients for every request.
-// Uses sync.Map for atomic operations without explicit locking.
-var vertexClientPool sync.Map
+// vertexTokenSources caches an OAuth2 token source per set of auth credentials, so an access token
+// is minted once and shared by every request of the key until shortly before it expires, when it
+// is refreshed. Uses sync.Map for atomic operations without explicit locking.
+var vertexTokenSources sync.Map
 
-// getClientKey generates a unique key for caching authenticated clients.
+// vertexTokenRefreshWindow is how long before expiry a cached access token is refreshed, so requests
+// never go out with a token that expires in flight.
+const vertexTokenRefreshWindow = 5 * time.Minute
+
+// getClientKey generates a unique key for caching token sources.
 // It uses a hash of the auth credentials for security.
 func getClientKey(authCredentials string) string {
 	hash := sha256.Sum256([]byte(authCredentials))
 	return hex.EncodeToString(hash[:])
 }
 
-// removeVertexClient removes a specific client from the pool.
+// removeVertexTokenSource removes the cached token source of a set of credentials.
 // This should be called when:
 // - API returns authentication/authorization errors (401, 403)
-// - Auth client creation fails
+// - Token source creation fails
 // - Network errors that might indicate credential issues
-// This ensures we don't keep using potentially invalid clients.
-func removeVertexClient(authCredentials string) {
+// This ensures we don't keep using potentially invalid tokens.
+func removeVertexTokenSource(authCredentials string) {
 	clientKey := getClientKey(authCredentials)
-	vertexClientPool.Delete(clientKey)
+	vertexTokenSources.Delete(clientKey)
 }
 
 // VertexProvider implements the Provider interface for Google's Vertex AI API.
+// Claude models use the Anthropic publisher endpoint, Gemini models the native generateContent
+// API and all other models, such as partner and open models, the OpenAI-compatible endpoint.
 type VertexProvider struct {
 	logger              schemas.Logger        // Logger for provider operations
+	client              *http.Client          // Base HTTP client, authenticated per key by getAuthClient
 	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
 	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
 }
@@ -64,6 +73,13 @@ type VertexProvider struct {
 func NewVertexProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*VertexProvider, error) {
 	config.CheckAndSetDefaults()
 
+	client := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Apply the HTTP client profile if provided, the authenticated clients wrap its transport
+	client = configureNetHTTPClientProfile(client, config.HTTPClientProfile, logger)
+
 	// Pre-warm response pools
 	for range config.ConcurrencyAndBufferSize.Concurrency {
 		// openAIResponsePool.Put(&schemas.BifrostResponse{})
@@ -73,6 +89,7 @@ func NewVertexProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*
 
 	return &VertexProvider{
 		logger:              logger,
+		client:              client,
 		networkConfig:       config.NetworkConfig,
 		sendBackRawResponse: config.SendBackRawResponse,
 	}, nil
@@ -80,47 +97,75 @@ func NewVertexProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*
 
 const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
 
-// getAuthClient returns an authenticated HTTP client for Vertex AI API requests.
-// This function implements client pooling to avoid creating and authenticating
-// clients for every request, which significantly improves performance by:
-// - Avoiding repeated JWT config creation
-// - Reusing OAuth2 token refresh logic
-// - Reducing authentication overhead
-func getAuthClient(key schemas.Key) (*http.Client, error) {
+// getVertexTokenSource returns the cached token source of a key's credentials, creating it on first
+// use. Service account JSON credentials are exchanged for access tokens with a signed JWT; without
+// credentials the default credentials of the environment (metadata server, gcloud, workload
+// identity) are used.
+func getVertexTokenSource(key schemas.Key) (oauth2.TokenSource, error) {
 	if key.VertexKeyConfig == nil {
 		return nil, fmt.Errorf("vertex key config is not set")
 	}
 
 	authCredentials := key.VertexKeyConfig.AuthCredentials
-	var client *http.Client
 	// Generate cache key from credentials
 	clientKey := getClientKey(authCredentials)
 
-	// Try to get existing client from pool
-	if value, exists := vertexClientPool.Load(clientKey); exists {
-		return value.(*http.Client), nil
+	// Try to get existing token source from cache
+	if value, exists := vertexTokenSources.Load(clientKey); exists {
+		return value.(oauth2.TokenSource), nil
 	}
 
+	var tokenSource oauth2.TokenSource
 	if authCredentials == "" {
 		// When auth credentials are not explicitly set, use default credentials
 		// This will automatically detect credentials from the environment/server
-		var err error
-		client, err = google.DefaultClient(context.Background(), cloudPlatformScope)
+		credentials, err := google.FindDefaultCredentials(context.Background(), cloudPlatformScope)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create default client: %w", err)
+			return nil, fmt.Errorf("failed to find default credentials: %w", err)
 		}
+		tokenSource = credentials.TokenSource
 	} else {
 		conf, err := google.JWTConfigFromJSON([]byte(authCredentials), cloudPlatformScope)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create JWT config: %w", err)
 		}
-		client = conf.Client(context.Background())
+		tokenSource = conf.TokenSource(context.Background())
+	}
+	tokenSource = oauth2.ReuseTokenSourceWithExpiry(nil, tokenSource, vertexTokenRefreshWindow)
+
+	// Store the token source using LoadOrStore to handle race conditions
+	// If another goroutine stored one while we were creating ours, use theirs
+	actual, _ := vertexTokenSources.LoadOrStore(clientKey, tokenSource)
+	return actual.(oauth2.TokenSource), nil
+}
+
+// getAuthClient returns an HTTP client that authenticates Vertex AI API requests with the cached
+// access token of the key, reusing the provider's timeouts.
+func (provider *VertexProvider) getAuthClient(key schemas.Key) (*http.Client, error) {
+	tokenSource, err := getVertexTokenSource(key)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Timeout: provider.client.Timeout,
+		Transport: &oauth2.Transport{
+			Source: tokenSource,
+			Base:   provider.client.Transport,
+		},
+	}, nil
+}
+
+// vertexBaseURL returns the API host of a Vertex region, "global" is served without a region prefix.
+func vertexBaseURL(region string) string {
+	if region == "global" {
+		return "https://aiplatform.googleapis.com"
 	}
+	return fmt.Sprintf("https://%s-aiplatform.googleapis.com", region)
+}
 
-	// Store the client using LoadOrStore to handle race conditions
-	// If another goroutine stored a client while we were creating ours, use theirs
-	actual, _ := vertexClientPool.LoadOrStore(clientKey, client)
-	return actual.(*http.Client), nil
+// isVertexGeminiModel reports whether a model is a Gemini model, served by the native generateContent API.
+func isVertexGeminiModel(model string) bool {
+	return strings.Contains(model, "gemini")
 }
 
 // GetProviderKey returns the provider identifier for Vertex.
@@ -128,6 +173,14 @@ func (provider *VertexProvider) GetProviderKey() schemas.ModelProvider {
 	return schemas.Vertex
 }
 
+// WarmUp opens connections to the Vertex region of a key ahead of the first request.
+func (provider *VertexProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	if key.VertexKeyConfig == nil || key.VertexKeyConfig.Region == "" {
+		return fmt.Errorf("region not set")
+	}
+	return warmUpConnections(ctx, nil, provider.client, vertexBaseURL(key.VertexKeyConfig.Region))
+}
+
 // TextCompletion is not supported by the Vertex provider.
 // Returns an error indicating that text completion is not available.
 func (provider *VertexProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
@@ -142,11 +195,28 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, model string
 		return nil, newConfigurationError("vertex key config is not set", schemas.Vertex)
 	}
 
+	projectID := key.VertexKeyConfig.ProjectID
+	if projectID == "" {
+		return nil, newConfigurationError("project ID is not set", schemas.Vertex)
+	}
+
+	region := key.VertexKeyConfig.Region
+	if region == "" {
+		return nil, newConfigurationError("region is not set in key config", schemas.Vertex)
+	}
+
+	if isVertexGeminiModel(model) {
+		return provider.geminiChatCompletion(ctx, model, key, messages, params)
+	}
+
 	// Format messages for Vertex API
 	var formattedMessages []map[string]interface{}
 	var preparedParams map[string]interface{}
 
 	if strings.Contains(model, "claude") {
+		if err := checkAnthropicTools(params, schemas.Vertex); err != nil {
+			return nil, err
+		}
 		formattedMessages, preparedParams = prepareAnthropicChatRequest(messages, params)
 	} else {
 		formattedMessages, preparedParams = prepareOpenAIChatRequest(messages, params)
@@ -167,25 +237,122 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, model string
 
 	delete(requestBody, "region")
 
-	jsonBody, err := sonic.Marshal(requestBody)
-	if err != nil {
-		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Vertex)
+	url := fmt.Sprintf("%s/v1beta1/projects/%s/locations/%s/endpoints/openapi/chat/completions", vertexBaseURL(region), projectID, region)
+
+	if strings.Contains(model, "claude") {
+		url = fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/anthropic/models/%s:streamRawPredict", vertexBaseURL(region), projectID, region, model)
 	}
 
-	projectID := key.VertexKeyConfig.ProjectID
-	if projectID == "" {
-		return nil, newConfigurationError("project ID is not set", schemas.Vertex)
+	body, bifrostErr := provider.completeRequest(ctx, key, url, requestBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
 	}
 
+	if strings.Contains(model, "claude") {
+		// Create response object from pool
+		response := acquireAnthropicChatResponse()
+		defer releaseAnthropicChatResponse(response)
+
+		rawResponse, bifrostErr := handleProviderResponse(body, response, provider.sendBackRawResponse)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		// Create final response
+		bifrostResponse := &schemas.BifrostResponse{}
+		var err *schemas.BifrostError
+		bifrostResponse, err = parseAnthropicResponse(response, bifrostResponse)
+		if err != nil {
+			return nil, err
+		}
+
+		bifrostResponse.ExtraFields = schemas.BifrostResponseExtraFields{
+			Provider: schemas.Vertex,
+		}
+
+		if provider.sendBackRawResponse {
+			bifrostResponse.ExtraFields.RawResponse = rawResponse
+		}
+
+		if params != nil {
+			bifrostResponse.ExtraFields.Params = *params
+		}
+
+		return bifrostResponse, nil
+	} else {
+		// Pre-allocate response structs from pools
+		// response := acquireOpenAIResponse()
+		response := &schemas.BifrostResponse{}
+		// defer releaseOpenAIResponse(response)
+
+		// Use enhanced response handler with pre-allocated response
+		rawResponse, bifrostErr := handleProviderResponse(body, response, provider.sendBackRawResponse)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		response.ExtraFields.Provider = schemas.Vertex
+
+		if provider.sendBackRawResponse {
+			response.ExtraFields.RawResponse = rawResponse
+		}
+
+		if params != nil {
+			response.ExtraFields.Params = *params
+		}
+
+		return response, nil
+	}
+}
+
+// geminiChatCompletion performs a chat completion request for a Gemini model with the native
+// generateContent API, so thoughts, their signatures and function calls are returned as Gemini
+// produces them, as with the Gemini provider.
+func (provider *VertexProvider) geminiChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
 	region := key.VertexKeyConfig.Region
-	if region == "" {
-		return nil, newConfigurationError("region is not set in key config", schemas.Vertex)
+	url := fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/google/models/%s:generateContent", vertexBaseURL(region), key.VertexKeyConfig.ProjectID, region, model)
+
+	body, bifrostErr := provider.completeRequest(ctx, key, url, prepareGeminiGenerationRequest(messages, params, nil))
+	if bifrostErr != nil {
+		return nil, bifrostErr
 	}
 
-	url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1beta1/projects/%s/locations/%s/endpoints/openapi/chat/completions", region, projectID, region)
+	var geminiResponse GenerateContentResponse
+	rawResponse, bifrostErr := handleProviderResponse(body, &geminiResponse, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
 
-	if strings.Contains(model, "claude") {
-		url = fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/anthropic/models/%s:streamRawPredict", region, projectID, region, model)
+	response := &schemas.BifrostResponse{
+		Model: model,
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Vertex,
+		},
+	}
+	setGeminiChatResponse(response, &geminiResponse)
+
+	if provider.sendBackRawResponse {
+		response.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		response.ExtraFields.Params = *params
+	}
+
+	return response, nil
+}
+
+// completeRequest sends an authenticated JSON request to the Vertex API and returns the body of a
+// successful response. The cached token of the key is dropped on authentication errors, so the
+// next request mints a new one.
+func (provider *VertexProvider) completeRequest(ctx context.Context, key schemas.Key, url string, requestBody map[string]interface{}) ([]byte, *schemas.BifrostError) {
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Vertex)
+	}
+
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
 	}
 
 	// Create request
@@ -193,6 +360,7 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, model string
 	if err != nil {
 		return nil, &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         schemas.ErrorOriginBifrostInternal,
 			Error: schemas.ErrorField{
 				Message: schemas.ErrProviderRequest,
 				Error:   err,
@@ -205,10 +373,10 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, model string
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client, err := getAuthClient(key)
+	client, err := provider.getAuthClient(key)
 	if err != nil {
-		// Remove client from pool if auth client creation fails
-		removeVertexClient(key.VertexKeyConfig.AuthCredentials)
+		// Remove token source from cache if its creation fails
+		removeVertexTokenSource(key.VertexKeyConfig.AuthCredentials)
 		return nil, newBifrostOperationError("error creating auth client", err, schemas.Vertex)
 	}
 
@@ -218,6 +386,7 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, model string
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return nil, &schemas.BifrostError{
 				IsBifrostError: false,
+				Origin:         requestErrorOrigin(err),
 				Error: schemas.ErrorField{
 					Type:    Ptr(schemas.RequestCancelled),
 					Message: fmt.Sprintf("Request cancelled or timed out by context: %v", ctx.Err()),
@@ -225,13 +394,12 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, model string
 				},
 			}
 		}
-		// Remove client from pool for non-context errors (could be auth/network issues)
-		removeVertexClient(key.VertexKeyConfig.AuthCredentials)
+		// Remove token source from cache for non-context errors (could be auth/network issues)
+		removeVertexTokenSource(key.VertexKeyConfig.AuthCredentials)
 		return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, schemas.Vertex)
 	}
 	defer resp.Body.Close()
 
-	// Handle error response
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -239,9 +407,9 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, model string
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		// Remove client from pool for authentication/authorization errors
+		// Remove token source from cache for authentication/authorization errors
 		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-			removeVertexClient(key.VertexKeyConfig.AuthCredentials)
+			removeVertexTokenSource(key.VertexKeyConfig.AuthCredentials)
 		}
 
 		var openAIErr schemas.BifrostError
@@ -261,61 +429,7 @@ func (provider *VertexProvider) ChatCompletion(ctx context.Context, model string
 		return nil, newProviderAPIError(openAIErr.Error.Message, nil, resp.StatusCode, schemas.Vertex, nil, nil)
 	}
 
-	if strings.Contains(model, "claude") {
-		// Create response object from pool
-		response := acquireAnthropicChatResponse()
-		defer releaseAnthropicChatResponse(response)
-
-		rawResponse, bifrostErr := handleProviderResponse(body, response, provider.sendBackRawResponse)
-		if bifrostErr != nil {
-			return nil, bifrostErr
-		}
-
-		// Create final response
-		bifrostResponse := &schemas.BifrostResponse{}
-		var err *schemas.BifrostError
-		bifrostResponse, err = parseAnthropicResponse(response, bifrostResponse)
-		if err != nil {
-			return nil, err
-		}
-
-		bifrostResponse.ExtraFields = schemas.BifrostResponseExtraFields{
-			Provider: schemas.Vertex,
-		}
-
-		if provider.sendBackRawResponse {
-			bifrostResponse.ExtraFields.RawResponse = rawResponse
-		}
-
-		if params != nil {
-			bifrostResponse.ExtraFields.Params = *params
-		}
-
-		return bifrostResponse, nil
-	} else {
-		// Pre-allocate response structs from pools
-		// response := acquireOpenAIResponse()
-		response := &schemas.BifrostResponse{}
-		// defer releaseOpenAIResponse(response)
-
-		// Use enhanced response handler with pre-allocated response
-		rawResponse, bifrostErr := handleProviderResponse(body, response, provider.sendBackRawResponse)
-		if bifrostErr != nil {
-			return nil, bifrostErr
-		}
-
-		response.ExtraFields.Provider = schemas.Vertex
-
-		if provider.sendBackRawResponse {
-			response.ExtraFields.RawResponse = rawResponse
-		}
-
-		if params != nil {
-			response.ExtraFields.Params = *params
-		}
-
-		return response, nil
-	}
+	return body, nil
 }
 
 // Embedding generates embeddings for the given input text(s) using Vertex AI.
@@ -415,9 +529,13 @@ func (provider *VertexProvider) handleVertexEmbedding(ctx context.Context, model
 		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Vertex)
 	}
 
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
 	// Build the native Vertex embedding API endpoint
-	url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/%s:predict",
-		key.VertexKeyConfig.Region, key.VertexKeyConfig.ProjectID, key.VertexKeyConfig.Region, model)
+	url := fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/google/models/%s:predict",
+		vertexBaseURL(key.VertexKeyConfig.Region), key.VertexKeyConfig.ProjectID, key.VertexKeyConfig.Region, model)
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
@@ -430,10 +548,10 @@ func (provider *VertexProvider) handleVertexEmbedding(ctx context.Context, model
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client, err := getAuthClient(key)
+	client, err := provider.getAuthClient(key)
 	if err != nil {
-		// Remove client from pool if auth client creation fails
-		removeVertexClient(key.VertexKeyConfig.AuthCredentials)
+		// Remove token source from cache if its creation fails
+		removeVertexTokenSource(key.VertexKeyConfig.AuthCredentials)
 		return nil, newBifrostOperationError("error creating auth client", err, schemas.Vertex)
 	}
 
@@ -443,6 +561,7 @@ func (provider *VertexProvider) handleVertexEmbedding(ctx context.Context, model
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return nil, &schemas.BifrostError{
 				IsBifrostError: false,
+				Origin:         requestErrorOrigin(err),
 				Error: schemas.ErrorField{
 					Type:    Ptr(schemas.RequestCancelled),
 					Message: fmt.Sprintf("Request cancelled or timed out by context: %v", ctx.Err()),
@@ -450,8 +569,8 @@ func (provider *VertexProvider) handleVertexEmbedding(ctx context.Context, model
 				},
 			}
 		}
-		// Remove client from pool for non-context errors (could be auth/network issues)
-		removeVertexClient(key.VertexKeyConfig.AuthCredentials)
+		// Remove token source from cache for non-context errors (could be auth/network issues)
+		removeVertexTokenSource(key.VertexKeyConfig.AuthCredentials)
 		return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, schemas.Vertex)
 	}
 	defer resp.Body.Close()
@@ -463,9 +582,9 @@ func (provider *VertexProvider) handleVertexEmbedding(ctx context.Context, model
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		// Remove client from pool for authentication/authorization errors
+		// Remove token source from cache for authentication/authorization errors
 		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-			removeVertexClient(key.VertexKeyConfig.AuthCredentials)
+			removeVertexTokenSource(key.VertexKeyConfig.AuthCredentials)
 		}
 
 		// Try to parse Vertex's error format
@@ -606,7 +725,8 @@ func (provider *VertexProvider) convertVertexEmbeddingResponse(vertexResponse ma
 }
 
 // ChatCompletionStream performs a streaming chat completion request to the Vertex API.
-// It supports both OpenAI-style streaming (for non-Claude models) and Anthropic-style streaming (for Claude models).
+// It uses native Gemini streaming for Gemini models, Anthropic-style streaming for Claude models and
+// OpenAI-style streaming for all other models.
 // Returns a channel of BifrostResponse objects for streaming results or an error if the request fails.
 func (provider *VertexProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	if key.VertexKeyConfig == nil {
@@ -623,15 +743,42 @@ func (provider *VertexProvider) ChatCompletionStream(ctx context.Context, postHo
 		return nil, newConfigurationError("region is not set in key config", schemas.Vertex)
 	}
 
-	client, err := getAuthClient(key)
+	client, err := provider.getAuthClient(key)
 	if err != nil {
-		// Remove client from pool if auth client creation fails
-		removeVertexClient(key.VertexKeyConfig.AuthCredentials)
+		// Remove token source from cache if its creation fails
+		removeVertexTokenSource(key.VertexKeyConfig.AuthCredentials)
 		return nil, newBifrostOperationError("error creating auth client", err, schemas.Vertex)
 	}
 
-	if strings.Contains(model, "claude") {
+	if isVertexGeminiModel(model) {
+		// Use the native Gemini streaming for Gemini models
+		url := fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/google/models/%s:streamGenerateContent?alt=sse", vertexBaseURL(region), projectID, region, model)
+
+		headers := map[string]string{
+			"Content-Type":  "application/json",
+			"Accept":        "text/event-stream",
+			"Cache-Control": "no-cache",
+		}
+
+		// Use shared Gemini streaming logic
+		return handleGeminiStreaming(
+			ctx,
+			client,
+			url,
+			prepareGeminiGenerationRequest(messages, params, nil),
+			headers,
+			provider.networkConfig.ExtraHeaders,
+			schemas.Vertex,
+			model,
+			params,
+			postHookRunner,
+			provider.logger,
+		)
+	} else if strings.Contains(model, "claude") {
 		// Use Anthropic-style streaming for Claude models
+		if err := checkAnthropicTools(params, schemas.Vertex); err != nil {
+			return nil, err
+		}
 		formattedMessages, preparedParams := prepareAnthropicChatRequest(messages, params)
 
 		requestBody := mergeConfig(map[string]interface{}{
@@ -646,7 +793,7 @@ func (provider *VertexProvider) ChatCompletionStream(ctx context.Context, postHo
 		delete(requestBody, "model")
 		delete(requestBody, "region")
 
-		url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/anthropic/models/%s:streamRawPredict", region, projectID, region, model)
+		url := fmt.Sprintf("%s/v1/projects/%s/locations/%s/publishers/anthropic/models/%s:streamRawPredict", vertexBaseURL(region), projectID, region, model)
 
 		// Prepare headers for Vertex Anthropic
 		headers := map[string]string{
@@ -680,7 +827,7 @@ func (provider *VertexProvider) ChatCompletionStream(ctx context.Context, postHo
 
 		delete(requestBody, "region")
 
-		url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1beta1/projects/%s/locations/%s/endpoints/openapi/chat/completions", region, projectID, region)
+		url := fmt.Sprintf("%s/v1beta1/projects/%s/locations/%s/endpoints/openapi/chat/completions", vertexBaseURL(region), projectID, region)
 
 		// Prepare headers for Vertex OpenAI-compatible
 		headers := map[string]string{
@@ -720,3 +867,27 @@ func (provider *VertexProvider) Transcription(ctx context.Context, model string,
 func (provider *VertexProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
 	return nil, newUnsupportedOperationError("transcription stream", "vertex")
 }
+
+func (provider *VertexProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "vertex")
+}
+
+func (provider *VertexProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "vertex")
+}
+
+func (provider *VertexProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "vertex")
+}
+
+func (provider *VertexProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "vertex")
+}
+
+func (provider *VertexProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "vertex")
+}
+
+func (provider *VertexProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "vertex")
+}
@@ -0,0 +1,694 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the IBM watsonx.ai provider implementation.
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// watsonxIAMTokenURL is the IBM Cloud IAM endpoint exchanging API keys for bearer tokens.
+	watsonxIAMTokenURL = "https://iam.cloud.ibm.com/identity/token"
+	// watsonxAPIVersion is the date version of the watsonx.ai API the requests are sent with.
+	watsonxAPIVersion = "2024-05-01"
+
+	watsonxChatPath       = "/ml/v1/text/chat"
+	watsonxChatStreamPath = "/ml/v1/text/chat_stream"
+	watsonxGenerationPath = "/ml/v1/text/generation"
+	watsonxEmbeddingPath  = "/ml/v1/text/embeddings"
+
+	// watsonxTokenRefreshMargin is how long before their expiry cached IAM tokens are exchanged
+	// again. IAM tokens are valid for an hour.
+	watsonxTokenRefreshMargin = 5 * time.Minute
+)
+
+// WatsonxError represents an error of the watsonx.ai API.
+type WatsonxError struct {
+	Errors []struct {
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+		MoreInfo string `json:"more_info,omitempty"`
+	} `json:"errors"`
+	Trace      string `json:"trace"`
+	StatusCode int    `json:"status_code"`
+}
+
+// WatsonxGenerationResponse represents a text generation response of the watsonx.ai API.
+type WatsonxGenerationResponse struct {
+	ModelID   string `json:"model_id"`
+	CreatedAt string `json:"created_at"`
+	Results   []struct {
+		GeneratedText       string `json:"generated_text"`
+		GeneratedTokenCount int    `json:"generated_token_count"`
+		InputTokenCount     int    `json:"input_token_count"`
+		StopReason          string `json:"stop_reason"`
+	} `json:"results"`
+}
+
+// WatsonxEmbeddingResponse represents an embedding response of the watsonx.ai API.
+type WatsonxEmbeddingResponse struct {
+	ModelID string `json:"model_id"`
+	Results []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"results"`
+	InputTokenCount int `json:"input_token_count"`
+}
+
+// watsonxTokenResponse represents the response of the IAM endpoint exchanging API keys for
+// bearer tokens.
+type watsonxTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int64  `json:"expires_in"` // Lifetime of the token in seconds
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+// watsonxToken is a bearer token exchanged for an API key, cached until shortly before it expires.
+type watsonxToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// WatsonxProvider implements the Provider interface for IBM watsonx.ai. Keys have the form
+// "{api_key}:{project_id}"; the IBM Cloud API key is exchanged with IAM for a bearer token,
+// which is cached until shortly before it expires and exchanged again when watsonx rejects it,
+// and requests run in the project. A project_id or space_id set via ExtraParams takes
+// precedence over the project of the key. The base URL is the regional watsonx.ai endpoint,
+// us-south by default.
+type WatsonxProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	streamClient        *http.Client          // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+
+	tokenMu sync.Mutex               // Serializes token exchanges, so concurrent requests share one
+	tokens  map[string]*watsonxToken // API key -> bearer token
+}
+
+// NewWatsonxProvider creates a new watsonx.ai provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewWatsonxProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*WatsonxProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://us-south.ml.cloud.ibm.com"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &WatsonxProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+		tokens:              make(map[string]*watsonxToken),
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for watsonx.ai.
+func (provider *WatsonxProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Watsonx
+}
+
+// WarmUp opens connections to the watsonx.ai API ahead of the first request, and exchanges the
+// API key of the key for its first bearer token.
+func (provider *WatsonxProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	if err := warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL); err != nil {
+		return err
+	}
+	if _, bifrostErr := provider.accessToken(ctx, key); bifrostErr != nil {
+		return fmt.Errorf("%s", bifrostErr.Error.Message)
+	}
+	return nil
+}
+
+// TextCompletion generates text with the watsonx.ai generation API. ModelParameters are mapped
+// to watsonx's generation parameters: max_tokens to max_new_tokens, a positive frequency
+// penalty to a repetition_penalty between 1 and 2, and the decoding_method is sample when a
+// temperature, top_p or top_k is set and greedy otherwise. decoding_method, repetition_penalty
+// and other generation parameters, such as min_new_tokens or random_seed, can be set directly
+// via ExtraParams.
+func (provider *WatsonxProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	requestBody, bifrostErr := watsonxRequestBody(key, model, params)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+	requestBody["input"] = text
+	requestBody["parameters"] = prepareWatsonxGenerationParameters(params)
+
+	responseBody, bifrostErr := provider.completeRequest(ctx, key, watsonxGenerationPath, requestBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var response WatsonxGenerationResponse
+	rawResponse, bifrostErr := handleProviderResponse(responseBody, &response, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	choices := make([]schemas.BifrostResponseChoice, 0, len(response.Results))
+	usage := &schemas.LLMUsage{}
+	for i, result := range response.Results {
+		choices = append(choices, schemas.BifrostResponseChoice{
+			Index: i,
+			BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
+				Message: schemas.BifrostMessage{
+					Role: schemas.ModelChatMessageRoleAssistant,
+					Content: schemas.MessageContent{
+						ContentStr: Ptr(result.GeneratedText),
+					},
+				},
+			},
+			FinishReason: Ptr(mapWatsonxStopReason(result.StopReason)),
+		})
+		usage.PromptTokens += result.InputTokenCount
+		usage.CompletionTokens += result.GeneratedTokenCount
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+
+	bifrostResponse := &schemas.BifrostResponse{
+		Object:  "text_completion",
+		Choices: choices,
+		Usage:   usage,
+		Model:   model,
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Watsonx,
+		},
+	}
+	if createdAt, err := time.Parse(time.RFC3339, response.CreatedAt); err == nil {
+		bifrostResponse.Created = int(createdAt.Unix())
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// ChatCompletion performs a chat completion request to the watsonx.ai chat API, whose
+// messages, tools and responses follow the OpenAI format.
+func (provider *WatsonxProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	requestBody, bifrostErr := prepareWatsonxChatRequest(key, model, messages, params)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	responseBody, bifrostErr := provider.completeRequest(ctx, key, watsonxChatPath, requestBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	response := &schemas.BifrostResponse{}
+	rawResponse, bifrostErr := handleProviderResponse(responseBody, response, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	if response.Model == "" {
+		response.Model = model
+	}
+	response.ExtraFields.Provider = schemas.Watsonx
+
+	if provider.sendBackRawResponse {
+		response.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		response.ExtraFields.Params = *params
+	}
+
+	return response, nil
+}
+
+// Embedding generates embeddings for the given input text(s) using watsonx.ai's embedding
+// models, e.g. ibm/slate-125m-english-rtrvr. Texts longer than the model's context fail unless
+// truncate_input_tokens is set via ExtraParams.
+func (provider *WatsonxProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	texts := input.Texts
+	if input.Text != nil {
+		texts = []string{*input.Text}
+	}
+	if len(texts) == 0 {
+		return nil, newBifrostOperationError("invalid embedding input: at least one text is required", nil, schemas.Watsonx)
+	}
+
+	requestBody, bifrostErr := watsonxRequestBody(key, model, params)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+	requestBody["inputs"] = texts
+	if params != nil {
+		if parameters := withoutWatsonxScope(params.ExtraParams); len(parameters) > 0 {
+			requestBody["parameters"] = parameters
+		}
+	}
+
+	responseBody, bifrostErr := provider.completeRequest(ctx, key, watsonxEmbeddingPath, requestBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	var response WatsonxEmbeddingResponse
+	rawResponse, bifrostErr := handleProviderResponse(responseBody, &response, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	embeddings := make([]schemas.BifrostEmbedding, 0, len(response.Results))
+	for i, result := range response.Results {
+		embedding := result.Embedding
+		embeddings = append(embeddings, schemas.BifrostEmbedding{
+			Index:  i,
+			Object: "embedding",
+			Embedding: schemas.BifrostEmbeddingResponse{
+				EmbeddingArray: &embedding,
+			},
+		})
+	}
+
+	bifrostResponse := &schemas.BifrostResponse{
+		Object: "list",
+		Data:   embeddings,
+		Model:  model,
+		Usage: &schemas.LLMUsage{
+			PromptTokens: response.InputTokenCount,
+			TotalTokens:  response.InputTokenCount,
+		},
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: schemas.Watsonx,
+		},
+	}
+
+	if provider.sendBackRawResponse {
+		bifrostResponse.ExtraFields.RawResponse = rawResponse
+	}
+
+	if params != nil {
+		bifrostResponse.ExtraFields.Params = *params
+	}
+
+	return bifrostResponse, nil
+}
+
+// ChatCompletionStream performs a streaming chat completion request to the watsonx.ai chat API.
+// It supports real-time streaming of responses using Server-Sent Events (SSE) in the OpenAI
+// chunk format; the last chunk carries the usage of the whole response.
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+func (provider *WatsonxProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	requestBody, bifrostErr := prepareWatsonxChatRequest(key, model, messages, params)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Watsonx)
+	}
+
+	resp, bifrostErr := provider.openStream(ctx, key, watsonxChatStreamPath, jsonBody)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	return streamOpenAIResponse(ctx, resp, schemas.Watsonx, params, postHookRunner, provider.logger, nil, nil), nil
+}
+
+func (provider *WatsonxProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "watsonx")
+}
+
+func (provider *WatsonxProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "watsonx")
+}
+
+func (provider *WatsonxProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "watsonx")
+}
+
+func (provider *WatsonxProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "watsonx")
+}
+
+func (provider *WatsonxProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "watsonx")
+}
+
+func (provider *WatsonxProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "watsonx")
+}
+
+func (provider *WatsonxProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "watsonx")
+}
+
+func (provider *WatsonxProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "watsonx")
+}
+
+func (provider *WatsonxProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "watsonx")
+}
+
+func (provider *WatsonxProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "watsonx")
+}
+
+// completeRequest sends a JSON request to a path of the watsonx.ai API with the bearer token of
+// the key and returns the body of a successful response. A request rejected because its token
+// is invalid or expired is sent again once, with a newly exchanged token.
+func (provider *WatsonxProvider) completeRequest(ctx context.Context, key schemas.Key, path string, requestBody map[string]interface{}) ([]byte, *schemas.BifrostError) {
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Watsonx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, bifrostErr := provider.accessToken(ctx, key)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+
+		// Set any extra headers from network config
+		setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+		req.SetRequestURI(provider.networkConfig.BaseURL + path + "?version=" + watsonxAPIVersion)
+		req.Header.SetMethod("POST")
+		req.Header.SetContentType("application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.SetBody(jsonBody)
+
+		bifrostErr = makeRequestWithContext(ctx, provider.client, req, resp)
+		statusCode := resp.StatusCode()
+		// Copy the body, the response is released below
+		body := append([]byte(nil), resp.Body()...)
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		if statusCode != fasthttp.StatusOK {
+			provider.logger.Debug(fmt.Sprintf("error from watsonx provider: %s", string(body)))
+			if attempt == 0 && provider.forgetRejectedToken(key, statusCode) {
+				continue
+			}
+			return nil, newWatsonxError(body, statusCode)
+		}
+
+		return body, nil
+	}
+}
+
+// openStream sends a streaming request to a path of the watsonx.ai API and returns the response
+// streaming its chunks. As in completeRequest, a request whose token is rejected is sent again
+// once.
+func (provider *WatsonxProvider) openStream(ctx context.Context, key schemas.Key, path string, jsonBody []byte) (*http.Response, *schemas.BifrostError) {
+	if bifrostErr := checkRequestBody(ctx, jsonBody); bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, bifrostErr := provider.accessToken(ctx, key)
+		if bifrostErr != nil {
+			return nil, bifrostErr
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", provider.networkConfig.BaseURL+path+"?version="+watsonxAPIVersion, bytes.NewReader(jsonBody))
+		if err != nil {
+			return nil, newBifrostOperationError("error creating request", err, schemas.Watsonx)
+		}
+
+		// Set any extra headers from network config
+		setExtraHeadersHTTP(req, provider.networkConfig.ExtraHeaders, nil)
+
+		// Set headers
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Cache-Control", "no-cache")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := provider.streamClient.Do(req)
+		if err != nil {
+			return nil, newBifrostOperationError(schemas.ErrProviderRequest, err, schemas.Watsonx)
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		provider.logger.Debug(fmt.Sprintf("error from watsonx provider: %s", string(body)))
+		if attempt == 0 && provider.forgetRejectedToken(key, resp.StatusCode) {
+			continue
+		}
+		return nil, newWatsonxError(body, resp.StatusCode)
+	}
+}
+
+// accessToken returns a cached or newly exchanged IAM bearer token for the API key of a key.
+func (provider *WatsonxProvider) accessToken(ctx context.Context, key schemas.Key) (string, *schemas.BifrostError) {
+	apiKey, _ := splitWatsonxKey(key)
+	if apiKey == "" {
+		return "", newConfigurationError("watsonx key must be of the form {api_key}:{project_id}", schemas.Watsonx)
+	}
+
+	provider.tokenMu.Lock()
+	defer provider.tokenMu.Unlock()
+
+	now := time.Now()
+	if token, ok := provider.tokens[apiKey]; ok && now.Add(watsonxTokenRefreshMargin).Before(token.expiresAt) {
+		return token.value, nil
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	form := url.Values{
+		"grant_type": {"urn:ibm:params:oauth:grant-type:apikey"},
+		"apikey":     {apiKey},
+	}
+	req.SetRequestURI(watsonxIAMTokenURL)
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBodyString(form.Encode())
+
+	if bifrostErr := makeRequestWithContext(ctx, provider.client, req, resp); bifrostErr != nil {
+		return "", bifrostErr
+	}
+
+	var tokenResp watsonxTokenResponse
+	if err := sonic.Unmarshal(resp.Body(), &tokenResp); err != nil {
+		return "", newBifrostOperationError(schemas.ErrProviderResponseUnmarshal, err, schemas.Watsonx)
+	}
+	if resp.StatusCode() != fasthttp.StatusOK || tokenResp.AccessToken == "" {
+		statusCode := resp.StatusCode()
+		if statusCode == fasthttp.StatusOK {
+			statusCode = fasthttp.StatusUnauthorized
+		}
+		message := fmt.Sprintf("failed to get watsonx IAM token: %s", tokenResp.ErrorCode)
+		if tokenResp.ErrorMessage != "" {
+			message += ": " + tokenResp.ErrorMessage
+		}
+		return "", newProviderAPIError(message, nil, statusCode, schemas.Watsonx, Ptr(tokenResp.ErrorCode), nil)
+	}
+
+	provider.tokens[apiKey] = &watsonxToken{
+		value:     tokenResp.AccessToken,
+		expiresAt: now.Add(time.Duration(tokenResp.ExpiresIn) * time.Second),
+	}
+	return tokenResp.AccessToken, nil
+}
+
+// forgetRejectedToken drops the cached token of a key if watsonx rejected it, and reports
+// whether the request should be sent again with a new token.
+func (provider *WatsonxProvider) forgetRejectedToken(key schemas.Key, statusCode int) bool {
+	if statusCode != http.StatusUnauthorized {
+		return false
+	}
+	apiKey, _ := splitWatsonxKey(key)
+	provider.tokenMu.Lock()
+	delete(provider.tokens, apiKey)
+	provider.tokenMu.Unlock()
+	return true
+}
+
+// splitWatsonxKey splits a key of the form "{api_key}:{project_id}" into its API key and
+// project. Keys without a project are API keys as they are.
+func splitWatsonxKey(key schemas.Key) (apiKey string, projectID string) {
+	apiKey, projectID, _ = strings.Cut(key.Value, ":")
+	return apiKey, projectID
+}
+
+// watsonxRequestBody returns the request body of a model with the project or deployment space
+// the request runs in: the project_id or space_id of ExtraParams, or the project of the key.
+func watsonxRequestBody(key schemas.Key, model string, params *schemas.ModelParameters) (map[string]interface{}, *schemas.BifrostError) {
+	requestBody := map[string]interface{}{
+		"model_id": model,
+	}
+	if params != nil {
+		for _, scope := range []string{"project_id", "space_id"} {
+			if id, ok := params.ExtraParams[scope]; ok {
+				requestBody[scope] = id
+				return requestBody, nil
+			}
+		}
+	}
+	_, projectID := splitWatsonxKey(key)
+	if projectID == "" {
+		return nil, newConfigurationError("watsonx requests need a project: use a key of the form {api_key}:{project_id} or set project_id or space_id", schemas.Watsonx)
+	}
+	requestBody["project_id"] = projectID
+	return requestBody, nil
+}
+
+// withoutWatsonxScope returns the extra parameters without the project and space they select,
+// which are sent at the top level of requests rather than with the parameters.
+func withoutWatsonxScope(extraParams map[string]interface{}) map[string]interface{} {
+	parameters := make(map[string]interface{}, len(extraParams))
+	for k, v := range extraParams {
+		if k != "project_id" && k != "space_id" {
+			parameters[k] = v
+		}
+	}
+	return parameters
+}
+
+// watsonxUnsupportedChatParams are the Bifrost parameters the watsonx chat API rejects.
+var watsonxUnsupportedChatParams = []string{"top_k", "user", "parallel_tool_calls", "encoding_format", "dimensions"}
+
+// prepareWatsonxChatRequest prepares the request body of watsonx chat requests. Messages and
+// tools are sent in the OpenAI format; stop sequences go in stop, and tool choices given as a
+// string, such as auto or required, go in tool_choice_option.
+func prepareWatsonxChatRequest(key schemas.Key, model string, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (map[string]interface{}, *schemas.BifrostError) {
+	requestBody, bifrostErr := watsonxRequestBody(key, model, params)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	formattedMessages, preparedParams := prepareOpenAIChatRequest(messages, params)
+	for _, name := range watsonxUnsupportedChatParams {
+		delete(preparedParams, name)
+	}
+	if stop, ok := preparedParams["stop_sequences"]; ok {
+		preparedParams["stop"] = stop
+		delete(preparedParams, "stop_sequences")
+	}
+	if toolChoice, ok := preparedParams["tool_choice"].(schemas.ToolChoice); ok && toolChoice.ToolChoiceStr != nil {
+		preparedParams["tool_choice_option"] = *toolChoice.ToolChoiceStr
+		delete(preparedParams, "tool_choice")
+	}
+
+	requestBody["messages"] = formattedMessages
+	return mergeConfig(requestBody, preparedParams), nil
+}
+
+// prepareWatsonxGenerationParameters maps ModelParameters to the parameters of the watsonx
+// generation API. Extra parameters are sent as generation parameters and take precedence.
+func prepareWatsonxGenerationParameters(params *schemas.ModelParameters) map[string]interface{} {
+	parameters := map[string]interface{}{
+		"decoding_method": "greedy",
+	}
+	if params == nil {
+		return parameters
+	}
+
+	if params.MaxTokens != nil {
+		parameters["max_new_tokens"] = *params.MaxTokens
+	}
+	if params.StopSequences != nil {
+		parameters["stop_sequences"] = *params.StopSequences
+	}
+	sampling := false
+	if params.Temperature != nil {
+		parameters["temperature"] = *params.Temperature
+		sampling = *params.Temperature > 0
+	}
+	if params.TopP != nil {
+		parameters["top_p"] = *params.TopP
+		sampling = true
+	}
+	if params.TopK != nil {
+		parameters["top_k"] = *params.TopK
+		sampling = true
+	}
+	if sampling {
+		parameters["decoding_method"] = "sample"
+	}
+	// watsonx penalizes repetitions with a factor from 1, no penalty, to 2, while frequency
+	// penalties go up to 2
+	if params.FrequencyPenalty != nil && *params.FrequencyPenalty > 0 {
+		parameters["repetition_penalty"] = 1 + min(*params.FrequencyPenalty, 2)/2
+	}
+
+	return mergeConfig(parameters, withoutWatsonxScope(params.ExtraParams))
+}
+
+// mapWatsonxStopReason maps the stop reasons of the watsonx generation API to finish reasons.
+func mapWatsonxStopReason(stopReason string) string {
+	switch stopReason {
+	case "eos_token", "stop_sequence":
+		return "stop"
+	case "max_tokens", "token_limit":
+		return "length"
+	}
+	return stopReason
+}
+
+// newWatsonxError converts a watsonx error response into a BifrostError with the code and
+// message of its first error.
+func newWatsonxError(body []byte, statusCode int) *schemas.BifrostError {
+	var errorResp WatsonxError
+	if err := sonic.Unmarshal(body, &errorResp); err != nil || len(errorResp.Errors) == 0 {
+		return newProviderAPIError(fmt.Sprintf("HTTP error from watsonx: %d", statusCode), fmt.Errorf("%s", string(body)), statusCode, schemas.Watsonx, nil, nil)
+	}
+	bifrostErr := newProviderAPIError(errorResp.Errors[0].Message, nil, statusCode, schemas.Watsonx, nil, nil)
+	bifrostErr.Error.Code = Ptr(errorResp.Errors[0].Code)
+	return bifrostErr
+}
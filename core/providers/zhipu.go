@@ -0,0 +1,550 @@
+// Package providers implements various LLM providers and their utility functions.
+// This file contains the Zhipu AI (GLM) provider implementation.
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// zhipuTokenTTL is the lifetime of the tokens signed from Zhipu API keys.
+	zhipuTokenTTL = 30 * time.Minute
+	// zhipuTokenRefreshMargin is how long before their expiry cached tokens are signed again.
+	zhipuTokenRefreshMargin = 5 * time.Minute
+)
+
+// ZhipuWebSearchResult is a web source of a response to a request with the web_search tool.
+type ZhipuWebSearchResult struct {
+	Title       string `json:"title,omitempty"`
+	Content     string `json:"content,omitempty"` // Excerpt of the page
+	Link        string `json:"link"`
+	Media       string `json:"media,omitempty"` // Name of the site
+	Icon        string `json:"icon,omitempty"`
+	Refer       string `json:"refer,omitempty"` // Reference marker, e.g. ref_1
+	PublishDate string `json:"publish_date,omitempty"`
+}
+
+// ZhipuError represents an error response of the Zhipu API.
+type ZhipuError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// zhipuToken is a token signed from an API key, cached until shortly before it expires.
+type zhipuToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// ZhipuProvider implements the Provider interface for Zhipu AI's open platform, which serves the
+// GLM models through an OpenAI-compatible API. API keys of the form "{id}.{secret}" are signed
+// into short-lived JWTs, other keys are sent as they are. The web_search tool is sent as GLM's
+// hosted web_search tool and its sources are returned as the search results of the response;
+// GLM specific parameters, such as do_sample or thinking, go in ExtraParams.
+type ZhipuProvider struct {
+	logger              schemas.Logger        // Logger for provider operations
+	client              *fasthttp.Client      // HTTP client for API requests
+	streamClient        *http.Client          // HTTP client for streaming requests
+	networkConfig       schemas.NetworkConfig // Network configuration including extra headers
+	sendBackRawResponse bool                  // Whether to include raw response in BifrostResponse
+	tokens              sync.Map              // SHA-256 of the API key -> *zhipuToken
+}
+
+// NewZhipuProvider creates a new Zhipu provider instance.
+// It initializes the HTTP client with the provided configuration.
+// The client is configured with timeouts, concurrency limits, and optional proxy settings.
+func NewZhipuProvider(config *schemas.ProviderConfig, logger schemas.Logger) (*ZhipuProvider, error) {
+	config.CheckAndSetDefaults()
+
+	client := &fasthttp.Client{
+		ReadTimeout:     time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		WriteTimeout:    time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+		MaxConnsPerHost: config.ConcurrencyAndBufferSize.BufferSize,
+	}
+
+	// Initialize streaming HTTP client
+	streamClient := &http.Client{
+		Timeout: time.Second * time.Duration(config.NetworkConfig.DefaultRequestTimeoutInSeconds),
+	}
+
+	// Configure proxy if provided
+	client = configureProxy(client, config.ProxyConfig, logger)
+
+	// Apply the HTTP client profile if provided
+	client = configureHTTPClientProfile(client, config.HTTPClientProfile, logger)
+	streamClient = configureNetHTTPClientProfile(streamClient, config.HTTPClientProfile, logger)
+
+	// Set default BaseURL if not provided
+	if config.NetworkConfig.BaseURL == "" {
+		config.NetworkConfig.BaseURL = "https://open.bigmodel.cn/api/paas/v4"
+	}
+	config.NetworkConfig.BaseURL = strings.TrimRight(config.NetworkConfig.BaseURL, "/")
+
+	return &ZhipuProvider{
+		logger:              logger,
+		client:              client,
+		streamClient:        streamClient,
+		networkConfig:       config.NetworkConfig,
+		sendBackRawResponse: config.SendBackRawResponse,
+	}, nil
+}
+
+// GetProviderKey returns the provider identifier for Zhipu.
+func (provider *ZhipuProvider) GetProviderKey() schemas.ModelProvider {
+	return schemas.Zhipu
+}
+
+// WarmUp opens connections to the Zhipu API ahead of the first request.
+func (provider *ZhipuProvider) WarmUp(ctx context.Context, key schemas.Key) error {
+	return warmUpConnections(ctx, provider.client, provider.streamClient, provider.networkConfig.BaseURL)
+}
+
+// TextCompletion is not supported by the Zhipu provider.
+func (provider *ZhipuProvider) TextCompletion(ctx context.Context, model string, key schemas.Key, text string, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("text completion", "zhipu")
+}
+
+// ChatCompletion performs a chat completion request to the Zhipu API.
+// The reasoning_content of thinking models is mapped to the thought of the assistant message, and
+// the web sources of requests with the web_search tool onto the search results of the response.
+// Tool calls of GLM's hosted tools are dropped, as they already ran.
+func (provider *ZhipuProvider) ChatCompletion(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	token, bifrostErr := provider.authToken(key)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	formattedMessages, preparedParams := prepareZhipuChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+	}, preparedParams)
+
+	jsonBody, err := sonic.Marshal(requestBody)
+	if err != nil {
+		return nil, newBifrostOperationError(schemas.ErrProviderJSONMarshaling, err, schemas.Zhipu)
+	}
+
+	// Create request
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	// Set any extra headers from network config
+	setExtraHeaders(req, provider.networkConfig.ExtraHeaders, nil)
+
+	req.SetRequestURI(provider.networkConfig.BaseURL + "/chat/completions")
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	req.SetBody(jsonBody)
+
+	// Make request
+	bifrostErr = makeRequestWithContext(ctx, provider.client, req, resp)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	// Handle error response
+	if resp.StatusCode() != fasthttp.StatusOK {
+		provider.logger.Debug(fmt.Sprintf("error from zhipu provider: %s", string(resp.Body())))
+		return nil, parseZhipuError(resp)
+	}
+
+	responseBody := resp.Body()
+
+	// Parse and map reasoning_content to thought
+	response, bifrostErr := parseReasoningChatResponse(responseBody, schemas.Zhipu, params, provider.sendBackRawResponse)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	for i := range response.Choices {
+		if response.Choices[i].BifrostNonStreamResponseChoice == nil {
+			continue
+		}
+		message := &response.Choices[i].BifrostNonStreamResponseChoice.Message
+		if message.AssistantMessage != nil && message.AssistantMessage.ToolCalls != nil {
+			toolCalls := zhipuFunctionToolCalls(*message.AssistantMessage.ToolCalls)
+			if len(toolCalls) > 0 {
+				message.AssistantMessage.ToolCalls = &toolCalls
+			} else {
+				message.AssistantMessage.ToolCalls = nil
+			}
+		}
+	}
+
+	var fields struct {
+		WebSearch []ZhipuWebSearchResult `json:"web_search,omitempty"`
+	}
+	if err := sonic.Unmarshal(responseBody, &fields); err != nil {
+		provider.logger.Warn(fmt.Sprintf("Failed to parse zhipu web search results: %v", err))
+	} else {
+		response.SearchResults = zhipuSearchResults(fields.WebSearch)
+	}
+
+	return response, nil
+}
+
+// Embedding generates embeddings for the given input text(s) using Zhipu's embeddings endpoint,
+// e.g. with embedding-3.
+func (provider *ZhipuProvider) Embedding(ctx context.Context, model string, key schemas.Key, input *schemas.EmbeddingInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	token, bifrostErr := provider.authToken(key)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	requestBody := prepareOpenAIEmbeddingRequest(input, params)
+	requestBody["model"] = model
+
+	// The shared handler sends the key value as a bearer token
+	signedKey := key
+	signedKey.Value = token
+
+	return handleOpenAIEmbeddingRequest(
+		ctx,
+		provider.client,
+		provider.networkConfig.BaseURL+"/embeddings",
+		requestBody,
+		signedKey,
+		params,
+		provider.networkConfig.ExtraHeaders,
+		schemas.Zhipu,
+		provider.sendBackRawResponse,
+		provider.logger,
+	)
+}
+
+// ChatCompletionStream performs a streaming chat completion request to the Zhipu API.
+// It supports real-time streaming of responses using Server-Sent Events (SSE).
+// Uses Zhipu's OpenAI-compatible streaming format. The web sources of requests with the
+// web_search tool are forwarded once, on the first chunk with content after they arrive.
+// Returns a channel containing BifrostResponse objects representing the stream or an error if the request fails.
+func (provider *ZhipuProvider) ChatCompletionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	token, bifrostErr := provider.authToken(key)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+
+	formattedMessages, preparedParams := prepareZhipuChatRequest(messages, params)
+
+	requestBody := mergeConfig(map[string]interface{}{
+		"model":    model,
+		"messages": formattedMessages,
+		"stream":   true,
+	}, preparedParams)
+
+	// Prepare Zhipu headers
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + token,
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+
+	// Web sources arrive in a chunk of their own, which may carry no content and not be sent
+	var pendingSearchResults []schemas.SearchResult
+	sentSearchResults := false
+	chunkHook := func(rawChunk map[string]interface{}, response *schemas.BifrostResponse) {
+		for i := range response.Choices {
+			if response.Choices[i].BifrostStreamResponseChoice == nil {
+				continue
+			}
+			delta := &response.Choices[i].BifrostStreamResponseChoice.Delta
+			if len(delta.ToolCalls) > 0 {
+				delta.ToolCalls = zhipuFunctionToolCalls(delta.ToolCalls)
+			}
+		}
+
+		if sentSearchResults {
+			return
+		}
+		if pendingSearchResults == nil {
+			webSearch, err := parseZhipuWebSearch(rawChunk)
+			if err != nil {
+				provider.logger.Warn(fmt.Sprintf("Failed to parse zhipu web search results: %v", err))
+				return
+			}
+			pendingSearchResults = zhipuSearchResults(webSearch)
+		}
+		if len(pendingSearchResults) > 0 && zhipuStreamChunkHasContent(response) {
+			response.SearchResults = pendingSearchResults
+			sentSearchResults = true
+		}
+	}
+
+	// Use shared OpenAI-compatible streaming logic
+	return handleOpenAIStreamingWithHook(
+		ctx,
+		provider.streamClient,
+		provider.networkConfig.BaseURL+"/chat/completions",
+		requestBody,
+		headers,
+		provider.networkConfig.ExtraHeaders,
+		schemas.Zhipu,
+		params,
+		postHookRunner,
+		provider.logger,
+		chunkHook,
+		nil,
+	)
+}
+
+func (provider *ZhipuProvider) Speech(ctx context.Context, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech", "zhipu")
+}
+
+func (provider *ZhipuProvider) SpeechStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.SpeechInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("speech stream", "zhipu")
+}
+
+func (provider *ZhipuProvider) Transcription(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription", "zhipu")
+}
+
+func (provider *ZhipuProvider) TranscriptionStream(ctx context.Context, postHookRunner schemas.PostHookRunner, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("transcription stream", "zhipu")
+}
+
+func (provider *ZhipuProvider) Translation(ctx context.Context, model string, key schemas.Key, input *schemas.TranscriptionInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("translation", "zhipu")
+}
+
+func (provider *ZhipuProvider) ImageGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.ImageInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("image generation", "zhipu")
+}
+
+func (provider *ZhipuProvider) VideoGeneration(ctx context.Context, model string, key schemas.Key, input *schemas.VideoInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video generation", "zhipu")
+}
+
+func (provider *ZhipuProvider) VideoStatus(ctx context.Context, model string, key schemas.Key, input *schemas.VideoJobInput) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("video status", "zhipu")
+}
+
+func (provider *ZhipuProvider) CountTokens(ctx context.Context, model string, key schemas.Key, messages []schemas.BifrostMessage, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("token count", "zhipu")
+}
+
+func (provider *ZhipuProvider) Rerank(ctx context.Context, model string, key schemas.Key, input *schemas.RerankInput, params *schemas.ModelParameters) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	return nil, newUnsupportedOperationError("rerank", "zhipu")
+}
+
+// authToken returns the bearer token of an API key: a cached or newly signed JWT for keys of the
+// form "{id}.{secret}", and the key itself otherwise.
+func (provider *ZhipuProvider) authToken(key schemas.Key) (string, *schemas.BifrostError) {
+	id, secret, ok := strings.Cut(key.Value, ".")
+	if !ok || id == "" || secret == "" {
+		return key.Value, nil
+	}
+
+	// Tokens are cached under a hash of the key, so the cache does not hold the secrets
+	cacheKey := sha256.Sum256([]byte(key.Value))
+	now := time.Now()
+	if cached, ok := provider.tokens.Load(cacheKey); ok {
+		if token := cached.(*zhipuToken); now.Add(zhipuTokenRefreshMargin).Before(token.expiresAt) {
+			return token.value, nil
+		}
+	}
+
+	token, err := signZhipuToken(id, secret, now, zhipuTokenTTL)
+	if err != nil {
+		return "", newBifrostOperationError("failed to sign zhipu api token", err, schemas.Zhipu)
+	}
+	provider.evictExpiredTokens(now)
+	provider.tokens.Store(cacheKey, &zhipuToken{value: token, expiresAt: now.Add(zhipuTokenTTL)})
+	return token, nil
+}
+
+// evictExpiredTokens drops the cached tokens that expired, such as those of keys removed from
+// the account since they were signed.
+func (provider *ZhipuProvider) evictExpiredTokens(now time.Time) {
+	provider.tokens.Range(func(cacheKey, cached interface{}) bool {
+		if !now.Before(cached.(*zhipuToken).expiresAt) {
+			provider.tokens.CompareAndDelete(cacheKey, cached)
+		}
+		return true
+	})
+}
+
+// signZhipuToken signs the HS256 JWT Zhipu accepts in place of an API key. Unlike standard JWTs,
+// its header carries sign_type and its timestamps are in milliseconds.
+func signZhipuToken(id, secret string, now time.Time, ttl time.Duration) (string, error) {
+	header, err := sonic.Marshal(map[string]string{
+		"alg":       "HS256",
+		"sign_type": "SIGN",
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := sonic.Marshal(map[string]interface{}{
+		"api_key":   id,
+		"exp":       now.Add(ttl).UnixMilli(),
+		"timestamp": now.UnixMilli(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// prepareZhipuChatRequest formats a chat request in the OpenAI format, with the web_search tool
+// in GLM's format. GLM only supports the "auto" tool choice, so others are not sent.
+func prepareZhipuChatRequest(messages []schemas.BifrostMessage, params *schemas.ModelParameters) ([]map[string]interface{}, map[string]interface{}) {
+	// Built-in tools are converted below rather than into OpenAI's format
+	formattedMessages, _ := prepareOpenAIChatRequest(messages, nil)
+	preparedParams := prepareParams(params)
+
+	if params == nil {
+		return formattedMessages, preparedParams
+	}
+
+	if params.ToolChoice != nil && (params.ToolChoice.ToolChoiceStr == nil || *params.ToolChoice.ToolChoiceStr != string(schemas.ToolChoiceTypeAuto)) {
+		if _, exists := params.ExtraParams["tool_choice"]; !exists {
+			delete(preparedParams, "tool_choice")
+		}
+	}
+
+	if params.Tools != nil {
+		tools := make([]interface{}, 0, len(*params.Tools))
+		for _, tool := range *params.Tools {
+			if tool.WebSearch != nil {
+				tools = append(tools, zhipuWebSearchTool(tool.WebSearch))
+			} else if !isBuiltInTool(tool) {
+				tools = append(tools, tool)
+			}
+		}
+		// Explicit tools passed through ExtraParams take precedence
+		if _, exists := params.ExtraParams["tools"]; !exists {
+			if len(tools) > 0 {
+				preparedParams["tools"] = tools
+			} else {
+				delete(preparedParams, "tools")
+			}
+		}
+	}
+
+	return formattedMessages, preparedParams
+}
+
+// zhipuWebSearchTool converts the web search tool into GLM's hosted web_search tool, which
+// returns its sources with the response.
+func zhipuWebSearchTool(webSearch *schemas.WebSearchTool) map[string]interface{} {
+	options := map[string]interface{}{
+		"enable":        true,
+		"search_result": true,
+	}
+	if webSearch.SearchContextSize != nil {
+		// GLM only has medium and high content sizes
+		if *webSearch.SearchContextSize == "high" {
+			options["content_size"] = "high"
+		} else {
+			options["content_size"] = "medium"
+		}
+	}
+	return map[string]interface{}{
+		"type":       schemas.ToolTypeWebSearch,
+		"web_search": options,
+	}
+}
+
+// zhipuFunctionToolCalls returns the function tool calls of a GLM message, without the calls of
+// hosted tools such as web_search, which GLM already ran.
+func zhipuFunctionToolCalls(toolCalls []schemas.ToolCall) []schemas.ToolCall {
+	functionCalls := make([]schemas.ToolCall, 0, len(toolCalls))
+	for _, toolCall := range toolCalls {
+		if toolCall.Type == nil || *toolCall.Type == "function" {
+			functionCalls = append(functionCalls, toolCall)
+		}
+	}
+	return functionCalls
+}
+
+// zhipuStreamChunkHasContent reports whether a stream chunk carries something, so it is sent.
+func zhipuStreamChunkHasContent(response *schemas.BifrostResponse) bool {
+	if len(response.Choices) == 0 || response.Choices[0].BifrostStreamResponseChoice == nil {
+		return false
+	}
+	delta := response.Choices[0].BifrostStreamResponseChoice.Delta
+	return delta.Content != nil || len(delta.ToolCalls) > 0 || delta.Thought != nil
+}
+
+// parseZhipuWebSearch parses the web sources of a raw stream chunk, if it has them.
+func parseZhipuWebSearch(rawChunk map[string]interface{}) ([]ZhipuWebSearchResult, error) {
+	rawWebSearch, ok := rawChunk["web_search"]
+	if !ok || rawWebSearch == nil {
+		return nil, nil
+	}
+	encoded, err := sonic.Marshal(rawWebSearch)
+	if err != nil {
+		return nil, err
+	}
+	var webSearch []ZhipuWebSearchResult
+	if err := sonic.Unmarshal(encoded, &webSearch); err != nil {
+		return nil, err
+	}
+	return webSearch, nil
+}
+
+// zhipuSearchResults converts the web sources of a response into search results, in the order of
+// GLM, which matches their ref_n markers.
+func zhipuSearchResults(webSearch []ZhipuWebSearchResult) []schemas.SearchResult {
+	if len(webSearch) == 0 {
+		return nil
+	}
+	searchResults := make([]schemas.SearchResult, 0, len(webSearch))
+	for _, result := range webSearch {
+		if result.Link == "" {
+			continue
+		}
+		searchResult := schemas.SearchResult{URL: result.Link}
+		if result.Title != "" {
+			searchResult.Title = Ptr(result.Title)
+		}
+		if result.Content != "" {
+			searchResult.Snippet = Ptr(result.Content)
+		}
+		if result.PublishDate != "" {
+			searchResult.Date = Ptr(result.PublishDate)
+		}
+		searchResults = append(searchResults, searchResult)
+	}
+	return searchResults
+}
+
+// parseZhipuError converts an error response of the Zhipu API into a BifrostError.
+func parseZhipuError(resp *fasthttp.Response) *schemas.BifrostError {
+	var errorResp ZhipuError
+	bifrostErr := handleProviderAPIError(resp, &errorResp)
+	if errorResp.Error.Message == "" {
+		bifrostErr.Error.Message = "Zhipu error: " + string(bytes.TrimSpace(resp.Body()))
+		return bifrostErr
+	}
+	bifrostErr.Error.Message = "Zhipu error: " + errorResp.Error.Message
+	if errorResp.Error.Code != "" {
+		bifrostErr.Error.Code = Ptr(errorResp.Error.Code)
+	}
+	return bifrostErr
+}
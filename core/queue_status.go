@@ -0,0 +1,215 @@
+package bifrost
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// QUEUE POSITION AND ETA FEEDBACK
+// ============================================================================
+
+const (
+	// queueStatusInterval is how often a waiting request reports its queue status.
+	// Requests dispatched within the first interval never report.
+	queueStatusInterval = 500 * time.Millisecond
+	// queueServiceTimeWeight is the weight of the latest sample in the service time average.
+	queueServiceTimeWeight = 0.2
+)
+
+// queueTracker follows a provider queue to estimate the position and wait of queued requests.
+// Positions are estimates: tickets are taken after a request enters the queue, so concurrent
+// senders may be numbered out of order.
+type queueTracker struct {
+	enqueued      atomic.Int64 // Tickets handed out to requests that entered the queue
+	dequeued      atomic.Int64 // Requests picked up by a worker
+	concurrency   atomic.Int64 // Number of workers
+	serviceTimeNs atomic.Int64 // Moving average of the time a worker spends on a request
+}
+
+// queueWait is shared between a waiting request and the worker that picks it up.
+type queueWait struct {
+	started atomic.Bool
+}
+
+// queueReporter reports the queue status of a waiting request to its callback.
+// A nil reporter is valid and reports nothing.
+type queueReporter struct {
+	callback   schemas.QueueStatusCallback
+	tracker    *queueTracker
+	provider   schemas.ModelProvider
+	wait       *queueWait
+	ticket     int64
+	enqueuedAt time.Time
+	ticker     *time.Ticker
+}
+
+// getQueueTracker returns the tracker of a provider queue, creating it if needed.
+func (bifrost *Bifrost) getQueueTracker(providerKey schemas.ModelProvider) *queueTracker {
+	tracker, _ := bifrost.queueTrackers.LoadOrStore(providerKey, &queueTracker{})
+	return tracker.(*queueTracker)
+}
+
+// observe adds the service time of a request to the moving average.
+func (t *queueTracker) observe(serviceTime time.Duration) {
+	for {
+		current := t.serviceTimeNs.Load()
+		next := int64(serviceTime)
+		if current > 0 {
+			next = int64(float64(current)*(1-queueServiceTimeWeight) + float64(serviceTime)*queueServiceTimeWeight)
+		}
+		if t.serviceTimeNs.CompareAndSwap(current, next) {
+			return
+		}
+	}
+}
+
+// getQueueStatusCallback returns the queue status callback set in the context, if any.
+func getQueueStatusCallback(ctx context.Context) schemas.QueueStatusCallback {
+	switch callback := ctx.Value(schemas.BifrostContextKeyQueueStatus).(type) {
+	case schemas.QueueStatusCallback:
+		return callback
+	case func(schemas.QueueStatus):
+		return callback
+	}
+	return nil
+}
+
+// newQueueReporter creates a reporter for msg if the context asks for queue status updates.
+func (bifrost *Bifrost) newQueueReporter(ctx context.Context, providerKey schemas.ModelProvider, msg *ChannelMessage) *queueReporter {
+	callback := getQueueStatusCallback(ctx)
+	if callback == nil {
+		return nil
+	}
+	msg.queueWait = &queueWait{}
+	return &queueReporter{
+		callback:   callback,
+		tracker:    bifrost.getQueueTracker(providerKey),
+		provider:   providerKey,
+		wait:       msg.queueWait,
+		enqueuedAt: time.Now(),
+		ticker:     time.NewTicker(queueStatusInterval),
+	}
+}
+
+// C returns the channel on which the reporter ticks, nil for a nil reporter.
+func (r *queueReporter) C() <-chan time.Time {
+	if r == nil {
+		return nil
+	}
+	return r.ticker.C
+}
+
+// entered records the ticket the request got when it entered the queue.
+func (r *queueReporter) entered(ticket int64) {
+	if r != nil {
+		r.ticket = ticket
+	}
+}
+
+// report calls the callback with the current status unless a worker already picked the request up.
+func (r *queueReporter) report() {
+	if r == nil || r.wait.started.Load() {
+		return
+	}
+
+	// Before entering the queue every queued request is ahead, afterwards only older tickets
+	ahead := r.tracker.enqueued.Load()
+	if r.ticket > 0 {
+		ahead = r.ticket - 1
+	}
+	position := int(max(0, ahead-r.tracker.dequeued.Load()))
+
+	status := schemas.QueueStatus{
+		Provider: r.provider,
+		Position: position,
+		WaitedMs: time.Since(r.enqueuedAt).Milliseconds(),
+	}
+	if serviceTime, concurrency := r.tracker.serviceTimeNs.Load(), r.tracker.concurrency.Load(); serviceTime > 0 && concurrency > 0 {
+		wait := time.Duration(serviceTime * int64(position+1) / concurrency)
+		status.EstimatedWaitMs = Ptr(wait.Milliseconds())
+	}
+	r.callback(status)
+}
+
+// stop releases the reporter's ticker.
+func (r *queueReporter) stop() {
+	if r != nil {
+		r.ticker.Stop()
+	}
+}
+
+// enqueueRequest sends msg to the provider queue, honouring dropExcessRequests and reporting
+// the queue status while it waits for space.
+func (bifrost *Bifrost) enqueueRequest(ctx context.Context, queue chan ChannelMessage, msg *ChannelMessage, reporter *queueReporter) *schemas.BifrostError {
+	if config := bifrost.fairQueuing.config.Load(); config != nil {
+		return bifrost.enqueueFairRequest(ctx, config, queue, msg, reporter)
+	}
+
+	tracker := bifrost.getQueueTracker(msg.Provider)
+
+	select {
+	case queue <- *msg:
+		// Message was sent successfully
+		reporter.entered(tracker.enqueued.Add(1))
+		return nil
+	case <-ctx.Done():
+		return newBifrostErrorFromMsg("request cancelled while waiting for queue space", schemas.ErrorOriginClientRequest)
+	default:
+		if bifrost.dropExcessRequests.Load() {
+			bifrost.logger.Warn("Request dropped: queue is full, please increase the queue size or set dropExcessRequests to false")
+			return newBifrostErrorFromMsg("request dropped: queue is full", schemas.ErrorOriginBifrostInternal)
+		}
+	}
+
+	for {
+		select {
+		case queue <- *msg:
+			// Message was sent successfully
+			reporter.entered(tracker.enqueued.Add(1))
+			return nil
+		case <-ctx.Done():
+			return newBifrostErrorFromMsg("request cancelled while waiting for queue space", schemas.ErrorOriginClientRequest)
+		case <-reporter.C():
+			reporter.report()
+		}
+	}
+}
+
+// streamWithQueueStatus runs a stream request in the background and returns a stream that
+// carries queue status chunks while the request waits for capacity, followed by the
+// request's chunks, or by a single error chunk if every provider failed.
+func (bifrost *Bifrost) streamWithQueueStatus(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType) chan *schemas.BifrostStream {
+	outputStream := make(chan *schemas.BifrostStream, 1)
+
+	callback := getQueueStatusCallback(ctx)
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyQueueStatusEvents, false)
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyQueueStatus, schemas.QueueStatusCallback(func(status schemas.QueueStatus) {
+		if callback != nil {
+			callback(status)
+		}
+		// Status updates are best effort, a slow reader only misses some of them
+		select {
+		case outputStream <- &schemas.BifrostStream{QueueStatus: &status}:
+		default:
+		}
+	}))
+
+	go func() {
+		defer close(outputStream)
+
+		stream, bifrostErr := bifrost.handleStreamRequest(ctx, req, requestType)
+		if bifrostErr != nil {
+			outputStream <- &schemas.BifrostStream{BifrostError: bifrostErr}
+			return
+		}
+		for chunk := range stream {
+			outputStream <- chunk
+		}
+	}()
+
+	return outputStream
+}
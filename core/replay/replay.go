@@ -0,0 +1,245 @@
+// Package replay replays captured provider streams through Bifrost offline. A Trace holds the
+// raw Server-Sent Events a provider sent for a streaming chat request; Replay serves them from a
+// local server standing in for the provider and runs the request through a Bifrost client with
+// the given plugins, so the provider's chunk parsing, Bifrost's post-processing and the plugins'
+// hooks see exactly the captured chunks, every time, without reaching the provider. It is meant
+// for reproducing chunk-handling bugs in tests.
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// Trace is a captured provider stream.
+type Trace struct {
+	Provider schemas.ModelProvider    `json:"provider"`
+	Model    string                   `json:"model"`
+	Messages []schemas.BifrostMessage `json:"messages,omitempty"` // Messages of the request, a placeholder user message if empty
+	Params   *schemas.ModelParameters `json:"params,omitempty"`
+	// Status code of the provider's response, 200 if 0. Traces of failed requests replay the
+	// provider's error handling.
+	StatusCode int               `json:"status_code,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"` // Response headers, a text/event-stream content type if empty
+	// Raw events of the stream in order, such as "data: {...}", each written and flushed to the
+	// provider on its own.
+	Events []string `json:"events"`
+}
+
+// Config configures a replay.
+type Config struct {
+	Plugins []schemas.Plugin // Plugins the stream runs through, in order. Their Cleanup is called when the replay ends.
+	Logger  schemas.Logger   // Optional, Bifrost's default logger if nil
+	// Include the raw provider chunk in the extra fields of every chunk, to compare what the
+	// provider sent with what it was parsed into.
+	SendBackRawResponse bool
+}
+
+// Result is the outcome of a replay.
+type Result struct {
+	Chunks []*schemas.BifrostStream `json:"chunks"` // Chunks of the stream as a client would receive them, errors included
+	Error  *schemas.BifrostError    `json:"error,omitempty"`
+	// Request the provider sent for the replayed stream, to check what the plugins' pre-hooks
+	// changed.
+	ProviderRequest json.RawMessage `json:"provider_request,omitempty"`
+}
+
+// ParseSSE splits a raw Server-Sent Events stream into its events, dropping the blank lines
+// separating them.
+func ParseSSE(raw string) []string {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	var events []string
+	for _, event := range strings.Split(raw, "\n\n") {
+		if event = strings.Trim(event, "\n"); event != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// LoadTrace reads a trace from a file: a JSON Trace if the file has a .json extension, else a
+// raw Server-Sent Events stream, such as the output of curl -N, replayed for provider and model.
+func LoadTrace(path string, provider schemas.ModelProvider, model string) (*Trace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace: %w", err)
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return &Trace{Provider: provider, Model: model, Events: ParseSSE(string(data))}, nil
+	}
+
+	var trace Trace
+	if err := json.Unmarshal(data, &trace); err != nil {
+		return nil, fmt.Errorf("failed to decode trace: %w", err)
+	}
+	if trace.Provider == "" {
+		trace.Provider = provider
+	}
+	if trace.Model == "" {
+		trace.Model = model
+	}
+	return &trace, nil
+}
+
+// Replay runs the streaming chat request of a trace through a Bifrost client with the plugins
+// of config, serving the captured events in place of the provider, and collects the stream.
+// Providers that sign their requests (Bedrock and Vertex) cannot be replayed.
+func Replay(ctx context.Context, trace *Trace, config Config) (*Result, error) {
+	if trace == nil || trace.Provider == "" || trace.Model == "" {
+		return nil, errors.New("trace needs a provider and a model")
+	}
+	if trace.Provider == schemas.Bedrock || trace.Provider == schemas.Vertex {
+		return nil, fmt.Errorf("provider %s signs its requests and cannot be replayed", trace.Provider)
+	}
+
+	server, err := startTraceServer(trace)
+	if err != nil {
+		return nil, err
+	}
+	defer server.close()
+
+	client, err := bifrost.Init(ctx, schemas.BifrostConfig{
+		Account: &replayAccount{provider: trace.Provider, baseURL: server.url, sendBackRawResponse: config.SendBackRawResponse},
+		Plugins: config.Plugins,
+		Logger:  config.Logger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize bifrost: %w", err)
+	}
+	defer client.Shutdown()
+
+	messages := trace.Messages
+	if len(messages) == 0 {
+		messages = []schemas.BifrostMessage{{
+			Role:    schemas.ModelChatMessageRoleUser,
+			Content: schemas.MessageContent{ContentStr: bifrost.Ptr("Replayed request")},
+		}}
+	}
+	stream, bifrostErr := client.ChatCompletionStreamRequest(ctx, &schemas.BifrostRequest{
+		Provider: trace.Provider,
+		Model:    trace.Model,
+		Input:    schemas.RequestInput{ChatCompletionInput: &messages},
+		Params:   trace.Params,
+	})
+
+	result := &Result{Error: bifrostErr}
+	if stream != nil {
+		for chunk := range stream {
+			result.Chunks = append(result.Chunks, chunk)
+		}
+	}
+	result.ProviderRequest = server.request()
+	return result, nil
+}
+
+// traceServer serves the events of a trace to every request, standing in for the provider.
+type traceServer struct {
+	trace  *Trace
+	server *http.Server
+	url    string
+
+	mu          sync.Mutex
+	lastRequest []byte
+}
+
+// startTraceServer starts serving a trace on a loopback port.
+func startTraceServer(trace *Trace) (*traceServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the trace server: %w", err)
+	}
+	server := &traceServer{
+		trace: trace,
+		url:   "http://" + listener.Addr().String(),
+	}
+	server.server = &http.Server{Handler: server}
+	go server.server.Serve(listener)
+	return server, nil
+}
+
+// ServeHTTP records the request and writes the events of the trace, flushing each one.
+func (s *traceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.lastRequest = body
+	s.mu.Unlock()
+
+	if len(s.trace.Headers) == 0 {
+		w.Header().Set("Content-Type", "text/event-stream")
+	}
+	for name, value := range s.trace.Headers {
+		w.Header().Set(name, value)
+	}
+	statusCode := s.trace.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	w.WriteHeader(statusCode)
+
+	flusher, _ := w.(http.Flusher)
+	for _, event := range s.trace.Events {
+		if _, err := io.WriteString(w, event+"\n\n"); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// request returns the body of the last request the provider sent, nil if it is not JSON.
+func (s *traceServer) request() json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !json.Valid(s.lastRequest) {
+		return nil
+	}
+	return bytes.Clone(s.lastRequest)
+}
+
+// close stops the trace server.
+func (s *traceServer) close() {
+	s.server.Close()
+}
+
+// replayAccount configures the provider of a trace to send its requests to the trace server.
+type replayAccount struct {
+	provider            schemas.ModelProvider
+	baseURL             string
+	sendBackRawResponse bool
+}
+
+func (a *replayAccount) GetConfiguredProviders() ([]schemas.ModelProvider, error) {
+	return []schemas.ModelProvider{a.provider}, nil
+}
+
+func (a *replayAccount) GetKeysForProvider(ctx *context.Context, providerKey schemas.ModelProvider) ([]schemas.Key, error) {
+	key := schemas.Key{ID: "replay", Value: "replay", Weight: 1}
+	if providerKey == schemas.Azure {
+		key.AzureKeyConfig = &schemas.AzureKeyConfig{Endpoint: a.baseURL}
+	}
+	return []schemas.Key{key}, nil
+}
+
+func (a *replayAccount) GetConfigForProvider(providerKey schemas.ModelProvider) (*schemas.ProviderConfig, error) {
+	networkConfig := schemas.DefaultNetworkConfig
+	networkConfig.BaseURL = a.baseURL
+	return &schemas.ProviderConfig{
+		NetworkConfig:            networkConfig,
+		ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		SendBackRawResponse:      a.sendBackRawResponse,
+	}, nil
+}
@@ -0,0 +1,275 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// PER-REQUEST POLICY OVERRIDES
+// ============================================================================
+
+// requestPolicy is the effective network policy of a request, the provider's network
+// config with the request's overrides applied.
+type requestPolicy struct {
+	timeout        time.Duration // Per-attempt timeout, 0 leaves it to the provider's HTTP client
+	maxRetries     int
+	backoffInitial time.Duration
+	backoffMax     time.Duration
+}
+
+// buildRequestPolicyLimits fills the unset limits with their defaults.
+func buildRequestPolicyLimits(limits *schemas.RequestPolicyLimits) schemas.RequestPolicyLimits {
+	result := schemas.RequestPolicyLimits{
+		MaxRetries:      schemas.DefaultMaxRequestRetries,
+		MaxRetryBackoff: schemas.DefaultMaxRequestRetryBackoff,
+		MaxFallbacks:    schemas.DefaultMaxRequestFallbacks,
+	}
+	if limits == nil {
+		return result
+	}
+	if limits.MaxRetries > 0 {
+		result.MaxRetries = limits.MaxRetries
+	}
+	if limits.MaxRetryBackoff > 0 {
+		result.MaxRetryBackoff = limits.MaxRetryBackoff
+	}
+	if limits.MaxFallbacks > 0 {
+		result.MaxFallbacks = limits.MaxFallbacks
+	}
+	return result
+}
+
+// getRequestPolicy resolves the effective policy of a request against the provider's config.
+// Overrides are clamped to the server-side limits and to the provider's request timeout: the
+// provider's HTTP client enforces DefaultRequestTimeoutInSeconds on every call, so a timeout
+// override can only shorten it, never extend it.
+func (bifrost *Bifrost) getRequestPolicy(config *schemas.ProviderConfig, params *schemas.ModelParameters) requestPolicy {
+	policy := requestPolicy{
+		maxRetries:     config.NetworkConfig.MaxRetries,
+		backoffInitial: config.NetworkConfig.RetryBackoffInitial,
+		backoffMax:     config.NetworkConfig.RetryBackoffMax,
+	}
+	if params == nil || params.RequestPolicy == nil {
+		return policy
+	}
+	overrides := params.RequestPolicy
+	limits := bifrost.requestPolicyLimits
+
+	if overrides.TimeoutInSeconds != nil && *overrides.TimeoutInSeconds > 0 {
+		timeout := *overrides.TimeoutInSeconds
+		if maxTimeout := config.NetworkConfig.DefaultRequestTimeoutInSeconds; maxTimeout > 0 && timeout > maxTimeout {
+			timeout = maxTimeout
+		}
+		policy.timeout = time.Duration(timeout) * time.Second
+	}
+	if overrides.MaxRetries != nil {
+		policy.maxRetries = max(0, min(*overrides.MaxRetries, limits.MaxRetries))
+	}
+	if overrides.RetryBackoffInitialMs != nil && *overrides.RetryBackoffInitialMs > 0 {
+		policy.backoffInitial = min(time.Duration(*overrides.RetryBackoffInitialMs)*time.Millisecond, limits.MaxRetryBackoff)
+	}
+	if overrides.RetryBackoffMaxMs != nil && *overrides.RetryBackoffMaxMs > 0 {
+		policy.backoffMax = min(time.Duration(*overrides.RetryBackoffMaxMs)*time.Millisecond, limits.MaxRetryBackoff)
+	}
+	policy.backoffMax = max(policy.backoffMax, policy.backoffInitial)
+	return policy
+}
+
+// getFallbacks returns the fallbacks to try for a request, the request's chain cut to its
+// max_fallbacks override, which is itself bounded by the server-side limit.
+func (bifrost *Bifrost) getFallbacks(req *schemas.BifrostRequest) []schemas.Fallback {
+	if req.Params == nil || req.Params.RequestPolicy == nil || req.Params.RequestPolicy.MaxFallbacks == nil {
+		return req.Fallbacks
+	}
+	maxFallbacks := max(0, min(*req.Params.RequestPolicy.MaxFallbacks, bifrost.requestPolicyLimits.MaxFallbacks))
+	if len(req.Fallbacks) > maxFallbacks {
+		return req.Fallbacks[:maxFallbacks]
+	}
+	return req.Fallbacks
+}
+
+// withAttemptTimeout derives the context of a single attempt, cancelled with
+// context.DeadlineExceeded as cause once timeout elapses. stop disarms the timeout, which
+// streams do once established so their chunks are not cut off, and cancel releases the
+// context once the attempt no longer needs it. The provider's own request timeout still
+// applies underneath, so timeout can only shorten an attempt.
+func withAttemptTimeout(ctx context.Context, timeout time.Duration) (attemptCtx context.Context, stop func(), cancel func()) {
+	if timeout <= 0 {
+		return ctx, func() {}, func() {}
+	}
+	attemptCtx, cancelCause := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(timeout, func() {
+		cancelCause(context.DeadlineExceeded)
+	})
+	return attemptCtx, func() { timer.Stop() }, func() {
+		timer.Stop()
+		cancelCause(context.Canceled)
+	}
+}
+
+// timeoutMiddleware wraps next, bounding each attempt by the request's timeout override. The
+// timeout of a stream is disarmed once the stream is established, and its context released
+// once the stream is closed.
+func (bifrost *Bifrost) timeoutMiddleware(next schemas.ProviderCaller) schemas.ProviderCaller {
+	return schemas.ProviderCallerFuncs{
+		CallFunc: func(call *schemas.ProviderCall) (*schemas.BifrostResponse, *schemas.BifrostError) {
+			timeout := bifrost.getRequestPolicy(call.Config, call.Params).timeout
+			attemptCtx, _, cancelAttempt := withAttemptTimeout(call.Context, timeout)
+			attempt := *call
+			attempt.Context = attemptCtx
+			result, bifrostErr := next.Call(&attempt)
+			cancelAttempt()
+			return result, attemptTimeoutError(call.Context, attemptCtx, timeout, bifrostErr)
+		},
+		CallStreamFunc: func(call *schemas.ProviderCall) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+			timeout := bifrost.getRequestPolicy(call.Config, call.Params).timeout
+			attemptCtx, stopTimeout, cancelAttempt := withAttemptTimeout(call.Context, timeout)
+			attempt := *call
+			attempt.Context = attemptCtx
+			stream, bifrostErr := next.CallStream(&attempt)
+			if bifrostErr != nil {
+				cancelAttempt()
+				return stream, attemptTimeoutError(call.Context, attemptCtx, timeout, bifrostErr)
+			}
+			stopTimeout()
+			if timeout <= 0 {
+				return stream, nil
+			}
+			return streamWithCancel(stream, cancelAttempt), nil
+		},
+	}
+}
+
+// streamWithCancel forwards the chunks of stream and calls cancel once it is closed, releasing
+// the context of the attempt that produced it.
+func streamWithCancel(stream chan *schemas.BifrostStream, cancel func()) chan *schemas.BifrostStream {
+	outputStream := make(chan *schemas.BifrostStream, cap(stream))
+	go func() {
+		defer close(outputStream)
+		defer cancel()
+		for chunk := range stream {
+			outputStream <- chunk
+		}
+	}()
+	return outputStream
+}
+
+// attemptTimeoutError replaces the cancellation error of an attempt that ran into its
+// timeout with a retryable timeout error, so retries and fallbacks still apply. Errors of
+// requests cancelled by the caller are returned unchanged.
+func attemptTimeoutError(parent, attemptCtx context.Context, timeout time.Duration, bifrostErr *schemas.BifrostError) *schemas.BifrostError {
+	if bifrostErr == nil || parent.Err() != nil || context.Cause(attemptCtx) != context.DeadlineExceeded {
+		return bifrostErr
+	}
+	return &schemas.BifrostError{
+		IsBifrostError: false,
+		StatusCode:     Ptr(504),
+		Origin:         schemas.ErrorOriginNetwork,
+		Error: schemas.ErrorField{
+			Message: fmt.Sprintf("request timed out after %s", timeout),
+			Error:   context.DeadlineExceeded,
+		},
+	}
+}
+
+// isRetryableError reports whether a failed attempt of a request without a request policy may
+// be retried: provider errors with a status in retryableStatusCodes, provider errors without a
+// status, and errors of failed provider requests. Cancelled requests and panics never are.
+func isRetryableError(bifrostErr *schemas.BifrostError) bool {
+	if bifrostErr.Error.Type != nil && (*bifrostErr.Error.Type == schemas.RequestCancelled || *bifrostErr.Error.Type == schemas.InternalPanic) {
+		return false
+	}
+	if bifrostErr.StatusCode != nil {
+		return retryableStatusCodes[*bifrostErr.StatusCode]
+	}
+	return !bifrostErr.IsBifrostError || bifrostErr.Error.Message == schemas.ErrProviderRequest
+}
+
+// isTransientError is the stricter classification of requests that set a request policy. Only
+// transient failures are retried: responses with a status in retryableStatusCodes, and network
+// errors, which include transport failures and attempt timeouts. Errors such as responses that
+// cannot be decoded or requests that cannot be marshalled would fail the same way again.
+func isTransientError(bifrostErr *schemas.BifrostError) bool {
+	if bifrostErr.Error.Type != nil && (*bifrostErr.Error.Type == schemas.RequestCancelled || *bifrostErr.Error.Type == schemas.InternalPanic) {
+		return false
+	}
+	if bifrostErr.StatusCode != nil {
+		return retryableStatusCodes[*bifrostErr.StatusCode]
+	}
+	return bifrostErr.Origin == schemas.ErrorOriginNetwork
+}
+
+// retryClassifier returns the classification of the retryable errors of a request with params.
+func retryClassifier(params *schemas.ModelParameters) func(*schemas.BifrostError) bool {
+	if params != nil && params.RequestPolicy != nil {
+		return isTransientError
+	}
+	return isRetryableError
+}
+
+// retryMiddleware wraps next, retrying attempts that failed with a retryable error with backoff,
+// up to the retries of the request's policy.
+func (bifrost *Bifrost) retryMiddleware(next schemas.ProviderCaller) schemas.ProviderCaller {
+	return schemas.ProviderCallerFuncs{
+		CallFunc: func(call *schemas.ProviderCall) (*schemas.BifrostResponse, *schemas.BifrostError) {
+			return withRetries(bifrost, call, next.Call)
+		},
+		CallStreamFunc: func(call *schemas.ProviderCall) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+			return withRetries(bifrost, call, next.CallStream)
+		},
+	}
+}
+
+// waitBackoff waits for backoff to elapse, returning false without waiting it out if ctx is
+// done first.
+func waitBackoff(ctx context.Context, backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// withRetries makes a call with attempt, retrying it while it fails with a retryable error.
+// A request cancelled during a backoff returns the error of its last attempt.
+func withRetries[T any](bifrost *Bifrost, call *schemas.ProviderCall, attempt func(*schemas.ProviderCall) (T, *schemas.BifrostError)) (T, *schemas.BifrostError) {
+	policy := bifrost.getRequestPolicy(call.Config, call.Params)
+	retryable := retryClassifier(call.Params)
+
+	var result T
+	var bifrostErr *schemas.BifrostError
+	var attempts int
+	for attempts = 0; attempts <= policy.maxRetries; attempts++ {
+		if attempts > 0 {
+			bifrost.logger.Info("retrying request (attempt %d/%d) for model %s: %s", attempts, policy.maxRetries, call.Model, bifrostErr.Error.Message)
+
+			// Calculate and apply backoff
+			backoff := calculateBackoff(attempts-1, policy.backoffInitial, policy.backoffMax)
+			if !waitBackoff(call.Context, backoff) {
+				attempts--
+				break
+			}
+		}
+
+		bifrost.logger.Debug("attempting request for provider %s", call.Provider)
+		result, bifrostErr = attempt(call)
+		bifrost.logger.Debug("request for provider %s completed", call.Provider)
+
+		// Check if successful or if we should retry
+		if bifrostErr == nil || !retryable(bifrostErr) {
+			break
+		}
+	}
+
+	if bifrostErr != nil && attempts > 0 {
+		bifrost.logger.Warn("request failed after %d %s", attempts, map[bool]string{true: "retries", false: "retry"}[attempts > 1])
+	}
+	return result, bifrostErr
+}
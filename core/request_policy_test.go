@@ -0,0 +1,179 @@
+package bifrost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestRetryClassification(t *testing.T) {
+	status := func(code int) *int { return &code }
+	errType := func(errType string) *string { return &errType }
+
+	tests := []struct {
+		name          string
+		err           schemas.BifrostError
+		wantRetryable bool
+		wantTransient bool
+	}{
+		{name: "retryable status", err: schemas.BifrostError{StatusCode: status(503), Origin: schemas.ErrorOriginProvider}, wantRetryable: true, wantTransient: true},
+		{name: "rate limited", err: schemas.BifrostError{StatusCode: status(429), Origin: schemas.ErrorOriginProvider}, wantRetryable: true, wantTransient: true},
+		{name: "client error status", err: schemas.BifrostError{StatusCode: status(400), Origin: schemas.ErrorOriginProvider}},
+		{name: "attempt timeout", err: schemas.BifrostError{StatusCode: status(504), Origin: schemas.ErrorOriginNetwork}, wantRetryable: true, wantTransient: true},
+		{name: "transport failure", err: schemas.BifrostError{IsBifrostError: true, Origin: schemas.ErrorOriginNetwork, Error: schemas.ErrorField{Message: schemas.ErrProviderRequest}}, wantRetryable: true, wantTransient: true},
+		{name: "provider error without status", err: schemas.BifrostError{Origin: schemas.ErrorOriginProvider}, wantRetryable: true},
+		{name: "undecodable response", err: schemas.BifrostError{IsBifrostError: true, Origin: schemas.ErrorOriginProvider, Error: schemas.ErrorField{Message: "failed to unmarshal response"}}},
+		{name: "cancelled", err: schemas.BifrostError{Origin: schemas.ErrorOriginNetwork, Error: schemas.ErrorField{Type: errType(schemas.RequestCancelled)}}},
+		{name: "panic", err: schemas.BifrostError{IsBifrostError: true, StatusCode: status(500), Error: schemas.ErrorField{Type: errType(schemas.InternalPanic)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(&tt.err); got != tt.wantRetryable {
+				t.Errorf("isRetryableError() = %v, want %v", got, tt.wantRetryable)
+			}
+			if got := isTransientError(&tt.err); got != tt.wantTransient {
+				t.Errorf("isTransientError() = %v, want %v", got, tt.wantTransient)
+			}
+		})
+	}
+}
+
+func TestGetRequestPolicy(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	bifrost := &Bifrost{requestPolicyLimits: buildRequestPolicyLimits(&schemas.RequestPolicyLimits{MaxRetries: 5, MaxRetryBackoff: 10 * time.Second})}
+	config := &schemas.ProviderConfig{NetworkConfig: schemas.NetworkConfig{
+		DefaultRequestTimeoutInSeconds: 30,
+		MaxRetries:                     2,
+		RetryBackoffInitial:            500 * time.Millisecond,
+		RetryBackoffMax:                5 * time.Second,
+	}}
+
+	tests := []struct {
+		name   string
+		policy *schemas.RequestPolicy
+		want   requestPolicy
+	}{
+		{
+			name: "no policy",
+			want: requestPolicy{maxRetries: 2, backoffInitial: 500 * time.Millisecond, backoffMax: 5 * time.Second},
+		},
+		{
+			name:   "shorter timeout",
+			policy: &schemas.RequestPolicy{TimeoutInSeconds: intPtr(10)},
+			want:   requestPolicy{timeout: 10 * time.Second, maxRetries: 2, backoffInitial: 500 * time.Millisecond, backoffMax: 5 * time.Second},
+		},
+		{
+			name:   "longer timeout clamped to provider timeout",
+			policy: &schemas.RequestPolicy{TimeoutInSeconds: intPtr(120)},
+			want:   requestPolicy{timeout: 30 * time.Second, maxRetries: 2, backoffInitial: 500 * time.Millisecond, backoffMax: 5 * time.Second},
+		},
+		{
+			name:   "retries clamped to limit",
+			policy: &schemas.RequestPolicy{MaxRetries: intPtr(50)},
+			want:   requestPolicy{maxRetries: 5, backoffInitial: 500 * time.Millisecond, backoffMax: 5 * time.Second},
+		},
+		{
+			name:   "negative retries",
+			policy: &schemas.RequestPolicy{MaxRetries: intPtr(-1)},
+			want:   requestPolicy{maxRetries: 0, backoffInitial: 500 * time.Millisecond, backoffMax: 5 * time.Second},
+		},
+		{
+			name:   "backoff clamped to limit",
+			policy: &schemas.RequestPolicy{RetryBackoffInitialMs: intPtr(20000), RetryBackoffMaxMs: intPtr(60000)},
+			want:   requestPolicy{maxRetries: 2, backoffInitial: 10 * time.Second, backoffMax: 10 * time.Second},
+		},
+		{
+			name:   "backoff max raised to initial",
+			policy: &schemas.RequestPolicy{RetryBackoffInitialMs: intPtr(8000)},
+			want:   requestPolicy{maxRetries: 2, backoffInitial: 8 * time.Second, backoffMax: 8 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bifrost.getRequestPolicy(config, &schemas.ModelParameters{RequestPolicy: tt.policy})
+			if got != tt.want {
+				t.Errorf("getRequestPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRetriesBackoffCancelled(t *testing.T) {
+	bifrost := &Bifrost{
+		logger:              NewDefaultLogger(schemas.LogLevelError),
+		requestPolicyLimits: buildRequestPolicyLimits(nil),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	call := &schemas.ProviderCall{
+		Context: ctx,
+		Config: &schemas.ProviderConfig{NetworkConfig: schemas.NetworkConfig{
+			MaxRetries:          3,
+			RetryBackoffInitial: time.Minute,
+			RetryBackoffMax:     time.Minute,
+		}},
+	}
+
+	var attempts int
+	attempt := func(*schemas.ProviderCall) (string, *schemas.BifrostError) {
+		attempts++
+		cancel()
+		return "", &schemas.BifrostError{StatusCode: Ptr(503), Error: schemas.ErrorField{Message: "unavailable"}}
+	}
+
+	done := make(chan *schemas.BifrostError, 1)
+	go func() {
+		_, bifrostErr := withRetries(bifrost, call, attempt)
+		done <- bifrostErr
+	}()
+	select {
+	case bifrostErr := <-done:
+		if bifrostErr == nil || bifrostErr.Error.Message != "unavailable" {
+			t.Errorf("withRetries() error = %+v, want the last attempt's error", bifrostErr)
+		}
+		if attempts != 1 {
+			t.Errorf("attempts = %d, want 1", attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("withRetries() kept waiting out the backoff after the request was cancelled")
+	}
+}
+
+func TestWithRetriesClassification(t *testing.T) {
+	bifrost := &Bifrost{
+		logger:              NewDefaultLogger(schemas.LogLevelError),
+		requestPolicyLimits: buildRequestPolicyLimits(nil),
+	}
+	config := &schemas.ProviderConfig{NetworkConfig: schemas.NetworkConfig{
+		MaxRetries:          2,
+		RetryBackoffInitial: time.Millisecond,
+		RetryBackoffMax:     time.Millisecond,
+	}}
+	maxRetries := 2
+
+	tests := []struct {
+		name         string
+		params       *schemas.ModelParameters
+		wantAttempts int
+	}{
+		{name: "without policy", wantAttempts: 3},
+		{name: "with policy", params: &schemas.ModelParameters{RequestPolicy: &schemas.RequestPolicy{MaxRetries: &maxRetries}}, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call := &schemas.ProviderCall{Context: context.Background(), Config: config, Params: tt.params}
+			var attempts int
+			_, bifrostErr := withRetries(bifrost, call, func(*schemas.ProviderCall) (string, *schemas.BifrostError) {
+				attempts++
+				return "", &schemas.BifrostError{Origin: schemas.ErrorOriginProvider, Error: schemas.ErrorField{Message: "empty response"}}
+			})
+			if bifrostErr == nil {
+				t.Fatal("withRetries() error = nil, want the provider error")
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// ROLE NORMALIZATION
+// ============================================================================
+
+// roleRules is how a provider accepts the roles of chat messages.
+type roleRules struct {
+	developer   bool // Accepts the developer role, otherwise developer messages are sent as system
+	alternating bool // Requires user and assistant turns to alternate, starting with a user turn
+}
+
+// providerRoleRules are the role rules of the providers that differ from the OpenAI-compatible
+// default, which accepts system but not developer messages and any order of turns.
+var providerRoleRules = map[schemas.ModelProvider]roleRules{
+	schemas.OpenAI:     {developer: true},
+	schemas.Anthropic:  {alternating: true},
+	schemas.Bedrock:    {alternating: true},
+	schemas.Vertex:     {alternating: true},
+	schemas.Gemini:     {alternating: true},
+	schemas.Perplexity: {alternating: true},
+}
+
+// continuationPrompt opens conversations that start with an assistant turn for providers
+// requiring the first turn to be a user turn.
+const continuationPrompt = "Continue."
+
+// normalizeRoles returns the messages with their roles as the provider accepts them: developer
+// messages become system messages for providers without the developer role, and for providers
+// requiring alternating turns, consecutive messages of the same role are merged and a user turn
+// is added before a leading assistant turn. Merges and added turns are reported as a warning.
+// The messages are returned unchanged if nothing needs to change, and are never modified.
+func normalizeRoles(ctx context.Context, provider schemas.ModelProvider, messages []schemas.BifrostMessage) []schemas.BifrostMessage {
+	if enabled, ok := ctx.Value(schemas.BifrostContextKeyRoleNormalization).(bool); ok && !enabled {
+		return messages
+	}
+	rules := providerRoleRules[provider]
+
+	normalized := messages
+	copied := false
+	copyOnWrite := func() {
+		if !copied {
+			normalized = append([]schemas.BifrostMessage(nil), messages...)
+			copied = true
+		}
+	}
+
+	if !rules.developer {
+		for i, message := range normalized {
+			if message.Role == schemas.ModelChatMessageRoleDeveloper {
+				copyOnWrite()
+				normalized[i].Role = schemas.ModelChatMessageRoleSystem
+			}
+		}
+	}
+	if !rules.alternating {
+		return normalized
+	}
+
+	var changes []string
+	merged := make([]schemas.BifrostMessage, 0, len(normalized))
+	merges := 0
+	for _, message := range normalized {
+		if n := len(merged); n > 0 && canMergeMessages(merged[n-1], message) {
+			merged[n-1] = mergeMessages(merged[n-1], message)
+			merges++
+			continue
+		}
+		merged = append(merged, message)
+	}
+	if merges > 0 {
+		changes = append(changes, fmt.Sprintf("%d messages merged into the previous message of the same role", merges))
+		normalized = merged
+	}
+
+	for i, message := range normalized {
+		if message.Role.IsInstruction() {
+			continue
+		}
+		if message.Role == schemas.ModelChatMessageRoleAssistant {
+			opening := schemas.BifrostMessage{
+				Role:    schemas.ModelChatMessageRoleUser,
+				Content: schemas.MessageContent{ContentStr: Ptr(continuationPrompt)},
+			}
+			normalized = append(append(append([]schemas.BifrostMessage(nil), normalized[:i]...), opening), normalized[i:]...)
+			changes = append(changes, "a user turn was added before the leading assistant turn")
+		}
+		break
+	}
+
+	if len(changes) > 0 {
+		schemas.AddWarning(ctx, schemas.WarningParamAdjusted, schemas.WarningOriginBifrost,
+			fmt.Sprintf("messages adjusted for the turn order required by %s: %s", provider, strings.Join(changes, ", ")))
+	}
+	return normalized
+}
+
+// canMergeMessages reports whether a message can be merged into the previous one: both have the
+// same user, system or plain assistant role. Tool results and assistant turns with tool calls or
+// thoughts are kept apart, since providers pair them by position.
+func canMergeMessages(previous, message schemas.BifrostMessage) bool {
+	if previous.Role != message.Role {
+		return false
+	}
+	switch message.Role {
+	case schemas.ModelChatMessageRoleUser, schemas.ModelChatMessageRoleSystem:
+		return true
+	case schemas.ModelChatMessageRoleAssistant:
+		return isPlainAssistantMessage(previous) && isPlainAssistantMessage(message)
+	}
+	return false
+}
+
+// isPlainAssistantMessage reports whether an assistant message carries nothing but content.
+func isPlainAssistantMessage(message schemas.BifrostMessage) bool {
+	assistant := message.AssistantMessage
+	return assistant == nil || ((assistant.ToolCalls == nil || len(*assistant.ToolCalls) == 0) && assistant.Thought == nil && assistant.Refusal == nil)
+}
+
+// mergeMessages appends the content of a message to the previous one. Text contents are joined
+// by a blank line, other contents are merged as content blocks.
+func mergeMessages(previous, message schemas.BifrostMessage) schemas.BifrostMessage {
+	if previous.Content.ContentStr != nil && message.Content.ContentStr != nil {
+		previous.Content = schemas.MessageContent{ContentStr: Ptr(*previous.Content.ContentStr + "\n\n" + *message.Content.ContentStr)}
+		return previous
+	}
+	blocks := append(contentBlocks(previous.Content), contentBlocks(message.Content)...)
+	previous.Content = schemas.MessageContent{ContentBlocks: &blocks}
+	return previous
+}
+
+// contentBlocks returns a copy of the content as content blocks.
+func contentBlocks(content schemas.MessageContent) []schemas.ContentBlock {
+	if content.ContentStr != nil {
+		return []schemas.ContentBlock{{Type: schemas.ContentBlockTypeText, Text: Ptr(*content.ContentStr)}}
+	}
+	if content.ContentBlocks != nil {
+		return append([]schemas.ContentBlock(nil), *content.ContentBlocks...)
+	}
+	return nil
+}
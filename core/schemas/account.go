@@ -19,7 +19,7 @@ type Key struct {
 // It contains Azure-specific settings required for service access and deployment management.
 type AzureKeyConfig struct {
 	Endpoint    string            `json:"endpoint"`              // Azure service endpoint URL
-	Deployments map[string]string `json:"deployments,omitempty"` // Mapping of model names to deployment names
+	Deployments map[string]string `json:"deployments,omitempty"` // Mapping of model names to deployment names; models are deployed under their own name if empty
 	APIVersion  *string           `json:"api_version,omitempty"` // Azure API version to use; defaults to "2024-08-01-preview"
 }
 
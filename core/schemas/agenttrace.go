@@ -0,0 +1,77 @@
+package schemas
+
+import "time"
+
+// Default agent tracing settings.
+const (
+	DefaultAgentTraceTTL       = time.Hour
+	DefaultAgentTraceMaxTraces = 1000
+	DefaultAgentTraceMaxSpans  = 1000
+)
+
+// AgentTracingConfig enables the agent step trace, which records the model calls, their
+// decisions, and the tool calls and results of an agent loop as a tree of spans. Steps are
+// grouped by BifrostContextKeyTraceID; requests without one start a new trace whose ID is
+// returned in the response's extra fields.
+type AgentTracingConfig struct {
+	TTL       time.Duration `json:"ttl"`        // Traces idle for longer are forgotten, DefaultAgentTraceTTL if 0
+	MaxTraces int           `json:"max_traces"` // Least recently used traces are forgotten beyond this, DefaultAgentTraceMaxTraces if 0
+	MaxSpans  int           `json:"max_spans"`  // Spans kept per trace, later spans are dropped, DefaultAgentTraceMaxSpans if 0
+
+	// SpanHandler receives every span once it ends, e.g. to export it to OpenTelemetry.
+	SpanHandler AgentSpanHandler `json:"-"`
+}
+
+// AgentStepKind is the kind of step a span records.
+type AgentStepKind string
+
+const (
+	AgentStepModelCall  AgentStepKind = "model_call"  // A chat or text completion request, including its fallbacks
+	AgentStepDecision   AgentStepKind = "decision"    // What the model decided: call tools or answer
+	AgentStepToolCall   AgentStepKind = "tool_call"   // An execution of a tool through MCP
+	AgentStepToolResult AgentStepKind = "tool_result" // The result of a tool execution
+)
+
+// Decisions recorded in the name of decision spans.
+const (
+	AgentDecisionToolCalls = "tool_calls" // The model requested tool calls
+	AgentDecisionAnswer    = "answer"     // The model answered without tool calls
+)
+
+// AgentSpanStatus is the outcome of a step.
+type AgentSpanStatus string
+
+const (
+	AgentSpanStatusOK    AgentSpanStatus = "ok"
+	AgentSpanStatusError AgentSpanStatus = "error"
+)
+
+// AgentSpan is a step of an agent trace. Decisions are children of their model call, tool calls
+// are children of the decision that requested them (matched by tool call ID), and results are
+// children of their tool call.
+type AgentSpan struct {
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	Kind         AgentStepKind          `json:"kind"`
+	Name         string                 `json:"name"`
+	Status       AgentSpanStatus        `json:"status"`
+	Error        string                 `json:"error,omitempty"`
+	StartTime    time.Time              `json:"start_time"`
+	EndTime      time.Time              `json:"end_time"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Children     []*AgentSpan           `json:"children,omitempty"` // Set in AgentTrace trees only
+}
+
+// AgentTrace is the tree of steps of a trace, in the order they started.
+type AgentTrace struct {
+	TraceID      string       `json:"trace_id"`
+	StartTime    time.Time    `json:"start_time"`
+	EndTime      time.Time    `json:"end_time"`
+	DroppedSpans int          `json:"dropped_spans,omitempty"` // Spans beyond MaxSpans that were not kept
+	Spans        []*AgentSpan `json:"spans"`                   // Root spans
+}
+
+// AgentSpanHandler receives agent spans as they end. It is called synchronously, so it should
+// hand off expensive work.
+type AgentSpanHandler func(span AgentSpan)
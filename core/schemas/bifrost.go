@@ -2,7 +2,12 @@
 package schemas
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
 
 	"github.com/bytedance/sonic"
 )
@@ -21,6 +26,105 @@ type BifrostConfig struct {
 	InitialPoolSize    int        // Initial pool size for sync pools in Bifrost. Higher values will reduce memory allocations but will increase memory usage.
 	DropExcessRequests bool       // If true, in cases where the queue is full, requests will not wait for the queue to be empty and will be dropped instead.
 	MCPConfig          *MCPConfig // MCP (Model Context Protocol) configuration for tool integration
+	// Optional post-processing that coerces every embedding response to a uniform dimension.
+	// Can be overridden per request with BifrostContextKeyEmbeddingDimension.
+	EmbeddingDimension *EmbeddingDimensionConfig
+	// If true, the final body of every provider request is validated against the provider's
+	// parameter schema before being sent, and requests with unsupported or invalid fields fail
+	// locally. Can be overridden per request with BifrostContextKeyStrictParams.
+	StrictParams bool
+	// Parameter schemas extending the built-in ones, keyed by provider and describing the
+	// top-level fields of the provider's request bodies.
+	// Custom providers fall back to the schema of their base provider.
+	ParamSchemas map[ModelProvider]ParamSchema
+	// Server-side maximums for the per-request overrides in ModelParameters.RequestPolicy.
+	RequestPolicyLimits *RequestPolicyLimits
+	// Tuning of session affinity, which pins the turns of a session (BifrostContextKeySessionID)
+	// to the provider and key that served it. Defaults are used if nil.
+	SessionAffinity *SessionAffinityConfig
+	// Optional turn and token limits of sessions (BifrostContextKeySessionID), with an optional
+	// summarize-and-continue recovery for chat requests. Sessions are not limited if nil.
+	SessionLimits *SessionLimitsConfig
+	// Optional agent step tracing, which records model calls, their decisions and MCP tool
+	// executions as a tree of spans per trace (BifrostContextKeyTraceID). Disabled if nil.
+	AgentTracing *AgentTracingConfig
+	// Optional prompt prefix cache manager, which keeps the stable prefix of chat requests (system
+	// messages and tool definitions) identical across requests and marks it for provider-side
+	// prompt caching. Can be toggled per request with BifrostContextKeyPromptCache.
+	PromptCache *PromptCacheConfig
+	// Optional micro-batching of embedding requests, which merges text embedding requests arriving
+	// within a short window into a single provider call. Can be turned off per request with
+	// BifrostContextKeyEmbeddingBatching.
+	EmbeddingBatching *EmbeddingBatchConfig
+	// Optional automatic max_tokens, computed from the model's context window minus the counted
+	// prompt tokens so requests do not fail with "max_tokens exceeds context" errors.
+	// Can be turned off per request with BifrostContextKeyAutoMaxTokens.
+	AutoMaxTokens *AutoMaxTokensConfig
+	// Optional slimming of chat and text completion requests rejected as too large (413, or a 400
+	// about the context length), which are slimmed down and sent again once. Can be turned off per
+	// request with BifrostContextKeyPayloadSlimming.
+	PayloadSlimming *PayloadSlimmingConfig
+	// Optional splitting of long audio into overlapping chunks that are transcribed in parallel and
+	// stitched into a single transcription. Can be turned off per request with
+	// BifrostContextKeyAudioChunking.
+	TranscriptionChunking *TranscriptionChunkingConfig
+	// Tuning of sticky key routing, which consistently hashes the routing key of a request
+	// (BifrostContextKeyRoutingKey, else BifrostContextKeySessionID) onto the keys of a provider,
+	// so a user keeps hitting the same backend. Defaults are used if nil.
+	StickyRouting *StickyRoutingConfig
+	// Optional dual-write mode of an embedding model migration: requests for the source model are
+	// served by it, and their texts are also embedded with the target model in the background and
+	// written to a sink, so an index can be re-embedded gradually. Disabled if nil.
+	EmbeddingMigration *EmbeddingMigrationConfig
+	// Custom middleware around provider calls, appended in order after the built-in middleware,
+	// closest to the provider. Middleware can also be inserted at precise positions later through
+	// Bifrost.Middleware.
+	Middleware []ProviderMiddleware
+	// Optional warm-up of providers, which opens connections to their APIs at startup and again
+	// after idle periods, and can send a tiny probe request at startup. Disabled if nil; can also be
+	// run on demand with Bifrost.WarmUp.
+	WarmUp *WarmUpConfig
+	// Optional weighted fair queuing of provider queues across tenants, so a tenant sending more
+	// requests than a provider can serve does not starve the others. Requests are served in FIFO
+	// order if nil; can be changed at runtime with Bifrost.UpdateFairQueuing.
+	FairQueuing *FairQueuingConfig
+	// Optional detection of agent loops that keep making the same tool call, which aborts the
+	// chat requests caught in a loop or appends a corrective system message to them. Disabled if
+	// nil; can be turned off per request with BifrostContextKeyLoopDetection.
+	LoopDetection *LoopDetectionConfig
+	// How streams are ended when the provider closes them without a terminator, such as [DONE]
+	// or a finish reason. IncompleteStreamFinish if empty; can be overridden per request with
+	// BifrostContextKeyIncompleteStream. Only applies to providers streaming through the
+	// OpenAI-compatible streamer, such as OpenAI, Azure, Mistral, SGL or Together; the
+	// native streamers of other providers end such streams as if they had ended normally.
+	IncompleteStreamBehavior IncompleteStreamBehavior
+}
+
+// FinishReasonIncomplete is the finish reason of the final chunk of streams the provider closed
+// without a terminator.
+const FinishReasonIncomplete = "incomplete"
+
+// IncompleteStreamBehavior is how a stream is ended when the provider closes it without a
+// terminator, leaving the response possibly cut short.
+type IncompleteStreamBehavior string
+
+const (
+	// Send a final chunk with finish reason incomplete and the usage received so far, and add a
+	// warning.
+	IncompleteStreamFinish IncompleteStreamBehavior = "finish"
+	// End the stream with an error instead of a final chunk.
+	IncompleteStreamError IncompleteStreamBehavior = "error"
+	// Send the final chunk as if the stream had ended normally, without a finish reason.
+	IncompleteStreamIgnore IncompleteStreamBehavior = "ignore"
+)
+
+// IsValid reports whether b is one of the known behaviors.
+func (b IncompleteStreamBehavior) IsValid() bool {
+	switch b {
+	case IncompleteStreamFinish, IncompleteStreamError, IncompleteStreamIgnore:
+		return true
+	}
+	return false
 }
 
 // ModelChatMessageRole represents the role of a chat message
@@ -30,10 +134,16 @@ const (
 	ModelChatMessageRoleAssistant ModelChatMessageRole = "assistant"
 	ModelChatMessageRoleUser      ModelChatMessageRole = "user"
 	ModelChatMessageRoleSystem    ModelChatMessageRole = "system"
+	ModelChatMessageRoleDeveloper ModelChatMessageRole = "developer" // System instructions for newer OpenAI models, sent as system to other providers
 	ModelChatMessageRoleChatbot   ModelChatMessageRole = "chatbot"
 	ModelChatMessageRoleTool      ModelChatMessageRole = "tool"
 )
 
+// IsInstruction reports whether the role carries instructions to the model, system or developer.
+func (r ModelChatMessageRole) IsInstruction() bool {
+	return r == ModelChatMessageRoleSystem || r == ModelChatMessageRoleDeveloper
+}
+
 // ModelProvider represents the different AI model providers supported by Bifrost.
 type ModelProvider string
 
@@ -52,6 +162,24 @@ const (
 	Cerebras   ModelProvider = "cerebras"
 	Gemini     ModelProvider = "gemini"
 	OpenRouter ModelProvider = "openrouter"
+	Perplexity ModelProvider = "perplexity"
+	DeepSeek   ModelProvider = "deepseek"
+	Together   ModelProvider = "together"
+	Replicate  ModelProvider = "replicate"
+	Qwen       ModelProvider = "qwen"     // Alibaba Cloud DashScope
+	Zhipu      ModelProvider = "zhipu"    // Zhipu AI (GLM)
+	Moonshot   ModelProvider = "moonshot" // Moonshot AI (Kimi)
+	Qianfan    ModelProvider = "qianfan"  // Baidu Qianfan (ERNIE)
+	SambaNova  ModelProvider = "sambanova"
+	NIM        ModelProvider = "nim"     // NVIDIA NIM
+	Watsonx    ModelProvider = "watsonx" // IBM watsonx.ai
+	Stability  ModelProvider = "stability"
+	BFL        ModelProvider = "bfl" // Black Forest Labs (FLUX)
+	Luma       ModelProvider = "luma"
+
+	// Template is only usable as the base provider of a custom provider, whose requests and
+	// responses are described by CustomProviderConfig.RequestTemplates.
+	Template ModelProvider = "template"
 )
 
 // SupportedBaseProviders is the list of base providers allowed for custom providers.
@@ -61,6 +189,7 @@ var SupportedBaseProviders = []ModelProvider{
 	Cohere,
 	Gemini,
 	OpenAI,
+	Template,
 }
 
 // StandardProviders is the list of all built-in (non-custom) providers.
@@ -76,9 +205,23 @@ var StandardProviders = []ModelProvider{
 	Ollama,
 	OpenAI,
 	Parasail,
+	Perplexity,
+	DeepSeek,
+	Together,
+	Replicate,
+	Qwen,
+	Zhipu,
+	Moonshot,
+	Qianfan,
+	SambaNova,
+	NIM,
+	Watsonx,
 	SGL,
 	Vertex,
 	OpenRouter,
+	Stability,
+	BFL,
+	Luma,
 }
 
 // RequestType represents the type of request being made to a provider.
@@ -93,6 +236,12 @@ const (
 	SpeechStreamRequest         RequestType = "speech_stream"
 	TranscriptionRequest        RequestType = "transcription"
 	TranscriptionStreamRequest  RequestType = "transcription_stream"
+	TranslationRequest          RequestType = "translation"
+	ImageGenerationRequest      RequestType = "image_generation"
+	VideoGenerationRequest      RequestType = "video_generation"
+	VideoStatusRequest          RequestType = "video_status"
+	TokenCountRequest           RequestType = "token_count"
+	RerankRequest               RequestType = "rerank"
 )
 
 // BifrostContextKey is a type for context keys used in Bifrost.
@@ -105,6 +254,25 @@ const (
 	BifrostContextKeyRequestType        BifrostContextKey = "bifrost-request-type"
 	BifrostContextKeyRequestProvider    BifrostContextKey = "bifrost-request-provider"
 	BifrostContextKeyRequestModel       BifrostContextKey = "bifrost-request-model"
+	BifrostContextKeyEmbeddingDimension BifrostContextKey = "bifrost-embedding-dimension" // *EmbeddingDimensionConfig
+	BifrostContextKeyStrictParams       BifrostContextKey = "bifrost-strict-params"       // bool
+	BifrostContextKeyRequestBodyCheck   BifrostContextKey = "bifrost-request-body-check"  // RequestBodyCheck, set by Bifrost in strict mode
+	BifrostContextKeyQueueStatus        BifrostContextKey = "bifrost-queue-status"        // QueueStatusCallback
+	BifrostContextKeyQueueStatusEvents  BifrostContextKey = "bifrost-queue-status-events" // bool, streams only
+	BifrostContextKeySessionID          BifrostContextKey = "bifrost-session-id"          // string, enables session affinity
+	BifrostContextKeyPromptCache        BifrostContextKey = "bifrost-prompt-cache"        // bool
+	BifrostContextKeyEmbeddingBatching  BifrostContextKey = "bifrost-embedding-batching"  // bool
+	BifrostContextKeySpeculativeDraft   BifrostContextKey = "bifrost-speculative-draft"   // SpeculativeDraft, chat streams only
+	BifrostContextKeyAutoMaxTokens      BifrostContextKey = "bifrost-auto-max-tokens"     // bool
+	BifrostContextKeyTraceID            BifrostContextKey = "bifrost-trace-id"            // string, groups the steps of an agent loop
+	BifrostContextKeyAudioChunking      BifrostContextKey = "bifrost-audio-chunking"      // bool
+	BifrostContextKeyWarnings           BifrostContextKey = "bifrost-warnings"            // *Warnings, set by Bifrost on every provider attempt
+	BifrostContextKeyRoutingKey         BifrostContextKey = "bifrost-routing-key"         // string, user or session ID hashed onto the keys of a provider
+	BifrostContextKeyPayloadSlimming    BifrostContextKey = "bifrost-payload-slimming"    // bool
+	BifrostContextKeyRoleNormalization  BifrostContextKey = "bifrost-role-normalization"  // bool
+	BifrostContextKeyTenant             BifrostContextKey = "bifrost-tenant"              // string, tenant of the request for fair queuing
+	BifrostContextKeyLoopDetection      BifrostContextKey = "bifrost-loop-detection"      // bool
+	BifrostContextKeyIncompleteStream   BifrostContextKey = "bifrost-incomplete-stream"   // IncompleteStreamBehavior, streams only
 )
 
 // NOTE: for custom plugin implementation dealing with streaming short circuit,
@@ -120,6 +288,10 @@ type RequestInput struct {
 	EmbeddingInput      *EmbeddingInput     `json:"embedding_input,omitempty"`
 	SpeechInput         *SpeechInput        `json:"speech_input,omitempty"`
 	TranscriptionInput  *TranscriptionInput `json:"transcription_input,omitempty"`
+	ImageInput          *ImageInput         `json:"image_input,omitempty"`
+	VideoInput          *VideoInput         `json:"video_input,omitempty"`
+	VideoJobInput       *VideoJobInput      `json:"video_job_input,omitempty"`
+	RerankInput         *RerankInput        `json:"rerank_input,omitempty"`
 }
 
 // EmbeddingInput represents the input for an embedding request.
@@ -260,6 +432,8 @@ func (tc *SpeechVoiceInput) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("voice field is neither a string, nor an array of VoiceConfig objects")
 }
 
+// TranscriptionInput is the audio of a transcription or translation request. Translations are
+// always in English, Language is ignored for them.
 type TranscriptionInput struct {
 	File           []byte  `json:"file"`
 	Language       *string `json:"language,omitempty"`
@@ -268,6 +442,39 @@ type TranscriptionInput struct {
 	Format         *string `json:"file_format,omitempty"`     // Type of file, not required in openai, but required in gemini
 }
 
+// ImageInput represents the input for an image generation request. Fields a provider has no
+// equivalent for are ignored, provider specific options go in ModelParameters.ExtraParams.
+type ImageInput struct {
+	Prompt         string  `json:"prompt"`
+	NegativePrompt *string `json:"negative_prompt,omitempty"`
+	N              *int    `json:"n,omitempty"`               // Number of images, 1 if not set
+	Size           *string `json:"size,omitempty"`            // "<width>x<height>", e.g. "1024x1024"
+	AspectRatio    *string `json:"aspect_ratio,omitempty"`    // e.g. "16:9", for providers sized by ratio
+	Seed           *int    `json:"seed,omitempty"`            // Seed of the first image
+	OutputFormat   *string `json:"output_format,omitempty"`   // "png", "jpeg" or "webp"
+	ResponseFormat *string `json:"response_format,omitempty"` // "b64_json" (default) or "url"
+}
+
+// VideoInput represents the input for a video generation request. Video generation is
+// asynchronous: the request submits a job, whose status is then polled with a VideoJobInput
+// or delivered to WebhookURL. Provider specific options go in ModelParameters.ExtraParams.
+type VideoInput struct {
+	Prompt         string  `json:"prompt"`
+	NegativePrompt *string `json:"negative_prompt,omitempty"`
+	ImageURL       *string `json:"image_url,omitempty"`    // First frame, for image to video. A URL or a base64 data URL
+	AspectRatio    *string `json:"aspect_ratio,omitempty"` // e.g. "16:9"
+	Resolution     *string `json:"resolution,omitempty"`   // e.g. "720p"
+	Duration       *int    `json:"duration,omitempty"`     // Seconds
+	Loop           *bool   `json:"loop,omitempty"`
+	Seed           *int    `json:"seed,omitempty"`
+	WebhookURL     *string `json:"webhook_url,omitempty"` // Receives the final BifrostResponse of the job as a JSON POST
+}
+
+// VideoJobInput identifies a submitted video generation job to poll.
+type VideoJobInput struct {
+	JobID string `json:"job_id"`
+}
+
 // BifrostRequest represents a request to be processed by Bifrost.
 // It must be provided when calling the Bifrost for text completion, chat completion, or embedding.
 // It contains the model identifier, input data, and parameters for the request.
@@ -306,11 +513,28 @@ type ModelParameters struct {
 	EncodingFormat    *string     `json:"encoding_format,omitempty"`     // Format for embedding output (e.g., "float", "base64")
 	Dimensions        *int        `json:"dimensions,omitempty"`          // Number of dimensions for embedding output
 	User              *string     `json:"user,omitempty"`                // User identifier for tracking
+	// Processing tier requested from the provider, translated by providers that support tiers.
+	ServiceTier *ServiceTier `json:"service_tier,omitempty"`
+	// Per-request timeout, retry and fallback overrides, not sent to the provider.
+	RequestPolicy *RequestPolicy `json:"request_policy,omitempty"`
+	// Set by the prompt cache manager, providers translate it into their caching controls.
+	PromptCache *PromptCacheHint `json:"-"`
 	// Dynamic parameters that can be provider-specific, they are directly
 	// added to the request as is.
 	ExtraParams map[string]interface{} `json:"-"`
 }
 
+// ServiceTier is the processing tier requested from a provider. Higher tiers trade cost for
+// latency and availability: flex is cheaper and slower, priority is faster and more expensive.
+type ServiceTier string
+
+const (
+	ServiceTierAuto     ServiceTier = "auto"     // Let the provider pick, usually the highest tier the account has access to
+	ServiceTierDefault  ServiceTier = "default"  // Standard processing
+	ServiceTierFlex     ServiceTier = "flex"     // Cheaper, higher latency processing (OpenAI)
+	ServiceTierPriority ServiceTier = "priority" // Faster, more expensive processing
+)
+
 // FunctionParameters represents the parameters for a function definition.
 type FunctionParameters struct {
 	Type        string                 `json:"type"`                  // Type of the parameters
@@ -329,9 +553,94 @@ type Function struct {
 
 // Tool represents a tool that can be used with the model.
 type Tool struct {
-	ID       *string  `json:"id,omitempty"` // Optional tool identifier
-	Type     string   `json:"type"`         // Type of the tool
-	Function Function `json:"function"`     // Function definition
+	ID          *string          `json:"id,omitempty"`           // Optional tool identifier
+	Type        string           `json:"type"`                   // Type of the tool
+	Function    Function         `json:"function"`               // Function definition
+	ComputerUse *ComputerUseTool `json:"computer_use,omitempty"` // Computer use configuration, set when Type is ToolTypeComputerUse
+
+	// Provider built-in tools, set when Type is the matching ToolType constant
+	CodeInterpreter *CodeInterpreterTool `json:"code_interpreter,omitempty"`
+	FileSearch      *FileSearchTool      `json:"file_search,omitempty"`
+	WebSearch       *WebSearchTool       `json:"web_search,omitempty"`
+}
+
+// Built-in tool types hosted by providers (currently OpenAI).
+const (
+	ToolTypeCodeInterpreter = "code_interpreter"
+	ToolTypeFileSearch      = "file_search"
+	ToolTypeWebSearch       = "web_search"
+)
+
+// CodeInterpreterTool configures the hosted code interpreter tool.
+type CodeInterpreterTool struct {
+	ContainerID *string  `json:"container_id,omitempty"` // Existing container to run code in, a new one is created if nil
+	FileIDs     []string `json:"file_ids,omitempty"`     // Files made available to the container
+}
+
+// FileSearchTool configures the hosted file search tool.
+type FileSearchTool struct {
+	VectorStoreIDs []string               `json:"vector_store_ids"`
+	MaxNumResults  *int                   `json:"max_num_results,omitempty"`
+	Filters        map[string]interface{} `json:"filters,omitempty"`
+}
+
+// WebSearchTool configures the hosted web search tool.
+type WebSearchTool struct {
+	SearchContextSize *string                `json:"search_context_size,omitempty"` // "low", "medium" or "high"
+	UserLocation      map[string]interface{} `json:"user_location,omitempty"`       // Approximate user location, e.g. {"type": "approximate", "approximate": {"country": "US"}}
+}
+
+// ToolTypeComputerUse is the tool type for provider hosted computer use tools (Anthropic
+// computer use). OpenAI only offers computer use through its Responses API, so its chat
+// completions reject them.
+const ToolTypeComputerUse = "computer_use"
+
+// ComputerUseTool describes the virtual display a computer use tool operates on.
+type ComputerUseTool struct {
+	DisplayWidth  int     `json:"display_width"`            // Display width in pixels
+	DisplayHeight int     `json:"display_height"`           // Display height in pixels
+	DisplayNumber *int    `json:"display_number,omitempty"` // X11 display number (Anthropic only)
+	Version       *string `json:"version,omitempty"`        // Provider tool version, e.g. "computer_20250124" for Anthropic
+}
+
+// ComputerActionType is a normalized computer use action.
+type ComputerActionType string
+
+const (
+	ComputerActionScreenshot  ComputerActionType = "screenshot"
+	ComputerActionClick       ComputerActionType = "click"
+	ComputerActionDoubleClick ComputerActionType = "double_click"
+	ComputerActionTripleClick ComputerActionType = "triple_click"
+	ComputerActionMove        ComputerActionType = "move"
+	ComputerActionDrag        ComputerActionType = "drag"
+	ComputerActionScroll      ComputerActionType = "scroll"
+	ComputerActionTypeText    ComputerActionType = "type"
+	ComputerActionKeypress    ComputerActionType = "keypress"
+	ComputerActionWait        ComputerActionType = "wait"
+	ComputerActionCursor      ComputerActionType = "cursor_position"
+)
+
+// ComputerCoordinate is a point on the virtual display.
+type ComputerCoordinate struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// ComputerAction is a provider independent representation of a computer use action
+// requested by the model. Executors should act on it and reply with a tool message
+// containing a screenshot image block.
+type ComputerAction struct {
+	Type     ComputerActionType   `json:"type"`
+	X        *int                 `json:"x,omitempty"`        // Target x coordinate for click, move and scroll actions
+	Y        *int                 `json:"y,omitempty"`        // Target y coordinate for click, move and scroll actions
+	Path     []ComputerCoordinate `json:"path,omitempty"`     // Points for drag actions, starting point first
+	Button   *string              `json:"button,omitempty"`   // "left", "right", "middle", "back" or "forward"
+	Text     *string              `json:"text,omitempty"`     // Text to type
+	Keys     []string             `json:"keys,omitempty"`     // Keys to press, pressed together
+	ScrollX  *int                 `json:"scroll_x,omitempty"` // Horizontal scroll amount
+	ScrollY  *int                 `json:"scroll_y,omitempty"` // Vertical scroll amount
+	Duration *float64             `json:"duration,omitempty"` // Seconds to wait or hold
+	Raw      map[string]any       `json:"raw,omitempty"`      // Original provider action input
 }
 
 // Combined tool choices for all providers, make sure to check the provider's
@@ -467,6 +776,24 @@ func (mc *MessageContent) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("content field is neither a string nor an array of ContentBlock")
 }
 
+// Text returns the text of the content. The text of content blocks is joined with newlines
+// and their other blocks are skipped.
+func (mc MessageContent) Text() string {
+	if mc.ContentStr != nil {
+		return *mc.ContentStr
+	}
+	if mc.ContentBlocks == nil {
+		return ""
+	}
+	var texts []string
+	for _, block := range *mc.ContentBlocks {
+		if block.Text != nil {
+			texts = append(texts, *block.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
 type ContentBlockType string
 
 const (
@@ -489,10 +816,61 @@ type ToolMessage struct {
 
 // AssistantMessage represents a message from an assistant
 type AssistantMessage struct {
-	Refusal     *string      `json:"refusal,omitempty"`
-	Annotations []Annotation `json:"annotations,omitempty"`
-	ToolCalls   *[]ToolCall  `json:"tool_calls,omitempty"`
-	Thought     *string      `json:"thought,omitempty"`
+	Refusal     *string           `json:"refusal,omitempty"`
+	Annotations []Annotation      `json:"annotations,omitempty"`
+	Citations   []MessageCitation `json:"citations,omitempty"`   // Normalized citations derived from provider annotations
+	Images      []SearchImage     `json:"images,omitempty"`      // Images returned by search-grounded providers
+	CodeBlocks  []CodeBlock       `json:"code_blocks,omitempty"` // Fenced code blocks extracted from the content by the markdown plugin
+	ToolCalls   *[]ToolCall       `json:"tool_calls,omitempty"`
+	Thought     *string           `json:"thought,omitempty"`
+	// Provider signature of the thought, required to send the thought back in later turns
+	ThoughtSignature *string `json:"thought_signature,omitempty"`
+}
+
+// CodeBlock is a fenced code block extracted from markdown content.
+type CodeBlock struct {
+	Language string `json:"language,omitempty"` // Info string language tag, e.g. "go", empty if none
+	Code     string `json:"code"`
+}
+
+// SearchImage is an image returned alongside a search-grounded response (e.g. Perplexity's return_images).
+type SearchImage struct {
+	ImageURL  string  `json:"image_url"`
+	OriginURL *string `json:"origin_url,omitempty"` // Page the image was found on
+	Width     *int    `json:"width,omitempty"`
+	Height    *int    `json:"height,omitempty"`
+}
+
+// SearchResult is a web source a search-grounded provider (e.g. Perplexity) consulted to answer.
+// Results are in the order of the provider, so the 1-based position of a result matches the
+// inline markers such as [1] of the content and the SourceID of its citation.
+type SearchResult struct {
+	URL         string  `json:"url"`
+	Title       *string `json:"title,omitempty"`
+	Snippet     *string `json:"snippet,omitempty"`      // Excerpt of the page
+	Date        *string `json:"date,omitempty"`         // Publication date of the page, as reported
+	LastUpdated *string `json:"last_updated,omitempty"` // Last update of the page, as reported
+}
+
+// Citation types used by MessageCitation.
+const (
+	MessageCitationTypeURL      = "url"      // Web page
+	MessageCitationTypeFile     = "file"     // File or vector store document
+	MessageCitationTypeDocument = "document" // Document supplied in the request
+)
+
+// MessageCitation is a provider independent reference to a source grounding part of a message.
+// StartIndex and EndIndex are character (rune) offsets into the message content, so RAG UIs can
+// render references the same way regardless of the provider.
+type MessageCitation struct {
+	Type       string   `json:"type"`
+	URL        *string  `json:"url,omitempty"`
+	Title      *string  `json:"title,omitempty"`
+	CitedText  *string  `json:"cited_text,omitempty"`  // Text quoted from the source, when provided
+	StartIndex *int     `json:"start_index,omitempty"` // Start of the cited span in the message content (inclusive)
+	EndIndex   *int     `json:"end_index,omitempty"`   // End of the cited span in the message content (exclusive)
+	SourceID   *string  `json:"source_id,omitempty"`   // Provider identifier of the source, e.g. a file id
+	Confidence *float64 `json:"confidence,omitempty"`  // Provider confidence score for the citation, when provided
 }
 
 // ImageContent represents image data in a message.
@@ -504,6 +882,15 @@ type ImageURLStruct struct {
 // InputAudioStruct represents audio data in a message.
 // Data carries the audio payload as a string (e.g., data URL or provider-accepted encoded content).
 // Format is optional (e.g., "wav", "mp3"); when nil, providers may attempt auto-detection.
+// RerankInput represents the input for a rerank request: the documents to order by their
+// relevance to the query. Provider specific options go in ModelParameters.ExtraParams.
+type RerankInput struct {
+	Query           string   `json:"query"`
+	Documents       []string `json:"documents"`
+	TopN            *int     `json:"top_n,omitempty"`            // Number of results to return, all documents if not set
+	ReturnDocuments *bool    `json:"return_documents,omitempty"` // Whether to include the document text in the results
+}
+
 type InputAudioStruct struct {
 	Data   string  `json:"data"`
 	Format *string `json:"format,omitempty"`
@@ -516,9 +903,14 @@ type BifrostResponse struct {
 	ID                string                     `json:"id,omitempty"`
 	Object            string                     `json:"object,omitempty"` // text.completion, chat.completion, embedding, speech, transcribe
 	Choices           []BifrostResponseChoice    `json:"choices,omitempty"`
-	Data              []BifrostEmbedding         `json:"data,omitempty"`       // Maps to "data" field in provider responses (e.g., OpenAI embedding format)
-	Speech            *BifrostSpeech             `json:"speech,omitempty"`     // Maps to "speech" field in provider responses (e.g., OpenAI speech format)
-	Transcribe        *BifrostTranscribe         `json:"transcribe,omitempty"` // Maps to "transcribe" field in provider responses (e.g., OpenAI transcription format)
+	Data              []BifrostEmbedding         `json:"data,omitempty"`           // Maps to "data" field in provider responses (e.g., OpenAI embedding format)
+	Speech            *BifrostSpeech             `json:"speech,omitempty"`         // Maps to "speech" field in provider responses (e.g., OpenAI speech format)
+	Transcribe        *BifrostTranscribe         `json:"transcribe,omitempty"`     // Maps to "transcribe" field in provider responses (e.g., OpenAI transcription format)
+	Image             *BifrostImage              `json:"image,omitempty"`          // Generated images, for image generation requests
+	Video             *BifrostVideo              `json:"video,omitempty"`          // Video generation job, for video generation and status requests
+	TokenCount        *BifrostTokenCount         `json:"token_count,omitempty"`    // Input tokens of a chat request, for token count requests
+	Rerank            []BifrostRerankResult      `json:"rerank,omitempty"`         // Documents ordered by relevance, for rerank requests
+	SearchResults     []SearchResult             `json:"search_results,omitempty"` // Web sources of search-grounded providers, sent once per stream
 	Model             string                     `json:"model,omitempty"`
 	Created           int                        `json:"created,omitempty"` // The Unix timestamp (in seconds).
 	ServiceTier       *string                    `json:"service_tier,omitempty"`
@@ -527,6 +919,20 @@ type BifrostResponse struct {
 	ExtraFields       BifrostResponseExtraFields `json:"extra_fields"`
 }
 
+// BifrostTokenCount represents the number of input tokens a chat request would use.
+type BifrostTokenCount struct {
+	InputTokens int  `json:"input_tokens"`
+	Exact       bool `json:"exact"`            // Counted by the provider; false when approximated because it cannot count tokens
+	Cached      bool `json:"cached,omitempty"` // Served from the count of an identical request
+}
+
+// BifrostRerankResult represents a document of a rerank request and its relevance to the query.
+type BifrostRerankResult struct {
+	Index          int     `json:"index"`              // Index of the document in the request
+	RelevanceScore float64 `json:"relevance_score"`    // Higher is more relevant
+	Document       *string `json:"document,omitempty"` // Document text, if requested
+}
+
 // LLMUsage represents token usage information
 type LLMUsage struct {
 	PromptTokens            int                      `json:"prompt_tokens"`
@@ -610,9 +1016,10 @@ type FunctionCall struct {
 
 // ToolCall represents a tool call in a message
 type ToolCall struct {
-	Type     *string      `json:"type,omitempty"`
-	ID       *string      `json:"id,omitempty"`
-	Function FunctionCall `json:"function"`
+	Type           *string         `json:"type,omitempty"`
+	ID             *string         `json:"id,omitempty"`
+	Function       FunctionCall    `json:"function"`
+	ComputerAction *ComputerAction `json:"computer_action,omitempty"` // Parsed action for computer use tool calls
 }
 
 // Citation represents a citation in a response.
@@ -625,10 +1032,48 @@ type Citation struct {
 	Type       *string      `json:"type,omitempty"`
 }
 
+// Annotation types returned by providers.
+const (
+	AnnotationTypeURLCitation           = "url_citation"
+	AnnotationTypeFileCitation          = "file_citation"
+	AnnotationTypeFilePath              = "file_path"
+	AnnotationTypeContainerFileCitation = "container_file_citation"
+)
+
 // Annotation represents an annotation in a response.
+// Citation is set for url_citation annotations, the other fields are set based on Type.
 type Annotation struct {
-	Type     string   `json:"type"`
-	Citation Citation `json:"url_citation"`
+	Type                  string                 `json:"type"`
+	Citation              *Citation              `json:"url_citation,omitempty"`
+	FileCitation          *FileCitation          `json:"file_citation,omitempty"`
+	FilePath              *FilePathAnnotation    `json:"file_path,omitempty"`
+	ContainerFileCitation *ContainerFileCitation `json:"container_file_citation,omitempty"`
+}
+
+// FileCitation references a file retrieved by the file search tool.
+type FileCitation struct {
+	FileID     string  `json:"file_id"`
+	Filename   *string `json:"filename,omitempty"`
+	Index      *int    `json:"index,omitempty"`
+	StartIndex *int    `json:"start_index,omitempty"`
+	EndIndex   *int    `json:"end_index,omitempty"`
+	Quote      *string `json:"quote,omitempty"`
+}
+
+// FilePathAnnotation references a file generated by the code interpreter tool.
+type FilePathAnnotation struct {
+	FileID     string `json:"file_id"`
+	StartIndex *int   `json:"start_index,omitempty"`
+	EndIndex   *int   `json:"end_index,omitempty"`
+}
+
+// ContainerFileCitation references a file written to a code interpreter container.
+type ContainerFileCitation struct {
+	ContainerID string  `json:"container_id"`
+	FileID      string  `json:"file_id"`
+	Filename    *string `json:"filename,omitempty"`
+	StartIndex  int     `json:"start_index"`
+	EndIndex    int     `json:"end_index"`
 }
 
 type BifrostEmbedding struct {
@@ -681,6 +1126,342 @@ func (be *BifrostEmbeddingResponse) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("embedding field is neither a string nor an array of float32 nor a 2D array of float32")
 }
 
+// EmbeddingDimensionConfig configures the coercion of embeddings to a fixed dimension, e.g. to keep
+// a shared vector index usable while migrating between embedding providers.
+// Longer embeddings are truncated (Matryoshka slicing) and shorter ones are zero padded.
+type EmbeddingDimensionConfig struct {
+	Dimension int  `json:"dimension"`
+	Normalize bool `json:"normalize"` // L2-normalize after resizing, recommended when truncating
+}
+
+// Embedding transform operations recorded in EmbeddingTransform.
+const (
+	EmbeddingTransformNone     = "none"
+	EmbeddingTransformTruncate = "truncate"
+	EmbeddingTransformPad      = "pad"
+)
+
+// EmbeddingTransform records the dimension coercion applied to an embedding response.
+type EmbeddingTransform struct {
+	OriginalDimension int    `json:"original_dimension"`
+	Dimension         int    `json:"dimension"`
+	Operation         string `json:"operation"` // none, truncate or pad
+	Normalized        bool   `json:"normalized"`
+}
+
+// Default embedding batching settings.
+const (
+	DefaultEmbeddingBatchWindow    = 10 * time.Millisecond
+	DefaultEmbeddingBatchMaxInputs = 256
+)
+
+// DefaultEmbeddingBatchProviders are the providers whose embedding APIs accept many texts per call.
+var DefaultEmbeddingBatchProviders = []ModelProvider{OpenAI, Azure, Cohere, Mistral}
+
+// EmbeddingBatchConfig configures cross-request batching of embedding requests. Requests are
+// only batched with requests for the same provider, model, parameters and API key.
+type EmbeddingBatchConfig struct {
+	Window    time.Duration   `json:"window"`     // How long a batch waits for more requests, DefaultEmbeddingBatchWindow if 0
+	MaxInputs int             `json:"max_inputs"` // Maximum number of texts per provider call, DefaultEmbeddingBatchMaxInputs if 0
+	Providers []ModelProvider `json:"providers"`  // Providers to batch, DefaultEmbeddingBatchProviders if empty
+}
+
+// Default embedding migration settings.
+const (
+	DefaultEmbeddingMigrationConcurrency = 4
+	DefaultEmbeddingMigrationQueueSize   = 1000
+)
+
+// EmbeddingMigrationConfig configures the dual-write mode of an embedding model migration.
+// Successful embedding requests for Source keep their response, and their texts are queued to be
+// embedded with Target and written to Sink. Requests arriving while the queue is full are not
+// migrated and are counted as dropped.
+type EmbeddingMigrationConfig struct {
+	Source      Fallback               `json:"source"`      // Provider and model migrated away from
+	Target      Fallback               `json:"target"`      // Provider and model migrated to
+	Params      *ModelParameters       `json:"params"`      // Parameters of the target requests, those of the source request if nil
+	Concurrency int                    `json:"concurrency"` // Target requests in flight, DefaultEmbeddingMigrationConcurrency if 0
+	QueueSize   int                    `json:"queue_size"`  // Requests waiting to be migrated, DefaultEmbeddingMigrationQueueSize if 0
+	Sink        EmbeddingMigrationSink `json:"-"`           // Receives the target embeddings, required
+}
+
+// EmbeddingMigrationSink receives the embeddings computed with the target model of a migration,
+// typically writing them to the new index. Implementations must be safe for concurrent use.
+type EmbeddingMigrationSink interface {
+	WriteEmbeddings(ctx context.Context, record EmbeddingMigrationRecord) error
+}
+
+// EmbeddingMigrationSinkFunc adapts a function to an EmbeddingMigrationSink.
+type EmbeddingMigrationSinkFunc func(ctx context.Context, record EmbeddingMigrationRecord) error
+
+// WriteEmbeddings calls f(ctx, record).
+func (f EmbeddingMigrationSinkFunc) WriteEmbeddings(ctx context.Context, record EmbeddingMigrationRecord) error {
+	return f(ctx, record)
+}
+
+// EmbeddingMigrationRecord holds the texts of a migrated request with their embeddings from both
+// models, in the order of the texts.
+type EmbeddingMigrationRecord struct {
+	Texts            []string           `json:"texts"`
+	Source           Fallback           `json:"source"`
+	Target           Fallback           `json:"target"`
+	SourceEmbeddings []BifrostEmbedding `json:"source_embeddings"`
+	TargetEmbeddings []BifrostEmbedding `json:"target_embeddings"`
+}
+
+// EmbeddingMigrationStatus is the progress of an embedding migration since startup.
+type EmbeddingMigrationStatus struct {
+	Source        Fallback   `json:"source"`
+	Target        Fallback   `json:"target"`
+	Queued        int64      `json:"queued"`         // Requests waiting to be migrated
+	InFlight      int64      `json:"in_flight"`      // Requests being embedded with the target model or written to the sink
+	Completed     int64      `json:"completed"`      // Requests written to the sink
+	Failed        int64      `json:"failed"`         // Requests whose target embedding or sink write failed
+	Dropped       int64      `json:"dropped"`        // Requests not migrated because the queue was full
+	TextsMigrated int64      `json:"texts_migrated"` // Texts of the completed requests
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+}
+
+// EmbeddingBatch records that an embedding response was served by a batched provider call.
+type EmbeddingBatch struct {
+	Requests int `json:"requests"` // Number of requests merged into the provider call
+	Inputs   int `json:"inputs"`   // Number of texts sent in the provider call
+}
+
+// Default transcription chunking settings.
+const (
+	DefaultTranscriptionChunkMaxBytes    = 24 * 1024 * 1024 // Below the 25MB upload limit of OpenAI
+	DefaultTranscriptionChunkOverlap     = 2 * time.Second
+	DefaultTranscriptionChunkConcurrency = 4
+)
+
+// TranscriptionChunkingConfig configures the splitting of long audio for transcription. WAV (PCM)
+// audio is cut at the quietest point before each limit, MP3 audio at frame boundaries; audio in
+// other formats is sent as is.
+type TranscriptionChunkingConfig struct {
+	MaxChunkBytes    int           `json:"max_chunk_bytes"`    // Audio above this size is split, DefaultTranscriptionChunkMaxBytes if 0
+	MaxChunkDuration time.Duration `json:"max_chunk_duration"` // Audio above this duration is split, no limit if 0
+	Overlap          time.Duration `json:"overlap"`            // Audio repeated at the start of the next chunk, DefaultTranscriptionChunkOverlap if 0
+	Concurrency      int           `json:"concurrency"`        // Chunks transcribed at the same time, DefaultTranscriptionChunkConcurrency if 0
+}
+
+// SpeculativeDraft selects a fast model whose output is streamed while the requested model warms
+// up. Once the requested model starts streaming, the stream carries a StreamResync marker and
+// continues with its output. Experimental.
+type SpeculativeDraft struct {
+	Provider ModelProvider `json:"provider"`
+	Model    string        `json:"model"`
+}
+
+// Reasons recorded in StreamResync.
+const (
+	StreamResyncPremiumStarted = "premium_started" // Discard the draft output, the requested model's output follows
+	StreamResyncDraftPromoted  = "draft_promoted"  // The requested model failed, the draft output so far is kept and continues
+)
+
+// StreamResync marks the point where a speculative stream stops treating its draft as a draft.
+type StreamResync struct {
+	Reason        string        `json:"reason"`
+	DraftProvider ModelProvider `json:"draft_provider"`
+	DraftModel    string        `json:"draft_model"`
+	DraftChunks   int           `json:"draft_chunks"` // Draft chunks streamed before the resync
+}
+
+// DefaultAutoMaxTokensHeadroomPercent is the share of the context window kept free by automatic
+// max_tokens to absorb errors in the prompt token count.
+const DefaultAutoMaxTokensHeadroomPercent = 10
+
+// ModelLimits are the token limits of a model. Zero values are unknown.
+type ModelLimits struct {
+	ContextWindow   int `json:"context_window"`    // Prompt and completion tokens combined
+	MaxOutputTokens int `json:"max_output_tokens"` // Completion tokens
+
+	// MaxCompletionTokens marks models that take max_completion_tokens instead of max_tokens,
+	// such as OpenAI reasoning models. The limit is then set in ExtraParams["max_completion_tokens"].
+	MaxCompletionTokens bool `json:"max_completion_tokens,omitempty"`
+}
+
+// AutoMaxTokensConfig configures automatic max_tokens. Requests without max_tokens get the
+// tokens left in the context window, capped by the model's output limit; requests asking for
+// more than is left are lowered to fit. Models without a known context window are left as is.
+type AutoMaxTokensConfig struct {
+	HeadroomPercent float64                `json:"headroom_percent"` // DefaultAutoMaxTokensHeadroomPercent if 0
+	Models          map[string]ModelLimits `json:"models"`           // Limits by model name, matched as a substring (longest wins), extending and overriding the built-in table
+	// Counts the tokens of a text, an approximation of four characters per token if nil
+	CountTokens func(text string) int `json:"-"`
+}
+
+// DefaultPayloadSlimmingTargetPercent is the size, as a percent of the original prompt, that
+// payload slimming truncates the oldest turns of a conversation down to.
+const DefaultPayloadSlimmingTargetPercent = 75
+
+// PayloadSlimmingConfig configures payload slimming. A request rejected as too large is slimmed
+// down in passes: inline image data is offloaded to ImageOffloader and sent by URL (or replaced
+// by a placeholder, with a warning, if there is none), whitespace is compressed, and if the
+// prompt is still above the target size, the oldest turns of the conversation are dropped.
+// System messages and the last user turn are always kept.
+type PayloadSlimmingConfig struct {
+	TargetPercent float64 `json:"target_percent"` // DefaultPayloadSlimmingTargetPercent if 0
+	// Stores an inline image and returns a URL the provider can fetch it from, such as the
+	// OffloadImage method of the artifact store plugin. Inline images are omitted if nil.
+	ImageOffloader func(ctx context.Context, data []byte, mimeType string) (string, error) `json:"-"`
+}
+
+// Default session affinity settings.
+const (
+	DefaultSessionAffinityTTL         = time.Hour
+	DefaultSessionAffinityMaxSessions = 10000
+)
+
+// SessionAffinityConfig configures how long and for how many sessions affinity is remembered.
+type SessionAffinityConfig struct {
+	TTL         time.Duration `json:"ttl"`          // Affinity of sessions idle for longer is forgotten, DefaultSessionAffinityTTL if 0
+	MaxSessions int           `json:"max_sessions"` // Least recently used sessions are forgotten beyond this, DefaultSessionAffinityMaxSessions if 0
+}
+
+// DefaultStickyRoutingVirtualNodes is the number of points a key of weight 1 gets on the hash ring.
+const DefaultStickyRoutingVirtualNodes = 100
+
+// StickyRoutingConfig configures the consistent hash ring that maps routing keys onto the keys
+// of a provider. Each key gets points on the ring in proportion to its weight, keys of weight 0
+// get none and are drained, and adding or removing a key only moves the routing keys it takes
+// over or gives up.
+type StickyRoutingConfig struct {
+	VirtualNodes int `json:"virtual_nodes"` // Points per unit of key weight, DefaultStickyRoutingVirtualNodes if 0
+}
+
+// Default session limit settings.
+const (
+	DefaultSessionLimitsTTL           = 24 * time.Hour
+	DefaultSessionLimitsMaxSessions   = 10000
+	DefaultSessionSummaryKeepMessages = 4
+)
+
+// SessionLimitExceeded is the error type of requests rejected because their session reached
+// one of its limits. The error field holds a *SessionLimitError.
+const SessionLimitExceeded = "session_limit_exceeded"
+
+// Limits recorded in SessionLimitError.
+const (
+	SessionLimitTurns  = "max_turns"
+	SessionLimitTokens = "max_tokens"
+)
+
+// SessionLimitsConfig caps the turns and cumulative tokens of a session (BifrostContextKeySessionID),
+// protecting against runaway agent loops. Requests of a session that reached a limit fail with a
+// SessionLimitExceeded error, unless Summarize is set and the request is a chat request.
+//
+// Usage is counted in the memory of each Bifrost instance, like session affinity, and is not
+// shared between replicas: a session spread over N replicas can use up to N times its limits.
+// Deployments running several replicas should route the requests of a session to the same
+// replica, e.g. with sticky load balancing on the session ID.
+type SessionLimitsConfig struct {
+	MaxTurns    int           `json:"max_turns"`    // Requests per session, unlimited if 0
+	MaxTokens   int           `json:"max_tokens"`   // Prompt and completion tokens per session, unlimited if 0
+	TTL         time.Duration `json:"ttl"`          // Usage of sessions idle for longer is forgotten, DefaultSessionLimitsTTL if 0
+	MaxSessions int           `json:"max_sessions"` // Least recently used sessions are forgotten beyond this, DefaultSessionLimitsMaxSessions if 0
+
+	// Optional recovery for chat requests: the earlier messages of the conversation are replaced
+	// by a summary, the usage of the session starts over and the request continues.
+	Summarize *SessionSummarizeConfig `json:"summarize,omitempty"`
+}
+
+// SessionSummarizeConfig configures the summary replacing the earlier messages of a session
+// that reached its limits.
+type SessionSummarizeConfig struct {
+	Provider     ModelProvider `json:"provider,omitempty"`      // Provider writing the summary, the request's provider if empty
+	Model        string        `json:"model,omitempty"`         // Model writing the summary, the request's model if empty
+	KeepMessages int           `json:"keep_messages,omitempty"` // Latest non-system messages kept as is, DefaultSessionSummaryKeepMessages if 0
+	Prompt       string        `json:"prompt,omitempty"`        // Instruction for the summary, a built-in one if empty
+}
+
+// SessionLimitError describes the limit a session reached.
+type SessionLimitError struct {
+	SessionID string `json:"session_id"`
+	Limit     string `json:"limit"` // SessionLimitTurns or SessionLimitTokens
+	Max       int    `json:"max"`
+	Used      int    `json:"used"`
+}
+
+func (e *SessionLimitError) Error() string {
+	unit := "turns"
+	if e.Limit == SessionLimitTokens {
+		unit = "tokens"
+	}
+	return fmt.Sprintf("session %s reached its limit of %d %s (used %d)", e.SessionID, e.Max, unit, e.Used)
+}
+
+// SessionUsage is the usage of a session with limits, including the response it is set on.
+type SessionUsage struct {
+	SessionID  string `json:"session_id"`
+	Turns      int    `json:"turns"`
+	Tokens     int    `json:"tokens"`
+	Summarized bool   `json:"summarized,omitempty"` // Set when the earlier messages were summarized to continue the session
+	Summary    string `json:"summary,omitempty"`    // The summary, for clients to replace their own history with
+}
+
+// Default prompt cache manager settings.
+const (
+	DefaultPromptCacheMinPrefixChars     = 1024
+	DefaultPromptCacheMaxTrackedPrefixes = 1000
+	DefaultPromptCacheTTL                = time.Hour
+)
+
+// PromptCacheConfig configures the prompt prefix cache manager.
+type PromptCacheConfig struct {
+	MinPrefixChars      int           `json:"min_prefix_chars"`      // Shorter prefixes are not marked for caching, DefaultPromptCacheMinPrefixChars if 0
+	MaxTrackedPrefixes  int           `json:"max_tracked_prefixes"`  // Least recently used prefixes are forgotten beyond this, DefaultPromptCacheMaxTrackedPrefixes if 0
+	TTL                 time.Duration `json:"ttl"`                   // Stats of prefixes idle for longer are forgotten, DefaultPromptCacheTTL if 0
+	HoistSystemMessages bool          `json:"hoist_system_messages"` // Move system messages ahead of the conversation so they join the prefix
+}
+
+// PromptCacheHint tells a provider which part of a chat request is its stable prefix.
+// OpenAI sends PrefixKey as prompt_cache_key, Anthropic marks the end of the system prompt
+// and of the tool definitions with cache_control breakpoints, and Moonshot stores the prefix
+// in a context cache that later requests reference.
+type PromptCacheHint struct {
+	PrefixKey string
+}
+
+// PromptCacheResult reports the prompt cache usage of a chat response.
+type PromptCacheResult struct {
+	PrefixKey    string `json:"prefix_key"`
+	CacheID      string `json:"cache_id,omitempty"` // Provider-side cache identifier, where the provider has one
+	CachedTokens int    `json:"cached_tokens"`
+	Hit          bool   `json:"hit"`
+}
+
+// PromptPrefixStats is the cache usage of a stable prompt prefix on one provider and model.
+type PromptPrefixStats struct {
+	PrefixKey    string        `json:"prefix_key"`
+	Provider     ModelProvider `json:"provider"`
+	Model        string        `json:"model"`
+	CacheID      string        `json:"cache_id,omitempty"`
+	PrefixChars  int           `json:"prefix_chars"`
+	Requests     int64         `json:"requests"`
+	Hits         int64         `json:"hits"` // Responses that reported cached prompt tokens
+	CachedTokens int64         `json:"cached_tokens"`
+	LastUsed     time.Time     `json:"last_used"`
+}
+
+// Reasons recorded in CacheReset.
+const (
+	CacheResetReasonFailover       = "provider_failover" // The pinned provider failed and a fallback served the request
+	CacheResetReasonKeyChanged     = "key_changed"       // The pinned key is no longer available for the model
+	CacheResetReasonRequestChanged = "request_changed"   // The request no longer includes the pinned provider and model
+)
+
+// CacheReset marks a response served by a different provider, model or key than the previous
+// turns of its session, so the provider-side prompt cache started cold.
+type CacheReset struct {
+	SessionID        string        `json:"session_id"`
+	Reason           string        `json:"reason"`
+	PreviousProvider ModelProvider `json:"previous_provider"`
+	PreviousModel    string        `json:"previous_model"`
+	PreviousKeyID    string        `json:"previous_key_id,omitempty"`
+}
+
 // BifrostResponseChoice represents a choice in the completion result.
 // This struct can represent either a streaming or non-streaming response choice.
 // IMPORTANT: Only one of BifrostNonStreamResponseChoice or BifrostStreamResponseChoice
@@ -707,16 +1488,22 @@ type BifrostStreamResponseChoice struct {
 
 // BifrostStreamDelta represents a delta in the stream response
 type BifrostStreamDelta struct {
-	Role      *string    `json:"role,omitempty"`       // Only in the first chunk
-	Content   *string    `json:"content,omitempty"`    // May be empty string or null
-	Thought   *string    `json:"thought,omitempty"`    // May be empty string or null
-	Refusal   *string    `json:"refusal,omitempty"`    // Refusal content if any
-	ToolCalls []ToolCall `json:"tool_calls,omitempty"` // If tool calls used (supports incremental updates)
+	Role        *string           `json:"role,omitempty"`        // Only in the first chunk
+	Content     *string           `json:"content,omitempty"`     // May be empty string or null
+	Thought     *string           `json:"thought,omitempty"`     // May be empty string or null
+	Refusal     *string           `json:"refusal,omitempty"`     // Refusal content if any
+	ToolCalls   []ToolCall        `json:"tool_calls,omitempty"`  // If tool calls used (supports incremental updates)
+	Annotations []Annotation      `json:"annotations,omitempty"` // Annotations such as citations, usually sent with the final content delta
+	Citations   []MessageCitation `json:"citations,omitempty"`   // Normalized citations, usually sent once per stream
+	Images      []SearchImage     `json:"images,omitempty"`      // Images returned by search-grounded providers
+	// Provider signature of the thought, sent once the thought is complete
+	ThoughtSignature *string `json:"thought_signature,omitempty"`
 }
 
 type BifrostSpeech struct {
 	Usage *AudioLLMUsage `json:"usage,omitempty"`
 	Audio []byte         `json:"audio"`
+	URL   string         `json:"url,omitempty"` // Signed URL of the audio when a plugin moved it to an artifact store, Audio is then empty
 
 	*BifrostSpeechStreamResponse
 }
@@ -789,6 +1576,74 @@ type TranscriptionUsage struct {
 	Seconds           *int               `json:"seconds,omitempty"` // For duration-based usage
 }
 
+// BifrostImage represents image generation response data
+type BifrostImage struct {
+	Images []GeneratedImage `json:"images"`
+	Usage  *ImageUsage      `json:"usage,omitempty"`
+}
+
+// GeneratedImage is a single generated image, either inline or as a URL.
+type GeneratedImage struct {
+	B64JSON       string  `json:"b64_json,omitempty"`
+	URL           string  `json:"url,omitempty"` // Provider hosted or signed artifact store URL, may expire
+	MimeType      string  `json:"mime_type,omitempty"`
+	RevisedPrompt *string `json:"revised_prompt,omitempty"`
+	Seed          *int    `json:"seed,omitempty"`
+}
+
+// ImageUsage represents the usage of an image generation request.
+type ImageUsage struct {
+	Images  int      `json:"images"`
+	Credits *float64 `json:"credits,omitempty"` // Provider credits charged, where reported
+}
+
+// VideoJobStatus is the state of a video generation job.
+type VideoJobStatus string
+
+const (
+	VideoJobQueued     VideoJobStatus = "queued"
+	VideoJobProcessing VideoJobStatus = "processing"
+	VideoJobCompleted  VideoJobStatus = "completed"
+	VideoJobFailed     VideoJobStatus = "failed"
+)
+
+// Done reports whether the job reached a final state.
+func (s VideoJobStatus) Done() bool {
+	return s == VideoJobCompleted || s == VideoJobFailed
+}
+
+// BifrostVideo represents a video generation job and, once completed, its videos.
+type BifrostVideo struct {
+	JobID         string           `json:"job_id"` // Provider job ID, pass it in a VideoJobInput to poll the job
+	Status        VideoJobStatus   `json:"status"`
+	Videos        []GeneratedVideo `json:"videos,omitempty"`
+	FailureReason *string          `json:"failure_reason,omitempty"`
+	Progress      *float64         `json:"progress,omitempty"` // 0 to 1, where reported
+	CreatedAt     *int             `json:"created_at,omitempty"`
+	Prompt        string           `json:"prompt,omitempty"`
+	AspectRatio   *string          `json:"aspect_ratio,omitempty"`
+	Resolution    *string          `json:"resolution,omitempty"`
+	Duration      *float64         `json:"duration,omitempty"` // Seconds
+}
+
+// GeneratedVideo is a single generated video. URLs are provider hosted, or signed artifact store
+// URLs, and may expire.
+type GeneratedVideo struct {
+	URL          string  `json:"url"`
+	MimeType     string  `json:"mime_type,omitempty"`
+	ThumbnailURL *string `json:"thumbnail_url,omitempty"`
+}
+
+// VideoWebhookEvent is the JSON body posted to the WebhookURL of a video generation request
+// once its job completes or fails, or when the job could not be polled to the end.
+type VideoWebhookEvent struct {
+	JobID    string           `json:"job_id"`
+	Provider ModelProvider    `json:"provider"`
+	Model    string           `json:"model"`
+	Response *BifrostResponse `json:"response,omitempty"` // Last status of the job
+	Error    *BifrostError    `json:"error,omitempty"`    // Set if polling the job failed
+}
+
 // BifrostResponseExtraFields contains additional fields in a response.
 type BifrostResponseExtraFields struct {
 	Provider    ModelProvider      `json:"provider"`
@@ -799,6 +1654,57 @@ type BifrostResponseExtraFields struct {
 	ChunkIndex  int                `json:"chunk_index"` // used for streaming responses to identify the chunk index, will be 0 for non-streaming responses
 	RawResponse interface{}        `json:"raw_response,omitempty"`
 	CacheDebug  *BifrostCacheDebug `json:"cache_debug,omitempty"`
+	AbortReason *string            `json:"abort_reason,omitempty"` // set on the terminal chunk of a stream stopped via AbortableStream.Abort
+
+	EmbeddingTransform *EmbeddingTransform `json:"embedding_transform,omitempty"` // set when EmbeddingDimensionConfig resized the embeddings
+	CacheReset         *CacheReset         `json:"cache_reset,omitempty"`         // set when session affinity broke and the prompt cache started cold
+	PromptCache        *PromptCacheResult  `json:"prompt_cache,omitempty"`        // set when the prompt cache manager marked the request's prefix
+	EmbeddingBatch     *EmbeddingBatch     `json:"embedding_batch,omitempty"`     // set when the request was merged into a batched provider call
+	Draft              bool                `json:"draft,omitempty"`               // set on stream chunks from a speculative draft model
+	Deprecation        *ModelDeprecation   `json:"deprecation,omitempty"`         // set when the requested model is scheduled for retirement
+	SessionUsage       *SessionUsage       `json:"session_usage,omitempty"`       // set on responses of sessions with limits
+	TraceID            string              `json:"trace_id,omitempty"`            // set when agent tracing is enabled, pass it on as the trace ID of the next steps
+	AudioChunks        int                 `json:"audio_chunks,omitempty"`        // set when the transcription was stitched from this many chunks of audio
+	Warnings           []Warning           `json:"warnings,omitempty"`            // behavior changes that did not fail the request, such as dropped parameters
+	ProviderTiming     *ProviderTiming     `json:"provider_timing,omitempty"`     // set when the provider reports a latency breakdown, e.g. Groq or Cerebras
+	UpstreamProvider   string              `json:"upstream_provider,omitempty"`   // set by routers such as OpenRouter to the provider that served the request
+
+	LanguageCorrections []LanguageCorrection `json:"language_corrections,omitempty"` // set when the language plugin found choices in the wrong language
+}
+
+// ProviderTiming is the latency breakdown of a request as reported by the provider, in seconds.
+// On streams it is set on the final chunk.
+type ProviderTiming struct {
+	RequestID             string  `json:"request_id,omitempty"`               // Provider's ID of the request, e.g. x_groq.id
+	QueueTime             float64 `json:"queue_time"`                         // Time waiting in the provider's queue
+	PromptTime            float64 `json:"prompt_time"`                        // Time processing the prompt
+	CompletionTime        float64 `json:"completion_time"`                    // Time generating the completion
+	TotalTime             float64 `json:"total_time"`                         // Prompt and completion time
+	OutputTokensPerSecond float64 `json:"output_tokens_per_second,omitempty"` // Completion tokens divided by completion time
+}
+
+// LanguageCorrection records a response choice that was not in the required language and how
+// the language plugin corrected it.
+type LanguageCorrection struct {
+	ChoiceIndex int       `json:"choice_index"`
+	Required    string    `json:"required"`           // Required language, ISO 639-1 code
+	Detected    string    `json:"detected"`           // Language detected in the original content
+	Confidence  float64   `json:"confidence"`         // Confidence of the detection, in [0, 1]
+	Action      string    `json:"action"`             // "reprompt" or "translate"
+	Provider    string    `json:"provider,omitempty"` // Provider that produced the corrected content
+	Model       string    `json:"model,omitempty"`    // Model that produced the corrected content
+	Corrected   bool      `json:"corrected"`          // False if the correction failed, the original content is kept
+	Error       string    `json:"error,omitempty"`    // Why the correction failed
+	Usage       *LLMUsage `json:"usage,omitempty"`    // Usage of the correction request, not included in the response usage
+}
+
+// ModelDeprecation warns that the requested model is scheduled for retirement by its provider.
+type ModelDeprecation struct {
+	Model         string `json:"model"`
+	ShutdownDate  string `json:"shutdown_date"` // YYYY-MM-DD
+	Replacement   string `json:"replacement,omitempty"`
+	DaysRemaining int    `json:"days_remaining"` // Negative once the shutdown date has passed
+	Message       string `json:"message"`
 }
 
 // BifrostCacheDebug represents debug information about the cache.
@@ -819,14 +1725,64 @@ type BifrostCacheDebug struct {
 }
 
 const (
-	RequestCancelled = "request_cancelled"
+	RequestCancelled     = "request_cancelled"
+	ProviderUnavailable  = "provider_unavailable"  // The provider is draining or inactive, fallbacks are tried
+	UnsupportedOperation = "unsupported_operation" // The provider does not support the request type
+	InternalPanic        = "internal_panic"        // A provider panicked while serving the request, fallbacks are tried
 )
 
+// QueueStatus reports a request that is waiting for provider capacity.
+type QueueStatus struct {
+	Provider        ModelProvider `json:"provider"`
+	Position        int           `json:"position"`                    // Requests ahead of this one in the provider queue
+	EstimatedWaitMs *int64        `json:"estimated_wait_ms,omitempty"` // Nil until the provider's service time is known
+	WaitedMs        int64         `json:"waited_ms"`                   // Time spent waiting so far
+}
+
+// QueueStatusCallback is called periodically while a request waits in a provider queue.
+// Set it in the context with BifrostContextKeyQueueStatus.
+type QueueStatusCallback func(status QueueStatus)
+
+// FairQueuingConfig configures weighted fair queuing of provider queues, across the tenants set
+// with BifrostContextKeyTenant. Each tenant waiting for a provider gets a share of its workers in proportion to its weight, and within a tenant
+// requests are served in FIFO order. A tenant alone in the queue still gets every worker. When
+// requests are dropped because the queue is full, the newest request of the tenant furthest
+// above its share makes room for the incoming one instead.
+type FairQueuingConfig struct {
+	Weights       map[string]float64 `json:"weights,omitempty"`        // Weight by tenant, DefaultWeight for the others
+	DefaultWeight float64            `json:"default_weight,omitempty"` // Weight of tenants not in Weights, 1 if 0
+}
+
+// TenantQueueStats is the queue of a tenant for a provider under fair queuing. Requests without
+// a tenant are counted under the empty tenant.
+type TenantQueueStats struct {
+	Provider   ModelProvider `json:"provider"`
+	Tenant     string        `json:"tenant"`
+	Weight     float64       `json:"weight"`
+	Queued     int           `json:"queued"`      // Requests waiting for a worker
+	Dispatched int64         `json:"dispatched"`  // Requests handed to a worker
+	Dropped    int64         `json:"dropped"`     // Requests dropped, or evicted for another tenant, because the queue was full
+	AvgWaitMs  float64       `json:"avg_wait_ms"` // Moving average of the time dispatched requests waited
+}
+
 // BifrostStream represents a stream of responses from the Bifrost system.
-// Either BifrostResponse or BifrostError will be non-nil.
+// Either BifrostResponse or BifrostError will be non-nil, except for queue status chunks
+// sent before the stream starts when BifrostContextKeyQueueStatusEvents is set.
 type BifrostStream struct {
 	*BifrostResponse
 	*BifrostError
+	QueueStatus *QueueStatus  `json:"queue_status,omitempty"`
+	Resync      *StreamResync `json:"resync,omitempty"`
+	Binary      *BinaryChunk  `json:"-"` // Raw content of the chunk, written as is by transports streaming binary
+}
+
+// BinaryChunk is the raw binary content of a stream chunk, such as a piece of speech audio.
+// Transports streaming binary write Data as is, without the base64 inflation of JSON; JSON
+// streams carry the same content in the response of the chunk.
+type BinaryChunk struct {
+	Sequence    int    // Position of the chunk among the binary chunks of the stream, from 0
+	ContentType string // Mime type of the content, e.g. audio/mpeg
+	Data        []byte
 }
 
 // BifrostError represents an error from the Bifrost system.
@@ -835,20 +1791,92 @@ type BifrostStream struct {
 // - AllowFallbacks = &true: Bifrost will try fallback providers if available
 // - AllowFallbacks = &false: Bifrost will return this error immediately, no fallbacks
 // - AllowFallbacks = nil: Treated as true by default (fallbacks allowed for resilience)
+//
+// Plugins rejecting a request for a policy reason, such as a budget or a rate limit, should set
+// Origin to ErrorOriginPolicy.
 type BifrostError struct {
 	Provider       ModelProvider  `json:"-"`
 	EventID        *string        `json:"event_id,omitempty"`
 	Type           *string        `json:"type,omitempty"`
 	IsBifrostError bool           `json:"is_bifrost_error"`
 	StatusCode     *int           `json:"status_code,omitempty"`
+	Origin         ErrorOrigin    `json:"origin,omitempty"` // Whose fault the error is, see GetOrigin
 	Error          ErrorField     `json:"error"`
 	AllowFallbacks *bool          `json:"-"` // Optional: Controls fallback behavior (nil = true by default)
 	StreamControl  *StreamControl `json:"-"` // Optional: Controls stream behavior
 }
 
+// ErrorOrigin tells where an error comes from, so that error metrics and alerts can tell
+// the errors of clients from those of providers and of Bifrost itself.
+type ErrorOrigin string
+
+const (
+	ErrorOriginClientRequest   ErrorOrigin = "client_request"   // The request is invalid or was cancelled by the client
+	ErrorOriginProvider        ErrorOrigin = "provider"         // The provider answered with an error or an invalid response
+	ErrorOriginNetwork         ErrorOrigin = "network"          // The provider could not be reached or timed out
+	ErrorOriginBifrostInternal ErrorOrigin = "bifrost_internal" // Bifrost failed, e.g. a misconfiguration, a full queue or a panic
+	ErrorOriginPolicy          ErrorOrigin = "policy"           // A policy rejected the request, e.g. a budget, rate limit or guardrail
+)
+
+// GetOrigin returns the origin of the error. Errors without an origin, such as those built by
+// plugins that do not set it, are attributed to Bifrost if IsBifrostError is set and to the
+// provider otherwise.
+func (e *BifrostError) GetOrigin() ErrorOrigin {
+	if e == nil {
+		return ""
+	}
+	if e.Origin != "" {
+		return e.Origin
+	}
+	if e.IsBifrostError {
+		return ErrorOriginBifrostInternal
+	}
+	return ErrorOriginProvider
+}
+
+// PanicError is a panic recovered from a provider or a plugin hook. It is the Error of the
+// BifrostError of a request whose provider panicked, and is logged with its stack.
+type PanicError struct {
+	Origin string // Provider or plugin hook that panicked, e.g. "openai" or "PostHook of telemetry"
+	Value  any    // Value passed to panic
+	Stack  []byte // Stack of the goroutine that panicked
+}
+
+// NewPanicError creates a PanicError from the value returned by recover. It must be called
+// from the deferred function that recovered, so the stack includes the panicking frames.
+func NewPanicError(origin string, recovered any) *PanicError {
+	return &PanicError{Origin: origin, Value: recovered, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in %s: %v", e.Origin, e.Value)
+}
+
+// BifrostError converts a provider panic into the error of the request.
+func (e *PanicError) BifrostError(provider ModelProvider) *BifrostError {
+	statusCode := http.StatusInternalServerError
+	errorType := InternalPanic
+	return &BifrostError{
+		IsBifrostError: true,
+		Provider:       provider,
+		StatusCode:     &statusCode,
+		Origin:         ErrorOriginBifrostInternal,
+		Type:           &errorType,
+		Error: ErrorField{
+			Type:    &errorType,
+			Message: fmt.Sprintf("%s panicked while serving the request: %v", e.Origin, e.Value),
+			Error:   e,
+		},
+	}
+}
+
+// StreamControl lets a plugin returning an error from a stream PostHook control what happens to the stream.
+// A plugin aborting the stream should set BifrostContextKeyStreamEndIndicator so that the plugins after it
+// treat the error as the end of the stream.
 type StreamControl struct {
-	LogError   *bool `json:"log_error,omitempty"`   // Optional: Controls logging of error
-	SkipStream *bool `json:"skip_stream,omitempty"` // Optional: Controls skipping of stream chunk
+	LogError    *bool `json:"log_error,omitempty"`    // Optional: Controls logging of error
+	SkipStream  *bool `json:"skip_stream,omitempty"`  // Optional: Controls skipping of stream chunk
+	AbortStream *bool `json:"abort_stream,omitempty"` // Optional: Ends the stream with the error and cancels the upstream request
 }
 
 // ErrorField represents detailed error information.
@@ -0,0 +1,180 @@
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ConversationFormatVersion is the version of the conversation format written by this version of
+// Bifrost. Readers accept every version up to it; fields are only ever added within a version.
+const ConversationFormatVersion = 1
+
+// Conversation is the canonical export of a conversation: its messages, including tool calls and
+// results, the artifacts produced along the way, the usage of each turn and the provider and
+// model that produced each answer. It is a stable, versioned JSON format for handing a
+// conversation off between environments or to offline evaluation, written with Export and read
+// back with ParseConversation.
+type Conversation struct {
+	Version    int                   `json:"version"`
+	ID         string                `json:"id,omitempty"`
+	Provider   ModelProvider         `json:"provider,omitempty"` // Provider the conversation continues with
+	Model      string                `json:"model,omitempty"`    // Model the conversation continues with
+	Messages   []ConversationMessage `json:"messages"`
+	Usage      *LLMUsage             `json:"usage,omitempty"` // Total of the usage of the messages
+	Metadata   map[string]string     `json:"metadata,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	ExportedAt time.Time             `json:"exported_at"`
+}
+
+// ConversationMessage is a message of a conversation with what is known of how it was produced.
+type ConversationMessage struct {
+	Message   BifrostMessage         `json:"message"`
+	Lineage   *ModelLineage          `json:"lineage,omitempty"`   // For messages produced by a model
+	Usage     *LLMUsage              `json:"usage,omitempty"`     // Usage of the request that produced the message
+	Artifacts []ConversationArtifact `json:"artifacts,omitempty"` // Binary outputs produced with the message
+	CreatedAt *time.Time             `json:"created_at,omitempty"`
+}
+
+// ModelLineage records the provider and model that produced a message.
+type ModelLineage struct {
+	Provider         ModelProvider `json:"provider"`
+	Model            string        `json:"model"`
+	UpstreamProvider string        `json:"upstream_provider,omitempty"` // Provider a router such as OpenRouter sent the request to
+	ResponseID       string        `json:"response_id,omitempty"`
+}
+
+// ConversationArtifactType is the kind of content of an artifact.
+type ConversationArtifactType string
+
+const (
+	ConversationArtifactImage ConversationArtifactType = "image"
+	ConversationArtifactAudio ConversationArtifactType = "audio"
+	ConversationArtifactVideo ConversationArtifactType = "video"
+	ConversationArtifactFile  ConversationArtifactType = "file"
+)
+
+// ConversationArtifact is a binary output of a conversation, such as a generated image or speech.
+// It is referenced by URL, or carried inline as base64 when it has none.
+type ConversationArtifact struct {
+	Type     ConversationArtifactType `json:"type"`
+	Name     string                   `json:"name,omitempty"`
+	MimeType string                   `json:"mime_type,omitempty"`
+	URL      string                   `json:"url,omitempty"`
+	Data     string                   `json:"data,omitempty"` // Base64 content, when there is no URL
+}
+
+// NewConversation starts a conversation with messages, such as its system prompt.
+func NewConversation(messages ...BifrostMessage) *Conversation {
+	conversation := &Conversation{
+		Version:   ConversationFormatVersion,
+		CreatedAt: time.Now(),
+	}
+	for _, message := range messages {
+		conversation.Append(message, nil, nil)
+	}
+	return conversation
+}
+
+// Append adds a message to the conversation, with the lineage and usage of the request that
+// produced it, if any. The usage is added to the total of the conversation.
+func (c *Conversation) Append(message BifrostMessage, lineage *ModelLineage, usage *LLMUsage) *ConversationMessage {
+	now := time.Now()
+	c.Messages = append(c.Messages, ConversationMessage{
+		Message:   message,
+		Lineage:   lineage,
+		Usage:     usage,
+		CreatedAt: &now,
+	})
+	if usage != nil {
+		if c.Usage == nil {
+			c.Usage = &LLMUsage{}
+		}
+		c.Usage.PromptTokens += usage.PromptTokens
+		c.Usage.CompletionTokens += usage.CompletionTokens
+		c.Usage.TotalTokens += usage.TotalTokens
+	}
+	return &c.Messages[len(c.Messages)-1]
+}
+
+// AppendResponse adds the message of the first choice of a chat response to the conversation,
+// with the provider, model and usage of the response. It returns nil if the response has no
+// message.
+func (c *Conversation) AppendResponse(response *BifrostResponse) *ConversationMessage {
+	if response == nil || len(response.Choices) == 0 || response.Choices[0].BifrostNonStreamResponseChoice == nil {
+		return nil
+	}
+	lineage := &ModelLineage{
+		Provider:         response.ExtraFields.Provider,
+		Model:            response.Model,
+		UpstreamProvider: response.ExtraFields.UpstreamProvider,
+		ResponseID:       response.ID,
+	}
+	return c.Append(response.Choices[0].Message, lineage, response.Usage)
+}
+
+// History returns the messages of the conversation, as sent in a chat request to continue it.
+func (c *Conversation) History() []BifrostMessage {
+	history := make([]BifrostMessage, len(c.Messages))
+	for i, message := range c.Messages {
+		history[i] = message.Message
+	}
+	return history
+}
+
+// Validate checks that the conversation can be read by this version of Bifrost: its version is
+// known, every message has a valid role, and every tool result answers a tool call made before
+// it, when both have IDs.
+func (c *Conversation) Validate() error {
+	if c.Version < 1 || c.Version > ConversationFormatVersion {
+		return fmt.Errorf("unsupported conversation format version %d, expected 1 to %d", c.Version, ConversationFormatVersion)
+	}
+	toolCalls := make(map[string]bool)
+	for i, message := range c.Messages {
+		switch message.Message.Role {
+		case ModelChatMessageRoleAssistant:
+			if message.Message.AssistantMessage != nil && message.Message.ToolCalls != nil {
+				for _, toolCall := range *message.Message.ToolCalls {
+					if toolCall.ID != nil {
+						toolCalls[*toolCall.ID] = true
+					}
+				}
+			}
+		case ModelChatMessageRoleTool:
+			if message.Message.ToolMessage != nil && message.Message.ToolCallID != nil && !toolCalls[*message.Message.ToolCallID] {
+				return fmt.Errorf("message %d: tool message answers unknown tool call %s", i, *message.Message.ToolCallID)
+			}
+		case ModelChatMessageRoleUser, ModelChatMessageRoleSystem, ModelChatMessageRoleDeveloper, ModelChatMessageRoleChatbot:
+		default:
+			return fmt.Errorf("message %d: invalid role %q", i, message.Message.Role)
+		}
+		for j, artifact := range message.Artifacts {
+			if artifact.URL == "" && artifact.Data == "" {
+				return fmt.Errorf("message %d: artifact %d has neither a url nor data", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// Export stamps the conversation with the time of the export and encodes it as indented JSON.
+func (c *Conversation) Export() ([]byte, error) {
+	c.ExportedAt = time.Now()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode conversation: %w", err)
+	}
+	return data, nil
+}
+
+// ParseConversation reads a conversation exported by Bifrost and validates it.
+func ParseConversation(data []byte) (*Conversation, error) {
+	var conversation Conversation
+	if err := json.Unmarshal(data, &conversation); err != nil {
+		return nil, fmt.Errorf("failed to decode conversation: %w", err)
+	}
+	if err := conversation.Validate(); err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
@@ -0,0 +1,50 @@
+package schemas
+
+import "time"
+
+// EventType identifies the kind of an event delivered to the subscribers of a Bifrost client,
+// see Bifrost.Subscribe.
+type EventType string
+
+const (
+	EventRequestStarted        EventType = "request.started"         // A request was received
+	EventRequestCompleted      EventType = "request.completed"       // A request succeeded, at the end of the stream for streams
+	EventRequestFailed         EventType = "request.failed"          // A request failed on its provider and every fallback
+	EventRequestFallback       EventType = "request.fallback"        // A request failed on a provider and goes on to a fallback
+	EventRequestBlocked        EventType = "request.blocked"         // A plugin rejected a request, e.g. governance for an exhausted budget
+	EventProviderStateChanged  EventType = "provider.state_changed"  // A provider started draining, became inactive or was activated
+	EventProviderHealthChanged EventType = "provider.health_changed" // A provider started or stopped failing requests
+)
+
+// Event is an event of a Bifrost client. Request or Provider is set depending on its type.
+type Event struct {
+	Type     EventType      `json:"type"`
+	Time     time.Time      `json:"time"`
+	Request  *RequestEvent  `json:"request,omitempty"`
+	Provider *ProviderEvent `json:"provider,omitempty"`
+	// Events of the subscription dropped before this one because its channel was full
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// RequestEvent describes the request of a request event.
+type RequestEvent struct {
+	ID          uint64        `json:"id"` // Same for all events of a request
+	RequestType RequestType   `json:"request_type"`
+	Provider    ModelProvider `json:"provider"` // Provider of the attempt, or that served a completed request
+	Model       string        `json:"model"`
+	Fallback    *Fallback     `json:"fallback,omitempty"` // Next provider and model, for fallback events
+	Plugin      string        `json:"plugin,omitempty"`   // Plugin that rejected the request, for blocked events
+	Latency     time.Duration `json:"latency,omitempty"`  // Since the request was received, for completed and failed events
+	Usage       *LLMUsage     `json:"usage,omitempty"`    // Usage reported for completed requests
+	Error       *BifrostError `json:"error,omitempty"`    // For failed, fallback and blocked events
+}
+
+// ProviderEvent describes the provider of a provider event.
+type ProviderEvent struct {
+	Provider      ModelProvider `json:"provider"`
+	State         ProviderState `json:"state,omitempty"`          // New state, for state changes
+	PreviousState ProviderState `json:"previous_state,omitempty"` // For state changes
+	Healthy       bool          `json:"healthy"`                  // For health changes
+	Failures      int           `json:"failures,omitempty"`       // Consecutive failed requests of an unhealthy provider
+	Error         *BifrostError `json:"error,omitempty"`          // Last error of an unhealthy provider
+}
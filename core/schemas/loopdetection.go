@@ -0,0 +1,51 @@
+package schemas
+
+import "fmt"
+
+// Default loop detection settings.
+const (
+	DefaultLoopDetectionThreshold  = 3
+	DefaultLoopDetectionWindow     = 20
+	DefaultLoopDetectionSimilarity = 0.9
+)
+
+// LoopDetected is the error type of requests aborted because their agent loop keeps repeating
+// the same tool call. The error field holds a *LoopDetectedError.
+const LoopDetected = "loop_detected"
+
+// LoopDetectionAction is what is done with a request whose agent loop repeats itself.
+type LoopDetectionAction string
+
+const (
+	LoopDetectionIntervene LoopDetectionAction = "intervene" // Append a corrective system message and send the request
+	LoopDetectionAbort     LoopDetectionAction = "abort"     // Fail the request with a LoopDetected error
+)
+
+// LoopDetectionConfig configures the detection of agent loops that keep making the same tool
+// call. The tool calls in the history of a chat request are fingerprinted by tool name,
+// arguments and result, and the loop is detected when the latest call was made
+// Threshold times, counting itself, among the latest Window calls.
+type LoopDetectionConfig struct {
+	Threshold int `json:"threshold"` // Calls of the same tool with similar arguments and results, DefaultLoopDetectionThreshold if below 2
+	Window    int `json:"window"`    // Latest tool calls compared, DefaultLoopDetectionWindow if 0
+	// Least similarity, from 0 to 1, of the arguments and results of two calls of the same tool for
+	// them to count as a repetition, DefaultLoopDetectionSimilarity if 0. 1 only counts identical
+	// calls.
+	Similarity float64             `json:"similarity"`
+	Action     LoopDetectionAction `json:"action,omitempty"`  // LoopDetectionIntervene if empty
+	Message    string              `json:"message,omitempty"` // Corrective system message of LoopDetectionIntervene, a built-in one if empty
+	// Repetitions at which LoopDetectionIntervene gives up and aborts, for models that ignore the
+	// corrective message. Never aborts if 0.
+	AbortAfter int `json:"abort_after,omitempty"`
+}
+
+// LoopDetectedError describes the repeated tool call of an agent loop.
+type LoopDetectedError struct {
+	Tool        string `json:"tool"`
+	Repetitions int    `json:"repetitions"` // Similar calls among the latest ones, counting the latest
+	Threshold   int    `json:"threshold"`
+}
+
+func (e *LoopDetectedError) Error() string {
+	return fmt.Sprintf("agent loop detected: tool %s was called %d times with the same arguments and results (threshold %d)", e.Tool, e.Repetitions, e.Threshold)
+}
@@ -1,6 +1,11 @@
 // Package schemas defines the core schemas and types used by the Bifrost system.
 package schemas
 
+import (
+	"context"
+	"time"
+)
+
 // MCPServerInstance represents an MCP server instance for InProcess connections.
 // This should be a *github.com/mark3labs/mcp-go/server.MCPServer instance.
 // We use interface{} to avoid creating a dependency on the mcp-go package in schemas.
@@ -9,9 +14,75 @@ type MCPServerInstance interface{}
 // MCPConfig represents the configuration for MCP integration in Bifrost.
 // It enables tool auto-discovery and execution from local and external MCP servers.
 type MCPConfig struct {
-	ClientConfigs []MCPClientConfig `json:"client_configs,omitempty"` // Per-client execution configurations
+	ClientConfigs []MCPClientConfig        `json:"client_configs,omitempty"` // Per-client execution configurations
+	ToolPolicies  map[string]MCPToolPolicy `json:"tool_policies,omitempty"`  // Per-tool execution policies, keyed by tool name
+
+	// ApprovalHandler is called for tools whose policy requires human approval. Execution of the
+	// tool is paused until it returns. Tools requiring approval are rejected if it is nil.
+	ApprovalHandler MCPToolApprovalHandler `json:"-"`
+	// AuditHandler receives an audit record for every tool invocation, including rejected ones.
+	AuditHandler MCPToolAuditHandler `json:"-"`
+}
+
+// MCPToolPolicy defines the execution constraints applied to a single tool.
+type MCPToolPolicy struct {
+	TimeoutInSeconds        int                                  `json:"timeout_in_seconds,omitempty"`        // Maximum execution time, 0 means no limit
+	MaxConcurrentExecutions int                                  `json:"max_concurrent_executions,omitempty"` // Maximum parallel executions, 0 means no limit
+	ArgumentConstraints     map[string]MCPToolArgumentConstraint `json:"argument_constraints,omitempty"`      // Allowed ranges for arguments, keyed by argument name
+	RequireApproval         bool                                 `json:"require_approval,omitempty"`          // If true, every execution must be approved by the ApprovalHandler
+}
+
+// MCPToolArgumentConstraint restricts the values a model may pass for a tool argument.
+type MCPToolArgumentConstraint struct {
+	Required      bool          `json:"required,omitempty"`       // Argument must be present
+	Min           *float64      `json:"min,omitempty"`            // Minimum numeric value (inclusive)
+	Max           *float64      `json:"max,omitempty"`            // Maximum numeric value (inclusive)
+	MaxLength     *int          `json:"max_length,omitempty"`     // Maximum string length
+	Pattern       *string       `json:"pattern,omitempty"`        // Regular expression string values must match
+	AllowedValues []interface{} `json:"allowed_values,omitempty"` // Exhaustive list of allowed values
+}
+
+// MCPToolApprovalRequest is the event emitted when a tool execution needs human approval.
+type MCPToolApprovalRequest struct {
+	ToolName    string                 `json:"tool_name"`
+	ClientName  string                 `json:"client_name"`
+	ToolCallID  *string                `json:"tool_call_id,omitempty"`
+	Arguments   map[string]interface{} `json:"arguments"`
+	RequestedAt time.Time              `json:"requested_at"`
 }
 
+// MCPToolApprovalHandler decides whether a tool execution may proceed. It may block, e.g. while
+// waiting on a user, and should honour ctx cancellation. A non-empty reason is recorded on rejection.
+type MCPToolApprovalHandler func(ctx context.Context, req MCPToolApprovalRequest) (approved bool, reason string, err error)
+
+// MCPToolAuditStatus is the outcome of a tool invocation.
+type MCPToolAuditStatus string
+
+const (
+	MCPToolAuditStatusSuccess  MCPToolAuditStatus = "success"  // Tool executed successfully
+	MCPToolAuditStatusError    MCPToolAuditStatus = "error"    // Tool execution failed
+	MCPToolAuditStatusTimeout  MCPToolAuditStatus = "timeout"  // Tool execution exceeded its policy timeout
+	MCPToolAuditStatusDenied   MCPToolAuditStatus = "denied"   // Arguments violated the tool policy
+	MCPToolAuditStatusRejected MCPToolAuditStatus = "rejected" // Approval was not granted
+)
+
+// MCPToolAuditRecord describes a single tool invocation.
+type MCPToolAuditRecord struct {
+	ToolName   string                 `json:"tool_name"`
+	ClientName string                 `json:"client_name,omitempty"`
+	ToolCallID *string                `json:"tool_call_id,omitempty"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	Approved   *bool                  `json:"approved,omitempty"` // Set only for tools requiring approval
+	Status     MCPToolAuditStatus     `json:"status"`
+	Error      string                 `json:"error,omitempty"`
+	StartedAt  time.Time              `json:"started_at"`
+	Duration   time.Duration          `json:"duration"`
+}
+
+// MCPToolAuditHandler receives tool audit records. It is called synchronously after each
+// invocation, so it should hand off expensive work.
+type MCPToolAuditHandler func(record MCPToolAuditRecord)
+
 // MCPClientConfig defines tool filtering for an MCP client.
 type MCPClientConfig struct {
 	Name             string            `json:"name"`                        // Client name
@@ -0,0 +1,89 @@
+package schemas
+
+import "context"
+
+// Names of the built-in provider middleware, in their order in the chain from the outermost to
+// the innermost. Custom middleware can be inserted before or after any of them.
+const (
+	MiddlewarePayloadSlimming = "payload_slimming" // Slims requests rejected as too large and sends them again once
+	MiddlewareRetry           = "retry"            // Retries failed attempts with backoff, per the request policy
+	MiddlewareTimeout         = "timeout"          // Bounds each attempt by the request's timeout override
+	MiddlewarePanicRecovery   = "panic_recovery"   // Converts a panic of the provider into an InternalPanic error
+)
+
+// ProviderCall is a single call of a provider, made once the request went through the plugin
+// PreHooks and a key was selected for it.
+type ProviderCall struct {
+	Context  context.Context
+	Type     RequestType
+	Provider ModelProvider   // Provider serving the call, the custom provider name for custom providers
+	Config   *ProviderConfig // Config of the provider serving the call
+	Model    string
+	Key      Key
+	Input    RequestInput
+	Params   *ModelParameters
+
+	// PostHookRunner runs the plugin PostHooks on every chunk of a stream. Only set for stream calls.
+	PostHookRunner PostHookRunner
+}
+
+// ProviderCaller makes provider calls. The innermost caller of the middleware chain calls the
+// provider itself, and every middleware wraps the caller that follows it in the chain.
+//
+// A middleware may call the next caller any number of times, change the call before passing it
+// on, or answer it without calling the next caller at all. Changes that should only apply to a
+// single call of the next caller are made on a copy of the call.
+type ProviderCaller interface {
+	// Call makes a non-streaming call.
+	Call(call *ProviderCall) (*BifrostResponse, *BifrostError)
+
+	// CallStream makes a streaming call. The stream is returned once established.
+	CallStream(call *ProviderCall) (chan *BifrostStream, *BifrostError)
+}
+
+// ProviderMiddleware wraps provider calls with a cross-cutting concern such as retries, circuit
+// breaking, metrics, auditing or caching. Unlike plugins, which run once per request around the
+// whole provider and fallback handling, middleware runs around the provider calls themselves and
+// sees every call made for a request, including retries.
+//
+// Middleware is registered in BifrostConfig.Middleware or on the chain returned by
+// Bifrost.Middleware, and names must be unique within the chain.
+type ProviderMiddleware interface {
+	// GetName returns the name of the middleware, used to position other middleware around it.
+	GetName() string
+
+	// Wrap returns the caller that runs the middleware around next. It is called again whenever
+	// the chain changes, and must not assume it is called once.
+	Wrap(next ProviderCaller) ProviderCaller
+}
+
+// ProviderCallerFuncs adapts a pair of functions to a ProviderCaller.
+type ProviderCallerFuncs struct {
+	CallFunc       func(call *ProviderCall) (*BifrostResponse, *BifrostError)
+	CallStreamFunc func(call *ProviderCall) (chan *BifrostStream, *BifrostError)
+}
+
+// Call calls CallFunc.
+func (f ProviderCallerFuncs) Call(call *ProviderCall) (*BifrostResponse, *BifrostError) {
+	return f.CallFunc(call)
+}
+
+// CallStream calls CallStreamFunc.
+func (f ProviderCallerFuncs) CallStream(call *ProviderCall) (chan *BifrostStream, *BifrostError) {
+	return f.CallStreamFunc(call)
+}
+
+// providerMiddlewareFunc is the ProviderMiddleware returned by NewProviderMiddleware.
+type providerMiddlewareFunc struct {
+	name string
+	wrap func(next ProviderCaller) ProviderCaller
+}
+
+func (m providerMiddlewareFunc) GetName() string { return m.name }
+
+func (m providerMiddlewareFunc) Wrap(next ProviderCaller) ProviderCaller { return m.wrap(next) }
+
+// NewProviderMiddleware creates a middleware from its name and wrap function.
+func NewProviderMiddleware(name string, wrap func(next ProviderCaller) ProviderCaller) ProviderMiddleware {
+	return providerMiddlewareFunc{name: name, wrap: wrap}
+}
@@ -0,0 +1,25 @@
+package schemas
+
+// ParamRule describes the values accepted for a request parameter, following a subset of JSON schema.
+type ParamRule struct {
+	Type       string        `json:"type,omitempty"` // string, number, integer, boolean, array, object, or empty for any type
+	Minimum    *float64      `json:"minimum,omitempty"`
+	Maximum    *float64      `json:"maximum,omitempty"`
+	Enum       []interface{} `json:"enum,omitempty"`
+	Properties ParamSchema   `json:"properties,omitempty"` // Rules of the fields of object values, which reject unknown fields like the top level
+}
+
+// ParamSchema maps the top-level fields accepted in the request bodies of a provider to their rules.
+// In strict mode, fields that are not part of the schema are rejected.
+type ParamSchema map[string]ParamRule
+
+// ParamViolation is a single parameter that failed strict validation.
+type ParamViolation struct {
+	Param  string      `json:"param"`
+	Value  interface{} `json:"value,omitempty"`
+	Reason string      `json:"reason"`
+}
+
+// RequestBodyCheck validates the final JSON body of a provider request before it is sent,
+// returning an error if the request must not be sent.
+type RequestBodyCheck func(body []byte) *BifrostError
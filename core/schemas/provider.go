@@ -3,7 +3,11 @@ package schemas
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"maps"
+	"strings"
 	"time"
 )
 
@@ -15,6 +19,10 @@ const (
 	DefaultBufferSize              = 5000
 	DefaultConcurrency             = 1000
 	DefaultStreamBufferSize        = 5000
+
+	DefaultMaxRequestRetries      = 5
+	DefaultMaxRequestRetryBackoff = 30 * time.Second
+	DefaultMaxRequestFallbacks    = 5
 )
 
 // Pre-defined errors for provider operations
@@ -39,6 +47,26 @@ type NetworkConfig struct {
 	RetryBackoffMax                time.Duration     `json:"retry_backoff_max"`                  // Maximum backoff duration
 }
 
+// RequestPolicy overrides the provider's network settings for a single request, so that e.g.
+// batch embeddings can retry patiently while interactive chat fails fast without fallbacks.
+// Overrides are clamped to the client's RequestPolicyLimits, and the timeout cannot exceed
+// the provider's DefaultRequestTimeoutInSeconds, which bounds every HTTP call to it.
+type RequestPolicy struct {
+	TimeoutInSeconds      *int `json:"timeout_in_seconds,omitempty"`       // Timeout of each attempt
+	MaxRetries            *int `json:"max_retries,omitempty"`              // Retries of retryable provider errors
+	RetryBackoffInitialMs *int `json:"retry_backoff_initial_ms,omitempty"` // Initial retry backoff in milliseconds
+	RetryBackoffMaxMs     *int `json:"retry_backoff_max_ms,omitempty"`     // Maximum retry backoff in milliseconds
+	MaxFallbacks          *int `json:"max_fallbacks,omitempty"`            // Number of fallbacks tried, 0 disables fallbacks
+}
+
+// RequestPolicyLimits are the server-side maximums for RequestPolicy overrides.
+// Zero values use the DefaultMaxRequest* constants.
+type RequestPolicyLimits struct {
+	MaxRetries      int           // Maximum retries a request can ask for
+	MaxRetryBackoff time.Duration // Maximum retry backoff a request can ask for
+	MaxFallbacks    int           // Maximum fallbacks a request can ask for
+}
+
 // DefaultNetworkConfig is the default network configuration for provider connections.
 var DefaultNetworkConfig = NetworkConfig{
 	DefaultRequestTimeoutInSeconds: DefaultRequestTimeoutInSeconds,
@@ -81,6 +109,161 @@ type ProxyConfig struct {
 	Password string    `json:"password"` // Password for proxy authentication
 }
 
+// HTTP versions of an HTTPClientProfile.
+const (
+	HTTPVersion1 = "1.1" // HTTP/1.1 only
+	HTTPVersion2 = "2"   // HTTP/2 when the server negotiates it, the default
+)
+
+// Header name cases of an HTTPClientProfile.
+const (
+	HeaderCaseCanonical = "canonical" // e.g. X-Api-Key, the default
+	HeaderCaseLower     = "lower"     // e.g. x-api-key
+)
+
+// HTTPClientProfile configures how the HTTP clients of a provider connect and send headers, e.g.
+// to satisfy an egress proxy that only accepts some cipher suites or header spellings. Requests
+// are sent by fasthttp clients, which always use HTTP/1.1, except streams and the requests of
+// Bedrock and Vertex, which are sent by net/http clients. Header names and order are rewritten
+// for fasthttp clients; net/http clients only apply the header names, as they sort headers.
+type HTTPClientProfile struct {
+	TLS         *TLSProfile       `json:"tls,omitempty"`
+	HTTPVersion string            `json:"http_version,omitempty"` // "1.1" or "2", applies to net/http clients
+	HeaderCase  string            `json:"header_case,omitempty"`  // "canonical" or "lower"
+	HeaderNames map[string]string `json:"header_names,omitempty"` // Exact spellings of some headers, overriding HeaderCase, e.g. {"x-api-key": "X-API-KEY"}
+	HeaderOrder []string          `json:"header_order,omitempty"` // Headers sent first, in this order, the others follow in the order they are set
+}
+
+// TLSProfile configures the TLS connections of a provider. Unset fields keep Go's defaults.
+type TLSProfile struct {
+	MinVersion       string   `json:"min_version,omitempty"`       // "1.0", "1.1", "1.2" or "1.3"
+	MaxVersion       string   `json:"max_version,omitempty"`       // "1.0", "1.1", "1.2" or "1.3"
+	CipherSuites     []string `json:"cipher_suites,omitempty"`     // IANA names, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, in order of preference. TLS 1.3 suites are not configurable
+	CurvePreferences []string `json:"curve_preferences,omitempty"` // X25519, P-256, P-384 or P-521, in order of preference
+	ServerName       string   `json:"server_name,omitempty"`       // Overrides the SNI and verified host name
+	CACertPEM        string   `json:"ca_cert_pem,omitempty"`       // PEM certificates trusted in addition to the system roots, e.g. of a TLS inspecting proxy
+}
+
+// tlsVersions are the TLS versions of a TLSProfile.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsCurves are the curves of a TLSProfile.
+var tlsCurves = map[string]tls.CurveID{
+	"X25519": tls.X25519,
+	"P-256":  tls.CurveP256,
+	"P-384":  tls.CurveP384,
+	"P-521":  tls.CurveP521,
+}
+
+// Validate checks the TLS settings, HTTP version and header case of the profile.
+func (p *HTTPClientProfile) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if _, err := p.TLS.Config(); err != nil {
+		return err
+	}
+	switch p.HTTPVersion {
+	case "", HTTPVersion1, HTTPVersion2:
+	default:
+		return fmt.Errorf("unsupported http version %q, expected %q or %q", p.HTTPVersion, HTTPVersion1, HTTPVersion2)
+	}
+	switch p.HeaderCase {
+	case "", HeaderCaseCanonical, HeaderCaseLower:
+	default:
+		return fmt.Errorf("unsupported header case %q, expected %q or %q", p.HeaderCase, HeaderCaseCanonical, HeaderCaseLower)
+	}
+	return nil
+}
+
+// RewritesHeaders reports whether the profile changes the names or order of headers.
+func (p *HTTPClientProfile) RewritesHeaders() bool {
+	return p != nil && (p.HeaderCase == HeaderCaseLower || len(p.HeaderNames) > 0 || len(p.HeaderOrder) > 0)
+}
+
+// HeaderName returns the name a header is sent with: its spelling in HeaderNames, matched case
+// insensitively, or else the name in HeaderCase.
+func (p *HTTPClientProfile) HeaderName(name string) string {
+	for configured, spelling := range p.HeaderNames {
+		if strings.EqualFold(configured, name) {
+			return spelling
+		}
+	}
+	if p.HeaderCase == HeaderCaseLower {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// Config returns the TLS config of the profile, nil if the profile is nil.
+func (t *TLSProfile) Config() (*tls.Config, error) {
+	if t == nil {
+		return nil, nil
+	}
+	config := &tls.Config{ServerName: t.ServerName}
+
+	if t.MinVersion != "" {
+		version, ok := tlsVersions[t.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls min version %q", t.MinVersion)
+		}
+		config.MinVersion = version
+	}
+	if t.MaxVersion != "" {
+		version, ok := tlsVersions[t.MaxVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls max version %q", t.MaxVersion)
+		}
+		config.MaxVersion = version
+	}
+	if config.MinVersion != 0 && config.MaxVersion != 0 && config.MinVersion > config.MaxVersion {
+		return nil, fmt.Errorf("tls min version %s is above max version %s", t.MinVersion, t.MaxVersion)
+	}
+
+	if len(t.CipherSuites) > 0 {
+		suites := make(map[string]uint16)
+		for _, suite := range tls.CipherSuites() {
+			suites[suite.Name] = suite.ID
+		}
+		for _, suite := range tls.InsecureCipherSuites() {
+			suites[suite.Name] = suite.ID
+		}
+		for _, name := range t.CipherSuites {
+			id, ok := suites[name]
+			if !ok {
+				return nil, fmt.Errorf("unsupported tls cipher suite %q", name)
+			}
+			config.CipherSuites = append(config.CipherSuites, id)
+		}
+	}
+
+	for _, name := range t.CurvePreferences {
+		curve, ok := tlsCurves[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls curve %q", name)
+		}
+		config.CurvePreferences = append(config.CurvePreferences, curve)
+	}
+
+	if t.CACertPEM != "" {
+		roots, err := x509.SystemCertPool()
+		if err != nil || roots == nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM([]byte(t.CACertPEM)) {
+			return nil, fmt.Errorf("tls ca_cert_pem contains no valid certificate")
+		}
+		config.RootCAs = roots
+	}
+
+	return config, nil
+}
+
 // AllowedRequests controls which operations are permitted.
 // A nil *AllowedRequests means "all operations allowed."
 // A non-nil value only allows fields set to true; omitted or false fields are disallowed.
@@ -93,6 +276,10 @@ type AllowedRequests struct {
 	SpeechStream         bool `json:"speech_stream"`
 	Transcription        bool `json:"transcription"`
 	TranscriptionStream  bool `json:"transcription_stream"`
+	Translation          bool `json:"translation"`
+	ImageGeneration      bool `json:"image_generation"`
+	VideoGeneration      bool `json:"video_generation"` // Covers submitting and polling jobs
+	Rerank               bool `json:"rerank"`
 }
 
 // IsOperationAllowed checks if a specific operation is allowed
@@ -118,6 +305,14 @@ func (ar *AllowedRequests) IsOperationAllowed(operation Operation) bool {
 		return ar.Transcription
 	case OperationTranscriptionStream:
 		return ar.TranscriptionStream
+	case OperationTranslation:
+		return ar.Translation
+	case OperationImageGeneration:
+		return ar.ImageGeneration
+	case OperationVideoGeneration:
+		return ar.VideoGeneration
+	case OperationRerank:
+		return ar.Rerank
 	default:
 		return false // Default to not allowed for unknown operations
 	}
@@ -127,6 +322,43 @@ type CustomProviderConfig struct {
 	CustomProviderKey string           `json:"-"`                  // Custom provider key, internally set by Bifrost
 	BaseProviderType  ModelProvider    `json:"base_provider_type"` // Base provider type
 	AllowedRequests   *AllowedRequests `json:"allowed_requests,omitempty"`
+	// Request and response mappings, required when BaseProviderType is Template
+	RequestTemplates *TemplateProviderConfig `json:"request_templates,omitempty"`
+}
+
+// TemplateProviderConfig describes a backend that is not worth a dedicated Go provider.
+// Each supported operation renders its request body from the canonical Bifrost request
+// and maps the JSON response back to a BifrostResponse. Operations left nil are unsupported.
+type TemplateProviderConfig struct {
+	TextCompletion *TemplateOperationConfig `json:"text_completion,omitempty"`
+	ChatCompletion *TemplateOperationConfig `json:"chat_completion,omitempty"`
+	Embedding      *TemplateOperationConfig `json:"embedding,omitempty"`
+}
+
+// TemplateOperationConfig configures a single templated operation.
+//
+// Body is a Go text/template rendering the JSON request body. It is executed with the fields
+// .Model, .Messages, .Prompt (the text completion input or the flattened chat messages),
+// .Texts (embedding inputs) and .Params, and can use the functions json (JSON encode a value),
+// default (fallback for nil values), lastUserMessage and messageText.
+type TemplateOperationConfig struct {
+	Path     string                  `json:"path"`             // Path appended to the network config base URL
+	Method   string                  `json:"method,omitempty"` // HTTP method, POST if empty
+	Headers  map[string]string       `json:"headers,omitempty"`
+	Body     string                  `json:"body"`
+	Response TemplateResponseMapping `json:"response"`
+}
+
+// TemplateResponseMapping maps fields of a JSON response to a BifrostResponse using JSONPath
+// expressions such as "$.choices[0].text" or "$.data[*].embedding". Empty paths are ignored.
+type TemplateResponseMapping struct {
+	ID               string `json:"id,omitempty"`
+	Content          string `json:"content,omitempty"`
+	FinishReason     string `json:"finish_reason,omitempty"`
+	PromptTokens     string `json:"prompt_tokens,omitempty"`
+	CompletionTokens string `json:"completion_tokens,omitempty"`
+	TotalTokens      string `json:"total_tokens,omitempty"`
+	Embeddings       string `json:"embeddings,omitempty"` // Must resolve to a list of number arrays
 }
 
 // IsOperationAllowed checks if a specific operation is allowed for this custom provider
@@ -145,8 +377,9 @@ type ProviderConfig struct {
 	ConcurrencyAndBufferSize ConcurrencyAndBufferSize `json:"concurrency_and_buffer_size"` // Concurrency settings
 	// Logger instance, can be provided by the user or bifrost default logger is used if not provided
 	Logger               Logger                `json:"-"`
-	ProxyConfig          *ProxyConfig          `json:"proxy_config,omitempty"` // Proxy configuration
-	SendBackRawResponse  bool                  `json:"send_back_raw_response"` // Send raw response back in the bifrost response (default: false)
+	ProxyConfig          *ProxyConfig          `json:"proxy_config,omitempty"`        // Proxy configuration
+	HTTPClientProfile    *HTTPClientProfile    `json:"http_client_profile,omitempty"` // TLS, HTTP version and header settings of the provider's HTTP clients
+	SendBackRawResponse  bool                  `json:"send_back_raw_response"`        // Send raw response back in the bifrost response (default: false)
 	CustomProviderConfig *CustomProviderConfig `json:"custom_provider_config,omitempty"`
 }
 
@@ -161,6 +394,10 @@ const (
 	OperationSpeechStream         Operation = "speech_stream"
 	OperationTranscription        Operation = "transcription"
 	OperationTranscriptionStream  Operation = "transcription_stream"
+	OperationTranslation          Operation = "translation"
+	OperationImageGeneration      Operation = "image_generation"
+	OperationVideoGeneration      Operation = "video_generation"
+	OperationRerank               Operation = "rerank"
 )
 
 func (config *ProviderConfig) CheckAndSetDefaults() {
@@ -218,4 +455,32 @@ type Provider interface {
 	Transcription(ctx context.Context, model string, key Key, input *TranscriptionInput, params *ModelParameters) (*BifrostResponse, *BifrostError)
 	// TranscriptionStream performs a transcription stream request
 	TranscriptionStream(ctx context.Context, postHookRunner PostHookRunner, model string, key Key, input *TranscriptionInput, params *ModelParameters) (chan *BifrostStream, *BifrostError)
+	// Translation performs a translation request, transcribing the audio into English text
+	Translation(ctx context.Context, model string, key Key, input *TranscriptionInput, params *ModelParameters) (*BifrostResponse, *BifrostError)
+	// ImageGeneration performs an image generation request
+	ImageGeneration(ctx context.Context, model string, key Key, input *ImageInput, params *ModelParameters) (*BifrostResponse, *BifrostError)
+	// VideoGeneration submits a video generation job and returns it without waiting for the video
+	VideoGeneration(ctx context.Context, model string, key Key, input *VideoInput, params *ModelParameters) (*BifrostResponse, *BifrostError)
+	// VideoStatus returns the current state of a video generation job submitted with the same key
+	VideoStatus(ctx context.Context, model string, key Key, input *VideoJobInput) (*BifrostResponse, *BifrostError)
+	// CountTokens returns the input tokens a chat completion request would use
+	CountTokens(ctx context.Context, model string, key Key, messages []BifrostMessage, params *ModelParameters) (*BifrostResponse, *BifrostError)
+	// Rerank orders documents by their relevance to a query
+	Rerank(ctx context.Context, model string, key Key, input *RerankInput, params *ModelParameters) (*BifrostResponse, *BifrostError)
+}
+
+// ProviderState is whether a provider accepts new requests, see Bifrost.DrainProvider.
+type ProviderState string
+
+const (
+	ProviderStateActive   ProviderState = "active"   // Accepts new requests
+	ProviderStateDraining ProviderState = "draining" // Rejects new requests, in-flight requests and streams are finishing
+	ProviderStateInactive ProviderState = "inactive" // Rejects new requests, its workers are stopped
+)
+
+// ProviderStatus is the state of a provider and the number of its requests in flight, streams included.
+type ProviderStatus struct {
+	Provider ModelProvider `json:"provider"`
+	State    ProviderState `json:"state"`
+	InFlight int64         `json:"in_flight"`
 }
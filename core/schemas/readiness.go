@@ -0,0 +1,112 @@
+package schemas
+
+import (
+	"context"
+	"time"
+)
+
+// Default readiness validation settings.
+const (
+	DefaultValidateProbeTimeout = 15 * time.Second
+	DefaultValidateConcurrency  = 8
+	DefaultValidatePrompt       = "ping"
+)
+
+// ValidateOptions configures Bifrost.Validate.
+type ValidateOptions struct {
+	// Model probed for keys that allow every model, by provider. Such keys are only checked
+	// statically if their provider has no probe model.
+	ProbeModels map[ModelProvider]string `json:"probe_models,omitempty"`
+	SkipProbes  bool                     `json:"skip_probes,omitempty"` // Only run the static checks, no request is sent to providers
+	Timeout     time.Duration            `json:"timeout,omitempty"`     // Timeout of each probe, DefaultValidateProbeTimeout if 0
+	Concurrency int                      `json:"concurrency,omitempty"` // Probes run at the same time, DefaultValidateConcurrency if 0
+}
+
+// ReadinessStatus is the outcome of a readiness check.
+type ReadinessStatus string
+
+const (
+	ReadinessOK      ReadinessStatus = "ok"
+	ReadinessWarning ReadinessStatus = "warning" // Usable, but something needs attention, e.g. the key was rate limited
+	ReadinessFailed  ReadinessStatus = "failed"  // Requests relying on this will fail
+	ReadinessSkipped ReadinessStatus = "skipped" // Could not be checked, e.g. no model to probe
+)
+
+// ReadinessReport is the result of Bifrost.Validate. Ready is false if any check failed.
+type ReadinessReport struct {
+	Ready      bool                    `json:"ready"`
+	CheckedAt  time.Time               `json:"checked_at"`
+	DurationMs int64                   `json:"duration_ms"`
+	Providers  []ProviderReadiness     `json:"providers"`
+	Plugins    []ComponentReadiness    `json:"plugins"`
+	MCPClients []ComponentReadiness    `json:"mcp_clients,omitempty"`
+	Summary    map[ReadinessStatus]int `json:"summary"` // Number of checks by status
+}
+
+// ProviderReadiness holds the checks of a provider: one probe per key and model it serves.
+type ProviderReadiness struct {
+	Provider ModelProvider    `json:"provider"`
+	Status   ReadinessStatus  `json:"status"` // The worst status of the provider and its probes
+	Message  string           `json:"message,omitempty"`
+	Probes   []ReadinessProbe `json:"probes,omitempty"`
+}
+
+// ReadinessProbe is the check of a key serving a model.
+type ReadinessProbe struct {
+	KeyID      string          `json:"key_id"`
+	Model      string          `json:"model,omitempty"`
+	Status     ReadinessStatus `json:"status"`
+	Message    string          `json:"message,omitempty"`
+	StatusCode *int            `json:"status_code,omitempty"` // Status code returned by the provider
+	LatencyMs  int64           `json:"latency_ms,omitempty"`
+}
+
+// ComponentReadiness is the check of a plugin or MCP client.
+type ComponentReadiness struct {
+	Name    string          `json:"name"`
+	Status  ReadinessStatus `json:"status"`
+	Message string          `json:"message,omitempty"`
+}
+
+// Add appends a check to the report and updates its summary and readiness.
+func (r *ReadinessReport) Add(status ReadinessStatus) {
+	if r.Summary == nil {
+		r.Summary = make(map[ReadinessStatus]int)
+	}
+	r.Summary[status]++
+	if status == ReadinessFailed {
+		r.Ready = false
+	}
+}
+
+// AddPlugin appends a plugin check to the report.
+func (r *ReadinessReport) AddPlugin(check ComponentReadiness) {
+	r.Plugins = append(r.Plugins, check)
+	r.Add(check.Status)
+}
+
+// WorseReadiness returns the more severe of two statuses: failed, then warning, then ok, then skipped.
+func WorseReadiness(a, b ReadinessStatus) ReadinessStatus {
+	rank := func(s ReadinessStatus) int {
+		switch s {
+		case ReadinessFailed:
+			return 3
+		case ReadinessWarning:
+			return 2
+		case ReadinessOK:
+			return 1
+		default:
+			return 0
+		}
+	}
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+// PluginValidator is implemented by plugins that can check their configuration and dependencies,
+// e.g. that a backing store is reachable. Bifrost.Validate reports the error of each plugin.
+type PluginValidator interface {
+	Validate(ctx context.Context) error
+}
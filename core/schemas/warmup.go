@@ -0,0 +1,48 @@
+package schemas
+
+import (
+	"context"
+	"time"
+)
+
+// Default warm-up settings.
+const (
+	DefaultWarmUpTimeout = 10 * time.Second
+	// Below the 10 second idle connection timeout of the providers' HTTP clients, so idle providers
+	// keep an open connection.
+	DefaultWarmUpIdleInterval = 8 * time.Second
+)
+
+// WarmUpConfig configures the warm-up of providers, which opens connections to their APIs at
+// startup and again after idle periods, so the first request after a deploy or a quiet spell does
+// not pay for the DNS lookup and TLS handshake.
+type WarmUpConfig struct {
+	// Providers to warm up, every configured provider if empty.
+	Providers []ModelProvider `json:"providers,omitempty"`
+	// Model sent a one-token probe request once the connections are open, by provider. Probes are
+	// only sent at startup and are billed by the providers; providers without a probe model only
+	// get their connections opened.
+	ProbeModels map[ModelProvider]string `json:"probe_models,omitempty"`
+	// Providers that served no request for this long are warmed up again, DefaultWarmUpIdleInterval
+	// if 0. Negative turns off warm-ups after startup.
+	IdleInterval time.Duration `json:"idle_interval,omitempty"`
+	// Timeout of the warm-up of each provider, DefaultWarmUpTimeout if 0.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// ProviderWarmer is implemented by providers that can open the connections to their API ahead of
+// the first request. The key is the one requests would use, for providers whose endpoint depends
+// on it such as Azure, Vertex and Bedrock.
+type ProviderWarmer interface {
+	WarmUp(ctx context.Context, key Key) error
+}
+
+// WarmUpResult is the outcome of the warm-up of a provider.
+type WarmUpResult struct {
+	Provider   ModelProvider `json:"provider"`
+	Connected  bool          `json:"connected"`             // Connections to the provider's API were opened
+	ProbeModel string        `json:"probe_model,omitempty"` // Model of the probe request, if one was sent
+	Probed     bool          `json:"probed"`                // The probe request succeeded
+	LatencyMs  int64         `json:"latency_ms"`            // Duration of the whole warm-up
+	Error      string        `json:"error,omitempty"`       // Why the connections could not be opened or the probe failed
+}
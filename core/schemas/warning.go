@@ -0,0 +1,67 @@
+package schemas
+
+import (
+	"context"
+	"sync"
+)
+
+// WarningCode identifies the kind of change made to a request or response without failing it.
+type WarningCode string
+
+const (
+	WarningParamDropped         WarningCode = "param_dropped"         // A parameter was not sent, e.g. the provider does not support it
+	WarningParamAdjusted        WarningCode = "param_adjusted"        // A parameter was changed, e.g. max_tokens lowered to fit the context window
+	WarningEstimated            WarningCode = "estimated"             // A value was estimated instead of reported by the provider
+	WarningTruncated            WarningCode = "truncated"             // Content was shortened, e.g. embeddings or the history of a session
+	WarningEnforced             WarningCode = "enforced"              // A policy changed the request or response
+	WarningCapabilityDowngraded WarningCode = "capability_downgraded" // A cheaper or weaker capability was used than requested
+)
+
+// Origins of warnings raised by Bifrost itself. Providers use their name, plugins their GetName.
+const (
+	WarningOriginBifrost = "bifrost"
+)
+
+// Warning reports a behavior change that did not fail the request, so callers can detect it
+// programmatically instead of reading logs.
+type Warning struct {
+	Code    WarningCode `json:"code"`
+	Message string      `json:"message"`
+	Origin  string      `json:"origin"` // bifrost, or the name of the provider or plugin that raised it
+}
+
+// Warnings collects the warnings raised while a request is served. Bifrost attaches one to the
+// context of every provider attempt (BifrostContextKeyWarnings) and returns its warnings in
+// ExtraFields.Warnings; providers and plugins add to it with AddWarning.
+type Warnings struct {
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+// Add records a warning.
+func (w *Warnings) Add(warning Warning) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.warnings = append(w.warnings, warning)
+}
+
+// Since returns the warnings recorded after the first n.
+func (w *Warnings) Since(n int) []Warning {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n >= len(w.warnings) {
+		return nil
+	}
+	return append([]Warning(nil), w.warnings[n:]...)
+}
+
+// AddWarning records a warning on the collector of the request context. It does nothing when
+// the context has no collector, e.g. outside of a Bifrost request.
+func AddWarning(ctx context.Context, code WarningCode, origin string, message string) {
+	if ctx == nil {
+		return
+	}
+	if warnings, ok := ctx.Value(BifrostContextKeyWarnings).(*Warnings); ok && warnings != nil {
+		warnings.Add(Warning{Code: code, Message: message, Origin: origin})
+	}
+}
@@ -0,0 +1,342 @@
+package bifrost
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// SESSION LIMITS
+// ============================================================================
+
+// defaultSessionSummaryPrompt instructs the model writing the summary of a session.
+const defaultSessionSummaryPrompt = "Summarize the following conversation so that it can be continued from the summary alone. " +
+	"Keep the goals, decisions, facts, open questions and results of tool calls that are still relevant. " +
+	"Reply with the summary only."
+
+// sessionUsage is the usage of a session counted against its limits.
+type sessionUsage struct {
+	sessionID string
+	turns     int
+	tokens    int
+	lastUsed  time.Time
+}
+
+// sessionLimiter counts the turns and tokens of recently active sessions, forgetting sessions
+// that were idle for longer than the TTL or least recently used beyond maxSessions. The counts
+// are local to this instance, see SessionLimitsConfig.
+type sessionLimiter struct {
+	mu          sync.Mutex
+	maxTurns    int
+	maxTokens   int
+	ttl         time.Duration
+	maxSessions int
+	summarize   *schemas.SessionSummarizeConfig
+	sessions    map[string]*list.Element // Elements hold a *sessionUsage
+	order       *list.List               // Most recently used first
+}
+
+// newSessionLimiter creates the session limiter, nil if config is nil or sets no limit.
+func newSessionLimiter(config *schemas.SessionLimitsConfig) *sessionLimiter {
+	if config == nil || (config.MaxTurns <= 0 && config.MaxTokens <= 0) {
+		return nil
+	}
+	limiter := &sessionLimiter{
+		maxTurns:    max(config.MaxTurns, 0),
+		maxTokens:   max(config.MaxTokens, 0),
+		ttl:         schemas.DefaultSessionLimitsTTL,
+		maxSessions: schemas.DefaultSessionLimitsMaxSessions,
+		summarize:   config.Summarize,
+		sessions:    make(map[string]*list.Element),
+		order:       list.New(),
+	}
+	if config.TTL > 0 {
+		limiter.ttl = config.TTL
+	}
+	if config.MaxSessions > 0 {
+		limiter.maxSessions = config.MaxSessions
+	}
+	return limiter
+}
+
+// usage returns the usage of a session, creating it if the session is new or expired. The
+// caller must hold the lock.
+func (l *sessionLimiter) usage(sessionID string) *sessionUsage {
+	if element, ok := l.sessions[sessionID]; ok {
+		usage := element.Value.(*sessionUsage)
+		if time.Since(usage.lastUsed) <= l.ttl {
+			usage.lastUsed = time.Now()
+			l.order.MoveToFront(element)
+			return usage
+		}
+		l.order.Remove(element)
+		delete(l.sessions, sessionID)
+	}
+
+	usage := &sessionUsage{sessionID: sessionID, lastUsed: time.Now()}
+	l.sessions[sessionID] = l.order.PushFront(usage)
+	for l.order.Len() > l.maxSessions {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.sessions, oldest.Value.(*sessionUsage).sessionID)
+	}
+	return usage
+}
+
+// begin counts a new turn of a session, or returns the limit the session reached.
+func (l *sessionLimiter) begin(sessionID string) *schemas.SessionLimitError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage := l.usage(sessionID)
+	if l.maxTurns > 0 && usage.turns >= l.maxTurns {
+		return &schemas.SessionLimitError{SessionID: sessionID, Limit: schemas.SessionLimitTurns, Max: l.maxTurns, Used: usage.turns}
+	}
+	if l.maxTokens > 0 && usage.tokens >= l.maxTokens {
+		return &schemas.SessionLimitError{SessionID: sessionID, Limit: schemas.SessionLimitTokens, Max: l.maxTokens, Used: usage.tokens}
+	}
+	usage.turns++
+	return nil
+}
+
+// reset starts the usage of a session over, counting the tokens spent on its summary.
+func (l *sessionLimiter) reset(sessionID string, tokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage := l.usage(sessionID)
+	usage.turns = 0
+	usage.tokens = tokens
+}
+
+// addTokens adds tokens to the usage of a session and returns its usage.
+func (l *sessionLimiter) addTokens(sessionID string, tokens int) (turns int, total int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage := l.usage(sessionID)
+	usage.tokens += tokens
+	return usage.turns, usage.tokens
+}
+
+// sessionTurn tracks a single request of a session with limits. A nil sessionTurn is valid and
+// does nothing.
+type sessionTurn struct {
+	limiter   *sessionLimiter
+	sessionID string
+	summary   string // Set when the earlier messages were summarized to continue the session
+
+	mu       sync.Mutex
+	recorded int // Tokens of the response recorded so far
+}
+
+// applySessionLimits counts a request against the limits of its session. A chat request of a
+// session that reached a limit continues with its earlier messages summarized when
+// summarization is configured, other requests fail with a SessionLimitExceeded error. It
+// returns the possibly summarized request.
+func (bifrost *Bifrost) applySessionLimits(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType) (*schemas.BifrostRequest, *sessionTurn, *schemas.BifrostError) {
+	limiter := bifrost.sessionLimiter
+	sessionID, ok := ctx.Value(schemas.BifrostContextKeySessionID).(string)
+	if limiter == nil || !ok || sessionID == "" {
+		return req, nil, nil
+	}
+
+	turn := &sessionTurn{limiter: limiter, sessionID: sessionID}
+	limitErr := limiter.begin(sessionID)
+	if limitErr == nil {
+		return req, turn, nil
+	}
+
+	if limiter.summarize == nil || (requestType != schemas.ChatCompletionRequest && requestType != schemas.ChatCompletionStreamRequest) {
+		return nil, nil, newSessionLimitError(req, limitErr, "")
+	}
+
+	summarizedReq, summary, tokens, err := bifrost.summarizeSession(ctx, req, limiter.summarize)
+	if err != nil {
+		bifrost.logger.Warn(fmt.Sprintf("failed to summarize session %s: %v", sessionID, err))
+		return nil, nil, newSessionLimitError(req, limitErr, err.Error())
+	}
+	limiter.reset(sessionID, tokens)
+	if limitErr := limiter.begin(sessionID); limitErr != nil {
+		return nil, nil, newSessionLimitError(req, limitErr, "the summary alone exceeds the limits")
+	}
+
+	turn.summary = summary
+	schemas.AddWarning(ctx, schemas.WarningTruncated, schemas.WarningOriginBifrost,
+		fmt.Sprintf("session %s reached its limits, its earlier messages were replaced by a summary", sessionID))
+	return summarizedReq, turn, nil
+}
+
+// summarizeSession replaces the earlier messages of a chat request with a system message
+// summarizing them, keeping the system messages and the latest messages as they are. It
+// returns the new request, the summary and the tokens spent on it.
+func (bifrost *Bifrost) summarizeSession(ctx context.Context, req *schemas.BifrostRequest, config *schemas.SessionSummarizeConfig) (*schemas.BifrostRequest, string, int, error) {
+	if req.Input.ChatCompletionInput == nil {
+		return nil, "", 0, fmt.Errorf("request has no messages to summarize")
+	}
+
+	var system, conversation []schemas.BifrostMessage
+	for _, message := range *req.Input.ChatCompletionInput {
+		if message.Role.IsInstruction() {
+			system = append(system, message)
+		} else {
+			conversation = append(conversation, message)
+		}
+	}
+
+	keep := schemas.DefaultSessionSummaryKeepMessages
+	if config.KeepMessages > 0 {
+		keep = config.KeepMessages
+	}
+	split := max(len(conversation)-keep, 0)
+	// Tool results stay with the assistant message that called the tools
+	for split > 0 && conversation[split].Role == schemas.ModelChatMessageRoleTool {
+		split--
+	}
+	if split == 0 {
+		return nil, "", 0, fmt.Errorf("no messages before the latest %d to summarize", keep)
+	}
+
+	prompt := defaultSessionSummaryPrompt
+	if config.Prompt != "" {
+		prompt = config.Prompt
+	}
+	transcript := sessionTranscript(conversation[:split])
+	summaryReq := &schemas.BifrostRequest{
+		Provider: req.Provider,
+		Model:    req.Model,
+		Input: schemas.RequestInput{
+			ChatCompletionInput: &[]schemas.BifrostMessage{
+				{Role: schemas.ModelChatMessageRoleSystem, Content: schemas.MessageContent{ContentStr: &prompt}},
+				{Role: schemas.ModelChatMessageRoleUser, Content: schemas.MessageContent{ContentStr: &transcript}},
+			},
+		},
+	}
+	if config.Provider != "" {
+		summaryReq.Provider = config.Provider
+	}
+	if config.Model != "" {
+		summaryReq.Model = config.Model
+	}
+
+	// The summary is a side request: it is neither limited nor moves session affinity
+	summaryCtx := context.WithValue(ctx, schemas.BifrostContextKeySessionID, "")
+	summaryCtx = context.WithValue(summaryCtx, schemas.BifrostContextKeyQueueStatus, nil)
+	result, bifrostErr := bifrost.ChatCompletionRequest(summaryCtx, summaryReq)
+	if bifrostErr != nil {
+		return nil, "", 0, fmt.Errorf("summary request failed: %s", bifrostErr.Error.Message)
+	}
+	if len(result.Choices) == 0 || result.Choices[0].BifrostNonStreamResponseChoice == nil || result.Choices[0].Message.Content.ContentStr == nil {
+		return nil, "", 0, fmt.Errorf("summary response has no text")
+	}
+	summary := strings.TrimSpace(*result.Choices[0].Message.Content.ContentStr)
+	if summary == "" {
+		return nil, "", 0, fmt.Errorf("summary response has no text")
+	}
+	tokens := 0
+	if result.Usage != nil {
+		tokens = result.Usage.TotalTokens
+	}
+
+	summaryMessage := "Summary of the earlier conversation:\n" + summary
+	messages := make([]schemas.BifrostMessage, 0, len(system)+1+len(conversation)-split)
+	messages = append(messages, system...)
+	messages = append(messages, schemas.BifrostMessage{
+		Role:    schemas.ModelChatMessageRoleSystem,
+		Content: schemas.MessageContent{ContentStr: &summaryMessage},
+	})
+	messages = append(messages, conversation[split:]...)
+
+	summarizedReq := *req
+	summarizedReq.Input.ChatCompletionInput = &messages
+	return &summarizedReq, summary, tokens, nil
+}
+
+// sessionTranscript renders messages as plain text for the summary request.
+func sessionTranscript(messages []schemas.BifrostMessage) string {
+	var transcript strings.Builder
+	for _, message := range messages {
+		fmt.Fprintf(&transcript, "%s: ", message.Role)
+		transcript.WriteString(message.Content.Text())
+		if message.AssistantMessage != nil && message.AssistantMessage.ToolCalls != nil {
+			for _, call := range *message.AssistantMessage.ToolCalls {
+				if call.Function.Name != nil {
+					fmt.Fprintf(&transcript, "\n[called %s(%s)]", *call.Function.Name, call.Function.Arguments)
+				}
+			}
+		}
+		transcript.WriteString("\n\n")
+	}
+	return transcript.String()
+}
+
+// record adds the tokens of a response to the usage of the session and returns the usage for
+// the response. Stream chunks may carry cumulative usage, so only the tokens beyond those
+// already recorded for the request are added.
+func (t *sessionTurn) record(usage *schemas.LLMUsage) *schemas.SessionUsage {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tokens := 0
+	if usage != nil && usage.TotalTokens > t.recorded {
+		tokens = usage.TotalTokens - t.recorded
+		t.recorded = usage.TotalTokens
+	}
+	turns, total := t.limiter.addTokens(t.sessionID, tokens)
+	return &schemas.SessionUsage{
+		SessionID:  t.sessionID,
+		Turns:      turns,
+		Tokens:     total,
+		Summarized: t.summary != "",
+		Summary:    t.summary,
+	}
+}
+
+// newSessionLimitError returns the error of a request rejected by the limits of its session.
+func newSessionLimitError(req *schemas.BifrostRequest, limitErr *schemas.SessionLimitError, summaryFailure string) *schemas.BifrostError {
+	message := limitErr.Error()
+	if summaryFailure != "" {
+		message += ", and the session could not be summarized: " + summaryFailure
+	}
+	return &schemas.BifrostError{
+		IsBifrostError: true,
+		Provider:       req.Provider,
+		StatusCode:     Ptr(429),
+		Type:           Ptr(schemas.SessionLimitExceeded),
+		Origin:         schemas.ErrorOriginPolicy,
+		Error: schemas.ErrorField{
+			Type:    Ptr(schemas.SessionLimitExceeded),
+			Code:    Ptr(limitErr.Limit),
+			Message: message,
+			Error:   limitErr,
+		},
+		AllowFallbacks: Ptr(false),
+	}
+}
+
+// streamWithSessionUsage sets the session usage on the first chunk of a stream and on every
+// chunk carrying usage, recording the tokens of the stream.
+func streamWithSessionUsage(stream chan *schemas.BifrostStream, turn *sessionTurn) chan *schemas.BifrostStream {
+	outputStream := make(chan *schemas.BifrostStream, cap(stream))
+	go func() {
+		defer close(outputStream)
+		first := true
+		for chunk := range stream {
+			if chunk != nil && chunk.BifrostResponse != nil && (first || chunk.BifrostResponse.Usage != nil) {
+				chunk.BifrostResponse.ExtraFields.SessionUsage = turn.record(chunk.BifrostResponse.Usage)
+				first = false
+			}
+			outputStream <- chunk
+		}
+	}()
+	return outputStream
+}
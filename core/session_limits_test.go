@@ -0,0 +1,209 @@
+package bifrost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestSessionLimiter(t *testing.T) {
+	type step struct {
+		begin     string // Session to begin a turn of
+		addTokens int    // Tokens then added to it
+		wantLimit string // Limit the turn runs into, empty if it is allowed
+	}
+	tests := []struct {
+		name   string
+		config schemas.SessionLimitsConfig
+		steps  []step
+	}{
+		{
+			name:   "turn limit",
+			config: schemas.SessionLimitsConfig{MaxTurns: 2},
+			steps: []step{
+				{begin: "a"}, {begin: "a"},
+				{begin: "a", wantLimit: schemas.SessionLimitTurns},
+				{begin: "b"},
+			},
+		},
+		{
+			name:   "token limit",
+			config: schemas.SessionLimitsConfig{MaxTokens: 100},
+			steps: []step{
+				{begin: "a", addTokens: 60}, {begin: "a", addTokens: 60},
+				{begin: "a", wantLimit: schemas.SessionLimitTokens},
+			},
+		},
+		{
+			name:   "least recently used session forgotten",
+			config: schemas.SessionLimitsConfig{MaxTurns: 1, MaxSessions: 1},
+			steps: []step{
+				{begin: "a"}, {begin: "b"},
+				{begin: "a"},
+				{begin: "a", wantLimit: schemas.SessionLimitTurns},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newSessionLimiter(&tt.config)
+			for i, step := range tt.steps {
+				limitErr := limiter.begin(step.begin)
+				switch {
+				case step.wantLimit == "" && limitErr != nil:
+					t.Fatalf("step %d: begin(%s) = %v, want allowed", i, step.begin, limitErr)
+				case step.wantLimit != "" && (limitErr == nil || limitErr.Limit != step.wantLimit):
+					t.Fatalf("step %d: begin(%s) = %v, want limit %s", i, step.begin, limitErr, step.wantLimit)
+				}
+				if step.addTokens > 0 {
+					limiter.addTokens(step.begin, step.addTokens)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionLimiterExpiry(t *testing.T) {
+	if newSessionLimiter(&schemas.SessionLimitsConfig{}) != nil {
+		t.Error("newSessionLimiter() without limits is not nil")
+	}
+
+	limiter := newSessionLimiter(&schemas.SessionLimitsConfig{MaxTurns: 1, TTL: time.Millisecond})
+	if limitErr := limiter.begin("a"); limitErr != nil {
+		t.Fatalf("begin() = %v, want allowed", limitErr)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if limitErr := limiter.begin("a"); limitErr != nil {
+		t.Errorf("begin() after the TTL = %v, want the usage forgotten", limitErr)
+	}
+}
+
+func TestSessionTurnRecord(t *testing.T) {
+	limiter := newSessionLimiter(&schemas.SessionLimitsConfig{MaxTokens: 1000})
+	limiter.begin("a")
+	turn := &sessionTurn{limiter: limiter, sessionID: "a"}
+
+	// Stream chunks report cumulative usage, only the tokens beyond those recorded count
+	for _, total := range []int{10, 25, 25} {
+		turn.record(&schemas.LLMUsage{TotalTokens: total})
+	}
+	usage := turn.record(nil)
+	if usage.Turns != 1 || usage.Tokens != 25 {
+		t.Errorf("session usage = %d turns, %d tokens, want 1 turn, 25 tokens", usage.Turns, usage.Tokens)
+	}
+
+	var nilTurn *sessionTurn
+	if nilTurn.record(&schemas.LLMUsage{TotalTokens: 10}) != nil {
+		t.Error("record() on a nil turn returned usage")
+	}
+}
+
+func TestApplySessionLimits(t *testing.T) {
+	// The server answers every chat request with a fixed text and records the messages it got
+	var mu sync.Mutex
+	var received [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var contents []string
+		for _, message := range req.Messages {
+			contents = append(contents, message.Content)
+		}
+		mu.Lock()
+		received = append(received, contents)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": "chatcmpl-1", "object": "chat.completion", "model": "gpt-4o",
+			"choices": []map[string]interface{}{{"index": 0, "finish_reason": "stop", "message": map[string]interface{}{"role": "assistant", "content": "noted"}}},
+			"usage":   map[string]interface{}{"prompt_tokens": 5, "completion_tokens": 5, "total_tokens": 10},
+		})
+	}))
+	defer server.Close()
+
+	text := func(role schemas.ModelChatMessageRole, content string) schemas.BifrostMessage {
+		return schemas.BifrostMessage{Role: role, Content: schemas.MessageContent{ContentStr: &content}}
+	}
+	messages := []schemas.BifrostMessage{
+		text(schemas.ModelChatMessageRoleSystem, "system prompt"),
+		text(schemas.ModelChatMessageRoleUser, "first question"),
+		text(schemas.ModelChatMessageRoleAssistant, "first answer"),
+		text(schemas.ModelChatMessageRoleUser, "second question"),
+	}
+	request := &schemas.BifrostRequest{Provider: schemas.OpenAI, Model: "gpt-4o", Input: schemas.RequestInput{ChatCompletionInput: &messages}}
+
+	tests := []struct {
+		name      string
+		summarize *schemas.SessionSummarizeConfig
+	}{
+		{name: "rejected"},
+		{name: "summarized", summarize: &schemas.SessionSummarizeConfig{KeepMessages: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			received = nil
+			mu.Unlock()
+			client, err := Init(context.Background(), schemas.BifrostConfig{
+				Account:       &upstreamAccount{baseURL: server.URL},
+				Logger:        NewDefaultLogger(schemas.LogLevelError),
+				SessionLimits: &schemas.SessionLimitsConfig{MaxTurns: 1, Summarize: tt.summarize},
+			})
+			if err != nil {
+				t.Fatalf("Init() error = %v", err)
+			}
+			defer client.Shutdown()
+			ctx := context.WithValue(context.Background(), schemas.BifrostContextKeySessionID, "session-1")
+
+			result, bifrostErr := client.ChatCompletionRequest(ctx, request)
+			if bifrostErr != nil {
+				t.Fatalf("first request error = %v", bifrostErr.Error.Message)
+			}
+			if usage := result.ExtraFields.SessionUsage; usage == nil || usage.Turns != 1 || usage.Tokens != 10 {
+				t.Fatalf("first request session usage = %+v, want 1 turn and 10 tokens", usage)
+			}
+
+			result, bifrostErr = client.ChatCompletionRequest(ctx, request)
+			if tt.summarize == nil {
+				if bifrostErr == nil || bifrostErr.StatusCode == nil || *bifrostErr.StatusCode != 429 ||
+					bifrostErr.Error.Type == nil || *bifrostErr.Error.Type != schemas.SessionLimitExceeded {
+					t.Fatalf("second request error = %+v, want a 429 %s error", bifrostErr, schemas.SessionLimitExceeded)
+				}
+				return
+			}
+			if bifrostErr != nil {
+				t.Fatalf("second request error = %v", bifrostErr.Error.Message)
+			}
+			if usage := result.ExtraFields.SessionUsage; usage == nil || !usage.Summarized || usage.Summary != "noted" || usage.Turns != 1 {
+				t.Errorf("second request session usage = %+v, want a summarized session on its first turn", usage)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(received) != 3 {
+				t.Fatalf("upstream got %d requests, want the request, the summary and the summarized request", len(received))
+			}
+			if summaryInput := strings.Join(received[1], "\n"); !strings.Contains(summaryInput, "first question") || strings.Contains(summaryInput, "second question") {
+				t.Errorf("summary request = %q, want the messages before the latest one", summaryInput)
+			}
+			want := []string{"system prompt", "Summary of the earlier conversation:\nnoted", "second question"}
+			if strings.Join(received[2], "|") != strings.Join(want, "|") {
+				t.Errorf("summarized request messages = %q, want %q", received[2], want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package bifrost
+
+import (
+	"context"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// SPECULATIVE DRAFT STREAMING
+// ============================================================================
+
+// streamStart is the outcome of starting a stream request.
+type streamStart struct {
+	stream chan *schemas.BifrostStream
+	err    *schemas.BifrostError
+}
+
+// getSpeculativeDraft returns the draft model set in the context for a chat stream, if any.
+func getSpeculativeDraft(ctx context.Context, requestType schemas.RequestType) (schemas.SpeculativeDraft, bool) {
+	if requestType != schemas.ChatCompletionStreamRequest {
+		return schemas.SpeculativeDraft{}, false
+	}
+	draft, ok := ctx.Value(schemas.BifrostContextKeySpeculativeDraft).(schemas.SpeculativeDraft)
+	return draft, ok && draft.Provider != "" && draft.Model != ""
+}
+
+// streamWithSpeculativeDraft runs a chat stream on the requested model and on a fast draft model
+// at the same time. Draft chunks are forwarded, marked with ExtraFields.Draft, until the requested
+// model sends its first chunk; the stream then carries a premium_started StreamResync, the draft
+// is cancelled and the requested model's chunks follow. If the requested model fails before it
+// starts, the stream carries a draft_promoted StreamResync and the draft continues as the answer.
+func (bifrost *Bifrost) streamWithSpeculativeDraft(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType, draft schemas.SpeculativeDraft) chan *schemas.BifrostStream {
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeySpeculativeDraft, schemas.SpeculativeDraft{})
+
+	// The draft is a side request: it neither moves session affinity nor reports queue status
+	draftCtx, cancelDraft := context.WithCancel(ctx)
+	draftCtx = context.WithValue(draftCtx, schemas.BifrostContextKeySessionID, "")
+	draftCtx = context.WithValue(draftCtx, schemas.BifrostContextKeyQueueStatus, nil)
+	draftReq := *req
+	draftReq.Provider = draft.Provider
+	draftReq.Model = draft.Model
+	draftReq.Fallbacks = nil
+
+	premiumStart := make(chan streamStart, 1)
+	draftStart := make(chan streamStart, 1)
+	go func() {
+		stream, bifrostErr := bifrost.handleStreamRequest(ctx, req, requestType)
+		premiumStart <- streamStart{stream: stream, err: bifrostErr}
+	}()
+	go func() {
+		stream, bifrostErr := bifrost.handleStreamRequest(draftCtx, &draftReq, requestType)
+		draftStart <- streamStart{stream: stream, err: bifrostErr}
+	}()
+
+	return bifrost.mergeSpeculativeStreams(premiumStart, draftStart, cancelDraft, draft)
+}
+
+// mergeSpeculativeStreams merges the requested model's stream and the draft stream as described
+// in streamWithSpeculativeDraft. The outcome of starting each stream arrives on premiumStart and
+// draftStart.
+func (bifrost *Bifrost) mergeSpeculativeStreams(premiumStart, draftStart chan streamStart, cancelDraft context.CancelFunc, draft schemas.SpeculativeDraft) chan *schemas.BifrostStream {
+	outputStream := make(chan *schemas.BifrostStream, 1)
+
+	go func() {
+		defer close(outputStream)
+		defer cancelDraft()
+
+		var premiumStream, draftStream chan *schemas.BifrostStream
+		premiumPending, draftPending := premiumStart, draftStart
+		var premiumErr *schemas.BifrostError
+		draftChunks := 0
+		started, promoted := false, false
+
+		resync := func(reason string) *schemas.BifrostStream {
+			return &schemas.BifrostStream{Resync: &schemas.StreamResync{
+				Reason:        reason,
+				DraftProvider: draft.Provider,
+				DraftModel:    draft.Model,
+				DraftChunks:   draftChunks,
+			}}
+		}
+
+		// premiumFailed handles the requested model failing before it sent any output: the draft
+		// becomes the answer if there is one. It reports whether the stream is over.
+		premiumFailed := func(bifrostErr *schemas.BifrostError) bool {
+			premiumErr = bifrostErr
+			if draftStream == nil && draftPending == nil && draftChunks == 0 {
+				outputStream <- &schemas.BifrostStream{BifrostError: bifrostErr}
+				return true
+			}
+			promoted = true
+			outputStream <- resync(schemas.StreamResyncDraftPromoted)
+			return draftStream == nil && draftPending == nil
+		}
+
+		for {
+			select {
+			case start := <-premiumPending:
+				premiumPending = nil
+				if start.err == nil {
+					premiumStream = start.stream
+				} else if premiumFailed(start.err) {
+					return
+				}
+
+			case start := <-draftPending:
+				draftPending = nil
+				if start.err == nil {
+					draftStream = start.stream
+					continue
+				}
+				bifrost.logger.Debug("speculative draft %s/%s failed: %s", draft.Provider, draft.Model, start.err.Error.Message)
+				if promoted && draftChunks == 0 {
+					outputStream <- &schemas.BifrostStream{BifrostError: premiumErr}
+					return
+				}
+				if promoted {
+					return
+				}
+
+			case chunk, ok := <-draftStream:
+				if !ok {
+					draftStream = nil
+					if promoted {
+						return
+					}
+					continue
+				}
+				if chunk == nil {
+					continue
+				}
+				if !promoted {
+					if chunk.BifrostResponse == nil {
+						// A failing draft is dropped, the requested model still answers
+						continue
+					}
+					chunk.BifrostResponse.ExtraFields.Draft = true
+					draftChunks++
+				}
+				outputStream <- chunk
+
+			case chunk, ok := <-premiumStream:
+				if !ok {
+					if started {
+						return
+					}
+					premiumStream = nil
+					if premiumFailed(newBifrostErrorFromMsg("stream ended without output", schemas.ErrorOriginProvider)) {
+						return
+					}
+					continue
+				}
+				if chunk == nil {
+					continue
+				}
+				if !started {
+					if chunk.BifrostResponse == nil && chunk.BifrostError != nil {
+						go drainStream(premiumStream)
+						premiumStream = nil
+						if premiumFailed(chunk.BifrostError) {
+							return
+						}
+						continue
+					}
+
+					// Cut over: the draft is cancelled and its remaining chunks discarded
+					started = true
+					cancelDraft()
+					if draftStream != nil {
+						go drainStream(draftStream)
+					}
+					if draftPending != nil {
+						go func(pending chan streamStart) {
+							if start := <-pending; start.err == nil {
+								drainStream(start.stream)
+							}
+						}(draftPending)
+					}
+					draftStream, draftPending = nil, nil
+					if draftChunks > 0 {
+						outputStream <- resync(schemas.StreamResyncPremiumStarted)
+					}
+				}
+				outputStream <- chunk
+			}
+		}
+	}()
+
+	return outputStream
+}
+
+// drainStream discards the remaining chunks of a stream.
+func drainStream(stream chan *schemas.BifrostStream) {
+	for range stream {
+	}
+}
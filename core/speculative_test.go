@@ -0,0 +1,161 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestGetSpeculativeDraft(t *testing.T) {
+	draft := schemas.SpeculativeDraft{Provider: schemas.Groq, Model: "llama-3.1-8b-instant"}
+
+	tests := []struct {
+		name        string
+		value       interface{}
+		requestType schemas.RequestType
+		want        bool
+	}{
+		{name: "chat stream", value: draft, requestType: schemas.ChatCompletionStreamRequest, want: true},
+		{name: "not set", requestType: schemas.ChatCompletionStreamRequest},
+		{name: "non-streaming chat", value: draft, requestType: schemas.ChatCompletionRequest},
+		{name: "other stream", value: draft, requestType: schemas.SpeechStreamRequest},
+		{name: "no model", value: schemas.SpeculativeDraft{Provider: schemas.Groq}, requestType: schemas.ChatCompletionStreamRequest},
+		{name: "cleared", value: schemas.SpeculativeDraft{}, requestType: schemas.ChatCompletionStreamRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.value != nil {
+				ctx = context.WithValue(ctx, schemas.BifrostContextKeySpeculativeDraft, tt.value)
+			}
+			got, ok := getSpeculativeDraft(ctx, tt.requestType)
+			if ok != tt.want {
+				t.Fatalf("getSpeculativeDraft() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && got != draft {
+				t.Errorf("getSpeculativeDraft() = %+v, want %+v", got, draft)
+			}
+		})
+	}
+}
+
+func TestMergeSpeculativeStreams(t *testing.T) {
+	// Steps are "<stream>:<event>" with stream premium or draft. Events are start, fail (to
+	// start), close, error (an error chunk) or the content of a chunk. Outputs are the content of
+	// chunks, prefixed with "draft " for draft chunks, "resync <reason> <draft chunks>" and
+	// "error <message>".
+	tests := []struct {
+		name  string
+		steps []string
+		want  []string
+	}{
+		{
+			name:  "requested model takes over",
+			steps: []string{"draft:start", "premium:start", "draft:Hi", "draft:!", "premium:Hello", "draft:late", "premium:close", "draft:close"},
+			want:  []string{"draft Hi", "draft !", "resync premium_started 2", "Hello"},
+		},
+		{
+			name:  "requested model first",
+			steps: []string{"premium:start", "draft:start", "premium:Hello", "premium:close", "draft:close"},
+			want:  []string{"Hello"},
+		},
+		{
+			name:  "requested model fails to start",
+			steps: []string{"draft:start", "draft:Hi", "premium:fail", "draft:!", "draft:close"},
+			want:  []string{"draft Hi", "resync draft_promoted 1", "!"},
+		},
+		{
+			name:  "requested model fails before the draft starts",
+			steps: []string{"premium:fail", "draft:start", "draft:Hi", "draft:close"},
+			want:  []string{"resync draft_promoted 0", "Hi"},
+		},
+		{
+			name:  "requested model sends an error first",
+			steps: []string{"draft:start", "premium:start", "draft:Hi", "premium:error", "draft:!", "draft:close", "premium:close"},
+			want:  []string{"draft Hi", "resync draft_promoted 1", "!"},
+		},
+		{
+			name:  "requested model ends without output",
+			steps: []string{"draft:fail", "premium:start", "premium:close"},
+			want:  []string{"error stream ended without output"},
+		},
+		{
+			name:  "both fail",
+			steps: []string{"premium:fail", "draft:fail"},
+			want:  []string{"resync draft_promoted 0", "error premium failed"},
+		},
+		{
+			name:  "draft fails",
+			steps: []string{"draft:fail", "premium:start", "premium:Hello", "premium:close"},
+			want:  []string{"Hello"},
+		},
+		{
+			name:  "draft error chunk dropped",
+			steps: []string{"draft:start", "draft:error", "premium:start", "premium:Hello", "premium:close", "draft:close"},
+			want:  []string{"Hello"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bifrost := &Bifrost{logger: NewDefaultLogger(schemas.LogLevelError)}
+			starts := map[string]chan streamStart{"premium": make(chan streamStart), "draft": make(chan streamStart)}
+			streams := map[string]chan *schemas.BifrostStream{"premium": make(chan *schemas.BifrostStream), "draft": make(chan *schemas.BifrostStream)}
+			draftCancelled := false
+			output := bifrost.mergeSpeculativeStreams(starts["premium"], starts["draft"], func() { draftCancelled = true }, schemas.SpeculativeDraft{Provider: schemas.Groq, Model: "llama-3.1-8b-instant"})
+
+			var got []string
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for chunk := range output {
+					got = append(got, describeSpeculativeChunk(chunk))
+				}
+			}()
+
+			for _, step := range tt.steps {
+				name, event, _ := strings.Cut(step, ":")
+				switch event {
+				case "start":
+					starts[name] <- streamStart{stream: streams[name]}
+				case "fail":
+					starts[name] <- streamStart{err: newBifrostErrorFromMsg(name+" failed", schemas.ErrorOriginProvider)}
+				case "close":
+					close(streams[name])
+				case "error":
+					streams[name] <- &schemas.BifrostStream{BifrostError: newBifrostErrorFromMsg(name+" stream failed", schemas.ErrorOriginProvider)}
+				default:
+					content := event
+					streams[name] <- &schemas.BifrostStream{BifrostResponse: &schemas.BifrostResponse{Choices: []schemas.BifrostResponseChoice{{
+						BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{Delta: schemas.BifrostStreamDelta{Content: &content}},
+					}}}}
+				}
+			}
+			<-done
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if !draftCancelled {
+				t.Error("draft was not cancelled when the stream ended")
+			}
+		})
+	}
+}
+
+// describeSpeculativeChunk summarizes an output chunk of a speculative stream.
+func describeSpeculativeChunk(chunk *schemas.BifrostStream) string {
+	switch {
+	case chunk.Resync != nil:
+		return fmt.Sprintf("resync %s %d", chunk.Resync.Reason, chunk.Resync.DraftChunks)
+	case chunk.BifrostError != nil:
+		return "error " + chunk.BifrostError.Error.Message
+	case chunk.BifrostResponse.ExtraFields.Draft:
+		return "draft " + *chunk.Choices[0].BifrostStreamResponseChoice.Delta.Content
+	default:
+		return *chunk.Choices[0].BifrostStreamResponseChoice.Delta.Content
+	}
+}
@@ -0,0 +1,154 @@
+package bifrost
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// STICKY KEY ROUTING
+// ============================================================================
+
+// stickyRouter maps routing keys onto the keys of a provider with a consistent hash ring, so
+// requests of the same user or session reach the same backend (an Azure deployment, a vLLM
+// node) and reuse its prompt and KV cache. Rings are built from the keys eligible for a
+// provider and model and rebuilt when those keys or their weights change.
+type stickyRouter struct {
+	virtualNodes int
+	rings        sync.Map // provider and model -> *hashRing
+}
+
+// hashRing is a consistent hash ring over a set of keys.
+type hashRing struct {
+	signature string      // Node IDs and weights the ring was built from
+	points    []ringPoint // Sorted by hash
+}
+
+// ringPoint is a point of a key on the hash ring.
+type ringPoint struct {
+	hash   uint64
+	nodeID string
+}
+
+// newStickyRouter creates a sticky router, applying defaults to unset config values.
+func newStickyRouter(config *schemas.StickyRoutingConfig) *stickyRouter {
+	router := &stickyRouter{virtualNodes: schemas.DefaultStickyRoutingVirtualNodes}
+	if config != nil && config.VirtualNodes > 0 {
+		router.virtualNodes = config.VirtualNodes
+	}
+	return router
+}
+
+// getRoutingKey returns the routing key of a request, the explicit routing key if set and
+// otherwise the session ID, empty if there is neither.
+func getRoutingKey(ctx *context.Context) string {
+	if ctx == nil || *ctx == nil {
+		return ""
+	}
+	if routingKey, ok := (*ctx).Value(schemas.BifrostContextKeyRoutingKey).(string); ok && routingKey != "" {
+		return routingKey
+	}
+	sessionID, _ := (*ctx).Value(schemas.BifrostContextKeySessionID).(string)
+	return sessionID
+}
+
+// selectKey returns the key the routing key hashes to among the given keys of a provider and
+// model. It returns false if none of the keys has a positive weight.
+func (r *stickyRouter) selectKey(providerKey schemas.ModelProvider, model string, routingKey string, keys []schemas.Key) (schemas.Key, bool) {
+	ring := r.getRing(string(providerKey)+"/"+model, keys)
+	if len(ring.points) == 0 {
+		return schemas.Key{}, false
+	}
+
+	hash := ringHash(routingKey)
+	i := sort.Search(len(ring.points), func(i int) bool { return ring.points[i].hash >= hash })
+	if i == len(ring.points) {
+		i = 0
+	}
+
+	nodeID := ring.points[i].nodeID
+	for _, key := range keys {
+		if stickyNodeID(key) == nodeID {
+			return key, true
+		}
+	}
+	return schemas.Key{}, false
+}
+
+// getRing returns the ring for a provider and model, rebuilding it if its keys changed.
+func (r *stickyRouter) getRing(ringID string, keys []schemas.Key) *hashRing {
+	signature := ringSignature(keys)
+	if value, ok := r.rings.Load(ringID); ok {
+		if ring := value.(*hashRing); ring.signature == signature {
+			return ring
+		}
+	}
+
+	ring := &hashRing{signature: signature}
+	for _, key := range keys {
+		if key.Weight <= 0 {
+			continue
+		}
+		nodeID := stickyNodeID(key)
+		points := max(1, int(math.Round(key.Weight*float64(r.virtualNodes))))
+		for replica := range points {
+			ring.points = append(ring.points, ringPoint{
+				hash:   ringHash(nodeID + "#" + strconv.Itoa(replica)),
+				nodeID: nodeID,
+			})
+		}
+	}
+	slices.SortFunc(ring.points, func(a, b ringPoint) int {
+		if a.hash != b.hash {
+			if a.hash < b.hash {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.nodeID, b.nodeID)
+	})
+
+	r.rings.Store(ringID, ring)
+	return ring
+}
+
+// ringSignature identifies a set of keys and their weights, independent of their order.
+func ringSignature(keys []schemas.Key) string {
+	nodes := make([]string, 0, len(keys))
+	for _, key := range keys {
+		nodes = append(nodes, stickyNodeID(key)+"="+strconv.FormatFloat(key.Weight, 'g', -1, 64))
+	}
+	slices.Sort(nodes)
+	return strings.Join(nodes, ",")
+}
+
+// stickyNodeID identifies a key on the ring by its ID, or by a hash of its value if it has none,
+// so the same key lands on the same points across restarts and replicas.
+func stickyNodeID(key schemas.Key) string {
+	if key.ID != "" {
+		return key.ID
+	}
+	return "value:" + strconv.FormatUint(ringHash(key.Value), 16)
+}
+
+// ringHash hashes a string onto the ring. FNV-1a is finalized with a 64-bit mix so that similar
+// strings, such as the replicas of a node, spread evenly.
+func ringHash(s string) uint64 {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(s))
+	hash := hasher.Sum64()
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	return hash
+}
@@ -0,0 +1,244 @@
+package streamio
+
+import (
+	"strings"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// Accumulate reads a stream to the end and assembles its chunks into a single
+// non-streaming response. Chat and text completion deltas are merged per choice
+// (content, thoughts, refusals, tool call arguments, citations and images), speech audio
+// is concatenated and transcription deltas are joined into the transcript text.
+// Draft chunks of a speculative stream are dropped unless the draft is promoted to the answer.
+// The first error chunk is returned and the rest of the stream is drained in the background.
+func Accumulate(stream <-chan *schemas.BifrostStream) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	var result *schemas.BifrostResponse
+	var choices []schemas.BifrostResponseChoice
+
+	add := func(response *schemas.BifrostResponse) {
+		if result == nil {
+			result = &schemas.BifrostResponse{
+				ID:          response.ID,
+				Object:      response.Object,
+				Model:       response.Model,
+				Created:     response.Created,
+				ExtraFields: response.ExtraFields,
+			}
+		}
+		if response.Usage != nil {
+			result.Usage = response.Usage
+		}
+		if response.ServiceTier != nil {
+			result.ServiceTier = response.ServiceTier
+		}
+		if response.SystemFingerprint != nil {
+			result.SystemFingerprint = response.SystemFingerprint
+		}
+		result.ExtraFields.Latency = response.ExtraFields.Latency
+
+		for _, choice := range response.Choices {
+			choices = accumulateChoice(choices, choice)
+		}
+
+		if response.Speech != nil {
+			if result.Speech == nil {
+				result.Speech = &schemas.BifrostSpeech{}
+			}
+			result.Speech.Audio = append(result.Speech.Audio, response.Speech.Audio...)
+			if response.Speech.Usage != nil {
+				result.Speech.Usage = response.Speech.Usage
+			}
+		}
+
+		if response.Transcribe != nil {
+			if result.Transcribe == nil {
+				result.Transcribe = &schemas.BifrostTranscribe{BifrostTranscribeNonStreamResponse: &schemas.BifrostTranscribeNonStreamResponse{}}
+			}
+			accumulateTranscription(result.Transcribe, response.Transcribe)
+		}
+	}
+
+	// Draft chunks of a speculative stream are held back until a resync tells whether the
+	// requested model took over or the draft was promoted to the answer
+	var drafts []*schemas.BifrostResponse
+
+	for chunk := range stream {
+		if chunk == nil {
+			continue
+		}
+		if chunk.BifrostError != nil {
+			go drain(stream)
+			return nil, chunk.BifrostError
+		}
+		if chunk.Resync != nil {
+			if chunk.Resync.Reason == schemas.StreamResyncDraftPromoted {
+				for _, draft := range drafts {
+					add(draft)
+				}
+			}
+			drafts = nil
+			continue
+		}
+		response := chunk.BifrostResponse
+		if response == nil {
+			continue
+		}
+		if response.ExtraFields.Draft {
+			drafts = append(drafts, response)
+			continue
+		}
+		add(response)
+	}
+
+	if result == nil {
+		return nil, &schemas.BifrostError{
+			IsBifrostError: true,
+			Origin:         schemas.ErrorOriginProvider,
+			Error: schemas.ErrorField{
+				Message: "stream ended without any response",
+			},
+		}
+	}
+
+	result.Choices = choices
+	result.Object = strings.TrimSuffix(result.Object, ".chunk")
+	result.ExtraFields.ChunkIndex = 0
+	return result, nil
+}
+
+// accumulateChoice merges a stream choice into the choice with the same index.
+func accumulateChoice(choices []schemas.BifrostResponseChoice, choice schemas.BifrostResponseChoice) []schemas.BifrostResponseChoice {
+	var target *schemas.BifrostResponseChoice
+	for i := range choices {
+		if choices[i].Index == choice.Index {
+			target = &choices[i]
+			break
+		}
+	}
+	if target == nil {
+		choices = append(choices, schemas.BifrostResponseChoice{
+			Index: choice.Index,
+			BifrostNonStreamResponseChoice: &schemas.BifrostNonStreamResponseChoice{
+				Message: schemas.BifrostMessage{Role: schemas.ModelChatMessageRoleAssistant},
+			},
+		})
+		target = &choices[len(choices)-1]
+	}
+
+	if choice.FinishReason != nil {
+		target.FinishReason = choice.FinishReason
+	}
+
+	// Providers that fall back to a single complete response send non-stream choices
+	if choice.BifrostNonStreamResponseChoice != nil {
+		target.BifrostNonStreamResponseChoice = choice.BifrostNonStreamResponseChoice
+		return choices
+	}
+	if choice.BifrostStreamResponseChoice == nil {
+		return choices
+	}
+
+	delta := choice.BifrostStreamResponseChoice.Delta
+	message := &target.BifrostNonStreamResponseChoice.Message
+	if delta.Role != nil {
+		message.Role = schemas.ModelChatMessageRole(*delta.Role)
+	}
+	if delta.Content != nil {
+		message.Content.ContentStr = appendString(message.Content.ContentStr, *delta.Content)
+	}
+
+	if delta.Thought == nil && delta.ThoughtSignature == nil && delta.Refusal == nil && len(delta.ToolCalls) == 0 &&
+		len(delta.Annotations) == 0 && len(delta.Citations) == 0 && len(delta.Images) == 0 {
+		return choices
+	}
+
+	if message.AssistantMessage == nil {
+		message.AssistantMessage = &schemas.AssistantMessage{}
+	}
+	assistant := message.AssistantMessage
+	if delta.Thought != nil {
+		assistant.Thought = appendString(assistant.Thought, *delta.Thought)
+	}
+	if delta.ThoughtSignature != nil {
+		assistant.ThoughtSignature = delta.ThoughtSignature
+	}
+	if delta.Refusal != nil {
+		assistant.Refusal = appendString(assistant.Refusal, *delta.Refusal)
+	}
+	assistant.Annotations = append(assistant.Annotations, delta.Annotations...)
+	assistant.Citations = append(assistant.Citations, delta.Citations...)
+	assistant.Images = append(assistant.Images, delta.Images...)
+	accumulateToolCalls(assistant, delta.ToolCalls)
+
+	return choices
+}
+
+// accumulateToolCalls merges tool call deltas. A delta with a new ID starts a tool call,
+// deltas without an ID continue the most recent one.
+func accumulateToolCalls(assistant *schemas.AssistantMessage, deltas []schemas.ToolCall) {
+	if len(deltas) == 0 {
+		return
+	}
+	if assistant.ToolCalls == nil {
+		assistant.ToolCalls = &[]schemas.ToolCall{}
+	}
+	toolCalls := *assistant.ToolCalls
+
+	for _, delta := range deltas {
+		index := -1
+		if delta.ID != nil {
+			for i := range toolCalls {
+				if toolCalls[i].ID != nil && *toolCalls[i].ID == *delta.ID {
+					index = i
+					break
+				}
+			}
+		} else if len(toolCalls) > 0 {
+			index = len(toolCalls) - 1
+		}
+
+		if index == -1 {
+			toolCalls = append(toolCalls, delta)
+			continue
+		}
+		toolCall := &toolCalls[index]
+		if delta.Function.Name != nil && toolCall.Function.Name == nil {
+			toolCall.Function.Name = delta.Function.Name
+		}
+		toolCall.Function.Arguments += delta.Function.Arguments
+	}
+
+	assistant.ToolCalls = &toolCalls
+}
+
+// accumulateTranscription merges a transcription stream chunk into the transcript.
+func accumulateTranscription(result *schemas.BifrostTranscribe, chunk *schemas.BifrostTranscribe) {
+	if chunk.Usage != nil {
+		result.Usage = chunk.Usage
+	}
+	result.LogProbs = append(result.LogProbs, chunk.LogProbs...)
+
+	if chunk.BifrostTranscribeStreamResponse == nil {
+		// Complete transcripts, e.g. the final "transcript.text.done" event, replace the deltas
+		if chunk.Text != "" {
+			result.Text = chunk.Text
+		}
+		return
+	}
+	stream := chunk.BifrostTranscribeStreamResponse
+	switch {
+	case stream.Delta != nil:
+		result.Text += *stream.Delta
+	case chunk.Text != "":
+		result.Text = chunk.Text
+	}
+}
+
+func appendString(existing *string, s string) *string {
+	if existing == nil {
+		return &s
+	}
+	joined := *existing + s
+	return &joined
+}
@@ -0,0 +1,146 @@
+package streamio
+
+import (
+	"reflect"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestAccumulate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	delta := func(index int, d schemas.BifrostStreamDelta, draft bool) *schemas.BifrostStream {
+		return &schemas.BifrostStream{BifrostResponse: &schemas.BifrostResponse{
+			ID:          "resp-1",
+			Object:      "chat.completion.chunk",
+			Choices:     []schemas.BifrostResponseChoice{{Index: index, BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{Delta: d}}},
+			ExtraFields: schemas.BifrostResponseExtraFields{Draft: draft},
+		}}
+	}
+	content := func(index int, s string) *schemas.BifrostStream {
+		return delta(index, schemas.BifrostStreamDelta{Content: &s}, false)
+	}
+	draft := func(s string) *schemas.BifrostStream {
+		return delta(0, schemas.BifrostStreamDelta{Content: &s}, true)
+	}
+	resync := func(reason string) *schemas.BifrostStream {
+		return &schemas.BifrostStream{Resync: &schemas.StreamResync{Reason: reason}}
+	}
+	toolCall := func(id *string, name *string, arguments string) *schemas.BifrostStream {
+		return delta(0, schemas.BifrostStreamDelta{ToolCalls: []schemas.ToolCall{{ID: id, Function: schemas.FunctionCall{Name: name, Arguments: arguments}}}}, false)
+	}
+	transcript := func(transcribe schemas.BifrostTranscribe) *schemas.BifrostStream {
+		return &schemas.BifrostStream{BifrostResponse: &schemas.BifrostResponse{Object: "audio.transcription.chunk", Transcribe: &transcribe}}
+	}
+
+	tests := []struct {
+		name          string
+		chunks        []*schemas.BifrostStream
+		wantContent   []string // Content of each choice, in order
+		wantToolCalls []string // "name(arguments)" of the first choice's tool calls
+		wantText      string   // Transcript text
+		wantErr       string
+	}{
+		{
+			name:        "content deltas per choice",
+			chunks:      []*schemas.BifrostStream{content(0, "Hel"), content(1, "Bye"), content(0, "lo"), nil, content(1, "!")},
+			wantContent: []string{"Hello", "Bye!"},
+		},
+		{
+			name: "tool call deltas",
+			chunks: []*schemas.BifrostStream{
+				toolCall(str("call_1"), str("search"), `{"q":`),
+				toolCall(nil, nil, `"go"}`),
+				toolCall(str("call_2"), str("fetch"), `{}`),
+				toolCall(str("call_1"), str("ignored"), ``),
+			},
+			wantContent:   []string{""},
+			wantToolCalls: []string{`search({"q":"go"})`, `fetch({})`},
+		},
+		{
+			name:        "draft discarded when the requested model starts",
+			chunks:      []*schemas.BifrostStream{draft("guess"), resync(schemas.StreamResyncPremiumStarted), content(0, "answer")},
+			wantContent: []string{"answer"},
+		},
+		{
+			name:        "draft promoted",
+			chunks:      []*schemas.BifrostStream{draft("dr"), draft("aft"), resync(schemas.StreamResyncDraftPromoted), content(0, " continued")},
+			wantContent: []string{"draft continued"},
+		},
+		{
+			name: "transcription deltas and final text",
+			chunks: []*schemas.BifrostStream{
+				transcript(schemas.BifrostTranscribe{BifrostTranscribeStreamResponse: &schemas.BifrostTranscribeStreamResponse{Delta: str("hello ")}}),
+				transcript(schemas.BifrostTranscribe{BifrostTranscribeStreamResponse: &schemas.BifrostTranscribeStreamResponse{Delta: str("wrld")}}),
+				transcript(schemas.BifrostTranscribe{Text: "hello world"}),
+			},
+			wantText: "hello world",
+		},
+		{
+			name:    "error chunk",
+			chunks:  []*schemas.BifrostStream{content(0, "partial"), {BifrostError: &schemas.BifrostError{Error: schemas.ErrorField{Message: "upstream failed"}}}, content(0, "more")},
+			wantErr: "upstream failed",
+		},
+		{
+			name:    "empty stream",
+			wantErr: "stream ended without any response",
+		},
+		{
+			name:    "only draft chunks",
+			chunks:  []*schemas.BifrostStream{draft("guess")},
+			wantErr: "stream ended without any response",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := make(chan *schemas.BifrostStream, len(tt.chunks))
+			for _, chunk := range tt.chunks {
+				stream <- chunk
+			}
+			close(stream)
+
+			result, bifrostErr := Accumulate(stream)
+			if tt.wantErr != "" {
+				if bifrostErr == nil || bifrostErr.Error.Message != tt.wantErr {
+					t.Fatalf("Accumulate() error = %+v, want %q", bifrostErr, tt.wantErr)
+				}
+				return
+			}
+			if bifrostErr != nil {
+				t.Fatalf("Accumulate() error = %s", bifrostErr.Error.Message)
+			}
+
+			var gotContent []string
+			var gotToolCalls []string
+			for _, choice := range result.Choices {
+				message := choice.BifrostNonStreamResponseChoice.Message
+				text := ""
+				if message.Content.ContentStr != nil {
+					text = *message.Content.ContentStr
+				}
+				gotContent = append(gotContent, text)
+				if len(gotContent) == 1 && message.AssistantMessage != nil && message.AssistantMessage.ToolCalls != nil {
+					for _, toolCall := range *message.AssistantMessage.ToolCalls {
+						gotToolCalls = append(gotToolCalls, *toolCall.Function.Name+"("+toolCall.Function.Arguments+")")
+					}
+				}
+			}
+			if !reflect.DeepEqual(gotContent, tt.wantContent) {
+				t.Errorf("content = %q, want %q", gotContent, tt.wantContent)
+			}
+			if !reflect.DeepEqual(gotToolCalls, tt.wantToolCalls) {
+				t.Errorf("tool calls = %q, want %q", gotToolCalls, tt.wantToolCalls)
+			}
+			gotText := ""
+			if result.Transcribe != nil {
+				gotText = result.Transcribe.Text
+			}
+			if gotText != tt.wantText {
+				t.Errorf("transcript = %q, want %q", gotText, tt.wantText)
+			}
+			if result.Object == "chat.completion.chunk" || result.Object == "audio.transcription.chunk" {
+				t.Errorf("object = %q, want the .chunk suffix removed", result.Object)
+			}
+		})
+	}
+}
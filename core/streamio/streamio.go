@@ -0,0 +1,227 @@
+// Package streamio adapts Bifrost stream channels to the standard io interfaces, so web
+// handlers and CLI tools can consume streams without reimplementing delta assembly.
+// Reader exposes the generated text as an io.Reader and WriteTo encodes the raw chunks
+// as Server-Sent Events or newline delimited JSON.
+package streamio
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// StreamError is returned by Reader when the stream delivers an error chunk.
+type StreamError struct {
+	Err *schemas.BifrostError
+}
+
+func (e *StreamError) Error() string {
+	if e.Err == nil {
+		return "stream error"
+	}
+	if e.Err.Error.Message != "" {
+		return e.Err.Error.Message
+	}
+	if e.Err.Error.Error != nil {
+		return e.Err.Error.Error.Error()
+	}
+	return "stream error"
+}
+
+// Reader is an io.ReadCloser over the text of a Bifrost stream. It concatenates the
+// content deltas of the first choice (chat and text completion streams) and the text
+// deltas of transcription streams. Other chunks are skipped.
+type Reader struct {
+	stream       <-chan *schemas.BifrostStream
+	pending      string
+	err          error
+	usage        *schemas.LLMUsage
+	finishReason string
+}
+
+// NewReader returns a Reader over the text of stream.
+func NewReader(stream <-chan *schemas.BifrostStream) *Reader {
+	return &Reader{stream: stream}
+}
+
+// Read reads generated text into p. It returns io.EOF once the stream is closed and a
+// *StreamError if the stream delivered an error.
+func (r *Reader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for r.pending == "" {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.next()
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// next receives the next chunk, appending its text to pending or setting err.
+func (r *Reader) next() {
+	chunk, ok := <-r.stream
+	if !ok {
+		r.err = io.EOF
+		return
+	}
+	if chunk == nil {
+		return
+	}
+	if chunk.BifrostError != nil {
+		r.err = &StreamError{Err: chunk.BifrostError}
+		return
+	}
+	if chunk.BifrostResponse == nil {
+		return
+	}
+
+	response := chunk.BifrostResponse
+	if response.Usage != nil {
+		r.usage = response.Usage
+	}
+	for _, choice := range response.Choices {
+		if choice.Index != 0 {
+			continue
+		}
+		if choice.FinishReason != nil {
+			r.finishReason = *choice.FinishReason
+		}
+		if choice.BifrostStreamResponseChoice != nil && choice.BifrostStreamResponseChoice.Delta.Content != nil {
+			r.pending += *choice.BifrostStreamResponseChoice.Delta.Content
+		}
+	}
+	if response.Transcribe != nil && response.Transcribe.BifrostTranscribeStreamResponse != nil && response.Transcribe.Delta != nil {
+		r.pending += *response.Transcribe.Delta
+	}
+}
+
+// Usage returns the token usage reported by the stream so far, usually only set once the
+// stream has been read to the end.
+func (r *Reader) Usage() *schemas.LLMUsage {
+	return r.usage
+}
+
+// FinishReason returns the finish reason of the first choice, empty until it is received.
+func (r *Reader) FinishReason() string {
+	return r.finishReason
+}
+
+// Close stops reading and drains the rest of the stream in the background, so the
+// producer is never blocked on an abandoned stream.
+func (r *Reader) Close() error {
+	if r.err == nil {
+		r.err = io.ErrClosedPipe
+		go drain(r.stream)
+	}
+	return nil
+}
+
+// ReadAll reads a stream to the end and returns its text.
+func ReadAll(stream <-chan *schemas.BifrostStream) (string, error) {
+	var text strings.Builder
+	_, err := io.Copy(&text, NewReader(stream))
+	return text.String(), err
+}
+
+func drain(stream <-chan *schemas.BifrostStream) {
+	for range stream {
+	}
+}
+
+// ============================================================================
+// ENCODING
+// ============================================================================
+
+// Format is a wire format for encoded stream chunks.
+type Format string
+
+const (
+	FormatSSE    Format = "sse"    // Server-Sent Events: "data: <json>\n\n" per chunk, terminated by "data: [DONE]\n\n"
+	FormatNDJSON Format = "ndjson" // Newline delimited JSON: one JSON object per line
+)
+
+// flusher is implemented by buffered writers such as *bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+// httpFlusher is implemented by http.ResponseWriter.
+type httpFlusher interface {
+	Flush()
+}
+
+// WriteTo encodes every chunk of stream to w in the given format, flushing after each
+// chunk when w supports it. Error chunks are written as {"error": ...} objects. If
+// writing fails the rest of the stream is drained in the background and the error is
+// returned together with the number of bytes written.
+func WriteTo(w io.Writer, stream <-chan *schemas.BifrostStream, format Format) (int64, error) {
+	if format != FormatSSE && format != FormatNDJSON {
+		go drain(stream)
+		return 0, fmt.Errorf("unsupported stream format %q", format)
+	}
+
+	var written int64
+	write := func(data []byte) error {
+		n, err := w.Write(data)
+		written += int64(n)
+		if err != nil {
+			return err
+		}
+		switch f := w.(type) {
+		case flusher:
+			return f.Flush()
+		case httpFlusher:
+			f.Flush()
+		}
+		return nil
+	}
+
+	for chunk := range stream {
+		if chunk == nil {
+			continue
+		}
+
+		var payload interface{} = chunk.BifrostResponse
+		if chunk.BifrostError != nil {
+			payload = map[string]interface{}{"error": chunk.BifrostError}
+		} else if chunk.BifrostResponse == nil {
+			continue
+		}
+
+		data, err := sonic.Marshal(payload)
+		if err != nil {
+			go drain(stream)
+			return written, fmt.Errorf("failed to marshal stream chunk: %w", err)
+		}
+
+		if err := write(encodeChunk(data, format)); err != nil {
+			go drain(stream)
+			return written, err
+		}
+	}
+
+	if format == FormatSSE {
+		if err := write([]byte("data: [DONE]\n\n")); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// encodeChunk frames a JSON encoded chunk in the given format.
+func encodeChunk(data []byte, format Format) []byte {
+	if format == FormatSSE {
+		framed := make([]byte, 0, len(data)+8)
+		framed = append(framed, "data: "...)
+		framed = append(framed, data...)
+		return append(framed, "\n\n"...)
+	}
+	return append(data, '\n')
+}
@@ -0,0 +1,175 @@
+package bifrost
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/chunker"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// TOKEN COUNTING
+// ============================================================================
+
+const (
+	// tokenCountCacheSize is the number of exact token counts remembered.
+	tokenCountCacheSize = 1024
+	// tokenCountCacheTTL is how long an exact token count is served to identical requests.
+	tokenCountCacheTTL = time.Hour
+)
+
+// tokenCountEntry is the exact token count of a request.
+type tokenCountEntry struct {
+	key         string
+	inputTokens int
+	counted     time.Time
+}
+
+// tokenCountCache remembers the exact token counts of recent requests, so counting the same
+// request again, as clients do before every turn, does not call the provider.
+type tokenCountCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element // Elements hold a *tokenCountEntry
+	order   *list.List               // Most recently used first
+}
+
+func newTokenCountCache() *tokenCountCache {
+	return &tokenCountCache{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the token count of a request key, false if unknown or expired.
+func (c *tokenCountCache) get(key string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return 0, false
+	}
+	entry := element.Value.(*tokenCountEntry)
+	if time.Since(entry.counted) > tokenCountCacheTTL {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return 0, false
+	}
+	c.order.MoveToFront(element)
+	return entry.inputTokens, true
+}
+
+// add remembers the token count of a request key and forgets the least recently used counts.
+func (c *tokenCountCache) add(key string, inputTokens int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&tokenCountEntry{key: key, inputTokens: inputTokens, counted: time.Now()})
+
+	for c.order.Len() > tokenCountCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*tokenCountEntry).key)
+	}
+}
+
+// tokenCountKey identifies the requests that have the same token count: same provider, model,
+// messages and parameters. It returns "" when the request cannot be keyed.
+func tokenCountKey(req *schemas.BifrostRequest) string {
+	// encoding/json sorts map keys, so identical extra params give identical keys
+	data, err := json.Marshal(struct {
+		Provider schemas.ModelProvider    `json:"provider"`
+		Model    string                   `json:"model"`
+		Messages []schemas.BifrostMessage `json:"messages"`
+		Params   *schemas.ModelParameters `json:"params"`
+	}{req.Provider, req.Model, *req.Input.ChatCompletionInput, req.Params})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// CountTokensRequest returns the input tokens a chat completion request would use. Providers
+// with a token counting endpoint, such as Anthropic, count them exactly, including tools and
+// images; exact counts are remembered for an hour and served to identical requests. Other
+// providers get an approximation, counted with the tokenizer of the automatic max_tokens
+// layer when configured. Fallbacks are never tried, as tokenizers differ between models.
+func (bifrost *Bifrost) CountTokensRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if req.Input.ChatCompletionInput == nil || len(*req.Input.ChatCompletionInput) == 0 {
+		return nil, newBifrostErrorFromMsg("messages not provided for token count request", schemas.ErrorOriginClientRequest)
+	}
+	if ctx == nil {
+		ctx = bifrost.ctx
+	}
+
+	key := tokenCountKey(req)
+	if key != "" {
+		if inputTokens, ok := bifrost.tokenCounts.get(key); ok {
+			return newTokenCountResponse(req, inputTokens, true, true), nil
+		}
+	}
+
+	countReq := *req
+	countReq.Fallbacks = nil
+	result, bifrostErr := bifrost.handleRequest(ctx, &countReq, schemas.TokenCountRequest)
+	if bifrostErr != nil {
+		if bifrostErr.Type == nil || *bifrostErr.Type != schemas.UnsupportedOperation {
+			return nil, bifrostErr
+		}
+		response := newTokenCountResponse(req, bifrost.approximateTokenCount(req), false, false)
+		response.ExtraFields.Warnings = []schemas.Warning{{
+			Code:    schemas.WarningEstimated,
+			Message: fmt.Sprintf("%s cannot count tokens, the count is approximated", req.Provider),
+			Origin:  schemas.WarningOriginBifrost,
+		}}
+		return response, nil
+	}
+	if result == nil || result.TokenCount == nil {
+		return nil, newBifrostErrorFromMsg("token count response without a count", schemas.ErrorOriginProvider)
+	}
+
+	if key != "" && result.TokenCount.Exact {
+		bifrost.tokenCounts.add(key, result.TokenCount.InputTokens)
+	}
+	return result, nil
+}
+
+// approximateTokenCount estimates the input tokens of a chat request.
+func (bifrost *Bifrost) approximateTokenCount(req *schemas.BifrostRequest) int {
+	counter := bifrost.autoMaxTokens
+	if counter == nil {
+		counter = &autoMaxTokens{countTokens: chunker.ApproximateTokenCount}
+	}
+	var tools *[]schemas.Tool
+	if req.Params != nil {
+		tools = req.Params.Tools
+	}
+	return counter.promptTokens(req.Input, tools)
+}
+
+// newTokenCountResponse builds the response of a token count that was not returned by a provider.
+func newTokenCountResponse(req *schemas.BifrostRequest, inputTokens int, exact bool, cached bool) *schemas.BifrostResponse {
+	return &schemas.BifrostResponse{
+		Object: "token_count",
+		Model:  req.Model,
+		TokenCount: &schemas.BifrostTokenCount{
+			InputTokens: inputTokens,
+			Exact:       exact,
+			Cached:      cached,
+		},
+		ExtraFields: schemas.BifrostResponseExtraFields{
+			Provider: req.Provider,
+		},
+	}
+}
@@ -0,0 +1,265 @@
+package bifrost
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// RegisterTool registers a typed tool with the MCP integration, deriving the parameter
+// JSON schema from T instead of requiring a hand written schemas.Tool. Arguments sent by
+// the model are unmarshaled into T before fn is called.
+//
+// Schema generation follows the struct's json tags. A field is required unless it is a
+// pointer, has the omitempty option or is tagged `jsonschema:"optional"`. Descriptions come
+// from the `description` tag, and the `jsonschema` tag accepts comma separated options:
+// required, optional, enum=a|b|c, minimum=N, maximum=N, minLength=N, maxLength=N, pattern=EXPR, format=NAME.
+//
+// Parameters:
+//   - bifrost: Bifrost instance with MCP configured
+//   - name: Unique tool name
+//   - description: Human-readable tool description
+//   - fn: Function that handles tool execution with typed arguments
+//
+// Returns:
+//   - error: Any schema generation or registration error
+//
+// Example:
+//
+//	type WeatherArgs struct {
+//	    City  string `json:"city" description:"City to get the weather for"`
+//	    Units string `json:"units,omitempty" jsonschema:"enum=celsius|fahrenheit"`
+//	}
+//
+//	err := bifrost.RegisterTool(client, "get_weather", "Get the current weather",
+//	    func(args WeatherArgs) (string, error) {
+//	        return fmt.Sprintf("Sunny in %s", args.City), nil
+//	    })
+func RegisterTool[T any](bifrost *Bifrost, name, description string, fn MCPToolHandler[T]) error {
+	if bifrost == nil || bifrost.mcpManager == nil {
+		return fmt.Errorf("MCP is not configured in this Bifrost instance")
+	}
+	if fn == nil {
+		return fmt.Errorf("tool handler cannot be nil")
+	}
+
+	toolSchema, err := NewToolSchema[T](name, description)
+	if err != nil {
+		return err
+	}
+
+	handler := func(args any) (string, error) {
+		typedArgs, err := decodeToolArguments[T](args)
+		if err != nil {
+			return "", fmt.Errorf("invalid arguments for tool '%s': %w", name, err)
+		}
+		return fn(typedArgs)
+	}
+
+	return bifrost.mcpManager.registerTool(name, description, handler, toolSchema)
+}
+
+// NewToolSchema builds a function tool definition whose parameters are derived from T.
+// T must be a struct (or pointer to a struct) since tool arguments are always a JSON object.
+func NewToolSchema[T any](name, description string) (schemas.Tool, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return schemas.Tool{}, fmt.Errorf("tool arguments type must be a struct, got %s", t.Kind())
+	}
+
+	properties, required := structToolProperties(t, map[reflect.Type]bool{})
+
+	return schemas.Tool{
+		Type: "function",
+		Function: schemas.Function{
+			Name:        name,
+			Description: description,
+			Parameters: schemas.FunctionParameters{
+				Type:       "object",
+				Required:   required,
+				Properties: properties,
+			},
+		},
+	}, nil
+}
+
+// decodeToolArguments converts the raw arguments received from MCP into T.
+func decodeToolArguments[T any](args any) (T, error) {
+	var typedArgs T
+
+	var raw []byte
+	switch v := args.(type) {
+	case nil:
+		return typedArgs, nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return typedArgs, err
+		}
+		raw = encoded
+	}
+
+	if err := json.Unmarshal(raw, &typedArgs); err != nil {
+		return typedArgs, err
+	}
+
+	return typedArgs, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// structToolProperties returns the JSON schema properties and required field names of a struct type.
+// Embedded structs without a json name are flattened, matching encoding/json behaviour.
+func structToolProperties(t reflect.Type, seen map[reflect.Type]bool) (map[string]interface{}, []string) {
+	properties := make(map[string]interface{})
+	var required []string
+
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(jsonTag, ",")
+
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			embedded := fieldType
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				embeddedProps, embeddedRequired := structToolProperties(embedded, seen)
+				for k, v := range embeddedProps {
+					properties[k] = v
+				}
+				required = append(required, embeddedRequired...)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := typeToolSchema(fieldType, seen)
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+
+		isRequired := fieldType.Kind() != reflect.Pointer && !strings.Contains(opts, "omitempty")
+		for _, option := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+			switch key {
+			case "required":
+				isRequired = true
+			case "optional":
+				isRequired = false
+			case "enum":
+				enum := strings.Split(value, "|")
+				values := make([]interface{}, 0, len(enum))
+				for _, e := range enum {
+					values = append(values, parseToolSchemaValue(e, property["type"]))
+				}
+				property["enum"] = values
+			case "minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems":
+				if n, err := strconv.ParseFloat(value, 64); err == nil {
+					property[key] = n
+				}
+			case "pattern", "format":
+				property[key] = value
+			case "default":
+				property["default"] = parseToolSchemaValue(value, property["type"])
+			}
+		}
+
+		properties[name] = property
+		if isRequired {
+			required = append(required, name)
+		}
+	}
+
+	return properties, required
+}
+
+// typeToolSchema returns the JSON schema for a Go type.
+func typeToolSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string by encoding/json
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": typeToolSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeToolSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			// Recursive type, leave it as a free form object
+			return map[string]interface{}{"type": "object"}
+		}
+		properties, required := structToolProperties(t, seen)
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		// interface{} and anything else accepts any JSON value
+		return map[string]interface{}{}
+	}
+}
+
+// parseToolSchemaValue converts a tag value into the JSON type of the property it belongs to.
+func parseToolSchemaValue(value string, schemaType interface{}) interface{} {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
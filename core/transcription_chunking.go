@@ -0,0 +1,264 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/maximhq/bifrost/core/audiosplit"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// TRANSCRIPTION CHUNKING
+// ============================================================================
+
+const (
+	// maxStitchOverlapWords bounds the words compared when removing the text repeated by the
+	// overlap of two chunks.
+	maxStitchOverlapWords = 50
+	// minStitchOverlapWords is the shortest repeated run removed, shorter runs are likely genuine.
+	minStitchOverlapWords = 2
+)
+
+// newTranscriptionChunking fills in the defaults of the transcription chunking config, nil if config is nil.
+func newTranscriptionChunking(config *schemas.TranscriptionChunkingConfig) *schemas.TranscriptionChunkingConfig {
+	if config == nil {
+		return nil
+	}
+	chunking := *config
+	if chunking.MaxChunkBytes <= 0 {
+		chunking.MaxChunkBytes = schemas.DefaultTranscriptionChunkMaxBytes
+	}
+	if chunking.Overlap <= 0 {
+		chunking.Overlap = schemas.DefaultTranscriptionChunkOverlap
+	}
+	if chunking.Concurrency <= 0 {
+		chunking.Concurrency = schemas.DefaultTranscriptionChunkConcurrency
+	}
+	return &chunking
+}
+
+// splitTranscription returns the chunks of the audio of a transcription request, nil when chunking
+// is not configured, turned off in the context, or the audio fits in a single chunk.
+func (bifrost *Bifrost) splitTranscription(ctx context.Context, req *schemas.BifrostRequest) []audiosplit.Chunk {
+	config := bifrost.audioChunking
+	if config == nil {
+		return nil
+	}
+	if enabled, ok := ctx.Value(schemas.BifrostContextKeyAudioChunking).(bool); ok && !enabled {
+		return nil
+	}
+	audio := req.Input.TranscriptionInput.File
+	if len(audio) <= config.MaxChunkBytes && config.MaxChunkDuration <= 0 {
+		return nil
+	}
+
+	chunks, err := audiosplit.Split(audio, audiosplit.Config{
+		MaxBytes:    config.MaxChunkBytes,
+		MaxDuration: config.MaxChunkDuration,
+		Overlap:     config.Overlap,
+	})
+	if err != nil {
+		// The provider decides whether it accepts the audio as is
+		bifrost.logger.Debug(fmt.Sprintf("transcription audio of %d bytes not split: %v", len(audio), err))
+		return nil
+	}
+	if len(chunks) < 2 {
+		return nil
+	}
+	return chunks
+}
+
+// chunkedTranscription transcribes (or translates, per requestType) the chunks of a long audio in
+// parallel and stitches their transcriptions into one. Every chunk runs through the plugins and
+// fallbacks as a request of its own; the first chunk to fail fails the whole transcription.
+func (bifrost *Bifrost) chunkedTranscription(ctx context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType, chunks []audiosplit.Chunk) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make([]*schemas.BifrostResponse, len(chunks))
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr *schemas.BifrostError
+	)
+	semaphore := make(chan struct{}, bifrost.audioChunking.Concurrency)
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk audiosplit.Chunk) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			if ctx.Err() != nil {
+				return
+			}
+
+			input := *req.Input.TranscriptionInput
+			input.File = chunk.Data
+			chunkReq := *req
+			chunkReq.Input = schemas.RequestInput{TranscriptionInput: &input}
+
+			response, err := bifrost.handleRequest(ctx, &chunkReq, requestType)
+			if err == nil && (response == nil || response.Transcribe == nil) {
+				err = newBifrostErrorFromMsg("transcription response without transcript", schemas.ErrorOriginProvider)
+			}
+			if err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					firstErr.Error.Message = fmt.Sprintf("chunk %d of %d (%s-%s): %s", i+1, len(chunks), chunk.Start.Round(time.Millisecond), chunk.End.Round(time.Millisecond), err.Error.Message)
+					cancel()
+				}
+				errMu.Unlock()
+				return
+			}
+			responses[i] = response
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	response := *responses[0]
+	response.Transcribe = stitchTranscriptions(responses, chunks)
+	response.ExtraFields.AudioChunks = len(chunks)
+	return &response, nil
+}
+
+// stitchTranscriptions merges the transcriptions of overlapping chunks. Segments and words are
+// shifted to the timeline of the original audio, and those in an overlap are kept from the chunk
+// they are most central to. Without timestamps, the words repeated by the overlap are found by
+// comparing the end of the transcript with the start of the next one.
+func stitchTranscriptions(responses []*schemas.BifrostResponse, chunks []audiosplit.Chunk) *schemas.BifrostTranscribe {
+	stitched := &schemas.BifrostTranscribe{}
+	var verbose *schemas.BifrostTranscribeNonStreamResponse
+	hasSegments := true
+	for _, response := range responses {
+		if response.Transcribe.BifrostTranscribeNonStreamResponse == nil || len(response.Transcribe.Segments) == 0 {
+			hasSegments = false
+		}
+	}
+
+	var segmentTexts []string
+	for i, response := range responses {
+		transcribe := response.Transcribe
+		offset := chunks[i].Start.Seconds()
+		// The chunk owns the audio from the middle of its overlap with the previous chunk
+		// to the middle of its overlap with the next one
+		ownStart, ownEnd := 0.0, chunks[len(chunks)-1].End.Seconds()+1
+		if i > 0 {
+			ownStart = (chunks[i].Start.Seconds() + chunks[i-1].End.Seconds()) / 2
+		}
+		if i < len(chunks)-1 {
+			ownEnd = (chunks[i+1].Start.Seconds() + chunks[i].End.Seconds()) / 2
+		}
+		owns := func(start, end float64) bool {
+			middle := offset + (start+end)/2
+			return middle >= ownStart && middle < ownEnd
+		}
+
+		if nonStream := transcribe.BifrostTranscribeNonStreamResponse; nonStream != nil {
+			if verbose == nil {
+				verbose = &schemas.BifrostTranscribeNonStreamResponse{Task: nonStream.Task, Language: nonStream.Language}
+			}
+			for _, segment := range nonStream.Segments {
+				if !owns(segment.Start, segment.End) {
+					continue
+				}
+				segment.ID = len(verbose.Segments)
+				segment.Start += offset
+				segment.End += offset
+				verbose.Segments = append(verbose.Segments, segment)
+				segmentTexts = append(segmentTexts, strings.TrimSpace(segment.Text))
+			}
+			for _, word := range nonStream.Words {
+				if !owns(word.Start, word.End) {
+					continue
+				}
+				word.Start += offset
+				word.End += offset
+				verbose.Words = append(verbose.Words, word)
+			}
+			if nonStream.Duration != nil {
+				verbose.Duration = Ptr(chunks[len(chunks)-1].End.Seconds())
+			}
+		}
+
+		if !hasSegments {
+			stitched.Text = stitchText(stitched.Text, transcribe.Text)
+		}
+		stitched.LogProbs = append(stitched.LogProbs, transcribe.LogProbs...)
+		stitched.Usage = addTranscriptionUsage(stitched.Usage, transcribe.Usage)
+	}
+
+	if hasSegments {
+		stitched.Text = strings.Join(segmentTexts, " ")
+	}
+	stitched.BifrostTranscribeNonStreamResponse = verbose
+	return stitched
+}
+
+// stitchText appends next to text, dropping the longest run of words at the start of next that
+// repeats the end of text.
+func stitchText(text, next string) string {
+	next = strings.TrimSpace(next)
+	if text == "" || next == "" {
+		return text + next
+	}
+
+	textWords, nextWords := strings.Fields(text), strings.Fields(next)
+	for n := min(len(textWords), len(nextWords), maxStitchOverlapWords); n >= minStitchOverlapWords; n-- {
+		if equalWords(textWords[len(textWords)-n:], nextWords[:n]) {
+			nextWords = nextWords[n:]
+			if len(nextWords) == 0 {
+				return text
+			}
+			return text + " " + strings.Join(nextWords, " ")
+		}
+	}
+	return text + " " + next
+}
+
+// equalWords compares words ignoring case and punctuation.
+func equalWords(a, b []string) bool {
+	normalize := func(word string) string {
+		return strings.ToLower(strings.TrimFunc(word, unicode.IsPunct))
+	}
+	for i := range a {
+		if normalize(a[i]) != normalize(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// addTranscriptionUsage sums the usage of two transcriptions.
+func addTranscriptionUsage(total, usage *schemas.TranscriptionUsage) *schemas.TranscriptionUsage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		total = &schemas.TranscriptionUsage{Type: usage.Type}
+	}
+	add := func(sum **int, value *int) {
+		if value == nil {
+			return
+		}
+		if *sum == nil {
+			*sum = Ptr(0)
+		}
+		**sum += *value
+	}
+	add(&total.InputTokens, usage.InputTokens)
+	add(&total.OutputTokens, usage.OutputTokens)
+	add(&total.TotalTokens, usage.TotalTokens)
+	add(&total.Seconds, usage.Seconds)
+	return total
+}
@@ -24,19 +24,20 @@ func attachContextKeys(ctx context.Context, req *schemas.BifrostRequest, request
 // providerRequiresKey returns true if the given provider requires an API key for authentication.
 // Some providers like Ollama and SGL are keyless and don't require API keys.
 func providerRequiresKey(providerKey schemas.ModelProvider) bool {
-	return providerKey != schemas.Ollama && providerKey != schemas.SGL
+	return providerKey != schemas.Ollama && providerKey != schemas.SGL && providerKey != schemas.Template
 }
 
 // canProviderKeyValueBeEmpty returns true if the given provider allows the API key to be empty.
-// Some providers like Vertex and Bedrock have their credentials in additional key configs..
+// Some providers like Vertex and Bedrock have their credentials in additional key configs,
+// and self-hosted NIM containers can run without auth.
 func canProviderKeyValueBeEmpty(providerKey schemas.ModelProvider) bool {
-	return providerKey == schemas.Vertex || providerKey == schemas.Bedrock
+	return providerKey == schemas.Vertex || providerKey == schemas.Bedrock || providerKey == schemas.NIM
 }
 
 // calculateBackoff implements exponential backoff with jitter for retry attempts.
-func calculateBackoff(attempt int, config *schemas.ProviderConfig) time.Duration {
+func calculateBackoff(attempt int, initial, maxBackoff time.Duration) time.Duration {
 	// Calculate an exponential backoff: initial * 2^attempt
-	backoff := min(config.NetworkConfig.RetryBackoffInitial*time.Duration(1<<uint(attempt)), config.NetworkConfig.RetryBackoffMax)
+	backoff := min(initial*time.Duration(1<<uint(attempt)), maxBackoff)
 
 	// Add jitter (±20%)
 	jitter := float64(backoff) * (0.8 + 0.4*rand.Float64())
@@ -46,15 +47,15 @@ func calculateBackoff(attempt int, config *schemas.ProviderConfig) time.Duration
 
 func validateRequest(req *schemas.BifrostRequest) *schemas.BifrostError {
 	if req == nil {
-		return newBifrostErrorFromMsg("bifrost request cannot be nil")
+		return newBifrostErrorFromMsg("bifrost request cannot be nil", schemas.ErrorOriginClientRequest)
 	}
 
 	if req.Provider == "" {
-		return newBifrostErrorFromMsg("provider is required")
+		return newBifrostErrorFromMsg("provider is required", schemas.ErrorOriginClientRequest)
 	}
 
 	if req.Model == "" {
-		return newBifrostErrorFromMsg("model is required")
+		return newBifrostErrorFromMsg("model is required", schemas.ErrorOriginClientRequest)
 	}
 
 	return nil
@@ -62,9 +63,10 @@ func validateRequest(req *schemas.BifrostRequest) *schemas.BifrostError {
 
 // newBifrostError wraps a standard error into a BifrostError with IsBifrostError set to false.
 // This helper function reduces code duplication when handling non-Bifrost errors.
-func newBifrostError(err error) *schemas.BifrostError {
+func newBifrostError(err error, origin schemas.ErrorOrigin) *schemas.BifrostError {
 	return &schemas.BifrostError{
 		IsBifrostError: false,
+		Origin:         origin,
 		Error: schemas.ErrorField{
 			Message: err.Error(),
 			Error:   err,
@@ -74,9 +76,10 @@ func newBifrostError(err error) *schemas.BifrostError {
 
 // newBifrostErrorFromMsg creates a BifrostError with a custom message.
 // This helper function is used for static error messages.
-func newBifrostErrorFromMsg(message string) *schemas.BifrostError {
+func newBifrostErrorFromMsg(message string, origin schemas.ErrorOrigin) *schemas.BifrostError {
 	return &schemas.BifrostError{
 		IsBifrostError: false,
+		Origin:         origin,
 		Error: schemas.ErrorField{
 			Message: message,
 		},
@@ -0,0 +1,373 @@
+package bifrost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// Validate runs a self-test of the configuration and returns a readiness report, e.g. to check a
+// deployment before it accepts traffic. For every provider it checks the configuration of each key
+// and sends one cheap authenticated request (a single token chat completion, or an embedding) per
+// key and model the key serves. Probes call the providers directly: they skip the request queues
+// and plugins, so they are not rate limited, cached, or logged. Plugins implementing
+// schemas.PluginValidator and MCP clients are checked too.
+func (bifrost *Bifrost) Validate(ctx context.Context, opts *schemas.ValidateOptions) *schemas.ReadinessReport {
+	if opts == nil {
+		opts = &schemas.ValidateOptions{}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = schemas.DefaultValidateProbeTimeout
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = schemas.DefaultValidateConcurrency
+	}
+
+	start := time.Now()
+	report := &schemas.ReadinessReport{
+		Ready:     true,
+		CheckedAt: start,
+		Providers: []schemas.ProviderReadiness{},
+		Plugins:   []schemas.ComponentReadiness{},
+		Summary:   make(map[schemas.ReadinessStatus]int),
+	}
+
+	providerKeys, err := bifrost.account.GetConfiguredProviders()
+	if err != nil {
+		report.Providers = append(report.Providers, schemas.ProviderReadiness{
+			Status:  schemas.ReadinessFailed,
+			Message: fmt.Sprintf("failed to list configured providers: %v", err),
+		})
+		report.Add(schemas.ReadinessFailed)
+	}
+
+	// Static checks first, they decide which probes to run
+	var probes []readinessProbe
+	for _, providerKey := range providerKeys {
+		readiness, providerProbes := bifrost.checkProvider(ctx, providerKey, opts)
+		report.Providers = append(report.Providers, readiness)
+		for _, probe := range providerProbes {
+			probe.provider = len(report.Providers) - 1
+			probes = append(probes, probe)
+		}
+	}
+
+	// Probes run concurrently, each writing its own result
+	results := make([]schemas.ReadinessProbe, len(probes))
+	if !opts.SkipProbes {
+		semaphore := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for i, probe := range probes {
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				results[i] = runReadinessProbe(ctx, probe, timeout)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i, probe := range probes {
+			results[i] = probe.result
+			if results[i].Status == "" {
+				results[i].Status = schemas.ReadinessOK
+				results[i].Message = "configuration valid, not probed"
+			}
+		}
+	}
+	for i, probe := range probes {
+		readiness := &report.Providers[probe.provider]
+		readiness.Probes = append(readiness.Probes, results[i])
+		readiness.Status = schemas.WorseReadiness(readiness.Status, results[i].Status)
+	}
+
+	for i := range report.Providers {
+		readiness := &report.Providers[i]
+		if readiness.Status == "" {
+			readiness.Status = schemas.ReadinessSkipped
+		}
+		for _, probe := range readiness.Probes {
+			report.Add(probe.Status)
+		}
+		if len(readiness.Probes) == 0 {
+			report.Add(readiness.Status)
+		}
+	}
+
+	for _, plugin := range bifrost.plugins {
+		report.AddPlugin(checkPlugin(ctx, plugin, timeout))
+	}
+
+	if bifrost.mcpManager != nil {
+		if clients, err := bifrost.GetMCPClients(); err == nil {
+			for _, client := range clients {
+				check := schemas.ComponentReadiness{Name: client.Name, Status: schemas.ReadinessOK}
+				if client.State != schemas.MCPConnectionStateConnected {
+					check.Status = schemas.ReadinessFailed
+					check.Message = fmt.Sprintf("client is %s", client.State)
+				}
+				report.MCPClients = append(report.MCPClients, check)
+				report.Add(check.Status)
+			}
+		}
+	}
+
+	report.DurationMs = time.Since(start).Milliseconds()
+	return report
+}
+
+// readinessProbe is a pending probe of a key serving a model. A result with a status is final and
+// the probe is not sent.
+type readinessProbe struct {
+	provider int // Index of the provider in the report
+	instance schemas.Provider
+	key      schemas.Key
+	model    string
+	result   schemas.ReadinessProbe
+}
+
+// checkProvider runs the static checks of a provider and returns the probes to send for it.
+func (bifrost *Bifrost) checkProvider(ctx context.Context, providerKey schemas.ModelProvider, opts *schemas.ValidateOptions) (schemas.ProviderReadiness, []readinessProbe) {
+	readiness := schemas.ProviderReadiness{Provider: providerKey}
+	failed := func(format string, args ...any) (schemas.ProviderReadiness, []readinessProbe) {
+		readiness.Status = schemas.ReadinessFailed
+		readiness.Message = fmt.Sprintf(format, args...)
+		return readiness, nil
+	}
+
+	config, err := bifrost.account.GetConfigForProvider(providerKey)
+	if err != nil {
+		return failed("failed to get provider config: %v", err)
+	}
+	baseProvider := providerKey
+	if config.CustomProviderConfig != nil && config.CustomProviderConfig.BaseProviderType != "" {
+		baseProvider = config.CustomProviderConfig.BaseProviderType
+	}
+	instance, err := bifrost.createBaseProvider(providerKey, config)
+	if err != nil {
+		return failed("failed to create provider: %v", err)
+	}
+
+	probeModel := opts.ProbeModels[providerKey]
+
+	if !providerRequiresKey(baseProvider) {
+		if probeModel == "" {
+			readiness.Status = schemas.ReadinessSkipped
+			readiness.Message = "no probe model configured"
+			return readiness, nil
+		}
+		return readiness, []readinessProbe{{instance: instance, model: probeModel}}
+	}
+
+	keys, err := bifrost.account.GetKeysForProvider(&ctx, providerKey)
+	if err != nil {
+		return failed("failed to get keys: %v", err)
+	}
+	if len(keys) == 0 {
+		return failed("no keys configured")
+	}
+
+	var probes []readinessProbe
+	for _, key := range keys {
+		keyID := key.ID
+		if keyID == "" {
+			keyID = "(unnamed)"
+		}
+		probe := readinessProbe{instance: instance, key: key, result: schemas.ReadinessProbe{KeyID: keyID}}
+
+		if problem := checkKeyConfig(baseProvider, key); problem != "" {
+			probe.result.Status = schemas.ReadinessFailed
+			probe.result.Message = problem
+			probes = append(probes, probe)
+			continue
+		}
+
+		models := keyModels(baseProvider, key)
+		if len(models) == 0 {
+			if probeModel == "" {
+				probe.result.Status = schemas.ReadinessSkipped
+				probe.result.Message = "key serves every model and no probe model is configured"
+				probes = append(probes, probe)
+				continue
+			}
+			models = []string{probeModel}
+		}
+
+		for _, model := range models {
+			modelProbe := probe
+			modelProbe.model = model
+			modelProbe.result.Model = model
+			if problem := checkKeyRoute(baseProvider, key, model); problem != "" {
+				modelProbe.result.Status = schemas.ReadinessFailed
+				modelProbe.result.Message = problem
+			} else if key.Weight <= 0 {
+				modelProbe.result.Status = schemas.ReadinessWarning
+				modelProbe.result.Message = "key has weight 0 and is never selected while other keys serve the model"
+			}
+			probes = append(probes, modelProbe)
+		}
+	}
+	return readiness, probes
+}
+
+// checkKeyConfig returns the configuration problem of a key, if any.
+func checkKeyConfig(baseProvider schemas.ModelProvider, key schemas.Key) string {
+	if strings.TrimSpace(key.Value) == "" && !canProviderKeyValueBeEmpty(baseProvider) {
+		return "key has no value"
+	}
+	switch baseProvider {
+	case schemas.Azure:
+		if key.AzureKeyConfig == nil || key.AzureKeyConfig.Endpoint == "" {
+			return "azure key config with an endpoint is required"
+		}
+	case schemas.Vertex:
+		if key.VertexKeyConfig == nil || key.VertexKeyConfig.ProjectID == "" || key.VertexKeyConfig.Region == "" {
+			return "vertex key config with a project ID and region is required"
+		}
+	case schemas.Bedrock:
+		if key.BedrockKeyConfig == nil {
+			return "bedrock key config is required"
+		}
+	}
+	return ""
+}
+
+// keyModels returns the models a key serves, or nil if it serves every model.
+func keyModels(baseProvider schemas.ModelProvider, key schemas.Key) []string {
+	if len(key.Models) > 0 {
+		return key.Models
+	}
+	var deployments map[string]string
+	if baseProvider == schemas.Azure && key.AzureKeyConfig != nil {
+		deployments = key.AzureKeyConfig.Deployments
+	} else if baseProvider == schemas.Bedrock && key.BedrockKeyConfig != nil {
+		deployments = key.BedrockKeyConfig.Deployments
+	}
+	models := make([]string, 0, len(deployments))
+	for model := range deployments {
+		models = append(models, model)
+	}
+	slices.Sort(models)
+	return models
+}
+
+// checkKeyRoute returns why a key cannot serve a model, matching the deployment checks of key selection.
+func checkKeyRoute(baseProvider schemas.ModelProvider, key schemas.Key, model string) string {
+	var deployments map[string]string
+	if baseProvider == schemas.Azure && key.AzureKeyConfig != nil {
+		deployments = key.AzureKeyConfig.Deployments
+	} else if baseProvider == schemas.Bedrock && key.BedrockKeyConfig != nil {
+		deployments = key.BedrockKeyConfig.Deployments
+	}
+	if len(deployments) > 0 {
+		if _, ok := deployments[model]; !ok {
+			return fmt.Sprintf("no deployment configured for model %s", model)
+		}
+	}
+	return ""
+}
+
+// runReadinessProbe sends the cheapest request the model supports and classifies the outcome.
+func runReadinessProbe(ctx context.Context, probe readinessProbe, timeout time.Duration) schemas.ReadinessProbe {
+	result := probe.result
+	if result.Status == schemas.ReadinessFailed || result.Status == schemas.ReadinessSkipped {
+		return result
+	}
+	warning := result.Message
+
+	// Image and video generation providers and audio, image, video, moderation and rerank models cannot answer a chat or embedding probe
+	if slices.Contains([]schemas.ModelProvider{schemas.Stability, schemas.BFL, schemas.Luma}, probe.instance.GetProviderKey()) {
+		result.Status = schemas.ReadinessSkipped
+		result.Message = "only chat and embedding models are probed"
+		return result
+	}
+	lowerModel := strings.ToLower(probe.model)
+	for _, kind := range []string{"tts", "whisper", "transcribe", "dall-e", "image", "moderation", "realtime", "audio", "rerank", "veo", "flux"} {
+		if strings.Contains(lowerModel, kind) {
+			result.Status = schemas.ReadinessSkipped
+			result.Message = "only chat and embedding models are probed"
+			return result
+		}
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	prompt := schemas.DefaultValidatePrompt
+	start := time.Now()
+	var bifrostErr *schemas.BifrostError
+	if strings.Contains(lowerModel, "embed") {
+		_, bifrostErr = probe.instance.Embedding(probeCtx, probe.model, probe.key, &schemas.EmbeddingInput{Text: &prompt}, nil)
+	} else {
+		maxTokens := 1
+		messages := []schemas.BifrostMessage{{
+			Role:    schemas.ModelChatMessageRoleUser,
+			Content: schemas.MessageContent{ContentStr: &prompt},
+		}}
+		_, bifrostErr = probe.instance.ChatCompletion(probeCtx, probe.model, probe.key, messages, &schemas.ModelParameters{MaxTokens: &maxTokens})
+	}
+	result.LatencyMs = time.Since(start).Milliseconds()
+
+	if bifrostErr == nil {
+		result.Status = schemas.ReadinessOK
+		if warning != "" {
+			result.Status = schemas.ReadinessWarning
+			result.Message = warning
+		}
+		return result
+	}
+
+	result.StatusCode = bifrostErr.StatusCode
+	message := bifrostErr.Error.Message
+	if bifrostErr.Error.Error != nil && message == "" {
+		message = bifrostErr.Error.Error.Error()
+	}
+	statusCode := 0
+	if bifrostErr.StatusCode != nil {
+		statusCode = *bifrostErr.StatusCode
+	}
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		result.Status = schemas.ReadinessFailed
+		result.Message = "authentication failed: " + message
+	case statusCode == http.StatusNotFound:
+		result.Status = schemas.ReadinessFailed
+		result.Message = "model not available: " + message
+	case statusCode == http.StatusTooManyRequests:
+		result.Status = schemas.ReadinessWarning
+		result.Message = "rate limited: " + message
+	case statusCode >= 400 && statusCode < 500:
+		// Authenticated, but the probe itself was rejected, e.g. a model without chat support
+		result.Status = schemas.ReadinessWarning
+		result.Message = "probe request rejected: " + message
+	default:
+		result.Status = schemas.ReadinessFailed
+		result.Message = message
+	}
+	return result
+}
+
+// checkPlugin runs the self-check of a plugin implementing schemas.PluginValidator.
+func checkPlugin(ctx context.Context, plugin schemas.Plugin, timeout time.Duration) schemas.ComponentReadiness {
+	check := schemas.ComponentReadiness{Name: plugin.GetName(), Status: schemas.ReadinessOK}
+	validator, ok := plugin.(schemas.PluginValidator)
+	if !ok {
+		return check
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := validator.Validate(checkCtx); err != nil {
+		check.Status = schemas.ReadinessFailed
+		check.Message = err.Error()
+	}
+	return check
+}
@@ -0,0 +1,147 @@
+package vecmath
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// Metric selects how vectors are compared in a top-k search.
+type Metric string
+
+const (
+	MetricCosine    Metric = "cosine"    // Higher is more similar
+	MetricDot       Metric = "dot"       // Higher is more similar
+	MetricEuclidean Metric = "euclidean" // Lower is more similar
+)
+
+// Match is a single top-k search result.
+type Match struct {
+	Index int     `json:"index"` // Row index in the matrix
+	Score float64 `json:"score"` // Similarity, or distance for MetricEuclidean
+}
+
+// Matrix is an in-memory set of equally sized vectors. It is not safe for concurrent
+// writes, but concurrent searches without writes are safe.
+type Matrix struct {
+	dimension int
+	rows      [][]float32
+}
+
+// NewMatrix creates a matrix from the given vectors, which must all have the same dimension.
+func NewMatrix(vectors [][]float32) (*Matrix, error) {
+	m := &Matrix{}
+	for _, vector := range vectors {
+		if err := m.Add(vector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// NewMatrixFromResponse creates a matrix from the embeddings of a Bifrost embedding response.
+func NewMatrixFromResponse(response *schemas.BifrostResponse) (*Matrix, error) {
+	vectors, err := FromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	return NewMatrix(vectors)
+}
+
+// Add appends a vector to the matrix and returns an error if its dimension does not match.
+func (m *Matrix) Add(vector []float32) error {
+	if len(vector) == 0 {
+		return errors.New("cannot add an empty vector")
+	}
+	if m.dimension == 0 {
+		m.dimension = len(vector)
+	} else if len(vector) != m.dimension {
+		return fmt.Errorf("%w: matrix has %d, vector has %d", ErrDimensionMismatch, m.dimension, len(vector))
+	}
+	m.rows = append(m.rows, vector)
+	return nil
+}
+
+// Len returns the number of vectors in the matrix.
+func (m *Matrix) Len() int {
+	return len(m.rows)
+}
+
+// Dimension returns the dimension of the vectors in the matrix, 0 if it is empty.
+func (m *Matrix) Dimension() int {
+	return m.dimension
+}
+
+// Row returns the vector at index i.
+func (m *Matrix) Row(i int) []float32 {
+	return m.rows[i]
+}
+
+// TopK returns the k rows most similar to query, best first.
+// Fewer than k matches are returned if the matrix has fewer rows.
+func (m *Matrix) TopK(query []float32, k int, metric Metric) ([]Match, error) {
+	if k <= 0 || len(m.rows) == 0 {
+		return nil, nil
+	}
+	if len(query) != m.dimension {
+		return nil, fmt.Errorf("%w: matrix has %d, query has %d", ErrDimensionMismatch, m.dimension, len(query))
+	}
+
+	var score func(a, b []float32) (float64, error)
+	switch metric {
+	case MetricCosine, "":
+		score = Cosine
+	case MetricDot:
+		score = Dot
+	case MetricEuclidean:
+		// Negate distances so that a higher score is always better while searching
+		score = func(a, b []float32) (float64, error) {
+			d, err := Euclidean(a, b)
+			return -d, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported metric: %s", metric)
+	}
+
+	h := &matchHeap{}
+	for i, row := range m.rows {
+		s, err := score(query, row)
+		if err != nil {
+			return nil, err
+		}
+		if h.Len() < k {
+			heap.Push(h, Match{Index: i, Score: s})
+		} else if s > (*h)[0].Score {
+			(*h)[0] = Match{Index: i, Score: s}
+			heap.Fix(h, 0)
+		}
+	}
+
+	matches := make([]Match, h.Len())
+	for i := len(matches) - 1; i >= 0; i-- {
+		matches[i] = heap.Pop(h).(Match)
+		if metric == MetricEuclidean {
+			matches[i].Score = -matches[i].Score
+		}
+	}
+	return matches, nil
+}
+
+// matchHeap is a min-heap of matches by score, keeping the best k seen so far.
+type matchHeap []Match
+
+func (h matchHeap) Len() int           { return len(h) }
+func (h matchHeap) Less(i, j int) bool { return h[i].Score < h[j].Score }
+func (h matchHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *matchHeap) Push(x interface{}) { *h = append(*h, x.(Match)) }
+
+func (h *matchHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
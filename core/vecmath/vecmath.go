@@ -0,0 +1,146 @@
+// Package vecmath provides small vector math helpers for Bifrost embedding responses,
+// covering similarity metrics, normalization and brute force top-k search over an
+// in-memory matrix, so simple retrieval use cases don't need a vector database.
+package vecmath
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ErrDimensionMismatch is returned when two vectors have different lengths.
+var ErrDimensionMismatch = errors.New("vectors have different dimensions")
+
+// Dot returns the dot product of a and b.
+func Dot(a, b []float32) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("%w: %d and %d", ErrDimensionMismatch, len(a), len(b))
+	}
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum, nil
+}
+
+// Cosine returns the cosine similarity of a and b, in [-1, 1].
+// The similarity involving a zero vector is 0.
+func Cosine(a, b []float32) (float64, error) {
+	dot, err := Dot(a, b)
+	if err != nil {
+		return 0, err
+	}
+	normA, normB := Norm(a), Norm(b)
+	if normA == 0 || normB == 0 {
+		return 0, nil
+	}
+	return dot / (normA * normB), nil
+}
+
+// Euclidean returns the euclidean distance between a and b.
+func Euclidean(a, b []float32) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("%w: %d and %d", ErrDimensionMismatch, len(a), len(b))
+	}
+	var sum float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return math.Sqrt(sum), nil
+}
+
+// Norm returns the L2 norm of v.
+func Norm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
+
+// Normalize scales v to unit L2 norm in place. Zero vectors are left unchanged.
+func Normalize(v []float32) {
+	norm := Norm(v)
+	if norm == 0 {
+		return
+	}
+	for i, x := range v {
+		v[i] = float32(float64(x) / norm)
+	}
+}
+
+// Normalized returns a unit L2 norm copy of v.
+func Normalized(v []float32) []float32 {
+	out := append([]float32(nil), v...)
+	Normalize(out)
+	return out
+}
+
+// FromResponse extracts the embeddings of a Bifrost embedding response, ordered by their
+// index, which is the position of their input. Entries with the same index, e.g. from
+// providers that do not set it, keep their response order. Base64 encoded embeddings are
+// decoded as little-endian float32 arrays and 2D embeddings are flattened into one vector
+// per row.
+func FromResponse(response *schemas.BifrostResponse) ([][]float32, error) {
+	if response == nil {
+		return nil, errors.New("response is nil")
+	}
+
+	ordered := slices.Clone(response.Data)
+	slices.SortStableFunc(ordered, func(a, b schemas.BifrostEmbedding) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+
+	vectors := make([][]float32, 0, len(ordered))
+	for _, data := range ordered {
+		switch {
+		case data.Embedding.EmbeddingArray != nil:
+			vectors = append(vectors, *data.Embedding.EmbeddingArray)
+		case data.Embedding.Embedding2DArray != nil:
+			vectors = append(vectors, *data.Embedding.Embedding2DArray...)
+		case data.Embedding.EmbeddingStr != nil:
+			vector, err := DecodeBase64(*data.Embedding.EmbeddingStr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode embedding at index %d: %w", data.Index, err)
+			}
+			vectors = append(vectors, vector)
+		default:
+			return nil, fmt.Errorf("embedding at index %d is empty", data.Index)
+		}
+	}
+	return vectors, nil
+}
+
+// DecodeBase64 decodes a base64 encoded little-endian float32 array, the format
+// returned by OpenAI compatible APIs when encoding_format is "base64".
+func DecodeBase64(encoded string) ([]float32, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("decoded length %d is not a multiple of 4", len(data))
+	}
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return vector, nil
+}
+
+// EncodeBase64 is the inverse of DecodeBase64.
+func EncodeBase64(vector []float32) string {
+	data := make([]byte, len(vector)*4)
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
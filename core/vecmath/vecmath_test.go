@@ -0,0 +1,174 @@
+package vecmath
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name          string
+		a, b          []float32
+		wantDot       float64
+		wantCosine    float64
+		wantEuclidean float64
+		wantErr       error
+	}{
+		{name: "identical", a: []float32{1, 2, 2}, b: []float32{1, 2, 2}, wantDot: 9, wantCosine: 1, wantEuclidean: 0},
+		{name: "orthogonal", a: []float32{1, 0}, b: []float32{0, 3}, wantDot: 0, wantCosine: 0, wantEuclidean: math.Sqrt(10)},
+		{name: "opposite", a: []float32{1, 1}, b: []float32{-2, -2}, wantDot: -4, wantCosine: -1, wantEuclidean: math.Sqrt(18)},
+		{name: "zero norm", a: []float32{0, 0}, b: []float32{1, 2}, wantDot: 0, wantCosine: 0, wantEuclidean: math.Sqrt(5)},
+		{name: "both zero", a: []float32{0, 0}, b: []float32{0, 0}, wantDot: 0, wantCosine: 0, wantEuclidean: 0},
+		{name: "dimension mismatch", a: []float32{1, 2}, b: []float32{1, 2, 3}, wantErr: ErrDimensionMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dot, dotErr := Dot(tt.a, tt.b)
+			cosine, cosineErr := Cosine(tt.a, tt.b)
+			euclidean, euclideanErr := Euclidean(tt.a, tt.b)
+			if tt.wantErr != nil {
+				for _, err := range []error{dotErr, cosineErr, euclideanErr} {
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("error = %v, want %v", err, tt.wantErr)
+					}
+				}
+				return
+			}
+			if dotErr != nil || cosineErr != nil || euclideanErr != nil {
+				t.Fatalf("unexpected errors: %v, %v, %v", dotErr, cosineErr, euclideanErr)
+			}
+			if !almostEqual(dot, tt.wantDot) || !almostEqual(cosine, tt.wantCosine) || !almostEqual(euclidean, tt.wantEuclidean) {
+				t.Errorf("dot, cosine, euclidean = %v, %v, %v, want %v, %v, %v", dot, cosine, euclidean, tt.wantDot, tt.wantCosine, tt.wantEuclidean)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	v := []float32{3, 4}
+	normalized := Normalized(v)
+	if !reflect.DeepEqual(v, []float32{3, 4}) {
+		t.Errorf("Normalized() modified its input: %v", v)
+	}
+	if !almostEqual(Norm(normalized), 1) || !almostEqual(float64(normalized[0]), 0.6) {
+		t.Errorf("Normalized() = %v, want [0.6 0.8]", normalized)
+	}
+
+	zero := []float32{0, 0}
+	Normalize(zero)
+	if !reflect.DeepEqual(zero, []float32{0, 0}) {
+		t.Errorf("Normalize() of a zero vector = %v, want it unchanged", zero)
+	}
+}
+
+func TestBase64RoundTrip(t *testing.T) {
+	vector := []float32{0.5, -1.25, 3}
+	decoded, err := DecodeBase64(EncodeBase64(vector))
+	if err != nil {
+		t.Fatalf("DecodeBase64() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, vector) {
+		t.Errorf("DecodeBase64(EncodeBase64(v)) = %v, want %v", decoded, vector)
+	}
+	if _, err := DecodeBase64("AAA="); err == nil {
+		t.Error("DecodeBase64() of 2 bytes succeeded, want a length error")
+	}
+}
+
+func TestFromResponse(t *testing.T) {
+	embedding := func(index int, vector ...float32) schemas.BifrostEmbedding {
+		return schemas.BifrostEmbedding{Index: index, Embedding: schemas.BifrostEmbeddingResponse{EmbeddingArray: &vector}}
+	}
+
+	encoded := EncodeBase64([]float32{4, 5})
+
+	tests := []struct {
+		name    string
+		data    []schemas.BifrostEmbedding
+		want    [][]float32
+		wantErr bool
+	}{
+		{
+			name: "ordered by index",
+			data: []schemas.BifrostEmbedding{embedding(2, 3), embedding(0, 1), embedding(1, 2)},
+			want: [][]float32{{1}, {2}, {3}},
+		},
+		{
+			name: "unset indices keep response order",
+			data: []schemas.BifrostEmbedding{embedding(0, 3), embedding(0, 1), embedding(0, 2)},
+			want: [][]float32{{3}, {1}, {2}},
+		},
+		{
+			name: "base64 and 2D embeddings",
+			data: []schemas.BifrostEmbedding{
+				{Index: 1, Embedding: schemas.BifrostEmbeddingResponse{EmbeddingStr: &encoded}},
+				{Index: 0, Embedding: schemas.BifrostEmbeddingResponse{Embedding2DArray: &[][]float32{{1, 2}, {2, 3}}}},
+			},
+			want: [][]float32{{1, 2}, {2, 3}, {4, 5}},
+		},
+		{name: "empty embedding", data: []schemas.BifrostEmbedding{{Index: 0}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &schemas.BifrostResponse{Data: tt.data}
+			got, err := FromResponse(response)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromResponse() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromResponse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := FromResponse(nil); err == nil {
+		t.Error("FromResponse(nil) succeeded, want an error")
+	}
+}
+
+func TestMatrixTopK(t *testing.T) {
+	m, err := NewMatrix([][]float32{{1, 0}, {0, 1}, {1, 1}, {-1, 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add([]float32{1, 2, 3}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("Add() of a 3 dimensional vector error = %v, want %v", err, ErrDimensionMismatch)
+	}
+
+	tests := []struct {
+		metric    Metric
+		k         int
+		wantIndex []int
+	}{
+		{metric: MetricCosine, k: 2, wantIndex: []int{0, 2}},
+		{metric: MetricDot, k: 1, wantIndex: []int{2}},
+		{metric: MetricEuclidean, k: 2, wantIndex: []int{0, 2}},
+		{metric: MetricCosine, k: 10, wantIndex: []int{0, 2, 1, 3}},
+		{metric: MetricCosine, k: 0},
+	}
+	for _, tt := range tests {
+		matches, err := m.TopK([]float32{2, 0.5}, tt.k, tt.metric)
+		if err != nil {
+			t.Fatalf("TopK(%s, %d) error = %v", tt.metric, tt.k, err)
+		}
+		var indices []int
+		for _, match := range matches {
+			indices = append(indices, match.Index)
+		}
+		if !reflect.DeepEqual(indices, tt.wantIndex) {
+			t.Errorf("TopK(%s, %d) = %v, want %v", tt.metric, tt.k, indices, tt.wantIndex)
+		}
+	}
+
+	if _, err := m.TopK([]float32{1}, 1, MetricCosine); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("TopK() with a 1 dimensional query error = %v, want %v", err, ErrDimensionMismatch)
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
@@ -0,0 +1,237 @@
+package bifrost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// VIDEO GENERATION JOBS
+// ============================================================================
+
+const (
+	// videoJobTTL is how long the key a video job was submitted with is remembered.
+	videoJobTTL = 24 * time.Hour
+	// videoPollInterval is the delay between two polls of a job with a webhook.
+	videoPollInterval = 10 * time.Second
+	// videoWatchTimeout bounds the time a job with a webhook is polled for.
+	videoWatchTimeout = time.Hour
+	// videoMaxPollErrors is the number of consecutive failed polls after which a job is given up.
+	videoMaxPollErrors = 3
+	// videoWebhookAttempts is the number of times a webhook delivery is attempted.
+	videoWebhookAttempts = 3
+)
+
+// videoJob is a submitted video generation job and the key it was submitted with.
+type videoJob struct {
+	keyID     string
+	submitted time.Time
+}
+
+// videoJobStore remembers the key of recently submitted video jobs, so polls use the key that
+// owns the job when a provider has several.
+type videoJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]videoJob // Keyed by provider and job ID
+}
+
+func newVideoJobStore() *videoJobStore {
+	return &videoJobStore{jobs: make(map[string]videoJob)}
+}
+
+// add records the key of a job and forgets expired jobs.
+func (s *videoJobStore) add(provider schemas.ModelProvider, jobID string, keyID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, job := range s.jobs {
+		if now.Sub(job.submitted) > videoJobTTL {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobs[string(provider)+"/"+jobID] = videoJob{keyID: keyID, submitted: now}
+}
+
+// keyID returns the ID of the key a job was submitted with, empty if unknown.
+func (s *videoJobStore) keyID(provider schemas.ModelProvider, jobID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[string(provider)+"/"+jobID].keyID
+}
+
+// VideoGenerationRequest submits a video generation job to the specified provider and returns
+// it queued or processing, without waiting for the video. Poll the job with VideoStatusRequest,
+// or set VideoInput.WebhookURL to have Bifrost poll it and post the outcome to the webhook.
+func (bifrost *Bifrost) VideoGenerationRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if req.Input.VideoInput == nil || (req.Input.VideoInput.Prompt == "" && req.Input.VideoInput.ImageURL == nil) {
+		return nil, newBifrostErrorFromMsg("prompt or image not provided for video generation request", schemas.ErrorOriginClientRequest)
+	}
+	if webhookURL := req.Input.VideoInput.WebhookURL; webhookURL != nil && *webhookURL != "" {
+		if parsed, err := url.Parse(*webhookURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, newBifrostErrorFromMsg("webhook url of video generation request must be an http or https url", schemas.ErrorOriginClientRequest)
+		}
+	}
+	if ctx == nil {
+		ctx = bifrost.ctx
+	}
+
+	// Learn the key the job is submitted with. Requests of a session use the selection of the
+	// session instead, so their jobs are polled with whichever key is selected
+	selection := &affinityKeySelection{}
+	ctx = context.WithValue(ctx, affinityContextKey{}, selection)
+
+	result, bifrostErr := bifrost.handleRequest(ctx, req, schemas.VideoGenerationRequest)
+	if bifrostErr != nil {
+		return nil, bifrostErr
+	}
+	if result == nil || result.Video == nil || result.Video.JobID == "" {
+		return nil, newBifrostErrorFromMsg("video generation response without a job", schemas.ErrorOriginProvider)
+	}
+
+	provider := result.ExtraFields.Provider
+	model := servedModel(req, provider)
+	bifrost.videoJobs.add(provider, result.Video.JobID, selection.selected(provider))
+
+	if webhookURL := req.Input.VideoInput.WebhookURL; webhookURL != nil && *webhookURL != "" && !result.Video.Status.Done() {
+		go bifrost.watchVideoJob(provider, model, result.Video.JobID, *webhookURL)
+	}
+	return result, nil
+}
+
+// VideoStatusRequest returns the current state of a video generation job. The request must
+// name the provider and model the job was submitted to; fallbacks are never tried.
+func (bifrost *Bifrost) VideoStatusRequest(ctx context.Context, req *schemas.BifrostRequest) (*schemas.BifrostResponse, *schemas.BifrostError) {
+	if req.Input.VideoJobInput == nil || req.Input.VideoJobInput.JobID == "" {
+		return nil, newBifrostErrorFromMsg("job id not provided for video status request", schemas.ErrorOriginClientRequest)
+	}
+	if ctx == nil {
+		ctx = bifrost.ctx
+	}
+
+	// A job only exists at the provider, and for the key, it was submitted to
+	statusReq := *req
+	statusReq.Fallbacks = nil
+	if keyID := bifrost.videoJobs.keyID(req.Provider, req.Input.VideoJobInput.JobID); keyID != "" {
+		if _, ok := ctx.Value(schemas.BifrostContextKeyDirectKey).(schemas.Key); !ok {
+			if key, ok := bifrost.findKey(ctx, req.Provider, keyID); ok {
+				ctx = context.WithValue(ctx, schemas.BifrostContextKeyDirectKey, key)
+			}
+		}
+	}
+
+	return bifrost.handleRequest(ctx, &statusReq, schemas.VideoStatusRequest)
+}
+
+// findKey returns the key of a provider with the given ID.
+func (bifrost *Bifrost) findKey(ctx context.Context, provider schemas.ModelProvider, keyID string) (schemas.Key, bool) {
+	keys, err := bifrost.account.GetKeysForProvider(&ctx, provider)
+	if err != nil {
+		return schemas.Key{}, false
+	}
+	for _, key := range keys {
+		if key.ID == keyID {
+			return key, true
+		}
+	}
+	return schemas.Key{}, false
+}
+
+// servedModel returns the model of the request or of the fallback that the provider served.
+func servedModel(req *schemas.BifrostRequest, provider schemas.ModelProvider) string {
+	if provider == req.Provider {
+		return req.Model
+	}
+	for _, fallback := range req.Fallbacks {
+		if fallback.Provider == provider {
+			return fallback.Model
+		}
+	}
+	return req.Model
+}
+
+// watchVideoJob polls a job until it completes or fails and posts the outcome to the webhook.
+// The job is given up, and the error posted, after videoMaxPollErrors consecutive failed polls
+// or once videoWatchTimeout has passed.
+func (bifrost *Bifrost) watchVideoJob(provider schemas.ModelProvider, model string, jobID string, webhookURL string) {
+	ctx, cancel := context.WithTimeout(bifrost.ctx, videoWatchTimeout)
+	defer cancel()
+
+	event := &schemas.VideoWebhookEvent{JobID: jobID, Provider: provider, Model: model}
+	failedPolls := 0
+	for {
+		timer := time.NewTimer(videoPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if bifrost.ctx.Err() != nil {
+				// The context Bifrost was initialized with ended
+				return
+			}
+			event.Error = newBifrostErrorFromMsg(fmt.Sprintf("video job %s did not finish within %s", jobID, videoWatchTimeout), schemas.ErrorOriginProvider)
+			bifrost.deliverVideoWebhook(webhookURL, event)
+			return
+		case <-timer.C:
+		}
+
+		result, bifrostErr := bifrost.VideoStatusRequest(ctx, &schemas.BifrostRequest{
+			Provider: provider,
+			Model:    model,
+			Input: schemas.RequestInput{
+				VideoJobInput: &schemas.VideoJobInput{JobID: jobID},
+			},
+		})
+		if bifrostErr != nil {
+			failedPolls++
+			bifrost.logger.Debug(fmt.Sprintf("polling video job %s failed (%d/%d): %s", jobID, failedPolls, videoMaxPollErrors, bifrostErr.Error.Message))
+			if failedPolls >= videoMaxPollErrors {
+				event.Error = bifrostErr
+				bifrost.deliverVideoWebhook(webhookURL, event)
+				return
+			}
+			continue
+		}
+		failedPolls = 0
+
+		if result.Video != nil && result.Video.Status.Done() {
+			event.Response = result
+			bifrost.deliverVideoWebhook(webhookURL, event)
+			return
+		}
+	}
+}
+
+// deliverVideoWebhook posts an event to a webhook, retrying with backoff until it answers 2xx.
+func (bifrost *Bifrost) deliverVideoWebhook(webhookURL string, event *schemas.VideoWebhookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		bifrost.logger.Warn(fmt.Sprintf("failed to marshal video webhook event for job %s: %v", event.JobID, err))
+		return
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	for attempt := 0; attempt < videoWebhookAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<attempt) * time.Second)
+		}
+		resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			bifrost.logger.Debug(fmt.Sprintf("video webhook for job %s failed: %v", event.JobID, err))
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return
+		}
+		bifrost.logger.Debug(fmt.Sprintf("video webhook for job %s answered %d", event.JobID, resp.StatusCode))
+	}
+	bifrost.logger.Warn(fmt.Sprintf("giving up on the video webhook for job %s after %d attempts", event.JobID, videoWebhookAttempts))
+}
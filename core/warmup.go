@@ -0,0 +1,218 @@
+package bifrost
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// PROVIDER WARM-UP
+// ============================================================================
+
+// providerWarmUp warms up providers at startup and keeps idle providers warm.
+type providerWarmUp struct {
+	config schemas.WarmUpConfig
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// newProviderWarmUp creates the warm-up layer with the defaults filled in, nil if config is nil.
+func newProviderWarmUp(config *schemas.WarmUpConfig) *providerWarmUp {
+	if config == nil {
+		return nil
+	}
+	warmUp := &providerWarmUp{
+		config: *config,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if warmUp.config.IdleInterval == 0 {
+		warmUp.config.IdleInterval = schemas.DefaultWarmUpIdleInterval
+	}
+	if warmUp.config.Timeout <= 0 {
+		warmUp.config.Timeout = schemas.DefaultWarmUpTimeout
+	}
+	return warmUp
+}
+
+// run warms up the providers, then warms up again the ones that stay idle until stopped.
+func (w *providerWarmUp) run(bifrost *Bifrost) {
+	defer close(w.done)
+
+	for _, result := range bifrost.WarmUp(bifrost.ctx, &w.config) {
+		if result.Error != "" {
+			bifrost.logger.Warn("warm-up of provider %s failed after %dms: %s", result.Provider, result.LatencyMs, result.Error)
+			continue
+		}
+		bifrost.logger.Info("warmed up provider %s in %dms", result.Provider, result.LatencyMs)
+	}
+
+	if w.config.IdleInterval < 0 {
+		return
+	}
+	ticker := time.NewTicker(w.config.IdleInterval)
+	defer ticker.Stop()
+	idleConfig := w.config
+	idleConfig.ProbeModels = nil // Probes are only sent at startup
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-bifrost.ctx.Done():
+			return
+		case <-ticker.C:
+			idleConfig.Providers = w.idleProviders(bifrost)
+			if len(idleConfig.Providers) == 0 {
+				continue
+			}
+			for _, result := range bifrost.WarmUp(bifrost.ctx, &idleConfig) {
+				if result.Error != "" {
+					bifrost.logger.Debug("warm-up of idle provider %s failed: %s", result.Provider, result.Error)
+				}
+			}
+		}
+	}
+}
+
+// idleProviders returns the providers to warm up that have no request in flight and served none
+// for the idle interval.
+func (w *providerWarmUp) idleProviders(bifrost *Bifrost) []schemas.ModelProvider {
+	var idle []schemas.ModelProvider
+	for _, providerKey := range bifrost.warmUpProviders(w.config.Providers) {
+		activity := bifrost.getProviderActivity(providerKey)
+		if activity.inFlight.Load() == 0 && time.Since(time.Unix(0, activity.lastUsed.Load())) >= w.config.IdleInterval {
+			idle = append(idle, providerKey)
+		}
+	}
+	return idle
+}
+
+// stopAndWait stops warming up idle providers. It is a no-op if warm-up is not configured.
+func (w *providerWarmUp) stopAndWait() {
+	if w == nil {
+		return
+	}
+	close(w.stop)
+	<-w.done
+}
+
+// WarmUp opens connections to the APIs of providers ahead of their first request, and sends the
+// probe requests of opts, all providers in parallel. Providers that do not implement
+// schemas.ProviderWarmer are reported as failed. A nil opts warms up every provider without
+// probes.
+func (bifrost *Bifrost) WarmUp(ctx context.Context, opts *schemas.WarmUpConfig) []schemas.WarmUpResult {
+	if opts == nil {
+		opts = &schemas.WarmUpConfig{}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = schemas.DefaultWarmUpTimeout
+	}
+
+	providerKeys := bifrost.warmUpProviders(opts.Providers)
+	results := make([]schemas.WarmUpResult, len(providerKeys))
+	var wg sync.WaitGroup
+	for i, providerKey := range providerKeys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = bifrost.warmUpProvider(ctx, providerKey, opts.ProbeModels[providerKey], timeout)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// warmUpProviders returns the running providers among the given ones, every running provider if
+// none are given.
+func (bifrost *Bifrost) warmUpProviders(providerKeys []schemas.ModelProvider) []schemas.ModelProvider {
+	var running []schemas.ModelProvider
+	bifrost.providers.Range(func(key, _ any) bool {
+		providerKey := key.(schemas.ModelProvider)
+		if len(providerKeys) == 0 || slices.Contains(providerKeys, providerKey) {
+			running = append(running, providerKey)
+		}
+		return true
+	})
+	slices.Sort(running)
+	return running
+}
+
+// warmUpProvider opens the connections of a provider with each key whose endpoint differs, then
+// sends the probe request if a probe model is given.
+func (bifrost *Bifrost) warmUpProvider(ctx context.Context, providerKey schemas.ModelProvider, probeModel string, timeout time.Duration) schemas.WarmUpResult {
+	start := time.Now()
+	result := schemas.WarmUpResult{Provider: providerKey, ProbeModel: probeModel}
+	finish := func(err string) schemas.WarmUpResult {
+		result.Error = err
+		result.LatencyMs = time.Since(start).Milliseconds()
+		return result
+	}
+
+	value, ok := bifrost.providers.Load(providerKey)
+	if !ok {
+		return finish("provider is not running")
+	}
+	instance := value.(schemas.Provider)
+	warmer, ok := instance.(schemas.ProviderWarmer)
+	if !ok {
+		return finish("provider does not support warm-up")
+	}
+
+	config, err := bifrost.account.GetConfigForProvider(providerKey)
+	if err != nil {
+		return finish("failed to get provider config: " + err.Error())
+	}
+	baseProvider := providerKey
+	if config.CustomProviderConfig != nil && config.CustomProviderConfig.BaseProviderType != "" {
+		baseProvider = config.CustomProviderConfig.BaseProviderType
+	}
+
+	warmUpCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	keys := []schemas.Key{{}}
+	if providerRequiresKey(baseProvider) {
+		keys, err = bifrost.account.GetKeysForProvider(&warmUpCtx, providerKey)
+		if err != nil {
+			return finish("failed to get keys: " + err.Error())
+		}
+		if len(keys) == 0 {
+			return finish("no keys configured")
+		}
+		// Only the endpoints of Azure, Vertex and Bedrock depend on the key
+		if baseProvider != schemas.Azure && baseProvider != schemas.Vertex && baseProvider != schemas.Bedrock {
+			keys = keys[:1]
+		}
+	}
+	for _, key := range keys {
+		if err := warmer.WarmUp(warmUpCtx, key); err != nil {
+			return finish("failed to open connections: " + err.Error())
+		}
+	}
+	result.Connected = true
+	bifrost.getProviderActivity(providerKey).lastUsed.Store(time.Now().UnixNano())
+
+	if probeModel == "" {
+		return finish("")
+	}
+	probe := readinessProbe{instance: instance, model: probeModel}
+	if providerRequiresKey(baseProvider) {
+		probe.key, err = bifrost.selectKeyFromProviderForModel(&warmUpCtx, providerKey, probeModel, baseProvider)
+		if err != nil {
+			return finish("failed to select a key for the probe: " + err.Error())
+		}
+	}
+	probeResult := runReadinessProbe(warmUpCtx, probe, timeout)
+	switch probeResult.Status {
+	case schemas.ReadinessOK, schemas.ReadinessWarning:
+		result.Probed = true
+		return finish("")
+	default:
+		return finish("probe failed: " + probeResult.Message)
+	}
+}
@@ -0,0 +1,47 @@
+package bifrost
+
+import (
+	"context"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// ============================================================================
+// RESPONSE WARNINGS
+// ============================================================================
+
+// withWarnings attaches a new warnings collector to the context. The collector of a provider
+// attempt hides the one of its request, so warnings of failed attempts are not returned with the
+// response of a fallback.
+func withWarnings(ctx context.Context) (context.Context, *schemas.Warnings) {
+	warnings := &schemas.Warnings{}
+	return context.WithValue(ctx, schemas.BifrostContextKeyWarnings, warnings), warnings
+}
+
+// addResponseWarnings appends the collected warnings to the response.
+func addResponseWarnings(result *schemas.BifrostResponse, warnings *schemas.Warnings) {
+	if result == nil || warnings == nil {
+		return
+	}
+	result.ExtraFields.Warnings = append(result.ExtraFields.Warnings, warnings.Since(0)...)
+}
+
+// streamWithWarnings sets the collected warnings on the chunks of a stream: the warnings known
+// when a chunk passes are set on it, each warning on a single chunk.
+func streamWithWarnings(stream chan *schemas.BifrostStream, warnings *schemas.Warnings) chan *schemas.BifrostStream {
+	outputStream := make(chan *schemas.BifrostStream, cap(stream))
+	go func() {
+		defer close(outputStream)
+		sent := 0
+		for chunk := range stream {
+			if chunk != nil && chunk.BifrostResponse != nil {
+				if pending := warnings.Since(sent); len(pending) > 0 {
+					chunk.BifrostResponse.ExtraFields.Warnings = append(chunk.BifrostResponse.ExtraFields.Warnings, pending...)
+					sent += len(pending)
+				}
+			}
+			outputStream <- chunk
+		}
+	}()
+	return outputStream
+}
@@ -0,0 +1,428 @@
+// Package wireschema exports the JSON wire format of Bifrost's types as JSON Schema and as
+// OpenAPI components, so clients written in other languages can generate typed SDKs that stay
+// in sync with the Go structs. Schemas are derived by reflection following encoding/json rules:
+// json tags name the properties, embedded structs are flattened, fields that are always encoded
+// are required and pointer, slice and map fields without omitempty may be null. Types with a
+// custom JSON encoding are described by overrides, built in for the schemas package.
+package wireschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	// JSONSchemaDialect is the JSON Schema version of the exported documents.
+	JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	// OpenAPIVersion is the OpenAPI version of the exported documents, the first whose schema
+	// objects are plain JSON Schema 2020-12.
+	OpenAPIVersion = "3.1.0"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// OverrideFunc returns the schema of a type with a custom JSON encoding. It can reference other
+// types through Generator.Ref.
+type OverrideFunc func(g *Generator) map[string]interface{}
+
+// Generator derives schemas from Go types. Configure it with Add, Override, Enum and Required,
+// then export with JSONSchema or OpenAPI.
+type Generator struct {
+	roots     []reflect.Type
+	rootNames map[reflect.Type]string
+	overrides map[reflect.Type]OverrideFunc
+	enums     map[reflect.Type][]interface{}
+	required  map[reflect.Type][]string
+
+	// Set while building
+	refPrefix string
+	defs      map[string]map[string]interface{}
+	names     map[reflect.Type]string
+}
+
+// NewGenerator creates a generator that knows the custom encodings and enums of the schemas package.
+func NewGenerator() *Generator {
+	g := &Generator{
+		rootNames: make(map[reflect.Type]string),
+		overrides: make(map[reflect.Type]OverrideFunc),
+		enums:     make(map[reflect.Type][]interface{}),
+		required:  make(map[reflect.Type][]string),
+	}
+
+	g.Override(schemas.MessageContent{}, func(g *Generator) map[string]interface{} {
+		return oneOf(
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": g.Ref(schemas.ContentBlock{})},
+			map[string]interface{}{"type": "null"},
+		)
+	})
+	g.Override(schemas.ToolChoice{}, func(g *Generator) map[string]interface{} {
+		return oneOf(
+			map[string]interface{}{"type": "string"},
+			g.Ref(schemas.ToolChoiceStruct{}),
+			map[string]interface{}{"type": "null"},
+		)
+	})
+	g.Override(schemas.SpeechVoiceInput{}, func(g *Generator) map[string]interface{} {
+		return oneOf(
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": g.Ref(schemas.VoiceConfig{})},
+			map[string]interface{}{"type": "null"},
+		)
+	})
+	g.Override(schemas.EmbeddingInput{}, func(g *Generator) map[string]interface{} {
+		return oneOf(
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}}},
+		)
+	})
+	g.Override(schemas.BifrostEmbeddingResponse{}, func(g *Generator) map[string]interface{} {
+		return oneOf(
+			map[string]interface{}{"type": "string", "contentEncoding": "base64"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "number"}},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "number"}}},
+		)
+	})
+
+	g.Enum(schemas.ModelChatMessageRole(""),
+		schemas.ModelChatMessageRoleAssistant,
+		schemas.ModelChatMessageRoleUser,
+		schemas.ModelChatMessageRoleSystem,
+		schemas.ModelChatMessageRoleDeveloper,
+		schemas.ModelChatMessageRoleChatbot,
+		schemas.ModelChatMessageRoleTool,
+	)
+	g.Enum(schemas.ToolChoiceType(""),
+		schemas.ToolChoiceTypeNone,
+		schemas.ToolChoiceTypeAuto,
+		schemas.ToolChoiceTypeAny,
+		schemas.ToolChoiceTypeFunction,
+		schemas.ToolChoiceTypeRequired,
+	)
+	g.Enum(schemas.ContentBlockType(""),
+		schemas.ContentBlockTypeText,
+		schemas.ContentBlockTypeImage,
+		schemas.ContentBlockTypeInputAudio,
+	)
+
+	return g
+}
+
+// Add exports the type of value under name, along with every type it references.
+func (g *Generator) Add(name string, value interface{}) {
+	t := indirect(reflect.TypeOf(value))
+	if _, ok := g.rootNames[t]; !ok {
+		g.roots = append(g.roots, t)
+	}
+	g.rootNames[t] = name
+}
+
+// Override sets the schema of the type of value, for types with a custom JSON encoding.
+func (g *Generator) Override(value interface{}, schema OverrideFunc) {
+	g.overrides[indirect(reflect.TypeOf(value))] = schema
+}
+
+// Enum restricts the type of value to the given values.
+func (g *Generator) Enum(value interface{}, values ...interface{}) {
+	g.enums[indirect(reflect.TypeOf(value))] = values
+}
+
+// Required replaces the required properties of the struct type of value. By default every
+// property that is always encoded is required, which describes responses but is too strict
+// for request bodies whose fields are optional on input.
+func (g *Generator) Required(value interface{}, properties ...string) {
+	g.required[indirect(reflect.TypeOf(value))] = properties
+}
+
+// Ref returns a schema referencing the type of value. It is meant for use in OverrideFunc.
+func (g *Generator) Ref(value interface{}) map[string]interface{} {
+	return g.schema(reflect.TypeOf(value))
+}
+
+// JSONSchema returns a JSON Schema document holding every exported type under $defs.
+func (g *Generator) JSONSchema(id string) ([]byte, error) {
+	defs, err := g.build("#/$defs/")
+	if err != nil {
+		return nil, err
+	}
+	document := map[string]interface{}{
+		"$schema": JSONSchemaDialect,
+		"$defs":   defs,
+	}
+	if id != "" {
+		document["$id"] = id
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// OpenAPI returns an OpenAPI document holding every exported type under components/schemas.
+// paths may reference the types as "#/components/schemas/<name>".
+func (g *Generator) OpenAPI(title, version string, paths map[string]interface{}) ([]byte, error) {
+	defs, err := g.build("#/components/schemas/")
+	if err != nil {
+		return nil, err
+	}
+	if paths == nil {
+		paths = map[string]interface{}{}
+	}
+	document := map[string]interface{}{
+		"openapi":           OpenAPIVersion,
+		"jsonSchemaDialect": JSONSchemaDialect,
+		"info":              map[string]interface{}{"title": title, "version": version},
+		"paths":             paths,
+		"components":        map[string]interface{}{"schemas": defs},
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// build derives the definitions of the exported types and the types they reference.
+func (g *Generator) build(refPrefix string) (map[string]map[string]interface{}, error) {
+	if len(g.roots) == 0 {
+		return nil, fmt.Errorf("no types added")
+	}
+	g.refPrefix = refPrefix
+	g.defs = make(map[string]map[string]interface{})
+	g.names = make(map[reflect.Type]string)
+	defer func() { g.defs, g.names = nil, nil }()
+
+	// Root names are reserved first so referenced types cannot take them
+	for _, t := range g.roots {
+		g.names[t] = g.rootNames[t]
+	}
+	for _, t := range g.roots {
+		g.schema(t)
+	}
+	for _, t := range g.roots {
+		if _, ok := g.defs[g.names[t]]; !ok {
+			return nil, fmt.Errorf("type %s exported as %s is not a struct and has no override", t, g.names[t])
+		}
+	}
+	return g.defs, nil
+}
+
+// schema returns the schema of a type: a reference for structs and overridden types, whose
+// definitions are added to the document, and an inline schema for everything else.
+func (g *Generator) schema(t reflect.Type) map[string]interface{} {
+	t = indirect(t)
+
+	if override, ok := g.overrides[t]; ok {
+		return g.ref(t, override)
+	}
+	if values, ok := g.enums[t]; ok {
+		schema := g.kindSchema(t)
+		schema["enum"] = values
+		return schema
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	if t.Kind() == reflect.Struct && t.Name() != "" {
+		if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+			// Custom encoding without an override, anything may come out
+			return map[string]interface{}{"description": fmt.Sprintf("%s (custom JSON encoding)", t.Name())}
+		}
+		return g.ref(t, func(g *Generator) map[string]interface{} { return g.structSchema(t) })
+	}
+	return g.kindSchema(t)
+}
+
+// ref adds the definition of a named type once and returns a reference to it.
+func (g *Generator) ref(t reflect.Type, define OverrideFunc) map[string]interface{} {
+	name, ok := g.names[t]
+	if !ok {
+		name = g.defName(t)
+		g.names[t] = name
+	}
+	if _, defined := g.defs[name]; !defined {
+		// Placeholder first, so recursive types reference instead of recursing forever
+		g.defs[name] = map[string]interface{}{}
+		g.defs[name] = define(g)
+	}
+	return map[string]interface{}{"$ref": g.refPrefix + name}
+}
+
+// defName returns the definition name of a type: its Go name, prefixed with its package name
+// if another type already has that name.
+func (g *Generator) defName(t reflect.Type) string {
+	name := sanitizeName(t.Name())
+	taken := func(name string) bool {
+		for other, otherName := range g.names {
+			if otherName == name && other != t {
+				return true
+			}
+		}
+		return false
+	}
+	if !taken(name) {
+		return name
+	}
+	pkg := []rune(path.Base(t.PkgPath()))
+	pkg[0] = unicode.ToUpper(pkg[0])
+	name = sanitizeName(string(pkg)) + name
+	for i := 2; taken(name); i++ {
+		name = fmt.Sprintf("%s%d", strings.TrimRight(name, "0123456789"), i)
+	}
+	return name
+}
+
+// kindSchema returns the schema of a type from its kind.
+func (g *Generator) kindSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string by encoding/json
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		// Anonymous struct
+		return g.structSchema(t)
+	default:
+		// interface{} and anything else accepts any JSON value
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema returns the object schema of a struct type.
+func (g *Generator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := g.structProperties(t, properties, true)
+	if custom, ok := g.required[t]; ok {
+		required = custom
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// structProperties adds the properties of a struct to properties and returns the required ones.
+// Embedded structs without a json name are flattened, matching encoding/json; the properties of
+// an embedded pointer are never required since the pointer may be nil.
+func (g *Generator) structProperties(t reflect.Type, properties map[string]interface{}, canRequire bool) []string {
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(jsonTag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			isPointer := embedded.Kind() == reflect.Pointer
+			if isPointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				required = append(required, g.structProperties(embedded, properties, canRequire && !isPointer)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, exists := properties[name]; exists {
+			// Shallower fields win over embedded ones
+			continue
+		}
+
+		omitEmpty := strings.Contains(opts, "omitempty")
+		property := g.schema(field.Type)
+		if description := field.Tag.Get("description"); description != "" {
+			property = withDescription(property, description)
+		}
+		switch field.Type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			if !omitEmpty {
+				property = nullable(property)
+			}
+		}
+		properties[name] = property
+
+		if canRequire && !omitEmpty && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+	return required
+}
+
+// nullable returns a schema that also accepts null.
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if len(schema) == 0 {
+		return schema
+	}
+	if schemaType, ok := schema["type"].(string); ok {
+		schema["type"] = []string{schemaType, "null"}
+		return schema
+	}
+	return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+}
+
+// withDescription returns schema with a description, wrapping references whose siblings older
+// tools ignore.
+func withDescription(schema map[string]interface{}, description string) map[string]interface{} {
+	if _, ok := schema["$ref"]; ok {
+		return map[string]interface{}{"allOf": []interface{}{schema}, "description": description}
+	}
+	schema["description"] = description
+	return schema
+}
+
+// oneOf returns a schema matching exactly one of the given schemas.
+func oneOf(options ...map[string]interface{}) map[string]interface{} {
+	values := make([]interface{}, len(options))
+	for i, option := range options {
+		values[i] = option
+	}
+	return map[string]interface{}{"oneOf": values}
+}
+
+// indirect returns the type a pointer type points to, following every level.
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// sanitizeName keeps the characters allowed in OpenAPI component names.
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_') {
+			return r
+		}
+		return '_'
+	}, name)
+}
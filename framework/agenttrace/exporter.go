@@ -0,0 +1,179 @@
+package agenttrace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// Default exporter settings.
+const (
+	DefaultExportInterval  = 5 * time.Second
+	DefaultExportBatchSize = 512
+	DefaultMaxQueueSize    = 8192
+
+	exportTimeout = 10 * time.Second
+)
+
+// ExporterConfig configures the OTLP exporter. Durations are Go duration strings such as "5s".
+type ExporterConfig struct {
+	Endpoint    string            `json:"endpoint"`               // OTLP/HTTP traces endpoint, e.g. http://localhost:4318/v1/traces
+	Headers     map[string]string `json:"headers,omitempty"`      // Extra request headers, e.g. authorization
+	ServiceName string            `json:"service_name,omitempty"` // service.name of the spans, DefaultServiceName if empty
+	Interval    string            `json:"interval,omitempty"`     // Longest time spans wait to be exported, DefaultExportInterval if empty
+	BatchSize   int               `json:"batch_size,omitempty"`   // Spans per export request, DefaultExportBatchSize if 0
+	MaxQueue    int               `json:"max_queue,omitempty"`    // Spans waiting for export beyond this are dropped, DefaultMaxQueueSize if 0
+}
+
+// Exporter pushes agent spans to an OTLP/HTTP collector in batches. Its Handle method is a
+// schemas.AgentSpanHandler; it only queues the span, exports run in the background.
+type Exporter struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	interval    time.Duration
+	batchSize   int
+	maxQueue    int
+	client      *http.Client
+	logger      schemas.Logger
+
+	mu      sync.Mutex
+	queue   []schemas.AgentSpan
+	dropped int
+	flush   chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewExporter creates an OTLP exporter and starts its export loop.
+func NewExporter(config ExporterConfig, logger schemas.Logger) (*Exporter, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("agent trace export endpoint is required")
+	}
+
+	e := &Exporter{
+		endpoint:    config.Endpoint,
+		headers:     config.Headers,
+		serviceName: config.ServiceName,
+		interval:    DefaultExportInterval,
+		batchSize:   DefaultExportBatchSize,
+		maxQueue:    DefaultMaxQueueSize,
+		client:      &http.Client{Timeout: exportTimeout},
+		logger:      logger,
+		flush:       make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+	if config.Interval != "" {
+		interval, err := time.ParseDuration(config.Interval)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid agent trace export interval %q", config.Interval)
+		}
+		e.interval = interval
+	}
+	if config.BatchSize > 0 {
+		e.batchSize = config.BatchSize
+	}
+	if config.MaxQueue > 0 {
+		e.maxQueue = config.MaxQueue
+	}
+
+	e.wg.Add(1)
+	go e.run()
+	return e, nil
+}
+
+// Handle queues a span for export, dropping it if the queue is full.
+func (e *Exporter) Handle(span schemas.AgentSpan) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.queue) >= e.maxQueue {
+		e.dropped++
+		return
+	}
+	e.queue = append(e.queue, span)
+	if len(e.queue) >= e.batchSize {
+		select {
+		case e.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run exports queued spans every interval, or sooner once a batch is full, until Close.
+func (e *Exporter) run() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			e.exportAll()
+			return
+		case <-ticker.C:
+		case <-e.flush:
+		}
+		e.exportAll()
+	}
+}
+
+// exportAll exports the queued spans in batches.
+func (e *Exporter) exportAll() {
+	e.mu.Lock()
+	spans := e.queue
+	dropped := e.dropped
+	e.queue = nil
+	e.dropped = 0
+	e.mu.Unlock()
+
+	if dropped > 0 {
+		e.logger.Warn("dropped %d agent spans, the export queue was full", dropped)
+	}
+	for start := 0; start < len(spans); start += e.batchSize {
+		batch := spans[start:min(start+e.batchSize, len(spans))]
+		if err := e.export(batch); err != nil {
+			e.logger.Warn("failed to export %d agent spans: %v", len(batch), err)
+		}
+	}
+}
+
+// export posts a batch of spans to the collector.
+func (e *Exporter) export(spans []schemas.AgentSpan) error {
+	body, err := json.Marshal(ToOTLP(spans, e.serviceName))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned status %d: %s", resp.StatusCode, message)
+	}
+	return nil
+}
+
+// Close exports the queued spans and stops the export loop.
+func (e *Exporter) Close() {
+	close(e.done)
+	e.wg.Wait()
+}
@@ -0,0 +1,221 @@
+// Package agenttrace exports agent step traces recorded by Bifrost to OpenTelemetry, converting
+// agent spans to the OTLP/HTTP JSON format and pushing them to a collector.
+package agenttrace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// DefaultServiceName is the service.name resource attribute of exported spans.
+const DefaultServiceName = "bifrost"
+
+// scopeName is the instrumentation scope of exported spans.
+const scopeName = "github.com/maximhq/bifrost/agenttrace"
+
+// OTLP span kinds and status codes.
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+
+	statusCodeOK    = 1
+	statusCodeError = 2
+)
+
+// genAIAttributes maps span attributes to the OpenTelemetry GenAI semantic conventions. Other
+// attributes are exported under the bifrost. prefix.
+var genAIAttributes = map[string]string{
+	"provider":                "gen_ai.system",
+	"model":                   "gen_ai.request.model",
+	"finish_reason":           "gen_ai.response.finish_reasons",
+	"usage.prompt_tokens":     "gen_ai.usage.input_tokens",
+	"usage.completion_tokens": "gen_ai.usage.output_tokens",
+	"tool_name":               "gen_ai.tool.name",
+	"tool_call_id":            "gen_ai.tool.call.id",
+}
+
+// TracesRequest is an OTLP ExportTraceServiceRequest in its JSON encoding.
+type TracesRequest struct {
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+// ResourceSpans are the spans of a resource.
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+}
+
+// Resource describes the service emitting the spans.
+type Resource struct {
+	Attributes []KeyValue `json:"attributes"`
+}
+
+// ScopeSpans are the spans of an instrumentation scope.
+type ScopeSpans struct {
+	Scope Scope  `json:"scope"`
+	Spans []Span `json:"spans"`
+}
+
+// Scope is an instrumentation scope.
+type Scope struct {
+	Name string `json:"name"`
+}
+
+// Span is an OTLP span.
+type Span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []KeyValue `json:"attributes,omitempty"`
+	Status            Status     `json:"status"`
+}
+
+// Status is the outcome of an OTLP span.
+type Status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// KeyValue is an OTLP attribute.
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// AnyValue is an OTLP attribute value. Integers are encoded as strings, as in the JSON encoding
+// of the protobuf int64 type.
+type AnyValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    *string     `json:"intValue,omitempty"`
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+	ArrayValue  *ArrayValue `json:"arrayValue,omitempty"`
+}
+
+// ArrayValue is an OTLP array attribute value.
+type ArrayValue struct {
+	Values []AnyValue `json:"values"`
+}
+
+// ToOTLP converts agent spans to an OTLP export request. Trace IDs that are not 32 hex
+// characters, such as IDs chosen by clients, are hashed into one and kept in the
+// bifrost.trace_id attribute.
+func ToOTLP(spans []schemas.AgentSpan, serviceName string) TracesRequest {
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+
+	otlpSpans := make([]Span, 0, len(spans))
+	for _, span := range spans {
+		otlpSpans = append(otlpSpans, toOTLPSpan(span))
+	}
+
+	return TracesRequest{
+		ResourceSpans: []ResourceSpans{{
+			Resource: Resource{Attributes: []KeyValue{stringAttribute("service.name", serviceName)}},
+			ScopeSpans: []ScopeSpans{{
+				Scope: Scope{Name: scopeName},
+				Spans: otlpSpans,
+			}},
+		}},
+	}
+}
+
+// FlattenTrace returns the spans of a trace tree in the order they started.
+func FlattenTrace(trace *schemas.AgentTrace) []schemas.AgentSpan {
+	var spans []schemas.AgentSpan
+	var walk func(nodes []*schemas.AgentSpan)
+	walk = func(nodes []*schemas.AgentSpan) {
+		for _, node := range nodes {
+			span := *node
+			span.Children = nil
+			spans = append(spans, span)
+			walk(node.Children)
+		}
+	}
+	walk(trace.Spans)
+	return spans
+}
+
+func toOTLPSpan(span schemas.AgentSpan) Span {
+	result := Span{
+		TraceID:           otlpTraceID(span.TraceID),
+		SpanID:            span.SpanID,
+		ParentSpanID:      span.ParentSpanID,
+		Name:              span.Name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+		Attributes:        []KeyValue{stringAttribute("bifrost.step.kind", string(span.Kind))},
+		Status:            Status{Code: statusCodeOK},
+	}
+	if span.Kind == schemas.AgentStepModelCall || span.Kind == schemas.AgentStepToolCall {
+		result.Kind = spanKindClient
+	}
+	if span.Status == schemas.AgentSpanStatusError {
+		result.Status = Status{Code: statusCodeError, Message: span.Error}
+	}
+	if result.TraceID != span.TraceID {
+		result.Attributes = append(result.Attributes, stringAttribute("bifrost.trace_id", span.TraceID))
+	}
+
+	for key, value := range span.Attributes {
+		name, ok := genAIAttributes[key]
+		if !ok {
+			name = "bifrost." + key
+		}
+		// The finish reasons convention is an array
+		if name == "gen_ai.response.finish_reasons" {
+			value = []string{fmt.Sprint(value)}
+		}
+		result.Attributes = append(result.Attributes, KeyValue{Key: name, Value: toAnyValue(value)})
+	}
+	return result
+}
+
+// otlpTraceID returns the trace ID as 32 hex characters.
+func otlpTraceID(traceID string) string {
+	if decoded, err := hex.DecodeString(traceID); err == nil && len(decoded) == 16 {
+		return traceID
+	}
+	sum := sha256.Sum256([]byte(traceID))
+	return hex.EncodeToString(sum[:16])
+}
+
+func stringAttribute(key, value string) KeyValue {
+	return KeyValue{Key: key, Value: AnyValue{StringValue: &value}}
+}
+
+func toAnyValue(value interface{}) AnyValue {
+	switch v := value.(type) {
+	case string:
+		return AnyValue{StringValue: &v}
+	case bool:
+		return AnyValue{BoolValue: &v}
+	case int:
+		s := strconv.Itoa(v)
+		return AnyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return AnyValue{IntValue: &s}
+	case float64:
+		return AnyValue{DoubleValue: &v}
+	case []string:
+		values := make([]AnyValue, len(v))
+		for i, item := range v {
+			values[i] = toAnyValue(item)
+		}
+		return AnyValue{ArrayValue: &ArrayValue{Values: values}}
+	default:
+		s := fmt.Sprint(v)
+		return AnyValue{StringValue: &s}
+	}
+}
@@ -0,0 +1,119 @@
+package agenttrace
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSpans() []schemas.AgentSpan {
+	start := time.Unix(1700000000, 0)
+	return []schemas.AgentSpan{
+		{
+			TraceID:    "run-42",
+			SpanID:     "00f067aa0ba902b7",
+			Kind:       schemas.AgentStepModelCall,
+			Name:       "chat_completion openai/gpt-4o",
+			Status:     schemas.AgentSpanStatusOK,
+			StartTime:  start,
+			EndTime:    start.Add(time.Second),
+			Attributes: map[string]interface{}{"provider": "openai", "usage.prompt_tokens": 12, "finish_reason": "tool_calls"},
+		},
+		{
+			TraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+			SpanID:       "b7ad6b7169203331",
+			ParentSpanID: "00f067aa0ba902b7",
+			Kind:         schemas.AgentStepToolCall,
+			Name:         "search",
+			Status:       schemas.AgentSpanStatusError,
+			Error:        "tool timed out",
+			StartTime:    start,
+			EndTime:      start.Add(2 * time.Second),
+			Attributes:   map[string]interface{}{"tool_name": "search", "arguments": `{"q":"x"}`},
+		},
+	}
+}
+
+func attribute(span Span, key string) *AnyValue {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return &kv.Value
+		}
+	}
+	return nil
+}
+
+func TestToOTLP(t *testing.T) {
+	request := ToOTLP(testSpans(), "")
+	require.Len(t, request.ResourceSpans, 1)
+	assert.Equal(t, DefaultServiceName, *request.ResourceSpans[0].Resource.Attributes[0].Value.StringValue)
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	modelCall := spans[0]
+	assert.Len(t, modelCall.TraceID, 32)
+	assert.Equal(t, "run-42", *attribute(modelCall, "bifrost.trace_id").StringValue)
+	assert.Equal(t, spanKindClient, modelCall.Kind)
+	assert.Equal(t, "1700000000000000000", modelCall.StartTimeUnixNano)
+	assert.Equal(t, "openai", *attribute(modelCall, "gen_ai.system").StringValue)
+	assert.Equal(t, "12", *attribute(modelCall, "gen_ai.usage.input_tokens").IntValue)
+	assert.Equal(t, "tool_calls", *attribute(modelCall, "gen_ai.response.finish_reasons").ArrayValue.Values[0].StringValue)
+
+	toolCall := spans[1]
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", toolCall.TraceID)
+	assert.Nil(t, attribute(toolCall, "bifrost.trace_id"))
+	assert.Equal(t, "00f067aa0ba902b7", toolCall.ParentSpanID)
+	assert.Equal(t, Status{Code: statusCodeError, Message: "tool timed out"}, toolCall.Status)
+	assert.Equal(t, `{"q":"x"}`, *attribute(toolCall, "bifrost.arguments").StringValue)
+}
+
+func TestExporterPushesBatches(t *testing.T) {
+	received := make(chan TracesRequest, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var request TracesRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		received <- request
+	}))
+	defer server.Close()
+
+	exporter, err := NewExporter(ExporterConfig{
+		Endpoint:  server.URL,
+		Headers:   map[string]string{"Authorization": "Bearer token"},
+		Interval:  "1h",
+		BatchSize: 2,
+	}, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	require.NoError(t, err)
+
+	// A full batch is exported without waiting for the interval
+	for _, span := range testSpans() {
+		exporter.Handle(span)
+	}
+	select {
+	case request := <-received:
+		assert.Len(t, request.ResourceSpans[0].ScopeSpans[0].Spans, 2)
+	case <-time.After(5 * time.Second):
+		t.Fatal("full batch was not exported")
+	}
+
+	// Close exports what is left
+	exporter.Handle(testSpans()[0])
+	exporter.Close()
+	request := <-received
+	assert.Len(t, request.ResourceSpans[0].ScopeSpans[0].Spans, 1)
+}
+
+func TestNewExporterValidatesConfig(t *testing.T) {
+	logger := bifrost.NewDefaultLogger(schemas.LogLevelError)
+	_, err := NewExporter(ExporterConfig{}, logger)
+	assert.Error(t, err)
+	_, err = NewExporter(ExporterConfig{Endpoint: "http://localhost:4318/v1/traces", Interval: "soon"}, logger)
+	assert.Error(t, err)
+}
@@ -0,0 +1,202 @@
+package artifactstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// ServePath is the path under which the HTTP transport serves the artifacts of a filesystem
+// store, followed by the key of the artifact.
+const ServePath = "/v1/artifacts/"
+
+// FilesystemConfig configures a store keeping artifacts in a local directory. Its signed URLs
+// point to the Bifrost server itself, which serves them under ServePath.
+type FilesystemConfig struct {
+	Directory  string `json:"directory"`             // Directory of the artifacts, created if missing - REQUIRED
+	PublicURL  string `json:"public_url"`            // Base URL clients reach Bifrost at, e.g. https://bifrost.example.com - REQUIRED
+	SigningKey string `json:"signing_key,omitempty"` // Secret signing the URLs; if empty a random one is generated and URLs stop working on restart
+}
+
+// FilesystemStore keeps artifacts in a directory, sharded by the first two characters of their
+// key. URLs are signed with an HMAC of the key and their expiry.
+type FilesystemStore struct {
+	directory  string
+	publicURL  string
+	signingKey []byte
+	logger     schemas.Logger
+}
+
+func newFilesystemStore(config FilesystemConfig, logger schemas.Logger) (*FilesystemStore, error) {
+	if config.Directory == "" {
+		return nil, fmt.Errorf("artifact directory is required")
+	}
+	if config.PublicURL == "" {
+		return nil, fmt.Errorf("artifact public url is required")
+	}
+	if err := os.MkdirAll(config.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	signingKey := []byte(config.SigningKey)
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, fmt.Errorf("failed to generate artifact signing key: %w", err)
+		}
+		if logger != nil {
+			logger.Warn("no artifact signing key configured, using a random one: artifact urls stop working on restart and are not shared between replicas")
+		}
+	}
+
+	return &FilesystemStore{
+		directory:  config.Directory,
+		publicURL:  strings.TrimSuffix(config.PublicURL, "/"),
+		signingKey: signingKey,
+		logger:     logger,
+	}, nil
+}
+
+// path returns the file of an artifact.
+func (s *FilesystemStore) path(key string) string {
+	return filepath.Join(s.directory, key[:2], key)
+}
+
+func (s *FilesystemStore) Put(ctx context.Context, data []byte, mimeType string) (*Artifact, error) {
+	if len(data) == 0 {
+		return nil, errEmptyContent
+	}
+	key := ContentKey(data, mimeType)
+	path := s.path(key)
+	now := time.Now()
+
+	// Same key, same content: only restart the TTL of the existing copy
+	if err := os.Chtimes(path, now, now); err == nil {
+		return &Artifact{Key: key, MimeType: mimeType, Size: len(data), StoredAt: now}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	// Write to a temporary file renamed into place, so readers never see a partial artifact
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write artifact: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write artifact: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to store artifact: %w", err)
+	}
+
+	return &Artifact{Key: key, MimeType: mimeType, Size: len(data), StoredAt: now}, nil
+}
+
+func (s *FilesystemStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+	return data, nil
+}
+
+func (s *FilesystemStore) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+	return s.publicURL + ServePath + key + "?" + query.Encode(), nil
+}
+
+// Verify checks the expiry and signature of a URL returned by SignedURL.
+func (s *FilesystemStore) Verify(key, expires, signature string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return ErrInvalidURL
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of a key and its expiry.
+func (s *FilesystemStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *FilesystemStore) Sweep(ctx context.Context, olderThan time.Time) (int, error) {
+	deleted := 0
+	err := filepath.WalkDir(s.directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files removed while walking, e.g. by another replica sharing the directory
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		// Only touch artifacts and temporary files left by interrupted writes
+		name := entry.Name()
+		if !ValidKey(name) && !strings.HasPrefix(name, ".tmp-") {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(olderThan) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if ValidKey(name) {
+			deleted++
+		}
+		return nil
+	})
+	if err != nil {
+		return deleted, fmt.Errorf("failed to sweep artifacts: %w", err)
+	}
+	return deleted, nil
+}
+
+func (s *FilesystemStore) Close() error {
+	return nil
+}
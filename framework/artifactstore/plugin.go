@@ -0,0 +1,308 @@
+package artifactstore
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	PluginName = "artifacts"
+
+	// DefaultMaxDownloadSize bounds the provider hosted images and videos downloaded into the
+	// store, larger ones keep their provider URL.
+	DefaultMaxDownloadSize = 512 << 20
+	// DefaultSweepInterval is the delay between two deletions of expired artifacts.
+	DefaultSweepInterval = time.Hour
+	// downloadTimeout bounds the download of a provider hosted artifact.
+	downloadTimeout = 5 * time.Minute
+)
+
+// PluginConfig configures the artifacts plugin.
+type PluginConfig struct {
+	Store           Config `json:"store"`
+	Speech          bool   `json:"speech,omitempty"`            // Also store speech audio; speech endpoints then answer JSON with the URL instead of the audio
+	MaxDownloadSize int64  `json:"max_download_size,omitempty"` // Bytes, DefaultMaxDownloadSize if 0
+	SweepInterval   string `json:"sweep_interval,omitempty"`    // e.g. "1h", DefaultSweepInterval if empty
+}
+
+// rehostedArtifact is the artifact a provider hosted URL was stored as.
+type rehostedArtifact struct {
+	key      string
+	storedAt time.Time
+}
+
+// Plugin stores the images, videos and, optionally, speech audio of responses in an artifact
+// store and replaces them with signed URLs. Inline content is stored as is; provider hosted
+// URLs, which usually expire within hours, are downloaded and stored too. Responses are left
+// untouched when an artifact cannot be stored. Expired artifacts are deleted in the background.
+type Plugin struct {
+	store           ArtifactStore
+	ttl             time.Duration
+	urlTTL          time.Duration
+	speech          bool
+	maxDownloadSize int64
+	client          *http.Client
+	logger          schemas.Logger
+
+	mu       sync.Mutex
+	rehosted map[string]rehostedArtifact // Keyed by provider URL, so polls of a finished video job download it once
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Init creates the configured store and starts deleting its expired artifacts.
+func Init(ctx context.Context, config PluginConfig, logger schemas.Logger) (*Plugin, error) {
+	ttl, urlTTL, err := config.Store.Durations()
+	if err != nil {
+		return nil, err
+	}
+	sweepInterval, err := parseDuration(config.SweepInterval, DefaultSweepInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact sweep interval: %w", err)
+	}
+	store, err := NewArtifactStore(ctx, &config.Store, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDownloadSize := config.MaxDownloadSize
+	if maxDownloadSize <= 0 {
+		maxDownloadSize = DefaultMaxDownloadSize
+	}
+	sweepCtx, cancel := context.WithCancel(ctx)
+	p := &Plugin{
+		store:           store,
+		ttl:             ttl,
+		urlTTL:          min(urlTTL, ttl),
+		speech:          config.Speech,
+		maxDownloadSize: maxDownloadSize,
+		client:          &http.Client{Timeout: downloadTimeout},
+		logger:          logger,
+		rehosted:        make(map[string]rehostedArtifact),
+		cancel:          cancel,
+		done:            make(chan struct{}),
+	}
+	go p.sweepLoop(sweepCtx, sweepInterval)
+	return p, nil
+}
+
+// GetName returns the name of the plugin.
+func (p *Plugin) GetName() string {
+	return PluginName
+}
+
+// GetStore returns the artifact store of the plugin.
+func (p *Plugin) GetStore() ArtifactStore {
+	return p.store
+}
+
+// PreHook is a no-op, artifacts are only found in responses.
+func (p *Plugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	return req, nil, nil
+}
+
+// PostHook replaces the images, videos and speech audio of responses with signed URLs.
+func (p *Plugin) PostHook(ctx *context.Context, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	if result == nil || bifrostErr != nil {
+		return result, bifrostErr, nil
+	}
+
+	requestType, _ := (*ctx).Value(schemas.BifrostContextKeyRequestType).(schemas.RequestType)
+	switch requestType {
+	case schemas.ImageGenerationRequest:
+		if result.Image != nil {
+			p.storeImages(*ctx, result.Image)
+		}
+	case schemas.VideoGenerationRequest, schemas.VideoStatusRequest:
+		if result.Video != nil && result.Video.Status == schemas.VideoJobCompleted {
+			p.storeVideos(*ctx, result.Video)
+		}
+	case schemas.SpeechRequest:
+		if p.speech && result.Speech != nil && len(result.Speech.Audio) > 0 {
+			p.storeSpeech(*ctx, result.Speech)
+		}
+	}
+	return result, nil, nil
+}
+
+func (p *Plugin) storeImages(ctx context.Context, image *schemas.BifrostImage) {
+	for i := range image.Images {
+		generated := &image.Images[i]
+		switch {
+		case generated.B64JSON != "":
+			data, err := base64.StdEncoding.DecodeString(generated.B64JSON)
+			if err != nil {
+				p.logger.Warn("failed to decode generated image: %v", err)
+				continue
+			}
+			signedURL, err := p.put(ctx, data, generated.MimeType)
+			if err != nil {
+				p.logger.Warn("failed to store generated image: %v", err)
+				continue
+			}
+			generated.URL = signedURL
+			generated.B64JSON = ""
+		case generated.URL != "":
+			if signedURL, ok := p.rehost(ctx, generated.URL, generated.MimeType); ok {
+				generated.URL = signedURL
+			}
+		}
+	}
+}
+
+func (p *Plugin) storeVideos(ctx context.Context, video *schemas.BifrostVideo) {
+	for i := range video.Videos {
+		generated := &video.Videos[i]
+		if signedURL, ok := p.rehost(ctx, generated.URL, generated.MimeType); ok {
+			generated.URL = signedURL
+		}
+		if generated.ThumbnailURL != nil {
+			if signedURL, ok := p.rehost(ctx, *generated.ThumbnailURL, ""); ok {
+				generated.ThumbnailURL = &signedURL
+			}
+		}
+	}
+}
+
+func (p *Plugin) storeSpeech(ctx context.Context, speech *schemas.BifrostSpeech) {
+	mimeType := http.DetectContentType(speech.Audio)
+	if !strings.HasPrefix(mimeType, "audio/") {
+		// Speech defaults to mp3, which is only detected with an ID3 header
+		mimeType = "audio/mpeg"
+	}
+	signedURL, err := p.put(ctx, speech.Audio, mimeType)
+	if err != nil {
+		p.logger.Warn("failed to store speech audio: %v", err)
+		return
+	}
+	speech.URL = signedURL
+	speech.Audio = nil
+}
+
+// OffloadImage stores an image and returns a signed URL to it. It can be used as the
+// ImageOffloader of payload slimming, so images of requests too large to send inline are sent
+// by URL instead. The URL must be reachable by the provider.
+func (p *Plugin) OffloadImage(ctx context.Context, data []byte, mimeType string) (string, error) {
+	return p.put(ctx, data, mimeType)
+}
+
+// put stores data and returns a signed URL to it.
+func (p *Plugin) put(ctx context.Context, data []byte, mimeType string) (string, error) {
+	artifact, err := p.store.Put(ctx, data, mimeType)
+	if err != nil {
+		return "", err
+	}
+	return p.store.SignedURL(ctx, artifact.Key, p.urlTTL)
+}
+
+// rehost downloads a provider hosted artifact into the store and returns a signed URL to it.
+// It returns false, logging why, when the artifact could not be stored.
+func (p *Plugin) rehost(ctx context.Context, sourceURL string, mimeType string) (string, bool) {
+	if !strings.HasPrefix(sourceURL, "https://") && !strings.HasPrefix(sourceURL, "http://") {
+		return "", false
+	}
+
+	p.mu.Lock()
+	existing, ok := p.rehosted[sourceURL]
+	p.mu.Unlock()
+	if ok && time.Since(existing.storedAt) < p.ttl-p.urlTTL {
+		if signedURL, err := p.store.SignedURL(ctx, existing.key, p.urlTTL); err == nil {
+			return signedURL, true
+		}
+	}
+
+	data, contentType, err := p.download(ctx, sourceURL)
+	if err != nil {
+		p.logger.Warn("failed to download artifact for the artifact store: %v", err)
+		return "", false
+	}
+	if mimeType == "" {
+		mimeType = contentType
+	}
+	artifact, err := p.store.Put(ctx, data, mimeType)
+	if err != nil {
+		p.logger.Warn("failed to store artifact: %v", err)
+		return "", false
+	}
+	signedURL, err := p.store.SignedURL(ctx, artifact.Key, p.urlTTL)
+	if err != nil {
+		p.logger.Warn("failed to sign artifact url: %v", err)
+		return "", false
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	for source, rehosted := range p.rehosted {
+		if now.Sub(rehosted.storedAt) > p.ttl {
+			delete(p.rehosted, source)
+		}
+	}
+	p.rehosted[sourceURL] = rehostedArtifact{key: artifact.Key, storedAt: artifact.StoredAt}
+	p.mu.Unlock()
+	return signedURL, true
+}
+
+// download fetches a provider hosted artifact and returns it with its content type.
+func (p *Plugin) download(ctx context.Context, sourceURL string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > p.maxDownloadSize {
+		return nil, "", fmt.Errorf("artifact of %d bytes exceeds the maximum download size of %d bytes", resp.ContentLength, p.maxDownloadSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.maxDownloadSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > p.maxDownloadSize {
+		return nil, "", fmt.Errorf("artifact exceeds the maximum download size of %d bytes", p.maxDownloadSize)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// sweepLoop deletes expired artifacts every interval until ctx is done.
+func (p *Plugin) sweepLoop(ctx context.Context, interval time.Duration) {
+	defer close(p.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := p.store.Sweep(ctx, time.Now().Add(-p.ttl))
+			if err != nil {
+				p.logger.Warn("failed to delete expired artifacts: %v", err)
+			}
+			if deleted > 0 {
+				p.logger.Debug("deleted %d expired artifacts", deleted)
+			}
+		}
+	}
+}
+
+// Cleanup stops deleting expired artifacts and closes the store.
+func (p *Plugin) Cleanup() error {
+	p.cancel()
+	<-p.done
+	return p.store.Close()
+}
@@ -0,0 +1,243 @@
+package artifactstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	// s3RequestTimeout bounds each call to S3.
+	s3RequestTimeout = 5 * time.Minute
+	// s3MaxPresignTTL is the longest validity S3 accepts for a presigned URL.
+	s3MaxPresignTTL = 7 * 24 * time.Hour
+	// emptyPayloadHash is the SHA-256 of an empty body.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3Config configures a store keeping artifacts in an S3 bucket, or in any S3 compatible object
+// storage through Endpoint. Credentials come from the default AWS chain: environment variables,
+// shared config files, or the instance or task role. Signed URLs are S3 presigned URLs.
+type S3Config struct {
+	Bucket   string `json:"bucket"`             // Bucket of the artifacts - REQUIRED
+	Region   string `json:"region"`             // Region of the bucket - REQUIRED
+	Endpoint string `json:"endpoint,omitempty"` // S3 compatible endpoint, addressed path-style; https://<bucket>.s3.<region>.amazonaws.com if empty
+	Prefix   string `json:"prefix,omitempty"`   // Prefix of the object names, e.g. "artifacts/"
+}
+
+// s3Store calls the S3 REST API directly, signing requests with the AWS v4 signer.
+type s3Store struct {
+	bucketURL   string // URL of the bucket, ending with "/"
+	region      string
+	prefix      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+	logger      schemas.Logger
+}
+
+func newS3Store(ctx context.Context, s3Config S3Config, logger schemas.Logger) (*s3Store, error) {
+	if s3Config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if s3Config.Region == "" {
+		return nil, fmt.Errorf("s3 region is required")
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(s3Config.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	bucketURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", s3Config.Bucket, s3Config.Region)
+	if s3Config.Endpoint != "" {
+		bucketURL = strings.TrimSuffix(s3Config.Endpoint, "/") + "/" + s3Config.Bucket + "/"
+	}
+	return &s3Store{
+		bucketURL:   bucketURL,
+		region:      s3Config.Region,
+		prefix:      strings.TrimPrefix(s3Config.Prefix, "/"),
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: s3RequestTimeout},
+		logger:      logger,
+	}, nil
+}
+
+// do signs and sends a request to S3 and returns the response body, or an error for statuses
+// other than 2xx.
+func (s *s3Store) do(ctx context.Context, method, rawURL string, body []byte, header http.Header) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to retrieve aws credentials: %w", err)
+	}
+	if err := s.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", s.region, time.Now()); err != nil {
+		return 0, nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		_ = xml.Unmarshal(data, &apiErr)
+		return resp.StatusCode, data, fmt.Errorf("s3 returned status %d: %s %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+	}
+	return resp.StatusCode, data, nil
+}
+
+// objectURL returns the URL of the object of an artifact.
+func (s *s3Store) objectURL(key string) string {
+	return s.bucketURL + s.prefix + key
+}
+
+// Put uploads the artifact even when it already exists: objects are content addressed, so the
+// upload only restarts the TTL of the existing copy.
+func (s *s3Store) Put(ctx context.Context, data []byte, mimeType string) (*Artifact, error) {
+	if len(data) == 0 {
+		return nil, errEmptyContent
+	}
+	key := ContentKey(data, mimeType)
+
+	header := http.Header{}
+	if mimeType != "" {
+		header.Set("Content-Type", mimeType)
+	}
+	if _, _, err := s.do(ctx, http.MethodPut, s.objectURL(key), data, header); err != nil {
+		return nil, fmt.Errorf("failed to upload artifact: %w", err)
+	}
+	return &Artifact{Key: key, MimeType: mimeType, Size: len(data), StoredAt: time.Now()}, nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+	status, data, err := s.do(ctx, http.MethodGet, s.objectURL(key), nil, nil)
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download artifact: %w", err)
+	}
+	return data, nil
+}
+
+func (s *s3Store) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	ttl = min(ttl, s3MaxPresignTTL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return "", err
+	}
+	query := req.URL.Query()
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	req.URL.RawQuery = query.Encode()
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve aws credentials: %w", err)
+	}
+	signedURL, _, err := s.signer.PresignHTTP(ctx, creds, req, "UNSIGNED-PAYLOAD", "s3", s.region, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to presign artifact url: %w", err)
+	}
+	return signedURL, nil
+}
+
+// s3ListResult is the response of ListObjectsV2.
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// Sweep lists the objects under the prefix and deletes the artifacts last uploaded before
+// olderThan. Objects that are not artifacts are left alone, so the bucket can be shared.
+func (s *s3Store) Sweep(ctx context.Context, olderThan time.Time) (int, error) {
+	deleted := 0
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		if s.prefix != "" {
+			query.Set("prefix", s.prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		_, data, err := s.do(ctx, http.MethodGet, s.bucketURL+"?"+query.Encode(), nil, nil)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list artifacts: %w", err)
+		}
+		var result s3ListResult
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return deleted, fmt.Errorf("failed to parse s3 list response: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			key := strings.TrimPrefix(object.Key, s.prefix)
+			if !ValidKey(key) || !object.LastModified.Before(olderThan) {
+				continue
+			}
+			status, _, err := s.do(ctx, http.MethodDelete, s.objectURL(key), nil, nil)
+			if err != nil && status != http.StatusNotFound {
+				return deleted, fmt.Errorf("failed to delete artifact %s: %w", key, err)
+			}
+			deleted++
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return deleted, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *s3Store) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,223 @@
+// Package artifactstore persists the binary outputs of requests, such as generated images,
+// videos and speech, and hands out expiring signed URLs to them, so responses carry a link
+// instead of megabytes of base64. Artifacts are addressed by the SHA-256 of their content, so
+// storing the same output twice keeps a single copy, and are deleted once older than a TTL.
+package artifactstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+type ArtifactStoreType string
+
+const (
+	ArtifactStoreTypeFilesystem ArtifactStoreType = "filesystem"
+	ArtifactStoreTypeS3         ArtifactStoreType = "s3"
+)
+
+const (
+	// DefaultTTL is how long artifacts are kept when no TTL is configured.
+	DefaultTTL = 24 * time.Hour
+	// DefaultURLTTL is how long signed URLs are valid when no URL TTL is configured.
+	DefaultURLTTL = time.Hour
+)
+
+var (
+	ErrNotFound     = errors.New("artifactstore: not found")
+	ErrInvalidKey   = errors.New("artifactstore: invalid key")
+	ErrInvalidURL   = errors.New("artifactstore: invalid or expired signature")
+	errEmptyContent = errors.New("artifactstore: empty content")
+)
+
+// Artifact is a stored binary.
+type Artifact struct {
+	Key      string    `json:"key"` // <sha256 hex>.<extension>
+	MimeType string    `json:"mime_type"`
+	Size     int       `json:"size"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// ArtifactStore persists artifacts and signs URLs to them.
+type ArtifactStore interface {
+	// Put stores data under the key derived from its content and returns the artifact. Storing
+	// content that already exists keeps a single copy and restarts its TTL.
+	Put(ctx context.Context, data []byte, mimeType string) (*Artifact, error)
+	// Get returns the content of an artifact, ErrNotFound if it does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// SignedURL returns a URL to an artifact that stops working after ttl.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// Sweep deletes the artifacts stored before olderThan and returns how many it deleted.
+	Sweep(ctx context.Context, olderThan time.Time) (int, error)
+	// Close releases the resources of the store.
+	Close() error
+}
+
+// Config represents the configuration of the artifact store.
+type Config struct {
+	Type   ArtifactStoreType `json:"type"`
+	TTL    string            `json:"ttl,omitempty"`     // How long artifacts are kept, e.g. "24h", DefaultTTL if empty
+	URLTTL string            `json:"url_ttl,omitempty"` // How long signed URLs are valid, e.g. "1h", DefaultURLTTL if empty
+	Config any               `json:"config"`
+}
+
+// UnmarshalJSON unmarshals the config from JSON.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type TempConfig struct {
+		Type   string          `json:"type"`
+		TTL    string          `json:"ttl,omitempty"`
+		URLTTL string          `json:"url_ttl,omitempty"`
+		Config json.RawMessage `json:"config"` // Keep as raw JSON
+	}
+
+	var temp TempConfig
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	c.Type = ArtifactStoreType(temp.Type)
+	c.TTL = temp.TTL
+	c.URLTTL = temp.URLTTL
+
+	switch c.Type {
+	case ArtifactStoreTypeFilesystem:
+		var filesystemConfig FilesystemConfig
+		if err := json.Unmarshal(temp.Config, &filesystemConfig); err != nil {
+			return fmt.Errorf("failed to unmarshal filesystem config: %w", err)
+		}
+		c.Config = filesystemConfig
+	case ArtifactStoreTypeS3:
+		var s3Config S3Config
+		if err := json.Unmarshal(temp.Config, &s3Config); err != nil {
+			return fmt.Errorf("failed to unmarshal s3 config: %w", err)
+		}
+		c.Config = s3Config
+	default:
+		return fmt.Errorf("unknown artifact store type: %s", temp.Type)
+	}
+
+	return nil
+}
+
+// Durations returns the artifact and signed URL TTLs of the config.
+func (c *Config) Durations() (time.Duration, time.Duration, error) {
+	ttl, err := parseDuration(c.TTL, DefaultTTL)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid artifact ttl: %w", err)
+	}
+	urlTTL, err := parseDuration(c.URLTTL, DefaultURLTTL)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid artifact url ttl: %w", err)
+	}
+	return ttl, urlTTL, nil
+}
+
+// NewArtifactStore returns a new artifact store based on the configuration.
+func NewArtifactStore(ctx context.Context, config *Config, logger schemas.Logger) (ArtifactStore, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	switch config.Type {
+	case ArtifactStoreTypeFilesystem:
+		filesystemConfig, ok := config.Config.(FilesystemConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid filesystem config")
+		}
+		return newFilesystemStore(filesystemConfig, logger)
+	case ArtifactStoreTypeS3:
+		s3Config, ok := config.Config.(S3Config)
+		if !ok {
+			return nil, fmt.Errorf("invalid s3 config")
+		}
+		return newS3Store(ctx, s3Config, logger)
+	default:
+		return nil, fmt.Errorf("invalid artifact store type: %s", config.Type)
+	}
+}
+
+// keyPattern matches the keys of artifacts: a SHA-256 hex digest and an optional extension.
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{64}(\.[0-9a-z]{1,8})?$`)
+
+// ContentKey returns the key of an artifact with the given content and mime type.
+func ContentKey(data []byte, mimeType string) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]) + extension(mimeType)
+}
+
+// ValidKey reports whether key is the key of an artifact. Keys are used as file and object
+// names, so anything else is rejected before reaching a store.
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// mimeTypes maps the extensions of artifact keys to their mime type.
+var mimeTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".webp": "image/webp",
+	".gif":  "image/gif",
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".ogg":  "audio/ogg",
+	".flac": "audio/flac",
+	".aac":  "audio/aac",
+}
+
+// mimeAliases maps alternative names of mime types to the name in mimeTypes.
+var mimeAliases = map[string]string{
+	"audio/x-wav": "audio/wav",
+	"audio/wave":  "audio/wav",
+	"audio/opus":  "audio/ogg",
+	"audio/mp3":   "audio/mpeg",
+}
+
+// extension returns the file extension of a mime type, with its leading dot, or "" if unknown.
+func extension(mimeType string) string {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	if alias, ok := mimeAliases[mediaType]; ok {
+		mediaType = alias
+	}
+	for ext, candidate := range mimeTypes {
+		if candidate == mediaType {
+			return ext
+		}
+	}
+	return ""
+}
+
+// MimeType returns the mime type of an artifact from the extension of its key.
+func MimeType(key string) string {
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		if mimeType, ok := mimeTypes[key[i:]]; ok {
+			return mimeType
+		}
+	}
+	return "application/octet-stream"
+}
+
+func parseDuration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
@@ -0,0 +1,157 @@
+package artifactstore
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFilesystemStore(t *testing.T) *FilesystemStore {
+	store, err := newFilesystemStore(FilesystemConfig{
+		Directory:  t.TempDir(),
+		PublicURL:  "https://bifrost.example.com/",
+		SigningKey: "test-signing-key",
+	}, nil)
+	require.NoError(t, err)
+	return store
+}
+
+func TestFilesystemStoreContentAddressing(t *testing.T) {
+	store := newTestFilesystemStore(t)
+	ctx := context.Background()
+
+	first, err := store.Put(ctx, []byte("png bytes"), "image/png")
+	require.NoError(t, err)
+	second, err := store.Put(ctx, []byte("png bytes"), "image/png")
+	require.NoError(t, err)
+	assert.Equal(t, first.Key, second.Key)
+	assert.True(t, strings.HasSuffix(first.Key, ".png"))
+	assert.True(t, ValidKey(first.Key))
+
+	other, err := store.Put(ctx, []byte("other bytes"), "image/png")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Key, other.Key)
+
+	data, err := store.Get(ctx, first.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "png bytes", string(data))
+	assert.Equal(t, "image/png", MimeType(first.Key))
+
+	_, err = store.Get(ctx, "../../etc/passwd")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = store.Get(ctx, ContentKey([]byte("missing"), "image/png"))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFilesystemStoreSignedURL(t *testing.T) {
+	store := newTestFilesystemStore(t)
+	artifact, err := store.Put(context.Background(), []byte("mp4 bytes"), "video/mp4")
+	require.NoError(t, err)
+
+	signedURL, err := store.SignedURL(context.Background(), artifact.Key, time.Minute)
+	require.NoError(t, err)
+	parsed, err := url.Parse(signedURL)
+	require.NoError(t, err)
+	assert.Equal(t, ServePath+artifact.Key, parsed.Path)
+	assert.Equal(t, "bifrost.example.com", parsed.Host)
+
+	expires, signature := parsed.Query().Get("expires"), parsed.Query().Get("signature")
+	assert.NoError(t, store.Verify(artifact.Key, expires, signature))
+
+	// Tampered signatures, other keys and expired URLs are rejected
+	assert.ErrorIs(t, store.Verify(artifact.Key, expires, signature[:len(signature)-1]+"0"), ErrInvalidURL)
+	assert.ErrorIs(t, store.Verify(ContentKey([]byte("other"), "video/mp4"), expires, signature), ErrInvalidURL)
+	expiredURL, err := store.SignedURL(context.Background(), artifact.Key, -time.Minute)
+	require.NoError(t, err)
+	parsed, err = url.Parse(expiredURL)
+	require.NoError(t, err)
+	assert.ErrorIs(t, store.Verify(artifact.Key, parsed.Query().Get("expires"), parsed.Query().Get("signature")), ErrInvalidURL)
+}
+
+func TestFilesystemStoreSweep(t *testing.T) {
+	store := newTestFilesystemStore(t)
+	ctx := context.Background()
+
+	old, err := store.Put(ctx, []byte("old"), "audio/mpeg")
+	require.NoError(t, err)
+	past := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(store.path(old.Key), past, past))
+	recent, err := store.Put(ctx, []byte("recent"), "audio/mpeg")
+	require.NoError(t, err)
+
+	deleted, err := store.Sweep(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	_, err = store.Get(ctx, old.Key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Get(ctx, recent.Key)
+	assert.NoError(t, err)
+
+	// Storing the content again restarts its TTL
+	require.NoError(t, os.Chtimes(store.path(recent.Key), past, past))
+	_, err = store.Put(ctx, []byte("recent"), "audio/mpeg")
+	require.NoError(t, err)
+	deleted, err = store.Sweep(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+}
+
+func TestPluginReplacesArtifactsWithSignedURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Write([]byte("provider video"))
+	}))
+	defer server.Close()
+
+	plugin, err := Init(context.Background(), PluginConfig{
+		Store: Config{
+			Type: ArtifactStoreTypeFilesystem,
+			Config: FilesystemConfig{
+				Directory:  t.TempDir(),
+				PublicURL:  "https://bifrost.example.com",
+				SigningKey: "test-signing-key",
+			},
+		},
+		Speech: true,
+	}, nil)
+	require.NoError(t, err)
+	defer plugin.Cleanup()
+
+	run := func(requestType schemas.RequestType, result *schemas.BifrostResponse) {
+		ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyRequestType, requestType)
+		_, bifrostErr, err := plugin.PostHook(&ctx, result, nil)
+		require.NoError(t, err)
+		require.Nil(t, bifrostErr)
+	}
+	artifactPrefix := "https://bifrost.example.com" + ServePath
+
+	image := &schemas.BifrostResponse{Image: &schemas.BifrostImage{Images: []schemas.GeneratedImage{
+		{B64JSON: base64.StdEncoding.EncodeToString([]byte("png bytes")), MimeType: "image/png"},
+	}}}
+	run(schemas.ImageGenerationRequest, image)
+	assert.Empty(t, image.Image.Images[0].B64JSON)
+	assert.True(t, strings.HasPrefix(image.Image.Images[0].URL, artifactPrefix+ContentKey([]byte("png bytes"), "image/png")))
+
+	video := &schemas.BifrostResponse{Video: &schemas.BifrostVideo{
+		Status: schemas.VideoJobCompleted,
+		Videos: []schemas.GeneratedVideo{{URL: server.URL + "/video.mp4", MimeType: "video/mp4"}},
+	}}
+	run(schemas.VideoStatusRequest, video)
+	assert.True(t, strings.HasPrefix(video.Video.Videos[0].URL, artifactPrefix+ContentKey([]byte("provider video"), "video/mp4")))
+
+	speech := &schemas.BifrostResponse{Speech: &schemas.BifrostSpeech{Audio: []byte("ID3 mp3 bytes")}}
+	run(schemas.SpeechRequest, speech)
+	assert.Nil(t, speech.Speech.Audio)
+	assert.True(t, strings.HasPrefix(speech.Speech.URL, artifactPrefix))
+	assert.Contains(t, speech.Speech.URL, ".mp3?")
+}
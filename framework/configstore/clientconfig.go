@@ -35,6 +35,13 @@ type ClientConfig struct {
 	AllowDirectKeys         bool     `json:"allow_direct_keys"`         // Allow direct keys to be used for requests
 	AllowedOrigins          []string `json:"allowed_origins,omitempty"` // Additional allowed origins for CORS and WebSocket (localhost is always allowed)
 	MaxRequestBodySizeMB    int      `json:"max_request_body_size_mb"`  // The maximum request body size in MB
+
+	// Slow streaming client protection, zero values use the transport defaults
+	StreamWriteTimeoutSeconds int    `json:"stream_write_timeout_seconds,omitempty"` // Longest a single stream write may block before the client is dropped
+	StreamBufferMaxChunks     int    `json:"stream_buffer_max_chunks,omitempty"`     // Chunks buffered for a client that reads slower than the provider streams
+	SlowStreamClientPolicy    string `json:"slow_stream_client_policy,omitempty"`    // "terminate" or "degrade", applied when the buffer is full
+
+	FairQueuing *schemas.FairQueuingConfig `json:"fair_queuing,omitempty"` // Weighted fair queuing of provider queues across tenants, FIFO if nil
 }
 
 // ProviderConfig represents the configuration for a specific AI model provider.
@@ -44,6 +51,7 @@ type ProviderConfig struct {
 	NetworkConfig            *schemas.NetworkConfig            `json:"network_config,omitempty"`              // Network-related settings
 	ConcurrencyAndBufferSize *schemas.ConcurrencyAndBufferSize `json:"concurrency_and_buffer_size,omitempty"` // Concurrency settings
 	ProxyConfig              *schemas.ProxyConfig              `json:"proxy_config,omitempty"`                // Proxy configuration
+	HTTPClientProfile        *schemas.HTTPClientProfile        `json:"http_client_profile,omitempty"`         // TLS, HTTP version and header settings of the provider's HTTP clients
 	SendBackRawResponse      bool                              `json:"send_back_raw_response"`                // Include raw response in BifrostResponse
 	CustomProviderConfig     *schemas.CustomProviderConfig     `json:"custom_provider_config,omitempty"`      // Custom provider configuration
 }
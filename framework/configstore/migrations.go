@@ -18,6 +18,18 @@ func triggerMigrations(db *gorm.DB) error {
 	if err := migrationAddCustomProviderConfigJSONColumn(db); err != nil {
 		return err
 	}
+	if err := migrationAddStreamProtectionColumns(db); err != nil {
+		return err
+	}
+	if err := migrationAddOutputCostPerImageColumn(db); err != nil {
+		return err
+	}
+	if err := migrationAddHTTPClientProfileJSONColumn(db); err != nil {
+		return err
+	}
+	if err := migrationAddFairQueuingJSONColumn(db); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -242,3 +254,93 @@ func migrationAddCustomProviderConfigJSONColumn(db *gorm.DB) error {
 	}
 	return nil
 }
+
+// migrationAddStreamProtectionColumns adds the slow streaming client settings to the client config.
+func migrationAddStreamProtectionColumns(db *gorm.DB) error {
+	m := migration.New(db, migration.DefaultOptions, []*migration.Migration{{
+		ID: "addstreamprotectioncolumns",
+		Migrate: func(tx *gorm.DB) error {
+			migrator := tx.Migrator()
+
+			for _, column := range []string{"stream_write_timeout_seconds", "stream_buffer_max_chunks", "slow_stream_client_policy"} {
+				if !migrator.HasColumn(&TableClientConfig{}, column) {
+					if err := migrator.AddColumn(&TableClientConfig{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running db migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddOutputCostPerImageColumn adds the per image price of image generation models.
+func migrationAddOutputCostPerImageColumn(db *gorm.DB) error {
+	m := migration.New(db, migration.DefaultOptions, []*migration.Migration{{
+		ID: "addoutputcostperimagecolumn",
+		Migrate: func(tx *gorm.DB) error {
+			migrator := tx.Migrator()
+
+			if !migrator.HasColumn(&TableModelPricing{}, "output_cost_per_image") {
+				if err := migrator.AddColumn(&TableModelPricing{}, "output_cost_per_image"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running db migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddHTTPClientProfileJSONColumn adds the HTTP client profile of providers.
+func migrationAddHTTPClientProfileJSONColumn(db *gorm.DB) error {
+	m := migration.New(db, migration.DefaultOptions, []*migration.Migration{{
+		ID: "addhttpclientprofilejsoncolumn",
+		Migrate: func(tx *gorm.DB) error {
+			migrator := tx.Migrator()
+
+			if !migrator.HasColumn(&TableProvider{}, "http_client_profile_json") {
+				if err := migrator.AddColumn(&TableProvider{}, "http_client_profile_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running db migration: %s", err.Error())
+	}
+	return nil
+}
+
+// migrationAddFairQueuingJSONColumn adds the fair queuing settings to the client config.
+func migrationAddFairQueuingJSONColumn(db *gorm.DB) error {
+	m := migration.New(db, migration.DefaultOptions, []*migration.Migration{{
+		ID: "addfairqueuingjsoncolumn",
+		Migrate: func(tx *gorm.DB) error {
+			migrator := tx.Migrator()
+
+			if !migrator.HasColumn(&TableClientConfig{}, "fair_queuing_json") {
+				if err := migrator.AddColumn(&TableClientConfig{}, "fair_queuing_json"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}})
+	err := m.Migrate()
+	if err != nil {
+		return fmt.Errorf("error while running db migration: %s", err.Error())
+	}
+	return nil
+}
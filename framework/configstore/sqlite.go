@@ -47,15 +47,19 @@ type SQLiteConfigStore struct {
 // UpdateClientConfig updates the client configuration in the database.
 func (s *SQLiteConfigStore) UpdateClientConfig(config *ClientConfig) error {
 	dbConfig := TableClientConfig{
-		DropExcessRequests:      config.DropExcessRequests,
-		InitialPoolSize:         config.InitialPoolSize,
-		EnableLogging:           config.EnableLogging,
-		EnableGovernance:        config.EnableGovernance,
-		EnforceGovernanceHeader: config.EnforceGovernanceHeader,
-		AllowDirectKeys:         config.AllowDirectKeys,
-		PrometheusLabels:        config.PrometheusLabels,
-		AllowedOrigins:          config.AllowedOrigins,
-		MaxRequestBodySizeMB:    config.MaxRequestBodySizeMB,
+		DropExcessRequests:        config.DropExcessRequests,
+		InitialPoolSize:           config.InitialPoolSize,
+		EnableLogging:             config.EnableLogging,
+		EnableGovernance:          config.EnableGovernance,
+		EnforceGovernanceHeader:   config.EnforceGovernanceHeader,
+		AllowDirectKeys:           config.AllowDirectKeys,
+		PrometheusLabels:          config.PrometheusLabels,
+		AllowedOrigins:            config.AllowedOrigins,
+		MaxRequestBodySizeMB:      config.MaxRequestBodySizeMB,
+		StreamWriteTimeoutSeconds: config.StreamWriteTimeoutSeconds,
+		StreamBufferMaxChunks:     config.StreamBufferMaxChunks,
+		SlowStreamClientPolicy:    config.SlowStreamClientPolicy,
+		FairQueuing:               config.FairQueuing,
 	}
 	// Delete existing client config and create new one in a transaction
 	return s.db.Transaction(func(tx *gorm.DB) error {
@@ -76,15 +80,19 @@ func (s *SQLiteConfigStore) GetClientConfig() (*ClientConfig, error) {
 		return nil, err
 	}
 	return &ClientConfig{
-		DropExcessRequests:      dbConfig.DropExcessRequests,
-		InitialPoolSize:         dbConfig.InitialPoolSize,
-		PrometheusLabels:        dbConfig.PrometheusLabels,
-		EnableLogging:           dbConfig.EnableLogging,
-		EnableGovernance:        dbConfig.EnableGovernance,
-		EnforceGovernanceHeader: dbConfig.EnforceGovernanceHeader,
-		AllowDirectKeys:         dbConfig.AllowDirectKeys,
-		AllowedOrigins:          dbConfig.AllowedOrigins,
-		MaxRequestBodySizeMB:    dbConfig.MaxRequestBodySizeMB,
+		DropExcessRequests:        dbConfig.DropExcessRequests,
+		InitialPoolSize:           dbConfig.InitialPoolSize,
+		PrometheusLabels:          dbConfig.PrometheusLabels,
+		EnableLogging:             dbConfig.EnableLogging,
+		EnableGovernance:          dbConfig.EnableGovernance,
+		EnforceGovernanceHeader:   dbConfig.EnforceGovernanceHeader,
+		AllowDirectKeys:           dbConfig.AllowDirectKeys,
+		AllowedOrigins:            dbConfig.AllowedOrigins,
+		MaxRequestBodySizeMB:      dbConfig.MaxRequestBodySizeMB,
+		StreamWriteTimeoutSeconds: dbConfig.StreamWriteTimeoutSeconds,
+		StreamBufferMaxChunks:     dbConfig.StreamBufferMaxChunks,
+		SlowStreamClientPolicy:    dbConfig.SlowStreamClientPolicy,
+		FairQueuing:               dbConfig.FairQueuing,
 	}, nil
 }
 
@@ -105,6 +113,7 @@ func (s *SQLiteConfigStore) UpdateProvidersConfig(providers map[schemas.ModelPro
 				NetworkConfig:            providerConfig.NetworkConfig,
 				ConcurrencyAndBufferSize: providerConfig.ConcurrencyAndBufferSize,
 				ProxyConfig:              providerConfig.ProxyConfig,
+				HTTPClientProfile:        providerConfig.HTTPClientProfile,
 				SendBackRawResponse:      providerConfig.SendBackRawResponse,
 				CustomProviderConfig:     providerConfig.CustomProviderConfig,
 			}
@@ -205,6 +214,7 @@ func (s *SQLiteConfigStore) UpdateProvider(provider schemas.ModelProvider, confi
 		dbProvider.NetworkConfig = configCopy.NetworkConfig
 		dbProvider.ConcurrencyAndBufferSize = configCopy.ConcurrencyAndBufferSize
 		dbProvider.ProxyConfig = configCopy.ProxyConfig
+		dbProvider.HTTPClientProfile = configCopy.HTTPClientProfile
 		dbProvider.SendBackRawResponse = configCopy.SendBackRawResponse
 		dbProvider.CustomProviderConfig = configCopy.CustomProviderConfig
 
@@ -314,6 +324,7 @@ func (s *SQLiteConfigStore) AddProvider(provider schemas.ModelProvider, config P
 			NetworkConfig:            configCopy.NetworkConfig,
 			ConcurrencyAndBufferSize: configCopy.ConcurrencyAndBufferSize,
 			ProxyConfig:              configCopy.ProxyConfig,
+			HTTPClientProfile:        configCopy.HTTPClientProfile,
 			SendBackRawResponse:      configCopy.SendBackRawResponse,
 			CustomProviderConfig:     configCopy.CustomProviderConfig,
 		}
@@ -490,6 +501,7 @@ func (s *SQLiteConfigStore) GetProvidersConfig() (map[schemas.ModelProvider]Prov
 			NetworkConfig:            dbProvider.NetworkConfig,
 			ConcurrencyAndBufferSize: dbProvider.ConcurrencyAndBufferSize,
 			ProxyConfig:              dbProvider.ProxyConfig,
+			HTTPClientProfile:        dbProvider.HTTPClientProfile,
 			SendBackRawResponse:      dbProvider.SendBackRawResponse,
 			CustomProviderConfig:     dbProvider.CustomProviderConfig,
 		}
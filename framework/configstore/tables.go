@@ -25,6 +25,7 @@ type TableProvider struct {
 	NetworkConfigJSON        string    `gorm:"type:text" json:"-"`                                // JSON serialized schemas.NetworkConfig
 	ConcurrencyBufferJSON    string    `gorm:"type:text" json:"-"`                                // JSON serialized schemas.ConcurrencyAndBufferSize
 	ProxyConfigJSON          string    `gorm:"type:text" json:"-"`                                // JSON serialized schemas.ProxyConfig
+	HTTPClientProfileJSON    string    `gorm:"type:text" json:"-"`                                // JSON serialized schemas.HTTPClientProfile
 	CustomProviderConfigJSON string    `gorm:"type:text" json:"-"`                                // JSON serialized schemas.CustomProviderConfig
 	SendBackRawResponse      bool      `json:"send_back_raw_response"`
 	CreatedAt                time.Time `gorm:"index;not null" json:"created_at"`
@@ -37,6 +38,7 @@ type TableProvider struct {
 	NetworkConfig            *schemas.NetworkConfig            `gorm:"-" json:"network_config,omitempty"`
 	ConcurrencyAndBufferSize *schemas.ConcurrencyAndBufferSize `gorm:"-" json:"concurrency_and_buffer_size,omitempty"`
 	ProxyConfig              *schemas.ProxyConfig              `gorm:"-" json:"proxy_config,omitempty"`
+	HTTPClientProfile        *schemas.HTTPClientProfile        `gorm:"-" json:"http_client_profile,omitempty"`
 
 	// Custom provider fields
 	CustomProviderConfig *schemas.CustomProviderConfig `gorm:"-" json:"custom_provider_config,omitempty"`
@@ -111,22 +113,27 @@ type TableMCPClient struct {
 
 // TableClientConfig represents global client configuration in the database
 type TableClientConfig struct {
-	ID                      uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	DropExcessRequests      bool      `gorm:"default:false" json:"drop_excess_requests"`
-	PrometheusLabelsJSON    string    `gorm:"type:text" json:"-"` // JSON serialized []string
-	AllowedOriginsJSON      string    `gorm:"type:text" json:"-"` // JSON serialized []string
-	InitialPoolSize         int       `gorm:"default:300" json:"initial_pool_size"`
-	EnableLogging           bool      `gorm:"" json:"enable_logging"`
-	EnableGovernance        bool      `gorm:"" json:"enable_governance"`
-	EnforceGovernanceHeader bool      `gorm:"" json:"enforce_governance_header"`
-	AllowDirectKeys         bool      `gorm:"" json:"allow_direct_keys"`
-	MaxRequestBodySizeMB    int       `gorm:"" json:"max_request_body_size_mb"`
-	CreatedAt               time.Time `gorm:"index;not null" json:"created_at"`
-	UpdatedAt               time.Time `gorm:"index;not null" json:"updated_at"`
+	ID                        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	DropExcessRequests        bool      `gorm:"default:false" json:"drop_excess_requests"`
+	PrometheusLabelsJSON      string    `gorm:"type:text" json:"-"` // JSON serialized []string
+	AllowedOriginsJSON        string    `gorm:"type:text" json:"-"` // JSON serialized []string
+	InitialPoolSize           int       `gorm:"default:300" json:"initial_pool_size"`
+	EnableLogging             bool      `gorm:"" json:"enable_logging"`
+	EnableGovernance          bool      `gorm:"" json:"enable_governance"`
+	EnforceGovernanceHeader   bool      `gorm:"" json:"enforce_governance_header"`
+	AllowDirectKeys           bool      `gorm:"" json:"allow_direct_keys"`
+	MaxRequestBodySizeMB      int       `gorm:"" json:"max_request_body_size_mb"`
+	StreamWriteTimeoutSeconds int       `gorm:"" json:"stream_write_timeout_seconds"`
+	StreamBufferMaxChunks     int       `gorm:"" json:"stream_buffer_max_chunks"`
+	SlowStreamClientPolicy    string    `gorm:"type:varchar(50)" json:"slow_stream_client_policy"`
+	FairQueuingJSON           string    `gorm:"type:text" json:"-"` // JSON serialized schemas.FairQueuingConfig, empty if off
+	CreatedAt                 time.Time `gorm:"index;not null" json:"created_at"`
+	UpdatedAt                 time.Time `gorm:"index;not null" json:"updated_at"`
 
 	// Virtual fields for runtime use (not stored in DB)
-	PrometheusLabels []string `gorm:"-" json:"prometheus_labels"`
-	AllowedOrigins   []string `gorm:"-" json:"allowed_origins,omitempty"`
+	PrometheusLabels []string                   `gorm:"-" json:"prometheus_labels"`
+	AllowedOrigins   []string                   `gorm:"-" json:"allowed_origins,omitempty"`
+	FairQueuing      *schemas.FairQueuingConfig `gorm:"-" json:"fair_queuing,omitempty"`
 }
 
 // TableEnvKey represents environment variable tracking in the database
@@ -217,6 +224,16 @@ func (p *TableProvider) BeforeSave(tx *gorm.DB) error {
 		p.ProxyConfigJSON = string(data)
 	}
 
+	if p.HTTPClientProfile != nil {
+		data, err := json.Marshal(p.HTTPClientProfile)
+		if err != nil {
+			return err
+		}
+		p.HTTPClientProfileJSON = string(data)
+	} else {
+		p.HTTPClientProfileJSON = ""
+	}
+
 	if p.CustomProviderConfig != nil && p.CustomProviderConfig.BaseProviderType == "" {
 		return fmt.Errorf("base_provider_type is required when custom_provider_config is set")
 	}
@@ -364,6 +381,16 @@ func (cc *TableClientConfig) BeforeSave(tx *gorm.DB) error {
 		cc.AllowedOriginsJSON = "[]"
 	}
 
+	if cc.FairQueuing != nil {
+		data, err := json.Marshal(cc.FairQueuing)
+		if err != nil {
+			return err
+		}
+		cc.FairQueuingJSON = string(data)
+	} else {
+		cc.FairQueuingJSON = ""
+	}
+
 	return nil
 }
 
@@ -407,6 +434,14 @@ func (p *TableProvider) AfterFind(tx *gorm.DB) error {
 		p.ProxyConfig = &proxyConfig
 	}
 
+	if p.HTTPClientProfileJSON != "" {
+		var profile schemas.HTTPClientProfile
+		if err := json.Unmarshal([]byte(p.HTTPClientProfileJSON), &profile); err != nil {
+			return err
+		}
+		p.HTTPClientProfile = &profile
+	}
+
 	if p.CustomProviderConfigJSON != "" {
 		var customConfig schemas.CustomProviderConfig
 		if err := json.Unmarshal([]byte(p.CustomProviderConfigJSON), &customConfig); err != nil {
@@ -528,6 +563,12 @@ func (cc *TableClientConfig) AfterFind(tx *gorm.DB) error {
 		}
 	}
 
+	if cc.FairQueuingJSON != "" {
+		if err := json.Unmarshal([]byte(cc.FairQueuingJSON), &cc.FairQueuing); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -654,6 +695,7 @@ type TableModelPricing struct {
 	InputCostPerImage          *float64 `gorm:"default:null" json:"input_cost_per_image,omitempty"`
 	InputCostPerVideoPerSecond *float64 `gorm:"default:null" json:"input_cost_per_video_per_second,omitempty"`
 	InputCostPerAudioPerSecond *float64 `gorm:"default:null" json:"input_cost_per_audio_per_second,omitempty"`
+	OutputCostPerImage         *float64 `gorm:"default:null" json:"output_cost_per_image,omitempty"`
 
 	// Character-based pricing
 	InputCostPerCharacter  *float64 `gorm:"default:null" json:"input_cost_per_character,omitempty"`
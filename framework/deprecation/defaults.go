@@ -0,0 +1,32 @@
+package deprecation
+
+import "github.com/maximhq/bifrost/core/schemas"
+
+// DefaultEntries are the retirements announced by providers at release time.
+// Sources and Config.Entries extend or override them.
+var DefaultEntries = []Entry{
+	// OpenAI
+	{Provider: schemas.OpenAI, Model: "gpt-4-vision-preview", ShutdownDate: "2024-12-06", Replacement: "gpt-4o"},
+	{Provider: schemas.OpenAI, Model: "gpt-4-1106-vision-preview", ShutdownDate: "2024-12-06", Replacement: "gpt-4o"},
+	{Provider: schemas.OpenAI, Model: "gpt-4-32k", ShutdownDate: "2025-06-06", Replacement: "gpt-4o"},
+	{Provider: schemas.OpenAI, Model: "gpt-4-32k-0613", ShutdownDate: "2025-06-06", Replacement: "gpt-4o"},
+	{Provider: schemas.OpenAI, Model: "gpt-4.5-preview", ShutdownDate: "2025-07-14", Replacement: "gpt-4.1"},
+	{Provider: schemas.OpenAI, Model: "gpt-4.5-preview-2025-02-27", ShutdownDate: "2025-07-14", Replacement: "gpt-4.1"},
+	{Provider: schemas.OpenAI, Model: "o1-preview", ShutdownDate: "2025-07-28", Replacement: "o3"},
+	{Provider: schemas.OpenAI, Model: "o1-mini", ShutdownDate: "2025-10-27", Replacement: "o4-mini"},
+
+	// Anthropic
+	{Provider: schemas.Anthropic, Model: "claude-2.0", ShutdownDate: "2025-07-21", Replacement: "claude-sonnet-4-20250514"},
+	{Provider: schemas.Anthropic, Model: "claude-2.1", ShutdownDate: "2025-07-21", Replacement: "claude-sonnet-4-20250514"},
+	{Provider: schemas.Anthropic, Model: "claude-3-sonnet-20240229", ShutdownDate: "2025-07-21", Replacement: "claude-sonnet-4-20250514"},
+	{Provider: schemas.Anthropic, Model: "claude-3-5-sonnet-20240620", ShutdownDate: "2025-10-22", Replacement: "claude-sonnet-4-5-20250929"},
+	{Provider: schemas.Anthropic, Model: "claude-3-5-sonnet-20241022", ShutdownDate: "2025-10-22", Replacement: "claude-sonnet-4-5-20250929"},
+	{Provider: schemas.Anthropic, Model: "claude-3-opus-20240229", ShutdownDate: "2026-01-05", Replacement: "claude-opus-4-1-20250805"},
+
+	// Gemini
+	{Provider: schemas.Gemini, Model: "gemini-1.5-pro", ShutdownDate: "2025-09-24", Replacement: "gemini-2.5-pro"},
+	{Provider: schemas.Gemini, Model: "gemini-1.5-flash", ShutdownDate: "2025-09-24", Replacement: "gemini-2.5-flash"},
+	{Provider: schemas.Gemini, Model: "gemini-1.5-flash-8b", ShutdownDate: "2025-09-24", Replacement: "gemini-2.5-flash-lite"},
+	{Provider: schemas.Vertex, Model: "gemini-1.5-pro", ShutdownDate: "2025-09-24", Replacement: "gemini-2.5-pro"},
+	{Provider: schemas.Vertex, Model: "gemini-1.5-flash", ShutdownDate: "2025-09-24", Replacement: "gemini-2.5-flash"},
+}
@@ -0,0 +1,233 @@
+// Package deprecation keeps a registry of models scheduled for retirement by their providers
+// and provides a plugin that warns about requests still targeting them.
+package deprecation
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/statestore"
+)
+
+// Default registry settings
+const (
+	DefaultWarningDays  = 90
+	DefaultSyncInterval = 24 * time.Hour
+
+	dateLayout = "2006-01-02"
+)
+
+// Entry is a model scheduled for retirement. An empty provider matches the model on every provider.
+type Entry struct {
+	Provider     schemas.ModelProvider `json:"provider,omitempty"`
+	Model        string                `json:"model"`
+	ShutdownDate string                `json:"shutdown_date"` // YYYY-MM-DD
+	Replacement  string                `json:"replacement,omitempty"`
+}
+
+// Source is an endpoint the registry is updated from. It may serve a list of entries or a
+// provider model list whose items carry a deprecation, shutdown or expiration date.
+type Source struct {
+	URL      string                `json:"url"`
+	Provider schemas.ModelProvider `json:"provider,omitempty"` // Provider of items that do not name one
+	Headers  map[string]string     `json:"headers,omitempty"`  // Extra request headers, e.g. authorization
+}
+
+// Config configures the deprecation registry and plugin.
+type Config struct {
+	WarningDays     int      `json:"warning_days,omitempty"`     // Days before shutdown from which requests are warned, DefaultWarningDays if 0
+	SyncInterval    string   `json:"sync_interval,omitempty"`    // How often sources are refreshed, DefaultSyncInterval if empty
+	Entries         []Entry  `json:"entries,omitempty"`          // Entries added to, or overriding, the built-in ones
+	Sources         []Source `json:"sources,omitempty"`          // Endpoints the registry is updated from
+	DisableDefaults bool     `json:"disable_defaults,omitempty"` // Skip the built-in entries
+
+	// StateStore shares synced entries between replicas: only the replica for which IsLeader
+	// returns true fetches the sources, the others load the entries it published. Every replica
+	// fetches the sources if StateStore is nil.
+	StateStore statestore.StateStore `json:"-"`
+	IsLeader   func() bool           `json:"-"`
+}
+
+// Registry maps models to their retirement schedule. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]entryData // provider/model -> entry
+
+	warningWindow time.Duration
+	sources       []Source
+	syncInterval  time.Duration
+	logger        schemas.Logger
+
+	// Sharing of synced entries, see Config.StateStore
+	stateStore     statestore.StateStore
+	isLeader       func() bool
+	sharedLoadedAt time.Time // Publication last loaded, only used by the sync worker
+
+	// Background sync worker
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// entryData is an entry with its parsed shutdown date.
+type entryData struct {
+	Entry
+	shutdown time.Time
+}
+
+// NewRegistry creates a registry from the built-in entries and config.Entries.
+// Sources are not fetched until Sync or StartSync is called.
+func NewRegistry(config Config, logger schemas.Logger) (*Registry, error) {
+	warningDays := config.WarningDays
+	if warningDays <= 0 {
+		warningDays = DefaultWarningDays
+	}
+
+	syncInterval := DefaultSyncInterval
+	if config.SyncInterval != "" {
+		parsed, err := time.ParseDuration(config.SyncInterval)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid deprecation sync interval: %s", config.SyncInterval)
+		}
+		syncInterval = parsed
+	}
+
+	for _, source := range config.Sources {
+		if source.URL == "" {
+			return nil, fmt.Errorf("deprecation source url is required")
+		}
+	}
+
+	r := &Registry{
+		entries:       make(map[string]entryData),
+		warningWindow: time.Duration(warningDays) * 24 * time.Hour,
+		sources:       config.Sources,
+		syncInterval:  syncInterval,
+		logger:        logger,
+		stateStore:    config.StateStore,
+		isLeader:      config.IsLeader,
+		done:          make(chan struct{}),
+	}
+
+	if !config.DisableDefaults {
+		if err := r.Update(DefaultEntries); err != nil {
+			return nil, fmt.Errorf("invalid built-in deprecation entries: %w", err)
+		}
+	}
+	if err := r.Update(config.Entries); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Update adds or replaces entries. Invalid entries are rejected before any is applied.
+func (r *Registry) Update(entries []Entry) error {
+	parsed := make([]entryData, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Model == "" {
+			return fmt.Errorf("deprecation entry model is required")
+		}
+		shutdown, err := time.Parse(dateLayout, entry.ShutdownDate)
+		if err != nil {
+			return fmt.Errorf("invalid shutdown date %q for model %s: expected YYYY-MM-DD", entry.ShutdownDate, entry.Model)
+		}
+		parsed = append(parsed, entryData{Entry: entry, shutdown: shutdown})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, entry := range parsed {
+		r.entries[makeKey(entry.Provider, entry.Model)] = entry
+	}
+	return nil
+}
+
+// Entries returns every registered entry sorted by shutdown date.
+func (r *Registry) Entries() []Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]entryData, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].shutdown.Equal(entries[j].shutdown) {
+			return entries[i].shutdown.Before(entries[j].shutdown)
+		}
+		return makeKey(entries[i].Provider, entries[i].Model) < makeKey(entries[j].Provider, entries[j].Model)
+	})
+
+	result := make([]Entry, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.Entry
+	}
+	return result
+}
+
+// Lookup returns the entry of a model, preferring a provider-specific entry over a provider-agnostic one.
+func (r *Registry) Lookup(provider schemas.ModelProvider, model string) (Entry, bool) {
+	entry, ok := r.lookup(provider, model)
+	return entry.Entry, ok
+}
+
+// Warning returns the deprecation warning for a request to the model at the given time,
+// or nil when the model is not deprecated or its shutdown is further away than the warning window.
+func (r *Registry) Warning(provider schemas.ModelProvider, model string, now time.Time) *schemas.ModelDeprecation {
+	entry, ok := r.lookup(provider, model)
+	if !ok {
+		return nil
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	remaining := entry.shutdown.Sub(today)
+	if remaining > r.warningWindow {
+		return nil
+	}
+	days := int(remaining.Hours() / 24)
+
+	var message string
+	if days >= 0 {
+		message = fmt.Sprintf("model %s is scheduled for shutdown on %s", model, entry.ShutdownDate)
+	} else {
+		message = fmt.Sprintf("model %s was scheduled for shutdown on %s", model, entry.ShutdownDate)
+	}
+	if entry.Replacement != "" {
+		message += fmt.Sprintf(", migrate to %s", entry.Replacement)
+	}
+
+	return &schemas.ModelDeprecation{
+		Model:         model,
+		ShutdownDate:  entry.ShutdownDate,
+		Replacement:   entry.Replacement,
+		DaysRemaining: days,
+		Message:       message,
+	}
+}
+
+func (r *Registry) lookup(provider schemas.ModelProvider, model string) (entryData, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if entry, ok := r.entries[makeKey(provider, model)]; ok {
+		return entry, true
+	}
+	entry, ok := r.entries[makeKey("", model)]
+	return entry, ok
+}
+
+// Stop stops the background sync worker.
+func (r *Registry) Stop() {
+	select {
+	case <-r.done:
+	default:
+		close(r.done)
+	}
+	r.wg.Wait()
+}
+
+func makeKey(provider schemas.ModelProvider, model string) string {
+	return string(provider) + "/" + model
+}
@@ -0,0 +1,106 @@
+package deprecation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	PluginName = "deprecation"
+
+	// logInterval limits how often the same model is logged as deprecated
+	logInterval = time.Hour
+)
+
+// Plugin attaches deprecation warnings to responses of requests targeting models that are
+// retired or about to be. Warnings are set in ExtraFields.Deprecation, on the first and final chunks of a stream.
+type Plugin struct {
+	registry *Registry
+	logger   schemas.Logger
+
+	loggedMu sync.Mutex
+	logged   map[string]time.Time // provider/model -> last warning log
+}
+
+// Init creates the registry from config, starts syncing its sources and returns the plugin.
+// The sync stops on Cleanup.
+func Init(ctx context.Context, config Config, logger schemas.Logger) (*Plugin, error) {
+	registry, err := NewRegistry(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	registry.StartSync(ctx)
+
+	return &Plugin{
+		registry: registry,
+		logger:   logger,
+		logged:   make(map[string]time.Time),
+	}, nil
+}
+
+// GetName returns the name of the plugin.
+func (p *Plugin) GetName() string {
+	return PluginName
+}
+
+// GetRegistry returns the registry the plugin checks requests against.
+func (p *Plugin) GetRegistry() *Registry {
+	return p.registry
+}
+
+// PreHook is a no-op, warnings are attached to responses.
+func (p *Plugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	return req, nil, nil
+}
+
+// PostHook attaches the deprecation warning of the requested model to the response.
+func (p *Plugin) PostHook(ctx *context.Context, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	if result == nil || (result.ExtraFields.ChunkIndex > 0 && !bifrost.IsFinalChunk(ctx)) {
+		return result, bifrostErr, nil
+	}
+
+	provider, _ := (*ctx).Value(schemas.BifrostContextKeyRequestProvider).(schemas.ModelProvider)
+	model, _ := (*ctx).Value(schemas.BifrostContextKeyRequestModel).(string)
+	if provider == "" {
+		provider = result.ExtraFields.Provider
+	}
+	if model == "" {
+		model = result.Model
+	}
+
+	warning := p.registry.Warning(provider, model, time.Now())
+	if warning == nil {
+		return result, bifrostErr, nil
+	}
+	result.ExtraFields.Deprecation = warning
+	p.logWarning(provider, warning)
+
+	return result, bifrostErr, nil
+}
+
+// logWarning logs a deprecation warning at most once per logInterval for each model.
+func (p *Plugin) logWarning(provider schemas.ModelProvider, warning *schemas.ModelDeprecation) {
+	key := makeKey(provider, warning.Model)
+	now := time.Now()
+
+	p.loggedMu.Lock()
+	last, ok := p.logged[key]
+	if ok && now.Sub(last) < logInterval {
+		p.loggedMu.Unlock()
+		return
+	}
+	p.logged[key] = now
+	p.loggedMu.Unlock()
+
+	p.logger.Warn("deprecated model in use (provider %s): %s", provider, warning.Message)
+}
+
+// Cleanup stops the registry sync.
+func (p *Plugin) Cleanup() error {
+	p.registry.Stop()
+	return nil
+}
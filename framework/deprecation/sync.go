@@ -0,0 +1,254 @@
+package deprecation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/statestore"
+)
+
+// sourceTimeout bounds each source request
+const sourceTimeout = 30 * time.Second
+
+// sourceItem is an item of a source response. Entry lists use the Entry fields, provider model
+// lists usually name the model "id" and the date one of the date fields.
+type sourceItem struct {
+	Provider        schemas.ModelProvider `json:"provider"`
+	Model           string                `json:"model"`
+	ID              string                `json:"id"`
+	ShutdownDate    string                `json:"shutdown_date"`
+	DeprecationDate string                `json:"deprecation_date"`
+	ExpirationDate  string                `json:"expiration_date"`
+	Replacement     string                `json:"replacement"`
+}
+
+// Sync fetches every source once and merges the entries found. Sources that fail are
+// reported in the returned error without preventing the others from being applied.
+func (r *Registry) Sync(ctx context.Context) error {
+	_, err := r.syncSources(ctx)
+	return err
+}
+
+// syncSources syncs every source and returns the entries applied.
+func (r *Registry) syncSources(ctx context.Context) ([]Entry, error) {
+	client := &http.Client{Timeout: sourceTimeout}
+
+	var synced []Entry
+	var errs []error
+	for _, source := range r.sources {
+		entries, err := fetchSource(ctx, client, source)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("deprecation source %s: %w", source.URL, err))
+			continue
+		}
+		if err := r.Update(entries); err != nil {
+			errs = append(errs, fmt.Errorf("deprecation source %s: %w", source.URL, err))
+			continue
+		}
+		r.logger.Debug("loaded %d deprecation entries from %s", len(entries), source.URL)
+		synced = append(synced, entries...)
+	}
+	return synced, errors.Join(errs...)
+}
+
+// StartSync syncs the sources now and then every sync interval until Stop is called.
+// It is a no-op without sources. With a state store, replicas check for entries published by
+// the leader every sharedPollInterval instead.
+func (r *Registry) StartSync(ctx context.Context) {
+	if len(r.sources) == 0 {
+		return
+	}
+
+	interval := r.syncInterval
+	if r.stateStore != nil {
+		interval = min(interval, sharedPollInterval)
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			syncOnce := r.Sync
+			if r.stateStore != nil {
+				syncOnce = r.syncShared
+			}
+			if err := syncOnce(ctx); err != nil {
+				r.logger.Warn("failed to sync deprecation registry: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sharedEntriesKey is the state store key of the entries published by the leader.
+const sharedEntriesKey = "deprecation:synced"
+
+// sharedPollInterval is how often replicas check the state store for published entries.
+const sharedPollInterval = time.Minute
+
+// sharedEntries are the synced entries published to the state store. Entries accumulate across
+// syncs, so a source failing once does not drop its entries from replicas starting later.
+type sharedEntries struct {
+	SyncedAt time.Time `json:"synced_at"`
+	Entries  []Entry   `json:"entries"`
+}
+
+// syncShared fetches the sources on the leader once the sync interval has elapsed since the last
+// publication, whichever replica made it, and publishes the result. The other replicas apply
+// publications they have not loaded yet.
+func (r *Registry) syncShared(ctx context.Context) error {
+	var published sharedEntries
+	data, err := r.stateStore.Get(ctx, sharedEntriesKey)
+	switch {
+	case errors.Is(err, statestore.ErrNotFound):
+	case err != nil:
+		return fmt.Errorf("failed to load published deprecation entries: %w", err)
+	default:
+		if err := json.Unmarshal(data, &published); err != nil {
+			return fmt.Errorf("failed to parse published deprecation entries: %w", err)
+		}
+	}
+
+	if (r.isLeader == nil || r.isLeader()) && time.Since(published.SyncedAt) >= r.syncInterval {
+		synced, syncErr := r.syncSources(ctx)
+		// Publish even if every source failed, so the sources are retried after the sync interval
+		// rather than at every poll
+		merged := make(map[string]Entry, len(published.Entries)+len(synced))
+		for _, entry := range append(published.Entries, synced...) {
+			merged[makeKey(entry.Provider, entry.Model)] = entry
+		}
+		publication := sharedEntries{SyncedAt: time.Now(), Entries: make([]Entry, 0, len(merged))}
+		for _, entry := range merged {
+			publication.Entries = append(publication.Entries, entry)
+		}
+		data, err := json.Marshal(publication)
+		if err == nil {
+			err = r.stateStore.Set(ctx, sharedEntriesKey, data, 0)
+		}
+		if err != nil {
+			return errors.Join(syncErr, fmt.Errorf("failed to publish deprecation entries: %w", err))
+		}
+		r.sharedLoadedAt = publication.SyncedAt
+		return syncErr
+	}
+
+	if !published.SyncedAt.After(r.sharedLoadedAt) {
+		return nil
+	}
+	if err := r.Update(published.Entries); err != nil {
+		return fmt.Errorf("invalid published deprecation entries: %w", err)
+	}
+	r.sharedLoadedAt = published.SyncedAt
+	return nil
+}
+
+// fetchSource downloads a source and converts its items to entries. Items without a date are skipped.
+func fetchSource(ctx context.Context, client *http.Client, source Source) ([]Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range source.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	items, err := parseSourceItems(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		entry, ok := item.toEntry(source.Provider)
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// parseSourceItems accepts a bare list or a {"data": [...]} / {"models": [...]} envelope.
+func parseSourceItems(data []byte) ([]sourceItem, error) {
+	var items []sourceItem
+	if err := json.Unmarshal(data, &items); err == nil {
+		return items, nil
+	}
+
+	var envelope struct {
+		Data   []sourceItem `json:"data"`
+		Models []sourceItem `json:"models"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return append(envelope.Data, envelope.Models...), nil
+}
+
+// toEntry converts an item to an entry, normalizing RFC 3339 timestamps to dates.
+func (item sourceItem) toEntry(defaultProvider schemas.ModelProvider) (Entry, bool) {
+	model := item.Model
+	if model == "" {
+		model = item.ID
+	}
+
+	date := item.ShutdownDate
+	if date == "" {
+		date = item.ExpirationDate
+	}
+	if date == "" {
+		date = item.DeprecationDate
+	}
+	if model == "" || date == "" {
+		return Entry{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		date = t.UTC().Format(dateLayout)
+	}
+
+	provider := item.Provider
+	if provider == "" {
+		provider = defaultProvider
+	}
+	// Model lists of aggregators name models "provider/model", keep only the model of the source's provider
+	if provider != "" {
+		model = strings.TrimPrefix(model, string(provider)+"/")
+	}
+
+	return Entry{
+		Provider:     provider,
+		Model:        model,
+		ShutdownDate: date,
+		Replacement:  item.Replacement,
+	}, true
+}
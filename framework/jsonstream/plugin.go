@@ -0,0 +1,163 @@
+package jsonstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	PluginName = "json-stream-validation"
+
+	// ErrorType is the type of the error ending streams whose output diverged from the schema
+	ErrorType = "json_schema_divergence"
+)
+
+// Config configures the validation plugin.
+type Config struct {
+	// Skip json_schema requests with strict set, whose output the provider already constrains
+	SkipStrict bool `json:"skip_strict,omitempty"`
+}
+
+// validationContextKey is the context key of the validators of a stream.
+type validationContextKey struct{}
+
+// streamValidators holds a validator per choice of a stream. Chunks of a stream are
+// processed sequentially, so it needs no locking.
+type streamValidators struct {
+	schema     *Schema
+	validators map[int]*Validator
+}
+
+// Plugin validates the output of chat streams requesting JSON (response_format json_schema
+// or json_object) while it is streamed. Once the output can no longer match the schema, the
+// stream ends with a json_schema_divergence error and the upstream request is cancelled.
+type Plugin struct {
+	config Config
+	logger schemas.Logger
+}
+
+// Init returns the validation plugin.
+func Init(config Config, logger schemas.Logger) *Plugin {
+	return &Plugin{config: config, logger: logger}
+}
+
+// GetName returns the name of the plugin.
+func (p *Plugin) GetName() string {
+	return PluginName
+}
+
+// PreHook prepares the validators of chat streams that request JSON output.
+func (p *Plugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	requestType, _ := (*ctx).Value(schemas.BifrostContextKeyRequestType).(schemas.RequestType)
+	if requestType != schemas.ChatCompletionStreamRequest || req.Params == nil {
+		return req, nil, nil
+	}
+
+	schema, err := p.responseSchema(req.Params.ExtraParams["response_format"])
+	if err != nil {
+		p.logger.Warn("skipping streamed JSON validation of %s: %v", req.Model, err)
+		return req, nil, nil
+	}
+	if schema != nil {
+		*ctx = context.WithValue(*ctx, validationContextKey{}, &streamValidators{
+			schema:     schema,
+			validators: make(map[int]*Validator),
+		})
+	}
+	return req, nil, nil
+}
+
+// responseSchema returns the schema the output of a response format must match, nil when
+// the format does not request JSON or is not validated.
+func (p *Plugin) responseSchema(responseFormat any) (*Schema, error) {
+	if responseFormat == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(responseFormat)
+	if err != nil {
+		return nil, err
+	}
+	var format struct {
+		Type       string `json:"type"`
+		JSONSchema *struct {
+			Schema json.RawMessage `json:"schema"`
+			Strict *bool           `json:"strict"`
+		} `json:"json_schema"`
+	}
+	if err := json.Unmarshal(data, &format); err != nil {
+		return nil, err
+	}
+
+	switch format.Type {
+	case "json_object":
+		return &Schema{Type: schemaTypes{string(typeObject)}}, nil
+	case "json_schema":
+		if format.JSONSchema == nil || len(format.JSONSchema.Schema) == 0 {
+			return nil, nil
+		}
+		if p.config.SkipStrict && format.JSONSchema.Strict != nil && *format.JSONSchema.Strict {
+			return nil, nil
+		}
+		return ParseSchema(format.JSONSchema.Schema)
+	}
+	return nil, nil
+}
+
+// PostHook feeds the content of each chunk to the validator of its choice, and ends the
+// stream with an error once the output diverges from the schema.
+func (p *Plugin) PostHook(ctx *context.Context, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	state, ok := (*ctx).Value(validationContextKey{}).(*streamValidators)
+	if !ok || result == nil || bifrostErr != nil {
+		return result, bifrostErr, nil
+	}
+
+	for _, choice := range result.Choices {
+		if choice.BifrostStreamResponseChoice == nil || choice.Delta.Content == nil {
+			continue
+		}
+
+		validator, ok := state.validators[choice.Index]
+		if !ok {
+			validator = NewValidator(state.schema)
+			state.validators[choice.Index] = validator
+		}
+
+		err := validator.Write(*choice.Delta.Content)
+		var divergence *DivergenceError
+		if !errors.As(err, &divergence) {
+			continue
+		}
+
+		p.logger.Debug("aborting stream of %s (choice %d): %v", result.Model, choice.Index, divergence)
+
+		// The error ends the stream for the plugins running after this one
+		*ctx = context.WithValue(*ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+		return nil, &schemas.BifrostError{
+			IsBifrostError: true,
+			Type:           bifrost.Ptr(ErrorType),
+			StatusCode:     bifrost.Ptr(422),
+			Origin:         schemas.ErrorOriginProvider,
+			Error: schemas.ErrorField{
+				Type:    bifrost.Ptr(ErrorType),
+				Message: divergence.Error(),
+				Error:   divergence,
+			},
+			AllowFallbacks: bifrost.Ptr(false),
+			StreamControl: &schemas.StreamControl{
+				AbortStream: bifrost.Ptr(true),
+			},
+		}, nil
+	}
+
+	return result, nil, nil
+}
+
+// Cleanup is a no-op, validators live in the request context.
+func (p *Plugin) Cleanup() error {
+	return nil
+}
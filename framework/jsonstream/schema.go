@@ -0,0 +1,264 @@
+package jsonstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema the validator checks while a value is streamed.
+// Keywords outside the subset (pattern, format, allOf, ...) are ignored, so they never
+// cause a stream to be aborted.
+type Schema struct {
+	Type                 schemaTypes        `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"` // nil allows any property
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Const                json.RawMessage    `json:"const,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+
+	// Boolean schemas: true accepts any value, false rejects every value
+	acceptNone bool
+}
+
+// schemaTypes is the "type" keyword, a single type name or a list of them.
+type schemaTypes []string
+
+// UnmarshalJSON accepts a type name or a list of type names.
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = schemaTypes{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("invalid schema type: %s", string(data))
+	}
+	*t = names
+	return nil
+}
+
+// UnmarshalJSON accepts boolean schemas besides schema objects.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch strings.TrimSpace(string(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{acceptNone: true}
+		return nil
+	}
+
+	type plain Schema
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*s = Schema(decoded)
+
+	// const is an enum of one value
+	if len(s.Const) > 0 {
+		var value any
+		if err := json.Unmarshal(s.Const, &value); err != nil {
+			return fmt.Errorf("invalid schema const: %w", err)
+		}
+		s.Enum = []any{value}
+	}
+	return nil
+}
+
+// ParseSchema decodes a JSON Schema from its JSON or decoded (map) form.
+func ParseSchema(schema any) (*Schema, error) {
+	var data []byte
+	switch v := schema.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case json.RawMessage:
+		data = v
+	default:
+		encoded, err := json.Marshal(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode schema: %w", err)
+		}
+		data = encoded
+	}
+
+	var parsed Schema
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return &parsed, nil
+}
+
+// jsonType is the type of a streamed value, named as in JSON Schema.
+type jsonType string
+
+const (
+	typeObject  jsonType = "object"
+	typeArray   jsonType = "array"
+	typeString  jsonType = "string"
+	typeNumber  jsonType = "number"
+	typeBoolean jsonType = "boolean"
+	typeNull    jsonType = "null"
+)
+
+// allowsType reports whether the schema accepts values of the type. Integers are numbers whose
+// integrality is only known once they are complete, so "integer" accepts numbers here.
+func (s *Schema) allowsType(t jsonType) bool {
+	if s.acceptNone {
+		return false
+	}
+	if len(s.Type) == 0 {
+		return true
+	}
+	for _, name := range s.Type {
+		if name == string(t) || (name == "integer" && t == typeNumber) {
+			return true
+		}
+	}
+	return false
+}
+
+// integerOnly reports whether the schema only accepts integral numbers.
+func (s *Schema) integerOnly() bool {
+	hasInteger := false
+	for _, name := range s.Type {
+		if name == "number" {
+			return false
+		}
+		if name == "integer" {
+			hasInteger = true
+		}
+	}
+	return hasInteger
+}
+
+// enumAllows reports whether the enum of the schema has a value of the type. Schemas without an
+// enum allow every type, enums of objects and arrays are not checked.
+func (s *Schema) enumAllows(t jsonType) bool {
+	if len(s.Enum) == 0 || t == typeObject || t == typeArray {
+		return true
+	}
+	for _, value := range s.Enum {
+		if valueType(value) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// property returns the schema of a property value, a rejecting schema when the property is not allowed.
+func (s *Schema) property(name string) *Schema {
+	if child, ok := s.Properties[name]; ok {
+		return child
+	}
+	if s.AdditionalProperties != nil {
+		return s.AdditionalProperties
+	}
+	return anySchema
+}
+
+// allowsPropertyPrefix reports whether some property allowed by the schema starts with prefix.
+func (s *Schema) allowsPropertyPrefix(prefix string) bool {
+	if s.AdditionalProperties == nil || !s.AdditionalProperties.acceptNone {
+		return true
+	}
+	for name := range s.Properties {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// anySchema accepts every value.
+var anySchema = &Schema{}
+
+// valueType returns the JSON type of a decoded value.
+func valueType(value any) jsonType {
+	switch value.(type) {
+	case map[string]any:
+		return typeObject
+	case []any:
+		return typeArray
+	case string:
+		return typeString
+	case float64:
+		return typeNumber
+	case bool:
+		return typeBoolean
+	}
+	return typeNull
+}
+
+// resolver expands references and alternatives of the schemas of a root schema.
+type resolver struct {
+	root *Schema
+}
+
+// maxRefDepth bounds reference chains, protecting against references that point to themselves
+const maxRefDepth = 32
+
+// expand returns the schemas a value must match one of: references are resolved and anyOf/oneOf
+// are replaced by their alternatives. Unresolvable references accept any value.
+func (r *resolver) expand(schemas []*Schema) []*Schema {
+	expanded := make([]*Schema, 0, len(schemas))
+	for _, schema := range schemas {
+		expanded = r.appendExpanded(expanded, schema, 0)
+	}
+	return expanded
+}
+
+func (r *resolver) appendExpanded(expanded []*Schema, schema *Schema, depth int) []*Schema {
+	if schema == nil || depth > maxRefDepth {
+		return append(expanded, anySchema)
+	}
+	if schema.Ref != "" {
+		return r.appendExpanded(expanded, r.resolve(schema.Ref), depth+1)
+	}
+
+	// The keywords next to anyOf/oneOf are not checked, which only makes validation more lenient
+	alternatives := schema.AnyOf
+	if len(alternatives) == 0 {
+		alternatives = schema.OneOf
+	}
+	if len(alternatives) > 0 {
+		for _, alternative := range alternatives {
+			expanded = r.appendExpanded(expanded, alternative, depth+1)
+		}
+		return expanded
+	}
+
+	return append(expanded, schema)
+}
+
+// resolve returns the schema of a local reference, nil if it cannot be resolved.
+func (r *resolver) resolve(ref string) *Schema {
+	if ref == "#" {
+		return r.root
+	}
+	if name, ok := strings.CutPrefix(ref, "#/$defs/"); ok {
+		return r.root.Defs[name]
+	}
+	if name, ok := strings.CutPrefix(ref, "#/definitions/"); ok {
+		return r.root.Definitions[name]
+	}
+	return nil
+}
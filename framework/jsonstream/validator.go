@@ -0,0 +1,631 @@
+// Package jsonstream validates JSON output against a JSON Schema while it is streamed, and provides a
+// plugin that ends chat streams as soon as their output can no longer match the requested schema.
+package jsonstream
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// DivergenceError reports streamed output that can no longer become a value matching the schema,
+// whatever is streamed next.
+type DivergenceError struct {
+	Path   string // Path of the offending value, e.g. $.items[2].name
+	Reason string
+	Offset int // Byte offset of the output at which the divergence was detected
+}
+
+// Error implements the error interface.
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("output diverged from the schema at %s (offset %d): %s", e.Path, e.Offset, e.Reason)
+}
+
+// Validator checks JSON output against a schema while it is streamed. It keeps only the
+// parsing state and the schemas the value being streamed may still match, so its memory
+// does not grow with the output, except for strings compared against enums and property names.
+// A Validator is not safe for concurrent use.
+type Validator struct {
+	resolver resolver
+	root     []*Schema
+
+	stack  []*container
+	scalar *scalar
+	done   bool // A complete top-level value was parsed
+	offset int
+	err    *DivergenceError
+
+	// Set when the divergence is in the innermost container itself rather than in its current member
+	containerError bool
+}
+
+// containerState is the parsing state of an object or array.
+type containerState int
+
+const (
+	stateFirst       containerState = iota // After the opening bracket: first member or end
+	stateNext                              // After a comma: next member
+	stateColon                             // Object only: after a property name
+	stateMemberValue                       // Object only: after the colon
+	stateCommaOrEnd                        // After a member
+)
+
+// container is an object or array being streamed.
+type container struct {
+	kind    jsonType
+	schemas []*Schema
+	state   containerState
+	key     string              // Objects: name of the current property
+	keys    map[string]struct{} // Objects: names of the properties seen
+	items   int                 // Arrays: number of items started
+}
+
+// scalar is a string, number or literal being streamed.
+type scalar struct {
+	kind    jsonType
+	isKey   bool
+	schemas []*Schema
+
+	value   strings.Builder // Decoded string (when needed), raw number or literal
+	keep    bool            // Strings: whether the decoded value is needed
+	bounded bool            // Strings: whether a schema limits the length or values
+	length  int             // Strings: length in code points
+
+	// String escapes
+	escaped       bool
+	hexDigits     int
+	hexValue      rune
+	highSurrogate rune
+
+	literal string // Literals: expected text, "true", "false" or "null"
+}
+
+// NewValidator returns a validator of output matching schema.
+func NewValidator(schema *Schema) *Validator {
+	return &Validator{
+		resolver: resolver{root: schema},
+		root:     []*Schema{schema},
+	}
+}
+
+// Write feeds the next part of the output. It returns a *DivergenceError once the output
+// can no longer match the schema, and the same error on every later call.
+func (v *Validator) Write(chunk string) error {
+	if v.err != nil {
+		return v.err
+	}
+	for i := 0; i < len(chunk); i++ {
+		if reason := v.step(chunk[i]); reason != "" {
+			v.err = &DivergenceError{Path: v.path(), Reason: reason, Offset: v.offset}
+			return v.err
+		}
+		v.offset++
+	}
+	return nil
+}
+
+// Complete reports whether a whole top-level value was streamed.
+func (v *Validator) Complete() bool {
+	return v.done
+}
+
+// step processes one byte of output and returns the reason of a divergence, if any.
+func (v *Validator) step(c byte) string {
+	if v.scalar != nil {
+		finished, reprocess, reason := v.scalarStep(c)
+		if reason != "" {
+			return reason
+		}
+		if finished {
+			if reason := v.finishScalar(); reason != "" {
+				return reason
+			}
+		}
+		if !reprocess {
+			return ""
+		}
+	}
+
+	if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+		return ""
+	}
+
+	if len(v.stack) == 0 {
+		if v.done {
+			return "unexpected content after the JSON value"
+		}
+		return v.startValue(c, v.resolver.expand(v.root))
+	}
+
+	top := v.stack[len(v.stack)-1]
+	switch top.state {
+	case stateFirst, stateNext:
+		if top.kind == typeObject {
+			if c == '}' && top.state == stateFirst {
+				return v.closeContainer()
+			}
+			if c != '"' {
+				return "expected a property name"
+			}
+			v.scalar = &scalar{kind: typeString, isKey: true, keep: true, bounded: true}
+			return ""
+		}
+		if c == ']' && top.state == stateFirst {
+			return v.closeContainer()
+		}
+		return v.startItem(top, c)
+
+	case stateColon:
+		if c != ':' {
+			return "expected ':' after the property name"
+		}
+		top.state = stateMemberValue
+		return ""
+
+	case stateMemberValue:
+		children := make([]*Schema, 0, len(top.schemas))
+		for _, schema := range top.schemas {
+			children = append(children, schema.property(top.key))
+		}
+		top.state = stateCommaOrEnd
+		return v.startValue(c, v.resolver.expand(children))
+
+	default: // stateCommaOrEnd
+		switch {
+		case c == ',':
+			top.state = stateNext
+			return ""
+		case c == '}' && top.kind == typeObject, c == ']' && top.kind == typeArray:
+			return v.closeContainer()
+		}
+		v.containerError = true
+		if top.kind == typeObject {
+			return "expected ',' or '}'"
+		}
+		return "expected ',' or ']'"
+	}
+}
+
+// startItem starts the next item of an array.
+func (v *Validator) startItem(array *container, c byte) string {
+	array.items++
+	schemas, reason := filter(array.schemas, func(s *Schema) string {
+		if s.MaxItems != nil && array.items > *s.MaxItems {
+			return fmt.Sprintf("expected at most %d items", *s.MaxItems)
+		}
+		return ""
+	})
+	if reason != "" {
+		return reason
+	}
+	array.schemas = schemas
+
+	children := make([]*Schema, 0, len(schemas))
+	for _, schema := range schemas {
+		children = append(children, schema.Items)
+	}
+	array.state = stateCommaOrEnd
+	return v.startValue(c, v.resolver.expand(children))
+}
+
+// startValue starts a value from its first byte, keeping the schemas that accept its type.
+func (v *Validator) startValue(c byte, schemas []*Schema) string {
+	var t jsonType
+	switch {
+	case c == '{':
+		t = typeObject
+	case c == '[':
+		t = typeArray
+	case c == '"':
+		t = typeString
+	case c == '-' || (c >= '0' && c <= '9'):
+		t = typeNumber
+	case c == 't' || c == 'f':
+		t = typeBoolean
+	case c == 'n':
+		t = typeNull
+	default:
+		return fmt.Sprintf("unexpected character %q", c)
+	}
+
+	schemas, reason := filter(schemas, func(s *Schema) string {
+		if !s.allowsType(t) {
+			if s.acceptNone {
+				return "no value is allowed"
+			}
+			return fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), t)
+		}
+		if !s.enumAllows(t) {
+			return "value is not one of the allowed values"
+		}
+		return ""
+	})
+	if reason != "" {
+		return reason
+	}
+
+	switch t {
+	case typeObject:
+		v.stack = append(v.stack, &container{kind: t, schemas: schemas, keys: make(map[string]struct{})})
+	case typeArray:
+		v.stack = append(v.stack, &container{kind: t, schemas: schemas})
+	case typeString:
+		v.scalar = &scalar{kind: t, schemas: schemas}
+		for _, schema := range schemas {
+			if len(schema.Enum) > 0 {
+				v.scalar.keep = true
+			}
+			if len(schema.Enum) > 0 || schema.MaxLength != nil {
+				v.scalar.bounded = true
+			}
+		}
+	case typeNumber:
+		v.scalar = &scalar{kind: t, schemas: schemas}
+		v.scalar.value.WriteByte(c)
+	default:
+		literal := "null"
+		if c == 't' {
+			literal = "true"
+		} else if c == 'f' {
+			literal = "false"
+		}
+		v.scalar = &scalar{kind: t, schemas: schemas, literal: literal}
+		v.scalar.value.WriteByte(c)
+	}
+	return ""
+}
+
+// scalarStep processes a byte of the current scalar. It reports whether the scalar is
+// finished and whether the byte, which ended a number, must be processed again.
+func (v *Validator) scalarStep(c byte) (finished bool, reprocess bool, reason string) {
+	s := v.scalar
+	switch s.kind {
+	case typeString:
+		return v.stringStep(c)
+
+	case typeNumber:
+		if (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E' {
+			s.value.WriteByte(c)
+			return false, false, ""
+		}
+		return true, true, ""
+
+	default:
+		n := s.value.Len()
+		if c != s.literal[n] {
+			return false, false, fmt.Sprintf("invalid literal, expected %s", s.literal)
+		}
+		s.value.WriteByte(c)
+		return n+1 == len(s.literal), false, ""
+	}
+}
+
+// stringStep processes a byte of the current string, decoding escapes.
+func (v *Validator) stringStep(c byte) (finished bool, reprocess bool, reason string) {
+	s := v.scalar
+
+	if s.hexDigits > 0 {
+		digit, ok := hexDigit(c)
+		if !ok {
+			return false, false, "invalid unicode escape"
+		}
+		s.hexValue = s.hexValue<<4 | digit
+		s.hexDigits--
+		if s.hexDigits > 0 {
+			return false, false, ""
+		}
+		r := s.hexValue
+		switch {
+		case utf16.IsSurrogate(r) && r < 0xDC00:
+			s.highSurrogate = r
+			return false, false, ""
+		case utf16.IsSurrogate(r) && s.highSurrogate != 0:
+			r = utf16.DecodeRune(s.highSurrogate, r)
+		case utf16.IsSurrogate(r):
+			r = utf8.RuneError
+		}
+		s.highSurrogate = 0
+		return false, false, v.appendRune(r)
+	}
+
+	if s.escaped {
+		s.escaped = false
+		switch c {
+		case 'u':
+			s.hexDigits = 4
+			s.hexValue = 0
+			return false, false, ""
+		case '"', '\\', '/':
+			return false, false, v.appendRune(rune(c))
+		case 'b':
+			return false, false, v.appendRune('\b')
+		case 'f':
+			return false, false, v.appendRune('\f')
+		case 'n':
+			return false, false, v.appendRune('\n')
+		case 'r':
+			return false, false, v.appendRune('\r')
+		case 't':
+			return false, false, v.appendRune('\t')
+		}
+		return false, false, fmt.Sprintf("invalid escape sequence \\%c", c)
+	}
+
+	switch {
+	case c == '\\':
+		s.escaped = true
+		return false, false, ""
+	case c == '"':
+		return true, false, ""
+	case c < 0x20:
+		return false, false, "control character in string"
+	}
+
+	if s.keep {
+		s.value.WriteByte(c)
+	}
+	// Continuation bytes belong to the code point already counted
+	if c&0xC0 != 0x80 {
+		s.length++
+	}
+	return false, false, v.checkStringPrefix()
+}
+
+// appendRune adds a decoded escape to the current string.
+func (v *Validator) appendRune(r rune) string {
+	s := v.scalar
+	if s.keep {
+		s.value.WriteRune(r)
+	}
+	s.length++
+	return v.checkStringPrefix()
+}
+
+// checkStringPrefix checks the string streamed so far: property names must start an allowed
+// property, values must fit the maximum length and start an enum value.
+func (v *Validator) checkStringPrefix() string {
+	s := v.scalar
+	if !s.bounded {
+		return ""
+	}
+	if s.isKey {
+		object := v.stack[len(v.stack)-1]
+		prefix := s.value.String()
+		schemas, reason := filter(object.schemas, func(schema *Schema) string {
+			if !schema.allowsPropertyPrefix(prefix) {
+				return fmt.Sprintf("property %q is not allowed", prefix)
+			}
+			return ""
+		})
+		if reason != "" {
+			return reason
+		}
+		object.schemas = schemas
+		return ""
+	}
+
+	schemas, reason := filter(s.schemas, func(schema *Schema) string {
+		if schema.MaxLength != nil && s.length > *schema.MaxLength {
+			return fmt.Sprintf("string longer than %d characters", *schema.MaxLength)
+		}
+		if len(schema.Enum) > 0 && !enumHasPrefix(schema.Enum, s.value.String()) {
+			return "value is not one of the allowed values"
+		}
+		return ""
+	})
+	if reason != "" {
+		return reason
+	}
+	s.schemas = schemas
+	return ""
+}
+
+// finishScalar validates the completed scalar against its schemas.
+func (v *Validator) finishScalar() string {
+	s := v.scalar
+	v.scalar = nil
+
+	if s.isKey {
+		object := v.stack[len(v.stack)-1]
+		name := s.value.String()
+		schemas, reason := filter(object.schemas, func(schema *Schema) string {
+			if schema.property(name).acceptNone {
+				return fmt.Sprintf("property %q is not allowed", name)
+			}
+			return ""
+		})
+		if reason != "" {
+			return reason
+		}
+		object.schemas = schemas
+		object.key = name
+		object.keys[name] = struct{}{}
+		object.state = stateColon
+		return ""
+	}
+
+	var check func(schema *Schema) string
+	switch s.kind {
+	case typeString:
+		value := s.value.String()
+		check = func(schema *Schema) string {
+			if schema.MinLength != nil && s.length < *schema.MinLength {
+				return fmt.Sprintf("string shorter than %d characters", *schema.MinLength)
+			}
+			if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+				return "value is not one of the allowed values"
+			}
+			return ""
+		}
+
+	case typeNumber:
+		raw := s.value.String()
+		if !numberPattern.MatchString(raw) {
+			return fmt.Sprintf("invalid number %s", raw)
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid number %s", raw)
+		}
+		check = func(schema *Schema) string {
+			return checkNumber(schema, value)
+		}
+
+	case typeBoolean:
+		value := s.literal == "true"
+		check = func(schema *Schema) string {
+			if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+				return "value is not one of the allowed values"
+			}
+			return ""
+		}
+
+	default:
+		// null passed the type and enum checks when it started
+		check = func(*Schema) string { return "" }
+	}
+
+	if _, reason := filter(s.schemas, check); reason != "" {
+		return reason
+	}
+	v.valueDone()
+	return ""
+}
+
+// checkNumber checks a complete number against a schema.
+func checkNumber(schema *Schema, value float64) string {
+	if schema.integerOnly() && value != math.Trunc(value) {
+		return "expected integer, got number"
+	}
+	if schema.Minimum != nil && value < *schema.Minimum {
+		return fmt.Sprintf("number less than the minimum %v", *schema.Minimum)
+	}
+	if schema.Maximum != nil && value > *schema.Maximum {
+		return fmt.Sprintf("number greater than the maximum %v", *schema.Maximum)
+	}
+	if schema.ExclusiveMinimum != nil && value <= *schema.ExclusiveMinimum {
+		return fmt.Sprintf("number not greater than the exclusive minimum %v", *schema.ExclusiveMinimum)
+	}
+	if schema.ExclusiveMaximum != nil && value >= *schema.ExclusiveMaximum {
+		return fmt.Sprintf("number not less than the exclusive maximum %v", *schema.ExclusiveMaximum)
+	}
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		return "value is not one of the allowed values"
+	}
+	return ""
+}
+
+// closeContainer validates and pops the current object or array.
+func (v *Validator) closeContainer() string {
+	top := v.stack[len(v.stack)-1]
+
+	var check func(schema *Schema) string
+	if top.kind == typeObject {
+		check = func(schema *Schema) string {
+			for _, name := range schema.Required {
+				if _, ok := top.keys[name]; !ok {
+					return fmt.Sprintf("missing required property %q", name)
+				}
+			}
+			return ""
+		}
+	} else {
+		check = func(schema *Schema) string {
+			if schema.MinItems != nil && top.items < *schema.MinItems {
+				return fmt.Sprintf("expected at least %d items", *schema.MinItems)
+			}
+			return ""
+		}
+	}
+	if _, reason := filter(top.schemas, check); reason != "" {
+		v.containerError = true
+		return reason
+	}
+
+	v.stack = v.stack[:len(v.stack)-1]
+	v.valueDone()
+	return ""
+}
+
+// valueDone records the end of a value; the container holding it is already past it.
+func (v *Validator) valueDone() {
+	if len(v.stack) == 0 {
+		v.done = true
+	}
+}
+
+// path returns the path of the value being streamed.
+func (v *Validator) path() string {
+	var path strings.Builder
+	path.WriteString("$")
+	for i, c := range v.stack {
+		// Containers are past their current member once it has started
+		inMember := c.state == stateCommaOrEnd && (i < len(v.stack)-1 || !v.containerError)
+		if !inMember {
+			continue
+		}
+		if c.kind == typeObject {
+			path.WriteString("." + c.key)
+		} else {
+			path.WriteString(fmt.Sprintf("[%d]", c.items-1))
+		}
+	}
+	return path.String()
+}
+
+// filter keeps the schemas check accepts. When it rejects all of them, it returns the reason
+// of the first rejection.
+func filter(schemas []*Schema, check func(*Schema) string) ([]*Schema, string) {
+	kept := schemas[:0]
+	var reason string
+	for _, schema := range schemas {
+		if r := check(schema); r != "" {
+			if reason == "" {
+				reason = r
+			}
+			continue
+		}
+		kept = append(kept, schema)
+	}
+	if len(kept) == 0 {
+		return nil, reason
+	}
+	return kept, ""
+}
+
+// numberPattern is the JSON number grammar
+var numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+func hexDigit(c byte) (rune, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return rune(c - '0'), true
+	case c >= 'a' && c <= 'f':
+		return rune(c-'a') + 10, true
+	case c >= 'A' && c <= 'F':
+		return rune(c-'A') + 10, true
+	}
+	return 0, false
+}
+
+func enumHasPrefix(enum []any, prefix string) bool {
+	for _, value := range enum {
+		if str, ok := value.(string); ok && strings.HasPrefix(str, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, candidate := range enum {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package jsonstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "maxLength": 10},
+		"status": {"enum": ["active", "inactive"]},
+		"age": {"type": "integer", "minimum": 0},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+		"address": {"$ref": "#/$defs/address"},
+		"contact": {"anyOf": [{"type": "string"}, {"$ref": "#/$defs/address"}]}
+	},
+	"required": ["name"],
+	"additionalProperties": false,
+	"$defs": {
+		"address": {
+			"type": "object",
+			"properties": {"city": {"type": "string"}},
+			"required": ["city"],
+			"additionalProperties": false
+		}
+	}
+}`
+
+// stream writes the output a few bytes at a time, as a provider would stream it.
+func stream(t *testing.T, output string) (*Validator, error) {
+	t.Helper()
+	schema, err := ParseSchema(testSchema)
+	require.NoError(t, err)
+
+	validator := NewValidator(schema)
+	for i := 0; i < len(output); i += 3 {
+		end := min(i+3, len(output))
+		if err := validator.Write(output[i:end]); err != nil {
+			return validator, err
+		}
+	}
+	return validator, nil
+}
+
+func TestValidatorAcceptsMatchingOutput(t *testing.T) {
+	outputs := []string{
+		`{"name": "Ada", "status": "active", "age": 36, "tags": ["math", "code"]}`,
+		`{"name":"Löwe \"Leo\"","address":{"city":"Paris"},"contact":{"city":"Rome"}}`,
+		`{"name": "Ada", "contact": "ada@example.com", "age": 3.0e1}` + "\n",
+	}
+	for _, output := range outputs {
+		validator, err := stream(t, output)
+		assert.NoError(t, err, output)
+		assert.True(t, validator.Complete(), output)
+	}
+}
+
+func TestValidatorAcceptsIncompleteOutput(t *testing.T) {
+	validator, err := stream(t, `{"name": "Ada", "stat`)
+	assert.NoError(t, err)
+	assert.False(t, validator.Complete())
+}
+
+func TestValidatorDetectsDivergence(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		path   string
+		reason string
+	}{
+		{"unknown property", `{"name": "Ada", "nickname": "A"}`, "$", `property "ni" is not allowed`},
+		{"wrong type", `{"name": 42}`, "$.name", "expected string, got number"},
+		{"string too long", `{"name": "Ada Lovelace King"}`, "$.name", "string longer than 10 characters"},
+		{"enum prefix", `{"name": "Ada", "status": "acti`, "$.status", ""},
+		{"enum divergence", `{"name": "Ada", "status": "pend`, "$.status", "value is not one of the allowed values"},
+		{"enum mismatch", `{"name": "Ada", "status": "activ"}`, "$.status", "value is not one of the allowed values"},
+		{"integer", `{"name": "Ada", "age": 36.5}`, "$.age", "expected integer, got number"},
+		{"minimum", `{"name": "Ada", "age": -1}`, "$.age", "number less than the minimum 0"},
+		{"max items", `{"name": "Ada", "tags": ["a", "b", "c"]}`, "$.tags", "expected at most 2 items"},
+		{"required", `{"status": "active"}`, "$", `missing required property "name"`},
+		{"nested ref", `{"name": "Ada", "address": {"city": 1}}`, "$.address.city", "expected string, got number"},
+		{"any of", `{"name": "Ada", "contact": 7}`, "$.contact", "expected string, got number"},
+		{"trailing content", `{"name": "Ada"} more`, "$", "unexpected content after the JSON value"},
+		{"fenced output", "```json\n{}", "$", "unexpected character '`'"},
+		{"syntax", `{"name": "Ada" "age": 1}`, "$", "expected ',' or '}'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := stream(t, test.output)
+			if test.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var divergence *DivergenceError
+			require.ErrorAs(t, err, &divergence)
+			assert.Equal(t, test.path, divergence.Path)
+			assert.Equal(t, test.reason, divergence.Reason)
+		})
+	}
+}
+
+func TestValidatorKeepsReturningDivergence(t *testing.T) {
+	validator, err := stream(t, `{"name": 1`)
+	require.Error(t, err)
+	assert.Equal(t, err, validator.Write(`}`))
+}
@@ -0,0 +1,114 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// requestTimeout bounds each call to a remote KMS.
+const requestTimeout = 30 * time.Second
+
+// AWSConfig configures the AWS KMS provider. Credentials come from the default AWS chain:
+// environment variables, shared config files, or the instance or task role.
+type AWSConfig struct {
+	Region   string `json:"region"`             // Region of the keys - REQUIRED
+	Endpoint string `json:"endpoint,omitempty"` // KMS endpoint, https://kms.<region>.amazonaws.com if empty
+}
+
+// awsKMS calls the KMS Decrypt API. Ciphertexts are the base64 CiphertextBlob returned by Encrypt,
+// e.g. from `aws kms encrypt --output text --query CiphertextBlob`.
+type awsKMS struct {
+	region      string
+	endpoint    string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+func newAWSKMS(ctx context.Context, awsConfig AWSConfig) (*awsKMS, error) {
+	if awsConfig.Region == "" {
+		return nil, fmt.Errorf("aws kms region is required")
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(awsConfig.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	endpoint := awsConfig.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com", awsConfig.Region)
+	}
+	return &awsKMS{
+		region:      awsConfig.Region,
+		endpoint:    strings.TrimSuffix(endpoint, "/") + "/",
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+func (k *awsKMS) Decrypt(ctx context.Context, ciphertext string) (string, error) {
+	body, err := json.Marshal(map[string]string{"CiphertextBlob": ciphertext})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService.Decrypt")
+
+	creds, err := k.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve aws credentials: %w", err)
+	}
+	bodyHash := sha256.Sum256(body)
+	if err := k.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(bodyHash[:]), "kms", k.region, time.Now()); err != nil {
+		return "", fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		return "", fmt.Errorf("aws kms returned status %d: %s %s", resp.StatusCode, apiErr.Type, apiErr.Message)
+	}
+
+	var result struct {
+		Plaintext string `json:"Plaintext"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("failed to parse aws kms response: %w", err)
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(result.Plaintext)
+	if err != nil {
+		return "", fmt.Errorf("aws kms plaintext is not valid base64: %w", err)
+	}
+	return string(plaintext), nil
+}
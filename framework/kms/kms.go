@@ -0,0 +1,188 @@
+// Package kms decrypts configuration values encrypted with a key management service, so secrets
+// kept in configuration files are never stored in plaintext. Encrypted values have the form
+// "enc:<provider>:<ciphertext>", e.g. "enc:aws:AQICAHh...", and are decrypted when the
+// configuration is loaded.
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// Prefix marks an encrypted configuration value.
+const Prefix = "enc:"
+
+// Names of the built-in providers.
+const (
+	ProviderLocal = "local"
+	ProviderAWS   = "aws"
+	ProviderVault = "vault"
+)
+
+// KMS decrypts ciphertexts produced by a key management service.
+type KMS interface {
+	// Decrypt returns the plaintext of a ciphertext, the part of the value after "enc:<provider>:".
+	Decrypt(ctx context.Context, ciphertext string) (string, error)
+}
+
+// Config configures the built-in providers. Providers without a config are unavailable, except
+// local, which is available whenever its key environment variable is set.
+type Config struct {
+	Local *LocalConfig `json:"local,omitempty"`
+	AWS   *AWSConfig   `json:"aws,omitempty"`
+	Vault *VaultConfig `json:"vault,omitempty"`
+}
+
+// Resolver decrypts encrypted values with the provider each value names. It is safe for
+// concurrent use.
+type Resolver struct {
+	mu        sync.RWMutex
+	providers map[string]KMS
+	logger    schemas.Logger
+}
+
+// NewResolver creates a resolver with the built-in providers enabled by config. config may be nil.
+func NewResolver(ctx context.Context, config *Config, logger schemas.Logger) (*Resolver, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	r := &Resolver{providers: make(map[string]KMS), logger: logger}
+
+	local, err := newLocalKMS(config.Local)
+	if err != nil {
+		return nil, err
+	}
+	if local != nil {
+		r.providers[ProviderLocal] = local
+	}
+	if config.AWS != nil {
+		aws, err := newAWSKMS(ctx, *config.AWS)
+		if err != nil {
+			return nil, err
+		}
+		r.providers[ProviderAWS] = aws
+	}
+	if config.Vault != nil {
+		vault, err := newVaultKMS(*config.Vault)
+		if err != nil {
+			return nil, err
+		}
+		r.providers[ProviderVault] = vault
+	}
+
+	return r, nil
+}
+
+// Register adds a provider, or replaces one with the same name, e.g. to support a KMS without a
+// built-in implementation.
+func (r *Resolver) Register(name string, kms KMS) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[name] = kms
+}
+
+// IsEncrypted reports whether a value is encrypted.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), Prefix)
+}
+
+// Decrypt returns the plaintext of an encrypted value. Values that are not encrypted are returned
+// unchanged.
+func (r *Resolver) Decrypt(ctx context.Context, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	rest := strings.TrimPrefix(strings.TrimSpace(value), Prefix)
+	name, ciphertext, ok := strings.Cut(rest, ":")
+	if !ok || name == "" || ciphertext == "" {
+		return "", fmt.Errorf("invalid encrypted value, expected %s<provider>:<ciphertext>", Prefix)
+	}
+
+	r.mu.RLock()
+	provider, ok := r.providers[name]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("kms provider %q is not configured", name)
+	}
+
+	plaintext, err := provider.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value with kms provider %s: %w", name, err)
+	}
+	return plaintext, nil
+}
+
+// DecryptJSON decrypts every encrypted string value of a JSON document. Documents without
+// encrypted values are returned unchanged; others are re-encoded with their numbers preserved.
+func (r *Resolver) DecryptJSON(ctx context.Context, data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte(`"`+Prefix)) {
+		return data, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	count := 0
+	document, err := r.decryptNode(ctx, document, "", &count)
+	if err != nil {
+		return nil, err
+	}
+	if r.logger != nil {
+		r.logger.Debug("decrypted %d encrypted configuration values", count)
+	}
+	return json.Marshal(document)
+}
+
+// decryptNode decrypts the encrypted strings under a JSON node. path locates the node in errors.
+func (r *Resolver) decryptNode(ctx context.Context, node any, path string, count *int) (any, error) {
+	switch v := node.(type) {
+	case string:
+		if !IsEncrypted(v) {
+			return v, nil
+		}
+		plaintext, err := r.Decrypt(ctx, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		*count++
+		return plaintext, nil
+	case map[string]any:
+		for key, value := range v {
+			decrypted, err := r.decryptNode(ctx, value, joinPath(path, key), count)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = decrypted
+		}
+		return v, nil
+	case []any:
+		for i, value := range v {
+			decrypted, err := r.decryptNode(ctx, value, fmt.Sprintf("%s[%d]", path, i), count)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = decrypted
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,118 @@
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLocalResolver(t *testing.T) ([]byte, *Resolver) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	t.Setenv("TEST_KMS_KEY", base64.StdEncoding.EncodeToString(key))
+
+	r, err := NewResolver(context.Background(), &Config{Local: &LocalConfig{KeyEnv: "TEST_KMS_KEY"}}, nil)
+	require.NoError(t, err)
+	return key, r
+}
+
+func TestLocalRoundTrip(t *testing.T) {
+	key, r := newLocalResolver(t)
+
+	value, err := EncryptLocal(key, "sk-secret")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(value))
+
+	plaintext, err := r.Decrypt(context.Background(), value)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-secret", plaintext)
+
+	// Plain values pass through, unknown providers and tampered ciphertexts fail
+	plaintext, err = r.Decrypt(context.Background(), "env.OPENAI_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "env.OPENAI_API_KEY", plaintext)
+
+	_, err = r.Decrypt(context.Background(), "enc:gcp:abc")
+	assert.Error(t, err)
+
+	other := make([]byte, 32)
+	value, err = EncryptLocal(other, "sk-secret")
+	require.NoError(t, err)
+	_, err = r.Decrypt(context.Background(), value)
+	assert.Error(t, err)
+}
+
+func TestDecryptJSON(t *testing.T) {
+	key, r := newLocalResolver(t)
+
+	value, err := EncryptLocal(key, "sk-secret")
+	require.NoError(t, err)
+	data := []byte(`{"providers":{"openai":{"keys":[{"value":"` + value + `","weight":1.0,"models":["gpt-4o"]}]}},"port":8080}`)
+
+	decrypted, err := r.DecryptJSON(context.Background(), data)
+	require.NoError(t, err)
+
+	var document struct {
+		Providers map[string]struct {
+			Keys []struct {
+				Value  string      `json:"value"`
+				Weight json.Number `json:"weight"`
+			} `json:"keys"`
+		} `json:"providers"`
+		Port int `json:"port"`
+	}
+	require.NoError(t, json.Unmarshal(decrypted, &document))
+	assert.Equal(t, "sk-secret", document.Providers["openai"].Keys[0].Value)
+	assert.Equal(t, "1.0", document.Providers["openai"].Keys[0].Weight.String())
+	assert.Equal(t, 8080, document.Port)
+
+	plain := []byte(`{"port": 8080}`)
+	unchanged, err := r.DecryptJSON(context.Background(), plain)
+	require.NoError(t, err)
+	assert.Equal(t, plain, unchanged)
+
+	_, err = r.DecryptJSON(context.Background(), []byte(`{"providers":{"openai":{"keys":[{"value":"enc:aws:abc"}]}}}`))
+	assert.ErrorContains(t, err, "providers.openai.keys[0].value")
+}
+
+func TestVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/v1/transit/decrypt/bifrost", req.URL.Path)
+		assert.Equal(t, "vault-token", req.Header.Get("X-Vault-Token"))
+
+		var body struct {
+			Ciphertext string `json:"ciphertext"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		if body.Ciphertext != "vault:v1:abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["invalid ciphertext"]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"plaintext":"` + base64.StdEncoding.EncodeToString([]byte("sk-vault")) + `"}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_VAULT_TOKEN", "vault-token")
+	r, err := NewResolver(context.Background(), &Config{Vault: &VaultConfig{
+		Address:  server.URL,
+		KeyName:  "bifrost",
+		TokenEnv: "TEST_VAULT_TOKEN",
+	}}, nil)
+	require.NoError(t, err)
+
+	plaintext, err := r.Decrypt(context.Background(), "enc:vault:vault:v1:abc")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-vault", plaintext)
+
+	_, err = r.Decrypt(context.Background(), "enc:vault:vault:v1:wrong")
+	assert.ErrorContains(t, err, "invalid ciphertext")
+}
@@ -0,0 +1,93 @@
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// DefaultLocalKeyEnv is the environment variable holding the key of the local provider.
+const DefaultLocalKeyEnv = "BIFROST_ENCRYPTION_KEY"
+
+// LocalConfig configures the local provider, which decrypts AES-256-GCM ciphertexts with a key
+// kept outside the configuration, in an environment variable.
+type LocalConfig struct {
+	KeyEnv string `json:"key_env,omitempty"` // Environment variable holding the base64 encoded 32 byte key, DefaultLocalKeyEnv if empty
+}
+
+// localKMS decrypts base64 encoded nonce||ciphertext values sealed with AES-256-GCM.
+type localKMS struct {
+	aead cipher.AEAD
+}
+
+// newLocalKMS returns the local provider, or nil if it is not configured and its key is not set.
+func newLocalKMS(config *LocalConfig) (*localKMS, error) {
+	keyEnv := DefaultLocalKeyEnv
+	if config != nil && config.KeyEnv != "" {
+		keyEnv = config.KeyEnv
+	}
+
+	encoded, ok := os.LookupEnv(keyEnv)
+	if !ok {
+		if config != nil {
+			return nil, fmt.Errorf("kms key environment variable %s is not set", keyEnv)
+		}
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("kms key in %s is not valid base64: %w", keyEnv, err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kms key in %s: %w", keyEnv, err)
+	}
+	return &localKMS{aead: aead}, nil
+}
+
+func (k *localKMS) Decrypt(ctx context.Context, ciphertext string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("ciphertext is not valid base64: %w", err)
+	}
+	nonceSize := k.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", fmt.Errorf("ciphertext is too short")
+	}
+	plaintext, err := k.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("ciphertext does not match the key")
+	}
+	return string(plaintext), nil
+}
+
+// EncryptLocal encrypts a value for the local provider with a 32 byte key and returns the
+// complete encrypted value, prefix included.
+func EncryptLocal(key []byte, plaintext string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return Prefix + ProviderLocal + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
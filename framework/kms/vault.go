@@ -0,0 +1,103 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultVaultTokenEnv is the environment variable holding the Vault token.
+const DefaultVaultTokenEnv = "VAULT_TOKEN"
+
+// VaultConfig configures the HashiCorp Vault transit provider.
+type VaultConfig struct {
+	Address   string `json:"address"`             // Vault address, e.g. https://vault:8200 - REQUIRED
+	KeyName   string `json:"key_name"`            // Transit key the values were encrypted with - REQUIRED
+	Mount     string `json:"mount,omitempty"`     // Mount path of the transit engine, "transit" if empty
+	Namespace string `json:"namespace,omitempty"` // Vault Enterprise namespace (optional)
+	TokenEnv  string `json:"token_env,omitempty"` // Environment variable holding the token, DefaultVaultTokenEnv if empty
+}
+
+// vaultKMS calls the transit decrypt API. Ciphertexts are the "vault:v1:..." values returned by
+// transit encrypt.
+type vaultKMS struct {
+	url       string
+	namespace string
+	token     string
+	client    *http.Client
+}
+
+func newVaultKMS(config VaultConfig) (*vaultKMS, error) {
+	if config.Address == "" || config.KeyName == "" {
+		return nil, fmt.Errorf("vault kms address and key_name are required")
+	}
+	tokenEnv := config.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = DefaultVaultTokenEnv
+	}
+	token, ok := os.LookupEnv(tokenEnv)
+	if !ok || token == "" {
+		return nil, fmt.Errorf("vault token environment variable %s is not set", tokenEnv)
+	}
+	mount := strings.Trim(config.Mount, "/")
+	if mount == "" {
+		mount = "transit"
+	}
+
+	return &vaultKMS{
+		url:       fmt.Sprintf("%s/v1/%s/decrypt/%s", strings.TrimSuffix(config.Address, "/"), mount, url.PathEscape(config.KeyName)),
+		namespace: config.Namespace,
+		token:     token,
+		client:    &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+func (k *vaultKMS) Decrypt(ctx context.Context, ciphertext string) (string, error) {
+	body, err := json.Marshal(map[string]string{"ciphertext": ciphertext})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", k.token)
+	if k.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", k.namespace)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+		Errors []string `json:"errors"`
+	}
+	_ = json.Unmarshal(data, &result)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d: %s", resp.StatusCode, strings.Join(result.Errors, "; "))
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(result.Data.Plaintext)
+	if err != nil {
+		return "", fmt.Errorf("vault plaintext is not valid base64: %w", err)
+	}
+	return string(plaintext), nil
+}
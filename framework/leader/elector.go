@@ -0,0 +1,226 @@
+// Package leader elects one replica of a Bifrost deployment to run the maintenance tasks that
+// must happen once across the fleet, such as pricing and deprecation syncs and retention
+// purging. Leadership is a lease kept in the shared state store and renewed in the background.
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/statestore"
+)
+
+// Default election settings.
+const (
+	DefaultElectionName = "maintenance"
+	DefaultLeaseTTL     = 15 * time.Second
+)
+
+// Config configures leader election. Durations are Go duration strings such as "15s".
+type Config struct {
+	Enabled       bool   `json:"enabled"`
+	Name          string `json:"name,omitempty"`           // Election name, replicas with the same name elect one leader, DefaultElectionName if empty
+	LeaseTTL      string `json:"lease_ttl,omitempty"`      // How long a lease outlives its last renewal, DefaultLeaseTTL if empty
+	RenewInterval string `json:"renew_interval,omitempty"` // How often the lease is renewed or contested, a third of the lease TTL if empty
+	ID            string `json:"id,omitempty"`             // Identity of this replica, the hostname with a random suffix if empty
+
+	OnChange func(isLeader bool) `json:"-"` // Called when this replica gains or loses leadership
+}
+
+// Elector campaigns for the leadership of an election. A nil Elector, or one without a state
+// store, always reports leadership, so single instances run every task.
+type Elector struct {
+	store         statestore.StateStore
+	key           string
+	id            []byte
+	ttl           time.Duration
+	renewInterval time.Duration
+	onChange      func(isLeader bool)
+	logger        schemas.Logger
+
+	mu         sync.Mutex
+	leader     bool
+	leaseUntil time.Time // Local deadline of the lease, set conservatively from the start of the renewal
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// NewElector creates an elector. Campaigning starts with Start.
+func NewElector(store statestore.StateStore, config Config, logger schemas.Logger) (*Elector, error) {
+	name := config.Name
+	if name == "" {
+		name = DefaultElectionName
+	}
+
+	ttl := DefaultLeaseTTL
+	if config.LeaseTTL != "" {
+		parsed, err := time.ParseDuration(config.LeaseTTL)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid leader lease ttl: %s", config.LeaseTTL)
+		}
+		ttl = parsed
+	}
+
+	renewInterval := ttl / 3
+	if config.RenewInterval != "" {
+		parsed, err := time.ParseDuration(config.RenewInterval)
+		if err != nil || parsed <= 0 || parsed >= ttl {
+			return nil, fmt.Errorf("invalid leader renew interval %s, it must be shorter than the lease ttl", config.RenewInterval)
+		}
+		renewInterval = parsed
+	}
+
+	id := config.ID
+	if id == "" {
+		id = replicaID()
+	}
+
+	return &Elector{
+		store:         store,
+		key:           "leader:" + name,
+		id:            []byte(id),
+		ttl:           ttl,
+		renewInterval: renewInterval,
+		onChange:      config.OnChange,
+		logger:        logger,
+	}, nil
+}
+
+// ID returns the identity of this replica.
+func (e *Elector) ID() string {
+	return string(e.id)
+}
+
+// IsLeader reports whether this replica holds the lease. Leadership lapses on its own once the
+// lease could have expired, even if the state store cannot be reached to renew it.
+func (e *Elector) IsLeader() bool {
+	if e == nil || e.store == nil {
+		return true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader && time.Now().Before(e.leaseUntil)
+}
+
+// Start campaigns for the lease every renew interval in the background until Stop is called or
+// ctx is cancelled. The first attempt is made before Start returns.
+func (e *Elector) Start(ctx context.Context) error {
+	if e.store == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	if e.cancel != nil {
+		e.mu.Unlock()
+		return errors.New("leader elector already started")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	done := e.done
+	e.mu.Unlock()
+
+	e.campaign(ctx)
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(e.renewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			e.campaign(ctx)
+		}
+	}()
+	return nil
+}
+
+// Stop stops campaigning and releases the lease, so another replica can take over without
+// waiting for it to expire.
+func (e *Elector) Stop() {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel, e.done = nil, nil
+	e.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+
+	if e.IsLeader() {
+		if _, err := e.store.CompareAndDelete(context.Background(), e.key, e.id); err != nil {
+			e.logger.Warn("failed to release leader lease %s: %v", e.key, err)
+		}
+	}
+	e.setLeader(false, time.Time{})
+}
+
+// campaign renews the lease if this replica holds it, or tries to acquire it otherwise.
+func (e *Elector) campaign(ctx context.Context) {
+	start := time.Now()
+	e.mu.Lock()
+	wasLeader := e.leader
+	e.mu.Unlock()
+
+	var held bool
+	var err error
+	if wasLeader {
+		held, err = e.store.CompareAndSwap(ctx, e.key, e.id, e.id, e.ttl)
+	}
+	// The lease expired, or another replica holds it
+	if err == nil && !held {
+		held, err = e.store.SetNX(ctx, e.key, e.id, e.ttl)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			e.logger.Warn("failed to campaign for leader lease %s: %v", e.key, err)
+		}
+		// Keep the current lease until it lapses on its own
+		return
+	}
+
+	e.setLeader(held, start.Add(e.ttl))
+}
+
+// setLeader records the outcome of a campaign and reports changes of leadership.
+func (e *Elector) setLeader(leader bool, leaseUntil time.Time) {
+	e.mu.Lock()
+	changed := e.leader != leader
+	e.leader = leader
+	e.leaseUntil = leaseUntil
+	e.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if leader {
+		e.logger.Info("replica %s became the leader of %s", e.id, e.key)
+	} else {
+		e.logger.Info("replica %s is no longer the leader of %s", e.id, e.key)
+	}
+	if e.onChange != nil {
+		e.onChange(leader)
+	}
+}
+
+// replicaID returns the hostname with a random suffix, unique even for replicas sharing a hostname.
+func replicaID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "bifrost"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return host + "-" + hex.EncodeToString(suffix)
+}
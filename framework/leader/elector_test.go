@@ -0,0 +1,64 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/statestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestElector(t *testing.T, store statestore.StateStore, id string) *Elector {
+	t.Helper()
+	elector, err := NewElector(store, Config{ID: id, LeaseTTL: "300ms", RenewInterval: "50ms"}, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	require.NoError(t, err)
+	return elector
+}
+
+func TestElectorSingleLeader(t *testing.T) {
+	store := statestore.NewMemoryStore()
+	a := newTestElector(t, store, "a")
+	b := newTestElector(t, store, "b")
+
+	require.NoError(t, a.Start(context.Background()))
+	require.NoError(t, b.Start(context.Background()))
+	defer b.Stop()
+
+	time.Sleep(150 * time.Millisecond)
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+
+	// Stopping releases the lease, the other replica takes over at its next campaign
+	a.Stop()
+	assert.False(t, a.IsLeader())
+	assert.Eventually(t, b.IsLeader, time.Second, 10*time.Millisecond)
+}
+
+func TestElectorLeaseExpiry(t *testing.T) {
+	store := statestore.NewMemoryStore()
+	a := newTestElector(t, store, "a")
+	require.NoError(t, a.Start(context.Background()))
+	defer a.Stop()
+	require.True(t, a.IsLeader())
+
+	// Another replica steals the lease, e.g. after a's renewals were delayed past the TTL
+	require.NoError(t, store.Set(context.Background(), "leader:"+DefaultElectionName, []byte("b"), time.Minute))
+	assert.Eventually(t, func() bool { return !a.IsLeader() }, time.Second, 10*time.Millisecond)
+}
+
+func TestElectorWithoutStore(t *testing.T) {
+	var nilElector *Elector
+	assert.True(t, nilElector.IsLeader())
+
+	elector := newTestElector(t, nil, "a")
+	require.NoError(t, elector.Start(context.Background()))
+	assert.True(t, elector.IsLeader())
+	elector.Stop()
+
+	_, err := NewElector(nil, Config{LeaseTTL: "1s", RenewInterval: "2s"}, nil)
+	assert.Error(t, err)
+}
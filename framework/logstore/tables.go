@@ -90,6 +90,7 @@ type Log struct {
 	Status              string    `gorm:"type:varchar(50);index;not null" json:"status"` // "processing", "success", or "error"
 	ErrorDetails        string    `gorm:"type:text" json:"-"`                            // JSON serialized *schemas.BifrostError
 	Stream              bool      `gorm:"default:false" json:"stream"`                   // true if this was a streaming response
+	MetadataOnly        bool      `gorm:"default:false" json:"metadata_only"`            // true if the request was not sampled for content logging
 	ContentSummary      string    `gorm:"type:text" json:"-"`                            // For content search
 
 	// Denormalized token fields for easier querying
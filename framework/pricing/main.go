@@ -29,6 +29,9 @@ type PricingManager struct {
 	syncTicker *time.Ticker
 	done       chan struct{}
 	wg         sync.WaitGroup
+
+	// Reports whether this replica runs the periodic sync, guarded by mu, every replica syncs if nil
+	isLeader func() bool
 }
 
 // PricingData represents the structure of the pricing.json file
@@ -46,6 +49,7 @@ type PricingEntry struct {
 	InputCostPerImage          *float64 `json:"input_cost_per_image,omitempty"`
 	InputCostPerVideoPerSecond *float64 `json:"input_cost_per_video_per_second,omitempty"`
 	InputCostPerAudioPerSecond *float64 `json:"input_cost_per_audio_per_second,omitempty"`
+	OutputCostPerImage         *float64 `json:"output_cost_per_image,omitempty"`
 
 	// Character-based pricing
 	InputCostPerCharacter  *float64 `json:"input_cost_per_character,omitempty"`
@@ -155,6 +159,11 @@ func (pm *PricingManager) CalculateCost(result *schemas.BifrostResponse, provide
 		}
 	}
 
+	// Generated images are billed per image
+	if result.Image != nil {
+		return pm.calculateImageCost(string(provider), model, len(result.Image.Images))
+	}
+
 	cost := 0.0
 	if usage != nil || audioSeconds != nil || audioTokenDetails != nil {
 		cost = pm.CalculateCostFromUsage(string(provider), model, usage, requestType, isCacheRead, isBatch, audioSeconds, audioTokenDetails)
@@ -200,6 +209,14 @@ func (pm *PricingManager) CalculateCostWithCacheDebug(result *schemas.BifrostRes
 	return pm.CalculateCost(result, provider, model, requestType)
 }
 
+// SetLeaderCheck restricts the periodic sync to the replica for which isLeader returns true, for
+// replicas sharing a config store. The other replicas reload the pricing the leader synced.
+func (pm *PricingManager) SetLeaderCheck(isLeader func() bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.isLeader = isLeader
+}
+
 func (pm *PricingManager) Cleanup() error {
 	if pm.syncTicker != nil {
 		pm.syncTicker.Stop()
@@ -211,6 +228,22 @@ func (pm *PricingManager) Cleanup() error {
 	return nil
 }
 
+// calculateImageCost calculates the cost in dollars of generating images, from the output cost per
+// image of the model.
+func (pm *PricingManager) calculateImageCost(provider string, model string, images int) float64 {
+	if images == 0 {
+		return 0.0
+	}
+
+	pricing, exists := pm.getPricing(model, provider, schemas.ImageGenerationRequest)
+	if !exists || pricing.OutputCostPerImage == nil {
+		pm.logger.Warn("image pricing not found for model %s and provider %s, skipping cost calculation", model, provider)
+		return 0.0
+	}
+
+	return float64(images) * *pricing.OutputCostPerImage
+}
+
 // CalculateCostFromUsage calculates cost in dollars using pricing manager and usage data with conditional pricing
 func (pm *PricingManager) CalculateCostFromUsage(provider string, model string, usage *schemas.LLMUsage, requestType schemas.RequestType, isCacheRead bool, isBatch bool, audioSeconds *int, audioTokenDetails *schemas.AudioTokenDetails) float64 {
 	// Allow audio-only flows by only returning early if we have no usage data at all
@@ -240,7 +273,7 @@ func (pm *PricingManager) CalculateCostFromUsage(provider string, model string,
 	completionTokens := safeTokenCount(usage, func(u *schemas.LLMUsage) int { return u.CompletionTokens })
 
 	// Special handling for audio operations with duration-based pricing
-	if (requestType == schemas.SpeechRequest || requestType == schemas.TranscriptionRequest) && audioSeconds != nil && *audioSeconds > 0 {
+	if (requestType == schemas.SpeechRequest || requestType == schemas.TranscriptionRequest || requestType == schemas.TranslationRequest) && audioSeconds != nil && *audioSeconds > 0 {
 		// Determine if this is above TokenTierAbove128K for pricing tier selection
 		isAbove128k := totalTokens > TokenTierAbove128K
 
@@ -266,7 +299,7 @@ func (pm *PricingManager) CalculateCostFromUsage(provider string, model string,
 	}
 
 	// Handle audio token details if available (for token-based audio pricing)
-	if audioTokenDetails != nil && (requestType == schemas.SpeechRequest || requestType == schemas.TranscriptionRequest) {
+	if audioTokenDetails != nil && (requestType == schemas.SpeechRequest || requestType == schemas.TranscriptionRequest || requestType == schemas.TranslationRequest) {
 		// Use audio-specific token pricing if available
 		audioTokens := float64(audioTokenDetails.AudioTokens)
 		textTokens := float64(audioTokenDetails.TextTokens)
@@ -331,6 +364,11 @@ func (pm *PricingManager) getPricing(model, provider string, requestType schemas
 	defer pm.mu.RUnlock()
 
 	pricing, ok := pm.pricingData[makeKey(model, provider, normalizeRequestType(requestType))]
+	if !ok && requestType == schemas.TranslationRequest {
+		// Translations are billed as transcriptions unless priced on their own
+		requestType = schemas.TranscriptionRequest
+		pricing, ok = pm.pricingData[makeKey(model, provider, normalizeRequestType(requestType))]
+	}
 	if !ok {
 		if provider == string(schemas.Gemini) {
 			pricing, ok = pm.pricingData[makeKey(model, "vertex", normalizeRequestType(requestType))]
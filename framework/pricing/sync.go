@@ -227,6 +227,17 @@ func (pm *PricingManager) syncWorker() {
 	for {
 		select {
 		case <-pm.syncTicker.C:
+			pm.mu.RLock()
+			isLeader := pm.isLeader
+			pm.mu.RUnlock()
+			if isLeader != nil && !isLeader() {
+				// Another replica syncs the shared config store, pick up the pricing it synced
+				if err := pm.loadPricingFromDatabase(); err != nil {
+					pm.logger.Error("background pricing reload failed: %v", err)
+				}
+				continue
+			}
+
 			// Check and sync pricing data - this handles the sync internally
 			if err := pm.checkAndSyncPricing(); err != nil {
 				pm.logger.Error("background pricing sync failed: %v", err)
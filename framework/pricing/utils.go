@@ -60,6 +60,12 @@ func normalizeRequestType(reqType schemas.RequestType) string {
 		baseType = "audio_speech"
 	case schemas.TranscriptionRequest, schemas.TranscriptionStreamRequest:
 		baseType = "audio_transcription"
+	case schemas.TranslationRequest:
+		baseType = "audio_translation"
+	case schemas.ImageGenerationRequest:
+		baseType = "image_generation"
+	case schemas.RerankRequest:
+		baseType = "rerank"
 	}
 
 	// TODO: Check for batch processing indicators
@@ -94,6 +100,7 @@ func convertPricingDataToTableModelPricing(modelKey string, entry PricingEntry)
 		InputCostPerImage:          entry.InputCostPerImage,
 		InputCostPerVideoPerSecond: entry.InputCostPerVideoPerSecond,
 		InputCostPerAudioPerSecond: entry.InputCostPerAudioPerSecond,
+		OutputCostPerImage:         entry.OutputCostPerImage,
 
 		// Character-based pricing
 		InputCostPerCharacter:  entry.InputCostPerCharacter,
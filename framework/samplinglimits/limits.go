@@ -0,0 +1,231 @@
+// Package samplinglimits caps the sampling parameters of requests per virtual key, provider and
+// model, e.g. limiting support bots to a temperature of 0.3 and 1024 output tokens. Parameters
+// above a cap are clamped to it, or the request is rejected.
+package samplinglimits
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// Action is what happens to a request whose parameters exceed a cap.
+type Action string
+
+const (
+	ActionClamp  Action = "clamp"  // Lower the parameter to the cap
+	ActionReject Action = "reject" // Reject the request
+)
+
+// Rule caps the sampling parameters of the requests it matches. Empty match lists match every
+// request; models ending with "*" match every model starting with what precedes it.
+type Rule struct {
+	Name           string                  `json:"name"`
+	VirtualKeys    []string                `json:"virtual_keys,omitempty"`
+	Providers      []schemas.ModelProvider `json:"providers,omitempty"`
+	Models         []string                `json:"models,omitempty"`
+	MaxTemperature *float64                `json:"max_temperature,omitempty"`
+	MaxTopP        *float64                `json:"max_top_p,omitempty"`
+	MaxTokens      *int                    `json:"max_tokens,omitempty"`
+	Action         Action                  `json:"action,omitempty"` // ActionClamp if empty
+}
+
+// Config configures the limits and plugin.
+type Config struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Adjustment is a parameter changed to respect the cap of a rule. From is nil when the
+// parameter was not set: capped parameters default to their cap, so a higher provider default
+// does not apply.
+type Adjustment struct {
+	Rule  string   `json:"rule"`
+	Param string   `json:"param"`
+	From  *float64 `json:"from,omitempty"`
+	To    float64  `json:"to"`
+}
+
+// String describes the adjustment, as in the warning of the request.
+func (a Adjustment) String() string {
+	if a.From == nil {
+		return fmt.Sprintf("%s set to %s by sampling limit %s", a.Param, formatValue(a.To), a.Rule)
+	}
+	return fmt.Sprintf("%s lowered from %s to %s by sampling limit %s", a.Param, formatValue(*a.From), formatValue(a.To), a.Rule)
+}
+
+// Violation is a parameter above the cap of a reject rule.
+type Violation struct {
+	Rule  string
+	Param string
+	Value float64
+	Max   float64
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s %s exceeds the maximum of %s allowed by sampling limit %s", v.Param, formatValue(v.Value), formatValue(v.Max), v.Rule)
+}
+
+// Limits holds the rules of the configuration. It is immutable and safe for concurrent use.
+type Limits struct {
+	rules []Rule
+}
+
+// NewLimits validates the rules of the configuration.
+func NewLimits(config Config) (*Limits, error) {
+	rules := make([]Rule, 0, len(config.Rules))
+	for i, rule := range config.Rules {
+		if rule.Name == "" {
+			rule.Name = "rule-" + strconv.Itoa(i+1)
+		}
+		switch rule.Action {
+		case "":
+			rule.Action = ActionClamp
+		case ActionClamp, ActionReject:
+		default:
+			return nil, fmt.Errorf("invalid action %q for sampling limit %s: expected clamp or reject", rule.Action, rule.Name)
+		}
+		if rule.MaxTemperature == nil && rule.MaxTopP == nil && rule.MaxTokens == nil {
+			return nil, fmt.Errorf("sampling limit %s caps no parameter", rule.Name)
+		}
+		if (rule.MaxTemperature != nil && *rule.MaxTemperature < 0) || (rule.MaxTopP != nil && *rule.MaxTopP < 0) || (rule.MaxTokens != nil && *rule.MaxTokens < 1) {
+			return nil, fmt.Errorf("sampling limit %s has a negative or zero cap", rule.Name)
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("at least one sampling limit is required")
+	}
+	return &Limits{rules: rules}, nil
+}
+
+// Apply enforces the rules matching a request made with a virtual key, which is empty for
+// requests made without one. Every matching rule applies, so the lowest cap wins. It returns
+// the adjustments made to the parameters of the request, or the first violation of a reject
+// rule, in which case the request is left unchanged.
+func (l *Limits) Apply(req *schemas.BifrostRequest, virtualKey string) ([]Adjustment, *Violation) {
+	var matched []Rule
+	for _, rule := range l.rules {
+		if rule.matches(req, virtualKey) {
+			matched = append(matched, rule)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, nil
+	}
+
+	// Reject rules are checked first, so that a rejected request is not modified
+	var params schemas.ModelParameters
+	if req.Params != nil {
+		params = *req.Params
+	}
+	for _, rule := range matched {
+		if rule.Action != ActionReject {
+			continue
+		}
+		for _, cap := range rule.caps(&params) {
+			if cap.value != nil && *cap.value > cap.max {
+				return nil, &Violation{Rule: rule.Name, Param: cap.param, Value: *cap.value, Max: cap.max}
+			}
+		}
+	}
+
+	var adjustments []Adjustment
+	for _, rule := range matched {
+		for _, cap := range rule.caps(&params) {
+			if cap.value != nil && *cap.value <= cap.max {
+				continue
+			}
+			var from *float64
+			if cap.value != nil {
+				value := *cap.value
+				from = &value
+			}
+			cap.set(cap.max)
+			adjustments = append(adjustments, Adjustment{Rule: rule.Name, Param: cap.param, From: from, To: cap.max})
+		}
+	}
+	if len(adjustments) > 0 {
+		req.Params = &params
+	}
+	return adjustments, nil
+}
+
+// matches reports whether the rule applies to a request.
+func (r *Rule) matches(req *schemas.BifrostRequest, virtualKey string) bool {
+	if len(r.VirtualKeys) > 0 && !contains(r.VirtualKeys, virtualKey) {
+		return false
+	}
+	if len(r.Providers) > 0 && !contains(r.Providers, req.Provider) {
+		return false
+	}
+	if len(r.Models) == 0 {
+		return true
+	}
+	for _, model := range r.Models {
+		if prefix, ok := strings.CutSuffix(model, "*"); ok && strings.HasPrefix(req.Model, prefix) {
+			return true
+		}
+		if model == req.Model {
+			return true
+		}
+	}
+	return false
+}
+
+// paramCap is a capped parameter of a request, as a float64 whatever its type.
+type paramCap struct {
+	param string
+	value *float64 // nil if not set
+	max   float64
+	set   func(float64)
+}
+
+// caps returns the parameters the rule caps.
+func (r *Rule) caps(params *schemas.ModelParameters) []paramCap {
+	var caps []paramCap
+	if r.MaxTemperature != nil {
+		caps = append(caps, paramCap{
+			param: "temperature",
+			value: params.Temperature,
+			max:   *r.MaxTemperature,
+			set:   func(v float64) { params.Temperature = &v },
+		})
+	}
+	if r.MaxTopP != nil {
+		caps = append(caps, paramCap{
+			param: "top_p",
+			value: params.TopP,
+			max:   *r.MaxTopP,
+			set:   func(v float64) { params.TopP = &v },
+		})
+	}
+	if r.MaxTokens != nil {
+		var value *float64
+		if params.MaxTokens != nil {
+			tokens := float64(*params.MaxTokens)
+			value = &tokens
+		}
+		caps = append(caps, paramCap{
+			param: "max_tokens",
+			value: value,
+			max:   float64(*r.MaxTokens),
+			set:   func(v float64) { tokens := int(v); params.MaxTokens = &tokens },
+		})
+	}
+	return caps
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
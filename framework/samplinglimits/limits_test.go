@@ -0,0 +1,79 @@
+package samplinglimits
+
+import (
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newChatRequest(model string, params *schemas.ModelParameters) *schemas.BifrostRequest {
+	return &schemas.BifrostRequest{
+		Provider: schemas.OpenAI,
+		Model:    model,
+		Input:    schemas.RequestInput{ChatCompletionInput: &[]schemas.BifrostMessage{}},
+		Params:   params,
+	}
+}
+
+func TestLimitsClampParameters(t *testing.T) {
+	limits, err := NewLimits(Config{Rules: []Rule{
+		{Name: "support", VirtualKeys: []string{"vk-support"}, MaxTemperature: bifrost.Ptr(0.3), MaxTokens: bifrost.Ptr(1024)},
+		{Name: "mini", Models: []string{"gpt-4o-mini*"}, MaxTokens: bifrost.Ptr(512)},
+	}})
+	require.NoError(t, err)
+
+	req := newChatRequest("gpt-4o-mini-2024-07-18", &schemas.ModelParameters{Temperature: bifrost.Ptr(0.9), MaxTokens: bifrost.Ptr(4096)})
+	adjustments, violation := limits.Apply(req, "vk-support")
+	require.Nil(t, violation)
+	assert.Equal(t, 0.3, *req.Params.Temperature)
+	assert.Equal(t, 512, *req.Params.MaxTokens) // The lowest cap wins
+	require.Len(t, adjustments, 3)
+	assert.Equal(t, "temperature lowered from 0.9 to 0.3 by sampling limit support", adjustments[0].String())
+
+	// Unset parameters default to their cap
+	req = newChatRequest("gpt-4o", nil)
+	adjustments, violation = limits.Apply(req, "vk-support")
+	require.Nil(t, violation)
+	require.NotNil(t, req.Params)
+	assert.Equal(t, 1024, *req.Params.MaxTokens)
+	assert.Equal(t, "max_tokens set to 1024 by sampling limit support", adjustments[1].String())
+
+	// Other keys and models are not limited
+	req = newChatRequest("gpt-4o", &schemas.ModelParameters{Temperature: bifrost.Ptr(0.9)})
+	adjustments, violation = limits.Apply(req, "vk-other")
+	assert.Nil(t, violation)
+	assert.Empty(t, adjustments)
+	assert.Equal(t, 0.9, *req.Params.Temperature)
+}
+
+func TestLimitsRejectParameters(t *testing.T) {
+	limits, err := NewLimits(Config{Rules: []Rule{
+		{Name: "strict", Providers: []schemas.ModelProvider{schemas.OpenAI}, MaxTopP: bifrost.Ptr(0.5), Action: ActionReject},
+		{Name: "tokens", MaxTokens: bifrost.Ptr(256)},
+	}})
+	require.NoError(t, err)
+
+	req := newChatRequest("gpt-4o", &schemas.ModelParameters{TopP: bifrost.Ptr(0.8), MaxTokens: bifrost.Ptr(1000)})
+	adjustments, violation := limits.Apply(req, "")
+	require.NotNil(t, violation)
+	assert.Empty(t, adjustments)
+	assert.Equal(t, "top_p 0.8 exceeds the maximum of 0.5 allowed by sampling limit strict", violation.Error())
+	assert.Equal(t, 1000, *req.Params.MaxTokens) // Rejected requests are left unchanged
+
+	// Unset parameters are not rejected
+	req = newChatRequest("gpt-4o", nil)
+	_, violation = limits.Apply(req, "")
+	assert.Nil(t, violation)
+}
+
+func TestNewLimitsValidatesRules(t *testing.T) {
+	_, err := NewLimits(Config{Rules: []Rule{{Name: "empty"}}})
+	assert.Error(t, err)
+	_, err = NewLimits(Config{Rules: []Rule{{Name: "bad", MaxTokens: bifrost.Ptr(10), Action: "drop"}}})
+	assert.Error(t, err)
+	_, err = NewLimits(Config{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package samplinglimits
+
+import (
+	"context"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	PluginName = "sampling-limits"
+
+	// ErrorType is the type of the errors of requests rejected by a sampling limit
+	ErrorType = "sampling_limit_exceeded"
+)
+
+// Plugin enforces sampling limits on chat and text completion requests. The virtual key of a
+// request is read from the x-bf-vk context value set by the HTTP transport. Each clamped
+// parameter is reported as an enforced warning of the response; requests rejected by a limit
+// fail with status 400 and do not fall back.
+type Plugin struct {
+	limits *Limits
+	logger schemas.Logger
+}
+
+// Init validates the configured rules and returns the plugin.
+func Init(config Config, logger schemas.Logger) (*Plugin, error) {
+	limits, err := NewLimits(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Plugin{
+		limits: limits,
+		logger: logger,
+	}, nil
+}
+
+// GetName returns the name of the plugin.
+func (p *Plugin) GetName() string {
+	return PluginName
+}
+
+// GetLimits returns the limits of the plugin.
+func (p *Plugin) GetLimits() *Limits {
+	return p.limits
+}
+
+// PreHook clamps the sampling parameters of the request, or rejects it.
+func (p *Plugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	if req.Input.ChatCompletionInput == nil && req.Input.TextCompletionInput == nil {
+		return req, nil, nil
+	}
+
+	virtualKey, _ := (*ctx).Value(schemas.BifrostContextKey("x-bf-vk")).(string)
+	adjustments, violation := p.limits.Apply(req, virtualKey)
+	if violation != nil {
+		p.logger.Debug("rejected request to %s: %s", req.Model, violation.Error())
+		return req, &schemas.PluginShortCircuit{
+			Error: &schemas.BifrostError{
+				IsBifrostError: true,
+				Type:           bifrost.Ptr(ErrorType),
+				StatusCode:     bifrost.Ptr(400),
+				Origin:         schemas.ErrorOriginPolicy,
+				Error: schemas.ErrorField{
+					Type:    bifrost.Ptr(ErrorType),
+					Message: violation.Error(),
+				},
+				AllowFallbacks: bifrost.Ptr(false),
+			},
+		}, nil
+	}
+
+	for _, adjustment := range adjustments {
+		schemas.AddWarning(*ctx, schemas.WarningEnforced, PluginName, adjustment.String())
+	}
+	return req, nil, nil
+}
+
+// PostHook does nothing, limits only apply to requests.
+func (p *Plugin) PostHook(ctx *context.Context, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	return result, bifrostErr, nil
+}
+
+// Cleanup is a no-op, the plugin holds no resources.
+func (p *Plugin) Cleanup() error {
+	return nil
+}
@@ -0,0 +1,380 @@
+package statestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// EtcdConfig configures the etcd state store. The store talks to the etcd v3 JSON gateway, so
+// no etcd client library is required.
+type EtcdConfig struct {
+	Endpoints      []string      `json:"endpoints"`                 // Gateway URLs, e.g. http://localhost:2379 - REQUIRED
+	Username       string        `json:"username,omitempty"`        // Username for etcd auth (optional)
+	Password       string        `json:"password,omitempty"`        // Password for etcd auth (optional)
+	ContextTimeout time.Duration `json:"context_timeout,omitempty"` // Timeout for etcd operations (optional)
+}
+
+// etcdMaxRetries bounds the compare-and-swap retries of IncrBy under contention.
+const etcdMaxRetries = 16
+
+// etcdMaxTxnOps is the default limit of etcd on the operations of a transaction, which bounds
+// the keys read by each round trip of GetMany.
+const etcdMaxTxnOps = 128
+
+// EtcdStore is a state store backed by etcd. TTLs are implemented with leases, one per write,
+// which etcd revokes together with the key once they expire.
+type EtcdStore struct {
+	endpoints []string
+	config    EtcdConfig
+	client    *http.Client
+	logger    schemas.Logger
+
+	mu    sync.Mutex
+	token string // Auth token, empty without auth
+	next  int    // Endpoint of the next request, rotated on connection errors
+}
+
+// etcdKeyValue is a key of a range response.
+type etcdKeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	ModRevision string `json:"mod_revision"`
+}
+
+func newEtcdStore(ctx context.Context, config EtcdConfig, logger schemas.Logger) (*EtcdStore, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, fmt.Errorf("etcd endpoints are required")
+	}
+
+	s := &EtcdStore{
+		config: config,
+		client: &http.Client{},
+		logger: logger,
+	}
+	for _, endpoint := range config.Endpoints {
+		s.endpoints = append(s.endpoints, strings.TrimSuffix(endpoint, "/"))
+	}
+
+	if config.Username != "" {
+		if err := s.authenticate(ctx); err != nil {
+			return nil, fmt.Errorf("failed to authenticate to etcd: %w", err)
+		}
+	}
+	// Check the connection
+	if _, _, err := s.get(ctx, "bifrost-statestore-ping"); err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to connect to etcd: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *EtcdStore) Get(ctx context.Context, key string) ([]byte, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	value, _, err := s.get(ctx, key)
+	return value, err
+}
+
+func (s *EtcdStore) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	values := make([][]byte, len(keys))
+	for start := 0; start < len(keys); start += etcdMaxTxnOps {
+		batch := keys[start:min(start+etcdMaxTxnOps, len(keys))]
+		ops := make([]any, len(batch))
+		for i, key := range batch {
+			ops[i] = map[string]any{"request_range": map[string]any{"key": encodeEtcd([]byte(key))}}
+		}
+		var resp struct {
+			Responses []struct {
+				ResponseRange struct {
+					Kvs []etcdKeyValue `json:"kvs"`
+				} `json:"response_range"`
+			} `json:"responses"`
+		}
+		if err := s.call(ctx, "/v3/kv/txn", map[string]any{"success": ops}, &resp); err != nil {
+			return nil, err
+		}
+		for i, response := range resp.Responses {
+			if i >= len(batch) || len(response.ResponseRange.Kvs) == 0 {
+				continue
+			}
+			value, err := base64.StdEncoding.DecodeString(response.ResponseRange.Kvs[0].Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode etcd value: %w", err)
+			}
+			values[start+i] = value
+		}
+	}
+	return values, nil
+}
+
+func (s *EtcdStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	lease, err := s.grantLease(ctx, ttl)
+	if err != nil {
+		return err
+	}
+	return s.call(ctx, "/v3/kv/put", putRequest(key, value, lease), nil)
+}
+
+func (s *EtcdStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	lease, err := s.grantLease(ctx, ttl)
+	if err != nil {
+		return false, err
+	}
+	// A key that does not exist has a create revision of 0
+	return s.txn(ctx, map[string]any{
+		"key":             encodeEtcd([]byte(key)),
+		"target":          "CREATE",
+		"result":          "EQUAL",
+		"create_revision": "0",
+	}, map[string]any{"request_put": putRequest(key, value, lease)})
+}
+
+func (s *EtcdStore) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	for range etcdMaxRetries {
+		value, modRevision, err := s.get(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			lease, err := s.grantLease(ctx, ttl)
+			if err != nil {
+				return 0, err
+			}
+			created, err := s.txn(ctx, map[string]any{
+				"key":             encodeEtcd([]byte(key)),
+				"target":          "CREATE",
+				"result":          "EQUAL",
+				"create_revision": "0",
+			}, map[string]any{"request_put": putRequest(key, []byte(strconv.FormatInt(delta, 10)), lease)})
+			if err != nil {
+				return 0, err
+			}
+			if created {
+				return delta, nil
+			}
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		count, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("statestore: value of %s is not a counter: %w", key, err)
+		}
+		count += delta
+		// Keep the lease, and with it the TTL, of the existing counter
+		put := putRequest(key, []byte(strconv.FormatInt(count, 10)), "")
+		put["ignore_lease"] = true
+		updated, err := s.txn(ctx, map[string]any{
+			"key":          encodeEtcd([]byte(key)),
+			"target":       "MOD",
+			"result":       "EQUAL",
+			"mod_revision": modRevision,
+		}, map[string]any{"request_put": put})
+		if err != nil {
+			return 0, err
+		}
+		if updated {
+			return count, nil
+		}
+	}
+	return 0, ErrConflict
+}
+
+func (s *EtcdStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	lease, err := s.grantLease(ctx, ttl)
+	if err != nil {
+		return false, err
+	}
+	return s.txn(ctx, valueEquals(key, old), map[string]any{"request_put": putRequest(key, value, lease)})
+}
+
+func (s *EtcdStore) Delete(ctx context.Context, key string) error {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	return s.call(ctx, "/v3/kv/deleterange", map[string]any{"key": encodeEtcd([]byte(key))}, nil)
+}
+
+func (s *EtcdStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	return s.txn(ctx, valueEquals(key, old), map[string]any{
+		"request_delete_range": map[string]any{"key": encodeEtcd([]byte(key))},
+	})
+}
+
+func (s *EtcdStore) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// get returns the value and modification revision of a key.
+func (s *EtcdStore) get(ctx context.Context, key string) ([]byte, string, error) {
+	var resp struct {
+		Kvs []etcdKeyValue `json:"kvs"`
+	}
+	if err := s.call(ctx, "/v3/kv/range", map[string]any{"key": encodeEtcd([]byte(key))}, &resp); err != nil {
+		return nil, "", err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, "", ErrNotFound
+	}
+	value, err := base64.StdEncoding.DecodeString(resp.Kvs[0].Value)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode etcd value: %w", err)
+	}
+	return value, resp.Kvs[0].ModRevision, nil
+}
+
+// txn runs an operation if a comparison holds, reporting whether it did.
+func (s *EtcdStore) txn(ctx context.Context, compare map[string]any, op map[string]any) (bool, error) {
+	var resp struct {
+		Succeeded bool `json:"succeeded"`
+	}
+	err := s.call(ctx, "/v3/kv/txn", map[string]any{
+		"compare": []any{compare},
+		"success": []any{op},
+	}, &resp)
+	return resp.Succeeded, err
+}
+
+// grantLease returns a lease expiring after the TTL, rounded up to whole seconds, or an empty
+// ID for keys that never expire.
+func (s *EtcdStore) grantLease(ctx context.Context, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", nil
+	}
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	var resp struct {
+		ID string `json:"ID"`
+	}
+	if err := s.call(ctx, "/v3/lease/grant", map[string]any{"TTL": strconv.FormatInt(seconds, 10)}, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// authenticate fetches an auth token for the configured user.
+func (s *EtcdStore) authenticate(ctx context.Context) error {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := s.call(ctx, "/v3/auth/authenticate", map[string]any{
+		"name":     s.config.Username,
+		"password": s.config.Password,
+	}, &resp); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.token = resp.Token
+	s.mu.Unlock()
+	return nil
+}
+
+// call posts a request to the gateway, trying each endpoint until one answers, and decodes the
+// response into out when it is not nil.
+func (s *EtcdStore) call(ctx context.Context, path string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	token := s.token
+	first := s.next
+	s.mu.Unlock()
+
+	var lastErr error
+	for i := range s.endpoints {
+		index := (first + i) % len(s.endpoints)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoints[index]+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		s.mu.Lock()
+		s.next = index
+		s.mu.Unlock()
+
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("etcd returned status %d: %s", resp.StatusCode, data)
+		}
+		if out == nil {
+			return nil
+		}
+		return json.Unmarshal(data, out)
+	}
+	return fmt.Errorf("failed to reach etcd: %w", lastErr)
+}
+
+// valueEquals compares the value of a key. It fails for missing keys.
+func valueEquals(key string, value []byte) map[string]any {
+	return map[string]any{
+		"key":    encodeEtcd([]byte(key)),
+		"target": "VALUE",
+		"result": "EQUAL",
+		"value":  encodeEtcd(value),
+	}
+}
+
+// putRequest returns a put of a key, attached to a lease unless it is empty.
+func putRequest(key string, value []byte, lease string) map[string]any {
+	put := map[string]any{
+		"key":   encodeEtcd([]byte(key)),
+		"value": encodeEtcd(value),
+	}
+	if lease != "" {
+		put["lease"] = lease
+	}
+	return put
+}
+
+// encodeEtcd encodes bytes as the gateway expects them.
+func encodeEtcd(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,157 @@
+package statestore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memoryEntry is a value of the memory store.
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time // Zero if the key never expires
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryStore is a state store local to the process. It is the single-instance default and
+// does not share state between replicas.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	writes  int // Writes since expired entries were last swept
+}
+
+// memorySweepInterval is the number of writes between sweeps of expired entries.
+const memorySweepInterval = 1024
+
+// NewMemoryStore returns an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(time.Now()) {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), entry.value...), nil
+}
+
+func (s *MemoryStore) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		if entry, ok := s.entries[key]; ok && !entry.expired(now) {
+			values[i] = append([]byte(nil), entry.value...)
+		}
+	}
+	return values, nil
+}
+
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, append([]byte(nil), value...), ttl)
+	return nil
+}
+
+func (s *MemoryStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && !entry.expired(time.Now()) {
+		return false, nil
+	}
+	s.set(key, append([]byte(nil), value...), ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(time.Now()) {
+		s.set(key, []byte(strconv.FormatInt(delta, 10)), ttl)
+		return delta, nil
+	}
+
+	count, err := strconv.ParseInt(string(entry.value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("statestore: value of %s is not a counter: %w", key, err)
+	}
+	count += delta
+	entry.value = []byte(strconv.FormatInt(count, 10))
+	s.entries[key] = entry
+	return count, nil
+}
+
+func (s *MemoryStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(time.Now()) || !bytes.Equal(entry.value, old) {
+		return false, nil
+	}
+	s.set(key, append([]byte(nil), value...), ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(time.Now()) || !bytes.Equal(entry.value, old) {
+		return false, nil
+	}
+	delete(s.entries, key)
+	return true, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// set stores an entry and periodically sweeps expired ones. The caller must hold the lock.
+func (s *MemoryStore) set(key string, value []byte, ttl time.Duration) {
+	now := time.Now()
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+	s.entries[key] = entry
+
+	s.writes++
+	if s.writes < memorySweepInterval {
+		return
+	}
+	s.writes = 0
+	for k, e := range s.entries {
+		if e.expired(now) {
+			delete(s.entries, k)
+		}
+	}
+}
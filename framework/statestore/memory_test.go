@@ -0,0 +1,147 @@
+package statestore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStoreIncrBy(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.IncrBy(ctx, "counter", 2, time.Minute)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	count, err := GetInt(ctx, store, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), count)
+
+	count, err = GetInt(ctx, store, "missing")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	require.NoError(t, store.Set(ctx, "text", []byte("abc"), 0))
+	_, err = store.IncrBy(ctx, "text", 1, 0)
+	assert.Error(t, err)
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	_, err := store.IncrBy(ctx, "counter", 5, 20*time.Millisecond)
+	require.NoError(t, err)
+	// Increments keep the TTL set when the counter was created
+	_, err = store.IncrBy(ctx, "counter", 5, time.Hour)
+	require.NoError(t, err)
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = store.Get(ctx, "counter")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	count, err := store.IncrBy(ctx, "counter", 1, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMemoryStoreSetNX(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	ok, err := store.SetNX(ctx, "lock", []byte("a"), 20*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = store.SetNX(ctx, "lock", []byte("b"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	value, err := store.Get(ctx, "lock")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(value))
+
+	time.Sleep(30 * time.Millisecond)
+	ok, err = store.SetNX(ctx, "lock", []byte("b"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, store.Delete(ctx, "lock"))
+	_, err = store.Get(ctx, "lock")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestConfigUnmarshal(t *testing.T) {
+	var config Config
+	require.NoError(t, config.UnmarshalJSON([]byte(`{"enabled":true,"type":"etcd","config":{"endpoints":["http://localhost:2379"]}}`)))
+	assert.Equal(t, StateStoreTypeEtcd, config.Type)
+	assert.Equal(t, EtcdConfig{Endpoints: []string{"http://localhost:2379"}}, config.Config)
+
+	assert.Error(t, config.UnmarshalJSON([]byte(`{"enabled":true,"type":"consul"}`)))
+}
+
+func TestMemoryStoreCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	ok, err := store.CompareAndSwap(ctx, "lease", []byte("a"), []byte("b"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok, "missing keys never match")
+
+	require.NoError(t, store.Set(ctx, "lease", []byte("a"), time.Minute))
+	ok, err = store.CompareAndSwap(ctx, "lease", []byte("x"), []byte("b"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = store.CompareAndSwap(ctx, "lease", []byte("a"), []byte("b"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = store.CompareAndDelete(ctx, "lease", []byte("a"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = store.CompareAndDelete(ctx, "lease", []byte("b"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, err = store.Get(ctx, "lease")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryStoreGetMany(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemoryStore()
+	store := &prefixedStore{store: memory, prefix: "test:"}
+
+	_, err := store.IncrBy(ctx, "a", 3, time.Minute)
+	require.NoError(t, err)
+	_, err = store.IncrBy(ctx, "c", 5, 0)
+	require.NoError(t, err)
+	_, err = store.IncrBy(ctx, "expired", 7, time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	values, err := store.GetMany(ctx, []string{"a", "b", "c", "expired", "a"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("3"), nil, []byte("5"), nil, []byte("3")}, values)
+
+	counts, err := GetInts(ctx, store, []string{"c", "b", "a"})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5, 0, 3}, counts)
+
+	require.NoError(t, store.Set(ctx, "text", []byte("abc"), 0))
+	_, err = GetInts(ctx, store, []string{"a", "text"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,278 @@
+package statestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// Postgres store defaults.
+const (
+	DefaultPostgresDriver        = "pgx"
+	DefaultPostgresTable         = "bifrost_state"
+	DefaultPostgresCleanupPeriod = time.Minute
+)
+
+// postgresMaxGetKeys bounds the keys read by each query of GetMany, well below the limit of
+// Postgres on the parameters of a statement.
+const postgresMaxGetKeys = 1000
+
+// PostgresConfig configures the Postgres state store. The store uses database/sql, so the
+// binary embedding Bifrost must register the driver, e.g. by importing github.com/jackc/pgx/v5/stdlib.
+type PostgresConfig struct {
+	DSN            string        `json:"dsn"`                       // Connection string - REQUIRED
+	DriverName     string        `json:"driver_name,omitempty"`     // database/sql driver, DefaultPostgresDriver if empty
+	Table          string        `json:"table,omitempty"`           // Table holding the state, DefaultPostgresTable if empty
+	MaxOpenConns   int           `json:"max_open_conns,omitempty"`  // Maximum number of open connections (optional)
+	CleanupPeriod  time.Duration `json:"cleanup_period,omitempty"`  // Interval between deletions of expired rows, DefaultPostgresCleanupPeriod if 0
+	ContextTimeout time.Duration `json:"context_timeout,omitempty"` // Timeout for Postgres operations (optional)
+}
+
+var postgresTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// PostgresStore is a state store backed by a Postgres table. Expiry uses the clock of the
+// database, so replicas with skewed clocks agree on it; expired rows are ignored by reads and
+// deleted periodically.
+type PostgresStore struct {
+	db     *sql.DB
+	config PostgresConfig
+	logger schemas.Logger
+
+	getQuery     string
+	getManyQuery string
+	setQuery     string
+	setNXQuery   string
+	incrQuery    string
+	casQuery     string
+	deleteQuery  string
+	cadQuery     string
+	purgeQuery   string
+
+	done     chan struct{}
+	wg       sync.WaitGroup
+	closeErr error
+	once     sync.Once
+}
+
+func newPostgresStore(ctx context.Context, config PostgresConfig, logger schemas.Logger) (*PostgresStore, error) {
+	if config.DSN == "" {
+		return nil, fmt.Errorf("postgres dsn is required")
+	}
+	if config.DriverName == "" {
+		config.DriverName = DefaultPostgresDriver
+	}
+	if config.Table == "" {
+		config.Table = DefaultPostgresTable
+	}
+	if !postgresTableName.MatchString(config.Table) {
+		return nil, fmt.Errorf("invalid postgres table name %q", config.Table)
+	}
+	if config.CleanupPeriod <= 0 {
+		config.CleanupPeriod = DefaultPostgresCleanupPeriod
+	}
+
+	db, err := sql.Open(config.DriverName, config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres connection: %w", err)
+	}
+	if config.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(config.MaxOpenConns)
+	}
+
+	initCtx, cancel := withTimeout(ctx, config.ContextTimeout)
+	defer cancel()
+	if _, err := db.ExecContext(initCtx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		key TEXT PRIMARY KEY,
+		value BYTEA NOT NULL,
+		expires_at TIMESTAMPTZ
+	)`, config.Table)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create postgres state table: %w", err)
+	}
+
+	// Queries are templates over the table name; a TTL of 0 ms maps to a NULL expiry, a key
+	// that never expires
+	queries := strings.NewReplacer(
+		"{table}", config.Table,
+		"{live}", fmt.Sprintf("(%[1]s.expires_at IS NULL OR %[1]s.expires_at > now())", config.Table),
+		"{expiry}", "CASE WHEN $3::bigint > 0 THEN now() + $3::bigint * interval '1 millisecond' END",
+	)
+	s := &PostgresStore{
+		db:       db,
+		config:   config,
+		logger:   logger,
+		getQuery: queries.Replace(`SELECT value FROM {table} WHERE key = $1 AND {live}`),
+		// Followed by the list of placeholders of the keys
+		getManyQuery: queries.Replace(`SELECT key, value FROM {table} WHERE {live} AND key IN `),
+		setQuery: queries.Replace(`INSERT INTO {table} (key, value, expires_at) VALUES ($1, $2, {expiry})
+			ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at`),
+		setNXQuery: queries.Replace(`INSERT INTO {table} (key, value, expires_at) VALUES ($1, $2, {expiry})
+			ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at
+			WHERE NOT {live}`),
+		incrQuery: queries.Replace(`INSERT INTO {table} (key, value, expires_at)
+			VALUES ($1, convert_to($2::bigint::text, 'UTF8'), {expiry})
+			ON CONFLICT (key) DO UPDATE SET
+				value = CASE WHEN {live}
+					THEN convert_to((convert_from({table}.value, 'UTF8')::bigint + $2::bigint)::text, 'UTF8')
+					ELSE EXCLUDED.value END,
+				expires_at = CASE WHEN {live} THEN {table}.expires_at ELSE EXCLUDED.expires_at END
+			RETURNING convert_from(value, 'UTF8')::bigint`),
+		casQuery: queries.Replace(`UPDATE {table} SET value = $2, expires_at = {expiry}
+			WHERE key = $1 AND value = $4 AND {live}`),
+		deleteQuery: queries.Replace(`DELETE FROM {table} WHERE key = $1`),
+		cadQuery:    queries.Replace(`DELETE FROM {table} WHERE key = $1 AND value = $2 AND {live}`),
+		purgeQuery:  queries.Replace(`DELETE FROM {table} WHERE expires_at <= now()`),
+		done:        make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.cleanup()
+	return s, nil
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) ([]byte, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	var value []byte
+	err := s.db.QueryRowContext(ctx, s.getQuery, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+func (s *PostgresStore) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	values := make([][]byte, len(keys))
+	for start := 0; start < len(keys); start += postgresMaxGetKeys {
+		end := min(start+postgresMaxGetKeys, len(keys))
+		if err := s.getBatch(ctx, keys[start:end], values[start:end]); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// getBatch reads the values of keys into values with a single query.
+func (s *PostgresStore) getBatch(ctx context.Context, keys []string, values [][]byte) error {
+	placeholders := make([]string, len(keys))
+	args := make([]any, len(keys))
+	positions := make(map[string][]int, len(keys))
+	for i, key := range keys {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = key
+		positions[key] = append(positions[key], i)
+	}
+	rows, err := s.db.QueryContext(ctx, s.getManyQuery+"("+strings.Join(placeholders, ", ")+")", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			return err
+		}
+		for _, i := range positions[key] {
+			values[i] = value
+		}
+	}
+	return rows.Err()
+}
+
+func (s *PostgresStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, s.setQuery, key, value, ttl.Milliseconds())
+	return err
+}
+
+func (s *PostgresStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	return s.execAffected(ctx, s.setNXQuery, key, value, ttl.Milliseconds())
+}
+
+func (s *PostgresStore) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	var count int64
+	err := s.db.QueryRowContext(ctx, s.incrQuery, key, delta, ttl.Milliseconds()).Scan(&count)
+	return count, err
+}
+
+func (s *PostgresStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	return s.execAffected(ctx, s.casQuery, key, value, ttl.Milliseconds(), old)
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, key string) error {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, s.deleteQuery, key)
+	return err
+}
+
+func (s *PostgresStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	return s.execAffected(ctx, s.cadQuery, key, old)
+}
+
+// execAffected runs a statement and reports whether it changed a row.
+func (s *PostgresStore) execAffected(ctx context.Context, query string, args ...any) (bool, error) {
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// Close stops the cleanup loop and closes the connections.
+func (s *PostgresStore) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		s.closeErr = s.db.Close()
+	})
+	return s.closeErr
+}
+
+// cleanup deletes expired rows every cleanup period until Close.
+func (s *PostgresStore) cleanup() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.config.CleanupPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := withTimeout(context.Background(), s.config.ContextTimeout)
+		if _, err := s.db.ExecContext(ctx, s.purgeQuery); err != nil {
+			s.logger.Warn("failed to delete expired state rows: %v", err)
+		}
+		cancel()
+	}
+}
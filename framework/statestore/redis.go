@@ -0,0 +1,160 @@
+package statestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig configures the Redis state store.
+type RedisConfig struct {
+	Addr           string        `json:"addr"`                      // Redis server address (host:port) - REQUIRED
+	Username       string        `json:"username,omitempty"`        // Username for Redis AUTH (optional)
+	Password       string        `json:"password,omitempty"`        // Password for Redis AUTH (optional)
+	DB             int           `json:"db,omitempty"`              // Redis database number (default: 0)
+	PoolSize       int           `json:"pool_size,omitempty"`       // Maximum number of socket connections (optional)
+	ContextTimeout time.Duration `json:"context_timeout,omitempty"` // Timeout for Redis operations (optional)
+}
+
+// incrByScript adds to a counter and sets the TTL of counters it creates.
+var incrByScript = redis.NewScript(`
+local count = redis.call('INCRBY', KEYS[1], ARGV[1])
+if count == tonumber(ARGV[1]) and tonumber(ARGV[2]) > 0 and redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return count
+`)
+
+// compareAndSwapScript replaces a value, with a TTL in milliseconds if positive, if it matches.
+var compareAndSwapScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// compareAndDeleteScript deletes a key if its value matches.
+var compareAndDeleteScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call('DEL', KEYS[1])
+`)
+
+// RedisStore is a state store backed by Redis.
+type RedisStore struct {
+	client *redis.Client
+	config RedisConfig
+	logger schemas.Logger
+}
+
+func newRedisStore(ctx context.Context, config RedisConfig, logger schemas.Logger) (*RedisStore, error) {
+	if config.Addr == "" {
+		return nil, fmt.Errorf("redis addr is required")
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Username: config.Username,
+		Password: config.Password,
+		DB:       config.DB,
+		PoolSize: config.PoolSize,
+	})
+
+	pingCtx, cancel := withTimeout(ctx, config.ContextTimeout)
+	defer cancel()
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisStore{client: client, config: config, logger: logger}, nil
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	value, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+func (s *RedisStore) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	results, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if value, ok := result.(string); ok {
+			values[i] = []byte(value)
+		}
+	}
+	return values, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *RedisStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	return s.client.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (s *RedisStore) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	return incrByScript.Run(ctx, s.client, []string{key}, delta, ttl.Milliseconds()).Int64()
+}
+
+func (s *RedisStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	swapped, err := compareAndSwapScript.Run(ctx, s.client, []string{key}, old, value, ttl.Milliseconds()).Int()
+	return swapped == 1, err
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	return s.client.Del(ctx, key).Err()
+}
+
+func (s *RedisStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	ctx, cancel := withTimeout(ctx, s.config.ContextTimeout)
+	defer cancel()
+
+	deleted, err := compareAndDeleteScript.Run(ctx, s.client, []string{key}, old).Int()
+	return deleted == 1, err
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,254 @@
+// Package statestore provides a key-value state backend shared by the replicas of a Bifrost
+// deployment, so counters and flags kept by features such as rate limits and budgets are
+// consistent across instances. Its users are the governance rate limits and budgets, the
+// deprecation sync and leader election. The tree has no circuit breakers or idempotency store
+// yet, and the key cooldowns of bulk runs are scoped to a single run, so they keep no shared
+// state; new features that do should keep it here rather than in process memory.
+package statestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+type StateStoreType string
+
+const (
+	StateStoreTypeMemory   StateStoreType = "memory"
+	StateStoreTypeRedis    StateStoreType = "redis"
+	StateStoreTypeEtcd     StateStoreType = "etcd"
+	StateStoreTypePostgres StateStoreType = "postgres"
+)
+
+// DefaultKeyPrefix is prepended to every key, so a backend can be shared with other applications.
+const DefaultKeyPrefix = "bifrost:"
+
+var (
+	ErrNotFound = errors.New("statestore: not found")
+	ErrConflict = errors.New("statestore: too many concurrent updates")
+)
+
+// StateStore is a key-value store with expiring keys and atomic counters. Keys with a zero TTL
+// never expire. Counters are stored as decimal strings, so Get returns their current value.
+type StateStore interface {
+	// Get returns the value of a key, ErrNotFound if it does not exist or expired.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// GetMany returns the values of keys in order in as few round trips as the backend allows,
+	// nil for keys that do not exist or expired.
+	GetMany(ctx context.Context, keys []string) ([][]byte, error)
+	// Set stores the value of a key, replacing any previous value and TTL.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores the value of a key only if it does not exist, reporting whether it did.
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// IncrBy atomically adds delta to a counter and returns its new value. A missing counter
+	// starts at zero and gets the TTL; existing counters keep theirs.
+	IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+	// CompareAndSwap replaces the value and TTL of a key only if its current value is old,
+	// reporting whether it did. It is the building block of leases held by one owner.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error)
+	// Delete removes a key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// CompareAndDelete removes a key only if its current value is old, reporting whether it did.
+	CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error)
+	// Close releases the connections of the store.
+	Close() error
+}
+
+// GetInt returns the value of a counter, zero if it does not exist.
+func GetInt(ctx context.Context, store StateStore, key string) (int64, error) {
+	value, err := store.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	count, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("statestore: value of %s is not a counter: %w", key, err)
+	}
+	return count, nil
+}
+
+// GetInts returns the values of counters in order, zero for those that do not exist.
+func GetInts(ctx context.Context, store StateStore, keys []string) ([]int64, error) {
+	values, err := store.GetMany(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]int64, len(keys))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		counts[i], err = strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("statestore: value of %s is not a counter: %w", keys[i], err)
+		}
+	}
+	return counts, nil
+}
+
+// Config represents the configuration for the state store.
+type Config struct {
+	Enabled   bool           `json:"enabled"`
+	Type      StateStoreType `json:"type"`
+	KeyPrefix *string        `json:"key_prefix,omitempty"` // DefaultKeyPrefix if nil
+	Config    any            `json:"config"`
+}
+
+// UnmarshalJSON unmarshals the config from JSON.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type TempConfig struct {
+		Enabled   bool            `json:"enabled"`
+		Type      string          `json:"type"`
+		KeyPrefix *string         `json:"key_prefix,omitempty"`
+		Config    json.RawMessage `json:"config"` // Keep as raw JSON
+	}
+
+	var temp TempConfig
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	c.Enabled = temp.Enabled
+	c.Type = StateStoreType(temp.Type)
+	c.KeyPrefix = temp.KeyPrefix
+
+	switch c.Type {
+	case StateStoreTypeMemory:
+		c.Config = nil
+	case StateStoreTypeRedis:
+		var redisConfig RedisConfig
+		if err := json.Unmarshal(temp.Config, &redisConfig); err != nil {
+			return fmt.Errorf("failed to unmarshal redis config: %w", err)
+		}
+		c.Config = redisConfig
+	case StateStoreTypeEtcd:
+		var etcdConfig EtcdConfig
+		if err := json.Unmarshal(temp.Config, &etcdConfig); err != nil {
+			return fmt.Errorf("failed to unmarshal etcd config: %w", err)
+		}
+		c.Config = etcdConfig
+	case StateStoreTypePostgres:
+		var postgresConfig PostgresConfig
+		if err := json.Unmarshal(temp.Config, &postgresConfig); err != nil {
+			return fmt.Errorf("failed to unmarshal postgres config: %w", err)
+		}
+		c.Config = postgresConfig
+	default:
+		return fmt.Errorf("unknown state store type: %s", temp.Type)
+	}
+
+	return nil
+}
+
+// NewStateStore returns a new state store based on the configuration.
+func NewStateStore(ctx context.Context, config *Config, logger schemas.Logger) (StateStore, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if !config.Enabled {
+		return nil, fmt.Errorf("state store is disabled")
+	}
+
+	var store StateStore
+	var err error
+	switch config.Type {
+	case StateStoreTypeMemory:
+		store = NewMemoryStore()
+	case StateStoreTypeRedis:
+		redisConfig, ok := config.Config.(RedisConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid redis config")
+		}
+		store, err = newRedisStore(ctx, redisConfig, logger)
+	case StateStoreTypeEtcd:
+		etcdConfig, ok := config.Config.(EtcdConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid etcd config")
+		}
+		store, err = newEtcdStore(ctx, etcdConfig, logger)
+	case StateStoreTypePostgres:
+		postgresConfig, ok := config.Config.(PostgresConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid postgres config")
+		}
+		store, err = newPostgresStore(ctx, postgresConfig, logger)
+	default:
+		return nil, fmt.Errorf("invalid state store type: %s", config.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := DefaultKeyPrefix
+	if config.KeyPrefix != nil {
+		prefix = *config.KeyPrefix
+	}
+	if prefix == "" {
+		return store, nil
+	}
+	return &prefixedStore{store: store, prefix: prefix}, nil
+}
+
+// prefixedStore prepends a prefix to the keys of another store.
+type prefixedStore struct {
+	store  StateStore
+	prefix string
+}
+
+func (s *prefixedStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.store.Get(ctx, s.prefix+key)
+}
+
+func (s *prefixedStore) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = s.prefix + key
+	}
+	return s.store.GetMany(ctx, prefixed)
+}
+
+func (s *prefixedStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.store.Set(ctx, s.prefix+key, value, ttl)
+}
+
+func (s *prefixedStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.store.SetNX(ctx, s.prefix+key, value, ttl)
+}
+
+func (s *prefixedStore) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	return s.store.IncrBy(ctx, s.prefix+key, delta, ttl)
+}
+
+func (s *prefixedStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	return s.store.CompareAndSwap(ctx, s.prefix+key, old, value, ttl)
+}
+
+func (s *prefixedStore) Delete(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, s.prefix+key)
+}
+
+func (s *prefixedStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	return s.store.CompareAndDelete(ctx, s.prefix+key, old)
+}
+
+func (s *prefixedStore) Close() error {
+	return s.store.Close()
+}
+
+// withTimeout bounds an operation when a timeout is configured.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return ctx, func() {}
+}
@@ -0,0 +1,327 @@
+// Package termfilter matches banned-term lists in text with an Aho-Corasick automaton, including
+// text streamed in parts, and provides a plugin that masks the terms or aborts on them.
+package termfilter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Action is what happens when a term of a list is found.
+type Action string
+
+const (
+	ActionMask  Action = "mask"  // Replace the term in the text
+	ActionAbort Action = "abort" // Reject the request or end the stream
+)
+
+// TermList is a named list of terms sharing an action.
+type TermList struct {
+	Name      string   `json:"name"`
+	Terms     []string `json:"terms"`
+	Action    Action   `json:"action,omitempty"`     // ActionMask if empty
+	WholeWord bool     `json:"whole_word,omitempty"` // Only match terms not surrounded by letters, digits or underscores
+}
+
+// Config configures the filter and plugin.
+type Config struct {
+	Lists         []TermList `json:"lists"`
+	CaseSensitive bool       `json:"case_sensitive,omitempty"`
+	Replacement   string     `json:"replacement,omitempty"`  // Replaces masked terms, each character is replaced by '*' if empty
+	FilterInput   bool       `json:"filter_input,omitempty"` // Also filter the text of request messages
+}
+
+// Match is an occurrence of a term.
+type Match struct {
+	Term   string `json:"term"` // Term as configured
+	List   string `json:"list"`
+	Action Action `json:"action"`
+	Offset int    `json:"offset"` // Offset of the term in the scanned text, in characters
+}
+
+// pattern is a term of a list.
+type pattern struct {
+	term      string
+	list      *TermList
+	length    int // In characters
+	wholeWord bool
+}
+
+// node is a state of the automaton.
+type node struct {
+	next    map[rune]int32
+	fail    int32
+	depth   int     // Length of the prefix the state stands for
+	outputs []int32 // Patterns ending at the state, including through fail links
+}
+
+// Filter is an Aho-Corasick automaton of the terms of the configured lists. It is immutable
+// and safe for concurrent use; each text or stream is scanned with its own Scanner.
+type Filter struct {
+	nodes         []node
+	patterns      []pattern
+	caseSensitive bool
+	replacement   string
+}
+
+// NewFilter builds the automaton of the configured lists.
+func NewFilter(config Config) (*Filter, error) {
+	f := &Filter{
+		nodes:         []node{{next: make(map[rune]int32)}},
+		caseSensitive: config.CaseSensitive,
+		replacement:   config.Replacement,
+	}
+
+	for i := range config.Lists {
+		list := &config.Lists[i]
+		switch list.Action {
+		case "":
+			list.Action = ActionMask
+		case ActionMask, ActionAbort:
+		default:
+			return nil, fmt.Errorf("invalid action %q for term list %s: expected mask or abort", list.Action, list.Name)
+		}
+
+		for _, term := range list.Terms {
+			if strings.TrimSpace(term) == "" {
+				continue
+			}
+			f.insert(term, list)
+		}
+	}
+	if len(f.patterns) == 0 {
+		return nil, fmt.Errorf("at least one term is required")
+	}
+
+	f.link()
+	return f, nil
+}
+
+// insert adds a term to the trie.
+func (f *Filter) insert(term string, list *TermList) {
+	state := int32(0)
+	length := 0
+	for _, r := range term {
+		r = f.fold(r)
+		next, ok := f.nodes[state].next[r]
+		if !ok {
+			next = int32(len(f.nodes))
+			f.nodes = append(f.nodes, node{next: make(map[rune]int32), depth: f.nodes[state].depth + 1})
+			f.nodes[state].next[r] = next
+		}
+		state = next
+		length++
+	}
+
+	f.patterns = append(f.patterns, pattern{term: term, list: list, length: length, wholeWord: list.WholeWord})
+	f.nodes[state].outputs = append(f.nodes[state].outputs, int32(len(f.patterns)-1))
+}
+
+// link sets the fail links breadth first and merges the outputs of each state's fail state into its own.
+func (f *Filter) link() {
+	queue := make([]int32, 0, len(f.nodes))
+	for _, child := range f.nodes[0].next {
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for r, child := range f.nodes[state].next {
+			fail := f.nodes[state].fail
+			for {
+				if next, ok := f.nodes[fail].next[r]; ok && next != child {
+					f.nodes[child].fail = next
+					break
+				}
+				if fail == 0 {
+					f.nodes[child].fail = 0
+					break
+				}
+				fail = f.nodes[fail].fail
+			}
+			f.nodes[child].outputs = append(f.nodes[child].outputs, f.nodes[f.nodes[child].fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// step returns the state after reading r from state.
+func (f *Filter) step(state int32, r rune) int32 {
+	for {
+		if next, ok := f.nodes[state].next[r]; ok {
+			return next
+		}
+		if state == 0 {
+			return 0
+		}
+		state = f.nodes[state].fail
+	}
+}
+
+func (f *Filter) fold(r rune) rune {
+	if f.caseSensitive {
+		return r
+	}
+	return unicode.ToLower(r)
+}
+
+// Apply scans a whole text, returning it with the masked terms replaced and every match.
+func (f *Filter) Apply(text string) (string, []Match) {
+	scanner := f.NewScanner()
+	out, matches := scanner.Write(text)
+	rest, more := scanner.Flush()
+	return out + rest, append(matches, more...)
+}
+
+// maskFlag marks the characters of masked terms.
+type maskFlag uint8
+
+const (
+	unmasked maskFlag = iota
+	maskStart
+	maskContinuation
+)
+
+// pendingMatch is a whole-word match waiting for the next character to confirm it ends a word.
+type pendingMatch struct {
+	start   int
+	pattern int32
+}
+
+// Scanner scans a text streamed in parts. Terms split across parts are found: characters that
+// may start a term are held back until the term is ruled out or masked, so output lags input
+// by at most the length of the longest term. A Scanner is not safe for concurrent use.
+type Scanner struct {
+	filter *Filter
+	state  int32
+
+	buf     []rune     // Characters read but not returned yet
+	flags   []maskFlag // Mask flag of each character of buf
+	pos     int        // Characters read
+	before  rune       // Last character returned, 0 at the start
+	pending []pendingMatch
+}
+
+// NewScanner returns a scanner of a new text.
+func (f *Filter) NewScanner() *Scanner {
+	return &Scanner{filter: f}
+}
+
+// Write scans the next part of the text. It returns the text that can be released, with
+// masked terms replaced, and the matches confirmed by this part.
+func (s *Scanner) Write(text string) (string, []Match) {
+	var matches []Match
+	for _, r := range text {
+		// Pending whole-word matches end right before this character
+		if len(s.pending) > 0 {
+			if !isWordChar(r) {
+				for _, p := range s.pending {
+					matches = append(matches, s.confirm(p.start, p.pattern))
+				}
+			}
+			s.pending = s.pending[:0]
+		}
+
+		s.state = s.filter.step(s.state, s.filter.fold(r))
+		s.buf = append(s.buf, r)
+		s.flags = append(s.flags, unmasked)
+		s.pos++
+
+		for _, index := range s.filter.nodes[s.state].outputs {
+			p := s.filter.patterns[index]
+			start := s.pos - p.length
+			if !p.wholeWord {
+				matches = append(matches, s.confirm(start, index))
+				continue
+			}
+			if start > 0 && isWordChar(s.runeAt(start-1)) {
+				continue
+			}
+			s.pending = append(s.pending, pendingMatch{start: start, pattern: index})
+		}
+	}
+
+	// Hold back the characters that may still be part of a term
+	hold := s.filter.nodes[s.state].depth
+	for _, p := range s.pending {
+		hold = max(hold, s.pos-p.start)
+	}
+	return s.release(len(s.buf) - hold), matches
+}
+
+// Flush ends the text, confirming pending matches, and returns the rest of the text.
+func (s *Scanner) Flush() (string, []Match) {
+	var matches []Match
+	for _, p := range s.pending {
+		matches = append(matches, s.confirm(p.start, p.pattern))
+	}
+	s.pending = nil
+	out := s.release(len(s.buf))
+	s.state = 0
+	return out, matches
+}
+
+// confirm records a match ending at the current position, masking its characters if its list
+// masks terms. A term overlapping or touching the previous masked term is masked with it.
+func (s *Scanner) confirm(start int, index int32) Match {
+	p := s.filter.patterns[index]
+	if p.list.Action == ActionMask {
+		offset := s.pos - len(s.buf)
+		for i := start - offset; i < start-offset+p.length; i++ {
+			switch {
+			case s.flags[i] != unmasked:
+			case i > 0 && s.flags[i-1] != unmasked:
+				s.flags[i] = maskContinuation
+			default:
+				s.flags[i] = maskStart
+			}
+		}
+	}
+	return Match{Term: p.term, List: p.list.Name, Action: p.list.Action, Offset: start}
+}
+
+// release returns the first n buffered characters, rendering masked terms.
+func (s *Scanner) release(n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	for i := 0; i < n; i++ {
+		switch s.flags[i] {
+		case unmasked:
+			out.WriteRune(s.buf[i])
+		case maskStart:
+			if s.filter.replacement != "" {
+				out.WriteString(s.filter.replacement)
+			} else {
+				out.WriteRune('*')
+			}
+		case maskContinuation:
+			if s.filter.replacement == "" {
+				out.WriteRune('*')
+			}
+		}
+	}
+
+	s.before = s.buf[n-1]
+	s.buf = append(s.buf[:0], s.buf[n:]...)
+	s.flags = append(s.flags[:0], s.flags[n:]...)
+	return out.String()
+}
+
+// runeAt returns the character at a position that is buffered or was the last one released.
+func (s *Scanner) runeAt(position int) rune {
+	offset := s.pos - len(s.buf)
+	if position >= offset {
+		return s.buf[position-offset]
+	}
+	return s.before
+}
+
+// isWordChar reports whether r is part of a word for whole-word matching.
+func isWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
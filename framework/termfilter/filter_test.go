@@ -0,0 +1,104 @@
+package termfilter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scanParts streams the parts through a scanner and returns the released text and the matches.
+func scanParts(filter *Filter, parts ...string) (string, []Match) {
+	scanner := filter.NewScanner()
+	var out strings.Builder
+	var matches []Match
+	for _, part := range parts {
+		text, found := scanner.Write(part)
+		out.WriteString(text)
+		matches = append(matches, found...)
+	}
+	text, found := scanner.Flush()
+	out.WriteString(text)
+	return out.String(), append(matches, found...)
+}
+
+func TestFilterMasksTermsSplitAcrossParts(t *testing.T) {
+	filter, err := NewFilter(Config{Lists: []TermList{{Name: "secrets", Terms: []string{"project falcon", "falcon-9"}}}})
+	require.NoError(t, err)
+
+	out, matches := scanParts(filter, "The Proj", "ect Fal", "con launch and falcon", "-9!")
+	assert.Equal(t, "The ************** launch and ********!", out)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "project falcon", matches[0].Term)
+	assert.Equal(t, 4, matches[0].Offset)
+	assert.Equal(t, "falcon-9", matches[1].Term)
+}
+
+func TestFilterHoldsBackOnlyPossibleTerms(t *testing.T) {
+	filter, err := NewFilter(Config{Lists: []TermList{{Terms: []string{"secret"}}}})
+	require.NoError(t, err)
+
+	scanner := filter.NewScanner()
+	out, _ := scanner.Write("a sec")
+	assert.Equal(t, "a ", out)
+	out, _ = scanner.Write("ond")
+	assert.Equal(t, "second", out)
+}
+
+func TestFilterReplacementAndOverlaps(t *testing.T) {
+	filter, err := NewFilter(Config{
+		Lists:       []TermList{{Name: "words", Terms: []string{"abc", "bcd", "c"}}},
+		Replacement: "[REDACTED]",
+	})
+	require.NoError(t, err)
+
+	out, matches := scanParts(filter, "xabcdx c")
+	assert.Equal(t, "x[REDACTED]x [REDACTED]", out)
+	assert.Len(t, matches, 4)
+}
+
+func TestFilterWholeWord(t *testing.T) {
+	filter, err := NewFilter(Config{Lists: []TermList{{Name: "words", Terms: []string{"ass"}, WholeWord: true}}})
+	require.NoError(t, err)
+
+	out, matches := scanParts(filter, "a class of as", "s, ASS")
+	assert.Equal(t, "a class of ***, ***", out)
+	assert.Len(t, matches, 2)
+
+	out, matches = scanParts(filter, "assess ass")
+	assert.Equal(t, "assess ***", out)
+	assert.Len(t, matches, 1)
+}
+
+func TestFilterCaseSensitive(t *testing.T) {
+	filter, err := NewFilter(Config{Lists: []TermList{{Terms: []string{"Falcon"}}}, CaseSensitive: true})
+	require.NoError(t, err)
+
+	out, matches := filter.Apply("falcon Falcon")
+	assert.Equal(t, "falcon ******", out)
+	assert.Len(t, matches, 1)
+}
+
+func TestFilterAbortListsDoNotMask(t *testing.T) {
+	filter, err := NewFilter(Config{Lists: []TermList{
+		{Name: "blocked", Terms: []string{"detonate"}, Action: ActionAbort},
+		{Name: "masked", Terms: []string{"codename"}},
+	}})
+	require.NoError(t, err)
+
+	out, matches := scanParts(filter, "codename: deto", "nate")
+	assert.Equal(t, "********: detonate", out)
+	require.Len(t, matches, 2)
+	assert.Equal(t, ActionMask, matches[0].Action)
+	assert.Equal(t, ActionAbort, matches[1].Action)
+	assert.Equal(t, "blocked", matches[1].List)
+}
+
+func TestNewFilterValidatesConfig(t *testing.T) {
+	_, err := NewFilter(Config{Lists: []TermList{{Terms: []string{"x"}, Action: "block"}}})
+	assert.Error(t, err)
+
+	_, err = NewFilter(Config{Lists: []TermList{{Terms: []string{" "}}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,244 @@
+package termfilter
+
+import (
+	"context"
+	"fmt"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	PluginName = "term-filter"
+
+	// ErrorType is the type of the errors of requests and responses containing a term of an abort list
+	ErrorType = "content_filtered"
+)
+
+// scannersContextKey is the context key of the scanners of a stream.
+type scannersContextKey struct{}
+
+// streamScanners holds a scanner per choice of a stream. Chunks of a stream are processed
+// sequentially, so it needs no locking.
+type streamScanners map[int]*Scanner
+
+// Plugin filters banned terms out of chat and text completion responses, and optionally out of
+// request messages. Terms of mask lists are replaced, terms of abort lists reject the request
+// or end the response with a content_filtered error. Streams are filtered as they are sent:
+// a term split across chunks is still found, and aborted streams cancel the upstream request.
+type Plugin struct {
+	filter      *Filter
+	filterInput bool
+	logger      schemas.Logger
+}
+
+// Init builds the filter of the configured lists and returns the plugin.
+func Init(config Config, logger schemas.Logger) (*Plugin, error) {
+	filter, err := NewFilter(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Plugin{
+		filter:      filter,
+		filterInput: config.FilterInput,
+		logger:      logger,
+	}, nil
+}
+
+// GetName returns the name of the plugin.
+func (p *Plugin) GetName() string {
+	return PluginName
+}
+
+// GetFilter returns the filter of the plugin.
+func (p *Plugin) GetFilter() *Filter {
+	return p.filter
+}
+
+// PreHook filters the request messages when input filtering is enabled, and prepares the
+// scanners of chat streams.
+func (p *Plugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	if p.filterInput {
+		if match, ok := p.filterRequest(req); !ok {
+			p.logger.Debug("rejected request to %s: term of list %s found in the input", req.Model, match.List)
+			return req, &schemas.PluginShortCircuit{
+				Error: filteredError(400, fmt.Sprintf("request contains a term of the banned-term list %s", match.List)),
+			}, nil
+		}
+	}
+
+	requestType, _ := (*ctx).Value(schemas.BifrostContextKeyRequestType).(schemas.RequestType)
+	if requestType == schemas.ChatCompletionStreamRequest {
+		*ctx = context.WithValue(*ctx, scannersContextKey{}, make(streamScanners))
+	}
+	return req, nil, nil
+}
+
+// filterRequest masks the terms of the request's text inputs. It returns false with the match
+// when a term of an abort list is found.
+func (p *Plugin) filterRequest(req *schemas.BifrostRequest) (Match, bool) {
+	if req.Input.TextCompletionInput != nil {
+		if match, ok := p.filterText(req.Input.TextCompletionInput); !ok {
+			return match, false
+		}
+	}
+	if req.Input.ChatCompletionInput == nil {
+		return Match{}, true
+	}
+	for i := range *req.Input.ChatCompletionInput {
+		if match, ok := p.filterContent(&(*req.Input.ChatCompletionInput)[i].Content); !ok {
+			return match, false
+		}
+	}
+	return Match{}, true
+}
+
+// filterContent masks the terms of the text of a message content.
+func (p *Plugin) filterContent(content *schemas.MessageContent) (Match, bool) {
+	if content.ContentStr != nil {
+		if match, ok := p.filterText(content.ContentStr); !ok {
+			return match, false
+		}
+	}
+	if content.ContentBlocks != nil {
+		for i := range *content.ContentBlocks {
+			block := &(*content.ContentBlocks)[i]
+			if block.Text == nil {
+				continue
+			}
+			if match, ok := p.filterText(block.Text); !ok {
+				return match, false
+			}
+		}
+	}
+	return Match{}, true
+}
+
+// filterText masks the terms of a text in place. It returns false with the match, leaving the
+// text unchanged, when a term of an abort list is found.
+func (p *Plugin) filterText(text *string) (Match, bool) {
+	filtered, matches := p.filter.Apply(*text)
+	if match, ok := abortMatch(matches); ok {
+		return match, false
+	}
+	if len(matches) > 0 {
+		*text = filtered
+	}
+	return Match{}, true
+}
+
+// PostHook filters the text of responses and stream chunks.
+func (p *Plugin) PostHook(ctx *context.Context, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	if result == nil || bifrostErr != nil {
+		return result, bifrostErr, nil
+	}
+
+	if scanners, ok := (*ctx).Value(scannersContextKey{}).(streamScanners); ok {
+		return p.filterChunk(ctx, scanners, result)
+	}
+
+	for i := range result.Choices {
+		choice := &result.Choices[i]
+		if choice.BifrostNonStreamResponseChoice == nil {
+			continue
+		}
+		if match, ok := p.filterContent(&choice.Message.Content); !ok {
+			p.logger.Debug("rejected response of %s: term of list %s found in the output", result.Model, match.List)
+			return nil, filteredError(422, fmt.Sprintf("response contains a term of the banned-term list %s", match.List)), nil
+		}
+	}
+	return result, nil, nil
+}
+
+// filterChunk runs the content of a chunk through the scanner of its choice. Held back text is
+// released with the final chunk. A term of an abort list ends the stream.
+func (p *Plugin) filterChunk(ctx *context.Context, scanners streamScanners, result *schemas.BifrostResponse) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	var matches []Match
+	for i := range result.Choices {
+		choice := &result.Choices[i]
+		if choice.BifrostStreamResponseChoice == nil || choice.Delta.Content == nil {
+			continue
+		}
+
+		scanner, ok := scanners[choice.Index]
+		if !ok {
+			scanner = p.filter.NewScanner()
+			scanners[choice.Index] = scanner
+		}
+		out, found := scanner.Write(*choice.Delta.Content)
+		choice.Delta.Content = &out
+		matches = append(matches, found...)
+	}
+
+	if bifrost.IsFinalChunk(ctx) {
+		for index, scanner := range scanners {
+			rest, found := scanner.Flush()
+			matches = append(matches, found...)
+			if rest != "" {
+				appendChunkContent(result, index, rest)
+			}
+		}
+	}
+
+	if match, ok := abortMatch(matches); ok {
+		p.logger.Debug("aborting stream of %s: term of list %s found in the output", result.Model, match.List)
+
+		// The error ends the stream for the plugins running after this one
+		*ctx = context.WithValue(*ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+		bifrostErr := filteredError(422, fmt.Sprintf("response contains a term of the banned-term list %s", match.List))
+		bifrostErr.StreamControl = &schemas.StreamControl{AbortStream: bifrost.Ptr(true)}
+		return nil, bifrostErr, nil
+	}
+	return result, nil, nil
+}
+
+// appendChunkContent appends text to the delta content of a choice of a chunk, adding the
+// choice if the chunk does not have it.
+func appendChunkContent(result *schemas.BifrostResponse, index int, text string) {
+	for i := range result.Choices {
+		choice := &result.Choices[i]
+		if choice.Index != index || choice.BifrostStreamResponseChoice == nil {
+			continue
+		}
+		if choice.Delta.Content != nil {
+			text = *choice.Delta.Content + text
+		}
+		choice.Delta.Content = &text
+		return
+	}
+	result.Choices = append(result.Choices, schemas.BifrostResponseChoice{
+		Index: index,
+		BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{
+			Delta: schemas.BifrostStreamDelta{Content: &text},
+		},
+	})
+}
+
+// abortMatch returns the first match of an abort list.
+func abortMatch(matches []Match) (Match, bool) {
+	for _, match := range matches {
+		if match.Action == ActionAbort {
+			return match, true
+		}
+	}
+	return Match{}, false
+}
+
+func filteredError(statusCode int, message string) *schemas.BifrostError {
+	return &schemas.BifrostError{
+		IsBifrostError: true,
+		Type:           bifrost.Ptr(ErrorType),
+		StatusCode:     bifrost.Ptr(statusCode),
+		Origin:         schemas.ErrorOriginPolicy,
+		Error: schemas.ErrorField{
+			Type:    bifrost.Ptr(ErrorType),
+			Message: message,
+		},
+		AllowFallbacks: bifrost.Ptr(false),
+	}
+}
+
+// Cleanup is a no-op, scanners live in the request context.
+func (p *Plugin) Cleanup() error {
+	return nil
+}
@@ -0,0 +1,235 @@
+package usageledger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/pricing"
+)
+
+const (
+	PluginName = "usage_ledger"
+
+	// DefaultFlushInterval is the longest entries wait before they are written.
+	DefaultFlushInterval = time.Second
+	// DefaultBatchSize is the number of entries written in one transaction.
+	DefaultBatchSize = 100
+	// bufferSize bounds the entries waiting to be written; beyond it entries are dropped rather
+	// than slowing down requests.
+	bufferSize = 10000
+	// writeTimeout bounds the write of a batch.
+	writeTimeout = 30 * time.Second
+)
+
+// PluginConfig configures the usage ledger plugin.
+type PluginConfig struct {
+	Ledger        Config `json:"ledger"`
+	FlushInterval string `json:"flush_interval,omitempty"` // e.g. "5s", DefaultFlushInterval if empty
+	BatchSize     int    `json:"batch_size,omitempty"`     // DefaultBatchSize if 0
+}
+
+type startTimeContextKey struct{}
+
+// Plugin records a ledger entry for every request once it completes, including failed ones. The
+// tenant and key are the customer or team and the virtual key of governance. Entries are written
+// in batches in the background, so requests never wait for the database.
+type Plugin struct {
+	ledger        Ledger
+	pricing       *pricing.PricingManager
+	logger        schemas.Logger
+	flushInterval time.Duration
+	batchSize     int
+
+	entries chan Entry
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Init opens the configured ledger and starts writing entries to it. pricingManager computes the
+// cost of entries; without it costs are 0.
+func Init(ctx context.Context, config PluginConfig, pricingManager *pricing.PricingManager, logger schemas.Logger) (*Plugin, error) {
+	flushInterval := DefaultFlushInterval
+	if config.FlushInterval != "" {
+		var err error
+		flushInterval, err = time.ParseDuration(config.FlushInterval)
+		if err != nil || flushInterval <= 0 {
+			return nil, fmt.Errorf("invalid usage ledger flush interval %q", config.FlushInterval)
+		}
+	}
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	ledger, err := NewLedger(ctx, &config.Ledger, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plugin{
+		ledger:        ledger,
+		pricing:       pricingManager,
+		logger:        logger,
+		flushInterval: flushInterval,
+		batchSize:     batchSize,
+		entries:       make(chan Entry, bufferSize),
+		done:          make(chan struct{}),
+	}
+	go p.writeLoop()
+	return p, nil
+}
+
+// GetName returns the name of the plugin.
+func (p *Plugin) GetName() string {
+	return PluginName
+}
+
+// GetLedger returns the ledger of the plugin, to query it.
+func (p *Plugin) GetLedger() Ledger {
+	return p.ledger
+}
+
+// PreHook notes when the request started, for its latency.
+func (p *Plugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	*ctx = context.WithValue(*ctx, startTimeContextKey{}, time.Now())
+	return req, nil, nil
+}
+
+// PostHook records the entry of a request. Streams are recorded once, on their last chunk, which
+// carries their usage.
+func (p *Plugin) PostHook(ctx *context.Context, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	requestType, _ := (*ctx).Value(schemas.BifrostContextKeyRequestType).(schemas.RequestType)
+	if isStream(requestType) && bifrostErr == nil {
+		if isFinalChunk, ok := (*ctx).Value(schemas.BifrostContextKeyStreamEndIndicator).(bool); !ok || !isFinalChunk {
+			return result, bifrostErr, nil
+		}
+	}
+
+	entry := p.newEntry(*ctx, requestType, result, bifrostErr)
+	select {
+	case p.entries <- entry:
+	default:
+		p.logger.Warn("usage ledger buffer is full, dropping the entry of a %s request to %s", entry.RequestType, entry.Model)
+	}
+	return result, bifrostErr, nil
+}
+
+// Cleanup writes the entries still buffered and closes the ledger.
+func (p *Plugin) Cleanup() error {
+	p.stop()
+	return p.ledger.Close()
+}
+
+// stop writes the entries still buffered and stops writing.
+func (p *Plugin) stop() {
+	p.once.Do(func() {
+		close(p.entries)
+		<-p.done
+	})
+}
+
+// newEntry builds the entry of a completed request.
+func (p *Plugin) newEntry(ctx context.Context, requestType schemas.RequestType, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) Entry {
+	entry := Entry{
+		Timestamp:   time.Now(),
+		RequestType: requestType,
+		Outcome:     OutcomeSuccess,
+		Key:         stringFromContext(ctx, schemas.BifrostContextKey("x-bf-vk")),
+		RequestID:   stringFromContext(ctx, schemas.BifrostContextKey("request-id")),
+	}
+	entry.Tenant = stringFromContext(ctx, schemas.BifrostContextKey("x-bf-customer"))
+	if entry.Tenant == "" {
+		entry.Tenant = stringFromContext(ctx, schemas.BifrostContextKey("x-bf-team"))
+	}
+	entry.Provider, _ = ctx.Value(schemas.BifrostContextKeyRequestProvider).(schemas.ModelProvider)
+	entry.Model, _ = ctx.Value(schemas.BifrostContextKeyRequestModel).(string)
+	if start, ok := ctx.Value(startTimeContextKey{}).(time.Time); ok {
+		entry.Latency = time.Since(start)
+	}
+
+	if bifrostErr != nil {
+		entry.Outcome = OutcomeError
+		if bifrostErr.Provider != "" {
+			entry.Provider = bifrostErr.Provider
+		}
+		if bifrostErr.StatusCode != nil {
+			entry.StatusCode = *bifrostErr.StatusCode
+		}
+		return entry
+	}
+	if result == nil {
+		return entry
+	}
+
+	// The provider and model that served the request, which differ from the requested ones after
+	// a fallback
+	if result.ExtraFields.Provider != "" {
+		entry.Provider = result.ExtraFields.Provider
+	}
+	if result.Model != "" {
+		entry.Model = result.Model
+	}
+	if result.Usage != nil {
+		entry.PromptTokens = result.Usage.PromptTokens
+		entry.CompletionTokens = result.Usage.CompletionTokens
+		entry.TotalTokens = result.Usage.TotalTokens
+	}
+	if p.pricing != nil {
+		entry.Cost = p.pricing.CalculateCost(result, entry.Provider, entry.Model, requestType)
+	}
+	return entry
+}
+
+// writeLoop writes the buffered entries in batches, when a batch is full or the flush interval
+// elapses, until the plugin is cleaned up.
+func (p *Plugin) writeLoop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Entry, 0, p.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+		defer cancel()
+		if err := p.ledger.Record(ctx, batch); err != nil {
+			p.logger.Warn("failed to write %d usage ledger entries: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-p.entries:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= p.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func isStream(requestType schemas.RequestType) bool {
+	switch requestType {
+	case schemas.ChatCompletionStreamRequest, schemas.SpeechStreamRequest, schemas.TranscriptionStreamRequest:
+		return true
+	}
+	return false
+}
+
+func stringFromContext(ctx context.Context, key any) string {
+	value, _ := ctx.Value(key).(string)
+	return value
+}
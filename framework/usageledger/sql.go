@@ -0,0 +1,290 @@
+package usageledger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+
+	_ "github.com/mattn/go-sqlite3" // Registers the sqlite3 driver
+)
+
+const defaultPostgresDriver = "pgx"
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// groupColumns maps the dimensions entries are grouped by to their column.
+var groupColumns = map[GroupBy]string{
+	GroupByDay:      "day",
+	GroupByTenant:   "tenant",
+	GroupByKey:      "virtual_key",
+	GroupByProvider: "provider",
+	GroupByModel:    "model",
+	GroupByOutcome:  "outcome",
+}
+
+// SQLLedger is a ledger in a SQL table. SQLite and Postgres share the same schema and queries:
+// timestamps are Unix milliseconds and the UTC day of each entry is stored with it, so entries
+// are grouped by day the same way in both.
+type SQLLedger struct {
+	db       *sql.DB
+	table    string
+	postgres bool // Postgres numbers its placeholders
+	logger   schemas.Logger
+}
+
+func newSQLiteLedger(ctx context.Context, config SQLiteConfig, logger schemas.Logger) (*SQLLedger, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("usage ledger sqlite path is required")
+	}
+	if dir := filepath.Dir(config.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create usage ledger directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", config.Path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger database: %w", err)
+	}
+	// SQLite allows a single writer
+	db.SetMaxOpenConns(1)
+	return newSQLLedger(ctx, db, config.Table, false, logger)
+}
+
+func newPostgresLedger(ctx context.Context, config PostgresConfig, logger schemas.Logger) (*SQLLedger, error) {
+	if config.DSN == "" {
+		return nil, fmt.Errorf("usage ledger postgres dsn is required")
+	}
+	if config.DriverName == "" {
+		config.DriverName = defaultPostgresDriver
+	}
+
+	db, err := sql.Open(config.DriverName, config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger connection: %w", err)
+	}
+	if config.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	return newSQLLedger(ctx, db, config.Table, true, logger)
+}
+
+func newSQLLedger(ctx context.Context, db *sql.DB, table string, postgres bool, logger schemas.Logger) (*SQLLedger, error) {
+	if table == "" {
+		table = DefaultTable
+	}
+	if !tableName.MatchString(table) {
+		db.Close()
+		return nil, fmt.Errorf("invalid usage ledger table name %q", table)
+	}
+
+	ledger := &SQLLedger{db: db, table: table, postgres: postgres, logger: logger}
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			timestamp_ms BIGINT NOT NULL,
+			day TEXT NOT NULL,
+			request_id TEXT NOT NULL DEFAULT '',
+			tenant TEXT NOT NULL DEFAULT '',
+			virtual_key TEXT NOT NULL DEFAULT '',
+			provider TEXT NOT NULL,
+			model TEXT NOT NULL,
+			request_type TEXT NOT NULL DEFAULT '',
+			prompt_tokens BIGINT NOT NULL DEFAULT 0,
+			completion_tokens BIGINT NOT NULL DEFAULT 0,
+			total_tokens BIGINT NOT NULL DEFAULT 0,
+			cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+			latency_ms BIGINT NOT NULL DEFAULT 0,
+			outcome TEXT NOT NULL,
+			status_code INTEGER NOT NULL DEFAULT 0
+		)`, table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %[1]s_timestamp ON %[1]s (timestamp_ms)`, table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %[1]s_day_key_model ON %[1]s (day, virtual_key, model)`, table),
+	}
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create usage ledger table: %w", err)
+		}
+	}
+	return ledger, nil
+}
+
+func (l *SQLLedger) Record(ctx context.Context, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statement, err := tx.PrepareContext(ctx, l.rebind(fmt.Sprintf(`INSERT INTO %s (
+		timestamp_ms, day, request_id, tenant, virtual_key, provider, model, request_type,
+		prompt_tokens, completion_tokens, total_tokens, cost, latency_ms, outcome, status_code
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, l.table)))
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	for _, entry := range entries {
+		timestamp := entry.Timestamp.UTC()
+		if _, err := statement.ExecContext(ctx,
+			timestamp.UnixMilli(), timestamp.Format(time.DateOnly), entry.RequestID, entry.Tenant, entry.Key,
+			string(entry.Provider), entry.Model, string(entry.RequestType),
+			entry.PromptTokens, entry.CompletionTokens, entry.TotalTokens, entry.Cost,
+			entry.Latency.Milliseconds(), string(entry.Outcome), entry.StatusCode,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (l *SQLLedger) Aggregate(ctx context.Context, query Query) ([]Aggregate, error) {
+	var columns []string
+	for _, dimension := range query.GroupBy {
+		column, ok := groupColumns[dimension]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown group by %q", ErrInvalidQuery, dimension)
+		}
+		columns = append(columns, column)
+	}
+
+	var conditions []string
+	var args []any
+	if !query.From.IsZero() {
+		conditions = append(conditions, "timestamp_ms >= ?")
+		args = append(args, query.From.UnixMilli())
+	}
+	if !query.To.IsZero() {
+		conditions = append(conditions, "timestamp_ms < ?")
+		args = append(args, query.To.UnixMilli())
+	}
+	for _, filter := range []struct{ column, value string }{
+		{"tenant", query.Tenant},
+		{"virtual_key", query.Key},
+		{"provider", string(query.Provider)},
+		{"model", query.Model},
+	} {
+		if filter.value != "" {
+			conditions = append(conditions, filter.column+" = ?")
+			args = append(args, filter.value)
+		}
+	}
+
+	selected := append([]string{}, columns...)
+	selected = append(selected,
+		"COUNT(*)",
+		fmt.Sprintf("CAST(COALESCE(SUM(CASE WHEN outcome = '%s' THEN 1 ELSE 0 END), 0) AS BIGINT)", OutcomeError),
+		"CAST(COALESCE(SUM(prompt_tokens), 0) AS BIGINT)",
+		"CAST(COALESCE(SUM(completion_tokens), 0) AS BIGINT)",
+		"CAST(COALESCE(SUM(total_tokens), 0) AS BIGINT)",
+		"COALESCE(SUM(cost), 0)",
+		"CAST(COALESCE(AVG(latency_ms), 0) AS DOUBLE PRECISION)",
+	)
+	statement := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selected, ", "), l.table)
+	if len(conditions) > 0 {
+		statement += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if len(columns) > 0 {
+		statement += fmt.Sprintf(" GROUP BY %[1]s ORDER BY %[1]s", strings.Join(columns, ", "))
+	}
+
+	rows, err := l.db.QueryContext(ctx, l.rebind(statement), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aggregates := []Aggregate{}
+	for rows.Next() {
+		var aggregate Aggregate
+		groups := make([]string, len(columns))
+		targets := make([]any, 0, len(columns)+7)
+		for i := range groups {
+			targets = append(targets, &groups[i])
+		}
+		targets = append(targets,
+			&aggregate.Requests, &aggregate.Failed,
+			&aggregate.PromptTokens, &aggregate.CompletionTokens, &aggregate.TotalTokens,
+			&aggregate.Cost, &aggregate.AvgLatencyMs,
+		)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+		if aggregate.Requests == 0 {
+			// Aggregating no entries without grouping still returns a row
+			continue
+		}
+		for i, dimension := range query.GroupBy {
+			switch dimension {
+			case GroupByDay:
+				aggregate.Day = groups[i]
+			case GroupByTenant:
+				aggregate.Tenant = groups[i]
+			case GroupByKey:
+				aggregate.Key = groups[i]
+			case GroupByProvider:
+				aggregate.Provider = schemas.ModelProvider(groups[i])
+			case GroupByModel:
+				aggregate.Model = groups[i]
+			case GroupByOutcome:
+				aggregate.Outcome = Outcome(groups[i])
+			}
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+	return aggregates, rows.Err()
+}
+
+func (l *SQLLedger) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := l.db.ExecContext(ctx, l.rebind(fmt.Sprintf("DELETE FROM %s WHERE timestamp_ms < ?", l.table)), before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (l *SQLLedger) Close() error {
+	return l.db.Close()
+}
+
+// rebind numbers the placeholders of a statement for Postgres.
+func (l *SQLLedger) rebind(statement string) string {
+	if !l.postgres {
+		return statement
+	}
+	var builder strings.Builder
+	n := 0
+	for _, r := range statement {
+		if r == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
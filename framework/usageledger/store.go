@@ -0,0 +1,197 @@
+// Package usageledger persists one row per request, with who made it, the model that served it,
+// its tokens, cost, latency and outcome, to SQLite or Postgres, and aggregates the rows by day,
+// key and model. It gives small deployments billing reports without an external analytics stack.
+package usageledger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+type LedgerType string
+
+const (
+	LedgerTypeSQLite   LedgerType = "sqlite"
+	LedgerTypePostgres LedgerType = "postgres"
+)
+
+// DefaultTable is the table of the ledger when none is configured.
+const DefaultTable = "bifrost_usage_ledger"
+
+var ErrInvalidQuery = errors.New("usageledger: invalid query")
+
+// Outcome is how a request ended.
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeError   Outcome = "error"
+)
+
+// Entry is the row of a request.
+type Entry struct {
+	Timestamp        time.Time             `json:"timestamp"`
+	RequestID        string                `json:"request_id,omitempty"`
+	Tenant           string                `json:"tenant,omitempty"` // Customer, or team, of the virtual key
+	Key              string                `json:"key,omitempty"`    // Virtual key the request was made with
+	Provider         schemas.ModelProvider `json:"provider"`
+	Model            string                `json:"model"`
+	RequestType      schemas.RequestType   `json:"request_type"`
+	PromptTokens     int                   `json:"prompt_tokens"`
+	CompletionTokens int                   `json:"completion_tokens"`
+	TotalTokens      int                   `json:"total_tokens"`
+	Cost             float64               `json:"cost"` // Dollars, 0 when no pricing is known
+	Latency          time.Duration         `json:"latency"`
+	Outcome          Outcome               `json:"outcome"`
+	StatusCode       int                   `json:"status_code,omitempty"` // Of the provider error, for failed requests
+}
+
+// GroupBy is a dimension entries are aggregated by.
+type GroupBy string
+
+const (
+	GroupByDay      GroupBy = "day" // UTC day, as YYYY-MM-DD
+	GroupByTenant   GroupBy = "tenant"
+	GroupByKey      GroupBy = "key"
+	GroupByProvider GroupBy = "provider"
+	GroupByModel    GroupBy = "model"
+	GroupByOutcome  GroupBy = "outcome"
+)
+
+// Query selects the entries to aggregate and how to group them. Empty filters match every entry;
+// without GroupBy every matching entry is aggregated into a single row.
+type Query struct {
+	From     time.Time // Inclusive, unbounded if zero
+	To       time.Time // Exclusive, unbounded if zero
+	Tenant   string
+	Key      string
+	Provider schemas.ModelProvider
+	Model    string
+	GroupBy  []GroupBy
+}
+
+// Aggregate is the total of the entries of a group. Only the fields of the dimensions grouped by
+// are set.
+type Aggregate struct {
+	Day              string                `json:"day,omitempty"`
+	Tenant           string                `json:"tenant,omitempty"`
+	Key              string                `json:"key,omitempty"`
+	Provider         schemas.ModelProvider `json:"provider,omitempty"`
+	Model            string                `json:"model,omitempty"`
+	Outcome          Outcome               `json:"outcome,omitempty"`
+	Requests         int64                 `json:"requests"`
+	Failed           int64                 `json:"failed"`
+	PromptTokens     int64                 `json:"prompt_tokens"`
+	CompletionTokens int64                 `json:"completion_tokens"`
+	TotalTokens      int64                 `json:"total_tokens"`
+	Cost             float64               `json:"cost"`
+	AvgLatencyMs     float64               `json:"avg_latency_ms"`
+}
+
+// Ledger stores entries and aggregates them.
+type Ledger interface {
+	// Record inserts entries in a single transaction.
+	Record(ctx context.Context, entries []Entry) error
+	// Aggregate returns the totals of the entries matching the query, one per group, ordered by
+	// the dimensions grouped by.
+	Aggregate(ctx context.Context, query Query) ([]Aggregate, error)
+	// DeleteBefore deletes the entries older than a time and returns how many it deleted.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+	// Close releases the connections of the ledger.
+	Close() error
+}
+
+// SQLiteConfig configures a ledger in a SQLite database file.
+type SQLiteConfig struct {
+	Path  string `json:"path"`            // Database file, created if missing - REQUIRED
+	Table string `json:"table,omitempty"` // DefaultTable if empty
+}
+
+// PostgresConfig configures a ledger in Postgres. The ledger uses database/sql, so the binary
+// embedding Bifrost must register the driver, e.g. by importing github.com/jackc/pgx/v5/stdlib.
+type PostgresConfig struct {
+	DSN          string `json:"dsn"`                      // Connection string - REQUIRED
+	DriverName   string `json:"driver_name,omitempty"`    // database/sql driver, "pgx" if empty
+	Table        string `json:"table,omitempty"`          // DefaultTable if empty
+	MaxOpenConns int    `json:"max_open_conns,omitempty"` // Maximum number of open connections (optional)
+}
+
+// Config represents the configuration of the ledger.
+type Config struct {
+	Type   LedgerType `json:"type"`
+	Config any        `json:"config"`
+}
+
+// UnmarshalJSON unmarshals the config from JSON.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type TempConfig struct {
+		Type   string          `json:"type"`
+		Config json.RawMessage `json:"config"` // Keep as raw JSON
+	}
+
+	var temp TempConfig
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return fmt.Errorf("failed to unmarshal usage ledger config: %w", err)
+	}
+
+	c.Type = LedgerType(temp.Type)
+	switch c.Type {
+	case LedgerTypeSQLite:
+		var sqliteConfig SQLiteConfig
+		if err := json.Unmarshal(temp.Config, &sqliteConfig); err != nil {
+			return fmt.Errorf("failed to unmarshal sqlite config: %w", err)
+		}
+		c.Config = sqliteConfig
+	case LedgerTypePostgres:
+		var postgresConfig PostgresConfig
+		if err := json.Unmarshal(temp.Config, &postgresConfig); err != nil {
+			return fmt.Errorf("failed to unmarshal postgres config: %w", err)
+		}
+		c.Config = postgresConfig
+	default:
+		return fmt.Errorf("unknown usage ledger type: %s", temp.Type)
+	}
+	return nil
+}
+
+// NewLedger opens the configured ledger, creating its table if missing.
+func NewLedger(ctx context.Context, config *Config, logger schemas.Logger) (Ledger, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	switch config.Type {
+	case LedgerTypeSQLite:
+		sqliteConfig, ok := config.Config.(SQLiteConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid sqlite config")
+		}
+		return newSQLiteLedger(ctx, sqliteConfig, logger)
+	case LedgerTypePostgres:
+		postgresConfig, ok := config.Config.(PostgresConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid postgres config")
+		}
+		return newPostgresLedger(ctx, postgresConfig, logger)
+	default:
+		return nil, fmt.Errorf("invalid usage ledger type: %s", config.Type)
+	}
+}
+
+// ParseGroupBy parses a comma separated list of dimensions, such as "day,model".
+func ParseGroupBy(value string) ([]GroupBy, error) {
+	var groupBy []GroupBy
+	for _, part := range splitList(value) {
+		dimension := GroupBy(part)
+		if _, ok := groupColumns[dimension]; !ok {
+			return nil, fmt.Errorf("%w: unknown group by %q", ErrInvalidQuery, part)
+		}
+		groupBy = append(groupBy, dimension)
+	}
+	return groupBy, nil
+}
@@ -0,0 +1,102 @@
+package usageledger
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLedger(t *testing.T) Ledger {
+	ledger, err := NewLedger(context.Background(), &Config{
+		Type:   LedgerTypeSQLite,
+		Config: SQLiteConfig{Path: filepath.Join(t.TempDir(), "ledger.db")},
+	}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { ledger.Close() })
+	return ledger
+}
+
+func TestSQLiteLedgerAggregate(t *testing.T) {
+	ledger := newTestLedger(t)
+	ctx := context.Background()
+
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, ledger.Record(ctx, []Entry{
+		{Timestamp: day, Key: "vk-a", Provider: schemas.OpenAI, Model: "gpt-4o", PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, Cost: 0.5, Latency: 100 * time.Millisecond, Outcome: OutcomeSuccess},
+		{Timestamp: day.Add(time.Hour), Key: "vk-a", Provider: schemas.OpenAI, Model: "gpt-4o", PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30, Cost: 1, Latency: 300 * time.Millisecond, Outcome: OutcomeSuccess},
+		{Timestamp: day.Add(2 * time.Hour), Key: "vk-a", Provider: schemas.OpenAI, Model: "gpt-4o", Outcome: OutcomeError, StatusCode: 429},
+		{Timestamp: day.Add(24 * time.Hour), Key: "vk-b", Provider: schemas.Anthropic, Model: "claude-3-5-haiku", TotalTokens: 7, Cost: 0.25, Outcome: OutcomeSuccess},
+	}))
+
+	aggregates, err := ledger.Aggregate(ctx, Query{GroupBy: []GroupBy{GroupByDay, GroupByKey, GroupByModel}})
+	require.NoError(t, err)
+	require.Len(t, aggregates, 2)
+	assert.Equal(t, Aggregate{
+		Day: "2026-03-01", Key: "vk-a", Model: "gpt-4o",
+		Requests: 3, Failed: 1, PromptTokens: 30, CompletionTokens: 15, TotalTokens: 45, Cost: 1.5,
+		AvgLatencyMs: 400.0 / 3,
+	}, aggregates[0])
+	assert.Equal(t, "2026-03-02", aggregates[1].Day)
+	assert.Equal(t, "vk-b", aggregates[1].Key)
+
+	// Filtered by key and time range, without grouping
+	aggregates, err = ledger.Aggregate(ctx, Query{From: day, To: day.Add(90 * time.Minute), Key: "vk-a"})
+	require.NoError(t, err)
+	require.Len(t, aggregates, 1)
+	assert.Equal(t, int64(2), aggregates[0].Requests)
+	assert.Equal(t, int64(45), aggregates[0].TotalTokens)
+
+	// No matching entry
+	aggregates, err = ledger.Aggregate(ctx, Query{Key: "vk-missing"})
+	require.NoError(t, err)
+	assert.Empty(t, aggregates)
+
+	deleted, err := ledger.DeleteBefore(ctx, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+}
+
+func TestParseGroupBy(t *testing.T) {
+	groupBy, err := ParseGroupBy("day, model,")
+	require.NoError(t, err)
+	assert.Equal(t, []GroupBy{GroupByDay, GroupByModel}, groupBy)
+
+	_, err = ParseGroupBy("day,region")
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+}
+
+func TestPluginRecordsFinalStreamChunk(t *testing.T) {
+	plugin, err := Init(context.Background(), PluginConfig{
+		Ledger: Config{Type: LedgerTypeSQLite, Config: SQLiteConfig{Path: filepath.Join(t.TempDir(), "ledger.db")}},
+	}, nil, nil)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyRequestType, schemas.ChatCompletionStreamRequest)
+	ctx = context.WithValue(ctx, schemas.BifrostContextKeyRequestProvider, schemas.OpenAI)
+	ctx = context.WithValue(ctx, schemas.BifrostContextKey("x-bf-vk"), "vk-a")
+	_, _, err = plugin.PreHook(&ctx, &schemas.BifrostRequest{})
+	require.NoError(t, err)
+
+	chunk := &schemas.BifrostResponse{Model: "gpt-4o"}
+	_, _, err = plugin.PostHook(&ctx, chunk, nil)
+	require.NoError(t, err)
+
+	final := context.WithValue(ctx, schemas.BifrostContextKeyStreamEndIndicator, true)
+	_, _, err = plugin.PostHook(&final, &schemas.BifrostResponse{Model: "gpt-4o", Usage: &schemas.LLMUsage{TotalTokens: 12}}, nil)
+	require.NoError(t, err)
+
+	plugin.stop()
+	aggregates, err := plugin.GetLedger().Aggregate(context.Background(), Query{GroupBy: []GroupBy{GroupByKey}})
+	require.NoError(t, err)
+	require.Len(t, aggregates, 1)
+	assert.Equal(t, int64(1), aggregates[0].Requests)
+	assert.Equal(t, int64(12), aggregates[0].TotalTokens)
+	assert.Equal(t, "vk-a", aggregates[0].Key)
+	require.NoError(t, plugin.Cleanup())
+}
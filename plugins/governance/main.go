@@ -9,6 +9,7 @@ import (
 	"github.com/maximhq/bifrost/core/schemas"
 	"github.com/maximhq/bifrost/framework/configstore"
 	"github.com/maximhq/bifrost/framework/pricing"
+	"github.com/maximhq/bifrost/framework/statestore"
 )
 
 // PluginName is the name of the governance plugin
@@ -25,7 +26,12 @@ const (
 
 // Config is the configuration for the governance plugin
 type Config struct {
-	IsVkMandatory *bool `json:"is_vk_mandatory"`
+	IsVkMandatory       *bool                 `json:"is_vk_mandatory"`
+	ServiceTierStepDown []ServiceTierStepDown `json:"service_tier_step_down,omitempty"` // Service tier caps applied as budgets fill up
+
+	// StateStore shares rate limit and budget usage between replicas, usage is local to each
+	// replica if nil
+	StateStore statestore.StateStore `json:"-"`
 }
 
 // GovernancePlugin implements the main governance plugin with hierarchical budget system
@@ -40,7 +46,8 @@ type GovernancePlugin struct {
 	pricingManager *pricing.PricingManager
 	logger         schemas.Logger
 
-	isVkMandatory *bool
+	isVkMandatory        *bool
+	serviceTierStepDowns []ServiceTierStepDown // Sorted by descending threshold
 }
 
 // Init creates a new governance plugin with cleanly segregated components
@@ -53,10 +60,16 @@ func Init(ctx context.Context, config *Config, logger schemas.Logger, store conf
 		logger.Warn("governance plugin requires pricing manager to calculate cost, all cost calculations will be skipped.")
 	}
 
+	serviceTierStepDowns, err := validateServiceTierStepDowns(config.ServiceTierStepDown)
+	if err != nil {
+		return nil, err
+	}
+
 	governanceStore, err := NewGovernanceStore(logger, store, governanceConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize governance store: %w", err)
 	}
+	governanceStore.shared = newSharedUsage(config.StateStore, logger)
 	// Initialize components in dependency order with fixed, optimal settings
 	// Resolver (pure decision engine for hierarchical governance, depends only on store)
 	resolver := NewBudgetResolver(governanceStore, logger)
@@ -73,13 +86,14 @@ func Init(ctx context.Context, config *Config, logger schemas.Logger, store conf
 	}
 
 	plugin := &GovernancePlugin{
-		store:          governanceStore,
-		resolver:       resolver,
-		tracker:        tracker,
-		configStore:    store,
-		pricingManager: pricingManager,
-		logger:         logger,
-		isVkMandatory:  config.IsVkMandatory,
+		store:                governanceStore,
+		resolver:             resolver,
+		tracker:              tracker,
+		configStore:          store,
+		pricingManager:       pricingManager,
+		logger:               logger,
+		isVkMandatory:        config.IsVkMandatory,
+		serviceTierStepDowns: serviceTierStepDowns,
 	}
 
 	return plugin, nil
@@ -103,6 +117,7 @@ func (p *GovernancePlugin) PreHook(ctx *context.Context, req *schemas.BifrostReq
 				Error: &schemas.BifrostError{
 					Type:       bifrost.Ptr("virtual_key_required"),
 					StatusCode: bifrost.Ptr(400),
+					Origin:     schemas.ErrorOriginClientRequest,
 					Error: schemas.ErrorField{
 						Message: "x-bf-vk header is missing",
 					},
@@ -144,6 +159,7 @@ func (p *GovernancePlugin) PreHook(ctx *context.Context, req *schemas.BifrostReq
 	// Handle decision
 	switch result.Decision {
 	case DecisionAllow:
+		p.stepDownServiceTier(*ctx, req, result.VirtualKey)
 		return req, nil, nil
 
 	case DecisionVirtualKeyNotFound, DecisionVirtualKeyBlocked, DecisionModelBlocked, DecisionProviderBlocked:
@@ -151,6 +167,7 @@ func (p *GovernancePlugin) PreHook(ctx *context.Context, req *schemas.BifrostReq
 			Error: &schemas.BifrostError{
 				Type:       bifrost.Ptr(string(result.Decision)),
 				StatusCode: bifrost.Ptr(403),
+				Origin:     schemas.ErrorOriginPolicy,
 				Error: schemas.ErrorField{
 					Message: result.Reason,
 				},
@@ -162,6 +179,7 @@ func (p *GovernancePlugin) PreHook(ctx *context.Context, req *schemas.BifrostReq
 			Error: &schemas.BifrostError{
 				Type:       bifrost.Ptr(string(result.Decision)),
 				StatusCode: bifrost.Ptr(429),
+				Origin:     schemas.ErrorOriginPolicy,
 				Error: schemas.ErrorField{
 					Message: result.Reason,
 				},
@@ -173,6 +191,7 @@ func (p *GovernancePlugin) PreHook(ctx *context.Context, req *schemas.BifrostReq
 			Error: &schemas.BifrostError{
 				Type:       bifrost.Ptr(string(result.Decision)),
 				StatusCode: bifrost.Ptr(402),
+				Origin:     schemas.ErrorOriginPolicy,
 				Error: schemas.ErrorField{
 					Message: result.Reason,
 				},
@@ -183,7 +202,8 @@ func (p *GovernancePlugin) PreHook(ctx *context.Context, req *schemas.BifrostReq
 		// Fallback to deny for unknown decisions
 		return req, &schemas.PluginShortCircuit{
 			Error: &schemas.BifrostError{
-				Type: bifrost.Ptr(string(result.Decision)),
+				Type:   bifrost.Ptr(string(result.Decision)),
+				Origin: schemas.ErrorOriginPolicy,
 				Error: schemas.ErrorField{
 					Message: "Governance decision error",
 				},
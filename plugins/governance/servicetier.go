@@ -0,0 +1,80 @@
+package governance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+)
+
+// ServiceTierStepDown caps the service tier of requests once a budget of the virtual key's
+// hierarchy has used Threshold (0-1] of its limit.
+type ServiceTierStepDown struct {
+	Threshold float64             `json:"threshold"` // Fraction of the budget used, e.g. 0.8 for 80%
+	MaxTier   schemas.ServiceTier `json:"max_tier"`  // Highest tier allowed past the threshold
+}
+
+// serviceTierRanks orders tiers from cheapest to most expensive. Requests without a tier
+// are served at the default tier, auto may use priority capacity.
+var serviceTierRanks = map[schemas.ServiceTier]int{
+	schemas.ServiceTierFlex:     1,
+	schemas.ServiceTierDefault:  2,
+	schemas.ServiceTierAuto:     3,
+	schemas.ServiceTierPriority: 4,
+}
+
+// validateServiceTierStepDowns checks the rules and returns them sorted by descending threshold.
+func validateServiceTierStepDowns(rules []ServiceTierStepDown) ([]ServiceTierStepDown, error) {
+	sorted := make([]ServiceTierStepDown, len(rules))
+	copy(sorted, rules)
+
+	for _, rule := range sorted {
+		if rule.Threshold <= 0 {
+			return nil, fmt.Errorf("service tier step-down threshold must be greater than 0, got %v", rule.Threshold)
+		}
+		if _, ok := serviceTierRanks[rule.MaxTier]; !ok {
+			return nil, fmt.Errorf("unknown service tier %q in step-down rule", rule.MaxTier)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Threshold > sorted[j].Threshold
+	})
+	return sorted, nil
+}
+
+// stepDownServiceTier lowers the service tier of the request to the cap of the highest threshold
+// reached by the virtual key's budgets, with a warning. Requests already at or below the cap are
+// left unchanged.
+func (p *GovernancePlugin) stepDownServiceTier(ctx context.Context, req *schemas.BifrostRequest, vk *configstore.TableVirtualKey) {
+	if len(p.serviceTierStepDowns) == 0 || vk == nil {
+		return
+	}
+
+	utilization := p.store.BudgetUtilization(vk)
+	for _, rule := range p.serviceTierStepDowns {
+		if utilization < rule.Threshold {
+			continue
+		}
+
+		current := schemas.ServiceTierDefault
+		if req.Params != nil && req.Params.ServiceTier != nil {
+			current = *req.Params.ServiceTier
+		}
+		if serviceTierRanks[current] <= serviceTierRanks[rule.MaxTier] {
+			return
+		}
+
+		if req.Params == nil {
+			req.Params = &schemas.ModelParameters{}
+		}
+		tier := rule.MaxTier
+		req.Params.ServiceTier = &tier
+		p.logger.Debug("stepped down service tier of virtual key %s from %s to %s at %.0f%% budget usage", vk.ID, current, tier, utilization*100)
+		schemas.AddWarning(ctx, schemas.WarningCapabilityDowngraded, PluginName,
+			fmt.Sprintf("service tier stepped down from %s to %s at %.0f%% budget usage", current, tier, utilization*100))
+		return
+	}
+}
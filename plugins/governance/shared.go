@@ -0,0 +1,173 @@
+package governance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/framework/statestore"
+)
+
+// budgetUnitsPerDollar is the resolution of shared budget counters, which are integers.
+const budgetUnitsPerDollar = 1_000_000
+
+// sharedUsageRefreshInterval is how often the usage of other replicas is loaded in the
+// background. Requests are checked against the usage loaded last, so replicas can overshoot a
+// limit by the usage of one interval.
+const sharedUsageRefreshInterval = 1 * time.Second
+
+// sharedUsage keeps rate limit and budget usage in a state store shared by all replicas. Windows
+// are aligned to the Unix epoch rather than to each replica's last reset, so every replica
+// counts into the same key; the in-memory usage is overwritten with the shared totals. A nil
+// sharedUsage keeps usage local to the replica.
+type sharedUsage struct {
+	store  statestore.StateStore
+	logger schemas.Logger
+}
+
+func newSharedUsage(store statestore.StateStore, logger schemas.Logger) *sharedUsage {
+	if store == nil {
+		return nil
+	}
+	return &sharedUsage{store: store, logger: logger}
+}
+
+// window returns the start of the current window of a reset duration and the TTL of its key.
+// Limits without a valid reset duration have a single window that never expires.
+func window(resetDuration *string, now time.Time) (time.Time, time.Duration) {
+	if resetDuration == nil {
+		return time.Time{}, 0
+	}
+	duration, err := configstore.ParseDuration(*resetDuration)
+	if err != nil || duration <= 0 {
+		return time.Time{}, 0
+	}
+	return now.Truncate(duration), duration
+}
+
+func windowKey(kind, id, counter string, start time.Time) string {
+	return fmt.Sprintf("governance:%s:%s:%s:%d", kind, id, counter, start.Unix())
+}
+
+// addRateLimitUsage adds usage to the shared counters of a rate limit and returns a copy of it
+// holding the totals, leaving the rate limit itself untouched as requests may be reading it.
+// Zero deltas only read the totals.
+func (s *sharedUsage) addRateLimitUsage(rateLimit *configstore.TableRateLimit, tokens, requests int64, now time.Time) (*configstore.TableRateLimit, error) {
+	if s == nil || rateLimit == nil {
+		return rateLimit, nil
+	}
+	updated := *rateLimit
+	if updated.TokenMaxLimit != nil {
+		start, ttl := window(updated.TokenResetDuration, now)
+		total, err := s.add(windowKey("ratelimit", updated.ID, "tokens", start), tokens, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update shared token usage: %w", err)
+		}
+		updated.TokenCurrentUsage = total
+		if ttl > 0 {
+			updated.TokenLastReset = start
+		}
+	}
+
+	if updated.RequestMaxLimit != nil {
+		start, ttl := window(updated.RequestResetDuration, now)
+		total, err := s.add(windowKey("ratelimit", updated.ID, "requests", start), requests, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update shared request usage: %w", err)
+		}
+		updated.RequestCurrentUsage = total
+		if ttl > 0 {
+			updated.RequestLastReset = start
+		}
+	}
+
+	return &updated, nil
+}
+
+// addBudgetUsage adds a cost to the shared counter of a budget and returns the total usage in
+// dollars and the start of the current window. A zero cost only reads the total.
+func (s *sharedUsage) addBudgetUsage(budget *configstore.TableBudget, cost float64, now time.Time) (float64, time.Time, error) {
+	start, ttl := window(&budget.ResetDuration, now)
+	units := int64(math.Round(cost * budgetUnitsPerDollar))
+	total, err := s.add(windowKey("budget", budget.ID, "usage", start), units, ttl)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to update shared budget usage: %w", err)
+	}
+	if ttl == 0 {
+		start = budget.LastReset
+	}
+	return float64(total) / budgetUnitsPerDollar, start, nil
+}
+
+// budgetUsage is the shared usage of a budget and the start of its current window.
+type budgetUsage struct {
+	usage     float64
+	lastReset time.Time
+}
+
+// readUsage reads the shared totals of rate limits and budgets in a single batched read, so a
+// refresh costs one round trip to the state store however many virtual keys there are. It
+// returns copies of the rate limits holding the totals, and the usage of each budget.
+func (s *sharedUsage) readUsage(rateLimits []*configstore.TableRateLimit, budgets []*configstore.TableBudget, now time.Time) ([]*configstore.TableRateLimit, []budgetUsage, error) {
+	var keys []string
+	var setTotals []func(total int64)
+
+	updated := make([]*configstore.TableRateLimit, len(rateLimits))
+	for i, rateLimit := range rateLimits {
+		clone := *rateLimit
+		updated[i] = &clone
+		if clone.TokenMaxLimit != nil {
+			start, ttl := window(clone.TokenResetDuration, now)
+			keys = append(keys, windowKey("ratelimit", clone.ID, "tokens", start))
+			setTotals = append(setTotals, func(total int64) {
+				clone.TokenCurrentUsage = total
+				if ttl > 0 {
+					clone.TokenLastReset = start
+				}
+			})
+		}
+		if clone.RequestMaxLimit != nil {
+			start, ttl := window(clone.RequestResetDuration, now)
+			keys = append(keys, windowKey("ratelimit", clone.ID, "requests", start))
+			setTotals = append(setTotals, func(total int64) {
+				clone.RequestCurrentUsage = total
+				if ttl > 0 {
+					clone.RequestLastReset = start
+				}
+			})
+		}
+	}
+
+	usages := make([]budgetUsage, len(budgets))
+	for i, budget := range budgets {
+		start, ttl := window(&budget.ResetDuration, now)
+		if ttl == 0 {
+			start = budget.LastReset
+		}
+		usages[i].lastReset = start
+		keys = append(keys, windowKey("budget", budget.ID, "usage", start))
+		setTotals = append(setTotals, func(total int64) {
+			usages[i].usage = float64(total) / budgetUnitsPerDollar
+		})
+	}
+
+	totals, err := statestore.GetInts(context.Background(), s.store, keys)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read shared usage: %w", err)
+	}
+	for i, total := range totals {
+		setTotals[i](total)
+	}
+	return updated, usages, nil
+}
+
+// add adds a delta to a shared counter and returns its total, only reading it for a zero delta.
+func (s *sharedUsage) add(key string, delta int64, ttl time.Duration) (int64, error) {
+	if delta == 0 {
+		return statestore.GetInt(context.Background(), s.store, key)
+	}
+	return s.store.IncrBy(context.Background(), key, delta, ttl)
+}
@@ -0,0 +1,89 @@
+package governance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/framework/statestore"
+)
+
+// countingStore counts the reads of a state store.
+type countingStore struct {
+	statestore.StateStore
+	gets, getManys int
+}
+
+func (s *countingStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.gets++
+	return s.StateStore.Get(ctx, key)
+}
+
+func (s *countingStore) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	s.getManys++
+	return s.StateStore.GetMany(ctx, keys)
+}
+
+func TestRefreshSharedUsageBatchesReads(t *testing.T) {
+	ctx := context.Background()
+	logger := bifrost.NewDefaultLogger(schemas.LogLevelError)
+	minute := "1m"
+	now := time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)
+
+	config := &configstore.GovernanceConfig{
+		Budgets: []configstore.TableBudget{{ID: "budget-a", MaxLimit: 10, ResetDuration: "1d"}},
+		RateLimits: []configstore.TableRateLimit{
+			{ID: "rl-a", TokenMaxLimit: bifrost.Ptr(int64(1000)), TokenResetDuration: &minute},
+			{ID: "rl-b", RequestMaxLimit: bifrost.Ptr(int64(10)), RequestResetDuration: &minute},
+		},
+		VirtualKeys: []configstore.TableVirtualKey{
+			{ID: "vk-a", Value: "sk-a", IsActive: true, RateLimitID: bifrost.Ptr("rl-a"), BudgetID: bifrost.Ptr("budget-a")},
+			{ID: "vk-b", Value: "sk-b", IsActive: true, RateLimitID: bifrost.Ptr("rl-b")},
+			{ID: "vk-c", Value: "sk-c", IsActive: true},
+		},
+	}
+	store, err := NewGovernanceStore(logger, nil, config)
+	if err != nil {
+		t.Fatalf("NewGovernanceStore failed: %v", err)
+	}
+	shared := &countingStore{StateStore: statestore.NewMemoryStore()}
+	store.shared = newSharedUsage(shared, logger)
+	store.now = func() time.Time { return now }
+
+	// Usage counted by other replicas
+	minuteStart := now.Truncate(time.Minute)
+	dayStart := now.Truncate(24 * time.Hour)
+	for key, delta := range map[string]int64{
+		windowKey("ratelimit", "rl-a", "tokens", minuteStart):   250,
+		windowKey("ratelimit", "rl-b", "requests", minuteStart): 4,
+		windowKey("budget", "budget-a", "usage", dayStart):      2_500_000,
+	} {
+		if _, err := shared.IncrBy(ctx, key, delta, time.Hour); err != nil {
+			t.Fatalf("IncrBy failed: %v", err)
+		}
+	}
+
+	store.RefreshSharedUsage()
+
+	if shared.getManys != 1 || shared.gets != 0 {
+		t.Errorf("refresh made %d batched and %d single reads, want 1 and 0", shared.getManys, shared.gets)
+	}
+	vkA, _ := store.GetVirtualKey("sk-a")
+	if vkA.RateLimit.TokenCurrentUsage != 250 || !vkA.RateLimit.TokenLastReset.Equal(minuteStart) {
+		t.Errorf("rl-a usage = %d since %v, want 250 since %v", vkA.RateLimit.TokenCurrentUsage, vkA.RateLimit.TokenLastReset, minuteStart)
+	}
+	vkB, _ := store.GetVirtualKey("sk-b")
+	if vkB.RateLimit.RequestCurrentUsage != 4 {
+		t.Errorf("rl-b usage = %d, want 4", vkB.RateLimit.RequestCurrentUsage)
+	}
+	budget := store.GetAllBudgets()["budget-a"]
+	if budget.CurrentUsage != 2.5 || !budget.LastReset.Equal(dayStart) {
+		t.Errorf("budget usage = %v since %v, want 2.5 since %v", budget.CurrentUsage, budget.LastReset, dayStart)
+	}
+	if config.RateLimits[0].TokenCurrentUsage != 0 {
+		t.Error("refresh mutated the rate limit shared with requests in flight")
+	}
+}
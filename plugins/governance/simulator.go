@@ -0,0 +1,410 @@
+package governance
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+)
+
+// SimulationScenario is a declarative test of routing, fallback and budget policies: the
+// governance config and providers to check, a trace of synthetic requests and scripted provider
+// failures. Simulate replays the trace and reports which provider would serve each request.
+type SimulationScenario struct {
+	Governance *configstore.GovernanceConfig `json:"governance,omitempty"`
+	// Configured providers and the models their keys serve. Every provider is configured if empty.
+	Providers map[schemas.ModelProvider]SimulatedProvider `json:"providers,omitempty"`
+	// Requests without a virtual key are rejected, as with the enforce_governance_header setting
+	VirtualKeyRequired bool               `json:"virtual_key_required,omitempty"`
+	Requests           []SimulatedRequest `json:"requests"`
+	Failures           []SimulatedFailure `json:"failures,omitempty"`
+}
+
+// SimulatedProvider is a configured provider of a scenario.
+type SimulatedProvider struct {
+	Models []string `json:"models,omitempty"` // Models served by its keys, every model if empty
+}
+
+// SimulatedRequest is a synthetic request of a trace.
+type SimulatedRequest struct {
+	ID           string                 `json:"id,omitempty"` // Position of the request in the trace if empty
+	At           string                 `json:"at,omitempty"` // Time since the start of the trace, e.g. "90s" or "2d"
+	VirtualKey   string                 `json:"virtual_key,omitempty"`
+	Provider     schemas.ModelProvider  `json:"provider"`
+	Model        string                 `json:"model"`
+	Fallbacks    []schemas.Fallback     `json:"fallbacks,omitempty"`
+	MaxFallbacks *int                   `json:"max_fallbacks,omitempty"` // The max_fallbacks override of the request policy
+	Tokens       int64                  `json:"tokens,omitempty"`        // Tokens used if served, counted against rate limits
+	Cost         float64                `json:"cost,omitempty"`          // Cost in dollars if served, counted against budgets
+	Expect       *SimulationExpectation `json:"expect,omitempty"`
+}
+
+// SimulationExpectation is the expected outcome of a simulated request. Empty fields are not
+// checked.
+type SimulationExpectation struct {
+	Outcome  SimulationOutcome     `json:"outcome,omitempty"` // served or failed
+	Provider schemas.ModelProvider `json:"provider,omitempty"`
+	Model    string                `json:"model,omitempty"`
+}
+
+// SimulatedFailure makes a provider fail every attempt during a window of the trace, retries
+// included.
+type SimulatedFailure struct {
+	Provider   schemas.ModelProvider `json:"provider"`
+	Model      string                `json:"model,omitempty"`       // Every model of the provider fails if empty
+	From       string                `json:"from,omitempty"`        // Start of the window, the start of the trace if empty
+	Until      string                `json:"until,omitempty"`       // End of the window (excluded), never if empty
+	StatusCode int                   `json:"status_code,omitempty"` // 503 if 0
+	Message    string                `json:"message,omitempty"`
+}
+
+// SimulationOutcome is the outcome of a simulated request or attempt.
+type SimulationOutcome string
+
+const (
+	SimulationServed      SimulationOutcome = "served"      // The provider served the request
+	SimulationFailed      SimulationOutcome = "failed"      // Every attempt failed, or the provider failed by script
+	SimulationRejected    SimulationOutcome = "rejected"    // Governance rejected the attempt
+	SimulationUnavailable SimulationOutcome = "unavailable" // The provider is not configured or does not serve the model
+	SimulationSkipped     SimulationOutcome = "skipped"     // The fallback is not configured and was not tried
+)
+
+// SimulationAttempt is the attempt of a request on its primary provider or a fallback.
+type SimulationAttempt struct {
+	Provider   schemas.ModelProvider `json:"provider"`
+	Model      string                `json:"model"`
+	Outcome    SimulationOutcome     `json:"outcome"`
+	Decision   Decision              `json:"decision,omitempty"` // Governance decision of rejected attempts
+	StatusCode int                   `json:"status_code,omitempty"`
+	Reason     string                `json:"reason"`
+}
+
+// SimulationResult is the outcome of a simulated request: the provider that would serve it and
+// every attempt made.
+type SimulationResult struct {
+	RequestID string                `json:"request_id"`
+	At        string                `json:"at,omitempty"`
+	Outcome   SimulationOutcome     `json:"outcome"` // served or failed
+	Provider  schemas.ModelProvider `json:"provider,omitempty"`
+	Model     string                `json:"model,omitempty"`
+	Reason    string                `json:"reason"`
+	Unmet     string                `json:"unmet,omitempty"` // How the outcome differs from the expected one
+	Attempts  []SimulationAttempt   `json:"attempts"`
+}
+
+// SimulationSummary counts the outcomes of a simulation.
+type SimulationSummary struct {
+	Requests   int                           `json:"requests"`
+	Served     int                           `json:"served"`
+	Failed     int                           `json:"failed"`
+	Fallbacks  int                           `json:"fallbacks"` // Requests served by a fallback
+	Unmet      int                           `json:"unmet"`     // Requests not meeting their expectation
+	ByProvider map[schemas.ModelProvider]int `json:"by_provider"`
+}
+
+// SimulationReport is the result of Simulate, with the requests in trace order. It passes if
+// every request met its expectation.
+type SimulationReport struct {
+	Passed  bool               `json:"passed"`
+	Results []SimulationResult `json:"results"`
+	Summary SimulationSummary  `json:"summary"`
+}
+
+// simulationRequest is a request of the trace with its parsed time.
+type simulationRequest struct {
+	SimulatedRequest
+	at time.Duration
+}
+
+// simulationFailure is a failure script with its parsed window.
+type simulationFailure struct {
+	SimulatedFailure
+	from, until time.Duration // until is 0 if the window never ends
+}
+
+// Simulate replays the requests of a scenario, in order of time, through the same decisions as
+// live traffic: the primary provider is tried first, then the fallbacks allowed by the request
+// policy, and every attempt is evaluated by governance against the scenario's virtual keys, rate
+// limits and budgets. Served requests count their tokens and cost, and budgets and rate limits
+// reset on the simulated clock, so a trace spanning days exercises their windows. The scenario's
+// governance config is not modified.
+func Simulate(scenario *SimulationScenario, logger schemas.Logger) (*SimulationReport, error) {
+	requests, failures, err := parseSimulationScenario(scenario)
+	if err != nil {
+		return nil, err
+	}
+
+	// The store links the config's entries together, so it gets copies
+	governanceConfig := &configstore.GovernanceConfig{}
+	if scenario.Governance != nil {
+		governanceConfig = &configstore.GovernanceConfig{
+			VirtualKeys: slices.Clone(scenario.Governance.VirtualKeys),
+			Teams:       slices.Clone(scenario.Governance.Teams),
+			Customers:   slices.Clone(scenario.Governance.Customers),
+			Budgets:     slices.Clone(scenario.Governance.Budgets),
+			RateLimits:  slices.Clone(scenario.Governance.RateLimits),
+		}
+	}
+	store, err := NewGovernanceStore(logger, nil, governanceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize governance store: %w", err)
+	}
+	start := time.Now()
+	now := start
+	store.now = func() time.Time { return now }
+	resolver := NewBudgetResolver(store, logger)
+	// No workers: resets run on the simulated clock before each request
+	tracker := &UsageTracker{store: store, resolver: resolver, logger: logger}
+
+	report := &SimulationReport{
+		Results: make([]SimulationResult, 0, len(requests)),
+		Summary: SimulationSummary{ByProvider: make(map[schemas.ModelProvider]int)},
+	}
+	for _, req := range requests {
+		now = start.Add(req.at)
+		tracker.resetExpiredCounters()
+
+		result := simulateRequest(scenario, resolver, tracker, failures, req)
+		result.Unmet = unmetExpectation(req.Expect, result)
+		report.Results = append(report.Results, result)
+		report.Summary.Requests++
+		if result.Unmet != "" {
+			report.Summary.Unmet++
+		}
+		if result.Outcome != SimulationServed {
+			report.Summary.Failed++
+			continue
+		}
+		report.Summary.Served++
+		report.Summary.ByProvider[result.Provider]++
+		if len(result.Attempts) > 1 {
+			report.Summary.Fallbacks++
+		}
+	}
+	report.Passed = report.Summary.Unmet == 0
+	return report, nil
+}
+
+// parseSimulationScenario checks a scenario and returns its requests sorted by time and its
+// failure windows.
+func parseSimulationScenario(scenario *SimulationScenario) ([]simulationRequest, []simulationFailure, error) {
+	if scenario == nil {
+		return nil, nil, fmt.Errorf("simulation scenario is nil")
+	}
+	parseOffset := func(offset string) (time.Duration, error) {
+		if offset == "" {
+			return 0, nil
+		}
+		duration, err := configstore.ParseDuration(offset)
+		if err != nil {
+			return 0, err
+		}
+		if duration < 0 {
+			return 0, fmt.Errorf("negative duration %s", offset)
+		}
+		return duration, nil
+	}
+
+	requests := make([]simulationRequest, len(scenario.Requests))
+	for i, req := range scenario.Requests {
+		if req.ID == "" {
+			req.ID = strconv.Itoa(i)
+		}
+		if req.Provider == "" || req.Model == "" {
+			return nil, nil, fmt.Errorf("request %s: provider and model are required", req.ID)
+		}
+		at, err := parseOffset(req.At)
+		if err != nil {
+			return nil, nil, fmt.Errorf("request %s: invalid at: %w", req.ID, err)
+		}
+		requests[i] = simulationRequest{SimulatedRequest: req, at: at}
+	}
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].at < requests[j].at
+	})
+
+	failures := make([]simulationFailure, len(scenario.Failures))
+	for i, failure := range scenario.Failures {
+		if failure.Provider == "" {
+			return nil, nil, fmt.Errorf("failure %d: provider is required", i)
+		}
+		from, err := parseOffset(failure.From)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failure %d: invalid from: %w", i, err)
+		}
+		until, err := parseOffset(failure.Until)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failure %d: invalid until: %w", i, err)
+		}
+		if failure.StatusCode == 0 {
+			failure.StatusCode = 503
+		}
+		if failure.Message == "" {
+			failure.Message = "scripted provider failure"
+		}
+		failures[i] = simulationFailure{SimulatedFailure: failure, from: from, until: until}
+	}
+	return requests, failures, nil
+}
+
+// simulateRequest tries a request on its primary provider, then on its fallbacks until one serves
+// it. As with live traffic, rejections and failures do not stop the fallbacks, and fallbacks
+// without a configured provider are skipped.
+func simulateRequest(scenario *SimulationScenario, resolver *BudgetResolver, tracker *UsageTracker, failures []simulationFailure, req simulationRequest) SimulationResult {
+	result := SimulationResult{RequestID: req.ID, At: req.At, Outcome: SimulationFailed}
+
+	fallbacks := req.Fallbacks
+	if req.MaxFallbacks != nil {
+		maxFallbacks := max(0, min(*req.MaxFallbacks, schemas.DefaultMaxRequestFallbacks))
+		if len(fallbacks) > maxFallbacks {
+			fallbacks = fallbacks[:maxFallbacks]
+		}
+	}
+
+	targets := append([]schemas.Fallback{{Provider: req.Provider, Model: req.Model}}, fallbacks...)
+	for i, target := range targets {
+		if _, configured := scenario.Providers[target.Provider]; i > 0 && len(scenario.Providers) > 0 && !configured {
+			result.Attempts = append(result.Attempts, SimulationAttempt{
+				Provider: target.Provider,
+				Model:    target.Model,
+				Outcome:  SimulationSkipped,
+				Reason:   "provider not configured, fallback skipped",
+			})
+			continue
+		}
+
+		attempt := simulateAttempt(scenario, resolver, tracker, failures, req, target)
+		result.Attempts = append(result.Attempts, attempt)
+		if attempt.Outcome != SimulationServed {
+			continue
+		}
+
+		result.Outcome = SimulationServed
+		result.Provider = target.Provider
+		result.Model = target.Model
+		if i == 0 {
+			result.Reason = "served by the primary provider"
+		} else {
+			primary := result.Attempts[0]
+			result.Reason = fmt.Sprintf("served by fallback %d, primary %s: %s", i, primary.Outcome, primary.Reason)
+		}
+		return result
+	}
+
+	if len(targets) == 1 {
+		result.Reason = fmt.Sprintf("primary %s without fallbacks: %s", result.Attempts[0].Outcome, result.Attempts[0].Reason)
+	} else {
+		result.Reason = fmt.Sprintf("primary and %d fallbacks did not serve the request", len(targets)-1)
+	}
+	return result
+}
+
+// simulateAttempt evaluates a single attempt as the governance plugin, the key selection and the
+// failure scripts would, and records the usage of served attempts.
+func simulateAttempt(scenario *SimulationScenario, resolver *BudgetResolver, tracker *UsageTracker, failures []simulationFailure, req simulationRequest, target schemas.Fallback) SimulationAttempt {
+	attempt := SimulationAttempt{Provider: target.Provider, Model: target.Model}
+
+	if len(scenario.Providers) > 0 {
+		provider, configured := scenario.Providers[target.Provider]
+		if !configured {
+			attempt.Outcome = SimulationUnavailable
+			attempt.Reason = "provider not configured"
+			return attempt
+		}
+		if len(provider.Models) > 0 && !slices.Contains(provider.Models, target.Model) {
+			attempt.Outcome = SimulationUnavailable
+			attempt.Reason = fmt.Sprintf("no key of %s serves model %s", target.Provider, target.Model)
+			return attempt
+		}
+	}
+
+	if req.VirtualKey == "" {
+		if scenario.VirtualKeyRequired {
+			attempt.Outcome = SimulationRejected
+			attempt.StatusCode = 400
+			attempt.Reason = "x-bf-vk header is missing"
+			return attempt
+		}
+	} else {
+		ctx := context.Background()
+		evaluation := resolver.EvaluateRequest(&ctx, &EvaluationRequest{
+			VirtualKey: req.VirtualKey,
+			Provider:   target.Provider,
+			Model:      target.Model,
+			RequestID:  req.ID,
+		})
+		if evaluation.Decision != DecisionAllow {
+			attempt.Outcome = SimulationRejected
+			attempt.Decision = evaluation.Decision
+			attempt.StatusCode = decisionStatusCode(evaluation.Decision)
+			attempt.Reason = evaluation.Reason
+			return attempt
+		}
+	}
+
+	for _, failure := range failures {
+		if failure.Provider != target.Provider || (failure.Model != "" && failure.Model != target.Model) {
+			continue
+		}
+		if req.at < failure.from || (failure.until > 0 && req.at >= failure.until) {
+			continue
+		}
+		attempt.Outcome = SimulationFailed
+		attempt.StatusCode = failure.StatusCode
+		attempt.Reason = failure.Message
+		return attempt
+	}
+
+	if req.VirtualKey != "" {
+		tracker.UpdateUsage(&UsageUpdate{
+			VirtualKey: req.VirtualKey,
+			Provider:   target.Provider,
+			Model:      target.Model,
+			Success:    true,
+			TokensUsed: req.Tokens,
+			Cost:       req.Cost,
+			RequestID:  req.ID,
+		})
+	}
+	attempt.Outcome = SimulationServed
+	attempt.Reason = "served"
+	return attempt
+}
+
+// unmetExpectation describes how a result differs from its expectation, "" if it meets it.
+func unmetExpectation(expect *SimulationExpectation, result SimulationResult) string {
+	if expect == nil {
+		return ""
+	}
+	var unmet []string
+	if expect.Outcome != "" && expect.Outcome != result.Outcome {
+		unmet = append(unmet, fmt.Sprintf("expected outcome %s, got %s", expect.Outcome, result.Outcome))
+	}
+	if expect.Provider != "" && expect.Provider != result.Provider {
+		unmet = append(unmet, fmt.Sprintf("expected provider %s, got %q", expect.Provider, result.Provider))
+	}
+	if expect.Model != "" && expect.Model != result.Model {
+		unmet = append(unmet, fmt.Sprintf("expected model %s, got %q", expect.Model, result.Model))
+	}
+	return strings.Join(unmet, "; ")
+}
+
+// decisionStatusCode returns the status code the plugin rejects requests with for a decision.
+func decisionStatusCode(decision Decision) int {
+	switch decision {
+	case DecisionVirtualKeyNotFound, DecisionVirtualKeyBlocked, DecisionModelBlocked, DecisionProviderBlocked:
+		return 403
+	case DecisionRateLimited, DecisionTokenLimited, DecisionRequestLimited:
+		return 429
+	case DecisionBudgetExceeded:
+		return 402
+	default:
+		return 0
+	}
+}
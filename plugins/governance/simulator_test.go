@@ -0,0 +1,94 @@
+package governance
+
+import (
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+)
+
+func TestSimulateRoutingFallbacksAndBudgets(t *testing.T) {
+	budgetID := "budget-team-a"
+	scenario := &SimulationScenario{
+		Governance: &configstore.GovernanceConfig{
+			VirtualKeys: []configstore.TableVirtualKey{
+				{ID: "vk-a", Value: "sk-team-a", IsActive: true, BudgetID: &budgetID},
+				{ID: "vk-b", Value: "sk-team-b", IsActive: true, AllowedProviders: []string{"openai", "anthropic"}},
+			},
+			Budgets: []configstore.TableBudget{
+				{ID: budgetID, MaxLimit: 1, ResetDuration: "1d"},
+			},
+		},
+		Providers: map[schemas.ModelProvider]SimulatedProvider{
+			schemas.OpenAI:    {},
+			schemas.Anthropic: {Models: []string{"claude-3-5-sonnet"}},
+		},
+		Requests: []SimulatedRequest{
+			{ID: "after-reset", At: "2d", VirtualKey: "sk-team-a", Provider: schemas.OpenAI, Model: "gpt-4o", Cost: 0.6},
+			{ID: "first", VirtualKey: "sk-team-a", Provider: schemas.OpenAI, Model: "gpt-4o", Cost: 0.6},
+			{ID: "second", At: "5m", VirtualKey: "sk-team-a", Provider: schemas.OpenAI, Model: "gpt-4o", Cost: 0.6},
+			{ID: "over-budget", At: "6m", VirtualKey: "sk-team-a", Provider: schemas.OpenAI, Model: "gpt-4o",
+				Fallbacks: []schemas.Fallback{{Provider: schemas.Anthropic, Model: "claude-3-5-sonnet"}}},
+			{ID: "outage", At: "15m", VirtualKey: "sk-team-b", Provider: schemas.OpenAI, Model: "gpt-4o",
+				Fallbacks: []schemas.Fallback{
+					{Provider: schemas.Gemini, Model: "gemini-2.0-flash"},
+					{Provider: schemas.Anthropic, Model: "claude-3-5-sonnet"},
+				}},
+			{ID: "no-fallbacks-allowed", At: "16m", VirtualKey: "sk-team-b", Provider: schemas.OpenAI, Model: "gpt-4o",
+				Fallbacks: []schemas.Fallback{{Provider: schemas.Anthropic, Model: "claude-3-5-sonnet"}}, MaxFallbacks: bifrost.Ptr(0),
+				Expect: &SimulationExpectation{Outcome: SimulationServed, Provider: schemas.Anthropic}},
+		},
+		Failures: []SimulatedFailure{
+			{Provider: schemas.OpenAI, From: "10m", Until: "20m", StatusCode: 500},
+		},
+	}
+
+	report, err := Simulate(scenario, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+
+	expected := []struct {
+		id       string
+		outcome  SimulationOutcome
+		provider schemas.ModelProvider
+		attempts int
+	}{
+		{"first", SimulationServed, schemas.OpenAI, 1},
+		{"second", SimulationServed, schemas.OpenAI, 1},
+		{"over-budget", SimulationFailed, "", 2},
+		{"outage", SimulationServed, schemas.Anthropic, 3},
+		{"no-fallbacks-allowed", SimulationFailed, "", 1},
+		{"after-reset", SimulationServed, schemas.OpenAI, 1},
+	}
+	if len(report.Results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(report.Results))
+	}
+	for i, want := range expected {
+		got := report.Results[i]
+		if got.RequestID != want.id || got.Outcome != want.outcome || got.Provider != want.provider || len(got.Attempts) != want.attempts {
+			t.Errorf("result %d: expected %s %s by %q in %d attempts, got %s %s by %q in %d attempts (%s)",
+				i, want.id, want.outcome, want.provider, want.attempts, got.RequestID, got.Outcome, got.Provider, len(got.Attempts), got.Reason)
+		}
+	}
+
+	overBudget := report.Results[2].Attempts
+	if overBudget[0].Decision != DecisionBudgetExceeded || overBudget[1].Decision != DecisionBudgetExceeded {
+		t.Errorf("expected both attempts to exceed the budget, got %+v", overBudget)
+	}
+	outage := report.Results[3].Attempts
+	if outage[0].Outcome != SimulationFailed || outage[0].StatusCode != 500 || outage[1].Outcome != SimulationSkipped {
+		t.Errorf("expected a scripted failure then a skipped fallback, got %+v", outage)
+	}
+
+	if report.Passed || report.Results[4].Unmet == "" || report.Summary.Unmet != 1 {
+		t.Errorf("expected the expectation of no-fallbacks-allowed to be unmet, got %+v", report.Results[4])
+	}
+	if report.Summary.Served != 4 || report.Summary.Failed != 2 || report.Summary.Fallbacks != 1 || report.Summary.ByProvider[schemas.OpenAI] != 3 {
+		t.Errorf("unexpected summary %+v", report.Summary)
+	}
+	if scenario.Governance.Budgets[0].CurrentUsage != 0 {
+		t.Errorf("expected the scenario's budget to be left unchanged, got usage %v", scenario.Governance.Budgets[0].CurrentUsage)
+	}
+}
@@ -23,6 +23,12 @@ type GovernanceStore struct {
 	// Config store for refresh operations
 	configStore configstore.ConfigStore
 
+	// Usage counters shared with other replicas, nil when usage is local
+	shared *sharedUsage
+
+	// Clock of the rate limit and budget windows, simulated when running a policy simulation
+	now func() time.Time
+
 	// Logger
 	logger schemas.Logger
 }
@@ -32,6 +38,7 @@ func NewGovernanceStore(logger schemas.Logger, configStore configstore.ConfigSto
 	store := &GovernanceStore{
 		configStore: configStore,
 		logger:      logger,
+		now:         time.Now,
 	}
 
 	if configStore != nil {
@@ -93,7 +100,7 @@ func (gs *GovernanceStore) CheckBudget(vk *configstore.TableVirtualKey) error {
 		// Check if budget needs reset (in-memory check)
 		if budget.ResetDuration != "" {
 			if duration, err := configstore.ParseDuration(budget.ResetDuration); err == nil {
-				if time.Since(budget.LastReset).Round(time.Millisecond) >= duration {
+				if gs.now().Sub(budget.LastReset).Round(time.Millisecond) >= duration {
 					// Budget expired but hasn't been reset yet - treat as reset
 					// Note: actual reset will happen in post-hook via AtomicBudgetUpdate
 					continue // Skip budget check for expired budgets
@@ -111,6 +118,31 @@ func (gs *GovernanceStore) CheckBudget(vk *configstore.TableVirtualKey) error {
 	return nil
 }
 
+// BudgetUtilization returns the highest fraction of its limit used by any budget in the hierarchy
+// (lock-free). Budgets due for a reset count as unused.
+func (gs *GovernanceStore) BudgetUtilization(vk *configstore.TableVirtualKey) float64 {
+	budgets, _ := gs.collectBudgetsFromHierarchy(vk)
+
+	var utilization float64
+	for _, budget := range budgets {
+		if budget.MaxLimit <= 0 {
+			continue
+		}
+		if budget.ResetDuration != "" {
+			if duration, err := configstore.ParseDuration(budget.ResetDuration); err == nil {
+				if gs.now().Sub(budget.LastReset).Round(time.Millisecond) >= duration {
+					continue
+				}
+			}
+		}
+		if used := budget.CurrentUsage / budget.MaxLimit; used > utilization {
+			utilization = used
+		}
+	}
+
+	return utilization
+}
+
 // UpdateBudget performs atomic budget updates across the hierarchy (both in memory and in database)
 func (gs *GovernanceStore) UpdateBudget(vk *configstore.TableVirtualKey, cost float64) error {
 	if vk == nil {
@@ -127,6 +159,7 @@ func (gs *GovernanceStore) UpdateBudget(vk *configstore.TableVirtualKey, cost fl
 				if cachedBudget, ok := cachedBudgetValue.(*configstore.TableBudget); ok && cachedBudget != nil {
 					clone := *cachedBudget
 					clone.CurrentUsage += cost
+					gs.applySharedBudgetUsage(&clone, cost)
 					gs.budgets.Store(budgetID, &clone)
 				}
 			}
@@ -162,6 +195,7 @@ func (gs *GovernanceStore) UpdateBudget(vk *configstore.TableVirtualKey, cost fl
 					clone := *cachedBudget
 					clone.CurrentUsage += cost
 					clone.LastReset = budget.LastReset
+					gs.applySharedBudgetUsage(&clone, cost)
 					gs.budgets.Store(budgetID, &clone)
 				}
 			}
@@ -191,7 +225,7 @@ func (gs *GovernanceStore) UpdateRateLimitUsage(vkValue string, tokensUsed int64
 	}
 
 	rateLimit := vk.RateLimit
-	now := time.Now()
+	now := gs.now()
 	updated := false
 
 	// Check and reset token counter if needed
@@ -227,6 +261,18 @@ func (gs *GovernanceStore) UpdateRateLimitUsage(vkValue string, tokensUsed int64
 		updated = true
 	}
 
+	// Replace the local counters with the totals of all replicas
+	if gs.shared != nil {
+		var tokens, requests int64
+		if shouldUpdateTokens && tokensUsed > 0 {
+			tokens = tokensUsed
+		}
+		if shouldUpdateRequests {
+			requests = 1
+		}
+		rateLimit = gs.applySharedRateLimitUsage(vk, tokens, requests, now)
+	}
+
 	// Save to database only if something changed
 	if updated && gs.configStore != nil {
 		if err := gs.configStore.UpdateRateLimit(rateLimit); err != nil {
@@ -237,6 +283,86 @@ func (gs *GovernanceStore) UpdateRateLimitUsage(vkValue string, tokensUsed int64
 	return nil
 }
 
+// RefreshSharedUsage loads the usage of all replicas into the rate limits and budgets of all
+// virtual keys. It runs in the background every sharedUsageRefreshInterval, so that checking a
+// request needs no round trip to the state store, and is a no-op when usage is local. All
+// counters are read in one batch, keeping the state store traffic flat as tenants are added.
+func (gs *GovernanceStore) RefreshSharedUsage() {
+	if gs.shared == nil {
+		return
+	}
+
+	var virtualKeys []*configstore.TableVirtualKey
+	var rateLimits []*configstore.TableRateLimit
+	gs.virtualKeys.Range(func(key, value interface{}) bool {
+		if vk, ok := value.(*configstore.TableVirtualKey); ok && vk != nil && vk.RateLimit != nil {
+			virtualKeys = append(virtualKeys, vk)
+			rateLimits = append(rateLimits, vk.RateLimit)
+		}
+		return true // continue
+	})
+
+	var budgetKeys []interface{}
+	var budgets []*configstore.TableBudget
+	gs.budgets.Range(func(key, value interface{}) bool {
+		if budget, ok := value.(*configstore.TableBudget); ok && budget != nil {
+			budgetKeys = append(budgetKeys, key)
+			budgets = append(budgets, budget)
+		}
+		return true // continue
+	})
+
+	totals, usages, err := gs.shared.readUsage(rateLimits, budgets, gs.now())
+	if err != nil {
+		gs.logger.Warn("keeping the last shared usage: %v", err)
+		return
+	}
+
+	// Entries replaced meanwhile, by a request or a config update, are left as they are
+	for i, vk := range virtualKeys {
+		clone := *vk
+		clone.RateLimit = totals[i]
+		gs.virtualKeys.CompareAndSwap(vk.Value, vk, &clone)
+	}
+	for i, budget := range budgets {
+		clone := *budget
+		clone.CurrentUsage = usages[i].usage
+		clone.LastReset = usages[i].lastReset
+		gs.budgets.CompareAndSwap(budgetKeys[i], budget, &clone)
+	}
+}
+
+// applySharedRateLimitUsage adds usage to the shared counters of the rate limit of a virtual key
+// and returns a copy of the rate limit holding the totals of all replicas. The copy replaces the
+// virtual key in memory unless it was replaced meanwhile; the local rate limit is returned if
+// the state store fails.
+func (gs *GovernanceStore) applySharedRateLimitUsage(vk *configstore.TableVirtualKey, tokens, requests int64, now time.Time) *configstore.TableRateLimit {
+	rateLimit, err := gs.shared.addRateLimitUsage(vk.RateLimit, tokens, requests, now)
+	if err != nil {
+		gs.logger.Warn("using local rate limit usage: %v", err)
+		return vk.RateLimit
+	}
+	clone := *vk
+	clone.RateLimit = rateLimit
+	gs.virtualKeys.CompareAndSwap(vk.Value, vk, &clone)
+	return rateLimit
+}
+
+// applySharedBudgetUsage adds a cost to the shared usage of a budget and replaces its usage with
+// the total of all replicas, keeping the local usage if the state store fails.
+func (gs *GovernanceStore) applySharedBudgetUsage(budget *configstore.TableBudget, cost float64) {
+	if gs.shared == nil {
+		return
+	}
+	usage, lastReset, err := gs.shared.addBudgetUsage(budget, cost, gs.now())
+	if err != nil {
+		gs.logger.Warn("using local budget usage: %v", err)
+		return
+	}
+	budget.CurrentUsage = usage
+	budget.LastReset = lastReset
+}
+
 // checkAndResetSingleRateLimit checks and resets a single rate limit's counters if expired
 func (gs *GovernanceStore) checkAndResetSingleRateLimit(rateLimit *configstore.TableRateLimit, now time.Time) bool {
 	updated := false
@@ -268,7 +394,7 @@ func (gs *GovernanceStore) checkAndResetSingleRateLimit(rateLimit *configstore.T
 
 // ResetExpiredRateLimits performs background reset of expired rate limits (lock-free)
 func (gs *GovernanceStore) ResetExpiredRateLimits() error {
-	now := time.Now()
+	now := gs.now()
 	var resetRateLimits []*configstore.TableRateLimit
 
 	gs.virtualKeys.Range(func(key, value interface{}) bool {
@@ -299,7 +425,7 @@ func (gs *GovernanceStore) ResetExpiredRateLimits() error {
 
 // ResetExpiredBudgets checks and resets budgets that have exceeded their reset duration (lock-free)
 func (gs *GovernanceStore) ResetExpiredBudgets() error {
-	now := time.Now()
+	now := gs.now()
 	var resetBudgets []*configstore.TableBudget
 
 	gs.budgets.Range(func(key, value interface{}) bool {
@@ -551,7 +677,7 @@ func (gs *GovernanceStore) resetBudgetIfNeeded(tx *gorm.DB, budget *configstore.
 		return fmt.Errorf("invalid reset duration %s: %w", budget.ResetDuration, err)
 	}
 
-	now := time.Now()
+	now := gs.now()
 	if now.Sub(budget.LastReset) >= duration {
 		budget.CurrentUsage = 0
 		budget.LastReset = now
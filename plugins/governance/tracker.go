@@ -36,9 +36,10 @@ type UsageTracker struct {
 	logger      schemas.Logger
 
 	// Background workers
-	resetTicker *time.Ticker
-	done        chan struct{}
-	wg          sync.WaitGroup
+	resetTicker   *time.Ticker
+	refreshTicker *time.Ticker // Loads the usage of other replicas, nil when usage is local
+	done          chan struct{}
+	wg            sync.WaitGroup
 }
 
 // NewUsageTracker creates a new usage tracker for the hierarchical budget system
@@ -105,6 +106,29 @@ func (t *UsageTracker) startWorkers() {
 	t.resetTicker = time.NewTicker(1 * time.Minute)
 	t.wg.Add(1)
 	go t.resetWorker()
+
+	// Shared usage refresher, so requests are checked without state store round trips
+	if t.store.shared != nil {
+		t.refreshTicker = time.NewTicker(sharedUsageRefreshInterval)
+		t.wg.Add(1)
+		go t.refreshWorker()
+	}
+}
+
+// refreshWorker periodically loads the usage of other replicas into the store
+func (t *UsageTracker) refreshWorker() {
+	defer t.wg.Done()
+
+	t.store.RefreshSharedUsage()
+	for {
+		select {
+		case <-t.refreshTicker.C:
+			t.store.RefreshSharedUsage()
+
+		case <-t.done:
+			return
+		}
+	}
 }
 
 // resetWorker manages periodic resets of rate limit and usage counters
@@ -236,6 +260,9 @@ func (t *UsageTracker) Cleanup() error {
 	if t.resetTicker != nil {
 		t.resetTicker.Stop()
 	}
+	if t.refreshTicker != nil {
+		t.refreshTicker.Stop()
+	}
 
 	// Wait for workers to finish
 	t.wg.Wait()
@@ -128,6 +128,7 @@ func (p *JsonParserPlugin) PostHook(ctx *context.Context, result *schemas.Bifros
 
 						if !p.isValidJSON(fixedContent) {
 							err = &schemas.BifrostError{
+								Origin: schemas.ErrorOriginProvider,
 								Error: schemas.ErrorField{
 									Message: "Invalid JSON in streaming response",
 								},
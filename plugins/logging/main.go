@@ -87,6 +87,7 @@ type InitialLogData struct {
 	SpeechInput        *schemas.SpeechInput
 	TranscriptionInput *schemas.TranscriptionInput
 	Tools              *[]schemas.Tool
+	MetadataOnly       bool // Not sampled for content logging
 }
 
 // LogCallback is a function that gets called when a new log entry is created
@@ -129,6 +130,9 @@ type LoggerPlugin struct {
 	streamDataPool     sync.Pool    // Pool for reusing StreamUpdateData structs
 	streamChunkPool    sync.Pool    // Pool for reusing StreamChunk structs
 	streamAccumulators sync.Map     // Track accumulators by request ID (atomic)
+	isLeader           func() bool  // Reports whether this replica purges the shared store, guarded by mu
+	sampler            ContentSampler
+	samplerMu          sync.RWMutex // Guards sampler, apart from mu which is held during log callbacks
 }
 
 // retryOnNotFound retries a function up to 3 times with 1-second delays if it returns logstore.ErrNotFound
@@ -178,6 +182,7 @@ func Init(logger schemas.Logger, logsStore logstore.LogStore, pricingManager *pr
 		pricingManager: pricingManager,
 		done:           make(chan struct{}),
 		logger:         logger,
+		sampler:        &RateSampler{}, // Logs every request with its content until configured
 		logMsgPool: sync.Pool{
 			New: func() interface{} {
 				return &LogMessage{}
@@ -236,9 +241,15 @@ func (p *LoggerPlugin) cleanupWorker() {
 func (p *LoggerPlugin) cleanupOldProcessingLogs() {
 	// Calculate timestamp for 5 minutes ago
 	fiveMinutesAgo := time.Now().Add(-1 * 5 * time.Minute)
-	// Delete processing logs older than 5 minutes using the store
-	if err := p.store.CleanupLogs(fiveMinutesAgo); err != nil {
-		p.logger.Error("failed to cleanup old processing logs: %v", err)
+	// Delete processing logs older than 5 minutes using the store, unless another replica
+	// sharing the store purges it
+	p.mu.Lock()
+	isLeader := p.isLeader
+	p.mu.Unlock()
+	if isLeader == nil || isLeader() {
+		if err := p.store.CleanupLogs(fiveMinutesAgo); err != nil {
+			p.logger.Error("failed to cleanup old processing logs: %v", err)
+		}
 	}
 
 	// Clean up old stream accumulators
@@ -252,6 +263,14 @@ func (p *LoggerPlugin) SetLogCallback(callback LogCallback) {
 	p.logCallback = callback
 }
 
+// SetLeaderCheck restricts purging old processing logs to the replica for which isLeader returns
+// true, for replicas sharing a logs store.
+func (p *LoggerPlugin) SetLeaderCheck(isLeader func() bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.isLeader = isLeader
+}
+
 // GetName returns the name of the plugin
 func (p *LoggerPlugin) GetName() string {
 	return PluginName
@@ -297,6 +316,15 @@ func (p *LoggerPlugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest
 		initialData.Tools = req.Params.Tools
 	}
 
+	// Requests not sampled for content logging keep their content in the context, to log it if
+	// they fail and the sampler samples their error
+	sampled := p.getSampler().SampleRequest(*ctx, req)
+	*ctx = context.WithValue(*ctx, ContentSampledContextKey, sampled)
+	if !sampled {
+		*ctx = context.WithValue(*ctx, DeferredContentContextKey, initialData)
+		initialData = withoutContent(initialData)
+	}
+
 	// Store created timestamp in context for latency calculation optimization
 	createdTimestamp := time.Now()
 	*ctx = context.WithValue(*ctx, CreatedTimestampKey, createdTimestamp)
@@ -326,6 +354,7 @@ func (p *LoggerPlugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest
 					InputHistoryParsed: logMsg.InitialData.InputHistory,
 					ParamsParsed:       logMsg.InitialData.Params,
 					ToolsParsed:        logMsg.InitialData.Tools,
+					MetadataOnly:       logMsg.InitialData.MetadataOnly,
 					Status:             "processing",
 					Stream:             false, // Initially false, will be updated if streaming
 					CreatedAt:          logMsg.Timestamp,
@@ -438,7 +467,7 @@ func (p *LoggerPlugin) PostHook(ctx *context.Context, result *schemas.BifrostRes
 					streamUpdateData.TokenUsage.TotalTokens = *transcriptionUsage.TotalTokens
 				}
 			}
-			if result.Transcribe != nil && result.Transcribe.BifrostTranscribeStreamResponse != nil && result.Transcribe.Text != "" {
+			if result.Transcribe != nil && result.Transcribe.BifrostTranscribeStreamResponse != nil && result.Transcribe.Text != "" && contentSampled(*ctx) {
 				streamUpdateData.TranscriptionOutput = result.Transcribe
 			}
 		}
@@ -525,6 +554,15 @@ func (p *LoggerPlugin) PostHook(ctx *context.Context, result *schemas.BifrostRes
 			}
 		}
 
+		if !contentSampled(*ctx) {
+			// Keep the usage extracted above, drop the outputs
+			updateData.OutputMessage = nil
+			updateData.EmbeddingOutput = nil
+			updateData.ToolCalls = nil
+			updateData.SpeechOutput = nil
+			updateData.TranscriptionOutput = nil
+		}
+
 		logMsg.UpdateData = updateData
 	}
 
@@ -631,6 +669,18 @@ func (p *LoggerPlugin) determineObjectType(requestType schemas.RequestType) stri
 		return "audio.transcription"
 	case schemas.TranscriptionStreamRequest:
 		return "audio.transcription.chunk"
+	case schemas.TranslationRequest:
+		return "audio.translation"
+	case schemas.ImageGenerationRequest:
+		return "image.generation"
+	case schemas.VideoGenerationRequest:
+		return "video.generation"
+	case schemas.VideoStatusRequest:
+		return "video.status"
+	case schemas.TokenCountRequest:
+		return "token_count"
+	case schemas.RerankRequest:
+		return "rerank"
 	}
 	return "unknown"
 }
@@ -27,6 +27,7 @@ func (p *LoggerPlugin) insertInitialLogEntry(requestID string, timestamp time.Ti
 		ToolsParsed:              data.Tools,
 		SpeechInputParsed:        data.SpeechInput,
 		TranscriptionInputParsed: data.TranscriptionInput,
+		MetadataOnly:             data.MetadataOnly,
 	}
 
 	return p.store.Create(entry)
@@ -132,6 +133,7 @@ func (p *LoggerPlugin) updateLogEntry(ctx context.Context, requestID string, tim
 		} else {
 			updates["error_details"] = tempEntry.ErrorDetails
 		}
+		p.addErrorContent(ctx, data.ErrorDetails, updates)
 	}
 	return p.store.Update(requestID, updates)
 }
@@ -148,11 +150,13 @@ func (p *LoggerPlugin) processStreamUpdate(ctx context.Context, requestID string
 			tempEntry := &logstore.Log{}
 			tempEntry.ErrorDetailsParsed = data.ErrorDetails
 			if err := tempEntry.SerializeFields(); err == nil {
-				return p.store.Update(requestID, map[string]interface{}{
+				updates := map[string]interface{}{
 					"status":        "error",
 					"error_details": tempEntry.ErrorDetails,
 					"timestamp":     timestamp,
-				})
+				}
+				p.addErrorContent(ctx, data.ErrorDetails, updates)
+				return p.store.Update(requestID, updates)
 			}
 			return err
 		}
@@ -162,12 +166,14 @@ func (p *LoggerPlugin) processStreamUpdate(ctx context.Context, requestID string
 		if err := tempEntry.SerializeFields(); err != nil {
 			return fmt.Errorf("failed to serialize error details: %w", err)
 		}
-		return p.store.Update(requestID, map[string]interface{}{
+		updates := map[string]interface{}{
 			"status":        "error",
 			"latency":       latency,
 			"timestamp":     timestamp,
 			"error_details": tempEntry.ErrorDetails,
-		})
+		}
+		p.addErrorContent(ctx, data.ErrorDetails, updates)
+		return p.store.Update(requestID, updates)
 	}
 
 	// Always mark as streaming and update timestamp
@@ -0,0 +1,223 @@
+// Package logging provides content sampling for the GORM-based logging plugin
+package logging
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/logstore"
+)
+
+// Context keys for content sampling
+const (
+	ContentSampledContextKey  ContextKey = "bifrost-logging-content-sampled"
+	DeferredContentContextKey ContextKey = "bifrost-logging-deferred-content"
+)
+
+// SamplingConfig controls which requests are logged with their full content (input messages,
+// tools, audio inputs and outputs) and which with their metadata only (provider, model, status,
+// latency, tokens, cost and error). Rates are probabilities between 0 and 1.
+type SamplingConfig struct {
+	Rate        *float64           `json:"rate,omitempty"`         // Rate of requests logged with their content, 1 if not set
+	TenantRates map[string]float64 `json:"tenant_rates,omitempty"` // Rates by customer, or team, of the virtual key, overriding Rate
+	ErrorRate   *float64           `json:"error_rate,omitempty"`   // Rate at which failed requests logged without content get it anyway, 1 if not set
+}
+
+// Config is the optional config of the logging plugin.
+type Config struct {
+	Sampling *SamplingConfig `json:"sampling,omitempty"` // Every request is logged with its content if not set
+}
+
+// Validate checks that every rate is between 0 and 1.
+func (c *SamplingConfig) Validate() error {
+	if c.Rate != nil && (*c.Rate < 0 || *c.Rate > 1) {
+		return fmt.Errorf("rate must be between 0 and 1")
+	}
+	if c.ErrorRate != nil && (*c.ErrorRate < 0 || *c.ErrorRate > 1) {
+		return fmt.Errorf("error_rate must be between 0 and 1")
+	}
+	for tenant, rate := range c.TenantRates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("rate of tenant %s must be between 0 and 1", tenant)
+		}
+	}
+	return nil
+}
+
+// ContentSampler decides which requests are logged with their content.
+type ContentSampler interface {
+	// SampleRequest reports whether a request is logged with its content, when it starts.
+	SampleRequest(ctx context.Context, req *schemas.BifrostRequest) bool
+	// SampleError reports whether a failed request that SampleRequest did not sample is logged
+	// with its content anyway.
+	SampleError(ctx context.Context, err *schemas.BifrostError) bool
+}
+
+// RateSampler samples requests at the rate of their tenant, or the default rate, and failed
+// requests at the error rate. Its config can be replaced while requests are sampled.
+type RateSampler struct {
+	mu     sync.RWMutex
+	config SamplingConfig
+}
+
+// NewRateSampler creates a sampler with the given config.
+func NewRateSampler(config SamplingConfig) (*RateSampler, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &RateSampler{config: config}, nil
+}
+
+// GetConfig returns the config of the sampler.
+func (s *RateSampler) GetConfig() SamplingConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// SetConfig replaces the config of the sampler.
+func (s *RateSampler) SetConfig(config SamplingConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+	return nil
+}
+
+func (s *RateSampler) SampleRequest(ctx context.Context, req *schemas.BifrostRequest) bool {
+	s.mu.RLock()
+	rate := s.config.Rate
+	if tenant := requestTenant(ctx); tenant != "" {
+		if tenantRate, ok := s.config.TenantRates[tenant]; ok {
+			rate = &tenantRate
+		}
+	}
+	s.mu.RUnlock()
+	return sample(rate)
+}
+
+func (s *RateSampler) SampleError(ctx context.Context, err *schemas.BifrostError) bool {
+	s.mu.RLock()
+	rate := s.config.ErrorRate
+	s.mu.RUnlock()
+	return sample(rate)
+}
+
+// sample draws with the given rate, 1 if not set.
+func sample(rate *float64) bool {
+	if rate == nil || *rate >= 1 {
+		return true
+	}
+	return *rate > 0 && rand.Float64() < *rate
+}
+
+// requestTenant returns the customer, or else the team, of the virtual key of a request.
+func requestTenant(ctx context.Context) string {
+	if customer, ok := ctx.Value(schemas.BifrostContextKey("x-bf-customer")).(string); ok && customer != "" {
+		return customer
+	}
+	team, _ := ctx.Value(schemas.BifrostContextKey("x-bf-team")).(string)
+	return team
+}
+
+// SetSampler replaces the sampler deciding which requests are logged with their content, e.g.
+// with a custom ContentSampler. GetSamplingConfig and SetSamplingConfig only apply to a
+// RateSampler.
+func (p *LoggerPlugin) SetSampler(sampler ContentSampler) {
+	p.samplerMu.Lock()
+	defer p.samplerMu.Unlock()
+	p.sampler = sampler
+}
+
+// GetSamplingConfig returns the config of the sampler, if it is a RateSampler.
+func (p *LoggerPlugin) GetSamplingConfig() (SamplingConfig, error) {
+	rateSampler, err := p.rateSampler()
+	if err != nil {
+		return SamplingConfig{}, err
+	}
+	return rateSampler.GetConfig(), nil
+}
+
+// SetSamplingConfig replaces the config of the sampler, if it is a RateSampler. It applies to
+// the requests that start afterwards.
+func (p *LoggerPlugin) SetSamplingConfig(config SamplingConfig) error {
+	rateSampler, err := p.rateSampler()
+	if err != nil {
+		return err
+	}
+	return rateSampler.SetConfig(config)
+}
+
+func (p *LoggerPlugin) rateSampler() (*RateSampler, error) {
+	rateSampler, ok := p.getSampler().(*RateSampler)
+	if !ok {
+		return nil, fmt.Errorf("the logging plugin uses a custom content sampler")
+	}
+	return rateSampler, nil
+}
+
+func (p *LoggerPlugin) getSampler() ContentSampler {
+	p.samplerMu.RLock()
+	defer p.samplerMu.RUnlock()
+	return p.sampler
+}
+
+// contentSampled reports whether the request of ctx is logged with its content. Requests without
+// a sampling decision are.
+func contentSampled(ctx context.Context) bool {
+	sampled, ok := ctx.Value(ContentSampledContextKey).(bool)
+	return !ok || sampled
+}
+
+// withoutContent returns the metadata of the initial data of a request: its parameters without
+// their tools.
+func withoutContent(data *InitialLogData) *InitialLogData {
+	metadata := &InitialLogData{
+		Provider:     data.Provider,
+		Model:        data.Model,
+		Object:       data.Object,
+		MetadataOnly: true,
+	}
+	if data.Params != nil {
+		params := *data.Params
+		params.Tools = nil
+		metadata.Params = &params
+	}
+	return metadata
+}
+
+// addErrorContent adds the input content of a failed request logged without it to updates, when
+// the sampler samples the error.
+func (p *LoggerPlugin) addErrorContent(ctx context.Context, bifrostErr *schemas.BifrostError, updates map[string]interface{}) {
+	if bifrostErr == nil || contentSampled(ctx) {
+		return
+	}
+	content, ok := ctx.Value(DeferredContentContextKey).(*InitialLogData)
+	if !ok || content == nil || !p.getSampler().SampleError(ctx, bifrostErr) {
+		return
+	}
+
+	tempEntry := &logstore.Log{
+		InputHistoryParsed:       content.InputHistory,
+		ParamsParsed:             content.Params,
+		ToolsParsed:              content.Tools,
+		SpeechInputParsed:        content.SpeechInput,
+		TranscriptionInputParsed: content.TranscriptionInput,
+	}
+	if err := tempEntry.SerializeFields(); err != nil {
+		p.logger.Error("failed to serialize the content of a failed request: %v", err)
+		return
+	}
+	updates["input_history"] = tempEntry.InputHistory
+	updates["params"] = tempEntry.Params
+	updates["tools"] = tempEntry.Tools
+	updates["speech_input"] = tempEntry.SpeechInput
+	updates["transcription_input"] = tempEntry.TranscriptionInput
+	updates["content_summary"] = tempEntry.ContentSummary
+	updates["metadata_only"] = false
+}
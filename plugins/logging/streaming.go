@@ -165,17 +165,19 @@ func (p *LoggerPlugin) processAccumulatedChunks(ctx context.Context, requestID s
 	if err := tempEntry.SerializeFields(); err != nil {
 		return fmt.Errorf("failed to serialize complete message: %w", err)
 	}
+	sampled := contentSampled(ctx)
 	if respErr != nil {
 		if b, mErr := sonic.Marshal(respErr); mErr == nil {
 			updates["error_details"] = string(b)
 		} else {
 			updates["error_details"] = fmt.Sprintf(`{"message":"failed to marshal error: %v"}`, mErr)
 		}
-	} else {
+		p.addErrorContent(ctx, respErr, updates)
+	} else if sampled {
 		updates["output_message"] = tempEntry.OutputMessage
 		updates["content_summary"] = tempEntry.ContentSummary
 	}
-	if tempEntry.ToolCalls != "" {
+	if tempEntry.ToolCalls != "" && sampled {
 		updates["tool_calls"] = tempEntry.ToolCalls
 	}
 
@@ -362,9 +364,12 @@ func (p *LoggerPlugin) handleStreamingResponse(ctx *context.Context, result *sch
 		if len(result.Choices) > 0 {
 			choice := result.Choices[0]
 			if choice.BifrostStreamResponseChoice != nil {
-				// Create a deep copy of the Delta to avoid pointing to stack memory
-				deltaCopy := choice.BifrostStreamResponseChoice.Delta
-				chunk.Delta = &deltaCopy
+				// Deltas are only accumulated for requests logged with their content
+				if contentSampled(*ctx) {
+					// Create a deep copy of the Delta to avoid pointing to stack memory
+					deltaCopy := choice.BifrostStreamResponseChoice.Delta
+					chunk.Delta = &deltaCopy
+				}
 				chunk.FinishReason = choice.FinishReason
 			}
 		}
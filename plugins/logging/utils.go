@@ -17,6 +17,12 @@ type LogManager interface {
 
 	// GetAvailableModels returns all unique models from logs
 	GetAvailableModels() []string
+
+	// GetSamplingConfig returns which requests are logged with their content
+	GetSamplingConfig() (SamplingConfig, error)
+
+	// SetSamplingConfig changes which requests are logged with their content
+	SetSamplingConfig(config SamplingConfig) error
 }
 
 // PluginLogManager implements LogManager interface wrapping the plugin
@@ -40,6 +46,14 @@ func (p *PluginLogManager) GetAvailableModels() []string {
 	return p.plugin.GetAvailableModels()
 }
 
+func (p *PluginLogManager) GetSamplingConfig() (SamplingConfig, error) {
+	return p.plugin.GetSamplingConfig()
+}
+
+func (p *PluginLogManager) SetSamplingConfig(config SamplingConfig) error {
+	return p.plugin.SetSamplingConfig(config)
+}
+
 // GetPluginLogManager returns a LogManager interface for this plugin
 func (p *LoggerPlugin) GetPluginLogManager() *PluginLogManager {
 	return &PluginLogManager{
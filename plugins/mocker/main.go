@@ -793,6 +793,7 @@ func (p *MockerPlugin) generateErrorShortCircuit(req *schemas.BifrostRequest, re
 
 	// Create mock error
 	mockError := &schemas.BifrostError{
+		Origin: schemas.ErrorOriginProvider,
 		Error: schemas.ErrorField{
 			Message: errorContent.Message,
 		},
@@ -884,6 +885,7 @@ func (p *MockerPlugin) handleDefaultBehavior(req *schemas.BifrostRequest) (*sche
 	case DefaultBehaviorError:
 		return req, &schemas.PluginShortCircuit{
 			Error: &schemas.BifrostError{
+				Origin: schemas.ErrorOriginProvider,
 				Error: schemas.ErrorField{
 					Message: "Mock plugin default error",
 				},
@@ -0,0 +1,537 @@
+// Package policywebhook provides a Bifrost plugin that delegates allow/deny decisions to an
+// external policy service. Requests and responses are redacted, POSTed to the service, and the
+// returned verdict is enforced before the request reaches the provider or the response reaches the caller.
+//
+// Streaming requests are only checked at the request stage: their chunks reach the caller as they
+// are produced, so there is no complete response to evaluate, and a deny or transform verdict
+// could not take back what was already sent. Routes that must vet responses should not allow
+// streaming.
+package policywebhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	PluginName = "policy-webhook"
+
+	// DefaultTimeout is used when neither the plugin nor the matching route configures a timeout.
+	DefaultTimeout = 2 * time.Second
+
+	redactedPlaceholder = "[REDACTED]"
+)
+
+// FailMode controls what happens when the policy service cannot be reached or returns an invalid verdict.
+type FailMode string
+
+const (
+	FailOpen   FailMode = "open"   // Let the request or response through
+	FailClosed FailMode = "closed" // Reject the request or response
+)
+
+// Stage identifies which side of a provider call is being checked.
+type Stage string
+
+const (
+	StageRequest  Stage = "request"
+	StageResponse Stage = "response"
+)
+
+// Verdict is the decision returned by the policy service.
+type Verdict string
+
+const (
+	VerdictAllow     Verdict = "allow"
+	VerdictDeny      Verdict = "deny"
+	VerdictTransform Verdict = "transform"
+)
+
+// DefaultRedactPatterns are applied to every string sent to the policy service when
+// Config.RedactPatterns is nil. Set RedactPatterns to an empty list to send content unredacted.
+var DefaultRedactPatterns = []string{
+	`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`, // Email addresses
+	`(?i)bearer\s+[A-Za-z0-9._\-]+`,                    // Bearer tokens
+	`\b(sk|pk|rk)-[A-Za-z0-9_\-]{16,}`,                 // API keys
+}
+
+// Config defines the policy service and how its verdicts are enforced.
+type Config struct {
+	URL            string            `json:"url"`             // Policy service endpoint that receives the POSTed payloads
+	Headers        map[string]string `json:"headers"`         // Extra headers sent with every call (e.g. authorization)
+	Timeout        time.Duration     `json:"timeout"`         // Per-call timeout, accepts "500ms" or seconds in JSON (default 2s)
+	FailMode       FailMode          `json:"fail_mode"`       // Behaviour when the service is unavailable: "open" (default) or "closed"
+	RedactPatterns []string          `json:"redact_patterns"` // Regex patterns replaced before content leaves Bifrost (nil = DefaultRedactPatterns)
+	Routes         []Route           `json:"routes"`          // Per-route overrides, the first matching route applies
+}
+
+// Route overrides the policy behaviour for the requests it matches.
+// An empty match list matches everything, so a route without conditions acts as a catch-all.
+type Route struct {
+	Name         string                `json:"name"`
+	Providers    []string              `json:"providers"`     // Match specific providers (e.g., ["openai", "anthropic"])
+	Models       []string              `json:"models"`        // Match specific models (e.g., ["gpt-4o"])
+	RequestTypes []schemas.RequestType `json:"request_types"` // Match specific request types (e.g., ["chat_completion"])
+	Stages       []Stage               `json:"stages"`        // Stages to check, empty checks both requests and responses
+	Timeout      time.Duration         `json:"timeout"`       // Overrides Config.Timeout
+	FailMode     FailMode              `json:"fail_mode"`     // Overrides Config.FailMode
+	Skip         bool                  `json:"skip"`          // Bypass the policy service for matching requests
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for Config, accepting the timeout
+// as a duration string ("500ms", "2s") or as a number of seconds.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type alias Config
+	temp := struct {
+		*alias
+		Timeout interface{} `json:"timeout,omitempty"`
+	}{alias: (*alias)(c)}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	timeout, err := parseDuration(temp.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+	c.Timeout = timeout
+	return nil
+}
+
+// UnmarshalJSON implements custom JSON unmarshaling for Route, accepting the timeout
+// in the same formats as Config.
+func (r *Route) UnmarshalJSON(data []byte) error {
+	type alias Route
+	temp := struct {
+		*alias
+		Timeout interface{} `json:"timeout,omitempty"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return fmt.Errorf("failed to unmarshal route: %w", err)
+	}
+	timeout, err := parseDuration(temp.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout for route %q: %w", r.Name, err)
+	}
+	r.Timeout = timeout
+	return nil
+}
+
+// parseDuration converts a JSON duration string or number of seconds into a time.Duration.
+func parseDuration(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		return time.ParseDuration(v)
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	default:
+		return 0, fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+// PolicyRequest is the payload POSTed to the policy service.
+type PolicyRequest struct {
+	Stage       Stage               `json:"stage"`
+	Provider    string              `json:"provider"`
+	Model       string              `json:"model"`
+	RequestType schemas.RequestType `json:"request_type,omitempty"`
+	Route       string              `json:"route,omitempty"`
+	Input       interface{}         `json:"input,omitempty"`    // Redacted request input
+	Params      interface{}         `json:"params,omitempty"`   // Redacted request parameters
+	Response    interface{}         `json:"response,omitempty"` // Redacted response, set on the response stage only
+}
+
+// PolicyResponse is the verdict returned by the policy service.
+// Input replaces the request input on a request stage transform, Response replaces the
+// response on a response stage transform.
+type PolicyResponse struct {
+	Verdict  Verdict                  `json:"verdict"`
+	Reason   string                   `json:"reason,omitempty"`
+	Input    *schemas.RequestInput    `json:"input,omitempty"`
+	Response *schemas.BifrostResponse `json:"response,omitempty"`
+}
+
+// PolicyWebhookPlugin enforces the verdicts of an external policy service.
+type PolicyWebhookPlugin struct {
+	config   Config
+	redactor []*regexp.Regexp
+	client   *http.Client
+	logger   schemas.Logger
+}
+
+// Init creates a new policy webhook plugin. A nil logger falls back to the default Bifrost logger.
+func Init(config Config, logger schemas.Logger) (*PolicyWebhookPlugin, error) {
+	if config.URL == "" {
+		return nil, errors.New("policy service url is required")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.FailMode == "" {
+		config.FailMode = FailOpen
+	}
+	if err := validateFailMode(config.FailMode); err != nil {
+		return nil, err
+	}
+	for _, route := range config.Routes {
+		if route.FailMode != "" {
+			if err := validateFailMode(route.FailMode); err != nil {
+				return nil, fmt.Errorf("route %q: %w", route.Name, err)
+			}
+		}
+		for _, stage := range route.Stages {
+			if stage != StageRequest && stage != StageResponse {
+				return nil, fmt.Errorf("route %q: invalid stage %q", route.Name, stage)
+			}
+		}
+	}
+
+	patterns := config.RedactPatterns
+	if patterns == nil {
+		patterns = DefaultRedactPatterns
+	}
+	redactor := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		redactor = append(redactor, re)
+	}
+
+	if logger == nil {
+		logger = bifrost.NewDefaultLogger(schemas.LogLevelInfo)
+	}
+
+	return &PolicyWebhookPlugin{
+		config:   config,
+		redactor: redactor,
+		client:   &http.Client{},
+		logger:   logger,
+	}, nil
+}
+
+func validateFailMode(mode FailMode) error {
+	if mode != FailOpen && mode != FailClosed {
+		return fmt.Errorf("invalid fail mode %q, must be %q or %q", mode, FailOpen, FailClosed)
+	}
+	return nil
+}
+
+// GetName returns the name of the plugin.
+func (p *PolicyWebhookPlugin) GetName() string {
+	return PluginName
+}
+
+// PreHook sends the redacted request to the policy service. A deny verdict short-circuits
+// the request with a 403 error, a transform verdict replaces the request input.
+func (p *PolicyWebhookPlugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
+	requestType := getRequestType(ctx)
+	route := p.findRoute(req.Provider, req.Model, requestType)
+	if !p.shouldCheck(route, StageRequest) {
+		return req, nil, nil
+	}
+
+	payload := &PolicyRequest{
+		Stage:       StageRequest,
+		Provider:    string(req.Provider),
+		Model:       req.Model,
+		RequestType: requestType,
+		Input:       p.redact(req.Input),
+	}
+	if req.Params != nil {
+		payload.Params = p.redact(req.Params)
+	}
+	if route != nil {
+		payload.Route = route.Name
+	}
+
+	verdict, err := p.evaluate(*ctx, route, payload)
+	if err != nil {
+		if p.failMode(route) == FailClosed {
+			return req, &schemas.PluginShortCircuit{Error: unavailableError(err)}, nil
+		}
+		p.logger.Warn("%s: policy check failed, allowing request: %v", PluginName, err)
+		return req, nil, nil
+	}
+
+	switch verdict.Verdict {
+	case VerdictDeny:
+		return req, &schemas.PluginShortCircuit{Error: deniedError(StageRequest, verdict.Reason)}, nil
+	case VerdictTransform:
+		req.Input = *verdict.Input
+		schemas.AddWarning(*ctx, schemas.WarningEnforced, PluginName, "the request input was transformed by the policy service")
+	}
+	return req, nil, nil
+}
+
+// PostHook sends the redacted response to the policy service. A deny verdict replaces the
+// response with a 403 error, a transform verdict replaces the response content.
+// Streaming chunks and errors are passed through unchecked, see the package documentation.
+func (p *PolicyWebhookPlugin) PostHook(ctx *context.Context, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	if result == nil || bifrostErr != nil {
+		return result, bifrostErr, nil
+	}
+	requestType := getRequestType(ctx)
+	if bifrost.IsStreamRequestType(requestType) {
+		return result, bifrostErr, nil
+	}
+
+	provider := result.ExtraFields.Provider
+	model := result.Model
+	if requestModel, ok := (*ctx).Value(schemas.BifrostContextKeyRequestModel).(string); ok && requestModel != "" {
+		model = requestModel
+	}
+	route := p.findRoute(provider, model, requestType)
+	if !p.shouldCheck(route, StageResponse) {
+		return result, bifrostErr, nil
+	}
+
+	payload := &PolicyRequest{
+		Stage:       StageResponse,
+		Provider:    string(provider),
+		Model:       model,
+		RequestType: requestType,
+		Response:    p.redact(result),
+	}
+	if route != nil {
+		payload.Route = route.Name
+	}
+
+	verdict, err := p.evaluate(*ctx, route, payload)
+	if err != nil {
+		if p.failMode(route) == FailClosed {
+			return nil, unavailableError(err), nil
+		}
+		p.logger.Warn("%s: policy check failed, allowing response: %v", PluginName, err)
+		return result, nil, nil
+	}
+
+	switch verdict.Verdict {
+	case VerdictDeny:
+		return nil, deniedError(StageResponse, verdict.Reason), nil
+	case VerdictTransform:
+		transformed := verdict.Response
+		transformed.ExtraFields = result.ExtraFields
+		schemas.AddWarning(*ctx, schemas.WarningEnforced, PluginName, "the response was transformed by the policy service")
+		return transformed, nil, nil
+	}
+	return result, nil, nil
+}
+
+// Cleanup releases idle connections to the policy service.
+func (p *PolicyWebhookPlugin) Cleanup() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// Validate checks that the policy service is reachable and accepts the configured headers, for the
+// startup self-test. It sends a HEAD request, so any other response status counts as reachable.
+func (p *PolicyWebhookPlugin) Validate(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodHead, p.config.URL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid policy service url: %w", err)
+	}
+	for key, value := range p.config.Headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	httpResp, err := p.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("policy service unreachable: %w", err)
+	}
+	httpResp.Body.Close()
+	if httpResp.StatusCode == http.StatusUnauthorized || httpResp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("policy service rejected the configured headers with status %d", httpResp.StatusCode)
+	}
+	return nil
+}
+
+// evaluate POSTs the payload to the policy service and decodes its verdict.
+func (p *PolicyWebhookPlugin) evaluate(ctx context.Context, route *Route, payload *PolicyRequest) (*PolicyResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal policy request: %w", err)
+	}
+
+	timeout := p.config.Timeout
+	if route != nil && route.Timeout > 0 {
+		timeout = route.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for key, value := range p.config.Headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	httpResp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("policy service request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy service response: %w", err)
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return nil, fmt.Errorf("policy service returned status %d", httpResp.StatusCode)
+	}
+
+	var verdict PolicyResponse
+	if err := json.Unmarshal(respBody, &verdict); err != nil {
+		return nil, fmt.Errorf("failed to decode policy verdict: %w", err)
+	}
+	switch verdict.Verdict {
+	case VerdictAllow, VerdictDeny:
+		return &verdict, nil
+	case VerdictTransform:
+		// A transform without a replacement is treated like an unreachable service, so the
+		// route's fail mode decides the outcome
+		if payload.Stage == StageRequest && verdict.Input == nil {
+			return nil, errors.New("transform verdict without input")
+		}
+		if payload.Stage == StageResponse && verdict.Response == nil {
+			return nil, errors.New("transform verdict without response")
+		}
+		return &verdict, nil
+	default:
+		return nil, fmt.Errorf("unknown policy verdict %q", verdict.Verdict)
+	}
+}
+
+// findRoute returns the first route matching the request, or nil when none match.
+func (p *PolicyWebhookPlugin) findRoute(provider schemas.ModelProvider, model string, requestType schemas.RequestType) *Route {
+	for i := range p.config.Routes {
+		route := &p.config.Routes[i]
+		if len(route.Providers) > 0 && !slices.Contains(route.Providers, string(provider)) {
+			continue
+		}
+		if len(route.Models) > 0 && !slices.Contains(route.Models, model) {
+			continue
+		}
+		if len(route.RequestTypes) > 0 && !slices.Contains(route.RequestTypes, requestType) {
+			continue
+		}
+		return route
+	}
+	return nil
+}
+
+// shouldCheck reports whether the given stage is checked for the matched route.
+// Requests without a matching route are checked at both stages.
+func (p *PolicyWebhookPlugin) shouldCheck(route *Route, stage Stage) bool {
+	if route == nil {
+		return true
+	}
+	if route.Skip {
+		return false
+	}
+	return len(route.Stages) == 0 || slices.Contains(route.Stages, stage)
+}
+
+// failMode returns the fail mode of the matched route, falling back to the plugin default.
+func (p *PolicyWebhookPlugin) failMode(route *Route) FailMode {
+	if route != nil && route.FailMode != "" {
+		return route.FailMode
+	}
+	return p.config.FailMode
+}
+
+// redact converts value into its generic JSON form and masks every string matching a redact pattern.
+func (p *PolicyWebhookPlugin) redact(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil
+	}
+	if len(p.redactor) == 0 {
+		return generic
+	}
+	return p.redactValue(generic)
+}
+
+func (p *PolicyWebhookPlugin) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		for _, re := range p.redactor {
+			v = re.ReplaceAllString(v, redactedPlaceholder)
+		}
+		return v
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = p.redactValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = p.redactValue(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func getRequestType(ctx *context.Context) schemas.RequestType {
+	if ctx == nil || *ctx == nil {
+		return ""
+	}
+	requestType, _ := (*ctx).Value(schemas.BifrostContextKeyRequestType).(schemas.RequestType)
+	return requestType
+}
+
+func deniedError(stage Stage, reason string) *schemas.BifrostError {
+	message := fmt.Sprintf("%s blocked by policy", stage)
+	if reason != "" {
+		message = fmt.Sprintf("%s: %s", message, reason)
+	}
+	return &schemas.BifrostError{
+		Type:           bifrost.Ptr("policy_violation"),
+		StatusCode:     bifrost.Ptr(http.StatusForbidden),
+		Origin:         schemas.ErrorOriginPolicy,
+		AllowFallbacks: bifrost.Ptr(false),
+		Error: schemas.ErrorField{
+			Type:    bifrost.Ptr("policy_violation"),
+			Message: message,
+		},
+	}
+}
+
+func unavailableError(err error) *schemas.BifrostError {
+	return &schemas.BifrostError{
+		Type:           bifrost.Ptr("policy_unavailable"),
+		StatusCode:     bifrost.Ptr(http.StatusServiceUnavailable),
+		Origin:         schemas.ErrorOriginPolicy,
+		AllowFallbacks: bifrost.Ptr(false),
+		Error: schemas.ErrorField{
+			Type:    bifrost.Ptr("policy_unavailable"),
+			Message: "policy service unavailable",
+			Error:   err,
+		},
+	}
+}
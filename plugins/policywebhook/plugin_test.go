@@ -0,0 +1,215 @@
+package policywebhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+// policyService is a fake policy service answering every call with a fixed status and body,
+// and recording the payloads it receives.
+type policyService struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []PolicyRequest
+}
+
+func newPolicyService(t *testing.T, status int, response string) *policyService {
+	service := &policyService{}
+	service.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload PolicyRequest
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode policy request: %v", err)
+		}
+		service.mu.Lock()
+		service.payloads = append(service.payloads, payload)
+		service.mu.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(service.Close)
+	return service
+}
+
+func (s *policyService) calls() []PolicyRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]PolicyRequest(nil), s.payloads...)
+}
+
+func newTestPlugin(t *testing.T, config Config) *PolicyWebhookPlugin {
+	plugin, err := Init(config, bifrost.NewDefaultLogger(schemas.LogLevelError))
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	t.Cleanup(func() { plugin.Cleanup() })
+	return plugin
+}
+
+func chatRequest(text string) *schemas.BifrostRequest {
+	messages := []schemas.BifrostMessage{{Role: schemas.ModelChatMessageRoleUser, Content: schemas.MessageContent{ContentStr: &text}}}
+	return &schemas.BifrostRequest{Provider: schemas.OpenAI, Model: "gpt-4o", Input: schemas.RequestInput{ChatCompletionInput: &messages}}
+}
+
+func requestContext(requestType schemas.RequestType) *context.Context {
+	ctx := context.WithValue(context.Background(), schemas.BifrostContextKeyRequestType, requestType)
+	return &ctx
+}
+
+func TestPreHookVerdicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		response   string
+		config     Config
+		wantStatus int // Status of the short-circuit error, 0 if the request goes through
+		wantText   string
+	}{
+		{name: "allow", status: http.StatusOK, response: `{"verdict":"allow"}`, wantText: "hello"},
+		{name: "deny", status: http.StatusOK, response: `{"verdict":"deny","reason":"no"}`, wantStatus: http.StatusForbidden},
+		{
+			name:     "transform",
+			status:   http.StatusOK,
+			response: `{"verdict":"transform","input":{"chat_completion_input":[{"role":"user","content":"rewritten"}]}}`,
+			wantText: "rewritten",
+		},
+		{name: "fail open on error status", status: http.StatusInternalServerError, wantText: "hello"},
+		{name: "fail open on unknown verdict", status: http.StatusOK, response: `{"verdict":"maybe"}`, wantText: "hello"},
+		{name: "fail closed", status: http.StatusInternalServerError, config: Config{FailMode: FailClosed}, wantStatus: http.StatusServiceUnavailable},
+		{
+			name:       "fail closed on transform without input",
+			status:     http.StatusOK,
+			response:   `{"verdict":"transform"}`,
+			config:     Config{FailMode: FailClosed},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "route fail mode overrides the default",
+			status:     http.StatusBadGateway,
+			config:     Config{Routes: []Route{{Name: "openai", Providers: []string{"openai"}, FailMode: FailClosed}}},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newPolicyService(t, tt.status, tt.response)
+			config := tt.config
+			config.URL = service.URL
+			plugin := newTestPlugin(t, config)
+
+			req, shortCircuit, err := plugin.PreHook(requestContext(schemas.ChatCompletionRequest), chatRequest("hello"))
+			if err != nil {
+				t.Fatalf("PreHook failed: %v", err)
+			}
+			if len(service.calls()) != 1 {
+				t.Errorf("policy service called %d times, want 1", len(service.calls()))
+			}
+			if tt.wantStatus != 0 {
+				if shortCircuit == nil || shortCircuit.Error == nil || *shortCircuit.Error.StatusCode != tt.wantStatus {
+					t.Fatalf("short circuit = %+v, want an error with status %d", shortCircuit, tt.wantStatus)
+				}
+				if shortCircuit.Error.Origin != schemas.ErrorOriginPolicy {
+					t.Errorf("error origin = %s, want %s", shortCircuit.Error.Origin, schemas.ErrorOriginPolicy)
+				}
+				return
+			}
+			if shortCircuit != nil {
+				t.Fatalf("short circuit = %+v, want the request to go through", shortCircuit)
+			}
+			if got := *(*req.Input.ChatCompletionInput)[0].Content.ContentStr; got != tt.wantText {
+				t.Errorf("request text = %q, want %q", got, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestPreHookRedaction(t *testing.T) {
+	text := "mail jane@example.com with key sk-abcdefghijklmnopqrstuv"
+	tests := []struct {
+		name     string
+		patterns []string
+		want     string
+	}{
+		{name: "default patterns", want: "mail [REDACTED] with key [REDACTED]"},
+		{name: "custom pattern", patterns: []string{`jane`}, want: "mail [REDACTED]@example.com with key sk-abcdefghijklmnopqrstuv"},
+		{name: "no patterns", patterns: []string{}, want: text},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newPolicyService(t, http.StatusOK, `{"verdict":"allow"}`)
+			plugin := newTestPlugin(t, Config{URL: service.URL, RedactPatterns: tt.patterns})
+
+			req, _, err := plugin.PreHook(requestContext(schemas.ChatCompletionRequest), chatRequest(text))
+			if err != nil {
+				t.Fatalf("PreHook failed: %v", err)
+			}
+			calls := service.calls()
+			if len(calls) != 1 {
+				t.Fatalf("policy service called %d times, want 1", len(calls))
+			}
+			sent, _ := json.Marshal(calls[0].Input)
+			if !strings.Contains(string(sent), tt.want) {
+				t.Errorf("policy service got input %s, want it to contain %q", sent, tt.want)
+			}
+			if got := *(*req.Input.ChatCompletionInput)[0].Content.ContentStr; got != text {
+				t.Errorf("request text = %q, redaction must not change the request", got)
+			}
+		})
+	}
+}
+
+func TestPostHook(t *testing.T) {
+	response := func() *schemas.BifrostResponse {
+		return &schemas.BifrostResponse{Model: "gpt-4o", ExtraFields: schemas.BifrostResponseExtraFields{Provider: schemas.OpenAI}}
+	}
+	tests := []struct {
+		name        string
+		requestType schemas.RequestType
+		verdict     string
+		config      Config
+		wantCalls   int
+		wantStatus  int // Status of the error replacing the response, 0 if it goes through
+	}{
+		{name: "allow", requestType: schemas.ChatCompletionRequest, verdict: `{"verdict":"allow"}`, wantCalls: 1},
+		{name: "deny", requestType: schemas.ChatCompletionRequest, verdict: `{"verdict":"deny"}`, wantCalls: 1, wantStatus: http.StatusForbidden},
+		{name: "streaming responses are not checked", requestType: schemas.ChatCompletionStreamRequest, verdict: `{"verdict":"deny"}`},
+		{
+			name:        "request stage only route",
+			requestType: schemas.ChatCompletionRequest,
+			verdict:     `{"verdict":"deny"}`,
+			config:      Config{Routes: []Route{{Name: "requests", Stages: []Stage{StageRequest}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newPolicyService(t, http.StatusOK, tt.verdict)
+			config := tt.config
+			config.URL = service.URL
+			plugin := newTestPlugin(t, config)
+
+			result, bifrostErr, err := plugin.PostHook(requestContext(tt.requestType), response(), nil)
+			if err != nil {
+				t.Fatalf("PostHook failed: %v", err)
+			}
+			if len(service.calls()) != tt.wantCalls {
+				t.Errorf("policy service called %d times, want %d", len(service.calls()), tt.wantCalls)
+			}
+			if tt.wantStatus != 0 {
+				if result != nil || bifrostErr == nil || *bifrostErr.StatusCode != tt.wantStatus {
+					t.Errorf("PostHook = %+v, %+v, want an error with status %d", result, bifrostErr, tt.wantStatus)
+				}
+				return
+			}
+			if result == nil || bifrostErr != nil {
+				t.Errorf("PostHook = %+v, %+v, want the response to go through", result, bifrostErr)
+			}
+		})
+	}
+}
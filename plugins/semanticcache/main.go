@@ -40,6 +40,11 @@ type Config struct {
 	CacheByModel                 *bool `json:"cache_by_model,omitempty"`                 // Include model in cache key (default: true)
 	CacheByProvider              *bool `json:"cache_by_provider,omitempty"`              // Include provider in cache key (default: true)
 	ExcludeSystemPrompt          *bool `json:"exclude_system_prompt,omitempty"`          // Exclude system prompt in cache key (default: false)
+
+	// Transcription caching, keyed by a hash of the audio content
+	TranscriptionTTL             time.Duration `json:"transcription_ttl,omitempty"`               // Time-to-live for cached transcriptions (default: TTL)
+	TranscriptionMaxAudioSize    int64         `json:"transcription_max_audio_size,omitempty"`    // Audio larger than this many bytes is not cached (default: 25MB)
+	TranscriptionMaxResponseSize int           `json:"transcription_max_response_size,omitempty"` // Transcriptions larger than this many bytes are not cached (default: 1MB)
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling for semantic cache Config.
@@ -59,6 +64,9 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 		CacheByModel                 *bool         `json:"cache_by_model,omitempty"`
 		CacheByProvider              *bool         `json:"cache_by_provider,omitempty"`
 		ExcludeSystemPrompt          *bool         `json:"exclude_system_prompt,omitempty"`
+		TranscriptionTTL             interface{}   `json:"transcription_ttl,omitempty"`
+		TranscriptionMaxAudioSize    int64         `json:"transcription_max_audio_size,omitempty"`
+		TranscriptionMaxResponseSize int           `json:"transcription_max_response_size,omitempty"`
 	}
 
 	var temp TempConfig
@@ -78,33 +86,49 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	c.ConversationHistoryThreshold = temp.ConversationHistoryThreshold
 	c.Threshold = temp.Threshold
 	c.ExcludeSystemPrompt = temp.ExcludeSystemPrompt
-	// Handle TTL field with custom parsing for VectorStore-backed cache behavior
-	if temp.TTL != nil {
-		switch v := temp.TTL.(type) {
-		case string:
-			// Try parsing as duration string (e.g., "1m", "1hr") for semantic cache TTL
-			duration, err := time.ParseDuration(v)
-			if err != nil {
-				return fmt.Errorf("failed to parse TTL duration string '%s': %w", v, err)
-			}
-			c.TTL = duration
-		case int:
-			// Handle integer seconds for semantic cache TTL
-			c.TTL = time.Duration(v) * time.Second
-		default:
-			// Try converting to string and parsing as number for semantic cache TTL
-			ttlStr := fmt.Sprintf("%v", v)
-			if seconds, err := strconv.ParseFloat(ttlStr, 64); err == nil {
-				c.TTL = time.Duration(seconds * float64(time.Second))
-			} else {
-				return fmt.Errorf("unsupported TTL type: %T (value: %v)", v, v)
-			}
-		}
+	c.TranscriptionMaxAudioSize = temp.TranscriptionMaxAudioSize
+	c.TranscriptionMaxResponseSize = temp.TranscriptionMaxResponseSize
+
+	// Handle TTL fields with custom parsing for VectorStore-backed cache behavior
+	ttl, err := parseTTL(temp.TTL)
+	if err != nil {
+		return err
+	}
+	c.TTL = ttl
+	transcriptionTTL, err := parseTTL(temp.TranscriptionTTL)
+	if err != nil {
+		return fmt.Errorf("transcription_ttl: %w", err)
 	}
+	c.TranscriptionTTL = transcriptionTTL
 
 	return nil
 }
 
+// parseTTL parses a TTL from a duration string ("1m", "1hr") or a number of seconds, 0 if unset.
+func parseTTL(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		// Try parsing as duration string (e.g., "1m", "1hr") for semantic cache TTL
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse TTL duration string '%s': %w", v, err)
+		}
+		return duration, nil
+	case int:
+		// Handle integer seconds for semantic cache TTL
+		return time.Duration(v) * time.Second, nil
+	default:
+		// Try converting to string and parsing as number for semantic cache TTL
+		ttlStr := fmt.Sprintf("%v", v)
+		if seconds, err := strconv.ParseFloat(ttlStr, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+		return 0, fmt.Errorf("unsupported TTL type: %T (value: %v)", v, v)
+	}
+}
+
 // StreamChunk represents a single chunk from a streaming response
 type StreamChunk struct {
 	Timestamp    time.Time                // When chunk was received
@@ -153,6 +177,8 @@ const (
 	DefaultCacheTTL                     time.Duration = 5 * time.Minute
 	DefaultCacheThreshold               float64       = 0.8
 	DefaultConversationHistoryThreshold int           = 3
+	DefaultTranscriptionMaxAudioSize    int64         = 25 * 1024 * 1024
+	DefaultTranscriptionMaxResponseSize int           = 1024 * 1024
 )
 
 var SelectFields = []string{"request_hash", "response", "stream_chunks", "expires_at", "cache_key", "provider", "model"}
@@ -239,6 +265,7 @@ const (
 	requestProviderKey        ContextKey = "semantic_cache_provider"
 	isCacheHitKey             ContextKey = "semantic_cache_is_cache_hit"
 	cacheHitTypeKey           ContextKey = "semantic_cache_cache_hit_type"
+	skipStoreKey              ContextKey = "semantic_cache_skip_store"
 )
 
 type CacheType string
@@ -281,6 +308,16 @@ func Init(ctx context.Context, config Config, logger schemas.Logger, store vecto
 		config.ConversationHistoryThreshold = DefaultConversationHistoryThreshold
 	}
 
+	if config.TranscriptionTTL == 0 {
+		config.TranscriptionTTL = config.TTL
+	}
+	if config.TranscriptionMaxAudioSize == 0 {
+		config.TranscriptionMaxAudioSize = DefaultTranscriptionMaxAudioSize
+	}
+	if config.TranscriptionMaxResponseSize == 0 {
+		config.TranscriptionMaxResponseSize = DefaultTranscriptionMaxResponseSize
+	}
+
 	// Set cache behavior defaults
 	if config.CacheByModel == nil {
 		config.CacheByModel = bifrost.Ptr(true)
@@ -461,6 +498,11 @@ func (plugin *Plugin) PostHook(ctx *context.Context, res *schemas.BifrostRespons
 		}
 	}
 
+	// Check if the request was not eligible for caching, e.g. a transcription above the size limit
+	if skipStore, ok := (*ctx).Value(skipStoreKey).(bool); ok && skipStore {
+		return res, nil, nil
+	}
+
 	// Get the request type from context
 	requestType, ok := (*ctx).Value(schemas.BifrostContextKeyRequestType).(schemas.RequestType)
 	if !ok {
@@ -546,6 +588,9 @@ func (plugin *Plugin) PostHook(ctx *context.Context, res *schemas.BifrostRespons
 	}
 
 	cacheTTL := plugin.config.TTL
+	if requestType == schemas.TranscriptionRequest {
+		cacheTTL = plugin.config.TranscriptionTTL
+	}
 
 	ttlValue := (*ctx).Value(CacheTTLKey)
 	if ttlValue != nil {
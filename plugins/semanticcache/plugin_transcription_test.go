@@ -0,0 +1,101 @@
+package semanticcache
+
+import (
+	"slices"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+func createTranscriptionRequest(audio []byte, responseFormat string) *schemas.BifrostRequest {
+	input := &schemas.TranscriptionInput{
+		File:     audio,
+		Language: bifrost.Ptr("en"),
+	}
+	if responseFormat != "" {
+		input.ResponseFormat = bifrost.Ptr(responseFormat)
+	}
+	return &schemas.BifrostRequest{
+		Provider: schemas.OpenAI,
+		Model:    "whisper-1",
+		Input:    schemas.RequestInput{TranscriptionInput: input},
+	}
+}
+
+func mustTranscriptionHash(t *testing.T, req *schemas.BifrostRequest) string {
+	t.Helper()
+	hash, err := (&Plugin{}).generateTranscriptionHash(req)
+	if err != nil {
+		t.Fatalf("Failed to generate transcription hash: %v", err)
+	}
+	return hash
+}
+
+// TestTranscriptionHash tests that transcriptions are keyed by audio content and not by response format
+func TestTranscriptionHash(t *testing.T) {
+	verbose := mustTranscriptionHash(t, createTranscriptionRequest([]byte("audio-1"), TranscriptionFormatVerboseJSON))
+	json := mustTranscriptionHash(t, createTranscriptionRequest([]byte("audio-1"), ""))
+	if verbose != json {
+		t.Errorf("Response format should not change the hash: %s != %s", verbose, json)
+	}
+
+	if other := mustTranscriptionHash(t, createTranscriptionRequest([]byte("audio-2"), "")); other == json {
+		t.Error("Different audio should change the hash")
+	}
+
+	req := createTranscriptionRequest([]byte("audio-1"), "")
+	req.Input.TranscriptionInput.Prompt = bifrost.Ptr("names: Bifrost")
+	if prompted := mustTranscriptionHash(t, req); prompted == json {
+		t.Error("Prompt should change the hash")
+	}
+}
+
+// TestTranscriptionSources tests which cached formats can serve each response format
+func TestTranscriptionSources(t *testing.T) {
+	if format := getTranscriptionFormat(&schemas.TranscriptionInput{}); format != TranscriptionFormatJSON {
+		t.Errorf("Expected default format %s, got %s", TranscriptionFormatJSON, format)
+	}
+	for _, format := range []string{TranscriptionFormatJSON, TranscriptionFormatText} {
+		if !slices.Contains(transcriptionSources[format], TranscriptionFormatVerboseJSON) {
+			t.Errorf("Expected %s to be served from %s", format, TranscriptionFormatVerboseJSON)
+		}
+	}
+	if sources := transcriptionSources[TranscriptionFormatVerboseJSON]; !slices.Equal(sources, []string{TranscriptionFormatVerboseJSON}) {
+		t.Errorf("Expected %s to be served from itself only, got %v", TranscriptionFormatVerboseJSON, sources)
+	}
+	if _, ok := transcriptionSources["srt"]; ok {
+		t.Error("Expected srt not to be cached")
+	}
+}
+
+// TestDeriveTranscription tests that a verbose transcription is reduced to the json fields
+func TestDeriveTranscription(t *testing.T) {
+	newResponse := func() *schemas.BifrostResponse {
+		return &schemas.BifrostResponse{
+			Transcribe: &schemas.BifrostTranscribe{
+				Text: "hello world",
+				BifrostTranscribeNonStreamResponse: &schemas.BifrostTranscribeNonStreamResponse{
+					Language: bifrost.Ptr("english"),
+					Duration: bifrost.Ptr(1.5),
+					Segments: []schemas.TranscriptionSegment{{Text: "hello world"}},
+				},
+			},
+		}
+	}
+
+	res := newResponse()
+	deriveTranscription(res, TranscriptionFormatJSON)
+	if res.Transcribe.Text != "hello world" {
+		t.Errorf("Expected the transcript to be kept, got %q", res.Transcribe.Text)
+	}
+	if res.Transcribe.BifrostTranscribeNonStreamResponse != nil {
+		t.Error("Expected the verbose fields to be removed for json")
+	}
+
+	res = newResponse()
+	deriveTranscription(res, TranscriptionFormatVerboseJSON)
+	if res.Transcribe.BifrostTranscribeNonStreamResponse == nil || len(res.Transcribe.Segments) != 1 {
+		t.Error("Expected the verbose fields to be kept for verbose_json")
+	}
+}
@@ -14,6 +14,11 @@ import (
 )
 
 func (plugin *Plugin) performDirectSearch(ctx *context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType, cacheKey string) (*schemas.PluginShortCircuit, error) {
+	// Transcriptions are matched by audio content hash
+	if req.Input.TranscriptionInput != nil {
+		return plugin.performTranscriptionSearch(ctx, req, requestType, cacheKey)
+	}
+
 	// Generate hash for the request
 	hash, err := plugin.generateRequestHash(req, requestType)
 	if err != nil {
@@ -33,19 +38,7 @@ func (plugin *Plugin) performDirectSearch(ctx *context.Context, req *schemas.Bif
 	*ctx = context.WithValue(*ctx, requestParamsHashKey, paramsHash)
 
 	// Build strict filters for direct hash search
-	filters := []vectorstore.Query{
-		{Field: "request_hash", Operator: vectorstore.QueryOperatorEqual, Value: hash},
-		{Field: "cache_key", Operator: vectorstore.QueryOperatorEqual, Value: cacheKey},
-		{Field: "params_hash", Operator: vectorstore.QueryOperatorEqual, Value: paramsHash},
-		{Field: "from_bifrost_semantic_cache_plugin", Operator: vectorstore.QueryOperatorEqual, Value: true},
-	}
-
-	if plugin.config.CacheByProvider != nil && *plugin.config.CacheByProvider {
-		filters = append(filters, vectorstore.Query{Field: "provider", Operator: vectorstore.QueryOperatorEqual, Value: string(req.Provider)})
-	}
-	if plugin.config.CacheByModel != nil && *plugin.config.CacheByModel {
-		filters = append(filters, vectorstore.Query{Field: "model", Operator: vectorstore.QueryOperatorEqual, Value: req.Model})
-	}
+	filters := plugin.buildDirectFilters(req, hash, cacheKey, paramsHash)
 
 	plugin.logger.Debug(fmt.Sprintf("%s Searching for direct hash match with %d filters", PluginLoggerPrefix, len(filters)))
 
@@ -80,6 +73,24 @@ func (plugin *Plugin) performDirectSearch(ctx *context.Context, req *schemas.Bif
 	return plugin.buildResponseFromResult(ctx, req, result, CacheTypeDirect, 1.0, 0)
 }
 
+// buildDirectFilters builds the strict filters matching entries with the given request hash and params hash.
+func (plugin *Plugin) buildDirectFilters(req *schemas.BifrostRequest, hash string, cacheKey string, paramsHash string) []vectorstore.Query {
+	filters := []vectorstore.Query{
+		{Field: "request_hash", Operator: vectorstore.QueryOperatorEqual, Value: hash},
+		{Field: "cache_key", Operator: vectorstore.QueryOperatorEqual, Value: cacheKey},
+		{Field: "params_hash", Operator: vectorstore.QueryOperatorEqual, Value: paramsHash},
+		{Field: "from_bifrost_semantic_cache_plugin", Operator: vectorstore.QueryOperatorEqual, Value: true},
+	}
+
+	if plugin.config.CacheByProvider != nil && *plugin.config.CacheByProvider {
+		filters = append(filters, vectorstore.Query{Field: "provider", Operator: vectorstore.QueryOperatorEqual, Value: string(req.Provider)})
+	}
+	if plugin.config.CacheByModel != nil && *plugin.config.CacheByModel {
+		filters = append(filters, vectorstore.Query{Field: "model", Operator: vectorstore.QueryOperatorEqual, Value: req.Model})
+	}
+	return filters
+}
+
 // performSemanticSearch performs semantic similarity search and returns matching response if found.
 func (plugin *Plugin) performSemanticSearch(ctx *context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType, cacheKey string) (*schemas.PluginShortCircuit, error) {
 	// Extract text and metadata for embedding
@@ -0,0 +1,149 @@
+package semanticcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/vectorstore"
+)
+
+// Transcriptions are cached by a hash of the audio content instead of by semantic similarity.
+// The response format is kept out of the hash and stored as the params hash of the entry, so a
+// cached verbose_json result can also serve json and text requests for the same audio.
+
+// Transcription response formats
+const (
+	TranscriptionFormatJSON        = "json"
+	TranscriptionFormatText        = "text"
+	TranscriptionFormatVerboseJSON = "verbose_json"
+)
+
+// transcriptionSources lists the cached formats each response format can be served from, in lookup order.
+// Formats that are not listed (e.g. srt, vtt) are not cached.
+var transcriptionSources = map[string][]string{
+	TranscriptionFormatJSON:        {TranscriptionFormatJSON, TranscriptionFormatVerboseJSON},
+	TranscriptionFormatText:        {TranscriptionFormatText, TranscriptionFormatJSON, TranscriptionFormatVerboseJSON},
+	TranscriptionFormatVerboseJSON: {TranscriptionFormatVerboseJSON},
+}
+
+// performTranscriptionSearch looks up a cached transcription of the same audio, first in the requested
+// response format, then in the richer formats it can be derived from.
+func (plugin *Plugin) performTranscriptionSearch(ctx *context.Context, req *schemas.BifrostRequest, requestType schemas.RequestType, cacheKey string) (*schemas.PluginShortCircuit, error) {
+	input := req.Input.TranscriptionInput
+
+	if requestType != schemas.TranscriptionRequest {
+		plugin.logger.Debug(PluginLoggerPrefix + " Skipping caching for streaming transcription")
+		*ctx = context.WithValue(*ctx, skipStoreKey, true)
+		return nil, nil
+	}
+	if int64(len(input.File)) > plugin.config.TranscriptionMaxAudioSize {
+		plugin.logger.Debug(fmt.Sprintf("%s Skipping caching for transcription of %d bytes of audio, above the limit of %d", PluginLoggerPrefix, len(input.File), plugin.config.TranscriptionMaxAudioSize))
+		*ctx = context.WithValue(*ctx, skipStoreKey, true)
+		return nil, nil
+	}
+
+	format := getTranscriptionFormat(input)
+	sources, ok := transcriptionSources[format]
+	if !ok {
+		plugin.logger.Debug(PluginLoggerPrefix + " Skipping caching for transcription response format " + format)
+		*ctx = context.WithValue(*ctx, skipStoreKey, true)
+		return nil, nil
+	}
+
+	hash, err := plugin.generateTranscriptionHash(req)
+	if err != nil {
+		*ctx = context.WithValue(*ctx, skipStoreKey, true)
+		return nil, fmt.Errorf("failed to generate transcription hash: %w", err)
+	}
+
+	plugin.logger.Debug(PluginLoggerPrefix + " Generated Hash for Transcription: " + hash)
+
+	// The response is stored under the requested format
+	*ctx = context.WithValue(*ctx, requestHashKey, hash)
+	*ctx = context.WithValue(*ctx, requestParamsHashKey, format)
+
+	selectFields := removeField(append([]string(nil), SelectFields...), "stream_chunks")
+	for _, source := range sources {
+		filters := plugin.buildDirectFilters(req, hash, cacheKey, source)
+
+		results, _, err := plugin.store.GetAll(*ctx, plugin.config.VectorStoreNamespace, filters, selectFields, nil, 1)
+		if err != nil {
+			if errors.Is(err, vectorstore.ErrNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to search for transcription match: %w", err)
+		}
+		if len(results) == 0 {
+			continue
+		}
+
+		plugin.logger.Debug(fmt.Sprintf("%s Found %s transcription match with ID: %s", PluginLoggerPrefix, source, results[0].ID))
+
+		shortCircuit, err := plugin.buildResponseFromResult(ctx, req, results[0], CacheTypeDirect, 1.0, 0)
+		if err != nil {
+			return nil, err
+		}
+		if shortCircuit == nil {
+			// Expired, a richer format may still be cached
+			continue
+		}
+		if source != format {
+			deriveTranscription(shortCircuit.Response, format)
+		}
+		return shortCircuit, nil
+	}
+
+	plugin.logger.Debug(PluginLoggerPrefix + " No transcription match found")
+	return nil, nil
+}
+
+// generateTranscriptionHash hashes the audio content and every input and parameter that affects the
+// transcript, except the response format.
+func (plugin *Plugin) generateTranscriptionHash(req *schemas.BifrostRequest) (string, error) {
+	input := req.Input.TranscriptionInput
+	audioHash := sha256.Sum256(input.File)
+
+	hashInput := struct {
+		Audio    string                   `json:"audio"`
+		Language *string                  `json:"language,omitempty"`
+		Prompt   *string                  `json:"prompt,omitempty"`
+		Format   *string                  `json:"file_format,omitempty"`
+		Params   *schemas.ModelParameters `json:"params,omitempty"`
+	}{
+		Audio:    hex.EncodeToString(audioHash[:]),
+		Language: input.Language,
+		Prompt:   input.Prompt,
+		Format:   input.Format,
+		Params:   req.Params,
+	}
+
+	jsonData, err := json.Marshal(hashInput)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal transcription for hashing: %w", err)
+	}
+
+	return fmt.Sprintf("%x", xxhash.Sum64(jsonData)), nil
+}
+
+// getTranscriptionFormat returns the requested response format, json if unset.
+func getTranscriptionFormat(input *schemas.TranscriptionInput) string {
+	if input.ResponseFormat == nil || *input.ResponseFormat == "" {
+		return TranscriptionFormatJSON
+	}
+	return *input.ResponseFormat
+}
+
+// deriveTranscription reduces a cached verbose_json transcription to the fields of a json or text
+// response: the transcript, its usage and log probabilities.
+func deriveTranscription(res *schemas.BifrostResponse, format string) {
+	if res == nil || res.Transcribe == nil || format == TranscriptionFormatVerboseJSON {
+		return
+	}
+	res.Transcribe.BifrostTranscribeNonStreamResponse = nil
+}
@@ -312,6 +312,11 @@ func (plugin *Plugin) addSingleResponse(ctx context.Context, responseID string,
 		return fmt.Errorf("failed to marshal response: %w", err)
 	}
 
+	if res.Transcribe != nil && len(responseData) > plugin.config.TranscriptionMaxResponseSize {
+		plugin.logger.Debug(fmt.Sprintf("%s Skipping caching for transcription of %d bytes, above the limit of %d", PluginLoggerPrefix, len(responseData), plugin.config.TranscriptionMaxResponseSize))
+		return nil
+	}
+
 	// Add response field to metadata
 	metadata["response"] = string(responseData)
 	metadata["stream_chunks"] = []string{}
@@ -397,7 +402,7 @@ func (plugin *Plugin) getInputForCaching(req *schemas.BifrostRequest) *schemas.R
 
 		for _, msg := range originalMessages {
 			// Skip system messages if configured to exclude them
-			if plugin.config.ExcludeSystemPrompt != nil && *plugin.config.ExcludeSystemPrompt && msg.Role == schemas.ModelChatMessageRoleSystem {
+			if plugin.config.ExcludeSystemPrompt != nil && *plugin.config.ExcludeSystemPrompt && msg.Role.IsInstruction() {
 				continue
 			}
 
@@ -0,0 +1,405 @@
+// Package telemetry provides Prometheus metrics collection and monitoring functionality
+// for the Bifrost HTTP service. This file contains the anonymized analytics exporter, which
+// pushes per-request structural features (token counts, latency, tool usage, refusals) without
+// any prompt or completion content, optionally with Laplace noise for differential privacy.
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// DefaultAnalyticsExportInterval is how often analytics events are pushed when no interval is configured.
+	DefaultAnalyticsExportInterval = time.Minute
+	// DefaultAnalyticsBufferSize is the number of events kept between pushes. Events beyond it are dropped.
+	DefaultAnalyticsBufferSize = 10000
+	// DefaultAnalyticsTimeBucket is the resolution timestamps are truncated to.
+	DefaultAnalyticsTimeBucket = time.Minute
+
+	// Default sensitivities used to scale the Laplace noise of each feature.
+	defaultTokenSensitivity   = 10
+	defaultLatencySensitivity = 100 // Milliseconds
+	defaultCountSensitivity   = 1
+
+	analyticsStateKey ContextKey = "bf-prom-analytics-state"
+)
+
+// AnalyticsExporterConfig configures the anonymized analytics exporter. Durations are Go
+// duration strings such as "30s" or "5m".
+type AnalyticsExporterConfig struct {
+	URL        string                `json:"url"`                   // Endpoint receiving a POST with an AnalyticsBatch JSON body
+	Interval   string                `json:"interval,omitempty"`    // Push interval, DefaultAnalyticsExportInterval if empty
+	TimeBucket string                `json:"time_bucket,omitempty"` // Timestamp resolution, DefaultAnalyticsTimeBucket if empty
+	BufferSize int                   `json:"buffer_size,omitempty"` // Events kept between pushes, DefaultAnalyticsBufferSize if 0
+	Headers    map[string]string     `json:"headers,omitempty"`     // Extra request headers, e.g. authorization
+	Noise      *AnalyticsNoiseConfig `json:"noise,omitempty"`       // Optional Laplace noise added to numeric features
+}
+
+// AnalyticsNoiseConfig configures the Laplace mechanism applied to numeric features. The noise
+// scale of each feature is its sensitivity divided by epsilon, so smaller epsilons give stronger
+// privacy and noisier numbers.
+type AnalyticsNoiseConfig struct {
+	Epsilon            float64 `json:"epsilon"`                          // Privacy budget per feature, must be positive
+	TokenSensitivity   float64 `json:"token_sensitivity,omitempty"`      // Token count sensitivity, 10 if 0
+	LatencySensitivity float64 `json:"latency_sensitivity_ms,omitempty"` // Latency sensitivity in milliseconds, 100 if 0
+	CountSensitivity   float64 `json:"count_sensitivity,omitempty"`      // Message and tool count sensitivity, 1 if 0
+}
+
+// AnalyticsEvent holds the structural features of one request. It never carries prompt or
+// completion content, request IDs, keys or custom labels.
+type AnalyticsEvent struct {
+	Timestamp        time.Time             `json:"timestamp"` // Truncated to the configured time bucket
+	Provider         schemas.ModelProvider `json:"provider"`
+	Model            string                `json:"model"`
+	RequestType      schemas.RequestType   `json:"request_type"`
+	Success          bool                  `json:"success"`
+	ErrorClass       string                `json:"error_class,omitempty"`
+	LatencyMs        int64                 `json:"latency_ms"`
+	PromptTokens     int64                 `json:"prompt_tokens"`
+	CompletionTokens int64                 `json:"completion_tokens"`
+	TotalTokens      int64                 `json:"total_tokens"`
+	MessageCount     int64                 `json:"message_count"`
+	ToolsOffered     int64                 `json:"tools_offered"`
+	ToolCalls        int64                 `json:"tool_calls"`
+	FinishReason     string                `json:"finish_reason,omitempty"`
+	Refusal          bool                  `json:"refusal"` // Refusal message or content filter finish reason
+	CacheHit         bool                  `json:"cache_hit"`
+}
+
+// AnalyticsBatch is the body of each push.
+type AnalyticsBatch struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Noised      bool             `json:"noised"`
+	Dropped     int64            `json:"dropped,omitempty"` // Events dropped since the previous push because the buffer was full
+	Events      []AnalyticsEvent `json:"events"`
+}
+
+// analyticsState accumulates the features of one request across PreHook and every PostHook
+// call of a stream.
+type analyticsState struct {
+	mu           sync.Mutex
+	start        time.Time
+	messageCount int64
+	toolsOffered int64
+	event        AnalyticsEvent
+	done         bool
+}
+
+// AnalyticsExporter buffers anonymized analytics events and pushes them to an HTTP endpoint on an interval.
+type AnalyticsExporter struct {
+	url        string
+	interval   time.Duration
+	timeBucket time.Duration
+	bufferSize int
+	headers    map[string]string
+	noise      *AnalyticsNoiseConfig
+	client     *fasthttp.Client
+	logger     schemas.Logger
+
+	eventsMu sync.Mutex
+	events   []AnalyticsEvent
+	dropped  int64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+}
+
+// NewAnalyticsExporter creates an anonymized analytics exporter.
+func NewAnalyticsExporter(config AnalyticsExporterConfig, logger schemas.Logger) (*AnalyticsExporter, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("analytics export url is required")
+	}
+
+	interval := DefaultAnalyticsExportInterval
+	if config.Interval != "" {
+		parsed, err := time.ParseDuration(config.Interval)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid analytics export interval: %s", config.Interval)
+		}
+		interval = parsed
+	}
+
+	timeBucket := DefaultAnalyticsTimeBucket
+	if config.TimeBucket != "" {
+		parsed, err := time.ParseDuration(config.TimeBucket)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid analytics time bucket: %s", config.TimeBucket)
+		}
+		timeBucket = parsed
+	}
+
+	bufferSize := config.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultAnalyticsBufferSize
+	}
+
+	var noise *AnalyticsNoiseConfig
+	if config.Noise != nil {
+		if config.Noise.Epsilon <= 0 {
+			return nil, fmt.Errorf("analytics noise epsilon must be positive")
+		}
+		noise = &AnalyticsNoiseConfig{
+			Epsilon:            config.Noise.Epsilon,
+			TokenSensitivity:   positiveOr(config.Noise.TokenSensitivity, defaultTokenSensitivity),
+			LatencySensitivity: positiveOr(config.Noise.LatencySensitivity, defaultLatencySensitivity),
+			CountSensitivity:   positiveOr(config.Noise.CountSensitivity, defaultCountSensitivity),
+		}
+	}
+
+	return &AnalyticsExporter{
+		url:        config.URL,
+		interval:   interval,
+		timeBucket: timeBucket,
+		bufferSize: bufferSize,
+		headers:    config.Headers,
+		noise:      noise,
+		client:     &fasthttp.Client{ReadTimeout: statsExportTimeout, WriteTimeout: statsExportTimeout},
+		logger:     logger,
+	}, nil
+}
+
+// Start pushes buffered events every interval until Stop is called or ctx is done.
+// Calling Start on a running exporter is a no-op.
+func (e *AnalyticsExporter) Start(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cancel != nil {
+		return
+	}
+
+	ctx, e.cancel = context.WithCancel(ctx)
+	done := make(chan struct{})
+	e.done = done
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.Push(); err != nil {
+					e.logger.Warn("failed to push analytics events: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the exporter, waits for an in-flight push to finish and flushes the remaining events.
+func (e *AnalyticsExporter) Stop() {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel, e.done = nil, nil
+	e.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	if err := e.Push(); err != nil {
+		e.logger.Warn("failed to push analytics events: %v", err)
+	}
+}
+
+// Add anonymizes the event and buffers it for the next push.
+func (e *AnalyticsExporter) Add(event AnalyticsEvent) {
+	event.Timestamp = event.Timestamp.UTC().Truncate(e.timeBucket)
+	if e.noise != nil {
+		event.LatencyMs = addLaplaceNoise(event.LatencyMs, e.noise.LatencySensitivity/e.noise.Epsilon)
+		event.PromptTokens = addLaplaceNoise(event.PromptTokens, e.noise.TokenSensitivity/e.noise.Epsilon)
+		event.CompletionTokens = addLaplaceNoise(event.CompletionTokens, e.noise.TokenSensitivity/e.noise.Epsilon)
+		event.TotalTokens = event.PromptTokens + event.CompletionTokens
+		event.MessageCount = addLaplaceNoise(event.MessageCount, e.noise.CountSensitivity/e.noise.Epsilon)
+		event.ToolsOffered = addLaplaceNoise(event.ToolsOffered, e.noise.CountSensitivity/e.noise.Epsilon)
+		event.ToolCalls = addLaplaceNoise(event.ToolCalls, e.noise.CountSensitivity/e.noise.Epsilon)
+	}
+
+	e.eventsMu.Lock()
+	defer e.eventsMu.Unlock()
+	if len(e.events) >= e.bufferSize {
+		e.dropped++
+		return
+	}
+	e.events = append(e.events, event)
+}
+
+// Push sends the buffered events once. Nothing is sent when the buffer is empty.
+// Events are discarded after a failed push so that the buffer cannot grow without bound.
+func (e *AnalyticsExporter) Push() error {
+	e.eventsMu.Lock()
+	events, dropped := e.events, e.dropped
+	e.events, e.dropped = nil, 0
+	e.eventsMu.Unlock()
+
+	if len(events) == 0 && dropped == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(AnalyticsBatch{
+		GeneratedAt: time.Now().UTC(),
+		Noised:      e.noise != nil,
+		Dropped:     dropped,
+		Events:      events,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode analytics batch: %w", err)
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(e.url)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+	req.SetBody(body)
+
+	if err := e.client.DoTimeout(req, resp, statsExportTimeout); err != nil {
+		return err
+	}
+	if resp.StatusCode() >= 300 {
+		return fmt.Errorf("analytics endpoint returned status %d", resp.StatusCode())
+	}
+	return nil
+}
+
+// startAnalytics stores the request-side features in the context for recordAnalytics.
+func startAnalytics(ctx *context.Context, req *schemas.BifrostRequest) {
+	state := &analyticsState{start: time.Now()}
+	if req.Input.ChatCompletionInput != nil {
+		state.messageCount = int64(len(*req.Input.ChatCompletionInput))
+	} else if req.Input.TextCompletionInput != nil {
+		state.messageCount = 1
+	}
+	if req.Params != nil && req.Params.Tools != nil {
+		state.toolsOffered = int64(len(*req.Params.Tools))
+	}
+	*ctx = context.WithValue(*ctx, analyticsStateKey, state)
+}
+
+// recordAnalytics folds a response or error into the request's features and hands the event to
+// the exporter once the request is complete. Stream chunks are accumulated until the final chunk.
+func (p *PrometheusPlugin) recordAnalytics(ctx context.Context, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) {
+	if p.analyticsExporter == nil {
+		return
+	}
+	state, ok := ctx.Value(analyticsStateKey).(*analyticsState)
+	if !ok {
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.done {
+		return
+	}
+
+	requestType, _ := ctx.Value(schemas.BifrostContextKeyRequestType).(schemas.RequestType)
+	stream := bifrost.IsStreamRequestType(requestType)
+	if result != nil {
+		state.addResponse(result, stream)
+	}
+
+	if bifrostErr == nil && stream {
+		if isFinalChunk, ok := ctx.Value(schemas.BifrostContextKeyStreamEndIndicator).(bool); !ok || !isFinalChunk {
+			return
+		}
+	}
+	state.done = true
+
+	event := state.event
+	event.Timestamp = time.Now()
+	event.Provider, _ = ctx.Value(schemas.BifrostContextKeyRequestProvider).(schemas.ModelProvider)
+	event.Model, _ = ctx.Value(schemas.BifrostContextKeyRequestModel).(string)
+	event.RequestType = requestType
+	event.Success = bifrostErr == nil
+	if bifrostErr != nil {
+		event.ErrorClass = ClassifyError(bifrostErr)
+	}
+	event.LatencyMs = time.Since(state.start).Milliseconds()
+	event.MessageCount = state.messageCount
+	event.ToolsOffered = state.toolsOffered
+	p.analyticsExporter.Add(event)
+}
+
+// addResponse extracts the structural features of a response or stream chunk.
+func (s *analyticsState) addResponse(result *schemas.BifrostResponse, stream bool) {
+	if result.Usage != nil {
+		s.event.PromptTokens = int64(result.Usage.PromptTokens)
+		s.event.CompletionTokens = int64(result.Usage.CompletionTokens)
+		s.event.TotalTokens = int64(result.Usage.TotalTokens)
+	}
+	if result.ExtraFields.CacheDebug != nil && result.ExtraFields.CacheDebug.CacheHit {
+		s.event.CacheHit = true
+	}
+
+	for _, choice := range result.Choices {
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			s.event.FinishReason = *choice.FinishReason
+			if *choice.FinishReason == "content_filter" {
+				s.event.Refusal = true
+			}
+		}
+		if stream {
+			if choice.BifrostStreamResponseChoice == nil {
+				continue
+			}
+			if choice.Delta.Refusal != nil && *choice.Delta.Refusal != "" {
+				s.event.Refusal = true
+			}
+			for _, toolCall := range choice.Delta.ToolCalls {
+				// Only the first delta of a streamed tool call carries its ID
+				if toolCall.ID != nil && *toolCall.ID != "" {
+					s.event.ToolCalls++
+				}
+			}
+			continue
+		}
+		if choice.BifrostNonStreamResponseChoice == nil || choice.Message.AssistantMessage == nil {
+			continue
+		}
+		if choice.Message.Refusal != nil && *choice.Message.Refusal != "" {
+			s.event.Refusal = true
+		}
+		if choice.Message.ToolCalls != nil {
+			s.event.ToolCalls += int64(len(*choice.Message.ToolCalls))
+		}
+	}
+}
+
+// addLaplaceNoise adds Laplace(0, scale) noise to value, rounding to an integer and clamping at zero.
+func addLaplaceNoise(value int64, scale float64) int64 {
+	u := rand.Float64() - 0.5
+	noise := -scale * math.Copysign(1, u) * math.Log(1-2*math.Abs(u))
+	noised := math.Round(float64(value) + noise)
+	if noised < 0 || math.IsNaN(noised) || math.IsInf(noised, 0) {
+		return 0
+	}
+	return int64(noised)
+}
+
+func positiveOr(value, fallback float64) float64 {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,164 @@
+// Package telemetry provides Prometheus metrics collection and monitoring functionality
+// for the Bifrost HTTP service. This file contains the stats push exporter, which
+// periodically posts provider stats snapshots to an external endpoint.
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// DefaultStatsExportInterval is how often stats are pushed when no interval is configured.
+	DefaultStatsExportInterval = time.Minute
+
+	statsExportTimeout = 10 * time.Second
+)
+
+// Config is the telemetry plugin configuration from the plugins section of the config file.
+type Config struct {
+	StatsExport     *StatsExporterConfig     `json:"stats_export,omitempty"`     // Optional push exporter for provider stats
+	AnalyticsExport *AnalyticsExporterConfig `json:"analytics_export,omitempty"` // Optional push exporter for anonymized per-request analytics
+}
+
+// StatsExporterConfig configures the push exporter. Durations are Go duration strings
+// such as "30s" or "5m".
+type StatsExporterConfig struct {
+	URL      string            `json:"url"`                // Endpoint receiving a POST with a StatsSnapshot JSON body
+	Interval string            `json:"interval,omitempty"` // Push interval, DefaultStatsExportInterval if empty
+	Window   string            `json:"window,omitempty"`   // Time range covered by each push, the interval if empty
+	Headers  map[string]string `json:"headers,omitempty"`  // Extra request headers, e.g. authorization
+}
+
+// StatsExporter pushes provider stats snapshots to an HTTP endpoint on an interval.
+type StatsExporter struct {
+	collector *StatsCollector
+	url       string
+	interval  time.Duration
+	window    time.Duration
+	headers   map[string]string
+	client    *fasthttp.Client
+	logger    schemas.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+}
+
+// NewStatsExporter creates a push exporter for the collector's stats.
+func NewStatsExporter(collector *StatsCollector, config StatsExporterConfig, logger schemas.Logger) (*StatsExporter, error) {
+	if collector == nil {
+		return nil, fmt.Errorf("stats collector is required")
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("stats export url is required")
+	}
+
+	interval := DefaultStatsExportInterval
+	if config.Interval != "" {
+		parsed, err := time.ParseDuration(config.Interval)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid stats export interval: %s", config.Interval)
+		}
+		interval = parsed
+	}
+
+	window := interval
+	if config.Window != "" {
+		parsed, err := time.ParseDuration(config.Window)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid stats export window: %s", config.Window)
+		}
+		window = parsed
+	}
+
+	return &StatsExporter{
+		collector: collector,
+		url:       config.URL,
+		interval:  interval,
+		window:    window,
+		headers:   config.Headers,
+		client:    &fasthttp.Client{ReadTimeout: statsExportTimeout, WriteTimeout: statsExportTimeout},
+		logger:    logger,
+	}, nil
+}
+
+// Start pushes a snapshot every interval until Stop is called or ctx is done.
+// Calling Start on a running exporter is a no-op.
+func (e *StatsExporter) Start(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cancel != nil {
+		return
+	}
+
+	ctx, e.cancel = context.WithCancel(ctx)
+	done := make(chan struct{})
+	e.done = done
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.Push(); err != nil {
+					e.logger.Warn("failed to push provider stats: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the exporter and waits for an in-flight push to finish.
+func (e *StatsExporter) Stop() {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel, e.done = nil, nil
+	e.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Push sends a snapshot of the last window once.
+func (e *StatsExporter) Push() error {
+	snapshot := e.collector.Query(StatsQuery{From: time.Now().Add(-e.window)})
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode stats snapshot: %w", err)
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(e.url)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+	req.SetBody(body)
+
+	if err := e.client.DoTimeout(req, resp, statsExportTimeout); err != nil {
+		return err
+	}
+	if resp.StatusCode() >= 300 {
+		return fmt.Errorf("stats endpoint returned status %d", resp.StatusCode())
+	}
+	return nil
+}
@@ -32,6 +32,10 @@ const (
 //   - Error counts
 type PrometheusPlugin struct {
 	pricingManager *pricing.PricingManager
+	statsCollector *StatsCollector
+	statsExporter  *StatsExporter
+
+	analyticsExporter *AnalyticsExporter
 
 	// Metrics are defined using promauto for automatic registration
 	UpstreamRequestsTotal *prometheus.CounterVec
@@ -42,6 +46,8 @@ type PrometheusPlugin struct {
 	OutputTokensTotal     *prometheus.CounterVec
 	CacheHitsTotal        *prometheus.CounterVec
 	CostTotal             *prometheus.CounterVec
+
+	DeprecatedModelRequestsTotal *prometheus.CounterVec
 }
 
 // NewPrometheusPlugin creates a new PrometheusPlugin with initialized metrics.
@@ -52,6 +58,7 @@ func Init(pricingManager *pricing.PricingManager, logger schemas.Logger) *Promet
 
 	return &PrometheusPlugin{
 		pricingManager:        pricingManager,
+		statsCollector:        NewStatsCollector(StatsConfig{}),
 		UpstreamRequestsTotal: bifrostUpstreamRequestsTotal,
 		UpstreamLatency:       bifrostUpstreamLatencySeconds,
 		SuccessRequestsTotal:  bifrostSuccessRequestsTotal,
@@ -60,6 +67,8 @@ func Init(pricingManager *pricing.PricingManager, logger schemas.Logger) *Promet
 		OutputTokensTotal:     bifrostOutputTokensTotal,
 		CacheHitsTotal:        bifrostCacheHitsTotal,
 		CostTotal:             bifrostCostTotal,
+
+		DeprecatedModelRequestsTotal: bifrostDeprecatedModelRequestsTotal,
 	}
 }
 
@@ -68,10 +77,49 @@ func (p *PrometheusPlugin) GetName() string {
 	return PluginName
 }
 
+// GetStatsCollector returns the collector holding per-provider availability, error-class
+// and latency time series.
+func (p *PrometheusPlugin) GetStatsCollector() *StatsCollector {
+	return p.statsCollector
+}
+
+// EnableStatsExport starts pushing provider stats to the configured endpoint.
+// The exporter is stopped on Cleanup.
+func (p *PrometheusPlugin) EnableStatsExport(ctx context.Context, config StatsExporterConfig, logger schemas.Logger) error {
+	exporter, err := NewStatsExporter(p.statsCollector, config, logger)
+	if err != nil {
+		return err
+	}
+	if p.statsExporter != nil {
+		p.statsExporter.Stop()
+	}
+	p.statsExporter = exporter
+	exporter.Start(ctx)
+	return nil
+}
+
+// EnableAnalyticsExport starts pushing anonymized per-request analytics events to the
+// configured endpoint. The exporter is stopped and flushed on Cleanup.
+func (p *PrometheusPlugin) EnableAnalyticsExport(ctx context.Context, config AnalyticsExporterConfig, logger schemas.Logger) error {
+	exporter, err := NewAnalyticsExporter(config, logger)
+	if err != nil {
+		return err
+	}
+	if p.analyticsExporter != nil {
+		p.analyticsExporter.Stop()
+	}
+	p.analyticsExporter = exporter
+	exporter.Start(ctx)
+	return nil
+}
+
 // PreHook records the start time of the request in the context.
 // This time is used later in PostHook to calculate request duration.
 func (p *PrometheusPlugin) PreHook(ctx *context.Context, req *schemas.BifrostRequest) (*schemas.BifrostRequest, *schemas.PluginShortCircuit, error) {
 	*ctx = context.WithValue(*ctx, startTimeKey, time.Now())
+	if p.analyticsExporter != nil {
+		startAnalytics(ctx, req)
+	}
 
 	return req, nil, nil
 }
@@ -81,6 +129,9 @@ func (p *PrometheusPlugin) PreHook(ctx *context.Context, req *schemas.BifrostReq
 //   - Request latency
 //   - Total request count
 func (p *PrometheusPlugin) PostHook(ctx *context.Context, result *schemas.BifrostResponse, bifrostErr *schemas.BifrostError) (*schemas.BifrostResponse, *schemas.BifrostError, error) {
+	p.recordStats(*ctx, bifrostErr)
+	p.recordAnalytics(*ctx, result, bifrostErr)
+
 	if result == nil {
 		return result, bifrostErr, nil
 	}
@@ -167,7 +218,10 @@ func (p *PrometheusPlugin) PostHook(ctx *context.Context, result *schemas.Bifros
 
 		// Record error and success counts
 		if bifrostErr != nil {
-			p.ErrorRequestsTotal.WithLabelValues(promLabelValues...).Inc()
+			errorLabelValues := append(append([]string{}, promLabelValues[:3]...), string(bifrostErr.GetOrigin()))
+			errorLabelValues = append(errorLabelValues, promLabelValues[3:]...)
+
+			p.ErrorRequestsTotal.WithLabelValues(errorLabelValues...).Inc()
 		} else {
 			p.SuccessRequestsTotal.WithLabelValues(promLabelValues...).Inc()
 		}
@@ -178,6 +232,14 @@ func (p *PrometheusPlugin) PostHook(ctx *context.Context, result *schemas.Bifros
 			p.OutputTokensTotal.WithLabelValues(promLabelValues...).Add(float64(result.Usage.CompletionTokens))
 		}
 
+		// Record requests to deprecated models with their shutdown date
+		if result.ExtraFields.Deprecation != nil {
+			deprecationLabelValues := append(append([]string{}, promLabelValues[:3]...), result.ExtraFields.Deprecation.ShutdownDate)
+			deprecationLabelValues = append(deprecationLabelValues, promLabelValues[3:]...)
+
+			p.DeprecatedModelRequestsTotal.WithLabelValues(deprecationLabelValues...).Inc()
+		}
+
 		// Record cache hits with cache type
 		if result.ExtraFields.CacheDebug != nil && result.ExtraFields.CacheDebug.CacheHit {
 			cacheType := "unknown"
@@ -196,6 +258,39 @@ func (p *PrometheusPlugin) PostHook(ctx *context.Context, result *schemas.Bifros
 	return result, bifrostErr, nil
 }
 
+// recordStats adds the request outcome to the provider stats. Errors are recorded as soon
+// as they happen, successful streams only on their final chunk.
+func (p *PrometheusPlugin) recordStats(ctx context.Context, bifrostErr *schemas.BifrostError) {
+	if p.statsCollector == nil {
+		return
+	}
+	if bifrostErr == nil {
+		if requestType, ok := ctx.Value(schemas.BifrostContextKeyRequestType).(schemas.RequestType); ok && bifrost.IsStreamRequestType(requestType) {
+			if isFinalChunk, ok := ctx.Value(schemas.BifrostContextKeyStreamEndIndicator).(bool); !ok || !isFinalChunk {
+				return
+			}
+		}
+	}
+
+	startTime, ok := ctx.Value(startTimeKey).(time.Time)
+	if !ok {
+		return
+	}
+	provider, ok := ctx.Value(schemas.BifrostContextKeyRequestProvider).(schemas.ModelProvider)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	p.statsCollector.Record(provider, now, now.Sub(startTime), bifrostErr)
+}
+
 func (p *PrometheusPlugin) Cleanup() error {
+	if p.statsExporter != nil {
+		p.statsExporter.Stop()
+	}
+	if p.analyticsExporter != nil {
+		p.analyticsExporter.Stop()
+	}
 	return nil
 }
@@ -49,6 +49,9 @@ var (
 	// bifrostCostTotal tracks the total cost in USD for requests to upstream providers
 	bifrostCostTotal *prometheus.CounterVec
 
+	// bifrostDeprecatedModelRequestsTotal tracks requests to models that are retired or scheduled for retirement.
+	bifrostDeprecatedModelRequestsTotal *prometheus.CounterVec
+
 	// customLabels stores the expected label names in order
 	customLabels  []string
 	isInitialized bool
@@ -134,9 +137,9 @@ func InitPrometheusMetrics(labels []string) {
 	bifrostErrorRequestsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "bifrost_error_requests_total",
-			Help: "Total number of error requests forwarded to upstream providers by Bifrost.",
+			Help: "Total number of error requests forwarded to upstream providers by Bifrost, separated by error origin (client_request/provider/network/bifrost_internal/policy).",
 		},
-		append(bifrostDefaultLabels, labels...),
+		append(append(bifrostDefaultLabels, "origin"), labels...),
 	)
 
 	bifrostInputTokensTotal = promauto.NewCounterVec(
@@ -171,6 +174,14 @@ func InitPrometheusMetrics(labels []string) {
 		append(bifrostDefaultLabels, labels...),
 	)
 
+	bifrostDeprecatedModelRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bifrost_deprecated_model_requests_total",
+			Help: "Total number of requests to models that are retired or scheduled for retirement, separated by shutdown date.",
+		},
+		append(append(bifrostDefaultLabels, "shutdown_date"), labels...),
+	)
+
 	isInitialized = true
 }
 
@@ -0,0 +1,353 @@
+// Package telemetry provides Prometheus metrics collection and monitoring functionality
+// for the Bifrost HTTP service. This file contains the in-memory provider stats collector,
+// which keeps per-provider availability, error-class counts and latency percentiles as a
+// time series so an ops dashboard can query provider health without scraping logs.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+const (
+	// DefaultStatsBucketSize is the resolution of the stats time series.
+	DefaultStatsBucketSize = time.Minute
+	// DefaultStatsRetention is how long stats are kept in memory.
+	DefaultStatsRetention = 24 * time.Hour
+
+	// maxLatencySamples bounds the latency reservoir kept per provider and bucket.
+	maxLatencySamples = 512
+)
+
+// Error classes used to group provider errors.
+const (
+	ErrorClassRateLimited = "rate_limited"
+	ErrorClassAuth        = "auth"
+	ErrorClassTimeout     = "timeout"
+	ErrorClassServer      = "server_error"
+	ErrorClassClient      = "client_error"
+	ErrorClassNetwork     = "network"
+	ErrorClassCancelled   = "cancelled"
+	ErrorClassBifrost     = "bifrost"
+)
+
+// StatsConfig configures the provider stats collector.
+type StatsConfig struct {
+	BucketSize time.Duration // Resolution of the time series, DefaultStatsBucketSize if 0
+	Retention  time.Duration // How long buckets are kept, DefaultStatsRetention if 0
+}
+
+// StatsCollector aggregates provider request outcomes into fixed-size time buckets.
+// It is safe for concurrent use.
+type StatsCollector struct {
+	mu         sync.RWMutex
+	bucketSize time.Duration
+	retention  time.Duration
+	series     map[schemas.ModelProvider][]*statsBucket // Sorted by start time
+}
+
+// statsBucket holds the outcomes of one provider during one bucket.
+type statsBucket struct {
+	start     time.Time
+	requests  int64
+	successes int64
+	errors    map[string]int64
+	latencies []float64 // Reservoir sample of latencies in milliseconds
+}
+
+// StatsQuery selects the stats returned by Query. Zero values select everything retained
+// at the collector's bucket resolution.
+type StatsQuery struct {
+	Providers []schemas.ModelProvider
+	From      time.Time
+	To        time.Time
+	Step      time.Duration // Rounded up to a multiple of the bucket size
+}
+
+// StatsPoint is the aggregate of one provider over a time range. Availability and
+// latency percentiles are nil when the range has no requests.
+type StatsPoint struct {
+	Timestamp    time.Time        `json:"timestamp"`
+	Requests     int64            `json:"requests"`
+	Successes    int64            `json:"successes"`
+	Errors       int64            `json:"errors"`
+	Availability *float64         `json:"availability,omitempty"` // Successes / requests, between 0 and 1
+	ErrorClasses map[string]int64 `json:"error_classes,omitempty"`
+	LatencyP50   *float64         `json:"latency_p50_ms,omitempty"`
+	LatencyP90   *float64         `json:"latency_p90_ms,omitempty"`
+	LatencyP99   *float64         `json:"latency_p99_ms,omitempty"`
+}
+
+// ProviderStats is the time series and overall summary of one provider.
+type ProviderStats struct {
+	Provider schemas.ModelProvider `json:"provider"`
+	Summary  StatsPoint            `json:"summary"`
+	Series   []StatsPoint          `json:"series"`
+}
+
+// StatsSnapshot is the result of a stats query.
+type StatsSnapshot struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	Step        string          `json:"step"`
+	Providers   []ProviderStats `json:"providers"`
+}
+
+// NewStatsCollector creates an empty provider stats collector.
+func NewStatsCollector(config StatsConfig) *StatsCollector {
+	if config.BucketSize <= 0 {
+		config.BucketSize = DefaultStatsBucketSize
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultStatsRetention
+	}
+	if config.Retention < config.BucketSize {
+		config.Retention = config.BucketSize
+	}
+	return &StatsCollector{
+		bucketSize: config.BucketSize,
+		retention:  config.Retention,
+		series:     make(map[schemas.ModelProvider][]*statsBucket),
+	}
+}
+
+// BucketSize returns the resolution of the time series.
+func (c *StatsCollector) BucketSize() time.Duration {
+	return c.bucketSize
+}
+
+// Record adds the outcome of a provider request finished at the given time.
+// A nil bifrostErr records a success.
+func (c *StatsCollector) Record(provider schemas.ModelProvider, at time.Time, latency time.Duration, bifrostErr *schemas.BifrostError) {
+	if provider == "" {
+		return
+	}
+	start := at.Truncate(c.bucketSize)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bucket := c.bucket(provider, start)
+	bucket.requests++
+	if bifrostErr == nil {
+		bucket.successes++
+	} else {
+		bucket.errors[ClassifyError(bifrostErr)]++
+	}
+
+	ms := float64(latency) / float64(time.Millisecond)
+	if len(bucket.latencies) < maxLatencySamples {
+		bucket.latencies = append(bucket.latencies, ms)
+	} else if i := rand.Int63n(bucket.requests); i < maxLatencySamples {
+		bucket.latencies[i] = ms
+	}
+
+	c.prune(provider, at)
+}
+
+// bucket returns the provider's bucket starting at start, creating it if needed.
+// Callers must hold the write lock.
+func (c *StatsCollector) bucket(provider schemas.ModelProvider, start time.Time) *statsBucket {
+	buckets := c.series[provider]
+	i := sort.Search(len(buckets), func(i int) bool { return !buckets[i].start.Before(start) })
+	if i < len(buckets) && buckets[i].start.Equal(start) {
+		return buckets[i]
+	}
+
+	bucket := &statsBucket{start: start, errors: make(map[string]int64)}
+	c.series[provider] = slices.Insert(buckets, i, bucket)
+	return bucket
+}
+
+// prune drops the provider's buckets that fell out of the retention window.
+// Callers must hold the write lock.
+func (c *StatsCollector) prune(provider schemas.ModelProvider, now time.Time) {
+	cutoff := now.Add(-c.retention)
+	buckets := c.series[provider]
+	i := 0
+	for i < len(buckets) && buckets[i].start.Add(c.bucketSize).Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		c.series[provider] = slices.Delete(buckets, 0, i)
+	}
+}
+
+// Query returns the per-provider time series and summaries selected by query.
+// Providers without requests in the range are omitted.
+func (c *StatsCollector) Query(query StatsQuery) StatsSnapshot {
+	now := time.Now()
+	if query.To.IsZero() || query.To.After(now) {
+		query.To = now
+	}
+	if query.From.IsZero() || query.From.Before(now.Add(-c.retention)) {
+		query.From = now.Add(-c.retention)
+	}
+	step := c.bucketSize
+	if query.Step > step {
+		step = ((query.Step + c.bucketSize - 1) / c.bucketSize) * c.bucketSize
+	}
+
+	snapshot := StatsSnapshot{
+		GeneratedAt: now,
+		From:        query.From,
+		To:          query.To,
+		Step:        step.String(),
+		Providers:   []ProviderStats{},
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	providers := query.Providers
+	if len(providers) == 0 {
+		for provider := range c.series {
+			providers = append(providers, provider)
+		}
+		slices.Sort(providers)
+	}
+
+	for _, provider := range providers {
+		var inRange []*statsBucket
+		for _, bucket := range c.series[provider] {
+			if bucket.start.Add(c.bucketSize).After(query.From) && bucket.start.Before(query.To) {
+				inRange = append(inRange, bucket)
+			}
+		}
+		if len(inRange) == 0 {
+			continue
+		}
+
+		stats := ProviderStats{
+			Provider: provider,
+			Summary:  aggregateBuckets(query.From, inRange),
+		}
+		for len(inRange) > 0 {
+			stepStart := inRange[0].start.Truncate(step)
+			n := 1
+			for n < len(inRange) && inRange[n].start.Before(stepStart.Add(step)) {
+				n++
+			}
+			stats.Series = append(stats.Series, aggregateBuckets(stepStart, inRange[:n]))
+			inRange = inRange[n:]
+		}
+		snapshot.Providers = append(snapshot.Providers, stats)
+	}
+
+	return snapshot
+}
+
+// aggregateBuckets merges buckets into a single point. Latency samples are weighted by
+// the number of requests they stand for, so busy buckets are not under-represented.
+func aggregateBuckets(timestamp time.Time, buckets []*statsBucket) StatsPoint {
+	point := StatsPoint{Timestamp: timestamp}
+	var samples []weightedSample
+
+	for _, bucket := range buckets {
+		point.Requests += bucket.requests
+		point.Successes += bucket.successes
+		for class, count := range bucket.errors {
+			if point.ErrorClasses == nil {
+				point.ErrorClasses = make(map[string]int64)
+			}
+			point.ErrorClasses[class] += count
+			point.Errors += count
+		}
+		if len(bucket.latencies) > 0 {
+			weight := float64(bucket.requests) / float64(len(bucket.latencies))
+			for _, latency := range bucket.latencies {
+				samples = append(samples, weightedSample{value: latency, weight: weight})
+			}
+		}
+	}
+
+	if point.Requests > 0 {
+		availability := float64(point.Successes) / float64(point.Requests)
+		point.Availability = &availability
+	}
+	if len(samples) > 0 {
+		sort.Slice(samples, func(i, j int) bool { return samples[i].value < samples[j].value })
+		point.LatencyP50 = bifrost.Ptr(percentile(samples, 0.50))
+		point.LatencyP90 = bifrost.Ptr(percentile(samples, 0.90))
+		point.LatencyP99 = bifrost.Ptr(percentile(samples, 0.99))
+	}
+	return point
+}
+
+type weightedSample struct {
+	value  float64
+	weight float64
+}
+
+// percentile returns the weighted p-th percentile of samples sorted by value.
+func percentile(samples []weightedSample, p float64) float64 {
+	var total float64
+	for _, sample := range samples {
+		total += sample.weight
+	}
+
+	target := p * total
+	var cumulative float64
+	for _, sample := range samples {
+		cumulative += sample.weight
+		if cumulative >= target {
+			return sample.value
+		}
+	}
+	return samples[len(samples)-1].value
+}
+
+// ClassifyError groups a Bifrost error into one of the ErrorClass* values.
+func ClassifyError(bifrostErr *schemas.BifrostError) string {
+	if bifrostErr.Error.Type != nil && *bifrostErr.Error.Type == schemas.RequestCancelled {
+		return ErrorClassCancelled
+	}
+	if bifrostErr.StatusCode != nil {
+		switch status := *bifrostErr.StatusCode; {
+		case status == 429:
+			return ErrorClassRateLimited
+		case status == 401 || status == 403:
+			return ErrorClassAuth
+		case status == 408 || status == 504:
+			return ErrorClassTimeout
+		case status >= 500:
+			return ErrorClassServer
+		case status >= 400:
+			return ErrorClassClient
+		}
+	}
+
+	if err := bifrostErr.Error.Error; err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+			return ErrorClassTimeout
+		case errors.Is(err, context.Canceled):
+			return ErrorClassCancelled
+		case netErr != nil:
+			return ErrorClassNetwork
+		}
+	}
+	message := strings.ToLower(bifrostErr.Error.Message)
+	switch {
+	case strings.Contains(message, "timeout") || strings.Contains(message, "timed out") || strings.Contains(message, "deadline exceeded"):
+		return ErrorClassTimeout
+	case strings.Contains(message, "rate limit"):
+		return ErrorClassRateLimited
+	case bifrostErr.IsBifrostError:
+		return ErrorClassBifrost
+	default:
+		return ErrorClassNetwork
+	}
+}
@@ -0,0 +1,222 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestClassifyError(t *testing.T) {
+	withStatus := func(status int) *schemas.BifrostError {
+		return &schemas.BifrostError{StatusCode: &status, Error: schemas.ErrorField{Message: "failed"}}
+	}
+	withErr := func(err error) *schemas.BifrostError {
+		return &schemas.BifrostError{Error: schemas.ErrorField{Message: "request failed", Error: err}}
+	}
+	withMessage := func(message string, isBifrostError bool) *schemas.BifrostError {
+		return &schemas.BifrostError{IsBifrostError: isBifrostError, Error: schemas.ErrorField{Message: message}}
+	}
+
+	tests := []struct {
+		name       string
+		bifrostErr *schemas.BifrostError
+		want       string
+	}{
+		{"cancelled type", &schemas.BifrostError{StatusCode: bifrost.Ptr(500), Error: schemas.ErrorField{Type: bifrost.Ptr(schemas.RequestCancelled)}}, ErrorClassCancelled},
+		{"429", withStatus(429), ErrorClassRateLimited},
+		{"401", withStatus(401), ErrorClassAuth},
+		{"403", withStatus(403), ErrorClassAuth},
+		{"408", withStatus(408), ErrorClassTimeout},
+		{"504", withStatus(504), ErrorClassTimeout},
+		{"502", withStatus(502), ErrorClassServer},
+		{"404", withStatus(404), ErrorClassClient},
+		{"deadline exceeded", withErr(context.DeadlineExceeded), ErrorClassTimeout},
+		{"net timeout", withErr(&net.DNSError{IsTimeout: true}), ErrorClassTimeout},
+		{"context cancelled", withErr(context.Canceled), ErrorClassCancelled},
+		{"net error", withErr(&net.OpError{Op: "dial", Err: errors.New("connection refused")}), ErrorClassNetwork},
+		{"timeout message", withMessage("upstream timed out", false), ErrorClassTimeout},
+		{"rate limit message", withMessage("Rate limit exceeded", false), ErrorClassRateLimited},
+		{"bifrost error", withMessage("no keys found for provider", true), ErrorClassBifrost},
+		{"unknown", withMessage("connection reset", false), ErrorClassNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.bifrostErr); got != tt.want {
+				t.Errorf("ClassifyError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	even := []weightedSample{{10, 1}, {20, 1}, {30, 1}, {40, 1}}
+	// The single slow sample stands for as many requests as the three fast ones
+	skewed := []weightedSample{{10, 1}, {20, 1}, {30, 1}, {100, 3}}
+
+	tests := []struct {
+		name    string
+		samples []weightedSample
+		p       float64
+		want    float64
+	}{
+		{"p50 even", even, 0.50, 20},
+		{"p90 even", even, 0.90, 40},
+		{"p0 even", even, 0, 10},
+		{"p50 skewed", skewed, 0.50, 30},
+		{"p60 skewed", skewed, 0.60, 100},
+		{"single sample", []weightedSample{{7, 2}}, 0.99, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.samples, tt.p); got != tt.want {
+				t.Errorf("percentile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsCollectorQuery(t *testing.T) {
+	collector := NewStatsCollector(StatsConfig{BucketSize: time.Minute, Retention: 24 * time.Hour})
+	now := time.Now()
+	// Aligned to the 90 minute step below, so the older requests share a step
+	base := now.Add(-3 * time.Hour).Truncate(90 * time.Minute)
+	rateLimited := &schemas.BifrostError{StatusCode: bifrost.Ptr(429)}
+
+	collector.Record(schemas.OpenAI, now.Add(-25*time.Hour), time.Second, nil) // Pruned by the next record
+	collector.Record(schemas.OpenAI, base.Add(time.Minute), 100*time.Millisecond, nil)
+	collector.Record(schemas.OpenAI, base.Add(2*time.Minute), 200*time.Millisecond, rateLimited)
+	collector.Record(schemas.OpenAI, now.Add(-time.Minute), 300*time.Millisecond, nil)
+	collector.Record(schemas.Anthropic, now.Add(-time.Minute), 50*time.Millisecond, nil)
+	collector.Record("", now, time.Second, nil) // Ignored
+
+	if buckets := len(collector.series[schemas.OpenAI]); buckets != 3 {
+		t.Errorf("openai has %d buckets, want 3 after pruning", buckets)
+	}
+
+	tests := []struct {
+		name          string
+		query         StatsQuery
+		wantProviders []schemas.ModelProvider
+		wantRequests  int64 // Summary requests of the first provider
+		wantSeries    int   // Points of the first provider
+		wantStep      string
+	}{
+		{
+			name:          "everything",
+			wantProviders: []schemas.ModelProvider{schemas.Anthropic, schemas.OpenAI},
+			wantRequests:  1,
+			wantSeries:    1,
+			wantStep:      "1m0s",
+		},
+		{
+			name:          "one provider",
+			query:         StatsQuery{Providers: []schemas.ModelProvider{schemas.OpenAI}},
+			wantProviders: []schemas.ModelProvider{schemas.OpenAI},
+			wantRequests:  3,
+			wantSeries:    3,
+			wantStep:      "1m0s",
+		},
+		{
+			name:          "step rounded up to the bucket size",
+			query:         StatsQuery{Providers: []schemas.ModelProvider{schemas.OpenAI}, Step: 90 * time.Minute},
+			wantProviders: []schemas.ModelProvider{schemas.OpenAI},
+			wantRequests:  3,
+			wantSeries:    2,
+			wantStep:      "1h30m0s",
+		},
+		{
+			name:          "time range",
+			query:         StatsQuery{From: now.Add(-5 * time.Minute)},
+			wantProviders: []schemas.ModelProvider{schemas.Anthropic, schemas.OpenAI},
+			wantRequests:  1,
+			wantSeries:    1,
+			wantStep:      "1m0s",
+		},
+		{
+			name:  "no requests in range",
+			query: StatsQuery{Providers: []schemas.ModelProvider{schemas.Gemini}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := collector.Query(tt.query)
+			var providers []schemas.ModelProvider
+			for _, stats := range snapshot.Providers {
+				providers = append(providers, stats.Provider)
+			}
+			if len(providers) != len(tt.wantProviders) {
+				t.Fatalf("providers = %v, want %v", providers, tt.wantProviders)
+			}
+			for i := range providers {
+				if providers[i] != tt.wantProviders[i] {
+					t.Fatalf("providers = %v, want %v", providers, tt.wantProviders)
+				}
+			}
+			if len(providers) == 0 {
+				return
+			}
+			if tt.wantStep != "" && snapshot.Step != tt.wantStep {
+				t.Errorf("step = %s, want %s", snapshot.Step, tt.wantStep)
+			}
+			stats := snapshot.Providers[0]
+			if stats.Summary.Requests != tt.wantRequests {
+				t.Errorf("summary requests = %d, want %d", stats.Summary.Requests, tt.wantRequests)
+			}
+			if len(stats.Series) != tt.wantSeries {
+				t.Errorf("series has %d points, want %d", len(stats.Series), tt.wantSeries)
+			}
+		})
+	}
+
+	summary := collector.Query(StatsQuery{Providers: []schemas.ModelProvider{schemas.OpenAI}}).Providers[0].Summary
+	if summary.Successes != 2 || summary.Errors != 1 || summary.ErrorClasses[ErrorClassRateLimited] != 1 {
+		t.Errorf("summary = %+v, want 2 successes and 1 rate limited error", summary)
+	}
+	if summary.Availability == nil || *summary.Availability != 2.0/3 {
+		t.Errorf("availability = %v, want 2/3", summary.Availability)
+	}
+	if summary.LatencyP50 == nil || *summary.LatencyP50 != 200 || *summary.LatencyP99 != 300 {
+		t.Errorf("latency p50 = %v, p99 = %v, want 200 and 300", summary.LatencyP50, summary.LatencyP99)
+	}
+}
+
+func TestNewStatsExporter(t *testing.T) {
+	collector := NewStatsCollector(StatsConfig{})
+	logger := bifrost.NewDefaultLogger(schemas.LogLevelError)
+
+	tests := []struct {
+		name         string
+		collector    *StatsCollector
+		config       StatsExporterConfig
+		wantErr      bool
+		wantInterval time.Duration
+		wantWindow   time.Duration
+	}{
+		{name: "defaults", collector: collector, config: StatsExporterConfig{URL: "http://stats.local"}, wantInterval: DefaultStatsExportInterval, wantWindow: DefaultStatsExportInterval},
+		{name: "window follows the interval", collector: collector, config: StatsExporterConfig{URL: "http://stats.local", Interval: "30s"}, wantInterval: 30 * time.Second, wantWindow: 30 * time.Second},
+		{name: "explicit window", collector: collector, config: StatsExporterConfig{URL: "http://stats.local", Interval: "30s", Window: "5m"}, wantInterval: 30 * time.Second, wantWindow: 5 * time.Minute},
+		{name: "no collector", config: StatsExporterConfig{URL: "http://stats.local"}, wantErr: true},
+		{name: "no url", collector: collector, wantErr: true},
+		{name: "invalid interval", collector: collector, config: StatsExporterConfig{URL: "http://stats.local", Interval: "soon"}, wantErr: true},
+		{name: "negative window", collector: collector, config: StatsExporterConfig{URL: "http://stats.local", Window: "-1m"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter, err := NewStatsExporter(tt.collector, tt.config, logger)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStatsExporter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if exporter.interval != tt.wantInterval || exporter.window != tt.wantWindow {
+				t.Errorf("interval = %v, window = %v, want %v and %v", exporter.interval, exporter.window, tt.wantInterval, tt.wantWindow)
+			}
+		})
+	}
+}
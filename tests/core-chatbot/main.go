@@ -35,6 +35,10 @@ type ChatSession struct {
 	config       ChatbotConfig
 	systemPrompt string
 	account      *ComprehensiveTestAccount
+	lineage      map[int]*schemas.ModelLineage // History index -> provider and model that produced the message
+	usage        map[int]*schemas.LLMUsage     // History index -> usage of the request that produced the message
+	createdAt    time.Time                     // Start of the conversation
+	sessionFile  string                        // File the session is auto-saved to after each turn, empty to disable
 }
 
 // ComprehensiveTestAccount provides a test implementation of the Account interface for comprehensive testing.
@@ -280,10 +284,11 @@ func NewChatSession(config ChatbotConfig) (*ChatSession, error) {
 	}
 
 	session := &ChatSession{
-		history: make([]schemas.BifrostMessage, 0),
-		client:  client,
-		config:  config,
-		account: account,
+		history:   make([]schemas.BifrostMessage, 0),
+		client:    client,
+		config:    config,
+		account:   account,
+		createdAt: time.Now(),
 		systemPrompt: "You are a helpful AI assistant with access to various tools. " +
 			"Use the available tools when they can help answer the user's questions more accurately or provide additional information.",
 	}
@@ -516,6 +521,7 @@ func (s *ChatSession) SendMessage(message string) (string, error) {
 
 	// Add assistant message to history
 	s.history = append(s.history, assistantMessage)
+	s.recordResponse(response)
 
 	// Check if assistant wants to use tools
 	if assistantMessage.ToolCalls != nil && len(*assistantMessage.ToolCalls) > 0 {
@@ -685,6 +691,7 @@ func (s *ChatSession) synthesizeToolResults() (string, error) {
 
 	// Add synthesized response to history (replace the temporary synthesis prompt effect)
 	s.history = append(s.history, synthesizedMessage)
+	s.recordResponse(synthesisResponse)
 
 	// Extract text content
 	var responseText string
@@ -713,23 +720,19 @@ func (s *ChatSession) PrintHistory() {
 			continue // Skip system messages in history display
 		}
 
-		var content string
-		if msg.Content.ContentStr != nil {
-			content = *msg.Content.ContentStr
-		} else if msg.Content.ContentBlocks != nil {
-			var textParts []string
-			for _, block := range *msg.Content.ContentBlocks {
-				if block.Text != nil {
-					textParts = append(textParts, *block.Text)
-				}
-			}
-			content = strings.Join(textParts, "\n")
-		}
+		content := msg.Content.Text()
 
 		role := cases.Title(language.English).String(string(msg.Role))
+		if responder, ok := s.responder(i); ok {
+			role = fmt.Sprintf("%s (%s)", role, responder)
+		}
 		timestamp := fmt.Sprintf("[%d]", i)
 
-		fmt.Printf("%s %s: %s\n\n", timestamp, role, content)
+		fmt.Printf("%s %s: %s\n", timestamp, role, content)
+		for _, line := range toolCallLines(msg) {
+			fmt.Printf("    🔧 %s\n", line)
+		}
+		fmt.Println()
 	}
 }
 
@@ -756,6 +759,9 @@ func printWelcome(config ChatbotConfig) {
 	fmt.Println("  /config    - Show current configuration")
 	fmt.Println("  /provider  - Switch provider")
 	fmt.Println("  /model     - Switch model")
+	fmt.Println("  /save      - Save the session")
+	fmt.Println("  /load      - Load a saved session")
+	fmt.Println("  /export    - Export the transcript")
 	fmt.Println("  /quit      - Exit the chatbot")
 	fmt.Println()
 	fmt.Println("Type your message and press Enter to chat!")
@@ -772,10 +778,18 @@ func printHelp() {
 	fmt.Println("  /history   - Show conversation history")
 	fmt.Println("  /clear     - Clear conversation history (keeps system prompt)")
 	fmt.Println("  /config    - Show current provider, model, and settings")
-	fmt.Println("  /provider  - Switch between different AI providers")
-	fmt.Println("  /model     - Switch between models for current provider")
+	fmt.Println("  /provider  - Switch between different AI providers (/provider <name> [model] to switch directly)")
+	fmt.Println("  /model     - Switch between models for current provider (/model <name> to switch directly)")
+	fmt.Println("  /save      - Save the session (/save [path], defaults to the session file)")
+	fmt.Println("  /load      - Load a saved session (/load [path])")
+	fmt.Println("  /export    - Export the transcript as markdown, or JSON for .json paths (/export <path>)")
 	fmt.Println("  /quit      - Exit the chatbot")
 	fmt.Println()
+	fmt.Println("Sessions:")
+	fmt.Println("• The conversation is saved after every turn to BIFROST_CHAT_SESSION_FILE (default " + defaultSessionFile + ")")
+	fmt.Println("• Switching provider or model keeps the conversation, so models can be compared mid-conversation")
+	fmt.Println("• /history and transcripts show which provider/model produced each reply and the tool calls it made")
+	fmt.Println()
 	fmt.Println("Supported providers:")
 	fmt.Println("• OpenAI (gpt-4o-mini, gpt-4-turbo, gpt-4o)")
 	fmt.Println("• Anthropic (claude models)")
@@ -865,6 +879,7 @@ func main() {
 		fmt.Printf("❌ Failed to create chat session: %v\n", err)
 		os.Exit(1)
 	}
+	session.sessionFile = getEnvWithDefault("BIFROST_CHAT_SESSION_FILE", defaultSessionFile)
 
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -883,8 +898,23 @@ func main() {
 	// Print welcome message
 	printWelcome(config)
 
-	// Main chat loop
 	scanner := bufio.NewScanner(os.Stdin)
+
+	// Offer to resume the previous session
+	if _, err := os.Stat(session.sessionFile); err == nil {
+		fmt.Printf("\n💾 Found a saved session in %s. Resume it? (y/n): ", session.sessionFile)
+		if scanner.Scan() {
+			if answer := strings.ToLower(strings.TrimSpace(scanner.Text())); answer == "y" || answer == "yes" {
+				if err := session.Load(""); err != nil {
+					fmt.Printf("❌ Failed to resume session: %v\n", err)
+				} else {
+					fmt.Printf("✅ Resumed session with %s/%s\n", session.config.Provider, session.config.Model)
+				}
+			}
+		}
+	}
+
+	// Main chat loop
 	for {
 		fmt.Print("\n💬 You: ")
 		if !scanner.Scan() {
@@ -896,8 +926,10 @@ func main() {
 			continue
 		}
 
-		// Handle commands
-		switch input {
+		// Handle commands, which may take arguments
+		command, args, _ := strings.Cut(input, " ")
+		args = strings.TrimSpace(args)
+		switch command {
 		case "/help":
 			printHelp()
 			continue
@@ -908,21 +940,57 @@ func main() {
 			// Keep system prompt but clear conversation history
 			systemPrompt := session.history[0] // Assuming first message is system
 			session.history = []schemas.BifrostMessage{systemPrompt}
+			session.resetConversation()
+			session.autoSave()
 			fmt.Println("🧹 Conversation history cleared!")
 			continue
 		case "/config":
 			session.showCurrentConfig()
 			continue
 		case "/provider":
-			if err := session.switchProvider(); err != nil {
+			if args != "" {
+				providerName, model, _ := strings.Cut(args, " ")
+				err = session.setProvider(schemas.ModelProvider(providerName), strings.TrimSpace(model))
+			} else {
+				err = session.switchProvider()
+			}
+			if err != nil {
 				fmt.Printf("❌ Error switching provider: %v\n", err)
 			}
 			continue
 		case "/model":
-			if err := session.switchModel(); err != nil {
+			if args != "" {
+				err = session.setModel(args)
+			} else {
+				err = session.switchModel()
+			}
+			if err != nil {
 				fmt.Printf("❌ Error switching model: %v\n", err)
 			}
 			continue
+		case "/save":
+			if err := session.Save(args); err != nil {
+				fmt.Printf("❌ Error saving session: %v\n", err)
+			} else {
+				fmt.Println("💾 Session saved!")
+			}
+			continue
+		case "/load":
+			if err := session.Load(args); err != nil {
+				fmt.Printf("❌ Error loading session: %v\n", err)
+			} else {
+				fmt.Printf("✅ Session loaded with %s/%s\n", session.config.Provider, session.config.Model)
+			}
+			continue
+		case "/export":
+			if args == "" {
+				fmt.Println("❌ Usage: /export <path>")
+			} else if err := session.ExportTranscript(args); err != nil {
+				fmt.Printf("❌ Error exporting transcript: %v\n", err)
+			} else {
+				fmt.Printf("📄 Transcript exported to %s\n", args)
+			}
+			continue
 		case "/quit":
 			fmt.Println("👋 Goodbye!")
 			session.Cleanup()
@@ -937,6 +1005,7 @@ func main() {
 		}
 
 		fmt.Printf("🤖 Assistant: %s\n", response)
+		session.autoSave()
 	}
 
 	// Cleanup
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// defaultSessionFile is where the session is persisted when no path is given
+const defaultSessionFile = "bifrost-chat-session.json"
+
+// legacySessionFile is the form sessions were saved in before the canonical conversation format,
+// still read by Load
+type legacySessionFile struct {
+	Provider   schemas.ModelProvider    `json:"provider"`
+	Model      string                   `json:"model"`
+	History    []schemas.BifrostMessage `json:"history"`
+	Responders map[int]string           `json:"responders,omitempty"` // History index -> "provider/model" that produced the message
+}
+
+// recordResponse remembers the provider, model and usage of the response that produced the last
+// message in history
+func (s *ChatSession) recordResponse(response *schemas.BifrostResponse) {
+	if s.lineage == nil {
+		s.lineage = make(map[int]*schemas.ModelLineage)
+		s.usage = make(map[int]*schemas.LLMUsage)
+	}
+	index := len(s.history) - 1
+	lineage := &schemas.ModelLineage{Provider: s.config.Provider, Model: s.config.Model}
+	if response != nil {
+		if response.ExtraFields.Provider != "" {
+			lineage.Provider = response.ExtraFields.Provider
+		}
+		if response.Model != "" {
+			lineage.Model = response.Model
+		}
+		lineage.UpstreamProvider = response.ExtraFields.UpstreamProvider
+		lineage.ResponseID = response.ID
+		if response.Usage != nil {
+			s.usage[index] = response.Usage
+		}
+	}
+	s.lineage[index] = lineage
+}
+
+// responder returns "provider/model" for the message at index in history, if a model produced it
+func (s *ChatSession) responder(index int) (string, bool) {
+	lineage, ok := s.lineage[index]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s/%s", lineage.Provider, lineage.Model), true
+}
+
+// resetConversation clears what is recorded about the messages of the conversation
+func (s *ChatSession) resetConversation() {
+	s.lineage = nil
+	s.usage = nil
+	s.createdAt = time.Now()
+}
+
+// Conversation returns the session in the canonical conversation format
+func (s *ChatSession) Conversation() *schemas.Conversation {
+	conversation := schemas.NewConversation()
+	conversation.Provider = s.config.Provider
+	conversation.Model = s.config.Model
+	if !s.createdAt.IsZero() {
+		conversation.CreatedAt = s.createdAt
+	}
+	for i, msg := range s.history {
+		added := conversation.Append(msg, s.lineage[i], s.usage[i])
+		added.CreatedAt = nil
+	}
+	return conversation
+}
+
+// ImportConversation replaces the session's conversation, provider and model with the ones of a
+// conversation in the canonical format
+func (s *ChatSession) ImportConversation(conversation *schemas.Conversation) error {
+	if len(conversation.Messages) == 0 {
+		return fmt.Errorf("conversation has no messages")
+	}
+
+	s.history = conversation.History()
+	s.lineage = make(map[int]*schemas.ModelLineage)
+	s.usage = make(map[int]*schemas.LLMUsage)
+	for i, msg := range conversation.Messages {
+		if msg.Lineage != nil {
+			s.lineage[i] = msg.Lineage
+		}
+		if msg.Usage != nil {
+			s.usage[i] = msg.Usage
+		}
+	}
+	s.createdAt = conversation.CreatedAt
+	if conversation.Provider != "" && conversation.Model != "" {
+		s.config.Provider = conversation.Provider
+		s.config.Model = conversation.Model
+	}
+	return nil
+}
+
+// Save writes the session to path in the canonical conversation format, defaulting to the
+// session's file
+func (s *ChatSession) Save(path string) error {
+	if path == "" {
+		path = s.sessionFile
+	}
+
+	data, err := s.Conversation().Export()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	return nil
+}
+
+// Load replaces the session's conversation, provider and model with the ones saved at path, in
+// the canonical conversation format or the legacy session format
+func (s *ChatSession) Load(path string) error {
+	if path == "" {
+		path = s.sessionFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return fmt.Errorf("failed to decode session file: %w", err)
+	}
+	if header.Version == 0 {
+		return s.loadLegacy(path, data)
+	}
+
+	conversation, err := schemas.ParseConversation(data)
+	if err != nil {
+		return fmt.Errorf("failed to import session file: %w", err)
+	}
+	if err := s.ImportConversation(conversation); err != nil {
+		return fmt.Errorf("session file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadLegacy loads a session saved before the canonical conversation format
+func (s *ChatSession) loadLegacy(path string, data []byte) error {
+	var saved legacySessionFile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to decode session file: %w", err)
+	}
+	if len(saved.History) == 0 {
+		return fmt.Errorf("session file %s has no history", path)
+	}
+
+	conversation := schemas.NewConversation()
+	conversation.Provider = saved.Provider
+	conversation.Model = saved.Model
+	for i, msg := range saved.History {
+		var lineage *schemas.ModelLineage
+		if responder, ok := saved.Responders[i]; ok {
+			provider, model, _ := strings.Cut(responder, "/")
+			lineage = &schemas.ModelLineage{Provider: schemas.ModelProvider(provider), Model: model}
+		}
+		conversation.Append(msg, lineage, nil)
+	}
+	return s.ImportConversation(conversation)
+}
+
+// autoSave persists the session after each turn, warning instead of failing the turn
+func (s *ChatSession) autoSave() {
+	if s.sessionFile == "" {
+		return
+	}
+	if err := s.Save(""); err != nil {
+		fmt.Printf("⚠️ Failed to save session: %v\n", err)
+	}
+}
+
+// ExportTranscript writes the conversation to path as markdown, or in the canonical conversation
+// format if path ends in .json
+func (s *ChatSession) ExportTranscript(path string) error {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return s.Save(path)
+	}
+
+	var transcript strings.Builder
+	transcript.WriteString("# Bifrost Chat Transcript\n\n")
+	transcript.WriteString(fmt.Sprintf("Exported: %s\n\n", time.Now().Format(time.RFC3339)))
+
+	for i, msg := range s.history {
+		if msg.Role == schemas.ModelChatMessageRoleSystem {
+			continue
+		}
+
+		heading := cases.Title(language.English).String(string(msg.Role))
+		if responder, ok := s.responder(i); ok {
+			heading = fmt.Sprintf("%s (%s)", heading, responder)
+		}
+		transcript.WriteString(fmt.Sprintf("## %s\n\n", heading))
+
+		if content := msg.Content.Text(); content != "" {
+			transcript.WriteString(content)
+			transcript.WriteString("\n\n")
+		}
+		for _, line := range toolCallLines(msg) {
+			transcript.WriteString(fmt.Sprintf("- 🔧 `%s`\n", line))
+		}
+		if msg.AssistantMessage != nil && msg.ToolCalls != nil && len(*msg.ToolCalls) > 0 {
+			transcript.WriteString("\n")
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(transcript.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// toolCallLines formats the tool calls of an assistant message as name(arguments)
+func toolCallLines(msg schemas.BifrostMessage) []string {
+	if msg.AssistantMessage == nil || msg.ToolCalls == nil {
+		return nil
+	}
+	var lines []string
+	for _, toolCall := range *msg.ToolCalls {
+		name := "unknown"
+		if toolCall.Function.Name != nil {
+			name = *toolCall.Function.Name
+		}
+		lines = append(lines, fmt.Sprintf("%s(%s)", name, toolCall.Function.Arguments))
+	}
+	return lines
+}
+
+// setProvider switches to a provider by name, using model or the provider's first configured model
+func (s *ChatSession) setProvider(provider schemas.ModelProvider, model string) error {
+	if !slices.Contains(s.getAvailableProviders(), provider) {
+		return fmt.Errorf("provider %s is not available", provider)
+	}
+
+	models := s.getAvailableModels(provider)
+	if model == "" {
+		if len(models) == 0 {
+			return fmt.Errorf("no models available for provider %s", provider)
+		}
+		model = models[0]
+	} else if len(models) > 0 && !slices.Contains(models, model) {
+		return fmt.Errorf("model %s is not configured for provider %s", model, provider)
+	}
+
+	s.config.Provider = provider
+	s.config.Model = model
+	fmt.Printf("✅ Switched to %s with model %s\n", provider, model)
+	return nil
+}
+
+// setModel switches to a model of the current provider by name
+func (s *ChatSession) setModel(model string) error {
+	if models := s.getAvailableModels(s.config.Provider); len(models) > 0 && !slices.Contains(models, model) {
+		return fmt.Errorf("model %s is not configured for provider %s", model, s.config.Provider)
+	}
+
+	s.config.Model = model
+	fmt.Printf("✅ Switched to model %s\n", model)
+	return nil
+}
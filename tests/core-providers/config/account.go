@@ -80,6 +80,17 @@ func (account *ComprehensiveTestAccount) GetConfiguredProviders() ([]schemas.Mod
 		schemas.Cerebras,
 		schemas.Gemini,
 		schemas.OpenRouter,
+		schemas.Perplexity,
+		schemas.DeepSeek,
+		schemas.Together,
+		schemas.Replicate,
+		schemas.Qwen,
+		schemas.Zhipu,
+		schemas.Moonshot,
+		schemas.Qianfan,
+		schemas.SambaNova,
+		schemas.NIM,
+		schemas.Watsonx,
 		ProviderOpenAICustom,
 	}, nil
 }
@@ -212,6 +223,95 @@ func (account *ComprehensiveTestAccount) GetKeysForProvider(ctx *context.Context
 				Weight: 1.0,
 			},
 		}, nil
+	case schemas.Perplexity:
+		return []schemas.Key{
+			{
+				Value:  os.Getenv("PERPLEXITY_API_KEY"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
+	case schemas.DeepSeek:
+		return []schemas.Key{
+			{
+				Value:  os.Getenv("DEEPSEEK_API_KEY"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
+	case schemas.Together:
+		return []schemas.Key{
+			{
+				Value:  os.Getenv("TOGETHER_API_KEY"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
+	case schemas.Replicate:
+		return []schemas.Key{
+			{
+				Value:  os.Getenv("REPLICATE_API_TOKEN"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
+	case schemas.Qwen:
+		return []schemas.Key{
+			{
+				Value:  os.Getenv("DASHSCOPE_API_KEY"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
+	case schemas.Zhipu:
+		return []schemas.Key{
+			{
+				Value:  os.Getenv("ZHIPU_API_KEY"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
+	case schemas.Moonshot:
+		return []schemas.Key{
+			{
+				Value:  os.Getenv("MOONSHOT_API_KEY"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
+	case schemas.Qianfan:
+		return []schemas.Key{
+			{
+				// API key and secret key, exchanged for an access token
+				Value:  os.Getenv("QIANFAN_API_KEY") + ":" + os.Getenv("QIANFAN_SECRET_KEY"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
+	case schemas.SambaNova:
+		return []schemas.Key{
+			{
+				Value:  os.Getenv("SAMBANOVA_API_KEY"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
+	case schemas.NIM:
+		return []schemas.Key{
+			{
+				Value:  os.Getenv("NVIDIA_API_KEY"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
+	case schemas.Watsonx:
+		return []schemas.Key{
+			{
+				Value:  os.Getenv("WATSONX_API_KEY") + ":" + os.Getenv("WATSONX_PROJECT_ID"),
+				Models: []string{},
+				Weight: 1.0,
+			},
+		}, nil
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", providerKey)
 	}
@@ -371,6 +471,101 @@ func (account *ComprehensiveTestAccount) GetConfigForProvider(providerKey schema
 			},
 			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
 		}, nil
+	case schemas.Perplexity:
+		return &schemas.ProviderConfig{
+			NetworkConfig: schemas.NetworkConfig{
+				DefaultRequestTimeoutInSeconds: 60,
+				MaxRetries:                     1,
+				RetryBackoffInitial:            100 * time.Millisecond,
+				RetryBackoffMax:                2 * time.Second,
+			},
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
+	case schemas.DeepSeek:
+		return &schemas.ProviderConfig{
+			NetworkConfig: schemas.NetworkConfig{
+				DefaultRequestTimeoutInSeconds: 60,
+				MaxRetries:                     1,
+				RetryBackoffInitial:            100 * time.Millisecond,
+				RetryBackoffMax:                2 * time.Second,
+			},
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
+	case schemas.Together:
+		return &schemas.ProviderConfig{
+			NetworkConfig: schemas.NetworkConfig{
+				DefaultRequestTimeoutInSeconds: 60,
+				MaxRetries:                     1,
+				RetryBackoffInitial:            100 * time.Millisecond,
+				RetryBackoffMax:                2 * time.Second,
+			},
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
+	case schemas.Replicate:
+		return &schemas.ProviderConfig{
+			NetworkConfig: schemas.NetworkConfig{
+				DefaultRequestTimeoutInSeconds: 120,
+				MaxRetries:                     1,
+				RetryBackoffInitial:            100 * time.Millisecond,
+				RetryBackoffMax:                2 * time.Second,
+			},
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
+	case schemas.Qwen:
+		return &schemas.ProviderConfig{
+			NetworkConfig: schemas.NetworkConfig{
+				DefaultRequestTimeoutInSeconds: 60,
+				MaxRetries:                     1,
+				RetryBackoffInitial:            100 * time.Millisecond,
+				RetryBackoffMax:                2 * time.Second,
+			},
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
+	case schemas.Zhipu:
+		return &schemas.ProviderConfig{
+			NetworkConfig: schemas.NetworkConfig{
+				DefaultRequestTimeoutInSeconds: 60,
+				MaxRetries:                     1,
+				RetryBackoffInitial:            100 * time.Millisecond,
+				RetryBackoffMax:                2 * time.Second,
+			},
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
+	case schemas.Moonshot:
+		return &schemas.ProviderConfig{
+			NetworkConfig: schemas.NetworkConfig{
+				DefaultRequestTimeoutInSeconds: 60,
+				MaxRetries:                     1,
+				RetryBackoffInitial:            100 * time.Millisecond,
+				RetryBackoffMax:                2 * time.Second,
+			},
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
+	case schemas.Qianfan:
+		return &schemas.ProviderConfig{
+			NetworkConfig: schemas.NetworkConfig{
+				DefaultRequestTimeoutInSeconds: 60,
+				MaxRetries:                     1,
+				RetryBackoffInitial:            100 * time.Millisecond,
+				RetryBackoffMax:                2 * time.Second,
+			},
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
+	case schemas.SambaNova:
+		return &schemas.ProviderConfig{
+			NetworkConfig:            schemas.DefaultNetworkConfig,
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
+	case schemas.NIM:
+		return &schemas.ProviderConfig{
+			NetworkConfig:            schemas.DefaultNetworkConfig,
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
+	case schemas.Watsonx:
+		return &schemas.ProviderConfig{
+			NetworkConfig:            schemas.DefaultNetworkConfig,
+			ConcurrencyAndBufferSize: schemas.DefaultConcurrencyAndBufferSize,
+		}, nil
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", providerKey)
 	}
@@ -718,4 +913,321 @@ var AllProviderConfigs = []ComprehensiveTestConfig{
 			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
 		},
 	},
+	{
+		Provider:  schemas.Perplexity,
+		ChatModel: "sonar",
+		TextModel: "", // Perplexity doesn't support text completion
+		Scenarios: TestScenarios{
+			TextCompletion:        false, // Not supported
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             false, // Not supported
+			MultipleToolCalls:     false,
+			End2EndToolCalling:    false,
+			AutomaticFunctionCall: false,
+			ImageURL:              false,
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       false,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             false,
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
+	{
+		Provider:  schemas.DeepSeek,
+		ChatModel: "deepseek-chat",
+		TextModel: "", // DeepSeek doesn't support text completion
+		Scenarios: TestScenarios{
+			TextCompletion:        false, // Not supported
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false,
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             false,
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
+	{
+		Provider:       schemas.Together,
+		ChatModel:      "meta-llama/Llama-3.3-70B-Instruct-Turbo",
+		TextModel:      "", // Together text completion is not supported
+		EmbeddingModel: "BAAI/bge-base-en-v1.5",
+		Scenarios: TestScenarios{
+			TextCompletion:        false, // Not supported
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false,
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             true,
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
+	{
+		Provider:       schemas.Replicate,
+		ChatModel:      "meta/meta-llama-3-8b-instruct",
+		TextModel:      "meta/meta-llama-3-8b",
+		EmbeddingModel: "", // Replicate embedding is not supported
+		Scenarios: TestScenarios{
+			TextCompletion:        true,
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             false, // Not supported
+			MultipleToolCalls:     false,
+			End2EndToolCalling:    false,
+			AutomaticFunctionCall: false,
+			ImageURL:              false,
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       false,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             false,
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
+	{
+		Provider:       schemas.Qwen,
+		ChatModel:      "qwen-plus",
+		TextModel:      "", // Qwen text completion is not supported
+		EmbeddingModel: "text-embedding-v3",
+		Scenarios: TestScenarios{
+			TextCompletion:        false, // Not supported
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false, // Images need a multimodal model such as qwen-vl-max
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             true,
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
+	{
+		Provider:       schemas.Zhipu,
+		ChatModel:      "glm-4.5-air",
+		TextModel:      "", // Zhipu text completion is not supported
+		EmbeddingModel: "embedding-3",
+		Scenarios: TestScenarios{
+			TextCompletion:        false, // Not supported
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: false, // GLM only supports the auto tool choice
+			ImageURL:              false, // Images need a vision model such as glm-4v
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             true,
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
+	{
+		Provider:       schemas.Moonshot,
+		ChatModel:      "moonshot-v1-8k",
+		TextModel:      "", // Moonshot text completion is not supported
+		EmbeddingModel: "", // Moonshot embedding is not supported
+		Scenarios: TestScenarios{
+			TextCompletion:        false, // Not supported
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false, // Images need a vision model such as moonshot-v1-8k-vision-preview
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             false, // Not supported
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
+	{
+		Provider:       schemas.Qianfan,
+		ChatModel:      "ernie-speed-8k",
+		TextModel:      "", // Qianfan text completion is not supported
+		EmbeddingModel: "embedding-v1",
+		Scenarios: TestScenarios{
+			TextCompletion:        false, // Not supported
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     false, // ERNIE calls one function per message
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false, // ERNIE chat models only take text
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             true,
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
+	{
+		Provider:       schemas.SambaNova,
+		ChatModel:      "Meta-Llama-3.3-70B-Instruct",
+		TextModel:      "", // SambaNova text completion is not supported
+		EmbeddingModel: "", // SambaNova embedding is not supported
+		Scenarios: TestScenarios{
+			TextCompletion:        false, // Not supported
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false, // Images need a vision model such as Llama-4-Maverick-17B-128E-Instruct
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             false, // Not supported
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
+	{
+		Provider:       schemas.NIM,
+		ChatModel:      "meta/llama-3.1-8b-instruct",
+		TextModel:      "", // NIM text completion is not supported
+		EmbeddingModel: "nvidia/nv-embedqa-e5-v5",
+		Scenarios: TestScenarios{
+			TextCompletion:        false, // Not supported
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false, // Images need a vision model such as meta/llama-3.2-11b-vision-instruct
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             true,
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
+	{
+		Provider:       schemas.Watsonx,
+		ChatModel:      "meta-llama/llama-3-3-70b-instruct",
+		TextModel:      "ibm/granite-3-8b-instruct",
+		EmbeddingModel: "ibm/slate-125m-english-rtrvr",
+		Scenarios: TestScenarios{
+			TextCompletion:        true,
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false, // Images need a vision model such as meta-llama/llama-3-2-11b-vision-instruct
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			SpeechSynthesis:       false,
+			SpeechSynthesisStream: false,
+			Transcription:         false,
+			TranscriptionStream:   false,
+			Embedding:             true,
+		},
+		Fallbacks: []schemas.Fallback{
+			{Provider: schemas.OpenAI, Model: "gpt-4o-mini"},
+		},
+	},
 }
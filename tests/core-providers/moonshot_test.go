@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/maximhq/bifrost/tests/core-providers/config"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestMoonshot(t *testing.T) {
+	client, ctx, cancel, err := config.SetupTest()
+	if err != nil {
+		t.Fatalf("Error initializing test setup: %v", err)
+	}
+	defer cancel()
+	defer client.Shutdown()
+
+	testConfig := config.ComprehensiveTestConfig{
+		Provider:       schemas.Moonshot,
+		ChatModel:      "moonshot-v1-8k",
+		TextModel:      "", // Moonshot text completion is not supported
+		EmbeddingModel: "", // Moonshot embedding is not supported
+		Scenarios: config.TestScenarios{
+			TextCompletion:        false,
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     true,
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false,
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			Embedding:             false,
+		},
+	}
+
+	runAllComprehensiveTests(t, client, ctx, testConfig)
+}
@@ -20,7 +20,7 @@ func TestOllama(t *testing.T) {
 		Provider:  schemas.Ollama,
 		ChatModel: "llama3.2",
 		TextModel: "", // Ollama doesn't support text completion in newer models
-		EmbeddingModel: "", // Ollama doesn't support embedding
+		EmbeddingModel: "nomic-embed-text",
 		Scenarios: config.TestScenarios{
 			TextCompletion:        false, // Not supported
 			SimpleChat:            true,
@@ -35,7 +35,7 @@ func TestOllama(t *testing.T) {
 			MultipleImages:        false,
 			CompleteEnd2End:       true,
 			ProviderSpecific:      true,
-			Embedding:             false,
+			Embedding:             true,
 		},
 	}
 
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/maximhq/bifrost/tests/core-providers/config"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestPerplexity(t *testing.T) {
+	client, ctx, cancel, err := config.SetupTest()
+	if err != nil {
+		t.Fatalf("Error initializing test setup: %v", err)
+	}
+	defer cancel()
+	defer client.Shutdown()
+
+	testConfig := config.ComprehensiveTestConfig{
+		Provider:       schemas.Perplexity,
+		ChatModel:      "sonar",
+		TextModel:      "", // Perplexity doesn't support text completion
+		EmbeddingModel: "", // Perplexity doesn't support embedding
+		Scenarios: config.TestScenarios{
+			TextCompletion:        false,
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             false,
+			MultipleToolCalls:     false,
+			End2EndToolCalling:    false,
+			AutomaticFunctionCall: false,
+			ImageURL:              false,
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       false,
+			ProviderSpecific:      false,
+			Embedding:             false,
+		},
+	}
+
+	runAllComprehensiveTests(t, client, ctx, testConfig)
+}
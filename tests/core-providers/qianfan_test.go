@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/maximhq/bifrost/tests/core-providers/config"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestQianfan(t *testing.T) {
+	client, ctx, cancel, err := config.SetupTest()
+	if err != nil {
+		t.Fatalf("Error initializing test setup: %v", err)
+	}
+	defer cancel()
+	defer client.Shutdown()
+
+	testConfig := config.ComprehensiveTestConfig{
+		Provider:       schemas.Qianfan,
+		ChatModel:      "ernie-speed-8k",
+		TextModel:      "", // Qianfan text completion is not supported
+		EmbeddingModel: "embedding-v1",
+		Scenarios: config.TestScenarios{
+			TextCompletion:        false,
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             true,
+			MultipleToolCalls:     false, // ERNIE calls one function per message
+			End2EndToolCalling:    true,
+			AutomaticFunctionCall: true,
+			ImageURL:              false,
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       true,
+			ProviderSpecific:      false,
+			Embedding:             true,
+		},
+	}
+
+	runAllComprehensiveTests(t, client, ctx, testConfig)
+}
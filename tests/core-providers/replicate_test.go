@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/maximhq/bifrost/tests/core-providers/config"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestReplicate(t *testing.T) {
+	client, ctx, cancel, err := config.SetupTest()
+	if err != nil {
+		t.Fatalf("Error initializing test setup: %v", err)
+	}
+	defer cancel()
+	defer client.Shutdown()
+
+	testConfig := config.ComprehensiveTestConfig{
+		Provider:       schemas.Replicate,
+		ChatModel:      "meta/meta-llama-3-8b-instruct",
+		TextModel:      "meta/meta-llama-3-8b",
+		EmbeddingModel: "", // Replicate embedding is not supported
+		Scenarios: config.TestScenarios{
+			TextCompletion:        true,
+			SimpleChat:            true,
+			ChatCompletionStream:  true,
+			MultiTurnConversation: true,
+			ToolCalls:             false, // Not supported
+			MultipleToolCalls:     false,
+			End2EndToolCalling:    false,
+			AutomaticFunctionCall: false,
+			ImageURL:              false,
+			ImageBase64:           false,
+			MultipleImages:        false,
+			CompleteEnd2End:       false,
+			ProviderSpecific:      false,
+			Embedding:             false,
+		},
+	}
+
+	runAllComprehensiveTests(t, client, ctx, testConfig)
+}
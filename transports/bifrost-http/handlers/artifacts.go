@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/artifactstore"
+	"github.com/valyala/fasthttp"
+)
+
+// ArtifactsHandler serves the artifacts of a filesystem artifact store to the holders of a signed
+// URL. Stores with URLs of their own, such as S3, need no handler.
+type ArtifactsHandler struct {
+	logger schemas.Logger
+	store  *artifactstore.FilesystemStore
+}
+
+// NewArtifactsHandler returns the handler of the store of an artifacts plugin, or nil if the
+// store serves its artifacts itself.
+func NewArtifactsHandler(plugin schemas.Plugin, logger schemas.Logger) *ArtifactsHandler {
+	artifactsPlugin, ok := plugin.(*artifactstore.Plugin)
+	if !ok {
+		logger.Fatal("Artifacts handler requires an artifacts plugin")
+	}
+	store, ok := artifactsPlugin.GetStore().(*artifactstore.FilesystemStore)
+	if !ok {
+		return nil
+	}
+
+	return &ArtifactsHandler{
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (h *ArtifactsHandler) RegisterRoutes(r *router.Router) {
+	r.GET(artifactstore.ServePath+"{key}", h.getArtifact)
+}
+
+// getArtifact serves an artifact once the expiry and signature of its URL are verified.
+func (h *ArtifactsHandler) getArtifact(ctx *fasthttp.RequestCtx) {
+	key, ok := ctx.UserValue("key").(string)
+	if !ok {
+		SendError(ctx, fasthttp.StatusBadRequest, "Invalid artifact key", h.logger)
+		return
+	}
+	expires := string(ctx.QueryArgs().Peek("expires"))
+	signature := string(ctx.QueryArgs().Peek("signature"))
+	if err := h.store.Verify(key, expires, signature); err != nil {
+		SendError(ctx, fasthttp.StatusForbidden, "Invalid or expired artifact URL", h.logger)
+		return
+	}
+
+	data, err := h.store.Get(ctx, key)
+	if errors.Is(err, artifactstore.ErrNotFound) {
+		SendError(ctx, fasthttp.StatusNotFound, "Artifact not found", h.logger)
+		return
+	}
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, "Failed to read artifact", h.logger)
+		return
+	}
+
+	ctx.Response.Header.Set("Content-Type", artifactstore.MimeType(key))
+	ctx.Response.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	// Content addressed, so the artifact never changes for as long as the URL is valid
+	if expiresAt, err := strconv.ParseInt(expires, 10, 64); err == nil {
+		ctx.Response.Header.Set("Cache-Control", fmt.Sprintf("private, max-age=%d, immutable", max(expiresAt-time.Now().Unix(), 0)))
+	}
+	ctx.Response.SetBody(data)
+}
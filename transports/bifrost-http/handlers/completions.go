@@ -18,10 +18,49 @@ import (
 	"github.com/fasthttp/router"
 	bifrost "github.com/maximhq/bifrost/core"
 	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/core/streamio"
 	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
 	"github.com/valyala/fasthttp"
 )
 
+// StreamMode selects how a streamed response is delivered to the client.
+type StreamMode string
+
+const (
+	StreamModeSSE    StreamMode = "sse"    // Server-Sent Events, the default
+	StreamModeNDJSON StreamMode = "ndjson" // One JSON object per line
+	StreamModeJSON   StreamMode = "json"   // A single response accumulated from the stream
+)
+
+// streamModeQueryParam is the query parameter selecting the stream output mode.
+const streamModeQueryParam = "stream_mode"
+
+// getStreamMode returns the stream output mode requested by the client through the
+// stream_mode query parameter or, failing that, the Accept header. Accumulated JSON can
+// only be selected through the query parameter, since most clients send
+// "Accept: application/json" by default.
+func getStreamMode(ctx *fasthttp.RequestCtx) (StreamMode, error) {
+	if mode := string(ctx.QueryArgs().Peek(streamModeQueryParam)); mode != "" {
+		switch StreamMode(mode) {
+		case StreamModeSSE, StreamModeNDJSON, StreamModeJSON:
+			return StreamMode(mode), nil
+		}
+		return "", fmt.Errorf("invalid %s %q: must be one of sse, ndjson, json", streamModeQueryParam, mode)
+	}
+
+	accept := string(ctx.Request.Header.Peek("Accept"))
+	for _, mediaType := range strings.Split(accept, ",") {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		switch strings.TrimSpace(strings.ToLower(mediaType)) {
+		case "application/x-ndjson", "application/ndjson", "application/jsonl":
+			return StreamModeNDJSON, nil
+		case "text/event-stream":
+			return StreamModeSSE, nil
+		}
+	}
+	return StreamModeSSE, nil
+}
+
 // CompletionHandler manages HTTP requests for completion operations
 type CompletionHandler struct {
 	client       *bifrost.Bifrost
@@ -63,8 +102,21 @@ var completionRequestKnownFields = map[string]bool{
 	"encoding_format":     true,
 	"dimensions":          true,
 	"user":                true,
+	"request_policy":      true,
 }
 
+// imageInputFields are the fields of an image generation request read into schemas.ImageInput.
+// They are not in completionRequestKnownFields, as other requests pass some of them (e.g. seed)
+// to the provider as extra parameters.
+var imageInputFields = []string{"prompt", "negative_prompt", "n", "size", "aspect_ratio", "seed", "output_format"}
+
+// videoInputFields are the fields of a video generation request read into schemas.VideoInput,
+// left out of completionRequestKnownFields for the same reason as imageInputFields.
+var videoInputFields = []string{"prompt", "negative_prompt", "image_url", "aspect_ratio", "resolution", "duration", "loop", "seed", "webhook_url"}
+
+// rerankInputFields are the fields of a rerank request read into schemas.RerankInput.
+var rerankInputFields = []string{"query", "documents", "top_n", "return_documents"}
+
 // CompletionRequest represents a request for either text or chat completion
 type CompletionRequest struct {
 	Model     string                   `json:"model"`     // Model to use in "provider/model" format
@@ -94,6 +146,8 @@ type CompletionRequest struct {
 	Dimensions        *int                `json:"dimensions,omitempty"`          // Number of dimensions for embedding output
 	User              *string             `json:"user,omitempty"`                // User identifier for tracking
 
+	RequestPolicy *schemas.RequestPolicy `json:"request_policy,omitempty"` // Per-request timeout, retry and fallback overrides
+
 	// Dynamic parameters that can be provider-specific, they are directly
 	// added to the request as is.
 	ExtraParams map[string]interface{} `json:"-"`
@@ -150,6 +204,7 @@ func (cr *CompletionRequest) GetModelParameters() *schemas.ModelParameters {
 		EncodingFormat:    cr.EncodingFormat,
 		Dimensions:        cr.Dimensions,
 		User:              cr.User,
+		RequestPolicy:     cr.RequestPolicy,
 	}
 
 	if cr.ExtraParams != nil {
@@ -169,6 +224,11 @@ const (
 	CompletionTypeEmbeddings    CompletionType = "embeddings"
 	CompletionTypeSpeech        CompletionType = "speech"
 	CompletionTypeTranscription CompletionType = "transcription"
+	CompletionTypeTranslation   CompletionType = "translation"
+	CompletionTypeImage         CompletionType = "image"
+	CompletionTypeVideo         CompletionType = "video"
+	CompletionTypeTokenCount    CompletionType = "token_count"
+	CompletionTypeRerank        CompletionType = "rerank"
 )
 
 const (
@@ -268,9 +328,15 @@ func (h *CompletionHandler) RegisterRoutes(r *router.Router) {
 	// Completion endpoints
 	r.POST("/v1/text/completions", h.textCompletion)
 	r.POST("/v1/chat/completions", h.chatCompletion)
+	r.POST("/v1/chat/count_tokens", h.countTokens)
 	r.POST("/v1/embeddings", h.embeddings)
+	r.POST("/v1/rerank", h.rerank)
 	r.POST("/v1/audio/speech", h.speechCompletion)
 	r.POST("/v1/audio/transcriptions", h.transcriptionCompletion)
+	r.POST("/v1/audio/translations", h.translationCompletion)
+	r.POST("/v1/images/generations", h.imageGeneration)
+	r.POST("/v1/videos/generations", h.videoGeneration)
+	r.GET("/v1/videos/generations", h.videoStatus)
 }
 
 // textCompletion handles POST /v1/text/completions - Process text completion requests
@@ -283,18 +349,88 @@ func (h *CompletionHandler) chatCompletion(ctx *fasthttp.RequestCtx) {
 	h.handleRequest(ctx, CompletionTypeChat)
 }
 
+// countTokens handles POST /v1/chat/count_tokens - Count the input tokens of chat completion requests
+func (h *CompletionHandler) countTokens(ctx *fasthttp.RequestCtx) {
+	h.handleRequest(ctx, CompletionTypeTokenCount)
+}
+
 // embeddings handles POST /v1/embeddings - Process embeddings requests
 func (h *CompletionHandler) embeddings(ctx *fasthttp.RequestCtx) {
 	h.handleRequest(ctx, CompletionTypeEmbeddings)
 }
 
+// rerank handles POST /v1/rerank - Order documents by their relevance to a query
+func (h *CompletionHandler) rerank(ctx *fasthttp.RequestCtx) {
+	h.handleRequest(ctx, CompletionTypeRerank)
+}
+
 // speechCompletion handles POST /v1/audio/speech - Process speech completion requests
 func (h *CompletionHandler) speechCompletion(ctx *fasthttp.RequestCtx) {
 	h.handleRequest(ctx, CompletionTypeSpeech)
 }
 
+// imageGeneration handles POST /v1/images/generations - Process image generation requests
+func (h *CompletionHandler) imageGeneration(ctx *fasthttp.RequestCtx) {
+	h.handleRequest(ctx, CompletionTypeImage)
+}
+
+// videoGeneration handles POST /v1/videos/generations - Submit video generation jobs
+func (h *CompletionHandler) videoGeneration(ctx *fasthttp.RequestCtx) {
+	h.handleRequest(ctx, CompletionTypeVideo)
+}
+
+// videoStatus handles GET /v1/videos/generations - Poll a video generation job, given by the
+// model and job_id query parameters
+func (h *CompletionHandler) videoStatus(ctx *fasthttp.RequestCtx) {
+	model := string(ctx.QueryArgs().Peek("model"))
+	jobID := string(ctx.QueryArgs().Peek("job_id"))
+	if model == "" || jobID == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "model and job_id query parameters are required", h.logger)
+		return
+	}
+
+	provider, modelName, err := ParseModel(model)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Model must be in the format of 'provider/model': %v", err), h.logger)
+		return
+	}
+
+	bifrostReq := &schemas.BifrostRequest{
+		Model:    modelName,
+		Provider: schemas.ModelProvider(provider),
+		Input: schemas.RequestInput{
+			VideoJobInput: &schemas.VideoJobInput{JobID: jobID},
+		},
+	}
+
+	// Convert context
+	bifrostCtx := lib.ConvertToBifrostContext(ctx, h.handlerStore.ShouldAllowDirectKeys())
+	if bifrostCtx == nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, "Failed to convert context", h.logger)
+		return
+	}
+
+	resp, bifrostErr := h.client.VideoStatusRequest(*bifrostCtx, bifrostReq)
+	if bifrostErr != nil {
+		SendBifrostError(ctx, bifrostErr, h.logger)
+		return
+	}
+
+	SendJSON(ctx, resp, h.logger)
+}
+
 // transcriptionCompletion handles POST /v1/audio/transcriptions - Process transcription requests
 func (h *CompletionHandler) transcriptionCompletion(ctx *fasthttp.RequestCtx) {
+	h.handleAudioRequest(ctx, CompletionTypeTranscription)
+}
+
+// translationCompletion handles POST /v1/audio/translations - Process translation requests into English
+func (h *CompletionHandler) translationCompletion(ctx *fasthttp.RequestCtx) {
+	h.handleAudioRequest(ctx, CompletionTypeTranslation)
+}
+
+// handleAudioRequest processes the multipart form of transcription and translation requests
+func (h *CompletionHandler) handleAudioRequest(ctx *fasthttp.RequestCtx, completionType CompletionType) {
 	// Parse multipart form
 	form, err := ctx.MultipartForm()
 	if err != nil {
@@ -349,8 +485,8 @@ func (h *CompletionHandler) transcriptionCompletion(ctx *fasthttp.RequestCtx) {
 		File: fileData,
 	}
 
-	// Extract optional parameters
-	if languageValues := form.Value["language"]; len(languageValues) > 0 && languageValues[0] != "" {
+	// Extract optional parameters, translations are always into English
+	if languageValues := form.Value["language"]; completionType == CompletionTypeTranscription && len(languageValues) > 0 && languageValues[0] != "" {
 		transcriptionInput.Language = &languageValues[0]
 	}
 
@@ -378,16 +514,26 @@ func (h *CompletionHandler) transcriptionCompletion(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	if streamValues := form.Value["stream"]; len(streamValues) > 0 && streamValues[0] != "" {
-		stream := streamValues[0]
-		if stream == "true" {
-			h.handleStreamingTranscriptionRequest(ctx, bifrostReq, bifrostCtx)
+	// An explicit stream_mode implies streaming
+	streamValues := form.Value["stream"]
+	if len(streamValues) > 0 && streamValues[0] == "true" || ctx.QueryArgs().Has(streamModeQueryParam) {
+		if completionType == CompletionTypeTranslation {
+			SendError(ctx, fasthttp.StatusBadRequest, "Streaming is not supported for translations", h.logger)
 			return
 		}
+		h.handleStreamingTranscriptionRequest(ctx, bifrostReq, bifrostCtx)
+		return
 	}
 
-	// Make transcription request
-	resp, bifrostErr := h.client.TranscriptionRequest(*bifrostCtx, bifrostReq)
+	var (
+		resp       *schemas.BifrostResponse
+		bifrostErr *schemas.BifrostError
+	)
+	if completionType == CompletionTypeTranslation {
+		resp, bifrostErr = h.client.TranslationRequest(*bifrostCtx, bifrostReq)
+	} else {
+		resp, bifrostErr = h.client.TranscriptionRequest(*bifrostCtx, bifrostReq)
+	}
 
 	// Handle response
 	if bifrostErr != nil {
@@ -454,7 +600,7 @@ func (h *CompletionHandler) handleRequest(ctx *fasthttp.RequestCtx, completionTy
 		bifrostReq.Input = schemas.RequestInput{
 			TextCompletionInput: &req.Text,
 		}
-	case CompletionTypeChat:
+	case CompletionTypeChat, CompletionTypeTokenCount:
 		if len(req.Messages) == 0 {
 			SendError(ctx, fasthttp.StatusBadRequest, "Messages array is required for chat completion", h.logger)
 			return
@@ -483,6 +629,57 @@ func (h *CompletionHandler) handleRequest(ctx *fasthttp.RequestCtx, completionTy
 				ResponseFormat: req.ResponseFormat,
 			},
 		}
+	case CompletionTypeImage:
+		var imageInput schemas.ImageInput
+		if err := sonic.Unmarshal(ctx.PostBody(), &imageInput); err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid request format: %v", err), h.logger)
+			return
+		}
+		if imageInput.Prompt == "" {
+			SendError(ctx, fasthttp.StatusBadRequest, "Prompt is required for image generation", h.logger)
+			return
+		}
+		// The image fields are part of the input, not provider parameters
+		for _, field := range imageInputFields {
+			delete(bifrostReq.Params.ExtraParams, field)
+		}
+		bifrostReq.Input = schemas.RequestInput{
+			ImageInput: &imageInput,
+		}
+	case CompletionTypeVideo:
+		var videoInput schemas.VideoInput
+		if err := sonic.Unmarshal(ctx.PostBody(), &videoInput); err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid request format: %v", err), h.logger)
+			return
+		}
+		if videoInput.Prompt == "" && videoInput.ImageURL == nil {
+			SendError(ctx, fasthttp.StatusBadRequest, "Prompt or image_url is required for video generation", h.logger)
+			return
+		}
+		// The video fields are part of the input, not provider parameters
+		for _, field := range videoInputFields {
+			delete(bifrostReq.Params.ExtraParams, field)
+		}
+		bifrostReq.Input = schemas.RequestInput{
+			VideoInput: &videoInput,
+		}
+	case CompletionTypeRerank:
+		var rerankInput schemas.RerankInput
+		if err := sonic.Unmarshal(ctx.PostBody(), &rerankInput); err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid request format: %v", err), h.logger)
+			return
+		}
+		if rerankInput.Query == "" || len(rerankInput.Documents) == 0 {
+			SendError(ctx, fasthttp.StatusBadRequest, "Query and documents are required for rerank", h.logger)
+			return
+		}
+		// The rerank fields are part of the input, not provider parameters
+		for _, field := range rerankInputFields {
+			delete(bifrostReq.Params.ExtraParams, field)
+		}
+		bifrostReq.Input = schemas.RequestInput{
+			RerankInput: &rerankInput,
+		}
 	}
 
 	// Convert context
@@ -492,8 +689,9 @@ func (h *CompletionHandler) handleRequest(ctx *fasthttp.RequestCtx, completionTy
 		return
 	}
 
-	// Check if streaming is requested
-	isStreaming := req.Stream != nil && *req.Stream || req.StreamFormat != nil && *req.StreamFormat == "sse"
+	// Check if streaming is requested, an explicit stream_mode implies streaming
+	binaryStream := completionType == CompletionTypeSpeech && req.StreamFormat != nil && *req.StreamFormat == "audio"
+	isStreaming := req.Stream != nil && *req.Stream || req.StreamFormat != nil && *req.StreamFormat == "sse" || binaryStream || ctx.QueryArgs().Has(streamModeQueryParam)
 
 	// Handle streaming for chat completions only
 	if isStreaming {
@@ -502,6 +700,10 @@ func (h *CompletionHandler) handleRequest(ctx *fasthttp.RequestCtx, completionTy
 			h.handleStreamingChatCompletion(ctx, bifrostReq, bifrostCtx)
 			return
 		case CompletionTypeSpeech:
+			if binaryStream {
+				h.handleBinaryStreamingSpeech(ctx, bifrostReq, bifrostCtx)
+				return
+			}
 			h.handleStreamingSpeech(ctx, bifrostReq, bifrostCtx)
 			return
 		}
@@ -520,6 +722,14 @@ func (h *CompletionHandler) handleRequest(ctx *fasthttp.RequestCtx, completionTy
 		resp, bifrostErr = h.client.EmbeddingRequest(*bifrostCtx, bifrostReq)
 	case CompletionTypeSpeech:
 		resp, bifrostErr = h.client.SpeechRequest(*bifrostCtx, bifrostReq)
+	case CompletionTypeImage:
+		resp, bifrostErr = h.client.ImageGenerationRequest(*bifrostCtx, bifrostReq)
+	case CompletionTypeVideo:
+		resp, bifrostErr = h.client.VideoGenerationRequest(*bifrostCtx, bifrostReq)
+	case CompletionTypeTokenCount:
+		resp, bifrostErr = h.client.CountTokensRequest(*bifrostCtx, bifrostReq)
+	case CompletionTypeRerank:
+		resp, bifrostErr = h.client.RerankRequest(*bifrostCtx, bifrostReq)
 	}
 
 	// Handle response
@@ -528,8 +738,9 @@ func (h *CompletionHandler) handleRequest(ctx *fasthttp.RequestCtx, completionTy
 		return
 	}
 
-	if completionType == CompletionTypeSpeech {
-		if resp.Speech.Audio == nil {
+	// Speech audio moved to an artifact store is sent as JSON with its URL
+	if completionType == CompletionTypeSpeech && (resp.Speech == nil || resp.Speech.URL == "") {
+		if resp.Speech == nil || resp.Speech.Audio == nil {
 			SendError(ctx, fasthttp.StatusInternalServerError, "Speech response is missing audio data", h.logger)
 			return
 		}
@@ -545,36 +756,82 @@ func (h *CompletionHandler) handleRequest(ctx *fasthttp.RequestCtx, completionTy
 	SendJSON(ctx, resp, h.logger)
 }
 
-// handleStreamingResponse is a generic function to handle streaming responses using Server-Sent Events (SSE)
-func (h *CompletionHandler) handleStreamingResponse(ctx *fasthttp.RequestCtx, getStream func() (chan *schemas.BifrostStream, *schemas.BifrostError), extractResponse func(*schemas.BifrostStream) (interface{}, bool)) {
-	// Set SSE headers
-	ctx.SetContentType("text/event-stream")
+// handleStreamingResponse is a generic function to handle streaming responses. The stream is
+// delivered as Server-Sent Events, NDJSON or a single accumulated JSON response depending on
+// the mode requested by the client (see getStreamMode).
+//
+// SSE and NDJSON streams are written through a lib.StreamGuard: the provider stream is drained
+// into a bounded buffer independently of the client, every write has a deadline, and a client
+// that falls too far behind is handled by the configured slow client policy. Dropping a client
+// cancels the upstream request.
+func (h *CompletionHandler) handleStreamingResponse(ctx *fasthttp.RequestCtx, bifrostCtx context.Context, getStream func(context.Context) (chan *schemas.BifrostStream, *schemas.BifrostError), extractResponse func(*schemas.BifrostStream) (interface{}, bool)) {
+	mode, err := getStreamMode(ctx)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+
+	if mode == StreamModeJSON {
+		h.handleAccumulatedStreamResponse(ctx, bifrostCtx, getStream)
+		return
+	}
+
+	if mode == StreamModeNDJSON {
+		ctx.SetContentType("application/x-ndjson")
+	} else {
+		// Set SSE headers
+		ctx.SetContentType("text/event-stream")
+		ctx.Response.Header.Set("Connection", "keep-alive")
+	}
 	ctx.Response.Header.Set("Cache-Control", "no-cache")
-	ctx.Response.Header.Set("Connection", "keep-alive")
 	ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
 
-	// Get the streaming channel
-	stream, bifrostErr := getStream()
+	// Get the streaming channel, on a context that is cancelled when the client is dropped
+	streamCtx, cancel := context.WithCancel(bifrostCtx)
+	stream, bifrostErr := getStream(streamCtx)
 	if bifrostErr != nil {
+		cancel()
+		if mode == StreamModeNDJSON {
+			SendNDJSONError(ctx, bifrostErr, h.logger)
+			return
+		}
 		// Send error in SSE format
 		SendSSEError(ctx, bifrostErr, h.logger)
 		return
 	}
 
+	guard := lib.GuardStream(stream, cancel, h.handlerStore.GetStreamGuardConfig())
+	conn := ctx.Conn()
+
 	// Use streaming response writer
 	ctx.Response.SetBodyStreamWriter(func(w *bufio.Writer) {
-		defer w.Flush()
+		defer cancel()
+		defer guard.ClearWriteDeadline(conn)
 
 		// Process streaming responses
-		for response := range stream {
-			if response == nil {
-				continue
+		for {
+			response, ok := guard.Next()
+			if !ok {
+				break
 			}
 
-			// Extract and validate the response data
-			data, valid := extractResponse(response)
-			if !valid {
-				continue
+			// Extract and validate the response data. Queue status and resync chunks carry no
+			// response, so they are written as is.
+			var data interface{}
+			event := ""
+			switch {
+			case response.QueueStatus != nil:
+				data = response
+				event = "queue_status"
+			case response.Resync != nil:
+				data = response
+				event = "resync"
+			default:
+				var valid bool
+				data, valid = extractResponse(response)
+				if !valid {
+					continue
+				}
 			}
 
 			// Convert response to JSON
@@ -584,67 +841,205 @@ func (h *CompletionHandler) handleStreamingResponse(ctx *fasthttp.RequestCtx, ge
 				continue
 			}
 
-			// Send as SSE data
-			if _, err := fmt.Fprintf(w, "data: %s\n\n", responseJSON); err != nil {
-				h.logger.Warn(fmt.Sprintf("Failed to write SSE data: %v", err))
-				break
+			// Send as SSE data or as a single NDJSON line, a client that does not take it in time is dropped
+			guard.ArmWriteDeadline(conn)
+			switch {
+			case mode == StreamModeNDJSON:
+				_, err = fmt.Fprintf(w, "%s\n", responseJSON)
+			case event != "":
+				_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, responseJSON)
+			default:
+				_, err = fmt.Fprintf(w, "data: %s\n\n", responseJSON)
+			}
+			if err != nil {
+				guard.Terminate(fmt.Sprintf("failed to write stream data: %v", err))
+				return
 			}
 
 			// Flush immediately to send the chunk
 			if err := w.Flush(); err != nil {
-				h.logger.Warn(fmt.Sprintf("Failed to flush SSE data: %v", err))
-				break
+				guard.Terminate(fmt.Sprintf("failed to flush stream data: %v", err))
+				return
 			}
 		}
 
-		// Send the [DONE] marker to indicate the end of the stream
-		if _, err := fmt.Fprint(w, "data: [DONE]\n\n"); err != nil {
-			h.logger.Warn(fmt.Sprintf("Failed to write SSE done marker: %v", err))
+		guard.ArmWriteDeadline(conn)
+		if reason := guard.Terminated(); reason != "" {
+			// Tell a client that was too slow why its stream ends early
+			terminated := &schemas.BifrostError{
+				IsBifrostError: true,
+				Origin:         schemas.ErrorOriginClientRequest,
+				Error:          schemas.ErrorField{Message: "stream terminated: " + reason},
+			}
+			if errorJSON, err := sonic.Marshal(terminated); err == nil {
+				if mode == StreamModeNDJSON {
+					fmt.Fprintf(w, "%s\n", errorJSON)
+				} else {
+					fmt.Fprintf(w, "data: %s\n\n", errorJSON)
+				}
+			}
+		} else if mode == StreamModeSSE {
+			// Send the [DONE] marker to indicate the end of the stream, NDJSON ends with the body
+			if _, err := fmt.Fprint(w, "data: [DONE]\n\n"); err != nil {
+				h.logger.Warn(fmt.Sprintf("Failed to write SSE done marker: %v", err))
+			}
 		}
+		w.Flush()
 	})
 }
 
+// handleAccumulatedStreamResponse consumes a stream and responds with the single response
+// assembled from its chunks.
+func (h *CompletionHandler) handleAccumulatedStreamResponse(ctx *fasthttp.RequestCtx, bifrostCtx context.Context, getStream func(context.Context) (chan *schemas.BifrostStream, *schemas.BifrostError)) {
+	stream, bifrostErr := getStream(bifrostCtx)
+	if bifrostErr != nil {
+		SendBifrostError(ctx, bifrostErr, h.logger)
+		return
+	}
+
+	resp, bifrostErr := streamio.Accumulate(stream)
+	if bifrostErr != nil {
+		SendBifrostError(ctx, bifrostErr, h.logger)
+		return
+	}
+
+	SendJSON(ctx, resp, h.logger)
+}
+
 // handleStreamingChatCompletion handles streaming chat completion requests using Server-Sent Events (SSE)
 func (h *CompletionHandler) handleStreamingChatCompletion(ctx *fasthttp.RequestCtx, req *schemas.BifrostRequest, bifrostCtx *context.Context) {
-	getStream := func() (chan *schemas.BifrostStream, *schemas.BifrostError) {
-		return h.client.ChatCompletionStreamRequest(*bifrostCtx, req)
+	getStream := func(streamCtx context.Context) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+		return h.client.ChatCompletionStreamRequest(streamCtx, req)
 	}
 
 	extractResponse := func(response *schemas.BifrostStream) (interface{}, bool) {
 		return response, true
 	}
 
-	h.handleStreamingResponse(ctx, getStream, extractResponse)
+	h.handleStreamingResponse(ctx, *bifrostCtx, getStream, extractResponse)
 }
 
 // handleStreamingSpeech handles streaming speech requests using Server-Sent Events (SSE)
 func (h *CompletionHandler) handleStreamingSpeech(ctx *fasthttp.RequestCtx, req *schemas.BifrostRequest, bifrostCtx *context.Context) {
-	getStream := func() (chan *schemas.BifrostStream, *schemas.BifrostError) {
-		return h.client.SpeechStreamRequest(*bifrostCtx, req)
+	getStream := func(streamCtx context.Context) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+		return h.client.SpeechStreamRequest(streamCtx, req)
 	}
 
-	extractResponse := func(response *schemas.BifrostStream) (interface{}, bool) {
-		if response.Speech == nil || response.Speech.BifrostSpeechStreamResponse == nil {
-			return nil, false
+	h.handleStreamingResponse(ctx, *bifrostCtx, getStream, speechStreamResponse)
+}
+
+// handleBinaryStreamingSpeech streams speech as raw audio (stream_format=audio): the body is the
+// audio of the chunks as it arrives, without SSE framing or base64. The body takes the content
+// type of the first audio chunk, so errors before it are sent as JSON with their status; later
+// errors can only end the body early.
+func (h *CompletionHandler) handleBinaryStreamingSpeech(ctx *fasthttp.RequestCtx, req *schemas.BifrostRequest, bifrostCtx *context.Context) {
+	// Get the streaming channel, on a context that is cancelled when the client is dropped
+	streamCtx, cancel := context.WithCancel(*bifrostCtx)
+	stream, bifrostErr := h.client.SpeechStreamRequest(streamCtx, req)
+	if bifrostErr != nil {
+		cancel()
+		SendBifrostError(ctx, bifrostErr, h.logger)
+		return
+	}
+	guard := lib.GuardStream(stream, cancel, h.handlerStore.GetStreamGuardConfig())
+
+	// Wait for the first audio to know the content type of the body
+	var first *schemas.BinaryChunk
+	for first == nil {
+		response, ok := guard.Next()
+		if !ok {
+			cancel()
+			SendError(ctx, fasthttp.StatusBadGateway, "Speech stream ended without audio", h.logger)
+			return
+		}
+		if response.BifrostResponse == nil && response.BifrostError != nil {
+			cancel()
+			SendBifrostError(ctx, response.BifrostError, h.logger)
+			return
 		}
-		return response.Speech, true
+		first = binaryChunk(response)
 	}
 
-	h.handleStreamingResponse(ctx, getStream, extractResponse)
+	ctx.SetContentType(first.ContentType)
+	ctx.Response.Header.Set("Cache-Control", "no-cache")
+	ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
+	conn := ctx.Conn()
+
+	ctx.Response.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		defer guard.ClearWriteDeadline(conn)
+
+		chunk := first
+		for {
+			if chunk != nil {
+				// A client that does not take the audio in time is dropped
+				guard.ArmWriteDeadline(conn)
+				if _, err := w.Write(chunk.Data); err != nil {
+					guard.Terminate(fmt.Sprintf("failed to write stream data: %v", err))
+					return
+				}
+				if err := w.Flush(); err != nil {
+					guard.Terminate(fmt.Sprintf("failed to flush stream data: %v", err))
+					return
+				}
+			}
+
+			response, ok := guard.Next()
+			if !ok {
+				break
+			}
+			if response.BifrostResponse == nil && response.BifrostError != nil {
+				h.logger.Warn(fmt.Sprintf("Speech stream failed after the audio started: %s", response.BifrostError.Error.Message))
+				return
+			}
+			chunk = binaryChunk(response)
+		}
+
+		if reason := guard.Terminated(); reason != "" {
+			h.logger.Warn(fmt.Sprintf("Speech stream ended early: %s", reason))
+		}
+	})
+}
+
+// binaryChunk returns the binary content of a stream chunk, nil if it has none. Speech chunks
+// sent without binary content, such as chunks replayed by a plugin, fall back to their audio.
+func binaryChunk(response *schemas.BifrostStream) *schemas.BinaryChunk {
+	if response.Binary != nil && len(response.Binary.Data) > 0 {
+		return response.Binary
+	}
+	if response.BifrostResponse == nil || response.Speech == nil || len(response.Speech.Audio) == 0 {
+		return nil
+	}
+	return &schemas.BinaryChunk{
+		Sequence:    response.ExtraFields.ChunkIndex,
+		ContentType: "application/octet-stream",
+		Data:        response.Speech.Audio,
+	}
 }
 
 // handleStreamingTranscriptionRequest handles streaming transcription requests using Server-Sent Events (SSE)
 func (h *CompletionHandler) handleStreamingTranscriptionRequest(ctx *fasthttp.RequestCtx, req *schemas.BifrostRequest, bifrostCtx *context.Context) {
-	getStream := func() (chan *schemas.BifrostStream, *schemas.BifrostError) {
-		return h.client.TranscriptionStreamRequest(*bifrostCtx, req)
+	getStream := func(streamCtx context.Context) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+		return h.client.TranscriptionStreamRequest(streamCtx, req)
 	}
 
-	extractResponse := func(response *schemas.BifrostStream) (interface{}, bool) {
-		if response.Transcribe == nil || response.Transcribe.BifrostTranscribeStreamResponse == nil {
-			return nil, false
-		}
-		return response.Transcribe, true
+	h.handleStreamingResponse(ctx, *bifrostCtx, getStream, transcriptionStreamResponse)
+}
+
+// speechStreamResponse extracts the speech chunks of a stream. Error chunks carry no
+// response and are not part of the speech stream, so they are skipped.
+func speechStreamResponse(response *schemas.BifrostStream) (interface{}, bool) {
+	if response.BifrostResponse == nil || response.Speech == nil || response.Speech.BifrostSpeechStreamResponse == nil {
+		return nil, false
 	}
+	return response.Speech, true
+}
 
-	h.handleStreamingResponse(ctx, getStream, extractResponse)
+// transcriptionStreamResponse extracts the transcription chunks of a stream. Error chunks carry no
+// response and are not part of the transcription stream, so they are skipped.
+func transcriptionStreamResponse(response *schemas.BifrostStream) (interface{}, bool) {
+	if response.BifrostResponse == nil || response.Transcribe == nil || response.Transcribe.BifrostTranscribeStreamResponse == nil {
+		return nil, false
+	}
+	return response.Transcribe, true
 }
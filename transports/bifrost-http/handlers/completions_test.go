@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+)
+
+// testHandlerStore is a HandlerStore with the default settings.
+type testHandlerStore struct{}
+
+func (testHandlerStore) ShouldAllowDirectKeys() bool                 { return false }
+func (testHandlerStore) GetStreamGuardConfig() lib.StreamGuardConfig { return lib.StreamGuardConfig{} }
+
+func TestHandleStreamingResponseChunks(t *testing.T) {
+	text := "hello"
+	queued := &schemas.BifrostStream{QueueStatus: &schemas.QueueStatus{Provider: schemas.OpenAI, Position: 2}}
+	chat := &schemas.BifrostStream{BifrostResponse: &schemas.BifrostResponse{Object: "chat.completion.chunk", Choices: []schemas.BifrostResponseChoice{{
+		BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{Delta: schemas.BifrostStreamDelta{Content: &text}},
+	}}}}
+	failed := &schemas.BifrostStream{BifrostError: &schemas.BifrostError{Error: schemas.ErrorField{Message: "upstream failed"}}}
+
+	tests := []struct {
+		name            string
+		extractResponse func(*schemas.BifrostStream) (interface{}, bool)
+		want            []string
+		wantNot         []string
+	}{
+		{
+			name: "chat",
+			extractResponse: func(response *schemas.BifrostStream) (interface{}, bool) {
+				return response, true
+			},
+			want: []string{"event: queue_status\ndata: ", `"position":2`, `"content":"hello"`, `"message":"upstream failed"`},
+		},
+		{
+			// Speech and transcription streams only write their own chunks, errors end them
+			name:            "speech",
+			extractResponse: speechStreamResponse,
+			want:            []string{"event: queue_status\ndata: "},
+			wantNot:         []string{"upstream failed", "hello"},
+		},
+		{
+			name:            "transcription",
+			extractResponse: transcriptionStreamResponse,
+			want:            []string{"event: queue_status\ndata: "},
+			wantNot:         []string{"upstream failed", "hello"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewCompletionHandler(nil, testHandlerStore{}, bifrost.NewDefaultLogger(schemas.LogLevelError))
+			getStream := func(context.Context) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+				stream := make(chan *schemas.BifrostStream, 3)
+				stream <- queued
+				stream <- chat
+				stream <- failed
+				close(stream)
+				return stream, nil
+			}
+
+			var ctx fasthttp.RequestCtx
+			h.handleStreamingResponse(&ctx, context.Background(), getStream, tt.extractResponse)
+			body := string(ctx.Response.Body())
+
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("body does not contain %q:\n%s", want, body)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(body, wantNot) {
+					t.Errorf("body contains %q:\n%s", wantNot, body)
+				}
+			}
+		})
+	}
+}
+
+func TestGetStreamMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		accept  string
+		want    StreamMode
+		wantErr bool
+	}{
+		{name: "default", want: StreamModeSSE},
+		{name: "query ndjson", query: "stream_mode=ndjson", want: StreamModeNDJSON},
+		{name: "query json", query: "stream_mode=json", accept: "text/event-stream", want: StreamModeJSON},
+		{name: "query wins over accept", query: "stream_mode=sse", accept: "application/x-ndjson", want: StreamModeSSE},
+		{name: "invalid query", query: "stream_mode=xml", wantErr: true},
+		{name: "accept ndjson", accept: "application/json, Application/NDJSON; q=0.9", want: StreamModeNDJSON},
+		{name: "accept jsonl", accept: "application/jsonl", want: StreamModeNDJSON},
+		{name: "accept event stream", accept: "text/event-stream, application/x-ndjson", want: StreamModeSSE},
+		{name: "accept json is not accumulated", accept: "application/json", want: StreamModeSSE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctx fasthttp.RequestCtx
+			ctx.Request.SetRequestURI("/v1/chat/completions?" + tt.query)
+			if tt.accept != "" {
+				ctx.Request.Header.Set("Accept", tt.accept)
+			}
+			got, err := getStreamMode(&ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getStreamMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getStreamMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleStreamingResponseModes(t *testing.T) {
+	chunk := func(content string) *schemas.BifrostStream {
+		return &schemas.BifrostStream{BifrostResponse: &schemas.BifrostResponse{Object: "chat.completion.chunk", Choices: []schemas.BifrostResponseChoice{{
+			BifrostStreamResponseChoice: &schemas.BifrostStreamResponseChoice{Delta: schemas.BifrostStreamDelta{Content: &content}},
+		}}}}
+	}
+
+	tests := []struct {
+		name            string
+		mode            StreamMode
+		wantContentType string
+		wantBody        []string // Expected body, in order
+	}{
+		{
+			name:            "sse",
+			mode:            StreamModeSSE,
+			wantContentType: "text/event-stream",
+			wantBody:        []string{"data: {", `"content":"Hel"`, "\n\ndata: {", `"content":"lo"`, "data: [DONE]\n\n"},
+		},
+		{
+			name:            "ndjson",
+			mode:            StreamModeNDJSON,
+			wantContentType: "application/x-ndjson",
+			wantBody:        []string{"{", `"content":"Hel"`, "}\n{", `"content":"lo"`, "}\n"},
+		},
+		{
+			name:            "json",
+			mode:            StreamModeJSON,
+			wantContentType: "application/json",
+			wantBody:        []string{`"object":"chat.completion"`, `"content":"Hello"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewCompletionHandler(nil, testHandlerStore{}, bifrost.NewDefaultLogger(schemas.LogLevelError))
+			getStream := func(context.Context) (chan *schemas.BifrostStream, *schemas.BifrostError) {
+				stream := make(chan *schemas.BifrostStream, 2)
+				stream <- chunk("Hel")
+				stream <- chunk("lo")
+				close(stream)
+				return stream, nil
+			}
+
+			var ctx fasthttp.RequestCtx
+			ctx.Request.SetRequestURI("/v1/chat/completions?stream_mode=" + string(tt.mode))
+			h.handleStreamingResponse(&ctx, context.Background(), getStream, func(response *schemas.BifrostStream) (interface{}, bool) {
+				return response, true
+			})
+
+			if got := string(ctx.Response.Header.ContentType()); !strings.HasPrefix(got, tt.wantContentType) {
+				t.Errorf("content type = %q, want %q", got, tt.wantContentType)
+			}
+			body := string(ctx.Response.Body())
+			rest := body
+			for _, want := range tt.wantBody {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("body does not contain %q in order:\n%s", want, body)
+				}
+				rest = rest[i+len(want):]
+			}
+			if tt.mode == StreamModeJSON && strings.Contains(body, "[DONE]") {
+				t.Errorf("accumulated body contains a stream marker:\n%s", body)
+			}
+		})
+	}
+}
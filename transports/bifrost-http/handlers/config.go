@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"slices"
 
 	"github.com/fasthttp/router"
@@ -75,7 +76,7 @@ func (h *ConfigHandler) getConfig(ctx *fasthttp.RequestCtx) {
 }
 
 // updateConfig updates the core configuration settings.
-// Currently, it supports hot-reloading of the `drop_excess_requests` setting.
+// Currently, it supports hot-reloading of the `drop_excess_requests` and `fair_queuing` settings.
 // Note that settings like `prometheus_labels` cannot be changed at runtime.
 func (h *ConfigHandler) updateConfig(ctx *fasthttp.RequestCtx) {
 	if h.store.ConfigStore == nil {
@@ -90,6 +91,26 @@ func (h *ConfigHandler) updateConfig(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	if req.FairQueuing != nil {
+		if req.FairQueuing.DefaultWeight < 0 {
+			SendError(ctx, fasthttp.StatusBadRequest, "fair_queuing.default_weight must not be negative", h.logger)
+			return
+		}
+		for tenant, weight := range req.FairQueuing.Weights {
+			if weight <= 0 {
+				SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("fair_queuing weight of tenant %q must be positive", tenant), h.logger)
+				return
+			}
+		}
+	}
+
+	switch req.SlowStreamClientPolicy {
+	case "", lib.SlowStreamClientPolicyTerminate, lib.SlowStreamClientPolicyDegrade:
+	default:
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("slow_stream_client_policy must be %q or %q", lib.SlowStreamClientPolicyTerminate, lib.SlowStreamClientPolicyDegrade), h.logger)
+		return
+	}
+
 	// Get current config with proper locking
 	currentConfig := h.store.ClientConfig
 	updatedConfig := currentConfig
@@ -99,6 +120,11 @@ func (h *ConfigHandler) updateConfig(ctx *fasthttp.RequestCtx) {
 		updatedConfig.DropExcessRequests = req.DropExcessRequests
 	}
 
+	if !reflect.DeepEqual(req.FairQueuing, currentConfig.FairQueuing) {
+		h.client.UpdateFairQueuing(req.FairQueuing)
+		updatedConfig.FairQueuing = req.FairQueuing
+	}
+
 	if !slices.Equal(req.PrometheusLabels, currentConfig.PrometheusLabels) {
 		updatedConfig.PrometheusLabels = req.PrometheusLabels
 	}
@@ -114,6 +140,17 @@ func (h *ConfigHandler) updateConfig(ctx *fasthttp.RequestCtx) {
 	updatedConfig.AllowDirectKeys = req.AllowDirectKeys
 	updatedConfig.MaxRequestBodySizeMB = req.MaxRequestBodySizeMB
 
+	// Stream protection settings are kept when the request leaves them out
+	if req.StreamWriteTimeoutSeconds > 0 {
+		updatedConfig.StreamWriteTimeoutSeconds = req.StreamWriteTimeoutSeconds
+	}
+	if req.StreamBufferMaxChunks > 0 {
+		updatedConfig.StreamBufferMaxChunks = req.StreamBufferMaxChunks
+	}
+	if req.SlowStreamClientPolicy != "" {
+		updatedConfig.SlowStreamClientPolicy = req.SlowStreamClientPolicy
+	}
+
 	// Update the store with the new config
 	h.store.ClientConfig = updatedConfig
 
@@ -0,0 +1,78 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the provider draining handlers used for key rotation and maintenance.
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// defaultDrainTimeout bounds how long a drain request waits for in-flight requests
+const defaultDrainTimeout = 30 * time.Second
+
+// DrainResponse is the response of the drain endpoints
+type DrainResponse struct {
+	schemas.ProviderStatus
+	Message string `json:"message,omitempty"` // Set when the drain timed out with requests in flight
+}
+
+// drainProvider handles POST /api/providers/{provider}/drain - Stop routing new requests to a provider,
+// wait up to the timeout query parameter (default 30s) for in-flight requests and streams, then mark it inactive.
+// If the timeout passes first, the provider becomes inactive once the remaining requests finish.
+func (h *ProviderHandler) drainProvider(ctx *fasthttp.RequestCtx) {
+	provider, err := getProviderFromCtx(ctx)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid provider: %v", err), h.logger)
+		return
+	}
+	if _, err := h.store.GetProviderConfigRedacted(provider); err != nil {
+		SendError(ctx, fasthttp.StatusNotFound, fmt.Sprintf("Provider not found: %v", err), h.logger)
+		return
+	}
+
+	timeout := defaultDrainTimeout
+	if value := string(ctx.QueryArgs().Peek("timeout")); value != "" {
+		timeout, err = time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid timeout: %s", value), h.logger)
+			return
+		}
+	}
+
+	drainErr := h.client.DrainProvider(provider, time.Now().Add(timeout))
+	response := DrainResponse{ProviderStatus: h.client.GetProviderStatus(provider)}
+	if drainErr != nil {
+		h.logger.Warn(fmt.Sprintf("Drain of provider %s incomplete: %v", provider, drainErr))
+		response.Message = drainErr.Error()
+	}
+	SendJSON(ctx, response, h.logger)
+}
+
+// activateProvider handles POST /api/providers/{provider}/activate - Let an inactive provider accept requests again
+func (h *ProviderHandler) activateProvider(ctx *fasthttp.RequestCtx) {
+	provider, err := getProviderFromCtx(ctx)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid provider: %v", err), h.logger)
+		return
+	}
+	if err := h.client.ActivateProvider(provider); err != nil {
+		SendError(ctx, fasthttp.StatusConflict, err.Error(), h.logger)
+		return
+	}
+
+	SendJSON(ctx, DrainResponse{ProviderStatus: h.client.GetProviderStatus(provider)}, h.logger)
+}
+
+// getProviderStatus handles GET /api/providers/{provider}/status - Get the drain state and in-flight requests of a provider
+func (h *ProviderHandler) getProviderStatus(ctx *fasthttp.RequestCtx) {
+	provider, err := getProviderFromCtx(ctx)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid provider: %v", err), h.logger)
+		return
+	}
+
+	SendJSON(ctx, DrainResponse{ProviderStatus: h.client.GetProviderStatus(provider)}, h.logger)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/maximhq/bifrost/transports/bifrost-http/integrations/genai"
 	"github.com/maximhq/bifrost/transports/bifrost-http/integrations/langchain"
 	"github.com/maximhq/bifrost/transports/bifrost-http/integrations/litellm"
+	"github.com/maximhq/bifrost/transports/bifrost-http/integrations/ollama"
 	"github.com/maximhq/bifrost/transports/bifrost-http/integrations/openai"
 	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
 )
@@ -28,6 +29,7 @@ func NewIntegrationHandler(client *bifrost.Bifrost, handlerStore lib.HandlerStor
 		genai.NewGenAIRouter(client, handlerStore),
 		litellm.NewLiteLLMRouter(client, handlerStore),
 		langchain.NewLangChainRouter(client, handlerStore),
+		ollama.NewOllamaRouter(client, handlerStore),
 	}
 
 	return &IntegrationHandler{
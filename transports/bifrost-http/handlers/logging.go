@@ -3,6 +3,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -35,6 +36,8 @@ func (h *LoggingHandler) RegisterRoutes(r *router.Router) {
 	r.GET("/api/logs", h.getLogs)
 	r.GET("/api/logs/dropped", h.getDroppedRequests)
 	r.GET("/api/logs/models", h.getAvailableModels)
+	r.GET("/api/logs/sampling", h.getSamplingConfig)
+	r.PUT("/api/logs/sampling", h.updateSamplingConfig)
 }
 
 // getLogs handles GET /api/logs - Get logs with filtering, search, and pagination via query parameters
@@ -163,6 +166,35 @@ func (h *LoggingHandler) getAvailableModels(ctx *fasthttp.RequestCtx) {
 	SendJSON(ctx, map[string]interface{}{"models": models}, h.logger)
 }
 
+// getSamplingConfig handles GET /api/logs/sampling - Get which requests are logged with their content
+func (h *LoggingHandler) getSamplingConfig(ctx *fasthttp.RequestCtx) {
+	config, err := h.logManager.GetSamplingConfig()
+	if err != nil {
+		SendError(ctx, fasthttp.StatusConflict, err.Error(), h.logger)
+		return
+	}
+	SendJSON(ctx, config, h.logger)
+}
+
+// updateSamplingConfig handles PUT /api/logs/sampling - Replace which requests are logged with their
+// content. The change applies to the requests that start afterwards and is not persisted.
+func (h *LoggingHandler) updateSamplingConfig(ctx *fasthttp.RequestCtx) {
+	var config logging.SamplingConfig
+	if err := json.Unmarshal(ctx.PostBody(), &config); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid request format: %v", err), h.logger)
+		return
+	}
+	if err := config.Validate(); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+	if err := h.logManager.SetSamplingConfig(config); err != nil {
+		SendError(ctx, fasthttp.StatusConflict, err.Error(), h.logger)
+		return
+	}
+	SendJSON(ctx, config, h.logger)
+}
+
 // Helper functions
 
 // parseCommaSeparated splits a comma-separated string into a slice
@@ -0,0 +1,471 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the provider key probing handler used to bootstrap provider configs.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/valyala/fasthttp"
+)
+
+// probeTimeout bounds each network call made while probing a key
+const probeTimeout = 20 * time.Second
+
+// modelListEndpoint describes how to list the models available to a key
+type modelListEndpoint struct {
+	baseURL string // Default base URL, the probe request's network config overrides it
+	path    string
+	auth    func(req *fasthttp.Request, key string)
+}
+
+func bearerAuth(req *fasthttp.Request, key string) {
+	req.Header.Set("Authorization", "Bearer "+key)
+}
+
+// modelListEndpoints lists the model listing endpoints of the providers that have one.
+// Azure, Bedrock, Vertex, Perplexity, Replicate, Zhipu, Qianfan, watsonx, Stability, BFL and Luma keys have to be probed with explicit models.
+var modelListEndpoints = map[schemas.ModelProvider]modelListEndpoint{
+	schemas.OpenAI:     {baseURL: "https://api.openai.com", path: "/v1/models", auth: bearerAuth},
+	schemas.Mistral:    {baseURL: "https://api.mistral.ai", path: "/v1/models", auth: bearerAuth},
+	schemas.Groq:       {baseURL: "https://api.groq.com/openai", path: "/v1/models", auth: bearerAuth},
+	schemas.Cerebras:   {baseURL: "https://api.cerebras.ai", path: "/v1/models", auth: bearerAuth},
+	schemas.Parasail:   {baseURL: "https://api.parasail.io", path: "/v1/models", auth: bearerAuth},
+	schemas.OpenRouter: {baseURL: "https://openrouter.ai/api", path: "/v1/models", auth: bearerAuth},
+	schemas.DeepSeek:   {baseURL: "https://api.deepseek.com", path: "/models", auth: bearerAuth},
+	schemas.Together:   {baseURL: "https://api.together.xyz", path: "/v1/models", auth: bearerAuth},
+	schemas.Qwen:       {baseURL: "https://dashscope-intl.aliyuncs.com", path: "/compatible-mode/v1/models", auth: bearerAuth},
+	schemas.Moonshot:   {baseURL: "https://api.moonshot.ai", path: "/v1/models", auth: bearerAuth},
+	schemas.SambaNova:  {baseURL: "https://api.sambanova.ai", path: "/v1/models", auth: bearerAuth},
+	schemas.NIM:        {baseURL: "https://integrate.api.nvidia.com", path: "/v1/models", auth: bearerAuth},
+	schemas.Cohere:     {baseURL: "https://api.cohere.ai", path: "/v1/models", auth: bearerAuth},
+	schemas.SGL:        {path: "/v1/models", auth: bearerAuth},
+	schemas.Ollama:     {path: "/api/tags"},
+	schemas.Anthropic: {baseURL: "https://api.anthropic.com", path: "/v1/models", auth: func(req *fasthttp.Request, key string) {
+		req.Header.Set("x-api-key", key)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	}},
+	schemas.Gemini: {baseURL: "https://generativelanguage.googleapis.com/v1beta", path: "/models", auth: func(req *fasthttp.Request, key string) {
+		req.Header.Set("x-goog-api-key", key)
+	}},
+}
+
+// probeDefaultModels are known-good models probed when the key lists them, so that the probe does
+// not depend on the position of a model in the sorted list (e.g. OpenAI lists babbage-002 first).
+var probeDefaultModels = map[schemas.ModelProvider]struct{ chat, embedding string }{
+	schemas.OpenAI:    {chat: "gpt-4o-mini", embedding: "text-embedding-3-small"},
+	schemas.Anthropic: {chat: "claude-3-5-haiku-latest"},
+	schemas.Mistral:   {chat: "mistral-small-latest", embedding: "mistral-embed"},
+	schemas.Groq:      {chat: "llama-3.1-8b-instant"},
+	schemas.Cerebras:  {chat: "llama3.1-8b"},
+	schemas.DeepSeek:  {chat: "deepseek-chat"},
+	schemas.Gemini:    {chat: "gemini-2.0-flash", embedding: "text-embedding-004"},
+	schemas.Cohere:    {chat: "command-r", embedding: "embed-english-v3.0"},
+	schemas.Qwen:      {chat: "qwen-turbo", embedding: "text-embedding-v3"},
+	schemas.Moonshot:  {chat: "moonshot-v1-8k"},
+}
+
+// ProbeRequest is the payload of POST /api/providers/probe
+type ProbeRequest struct {
+	Provider      schemas.ModelProvider  `json:"provider"`
+	Key           schemas.Key            `json:"key"`                      // Key to probe, Models limits the probed models
+	NetworkConfig *schemas.NetworkConfig `json:"network_config,omitempty"` // Required for self-hosted providers such as Ollama and SGL, see checkProbeBaseURL
+	KeyEnvVar     string                 `json:"key_env_var,omitempty"`    // Env var referenced by the generated config, defaults to <PROVIDER>_API_KEY
+}
+
+// ProbeCheck is the outcome of a single probe step
+type ProbeCheck struct {
+	OK        bool   `json:"ok"`
+	Model     string `json:"model,omitempty"`
+	LatencyMs int64  `json:"latency_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ProbeCapabilities are the capabilities inferred for a key
+type ProbeCapabilities struct {
+	ChatCompletion  bool     `json:"chat_completion"`
+	Embedding       bool     `json:"embedding"`
+	Speech          bool     `json:"speech"`
+	Transcription   bool     `json:"transcription"`
+	ChatModels      []string `json:"chat_models,omitempty"`
+	EmbeddingModels []string `json:"embedding_models,omitempty"`
+}
+
+// ProbeResponse is the result of probing a key, including a ready-to-use provider config
+type ProbeResponse struct {
+	Provider     schemas.ModelProvider      `json:"provider"`
+	Models       []string                   `json:"models"`
+	ListModels   *ProbeCheck                `json:"list_models,omitempty"` // Nil when the provider has no model listing endpoint
+	Chat         *ProbeCheck                `json:"chat,omitempty"`
+	Embedding    *ProbeCheck                `json:"embedding,omitempty"`
+	Capabilities ProbeCapabilities          `json:"capabilities"`
+	Config       configstore.ProviderConfig `json:"config"`      // Provider config with the key value replaced by an env reference
+	KeyEnvVar    string                     `json:"key_env_var"` // Env var the generated config reads the key from
+}
+
+// probeProvider handles POST /api/providers/probe - Probe a key and generate its provider config.
+// The key is listed for models, a minimal chat (and embedding, when an embedding model is found)
+// request is sent through a temporary Bifrost client, and capabilities are inferred from the
+// results. The generated config references secrets through env vars, so credentials are
+// never echoed back, and nothing is stored.
+func (h *ProviderHandler) probeProvider(ctx *fasthttp.RequestCtx) {
+	var req ProbeRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err), h.logger)
+		return
+	}
+	if req.Provider == "" {
+		SendError(ctx, fasthttp.StatusBadRequest, "Missing provider", h.logger)
+		return
+	}
+	if !bifrost.IsStandardProvider(req.Provider) {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Provider %s cannot be probed, only standard providers are supported", req.Provider), h.logger)
+		return
+	}
+	if req.NetworkConfig != nil && req.NetworkConfig.BaseURL != "" {
+		if err := checkProbeBaseURL(req.NetworkConfig.BaseURL, h.probeBaseURLs(req.Provider)); err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, err.Error(), h.logger)
+			return
+		}
+	}
+
+	resp := ProbeResponse{
+		Provider:  req.Provider,
+		Models:    req.Key.Models,
+		KeyEnvVar: req.KeyEnvVar,
+	}
+	if resp.KeyEnvVar == "" {
+		resp.KeyEnvVar = strings.ToUpper(strings.ReplaceAll(string(req.Provider), "-", "_")) + "_API_KEY"
+	}
+
+	// List models unless the caller restricted the probe to specific models
+	if len(resp.Models) == 0 {
+		models, check := listProviderModels(req)
+		resp.ListModels = check
+		resp.Models = models
+	}
+	if len(resp.Models) == 0 {
+		SendJSON(ctx, finishProbe(resp, req), h.logger)
+		return
+	}
+
+	chatModels, embeddingModels := classifyModels(resp.Models)
+	resp.Capabilities.ChatModels = chatModels
+	resp.Capabilities.EmbeddingModels = embeddingModels
+
+	client, err := newProbeClient(req, h.logger)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Failed to create probe client: %v", err), h.logger)
+		return
+	}
+	defer client.Shutdown()
+
+	defaults := probeDefaultModels[req.Provider]
+	if len(chatModels) > 0 {
+		resp.Chat = probeChat(client, req.Provider, pickProbeModel(chatModels, defaults.chat))
+	}
+	if len(embeddingModels) > 0 {
+		resp.Embedding = probeEmbedding(client, req.Provider, pickProbeModel(embeddingModels, defaults.embedding))
+	}
+
+	SendJSON(ctx, finishProbe(resp, req), h.logger)
+}
+
+// probeBaseURLs returns the base URLs a probe may target for a provider: its default model
+// listing URL and the base URL of the provider's stored config.
+func (h *ProviderHandler) probeBaseURLs(provider schemas.ModelProvider) []string {
+	var allowed []string
+	if endpoint, ok := modelListEndpoints[provider]; ok && endpoint.baseURL != "" {
+		allowed = append(allowed, endpoint.baseURL)
+	}
+	if config, err := h.store.GetProviderConfigRaw(provider); err == nil && config.NetworkConfig != nil && config.NetworkConfig.BaseURL != "" {
+		allowed = append(allowed, config.NetworkConfig.BaseURL)
+	}
+	return allowed
+}
+
+// checkProbeBaseURL rejects a caller supplied base URL that is not one of the allowed URLs. The
+// probe sends the key to this URL from the gateway's network, so arbitrary URLs would let callers
+// reach internal services; self-hosted providers have to be configured before they are probed.
+func checkProbeBaseURL(baseURL string, allowed []string) error {
+	normalized := strings.TrimRight(baseURL, "/")
+	for _, url := range allowed {
+		if strings.EqualFold(normalized, strings.TrimRight(url, "/")) {
+			return nil
+		}
+	}
+	return fmt.Errorf("network_config.base_url %s is not allowed, probes can only target the provider's default or configured base URL", baseURL)
+}
+
+// pickProbeModel returns the preferred model when it is a candidate, otherwise the first candidate
+func pickProbeModel(candidates []string, preferred string) string {
+	if preferred != "" && slices.Contains(candidates, preferred) {
+		return preferred
+	}
+	return candidates[0]
+}
+
+// finishProbe infers capabilities from the probe results and builds the provider config
+func finishProbe(resp ProbeResponse, req ProbeRequest) ProbeResponse {
+	resp.Capabilities.ChatCompletion = resp.Chat != nil && resp.Chat.OK
+	resp.Capabilities.Embedding = resp.Embedding != nil && resp.Embedding.OK
+	for _, model := range resp.Models {
+		lower := strings.ToLower(model)
+		if strings.Contains(lower, "tts") {
+			resp.Capabilities.Speech = true
+		}
+		if strings.Contains(lower, "whisper") || strings.Contains(lower, "transcribe") {
+			resp.Capabilities.Transcription = true
+		}
+	}
+
+	resp.Config = configstore.ProviderConfig{
+		Keys:          []schemas.Key{probeConfigKey(req.Key, resp)},
+		NetworkConfig: req.NetworkConfig,
+	}
+	return resp
+}
+
+// probeConfigKey returns the probed key for the generated config, with every secret
+// replaced by an env reference so the response never contains credentials.
+func probeConfigKey(probed schemas.Key, resp ProbeResponse) schemas.Key {
+	key := schemas.Key{
+		Models: resp.Models,
+		Weight: probed.Weight,
+	}
+	if key.Weight == 0 {
+		key.Weight = 1.0
+	}
+	if resp.ListModels != nil && resp.ListModels.OK {
+		// Models listed by the provider are all usable, leave the key unrestricted
+		key.Models = []string{}
+	}
+	if probed.Value != "" {
+		key.Value = "env." + resp.KeyEnvVar
+	}
+
+	envPrefix := strings.TrimSuffix(resp.KeyEnvVar, "_API_KEY")
+	if probed.AzureKeyConfig != nil {
+		azure := *probed.AzureKeyConfig
+		key.AzureKeyConfig = &azure
+	}
+	if probed.VertexKeyConfig != nil {
+		vertex := *probed.VertexKeyConfig
+		if vertex.AuthCredentials != "" {
+			vertex.AuthCredentials = "env." + envPrefix + "_CREDENTIALS"
+		}
+		key.VertexKeyConfig = &vertex
+	}
+	if probed.BedrockKeyConfig != nil {
+		bedrock := *probed.BedrockKeyConfig
+		if bedrock.AccessKey != "" {
+			bedrock.AccessKey = "env.AWS_ACCESS_KEY_ID"
+		}
+		if bedrock.SecretKey != "" {
+			bedrock.SecretKey = "env.AWS_SECRET_ACCESS_KEY"
+		}
+		if bedrock.SessionToken != nil {
+			bedrock.SessionToken = bifrost.Ptr("env.AWS_SESSION_TOKEN")
+		}
+		key.BedrockKeyConfig = &bedrock
+	}
+	return key
+}
+
+// listProviderModels lists the models available to the probed key
+func listProviderModels(req ProbeRequest) ([]string, *ProbeCheck) {
+	endpoint, ok := modelListEndpoints[req.Provider]
+	if !ok {
+		return nil, nil
+	}
+
+	baseURL := endpoint.baseURL
+	if req.NetworkConfig != nil && req.NetworkConfig.BaseURL != "" {
+		baseURL = req.NetworkConfig.BaseURL
+	}
+	if baseURL == "" {
+		return nil, &ProbeCheck{Error: "network_config.base_url is required for this provider"}
+	}
+
+	httpReq := fasthttp.AcquireRequest()
+	httpResp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(httpReq)
+	defer fasthttp.ReleaseResponse(httpResp)
+
+	httpReq.SetRequestURI(strings.TrimRight(baseURL, "/") + endpoint.path)
+	httpReq.Header.SetMethod(fasthttp.MethodGet)
+	if endpoint.auth != nil && req.Key.Value != "" {
+		endpoint.auth(httpReq, req.Key.Value)
+	}
+
+	start := time.Now()
+	err := fasthttp.DoTimeout(httpReq, httpResp, probeTimeout)
+	check := &ProbeCheck{LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		check.Error = err.Error()
+		return nil, check
+	}
+	if httpResp.StatusCode() != fasthttp.StatusOK {
+		check.Error = fmt.Sprintf("status %d: %s", httpResp.StatusCode(), truncate(string(httpResp.Body()), 300))
+		return nil, check
+	}
+
+	models, err := parseModelList(httpResp.Body())
+	if err != nil {
+		check.Error = err.Error()
+		return nil, check
+	}
+	check.OK = true
+	return models, check
+}
+
+// parseModelList extracts model IDs from the common model listing shapes:
+// {"data": [{"id": ...}]} (OpenAI compatible) and {"models": [{"name": ...}]} (Gemini, Cohere, Ollama).
+func parseModelList(body []byte) ([]string, error) {
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+		Models []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %v", err)
+	}
+
+	var models []string
+	for _, model := range list.Data {
+		models = append(models, model.ID)
+	}
+	for _, model := range list.Models {
+		id := model.ID
+		if id == "" {
+			id = strings.TrimPrefix(model.Name, "models/")
+		}
+		models = append(models, id)
+	}
+	slices.Sort(models)
+	return slices.Compact(models), nil
+}
+
+// classifyModels splits model IDs into chat and embedding candidates by name, skipping
+// audio, image, moderation and legacy completion models that cannot answer a chat probe.
+func classifyModels(models []string) (chatModels []string, embeddingModels []string) {
+	for _, model := range models {
+		lower := strings.ToLower(model)
+		switch {
+		case strings.Contains(lower, "embed"):
+			embeddingModels = append(embeddingModels, model)
+		case strings.Contains(lower, "tts"), strings.Contains(lower, "whisper"), strings.Contains(lower, "transcribe"),
+			strings.Contains(lower, "dall-e"), strings.Contains(lower, "image"), strings.Contains(lower, "moderation"),
+			strings.Contains(lower, "realtime"), strings.Contains(lower, "audio"), strings.Contains(lower, "rerank"),
+			strings.Contains(lower, "babbage"), strings.Contains(lower, "davinci"), strings.Contains(lower, "turbo-instruct"):
+			continue
+		default:
+			chatModels = append(chatModels, model)
+		}
+	}
+	return chatModels, embeddingModels
+}
+
+// probeAccount is a single key account used by the temporary probe client
+type probeAccount struct {
+	provider      schemas.ModelProvider
+	key           schemas.Key
+	networkConfig *schemas.NetworkConfig
+}
+
+func (a *probeAccount) GetConfiguredProviders() ([]schemas.ModelProvider, error) {
+	return []schemas.ModelProvider{a.provider}, nil
+}
+
+func (a *probeAccount) GetKeysForProvider(ctx *context.Context, providerKey schemas.ModelProvider) ([]schemas.Key, error) {
+	return []schemas.Key{a.key}, nil
+}
+
+func (a *probeAccount) GetConfigForProvider(providerKey schemas.ModelProvider) (*schemas.ProviderConfig, error) {
+	config := &schemas.ProviderConfig{
+		NetworkConfig:            schemas.DefaultNetworkConfig,
+		ConcurrencyAndBufferSize: schemas.ConcurrencyAndBufferSize{Concurrency: 1, BufferSize: 2},
+	}
+	if a.networkConfig != nil {
+		config.NetworkConfig = *a.networkConfig
+	}
+	config.CheckAndSetDefaults()
+	return config, nil
+}
+
+// newProbeClient creates a temporary Bifrost client serving only the probed key
+func newProbeClient(req ProbeRequest, logger schemas.Logger) (*bifrost.Bifrost, error) {
+	key := req.Key
+	key.Models = []string{} // Allow every model, the probe picks the models itself
+	if key.Weight == 0 {
+		key.Weight = 1.0
+	}
+
+	return bifrost.Init(context.Background(), schemas.BifrostConfig{
+		Account: &probeAccount{provider: req.Provider, key: key, networkConfig: req.NetworkConfig},
+		Logger:  logger,
+	})
+}
+
+// probeChat sends a minimal chat completion request
+func probeChat(client *bifrost.Bifrost, provider schemas.ModelProvider, model string) *ProbeCheck {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, bifrostErr := client.ChatCompletionRequest(ctx, &schemas.BifrostRequest{
+		Provider: provider,
+		Model:    model,
+		Input: schemas.RequestInput{
+			ChatCompletionInput: &[]schemas.BifrostMessage{{
+				Role:    schemas.ModelChatMessageRoleUser,
+				Content: schemas.MessageContent{ContentStr: bifrost.Ptr("Reply with OK.")},
+			}},
+		},
+		Params: &schemas.ModelParameters{MaxTokens: bifrost.Ptr(5)},
+	})
+	return probeCheck(model, start, bifrostErr)
+}
+
+// probeEmbedding sends a minimal embedding request
+func probeEmbedding(client *bifrost.Bifrost, provider schemas.ModelProvider, model string) *ProbeCheck {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, bifrostErr := client.EmbeddingRequest(ctx, &schemas.BifrostRequest{
+		Provider: provider,
+		Model:    model,
+		Input: schemas.RequestInput{
+			EmbeddingInput: &schemas.EmbeddingInput{Text: bifrost.Ptr("ok")},
+		},
+	})
+	return probeCheck(model, start, bifrostErr)
+}
+
+func probeCheck(model string, start time.Time, bifrostErr *schemas.BifrostError) *ProbeCheck {
+	check := &ProbeCheck{Model: model, LatencyMs: time.Since(start).Milliseconds(), OK: bifrostErr == nil}
+	if bifrostErr != nil {
+		check.Error = bifrostErr.Error.Message
+	}
+	return check
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
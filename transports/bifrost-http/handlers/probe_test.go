@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/maximhq/bifrost/core/schemas"
+)
+
+func TestClassifyModels(t *testing.T) {
+	models := []string{
+		"babbage-002", "dall-e-3", "davinci-002", "gpt-3.5-turbo-instruct", "gpt-4o", "gpt-4o-mini",
+		"gpt-4o-realtime-preview", "meta-llama-3.1-8b-instruct", "omni-moderation-latest",
+		"text-embedding-3-small", "tts-1", "whisper-1",
+	}
+	chatModels, embeddingModels := classifyModels(models)
+
+	wantChat := []string{"gpt-4o", "gpt-4o-mini", "meta-llama-3.1-8b-instruct"}
+	if !slices.Equal(chatModels, wantChat) {
+		t.Errorf("chat models = %v, want %v", chatModels, wantChat)
+	}
+	wantEmbedding := []string{"text-embedding-3-small"}
+	if !slices.Equal(embeddingModels, wantEmbedding) {
+		t.Errorf("embedding models = %v, want %v", embeddingModels, wantEmbedding)
+	}
+}
+
+func TestPickProbeModel(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		preferred  string
+		want       string
+	}{
+		{name: "preferred listed", candidates: []string{"chatgpt-4o-latest", "gpt-4o", "gpt-4o-mini"}, preferred: "gpt-4o-mini", want: "gpt-4o-mini"},
+		{name: "preferred not listed", candidates: []string{"chatgpt-4o-latest", "gpt-4o"}, preferred: "gpt-4o-mini", want: "chatgpt-4o-latest"},
+		{name: "no preferred model", candidates: []string{"llama3.2", "qwen2.5"}, want: "llama3.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickProbeModel(tt.candidates, tt.preferred); got != tt.want {
+				t.Errorf("pickProbeModel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckProbeBaseURL(t *testing.T) {
+	allowed := []string{"https://api.openai.com", "http://ollama.internal:11434/"}
+	tests := []struct {
+		baseURL string
+		wantErr bool
+	}{
+		{baseURL: "https://api.openai.com"},
+		{baseURL: "https://api.openai.com/"},
+		{baseURL: "HTTPS://API.OPENAI.COM"},
+		{baseURL: "http://ollama.internal:11434"},
+		{baseURL: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{baseURL: "http://localhost:8080", wantErr: true},
+		{baseURL: "https://api.openai.com.attacker.example", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			if err := checkProbeBaseURL(tt.baseURL, allowed); (err != nil) != tt.wantErr {
+				t.Errorf("checkProbeBaseURL(%q) error = %v, want error %v", tt.baseURL, err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := checkProbeBaseURL("http://localhost:11434", nil); err == nil {
+		t.Error("checkProbeBaseURL() allowed a URL for a provider without allowed URLs")
+	}
+}
+
+func TestParseModelList(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "openai compatible", body: `{"data":[{"id":"gpt-4o"},{"id":"babbage-002"}]}`, want: []string{"babbage-002", "gpt-4o"}},
+		{name: "gemini", body: `{"models":[{"name":"models/gemini-2.0-flash"},{"name":"models/text-embedding-004"}]}`, want: []string{"gemini-2.0-flash", "text-embedding-004"}},
+		{name: "duplicates", body: `{"data":[{"id":"a"},{"id":"a"}]}`, want: []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseModelList([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("parseModelList() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseModelList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListProviderModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"gpt-4o-mini"},{"id":"babbage-002"}]}`))
+	}))
+	defer server.Close()
+
+	models, check := listProviderModels(ProbeRequest{
+		Provider:      schemas.OpenAI,
+		Key:           schemas.Key{Value: "sk-test"},
+		NetworkConfig: &schemas.NetworkConfig{BaseURL: server.URL},
+	})
+	if check == nil || !check.OK {
+		t.Fatalf("listProviderModels() check = %+v, want OK", check)
+	}
+	if want := []string{"babbage-002", "gpt-4o-mini"}; !slices.Equal(models, want) {
+		t.Errorf("listProviderModels() = %v, want %v", models, want)
+	}
+
+	chatModels, _ := classifyModels(models)
+	if got := pickProbeModel(chatModels, probeDefaultModels[schemas.OpenAI].chat); got != "gpt-4o-mini" {
+		t.Errorf("probed chat model = %q, want gpt-4o-mini", got)
+	}
+
+	_, check = listProviderModels(ProbeRequest{
+		Provider:      schemas.OpenAI,
+		Key:           schemas.Key{Value: "sk-wrong"},
+		NetworkConfig: &schemas.NetworkConfig{BaseURL: server.URL},
+	})
+	if check == nil || check.OK || check.Error == "" {
+		t.Errorf("listProviderModels() with a wrong key check = %+v, want an error", check)
+	}
+}
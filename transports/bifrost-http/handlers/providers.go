@@ -42,6 +42,7 @@ type ProviderResponse struct {
 	NetworkConfig            schemas.NetworkConfig            `json:"network_config"`                   // Network-related settings
 	ConcurrencyAndBufferSize schemas.ConcurrencyAndBufferSize `json:"concurrency_and_buffer_size"`      // Concurrency settings
 	ProxyConfig              *schemas.ProxyConfig             `json:"proxy_config"`                     // Proxy configuration
+	HTTPClientProfile        *schemas.HTTPClientProfile       `json:"http_client_profile,omitempty"`    // HTTP client profile
 	SendBackRawResponse      bool                             `json:"send_back_raw_response"`           // Include raw response in BifrostResponse
 	CustomProviderConfig     *schemas.CustomProviderConfig    `json:"custom_provider_config,omitempty"` // Custom provider configuration
 }
@@ -66,6 +67,12 @@ func (h *ProviderHandler) RegisterRoutes(r *router.Router) {
 	r.POST("/api/providers", h.addProvider)
 	r.PUT("/api/providers/{provider}", h.updateProvider)
 	r.DELETE("/api/providers/{provider}", h.deleteProvider)
+	r.POST("/api/providers/probe", h.probeProvider)
+	r.GET("/api/providers/queues", h.getQueues)
+	r.GET("/api/providers/{provider}/status", h.getProviderStatus)
+	r.POST("/api/providers/{provider}/drain", h.drainProvider)
+	r.POST("/api/providers/{provider}/activate", h.activateProvider)
+	r.GET("/api/providers/{provider}/queue", h.getProviderQueue)
 	r.GET("/api/keys", h.listKeys)
 }
 
@@ -134,6 +141,7 @@ func (h *ProviderHandler) addProvider(ctx *fasthttp.RequestCtx) {
 		NetworkConfig            *schemas.NetworkConfig            `json:"network_config,omitempty"`              // Network-related settings
 		ConcurrencyAndBufferSize *schemas.ConcurrencyAndBufferSize `json:"concurrency_and_buffer_size,omitempty"` // Concurrency settings
 		ProxyConfig              *schemas.ProxyConfig              `json:"proxy_config,omitempty"`                // Proxy configuration
+		HTTPClientProfile        *schemas.HTTPClientProfile        `json:"http_client_profile,omitempty"`         // HTTP client profile
 		SendBackRawResponse      *bool                             `json:"send_back_raw_response,omitempty"`      // Include raw response in BifrostResponse
 		CustomProviderConfig     *schemas.CustomProviderConfig     `json:"custom_provider_config,omitempty"`      // Custom provider configuration
 	}{}
@@ -184,6 +192,11 @@ func (h *ProviderHandler) addProvider(ctx *fasthttp.RequestCtx) {
 		}
 	}
 
+	if err := payload.HTTPClientProfile.Validate(); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid http client profile: %v", err), h.logger)
+		return
+	}
+
 	// Check if provider already exists
 	if _, err := h.store.GetProviderConfigRedacted(payload.Provider); err == nil {
 		SendError(ctx, fasthttp.StatusConflict, fmt.Sprintf("Provider %s already exists", payload.Provider), h.logger)
@@ -195,6 +208,7 @@ func (h *ProviderHandler) addProvider(ctx *fasthttp.RequestCtx) {
 		Keys:                     payload.Keys,
 		NetworkConfig:            payload.NetworkConfig,
 		ProxyConfig:              payload.ProxyConfig,
+		HTTPClientProfile:        payload.HTTPClientProfile,
 		ConcurrencyAndBufferSize: payload.ConcurrencyAndBufferSize,
 		SendBackRawResponse:      payload.SendBackRawResponse != nil && *payload.SendBackRawResponse,
 		CustomProviderConfig:     payload.CustomProviderConfig,
@@ -218,6 +232,7 @@ func (h *ProviderHandler) addProvider(ctx *fasthttp.RequestCtx) {
 			NetworkConfig:            config.NetworkConfig,
 			ConcurrencyAndBufferSize: config.ConcurrencyAndBufferSize,
 			ProxyConfig:              config.ProxyConfig,
+			HTTPClientProfile:        config.HTTPClientProfile,
 			SendBackRawResponse:      config.SendBackRawResponse,
 			CustomProviderConfig:     config.CustomProviderConfig,
 		})
@@ -248,6 +263,7 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 		NetworkConfig            schemas.NetworkConfig            `json:"network_config"`                   // Network-related settings
 		ConcurrencyAndBufferSize schemas.ConcurrencyAndBufferSize `json:"concurrency_and_buffer_size"`      // Concurrency settings
 		ProxyConfig              *schemas.ProxyConfig             `json:"proxy_config,omitempty"`           // Proxy configuration
+		HTTPClientProfile        *schemas.HTTPClientProfile       `json:"http_client_profile,omitempty"`    // HTTP client profile
 		SendBackRawResponse      *bool                            `json:"send_back_raw_response,omitempty"` // Include raw response in BifrostResponse
 		CustomProviderConfig     *schemas.CustomProviderConfig    `json:"custom_provider_config,omitempty"` // Custom provider configuration
 	}{}
@@ -290,6 +306,7 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 		NetworkConfig:            oldConfigRaw.NetworkConfig,
 		ConcurrencyAndBufferSize: oldConfigRaw.ConcurrencyAndBufferSize,
 		ProxyConfig:              oldConfigRaw.ProxyConfig,
+		HTTPClientProfile:        oldConfigRaw.HTTPClientProfile,
 		CustomProviderConfig:     oldConfigRaw.CustomProviderConfig,
 	}
 
@@ -338,6 +355,11 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	if err := payload.HTTPClientProfile.Validate(); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid http client profile: %v", err), h.logger)
+		return
+	}
+
 	// Build a prospective config with the requested CustomProviderConfig (including nil)
 	prospective := config
 	prospective.CustomProviderConfig = payload.CustomProviderConfig
@@ -349,6 +371,7 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 	config.ConcurrencyAndBufferSize = &payload.ConcurrencyAndBufferSize
 	config.NetworkConfig = &payload.NetworkConfig
 	config.ProxyConfig = payload.ProxyConfig
+	config.HTTPClientProfile = payload.HTTPClientProfile
 	config.CustomProviderConfig = payload.CustomProviderConfig
 	if payload.SendBackRawResponse != nil {
 		config.SendBackRawResponse = *payload.SendBackRawResponse
@@ -392,6 +415,7 @@ func (h *ProviderHandler) updateProvider(ctx *fasthttp.RequestCtx) {
 			NetworkConfig:            config.NetworkConfig,
 			ConcurrencyAndBufferSize: config.ConcurrencyAndBufferSize,
 			ProxyConfig:              config.ProxyConfig,
+			HTTPClientProfile:        config.HTTPClientProfile,
 			SendBackRawResponse:      config.SendBackRawResponse,
 			CustomProviderConfig:     config.CustomProviderConfig,
 		})
@@ -594,6 +618,7 @@ func (h *ProviderHandler) getProviderResponseFromConfig(provider schemas.ModelPr
 		NetworkConfig:            *config.NetworkConfig,
 		ConcurrencyAndBufferSize: *config.ConcurrencyAndBufferSize,
 		ProxyConfig:              config.ProxyConfig,
+		HTTPClientProfile:        config.HTTPClientProfile,
 		SendBackRawResponse:      config.SendBackRawResponse,
 		CustomProviderConfig:     config.CustomProviderConfig,
 	}
@@ -0,0 +1,42 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the provider queue handler exposing the per-tenant queues of fair queuing.
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/valyala/fasthttp"
+)
+
+// QueuesResponse is the response of the queue endpoints
+type QueuesResponse struct {
+	FairQueuing bool                       `json:"fair_queuing"` // Whether new requests are fair queued
+	Tenants     []schemas.TenantQueueStats `json:"tenants"`
+}
+
+// getQueues handles GET /api/providers/queues - Get the fair queue of every tenant of every provider
+func (h *ProviderHandler) getQueues(ctx *fasthttp.RequestCtx) {
+	h.sendQueues(ctx, "")
+}
+
+// getProviderQueue handles GET /api/providers/{provider}/queue - Get the fair queue of every tenant of a provider
+func (h *ProviderHandler) getProviderQueue(ctx *fasthttp.RequestCtx) {
+	provider, err := getProviderFromCtx(ctx)
+	if err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid provider: %v", err), h.logger)
+		return
+	}
+	h.sendQueues(ctx, provider)
+}
+
+func (h *ProviderHandler) sendQueues(ctx *fasthttp.RequestCtx, provider schemas.ModelProvider) {
+	tenants := h.client.GetTenantQueueStats(provider)
+	if tenants == nil {
+		tenants = []schemas.TenantQueueStats{}
+	}
+	SendJSON(ctx, QueuesResponse{
+		FairQueuing: h.store.ClientConfig.FairQueuing != nil,
+		Tenants:     tenants,
+	}, h.logger)
+}
@@ -0,0 +1,401 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the export and import of gateway state snapshots, used to move the runtime
+// state of one control plane to another in blue/green migrations.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fasthttp/router"
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/plugins/governance"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+// StateSnapshotVersion is the version of the snapshots written by the export endpoint. Import
+// accepts snapshots up to this version.
+const StateSnapshotVersion = 1
+
+// errDryRun rolls back the transaction of a dry-run import.
+var errDryRun = errors.New("dry run")
+
+// StateHandler manages HTTP requests for exporting and importing gateway state
+type StateHandler struct {
+	store       *lib.Config
+	client      *bifrost.Bifrost
+	pluginStore *governance.GovernanceStore // Nil without the governance plugin
+	logger      schemas.Logger
+}
+
+// NewStateHandler creates a new state handler instance. The governance plugin is optional.
+func NewStateHandler(store *lib.Config, client *bifrost.Bifrost, governancePlugin *governance.GovernancePlugin, logger schemas.Logger) *StateHandler {
+	h := &StateHandler{
+		store:  store,
+		client: client,
+		logger: logger,
+	}
+	if governancePlugin != nil {
+		h.pluginStore = governancePlugin.GetGovernanceStore()
+	}
+	return h
+}
+
+// StateSnapshot is a versioned snapshot of the runtime state of a gateway. It contains virtual
+// key values and is as sensitive as the keys themselves. Provider keys are referenced by ID,
+// their values are not included.
+type StateSnapshot struct {
+	Version        int                                             `json:"version"`
+	BifrostVersion string                                          `json:"bifrost_version,omitempty"`
+	ExportedAt     time.Time                                       `json:"exported_at"`
+	Governance     *GovernanceSnapshot                             `json:"governance,omitempty"`      // Nil without a config store
+	RoutingWeights map[schemas.ModelProvider][]KeyWeight           `json:"routing_weights,omitempty"` // Weights of the keys of each provider
+	ProviderStates map[schemas.ModelProvider]schemas.ProviderState `json:"provider_states,omitempty"` // Whether each provider accepts requests
+}
+
+// GovernanceSnapshot is the governance state of a snapshot, including the current usage of
+// budgets and rate limits. Relationships are referenced by ID.
+type GovernanceSnapshot struct {
+	Customers   []configstore.TableCustomer  `json:"customers"`
+	Teams       []configstore.TableTeam      `json:"teams"`
+	VirtualKeys []VirtualKeySnapshot         `json:"virtual_keys"`
+	Budgets     []configstore.TableBudget    `json:"budgets"`
+	RateLimits  []configstore.TableRateLimit `json:"rate_limits"`
+}
+
+// VirtualKeySnapshot is a virtual key of a snapshot, with the provider keys it may use by ID.
+type VirtualKeySnapshot struct {
+	configstore.TableVirtualKey
+	KeyIDs []string `json:"key_ids,omitempty"`
+}
+
+// KeyWeight is the routing weight of a provider key.
+type KeyWeight struct {
+	KeyID  string  `json:"key_id"`
+	Weight float64 `json:"weight"`
+}
+
+// StateImportResult reports what an import applied, or would apply for a dry run.
+type StateImportResult struct {
+	DryRun         bool     `json:"dry_run"`
+	Customers      int      `json:"customers"`
+	Teams          int      `json:"teams"`
+	VirtualKeys    int      `json:"virtual_keys"`
+	Budgets        int      `json:"budgets"`
+	RateLimits     int      `json:"rate_limits"`
+	KeyWeights     int      `json:"key_weights"`
+	ProviderStates int      `json:"provider_states"`
+	Warnings       []string `json:"warnings,omitempty"` // Parts of the snapshot that were skipped
+}
+
+// RegisterRoutes registers the state snapshot routes
+func (h *StateHandler) RegisterRoutes(r *router.Router) {
+	r.GET("/api/state/export", h.exportState)
+	r.POST("/api/state/import", h.importState)
+}
+
+// exportState handles GET /api/state/export - Export a snapshot of the gateway state
+func (h *StateHandler) exportState(ctx *fasthttp.RequestCtx) {
+	snapshot := StateSnapshot{
+		Version:        StateSnapshotVersion,
+		BifrostVersion: version,
+		ExportedAt:     time.Now().UTC(),
+		RoutingWeights: make(map[schemas.ModelProvider][]KeyWeight),
+		ProviderStates: make(map[schemas.ModelProvider]schemas.ProviderState),
+	}
+
+	if h.store.ConfigStore != nil {
+		governanceSnapshot, err := h.exportGovernance()
+		if err != nil {
+			SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to export governance state: %v", err), h.logger)
+			return
+		}
+		snapshot.Governance = governanceSnapshot
+	}
+
+	providers, err := h.store.GetAllProviders()
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to get providers: %v", err), h.logger)
+		return
+	}
+	for _, provider := range providers {
+		config, err := h.store.GetProviderConfigRaw(provider)
+		if err != nil {
+			continue
+		}
+		weights := make([]KeyWeight, 0, len(config.Keys))
+		for _, key := range config.Keys {
+			weights = append(weights, KeyWeight{KeyID: key.ID, Weight: key.Weight})
+		}
+		snapshot.RoutingWeights[provider] = weights
+		snapshot.ProviderStates[provider] = h.client.GetProviderStatus(provider).State
+	}
+
+	ctx.Response.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"bifrost-state-%s.json\"", snapshot.ExportedAt.Format("20060102T150405Z")))
+	SendJSON(ctx, snapshot, h.logger)
+}
+
+// exportGovernance reads the governance state from the config store, where usage is persisted as
+// it is recorded, and flattens relationships to IDs.
+func (h *StateHandler) exportGovernance() (*GovernanceSnapshot, error) {
+	configStore := h.store.ConfigStore
+
+	customers, err := configStore.GetCustomers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customers: %w", err)
+	}
+	teams, err := configStore.GetTeams("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teams: %w", err)
+	}
+	virtualKeys, err := configStore.GetVirtualKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual keys: %w", err)
+	}
+	budgets, err := configStore.GetBudgets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budgets: %w", err)
+	}
+
+	snapshot := &GovernanceSnapshot{
+		Customers:   make([]configstore.TableCustomer, 0, len(customers)),
+		Teams:       make([]configstore.TableTeam, 0, len(teams)),
+		VirtualKeys: make([]VirtualKeySnapshot, 0, len(virtualKeys)),
+		Budgets:     budgets,
+		RateLimits:  make([]configstore.TableRateLimit, 0),
+	}
+	if snapshot.Budgets == nil {
+		snapshot.Budgets = []configstore.TableBudget{}
+	}
+	for _, customer := range customers {
+		customer.Budget, customer.Teams, customer.VirtualKeys = nil, nil, nil
+		snapshot.Customers = append(snapshot.Customers, customer)
+	}
+	for _, team := range teams {
+		team.Customer, team.Budget, team.VirtualKeys = nil, nil, nil
+		snapshot.Teams = append(snapshot.Teams, team)
+	}
+	for _, vk := range virtualKeys {
+		if vk.RateLimit != nil {
+			snapshot.RateLimits = append(snapshot.RateLimits, *vk.RateLimit)
+		}
+		keyIDs := make([]string, 0, len(vk.Keys))
+		for _, key := range vk.Keys {
+			keyIDs = append(keyIDs, key.KeyID)
+		}
+		vk.Team, vk.Customer, vk.Budget, vk.RateLimit, vk.Keys = nil, nil, nil, nil, nil
+		snapshot.VirtualKeys = append(snapshot.VirtualKeys, VirtualKeySnapshot{TableVirtualKey: vk, KeyIDs: keyIDs})
+	}
+	return snapshot, nil
+}
+
+// importState handles POST /api/state/import - Apply a state snapshot. Entities of the snapshot
+// are created or updated by ID, entities missing from it are left untouched. With dry_run=true
+// the governance state is written in a transaction that is rolled back, and nothing is applied.
+func (h *StateHandler) importState(ctx *fasthttp.RequestCtx) {
+	var snapshot StateSnapshot
+	if err := json.Unmarshal(ctx.PostBody(), &snapshot); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid snapshot: %v", err), h.logger)
+		return
+	}
+	if snapshot.Version < 1 || snapshot.Version > StateSnapshotVersion {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Unsupported snapshot version %d, this gateway supports versions 1 to %d", snapshot.Version, StateSnapshotVersion), h.logger)
+		return
+	}
+
+	result := StateImportResult{DryRun: string(ctx.QueryArgs().Peek("dry_run")) == "true"}
+
+	if snapshot.Governance != nil {
+		if h.store.ConfigStore == nil {
+			result.Warnings = append(result.Warnings, "governance state skipped: no config store")
+		} else if err := h.importGovernance(snapshot.Governance, &result); err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Failed to import governance state: %v", err), h.logger)
+			return
+		}
+	}
+
+	h.importRoutingWeights(snapshot.RoutingWeights, &result)
+	h.importProviderStates(snapshot.ProviderStates, &result)
+
+	SendJSON(ctx, result, h.logger)
+}
+
+// importGovernance writes the governance state of a snapshot in one transaction and refreshes the
+// in-memory governance store.
+func (h *StateHandler) importGovernance(snapshot *GovernanceSnapshot, result *StateImportResult) error {
+	configStore := h.store.ConfigStore
+
+	// Provider keys are resolved before the transaction, they are not part of the snapshot
+	vkKeys := make([][]configstore.TableKey, len(snapshot.VirtualKeys))
+	for i, vk := range snapshot.VirtualKeys {
+		keys, err := configStore.GetKeysByIDs(vk.KeyIDs)
+		if err != nil {
+			return fmt.Errorf("virtual key %s: failed to get keys: %w", vk.ID, err)
+		}
+		for _, keyID := range vk.KeyIDs {
+			if !slices.ContainsFunc(keys, func(key configstore.TableKey) bool { return key.KeyID == keyID }) {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("virtual key %s: provider key %s not found", vk.Name, keyID))
+			}
+		}
+		vkKeys[i] = keys
+	}
+
+	err := configStore.ExecuteTransaction(func(tx *gorm.DB) error {
+		// Referenced entities first
+		for i := range snapshot.Budgets {
+			if err := configStore.UpdateBudget(&snapshot.Budgets[i], tx); err != nil {
+				return fmt.Errorf("budget %s: %w", snapshot.Budgets[i].ID, err)
+			}
+		}
+		for i := range snapshot.RateLimits {
+			if err := configStore.UpdateRateLimit(&snapshot.RateLimits[i], tx); err != nil {
+				return fmt.Errorf("rate limit %s: %w", snapshot.RateLimits[i].ID, err)
+			}
+		}
+		for i := range snapshot.Customers {
+			customer := snapshot.Customers[i]
+			customer.Budget, customer.Teams, customer.VirtualKeys = nil, nil, nil
+			if err := configStore.UpdateCustomer(&customer, tx); err != nil {
+				return fmt.Errorf("customer %s: %w", customer.ID, err)
+			}
+		}
+		for i := range snapshot.Teams {
+			team := snapshot.Teams[i]
+			team.Customer, team.Budget, team.VirtualKeys = nil, nil, nil
+			if err := configStore.UpdateTeam(&team, tx); err != nil {
+				return fmt.Errorf("team %s: %w", team.ID, err)
+			}
+		}
+		for i := range snapshot.VirtualKeys {
+			vk := snapshot.VirtualKeys[i].TableVirtualKey
+			vk.Team, vk.Customer, vk.Budget, vk.RateLimit = nil, nil, nil, nil
+			vk.Keys = vkKeys[i]
+			if err := configStore.UpdateVirtualKey(&vk, tx); err != nil {
+				return fmt.Errorf("virtual key %s: %w", vk.ID, err)
+			}
+		}
+
+		if result.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return err
+	}
+
+	result.Customers = len(snapshot.Customers)
+	result.Teams = len(snapshot.Teams)
+	result.VirtualKeys = len(snapshot.VirtualKeys)
+	result.Budgets = len(snapshot.Budgets)
+	result.RateLimits = len(snapshot.RateLimits)
+	if result.DryRun || h.pluginStore == nil {
+		return nil
+	}
+
+	// Refresh the in-memory store with the relationships preloaded
+	for i := range snapshot.Budgets {
+		budget := snapshot.Budgets[i]
+		if err := h.pluginStore.UpdateBudgetInMemory(&budget); err != nil {
+			h.logger.Warn(fmt.Sprintf("Failed to update budget %s in memory: %v", budget.ID, err))
+		}
+	}
+	for _, customer := range snapshot.Customers {
+		if preloaded, err := configStore.GetCustomer(customer.ID); err == nil {
+			h.pluginStore.UpdateCustomerInMemory(preloaded)
+		}
+	}
+	for _, team := range snapshot.Teams {
+		if preloaded, err := configStore.GetTeam(team.ID); err == nil {
+			h.pluginStore.UpdateTeamInMemory(preloaded)
+		}
+	}
+	for _, vk := range snapshot.VirtualKeys {
+		// The value of the key may have changed, and the in-memory store is keyed by value
+		h.pluginStore.DeleteVirtualKeyInMemory(vk.ID)
+		if preloaded, err := configStore.GetVirtualKey(vk.ID); err == nil {
+			h.pluginStore.CreateVirtualKeyInMemory(preloaded)
+		}
+	}
+	return nil
+}
+
+// importRoutingWeights applies the key weights of a snapshot to the providers configured on this
+// gateway. Keys are matched by ID.
+func (h *StateHandler) importRoutingWeights(routingWeights map[schemas.ModelProvider][]KeyWeight, result *StateImportResult) {
+	for provider, keyWeights := range routingWeights {
+		config, err := h.store.GetProviderConfigRaw(provider)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("routing weights of %s skipped: provider not configured", provider))
+			continue
+		}
+
+		weights := make(map[string]float64, len(keyWeights))
+		for _, keyWeight := range keyWeights {
+			if keyWeight.Weight < 0 {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("routing weight of %s key %s skipped: negative weight", provider, keyWeight.KeyID))
+				continue
+			}
+			weights[keyWeight.KeyID] = keyWeight.Weight
+		}
+
+		var unknown []string
+		if result.DryRun {
+			for keyID := range weights {
+				if !slices.ContainsFunc(config.Keys, func(key schemas.Key) bool { return key.ID == keyID }) {
+					unknown = append(unknown, keyID)
+				}
+			}
+			slices.Sort(unknown)
+		} else {
+			unknown, err = h.store.UpdateKeyWeights(provider, weights)
+			if err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("routing weights of %s: %v", provider, err))
+			}
+		}
+		for _, keyID := range unknown {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("routing weight of %s key %s skipped: key not found", provider, keyID))
+		}
+		result.KeyWeights += len(weights) - len(unknown)
+	}
+}
+
+// importProviderStates drains the providers the snapshot has draining or inactive and activates
+// the ones it has active. Drains do not wait for the requests in flight: providers become
+// inactive once they finish.
+func (h *StateHandler) importProviderStates(providerStates map[schemas.ModelProvider]schemas.ProviderState, result *StateImportResult) {
+	for provider, state := range providerStates {
+		if _, err := h.store.GetProviderConfigRaw(provider); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("state of %s skipped: provider not configured", provider))
+			continue
+		}
+
+		current := h.client.GetProviderStatus(provider).State
+		switch state {
+		case schemas.ProviderStateActive:
+			if current != schemas.ProviderStateActive && !result.DryRun {
+				if err := h.client.ActivateProvider(provider); err != nil {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("state of %s: %v", provider, err))
+					continue
+				}
+			}
+		case schemas.ProviderStateDraining, schemas.ProviderStateInactive:
+			if current == schemas.ProviderStateActive && !result.DryRun {
+				if err := h.client.DrainProvider(provider, time.Now()); err != nil {
+					h.logger.Info(fmt.Sprintf("Drain of provider %s from snapshot: %v", provider, err))
+				}
+			}
+		default:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("state of %s skipped: unknown state %q", provider, state))
+			continue
+		}
+		result.ProviderStates++
+	}
+}
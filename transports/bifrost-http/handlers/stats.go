@@ -0,0 +1,81 @@
+// Package handlers provides HTTP request handlers for the Bifrost HTTP transport.
+// This file contains the provider stats handler backing ops dashboards.
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/plugins/telemetry"
+	"github.com/valyala/fasthttp"
+)
+
+// StatsHandler manages HTTP requests for provider availability, error and latency stats
+type StatsHandler struct {
+	collector *telemetry.StatsCollector
+	logger    schemas.Logger
+}
+
+// NewStatsHandler creates a new stats handler instance
+func NewStatsHandler(collector *telemetry.StatsCollector, logger schemas.Logger) *StatsHandler {
+	return &StatsHandler{
+		collector: collector,
+		logger:    logger,
+	}
+}
+
+// RegisterRoutes registers all stats-related routes
+func (h *StatsHandler) RegisterRoutes(r *router.Router) {
+	r.GET("/api/stats/providers", h.getProviderStats)
+}
+
+// getProviderStats handles GET /api/stats/providers - Get per-provider time series via query parameters
+func (h *StatsHandler) getProviderStats(ctx *fasthttp.RequestCtx) {
+	query := telemetry.StatsQuery{}
+
+	if providers := string(ctx.QueryArgs().Peek("providers")); providers != "" {
+		for _, provider := range parseCommaSeparated(providers) {
+			query.Providers = append(query.Providers, schemas.ModelProvider(provider))
+		}
+	}
+	if startTime := string(ctx.QueryArgs().Peek("start_time")); startTime != "" {
+		t, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid start_time: %v", err), h.logger)
+			return
+		}
+		query.From = t
+	} else if window := string(ctx.QueryArgs().Peek("window")); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid window: %s", window), h.logger)
+			return
+		}
+		query.From = time.Now().Add(-d)
+	}
+	if endTime := string(ctx.QueryArgs().Peek("end_time")); endTime != "" {
+		t, err := time.Parse(time.RFC3339, endTime)
+		if err != nil {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid end_time: %v", err), h.logger)
+			return
+		}
+		query.To = t
+	}
+	if step := string(ctx.QueryArgs().Peek("step")); step != "" {
+		d, err := time.ParseDuration(step)
+		if err != nil || d <= 0 {
+			SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid step: %s", step), h.logger)
+			return
+		}
+		query.Step = d
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		SendError(ctx, fasthttp.StatusBadRequest, "start_time must be before end_time", h.logger)
+		return
+	}
+
+	SendJSON(ctx, h.collector.Query(query), h.logger)
+}
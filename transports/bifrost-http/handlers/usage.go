@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/usageledger"
+	"github.com/valyala/fasthttp"
+)
+
+// UsageHandler serves the aggregates of the usage ledger, for billing reports.
+type UsageHandler struct {
+	logger schemas.Logger
+	ledger usageledger.Ledger
+}
+
+// NewUsageHandler returns the handler of the ledger of a usage ledger plugin.
+func NewUsageHandler(plugin schemas.Plugin, logger schemas.Logger) *UsageHandler {
+	usagePlugin, ok := plugin.(*usageledger.Plugin)
+	if !ok {
+		logger.Fatal("Usage handler requires a usage ledger plugin")
+	}
+
+	return &UsageHandler{
+		ledger: usagePlugin.GetLedger(),
+		logger: logger,
+	}
+}
+
+func (h *UsageHandler) RegisterRoutes(r *router.Router) {
+	r.GET("/api/usage", h.getUsage)
+}
+
+// getUsage aggregates the ledger entries matching the query arguments: from and to (RFC3339 or
+// YYYY-MM-DD, to being exclusive), tenant, key, provider and model, grouped by the comma separated
+// dimensions of group_by, such as "day,key,model".
+func (h *UsageHandler) getUsage(ctx *fasthttp.RequestCtx) {
+	args := ctx.QueryArgs()
+	query := usageledger.Query{
+		Tenant:   string(args.Peek("tenant")),
+		Key:      string(args.Peek("key")),
+		Provider: schemas.ModelProvider(args.Peek("provider")),
+		Model:    string(args.Peek("model")),
+	}
+
+	var err error
+	if query.From, err = parseUsageTime(string(args.Peek("from"))); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid from: %v", err), h.logger)
+		return
+	}
+	if query.To, err = parseUsageTime(string(args.Peek("to"))); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("Invalid to: %v", err), h.logger)
+		return
+	}
+	if query.GroupBy, err = usageledger.ParseGroupBy(string(args.Peek("group_by"))); err != nil {
+		SendError(ctx, fasthttp.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+
+	aggregates, err := h.ledger.Aggregate(ctx, query)
+	if errors.Is(err, usageledger.ErrInvalidQuery) {
+		SendError(ctx, fasthttp.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+	if err != nil {
+		SendError(ctx, fasthttp.StatusInternalServerError, fmt.Sprintf("Failed to aggregate usage: %v", err), h.logger)
+		return
+	}
+
+	SendJSON(ctx, map[string]interface{}{
+		"aggregates": aggregates,
+	}, h.logger)
+}
+
+// parseUsageTime parses a time as RFC3339 or as a UTC day, the zero time if empty.
+func parseUsageTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
@@ -25,9 +25,14 @@ func SendJSON(ctx *fasthttp.RequestCtx, data interface{}, logger schemas.Logger)
 
 // SendError sends a BifrostError response
 func SendError(ctx *fasthttp.RequestCtx, statusCode int, message string, logger schemas.Logger) {
+	origin := schemas.ErrorOriginClientRequest
+	if statusCode >= fasthttp.StatusInternalServerError {
+		origin = schemas.ErrorOriginBifrostInternal
+	}
 	bifrostErr := &schemas.BifrostError{
 		IsBifrostError: false,
 		StatusCode:     &statusCode,
+		Origin:         origin,
 		Error: schemas.ErrorField{
 			Message: message,
 		},
@@ -35,8 +40,10 @@ func SendError(ctx *fasthttp.RequestCtx, statusCode int, message string, logger
 	SendBifrostError(ctx, bifrostErr, logger)
 }
 
-// SendBifrostError sends a BifrostError response
+// SendBifrostError sends a BifrostError response. Errors without an origin are sent with the
+// origin they are attributed to, so that clients always know whose fault an error is.
 func SendBifrostError(ctx *fasthttp.RequestCtx, bifrostErr *schemas.BifrostError, logger schemas.Logger) {
+	bifrostErr.Origin = bifrostErr.GetOrigin()
 	if bifrostErr.StatusCode != nil {
 		ctx.SetStatusCode(*bifrostErr.StatusCode)
 	} else if !bifrostErr.IsBifrostError {
@@ -55,6 +62,7 @@ func SendBifrostError(ctx *fasthttp.RequestCtx, bifrostErr *schemas.BifrostError
 
 // SendSSEError sends an error in Server-Sent Events format
 func SendSSEError(ctx *fasthttp.RequestCtx, bifrostErr *schemas.BifrostError, logger schemas.Logger) {
+	bifrostErr.Origin = bifrostErr.GetOrigin()
 	errorJSON, err := json.Marshal(map[string]interface{}{
 		"error": bifrostErr,
 	})
@@ -69,6 +77,23 @@ func SendSSEError(ctx *fasthttp.RequestCtx, bifrostErr *schemas.BifrostError, lo
 	}
 }
 
+// SendNDJSONError sends an error as a single NDJSON line, the NDJSON counterpart of SendSSEError.
+func SendNDJSONError(ctx *fasthttp.RequestCtx, bifrostErr *schemas.BifrostError, logger schemas.Logger) {
+	bifrostErr.Origin = bifrostErr.GetOrigin()
+	errorJSON, err := json.Marshal(map[string]interface{}{
+		"error": bifrostErr,
+	})
+	if err != nil {
+		logger.Error("failed to marshal error for NDJSON: %v", err)
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		return
+	}
+
+	if _, err := fmt.Fprintf(ctx, "%s\n", errorJSON); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to write NDJSON error: %v", err))
+	}
+}
+
 // IsOriginAllowed checks if the given origin is allowed based on localhost rules and configured allowed origins.
 // Localhost origins are always allowed. Additional origins can be configured in allowedOrigins.
 func IsOriginAllowed(origin string, allowedOrigins []string) bool {
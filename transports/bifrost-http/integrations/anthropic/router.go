@@ -44,6 +44,26 @@ func CreateAnthropicRouteConfigs(pathPrefix string) []integrations.RouteConfig {
 				},
 			},
 		},
+		{
+			Path:   pathPrefix + "/v1/messages/count_tokens",
+			Method: "POST",
+			GetRequestTypeInstance: func() interface{} {
+				return &AnthropicMessageRequest{}
+			},
+			RequestConverter: func(req interface{}) (*schemas.BifrostRequest, error) {
+				if anthropicReq, ok := req.(*AnthropicMessageRequest); ok {
+					return anthropicReq.ConvertToBifrostRequest(), nil
+				}
+				return nil, errors.New("invalid request type")
+			},
+			ResponseConverter: func(resp *schemas.BifrostResponse) (interface{}, error) {
+				return DeriveAnthropicCountTokensFromBifrostResponse(resp), nil
+			},
+			ErrorConverter: func(err *schemas.BifrostError) interface{} {
+				return DeriveAnthropicErrorFromBifrostError(err)
+			},
+			RequestType: schemas.TokenCountRequest,
+		},
 	}
 }
 
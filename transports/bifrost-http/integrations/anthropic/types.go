@@ -13,8 +13,10 @@ var fnTypePtr = bifrost.Ptr(string(schemas.ToolChoiceTypeFunction))
 
 // AnthropicContentBlock represents content in Anthropic message format
 type AnthropicContentBlock struct {
-	Type      string                `json:"type"`                  // "text", "image", "tool_use", "tool_result"
+	Type      string                `json:"type"`                  // "text", "image", "tool_use", "tool_result", "thinking"
 	Text      *string               `json:"text,omitempty"`        // For text content
+	Thinking  *string               `json:"thinking,omitempty"`    // For thinking content
+	Signature *string               `json:"signature,omitempty"`   // For thinking content
 	ToolUseID *string               `json:"tool_use_id,omitempty"` // For tool_result content
 	ID        *string               `json:"id,omitempty"`          // For tool_use content
 	Name      *string               `json:"name,omitempty"`        // For tool_use content
@@ -73,6 +75,7 @@ type AnthropicMessageRequest struct {
 	Stream        *bool                `json:"stream,omitempty"`
 	Tools         *[]AnthropicTool     `json:"tools,omitempty"`
 	ToolChoice    *AnthropicToolChoice `json:"tool_choice,omitempty"`
+	ServiceTier   *string              `json:"service_tier,omitempty"` // "auto" or "standard_only"
 }
 
 // IsStreamingRequested implements the StreamingRequest interface
@@ -80,6 +83,11 @@ func (r *AnthropicMessageRequest) IsStreamingRequested() bool {
 	return r.Stream != nil && *r.Stream
 }
 
+// AnthropicCountTokensResponse represents an Anthropic count_tokens API response
+type AnthropicCountTokensResponse struct {
+	InputTokens int `json:"input_tokens"`
+}
+
 // AnthropicMessageResponse represents an Anthropic messages API response
 type AnthropicMessageResponse struct {
 	ID           string                  `json:"id"`
@@ -94,8 +102,9 @@ type AnthropicMessageResponse struct {
 
 // AnthropicUsage represents usage information in Anthropic format
 type AnthropicUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	ServiceTier  *string `json:"service_tier,omitempty"`
 }
 
 // AnthropicMessageError represents an Anthropic messages API error response
@@ -140,6 +149,7 @@ type AnthropicStreamDelta struct {
 	Type         string  `json:"type"`
 	Text         *string `json:"text,omitempty"`
 	Thinking     *string `json:"thinking,omitempty"`
+	Signature    *string `json:"signature,omitempty"`
 	PartialJSON  *string `json:"partial_json,omitempty"`
 	StopReason   *string `json:"stop_reason,omitempty"`
 	StopSequence *string `json:"stop_sequence,omitempty"`
@@ -234,6 +244,7 @@ func (r *AnthropicMessageRequest) ConvertToBifrostRequest() *schemas.BifrostRequ
 			// Handle different content types
 			var toolCalls []schemas.ToolCall
 			var contentBlocks []schemas.ContentBlock
+			var thought, thoughtSignature *string
 
 			for _, content := range *msg.Content.ContentBlocks {
 				switch content.Type {
@@ -265,6 +276,9 @@ func (r *AnthropicMessageRequest) ConvertToBifrostRequest() *schemas.BifrostRequ
 							},
 						})
 					}
+				case "thinking":
+					thought = content.Thinking
+					thoughtSignature = content.Signature
 				case "tool_use":
 					if content.ID != nil && content.Name != nil {
 						tc := schemas.ToolCall{
@@ -327,9 +341,13 @@ func (r *AnthropicMessageRequest) ConvertToBifrostRequest() *schemas.BifrostRequ
 				}
 			}
 
-			if len(toolCalls) > 0 && msg.Role == string(schemas.ModelChatMessageRoleAssistant) {
+			if (len(toolCalls) > 0 || thought != nil) && msg.Role == string(schemas.ModelChatMessageRoleAssistant) {
 				bifrostMsg.AssistantMessage = &schemas.AssistantMessage{
-					ToolCalls: &toolCalls,
+					Thought:          thought,
+					ThoughtSignature: thoughtSignature,
+				}
+				if len(toolCalls) > 0 {
+					bifrostMsg.AssistantMessage.ToolCalls = &toolCalls
 				}
 			}
 		}
@@ -339,7 +357,7 @@ func (r *AnthropicMessageRequest) ConvertToBifrostRequest() *schemas.BifrostRequ
 	bifrostReq.Input.ChatCompletionInput = &messages
 
 	// Convert parameters
-	if r.MaxTokens > 0 || r.Temperature != nil || r.TopP != nil || r.TopK != nil || r.StopSequences != nil {
+	if r.MaxTokens > 0 || r.Temperature != nil || r.TopP != nil || r.TopK != nil || r.StopSequences != nil || r.ServiceTier != nil {
 		params := &schemas.ModelParameters{}
 
 		if r.MaxTokens > 0 {
@@ -357,6 +375,14 @@ func (r *AnthropicMessageRequest) ConvertToBifrostRequest() *schemas.BifrostRequ
 		if r.StopSequences != nil {
 			params.StopSequences = r.StopSequences
 		}
+		if r.ServiceTier != nil {
+			// "auto" may use priority capacity, "standard_only" never does
+			tier := schemas.ServiceTierDefault
+			if *r.ServiceTier == "auto" {
+				tier = schemas.ServiceTierAuto
+			}
+			params.ServiceTier = &tier
+		}
 
 		bifrostReq.Params = params
 	}
@@ -451,6 +477,7 @@ func DeriveAnthropicFromBifrostResponse(bifrostResp *schemas.BifrostResponse) *A
 		anthropicResp.Usage = &AnthropicUsage{
 			InputTokens:  bifrostResp.Usage.PromptTokens,
 			OutputTokens: bifrostResp.Usage.CompletionTokens,
+			ServiceTier:  bifrostResp.ServiceTier,
 		}
 	}
 
@@ -470,8 +497,9 @@ func DeriveAnthropicFromBifrostResponse(bifrostResp *schemas.BifrostResponse) *A
 		// Add thinking content if present
 		if choice.Message.AssistantMessage != nil && choice.Message.AssistantMessage.Thought != nil && *choice.Message.AssistantMessage.Thought != "" {
 			content = append(content, AnthropicContentBlock{
-				Type: "thinking",
-				Text: choice.Message.AssistantMessage.Thought,
+				Type:      "thinking",
+				Thinking:  choice.Message.AssistantMessage.Thought,
+				Signature: choice.Message.AssistantMessage.ThoughtSignature,
 			})
 		}
 
@@ -523,6 +551,14 @@ func DeriveAnthropicFromBifrostResponse(bifrostResp *schemas.BifrostResponse) *A
 	return anthropicResp
 }
 
+// DeriveAnthropicCountTokensFromBifrostResponse converts a Bifrost token count response to Anthropic format
+func DeriveAnthropicCountTokensFromBifrostResponse(bifrostResp *schemas.BifrostResponse) *AnthropicCountTokensResponse {
+	if bifrostResp == nil || bifrostResp.TokenCount == nil {
+		return &AnthropicCountTokensResponse{}
+	}
+	return &AnthropicCountTokensResponse{InputTokens: bifrostResp.TokenCount.InputTokens}
+}
+
 // DeriveAnthropicStreamFromBifrostResponse converts a Bifrost streaming response to Anthropic SSE string format
 func DeriveAnthropicStreamFromBifrostResponse(bifrostResp *schemas.BifrostResponse) string {
 	if bifrostResp == nil {
@@ -555,6 +591,14 @@ func DeriveAnthropicStreamFromBifrostResponse(bifrostResp *schemas.BifrostRespon
 					Type:     "thinking_delta",
 					Thinking: delta.Thought,
 				}
+			} else if delta.ThoughtSignature != nil {
+				// Handle the signature closing a thinking block
+				streamResp.Type = "content_block_delta"
+				streamResp.Index = &choice.Index
+				streamResp.Delta = &AnthropicStreamDelta{
+					Type:      "signature_delta",
+					Signature: delta.ThoughtSignature,
+				}
 			} else if len(delta.ToolCalls) > 0 {
 				// Handle tool call deltas
 				toolCall := delta.ToolCalls[0] // Take first tool call
@@ -621,6 +665,7 @@ func DeriveAnthropicStreamFromBifrostResponse(bifrostResp *schemas.BifrostRespon
 		streamResp.Usage = &AnthropicUsage{
 			InputTokens:  bifrostResp.Usage.PromptTokens,
 			OutputTokens: bifrostResp.Usage.CompletionTokens,
+			ServiceTier:  bifrostResp.ServiceTier,
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/google/uuid"
 	bifrost "github.com/maximhq/bifrost/core"
 	"github.com/maximhq/bifrost/core/schemas"
 	"github.com/maximhq/bifrost/transports/bifrost-http/integrations"
@@ -48,7 +49,10 @@ func CreateGenAIRouteConfigs(pathPrefix string) []integrations.RouteConfig {
 				return DeriveGeminiStreamFromBifrostError(err)
 			},
 		},
-		PreCallback: extractAndSetModelFromURL,
+		PreCallback: func(ctx *fasthttp.RequestCtx, req interface{}) error {
+			useAPIKeyAsVirtualKey(ctx)
+			return extractAndSetModelFromURL(ctx, req)
+		},
 	})
 
 	return routes
@@ -67,6 +71,23 @@ var embeddingPaths = []string{
 	":predict",
 }
 
+// useAPIKeyAsVirtualKey lets Google GenAI SDK clients, which always send an API key in the
+// x-goog-api-key header or the key query parameter, authenticate with a Bifrost virtual key in its
+// place. Virtual keys are UUIDs, unlike Google API keys, so a UUID there is taken as the x-bf-vk
+// header unless that header is already set.
+func useAPIKeyAsVirtualKey(ctx *fasthttp.RequestCtx) {
+	if len(ctx.Request.Header.Peek("x-bf-vk")) > 0 {
+		return
+	}
+	apiKey := ctx.Request.Header.Peek("x-goog-api-key")
+	if len(apiKey) == 0 {
+		apiKey = ctx.QueryArgs().Peek("key")
+	}
+	if _, err := uuid.ParseBytes(apiKey); err == nil {
+		ctx.Request.Header.SetBytesV("x-bf-vk", apiKey)
+	}
+}
+
 // extractAndSetModelFromURL extracts model from URL and sets it in the request
 func extractAndSetModelFromURL(ctx *fasthttp.RequestCtx, req interface{}) error {
 	model := ctx.UserValue("model")
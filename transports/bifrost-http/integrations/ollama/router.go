@@ -0,0 +1,93 @@
+package ollama
+
+import (
+	"errors"
+
+	bifrost "github.com/maximhq/bifrost/core"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/transports/bifrost-http/integrations"
+	"github.com/maximhq/bifrost/transports/bifrost-http/lib"
+)
+
+// OllamaRouter handles Ollama-compatible API endpoints, so tools that only speak Ollama can use
+// any configured provider. Responses stream as newline delimited JSON, as Ollama's do.
+type OllamaRouter struct {
+	*integrations.GenericRouter
+}
+
+// CreateOllamaRouteConfigs creates route configurations for Ollama endpoints.
+func CreateOllamaRouteConfigs(pathPrefix string) []integrations.RouteConfig {
+	return []integrations.RouteConfig{
+		{
+			Path:   pathPrefix + "/api/chat",
+			Method: "POST",
+			GetRequestTypeInstance: func() interface{} {
+				return &OllamaChatRequest{}
+			},
+			RequestConverter: func(req interface{}) (*schemas.BifrostRequest, error) {
+				if ollamaReq, ok := req.(*OllamaChatRequest); ok {
+					return ollamaReq.ConvertToBifrostRequest(), nil
+				}
+				return nil, errors.New("invalid request type")
+			},
+			ResponseConverter: func(resp *schemas.BifrostResponse) (interface{}, error) {
+				return DeriveOllamaChatFromBifrostResponse(resp), nil
+			},
+			ErrorConverter: func(err *schemas.BifrostError) interface{} {
+				return DeriveOllamaErrorFromBifrostError(err)
+			},
+			StreamConfig: &integrations.StreamConfig{
+				ResponseConverter: func(resp *schemas.BifrostResponse) (interface{}, error) {
+					chunk := DeriveOllamaChatFromBifrostResponse(resp)
+					if chunk == nil {
+						return "", nil
+					}
+					return toNDJSONLine(chunk)
+				},
+				ErrorConverter: func(err *schemas.BifrostError) interface{} {
+					return DeriveOllamaStreamFromBifrostError(err)
+				},
+				ContentType: "application/x-ndjson",
+			},
+		},
+		{
+			Path:   pathPrefix + "/api/generate",
+			Method: "POST",
+			GetRequestTypeInstance: func() interface{} {
+				return &OllamaGenerateRequest{}
+			},
+			RequestConverter: func(req interface{}) (*schemas.BifrostRequest, error) {
+				if ollamaReq, ok := req.(*OllamaGenerateRequest); ok {
+					return ollamaReq.ConvertToBifrostRequest(), nil
+				}
+				return nil, errors.New("invalid request type")
+			},
+			ResponseConverter: func(resp *schemas.BifrostResponse) (interface{}, error) {
+				return DeriveOllamaGenerateFromBifrostResponse(resp), nil
+			},
+			ErrorConverter: func(err *schemas.BifrostError) interface{} {
+				return DeriveOllamaErrorFromBifrostError(err)
+			},
+			StreamConfig: &integrations.StreamConfig{
+				ResponseConverter: func(resp *schemas.BifrostResponse) (interface{}, error) {
+					chunk := DeriveOllamaGenerateFromBifrostResponse(resp)
+					if chunk == nil {
+						return "", nil
+					}
+					return toNDJSONLine(chunk)
+				},
+				ErrorConverter: func(err *schemas.BifrostError) interface{} {
+					return DeriveOllamaStreamFromBifrostError(err)
+				},
+				ContentType: "application/x-ndjson",
+			},
+		},
+	}
+}
+
+// NewOllamaRouter creates a new OllamaRouter with the given bifrost client.
+func NewOllamaRouter(client *bifrost.Bifrost, handlerStore lib.HandlerStore) *OllamaRouter {
+	return &OllamaRouter{
+		GenericRouter: integrations.NewGenericRouter(client, handlerStore, CreateOllamaRouteConfigs("/ollama")),
+	}
+}
@@ -0,0 +1,420 @@
+package ollama
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/transports/bifrost-http/integrations"
+)
+
+// OllamaOptions represents the model options of an Ollama request
+type OllamaOptions struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	TopK             *int     `json:"top_k,omitempty"`
+	NumPredict       *int     `json:"num_predict,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+}
+
+// OllamaToolCall represents a tool call in an Ollama message
+type OllamaToolCall struct {
+	Function OllamaToolCallFunction `json:"function"`
+}
+
+// OllamaToolCallFunction represents the function called by an Ollama tool call
+type OllamaToolCallFunction struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// OllamaMessage represents a message in an Ollama chat request or response
+type OllamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	Images    []string         `json:"images,omitempty"` // Base64 encoded images
+	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+// OllamaChatRequest represents an Ollama /api/chat request
+type OllamaChatRequest struct {
+	Model     string          `json:"model"`
+	Messages  []OllamaMessage `json:"messages"`
+	Tools     *[]schemas.Tool `json:"tools,omitempty"`
+	Format    interface{}     `json:"format,omitempty"` // "json" or a JSON schema
+	Options   *OllamaOptions  `json:"options,omitempty"`
+	Stream    *bool           `json:"stream,omitempty"`
+	KeepAlive interface{}     `json:"keep_alive,omitempty"` // Accepted for compatibility, has no effect
+}
+
+// OllamaGenerateRequest represents an Ollama /api/generate request
+type OllamaGenerateRequest struct {
+	Model     string         `json:"model"`
+	Prompt    string         `json:"prompt"`
+	System    string         `json:"system,omitempty"`
+	Images    []string       `json:"images,omitempty"` // Base64 encoded images
+	Format    interface{}    `json:"format,omitempty"` // "json" or a JSON schema
+	Options   *OllamaOptions `json:"options,omitempty"`
+	Stream    *bool          `json:"stream,omitempty"`
+	Raw       bool           `json:"raw,omitempty"`
+	KeepAlive interface{}    `json:"keep_alive,omitempty"` // Accepted for compatibility, has no effect
+}
+
+// IsStreamingRequested implements the StreamingRequest interface. Ollama streams unless told not to.
+func (r *OllamaChatRequest) IsStreamingRequested() bool {
+	return r.Stream == nil || *r.Stream
+}
+
+// IsStreamingRequested implements the StreamingRequest interface. Ollama streams unless told not to.
+func (r *OllamaGenerateRequest) IsStreamingRequested() bool {
+	return r.Stream == nil || *r.Stream
+}
+
+// OllamaChatResponse represents an Ollama /api/chat response or stream chunk
+type OllamaChatResponse struct {
+	Model           string         `json:"model"`
+	CreatedAt       string         `json:"created_at"`
+	Message         *OllamaMessage `json:"message,omitempty"`
+	Done            bool           `json:"done"`
+	DoneReason      string         `json:"done_reason,omitempty"`
+	TotalDuration   int64          `json:"total_duration,omitempty"` // Nanoseconds
+	PromptEvalCount int            `json:"prompt_eval_count,omitempty"`
+	EvalCount       int            `json:"eval_count,omitempty"`
+}
+
+// OllamaGenerateResponse represents an Ollama /api/generate response or stream chunk
+type OllamaGenerateResponse struct {
+	Model           string `json:"model"`
+	CreatedAt       string `json:"created_at"`
+	Response        string `json:"response"`
+	Thinking        string `json:"thinking,omitempty"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason,omitempty"`
+	TotalDuration   int64  `json:"total_duration,omitempty"` // Nanoseconds
+	PromptEvalCount int    `json:"prompt_eval_count,omitempty"`
+	EvalCount       int    `json:"eval_count,omitempty"`
+}
+
+// OllamaError represents an Ollama error response
+type OllamaError struct {
+	Error string `json:"error"`
+}
+
+// ConvertToBifrostRequest converts an Ollama chat request to Bifrost format. Models without a
+// provider prefix are served by the Ollama provider, prefixed models (e.g. "openai/gpt-4o") by
+// the named provider.
+func (r *OllamaChatRequest) ConvertToBifrostRequest() *schemas.BifrostRequest {
+	provider, model := integrations.ParseModelString(r.Model, schemas.Ollama, false)
+
+	messages := make([]schemas.BifrostMessage, 0, len(r.Messages))
+	// Ollama tool calls carry no IDs; IDs are assigned to assistant tool calls and handed to the
+	// tool results that follow, matched by tool name when given and in call order otherwise.
+	var pending []schemas.ToolCall
+	for i, msg := range r.Messages {
+		bifrostMsg := schemas.BifrostMessage{
+			Role:    schemas.ModelChatMessageRole(msg.Role),
+			Content: convertMessageContent(msg.Content, msg.Images),
+		}
+
+		switch bifrostMsg.Role {
+		case schemas.ModelChatMessageRoleAssistant:
+			if len(msg.ToolCalls) > 0 || msg.Thinking != "" {
+				bifrostMsg.AssistantMessage = &schemas.AssistantMessage{}
+			}
+			if msg.Thinking != "" {
+				bifrostMsg.AssistantMessage.Thought = &msg.Thinking
+			}
+			if len(msg.ToolCalls) > 0 {
+				toolCalls := make([]schemas.ToolCall, 0, len(msg.ToolCalls))
+				for j, call := range msg.ToolCalls {
+					functionType := "function"
+					id := fmt.Sprintf("call_%d_%d", i, j)
+					name := call.Function.Name
+					arguments, err := sonic.Marshal(call.Function.Arguments)
+					if err != nil || call.Function.Arguments == nil {
+						arguments = []byte("{}")
+					}
+					toolCalls = append(toolCalls, schemas.ToolCall{
+						Type: &functionType,
+						ID:   &id,
+						Function: schemas.FunctionCall{
+							Name:      &name,
+							Arguments: string(arguments),
+						},
+					})
+				}
+				bifrostMsg.AssistantMessage.ToolCalls = &toolCalls
+				pending = append(pending[:0:0], toolCalls...)
+			}
+		case schemas.ModelChatMessageRoleTool:
+			match := 0
+			for j, call := range pending {
+				if msg.ToolName != "" && call.Function.Name != nil && *call.Function.Name == msg.ToolName {
+					match = j
+					break
+				}
+			}
+			if match < len(pending) {
+				bifrostMsg.ToolMessage = &schemas.ToolMessage{ToolCallID: pending[match].ID}
+				pending = append(pending[:match], pending[match+1:]...)
+			}
+		}
+
+		messages = append(messages, bifrostMsg)
+	}
+
+	return &schemas.BifrostRequest{
+		Provider: provider,
+		Model:    model,
+		Input: schemas.RequestInput{
+			ChatCompletionInput: &messages,
+		},
+		Params: convertParameters(provider, r.Options, r.Format, r.Tools),
+	}
+}
+
+// ConvertToBifrostRequest converts an Ollama generate request to a Bifrost chat request, with the
+// system prompt, if any, as a system message and the prompt as a user message.
+func (r *OllamaGenerateRequest) ConvertToBifrostRequest() *schemas.BifrostRequest {
+	provider, model := integrations.ParseModelString(r.Model, schemas.Ollama, false)
+
+	var messages []schemas.BifrostMessage
+	if r.System != "" {
+		messages = append(messages, schemas.BifrostMessage{
+			Role:    schemas.ModelChatMessageRoleSystem,
+			Content: schemas.MessageContent{ContentStr: &r.System},
+		})
+	}
+	messages = append(messages, schemas.BifrostMessage{
+		Role:    schemas.ModelChatMessageRoleUser,
+		Content: convertMessageContent(r.Prompt, r.Images),
+	})
+
+	return &schemas.BifrostRequest{
+		Provider: provider,
+		Model:    model,
+		Input: schemas.RequestInput{
+			ChatCompletionInput: &messages,
+		},
+		Params: convertParameters(provider, r.Options, r.Format, nil),
+	}
+}
+
+// convertMessageContent converts Ollama message text and base64 images to Bifrost content.
+func convertMessageContent(content string, images []string) schemas.MessageContent {
+	if len(images) == 0 {
+		return schemas.MessageContent{ContentStr: &content}
+	}
+
+	blocks := make([]schemas.ContentBlock, 0, len(images)+1)
+	if content != "" {
+		blocks = append(blocks, schemas.ContentBlock{
+			Type: schemas.ContentBlockTypeText,
+			Text: &content,
+		})
+	}
+	for _, image := range images {
+		url := image
+		if !strings.HasPrefix(url, "data:") {
+			url = "data:image/jpeg;base64," + image
+		}
+		blocks = append(blocks, schemas.ContentBlock{
+			Type:     schemas.ContentBlockTypeImage,
+			ImageURL: &schemas.ImageURLStruct{URL: url},
+		})
+	}
+	return schemas.MessageContent{ContentBlocks: &blocks}
+}
+
+// convertParameters converts Ollama options and format to Bifrost ModelParameters
+func convertParameters(provider schemas.ModelProvider, options *OllamaOptions, format interface{}, tools *[]schemas.Tool) *schemas.ModelParameters {
+	params := &schemas.ModelParameters{
+		Tools:       tools,
+		ExtraParams: make(map[string]interface{}),
+	}
+
+	if options != nil {
+		params.Temperature = options.Temperature
+		params.TopP = options.TopP
+		params.TopK = options.TopK
+		params.MaxTokens = options.NumPredict
+		params.PresencePenalty = options.PresencePenalty
+		params.FrequencyPenalty = options.FrequencyPenalty
+		if len(options.Stop) > 0 {
+			params.StopSequences = &options.Stop
+		}
+		if options.Seed != nil {
+			params.ExtraParams["seed"] = *options.Seed
+		}
+	}
+
+	switch f := format.(type) {
+	case string:
+		if f == "json" {
+			params.ExtraParams["response_format"] = map[string]interface{}{"type": "json_object"}
+		}
+	case map[string]interface{}:
+		params.ExtraParams["response_format"] = map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":   "response",
+				"schema": f,
+			},
+		}
+	}
+
+	return integrations.ValidateAndFilterParamsForProvider(provider, params)
+}
+
+// DeriveOllamaChatFromBifrostResponse converts a Bifrost response, or stream chunk, to an Ollama
+// chat response. It returns nil for stream chunks without choices, which Ollama has no
+// equivalent for.
+func DeriveOllamaChatFromBifrostResponse(bifrostResp *schemas.BifrostResponse) *OllamaChatResponse {
+	if bifrostResp == nil || len(bifrostResp.Choices) == 0 {
+		return nil
+	}
+
+	choice := bifrostResp.Choices[0]
+	message := &OllamaMessage{Role: string(schemas.ModelChatMessageRoleAssistant)}
+	var toolCalls []schemas.ToolCall
+
+	if choice.BifrostNonStreamResponseChoice != nil {
+		msg := choice.BifrostNonStreamResponseChoice.Message
+		message.Content = msg.Content.Text()
+		if msg.AssistantMessage != nil {
+			if msg.AssistantMessage.Thought != nil {
+				message.Thinking = *msg.AssistantMessage.Thought
+			}
+			if msg.AssistantMessage.ToolCalls != nil {
+				toolCalls = *msg.AssistantMessage.ToolCalls
+			}
+		}
+	} else if choice.BifrostStreamResponseChoice != nil {
+		delta := choice.BifrostStreamResponseChoice.Delta
+		if delta.Content != nil {
+			message.Content = *delta.Content
+		}
+		if delta.Thought != nil {
+			message.Thinking = *delta.Thought
+		}
+		toolCalls = delta.ToolCalls
+	}
+
+	for _, call := range toolCalls {
+		ollamaCall := OllamaToolCall{}
+		if call.Function.Name != nil {
+			ollamaCall.Function.Name = *call.Function.Name
+		}
+		if err := sonic.Unmarshal([]byte(call.Function.Arguments), &ollamaCall.Function.Arguments); err != nil {
+			ollamaCall.Function.Arguments = map[string]interface{}{}
+		}
+		message.ToolCalls = append(message.ToolCalls, ollamaCall)
+	}
+
+	resp := &OllamaChatResponse{
+		Model:     bifrostResp.Model,
+		CreatedAt: createdAt(bifrostResp.Created),
+		Message:   message,
+	}
+	resp.Done, resp.DoneReason = doneReason(choice.FinishReason)
+	if !resp.Done && choice.BifrostNonStreamResponseChoice != nil {
+		// A complete response is always done
+		resp.Done, resp.DoneReason = true, "stop"
+	}
+	if resp.Done {
+		resp.TotalDuration, resp.PromptEvalCount, resp.EvalCount = stats(bifrostResp)
+	}
+	return resp
+}
+
+// DeriveOllamaGenerateFromBifrostResponse converts a Bifrost response, or stream chunk, to an
+// Ollama generate response. It returns nil for stream chunks without choices.
+func DeriveOllamaGenerateFromBifrostResponse(bifrostResp *schemas.BifrostResponse) *OllamaGenerateResponse {
+	chat := DeriveOllamaChatFromBifrostResponse(bifrostResp)
+	if chat == nil {
+		return nil
+	}
+	return &OllamaGenerateResponse{
+		Model:           chat.Model,
+		CreatedAt:       chat.CreatedAt,
+		Response:        chat.Message.Content,
+		Thinking:        chat.Message.Thinking,
+		Done:            chat.Done,
+		DoneReason:      chat.DoneReason,
+		TotalDuration:   chat.TotalDuration,
+		PromptEvalCount: chat.PromptEvalCount,
+		EvalCount:       chat.EvalCount,
+	}
+}
+
+// DeriveOllamaErrorFromBifrostError derives an Ollama error from a BifrostError
+func DeriveOllamaErrorFromBifrostError(bifrostErr *schemas.BifrostError) *OllamaError {
+	if bifrostErr == nil {
+		return nil
+	}
+	return &OllamaError{Error: bifrostErr.Error.Message}
+}
+
+// DeriveOllamaStreamFromBifrostError derives an Ollama stream error line from a BifrostError
+func DeriveOllamaStreamFromBifrostError(bifrostErr *schemas.BifrostError) string {
+	errorResp := DeriveOllamaErrorFromBifrostError(bifrostErr)
+	if errorResp == nil {
+		return ""
+	}
+	line, err := toNDJSONLine(errorResp)
+	if err != nil {
+		return ""
+	}
+	return line
+}
+
+// toNDJSONLine marshals an Ollama stream chunk to a newline terminated JSON line, the stream
+// format of Ollama.
+func toNDJSONLine(chunk interface{}) (string, error) {
+	data, err := sonic.Marshal(chunk)
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// doneReason maps a Bifrost finish reason to the done flag and done_reason of Ollama.
+func doneReason(finishReason *string) (bool, string) {
+	if finishReason == nil {
+		return false, ""
+	}
+	switch *finishReason {
+	case "length", "max_tokens":
+		return true, "length"
+	case "", "end_turn", "stop_sequence", "tool_calls", "tool_use":
+		return true, "stop"
+	}
+	return true, *finishReason
+}
+
+// stats returns the total duration and token counts reported on the final Ollama chunk.
+func stats(bifrostResp *schemas.BifrostResponse) (int64, int, int) {
+	var totalDuration int64
+	if bifrostResp.ExtraFields.Latency != nil {
+		totalDuration = int64(*bifrostResp.ExtraFields.Latency * float64(time.Millisecond))
+	}
+	if bifrostResp.Usage == nil {
+		return totalDuration, 0, 0
+	}
+	return totalDuration, bifrostResp.Usage.PromptTokens, bifrostResp.Usage.CompletionTokens
+}
+
+// createdAt formats a unix timestamp the way Ollama does, using the current time when unset.
+func createdAt(created int) string {
+	if created == 0 {
+		return time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	return time.Unix(int64(created), 0).UTC().Format(time.RFC3339Nano)
+}
@@ -26,7 +26,7 @@ func setAzureModelName(currentModel, deploymentID string) string {
 }
 
 // OpenAIRouter holds route registrations for OpenAI endpoints.
-// It supports standard chat completions, speech synthesis, audio transcription, and streaming capabilities with OpenAI-specific formatting.
+// It supports standard chat completions, legacy completions, speech synthesis, audio transcription and translation, and streaming capabilities with OpenAI-specific formatting.
 type OpenAIRouter struct {
 	*integrations.GenericRouter
 }
@@ -47,6 +47,8 @@ func AzureEndpointPreHook(handlerStore lib.HandlerStore) func(ctx *fasthttp.Requ
 			switch r := req.(type) {
 			case *OpenAIChatRequest:
 				r.Model = setAzureModelName(r.Model, deploymentIDStr)
+			case *OpenAICompletionRequest:
+				r.Model = setAzureModelName(r.Model, deploymentIDStr)
 			case *OpenAISpeechRequest:
 				r.Model = setAzureModelName(r.Model, deploymentIDStr)
 			case *OpenAITranscriptionRequest:
@@ -128,6 +130,48 @@ func CreateOpenAIRouteConfigs(pathPrefix string, handlerStore lib.HandlerStore)
 		})
 	}
 
+	// Legacy completions endpoint, served through chat completions for older client libraries
+	for _, path := range []string{
+		"/v1/completions",
+		"/completions",
+		"/openai/deployments/{deployment-id}/completions",
+	} {
+		routes = append(routes, integrations.RouteConfig{
+			Path:   pathPrefix + path,
+			Method: "POST",
+			GetRequestTypeInstance: func() interface{} {
+				return &OpenAICompletionRequest{}
+			},
+			RequestConverter: func(req interface{}) (*schemas.BifrostRequest, error) {
+				if completionReq, ok := req.(*OpenAICompletionRequest); ok {
+					return completionReq.ConvertToBifrostRequest(pathPrefix != "/openai")
+				}
+				return nil, errors.New("invalid completion request type")
+			},
+			ResponseConverter: func(resp *schemas.BifrostResponse) (interface{}, error) {
+				return DeriveOpenAICompletionFromBifrostResponse(resp), nil
+			},
+			ErrorConverter: func(err *schemas.BifrostError) interface{} {
+				return DeriveOpenAIErrorFromBifrostError(err)
+			},
+			StreamConfig: &integrations.StreamConfig{
+				ResponseConverter: func(resp *schemas.BifrostResponse) (interface{}, error) {
+					return DeriveOpenAICompletionFromBifrostResponse(resp), nil
+				},
+				ErrorConverter: func(err *schemas.BifrostError) interface{} {
+					return DeriveOpenAIStreamFromBifrostError(err)
+				},
+			},
+			PreCallback: AzureEndpointPreHook(handlerStore),
+			PostCallback: func(ctx *fasthttp.RequestCtx, req interface{}, resp *schemas.BifrostResponse) error {
+				if completionReq, ok := req.(*OpenAICompletionRequest); ok {
+					return EchoCompletionPrompt(completionReq, resp)
+				}
+				return nil
+			},
+		})
+	}
+
 	// Embeddings endpoint
 	for _, path := range []string{
 		"/v1/embeddings",
@@ -179,6 +223,10 @@ func CreateOpenAIRouteConfigs(pathPrefix string, handlerStore lib.HandlerStore)
 				if speechResp == nil {
 					return nil, errors.New("failed to convert speech response")
 				}
+				// Audio moved to an artifact store is returned as JSON with its URL
+				if len(speechResp.Audio) == 0 && speechResp.URL != "" {
+					return speechResp, nil
+				}
 				// For speech, we return the raw audio data directly
 				return speechResp.Audio, nil
 			},
@@ -234,6 +282,37 @@ func CreateOpenAIRouteConfigs(pathPrefix string, handlerStore lib.HandlerStore)
 		})
 	}
 
+	// Audio translation endpoint, transcribes the audio into English
+	for _, path := range []string{
+		"/v1/audio/translations",
+		"/audio/translations",
+		"/openai/deployments/{deployment-id}/audio/translations",
+	} {
+		routes = append(routes, integrations.RouteConfig{
+			Path:   pathPrefix + path,
+			Method: "POST",
+			GetRequestTypeInstance: func() interface{} {
+				return &OpenAITranscriptionRequest{}
+			},
+			RequestParser: parseTranscriptionMultipartRequest, // Same form as transcriptions
+			RequestConverter: func(req interface{}) (*schemas.BifrostRequest, error) {
+				if translationReq, ok := req.(*OpenAITranscriptionRequest); ok {
+					translationReq.Language = nil
+					return translationReq.ConvertToBifrostRequest(pathPrefix != "/openai"), nil
+				}
+				return nil, errors.New("invalid translation request type")
+			},
+			ResponseConverter: func(resp *schemas.BifrostResponse) (interface{}, error) {
+				return DeriveOpenAITranscriptionFromBifrostResponse(resp), nil
+			},
+			ErrorConverter: func(err *schemas.BifrostError) interface{} {
+				return DeriveOpenAIErrorFromBifrostError(err)
+			},
+			PreCallback: AzureEndpointPreHook(handlerStore),
+			RequestType: schemas.TranslationRequest,
+		})
+	}
+
 	return routes
 }
 
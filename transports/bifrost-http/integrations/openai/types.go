@@ -1,6 +1,8 @@
 package openai
 
 import (
+	"errors"
+
 	"github.com/bytedance/sonic"
 	"github.com/maximhq/bifrost/core/schemas"
 	"github.com/maximhq/bifrost/transports/bifrost-http/integrations"
@@ -28,6 +30,7 @@ type OpenAIChatRequest struct {
 	Seed                *int                     `json:"seed,omitempty"`
 	MaxCompletionTokens *int                     `json:"max_completion_tokens,omitempty"`
 	ReasoningEffort     *string                  `json:"reasoning_effort,omitempty"`
+	ServiceTier         *string                  `json:"service_tier,omitempty"`
 	StreamOptions       *map[string]interface{}  `json:"stream_options,omitempty"`
 }
 
@@ -65,6 +68,28 @@ type OpenAIEmbeddingRequest struct {
 	User           *string `json:"user,omitempty"`
 }
 
+// OpenAICompletionRequest represents a request to the legacy OpenAI completions API.
+// Bifrost serves it with a chat completion whose only message is the prompt.
+type OpenAICompletionRequest struct {
+	Model            string             `json:"model"`
+	Prompt           interface{}        `json:"prompt"` // Can be string or a single element []string
+	Suffix           *string            `json:"suffix,omitempty"`
+	MaxTokens        *int               `json:"max_tokens,omitempty"`
+	Temperature      *float64           `json:"temperature,omitempty"`
+	TopP             *float64           `json:"top_p,omitempty"`
+	N                *int               `json:"n,omitempty"`
+	Stream           *bool              `json:"stream,omitempty"`
+	LogProbs         *int               `json:"logprobs,omitempty"` // Number of most likely tokens to return per position
+	Echo             *bool              `json:"echo,omitempty"`
+	Stop             interface{}        `json:"stop,omitempty"`
+	PresencePenalty  *float64           `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64           `json:"frequency_penalty,omitempty"`
+	BestOf           *int               `json:"best_of,omitempty"`
+	LogitBias        map[string]float64 `json:"logit_bias,omitempty"`
+	Seed             *int               `json:"seed,omitempty"`
+	User             *string            `json:"user,omitempty"`
+}
+
 // IsStreamingRequested implements the StreamingRequest interface
 func (r *OpenAIChatRequest) IsStreamingRequested() bool {
 	return r.Stream != nil && *r.Stream
@@ -80,6 +105,11 @@ func (r *OpenAITranscriptionRequest) IsStreamingRequested() bool {
 	return r.Stream != nil && *r.Stream
 }
 
+// IsStreamingRequested implements the StreamingRequest interface for legacy completions
+func (r *OpenAICompletionRequest) IsStreamingRequested() bool {
+	return r.Stream != nil && *r.Stream
+}
+
 // IsStreamingRequested implements the StreamingRequest interface for embeddings
 // Note: Embeddings don't support streaming in OpenAI API
 func (r *OpenAIEmbeddingRequest) IsStreamingRequested() bool {
@@ -96,6 +126,7 @@ type OpenAIChatResponse struct {
 	Usage             *schemas.LLMUsage               `json:"usage,omitempty"` // Reuse schema type
 	ServiceTier       *string                         `json:"service_tier,omitempty"`
 	SystemFingerprint *string                         `json:"system_fingerprint,omitempty"`
+	SearchResults     []schemas.SearchResult          `json:"search_results,omitempty"` // As returned by Perplexity
 }
 
 // OpenAIEmbeddingResponse represents an OpenAI embedding response
@@ -108,6 +139,25 @@ type OpenAIEmbeddingResponse struct {
 	SystemFingerprint *string                    `json:"system_fingerprint,omitempty"`
 }
 
+// OpenAICompletionResponse represents a legacy OpenAI completions response, also used for its stream chunks
+type OpenAICompletionResponse struct {
+	ID                string                   `json:"id"`
+	Object            string                   `json:"object"`
+	Created           int                      `json:"created"`
+	Model             string                   `json:"model"`
+	Choices           []OpenAICompletionChoice `json:"choices"`
+	Usage             *schemas.LLMUsage        `json:"usage,omitempty"`
+	SystemFingerprint *string                  `json:"system_fingerprint,omitempty"`
+}
+
+// OpenAICompletionChoice represents a choice in a legacy OpenAI completions response
+type OpenAICompletionChoice struct {
+	Text         string                         `json:"text"`
+	Index        int                            `json:"index"`
+	LogProbs     *schemas.TextCompletionLogProb `json:"logprobs"`
+	FinishReason *string                        `json:"finish_reason"`
+}
+
 // OpenAIChatError represents an OpenAI chat completion error response
 type OpenAIChatError struct {
 	EventID string `json:"event_id"` // Unique identifier for the error event
@@ -147,13 +197,14 @@ type OpenAIStreamDelta struct {
 
 // OpenAIStreamResponse represents a single chunk in the OpenAI streaming response
 type OpenAIStreamResponse struct {
-	ID                string               `json:"id"`
-	Object            string               `json:"object"`
-	Created           int                  `json:"created"`
-	Model             string               `json:"model"`
-	SystemFingerprint *string              `json:"system_fingerprint,omitempty"`
-	Choices           []OpenAIStreamChoice `json:"choices"`
-	Usage             *schemas.LLMUsage    `json:"usage,omitempty"`
+	ID                string                 `json:"id"`
+	Object            string                 `json:"object"`
+	Created           int                    `json:"created"`
+	Model             string                 `json:"model"`
+	SystemFingerprint *string                `json:"system_fingerprint,omitempty"`
+	Choices           []OpenAIStreamChoice   `json:"choices"`
+	Usage             *schemas.LLMUsage      `json:"usage,omitempty"`
+	SearchResults     []schemas.SearchResult `json:"search_results,omitempty"` // As returned by Perplexity
 }
 
 // ConvertToBifrostRequest converts an OpenAI chat request to Bifrost format
@@ -175,6 +226,66 @@ func (r *OpenAIChatRequest) ConvertToBifrostRequest(checkProviderFromModel bool)
 	return bifrostReq
 }
 
+// ConvertToBifrostRequest converts a legacy OpenAI completions request to a Bifrost chat request.
+// The prompt becomes a single user message. best_of is accepted for compatibility but, as chat
+// models do not score their candidates server-side, only n completions are generated.
+func (r *OpenAICompletionRequest) ConvertToBifrostRequest(checkProviderFromModel bool) (*schemas.BifrostRequest, error) {
+	prompt, err := r.promptText()
+	if err != nil {
+		return nil, err
+	}
+	if r.BestOf != nil && r.N != nil && *r.BestOf < *r.N {
+		return nil, errors.New("best_of must be greater than or equal to n")
+	}
+	if r.Echo != nil && *r.Echo && r.IsStreamingRequested() {
+		return nil, errors.New("echo is not supported for streaming completions")
+	}
+	if r.Suffix != nil && *r.Suffix != "" {
+		return nil, errors.New("suffix is not supported")
+	}
+
+	provider, model := integrations.ParseModelString(r.Model, schemas.OpenAI, checkProviderFromModel)
+
+	messages := []schemas.BifrostMessage{
+		{
+			Role:    schemas.ModelChatMessageRoleUser,
+			Content: schemas.MessageContent{ContentStr: &prompt},
+		},
+	}
+
+	bifrostReq := &schemas.BifrostRequest{
+		Provider: provider,
+		Model:    model,
+		Input: schemas.RequestInput{
+			ChatCompletionInput: &messages,
+		},
+		Params: filterParams(provider, r.convertCompletionParameters()),
+	}
+
+	return bifrostReq, nil
+}
+
+// promptText returns the prompt of a legacy completions request. Chat completions take a single
+// prompt, so arrays must hold exactly one string; token arrays are not supported.
+func (r *OpenAICompletionRequest) promptText() (string, error) {
+	switch prompt := r.Prompt.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return prompt, nil
+	case []interface{}:
+		if len(prompt) == 1 {
+			if text, ok := prompt[0].(string); ok {
+				return text, nil
+			}
+		}
+		if len(prompt) > 1 {
+			return "", errors.New("batched prompts are not supported, send one prompt per request")
+		}
+	}
+	return "", errors.New("prompt must be a string")
+}
+
 // ConvertToBifrostRequest converts an OpenAI speech request to Bifrost format
 func (r *OpenAISpeechRequest) ConvertToBifrostRequest(checkProviderFromModel bool) *schemas.BifrostRequest {
 	provider, model := integrations.ParseModelString(r.Model, schemas.OpenAI, checkProviderFromModel)
@@ -364,6 +475,49 @@ func (r *OpenAIChatRequest) convertParameters() *schemas.ModelParameters {
 	if r.ReasoningEffort != nil {
 		params.ExtraParams["reasoning_effort"] = *r.ReasoningEffort
 	}
+	if r.ServiceTier != nil {
+		tier := schemas.ServiceTier(*r.ServiceTier)
+		params.ServiceTier = &tier
+	}
+
+	return params
+}
+
+// convertCompletionParameters converts legacy completions request parameters to Bifrost ModelParameters
+func (r *OpenAICompletionRequest) convertCompletionParameters() *schemas.ModelParameters {
+	params := &schemas.ModelParameters{
+		MaxTokens:        r.MaxTokens,
+		Temperature:      r.Temperature,
+		TopP:             r.TopP,
+		PresencePenalty:  r.PresencePenalty,
+		FrequencyPenalty: r.FrequencyPenalty,
+		ExtraParams:      make(map[string]interface{}),
+	}
+
+	if r.N != nil {
+		params.ExtraParams["n"] = *r.N
+	}
+	if r.LogProbs != nil {
+		params.ExtraParams["logprobs"] = true
+		if *r.LogProbs > 0 {
+			params.ExtraParams["top_logprobs"] = *r.LogProbs
+		}
+	}
+	if r.Stop != nil {
+		params.ExtraParams["stop"] = r.Stop
+	}
+	if r.LogitBias != nil {
+		params.ExtraParams["logit_bias"] = r.LogitBias
+	}
+	if r.User != nil {
+		params.ExtraParams["user"] = *r.User
+	}
+	if r.Stream != nil {
+		params.ExtraParams["stream"] = *r.Stream
+	}
+	if r.Seed != nil {
+		params.ExtraParams["seed"] = *r.Seed
+	}
 
 	return params
 }
@@ -437,11 +591,99 @@ func DeriveOpenAIFromBifrostResponse(bifrostResp *schemas.BifrostResponse) *Open
 		Usage:             bifrostResp.Usage,
 		ServiceTier:       bifrostResp.ServiceTier,
 		SystemFingerprint: bifrostResp.SystemFingerprint,
+		SearchResults:     bifrostResp.SearchResults,
 	}
 
 	return openaiResp
 }
 
+// DeriveOpenAICompletionFromBifrostResponse converts a Bifrost chat response to the legacy OpenAI completions format
+func DeriveOpenAICompletionFromBifrostResponse(bifrostResp *schemas.BifrostResponse) *OpenAICompletionResponse {
+	if bifrostResp == nil {
+		return nil
+	}
+
+	completionResp := &OpenAICompletionResponse{
+		ID:                bifrostResp.ID,
+		Object:            "text_completion",
+		Created:           bifrostResp.Created,
+		Model:             bifrostResp.Model,
+		Choices:           make([]OpenAICompletionChoice, 0, len(bifrostResp.Choices)),
+		Usage:             bifrostResp.Usage,
+		SystemFingerprint: bifrostResp.SystemFingerprint,
+	}
+
+	for _, choice := range bifrostResp.Choices {
+		completionChoice := OpenAICompletionChoice{
+			Index:        choice.Index,
+			FinishReason: choice.FinishReason,
+		}
+		if choice.BifrostNonStreamResponseChoice != nil {
+			completionChoice.Text = choice.BifrostNonStreamResponseChoice.Message.Content.Text()
+			completionChoice.LogProbs = deriveTextLogProbs(choice.BifrostNonStreamResponseChoice.LogProbs)
+		} else if choice.BifrostStreamResponseChoice != nil && choice.BifrostStreamResponseChoice.Delta.Content != nil {
+			completionChoice.Text = *choice.BifrostStreamResponseChoice.Delta.Content
+		}
+		completionResp.Choices = append(completionResp.Choices, completionChoice)
+	}
+
+	return completionResp
+}
+
+// EchoCompletionPrompt prepends the prompt of a legacy completions request that sets echo to the
+// text of every choice. The choices of the response are replaced, not modified, since the response
+// may still be referenced by plugins.
+func EchoCompletionPrompt(req *OpenAICompletionRequest, resp *schemas.BifrostResponse) error {
+	if req.Echo == nil || !*req.Echo || resp == nil {
+		return nil
+	}
+	prompt, err := req.promptText()
+	if err != nil {
+		return err
+	}
+
+	choices := make([]schemas.BifrostResponseChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		if choice.BifrostNonStreamResponseChoice != nil {
+			nonStream := *choice.BifrostNonStreamResponseChoice
+			text := prompt + nonStream.Message.Content.Text()
+			nonStream.Message.Content = schemas.MessageContent{ContentStr: &text}
+			choice.BifrostNonStreamResponseChoice = &nonStream
+		}
+		choices[i] = choice
+	}
+	resp.Choices = choices
+	return nil
+}
+
+// deriveTextLogProbs converts chat token log probabilities to the legacy completions format.
+func deriveTextLogProbs(logProbs *schemas.LogProbs) *schemas.TextCompletionLogProb {
+	if logProbs == nil {
+		return nil
+	}
+	if len(logProbs.Text.Tokens) > 0 {
+		return &logProbs.Text
+	}
+	if len(logProbs.Content) == 0 {
+		return nil
+	}
+
+	textLogProbs := &schemas.TextCompletionLogProb{}
+	offset := 0
+	for _, token := range logProbs.Content {
+		textLogProbs.TextOffset = append(textLogProbs.TextOffset, offset)
+		textLogProbs.Tokens = append(textLogProbs.Tokens, token.Token)
+		textLogProbs.TokenLogProbs = append(textLogProbs.TokenLogProbs, token.LogProb)
+		top := make(map[string]float64, len(token.TopLogProbs))
+		for _, candidate := range token.TopLogProbs {
+			top[candidate.Token] = candidate.LogProb
+		}
+		textLogProbs.TopLogProbs = append(textLogProbs.TopLogProbs, top)
+		offset += len(token.Token)
+	}
+	return textLogProbs
+}
+
 // DeriveOpenAISpeechFromBifrostResponse converts a Bifrost speech response to OpenAI format
 func DeriveOpenAISpeechFromBifrostResponse(bifrostResp *schemas.BifrostResponse) *schemas.BifrostSpeech {
 	if bifrostResp == nil || bifrostResp.Speech == nil {
@@ -539,6 +781,7 @@ func DeriveOpenAIStreamFromBifrostResponse(bifrostResp *schemas.BifrostResponse)
 		Model:             bifrostResp.Model,
 		SystemFingerprint: bifrostResp.SystemFingerprint,
 		Usage:             bifrostResp.Usage,
+		SearchResults:     bifrostResp.SearchResults,
 	}
 
 	// Convert choices to streaming format
@@ -132,9 +132,13 @@ type PostRequestCallback func(ctx *fasthttp.RequestCtx, req interface{}, resp *s
 //     data: {"type":"text"}
 //
 // Choose the appropriate return type based on your provider's SSE specification.
+//
+// Integrations that do not stream SSE (e.g. Ollama's newline delimited JSON) return strings and
+// set ContentType to the content type of their stream.
 type StreamConfig struct {
 	ResponseConverter StreamResponseConverter // Function to convert BifrostResponse to streaming format
 	ErrorConverter    StreamErrorConverter    // Function to convert BifrostError to streaming error format
+	ContentType       string                  // Optional: content type of the stream, text/event-stream if empty
 }
 
 // RouteConfig defines the configuration for a single route in an integration.
@@ -150,6 +154,7 @@ type RouteConfig struct {
 	StreamConfig           *StreamConfig       // Optional: Streaming configuration (if nil, streaming not supported)
 	PreCallback            PreRequestCallback  // Optional: called after parsing but before Bifrost processing
 	PostCallback           PostRequestCallback // Optional: called after request processing
+	RequestType            schemas.RequestType // Optional: request type when the input alone does not tell (e.g., translations)
 }
 
 // DefaultParameters defines the common parameters that most providers support
@@ -252,6 +257,10 @@ func (v *ParameterValidator) ValidateAndFilterParams(
 		filteredParams.ParallelToolCalls = params.ParallelToolCalls
 	}
 
+	if params.ServiceTier != nil && schema.ValidParams["service_tier"] {
+		filteredParams.ServiceTier = params.ServiceTier
+	}
+
 	// Filter extra parameters
 	for key, value := range params.ExtraParams {
 		if schema.ValidParams[key] {
@@ -281,7 +290,8 @@ func hasNoValidFields(params *schemas.ModelParameters) bool {
 		params.ParallelToolCalls == nil &&
 		params.EncodingFormat == nil &&
 		params.Dimensions == nil &&
-		params.User == nil
+		params.User == nil &&
+		params.ServiceTier == nil
 }
 
 // buildProviderSchemas defines which parameters are valid for each provider
@@ -438,6 +448,79 @@ func buildProviderSchemas() map[schemas.ModelProvider]ProviderParameterSchema {
 		openRouterParams[k] = v
 	}
 
+	perplexitySpecificParams := map[string]bool{
+		"search_domain_filter":     true, // Restrict or exclude (with a "-" prefix) search domains
+		"search_recency_filter":    true, // "month", "week", "day" or "hour"
+		"return_images":            true,
+		"return_related_questions": true,
+		"search_mode":              true, // "web" or "academic"
+		"web_search_options":       true,
+	}
+	perplexityParams := mergeWithDefaults(openAIParams)
+	for k, v := range perplexitySpecificParams {
+		perplexityParams[k] = v
+	}
+
+	deepSeekParams := mergeWithDefaults(openAIParams)
+	deepSeekParams["thinking"] = true // Boolean or {"type": "enabled"}, deepseek-reasoner always thinks
+
+	togetherSpecificParams := map[string]bool{
+		"top_k":              true,
+		"min_p":              true,
+		"repetition_penalty": true,
+		"safety_model":       true, // Moderation model run on the prompt and completion
+		"steps":              true, // Image generation diffusion steps
+		"guidance_scale":     true, // Image generation prompt adherence
+	}
+	togetherParams := mergeWithDefaults(openAIParams)
+	for k, v := range togetherSpecificParams {
+		togetherParams[k] = v
+	}
+
+	qwenSpecificParams := map[string]bool{
+		"top_k":                     true,
+		"repetition_penalty":        true,
+		"enable_search":             true, // Web search
+		"search_options":            true, // e.g. {"enable_source": true} to return the web sources
+		"enable_thinking":           true,
+		"thinking_budget":           true,
+		"vl_high_resolution_images": true, // Multimodal models
+	}
+	qwenParams := mergeWithDefaults(openAIParams)
+	for k, v := range qwenSpecificParams {
+		qwenParams[k] = v
+	}
+
+	zhipuSpecificParams := map[string]bool{
+		"do_sample":  true,
+		"request_id": true,
+		"user_id":    true,
+		"thinking":   true, // e.g. {"type": "enabled"} for GLM-4.5 and later
+	}
+	zhipuParams := mergeWithDefaults(openAIParams)
+	for k, v := range zhipuSpecificParams {
+		zhipuParams[k] = v
+	}
+
+	qianfanParams := map[string]bool{
+		"stop":            true,
+		"user":            true,
+		"penalty_score":   true,
+		"system":          true,
+		"response_format": true, // "text" or "json_object"
+		"disable_search":  true,
+		"enable_citation": true, // Returns the web sources of the response
+		"enable_trace":    true,
+	}
+
+	sambaNovaParams := mergeWithDefaults(openAIParams)
+	sambaNovaParams["top_k"] = true
+
+	watsonxParams := mergeWithDefaults(openAIParams)
+	for _, param := range []string{"top_k", "project_id", "space_id", "decoding_method", "repetition_penalty", "min_new_tokens", "random_seed", "time_limit"} {
+		watsonxParams[param] = true
+	}
+
 	return map[schemas.ModelProvider]ProviderParameterSchema{
 		schemas.OpenAI:     {ValidParams: mergeWithDefaults(openAIParams)},
 		schemas.Azure:      {ValidParams: mergeWithDefaults(openAIParams)},
@@ -453,6 +536,16 @@ func buildProviderSchemas() map[schemas.ModelProvider]ProviderParameterSchema {
 		schemas.Parasail:   {ValidParams: mergeWithDefaults(openAIParams)},
 		schemas.Gemini:     {ValidParams: geminiParams},
 		schemas.OpenRouter: {ValidParams: openRouterParams},
+		schemas.Perplexity: {ValidParams: perplexityParams},
+		schemas.DeepSeek:   {ValidParams: deepSeekParams},
+		schemas.Together:   {ValidParams: togetherParams},
+		schemas.Qwen:       {ValidParams: qwenParams},
+		schemas.Zhipu:      {ValidParams: zhipuParams},
+		schemas.Moonshot:   {ValidParams: mergeWithDefaults(openAIParams)},
+		schemas.Qianfan:    {ValidParams: mergeWithDefaults(qianfanParams)},
+		schemas.SambaNova:  {ValidParams: sambaNovaParams},
+		schemas.NIM:        {ValidParams: mergeWithDefaults(openAIParams)},
+		schemas.Watsonx:    {ValidParams: watsonxParams},
 	}
 }
 
@@ -578,7 +671,7 @@ func (g *GenericRouter) createHandler(config RouteConfig) fasthttp.RequestHandle
 			if config.RequestParser != nil {
 				// Use custom parser (e.g., for multipart/form-data)
 				if err := config.RequestParser(ctx, req); err != nil {
-					g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "failed to parse request"))
+					g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "failed to parse request", schemas.ErrorOriginClientRequest))
 					return
 				}
 			} else {
@@ -586,7 +679,7 @@ func (g *GenericRouter) createHandler(config RouteConfig) fasthttp.RequestHandle
 				body := ctx.Request.Body()
 				if len(body) > 0 {
 					if err := json.Unmarshal(body, req); err != nil {
-						g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "Invalid JSON"))
+						g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "Invalid JSON", schemas.ErrorOriginClientRequest))
 						return
 					}
 				}
@@ -598,7 +691,7 @@ func (g *GenericRouter) createHandler(config RouteConfig) fasthttp.RequestHandle
 		// or performing request validation after parsing
 		if config.PreCallback != nil {
 			if err := config.PreCallback(ctx, req); err != nil {
-				g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "failed to execute pre-request callback: "+err.Error()))
+				g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "failed to execute pre-request callback: "+err.Error(), schemas.ErrorOriginClientRequest))
 				return
 			}
 		}
@@ -606,15 +699,15 @@ func (g *GenericRouter) createHandler(config RouteConfig) fasthttp.RequestHandle
 		// Convert the integration-specific request to Bifrost format
 		bifrostReq, err := config.RequestConverter(req)
 		if err != nil {
-			g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "failed to convert request to Bifrost format"))
+			g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "failed to convert request to Bifrost format", schemas.ErrorOriginClientRequest))
 			return
 		}
 		if bifrostReq == nil {
-			g.sendError(ctx, config.ErrorConverter, newBifrostError(nil, "Invalid request"))
+			g.sendError(ctx, config.ErrorConverter, newBifrostError(nil, "Invalid request", schemas.ErrorOriginClientRequest))
 			return
 		}
 		if bifrostReq.Model == "" {
-			g.sendError(ctx, config.ErrorConverter, newBifrostError(nil, "Model parameter is required"))
+			g.sendError(ctx, config.ErrorConverter, newBifrostError(nil, "Model parameter is required", schemas.ErrorOriginClientRequest))
 			return
 		}
 
@@ -650,14 +743,26 @@ func (g *GenericRouter) handleNonStreamingRequest(ctx *fasthttp.RequestCtx, conf
 	// Handle different request types
 	if bifrostReq.Input.TextCompletionInput != nil {
 		result, bifrostErr = g.client.TextCompletionRequest(*bifrostCtx, bifrostReq)
+	} else if bifrostReq.Input.ChatCompletionInput != nil && config.RequestType == schemas.TokenCountRequest {
+		result, bifrostErr = g.client.CountTokensRequest(*bifrostCtx, bifrostReq)
 	} else if bifrostReq.Input.ChatCompletionInput != nil {
 		result, bifrostErr = g.client.ChatCompletionRequest(*bifrostCtx, bifrostReq)
 	} else if bifrostReq.Input.EmbeddingInput != nil {
 		result, bifrostErr = g.client.EmbeddingRequest(*bifrostCtx, bifrostReq)
 	} else if bifrostReq.Input.SpeechInput != nil {
 		result, bifrostErr = g.client.SpeechRequest(*bifrostCtx, bifrostReq)
+	} else if bifrostReq.Input.TranscriptionInput != nil && config.RequestType == schemas.TranslationRequest {
+		result, bifrostErr = g.client.TranslationRequest(*bifrostCtx, bifrostReq)
 	} else if bifrostReq.Input.TranscriptionInput != nil {
 		result, bifrostErr = g.client.TranscriptionRequest(*bifrostCtx, bifrostReq)
+	} else if bifrostReq.Input.ImageInput != nil {
+		result, bifrostErr = g.client.ImageGenerationRequest(*bifrostCtx, bifrostReq)
+	} else if bifrostReq.Input.VideoInput != nil {
+		result, bifrostErr = g.client.VideoGenerationRequest(*bifrostCtx, bifrostReq)
+	} else if bifrostReq.Input.VideoJobInput != nil {
+		result, bifrostErr = g.client.VideoStatusRequest(*bifrostCtx, bifrostReq)
+	} else if bifrostReq.Input.RerankInput != nil {
+		result, bifrostErr = g.client.RerankRequest(*bifrostCtx, bifrostReq)
 	}
 
 	// Handle errors
@@ -670,20 +775,20 @@ func (g *GenericRouter) handleNonStreamingRequest(ctx *fasthttp.RequestCtx, conf
 	// This is typically used for response modification or additional processing
 	if config.PostCallback != nil {
 		if err := config.PostCallback(ctx, req, result); err != nil {
-			g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "failed to execute post-request callback"))
+			g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "failed to execute post-request callback", schemas.ErrorOriginBifrostInternal))
 			return
 		}
 	}
 
 	if result == nil {
-		g.sendError(ctx, config.ErrorConverter, newBifrostError(nil, "Bifrost response is nil after post-request callback"))
+		g.sendError(ctx, config.ErrorConverter, newBifrostError(nil, "Bifrost response is nil after post-request callback", schemas.ErrorOriginBifrostInternal))
 		return
 	}
 
 	// Convert Bifrost response to integration-specific format and send
 	response, err := config.ResponseConverter(result)
 	if err != nil {
-		g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "failed to encode response"))
+		g.sendError(ctx, config.ErrorConverter, newBifrostError(err, "failed to encode response", schemas.ErrorOriginBifrostInternal))
 		return
 	}
 
@@ -705,42 +810,53 @@ func (g *GenericRouter) handleNonStreamingRequest(ctx *fasthttp.RequestCtx, conf
 func (g *GenericRouter) handleStreamingRequest(ctx *fasthttp.RequestCtx, config RouteConfig, req interface{}, bifrostReq *schemas.BifrostRequest, bifrostCtx *context.Context) {
 	// Set common SSE headers
 	ctx.SetContentType("text/event-stream")
+	if config.StreamConfig != nil && config.StreamConfig.ContentType != "" {
+		ctx.SetContentType(config.StreamConfig.ContentType)
+	}
 	ctx.Response.Header.Set("Cache-Control", "no-cache")
 	ctx.Response.Header.Set("Connection", "keep-alive")
 	ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
 
+	// Check if streaming is configured for this route
+	if config.StreamConfig == nil {
+		g.sendStreamError(ctx, config, newBifrostError(nil, "streaming is not supported for this integration", schemas.ErrorOriginClientRequest))
+		return
+	}
+
 	var stream chan *schemas.BifrostStream
 	var bifrostErr *schemas.BifrostError
 
+	// The stream runs on a context that is cancelled when the client is dropped
+	streamCtx, cancel := context.WithCancel(*bifrostCtx)
+
 	// Handle different request types
 	if bifrostReq.Input.ChatCompletionInput != nil {
-		stream, bifrostErr = g.client.ChatCompletionStreamRequest(*bifrostCtx, bifrostReq)
+		stream, bifrostErr = g.client.ChatCompletionStreamRequest(streamCtx, bifrostReq)
 	} else if bifrostReq.Input.SpeechInput != nil {
-		stream, bifrostErr = g.client.SpeechStreamRequest(*bifrostCtx, bifrostReq)
+		stream, bifrostErr = g.client.SpeechStreamRequest(streamCtx, bifrostReq)
 	} else if bifrostReq.Input.TranscriptionInput != nil {
-		stream, bifrostErr = g.client.TranscriptionStreamRequest(*bifrostCtx, bifrostReq)
+		stream, bifrostErr = g.client.TranscriptionStreamRequest(streamCtx, bifrostReq)
 	}
 
 	// Get the streaming channel from Bifrost
 	if bifrostErr != nil {
+		cancel()
 		// Send error in SSE format
 		g.sendStreamError(ctx, config, bifrostErr)
 		return
 	}
 
-	// Check if streaming is configured for this route
-	if config.StreamConfig == nil {
-		g.sendStreamError(ctx, config, newBifrostError(nil, "streaming is not supported for this integration"))
-		return
-	}
-
 	// Handle streaming using the centralized approach
-	g.handleStreaming(ctx, config, stream)
+	g.handleStreaming(ctx, config, stream, cancel)
 }
 
 // handleStreaming processes a stream of BifrostResponse objects and sends them as Server-Sent Events (SSE).
 // It handles both successful responses and errors in the streaming format.
 //
+// The stream is written through a lib.StreamGuard, which drains it independently of the client,
+// puts a deadline on every write and applies the slow client policy when the client falls too
+// far behind. cancel cancels the request of the stream and is called once the client is done.
+//
 // SSE FORMAT HANDLING:
 //
 // By default, all responses and errors are sent in the standard SSE format:
@@ -782,17 +898,26 @@ func (g *GenericRouter) handleStreamingRequest(ctx *fasthttp.RequestCtx, config
 // - Include data: lines with JSON content
 // - End with \n\n for proper SSE formatting
 // - Follow the provider's specific SSE event specification
-func (g *GenericRouter) handleStreaming(ctx *fasthttp.RequestCtx, config RouteConfig, streamChan chan *schemas.BifrostStream) {
+func (g *GenericRouter) handleStreaming(ctx *fasthttp.RequestCtx, config RouteConfig, streamChan chan *schemas.BifrostStream, cancel context.CancelFunc) {
+	guard := lib.GuardStream(streamChan, cancel, g.handlerStore.GetStreamGuardConfig())
+	conn := ctx.Conn()
+
 	// Use streaming response writer
 	ctx.Response.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		defer guard.ClearWriteDeadline(conn)
 		defer w.Flush()
 
 		// Process streaming responses
-		for response := range streamChan {
-			if response == nil {
-				continue
+		for {
+			response, ok := guard.Next()
+			if !ok {
+				return
 			}
 
+			// A client that does not take the chunk in time is dropped
+			guard.ArmWriteDeadline(conn)
+
 			// Check for context cancellation
 			select {
 			case <-ctx.Done():
@@ -924,6 +1049,14 @@ func (g *GenericRouter) sendStreamError(ctx *fasthttp.RequestCtx, config RouteCo
 		errorResponse = config.ErrorConverter(bifrostErr)
 	}
 
+	// A string is a complete, integration formatted error event and is sent as-is
+	if errorString, ok := errorResponse.(string); ok {
+		if _, err := fmt.Fprint(ctx, errorString); err != nil {
+			log.Printf("Failed to write stream error: %v", err)
+		}
+		return
+	}
+
 	errorJSON, err := json.Marshal(map[string]interface{}{
 		"error": errorResponse,
 	})
@@ -965,7 +1098,7 @@ func (g *GenericRouter) sendSuccess(ctx *fasthttp.RequestCtx, errorConverter Err
 
 	responseBody, err := json.Marshal(response)
 	if err != nil {
-		g.sendError(ctx, errorConverter, newBifrostError(err, "failed to encode response"))
+		g.sendError(ctx, errorConverter, newBifrostError(err, "failed to encode response", schemas.ErrorOriginBifrostInternal))
 		return
 	}
 
@@ -988,6 +1121,20 @@ var ValidProviders = map[schemas.ModelProvider]bool{
 	schemas.Cerebras:   true,
 	schemas.Gemini:     true,
 	schemas.OpenRouter: true,
+	schemas.Perplexity: true,
+	schemas.DeepSeek:   true,
+	schemas.Together:   true,
+	schemas.Replicate:  true,
+	schemas.Qwen:       true,
+	schemas.Zhipu:      true,
+	schemas.Moonshot:   true,
+	schemas.Qianfan:    true,
+	schemas.SambaNova:  true,
+	schemas.NIM:        true,
+	schemas.Watsonx:    true,
+	schemas.Stability:  true,
+	schemas.BFL:        true,
+	schemas.Luma:       true,
 }
 
 // ParseModelString extracts provider and model from a model string.
@@ -1159,10 +1306,11 @@ func matchesAnyPattern(model string, patterns []string) bool {
 
 // newBifrostError wraps a standard error into a BifrostError with IsBifrostError set to false.
 // This helper function reduces code duplication when handling non-Bifrost errors.
-func newBifrostError(err error, message string) *schemas.BifrostError {
+func newBifrostError(err error, message string, origin schemas.ErrorOrigin) *schemas.BifrostError {
 	if err == nil {
 		return &schemas.BifrostError{
 			IsBifrostError: false,
+			Origin:         origin,
 			Error: schemas.ErrorField{
 				Message: message,
 			},
@@ -1171,6 +1319,7 @@ func newBifrostError(err error, message string) *schemas.BifrostError {
 
 	return &schemas.BifrostError{
 		IsBifrostError: false,
+		Origin:         origin,
 		Error: schemas.ErrorField{
 			Message: message,
 			Error:   err,
@@ -93,6 +93,10 @@ func (baseAccount *BaseAccount) GetConfigForProvider(providerKey schemas.ModelPr
 		providerConfig.ProxyConfig = config.ProxyConfig
 	}
 
+	if config.HTTPClientProfile != nil {
+		providerConfig.HTTPClientProfile = config.HTTPClientProfile
+	}
+
 	if config.NetworkConfig != nil {
 		providerConfig.NetworkConfig = *config.NetworkConfig
 	} else {
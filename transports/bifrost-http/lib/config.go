@@ -9,14 +9,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	bifrost "github.com/maximhq/bifrost/core"
 	"github.com/maximhq/bifrost/core/schemas"
 	"github.com/maximhq/bifrost/framework/configstore"
+	"github.com/maximhq/bifrost/framework/kms"
+	"github.com/maximhq/bifrost/framework/leader"
 	"github.com/maximhq/bifrost/framework/logstore"
+	"github.com/maximhq/bifrost/framework/statestore"
 	"github.com/maximhq/bifrost/framework/vectorstore"
 	"github.com/maximhq/bifrost/plugins/semanticcache"
 	"gorm.io/gorm"
@@ -28,6 +33,8 @@ import (
 type HandlerStore interface {
 	// ShouldAllowDirectKeys returns whether direct API keys in headers are allowed
 	ShouldAllowDirectKeys() bool
+	// GetStreamGuardConfig returns the protection settings for streaming responses
+	GetStreamGuardConfig() StreamGuardConfig
 }
 
 // ConfigData represents the configuration data for the Bifrost HTTP transport.
@@ -39,6 +46,9 @@ type ConfigData struct {
 	MCP               *schemas.MCPConfig                    `json:"mcp,omitempty"`
 	Governance        *configstore.GovernanceConfig         `json:"governance,omitempty"`
 	VectorStoreConfig *vectorstore.Config                   `json:"vector_store,omitempty"`
+	StateStoreConfig  *statestore.Config                    `json:"state_store,omitempty"`
+	LeaderElection    *leader.Config                        `json:"leader_election,omitempty"`
+	KMS               *kms.Config                           `json:"kms,omitempty"`
 	ConfigStoreConfig *configstore.Config                   `json:"config_store,omitempty"`
 	LogsStoreConfig   *logstore.Config                      `json:"logs_store,omitempty"`
 	Plugins           []*schemas.PluginConfig               `json:"plugins,omitempty"`
@@ -55,6 +65,9 @@ func (cd *ConfigData) UnmarshalJSON(data []byte) error {
 		MCP               *schemas.MCPConfig                    `json:"mcp,omitempty"`
 		Governance        *configstore.GovernanceConfig         `json:"governance,omitempty"`
 		VectorStoreConfig json.RawMessage                       `json:"vector_store,omitempty"`
+		StateStoreConfig  json.RawMessage                       `json:"state_store,omitempty"`
+		LeaderElection    *leader.Config                        `json:"leader_election,omitempty"`
+		KMS               *kms.Config                           `json:"kms,omitempty"`
 		ConfigStoreConfig json.RawMessage                       `json:"config_store,omitempty"`
 		LogsStoreConfig   json.RawMessage                       `json:"logs_store,omitempty"`
 		Plugins           []*schemas.PluginConfig               `json:"plugins,omitempty"`
@@ -70,6 +83,8 @@ func (cd *ConfigData) UnmarshalJSON(data []byte) error {
 	cd.Providers = temp.Providers
 	cd.MCP = temp.MCP
 	cd.Governance = temp.Governance
+	cd.LeaderElection = temp.LeaderElection
+	cd.KMS = temp.KMS
 	cd.Plugins = temp.Plugins
 
 	// Parse VectorStoreConfig using its internal unmarshaler
@@ -81,6 +96,15 @@ func (cd *ConfigData) UnmarshalJSON(data []byte) error {
 		cd.VectorStoreConfig = &vectorStoreConfig
 	}
 
+	// Parse StateStoreConfig using its internal unmarshaler
+	if len(temp.StateStoreConfig) > 0 {
+		var stateStoreConfig statestore.Config
+		if err := json.Unmarshal(temp.StateStoreConfig, &stateStoreConfig); err != nil {
+			return fmt.Errorf("failed to unmarshal state store config: %w", err)
+		}
+		cd.StateStoreConfig = &stateStoreConfig
+	}
+
 	// Parse ConfigStoreConfig using its internal unmarshaler
 	if len(temp.ConfigStoreConfig) > 0 {
 		var configStoreConfig configstore.Config
@@ -122,6 +146,13 @@ type Config struct {
 	ConfigStore configstore.ConfigStore
 	VectorStore vectorstore.VectorStore
 	LogsStore   logstore.LogStore
+	StateStore  statestore.StateStore // Shared by replicas, nil for a single instance
+
+	// Elects the replica running maintenance tasks once for the fleet, nil when every replica runs them
+	Elector *leader.Elector
+
+	// Decrypts "enc:" values in the config file and in values set via the API
+	KMS *kms.Resolver
 
 	// In-memory storage
 	ClientConfig     configstore.ClientConfig
@@ -137,15 +168,18 @@ type Config struct {
 }
 
 var DefaultClientConfig = configstore.ClientConfig{
-	DropExcessRequests:      false,
-	PrometheusLabels:        []string{},
-	InitialPoolSize:         schemas.DefaultInitialPoolSize,
-	EnableLogging:           true,
-	EnableGovernance:        true,
-	EnforceGovernanceHeader: false,
-	AllowDirectKeys:         false,
-	AllowedOrigins:          []string{},
-	MaxRequestBodySizeMB:    100,
+	DropExcessRequests:        false,
+	PrometheusLabels:          []string{},
+	InitialPoolSize:           schemas.DefaultInitialPoolSize,
+	EnableLogging:             true,
+	EnableGovernance:          true,
+	EnforceGovernanceHeader:   false,
+	AllowDirectKeys:           false,
+	AllowedOrigins:            []string{},
+	MaxRequestBodySizeMB:      100,
+	StreamWriteTimeoutSeconds: int(DefaultStreamWriteTimeout / time.Second),
+	StreamBufferMaxChunks:     DefaultStreamBufferMaxChunks,
+	SlowStreamClientPolicy:    SlowStreamClientPolicyTerminate,
 }
 
 // LoadConfig loads initial configuration from a JSON config file into memory
@@ -185,6 +219,11 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 		// If config file doesn't exist, we will directly use the config store (create one if it doesn't exist)
 		if os.IsNotExist(err) {
 			logger.Info("config file not found at path: %s, initializing with default values", absConfigFilePath)
+			// Encrypted values set via the API can still use the local kms key
+			config.KMS, err = kms.NewResolver(ctx, nil, logger)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize kms: %w", err)
+			}
 			// Initializing with default values
 			config.ConfigStore, err = configstore.NewConfigStore(&configstore.Config{
 				Enabled: true,
@@ -274,6 +313,7 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 						NetworkConfig:            dbProvider.NetworkConfig,
 						ConcurrencyAndBufferSize: dbProvider.ConcurrencyAndBufferSize,
 						ProxyConfig:              dbProvider.ProxyConfig,
+						HTTPClientProfile:        dbProvider.HTTPClientProfile,
 						SendBackRawResponse:      dbProvider.SendBackRawResponse,
 						CustomProviderConfig:     dbProvider.CustomProviderConfig,
 					}
@@ -359,6 +399,22 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 
 	logger.Info("loading configuration from: %s", absConfigFilePath)
 
+	// Decrypting "enc:" values before parsing, so secrets are only ever plaintext in memory
+	var kmsData struct {
+		KMS *kms.Config `json:"kms,omitempty"`
+	}
+	if err := json.Unmarshal(data, &kmsData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	config.KMS, err = kms.NewResolver(ctx, kmsData.KMS, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize kms: %w", err)
+	}
+	data, err = config.KMS.DecryptJSON(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt config: %w", err)
+	}
+
 	var configData ConfigData
 	if err := json.Unmarshal(data, &configData); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
@@ -398,6 +454,30 @@ func LoadConfig(ctx context.Context, configDirPath string) (*Config, error) {
 		}
 	}
 
+	// Initializing state store
+	if configData.StateStoreConfig != nil && configData.StateStoreConfig.Enabled {
+		logger.Info("connecting to state store")
+		config.StateStore, err = statestore.NewStateStore(ctx, configData.StateStoreConfig, logger)
+		if err != nil {
+			logger.Fatal("failed to connect to state store: %v", err)
+		}
+	}
+
+	// Initializing leader election, replicas sharing stores run maintenance tasks once
+	if configData.LeaderElection != nil && configData.LeaderElection.Enabled {
+		if config.StateStore == nil {
+			logger.Fatal("leader election requires a state store")
+		}
+		config.Elector, err = leader.NewElector(config.StateStore, *configData.LeaderElection, logger)
+		if err != nil {
+			logger.Fatal("failed to initialize leader election: %v", err)
+		}
+		if err := config.Elector.Start(ctx); err != nil {
+			logger.Fatal("failed to start leader election: %v", err)
+		}
+		logger.Info("leader election started as replica %s", config.Elector.ID())
+	}
+
 	// From now on, config store gets the priority if enabled and we find data
 	// if we don't find any data in the store, then we resort to config file
 
@@ -748,13 +828,23 @@ func (s *Config) GetRawConfigString() string {
 
 // processEnvValue checks and replaces environment variable references in configuration values.
 // Returns the processed value and the environment variable name if it was an env reference.
-// Supports the "env.VARIABLE_NAME" syntax for referencing environment variables.
+// Supports the "env.VARIABLE_NAME" syntax for referencing environment variables, and
+// "enc:<provider>:<ciphertext>" values decrypted through the configured KMS.
 // This enables secure configuration management without hardcoding sensitive values.
 //
 // Examples:
 //   - "env.OPENAI_API_KEY" -> actual value from OPENAI_API_KEY environment variable
+//   - "enc:aws:AQICAHh..." -> value decrypted with AWS KMS
 //   - "sk-1234567890" -> returned as-is (no env prefix)
 func (s *Config) processEnvValue(value string) (string, string, error) {
+	if kms.IsEncrypted(value) {
+		if s.KMS == nil {
+			return "", "", fmt.Errorf("encrypted value found but no kms is configured")
+		}
+		decrypted, err := s.KMS.Decrypt(context.Background(), value)
+		return decrypted, "", err
+	}
+
 	v := strings.TrimSpace(value)
 	if !strings.HasPrefix(v, "env.") {
 		return value, "", nil // do not trim non-env values
@@ -840,6 +930,16 @@ func (s *Config) ShouldAllowDirectKeys() bool {
 	return s.ClientConfig.AllowDirectKeys
 }
 
+// GetStreamGuardConfig returns the protection settings for streaming responses. Unset values
+// are filled with defaults by GuardStream.
+func (s *Config) GetStreamGuardConfig() StreamGuardConfig {
+	return StreamGuardConfig{
+		WriteTimeout:      time.Duration(s.ClientConfig.StreamWriteTimeoutSeconds) * time.Second,
+		MaxBufferedChunks: s.ClientConfig.StreamBufferMaxChunks,
+		Policy:            s.ClientConfig.SlowStreamClientPolicy,
+	}
+}
+
 // GetProviderConfigRedacted retrieves a provider configuration with sensitive values redacted.
 // This method is intended for external API responses and logging.
 //
@@ -872,6 +972,7 @@ func (s *Config) GetProviderConfigRedacted(provider schemas.ModelProvider) (*con
 		NetworkConfig:            config.NetworkConfig,
 		ConcurrencyAndBufferSize: config.ConcurrencyAndBufferSize,
 		ProxyConfig:              config.ProxyConfig,
+		HTTPClientProfile:        config.HTTPClientProfile,
 		SendBackRawResponse:      config.SendBackRawResponse,
 		CustomProviderConfig:     config.CustomProviderConfig,
 	}
@@ -1258,6 +1359,46 @@ func (s *Config) GetAllKeys() ([]configstore.TableKey, error) {
 	return keys, nil
 }
 
+// UpdateKeyWeights sets the routing weights of the keys of a provider by key ID, leaving the rest
+// of the provider configuration untouched. It returns the IDs that match no key of the provider.
+func (s *Config) UpdateKeyWeights(provider schemas.ModelProvider, weights map[string]float64) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, exists := s.Providers[provider]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	// Copy the keys, the slice is shared with readers of GetProviderConfigRaw
+	config.Keys = slices.Clone(config.Keys)
+	matched := make(map[string]bool, len(weights))
+	for i, key := range config.Keys {
+		if weight, ok := weights[key.ID]; ok {
+			config.Keys[i].Weight = weight
+			matched[key.ID] = true
+		}
+	}
+	var unknown []string
+	for keyID := range weights {
+		if !matched[keyID] {
+			unknown = append(unknown, keyID)
+		}
+	}
+	slices.Sort(unknown)
+
+	s.Providers[provider] = config
+
+	if s.ConfigStore != nil {
+		if err := s.ConfigStore.UpdateProvider(provider, config, s.EnvKeys); err != nil {
+			return unknown, fmt.Errorf("failed to update provider config in store: %w", err)
+		}
+	}
+
+	logger.Info("Updated key weights for provider: %s", provider)
+	return unknown, nil
+}
+
 // processMCPEnvVars processes environment variables in the MCP configuration.
 // This method handles the MCP config structures and processes environment
 // variables in their fields, ensuring type safety and proper field handling.
@@ -2087,6 +2228,11 @@ func ValidateCustomProvider(config configstore.ProviderConfig, provider schemas.
 	if !bifrost.IsSupportedBaseProvider(cpc.BaseProviderType) {
 		return fmt.Errorf("custom provider validation failed: unsupported base_provider_type: %s", cpc.BaseProviderType)
 	}
+
+	// Template providers are entirely described by their request templates
+	if cpc.BaseProviderType == schemas.Template && cpc.RequestTemplates == nil {
+		return fmt.Errorf("custom provider validation failed: request_templates is required for base_provider_type %s", schemas.Template)
+	}
 	return nil
 }
 
@@ -45,6 +45,7 @@ import (
 //   - x-bf-team: Team identifier for team-based governance rules
 //   - x-bf-user: User identifier for user-based governance rules
 //   - x-bf-customer: Customer identifier for customer-based governance rules
+//   - The customer, else the team, else the virtual key is the tenant of fair queuing
 //
 // 5. API Key Headers:
 //   - Authorization: Bearer token format only (e.g., "Bearer sk-...") - OpenAI style
@@ -52,6 +53,29 @@ import (
 //   - Keys are extracted and stored in the context using schemas.BifrostContextKey
 //   - This enables explicit key usage for requests via headers
 //
+// 6. Queue Status Header:
+//   - x-bf-queue-status: "true" makes streaming requests emit queue position and ETA
+//     events while they wait for provider capacity
+//
+// 7. Session Affinity Header:
+//   - x-bf-session-id: Routes all turns of a session to the provider and key that served it,
+//     responses carry extra_fields.cache_reset when the session had to move
+//
+//   - x-bf-routing-key: Hashes the requests of a user onto the keys of a provider so they keep
+//     hitting the same deployment or node, the session ID is used if it is not set
+//
+// 8. Speculative Draft Header (experimental):
+//   - x-bf-speculative-draft: "provider/model" of a fast model whose output is streamed while the
+//     requested model warms up, followed by a resync event once the requested model starts
+//
+// 9. Agent Trace Header:
+//   - x-bf-trace-id: Groups the model calls and tool executions of an agent loop into one trace
+//     when agent tracing is enabled, responses carry the trace ID in extra_fields.trace_id
+//
+// 10. Incomplete Stream Header:
+//   - x-bf-incomplete-stream: "finish", "error" or "ignore", how a stream the provider closes
+//     without [DONE] or a finish reason is ended
+//
 
 // Parameters:
 //   - ctx: The FastHTTP request context containing the original headers
@@ -192,6 +216,51 @@ func ConvertToBifrostContext(ctx *fasthttp.RequestCtx, allowDirectKeys bool) *co
 			}
 		}
 
+		// Handle queue status header (x-bf-queue-status), streams report queue position while waiting
+		if keyStr == "x-bf-queue-status" {
+			if valueStr := string(value); valueStr == "true" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyQueueStatusEvents, true)
+			}
+		}
+
+		// Handle session affinity header (x-bf-session-id), turns of a session stick to one provider
+		if keyStr == "x-bf-session-id" {
+			if valueStr := strings.TrimSpace(string(value)); valueStr != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeySessionID, valueStr)
+			}
+		}
+
+		// Handle routing key header (x-bf-routing-key), requests of a user stick to one provider key
+		if keyStr == "x-bf-routing-key" {
+			if valueStr := strings.TrimSpace(string(value)); valueStr != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyRoutingKey, valueStr)
+			}
+		}
+
+		// Handle agent trace header (x-bf-trace-id), steps of an agent loop share a trace
+		if keyStr == "x-bf-trace-id" {
+			if valueStr := strings.TrimSpace(string(value)); valueStr != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyTraceID, valueStr)
+			}
+		}
+
+		// Handle incomplete stream header (x-bf-incomplete-stream), how streams closed early are ended
+		if keyStr == "x-bf-incomplete-stream" {
+			if behavior := schemas.IncompleteStreamBehavior(strings.ToLower(strings.TrimSpace(string(value)))); behavior.IsValid() {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyIncompleteStream, behavior)
+			}
+		}
+
+		// Handle speculative draft header (x-bf-speculative-draft), chat streams open with a draft model
+		if keyStr == "x-bf-speculative-draft" {
+			if provider, model, ok := strings.Cut(strings.TrimSpace(string(value)), "/"); ok && provider != "" && model != "" {
+				bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeySpeculativeDraft, schemas.SpeculativeDraft{
+					Provider: schemas.ModelProvider(provider),
+					Model:    model,
+				})
+			}
+		}
+
 		return true
 	})
 
@@ -200,6 +269,14 @@ func ConvertToBifrostContext(ctx *fasthttp.RequestCtx, allowDirectKeys bool) *co
 		bifrostCtx = context.WithValue(bifrostCtx, maxim.ContextKey(maxim.TagsKey), maximTags)
 	}
 
+	// Requests of a tenant share its fair queuing weight
+	for _, header := range []string{"x-bf-customer", "x-bf-team", "x-bf-vk"} {
+		if tenant := strings.TrimSpace(string(ctx.Request.Header.Peek(header))); tenant != "" {
+			bifrostCtx = context.WithValue(bifrostCtx, schemas.BifrostContextKeyTenant, tenant)
+			break
+		}
+	}
+
 	if allowDirectKeys {
 		// Extract API key from Authorization header (Bearer format) or x-api-key header
 		var apiKey string
@@ -0,0 +1,275 @@
+package lib
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+)
+
+// Policies applied to streaming clients that read slower than the provider streams.
+const (
+	// SlowStreamClientPolicyTerminate cancels the upstream request and ends the response.
+	SlowStreamClientPolicyTerminate = "terminate"
+	// SlowStreamClientPolicyDegrade merges buffered text deltas into fewer, larger chunks and
+	// drops queue status updates, terminating only when merging frees no room.
+	SlowStreamClientPolicyDegrade = "degrade"
+)
+
+// Default slow streaming client protection settings.
+const (
+	DefaultStreamWriteTimeout    = 30 * time.Second
+	DefaultStreamBufferMaxChunks = 1024
+)
+
+// StreamGuardConfig configures the protection of streaming responses against slow clients.
+type StreamGuardConfig struct {
+	WriteTimeout      time.Duration // Longest a single write to the client may block
+	MaxBufferedChunks int           // Chunks held for the client before the policy applies
+	Policy            string        // SlowStreamClientPolicyTerminate or SlowStreamClientPolicyDegrade
+}
+
+// StreamGuard decouples a Bifrost stream from the client connection. A goroutine drains the
+// stream into a bounded buffer as fast as the provider produces chunks, so a stalled client
+// never blocks the provider stream; the client's writer takes chunks from the buffer with Next.
+// When the buffer is full the configured policy applies, and a terminated guard cancels the
+// upstream request and discards the rest of the stream.
+type StreamGuard struct {
+	config StreamGuardConfig
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	buffer   []*schemas.BifrostStream
+	ended    bool   // The upstream stream is closed
+	stopped  bool   // The guard was terminated
+	reason   string // Why the guard was terminated
+	degraded bool   // Whether buffered chunks are being merged
+	notify   chan struct{}
+}
+
+// GuardStream starts draining stream into a StreamGuard. cancel must cancel the context of the
+// request that produced stream.
+func GuardStream(stream chan *schemas.BifrostStream, cancel context.CancelFunc, config StreamGuardConfig) *StreamGuard {
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = DefaultStreamWriteTimeout
+	}
+	if config.MaxBufferedChunks <= 0 {
+		config.MaxBufferedChunks = DefaultStreamBufferMaxChunks
+	}
+	if config.Policy != SlowStreamClientPolicyDegrade {
+		config.Policy = SlowStreamClientPolicyTerminate
+	}
+
+	g := &StreamGuard{
+		config: config,
+		cancel: cancel,
+		notify: make(chan struct{}, 1),
+	}
+	go g.pump(stream)
+	return g
+}
+
+// ArmWriteDeadline gives the next write to conn the write timeout to complete. A nil conn,
+// as in tests without a network connection, is ignored.
+func (g *StreamGuard) ArmWriteDeadline(conn net.Conn) {
+	if conn != nil {
+		conn.SetWriteDeadline(time.Now().Add(g.config.WriteTimeout))
+	}
+}
+
+// ClearWriteDeadline removes the write deadline from conn once the stream is written, so a
+// kept-alive connection is not closed by it later.
+func (g *StreamGuard) ClearWriteDeadline(conn net.Conn) {
+	if conn != nil {
+		conn.SetWriteDeadline(time.Time{})
+	}
+}
+
+// pump moves chunks from the upstream stream into the buffer until the stream closes.
+func (g *StreamGuard) pump(stream chan *schemas.BifrostStream) {
+	for chunk := range stream {
+		if chunk == nil {
+			continue
+		}
+		g.mu.Lock()
+		if g.stopped {
+			// Keep draining so the producer is never blocked
+			g.mu.Unlock()
+			continue
+		}
+		if !(g.degraded && chunk.QueueStatus != nil) {
+			g.buffer = append(g.buffer, chunk)
+			if len(g.buffer) > g.config.MaxBufferedChunks {
+				g.overflow()
+			}
+		}
+		g.mu.Unlock()
+		g.signal()
+	}
+
+	g.mu.Lock()
+	g.ended = true
+	g.mu.Unlock()
+	g.signal()
+}
+
+// overflow applies the policy to a full buffer. Callers must hold the lock.
+func (g *StreamGuard) overflow() {
+	if g.config.Policy == SlowStreamClientPolicyDegrade {
+		if !g.degraded {
+			g.degraded = true
+			logger.Warn("streaming client is slow, merging buffered chunks")
+		}
+		g.buffer = coalesceStreamChunks(g.buffer)
+		if len(g.buffer) <= g.config.MaxBufferedChunks {
+			return
+		}
+	}
+	g.stop("client is not reading the stream fast enough")
+}
+
+// stop terminates the guard and cancels the upstream request. Callers must hold the lock.
+func (g *StreamGuard) stop(reason string) {
+	if g.stopped {
+		return
+	}
+	g.stopped = true
+	g.reason = reason
+	g.buffer = nil
+	g.cancel()
+	logger.Warn("terminated stream to slow client: %s", reason)
+}
+
+// signal wakes up a waiting Next.
+func (g *StreamGuard) signal() {
+	select {
+	case g.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Next returns the next chunk to write to the client, blocking until one is available. It
+// returns false once the stream has ended or the guard was terminated.
+func (g *StreamGuard) Next() (*schemas.BifrostStream, bool) {
+	for {
+		g.mu.Lock()
+		switch {
+		case g.stopped:
+			g.mu.Unlock()
+			return nil, false
+		case len(g.buffer) > 0:
+			chunk := g.buffer[0]
+			g.buffer[0] = nil
+			g.buffer = g.buffer[1:]
+			g.mu.Unlock()
+			return chunk, true
+		case g.ended:
+			g.mu.Unlock()
+			return nil, false
+		}
+		g.mu.Unlock()
+		<-g.notify
+	}
+}
+
+// Terminate stops the stream, e.g. after a failed or timed out write, cancelling the upstream
+// request and discarding the remaining chunks.
+func (g *StreamGuard) Terminate(reason string) {
+	g.mu.Lock()
+	g.stop(reason)
+	g.mu.Unlock()
+	g.signal()
+}
+
+// Terminated returns why the guard was terminated, empty if it was not.
+func (g *StreamGuard) Terminated() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.reason
+}
+
+// coalesceStreamChunks merges runs of adjacent chunks that only carry text deltas into single
+// chunks and drops queue status updates. Chunks with tool calls, finish reasons, usage, errors
+// or any other payload are kept as they are and end the run.
+func coalesceStreamChunks(chunks []*schemas.BifrostStream) []*schemas.BifrostStream {
+	merged := make([]*schemas.BifrostStream, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.QueueStatus != nil {
+			continue
+		}
+		if n := len(merged); n > 0 && isTextDeltaChunk(merged[n-1]) && isTextDeltaChunk(chunk) && sameChoices(merged[n-1], chunk) {
+			merged[n-1] = mergeTextDeltaChunks(merged[n-1], chunk)
+			continue
+		}
+		merged = append(merged, chunk)
+	}
+	return merged
+}
+
+// isTextDeltaChunk reports whether a chunk carries nothing but content, thought and refusal deltas.
+func isTextDeltaChunk(chunk *schemas.BifrostStream) bool {
+	response := chunk.BifrostResponse
+	if response == nil || chunk.BifrostError != nil || chunk.Resync != nil || len(response.Choices) == 0 ||
+		response.Usage != nil || response.Speech != nil || response.Transcribe != nil || len(response.SearchResults) > 0 {
+		return false
+	}
+	extra := response.ExtraFields
+	if extra.CacheReset != nil || extra.PromptCache != nil || extra.AbortReason != nil || extra.BilledUsage != nil || len(extra.Warnings) > 0 {
+		return false
+	}
+	for _, choice := range response.Choices {
+		if choice.FinishReason != nil || choice.BifrostStreamResponseChoice == nil {
+			return false
+		}
+		delta := choice.Delta
+		if delta.Role != nil || delta.ThoughtSignature != nil || len(delta.ToolCalls) > 0 || len(delta.Annotations) > 0 || len(delta.Citations) > 0 || len(delta.Images) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sameChoices reports whether two chunks belong to the same response and carry the same choices.
+func sameChoices(a, b *schemas.BifrostStream) bool {
+	if a.ID != b.ID || a.ExtraFields.Draft != b.ExtraFields.Draft || len(a.Choices) != len(b.Choices) {
+		return false
+	}
+	for i := range a.Choices {
+		if a.Choices[i].Index != b.Choices[i].Index {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeTextDeltaChunks returns a new chunk with the deltas of b appended to those of a, carrying
+// the chunk index of b. Neither chunk is modified.
+func mergeTextDeltaChunks(a, b *schemas.BifrostStream) *schemas.BifrostStream {
+	response := *a.BifrostResponse
+	response.ExtraFields.ChunkIndex = b.ExtraFields.ChunkIndex
+	response.Choices = make([]schemas.BifrostResponseChoice, len(a.Choices))
+	for i, choice := range a.Choices {
+		delta := choice.Delta
+		next := b.Choices[i].Delta
+		delta.Content = concatDelta(delta.Content, next.Content)
+		delta.Thought = concatDelta(delta.Thought, next.Thought)
+		delta.Refusal = concatDelta(delta.Refusal, next.Refusal)
+		choice.BifrostStreamResponseChoice = &schemas.BifrostStreamResponseChoice{Delta: delta}
+		response.Choices[i] = choice
+	}
+	return &schemas.BifrostStream{BifrostResponse: &response}
+}
+
+// concatDelta appends an optional text delta to another.
+func concatDelta(a, b *string) *string {
+	switch {
+	case b == nil:
+		return a
+	case a == nil:
+		return b
+	}
+	merged := *a + *b
+	return &merged
+}
@@ -64,6 +64,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -71,7 +72,13 @@ import (
 	"github.com/fasthttp/router"
 	bifrost "github.com/maximhq/bifrost/core"
 	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/framework/artifactstore"
+	"github.com/maximhq/bifrost/framework/deprecation"
+	"github.com/maximhq/bifrost/framework/jsonstream"
 	"github.com/maximhq/bifrost/framework/pricing"
+	"github.com/maximhq/bifrost/framework/samplinglimits"
+	"github.com/maximhq/bifrost/framework/termfilter"
+	"github.com/maximhq/bifrost/framework/usageledger"
 	"github.com/maximhq/bifrost/plugins/governance"
 	"github.com/maximhq/bifrost/plugins/logging"
 	"github.com/maximhq/bifrost/plugins/maxim"
@@ -101,6 +108,11 @@ var (
 
 	logLevel       string // Logger level: debug, info, warn, error
 	logOutputStyle string // Logger output style: json, pretty
+
+	validateOnly bool   // Run the startup self-test, print the readiness report and exit
+	probeModels  string // Models probed for keys serving every model, e.g. "openai=gpt-4o-mini,anthropic=claude-3-5-haiku-latest"
+	warmUp       bool   // Open connections to providers at startup and after idle periods
+	simulateFile string // Policy simulation scenario to replay against the config, printing the report and exiting
 )
 
 const (
@@ -118,6 +130,10 @@ const (
 //   - app-dir: Application data directory (default: current directory)
 //   - log-level: Logger level (debug, info, warn, error). Default is info.
 //   - log-style: Logger output type (json or pretty). Default is JSON.
+//   - validate: Run the startup self-test, print the readiness report and exit (non-zero if not ready).
+//   - probe-models: Models the self-test probes for keys serving every model, as provider=model pairs.
+//   - warm-up: Open connections to providers at startup and after idle periods, probing the models of probe-models at startup.
+//   - simulate: Replay a policy simulation scenario against the config, print the report and exit (non-zero if an expectation is unmet).
 
 func init() {
 	if Version == "" {
@@ -156,6 +172,10 @@ func init() {
 	flag.StringVar(&appDir, "app-dir", DefaultAppDir, "Application data directory (contains config.json and logs)")
 	flag.StringVar(&logLevel, "log-level", DefaultLogLevel, "Logger level (debug, info, warn, error). Default is info.")
 	flag.StringVar(&logOutputStyle, "log-style", DefaultLogOutputStyle, "Logger output type (json or pretty). Default is JSON.")
+	flag.BoolVar(&validateOnly, "validate", false, "Probe every configured provider, key and model, check plugins, print a readiness report and exit (non-zero if not ready)")
+	flag.StringVar(&probeModels, "probe-models", "", "Models probed for keys serving every model, as comma separated provider=model pairs")
+	flag.BoolVar(&warmUp, "warm-up", false, "Open connections to every provider at startup and after idle periods, sending a one-token probe to the models of -probe-models at startup")
+	flag.StringVar(&simulateFile, "simulate", "", "Replay the requests and failures of a policy simulation scenario file against the routing, fallback and budget config, print the report and exit (non-zero if an expectation is unmet)")
 	flag.Parse()
 
 	// Configure logger from flags
@@ -163,6 +183,115 @@ func init() {
 	logger.SetLevel(schemas.LogLevel(logLevel))
 }
 
+// runSelfTest validates the configuration, prints the readiness report to stdout and returns the
+// exit code: 0 if ready, 1 otherwise. Besides the checks of Bifrost.Validate, it fails plugins that
+// are enabled in the config but were not loaded, since their initialization errors are only logged.
+func runSelfTest(ctx context.Context, client *bifrost.Bifrost, config *lib.Config, loadedPlugins []schemas.Plugin) int {
+	opts := &schemas.ValidateOptions{ProbeModels: parseProbeModels()}
+
+	report := client.Validate(ctx, opts)
+	for _, plugin := range config.Plugins {
+		if !plugin.Enabled || slices.ContainsFunc(loadedPlugins, func(loaded schemas.Plugin) bool {
+			return loaded.GetName() == strings.ToLower(plugin.Name)
+		}) {
+			continue
+		}
+		report.AddPlugin(schemas.ComponentReadiness{
+			Name:    plugin.Name,
+			Status:  schemas.ReadinessFailed,
+			Message: "enabled in config but not loaded: unknown plugin, missing dependency or invalid config, see logs",
+		})
+	}
+	client.Shutdown()
+	if config.Elector != nil {
+		config.Elector.Stop()
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		logger.Fatal("failed to marshal readiness report: %v", err)
+	}
+	fmt.Println(string(output))
+
+	if !report.Ready {
+		logger.Error("self-test failed: %d checks failed", report.Summary[schemas.ReadinessFailed])
+		return 1
+	}
+	logger.Info("self-test passed")
+	return 0
+}
+
+// parseProbeModels parses the provider=model pairs of the probe-models flag.
+func parseProbeModels() map[schemas.ModelProvider]string {
+	models := make(map[schemas.ModelProvider]string)
+	for _, pair := range strings.Split(probeModels, ",") {
+		provider, model, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			if pair != "" {
+				logger.Fatal("invalid probe model %q, expected provider=model", pair)
+			}
+			continue
+		}
+		models[schemas.ModelProvider(strings.TrimSpace(provider))] = strings.TrimSpace(model)
+	}
+	return models
+}
+
+// runSimulation replays the policy simulation scenario of simulateFile, prints the report to stdout
+// and returns the exit code: 0 if every request met its expectation, 1 otherwise. The scenario's
+// governance config and providers default to the ones of the config, and no request is sent.
+func runSimulation(config *lib.Config) int {
+	data, err := os.ReadFile(simulateFile)
+	if err != nil {
+		logger.Fatal("failed to read simulation scenario: %v", err)
+	}
+	var scenario governance.SimulationScenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		logger.Fatal("failed to parse simulation scenario: %v", err)
+	}
+
+	if scenario.Governance == nil {
+		scenario.Governance = config.GovernanceConfig
+	}
+	if len(scenario.Providers) == 0 {
+		scenario.Providers = make(map[schemas.ModelProvider]governance.SimulatedProvider, len(config.Providers))
+		for provider, providerConfig := range config.Providers {
+			// A key serving every model makes the provider serve every model
+			var models []string
+			for _, key := range providerConfig.Keys {
+				if len(key.Models) == 0 {
+					models = nil
+					break
+				}
+				models = append(models, key.Models...)
+			}
+			scenario.Providers[provider] = governance.SimulatedProvider{Models: models}
+		}
+	}
+	scenario.VirtualKeyRequired = scenario.VirtualKeyRequired || config.ClientConfig.EnforceGovernanceHeader
+
+	report, err := governance.Simulate(&scenario, logger)
+	if config.Elector != nil {
+		config.Elector.Stop()
+	}
+	if err != nil {
+		logger.Fatal("failed to run simulation: %v", err)
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		logger.Fatal("failed to marshal simulation report: %v", err)
+	}
+	fmt.Println(string(output))
+
+	if !report.Passed {
+		logger.Error("simulation failed: %d requests did not meet their expectation", report.Summary.Unmet)
+		return 1
+	}
+	logger.Info("simulation passed")
+	return 0
+}
+
 // registerCollectorSafely attempts to register a Prometheus collector,
 // handling the case where it may already be registered.
 // It logs any errors that occur during registration, except for AlreadyRegisteredError.
@@ -350,11 +479,16 @@ func main() {
 	if err != nil {
 		logger.Fatal("failed to load config %v", err)
 	}
+	if simulateFile != "" {
+		os.Exit(runSimulation(config))
+	}
 
 	// Initialize pricing manager
 	pricingManager, err := pricing.Init(config.ConfigStore, logger)
 	if err != nil {
 		logger.Error("failed to initialize pricing manager: %v", err)
+	} else if config.Elector != nil {
+		pricingManager.SetLeaderCheck(config.Elector.IsLeader)
 	}
 
 	// Create account backed by the high-performance store (all processing is done in LoadFromDatabase)
@@ -381,6 +515,28 @@ func main() {
 		if err != nil {
 			logger.Fatal("failed to initialize logging plugin: %v", err)
 		}
+		if config.Elector != nil {
+			loggingPlugin.SetLeaderCheck(config.Elector.IsLeader)
+		}
+		// A logging plugin entry carries the initial content sampling, which the admin API changes at runtime
+		for _, plugin := range config.Plugins {
+			if strings.ToLower(plugin.Name) != logging.PluginName || plugin.Config == nil {
+				continue
+			}
+			var loggingConfig logging.Config
+			configBytes, err := json.Marshal(plugin.Config)
+			if err != nil {
+				logger.Fatal("failed to marshal logging config: %v", err)
+			}
+			if err := json.Unmarshal(configBytes, &loggingConfig); err != nil {
+				logger.Fatal("failed to unmarshal logging config: %v", err)
+			}
+			if loggingConfig.Sampling != nil {
+				if err := loggingPlugin.SetSamplingConfig(*loggingConfig.Sampling); err != nil {
+					logger.Fatal("invalid logging sampling config: %v", err)
+				}
+			}
+		}
 
 		loadedPlugins = append(loadedPlugins, loggingPlugin)
 		loggingHandler = handlers.NewLoggingHandler(loggingPlugin.GetPluginLogManager(), logger)
@@ -391,10 +547,25 @@ func main() {
 	var governanceHandler *handlers.GovernanceHandler
 
 	if config.ClientConfig.EnableGovernance {
+		// A "governance" plugin entry carries the optional plugin settings, the header enforcement comes from the client config
+		var governanceConfig governance.Config
+		for _, plugin := range config.Plugins {
+			if strings.ToLower(plugin.Name) != governance.PluginName || plugin.Config == nil {
+				continue
+			}
+			configBytes, err := json.Marshal(plugin.Config)
+			if err != nil {
+				logger.Fatal("failed to marshal governance config: %v", err)
+			}
+			if err := json.Unmarshal(configBytes, &governanceConfig); err != nil {
+				logger.Fatal("failed to unmarshal governance config: %v", err)
+			}
+		}
+		governanceConfig.IsVkMandatory = &config.ClientConfig.EnforceGovernanceHeader
+		governanceConfig.StateStore = config.StateStore
+
 		// Initialize governance plugin
-		governancePlugin, err = governance.Init(ctx, &governance.Config{
-			IsVkMandatory: &config.ClientConfig.EnforceGovernanceHeader,
-		}, logger, config.ConfigStore, config.GovernanceConfig, pricingManager)
+		governancePlugin, err = governance.Init(ctx, &governanceConfig, logger, config.ConfigStore, config.GovernanceConfig, pricingManager)
 		if err != nil {
 			logger.Error("failed to initialize governance plugin: %s", err.Error())
 		} else {
@@ -407,6 +578,37 @@ func main() {
 		}
 	}
 
+	// Deprecation warnings are enabled by default, a "deprecation" plugin entry configures or disables them
+	deprecationEnabled := true
+	var deprecationConfig deprecation.Config
+	for _, plugin := range config.Plugins {
+		if strings.ToLower(plugin.Name) != deprecation.PluginName {
+			continue
+		}
+		deprecationEnabled = plugin.Enabled
+		if plugin.Config != nil {
+			configBytes, err := json.Marshal(plugin.Config)
+			if err != nil {
+				logger.Fatal("failed to marshal deprecation config: %v", err)
+			}
+			if err := json.Unmarshal(configBytes, &deprecationConfig); err != nil {
+				logger.Fatal("failed to unmarshal deprecation config: %v", err)
+			}
+		}
+	}
+	if deprecationEnabled {
+		if config.Elector != nil {
+			deprecationConfig.StateStore = config.StateStore
+			deprecationConfig.IsLeader = config.Elector.IsLeader
+		}
+		deprecationPlugin, err := deprecation.Init(ctx, deprecationConfig, logger)
+		if err != nil {
+			logger.Error("failed to initialize deprecation plugin: %v", err)
+		} else {
+			loadedPlugins = append(loadedPlugins, deprecationPlugin)
+		}
+	}
+
 	// Currently we support first party plugins only
 	// Eventually same flow will be used for third party plugins
 	for _, plugin := range config.Plugins {
@@ -434,6 +636,118 @@ func main() {
 			} else {
 				loadedPlugins = append(loadedPlugins, maximPlugin)
 			}
+		case telemetry.PluginName:
+			var telemetryConfig telemetry.Config
+			if plugin.Config != nil {
+				configBytes, err := json.Marshal(plugin.Config)
+				if err != nil {
+					logger.Fatal("failed to marshal telemetry config: %v", err)
+				}
+				if err := json.Unmarshal(configBytes, &telemetryConfig); err != nil {
+					logger.Fatal("failed to unmarshal telemetry config: %v", err)
+				}
+			}
+
+			if telemetryConfig.StatsExport != nil {
+				if err := promPlugin.EnableStatsExport(ctx, *telemetryConfig.StatsExport, logger); err != nil {
+					logger.Error("failed to start provider stats exporter: %v", err)
+				} else {
+					logger.Info("provider stats exporter pushing to %s", telemetryConfig.StatsExport.URL)
+				}
+			}
+
+			if telemetryConfig.AnalyticsExport != nil {
+				if err := promPlugin.EnableAnalyticsExport(ctx, *telemetryConfig.AnalyticsExport, logger); err != nil {
+					logger.Error("failed to start analytics exporter: %v", err)
+				} else {
+					logger.Info("anonymized analytics exporter pushing to %s", telemetryConfig.AnalyticsExport.URL)
+				}
+			}
+		case jsonstream.PluginName:
+			var jsonStreamConfig jsonstream.Config
+			if plugin.Config != nil {
+				configBytes, err := json.Marshal(plugin.Config)
+				if err != nil {
+					logger.Fatal("failed to marshal json stream validation config: %v", err)
+				}
+				if err := json.Unmarshal(configBytes, &jsonStreamConfig); err != nil {
+					logger.Fatal("failed to unmarshal json stream validation config: %v", err)
+				}
+			}
+
+			loadedPlugins = append(loadedPlugins, jsonstream.Init(jsonStreamConfig, logger))
+		case termfilter.PluginName:
+			var termFilterConfig termfilter.Config
+			if plugin.Config != nil {
+				configBytes, err := json.Marshal(plugin.Config)
+				if err != nil {
+					logger.Fatal("failed to marshal term filter config: %v", err)
+				}
+				if err := json.Unmarshal(configBytes, &termFilterConfig); err != nil {
+					logger.Fatal("failed to unmarshal term filter config: %v", err)
+				}
+			}
+
+			termFilterPlugin, err := termfilter.Init(termFilterConfig, logger)
+			if err != nil {
+				logger.Error("failed to initialize term filter plugin: %v", err)
+			} else {
+				loadedPlugins = append(loadedPlugins, termFilterPlugin)
+			}
+		case samplinglimits.PluginName:
+			var samplingLimitsConfig samplinglimits.Config
+			if plugin.Config != nil {
+				configBytes, err := json.Marshal(plugin.Config)
+				if err != nil {
+					logger.Fatal("failed to marshal sampling limits config: %v", err)
+				}
+				if err := json.Unmarshal(configBytes, &samplingLimitsConfig); err != nil {
+					logger.Fatal("failed to unmarshal sampling limits config: %v", err)
+				}
+			}
+
+			samplingLimitsPlugin, err := samplinglimits.Init(samplingLimitsConfig, logger)
+			if err != nil {
+				logger.Error("failed to initialize sampling limits plugin: %v", err)
+			} else {
+				loadedPlugins = append(loadedPlugins, samplingLimitsPlugin)
+			}
+		case artifactstore.PluginName:
+			var artifactsConfig artifactstore.PluginConfig
+			if plugin.Config != nil {
+				configBytes, err := json.Marshal(plugin.Config)
+				if err != nil {
+					logger.Fatal("failed to marshal artifacts config: %v", err)
+				}
+				if err := json.Unmarshal(configBytes, &artifactsConfig); err != nil {
+					logger.Fatal("failed to unmarshal artifacts config: %v", err)
+				}
+			}
+
+			artifactsPlugin, err := artifactstore.Init(ctx, artifactsConfig, logger)
+			if err != nil {
+				logger.Error("failed to initialize artifacts plugin: %v", err)
+			} else {
+				loadedPlugins = append(loadedPlugins, artifactsPlugin)
+			}
+		case usageledger.PluginName:
+			var usageConfig usageledger.PluginConfig
+			if plugin.Config != nil {
+				configBytes, err := json.Marshal(plugin.Config)
+				if err != nil {
+					logger.Fatal("failed to marshal usage ledger config: %v", err)
+				}
+				if err := json.Unmarshal(configBytes, &usageConfig); err != nil {
+					logger.Fatal("failed to unmarshal usage ledger config: %v", err)
+				}
+			}
+
+			usagePlugin, err := usageledger.Init(ctx, usageConfig, pricingManager, logger)
+			if err != nil {
+				logger.Error("failed to initialize usage ledger plugin: %v", err)
+			} else {
+				loadedPlugins = append(loadedPlugins, usagePlugin)
+			}
 		case semanticcache.PluginName:
 			if config.VectorStore == nil {
 				logger.Error("vector store is required to initialize semantic cache plugin, skipping initialization")
@@ -462,6 +776,12 @@ func main() {
 		}
 	}
 
+	// The self-test probes providers itself
+	var warmUpConfig *schemas.WarmUpConfig
+	if warmUp && !validateOnly {
+		warmUpConfig = &schemas.WarmUpConfig{ProbeModels: parseProbeModels()}
+	}
+
 	client, err := bifrost.Init(ctx, schemas.BifrostConfig{
 		Account:            account,
 		InitialPoolSize:    config.ClientConfig.InitialPoolSize,
@@ -469,6 +789,8 @@ func main() {
 		Plugins:            loadedPlugins,
 		MCPConfig:          config.MCPConfig,
 		Logger:             logger,
+		WarmUp:             warmUpConfig,
+		FairQueuing:        config.ClientConfig.FairQueuing,
 	})
 	if err != nil {
 		logger.Fatal("failed to initialize bifrost: %v", err)
@@ -476,6 +798,10 @@ func main() {
 
 	config.SetBifrostClient(client)
 
+	if validateOnly {
+		os.Exit(runSelfTest(ctx, client, config, loadedPlugins))
+	}
+
 	// Initialize handlers
 	providerHandler := handlers.NewProviderHandler(config, client, logger)
 	completionHandler := handlers.NewCompletionHandler(client, config, logger)
@@ -483,11 +809,20 @@ func main() {
 	integrationHandler := handlers.NewIntegrationHandler(client, config)
 	configHandler := handlers.NewConfigHandler(client, logger, config)
 	pluginsHandler := handlers.NewPluginsHandler(config.ConfigStore, logger)
+	statsHandler := handlers.NewStatsHandler(promPlugin.GetStatsCollector(), logger)
+	stateHandler := handlers.NewStateHandler(config, client, governancePlugin, logger)
 
 	var cacheHandler *handlers.CacheHandler
+	var artifactsHandler *handlers.ArtifactsHandler
+	var usageHandler *handlers.UsageHandler
 	for _, plugin := range loadedPlugins {
-		if plugin.GetName() == semanticcache.PluginName {
+		switch plugin.GetName() {
+		case semanticcache.PluginName:
 			cacheHandler = handlers.NewCacheHandler(plugin, logger)
+		case artifactstore.PluginName:
+			artifactsHandler = handlers.NewArtifactsHandler(plugin, logger)
+		case usageledger.PluginName:
+			usageHandler = handlers.NewUsageHandler(plugin, logger)
 		}
 	}
 
@@ -507,9 +842,17 @@ func main() {
 	integrationHandler.RegisterRoutes(r)
 	configHandler.RegisterRoutes(r)
 	pluginsHandler.RegisterRoutes(r)
+	statsHandler.RegisterRoutes(r)
+	stateHandler.RegisterRoutes(r)
 	if cacheHandler != nil {
 		cacheHandler.RegisterRoutes(r)
 	}
+	if artifactsHandler != nil {
+		artifactsHandler.RegisterRoutes(r)
+	}
+	if usageHandler != nil {
+		usageHandler.RegisterRoutes(r)
+	}
 	if governanceHandler != nil {
 		governanceHandler.RegisterRoutes(r)
 	}
@@ -577,6 +920,10 @@ func main() {
 				wsHandler.Stop()
 			}
 			client.Shutdown()
+			// Hand over maintenance tasks without waiting for the lease to expire
+			if config.Elector != nil {
+				config.Elector.Stop()
+			}
 		}()
 
 		select {
@@ -0,0 +1,118 @@
+// Command schemagen exports the wire contract of the HTTP transport, its request, response,
+// stream chunk and error bodies, as a JSON Schema document and an OpenAPI document, so SDKs
+// for other languages can be generated from the Go structs instead of written by hand.
+//
+// Example usage, from the repository root:
+//
+//	cd transports && go run ./schemagen -out ../docs/apis/schemas -version "$(cat version)"
+//
+// It writes bifrost.schema.json and bifrost.openapi.json to the output directory. Run it
+// again whenever the types change; the output is stable so diffs show only real changes.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	schemas "github.com/maximhq/bifrost/core/schemas"
+	"github.com/maximhq/bifrost/core/wireschema"
+	"github.com/maximhq/bifrost/transports/bifrost-http/handlers"
+)
+
+const (
+	schemaID = "https://getbifrost.ai/schemas/bifrost.schema.json"
+	title    = "Bifrost HTTP Transport Wire Contract"
+)
+
+func main() {
+	out := flag.String("out", "docs/apis/schemas", "Directory to write the documents to")
+	version := flag.String("version", "dev", "Version recorded in the OpenAPI document")
+	flag.Parse()
+
+	if err := run(*out, *version); err != nil {
+		fmt.Fprintf(os.Stderr, "schemagen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(out, version string) error {
+	g := wireschema.NewGenerator()
+	g.Add("CompletionRequest", handlers.CompletionRequest{})
+	g.Add("BifrostResponse", schemas.BifrostResponse{})
+	g.Add("BifrostStream", schemas.BifrostStream{})
+	g.Add("BifrostError", schemas.BifrostError{})
+	g.Add("ToolCall", schemas.ToolCall{})
+	g.Add("BifrostMessage", schemas.BifrostMessage{})
+
+	// Every field of a completion request is optional on input except the model
+	g.Required(handlers.CompletionRequest{}, "model")
+
+	jsonSchema, err := g.JSONSchema(schemaID)
+	if err != nil {
+		return err
+	}
+	openAPI, err := g.OpenAPI(title, version, paths())
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(out, "bifrost.schema.json"), append(jsonSchema, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(out, "bifrost.openapi.json"), append(openAPI, '\n'), 0644)
+}
+
+// paths describes the unified endpoints of the transport in terms of the exported schemas.
+func paths() map[string]interface{} {
+	ref := func(name string) map[string]interface{} {
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	jsonBody := func(schema map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+	}
+	errorResponse := map[string]interface{}{
+		"description": "Error returned by Bifrost or the provider",
+		"content":     jsonBody(ref("BifrostError")),
+	}
+	completion := func(operationID, summary string, streams bool) map[string]interface{} {
+		content := jsonBody(ref("BifrostResponse"))
+		if streams {
+			// Each SSE data payload, or NDJSON line, is one stream chunk
+			content["text/event-stream"] = map[string]interface{}{"schema": ref("BifrostStream")}
+			content["application/x-ndjson"] = map[string]interface{}{"schema": ref("BifrostStream")}
+		}
+		return map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": operationID,
+				"summary":     summary,
+				"requestBody": map[string]interface{}{"required": true, "content": jsonBody(ref("CompletionRequest"))},
+				"responses": map[string]interface{}{
+					"200":     map[string]interface{}{"description": "Successful response", "content": content},
+					"default": errorResponse,
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"/v1/chat/completions": completion("createChatCompletion", "Create a chat completion", true),
+		"/v1/text/completions": completion("createTextCompletion", "Create a text completion", true),
+		"/v1/embeddings":       completion("createEmbedding", "Create embeddings", false),
+		"/v1/mcp/tool/execute": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "executeMCPTool",
+				"summary":     "Execute an MCP tool call",
+				"requestBody": map[string]interface{}{"required": true, "content": jsonBody(ref("ToolCall"))},
+				"responses": map[string]interface{}{
+					"200":     map[string]interface{}{"description": "Tool result message", "content": jsonBody(ref("BifrostMessage"))},
+					"default": errorResponse,
+				},
+			},
+		},
+	}
+}
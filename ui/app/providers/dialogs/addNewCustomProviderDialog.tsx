@@ -21,6 +21,10 @@ const allowedRequestsSchema = z.object({
 	speech_stream: z.boolean(),
 	transcription: z.boolean(),
 	transcription_stream: z.boolean(),
+	translation: z.boolean(),
+	image_generation: z.boolean(),
+	video_generation: z.boolean(),
+	rerank: z.boolean(),
 });
 
 const formSchema = z.object({
@@ -53,6 +57,10 @@ export default function AddCustomProviderDialog({ show, onClose, onSave }: Props
 				speech_stream: true,
 				transcription: true,
 				transcription_stream: true,
+				translation: true,
+				image_generation: true,
+				video_generation: true,
+				rerank: true,
 			},
 		},
 	});
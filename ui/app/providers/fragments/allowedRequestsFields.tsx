@@ -18,6 +18,10 @@ const REQUEST_TYPES = [
 	{ key: "speech_stream", label: "Speech Stream" },
 	{ key: "transcription", label: "Transcription" },
 	{ key: "transcription_stream", label: "Transcription Stream" },
+	{ key: "translation", label: "Translation" },
+	{ key: "image_generation", label: "Image Generation" },
+	{ key: "video_generation", label: "Video Generation" },
+	{ key: "rerank", label: "Rerank" },
 ];
 
 export function AllowedRequestsFields({ control, namePrefix = "allowed_requests" }: AllowedRequestsFieldsProps) {
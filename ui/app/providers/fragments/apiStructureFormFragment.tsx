@@ -45,6 +45,10 @@ export function ApiStructureFormFragment({ provider, showRestartAlert }: Props)
 				speech_stream: provider.custom_provider_config?.allowed_requests?.speech_stream ?? true,
 				transcription: provider.custom_provider_config?.allowed_requests?.transcription ?? true,
 				transcription_stream: provider.custom_provider_config?.allowed_requests?.transcription_stream ?? true,
+				translation: provider.custom_provider_config?.allowed_requests?.translation ?? true,
+				image_generation: provider.custom_provider_config?.allowed_requests?.image_generation ?? true,
+				video_generation: provider.custom_provider_config?.allowed_requests?.video_generation ?? true,
+				rerank: provider.custom_provider_config?.allowed_requests?.rerank ?? true,
 			},
 		},
 	});
@@ -28,4 +28,8 @@ export const DEFAULT_ALLOWED_REQUESTS = {
 	speech_stream: true,
 	transcription: true,
 	transcription_stream: true,
+	translation: true,
+	image_generation: true,
+	video_generation: true,
+	rerank: true,
 } as const satisfies Required<AllowedRequests>;
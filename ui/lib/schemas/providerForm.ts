@@ -61,6 +61,10 @@ const AllowedRequestsSchema = z.object({
 	speech_stream: z.boolean(),
 	transcription: z.boolean(),
 	transcription_stream: z.boolean(),
+	translation: z.boolean(),
+	image_generation: z.boolean(),
+	video_generation: z.boolean(),
+	rerank: z.boolean(),
 });
 
 // Key configuration schemas
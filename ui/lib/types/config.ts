@@ -118,6 +118,10 @@ export interface AllowedRequests {
 	speech_stream: boolean;
 	transcription: boolean;
 	transcription_stream: boolean;
+	translation: boolean;
+	image_generation: boolean;
+	video_generation: boolean;
+	rerank: boolean;
 }
 
 export const DefaultAllowedRequests: AllowedRequests = {
@@ -129,6 +133,10 @@ export const DefaultAllowedRequests: AllowedRequests = {
 	speech_stream: true,
 	transcription: true,
 	transcription_stream: true,
+	translation: true,
+	image_generation: true,
+	video_generation: true,
+	rerank: true,
 } as const satisfies Required<AllowedRequests>;
 
 // CustomProviderConfig matching Go's schemas.CustomProviderConfig
@@ -217,6 +225,9 @@ export interface CoreConfig {
 	allow_direct_keys: boolean;
 	allowed_origins: string[];
 	max_request_body_size_mb: number;
+	stream_write_timeout_seconds?: number;
+	stream_buffer_max_chunks?: number;
+	slow_stream_client_policy?: "terminate" | "degrade";
 }
 
 // Semantic cache configuration types
@@ -212,6 +212,7 @@ export interface BifrostError {
 	type?: string;
 	is_bifrost_error: boolean;
 	status_code?: number;
+	origin?: "client_request" | "provider" | "network" | "bifrost_internal" | "policy";
 	error: ErrorField;
 }
 
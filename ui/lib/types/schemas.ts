@@ -193,6 +193,10 @@ export const allowedRequestsSchema = z.object({
 	speech_stream: z.boolean(),
 	transcription: z.boolean(),
 	transcription_stream: z.boolean(),
+	translation: z.boolean(),
+	image_generation: z.boolean(),
+	video_generation: z.boolean(),
+	rerank: z.boolean(),
 });
 
 // Custom provider config schema
@@ -284,6 +288,9 @@ export const coreConfigSchema = z.object({
 	allow_direct_keys: z.boolean().default(false),
 	allowed_origins: z.array(z.string()).default(["*"]),
 	max_request_body_size_mb: z.number().min(1).default(100),
+	stream_write_timeout_seconds: z.number().min(1).optional(),
+	stream_buffer_max_chunks: z.number().min(1).optional(),
+	slow_stream_client_policy: z.enum(["terminate", "degrade"]).optional(),
 });
 
 // Bifrost config schema